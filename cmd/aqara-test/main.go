@@ -50,7 +50,7 @@ func main() {
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
-	if err := db.SaveAqaraTokens(context.Background(), tokens); err != nil {
+	if err := aqara.NewTokenStore(db).Save(context.Background(), tokens); err != nil {
 		log.Fatalf("Failed to save refresh token: %v", err)
 	}
 
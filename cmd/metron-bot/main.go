@@ -78,11 +78,35 @@ func main() {
 
 	logger.Info("Webhook configured successfully")
 
+	// Alert on tamper events and on agent-managed devices that stop checking in
+	monitorCtx, stopMonitor := context.WithCancel(context.Background())
+	defer stopMonitor()
+	go telegramBot.RunDeviceMonitor(monitorCtx, time.Duration(cfg.Telegram.DeviceOfflineMinutes)*time.Minute)
+	if cfg.Telegram.SessionWarnings {
+		// Forward expiry warnings with buttons to extend or stop the session
+		go telegramBot.RunSessionMonitor(monitorCtx)
+	}
+	if cfg.Telegram.WeeklyDigest {
+		// Send the weekly usage digest on Monday mornings
+		go telegramBot.RunWeeklyDigest(monitorCtx)
+	}
+	if cfg.Telegram.ProfileTransitions {
+		// Ask parents to apply the new limit profile after a child's birthday
+		go telegramBot.RunProfileMonitor(monitorCtx)
+	}
+	if cfg.Telegram.UsageAlerts {
+		// Tell parents when a child has used most of the day's time
+		go telegramBot.RunUsageAlertMonitor(monitorCtx)
+	}
+
 	// Create HTTP router
+	webhookNetworks, _ := cfg.Telegram.WebhookNetworks() // Validated with the config
 	router := bot.NewRouter(bot.RouterConfig{
-		Bot:           telegramBot,
-		WebhookSecret: cfg.Telegram.WebhookSecret,
-		Logger:        logger,
+		Bot:             telegramBot,
+		WebhookSecrets:  cfg.Telegram.WebhookSecrets(),
+		WebhookNetworks: webhookNetworks,
+		TrustedProxies:  cfg.Server.TrustedProxies,
+		Logger:          logger,
 	})
 
 	// Create HTTP server
@@ -108,6 +132,7 @@ func main() {
 	<-quit
 
 	logger.Info("Shutting down bot...")
+	stopMonitor()
 
 	// Graceful shutdown with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"os/exec"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 	"time"
 
@@ -15,10 +17,14 @@ import (
 )
 
 const (
-	defaultPollInterval = 15
-	defaultGracePeriod  = 30
+	defaultPollInterval   = 15
+	defaultGracePeriod    = 30
+	defaultUpdateInterval = 360
 )
 
+// version is set at build time (-ldflags "-X main.version=1.4.0"); development builds never self-update
+var version = "dev"
+
 func main() {
 	// Parse command-line flags
 	deviceID := flag.String("device-id", "", "Device ID registered in Metron (required)")
@@ -29,8 +35,18 @@ func main() {
 	logPath := flag.String("log-path", "", "Log file path (stdout if empty)")
 	logLevel := flag.String("log-level", "info", "Log level: debug, info, warn, error")
 	logFormat := flag.String("log-format", "json", "Log format: json or text")
+	markerPath := flag.String("marker-path", filepath.Join(os.TempDir(), "metron-win-agent.running"),
+		"File that exists while the agent runs, used to detect the agent being killed (empty disables)")
+	updateKey := flag.String("update-key", "", "Base64 Ed25519 public key for signed self-updates (empty disables updates)")
+	updateInterval := flag.Int("update-interval", defaultUpdateInterval, "How often to check for updates (minutes)")
+	showVersion := flag.Bool("version", false, "Print the agent version and exit")
 	flag.Parse()
 
+	if *showVersion {
+		fmt.Println(version)
+		return
+	}
+
 	// Validate required flags
 	if *deviceID == "" {
 		fmt.Fprintln(os.Stderr, "Error: -device-id is required")
@@ -80,6 +96,7 @@ func main() {
 
 	mainLogger := logger.With("component", "main")
 	mainLogger.Info("Metron Windows Agent starting",
+		"version", version,
 		"device_id", *deviceID,
 		"metron_url", *metronURL,
 		"poll_interval", *pollInterval,
@@ -95,6 +112,10 @@ func main() {
 		GracePeriod:   time.Duration(*gracePeriod) * time.Second,
 		LogPath:       *logPath,
 		LogLevel:      *logLevel,
+		MarkerPath:    *markerPath,
+
+		UpdatePublicKey: *updateKey,
+		UpdateInterval:  time.Duration(*updateInterval) * time.Minute,
 	}
 
 	if err := config.Validate(); err != nil {
@@ -110,6 +131,19 @@ func main() {
 	// Create enforcer
 	enforcer := winagent.NewEnforcer(client, platform, clock, config, logger)
 
+	// A marker left over from the previous run means it was killed rather than stopped
+	// Logoff and shutdown arrive as signals and remove the marker, so only forced kills
+	// (Task Manager, taskkill /F) and power loss are reported
+	if config.MarkerPath != "" {
+		unclean, err := winagent.AcquireRunMarker(config.MarkerPath)
+		if err != nil {
+			mainLogger.Warn("Failed to write run marker", "path", config.MarkerPath, "error", err)
+		}
+		if unclean {
+			enforcer.ReportTamper(winagent.TamperProcessKill, "agent did not shut down cleanly (killed, crashed or power lost)")
+		}
+	}
+
 	// Setup context with cancellation
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -123,9 +157,37 @@ func main() {
 		enforcer.Start(ctx)
 	}()
 
-	// Wait for shutdown signal
-	sig := <-sigChan
-	mainLogger.Info("Shutdown signal received", "signal", sig.String())
+	// Check for signed updates in background
+	updated := make(chan struct{})
+	exePath, err := os.Executable()
+	if err != nil {
+		mainLogger.Warn("Cannot locate executable, updates disabled", "error", err)
+	} else if config.UpdatePublicKey != "" {
+		if err := winagent.RemovePreviousVersion(exePath); err != nil {
+			mainLogger.Warn("Failed to remove previous version", "error", err)
+		}
+
+		updater, err := winagent.NewUpdater(client, config, version, exePath, logger)
+		if err != nil {
+			mainLogger.Error("Invalid update key, updates disabled", "error", err)
+		} else {
+			go func() {
+				if updater.Run(ctx) {
+					close(updated)
+				}
+			}()
+		}
+	}
+
+	// Wait for shutdown signal or an installed update
+	restart := false
+	select {
+	case sig := <-sigChan:
+		mainLogger.Info("Shutdown signal received", "signal", sig.String())
+	case <-updated:
+		mainLogger.Info("Restarting into updated agent")
+		restart = true
+	}
 
 	// Cancel context to stop enforcer
 	cancel()
@@ -133,5 +195,20 @@ func main() {
 	// Give enforcer time to stop gracefully
 	time.Sleep(1 * time.Second)
 
+	if config.MarkerPath != "" {
+		if err := winagent.ReleaseRunMarker(config.MarkerPath); err != nil {
+			mainLogger.Warn("Failed to remove run marker", "path", config.MarkerPath, "error", err)
+		}
+	}
+
+	// The updated binary takes over with the same arguments
+	if restart {
+		cmd := exec.Command(exePath, os.Args[1:]...)
+		if err := cmd.Start(); err != nil {
+			mainLogger.Error("Failed to start updated agent", "error", err)
+			os.Exit(1)
+		}
+	}
+
 	mainLogger.Info("Metron Windows Agent stopped")
 }
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"metron/internal/agentupdate"
+)
+
+// runAgentReleaseCommand signs an agent binary and publishes it to a release directory,
+// or generates a signing key pair
+// Usage:
+//
+//	metron agent-release -keygen
+//	metron agent-release -key signing.key -version 1.4.0 -binary metron-win-agent.exe -dir /var/lib/metron/agent-updates
+func runAgentReleaseCommand(args []string, out io.Writer) int {
+	fs := flag.NewFlagSet("agent-release", flag.ContinueOnError)
+	fs.SetOutput(out)
+	keygen := fs.Bool("keygen", false, "Generate a signing key pair and exit")
+	keyPath := fs.String("key", "", "File with the base64 Ed25519 private key")
+	version := fs.String("version", "", "Release version (major.minor.patch)")
+	binaryPath := fs.String("binary", "", "Agent binary to publish")
+	dir := fs.String("dir", "", "Release directory (agent_update.dir in config.json)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *keygen {
+		publicKey, privateKey, err := agentupdate.GenerateKey()
+		if err != nil {
+			fmt.Fprintf(out, "Failed to generate key: %v\n", err)
+			return 1
+		}
+		fmt.Fprintf(out, "Public key (install agents with -update-key):\n%s\n\n", publicKey)
+		fmt.Fprintf(out, "Private key (keep offline, pass with -key):\n%s\n", privateKey)
+		return 0
+	}
+
+	if *keyPath == "" || *version == "" || *binaryPath == "" || *dir == "" {
+		fmt.Fprintln(out, "Usage: metron agent-release -key signing.key -version 1.4.0 -binary metron-win-agent.exe -dir release-dir")
+		fmt.Fprintln(out, "       metron agent-release -keygen")
+		return 2
+	}
+
+	encodedKey, err := os.ReadFile(*keyPath)
+	if err != nil {
+		fmt.Fprintf(out, "Failed to read key: %v\n", err)
+		return 1
+	}
+	privateKey, err := agentupdate.ParsePrivateKey(string(encodedKey))
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+		return 1
+	}
+
+	binary, err := os.ReadFile(*binaryPath)
+	if err != nil {
+		fmt.Fprintf(out, "Failed to read binary: %v\n", err)
+		return 1
+	}
+
+	release, err := agentupdate.Sign(privateKey, *version, filepath.Base(*binaryPath), binary)
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+		return 1
+	}
+	if err := agentupdate.WriteRelease(*dir, release, binary); err != nil {
+		fmt.Fprintf(out, "Failed to write release: %v\n", err)
+		return 1
+	}
+
+	fmt.Fprintf(out, "Published %s %s (%d bytes, sha256 %s) to %s\n", release.File, release.Version, release.Size, release.SHA256, *dir)
+	return 0
+}
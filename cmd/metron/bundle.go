@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"metron/config"
+	"metron/internal/bundle"
+	"metron/internal/storage/sqlite"
+)
+
+// runExportCommand writes the configuration and data to a bundle
+// Usage:
+//
+//	metron export [-config path] [-history] [-o metron-bundle.json]
+func runExportCommand(args []string, out io.Writer) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	fs.SetOutput(out)
+	configPath := fs.String("config", defaultConfigPath, "Path to configuration file")
+	useEnv := fs.Bool("env", false, "Load configuration from environment variables")
+	history := fs.Bool("history", false, "Include sessions, daily usage and allocations")
+	outPath := fs.String("o", "", "Bundle file to write (default standard output)")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	_, service, closeDB, err := openBundleService(*configPath, *useEnv)
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+		return 1
+	}
+	defer closeDB()
+
+	b, err := service.Export(context.Background(), *history)
+	if err != nil {
+		fmt.Fprintf(out, "Export failed: %v\n", err)
+		return 1
+	}
+
+	w := out
+	if *outPath != "" {
+		file, err := os.OpenFile(*outPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+		if err != nil {
+			fmt.Fprintf(out, "Failed to create bundle: %v\n", err)
+			return 1
+		}
+		defer file.Close()
+		w = file
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(b); err != nil {
+		fmt.Fprintf(out, "Failed to write bundle: %v\n", err)
+		return 1
+	}
+
+	if *outPath != "" {
+		fmt.Fprintf(out, "Exported %d children to %s\n", len(b.Children), *outPath)
+	}
+	return 0
+}
+
+// runImportCommand imports a bundle into the database, and optionally its configuration
+// sections into the configuration file
+// Usage:
+//
+//	metron import [-config path] [-merge-config] metron-bundle.json
+func runImportCommand(args []string, out io.Writer) int {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(out)
+	configPath := fs.String("config", defaultConfigPath, "Path to configuration file")
+	useEnv := fs.Bool("env", false, "Load configuration from environment variables")
+	mergeConfig := fs.Bool("merge-config", false, "Replace devices, downtime, movie_time and limit_profiles in the configuration file with the bundle's sections")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if fs.NArg() != 1 || (*mergeConfig && *useEnv) {
+		fmt.Fprintln(out, "Usage: metron import [-config path] [-merge-config] metron-bundle.json")
+		fmt.Fprintln(out, "       metron import -env metron-bundle.json")
+		return 2
+	}
+
+	file, err := os.Open(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(out, "Failed to open bundle: %v\n", err)
+		return 1
+	}
+	defer file.Close()
+	b, err := bundle.Read(file)
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+		return 1
+	}
+
+	cfg, service, closeDB, err := openBundleService(*configPath, *useEnv)
+	if err != nil {
+		fmt.Fprintf(out, "%v\n", err)
+		return 1
+	}
+	defer closeDB()
+
+	result, err := service.Import(context.Background(), b)
+	if err != nil {
+		fmt.Fprintf(out, "Import failed: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(out, "Imported %d children, %d limit changes, %d sessions, %d days of usage and %d allocations\n",
+		result.Children, result.LimitChanges, result.Sessions, result.UsageDays, result.Allocations)
+
+	if b.Config == nil {
+		return 0
+	}
+	if b.Config.Timezone != cfg.Timezone {
+		fmt.Fprintf(out, "Warning: the bundle was exported in timezone %s, this server uses %s\n", b.Config.Timezone, cfg.Timezone)
+	}
+	if !*mergeConfig {
+		fmt.Fprintf(out, "The bundle's devices, downtime, movie time and limit profiles were not applied (use -merge-config, or copy them from its \"config\" section)\n")
+		return 0
+	}
+	if err := mergeBundleConfig(*configPath, b.Config); err != nil {
+		fmt.Fprintf(out, "Failed to update %s: %v\n", *configPath, err)
+		return 1
+	}
+	fmt.Fprintf(out, "Updated %s (previous version saved as %s.bak); restart metron to apply it\n", *configPath, *configPath)
+	return 0
+}
+
+// openBundleService loads the configuration and opens its database for export or import
+// The server may keep running, but imported data is best loaded before it starts.
+func openBundleService(configPath string, useEnv bool) (*config.Config, *bundle.Service, func(), error) {
+	if useEnv {
+		configPath = ""
+	}
+	// Environment variables that are set override the file, like for the server
+	cfg, err := config.LoadLayered(configPath, nil)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	timezone, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load timezone '%s': %w", cfg.Timezone, err)
+	}
+	db, err := sqlite.New(cfg.Database.Path, timezone)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	service := bundle.NewService(db, bundle.NewConfig(cfg), timezone, nil)
+	return cfg, service, func() { db.Close() }, nil
+}
+
+// mergeBundleConfig replaces the sections the bundle has in the configuration file
+// Other settings are kept; the previous file is saved with a .bak suffix.
+func mergeBundleConfig(path string, c *bundle.Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var sections map[string]json.RawMessage
+	if err := json.Unmarshal(data, &sections); err != nil {
+		return err
+	}
+
+	for name, value := range map[string]any{
+		"devices":        c.Devices,
+		"downtime":       c.Downtime,
+		"movie_time":     c.MovieTime,
+		"limit_profiles": c.LimitProfiles,
+	} {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		if string(encoded) == "null" {
+			continue // Not in the bundle
+		}
+		sections[name] = encoded
+	}
+
+	merged, err := json.MarshalIndent(sections, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path+".bak", data, 0600); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(merged, '\n'), 0600)
+}
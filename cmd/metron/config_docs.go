@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"metron/config"
+)
+
+// runConfigCommand handles the config subcommands; "docs" writes the Markdown reference
+// of every server and bot option (with defaults and environment variables), generated from
+// the struct tags of the config types
+// Usage:
+//
+//	metron config docs
+//	metron config docs -o CONFIG_REFERENCE.md
+func runConfigCommand(args []string, out io.Writer) int {
+	if len(args) == 0 || args[0] != "docs" {
+		fmt.Fprintln(out, "Usage: metron config docs [-o file]")
+		return 2
+	}
+
+	fs := flag.NewFlagSet("config docs", flag.ContinueOnError)
+	fs.SetOutput(out)
+	outputPath := fs.String("o", "", "Write the reference to this file instead of stdout")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	if *outputPath == "" {
+		if err := config.WriteReference(out); err != nil {
+			fmt.Fprintf(out, "Failed to write reference: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	file, err := os.Create(*outputPath)
+	if err != nil {
+		fmt.Fprintf(out, "Failed to create %s: %v\n", *outputPath, err)
+		return 1
+	}
+	if err := config.WriteReference(file); err != nil {
+		file.Close()
+		fmt.Fprintf(out, "Failed to write reference: %v\n", err)
+		return 1
+	}
+	if err := file.Close(); err != nil {
+		fmt.Fprintf(out, "Failed to write reference: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(out, "Wrote configuration reference to %s\n", *outputPath)
+	return 0
+}
@@ -0,0 +1,362 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+
+	"metron/config"
+	"metron/internal/adb"
+	"metron/internal/devices"
+	"metron/internal/drivers"
+	"metron/internal/drivers/aqara"
+	"metron/internal/drivers/familylink"
+	"metron/internal/drivers/plugin"
+	"metron/internal/drivers/router"
+	"metron/internal/steam"
+	"metron/internal/storage/sqlite"
+)
+
+// doctorTimeout bounds how long the doctor command may spend on checks
+const doctorTimeout = 30 * time.Second
+
+// doctorStatus is the outcome of a single doctor check
+type doctorStatus string
+
+const (
+	doctorOK   doctorStatus = "OK"
+	doctorWarn doctorStatus = "WARN"
+	doctorFail doctorStatus = "FAIL"
+)
+
+// doctorResult is a single line of the doctor report
+type doctorResult struct {
+	Name    string
+	Status  doctorStatus
+	Message string
+}
+
+// doctorReport collects check results and renders them
+type doctorReport struct {
+	results []doctorResult
+}
+
+func (r *doctorReport) add(name string, status doctorStatus, format string, args ...interface{}) {
+	r.results = append(r.results, doctorResult{
+		Name:    name,
+		Status:  status,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+func (r *doctorReport) failures() int {
+	count := 0
+	for _, result := range r.results {
+		if result.Status == doctorFail {
+			count++
+		}
+	}
+	return count
+}
+
+func (r *doctorReport) print(w io.Writer) {
+	fmt.Fprintln(w, "Metron doctor report")
+	fmt.Fprintln(w)
+
+	ok, warn, fail := 0, 0, 0
+	for _, result := range r.results {
+		fmt.Fprintf(w, "[%-4s] %-12s %s\n", result.Status, result.Name, result.Message)
+		switch result.Status {
+		case doctorOK:
+			ok++
+		case doctorWarn:
+			warn++
+		case doctorFail:
+			fail++
+		}
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Summary: %d ok, %d warnings, %d failures\n", ok, warn, fail)
+}
+
+// runDoctorCommand parses doctor flags and runs all checks
+// Usage: metron doctor [-config path] [-env]
+func runDoctorCommand(args []string, out io.Writer) int {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fs.SetOutput(out)
+	configPath := fs.String("config", defaultConfigPath, "Path to configuration file")
+	useEnv := fs.Bool("env", false, "Load configuration from environment variables")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+	defer cancel()
+
+	report := runDoctor(ctx, *configPath, *useEnv)
+	report.print(out)
+
+	if report.failures() > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runDoctor checks configuration, timezone, database schema, driver credentials and devices
+func runDoctor(ctx context.Context, configPath string, useEnv bool) *doctorReport {
+	report := &doctorReport{}
+
+	// Configuration
+	var cfg *config.Config
+	var err error
+	source := configPath
+	if useEnv {
+		source = "environment"
+		configPath = ""
+	}
+	// Environment variables that are set override the file, like for the server
+	cfg, err = config.LoadLayered(configPath, nil)
+	if err != nil {
+		report.add("config", doctorFail, "failed to load from %s: %v", source, err)
+		// Nothing else can be checked without a configuration
+		return report
+	}
+	report.add("config", doctorOK, "loaded from %s", source)
+
+	// Timezone
+	if location, err := time.LoadLocation(cfg.Timezone); err != nil {
+		report.add("timezone", doctorFail, "cannot load %q: %v", cfg.Timezone, err)
+	} else {
+		report.add("timezone", doctorOK, "%s (local time %s)", cfg.Timezone, time.Now().In(location).Format("2006-01-02 15:04 MST"))
+	}
+
+	// Database schema
+	dbReady := checkDoctorDatabase(ctx, report, cfg.Database.Path)
+
+	// Driver credentials
+	checkDoctorDrivers(ctx, report, cfg, dbReady)
+
+	// Driver plugins
+	plugins := checkDoctorPlugins(ctx, report, cfg)
+
+	// Devices
+	checkDoctorDevices(report, cfg, plugins)
+
+	return report
+}
+
+// checkDoctorDatabase reports the schema version without migrating the database
+// Returns true if the database exists and has the current schema
+func checkDoctorDatabase(ctx context.Context, report *doctorReport, path string) bool {
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			report.add("database", doctorWarn, "%s does not exist yet (it will be created on first start)", path)
+			return false
+		}
+		report.add("database", doctorFail, "cannot access %s: %v", path, err)
+		return false
+	}
+
+	version, err := sqlite.ReadSchemaVersion(ctx, path)
+	if err != nil {
+		report.add("database", doctorFail, "%s: %v", path, err)
+		return false
+	}
+
+	switch {
+	case version == sqlite.SchemaVersion:
+		report.add("database", doctorOK, "%s (schema version %d)", path, version)
+		return true
+	case version < sqlite.SchemaVersion:
+		report.add("database", doctorWarn, "%s has schema version %d, expected %d (it will be migrated on next start)", path, version, sqlite.SchemaVersion)
+		return false
+	default:
+		report.add("database", doctorFail, "%s has schema version %d, newer than this binary supports (%d)", path, version, sqlite.SchemaVersion)
+		return false
+	}
+}
+
+// checkDoctorDrivers verifies credentials for every configured driver
+// Config validation already guarantees required fields, so this mostly checks stored tokens
+func checkDoctorDrivers(ctx context.Context, report *doctorReport, cfg *config.Config, dbReady bool) {
+	// Aqara: refresh token lives in the database
+	if !dbReady {
+		report.add("aqara", doctorWarn, "credentials configured, refresh token not checked (database not ready)")
+	} else {
+		// Read-only, so the check never migrates or writes the database
+		db, err := sqlite.NewReadOnly(cfg.Database.Path, nil)
+		if err != nil {
+			report.add("aqara", doctorFail, "failed to open database: %v", err)
+		} else {
+			driver := aqara.NewDriver(aqara.Config{}, db, nil)
+			if err := driver.HealthCheck(ctx); err != nil {
+				report.add("aqara", doctorFail, "%v", err)
+			} else {
+				report.add("aqara", doctorOK, "credentials configured, refresh token stored")
+			}
+			db.Close()
+		}
+	}
+
+	if cfg.Kidslox != nil {
+		report.add("kidslox", doctorOK, "credentials configured (account %s)", cfg.Kidslox.AccountID)
+	}
+
+	if cfg.Notify != nil {
+		report.add("notify", doctorOK, "telegram token configured, %d chat(s)", len(cfg.Notify.ChatIDs))
+	}
+
+	// Family Link: only the cookie format can be checked without changing devices
+	if cfg.FamilyLink != nil {
+		if _, err := familylink.NewHTTPClient(familylink.Config{Cookies: cfg.FamilyLink.Cookies, APIKey: cfg.FamilyLink.APIKey}); err != nil {
+			report.add("familylink", doctorFail, "%v", err)
+		} else {
+			report.add("familylink", doctorOK, "cookies configured")
+		}
+	}
+
+	// Android TV: devices may be asleep or unplugged, so only the key is checked
+	if cfg.AndroidTV != nil {
+		if cfg.AndroidTV.KeyFile == "" {
+			report.add("androidtv", doctorOK, "no default key, devices must set the key parameter")
+		} else if _, err := adb.LoadKey(cfg.AndroidTV.KeyFile); err != nil {
+			report.add("androidtv", doctorFail, "%v", err)
+		} else {
+			report.add("androidtv", doctorOK, "adb key loaded")
+		}
+	}
+
+	// Steam: look up the first account to check the API key
+	if cfg.Steam != nil {
+		devicesByID := make(map[string]bool, len(cfg.Devices))
+		for _, device := range cfg.Devices {
+			devicesByID[device.ID] = true
+		}
+		for _, account := range cfg.Steam.Accounts {
+			if account.DeviceID != "" && !devicesByID[account.DeviceID] {
+				report.add("steam", doctorFail, "account %s references unknown device %q", account.SteamID, account.DeviceID)
+			}
+		}
+
+		if _, err := steam.NewHTTPClient(cfg.Steam.APIKey).Player(ctx, cfg.Steam.Accounts[0].SteamID); err != nil {
+			report.add("steam", doctorFail, "%v", err)
+		} else {
+			report.add("steam", doctorOK, "api key accepted, %d account(s)", len(cfg.Steam.Accounts))
+		}
+	}
+
+	// HomeKit: controllers connect to the bridge, so only the device settings are checked
+	if cfg.HomeKit != nil {
+		devicesByID := make(map[string]bool, len(cfg.Devices))
+		for _, device := range cfg.Devices {
+			devicesByID[device.ID] = true
+		}
+		failed := false
+		for _, device := range cfg.HomeKit.Devices {
+			if !devicesByID[device.DeviceID] {
+				report.add("homekit", doctorFail, "start settings reference unknown device %q", device.DeviceID)
+				failed = true
+			}
+		}
+		if !failed {
+			report.add("homekit", doctorOK, "bridge with %d accessory(s), %d can start sessions", len(cfg.Devices), len(cfg.HomeKit.Devices))
+		}
+	}
+
+	// Router: log in to check the address and credentials
+	if cfg.Router != nil {
+		driver, err := router.NewDriver(router.Config{
+			Type:               cfg.Router.Type,
+			URL:                cfg.Router.URL,
+			Username:           cfg.Router.Username,
+			Password:           cfg.Router.Password,
+			InsecureSkipVerify: cfg.Router.InsecureSkipVerify,
+		}, devices.NewRegistry(), nil)
+		if err == nil {
+			err = driver.HealthCheck(ctx)
+		}
+		if err != nil {
+			report.add("router", doctorFail, "%v", err)
+		} else {
+			report.add("router", doctorOK, "%s router at %s accepts the credentials", cfg.Router.Type, cfg.Router.URL)
+		}
+	}
+}
+
+// checkDoctorPlugins starts each driver plugin and runs its health check
+// Returns the names of the plugins found in the drivers directory
+func checkDoctorPlugins(ctx context.Context, report *doctorReport, cfg *config.Config) []string {
+	if cfg.DriversDir == "" {
+		return nil
+	}
+	manifests, err := plugin.LoadManifests(cfg.DriversDir)
+	if err != nil {
+		report.add("plugins", doctorFail, "%v", err)
+		return nil
+	}
+	if len(manifests) == 0 {
+		report.add("plugins", doctorWarn, "no manifests in %s", cfg.DriversDir)
+		return nil
+	}
+
+	var names []string
+	for _, manifest := range manifests {
+		names = append(names, manifest.Name)
+		name := "plugin:" + manifest.Name
+
+		driver := plugin.NewDriver(manifest, devices.NewRegistry(), nil)
+		err := driver.Start(ctx)
+		if err == nil {
+			err = driver.HealthCheck(ctx)
+		}
+		driver.Close()
+		if err != nil {
+			report.add(name, doctorFail, "%v", err)
+		} else {
+			report.add(name, doctorOK, "started %s", manifest.Command)
+		}
+	}
+	return names
+}
+
+// checkDoctorDevices verifies every device references a driver that will be registered
+// The built-in drivers are registered like on startup, so the check follows the configuration.
+func checkDoctorDevices(report *doctorReport, cfg *config.Config, plugins []string) {
+	registry := drivers.NewRegistry()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	if _, err := registerBuiltinDrivers(cfg, nil, nil, devices.NewRegistry(), registry, logger); err != nil {
+		// Drivers registered before the failing one are still checked
+		report.add("drivers", doctorFail, "%v", err)
+	}
+
+	available := make(map[string]bool)
+	for _, name := range registry.List() {
+		available[name] = true
+	}
+	for _, name := range plugins {
+		available[name] = true
+	}
+
+	if len(cfg.Devices) == 0 {
+		report.add("devices", doctorWarn, "no devices configured")
+		return
+	}
+
+	for _, device := range cfg.Devices {
+		name := "device:" + device.ID
+		switch {
+		case len(device.ID) > 15:
+			report.add(name, doctorFail, "ID is longer than 15 characters")
+		case !available[device.Driver]:
+			report.add(name, doctorFail, "driver %q is not configured", device.Driver)
+		default:
+			report.add(name, doctorOK, "%s (driver %s)", device.Name, device.Driver)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+
+	"metron/config"
+	"metron/internal/credentials"
+	"metron/internal/devices"
+	"metron/internal/drivers"
+	"metron/internal/drivers/androidtv"
+	"metron/internal/drivers/aqara"
+	"metron/internal/drivers/familylink"
+	"metron/internal/drivers/kidslox"
+	"metron/internal/drivers/minecraft"
+	"metron/internal/drivers/notify"
+	"metron/internal/drivers/passive"
+	"metron/internal/drivers/roku"
+	"metron/internal/drivers/router"
+	"metron/internal/drivers/smarttv"
+)
+
+// builtinDrivers holds the built-in drivers the server sets up further after registering them
+type builtinDrivers struct {
+	familyLinkClient familylink.Client // nil unless Family Link is configured
+	smartTV          *smarttv.Driver   // nil unless smart TVs are configured
+}
+
+// registerBuiltinDrivers registers the built-in drivers enabled by the configuration
+// Driver plugins are registered separately. doctor registers the same drivers to check which
+// drivers devices can use, so the two can't disagree.
+func registerBuiltinDrivers(cfg *config.Config, credentialStore credentials.Store, children notify.ChildLookup, deviceRegistry *devices.Registry, driverRegistry *drivers.Registry, logger *slog.Logger) (*builtinDrivers, error) {
+	mainLogger := logger.With("component", "main")
+	registered := &builtinDrivers{}
+	var err error
+
+	// Register Aqara driver
+	mainLogger.Info("Registering Aqara Cloud driver",
+		"base_url", cfg.Aqara.BaseURL,
+		"pin_scene", cfg.Aqara.Scenes.TVPINEntry,
+		"warn_scene", cfg.Aqara.Scenes.TVWarning,
+		"off_scene", cfg.Aqara.Scenes.TVPowerOff)
+
+	aqaraConfig := aqara.Config{
+		AppID:       cfg.Aqara.AppID,
+		AppKey:      cfg.Aqara.AppKey,
+		KeyID:       cfg.Aqara.KeyID,
+		BaseURL:     cfg.Aqara.BaseURL,
+		PINSceneID:  cfg.Aqara.Scenes.TVPINEntry,
+		WarnSceneID: cfg.Aqara.Scenes.TVWarning,
+		OffSceneID:  cfg.Aqara.Scenes.TVPowerOff,
+		Timeout:     cfg.DriverTimeout("aqara"),
+	}
+	aqaraLogger := logger.With("component", "driver.aqara")
+	aqaraDriver := aqara.NewDriver(aqaraConfig, credentialStore, aqaraLogger)
+	if err := driverRegistry.Register(aqaraDriver); err != nil {
+		return nil, fmt.Errorf("failed to register aqara driver: %w", err)
+	}
+
+	// Register Kidslox driver if configured
+	if cfg.Kidslox != nil {
+		mainLogger.Info("Registering Kidslox driver")
+		kidsloxConfig := kidslox.Config{
+			BaseURL:   cfg.Kidslox.BaseURL,
+			APIKey:    cfg.Kidslox.APIKey,
+			AccountID: cfg.Kidslox.AccountID,
+			DeviceID:  cfg.Kidslox.DeviceID,
+			ProfileID: cfg.Kidslox.ProfileID,
+			Timeout:   cfg.DriverTimeout("kidslox"),
+		}
+		kidsloxLogger := logger.With("component", "driver.kidslox")
+		kidsloxDriver := kidslox.NewDriver(kidsloxConfig, deviceRegistry, kidsloxLogger)
+		if err := driverRegistry.Register(kidsloxDriver); err != nil {
+			return nil, fmt.Errorf("failed to register kidslox driver: %w", err)
+		}
+	}
+
+	// Register notify driver if configured (for manual-enforcement devices like Family Link)
+	if cfg.Notify != nil {
+		mainLogger.Info("Registering notify driver")
+		notifyConfig := notify.Config{
+			TelegramToken: cfg.Notify.TelegramToken,
+			ChatIDs:       cfg.Notify.ChatIDs,
+		}
+		notifyLogger := logger.With("component", "driver.notify")
+		notifyDriver := notify.NewDriver(notifyConfig, children, deviceRegistry, notifyLogger)
+		if err := driverRegistry.Register(notifyDriver); err != nil {
+			return nil, fmt.Errorf("failed to register notify driver: %w", err)
+		}
+	}
+
+	// Register router driver if configured (internet access via firewall rules)
+	if cfg.Router != nil {
+		mainLogger.Info("Registering router driver", "type", cfg.Router.Type)
+		routerConfig := router.Config{
+			Type:               cfg.Router.Type,
+			URL:                cfg.Router.URL,
+			Username:           cfg.Router.Username,
+			Password:           cfg.Router.Password,
+			InsecureSkipVerify: cfg.Router.InsecureSkipVerify,
+		}
+		routerLogger := logger.With("component", "driver.router")
+		routerDriver, err := router.NewDriver(routerConfig, deviceRegistry, routerLogger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create router driver: %w", err)
+		}
+		if err := driverRegistry.Register(routerDriver); err != nil {
+			return nil, fmt.Errorf("failed to register router driver: %w", err)
+		}
+	}
+
+	// Register Family Link driver if configured (Android devices supervised with Family Link)
+	if cfg.FamilyLink != nil {
+		mainLogger.Info("Registering Family Link driver")
+		registered.familyLinkClient, err = familylink.NewHTTPClient(familylink.Config{
+			Cookies: cfg.FamilyLink.Cookies,
+			APIKey:  cfg.FamilyLink.APIKey,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create family link client: %w", err)
+		}
+		familyLinkLogger := logger.With("component", "driver.familylink")
+		familyLinkDriver := familylink.NewDriver(registered.familyLinkClient, deviceRegistry, familyLinkLogger)
+		if err := driverRegistry.Register(familyLinkDriver); err != nil {
+			return nil, fmt.Errorf("failed to register familylink driver: %w", err)
+		}
+	}
+
+	// Register smart TV driver if configured (Samsung/LG TVs on the local network)
+	if cfg.SmartTV != nil {
+		mainLogger.Info("Registering smart TV driver")
+		smartTVLogger := logger.With("component", "driver.smarttv")
+		registered.smartTV = smarttv.NewDriver(smarttv.Config{ClientName: cfg.SmartTV.ClientName}, deviceRegistry, smartTVLogger)
+		if err := driverRegistry.Register(registered.smartTV); err != nil {
+			return nil, fmt.Errorf("failed to register smarttv driver: %w", err)
+		}
+	}
+
+	// Register Android TV driver if configured (ADB network debugging)
+	if cfg.AndroidTV != nil {
+		mainLogger.Info("Registering Android TV driver")
+		androidTVLogger := logger.With("component", "driver.androidtv")
+		androidTVDriver, err := androidtv.NewDriver(androidtv.Config{
+			KeyFile:    cfg.AndroidTV.KeyFile,
+			ClientName: cfg.AndroidTV.ClientName,
+		}, deviceRegistry, androidTVLogger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create androidtv driver: %w", err)
+		}
+		if err := driverRegistry.Register(androidTVDriver); err != nil {
+			return nil, fmt.Errorf("failed to register androidtv driver: %w", err)
+		}
+
+		// Fire TV devices share the ADB key and commands
+		fireTVDriver, err := androidtv.NewFireTVDriver(androidtv.Config{
+			KeyFile:    cfg.AndroidTV.KeyFile,
+			ClientName: cfg.AndroidTV.ClientName,
+		}, deviceRegistry, logger.With("component", "driver.firetv"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create firetv driver: %w", err)
+		}
+		if err := driverRegistry.Register(fireTVDriver); err != nil {
+			return nil, fmt.Errorf("failed to register firetv driver: %w", err)
+		}
+	}
+
+	// Register Roku driver (ECP needs no credentials, devices are set by host)
+	mainLogger.Info("Registering Roku driver")
+	rokuDriver := roku.NewDriver(deviceRegistry, logger.With("component", "driver.roku"))
+	if err := driverRegistry.Register(rokuDriver); err != nil {
+		return nil, fmt.Errorf("failed to register roku driver: %w", err)
+	}
+
+	// Register Minecraft driver (RCON address and password are device parameters)
+	mainLogger.Info("Registering Minecraft driver")
+	minecraftDriver := minecraft.NewDriver(deviceRegistry, logger.With("component", "driver.minecraft"))
+	if err := driverRegistry.Register(minecraftDriver); err != nil {
+		return nil, fmt.Errorf("failed to register minecraft driver: %w", err)
+	}
+
+	// Register passive driver (for agent-controlled devices like Windows PCs)
+	mainLogger.Info("Registering passive driver for agent-controlled devices")
+	passiveLogger := logger.With("component", "driver.passive")
+	passiveDriver := passive.NewDriver(passiveLogger)
+	if err := driverRegistry.Register(passiveDriver); err != nil {
+		return nil, fmt.Errorf("failed to register passive driver: %w", err)
+	}
+
+	return registered, nil
+}
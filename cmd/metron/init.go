@@ -0,0 +1,333 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"metron/config"
+	"metron/internal/core"
+	"metron/internal/drivers/aqara"
+	"metron/internal/idgen"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// initDeviceID matches device IDs accepted by the wizard (short enough for Telegram buttons)
+var initDeviceID = regexp.MustCompile(`^[a-z0-9_-]{1,15}$`)
+
+// initPIN matches the 4-digit PINs children log in to the web app with
+var initPIN = regexp.MustCompile(`^\d{4}$`)
+
+// runInitCommand asks for the basic settings, writes a new configuration file, adds the first
+// child and device and checks that the drivers can be reached
+// Usage: metron init [-config path] [-force]
+func runInitCommand(args []string, in io.Reader, out io.Writer) int {
+	fs := flag.NewFlagSet("init", flag.ContinueOnError)
+	fs.SetOutput(out)
+	configPath := fs.String("config", defaultConfigPath, "Path of the configuration file to create")
+	force := fs.Bool("force", false, "Overwrite an existing configuration file")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if _, err := os.Stat(*configPath); err == nil && !*force {
+		fmt.Fprintf(out, "%s already exists; edit it, or run metron init -force to start over\n", *configPath)
+		return 1
+	}
+
+	p := &initPrompter{in: bufio.NewReader(in), out: out}
+	fmt.Fprintln(out, "Metron setup")
+	fmt.Fprintln(out, "Press Enter to accept the [default] answer.")
+
+	cfg, data, err := p.askConfig()
+	if err != nil {
+		fmt.Fprintf(out, "\nSetup aborted: %v\n", err)
+		return 1
+	}
+
+	if err := writeInitConfig(*configPath, cfg); err != nil {
+		fmt.Fprintf(out, "Failed to write %s: %v\n", *configPath, err)
+		return 1
+	}
+	fmt.Fprintf(out, "\nWrote %s\n", *configPath)
+
+	if err := initDatabase(cfg, data); err != nil {
+		fmt.Fprintf(out, "Failed to set up %s: %v\n", cfg.Database.Path, err)
+		return 1
+	}
+	fmt.Fprintf(out, "Added %s to %s\n\n", data.child.Name, cfg.Database.Path)
+
+	// The doctor checks the new configuration, database and driver connectivity
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+	defer cancel()
+	report := runDoctor(ctx, *configPath, false)
+	report.print(out)
+
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "API key (sent as X-Metron-Key; also goes into bot-config.json as metron.api_key): %s\n", cfg.Security.APIKey)
+	fmt.Fprintf(out, "Start the server with: metron -config %s\n", *configPath)
+	if report.failures() > 0 {
+		fmt.Fprintln(out, "Fix the failures above first (see CONFIG.md), then run metron doctor again.")
+		return 1
+	}
+	return 0
+}
+
+// askConfig asks for the settings of a minimal configuration and the first child
+func (p *initPrompter) askConfig() (*config.Config, *initData, error) {
+	cfg := &config.Config{}
+
+	p.section("Server")
+	timezone, err := p.askValid("Timezone (IANA name, e.g. Europe/Riga)", "UTC", func(value string) error {
+		_, err := time.LoadLocation(value)
+		return err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg.Timezone = timezone
+	if cfg.Server.Port, err = p.askInt("Port", 8080); err != nil {
+		return nil, nil, err
+	}
+	cfg.Server.Host = "0.0.0.0"
+	if cfg.Database.Path, err = p.ask("Database file", "./metron.db"); err != nil {
+		return nil, nil, err
+	}
+	if cfg.Security.APIKey, err = p.ask("API key (empty generates one)", ""); err != nil {
+		return nil, nil, err
+	}
+	if cfg.Security.APIKey == "" {
+		if cfg.Security.APIKey, err = generateAPIKey(); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	p.section("Aqara Cloud (required; app credentials from developer.aqara.com)")
+	for _, field := range []struct {
+		question string
+		value    *string
+	}{
+		{"App ID", &cfg.Aqara.AppID},
+		{"App key", &cfg.Aqara.AppKey},
+		{"Key ID", &cfg.Aqara.KeyID},
+	} {
+		if *field.value, err = p.askRequired(field.question); err != nil {
+			return nil, nil, err
+		}
+	}
+	cfg.Aqara.BaseURL = "https://open-cn.aqara.com"
+	refreshToken, err := p.ask("Refresh token (empty to add it later with POST /api/v1/admin/aqara/refresh-token)", "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p.section("First device")
+	device := config.DeviceConfig{}
+	if device.ID, err = p.askValid("Device ID (lowercase, up to 15 characters)", "tv1", func(value string) error {
+		if !initDeviceID.MatchString(value) {
+			return errors.New("use up to 15 lowercase letters, digits, - and _")
+		}
+		return nil
+	}); err != nil {
+		return nil, nil, err
+	}
+	if device.Name, err = p.ask("Name", "Living Room TV"); err != nil {
+		return nil, nil, err
+	}
+	if device.Type, err = p.ask("Type (tv, ps5, pc, tablet, ...)", "tv"); err != nil {
+		return nil, nil, err
+	}
+	if device.Driver, err = p.askValid("Driver: aqara (TV scenes) or passive (Windows agent or manual)", "aqara", func(value string) error {
+		if value != "aqara" && value != "passive" {
+			return errors.New("choose aqara or passive (other drivers are set up in the configuration file, see CONFIG.md)")
+		}
+		return nil
+	}); err != nil {
+		return nil, nil, err
+	}
+	if device.Driver == "aqara" {
+		for _, field := range []struct {
+			question string
+			value    *string
+		}{
+			{"Scene ID entering the TV PIN", &cfg.Aqara.Scenes.TVPINEntry},
+			{"Scene ID warning before the end", &cfg.Aqara.Scenes.TVWarning},
+			{"Scene ID turning the TV off", &cfg.Aqara.Scenes.TVPowerOff},
+		} {
+			if *field.value, err = p.ask(field.question, ""); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+	cfg.Devices = []config.DeviceConfig{device}
+
+	p.section("First child")
+	child := &core.Child{Emoji: "🧒"}
+	if child.Name, err = p.askRequired("Name"); err != nil {
+		return nil, nil, err
+	}
+	if child.WeekdayLimit, err = p.askInt("Minutes per weekday", 120); err != nil {
+		return nil, nil, err
+	}
+	if child.WeekendLimit, err = p.askInt("Minutes per weekend day", 180); err != nil {
+		return nil, nil, err
+	}
+	if child.PIN, err = p.askValid("4-digit PIN for the child web app (empty for none)", "", func(value string) error {
+		if value != "" && !initPIN.MatchString(value) {
+			return errors.New("the PIN must be 4 digits")
+		}
+		return nil
+	}); err != nil {
+		return nil, nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if err := child.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return cfg, &initData{child: child, aqaraRefreshToken: refreshToken}, nil
+}
+
+// initData is what the wizard stores in the database
+type initData struct {
+	child             *core.Child
+	aqaraRefreshToken string // Optional
+}
+
+// writeInitConfig writes a new configuration file, readable only by its owner (it contains keys)
+func writeInitConfig(path string, cfg *config.Config) error {
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0600)
+}
+
+// initDatabase creates the database (migrating it), adds the first child and stores the
+// Aqara refresh token, encrypted like the server does
+func initDatabase(cfg *config.Config, data *initData) error {
+	timezone, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return err
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	db, err := openStorage(storageSQLite, cfg.Database.Path, cfg.Security.CredentialsKey, timezone, logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	child := data.child
+	child.ID = idgen.NewChild()
+	if child.PIN != "" {
+		hash, err := bcrypt.GenerateFromPassword([]byte(child.PIN), bcrypt.DefaultCost)
+		if err != nil {
+			return err
+		}
+		child.PIN = string(hash)
+	}
+	if err := db.CreateChild(ctx, child); err != nil {
+		return fmt.Errorf("failed to add %s: %w", child.Name, err)
+	}
+
+	if data.aqaraRefreshToken != "" {
+		now := time.Now()
+		tokens := &aqara.AqaraTokens{RefreshToken: data.aqaraRefreshToken, CreatedAt: now, UpdatedAt: now}
+		if err := aqara.NewTokenStore(db).Save(ctx, tokens); err != nil {
+			return fmt.Errorf("failed to save the Aqara refresh token: %w", err)
+		}
+	}
+	return nil
+}
+
+// generateAPIKey returns a random 32-byte key, hex encoded
+func generateAPIKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(key), nil
+}
+
+// initPrompter asks questions on a terminal, one answer per line
+type initPrompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+// section prints the heading of a group of questions
+func (p *initPrompter) section(title string) {
+	fmt.Fprintf(p.out, "\n%s\n", title)
+}
+
+// ask returns the answer to a question, or defaultValue for an empty answer
+func (p *initPrompter) ask(question, defaultValue string) (string, error) {
+	if defaultValue != "" {
+		fmt.Fprintf(p.out, "  %s [%s]: ", question, defaultValue)
+	} else {
+		fmt.Fprintf(p.out, "  %s: ", question)
+	}
+	line, err := p.in.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", errors.New("no more input")
+	}
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer, nil
+	}
+	return defaultValue, nil
+}
+
+// askValid asks until the answer passes validate
+func (p *initPrompter) askValid(question, defaultValue string, validate func(string) error) (string, error) {
+	for {
+		answer, err := p.ask(question, defaultValue)
+		if err != nil {
+			return "", err
+		}
+		if err := validate(answer); err != nil {
+			fmt.Fprintf(p.out, "  %v\n", err)
+			continue
+		}
+		return answer, nil
+	}
+}
+
+// askRequired asks until the answer is not empty
+func (p *initPrompter) askRequired(question string) (string, error) {
+	return p.askValid(question, "", func(value string) error {
+		if value == "" {
+			return errors.New("an answer is required")
+		}
+		return nil
+	})
+}
+
+// askInt asks until the answer is a positive number
+func (p *initPrompter) askInt(question string, defaultValue int) (int, error) {
+	answer, err := p.askValid(question, strconv.Itoa(defaultValue), func(value string) error {
+		if n, err := strconv.Atoi(value); err != nil || n <= 0 {
+			return errors.New("enter a positive number")
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(answer)
+}
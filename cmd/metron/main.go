@@ -2,34 +2,142 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"metron/config"
 	"metron/internal/api"
+	"metron/internal/api/handlers"
+	"metron/internal/api/middleware"
+	"metron/internal/bundle"
 	"metron/internal/core"
+	"metron/internal/credentials"
 	"metron/internal/devices"
 	"metron/internal/drivers"
-	"metron/internal/drivers/aqara"
-	"metron/internal/drivers/kidslox"
-	"metron/internal/drivers/notify"
-	"metron/internal/drivers/passive"
+	"metron/internal/drivers/familylink"
+	"metron/internal/drivers/plugin"
+	"metron/internal/homekit"
 	"metron/internal/logging"
+	"metron/internal/mediaserver"
+	"metron/internal/metrics"
 	"metron/internal/scheduler"
+	"metron/internal/steam"
+	"metron/internal/storage"
+	"metron/internal/storage/memory"
 	"metron/internal/storage/sqlite"
+	"metron/internal/systemd"
+	"metron/internal/webhook"
+	"metron/internal/webpush"
 )
 
 const (
-	shutdownTimeout   = 10 * time.Second
-	defaultConfigPath = "config.json"
+	shutdownTimeout      = 10 * time.Second
+	schedulerStopTimeout = 30 * time.Second // Allows slow driver stop calls to finish
+	defaultConfigPath    = "config.json"
 )
 
+// Storage backends selectable with -storage
+const (
+	storageSQLite = "sqlite"
+	storageMemory = "memory"
+)
+
+// appStorage is everything the server needs from a storage backend
+type appStorage interface {
+	storage.Storage
+	credentials.Store
+	core.DowntimeSkipStorage
+	core.AgentTokenStorage
+	core.ChildLoginStorage
+	core.DowntimeOverrideStorage
+	core.UsageAlertStorage
+	core.DayRolloverStorage
+	core.AllocationStorage
+	core.DriverJobStorage
+	core.DriverCallStorage
+	core.LeaderLeaseStorage
+	core.ChildActivityStorage
+	core.AppUsageStorage
+	core.PushStorage
+	familylink.UsageImportStorage
+	steam.PlaytimeStorage
+	homekit.Storage
+	Ping(ctx context.Context) error
+}
+
+// openStorage opens the selected storage backend
+// The memory backend is meant for demos and testing: nothing survives a restart
+func openStorage(backend, dbPath, credentialsKey string, timezone *time.Location, logger *slog.Logger) (appStorage, error) {
+	switch backend {
+	case storageSQLite:
+		logger.Info("Initializing database", "path", dbPath)
+		db, err := sqlite.New(dbPath, timezone)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize database: %w", err)
+		}
+
+		// Encrypt driver credentials (e.g. Aqara tokens) with the key from config, env or keyring
+		key, source, err := credentials.LoadKey(credentialsKey)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to load credentials key: %w", err)
+		}
+		if key == nil {
+			logger.Warn("No credentials key configured; driver credentials are stored unencrypted",
+				"env", credentials.KeyEnv)
+			return db, nil
+		}
+		cipher, err := credentials.NewCipher(key)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		if err := db.SetCredentialCipher(context.Background(), cipher); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to encrypt stored credentials: %w", err)
+		}
+		logger.Info("Driver credentials are encrypted", "key_source", source, "key_id", cipher.KeyID())
+		return db, nil
+	case storageMemory:
+		logger.Warn("Using in-memory storage; all data is lost on exit")
+		return memory.New(timezone), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q (use %s or %s)", backend, storageSQLite, storageMemory)
+	}
+}
+
+// openReader opens the storage reporting endpoints read from (see config.DatabaseConfig.ReadPath)
+// Returns db itself without a read path or with the memory backend; close is a no-op then.
+func openReader(backend string, database config.DatabaseConfig, db appStorage, timezone *time.Location, logger *slog.Logger) (storage.Reader, func() error, error) {
+	path := database.ReadPath()
+	sqliteDB, ok := db.(*sqlite.SQLiteStorage)
+	if path == "" || backend != storageSQLite || !ok {
+		return db, func() error { return nil }, nil
+	}
+
+	if path == database.Path {
+		// Readers and the writer only stop blocking each other with write-ahead logging
+		if err := sqliteDB.EnableWAL(context.Background()); err != nil {
+			return nil, nil, err
+		}
+	}
+	reader, err := sqlite.NewReadOnly(path, timezone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open read-only database: %w", err)
+	}
+	logger.Info("Reporting endpoints use a read-only database connection", "path", path)
+	return reader, reader.Close, nil
+}
+
 // Adapter types to bridge interface differences between packages
 
 type coreDeviceRegistry struct {
@@ -60,6 +168,20 @@ type coreDriverAdapter struct {
 	devices.DeviceDriver
 }
 
+// Capabilities reports the wrapped driver's capabilities, which the embedded interface hides
+func (a *coreDriverAdapter) Capabilities() core.DriverCapabilities {
+	return core.CapabilitiesOf(a.DeviceDriver)
+}
+
+// ExtendSession forwards to the wrapped driver; only called when it supports extensions
+func (a *coreDriverAdapter) ExtendSession(ctx context.Context, session *core.Session, additionalMinutes int) error {
+	extendable, ok := a.DeviceDriver.(devices.ExtendableDriver)
+	if !ok {
+		return nil
+	}
+	return extendable.ExtendSession(ctx, session, additionalMinutes)
+}
+
 type schedulerDeviceRegistry struct {
 	registry *devices.Registry
 }
@@ -72,16 +194,139 @@ type schedulerDriverRegistry struct {
 	registry *drivers.Registry
 }
 
+// Get returns the driver itself (not wrapped) so the scheduler can read its capabilities
+// and call optional interfaces such as core.BreakableDriver
 func (r *schedulerDriverRegistry) Get(name string) (scheduler.DeviceDriver, error) {
 	driver, err := r.registry.Get(name)
 	if err != nil {
 		return nil, err
 	}
-	return &schedulerDriverAdapter{driver}, nil
+	return driver, nil
 }
 
-type schedulerDriverAdapter struct {
-	devices.DeviceDriver
+// usageAlertingStorage checks usage alerts after usage is written outside sessions
+// (imported or polled usage), so parents are alerted without waiting for a session
+type usageAlertingStorage struct {
+	appStorage
+	alerts *core.UsageAlertService
+	logger *slog.Logger
+}
+
+func (s *usageAlertingStorage) IncrementDailyUsageSummary(ctx context.Context, childID string, date time.Time, minutes int) error {
+	if err := s.appStorage.IncrementDailyUsageSummary(ctx, childID, date, minutes); err != nil {
+		return err
+	}
+	if _, err := s.alerts.Check(ctx, childID); err != nil {
+		s.logger.Error("Failed to check usage alerts", "child_id", childID, "error", err)
+	}
+	return nil
+}
+
+// serverProtocols enables HTTP/2 next to HTTP/1.1: over TLS when a certificate is configured,
+// and unencrypted (prior knowledge, e.g. from a reverse proxy) otherwise
+func serverProtocols() *http.Protocols {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(true)
+	protocols.SetUnencryptedHTTP2(true)
+	return protocols
+}
+
+// responseCacheTTL converts the configured cache lifetime (0 for the default, negative to disable caching)
+func responseCacheTTL(seconds int) time.Duration {
+	switch {
+	case seconds == 0:
+		return middleware.DefaultCacheTTL
+	case seconds < 0:
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// corsConfig converts the configured CORS origins (nil keeps the router's default of any origin)
+func corsConfig(cfg *config.CORSConfig) *middleware.CORSConfig {
+	if cfg == nil {
+		return nil
+	}
+	return &middleware.CORSConfig{
+		AllowedOrigins:   cfg.AllowedOrigins,
+		AllowCredentials: cfg.AllowCredentials,
+	}
+}
+
+// childCookieConfig converts the configured child session cookie attributes
+func childCookieConfig(cfg *config.ChildCookieConfig) handlers.ChildCookieConfig {
+	if cfg == nil {
+		return handlers.ChildCookieConfig{}
+	}
+
+	cookie := handlers.ChildCookieConfig{Secure: cfg.Secure, Domain: cfg.Domain}
+	switch strings.ToLower(cfg.SameSite) {
+	case "lax":
+		cookie.SameSite = http.SameSiteLaxMode
+	case "strict":
+		cookie.SameSite = http.SameSiteStrictMode
+	case "none":
+		cookie.SameSite = http.SameSiteNoneMode
+	}
+	return cookie
+}
+
+// metricsHandler serves the registry's metrics; nil (no /metrics route) when metrics are disabled
+func metricsHandler(registry *metrics.Registry) http.Handler {
+	if registry == nil {
+		return nil
+	}
+	return registry.Handler()
+}
+
+// driversHealthCheck combines the health of all registered drivers into a single readiness check
+func driversHealthCheck(registry *drivers.Registry) handlers.HealthCheck {
+	return func(ctx context.Context) error {
+		var errs []error
+		for name, err := range registry.CheckHealth(ctx) {
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			}
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// runWatchdog pings the systemd watchdog every interval while check succeeds
+// Skipping pings on failure lets systemd restart a process that is alive but stuck
+func runWatchdog(interval time.Duration, stop <-chan struct{}, check func(ctx context.Context) error, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), interval)
+			err := check(ctx)
+			cancel()
+			if err != nil {
+				logger.Warn("Health check failed, skipping watchdog ping", "error", err)
+				continue
+			}
+			if _, err := systemd.Notify(systemd.StateWatchdog); err != nil {
+				logger.Warn("Failed to ping systemd watchdog", "error", err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+// runWhileLeader runs fn in the background until ctx is done
+// With leader election, fn only runs while this instance leads, so instances sharing a
+// database don't poll or lock the same devices twice.
+func runWhileLeader(ctx context.Context, election *core.LeaderElection, fn func(ctx context.Context)) {
+	if election == nil {
+		go fn(ctx)
+		return
+	}
+	go election.RunWhileLeader(ctx, fn)
 }
 
 // parseTimeOfDay parses a time string in HH:MM format and returns hour and minute
@@ -102,45 +347,98 @@ func parseTimeOfDay(timeStr string) (hour, minute int, err error) {
 	return hour, minute, nil
 }
 
+// overrideFlags collects repeated -set path=value flags
+type overrideFlags []string
+
+func (o *overrideFlags) String() string {
+	return strings.Join(*o, ",")
+}
+
+func (o *overrideFlags) Set(value string) error {
+	*o = append(*o, value)
+	return nil
+}
+
 func main() {
+	// Subcommands (metron doctor ...) are handled before the server flags
+	if len(os.Args) > 1 && os.Args[1] == "doctor" {
+		os.Exit(runDoctorCommand(os.Args[2:], os.Stdout))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "simulate" {
+		os.Exit(runSimulateCommand(os.Args[2:], os.Stdout))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "tv-pair" {
+		os.Exit(runTVPairCommand(os.Args[2:], os.Stdout))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "agent-release" {
+		os.Exit(runAgentReleaseCommand(os.Args[2:], os.Stdout))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		os.Exit(runExportCommand(os.Args[2:], os.Stdout))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		os.Exit(runImportCommand(os.Args[2:], os.Stdout))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "init" {
+		os.Exit(runInitCommand(os.Args[2:], os.Stdin, os.Stdout))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		os.Exit(runConfigCommand(os.Args[2:], os.Stdout))
+	}
+
 	// Parse command-line flags
 	configPath := flag.String("config", defaultConfigPath, "Path to configuration file")
-	useEnv := flag.Bool("env", false, "Load configuration from environment variables")
+	useEnv := flag.Bool("env", false, "Load configuration from environment variables only (no config file)")
+	var overrides overrideFlags
+	flag.Var(&overrides, "set", "Override a config option, e.g. -set server.port=9090 (repeatable, see metron config docs)")
 	logFormat := flag.String("log-format", "json", "Log format: json or text")
 	logLevel := flag.String("log-level", "info", "Log level: debug, info, warn, error")
+	pidFile := flag.String("pid-file", "", "Write process ID to this file (removed on exit)")
+	storageBackend := flag.String("storage", storageSQLite, "Storage backend: sqlite or memory (memory loses all data on exit)")
 	flag.Parse()
 
-	// Parse log level and create logger (writes to stdout)
+	// Parse log level and create logger (writes to stdout, recent lines are kept for GET /api/v1/admin/logs)
 	level := logging.ParseLevel(*logLevel)
+	logTail := logging.NewTail(logging.DefaultTailLines)
 	logger := logging.NewLogger(logging.LoggerConfig{
 		Format: *logFormat,
 		Level:  level,
+		Tail:   logTail,
 	})
 	slog.SetDefault(logger)
 
 	// Create main component logger
 	mainLogger := logger.With("component", "main")
 
-	if err := run(*configPath, *useEnv, logger); err != nil {
+	if err := run(*configPath, *useEnv, overrides, *pidFile, *storageBackend, logger, logTail); err != nil {
 		mainLogger.Error("Application failed", "error", err)
 		os.Exit(1)
 	}
 }
 
-func run(configPath string, useEnv bool, logger *slog.Logger) error {
+func run(configPath string, useEnv bool, overrides []string, pidFile string, storageBackend string, logger *slog.Logger, logTail *logging.Tail) error {
 	mainLogger := logger.With("component", "main")
 
-	// Load configuration
-	mainLogger.Info("Loading configuration", "use_env", useEnv, "config_path", configPath)
-	var cfg *config.Config
-	var err error
+	// Write PID file for daemon supervisors that track the process by file
+	if pidFile != "" {
+		if err := systemd.WritePIDFile(pidFile); err != nil {
+			return err
+		}
+		defer func() {
+			if err := systemd.RemovePIDFile(pidFile); err != nil {
+				mainLogger.Error("Failed to remove PID file", "path", pidFile, "error", err)
+			}
+		}()
+		mainLogger.Info("PID file written", "path", pidFile, "pid", os.Getpid())
+	}
 
+	// Load configuration: the file (or only the environment with -env), then environment
+	// variables that are set, then -set overrides
+	mainLogger.Info("Loading configuration", "use_env", useEnv, "config_path", configPath, "overrides", len(overrides))
 	if useEnv {
-		cfg, err = config.LoadFromEnv()
-	} else {
-		cfg, err = config.Load(configPath)
+		configPath = ""
 	}
-
+	cfg, err := config.LoadLayered(configPath, overrides)
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
@@ -153,16 +451,24 @@ func run(configPath string, useEnv bool, logger *slog.Logger) error {
 	mainLogger.Info("Application timezone configured", "timezone", cfg.Timezone)
 
 	// Initialize database
-	mainLogger.Info("Initializing database", "path", cfg.Database.Path)
-	db, err := sqlite.New(cfg.Database.Path, timezone)
+	db, err := openStorage(storageBackend, cfg.Database.Path, cfg.Security.CredentialsKey, timezone, mainLogger)
 	if err != nil {
-		return fmt.Errorf("failed to initialize database: %w", err)
+		return err
 	}
 	defer func() {
 		if err := db.Close(); err != nil {
 			mainLogger.Error("Failed to close database", "error", err)
 		}
 	}()
+	reader, closeReader, err := openReader(storageBackend, cfg.Database, db, timezone, mainLogger)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := closeReader(); err != nil {
+			mainLogger.Error("Failed to close read-only database", "error", err)
+		}
+	}()
 
 	// Initialize device registry first (needed by drivers)
 	mainLogger.Info("Initializing device registry")
@@ -172,67 +478,44 @@ func run(configPath string, useEnv bool, logger *slog.Logger) error {
 	mainLogger.Info("Initializing device driver registry")
 	driverRegistry := drivers.NewRegistry()
 
-	// Register Aqara driver
-	mainLogger.Info("Registering Aqara Cloud driver",
-		"base_url", cfg.Aqara.BaseURL,
-		"pin_scene", cfg.Aqara.Scenes.TVPINEntry,
-		"warn_scene", cfg.Aqara.Scenes.TVWarning,
-		"off_scene", cfg.Aqara.Scenes.TVPowerOff)
-
-	aqaraConfig := aqara.Config{
-		AppID:       cfg.Aqara.AppID,
-		AppKey:      cfg.Aqara.AppKey,
-		KeyID:       cfg.Aqara.KeyID,
-		BaseURL:     cfg.Aqara.BaseURL,
-		PINSceneID:  cfg.Aqara.Scenes.TVPINEntry,
-		WarnSceneID: cfg.Aqara.Scenes.TVWarning,
-		OffSceneID:  cfg.Aqara.Scenes.TVPowerOff,
-	}
-	aqaraLogger := logger.With("component", "driver.aqara")
-	aqaraDriver := aqara.NewDriver(aqaraConfig, db, aqaraLogger)
-	if err := driverRegistry.Register(aqaraDriver); err != nil {
-		return fmt.Errorf("failed to register aqara driver: %w", err)
+	// Per-driver call timeouts (driver_timeouts); plugins default to their manifest's timeout
+	driverTimeouts := make(map[string]time.Duration)
+	for name := range cfg.DriverTimeouts {
+		if name != "default" {
+			driverTimeouts[name] = cfg.DriverTimeout(name)
+		}
 	}
 
-	// Register Kidslox driver if configured
-	if cfg.Kidslox != nil {
-		mainLogger.Info("Registering Kidslox driver")
-		kidsloxConfig := kidslox.Config{
-			BaseURL:   cfg.Kidslox.BaseURL,
-			APIKey:    cfg.Kidslox.APIKey,
-			AccountID: cfg.Kidslox.AccountID,
-			DeviceID:  cfg.Kidslox.DeviceID,
-			ProfileID: cfg.Kidslox.ProfileID,
-		}
-		kidsloxLogger := logger.With("component", "driver.kidslox")
-		kidsloxDriver := kidslox.NewDriver(kidsloxConfig, deviceRegistry, kidsloxLogger)
-		if err := driverRegistry.Register(kidsloxDriver); err != nil {
-			return fmt.Errorf("failed to register kidslox driver: %w", err)
-		}
+	// Register the built-in drivers enabled by the configuration
+	builtin, err := registerBuiltinDrivers(cfg, db, db, deviceRegistry, driverRegistry, logger)
+	if err != nil {
+		return err
 	}
+	familyLinkClient := builtin.familyLinkClient
+	smartTVDriver := builtin.smartTV
 
-	// Register notify driver if configured (for manual-enforcement devices like Family Link)
-	if cfg.Notify != nil {
-		mainLogger.Info("Registering notify driver")
-		notifyConfig := notify.Config{
-			TelegramToken: cfg.Notify.TelegramToken,
-			ChatIDs:       cfg.Notify.ChatIDs,
+	// Register driver plugins (out-of-tree drivers described in the drivers directory)
+	if cfg.DriversDir != "" {
+		manifests, err := plugin.LoadManifests(cfg.DriversDir)
+		if err != nil {
+			return fmt.Errorf("failed to load driver plugins: %w", err)
 		}
-		notifyLogger := logger.With("component", "driver.notify")
-		notifyDriver := notify.NewDriver(notifyConfig, db, deviceRegistry, notifyLogger)
-		if err := driverRegistry.Register(notifyDriver); err != nil {
-			return fmt.Errorf("failed to register notify driver: %w", err)
+		for _, manifest := range manifests {
+			mainLogger.Info("Registering driver plugin", "name", manifest.Name, "command", manifest.Command)
+			if _, ok := driverTimeouts[manifest.Name]; !ok {
+				driverTimeouts[manifest.Name] = manifest.Timeout()
+			}
+			pluginDriver := plugin.NewDriver(manifest, deviceRegistry, logger.With("component", "driver."+manifest.Name))
+			if err := pluginDriver.Start(context.Background()); err != nil {
+				return fmt.Errorf("failed to start driver plugin %s: %w", manifest.Name, err)
+			}
+			defer pluginDriver.Close()
+			if err := driverRegistry.Register(pluginDriver); err != nil {
+				return fmt.Errorf("failed to register driver plugin %s: %w", manifest.Name, err)
+			}
 		}
 	}
 
-	// Register passive driver (for agent-controlled devices like Windows PCs)
-	mainLogger.Info("Registering passive driver for agent-controlled devices")
-	passiveLogger := logger.With("component", "driver.passive")
-	passiveDriver := passive.NewDriver(passiveLogger)
-	if err := driverRegistry.Register(passiveDriver); err != nil {
-		return fmt.Errorf("failed to register passive driver: %w", err)
-	}
-
 	// Register devices from configuration
 	mainLogger.Info("Registering devices", "count", len(cfg.Devices))
 	for _, deviceCfg := range cfg.Devices {
@@ -241,9 +524,14 @@ func run(configPath string, useEnv bool, logger *slog.Logger) error {
 			Name:       deviceCfg.Name,
 			Type:       deviceCfg.Type,
 			Emoji:      deviceCfg.Emoji,
+			Timezone:   deviceCfg.Timezone,
 			Driver:     deviceCfg.Driver,
 			Parameters: deviceCfg.Parameters,
 		}
+		// Catch missing or invalid parameters now instead of at the device's first session
+		if err := driverRegistry.ValidateDevice(device); err != nil {
+			return fmt.Errorf("invalid device configuration: %w", err)
+		}
 		if err := deviceRegistry.Register(device); err != nil {
 			mainLogger.Error("Failed to register device",
 				"device_id", deviceCfg.ID,
@@ -263,9 +551,18 @@ func run(configPath string, useEnv bool, logger *slog.Logger) error {
 	schedulerLogger := logger.With("component", "scheduler")
 	apiLogger := logger.With("component", "api")
 
+	// Record storage call counts and latencies of the session manager, scheduler and API
+	var coreStorage storage.Storage = db
+	var metricsRegistry *metrics.Registry
+	if cfg.Server.Metrics {
+		metricsRegistry = metrics.NewRegistry()
+		coreStorage = metrics.NewStorageMetrics(db, metricsRegistry)
+		mainLogger.Info("Serving Prometheus metrics", "path", "/metrics")
+	}
+
 	// Initialize time calculation service
 	mainLogger.Info("Initializing time calculation service")
-	calculator := core.NewTimeCalculationService(db, timezone)
+	calculator := core.NewTimeCalculationService(coreStorage, timezone)
 
 	// Initialize downtime service
 	var downtimeService *core.DowntimeService
@@ -352,30 +649,406 @@ func run(configPath string, useEnv bool, logger *slog.Logger) error {
 
 	// Initialize session manager
 	mainLogger.Info("Initializing session manager")
-	baseManager := core.NewSessionManager(db, &coreDeviceRegistry{deviceRegistry}, &coreDriverRegistry{driverRegistry}, calculator, downtimeService, timezone, managerLogger)
+	baseManager := core.NewSessionManager(coreStorage, &coreDeviceRegistry{deviceRegistry}, &coreDriverRegistry{driverRegistry}, calculator, downtimeService, timezone, managerLogger)
+	timeouts := core.NewDriverTimeouts(cfg.DriverTimeout("default"), driverTimeouts)
+	baseManager.SetDriverTimeouts(timeouts)
+	baseManager.SetInitiatorLimits(cfg.InitiatorLimits)
+	if len(cfg.SessionPolicies) > 0 {
+		policies := make([]core.SessionPolicy, len(cfg.SessionPolicies))
+		for i, policy := range cfg.SessionPolicies {
+			policies[i] = core.SessionPolicy{
+				Name:              policy.Name,
+				ChildIDs:          policy.ChildIDs,
+				DeviceIDs:         policy.DeviceIDs,
+				MaxSessionMinutes: policy.MaxSessionMinutes,
+				MinGapMinutes:     policy.MinGapMinutes,
+				MaxSessionsPerDay: policy.MaxSessionsPerDay,
+			}
+		}
+		baseManager.SetPolicies(policies)
+		mainLogger.Info("Session policies enabled", "policies", len(policies))
+	}
+
+	// Daily budget shared by all children (optional)
+	var familyBudgetService *core.FamilyBudgetService
+	if cfg.FamilyBudget != nil {
+		familyBudgetService = core.NewFamilyBudgetService(coreStorage, core.FamilyBudget{
+			Minutes:     cfg.FamilyBudget.Minutes,
+			DeviceIDs:   cfg.FamilyBudget.DeviceIDs,
+			DeviceTypes: cfg.FamilyBudget.DeviceTypes,
+		}, timezone)
+		baseManager.SetFamilyBudget(familyBudgetService)
+		mainLogger.Info("Family budget enabled", "minutes", cfg.FamilyBudget.Minutes)
+	}
+
+	// Activities whose sessions are not charged against daily time (optional)
+	if len(cfg.ExemptActivities) > 0 {
+		activities := make([]core.ExemptActivity, len(cfg.ExemptActivities))
+		for i, activity := range cfg.ExemptActivities {
+			activities[i] = core.ExemptActivity{
+				Name:         activity.Name,
+				DeviceIDs:    activity.DeviceIDs,
+				DailyMinutes: activity.DailyMinutes,
+			}
+		}
+		baseManager.SetExemptActivities(activities)
+		mainLogger.Info("Exempt activities enabled", "activities", len(activities))
+	}
+
+	// What starting a session on a device already in use does (default: allow it)
+	if cfg.SessionConflicts != "" && cfg.SessionConflicts != core.ConflictAllow {
+		baseManager.SetConflictPolicy(cfg.SessionConflicts, nil)
+		mainLogger.Info("Session conflict policy enabled", "policy", cfg.SessionConflicts)
+	}
+	if movieTimeService != nil {
+		movieTimeService.SetDriverTimeouts(timeouts)
+	}
+
+	// Record every driver call for GET /api/v1/admin/driver-calls
+	driverCallLog := core.NewDriverCallLog(db, cfg.DriverCallHistory, logger.With("component", "driver-calls"))
+	timeouts.SetCallLog(driverCallLog)
+
+	// Make driver calls in the background so slow cloud APIs don't stall API requests (optional)
+	var driverQueue *core.DriverQueue
+	if cfg.DriverQueue != nil {
+		driverQueue = core.NewDriverQueue(db, &coreDeviceRegistry{deviceRegistry}, &coreDriverRegistry{driverRegistry}, cfg.DriverQueue.Workers, cfg.DriverQueue.MaxAttempts, logger.With("component", "driver-queue"))
+		driverQueue.SetTimeouts(timeouts)
+		baseManager.SetDriverQueue(driverQueue)
+		if err := driverQueue.Start(context.Background()); err != nil {
+			return fmt.Errorf("failed to start driver queue: %w", err)
+		}
+		mainLogger.Info("Driver queue enabled", "workers", driverQueue.Workers(), "max_attempts", driverQueue.MaxAttempts())
+	}
 
 	// Wrap session manager with logging decorator
 	sessionManager := logging.NewSessionManagerLogger(baseManager, logger)
 
+	// Initialize lockdown service (panic button); it stops sessions through the decorated manager
+	lockdownService := core.NewLockdownService(db, sessionManager, logger.With("component", "lockdown"))
+	baseManager.SetLockdown(lockdownService)
+	if movieTimeService != nil {
+		movieTimeService.SetLockdown(lockdownService)
+	}
+
+	// Initialize tracking pause service (vacation mode)
+	trackingPauseService := core.NewTrackingPauseService(db, logger.With("component", "tracking-pause"))
+	baseManager.SetTrackingPause(trackingPauseService)
+
+	// Initialize downtime overrides (downtime lifted for a session or the rest of the day, re-armed when they end)
+	downtimeOverrideService := core.NewDowntimeOverrideService(db, timezone, logger.With("component", "downtime-overrides"))
+	baseManager.SetDowntimeOverrides(downtimeOverrideService)
+
+	// Initialize agent token service (server-issued tokens for device agents)
+	agentTokenService := core.NewAgentTokenService(db, logger.With("component", "agent-tokens"))
+
+	// Initialize heartbeat service (device last-seen tracking from agents and polling drivers)
+	heartbeatService := core.NewHeartbeatService(db, logger.With("component", "heartbeat"))
+	if names := driverRegistry.SetHeartbeatRecorder(heartbeatService); len(names) > 0 {
+		mainLogger.Info("Drivers reporting device heartbeats", "drivers", names)
+	}
+
+	// Initialize tamper service (clock changes, kill attempts and safe-mode boots reported by agents)
+	tamperService := core.NewTamperService(db, logger.With("component", "tamper"))
+
+	// Initialize trends service (rolling averages for reports and the bot's weekly digest)
+	trendsService := core.NewTrendsService(reader, calculator)
+
+	// Initialize audit log and limit schedule (limit changes from a given day, with history)
+	auditService := core.NewAuditService(db, logger.With("component", "audit"))
+	limitScheduleService := core.NewLimitScheduleService(db, calculator, auditService, logger.With("component", "limit-schedule"))
+	baseManager.SetAudit(auditService) // Parent overrides of downtime and limits
+
+	// Initialize child activity feeds (what happened to each child's time, for the child web app)
+	childActivityService := core.NewChildActivityService(db, logger.With("component", "child-activity"))
+	baseManager.SetActivity(childActivityService) // Also records the scheduler's breaks and ends through the shared state machine
+	if movieTimeService != nil {
+		movieTimeService.SetActivity(childActivityService)
+	}
+
+	// Initialize age-based limit profiles (optional; proposed to parents on birthdays)
+	var limitProfileService *core.LimitProfileService
+	if len(cfg.LimitProfiles) > 0 {
+		profiles := make([]core.LimitProfile, len(cfg.LimitProfiles))
+		for i, profile := range cfg.LimitProfiles {
+			profiles[i] = core.LimitProfile{
+				Name:         profile.Name,
+				MinAge:       profile.MinAge,
+				MaxAge:       profile.MaxAge,
+				WeekdayLimit: profile.WeekdayLimit,
+				WeekendLimit: profile.WeekendLimit,
+			}
+		}
+		limitProfileService = core.NewLimitProfileService(db, profiles, calculator, limitScheduleService, auditService, logger.With("component", "limit-profiles"))
+		mainLogger.Info("Limit profiles enabled", "profiles", len(profiles))
+	}
+
+	// Initialize usage alerts (children reaching a share of their daily time, alerted once a day per threshold)
+	var usageAlertThresholds []int
+	if cfg.UsageAlerts != nil {
+		usageAlertThresholds = cfg.UsageAlerts.Thresholds
+	}
+	usageAlertService := core.NewUsageAlertService(db, calculator, usageAlertThresholds, logger.With("component", "usage-alerts"))
+	usageAlertService.SetTrackingPause(trackingPauseService)
+	if cfg.UsageAlerts != nil && cfg.UsageAlerts.WebhookURL != "" {
+		usageAlertService.AddNotifier(webhook.NewNotifier(cfg.UsageAlerts.WebhookURL, cfg.UsageAlerts.WebhookSecret))
+		mainLogger.Info("Usage alerts are sent to a webhook")
+	}
+	mainLogger.Info("Usage alerts enabled", "thresholds", usageAlertService.Thresholds())
+
+	// Initialize Web Push (optional; session warnings and ends in subscribed browsers)
+	var pushService *core.PushService
+	if cfg.WebPush != nil {
+		keys, generated, err := webpush.LoadKeys(context.Background(), db, webpush.Keys{
+			PublicKey:  cfg.WebPush.VAPIDPublicKey,
+			PrivateKey: cfg.WebPush.VAPIDPrivateKey,
+		})
+		if err != nil {
+			mainLogger.Error("Failed to load Web Push keys", "error", err)
+			os.Exit(1)
+		}
+		if generated {
+			mainLogger.Info("Generated Web Push keys and stored them in the database")
+		}
+		sender, err := webpush.NewSender(keys, cfg.WebPush.Subject)
+		if err != nil {
+			mainLogger.Error("Failed to create Web Push sender", "error", err)
+			os.Exit(1)
+		}
+		pushService = core.NewPushService(db, sender, keys.PublicKey, logger.With("component", "push"))
+		baseManager.SessionStates().AddHook(pushService.SessionHook())
+		usageAlertService.AddNotifier(pushService)
+		mainLogger.Info("Web Push notifications enabled")
+	}
+	usageStorage := &usageAlertingStorage{appStorage: db, alerts: usageAlertService, logger: logger.With("component", "usage-alerts")}
+
+	// Initialize day rollover (closes out each child's day after midnight and creates the new day's allocation)
+	dayRolloverService := core.NewDayRolloverService(db, calculator, logger.With("component", "day-rollover"))
+
+	// Materialize allocations every day, including days missed while the server was down
+	allocationService := core.NewAllocationService(db, calculator, logger.With("component", "allocations"))
+
+	// Corrections of charged usage share the manager's locks, so refunds are checked one at a time
+	usageCorrectionService := core.NewUsageCorrectionService(db, calculator, dayRolloverService, auditService, logger.With("component", "usage-corrections"))
+	usageCorrectionService.SetSessionLocks(baseManager.SessionLocks())
+
 	// Start scheduler
 	mainLogger.Info("Starting session scheduler", "interval", "1m")
-	sched := scheduler.NewScheduler(db, &schedulerDeviceRegistry{deviceRegistry}, &schedulerDriverRegistry{driverRegistry}, downtimeService, 1*time.Minute, timezone, schedulerLogger)
+	sched := scheduler.NewScheduler(coreStorage, &schedulerDeviceRegistry{deviceRegistry}, &schedulerDriverRegistry{driverRegistry}, calculator, downtimeService, cfg.SchedulerInterval(), timezone, schedulerLogger)
+	sched.SetJitter(cfg.SchedulerJitter())
+	deviceTicks := make(map[string]time.Duration)
+	for _, device := range cfg.Devices {
+		if device.TickIntervalSeconds > 0 {
+			deviceTicks[device.ID] = time.Duration(device.TickIntervalSeconds) * time.Second
+		}
+	}
+	sched.SetDeviceTicks(deviceTicks)
+
+	// With several instances on one database, only the elected leader runs scheduler actions
+	// and the background jobs below that poll or lock devices
+	var leaderElection *core.LeaderElection
+	electionCtx, stopElection := context.WithCancel(context.Background())
+	defer stopElection()
+	if cfg.LeaderElection != nil {
+		leaderElection = core.NewLeaderElection(db, "scheduler", time.Duration(cfg.LeaderElection.LeaseSeconds)*time.Second, logger.With("component", "leader-election"))
+		sched.SetLeader(leaderElection)
+		go leaderElection.Run(electionCtx)
+		mainLogger.Info("Leader election enabled", "owner", leaderElection.Owner())
+	}
+	sched.SetTrackingPause(trackingPauseService)
+	sched.SetDowntimeOverrides(downtimeOverrideService)
+	sched.SetSessionLocks(baseManager.SessionLocks())
+	sched.SetDriverTimeouts(timeouts)
+	sched.SetSessionStates(baseManager.SessionStates())
+	sched.SetLimitSchedule(limitScheduleService)
+	if limitProfileService != nil {
+		sched.SetLimitProfiles(limitProfileService)
+	}
+	sched.SetUsageAlerts(usageAlertService)
+	sched.SetDayRollover(dayRolloverService)
+	sched.SetAllocations(allocationService)
+	sched.SetQueuedStarts(baseManager)
+	if pushService != nil {
+		sched.SetPush(pushService)
+	}
 	go sched.Start()
 
+	// Import Family Link device usage outside sessions into daily summaries
+	if familyLinkClient != nil && cfg.FamilyLink.ImportIntervalMinutes > 0 {
+		importCtx, stopImport := context.WithCancel(context.Background())
+		defer stopImport()
+
+		importer := familylink.NewUsageImporter(familyLinkClient, usageStorage, deviceRegistry, timezone, logger.With("component", "driver.familylink"))
+		runWhileLeader(importCtx, leaderElection, func(ctx context.Context) {
+			importer.Run(ctx, time.Duration(cfg.FamilyLink.ImportIntervalMinutes)*time.Minute)
+		})
+	}
+
+	// Charge Steam play outside sessions and optionally start sessions for it
+	if cfg.Steam != nil {
+		steamCtx, stopSteam := context.WithCancel(context.Background())
+		defer stopSteam()
+
+		accounts := make([]steam.Account, 0, len(cfg.Steam.Accounts))
+		for _, account := range cfg.Steam.Accounts {
+			accounts = append(accounts, steam.Account{
+				SteamID:          account.SteamID,
+				ChildID:          account.ChildID,
+				DeviceID:         account.DeviceID,
+				AutoStartMinutes: account.AutoStartMinutes,
+			})
+		}
+		poller := steam.NewPoller(steam.NewHTTPClient(cfg.Steam.APIKey), usageStorage, sessionManager, accounts, calculator, logger)
+		runWhileLeader(steamCtx, leaderElection, func(ctx context.Context) {
+			poller.Run(ctx, time.Duration(cfg.Steam.GetPollIntervalMinutes())*time.Minute)
+		})
+	}
+
+	// Turn locked TVs off again when they are switched on outside a session
+	if smartTVDriver != nil {
+		lockCtx, stopLock := context.WithCancel(context.Background())
+		defer stopLock()
+
+		runWhileLeader(lockCtx, leaderElection, func(ctx context.Context) {
+			smartTVDriver.RunLock(ctx, time.Duration(cfg.SmartTV.GetLockCheckSeconds())*time.Second)
+		})
+	}
+
+	// Expose devices to Apple Home as a HomeKit bridge
+	if cfg.HomeKit != nil {
+		homekitCtx, stopHomeKit := context.WithCancel(context.Background())
+		defer stopHomeKit()
+
+		startSettings := make(map[string]config.HomeKitDeviceConfig, len(cfg.HomeKit.Devices))
+		for _, device := range cfg.HomeKit.Devices {
+			startSettings[device.DeviceID] = device
+		}
+		var homekitDevices []homekit.Device
+		for _, device := range deviceRegistry.List() {
+			settings := startSettings[device.ID]
+			homekitDevices = append(homekitDevices, homekit.Device{
+				ID:       device.ID,
+				Name:     device.Name,
+				Type:     device.Type,
+				ChildIDs: settings.ChildIDs,
+				Minutes:  settings.Minutes,
+			})
+		}
+
+		bridge, err := homekit.NewBridge(homekit.Config{
+			Name:      cfg.HomeKit.Name,
+			SetupCode: cfg.HomeKit.SetupCode,
+			Port:      cfg.HomeKit.Port,
+			Devices:   homekitDevices,
+		}, db, sessionManager, logger)
+		if err != nil {
+			return fmt.Errorf("failed to create homekit bridge: %w", err)
+		}
+		runWhileLeader(homekitCtx, leaderElection, func(ctx context.Context) {
+			if err := bridge.Run(ctx); err != nil {
+				mainLogger.Error("HomeKit bridge stopped", "error", err)
+			}
+		})
+	}
+
+	// Signed agent releases served to agents (published with "metron agent-release")
+	var agentUpdateDir string
+	if cfg.AgentUpdate != nil {
+		agentUpdateDir = cfg.AgentUpdate.Dir
+		mainLogger.Info("Serving agent updates", "dir", agentUpdateDir)
+	}
+
+	// What devices play, for extensions until the current episode ends
+	mediaService := core.NewMediaService(sessionManager, &coreDeviceRegistry{deviceRegistry}, &coreDriverRegistry{driverRegistry}, logger.With("component", "media"))
+
+	// Check Plex and Jellyfin playback against sessions and note what was watched
+	var mediaServers handlers.MediaServerWatcher
+	var mediaServerToken string
+	if cfg.MediaServers != nil {
+		profiles := make([]mediaserver.Profile, 0, len(cfg.MediaServers.Profiles))
+		for _, profile := range cfg.MediaServers.Profiles {
+			profiles = append(profiles, mediaserver.Profile{
+				Server:           profile.Server,
+				User:             profile.User,
+				ChildID:          profile.ChildID,
+				DeviceID:         profile.DeviceID,
+				AutoStartMinutes: profile.AutoStartMinutes,
+			})
+		}
+		watcher := mediaserver.NewWatcher(sessionManager, profiles, logger)
+		watcher.SetMedia(mediaService)
+		mediaServers = watcher
+		mediaServerToken = cfg.MediaServers.WebhookToken
+	}
+
+	// External agents reporting children's app usage
+	usageSources := make([]handlers.UsageSource, 0, len(cfg.UsageSources))
+	for _, source := range cfg.UsageSources {
+		usageSources = append(usageSources, handlers.UsageSource{
+			Name:     source.Name,
+			Token:    source.Token,
+			ChildIDs: source.ChildIDs,
+		})
+	}
+
 	// Initialize REST API with Gin
 	mainLogger.Info("Initializing REST API server")
+	legacySunset, _ := cfg.Server.LegacySunset() // Checked when the config was validated
 	router := api.NewRouter(api.RouterConfig{
-		Storage:             db,
+		Storage:             coreStorage,
+		Reader:              reader,
 		Manager:             sessionManager,
 		DriverRegistry:      driverRegistry,
 		DeviceRegistry:      deviceRegistry,
 		Downtime:            downtimeService,
 		MovieTime:           movieTimeService,
-		DowntimeSkipStorage: db, // SQLite storage also implements core.DowntimeSkipStorage
+		Lockdown:            lockdownService,
+		TrackingPause:       trackingPauseService,
+		DowntimeOverrides:   downtimeOverrideService,
+		UsageAlerts:         usageAlertService,
+		DayRollover:         dayRolloverService,
+		Allocations:         allocationService,
+		UsageCorrections:    usageCorrectionService,
+		DriverCalls:         driverCallLog,
+		Bundles:             bundle.NewService(db, bundle.NewConfig(cfg), timezone, logger.With("component", "bundle")),
+		Trends:              trendsService,
+		FamilyBudget:        familyBudgetService,
+		DeviceUsage:         core.NewDeviceUsageService(reader, timezone),
+		SessionQueue:        baseManager.SessionQueue(),
+		Media:               mediaService,
+		MediaServers:        mediaServers,
+		MediaServerToken:    mediaServerToken,
+		AppUsage:            core.NewAppUsageService(db, timezone, logger.With("component", "app-usage")),
+		UsageSources:        usageSources,
+		LimitSchedule:       limitScheduleService,
+		Audit:               auditService,
+		LimitProfiles:       limitProfileService,
+		AgentTokens:         agentTokenService,
+		ChildLogins:         core.NewChildLoginService(db, logger.With("component", "child-logins")),
+		ChildActivity:       childActivityService,
+		Push:                pushService,
+		Heartbeat:           heartbeatService,
+		Tamper:              tamperService,
+		DowntimeSkipStorage: db, // Storage backends also implement core.DowntimeSkipStorage
 		APIKey:              cfg.Security.APIKey,
+		OverrideKey:         cfg.Security.OverrideKey,
 		Logger:              apiLogger,
-		AqaraTokenStorage:   db,         // SQLite storage also implements aqara.AqaraTokenStorage
-		Devices:             cfg.Devices, // For agent auth (tokens in device parameters)
+		Credentials:         db,          // Storage backends also implement credentials.Store
+		Devices:             cfg.Devices, // For agent auth (tokens in device parameters and issued tokens' devices)
+		Scheduler:           sched,       // For GET /api/v1/admin/scheduler/preview
+		AgentUpdateDir:      agentUpdateDir,
+		CacheTTL:            responseCacheTTL(cfg.Server.CacheTTLSeconds),
+		MaxBodyBytes:        cfg.Server.MaxBodyBytes,
+		CORS:                corsConfig(cfg.Server.CORS),
+		ChildCookie:         childCookieConfig(cfg.Server.ChildCookie),
+		ChildAppURL:         cfg.Server.ChildAppURL,
+		LegacySunset:        legacySunset,
+		Metrics:             metricsHandler(metricsRegistry),
+		Logs:                logTail,
+		ReadinessChecks: map[string]handlers.HealthCheck{
+			"database":  db.Ping,
+			"drivers":   driversHealthCheck(driverRegistry),
+			"scheduler": sched.CheckHealth,
+		},
 	})
 
 	server := &http.Server{
@@ -384,18 +1057,56 @@ func run(configPath string, useEnv bool, logger *slog.Logger) error {
 		ReadTimeout:  15 * time.Second,
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
+		Protocols:    serverProtocols(),
+	}
+
+	// Bind the listener before reporting readiness so systemd only sees us ready once we accept connections
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", server.Addr, err)
 	}
 
 	// Start server in a goroutine
 	serverErrors := make(chan error, 1)
 	go func() {
+		if cfg.Server.TLSCertFile != "" {
+			mainLogger.Info("HTTPS server starting",
+				"host", cfg.Server.Host,
+				"port", cfg.Server.Port,
+				"endpoint", fmt.Sprintf("https://%s:%d", cfg.Server.Host, cfg.Server.Port))
+			serverErrors <- server.ServeTLS(listener, cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile)
+			return
+		}
 		mainLogger.Info("HTTP server starting",
 			"host", cfg.Server.Host,
 			"port", cfg.Server.Port,
 			"endpoint", fmt.Sprintf("http://%s:%d", cfg.Server.Host, cfg.Server.Port))
-		serverErrors <- server.ListenAndServe()
+		serverErrors <- server.Serve(listener)
 	}()
 
+	// Notify systemd (Type=notify) that migrations, drivers and the listener are ready
+	if sent, err := systemd.Notify(systemd.StateReady); err != nil {
+		mainLogger.Warn("Failed to send systemd readiness notification", "error", err)
+	} else if sent {
+		mainLogger.Info("Readiness reported to systemd")
+	}
+
+	// Feed the systemd watchdog only while the database and scheduler are healthy
+	watchdogStop := make(chan struct{})
+	defer close(watchdogStop)
+	if interval, ok := systemd.WatchdogInterval(); ok {
+		mainLogger.Info("Systemd watchdog enabled", "interval", interval)
+		go runWatchdog(interval/2, watchdogStop, func(ctx context.Context) error {
+			if err := db.Ping(ctx); err != nil {
+				return fmt.Errorf("database: %w", err)
+			}
+			if err := sched.CheckHealth(ctx); err != nil {
+				return fmt.Errorf("scheduler: %w", err)
+			}
+			return nil
+		}, mainLogger)
+	}
+
 	// Wait for interrupt signal or server error
 	shutdown := make(chan os.Signal, 1)
 	signal.Notify(shutdown, os.Interrupt, syscall.SIGTERM)
@@ -406,18 +1117,45 @@ func run(configPath string, useEnv bool, logger *slog.Logger) error {
 
 	case sig := <-shutdown:
 		mainLogger.Info("Shutdown signal received", "signal", sig.String())
+		systemd.Notify(systemd.StateStopping)
 
-		// Stop scheduler
-		mainLogger.Info("Stopping scheduler")
-		sched.Stop()
-
-		// Shutdown HTTP server
+		// Shutdown HTTP server first so no new sessions can be started
+		// while the scheduler finishes its in-flight tick
 		mainLogger.Info("Shutting down HTTP server", "timeout", shutdownTimeout)
 		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
 		defer shutdownCancel()
 
-		if err := server.Shutdown(shutdownCtx); err != nil {
-			return fmt.Errorf("server shutdown error: %w", err)
+		serverErr := server.Shutdown(shutdownCtx)
+		if serverErr != nil {
+			mainLogger.Error("HTTP server shutdown error", "error", serverErr)
+		}
+
+		// Stop scheduler, waiting for any in-flight driver calls to complete
+		mainLogger.Info("Stopping scheduler", "timeout", schedulerStopTimeout)
+		stopCtx, stopCancel := context.WithTimeout(context.Background(), schedulerStopTimeout)
+		defer stopCancel()
+
+		if err := sched.Stop(stopCtx); err != nil {
+			mainLogger.Error("Scheduler did not stop cleanly", "error", err)
+		}
+
+		// Hand leadership to another instance right away instead of after the lease expires
+		stopElection()
+		if leaderElection != nil {
+			if err := leaderElection.Resign(stopCtx); err != nil {
+				mainLogger.Error("Failed to resign leadership", "error", err)
+			}
+		}
+
+		// Queued driver calls not made yet are stored and made after the next start
+		if driverQueue != nil {
+			if err := driverQueue.Stop(stopCtx); err != nil {
+				mainLogger.Error("Driver queue did not stop cleanly", "error", err)
+			}
+		}
+
+		if serverErr != nil {
+			return fmt.Errorf("server shutdown error: %w", serverErr)
 		}
 
 		mainLogger.Info("Graceful shutdown complete")
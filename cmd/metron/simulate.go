@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+
+	"metron/internal/simulation"
+)
+
+// runSimulateCommand replays scenario files and reports mismatches
+// Usage: metron simulate [-v] [-log] scenario.json...
+func runSimulateCommand(args []string, out io.Writer) int {
+	fs := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	fs.SetOutput(out)
+	verbose := fs.Bool("v", false, "Print the driver calls of each scenario")
+	showLogs := fs.Bool("log", false, "Print manager and scheduler logs")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fmt.Fprintln(out, "Usage: metron simulate [-v] [-log] scenario.json...")
+		return 2
+	}
+
+	var logger *slog.Logger
+	if *showLogs {
+		logger = slog.New(slog.NewTextHandler(out, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	}
+
+	failed := 0
+	for _, path := range fs.Args() {
+		if !runScenario(path, logger, *verbose, out) {
+			failed++
+		}
+	}
+
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "Summary: %d passed, %d failed\n", fs.NArg()-failed, failed)
+	if failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runScenario runs one scenario file and prints its result
+func runScenario(path string, logger *slog.Logger, verbose bool, out io.Writer) bool {
+	scenario, err := simulation.LoadScenario(path)
+	if err != nil {
+		fmt.Fprintf(out, "[FAIL] %s: %v\n", path, err)
+		return false
+	}
+
+	result, err := simulation.Run(context.Background(), scenario, logger)
+	if err != nil {
+		fmt.Fprintf(out, "[FAIL] %s: %v\n", scenario.Name, err)
+		return false
+	}
+
+	status := "OK"
+	if !result.Passed() {
+		status = "FAIL"
+	}
+	fmt.Fprintf(out, "[%-4s] %s (%d steps, ends %s)\n", status, result.Scenario, result.Steps, result.End.Format("2006-01-02 15:04 MST"))
+
+	if verbose {
+		for _, event := range result.Events {
+			fmt.Fprintf(out, "       %s  %-8s %-12s minutes=%d\n", event.At.Format("15:04"), event.DeviceID, event.Call, event.Minutes)
+		}
+	}
+	for _, failure := range result.Failures {
+		fmt.Fprintf(out, "       step %d at %s: %s\n", failure.Step, failure.At.Format("15:04"), failure.Message)
+	}
+	return result.Passed()
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"time"
+
+	"metron/internal/drivers/smarttv"
+)
+
+// runTVPairCommand pairs with a smart TV and prints the key for the device parameters
+// Usage:
+//
+//	metron tv-pair -brand lg -host 192.168.1.50
+func runTVPairCommand(args []string, out io.Writer) int {
+	fs := flag.NewFlagSet("tv-pair", flag.ContinueOnError)
+	fs.SetOutput(out)
+	brand := fs.String("brand", "", "TV brand (samsung or lg)")
+	host := fs.String("host", "", "TV address on the local network")
+	port := fs.Int("port", 0, "TV port (default 8002 for Samsung, 3001 for LG)")
+	clientName := fs.String("name", smarttv.DefaultClientName, "Name shown on the TV prompt")
+	timeout := fs.Duration("timeout", time.Minute, "How long to wait for the prompt to be accepted")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	if *brand == "" || *host == "" {
+		fmt.Fprintln(out, "Usage: metron tv-pair -brand samsung|lg -host 192.168.1.50 [-port 3001]")
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	fmt.Fprintf(out, "Accept the connection request on the TV with the remote (waiting %s)...\n", *timeout)
+	key, err := smarttv.Pair(ctx, *brand, *host, *port, *clientName)
+	if err != nil {
+		fmt.Fprintf(out, "Pairing failed: %v\n", err)
+		return 1
+	}
+
+	param := "token"
+	if *brand == smarttv.BrandLG {
+		param = "client_key"
+	}
+	fmt.Fprintf(out, "Paired. Add to the device parameters:\n\"%s\": %q\n", param, key)
+	return 0
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"metron/pkg/client"
+)
+
+const (
+	childrenAddUsage  = "children add -name NAME -weekday MINUTES -weekend MINUTES [-emoji E] [-pin PIN] [-devices ID,...] [-timezone TZ] [-grace MINUTES] [-birthdate YYYY-MM-DD]"
+	rewardGrantUsage  = "reward grant CHILD MINUTES"
+	childrenListUsage = "children list"
+)
+
+// children runs "children list" and "children add"
+func (c *cli) children(ctx context.Context, args []string) int {
+	name, args := subcommand(args)
+	switch name {
+	case "list", "ls":
+		if len(args) != 0 {
+			return c.usageError(childrenListUsage)
+		}
+		return c.listChildren(ctx)
+	case "add", "create":
+		return c.addChild(ctx, args)
+	}
+	return c.usageError("children list|add")
+}
+
+func (c *cli) listChildren(ctx context.Context) int {
+	children, err := c.client.ListChildren(ctx)
+	if err != nil {
+		return c.fail("list children", err)
+	}
+
+	return c.print(children, func(w *tabwriter.Writer) {
+		fmt.Fprintln(w, "ID\tNAME\tWEEKDAY\tWEEKEND\tDOWNTIME\tDEVICES")
+		for _, child := range children {
+			devices := "all"
+			if len(child.AllowedDevices) > 0 {
+				devices = strings.Join(child.AllowedDevices, ",")
+			}
+			fmt.Fprintf(w, "%s\t%s %s\t%d\t%d\t%s\t%s\n",
+				child.ID, child.Emoji, child.Name, child.WeekdayLimit, child.WeekendLimit, onOff(child.DowntimeEnabled), devices)
+		}
+	})
+}
+
+func (c *cli) addChild(ctx context.Context, args []string) int {
+	fs := c.flagSet("children add", childrenAddUsage)
+	var req client.CreateChildRequest
+	fs.StringVar(&req.Name, "name", "", "Child's name (required)")
+	fs.IntVar(&req.WeekdayLimit, "weekday", 0, "Daily limit on weekdays in minutes (required)")
+	fs.IntVar(&req.WeekendLimit, "weekend", 0, "Daily limit on weekends in minutes (required)")
+	fs.StringVar(&req.Emoji, "emoji", "", "Emoji shown next to the name (random if empty)")
+	fs.StringVar(&req.PIN, "pin", "", "4-digit PIN for the child web app")
+	devices := fs.String("devices", "", "Comma-separated devices the child may use (default all)")
+	fs.StringVar(&req.Timezone, "timezone", "", "IANA timezone of the child (default the server's)")
+	fs.IntVar(&req.GraceMinutes, "grace", 0, "Minutes a session may run past the daily limit")
+	fs.StringVar(&req.Birthdate, "birthdate", "", "Date of birth (YYYY-MM-DD), for age-based limit profiles")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 || req.Name == "" || req.WeekdayLimit <= 0 || req.WeekendLimit <= 0 {
+		return c.usageError(childrenAddUsage)
+	}
+	if *devices != "" {
+		req.AllowedDevices = strings.Split(*devices, ",")
+	}
+
+	child, err := c.client.CreateChild(ctx, req)
+	if err != nil {
+		return c.fail("create child", err)
+	}
+
+	return c.print(child, func(w *tabwriter.Writer) {
+		fmt.Fprintf(w, "Created %s %s (%s)\n", child.Emoji, child.Name, child.ID)
+	})
+}
+
+// reward runs "reward grant"
+func (c *cli) reward(ctx context.Context, args []string) int {
+	name, args := subcommand(args)
+	if name != "grant" || len(args) != 2 {
+		return c.usageError(rewardGrantUsage)
+	}
+	minutes, err := strconv.Atoi(args[1])
+	if err != nil || minutes <= 0 {
+		return c.usageError(rewardGrantUsage)
+	}
+
+	reward, err := c.client.GrantReward(ctx, args[0], minutes)
+	if err != nil {
+		return c.fail("grant reward", err)
+	}
+
+	return c.print(reward, func(w *tabwriter.Writer) {
+		fmt.Fprintf(w, "Granted %d minutes to %s (%d of %d minutes left today)\n",
+			reward.MinutesGranted, args[0], reward.TodayRemaining, reward.TodayLimit)
+	})
+}
+
+func onOff(on bool) string {
+	if on {
+		return "on"
+	}
+	return "off"
+}
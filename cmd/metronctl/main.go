@@ -0,0 +1,186 @@
+// Command metronctl manages a Metron server from the terminal through its REST API.
+// It is meant for parents who prefer a shell to the Telegram bot, and for cron jobs.
+//
+// Usage:
+//
+//	metronctl [-url URL] [-key KEY] [-json] <command> [arguments]
+//
+// The server URL and API key can also be set with METRON_URL and METRON_API_KEY.
+// Exit status is 0 on success, 1 if the request failed and 2 for usage errors.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"metron/pkg/client"
+)
+
+const defaultURL = "http://localhost:8080"
+
+// requestTimeout bounds one command, including retries
+const requestTimeout = 30 * time.Second
+
+const usage = `Usage: metronctl [-url URL] [-key KEY] [-json] <command> [arguments]
+
+Commands:
+  children list                       List children and their limits
+  children add -name NAME -weekday MIN -weekend MIN [flags]
+                                      Create a child
+  session list [-all] [-child ID]     List active sessions (-all: also ended ones)
+  session start -device ID [-by NAME] MINUTES CHILD...
+                                      Start a session for one or more children
+  session extend SESSION MINUTES      Add minutes to a session
+  session stop SESSION                Stop a session
+  reward grant CHILD MINUTES          Grant reward minutes for today
+  device list                         List devices
+  report [today|trends]               Today's usage (default) or 7/30-day trends
+  seed -file fixtures.yaml [-replace] [-dry-run]
+                                      Create children, limits and sample history from fixtures
+
+Run "metronctl <command> -h" for the flags of a command.
+`
+
+// cli is the state shared by all commands
+type cli struct {
+	client *client.Client
+	out    io.Writer
+	errOut io.Writer
+	json   bool // Print API responses as JSON instead of tables
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, out, errOut io.Writer) int {
+	fs := flag.NewFlagSet("metronctl", flag.ContinueOnError)
+	fs.SetOutput(errOut)
+	fs.Usage = func() {
+		fmt.Fprint(errOut, usage)
+		fmt.Fprintln(errOut)
+		fmt.Fprintln(errOut, "Flags:")
+		fs.PrintDefaults()
+	}
+	baseURL := fs.String("url", envOr("METRON_URL", defaultURL), "Metron server URL (METRON_URL)")
+	apiKey := fs.String("key", os.Getenv("METRON_API_KEY"), "API key, security.api_key of the server (METRON_API_KEY)")
+	overrideKey := fs.String("override-key", os.Getenv("METRON_OVERRIDE_KEY"), "Key for parent overrides, if the server requires one (METRON_OVERRIDE_KEY)")
+	asJSON := fs.Bool("json", false, "Print responses as JSON")
+	timeout := fs.Duration("timeout", client.DefaultTimeout, "Timeout of one request attempt")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return 2
+	}
+	if *apiKey == "" {
+		fmt.Fprintln(errOut, "No API key: set -key or METRON_API_KEY")
+		return 2
+	}
+
+	metron := client.New(*baseURL, *apiKey)
+	metron.SetOverrideKey(*overrideKey)
+	metron.SetTimeout(*timeout)
+	c := &cli{client: metron, out: out, errOut: errOut, json: *asJSON}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	command, rest := fs.Arg(0), fs.Args()[1:]
+	switch command {
+	case "children", "child":
+		return c.children(ctx, rest)
+	case "session", "sessions":
+		return c.session(ctx, rest)
+	case "reward", "rewards":
+		return c.reward(ctx, rest)
+	case "device", "devices":
+		return c.device(ctx, rest)
+	case "report":
+		return c.report(ctx, rest)
+	case "seed":
+		return c.seed(ctx, rest)
+	case "help":
+		fs.Usage()
+		return 0
+	}
+	fmt.Fprintf(errOut, "Unknown command %q\n\n", command)
+	fs.Usage()
+	return 2
+}
+
+// subcommand splits "list", "add", ... from the arguments of a command
+func subcommand(args []string) (string, []string) {
+	if len(args) == 0 {
+		return "", nil
+	}
+	return args[0], args[1:]
+}
+
+// flagSet creates the flag set of a subcommand, which prints its usage line on -h
+func (c *cli) flagSet(name, usageLine string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(c.errOut)
+	fs.Usage = func() {
+		fmt.Fprintf(c.errOut, "Usage: metronctl %s\n", usageLine)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// usageError prints the usage line of a command and returns the usage exit status
+func (c *cli) usageError(usageLine string) int {
+	fmt.Fprintf(c.errOut, "Usage: metronctl %s\n", usageLine)
+	return 2
+}
+
+// fail prints a failed request and returns the failure exit status
+func (c *cli) fail(action string, err error) int {
+	var apiErr *client.Error
+	if errors.As(err, &apiErr) && apiErr.Code != "" {
+		fmt.Fprintf(c.errOut, "Failed to %s: %s (%s)\n", action, apiErr.Message, apiErr.Code)
+		return 1
+	}
+	fmt.Fprintf(c.errOut, "Failed to %s: %v\n", action, err)
+	return 1
+}
+
+// print writes v as indented JSON with -json, and calls table otherwise
+func (c *cli) print(v any, table func(w *tabwriter.Writer)) int {
+	if c.json {
+		encoder := json.NewEncoder(c.out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(v); err != nil {
+			fmt.Fprintf(c.errOut, "Failed to write output: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	w := tabwriter.NewWriter(c.out, 0, 0, 2, ' ', 0)
+	table(w)
+	if err := w.Flush(); err != nil {
+		fmt.Fprintf(c.errOut, "Failed to write output: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
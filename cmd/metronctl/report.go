@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"text/tabwriter"
+)
+
+const (
+	deviceListUsage = "device list"
+	reportUsage     = "report [today|trends]"
+)
+
+// device runs "device list"
+func (c *cli) device(ctx context.Context, args []string) int {
+	name, args := subcommand(args)
+	if (name != "list" && name != "ls") || len(args) != 0 {
+		return c.usageError(deviceListUsage)
+	}
+
+	devices, err := c.client.ListDevices(ctx)
+	if err != nil {
+		return c.fail("list devices", err)
+	}
+
+	return c.print(devices, func(w *tabwriter.Writer) {
+		fmt.Fprintln(w, "ID\tNAME\tTYPE\tLAST SEEN")
+		for _, device := range devices {
+			lastSeen := device.LastSeenAt
+			if lastSeen == "" {
+				lastSeen = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", device.ID, device.Name, device.Type, lastSeen)
+		}
+	})
+}
+
+// report runs "report today" (the default) and "report trends"
+func (c *cli) report(ctx context.Context, args []string) int {
+	name, args := subcommand(args)
+	if len(args) != 0 {
+		return c.usageError(reportUsage)
+	}
+	switch name {
+	case "", "today":
+		return c.todayReport(ctx)
+	case "trends":
+		return c.trendsReport(ctx)
+	}
+	return c.usageError(reportUsage)
+}
+
+func (c *cli) todayReport(ctx context.Context) int {
+	stats, err := c.client.GetTodayStats(ctx)
+	if err != nil {
+		return c.fail("get today's usage", err)
+	}
+
+	return c.print(stats, func(w *tabwriter.Writer) {
+		fmt.Fprintf(w, "Usage on %s (%d active sessions)\n\n", stats.Date, stats.ActiveSessions)
+		fmt.Fprintln(w, "CHILD\tUSED\tREMAINING\tLIMIT\tSESSIONS\tUSAGE")
+		for _, child := range stats.Children {
+			name := child.ChildEmoji + " " + child.ChildName
+			if child.TrackingPaused {
+				name += " (tracking paused)"
+			}
+			fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%d\t%d%%\n",
+				name, child.TodayUsed, child.TodayRemaining, child.TodayLimit, child.SessionsToday, child.UsagePercent)
+		}
+	})
+}
+
+func (c *cli) trendsReport(ctx context.Context) int {
+	report, err := c.client.GetTrends(ctx)
+	if err != nil {
+		return c.fail("get trends", err)
+	}
+
+	return c.print(report, func(w *tabwriter.Writer) {
+		fmt.Fprintln(w, "CHILD\tWEEK AVG\tMONTH AVG\tWEEKDAYS\tWEEKENDS\tCHANGE")
+		for _, child := range report.Children {
+			change := "-"
+			if child.WeekChangePercent != nil {
+				change = fmt.Sprintf("%+.0f%% (%s)", *child.WeekChangePercent, child.Direction)
+			}
+			fmt.Fprintf(w, "%s %s\t%.0f\t%.0f\t%.0f\t%.0f\t%s\n",
+				child.ChildEmoji, child.ChildName, child.Week.AverageMinutes, child.Month.AverageMinutes,
+				child.Weekdays.AverageMinutes, child.Weekends.AverageMinutes, change)
+		}
+	})
+}
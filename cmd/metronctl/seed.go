@@ -0,0 +1,508 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"metron/config"
+	"metron/internal/bundle"
+	"metron/internal/core"
+	"metron/internal/idgen"
+	"metron/pkg/client"
+)
+
+const seedUsage = "seed -file fixtures.yaml [-replace] [-dry-run]"
+
+// maxHistoryDays bounds generated history, so the bundle stays under the server's body size limit
+const maxHistoryDays = 90
+
+// dateLayout is the layout of calendar days in fixtures
+const dateLayout = "2006-01-02"
+
+// seedInitiator is recorded as the initiator of generated sessions
+const seedInitiator = "metronctl-seed"
+
+// fixtures is a seed file (see fixtures.example.yaml)
+type fixtures struct {
+	Timezone string           `yaml:"timezone"` // Timezone of the history's days; set it to the server's (default local)
+	Devices  []fixtureDevice  `yaml:"devices"`
+	Children []fixtureChild   `yaml:"children"`
+	History  *fixturesHistory `yaml:"history"`
+}
+
+// fixtureDevice is a device the fixtures expect the server to have
+// Devices live in the server's configuration file, so they are checked rather than created.
+type fixtureDevice struct {
+	ID         string         `yaml:"id"`
+	Name       string         `yaml:"name"`
+	Type       string         `yaml:"type"`
+	Driver     string         `yaml:"driver"` // For the configuration printed for missing devices (default passive)
+	Parameters map[string]any `yaml:"parameters"`
+}
+
+type fixtureChild struct {
+	ID              string               `yaml:"id"` // Derived from the name if empty
+	Name            string               `yaml:"name"`
+	Emoji           string               `yaml:"emoji"`
+	PIN             string               `yaml:"pin"`
+	WeekdayLimit    int                  `yaml:"weekday_limit"`
+	WeekendLimit    int                  `yaml:"weekend_limit"`
+	BreakRule       *fixtureBreakRule    `yaml:"break_rule"`
+	DowntimeEnabled bool                 `yaml:"downtime_enabled"`
+	AllowedDevices  []string             `yaml:"allowed_devices"`
+	Timezone        string               `yaml:"timezone"`
+	GraceMinutes    int                  `yaml:"grace_minutes"`
+	Birthdate       string               `yaml:"birthdate"`     // YYYY-MM-DD
+	LimitChanges    []fixtureLimitChange `yaml:"limit_changes"` // Scheduled changes, effective after today
+}
+
+type fixtureBreakRule struct {
+	BreakAfterMinutes    int    `yaml:"break_after_minutes"`
+	BreakDurationMinutes int    `yaml:"break_duration_minutes"`
+	Action               string `yaml:"action"`
+}
+
+type fixtureLimitChange struct {
+	EffectiveFrom string `yaml:"effective_from"` // YYYY-MM-DD
+	WeekdayLimit  int    `yaml:"weekday_limit"`
+	WeekendLimit  int    `yaml:"weekend_limit"`
+}
+
+// fixturesHistory is the sample history: sessions generated for the days before today,
+// and sessions listed explicitly
+type fixturesHistory struct {
+	Days     int              `yaml:"days"` // Days of generated sessions before today (0 for none)
+	Seed     uint64           `yaml:"seed"` // The same file and seed generate the same sessions
+	Sessions []fixtureSession `yaml:"sessions"`
+}
+
+type fixtureSession struct {
+	Device   string    `yaml:"device"`
+	Children []string  `yaml:"children"`
+	Start    time.Time `yaml:"start"` // RFC 3339, in the past
+	Minutes  int       `yaml:"minutes"`
+	Activity string    `yaml:"activity"`
+}
+
+// seed runs "seed": it creates the fixtures' children, limit changes and sample history
+// through the bundle import, after checking that the fixtures' devices exist
+func (c *cli) seed(ctx context.Context, args []string) int {
+	fs := c.flagSet("seed", seedUsage)
+	path := fs.String("file", "", "Fixtures file (YAML or JSON, required)")
+	replace := fs.Bool("replace", false, "Delete the fixtures' children (with their history) first, to restore the baseline")
+	dryRun := fs.Bool("dry-run", false, "Check the fixtures and print what would be created, without changing anything")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *path == "" || fs.NArg() != 0 {
+		return c.usageError(seedUsage)
+	}
+
+	f, err := loadFixtures(*path)
+	if err != nil {
+		fmt.Fprintf(c.errOut, "Failed to load fixtures: %v\n", err)
+		return 1
+	}
+
+	devices, err := c.client.ListDevices(ctx)
+	if err != nil {
+		return c.fail("list devices", err)
+	}
+	if missing := f.missingDevices(devices); len(missing) > 0 {
+		return c.reportMissingDevices(missing)
+	}
+
+	b, err := f.bundle(devices, time.Now())
+	if err != nil {
+		fmt.Fprintf(c.errOut, "%s: %v\n", *path, err)
+		return 1
+	}
+
+	children, err := c.client.ListChildren(ctx)
+	if err != nil {
+		return c.fail("list children", err)
+	}
+	var existing []string
+	for _, child := range b.Children {
+		if slices.ContainsFunc(children, func(c client.Child) bool { return c.ID == child.ID }) {
+			existing = append(existing, child.ID)
+		}
+	}
+	if len(existing) > 0 && !*replace {
+		fmt.Fprintf(c.errOut, "Children already exist: %s (use -replace to delete and recreate them)\n", strings.Join(existing, ", "))
+		return 1
+	}
+
+	if *dryRun {
+		return c.print(b, func(w *tabwriter.Writer) {
+			fmt.Fprintf(w, "Would create %d children, %d limit changes, %d sessions and %d days of usage\n",
+				len(b.Children), len(b.LimitChanges), len(b.History.Sessions), len(b.History.Usage))
+			if len(existing) > 0 {
+				fmt.Fprintf(w, "Would first delete %s\n", strings.Join(existing, ", "))
+			}
+		})
+	}
+
+	data, err := json.Marshal(b)
+	if err != nil {
+		fmt.Fprintf(c.errOut, "Failed to encode bundle: %v\n", err)
+		return 1
+	}
+	for _, id := range existing {
+		if err := c.client.DeleteChild(ctx, id); err != nil {
+			return c.fail("delete child "+id, err)
+		}
+	}
+	result, err := c.client.ImportBundle(ctx, data)
+	if err != nil {
+		return c.fail("import fixtures", err)
+	}
+
+	return c.print(result, func(w *tabwriter.Writer) {
+		if len(existing) > 0 {
+			fmt.Fprintf(w, "Deleted %s\n", strings.Join(existing, ", "))
+		}
+		fmt.Fprintf(w, "Created %d children, %d limit changes, %d sessions, %d days of usage and %d allocations\n",
+			result.Children, result.LimitChanges, result.Sessions, result.UsageDays, result.Allocations)
+	})
+}
+
+// reportMissingDevices prints the configuration of devices the server does not have
+func (c *cli) reportMissingDevices(missing []fixtureDevice) int {
+	configs := make([]config.DeviceConfig, 0, len(missing))
+	ids := make([]string, 0, len(missing))
+	for _, device := range missing {
+		driver := device.Driver
+		if driver == "" {
+			driver = "passive"
+		}
+		configs = append(configs, config.DeviceConfig{
+			ID:         device.ID,
+			Name:       device.Name,
+			Type:       device.Type,
+			Driver:     driver,
+			Parameters: device.Parameters,
+		})
+		ids = append(ids, device.ID)
+	}
+
+	snippet, _ := json.MarshalIndent(configs, "", "  ")
+	fmt.Fprintf(c.errOut, "The server has no device %s. Devices are defined in the server's configuration file;\n", strings.Join(ids, ", "))
+	fmt.Fprintf(c.errOut, "add them to its \"devices\" section and restart it:\n\n%s\n", snippet)
+	return 1
+}
+
+// loadFixtures reads and decodes a fixtures file
+func loadFixtures(path string) (*fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f fixtures
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&f); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(f.Children) == 0 {
+		return nil, fmt.Errorf("%s has no children", path)
+	}
+	return &f, nil
+}
+
+// missingDevices returns the fixtures' devices the server does not have
+func (f *fixtures) missingDevices(devices []client.Device) []fixtureDevice {
+	var missing []fixtureDevice
+	for _, device := range f.Devices {
+		if !slices.ContainsFunc(devices, func(d client.Device) bool { return d.ID == device.ID }) {
+			missing = append(missing, device)
+		}
+	}
+	return missing
+}
+
+// bundle converts the fixtures into a bundle for the server's import, generating the sample
+// history for the days before now
+func (f *fixtures) bundle(devices []client.Device, now time.Time) (*bundle.Bundle, error) {
+	timezone := time.Local
+	if f.Timezone != "" {
+		var err error
+		if timezone, err = time.LoadLocation(f.Timezone); err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", f.Timezone, err)
+		}
+	}
+	now = now.In(timezone)
+	today := now.Format(dateLayout)
+
+	deviceTypes := make(map[string]string, len(devices))
+	for _, device := range devices {
+		deviceTypes[device.ID] = device.Type
+	}
+
+	b := &bundle.Bundle{
+		Format:     bundle.Format,
+		Version:    bundle.Version,
+		ExportedAt: now.UTC(),
+		History:    &bundle.History{},
+	}
+	children := make(map[string]*fixtureChild, len(f.Children))
+	for i := range f.Children {
+		child := &f.Children[i]
+		if child.ID == "" {
+			child.ID = childID(child.Name)
+		}
+		if _, ok := children[child.ID]; ok {
+			return nil, fmt.Errorf("child %s is listed twice", child.ID)
+		}
+		for _, deviceID := range child.AllowedDevices {
+			if _, ok := deviceTypes[deviceID]; !ok {
+				return nil, fmt.Errorf("child %s: unknown device %s", child.ID, deviceID)
+			}
+		}
+		children[child.ID] = child
+		b.Children = append(b.Children, child.bundleChild())
+
+		for _, change := range child.LimitChanges {
+			if _, err := time.Parse(dateLayout, change.EffectiveFrom); err != nil || change.EffectiveFrom <= today {
+				return nil, fmt.Errorf("child %s: limit change effective_from %q must be a date after today (YYYY-MM-DD)", child.ID, change.EffectiveFrom)
+			}
+			b.LimitChanges = append(b.LimitChanges, bundle.LimitChange{
+				ID:            idgen.NewLimitChange(),
+				ChildID:       child.ID,
+				WeekdayLimit:  change.WeekdayLimit,
+				WeekendLimit:  change.WeekendLimit,
+				EffectiveFrom: change.EffectiveFrom,
+				CreatedBy:     seedInitiator,
+				CreatedAt:     now.UTC(),
+			})
+		}
+	}
+
+	var sessions []bundle.Session
+	var days []string
+	if f.History != nil {
+		if f.History.Days < 0 || f.History.Days > maxHistoryDays {
+			return nil, fmt.Errorf("history days must be between 0 and %d", maxHistoryDays)
+		}
+		sessions = generateSessions(f.Children, devices, f.History.Days, f.History.Seed, now)
+		for day := f.History.Days; day >= 1; day-- {
+			days = append(days, now.AddDate(0, 0, -day).Format(dateLayout))
+		}
+		for _, s := range f.History.Sessions {
+			session, err := s.bundleSession(children, deviceTypes, now)
+			if err != nil {
+				return nil, err
+			}
+			sessions = append(sessions, session)
+		}
+	}
+	slices.SortFunc(sessions, func(a, b bundle.Session) int {
+		return a.StartTime.Compare(b.StartTime)
+	})
+	b.History.Sessions = sessions
+	b.History.Usage, b.History.Allocations = dailyHistory(sessions, days, f.Children, timezone)
+
+	// Reports skip the days before a child was created, so the children date from the first day of history
+	if len(days) > 0 || len(sessions) > 0 {
+		first := now
+		if len(days) > 0 {
+			first = now.AddDate(0, 0, -f.History.Days)
+		}
+		if len(sessions) > 0 && sessions[0].StartTime.Before(first) {
+			first = sessions[0].StartTime.In(timezone)
+		}
+		createdAt := time.Date(first.Year(), first.Month(), first.Day(), 0, 0, 0, 0, timezone).UTC()
+		for i := range b.Children {
+			b.Children[i].CreatedAt = &createdAt
+		}
+	}
+	return b, nil
+}
+
+// bundleChild converts a fixture child for the bundle
+func (c *fixtureChild) bundleChild() bundle.Child {
+	child := bundle.Child{
+		ID:              c.ID,
+		Name:            c.Name,
+		Emoji:           c.Emoji,
+		PIN:             c.PIN, // The child web app also accepts PINs stored in plain text
+		WeekdayLimit:    c.WeekdayLimit,
+		WeekendLimit:    c.WeekendLimit,
+		DowntimeEnabled: c.DowntimeEnabled,
+		AllowedDevices:  c.AllowedDevices,
+		Timezone:        c.Timezone,
+		GraceMinutes:    c.GraceMinutes,
+		Birthdate:       c.Birthdate,
+	}
+	if c.BreakRule != nil {
+		child.BreakRule = &bundle.BreakRule{
+			BreakAfterMinutes:    c.BreakRule.BreakAfterMinutes,
+			BreakDurationMinutes: c.BreakRule.BreakDurationMinutes,
+			Action:               c.BreakRule.Action,
+		}
+	}
+	return child
+}
+
+// bundleSession converts a session listed in the fixtures
+func (s *fixtureSession) bundleSession(children map[string]*fixtureChild, deviceTypes map[string]string, now time.Time) (bundle.Session, error) {
+	deviceType, ok := deviceTypes[s.Device]
+	if !ok {
+		return bundle.Session{}, fmt.Errorf("session at %s: unknown device %q", s.Start.Format(time.RFC3339), s.Device)
+	}
+	if len(s.Children) == 0 || s.Minutes <= 0 {
+		return bundle.Session{}, fmt.Errorf("session at %s: children and positive minutes are required", s.Start.Format(time.RFC3339))
+	}
+	for _, id := range s.Children {
+		if _, ok := children[id]; !ok {
+			return bundle.Session{}, fmt.Errorf("session at %s: unknown child %s", s.Start.Format(time.RFC3339), id)
+		}
+	}
+	if !s.Start.Add(time.Duration(s.Minutes) * time.Minute).Before(now) {
+		return bundle.Session{}, fmt.Errorf("session at %s: sessions must have ended before now", s.Start.Format(time.RFC3339))
+	}
+	return endedSession(s.Device, deviceType, s.Children, s.Start, s.Minutes, s.Activity), nil
+}
+
+// generateSessions generates up to three afternoon sessions per child and day for the days
+// before now, on the child's allowed devices, within the child's daily limit
+func generateSessions(children []fixtureChild, devices []client.Device, days int, seed uint64, now time.Time) []bundle.Session {
+	if len(devices) == 0 {
+		return nil
+	}
+	rng := rand.New(rand.NewPCG(seed, seed))
+	lengths := []int{15, 30, 45, 60}
+
+	var sessions []bundle.Session
+	for _, child := range children {
+		var allowed []client.Device
+		for _, device := range devices {
+			if len(child.AllowedDevices) == 0 || slices.Contains(child.AllowedDevices, device.ID) {
+				allowed = append(allowed, device)
+			}
+		}
+
+		for day := days; day >= 1; day-- {
+			date := now.AddDate(0, 0, -day)
+			remaining := child.WeekdayLimit
+			if weekday := date.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+				remaining = child.WeekendLimit
+			}
+
+			start := time.Date(date.Year(), date.Month(), date.Day(), 15, 0, 0, 0, date.Location())
+			for n := rng.IntN(4); n > 0; n-- {
+				minutes := min(lengths[rng.IntN(len(lengths))], remaining)
+				if minutes <= 0 {
+					break
+				}
+				start = start.Add(time.Duration(rng.IntN(60)) * time.Minute)
+				device := allowed[rng.IntN(len(allowed))]
+				sessions = append(sessions, endedSession(device.ID, device.Type, []string{child.ID}, start, minutes, ""))
+
+				remaining -= minutes
+				start = start.Add(time.Duration(minutes) * time.Minute)
+			}
+		}
+	}
+	return sessions
+}
+
+// endedSession is a session that ran for its planned minutes
+func endedSession(deviceID, deviceType string, childIDs []string, start time.Time, minutes int, activity string) bundle.Session {
+	actual := minutes
+	return bundle.Session{
+		ID:              idgen.NewSession(),
+		DeviceType:      deviceType,
+		DeviceID:        deviceID,
+		ChildIDs:        childIDs,
+		StartTime:       start.UTC(),
+		ExpectedMinutes: minutes,
+		ActualMinutes:   &actual,
+		Status:          string(core.SessionStatusExpired),
+		EndReason:       core.SessionEndExpired,
+		InitiatorType:   core.InitiatorAPI,
+		InitiatorID:     seedInitiator,
+		Activity:        activity,
+	}
+}
+
+// dailyHistory sums the sessions into each child's daily usage, and records the child's
+// limit as the allocation of each generated day and each day with usage
+func dailyHistory(sessions []bundle.Session, days []string, children []fixtureChild, timezone *time.Location) ([]bundle.Usage, []bundle.Allocation) {
+	type key struct {
+		childID string
+		date    string
+	}
+	var usageKeys []key
+	usage := make(map[key]*bundle.Usage)
+	for _, session := range sessions {
+		if session.Activity != "" {
+			continue // Exempt activities are not charged
+		}
+		date := session.StartTime.In(timezone).Format(dateLayout)
+		for _, childID := range session.ChildIDs {
+			k := key{childID, date}
+			if usage[k] == nil {
+				usage[k] = &bundle.Usage{ChildID: childID, Date: date}
+				usageKeys = append(usageKeys, k)
+			}
+			usage[k].MinutesUsed += *session.ActualMinutes
+			usage[k].SessionCount++
+		}
+	}
+
+	history := make([]bundle.Usage, 0, len(usageKeys))
+	for _, k := range usageKeys {
+		history = append(history, *usage[k])
+	}
+
+	var allocations []bundle.Allocation
+	allocated := make(map[key]bool)
+	allocate := func(child *fixtureChild, date string) {
+		k := key{child.ID, date}
+		if allocated[k] {
+			return
+		}
+		allocated[k] = true
+
+		limit := child.WeekdayLimit
+		if day, _ := time.ParseInLocation(dateLayout, date, timezone); day.Weekday() == time.Saturday || day.Weekday() == time.Sunday {
+			limit = child.WeekendLimit
+		}
+		allocations = append(allocations, bundle.Allocation{
+			ChildID:   child.ID,
+			Date:      date,
+			BaseLimit: limit,
+		})
+	}
+	for i := range children {
+		child := &children[i]
+		for _, date := range days {
+			allocate(child, date)
+		}
+		for _, k := range usageKeys {
+			if k.childID == child.ID {
+				allocate(child, k.date)
+			}
+		}
+	}
+	return history, allocations
+}
+
+var nonIDChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// childID derives an ID from a child's name (e.g., "Anna Maria" becomes "anna-maria")
+func childID(name string) string {
+	return strings.Trim(nonIDChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"metron/pkg/client"
+)
+
+const (
+	sessionListUsage   = "session list [-all] [-child ID]"
+	sessionStartUsage  = "session start -device ID [-by NAME] MINUTES CHILD..."
+	sessionExtendUsage = "session extend SESSION MINUTES"
+	sessionStopUsage   = "session stop SESSION"
+)
+
+// session runs "session list", "start", "extend" and "stop"
+func (c *cli) session(ctx context.Context, args []string) int {
+	name, args := subcommand(args)
+	switch name {
+	case "list", "ls":
+		return c.listSessions(ctx, args)
+	case "start":
+		return c.startSession(ctx, args)
+	case "extend":
+		return c.extendSession(ctx, args)
+	case "stop":
+		return c.stopSession(ctx, args)
+	}
+	return c.usageError("session list|start|extend|stop")
+}
+
+func (c *cli) listSessions(ctx context.Context, args []string) int {
+	fs := c.flagSet("session list", sessionListUsage)
+	all := fs.Bool("all", false, "Also list ended sessions")
+	childID := fs.String("child", "", "Only sessions of this child")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 0 {
+		return c.usageError(sessionListUsage)
+	}
+
+	// The API filters either by state or by child, so active sessions of a child are filtered here
+	sessions, err := c.client.ListSessions(ctx, !*all && *childID == "", *childID)
+	if err != nil {
+		return c.fail("list sessions", err)
+	}
+	if !*all && *childID != "" {
+		active := sessions[:0]
+		for _, session := range sessions {
+			if session.Status == "active" || session.Status == "paused" {
+				active = append(active, session)
+			}
+		}
+		sessions = active
+	}
+
+	return c.print(sessions, func(w *tabwriter.Writer) {
+		fmt.Fprintln(w, "ID\tDEVICE\tCHILDREN\tSTARTED\tMINUTES\tREMAINING\tSTATUS")
+		for _, session := range sessions {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\t%s\n",
+				session.ID, session.DeviceID, strings.Join(session.ChildIDs, ","), session.StartTime,
+				session.ExpectedDuration, session.RemainingMinutes, session.Status)
+		}
+	})
+}
+
+func (c *cli) startSession(ctx context.Context, args []string) int {
+	fs := c.flagSet("session start", sessionStartUsage)
+	deviceID := fs.String("device", "", "Device to start the session on (required)")
+	by := fs.String("by", "", "Start the session as this parent (recorded as the initiator); the API key by default")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *deviceID == "" || fs.NArg() < 2 {
+		return c.usageError(sessionStartUsage)
+	}
+	minutes, err := strconv.Atoi(fs.Arg(0))
+	if err != nil || minutes <= 0 {
+		return c.usageError(sessionStartUsage)
+	}
+
+	req := client.CreateSessionRequest{
+		DeviceID: *deviceID,
+		ChildIDs: fs.Args()[1:],
+		Minutes:  minutes,
+	}
+	if *by != "" {
+		req.InitiatorType = "parent"
+		req.InitiatorID = *by
+	}
+
+	session, err := c.client.CreateSession(ctx, req)
+	if err != nil {
+		return c.fail("start session", err)
+	}
+
+	return c.print(session, func(w *tabwriter.Writer) {
+		switch {
+		case session.Queued:
+			fmt.Fprintf(w, "Device %s is busy; queued as %s (position %d, starts at %s)\n",
+				*deviceID, session.QueueID, session.Position, session.StartsAt)
+		case session.Merged:
+			fmt.Fprintf(w, "Joined running session %s on %s (%d minutes left)\n",
+				session.ID, session.DeviceID, session.RemainingMinutes)
+		default:
+			fmt.Fprintf(w, "Started session %s on %s for %d minutes\n",
+				session.ID, session.DeviceID, session.ExpectedDuration)
+		}
+		if session.Capped {
+			fmt.Fprintf(w, "Requested %d minutes, granted %d (%s)\n", session.RequestedMinutes, session.GrantedMinutes, session.CapReason)
+		}
+	})
+}
+
+func (c *cli) extendSession(ctx context.Context, args []string) int {
+	if len(args) != 2 {
+		return c.usageError(sessionExtendUsage)
+	}
+	minutes, err := strconv.Atoi(args[1])
+	if err != nil || minutes <= 0 {
+		return c.usageError(sessionExtendUsage)
+	}
+
+	session, err := c.client.ExtendSession(ctx, args[0], minutes)
+	if err != nil {
+		return c.fail("extend session", err)
+	}
+
+	return c.print(session, func(w *tabwriter.Writer) {
+		fmt.Fprintf(w, "Extended session %s to %d minutes (%d left)\n",
+			session.ID, session.ExpectedDuration, session.RemainingMinutes)
+		if session.Capped {
+			fmt.Fprintf(w, "Requested %d minutes, granted %d (%s)\n", session.RequestedMinutes, session.GrantedMinutes, session.CapReason)
+		}
+	})
+}
+
+func (c *cli) stopSession(ctx context.Context, args []string) int {
+	if len(args) != 1 {
+		return c.usageError(sessionStopUsage)
+	}
+
+	if err := c.client.StopSession(ctx, args[0]); err != nil {
+		return c.fail("stop session", err)
+	}
+
+	return c.print(map[string]string{"id": args[0], "status": "stopped"}, func(w *tabwriter.Writer) {
+		fmt.Fprintf(w, "Stopped session %s\n", args[0])
+	})
+}
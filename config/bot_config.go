@@ -3,35 +3,83 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
+	"regexp"
 )
 
 // BotConfig represents the Telegram bot configuration
 type BotConfig struct {
-	Server   BotServerConfig   `json:"server"`
-	Telegram TelegramBotConfig `json:"telegram"`
-	Metron   MetronAPIConfig   `json:"metron"`
+	Server   BotServerConfig   `json:"server" doc:"HTTP server receiving Telegram webhooks"`
+	Telegram TelegramBotConfig `json:"telegram" doc:"Telegram bot"`
+	Metron   MetronAPIConfig   `json:"metron" doc:"Connection to the Metron API"`
 }
 
 // BotServerConfig contains HTTP server settings for the bot
 type BotServerConfig struct {
-	Host string `json:"host"`
-	Port int    `json:"port"`
+	Host string `json:"host" default:"0.0.0.0" doc:"Address the bot listens on"`
+	Port int    `json:"port" doc:"Port the bot listens on (required)"`
+
+	TrustedProxies []string `json:"trusted_proxies" doc:"Reverse proxies (IPs or CIDRs) whose X-Forwarded-For header gives the client address"`
 }
 
 // TelegramBotConfig contains Telegram bot settings
 type TelegramBotConfig struct {
-	Token         string  `json:"token"`
-	AllowedUsers  []int64 `json:"allowed_users"`
-	WebhookURL    string  `json:"webhook_url"`
-	WebhookSecret string  `json:"webhook_secret"`
-	Timezone      string  `json:"timezone"` // IANA timezone (e.g., "Europe/Riga", "UTC")
+	Token         string  `json:"token" doc:"Bot token from @BotFather (required)"`
+	AllowedUsers  []int64 `json:"allowed_users" doc:"Telegram user IDs allowed to use the bot (required)"`
+	WebhookURL    string  `json:"webhook_url" doc:"Public URL Telegram sends updates to (required)"`
+	WebhookSecret string  `json:"webhook_secret" doc:"Optional: secret token Telegram sends with each update"`
+	Timezone      string  `json:"timezone" default:"UTC" doc:"IANA timezone (e.g., \"Europe/Riga\")"`
+
+	PreviousWebhookSecrets []string         `json:"previous_webhook_secrets" doc:"Still accepted after webhook_secret was rotated, for updates already on the way"`
+	WebhookIPCheck         bool             `json:"webhook_ip_check" doc:"Only accept webhook requests from Telegram's networks (or webhook_allowed_ips)"`
+	WebhookAllowedIPs      []string         `json:"webhook_allowed_ips" doc:"Replaces Telegram's published networks (CIDRs) for the IP check"`
+	Roles                  map[int64]string `json:"roles" doc:"Bot role (viewer, operator or admin) by user ID; users without one are admins"`
+	DeviceOfflineMinutes   int              `json:"device_offline_minutes" doc:"Alert allowed users when a device has not checked in for this long (0 disables)"`
+	SessionWarnings        bool             `json:"session_warnings" doc:"Forward session expiry warnings to allowed users with buttons to extend or stop"`
+	WeeklyDigest           bool             `json:"weekly_digest" doc:"Send allowed users a usage digest with trends versus the previous week every Monday morning"`
+	ProfileTransitions     bool             `json:"profile_transitions" doc:"Ask allowed users to confirm limit profile changes proposed on children's birthdays"`
+	WebAppURL              string           `json:"web_app_url" doc:"Public HTTPS URL of the bot's /webapp dashboard; enables the /dashboard command"`
+	UsageAlerts            bool             `json:"usage_alerts" doc:"Notify allowed users when a child reaches one of the server's usage alert thresholds"`
+}
+
+// TelegramWebhookNetworks are the networks Telegram sends webhook requests from
+// See https://core.telegram.org/bots/webhooks#the-short-version
+var TelegramWebhookNetworks = []string{"149.154.160.0/20", "91.108.4.0/22"}
+
+// webhookSecretPattern is what Telegram accepts as a webhook secret token
+var webhookSecretPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,256}$`)
+
+// Bot roles, from least to most privileged
+const (
+	BotRoleViewer   = "viewer"   // Views status, usage, children and devices
+	BotRoleOperator = "operator" // Also starts, extends and stops sessions
+	BotRoleAdmin    = "admin"    // Everything, including rewards, fines, downtime, bypass and lockdown
+)
+
+// botRoleRanks orders the bot roles by privilege
+var botRoleRanks = map[string]int{
+	BotRoleViewer:   1,
+	BotRoleOperator: 2,
+	BotRoleAdmin:    3,
+}
+
+// BotRoleAllows reports whether role grants at least the privileges of required
+func BotRoleAllows(role, required string) bool {
+	rank, ok := botRoleRanks[role]
+	return ok && rank >= botRoleRanks[required]
 }
 
 // MetronAPIConfig contains Metron API connection settings
 type MetronAPIConfig struct {
-	BaseURL string `json:"base_url"`
-	APIKey  string `json:"api_key"`
+	BaseURL     string `json:"base_url" doc:"Metron API URL (required)"`
+	APIKey      string `json:"api_key" doc:"The server's security.api_key (required)"`
+	OverrideKey string `json:"override_key" doc:"Optional: the server's security.override_key, if set"`
+
+	TimeoutSeconds int  `json:"timeout_seconds" default:"10" doc:"Limit of each request attempt"`
+	Retries        *int `json:"retries" default:"2" doc:"Retries after a transient failure (0 disables)"`
+
+	CacheSeconds *int `json:"cache_seconds" default:"30" doc:"How long the children and devices lists are reused (0 disables)"`
 }
 
 // LoadBotConfig loads bot configuration from a file
@@ -74,6 +122,35 @@ func (c *BotConfig) Validate() error {
 		return fmt.Errorf("%w: telegram.webhook_url is required", ErrInvalidConfig)
 	}
 
+	for _, secret := range append([]string{c.Telegram.WebhookSecret}, c.Telegram.PreviousWebhookSecrets...) {
+		if secret != "" && !webhookSecretPattern.MatchString(secret) {
+			return fmt.Errorf("%w: webhook secrets may only contain letters, digits, _ and - (up to 256 characters)", ErrInvalidConfig)
+		}
+	}
+
+	for _, proxy := range c.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			return fmt.Errorf("%w: server.trusted_proxies: invalid address %q", ErrInvalidConfig, proxy)
+		}
+	}
+
+	if _, err := c.Telegram.WebhookNetworks(); err != nil {
+		return fmt.Errorf("%w: telegram.webhook_allowed_ips: %v", ErrInvalidConfig, err)
+	}
+
+	for userID, role := range c.Telegram.Roles {
+		if _, ok := botRoleRanks[role]; !ok {
+			return fmt.Errorf("%w: telegram.roles: invalid role %q for user %d (must be viewer, operator or admin)", ErrInvalidConfig, role, userID)
+		}
+		if !c.IsUserAllowed(userID) {
+			return fmt.Errorf("%w: telegram.roles: user %d is not in telegram.allowed_users", ErrInvalidConfig, userID)
+		}
+	}
+
+	if c.Telegram.DeviceOfflineMinutes < 0 {
+		return fmt.Errorf("%w: telegram.device_offline_minutes cannot be negative", ErrInvalidConfig)
+	}
+
 	if c.Metron.BaseURL == "" {
 		return fmt.Errorf("%w: metron.base_url is required", ErrInvalidConfig)
 	}
@@ -82,6 +159,18 @@ func (c *BotConfig) Validate() error {
 		return fmt.Errorf("%w: metron.api_key is required", ErrInvalidConfig)
 	}
 
+	if c.Metron.TimeoutSeconds < 0 {
+		return fmt.Errorf("%w: metron.timeout_seconds cannot be negative", ErrInvalidConfig)
+	}
+
+	if c.Metron.Retries != nil && *c.Metron.Retries < 0 {
+		return fmt.Errorf("%w: metron.retries cannot be negative", ErrInvalidConfig)
+	}
+
+	if c.Metron.CacheSeconds != nil && *c.Metron.CacheSeconds < 0 {
+		return fmt.Errorf("%w: metron.cache_seconds cannot be negative", ErrInvalidConfig)
+	}
+
 	// Set default host if not specified
 	if c.Server.Host == "" {
 		c.Server.Host = "0.0.0.0"
@@ -104,3 +193,51 @@ func (c *BotConfig) IsUserAllowed(userID int64) bool {
 	}
 	return false
 }
+
+// UserRole returns the bot role of a user, or "" if the user is not allowed
+func (c *BotConfig) UserRole(userID int64) string {
+	if !c.IsUserAllowed(userID) {
+		return ""
+	}
+	if role, ok := c.Telegram.Roles[userID]; ok {
+		return role
+	}
+	return BotRoleAdmin
+}
+
+// WebhookSecrets returns the secrets accepted on the webhook, the current one first
+// Empty if no secret is configured.
+func (t *TelegramBotConfig) WebhookSecrets() []string {
+	if t.WebhookSecret == "" {
+		return nil
+	}
+	secrets := []string{t.WebhookSecret}
+	for _, secret := range t.PreviousWebhookSecrets {
+		if secret != "" {
+			secrets = append(secrets, secret)
+		}
+	}
+	return secrets
+}
+
+// WebhookNetworks returns the networks webhook requests are accepted from, or nil without the IP check
+func (t *TelegramBotConfig) WebhookNetworks() ([]*net.IPNet, error) {
+	if !t.WebhookIPCheck {
+		return nil, nil
+	}
+
+	cidrs := t.WebhookAllowedIPs
+	if len(cidrs) == 0 {
+		cidrs = TelegramWebhookNetworks
+	}
+
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
@@ -4,9 +4,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net/url"
 	"os"
-	"strconv"
+	"regexp"
+	"strings"
 	"time"
+
+	"metron/internal/credentials"
 )
 
 var (
@@ -16,90 +20,396 @@ var (
 
 // Config represents the application configuration
 type Config struct {
-	Server    ServerConfig     `json:"server"`
-	Database  DatabaseConfig   `json:"database"`
-	Security  SecurityConfig   `json:"security"`
-	Timezone  string           `json:"timezone"` // IANA timezone string (e.g., "Europe/Riga")
-	Devices   []DeviceConfig   `json:"devices"`  // Global device registry
-	Aqara     AqaraConfig      `json:"aqara"`
-	Kidslox   *KidsloxConfig   `json:"kidslox,omitempty"`
-	Notify    *NotifyConfig    `json:"notify,omitempty"`
-	Downtime  *DowntimeConfig  `json:"downtime,omitempty"`
-	MovieTime *MovieTimeConfig `json:"movie_time,omitempty"`
+	Server     ServerConfig      `json:"server" doc:"HTTP server"`
+	Database   DatabaseConfig    `json:"database" doc:"SQLite database"`
+	Security   SecurityConfig    `json:"security" doc:"API authentication and access control"`
+	Timezone   string            `json:"timezone" env:"METRON_TIMEZONE" default:"UTC" doc:"IANA timezone string (e.g., \"Europe/Riga\")"`
+	Devices    []DeviceConfig    `json:"devices" doc:"Global device registry"`
+	Aqara      AqaraConfig       `json:"aqara" doc:"Aqara Cloud API (aqara driver, credentials required)"`
+	Kidslox    *KidsloxConfig    `json:"kidslox,omitempty" doc:"Optional: Kidslox API (kidslox driver)"`
+	Notify     *NotifyConfig     `json:"notify,omitempty" doc:"Optional: Telegram notifications for manual enforcement (notify driver)"`
+	Router     *RouterConfig     `json:"router,omitempty" doc:"Optional: internet access gated by firewall rules (router driver)"`
+	FamilyLink *FamilyLinkConfig `json:"familylink,omitempty" doc:"Optional: Family Link driver and usage import"`
+	SmartTV    *SmartTVConfig    `json:"smarttv,omitempty" doc:"Optional: Samsung Tizen and LG webOS TVs (smarttv driver)"`
+	AndroidTV  *AndroidTVConfig  `json:"androidtv,omitempty" doc:"Optional: Android TVs over ADB network debugging (androidtv driver)"`
+	Steam      *SteamConfig      `json:"steam,omitempty" doc:"Optional: Steam playtime integration"`
+	HomeKit    *HomeKitConfig    `json:"homekit,omitempty" doc:"Optional: HomeKit bridge exposing devices to Apple Home"`
+	Downtime   *DowntimeConfig   `json:"downtime,omitempty" doc:"Optional: global downtime schedule (per-day, weekday/weekend or flat start_time/end_time)"`
+	MovieTime  *MovieTimeConfig  `json:"movie_time,omitempty" doc:"Optional: weekend shared movie time"`
+
+	MediaServers *MediaServersConfig `json:"media_servers,omitempty" doc:"Optional: Plex and Jellyfin playback webhooks"`
+
+	UsageSources []UsageSourceConfig `json:"usage_sources,omitempty" doc:"External agents reporting app usage to /ingest/usage"`
+
+	LimitProfiles []LimitProfileConfig `json:"limit_profiles,omitempty" doc:"Age-based limits proposed on birthdays"`
+
+	SessionPolicies []SessionPolicyConfig `json:"session_policies,omitempty" doc:"Session length, gap and per-day rules by child and device"`
+
+	FamilyBudget *FamilyBudgetConfig `json:"family_budget,omitempty" doc:"Optional: daily screen time budget shared by all children"`
+
+	ExemptActivities []ExemptActivityConfig `json:"exempt_activities,omitempty" doc:"Activities (e.g., homework) whose sessions are not charged against daily time"`
+
+	SessionConflicts string `json:"session_conflicts,omitempty" default:"allow" doc:"Starting a session on a device already in use: \"allow\" it, \"reject\" it, \"queue\" it until the device is free or \"merge\" the children into the running session"`
+
+	AgentUpdate *AgentUpdateConfig `json:"agent_update,omitempty" doc:"Optional: host signed device agent releases"`
+	UsageAlerts *UsageAlertsConfig `json:"usage_alerts,omitempty" doc:"Optional: alert parents when children use a share of their daily time"`
+	WebPush     *WebPushConfig     `json:"web_push,omitempty" doc:"Optional: browser notifications of session warnings and ends (Web Push)"`
+	DriverQueue *DriverQueueConfig `json:"driver_queue,omitempty" doc:"Optional: make driver calls in a background queue instead of while API requests wait"`
+	Scheduler   *SchedulerConfig   `json:"scheduler,omitempty" doc:"Optional: scheduler loop settings"`
+
+	LeaderElection *LeaderElectionConfig `json:"leader_election,omitempty" doc:"Optional: run several instances on one database, only the leader runs the scheduler"`
+
+	DriverTimeouts    map[string]int `json:"driver_timeouts,omitempty" default:"30" doc:"Seconds a driver call may take, by driver name (\"default\" for the others)"`
+	DriverCallHistory int            `json:"driver_call_history,omitempty" default:"1000" doc:"Driver calls kept for /admin/driver-calls"`
+
+	InitiatorLimits map[string]int `json:"initiator_limits,omitempty" doc:"Longest session in minutes by who starts or extends it: \"parent\", \"child\", \"api\" or \"automation\" (e.g., {\"child\": 30})"`
+
+	DriversDir string `json:"drivers_dir,omitempty" doc:"Directory of driver plugin manifests (e.g., \"/etc/metron/drivers.d\")"`
+}
+
+// AgentUpdateConfig contains settings for hosting signed device agent releases
+type AgentUpdateConfig struct {
+	Dir string `json:"dir" doc:"Release directory written by \"metron agent-release\" (manifest.json + binary)"`
+}
+
+// UsageAlertsConfig contains settings for alerting parents when children use a share of their daily time
+type UsageAlertsConfig struct {
+	Thresholds    []int  `json:"thresholds,omitempty" default:"[80]" doc:"Percentages of the day's time that are alerted"`
+	WebhookURL    string `json:"webhook_url,omitempty" doc:"Optional: alerts are also POSTed here"`
+	WebhookSecret string `json:"webhook_secret,omitempty" doc:"Optional: signs webhook bodies (X-Metron-Signature, HMAC-SHA256)"`
+}
+
+// DriverQueueConfig enables the background queue for driver calls (see core.DriverQueue)
+// Without it, driver calls are made inline while API requests wait for them
+type DriverQueueConfig struct {
+	Workers     int `json:"workers,omitempty" default:"4" doc:"Driver calls made at once"`
+	MaxAttempts int `json:"max_attempts,omitempty" default:"5" doc:"Attempts before a call is given up"`
+}
+
+// SchedulerConfig contains settings for the scheduler loop
+type SchedulerConfig struct {
+	IntervalSeconds int `json:"interval_seconds,omitempty" default:"60" doc:"Time between scheduler ticks"`
+	JitterSeconds   int `json:"jitter_seconds,omitempty" doc:"Up to this much random delay per tick, so instances sharing a database don't tick together"`
+}
+
+// LeaderElectionConfig enables leader election between instances sharing the database
+// (see core.LeaderElection)
+type LeaderElectionConfig struct {
+	LeaseSeconds int `json:"lease_seconds,omitempty" default:"30" doc:"How long a leader that stops renewing keeps leading"`
+}
+
+// defaultSchedulerIntervalSeconds is the scheduler interval without a scheduler section
+const defaultSchedulerIntervalSeconds = 60
+
+// SchedulerInterval returns the time between scheduler ticks, with default fallback
+func (c *Config) SchedulerInterval() time.Duration {
+	if c.Scheduler != nil && c.Scheduler.IntervalSeconds > 0 {
+		return time.Duration(c.Scheduler.IntervalSeconds) * time.Second
+	}
+	return defaultSchedulerIntervalSeconds * time.Second
+}
+
+// SchedulerJitter returns the random delay added to scheduler ticks (0 = none)
+func (c *Config) SchedulerJitter() time.Duration {
+	if c.Scheduler == nil {
+		return 0
+	}
+	return time.Duration(c.Scheduler.JitterSeconds) * time.Second
+}
+
+// defaultDriverTimeoutSeconds bounds driver calls without a driver_timeouts entry
+const defaultDriverTimeoutSeconds = 30
+
+// DriverTimeout returns how long a call to the named driver may take, with default fallback
+func (c *Config) DriverTimeout(driver string) time.Duration {
+	if seconds, ok := c.DriverTimeouts[driver]; ok {
+		return time.Duration(seconds) * time.Second
+	}
+	if seconds, ok := c.DriverTimeouts["default"]; ok {
+		return time.Duration(seconds) * time.Second
+	}
+	return defaultDriverTimeoutSeconds * time.Second
 }
 
 // MovieTimeConfig contains settings for weekend shared movie time feature
 type MovieTimeConfig struct {
-	Enabled          bool     `json:"enabled"`            // Whether movie time feature is enabled
-	DurationMinutes  int      `json:"duration_minutes"`   // Movie session duration (default: 120)
-	BreakMinutes     int      `json:"break_minutes"`      // Required break after last personal session (default: 60)
-	AllowedDeviceIDs []string `json:"allowed_device_ids"` // Devices where movie time can be used (e.g., ["tv1"])
+	Enabled          bool     `json:"enabled" doc:"Whether movie time feature is enabled"`
+	DurationMinutes  int      `json:"duration_minutes" default:"120" doc:"Movie session duration"`
+	BreakMinutes     int      `json:"break_minutes" default:"60" doc:"Required break after last personal session"`
+	AllowedDeviceIDs []string `json:"allowed_device_ids" doc:"Devices where movie time can be used (e.g., [\"tv1\"])"`
+}
+
+// LimitProfileConfig defines the daily limits for children within an age range
+type LimitProfileConfig struct {
+	Name         string `json:"name" doc:"Display name (e.g., \"9-12\")"`
+	MinAge       int    `json:"min_age" doc:"Inclusive, in full years"`
+	MaxAge       int    `json:"max_age" doc:"Inclusive, in full years"`
+	WeekdayLimit int    `json:"weekday_limit" doc:"Minutes per weekday"`
+	WeekendLimit int    `json:"weekend_limit" doc:"Minutes per weekend day"`
+}
+
+// SessionPolicyConfig limits sessions of some children on some devices, beyond their daily limits
+// Rules left at 0 are not enforced.
+type SessionPolicyConfig struct {
+	Name              string   `json:"name" doc:"Name reported when the policy blocks or caps a request (e.g., \"consoles\")"`
+	ChildIDs          []string `json:"child_ids,omitempty" doc:"Children the policy applies to (default all)"`
+	DeviceIDs         []string `json:"device_ids,omitempty" doc:"Devices the policy applies to (default all)"`
+	MaxSessionMinutes int      `json:"max_session_minutes,omitempty" doc:"Longest single session; longer starts and extensions are capped"`
+	MinGapMinutes     int      `json:"min_gap_minutes,omitempty" doc:"Minutes between the end of a session and the start of the next"`
+	MaxSessionsPerDay int      `json:"max_sessions_per_day,omitempty" doc:"Sessions a child may start per day"`
+}
+
+// FamilyBudgetConfig defines a daily budget shared by all children (e.g., 3 hours of TV),
+// enforced on top of each child's own limits
+type FamilyBudgetConfig struct {
+	Minutes     int      `json:"minutes" doc:"Minutes per day across all children; a shared session counts once"`
+	DeviceIDs   []string `json:"device_ids,omitempty" doc:"Devices the budget covers (e.g., [\"tv1\"])"`
+	DeviceTypes []string `json:"device_types,omitempty" doc:"Device types the budget covers (e.g., [\"tv\"]); all devices if neither is set"`
+}
+
+// ExemptActivityConfig defines an activity whose sessions unlock devices without being charged
+// against children's daily time (e.g., homework on the PC), drawing on its own daily budget
+type ExemptActivityConfig struct {
+	Name         string   `json:"name" doc:"Activity name requested when starting a session (e.g., \"homework\")"`
+	DeviceIDs    []string `json:"device_ids,omitempty" doc:"Devices the activity may run on; all devices if not set"`
+	DailyMinutes int      `json:"daily_minutes,omitempty" doc:"Minutes per child per day; not limited if 0"`
 }
 
 // DeviceConfig represents a device configuration
 type DeviceConfig struct {
-	ID         string                 `json:"id"`                   // Unique device ID (e.g., "tv1", "ps5")
-	Name       string                 `json:"name"`                 // Display name (e.g., "Living Room TV")
-	Type       string                 `json:"type"`                 // Device type (e.g., "tv", "ps5") - for display/stats
-	Emoji      string                 `json:"emoji,omitempty"`      // Optional emoji override (default derived from type)
-	Driver     string                 `json:"driver"`               // Driver name (e.g., "aqara") - for control
-	Parameters map[string]interface{} `json:"parameters,omitempty"` // Driver-specific parameters (overrides defaults)
+	ID                  string                 `json:"id" doc:"Unique device ID (e.g., \"tv1\", \"ps5\")"`
+	Name                string                 `json:"name" doc:"Display name (e.g., \"Living Room TV\")"`
+	Type                string                 `json:"type" doc:"Device type (e.g., \"tv\", \"ps5\") - for display/stats"`
+	Emoji               string                 `json:"emoji,omitempty" doc:"Optional emoji override (default derived from type)"`
+	Timezone            string                 `json:"timezone,omitempty" doc:"Optional IANA timezone where the device is (overrides child/server timezone for downtime)"`
+	Driver              string                 `json:"driver" doc:"Driver name (e.g., \"aqara\") - for control"`
+	Parameters          map[string]interface{} `json:"parameters,omitempty" doc:"Driver-specific parameters (overrides defaults)"`
+	TickIntervalSeconds int                    `json:"tick_interval_seconds,omitempty" doc:"Optional: check this device's sessions more often than the scheduler interval (e.g., 15 for agent-backed PCs)"`
 }
 
 // ServerConfig contains HTTP server settings
 type ServerConfig struct {
-	Host string `json:"host"`
-	Port int    `json:"port"`
+	Host            string `json:"host" env:"METRON_HOST" default:"0.0.0.0" doc:"Address the API listens on"`
+	Port            int    `json:"port" env:"METRON_PORT" default:"8080" doc:"Port the API listens on"`
+	CacheTTLSeconds int    `json:"cache_ttl_seconds,omitempty" default:"5" doc:"Lifetime of cached responses of polled endpoints (-1 disables caching)"`
+	TLSCertFile     string `json:"tls_cert_file,omitempty" doc:"Optional: serve HTTPS (and HTTP/2 over TLS) with this certificate"`
+	TLSKeyFile      string `json:"tls_key_file,omitempty" doc:"Private key of tls_cert_file"`
+	MaxBodyBytes    int64  `json:"max_body_bytes,omitempty" default:"1048576" doc:"Request body size limit"`
+	Metrics         bool   `json:"metrics,omitempty" env:"METRON_METRICS" doc:"Serve Prometheus metrics at GET /metrics (no auth)"`
+
+	CORS        *CORSConfig        `json:"cors,omitempty" doc:"Optional: browser origins allowed to call the API (default: any, with credentials)"`
+	ChildCookie *ChildCookieConfig `json:"child_cookie,omitempty" doc:"Optional: attributes of the child session cookie"`
+	ChildAppURL string             `json:"child_app_url,omitempty" env:"METRON_CHILD_APP_URL" doc:"Optional: public URL of the child web app; login links from GET /api/v1/child/auth/qr point to it"`
+
+	LegacyRoutesSunset string `json:"legacy_routes_sunset,omitempty" doc:"Optional: date (YYYY-MM-DD, UTC) from which the unversioned /v1 and /child routes answer 410; until then they are served with Deprecation and Sunset headers"`
+}
+
+// LegacySunset returns when the unversioned routes stop working (zero if never)
+func (s ServerConfig) LegacySunset() (time.Time, error) {
+	if s.LegacyRoutesSunset == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse("2006-01-02", s.LegacyRoutesSunset)
+}
+
+// CORSConfig lists the browser origins allowed to call the API (e.g. the child web app)
+type CORSConfig struct {
+	AllowedOrigins   []string `json:"allowed_origins" doc:"e.g. [\"https://kids.example.com\"]; \"*\" allows any origin"`
+	AllowCredentials bool     `json:"allow_credentials" doc:"Let browsers send the child session cookie cross-origin"`
+}
+
+// ChildCookieConfig sets the attributes of the child session cookie
+type ChildCookieConfig struct {
+	SameSite string `json:"same_site,omitempty" doc:"\"lax\", \"strict\" or \"none\" (none requires secure); empty leaves it out"`
+	Secure   bool   `json:"secure" doc:"Only send the cookie over HTTPS"`
+	Domain   string `json:"domain,omitempty" doc:"Optional cookie domain (default: the API host)"`
 }
 
+// originPattern matches browser origins like "https://kids.example.com:8443" (no path)
+var originPattern = regexp.MustCompile(`^https?://[A-Za-z0-9.-]+(:\d+)?$`)
+
 // DatabaseConfig contains database settings
 type DatabaseConfig struct {
-	Path string `json:"path"`
+	Path string `json:"path" env:"METRON_DB_PATH" default:"./metron.db" doc:"Database file"`
+
+	// Reporting endpoints (stats, trends) can read from a second, read-only connection so that
+	// heavy reports don't contend with the scheduler's writes
+	ReadOnlyConnection bool   `json:"read_only_connection,omitempty" env:"METRON_DB_READ_ONLY_CONNECTION" doc:"Open a read-only connection to path (switches the database to WAL)"`
+	ReadReplicaPath    string `json:"read_replica_path,omitempty" env:"METRON_DB_READ_REPLICA_PATH" doc:"Optional: read from this replica of path instead (may lag behind)"`
+}
+
+// ReadPath returns the database reporting endpoints read from ("" to use the main connection)
+func (d DatabaseConfig) ReadPath() string {
+	if d.ReadReplicaPath != "" {
+		return d.ReadReplicaPath
+	}
+	if d.ReadOnlyConnection {
+		return d.Path
+	}
+	return ""
 }
 
 // SecurityConfig contains security settings
 type SecurityConfig struct {
-	APIKey        string   `json:"api_key"`
-	AllowedIPs    []string `json:"allowed_ips"`
-	EnableIPCheck bool     `json:"enable_ip_check"`
+	APIKey        string   `json:"api_key" env:"METRON_API_KEY" doc:"Key parents and the bot send in the X-Metron-Key header (required)"`
+	OverrideKey   string   `json:"override_key" doc:"Optional: also required for parent overrides of downtime and limits"`
+	AllowedIPs    []string `json:"allowed_ips" doc:"Client IPs allowed to call the API when enable_ip_check is set"`
+	EnableIPCheck bool     `json:"enable_ip_check" env:"METRON_ENABLE_IP_CHECK" doc:"Only accept requests from allowed_ips"`
+
+	CredentialsKey string `json:"credentials_key,omitempty" doc:"Optional: base64 32-byte key encrypting driver credentials (else METRON_CREDENTIALS_KEY or the OS keyring)"`
 }
 
 // AqaraConfig contains Aqara Cloud API settings
 type AqaraConfig struct {
-	AppID   string      `json:"app_id"`
-	AppKey  string      `json:"app_key"`
-	KeyID   string      `json:"key_id"`
-	BaseURL string      `json:"base_url"`
-	Scenes  AqaraScenes `json:"scenes"` // Default scenes (can be overridden per device)
+	AppID   string      `json:"app_id" env:"METRON_AQARA_APP_ID" doc:"Aqara developer app ID"`
+	AppKey  string      `json:"app_key" env:"METRON_AQARA_APP_KEY" doc:"Aqara developer app key"`
+	KeyID   string      `json:"key_id" env:"METRON_AQARA_KEY_ID" doc:"Aqara developer key ID"`
+	BaseURL string      `json:"base_url" env:"METRON_AQARA_BASE_URL" default:"https://open-cn.aqara.com" doc:"Aqara Cloud API base URL"`
+	Scenes  AqaraScenes `json:"scenes" doc:"Default scenes (can be overridden per device)"`
 }
 
 // AqaraScenes contains scene IDs for different actions
 type AqaraScenes struct {
-	TVPINEntry string `json:"tv_pin_entry"`
-	TVWarning  string `json:"tv_warning"`
-	TVPowerOff string `json:"tv_power_off"`
+	TVPINEntry string `json:"tv_pin_entry" env:"METRON_AQARA_TV_PIN_SCENE" doc:"Scene entering the TV PIN (unlocks the TV)"`
+	TVWarning  string `json:"tv_warning" env:"METRON_AQARA_TV_WARNING_SCENE" doc:"Scene shown when a session is about to end"`
+	TVPowerOff string `json:"tv_power_off" env:"METRON_AQARA_TV_POWEROFF_SCENE" doc:"Scene turning the TV off"`
 }
 
 // KidsloxConfig contains Kidslox API settings
 type KidsloxConfig struct {
-	BaseURL   string `json:"base_url"`   // API base URL
-	APIKey    string `json:"api_key"`    // Static API key for authentication
-	AccountID string `json:"account_id"` // Account ID for actions
+	BaseURL   string `json:"base_url" default:"https://admin.kdlparentalcontrol.com" doc:"API base URL"`
+	APIKey    string `json:"api_key" doc:"Static API key for authentication"`
+	AccountID string `json:"account_id" doc:"Account ID for actions"`
 	// Default device parameters (can be overridden by device-specific parameters)
-	DeviceID  string `json:"device_id,omitempty"`  // Default Kidslox device ID
-	ProfileID string `json:"profile_id,omitempty"` // Default Kidslox profile ID
+	DeviceID  string `json:"device_id,omitempty" doc:"Default Kidslox device ID"`
+	ProfileID string `json:"profile_id,omitempty" doc:"Default Kidslox profile ID"`
 }
 
 // NotifyConfig contains settings for the notify driver (Telegram notifications for manual enforcement)
 type NotifyConfig struct {
-	TelegramToken string  `json:"telegram_token"`
-	ChatIDs       []int64 `json:"chat_ids"`
+	TelegramToken string  `json:"telegram_token" doc:"Telegram bot token the notifications are sent with"`
+	ChatIDs       []int64 `json:"chat_ids" doc:"Telegram chats notified"`
+}
+
+// RouterConfig contains settings for the router driver (internet access gated by firewall rules)
+type RouterConfig struct {
+	Type               string `json:"type" doc:"\"openwrt\" (ubus JSON-RPC) or \"mikrotik\" (RouterOS v7 REST API)"`
+	URL                string `json:"url" doc:"Router base URL (e.g., \"http://192.168.1.1\")"`
+	Username           string `json:"username" doc:"rpcd login (OpenWrt) or RouterOS user"`
+	Password           string `json:"password" doc:"Password for the user"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty" doc:"Accept self-signed router certificates"`
+}
+
+// SmartTVConfig contains settings for the smart TV driver (Samsung Tizen and LG webOS over the local network)
+// TV addresses and pairing keys are device parameters
+type SmartTVConfig struct {
+	ClientName       string `json:"client_name,omitempty" default:"Metron" doc:"Name shown on the TV pairing prompt"`
+	LockCheckSeconds int    `json:"lock_check_seconds,omitempty" default:"30" doc:"How often locked TVs are turned off again"`
+}
+
+// GetLockCheckSeconds returns the lock check interval, with default fallback
+func (s *SmartTVConfig) GetLockCheckSeconds() int {
+	if s.LockCheckSeconds <= 0 {
+		return 30
+	}
+	return s.LockCheckSeconds
+}
+
+// AndroidTVConfig contains settings for the Android TV driver (ADB network debugging)
+// Device address, port and key override are device parameters
+type AndroidTVConfig struct {
+	KeyFile    string `json:"key_file,omitempty" doc:"Default adb private key (PEM), e.g. ~/.android/adbkey"`
+	ClientName string `json:"client_name,omitempty" default:"metron" doc:"Name shown on the debugging prompt"`
+}
+
+// FamilyLinkConfig contains settings for the Family Link driver and usage import
+type FamilyLinkConfig struct {
+	Cookies               string `json:"cookies" doc:"Cookie header of a signed-in familylink.google.com session (must include SAPISID)"`
+	APIKey                string `json:"api_key" doc:"X-Goog-Api-Key sent by the Family Link web app"`
+	ImportIntervalMinutes int    `json:"import_interval_minutes,omitempty" doc:"Import device usage every N minutes (0 = disabled)"`
+}
+
+// SteamConfig contains settings for the Steam playtime integration
+type SteamConfig struct {
+	APIKey              string               `json:"api_key" doc:"Steam Web API key (https://steamcommunity.com/dev/apikey)"`
+	PollIntervalMinutes int                  `json:"poll_interval_minutes,omitempty" default:"5" doc:"How often playtime is polled"`
+	Accounts            []SteamAccountConfig `json:"accounts" doc:"Children's Steam accounts"`
+}
+
+// SteamAccountConfig links a Steam account to a child
+type SteamAccountConfig struct {
+	SteamID          string `json:"steam_id" doc:"64-bit SteamID (e.g., \"76561198000000000\")"`
+	ChildID          string `json:"child_id" doc:"Child charged for play outside sessions"`
+	DeviceID         string `json:"device_id,omitempty" doc:"Gaming device; only sessions on it cover play (default: any session)"`
+	AutoStartMinutes int    `json:"auto_start_minutes,omitempty" doc:"Start a session of this length on device_id when play is detected (0 = disabled)"`
+}
+
+// GetPollIntervalMinutes returns the poll interval, with default fallback
+func (s *SteamConfig) GetPollIntervalMinutes() int {
+	if s.PollIntervalMinutes <= 0 {
+		return 5
+	}
+	return s.PollIntervalMinutes
+}
+
+// WebPushConfig contains settings for browser notifications with Web Push
+type WebPushConfig struct {
+	Subject         string `json:"subject" doc:"Contact push services can reach the operator at (mailto: or https: URL)"`
+	VAPIDPublicKey  string `json:"vapid_public_key,omitempty" doc:"Optional: VAPID public key (base64url); generated and stored in the database when empty"`
+	VAPIDPrivateKey string `json:"vapid_private_key,omitempty" doc:"Optional: VAPID private key (base64url), set together with vapid_public_key"`
+}
+
+// MediaServersConfig contains settings for the Plex and Jellyfin playback webhooks
+type MediaServersConfig struct {
+	WebhookToken string                     `json:"webhook_token" doc:"Secret the webhook URLs carry as ?token= (media servers cannot send the API key)"`
+	Profiles     []MediaServerProfileConfig `json:"profiles" doc:"Children's media server profiles"`
+}
+
+// MediaServerProfileConfig links a Plex or Jellyfin profile to a child
+type MediaServerProfileConfig struct {
+	Server           string `json:"server" doc:"\"plex\" or \"jellyfin\""`
+	User             string `json:"user" doc:"Plex account title or Jellyfin user name"`
+	ChildID          string `json:"child_id" doc:"Child watching on the profile"`
+	DeviceID         string `json:"device_id,omitempty" doc:"Device the profile watches on; only sessions on it cover playback (default: any session)"`
+	AutoStartMinutes int    `json:"auto_start_minutes,omitempty" doc:"Start a session of this length on device_id when playback starts without one (0 = disabled)"`
+}
+
+// UsageSourceConfig is an external agent that reports children's app usage (e.g., a YouTube history exporter)
+type UsageSourceConfig struct {
+	Name     string   `json:"name" doc:"Source name stored with the reported usage (e.g., \"youtube\")"`
+	Token    string   `json:"token" doc:"Bearer token the agent sends to /ingest/usage"`
+	ChildIDs []string `json:"child_ids,omitempty" doc:"Children the source may report for (default: all)"`
+}
+
+// HomeKitConfig contains settings for the HomeKit bridge that exposes devices to Apple Home
+type HomeKitConfig struct {
+	Name      string                `json:"name,omitempty" default:"Metron" doc:"Bridge name shown in the Home app"`
+	SetupCode string                `json:"setup_code" doc:"Code entered when adding the bridge, \"XXX-XX-XXX\""`
+	Port      int                   `json:"port,omitempty" default:"51826" doc:"TCP port of the bridge"`
+	Devices   []HomeKitDeviceConfig `json:"devices,omitempty" doc:"Session start settings; devices without them are read-only"`
+}
+
+// HomeKitDeviceConfig lets the Home app start sessions on a device
+type HomeKitDeviceConfig struct {
+	DeviceID string   `json:"device_id" doc:"Device sessions are started on"`
+	ChildIDs []string `json:"child_ids" doc:"Children of sessions started from Home"`
+	Minutes  int      `json:"minutes" doc:"Duration of sessions started from Home"`
+}
+
+// homekitSetupCode matches HomeKit setup codes like "123-45-678"
+var homekitSetupCode = regexp.MustCompile(`^\d{3}-\d{2}-\d{3}$`)
+
+// trivialSetupCodes are refused by HomeKit controllers
+var trivialSetupCodes = map[string]bool{
+	"000-00-000": true, "111-11-111": true, "222-22-222": true, "333-33-333": true, "444-44-444": true,
+	"555-55-555": true, "666-66-666": true, "777-77-777": true, "888-88-888": true, "999-99-999": true,
+	"123-45-678": true, "876-54-321": true,
 }
 
 // DayScheduleConfig defines start/end times for a day
 type DayScheduleConfig struct {
-	StartTime string `json:"start_time"` // HH:MM format (e.g., "22:00")
-	EndTime   string `json:"end_time"`   // HH:MM format (e.g., "10:00")
+	StartTime string `json:"start_time" doc:"HH:MM format (e.g., \"22:00\")"`
+	EndTime   string `json:"end_time" doc:"HH:MM format (e.g., \"10:00\")"`
 }
 
 // DowntimeConfig defines the global downtime schedule
@@ -109,21 +419,21 @@ type DayScheduleConfig struct {
 // 3. Legacy flat format: {"start_time": "22:00", "end_time": "10:00"} - applies to all days
 type DowntimeConfig struct {
 	// Legacy flat fields (for backward compatibility)
-	StartTime string `json:"start_time,omitempty"` // HH:MM format (e.g., "22:00")
-	EndTime   string `json:"end_time,omitempty"`   // HH:MM format (e.g., "10:00")
+	StartTime string `json:"start_time,omitempty" doc:"HH:MM format (e.g., \"22:00\")"`
+	EndTime   string `json:"end_time,omitempty" doc:"HH:MM format (e.g., \"10:00\")"`
 
 	// Grouped schedules (weekday/weekend)
-	Weekday *DayScheduleConfig `json:"weekday,omitempty"` // Default for Mon-Fri (if per-day not set)
-	Weekend *DayScheduleConfig `json:"weekend,omitempty"` // Default for Sat-Sun (if per-day not set)
+	Weekday *DayScheduleConfig `json:"weekday,omitempty" doc:"Default for Mon-Fri (if per-day not set)"`
+	Weekend *DayScheduleConfig `json:"weekend,omitempty" doc:"Default for Sat-Sun (if per-day not set)"`
 
 	// Explicit per-day schedules (highest priority)
-	Sunday    *DayScheduleConfig `json:"sunday,omitempty"`
-	Monday    *DayScheduleConfig `json:"monday,omitempty"`
-	Tuesday   *DayScheduleConfig `json:"tuesday,omitempty"`
-	Wednesday *DayScheduleConfig `json:"wednesday,omitempty"`
-	Thursday  *DayScheduleConfig `json:"thursday,omitempty"`
-	Friday    *DayScheduleConfig `json:"friday,omitempty"`
-	Saturday  *DayScheduleConfig `json:"saturday,omitempty"`
+	Sunday    *DayScheduleConfig `json:"sunday,omitempty" doc:"Sunday schedule (overrides weekend)"`
+	Monday    *DayScheduleConfig `json:"monday,omitempty" doc:"Monday schedule (overrides weekday)"`
+	Tuesday   *DayScheduleConfig `json:"tuesday,omitempty" doc:"Tuesday schedule (overrides weekday)"`
+	Wednesday *DayScheduleConfig `json:"wednesday,omitempty" doc:"Wednesday schedule (overrides weekday)"`
+	Thursday  *DayScheduleConfig `json:"thursday,omitempty" doc:"Thursday schedule (overrides weekday)"`
+	Friday    *DayScheduleConfig `json:"friday,omitempty" doc:"Friday schedule (overrides weekday)"`
+	Saturday  *DayScheduleConfig `json:"saturday,omitempty" doc:"Saturday schedule (overrides weekend)"`
 }
 
 // IsLegacyFormat returns true if using old flat start_time/end_time format
@@ -316,6 +626,34 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("%w: invalid server port", ErrInvalidConfig)
 	}
 
+	if (c.Server.TLSCertFile == "") != (c.Server.TLSKeyFile == "") {
+		return fmt.Errorf("%w: server tls_cert_file and tls_key_file must be set together", ErrInvalidConfig)
+	}
+
+	if c.Server.CORS != nil {
+		for _, origin := range c.Server.CORS.AllowedOrigins {
+			if origin != "*" && !originPattern.MatchString(origin) {
+				return fmt.Errorf("%w: invalid cors origin '%s' (expected scheme://host[:port])", ErrInvalidConfig, origin)
+			}
+		}
+	}
+
+	if cookie := c.Server.ChildCookie; cookie != nil {
+		switch strings.ToLower(cookie.SameSite) {
+		case "", "lax", "strict":
+		case "none":
+			if !cookie.Secure {
+				return fmt.Errorf("%w: child_cookie same_site none requires secure", ErrInvalidConfig)
+			}
+		default:
+			return fmt.Errorf("%w: child_cookie same_site must be lax, strict or none", ErrInvalidConfig)
+		}
+	}
+
+	if _, err := c.Server.LegacySunset(); err != nil {
+		return fmt.Errorf("%w: legacy_routes_sunset must use the YYYY-MM-DD format", ErrInvalidConfig)
+	}
+
 	if c.Database.Path == "" {
 		return fmt.Errorf("%w: database path is required", ErrInvalidConfig)
 	}
@@ -335,6 +673,50 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("%w: invalid timezone '%s': %v", ErrInvalidConfig, c.Timezone, err)
 	}
 
+	// Validate device timezone overrides
+	for _, device := range c.Devices {
+		if device.Timezone == "" {
+			continue
+		}
+		if _, err := time.LoadLocation(device.Timezone); err != nil {
+			return fmt.Errorf("%w: invalid timezone '%s' for device %s: %v", ErrInvalidConfig, device.Timezone, device.ID, err)
+		}
+	}
+
+	if c.Security.CredentialsKey != "" {
+		if _, err := credentials.ParseKey(c.Security.CredentialsKey); err != nil {
+			return fmt.Errorf("%w: security credentials_key: %v", ErrInvalidConfig, err)
+		}
+	}
+
+	// Validate scheduler config if present
+	if c.Scheduler != nil {
+		if c.Scheduler.IntervalSeconds < 0 {
+			return fmt.Errorf("%w: scheduler interval_seconds cannot be negative", ErrInvalidConfig)
+		}
+		if c.Scheduler.JitterSeconds < 0 {
+			return fmt.Errorf("%w: scheduler jitter_seconds cannot be negative", ErrInvalidConfig)
+		}
+		if c.SchedulerJitter() >= c.SchedulerInterval() {
+			return fmt.Errorf("%w: scheduler jitter_seconds must be less than the interval", ErrInvalidConfig)
+		}
+	}
+
+	// Validate leader election config if present (renewed every third of the lease)
+	if c.LeaderElection != nil && c.LeaderElection.LeaseSeconds != 0 && c.LeaderElection.LeaseSeconds < 3 {
+		return fmt.Errorf("%w: leader_election lease_seconds must be at least 3", ErrInvalidConfig)
+	}
+
+	// Per-device ticks can only be faster than the scheduler interval
+	for _, device := range c.Devices {
+		if device.TickIntervalSeconds < 0 {
+			return fmt.Errorf("%w: tick_interval_seconds of device %s cannot be negative", ErrInvalidConfig, device.ID)
+		}
+		if time.Duration(device.TickIntervalSeconds)*time.Second > c.SchedulerInterval() {
+			return fmt.Errorf("%w: tick_interval_seconds of device %s must not exceed the scheduler interval", ErrInvalidConfig, device.ID)
+		}
+	}
+
 	// Validate Aqara config (required for now for backward compatibility)
 	if c.Aqara.AppID == "" || c.Aqara.AppKey == "" || c.Aqara.KeyID == "" {
 		return fmt.Errorf("%w: Aqara credentials are required", ErrInvalidConfig)
@@ -365,6 +747,130 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Validate router config if present
+	if c.Router != nil {
+		if c.Router.Type != "openwrt" && c.Router.Type != "mikrotik" {
+			return fmt.Errorf("%w: router type must be \"openwrt\" or \"mikrotik\"", ErrInvalidConfig)
+		}
+		if c.Router.URL == "" || c.Router.Username == "" {
+			return fmt.Errorf("%w: router url and username are required when router is configured", ErrInvalidConfig)
+		}
+	}
+
+	// Validate Family Link config if present
+	if c.FamilyLink != nil {
+		if c.FamilyLink.Cookies == "" || c.FamilyLink.APIKey == "" {
+			return fmt.Errorf("%w: familylink cookies and api_key are required when familylink is configured", ErrInvalidConfig)
+		}
+		if c.FamilyLink.ImportIntervalMinutes < 0 {
+			return fmt.Errorf("%w: familylink import_interval_minutes must not be negative", ErrInvalidConfig)
+		}
+	}
+
+	// Validate smart TV config if present
+	if c.SmartTV != nil && c.SmartTV.LockCheckSeconds < 0 {
+		return fmt.Errorf("%w: smarttv lock_check_seconds must not be negative", ErrInvalidConfig)
+	}
+
+	// Validate Steam config if present
+	if c.Steam != nil {
+		if c.Steam.APIKey == "" {
+			return fmt.Errorf("%w: steam api_key is required when steam is configured", ErrInvalidConfig)
+		}
+		if c.Steam.PollIntervalMinutes < 0 {
+			return fmt.Errorf("%w: steam poll_interval_minutes must not be negative", ErrInvalidConfig)
+		}
+		if len(c.Steam.Accounts) == 0 {
+			return fmt.Errorf("%w: steam accounts must not be empty when steam is configured", ErrInvalidConfig)
+		}
+		for i, account := range c.Steam.Accounts {
+			if account.SteamID == "" || account.ChildID == "" {
+				return fmt.Errorf("%w: steam account %d: steam_id and child_id are required", ErrInvalidConfig, i)
+			}
+			if account.AutoStartMinutes < 0 {
+				return fmt.Errorf("%w: steam account %s: auto_start_minutes must not be negative", ErrInvalidConfig, account.SteamID)
+			}
+			if account.AutoStartMinutes > 0 && account.DeviceID == "" {
+				return fmt.Errorf("%w: steam account %s: auto_start_minutes requires device_id", ErrInvalidConfig, account.SteamID)
+			}
+		}
+	}
+
+	// Validate media server config if present
+	if c.MediaServers != nil {
+		if c.MediaServers.WebhookToken == "" {
+			return fmt.Errorf("%w: media_servers webhook_token is required when media_servers is configured", ErrInvalidConfig)
+		}
+		if len(c.MediaServers.Profiles) == 0 {
+			return fmt.Errorf("%w: media_servers profiles must not be empty when media_servers is configured", ErrInvalidConfig)
+		}
+		for i, profile := range c.MediaServers.Profiles {
+			if profile.Server != "plex" && profile.Server != "jellyfin" {
+				return fmt.Errorf("%w: media_servers profile %d: server must be plex or jellyfin", ErrInvalidConfig, i)
+			}
+			if profile.User == "" || profile.ChildID == "" {
+				return fmt.Errorf("%w: media_servers profile %d: user and child_id are required", ErrInvalidConfig, i)
+			}
+			if profile.AutoStartMinutes < 0 {
+				return fmt.Errorf("%w: media_servers profile %s: auto_start_minutes must not be negative", ErrInvalidConfig, profile.User)
+			}
+			if profile.AutoStartMinutes > 0 && profile.DeviceID == "" {
+				return fmt.Errorf("%w: media_servers profile %s: auto_start_minutes requires device_id", ErrInvalidConfig, profile.User)
+			}
+		}
+	}
+
+	// Validate web push config if present
+	if c.WebPush != nil {
+		if !strings.HasPrefix(c.WebPush.Subject, "mailto:") && !strings.HasPrefix(c.WebPush.Subject, "https://") {
+			return fmt.Errorf("%w: web_push subject must be a mailto: or https: URL", ErrInvalidConfig)
+		}
+		if (c.WebPush.VAPIDPublicKey == "") != (c.WebPush.VAPIDPrivateKey == "") {
+			return fmt.Errorf("%w: web_push vapid_public_key and vapid_private_key must be set together", ErrInvalidConfig)
+		}
+	}
+
+	// Validate usage sources: names and tokens identify a source, so both are unique
+	sourceNames := make(map[string]bool)
+	sourceTokens := make(map[string]bool)
+	for i, source := range c.UsageSources {
+		if source.Name == "" || source.Token == "" {
+			return fmt.Errorf("%w: usage source %d: name and token are required", ErrInvalidConfig, i)
+		}
+		if sourceNames[source.Name] {
+			return fmt.Errorf("%w: usage source %s is configured twice", ErrInvalidConfig, source.Name)
+		}
+		if sourceTokens[source.Token] {
+			return fmt.Errorf("%w: usage source %s: token is used by another source", ErrInvalidConfig, source.Name)
+		}
+		sourceNames[source.Name] = true
+		sourceTokens[source.Token] = true
+	}
+
+	// Validate HomeKit config if present
+	if c.HomeKit != nil {
+		if !homekitSetupCode.MatchString(c.HomeKit.SetupCode) {
+			return fmt.Errorf("%w: homekit setup_code must look like 123-45-678", ErrInvalidConfig)
+		}
+		if trivialSetupCodes[c.HomeKit.SetupCode] {
+			return fmt.Errorf("%w: homekit setup_code %s is too simple and is refused by Apple Home", ErrInvalidConfig, c.HomeKit.SetupCode)
+		}
+		if strings.Contains(c.HomeKit.Name, ".") {
+			return fmt.Errorf("%w: homekit name must not contain dots", ErrInvalidConfig)
+		}
+		if c.HomeKit.Port < 0 || c.HomeKit.Port > 65535 {
+			return fmt.Errorf("%w: homekit port must be between 1 and 65535", ErrInvalidConfig)
+		}
+		for i, device := range c.HomeKit.Devices {
+			if device.DeviceID == "" {
+				return fmt.Errorf("%w: homekit device %d: device_id is required", ErrInvalidConfig, i)
+			}
+			if len(device.ChildIDs) == 0 || device.Minutes <= 0 {
+				return fmt.Errorf("%w: homekit device %s: child_ids and positive minutes are required", ErrInvalidConfig, device.DeviceID)
+			}
+		}
+	}
+
 	// Validate downtime config if present
 	if c.Downtime != nil {
 		if err := c.Downtime.Validate(); err != nil {
@@ -379,6 +885,127 @@ func (c *Config) Validate() error {
 		}
 	}
 
+	// Validate limit profiles: named, with positive limits and non-overlapping age ranges
+	for i, profile := range c.LimitProfiles {
+		if profile.Name == "" {
+			return fmt.Errorf("%w: limit profile %d: name is required", ErrInvalidConfig, i)
+		}
+		if profile.MinAge < 0 || profile.MaxAge < profile.MinAge {
+			return fmt.Errorf("%w: limit profile %s: invalid age range %d-%d", ErrInvalidConfig, profile.Name, profile.MinAge, profile.MaxAge)
+		}
+		if profile.WeekdayLimit <= 0 || profile.WeekendLimit <= 0 {
+			return fmt.Errorf("%w: limit profile %s: weekday_limit and weekend_limit must be positive", ErrInvalidConfig, profile.Name)
+		}
+		for _, other := range c.LimitProfiles[:i] {
+			if other.Name == profile.Name {
+				return fmt.Errorf("%w: duplicate limit profile %s", ErrInvalidConfig, profile.Name)
+			}
+			if profile.MinAge <= other.MaxAge && other.MinAge <= profile.MaxAge {
+				return fmt.Errorf("%w: limit profiles %s and %s overlap", ErrInvalidConfig, other.Name, profile.Name)
+			}
+		}
+	}
+
+	// Validate session policies: named, with at least one non-negative rule
+	for i, policy := range c.SessionPolicies {
+		if policy.Name == "" {
+			return fmt.Errorf("%w: session policy %d: name is required", ErrInvalidConfig, i)
+		}
+		if policy.MaxSessionMinutes < 0 || policy.MinGapMinutes < 0 || policy.MaxSessionsPerDay < 0 {
+			return fmt.Errorf("%w: session policy %s: rules cannot be negative", ErrInvalidConfig, policy.Name)
+		}
+		if policy.MaxSessionMinutes == 0 && policy.MinGapMinutes == 0 && policy.MaxSessionsPerDay == 0 {
+			return fmt.Errorf("%w: session policy %s: set max_session_minutes, min_gap_minutes or max_sessions_per_day", ErrInvalidConfig, policy.Name)
+		}
+		for _, other := range c.SessionPolicies[:i] {
+			if other.Name == policy.Name {
+				return fmt.Errorf("%w: duplicate session policy %s", ErrInvalidConfig, policy.Name)
+			}
+		}
+	}
+
+	// Validate family budget config if present
+	if c.FamilyBudget != nil && c.FamilyBudget.Minutes <= 0 {
+		return fmt.Errorf("%w: family_budget minutes must be positive", ErrInvalidConfig)
+	}
+
+	// Validate exempt activities: named uniquely, with a non-negative budget
+	for i, activity := range c.ExemptActivities {
+		if activity.Name == "" {
+			return fmt.Errorf("%w: exempt activity %d: name is required", ErrInvalidConfig, i)
+		}
+		if activity.DailyMinutes < 0 {
+			return fmt.Errorf("%w: exempt activity %s: daily_minutes cannot be negative", ErrInvalidConfig, activity.Name)
+		}
+		for _, other := range c.ExemptActivities[:i] {
+			if strings.EqualFold(other.Name, activity.Name) {
+				return fmt.Errorf("%w: duplicate exempt activity %s", ErrInvalidConfig, activity.Name)
+			}
+		}
+	}
+
+	// Validate session conflict policy
+	switch c.SessionConflicts {
+	case "", "allow", "reject", "queue", "merge":
+	default:
+		return fmt.Errorf("%w: session_conflicts must be allow, reject, queue or merge, got %q", ErrInvalidConfig, c.SessionConflicts)
+	}
+
+	// Validate agent update config if present
+	if c.AgentUpdate != nil && c.AgentUpdate.Dir == "" {
+		return fmt.Errorf("%w: agent_update dir is required when agent_update is configured", ErrInvalidConfig)
+	}
+
+	// Validate usage alerts config if present
+	if c.UsageAlerts != nil {
+		seen := make(map[int]bool, len(c.UsageAlerts.Thresholds))
+		for _, threshold := range c.UsageAlerts.Thresholds {
+			if threshold < 1 || threshold > 100 {
+				return fmt.Errorf("%w: usage_alerts threshold %d must be between 1 and 100", ErrInvalidConfig, threshold)
+			}
+			if seen[threshold] {
+				return fmt.Errorf("%w: duplicate usage_alerts threshold %d", ErrInvalidConfig, threshold)
+			}
+			seen[threshold] = true
+		}
+		if c.UsageAlerts.WebhookURL != "" {
+			if u, err := url.Parse(c.UsageAlerts.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("%w: usage_alerts webhook_url must be an http(s) URL", ErrInvalidConfig)
+			}
+		}
+	}
+
+	for driver, seconds := range c.DriverTimeouts {
+		if seconds < 1 {
+			return fmt.Errorf("%w: driver_timeouts %s must be at least 1 second", ErrInvalidConfig, driver)
+		}
+	}
+
+	for initiator, minutes := range c.InitiatorLimits {
+		switch initiator {
+		case "parent", "child", "api", "automation":
+		default:
+			return fmt.Errorf("%w: initiator_limits %q must be parent, child, api or automation", ErrInvalidConfig, initiator)
+		}
+		if minutes < 1 {
+			return fmt.Errorf("%w: initiator_limits %s must be at least 1 minute", ErrInvalidConfig, initiator)
+		}
+	}
+
+	if c.DriverCallHistory < 0 {
+		return fmt.Errorf("%w: driver_call_history cannot be negative", ErrInvalidConfig)
+	}
+
+	// Validate driver queue config if present
+	if c.DriverQueue != nil {
+		if c.DriverQueue.Workers < 0 {
+			return fmt.Errorf("%w: driver_queue workers cannot be negative", ErrInvalidConfig)
+		}
+		if c.DriverQueue.MaxAttempts < 0 {
+			return fmt.Errorf("%w: driver_queue max_attempts cannot be negative", ErrInvalidConfig)
+		}
+	}
+
 	return nil
 }
 
@@ -402,6 +1029,20 @@ func parseTimeOfDay(timeStr string) (hour, minute int, err error) {
 
 // Load loads configuration from a JSON file
 func Load(path string) (*Config, error) {
+	config, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// readFile parses a JSON configuration file without validating it
+func readFile(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -415,70 +1056,12 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config: %w", err)
 	}
 
-	if err := config.Validate(); err != nil {
-		return nil, err
-	}
-
 	return &config, nil
 }
 
-// LoadFromEnv loads configuration from environment variables
-// This is useful for containerized deployments
+// LoadFromEnv loads configuration from environment variables only
+// This is useful for containerized deployments. The options read, their variables and
+// defaults are the env and default struct tags (see Options).
 func LoadFromEnv() (*Config, error) {
-	config := &Config{
-		Server: ServerConfig{
-			Host: getEnv("METRON_HOST", "0.0.0.0"),
-			Port: getEnvInt("METRON_PORT", 8080),
-		},
-		Database: DatabaseConfig{
-			Path: getEnv("METRON_DB_PATH", "./metron.db"),
-		},
-		Security: SecurityConfig{
-			APIKey:        getEnv("METRON_API_KEY", ""),
-			EnableIPCheck: getEnvBool("METRON_ENABLE_IP_CHECK", false),
-		},
-		Timezone: getEnv("METRON_TIMEZONE", "UTC"),
-		Aqara: AqaraConfig{
-			AppID:   getEnv("METRON_AQARA_APP_ID", ""),
-			AppKey:  getEnv("METRON_AQARA_APP_KEY", ""),
-			KeyID:   getEnv("METRON_AQARA_KEY_ID", ""),
-			BaseURL: getEnv("METRON_AQARA_BASE_URL", "https://open-cn.aqara.com"),
-			Scenes: AqaraScenes{
-				TVPINEntry: getEnv("METRON_AQARA_TV_PIN_SCENE", ""),
-				TVWarning:  getEnv("METRON_AQARA_TV_WARNING_SCENE", ""),
-				TVPowerOff: getEnv("METRON_AQARA_TV_POWEROFF_SCENE", ""),
-			},
-		},
-	}
-
-	if err := config.Validate(); err != nil {
-		return nil, err
-	}
-
-	return config, nil
-}
-
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
-	return defaultValue
-}
-
-func getEnvInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
-		intVal, err := strconv.Atoi(value)
-		if err != nil {
-			return defaultValue
-		}
-		return intVal
-	}
-	return defaultValue
-}
-
-func getEnvBool(key string, defaultValue bool) bool {
-	if value := os.Getenv(key); value != "" {
-		return value == "true" || value == "1"
-	}
-	return defaultValue
+	return LoadLayered("", nil)
 }
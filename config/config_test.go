@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -60,6 +61,70 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "TLS certificate without key",
+			config: Config{
+				Server:   ServerConfig{Port: 8443, TLSCertFile: "/etc/metron/cert.pem"},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "CORS origin and cross-site child cookie",
+			config: Config{
+				Server: ServerConfig{
+					Port:        8080,
+					CORS:        &CORSConfig{AllowedOrigins: []string{"https://kids.example.com", "http://localhost:5173"}, AllowCredentials: true},
+					ChildCookie: &ChildCookieConfig{SameSite: "none", Secure: true},
+				},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "CORS origin with path",
+			config: Config{
+				Server:   ServerConfig{Port: 8080, CORS: &CORSConfig{AllowedOrigins: []string{"https://kids.example.com/app"}}},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "SameSite none child cookie without secure",
+			config: Config{
+				Server:   ServerConfig{Port: 8080, ChildCookie: &ChildCookieConfig{SameSite: "none"}},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "legacy routes sunset",
+			config: Config{
+				Server:   ServerConfig{Port: 8080, LegacyRoutesSunset: "2027-04-01"},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "legacy routes sunset not a date",
+			config: Config{
+				Server:   ServerConfig{Port: 8080, LegacyRoutesSunset: "April 2027"},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+			},
+			wantErr: true,
+		},
 		{
 			name: "missing database path",
 			config: Config{
@@ -78,6 +143,17 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "invalid device timezone",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				Devices:  []DeviceConfig{{ID: "tv1", Name: "TV", Type: "tv", Driver: "aqara", Timezone: "Nowhere/Land"}},
+			},
+			wantErr: true,
+		},
 		{
 			name: "missing Aqara credentials",
 			config: Config{
@@ -87,6 +163,500 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid router",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				Router:   &RouterConfig{Type: "openwrt", URL: "http://192.168.1.1", Username: "metron"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown router type",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				Router:   &RouterConfig{Type: "tplink", URL: "http://192.168.0.1", Username: "admin"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid familylink",
+			config: Config{
+				Server:     ServerConfig{Port: 8080},
+				Database:   DatabaseConfig{Path: "/path/to/db"},
+				Security:   SecurityConfig{APIKey: "test-key"},
+				Aqara:      AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				FamilyLink: &FamilyLinkConfig{Cookies: "SID=a; SAPISID=b", APIKey: "api-key", ImportIntervalMinutes: 15},
+			},
+			wantErr: false,
+		},
+		{
+			name: "negative smarttv lock check",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				SmartTV:  &SmartTVConfig{LockCheckSeconds: -1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "familylink without api key",
+			config: Config{
+				Server:     ServerConfig{Port: 8080},
+				Database:   DatabaseConfig{Path: "/path/to/db"},
+				Security:   SecurityConfig{APIKey: "test-key"},
+				Aqara:      AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				FamilyLink: &FamilyLinkConfig{Cookies: "SID=a; SAPISID=b"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid steam",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				Steam: &SteamConfig{APIKey: "steam-key", Accounts: []SteamAccountConfig{
+					{SteamID: "76561198000000000", ChildID: "alice", DeviceID: "pc", AutoStartMinutes: 60},
+				}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "steam auto start without device",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				Steam: &SteamConfig{APIKey: "steam-key", Accounts: []SteamAccountConfig{
+					{SteamID: "76561198000000000", ChildID: "alice", AutoStartMinutes: 60},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "steam without accounts",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				Steam:    &SteamConfig{APIKey: "steam-key"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid media servers",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				MediaServers: &MediaServersConfig{WebhookToken: "secret", Profiles: []MediaServerProfileConfig{
+					{Server: "plex", User: "alice", ChildID: "alice", DeviceID: "tv1", AutoStartMinutes: 30},
+					{Server: "jellyfin", User: "bob", ChildID: "bob"},
+				}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "media servers unknown server",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				MediaServers: &MediaServersConfig{WebhookToken: "secret", Profiles: []MediaServerProfileConfig{
+					{Server: "emby", User: "alice", ChildID: "alice"},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "media servers without webhook token",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				MediaServers: &MediaServersConfig{Profiles: []MediaServerProfileConfig{
+					{Server: "plex", User: "alice", ChildID: "alice"},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid web push",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				WebPush:  &WebPushConfig{Subject: "mailto:parent@example.com"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "web push subject not a URL",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				WebPush:  &WebPushConfig{Subject: "parent@example.com"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "web push with only a public key",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				WebPush:  &WebPushConfig{Subject: "https://metron.example.com", VAPIDPublicKey: "BPub"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid usage sources",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				UsageSources: []UsageSourceConfig{
+					{Name: "youtube", Token: "yt-secret", ChildIDs: []string{"alice"}},
+					{Name: "phone", Token: "phone-secret"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "usage source without token",
+			config: Config{
+				Server:       ServerConfig{Port: 8080},
+				Database:     DatabaseConfig{Path: "/path/to/db"},
+				Security:     SecurityConfig{APIKey: "test-key"},
+				Aqara:        AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				UsageSources: []UsageSourceConfig{{Name: "youtube"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "usage sources sharing a token",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				UsageSources: []UsageSourceConfig{
+					{Name: "youtube", Token: "secret"},
+					{Name: "phone", Token: "secret"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid homekit",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				HomeKit: &HomeKitConfig{SetupCode: "031-45-154", Devices: []HomeKitDeviceConfig{
+					{DeviceID: "tv1", ChildIDs: []string{"alice"}, Minutes: 30},
+				}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "homekit trivial setup code",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				HomeKit:  &HomeKitConfig{SetupCode: "123-45-678"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "homekit device without minutes",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				HomeKit: &HomeKitConfig{SetupCode: "031-45-154", Devices: []HomeKitDeviceConfig{
+					{DeviceID: "tv1", ChildIDs: []string{"alice"}},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid limit profiles",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				LimitProfiles: []LimitProfileConfig{
+					{Name: "6-8", MinAge: 6, MaxAge: 8, WeekdayLimit: 45, WeekendLimit: 90},
+					{Name: "9-12", MinAge: 9, MaxAge: 12, WeekdayLimit: 60, WeekendLimit: 120},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "overlapping limit profiles",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				LimitProfiles: []LimitProfileConfig{
+					{Name: "6-8", MinAge: 6, MaxAge: 8, WeekdayLimit: 45, WeekendLimit: 90},
+					{Name: "8-12", MinAge: 8, MaxAge: 12, WeekdayLimit: 60, WeekendLimit: 120},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "limit profile without limits",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				LimitProfiles: []LimitProfileConfig{
+					{Name: "6-8", MinAge: 6, MaxAge: 8, WeekdayLimit: 45},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid usage alerts",
+			config: Config{
+				Server:      ServerConfig{Port: 8080},
+				Database:    DatabaseConfig{Path: "/path/to/db"},
+				Security:    SecurityConfig{APIKey: "test-key"},
+				Aqara:       AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				UsageAlerts: &UsageAlertsConfig{Thresholds: []int{80, 100}, WebhookURL: "https://example.com/hooks/metron"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "usage alert threshold out of range",
+			config: Config{
+				Server:      ServerConfig{Port: 8080},
+				Database:    DatabaseConfig{Path: "/path/to/db"},
+				Security:    SecurityConfig{APIKey: "test-key"},
+				Aqara:       AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				UsageAlerts: &UsageAlertsConfig{Thresholds: []int{80, 120}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate usage alert threshold",
+			config: Config{
+				Server:      ServerConfig{Port: 8080},
+				Database:    DatabaseConfig{Path: "/path/to/db"},
+				Security:    SecurityConfig{APIKey: "test-key"},
+				Aqara:       AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				UsageAlerts: &UsageAlertsConfig{Thresholds: []int{80, 80}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid driver queue",
+			config: Config{
+				Server:      ServerConfig{Port: 8080},
+				Database:    DatabaseConfig{Path: "/path/to/db"},
+				Security:    SecurityConfig{APIKey: "test-key"},
+				Aqara:       AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				DriverQueue: &DriverQueueConfig{Workers: 2},
+			},
+			wantErr: false,
+		},
+		{
+			name: "zero driver timeout",
+			config: Config{
+				Server:         ServerConfig{Port: 8080},
+				Database:       DatabaseConfig{Path: "/path/to/db"},
+				Security:       SecurityConfig{APIKey: "test-key"},
+				Aqara:          AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				DriverTimeouts: map[string]int{"aqara": 0},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid session policy",
+			config: Config{
+				Server:          ServerConfig{Port: 8080},
+				Database:        DatabaseConfig{Path: "/path/to/db"},
+				Security:        SecurityConfig{APIKey: "test-key"},
+				Aqara:           AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				SessionPolicies: []SessionPolicyConfig{{Name: "consoles", DeviceIDs: []string{"ps5"}, MaxSessionMinutes: 45, MinGapMinutes: 30}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "session policy without rules",
+			config: Config{
+				Server:          ServerConfig{Port: 8080},
+				Database:        DatabaseConfig{Path: "/path/to/db"},
+				Security:        SecurityConfig{APIKey: "test-key"},
+				Aqara:           AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				SessionPolicies: []SessionPolicyConfig{{Name: "consoles"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid exempt activities",
+			config: Config{
+				Server:   ServerConfig{Port: 8080},
+				Database: DatabaseConfig{Path: "/path/to/db"},
+				Security: SecurityConfig{APIKey: "test-key"},
+				Aqara:    AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				ExemptActivities: []ExemptActivityConfig{
+					{Name: "homework", DeviceIDs: []string{"pc"}},
+					{Name: "duolingo", DeviceIDs: []string{"ipad"}, DailyMinutes: 20},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "exempt activity with negative budget",
+			config: Config{
+				Server:           ServerConfig{Port: 8080},
+				Database:         DatabaseConfig{Path: "/path/to/db"},
+				Security:         SecurityConfig{APIKey: "test-key"},
+				Aqara:            AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				ExemptActivities: []ExemptActivityConfig{{Name: "homework", DailyMinutes: -1}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "duplicate exempt activities",
+			config: Config{
+				Server:           ServerConfig{Port: 8080},
+				Database:         DatabaseConfig{Path: "/path/to/db"},
+				Security:         SecurityConfig{APIKey: "test-key"},
+				Aqara:            AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				ExemptActivities: []ExemptActivityConfig{{Name: "homework"}, {Name: "Homework"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid family budget",
+			config: Config{
+				Server:       ServerConfig{Port: 8080},
+				Database:     DatabaseConfig{Path: "/path/to/db"},
+				Security:     SecurityConfig{APIKey: "test-key"},
+				Aqara:        AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				FamilyBudget: &FamilyBudgetConfig{Minutes: 180, DeviceTypes: []string{"tv"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "family budget without minutes",
+			config: Config{
+				Server:       ServerConfig{Port: 8080},
+				Database:     DatabaseConfig{Path: "/path/to/db"},
+				Security:     SecurityConfig{APIKey: "test-key"},
+				Aqara:        AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				FamilyBudget: &FamilyBudgetConfig{DeviceTypes: []string{"tv"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid session conflicts",
+			config: Config{
+				Server:           ServerConfig{Port: 8080},
+				Database:         DatabaseConfig{Path: "/path/to/db"},
+				Security:         SecurityConfig{APIKey: "test-key"},
+				Aqara:            AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				SessionConflicts: "queue",
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown session conflicts",
+			config: Config{
+				Server:           ServerConfig{Port: 8080},
+				Database:         DatabaseConfig{Path: "/path/to/db"},
+				Security:         SecurityConfig{APIKey: "test-key"},
+				Aqara:            AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				SessionConflicts: "share",
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid initiator limits",
+			config: Config{
+				Server:          ServerConfig{Port: 8080},
+				Database:        DatabaseConfig{Path: "/path/to/db"},
+				Security:        SecurityConfig{APIKey: "test-key"},
+				Aqara:           AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				InitiatorLimits: map[string]int{"child": 30},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown initiator limit",
+			config: Config{
+				Server:          ServerConfig{Port: 8080},
+				Database:        DatabaseConfig{Path: "/path/to/db"},
+				Security:        SecurityConfig{APIKey: "test-key"},
+				Aqara:           AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				InitiatorLimits: map[string]int{"kid": 30},
+			},
+			wantErr: true,
+		},
+		{
+			name: "short leader lease",
+			config: Config{
+				Server:         ServerConfig{Port: 8080},
+				Database:       DatabaseConfig{Path: "/path/to/db"},
+				Security:       SecurityConfig{APIKey: "test-key"},
+				Aqara:          AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				LeaderElection: &LeaderElectionConfig{LeaseSeconds: 1},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative driver call history",
+			config: Config{
+				Server:            ServerConfig{Port: 8080},
+				Database:          DatabaseConfig{Path: "/path/to/db"},
+				Security:          SecurityConfig{APIKey: "test-key"},
+				Aqara:             AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				DriverCallHistory: -1,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative driver queue workers",
+			config: Config{
+				Server:      ServerConfig{Port: 8080},
+				Database:    DatabaseConfig{Path: "/path/to/db"},
+				Security:    SecurityConfig{APIKey: "test-key"},
+				Aqara:       AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+				DriverQueue: &DriverQueueConfig{Workers: -1},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -188,3 +758,78 @@ func TestLoadFromEnv(t *testing.T) {
 	assert.Equal(t, "env-app-id", config.Aqara.AppID)
 	assert.Equal(t, true, config.Security.EnableIPCheck)
 }
+
+func TestBotConfig_Roles(t *testing.T) {
+	newConfig := func(roles map[int64]string) *BotConfig {
+		return &BotConfig{
+			Server: BotServerConfig{Port: 8081},
+			Telegram: TelegramBotConfig{
+				Token:        "token",
+				AllowedUsers: []int64{1, 2, 3},
+				WebhookURL:   "https://bot.example.com/webhook",
+				Roles:        roles,
+			},
+			Metron: MetronAPIConfig{BaseURL: "http://localhost:8080", APIKey: "key"},
+		}
+	}
+
+	cfg := newConfig(map[int64]string{2: BotRoleOperator, 3: BotRoleViewer})
+	require.NoError(t, cfg.Validate())
+
+	assert.Equal(t, BotRoleAdmin, cfg.UserRole(1), "users without a role are admins")
+	assert.Equal(t, BotRoleOperator, cfg.UserRole(2))
+	assert.Equal(t, BotRoleViewer, cfg.UserRole(3))
+	assert.Equal(t, "", cfg.UserRole(4), "users not allowed have no role")
+
+	assert.True(t, BotRoleAllows(BotRoleAdmin, BotRoleOperator))
+	assert.True(t, BotRoleAllows(BotRoleOperator, BotRoleOperator))
+	assert.False(t, BotRoleAllows(BotRoleViewer, BotRoleOperator))
+	assert.False(t, BotRoleAllows("", BotRoleViewer))
+
+	assert.Error(t, newConfig(map[int64]string{2: "babysitter"}).Validate(), "unknown role")
+	assert.Error(t, newConfig(map[int64]string{4: BotRoleViewer}).Validate(), "role for a user not allowed")
+}
+
+func TestConfig_DriverTimeout(t *testing.T) {
+	cfg := &Config{}
+	assert.Equal(t, 30*time.Second, cfg.DriverTimeout("aqara"))
+
+	cfg.DriverTimeouts = map[string]int{"default": 20, "kidslox": 45}
+	assert.Equal(t, 45*time.Second, cfg.DriverTimeout("kidslox"))
+	assert.Equal(t, 20*time.Second, cfg.DriverTimeout("aqara"))
+}
+
+func TestConfig_Scheduler(t *testing.T) {
+	cfg := &Config{}
+	assert.Equal(t, time.Minute, cfg.SchedulerInterval())
+	assert.Zero(t, cfg.SchedulerJitter())
+
+	cfg.Scheduler = &SchedulerConfig{IntervalSeconds: 30, JitterSeconds: 5}
+	assert.Equal(t, 30*time.Second, cfg.SchedulerInterval())
+	assert.Equal(t, 5*time.Second, cfg.SchedulerJitter())
+
+	newConfig := func(scheduler *SchedulerConfig, tickSeconds int) *Config {
+		return &Config{
+			Server:    ServerConfig{Port: 8080},
+			Database:  DatabaseConfig{Path: "/path/to/db"},
+			Security:  SecurityConfig{APIKey: "test-key"},
+			Aqara:     AqaraConfig{AppID: "app-id", AppKey: "app-key", KeyID: "key-id"},
+			Devices:   []DeviceConfig{{ID: "pc1", Name: "PC", Type: "pc", Driver: "winagent", TickIntervalSeconds: tickSeconds}},
+			Scheduler: scheduler,
+		}
+	}
+
+	assert.NoError(t, newConfig(nil, 15).Validate())
+	assert.NoError(t, newConfig(&SchedulerConfig{IntervalSeconds: 30, JitterSeconds: 10}, 30).Validate())
+	assert.Error(t, newConfig(&SchedulerConfig{IntervalSeconds: -1}, 0).Validate(), "negative interval")
+	assert.Error(t, newConfig(&SchedulerConfig{JitterSeconds: -1}, 0).Validate(), "negative jitter")
+	assert.Error(t, newConfig(&SchedulerConfig{IntervalSeconds: 30, JitterSeconds: 30}, 0).Validate(), "jitter not below the interval")
+	assert.Error(t, newConfig(nil, -5).Validate(), "negative device tick")
+	assert.Error(t, newConfig(&SchedulerConfig{IntervalSeconds: 30}, 45).Validate(), "device tick slower than the interval")
+}
+
+func TestDatabaseConfig_ReadPath(t *testing.T) {
+	assert.Empty(t, DatabaseConfig{Path: "metron.db"}.ReadPath())
+	assert.Equal(t, "metron.db", DatabaseConfig{Path: "metron.db", ReadOnlyConnection: true}.ReadPath())
+	assert.Equal(t, "replica.db", DatabaseConfig{Path: "metron.db", ReadOnlyConnection: true, ReadReplicaPath: "replica.db"}.ReadPath())
+}
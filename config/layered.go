@@ -0,0 +1,136 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// LoadLayered loads the configuration from layers, each overriding the ones before:
+//
+//  1. the configuration file at path, or without a path the defaults of the options
+//     that have environment variables (see Options)
+//  2. the environment variables that are set (METRON_API_KEY, METRON_DB_PATH, ...)
+//  3. overrides of the form "path=value" (e.g., "server.port=9090", from -set flags)
+//
+// so a deployment can keep its file and override just a few options, e.g. the API key
+// from a container secret. The result is validated like Load.
+func LoadLayered(path string, overrides []string) (*Config, error) {
+	config := &Config{}
+	if path != "" {
+		var err error
+		if config, err = readFile(path); err != nil {
+			return nil, err
+		}
+	} else if err := config.applyDefaults(); err != nil {
+		return nil, err
+	}
+
+	if err := config.applyEnv(); err != nil {
+		return nil, err
+	}
+
+	for _, override := range overrides {
+		optionPath, value, ok := strings.Cut(override, "=")
+		if !ok {
+			return nil, fmt.Errorf("%w: override %q is not of the form path=value", ErrInvalidConfig, override)
+		}
+		if err := config.Set(optionPath, value); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// Set sets an option by its path in the reference (e.g., "security.api_key"), creating the
+// optional sections on the way. Only string, number and boolean options can be set.
+func (c *Config) Set(path, value string) error {
+	for _, option := range Options(Config{}) {
+		if option.Path != path {
+			continue
+		}
+		if err := setOption(fieldByIndex(reflect.ValueOf(c).Elem(), option.index), value); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidConfig, path, err)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: unknown option %q (see metron config docs)", ErrInvalidConfig, path)
+}
+
+// applyDefaults sets the options that have environment variables to their defaults
+func (c *Config) applyDefaults() error {
+	for _, option := range Options(Config{}) {
+		if option.Env == "" || option.Default == "" {
+			continue
+		}
+		if err := setOption(fieldByIndex(reflect.ValueOf(c).Elem(), option.index), option.Default); err != nil {
+			return fmt.Errorf("%w: default of %s: %v", ErrInvalidConfig, option.Path, err)
+		}
+	}
+	return nil
+}
+
+// applyEnv sets the options whose environment variables are set (not empty)
+func (c *Config) applyEnv() error {
+	for _, option := range Options(Config{}) {
+		if option.Env == "" {
+			continue
+		}
+		value := os.Getenv(option.Env)
+		if value == "" {
+			continue
+		}
+		if err := setOption(fieldByIndex(reflect.ValueOf(c).Elem(), option.index), value); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalidConfig, option.Env, err)
+		}
+	}
+	return nil
+}
+
+// fieldByIndex returns a nested field like reflect.Value.FieldByIndex, allocating nil sections
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for _, i := range index {
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(i)
+	}
+	return v
+}
+
+// setOption parses a value into a string, number or boolean option
+// Booleans are true for "true" and "1", like the environment variables always were.
+func setOption(field reflect.Value, value string) error {
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		field = field.Elem()
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return fmt.Errorf("invalid number %q", value)
+		}
+		field.SetInt(n)
+	case reflect.Bool:
+		field.SetBool(value == "true" || value == "1")
+	default:
+		return fmt.Errorf("%s options cannot be set, only strings, numbers and booleans", optionType(field.Type()))
+	}
+	return nil
+}
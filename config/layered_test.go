@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeLayeredConfig(t *testing.T) string {
+	configPath := filepath.Join(t.TempDir(), "config.json")
+	err := os.WriteFile(configPath, []byte(`{
+		"server": {"port": 8080, "cache_ttl_seconds": 10},
+		"database": {"path": "/var/lib/metron/metron.db"},
+		"security": {"api_key": "file-key"},
+		"timezone": "Europe/Riga",
+		"aqara": {"app_id": "app-id", "app_key": "app-key", "key_id": "key-id"}
+	}`), 0644)
+	require.NoError(t, err)
+	return configPath
+}
+
+func TestLoadLayered(t *testing.T) {
+	configPath := writeLayeredConfig(t)
+	t.Setenv("METRON_API_KEY", "env-key")
+	t.Setenv("METRON_DB_PATH", "/data/metron.db")
+	t.Setenv("METRON_PORT", "8081")
+
+	config, err := LoadLayered(configPath, []string{"server.port=9090", "kidslox.api_key=kidslox-key", "kidslox.account_id=42"})
+	require.NoError(t, err)
+
+	// Environment variables override the file
+	assert.Equal(t, "env-key", config.Security.APIKey)
+	assert.Equal(t, "/data/metron.db", config.Database.Path)
+	// Overrides win over both
+	assert.Equal(t, 9090, config.Server.Port)
+	// Optional sections are created for their overrides
+	require.NotNil(t, config.Kidslox)
+	assert.Equal(t, "kidslox-key", config.Kidslox.APIKey)
+	// The rest of the file is kept, and unset variables don't reset it to defaults
+	assert.Equal(t, 10, config.Server.CacheTTLSeconds)
+	assert.Equal(t, "Europe/Riga", config.Timezone)
+	assert.Equal(t, "app-id", config.Aqara.AppID)
+}
+
+func TestLoadLayered_WithoutFile(t *testing.T) {
+	t.Setenv("METRON_API_KEY", "env-key")
+	t.Setenv("METRON_AQARA_APP_ID", "app-id")
+	t.Setenv("METRON_AQARA_APP_KEY", "app-key")
+	t.Setenv("METRON_AQARA_KEY_ID", "key-id")
+
+	config, err := LoadLayered("", []string{"database.read_only_connection=true"})
+	require.NoError(t, err)
+	assert.Equal(t, 8080, config.Server.Port, "defaults without a file")
+	assert.Equal(t, "./metron.db", config.Database.Path)
+	assert.True(t, config.Database.ReadOnlyConnection)
+}
+
+func TestLoadLayered_Errors(t *testing.T) {
+	configPath := writeLayeredConfig(t)
+
+	tests := []struct {
+		name     string
+		override string
+	}{
+		{"malformed", "server.port"},
+		{"unknown option", "server.prot=9090"},
+		{"invalid number", "server.port=http"},
+		{"list", "security.allowed_ips=10.0.0.1"},
+		{"section", "server=9090"},
+		{"invalid after overrides", "server.port=0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadLayered(configPath, []string{tt.override})
+			assert.ErrorIs(t, err, ErrInvalidConfig)
+		})
+	}
+
+	t.Run("invalid environment variable", func(t *testing.T) {
+		t.Setenv("METRON_PORT", "eighty")
+		_, err := LoadLayered(configPath, nil)
+		assert.ErrorIs(t, err, ErrInvalidConfig)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadLayered(filepath.Join(t.TempDir(), "missing.json"), nil)
+		assert.ErrorIs(t, err, ErrConfigFileNotFound)
+	})
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// Option is one option of the configuration reference, read from the struct tags of the
+// config types: json (name), doc (description), default and env (read by LoadLayered)
+type Option struct {
+	Path    string // Dotted JSON path, list elements are "[]" (e.g., "devices[].id")
+	Type    string // JSON type (e.g., "string", "integer", "list of strings", "object")
+	Default string // Value used when the option is left out; empty if none
+	Env     string // Environment variable overriding the option; empty if none
+	Doc     string
+
+	index []int // Field index from the root config type (see reflect.Value.FieldByIndex)
+}
+
+// Options lists the options of a config type (e.g., Config{} or BotConfig{}), sections before their options
+func Options(config interface{}) []Option {
+	return appendOptions(nil, reflect.TypeOf(config), "", nil)
+}
+
+// appendOptions appends the options of the fields of a struct type
+func appendOptions(options []Option, t reflect.Type, prefix string, index []int) []Option {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if field.PkgPath != "" || name == "" || name == "-" {
+			continue
+		}
+
+		fieldIndex := append(append([]int(nil), index...), i)
+		path := prefix + name
+		options = append(options, Option{
+			Path:    path,
+			Type:    optionType(field.Type),
+			Default: field.Tag.Get("default"),
+			Env:     field.Tag.Get("env"),
+			Doc:     field.Tag.Get("doc"),
+			index:   fieldIndex,
+		})
+
+		// Options of sections and of list elements follow their parent
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		switch {
+		case fieldType.Kind() == reflect.Struct:
+			options = appendOptions(options, fieldType, path+".", fieldIndex)
+		case fieldType.Kind() == reflect.Slice && fieldType.Elem().Kind() == reflect.Struct:
+			options = appendOptions(options, fieldType.Elem(), path+"[].", fieldIndex)
+		}
+	}
+	return options
+}
+
+// optionType describes a Go type as the JSON it is read from
+func optionType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return optionType(t.Elem())
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "list of " + optionType(t.Elem()) + "s"
+	case reflect.Map:
+		if t.Elem().Kind() == reflect.Interface {
+			return "object"
+		}
+		return "map of " + optionType(t.Key()) + " to " + optionType(t.Elem())
+	case reflect.Interface:
+		return "any"
+	default:
+		return "object"
+	}
+}
+
+// WriteReference writes the Markdown reference of every option of the server (Config) and
+// bot (BotConfig) configuration files
+func WriteReference(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("# Configuration reference\n\n")
+	b.WriteString("Generated by `metron config docs` from the config structs. ")
+	b.WriteString("Environment variables that are set override the config file, and `-set path=value` flags override both.\n")
+
+	writeReferenceSection(&b, "Server (`metron -config config.json`)", Options(Config{}))
+	writeReferenceSection(&b, "Telegram bot (`metron-bot -config bot-config.json`)", Options(BotConfig{}))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writeReferenceSection writes a table of options
+func writeReferenceSection(b *strings.Builder, title string, options []Option) {
+	fmt.Fprintf(b, "\n## %s\n\n", title)
+	b.WriteString("| Option | Type | Default | Env | Description |\n")
+	b.WriteString("|--------|------|---------|-----|-------------|\n")
+	for _, option := range options {
+		fmt.Fprintf(b, "| `%s` | %s | %s | %s | %s |\n",
+			option.Path, option.Type, codeOrEmpty(option.Default), codeOrEmpty(option.Env),
+			strings.ReplaceAll(option.Doc, "|", `\|`))
+	}
+}
+
+// codeOrEmpty formats a table cell as code, leaving empty cells empty
+func codeOrEmpty(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + s + "`"
+}
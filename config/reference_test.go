@@ -0,0 +1,108 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOptions_Documented(t *testing.T) {
+	for _, config := range []interface{}{Config{}, BotConfig{}} {
+		options := Options(config)
+		require.NotEmpty(t, options)
+		for _, option := range options {
+			assert.NotEmpty(t, option.Doc, "%T option %s has no doc tag", config, option.Path)
+		}
+	}
+}
+
+func TestOptions_Paths(t *testing.T) {
+	options := make(map[string]Option)
+	for _, option := range Options(Config{}) {
+		options[option.Path] = option
+	}
+
+	assert.Equal(t, "object", options["server"].Type)
+	assert.Equal(t, Option{Path: "server.port", Type: "integer", Default: "8080", Env: "METRON_PORT",
+		Doc: "Port the API listens on", index: []int{0, 1}}, options["server.port"])
+	assert.Equal(t, "list of objects", options["devices"].Type)
+	assert.Equal(t, "string", options["devices[].id"].Type)
+	assert.Equal(t, "object", options["devices[].parameters"].Type)
+	assert.Equal(t, "list of strings", options["movie_time.allowed_device_ids"].Type)
+	assert.Equal(t, "map of string to integer", options["driver_timeouts"].Type)
+	assert.Equal(t, "string", options["downtime.weekday.start_time"].Type)
+}
+
+func TestLoadFromEnv_Options(t *testing.T) {
+	required := map[string]string{
+		"METRON_API_KEY":       "env-api-key",
+		"METRON_AQARA_APP_ID":  "env-app-id",
+		"METRON_AQARA_APP_KEY": "env-app-key",
+		"METRON_AQARA_KEY_ID":  "env-key-id",
+	}
+	for key, value := range required {
+		t.Setenv(key, value)
+	}
+
+	defaults, err := LoadFromEnv()
+	require.NoError(t, err)
+
+	options := Options(Config{})
+	for _, option := range options {
+		if option.Env == "" {
+			continue
+		}
+		value := fmt.Sprint(reflect.ValueOf(defaults).Elem().FieldByIndex(option.index).Interface())
+		if sample, ok := required[option.Env]; ok {
+			assert.Equal(t, sample, value, option.Path)
+			continue
+		}
+		want := option.Default
+		if want == "" {
+			want = fmt.Sprint(reflect.Zero(reflect.TypeOf(defaults).Elem().FieldByIndex(option.index).Type).Interface())
+		}
+		assert.Equal(t, want, value, "default of %s", option.Path)
+	}
+
+	samples := map[string]string{"string": "env-value", "integer": "4242", "boolean": "true"}
+	for _, option := range options {
+		if option.Env == "" {
+			continue
+		}
+		sample := samples[option.Type]
+		if option.Path == "timezone" {
+			sample = "Europe/Riga"
+		}
+		t.Setenv(option.Env, sample)
+	}
+
+	config, err := LoadFromEnv()
+	require.NoError(t, err)
+	for _, option := range options {
+		if option.Env == "" {
+			continue
+		}
+		sample := samples[option.Type]
+		if option.Path == "timezone" {
+			sample = "Europe/Riga"
+		}
+		value := fmt.Sprint(reflect.ValueOf(config).Elem().FieldByIndex(option.index).Interface())
+		assert.Equal(t, sample, value, "%s is not read from %s", option.Path, option.Env)
+	}
+}
+
+func TestWriteReference(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteReference(&buf))
+	reference := buf.String()
+
+	assert.Contains(t, reference, "## Server")
+	assert.Contains(t, reference, "## Telegram bot")
+	assert.Contains(t, reference, "| `server.port` | integer | `8080` | `METRON_PORT` | Port the API listens on |")
+	assert.Contains(t, reference, "| `metron.retries` | integer | `2` |  | Retries after a transient failure (0 disables) |")
+	assert.Contains(t, reference, "| `steam.accounts[].steam_id` | string |")
+}
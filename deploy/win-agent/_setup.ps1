@@ -197,7 +197,8 @@ try {
 Write-Step "Configuring scheduled task..."
 
 $LogPath = Join-Path $DataDir "agent.log"
-$Arguments = "-device-id `"$DeviceID`" -token `"$Token`" -url `"$URL`" -log-path `"$LogPath`""
+$MarkerPath = Join-Path $DataDir "agent.running"
+$Arguments = "-device-id `"$DeviceID`" -token `"$Token`" -url `"$URL`" -log-path `"$LogPath`" -marker-path `"$MarkerPath`""
 
 # Add optional parameters if specified
 if ($Config["POLL_INTERVAL"]) {
@@ -212,6 +213,9 @@ if ($Config["LOG_LEVEL"]) {
 if ($Config["LOG_FORMAT"]) {
     $Arguments += " -log-format $($Config["LOG_FORMAT"])"
 }
+if ($Config["UPDATE_KEY"]) {
+    $Arguments += " -update-key `"$($Config["UPDATE_KEY"])`""
+}
 
 Write-Info "Executable: $DestBinary"
 Write-Info "Arguments: $Arguments"
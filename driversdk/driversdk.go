@@ -0,0 +1,131 @@
+// Package driversdk is the SDK for driver plugins: device drivers shipped outside the
+// Metron tree. A plugin is an executable that implements Driver and calls Serve. Metron
+// starts it from a manifest in its drivers directory (drivers.d) and calls it with
+// JSON-RPC over the plugin's stdin and stdout, so plugins must log to stderr only.
+//
+// A minimal plugin:
+//
+//	type driver struct{}
+//
+//	func (driver) StartSession(ctx context.Context, device driversdk.Device, session driversdk.Session) error {
+//		return unlock(device.Parameters["host"].(string))
+//	}
+//
+//	func (driver) StopSession(ctx context.Context, device driversdk.Device, session driversdk.Session) error {
+//		return lock(device.Parameters["host"].(string))
+//	}
+//
+//	func main() {
+//		if err := driversdk.Serve(driver{}); err != nil {
+//			log.Fatal(err)
+//		}
+//	}
+//
+// Optional features are enabled by implementing WarningDriver, ExtendableDriver,
+// BreakableDriver, HealthCheckableDriver, LiveStateDriver and ValidatingDriver.
+package driversdk
+
+import (
+	"context"
+	"time"
+)
+
+// ProtocolVersion is the plugin protocol version; Metron refuses plugins built for another one
+const ProtocolVersion = 1
+
+// Session is the session a call is about.
+type Session struct {
+	ID               string    `json:"id"`
+	DeviceID         string    `json:"device_id"`
+	DeviceType       string    `json:"device_type"`
+	ChildIDs         []string  `json:"child_ids"`
+	StartTime        time.Time `json:"start_time"`
+	ExpectedDuration int       `json:"expected_duration"` // Planned length in minutes (including extensions)
+	RemainingMinutes int       `json:"remaining_minutes"`
+}
+
+// Device is a device of the plugin's driver, as configured in Metron.
+type Device struct {
+	ID         string                 `json:"id"`
+	Name       string                 `json:"name"`
+	Type       string                 `json:"type"`
+	Parameters map[string]interface{} `json:"parameters"` // The device's "parameters" from the Metron config
+}
+
+// DeviceState is the live state of a device.
+type DeviceState struct {
+	IsActive bool                   `json:"is_active"`
+	Metadata map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// Driver starts and stops sessions on devices. Every plugin implements it.
+type Driver interface {
+	// StartSession allows use of the device (e.g., unlock it)
+	StartSession(ctx context.Context, device Device, session Session) error
+	// StopSession ends use of the device (e.g., lock it or turn it off)
+	StopSession(ctx context.Context, device Device, session Session) error
+}
+
+// WarningDriver is implemented by plugins that can warn before a session ends.
+// Breaks with the "warn" action also arrive as warnings with 0 minutes remaining.
+type WarningDriver interface {
+	ApplyWarning(ctx context.Context, device Device, session Session, minutesRemaining int) error
+}
+
+// ExtendableDriver is implemented by plugins that act on session extensions.
+type ExtendableDriver interface {
+	ExtendSession(ctx context.Context, device Device, session Session, additionalMinutes int) error
+}
+
+// BreakableDriver is implemented by plugins with a dedicated break action.
+// Without it, breaks with the "break" action are sent as warnings.
+type BreakableDriver interface {
+	StartBreak(ctx context.Context, device Device, session Session, breakMinutes int) error
+	EndBreak(ctx context.Context, device Device, session Session) error
+}
+
+// HealthCheckableDriver is implemented by plugins that can report whether they are
+// ready (e.g., credentials accepted); shown by GET /health/ready and metron doctor.
+type HealthCheckableDriver interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// LiveStateDriver is implemented by plugins that can read a device's current state.
+type LiveStateDriver interface {
+	GetLiveState(ctx context.Context, device Device) (*DeviceState, error)
+}
+
+// ValidatingDriver is implemented by plugins that check device parameters. Metron calls
+// Validate for each of the plugin's devices at startup and refuses to start on errors,
+// so errors should name the parameter and how to fix it.
+type ValidatingDriver interface {
+	Validate(device Device) error
+}
+
+// Capabilities lists the optional interfaces a plugin implements.
+type Capabilities struct {
+	Warnings    bool `json:"warnings"`
+	Extend      bool `json:"extend"`
+	Breaks      bool `json:"breaks"`
+	HealthCheck bool `json:"health_check"`
+	LiveState   bool `json:"live_state"`
+	Validate    bool `json:"validate"`
+}
+
+// CapabilitiesOf reports the optional interfaces a driver implements
+func CapabilitiesOf(driver Driver) Capabilities {
+	_, warnings := driver.(WarningDriver)
+	_, extend := driver.(ExtendableDriver)
+	_, breaks := driver.(BreakableDriver)
+	_, healthCheck := driver.(HealthCheckableDriver)
+	_, liveState := driver.(LiveStateDriver)
+	_, validate := driver.(ValidatingDriver)
+	return Capabilities{
+		Warnings:    warnings,
+		Extend:      extend,
+		Breaks:      breaks,
+		HealthCheck: healthCheck,
+		LiveState:   liveState,
+		Validate:    validate,
+	}
+}
@@ -0,0 +1,169 @@
+package driversdk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"time"
+)
+
+// ServiceName is the JSON-RPC service plugins register; methods are called as "Plugin.<Method>"
+const ServiceName = "Plugin"
+
+// InfoArgs are the arguments of Plugin.Info, the first call Metron makes
+type InfoArgs struct {
+	ProtocolVersion int `json:"protocol_version"` // Version spoken by Metron
+}
+
+// InfoReply describes the plugin
+type InfoReply struct {
+	ProtocolVersion int          `json:"protocol_version"`
+	Capabilities    Capabilities `json:"capabilities"`
+}
+
+// CallArgs are the arguments of all device calls. Minutes is the warning's remaining
+// minutes, the extension's additional minutes or the break's length.
+type CallArgs struct {
+	Device   Device    `json:"device"`
+	Session  Session   `json:"session"`
+	Minutes  int       `json:"minutes,omitempty"`
+	Deadline time.Time `json:"deadline,omitempty"` // When Metron stops waiting for the reply
+}
+
+// LiveStateReply is the reply of Plugin.GetLiveState
+type LiveStateReply struct {
+	State *DeviceState `json:"state"` // nil if unknown
+}
+
+// Empty is the reply of calls without a result
+type Empty struct{}
+
+// errNotSupported is returned for calls to optional interfaces the driver does not implement
+var errNotSupported = errors.New("not supported by this driver")
+
+// Serve answers Metron's calls on stdin and stdout until Metron closes stdin.
+// Calls may arrive concurrently, so the driver must be safe for concurrent use.
+func Serve(driver Driver) error {
+	return ServeConn(driver, stdio{})
+}
+
+// ServeConn answers calls on conn until it is closed (used by Serve and by tests)
+func ServeConn(driver Driver, conn io.ReadWriteCloser) error {
+	server := rpc.NewServer()
+	if err := server.RegisterName(ServiceName, &service{driver: driver}); err != nil {
+		return err
+	}
+	server.ServeCodec(jsonrpc.NewServerCodec(conn))
+	return nil
+}
+
+// stdio joins stdin and stdout into one connection
+type stdio struct{}
+
+func (stdio) Read(p []byte) (int, error)  { return os.Stdin.Read(p) }
+func (stdio) Write(p []byte) (int, error) { return os.Stdout.Write(p) }
+func (stdio) Close() error                { return os.Stdin.Close() }
+
+// service exposes a driver as RPC methods
+type service struct {
+	driver Driver
+}
+
+// callContext returns a context that ends at the call's deadline
+func callContext(args *CallArgs) (context.Context, context.CancelFunc) {
+	if args.Deadline.IsZero() {
+		return context.WithCancel(context.Background())
+	}
+	return context.WithDeadline(context.Background(), args.Deadline)
+}
+
+func (s *service) Info(args *InfoArgs, reply *InfoReply) error {
+	reply.ProtocolVersion = ProtocolVersion
+	reply.Capabilities = CapabilitiesOf(s.driver)
+	return nil
+}
+
+func (s *service) StartSession(args *CallArgs, reply *Empty) error {
+	ctx, cancel := callContext(args)
+	defer cancel()
+	return s.driver.StartSession(ctx, args.Device, args.Session)
+}
+
+func (s *service) StopSession(args *CallArgs, reply *Empty) error {
+	ctx, cancel := callContext(args)
+	defer cancel()
+	return s.driver.StopSession(ctx, args.Device, args.Session)
+}
+
+func (s *service) ApplyWarning(args *CallArgs, reply *Empty) error {
+	driver, ok := s.driver.(WarningDriver)
+	if !ok {
+		return errNotSupported
+	}
+	ctx, cancel := callContext(args)
+	defer cancel()
+	return driver.ApplyWarning(ctx, args.Device, args.Session, args.Minutes)
+}
+
+func (s *service) ExtendSession(args *CallArgs, reply *Empty) error {
+	driver, ok := s.driver.(ExtendableDriver)
+	if !ok {
+		return errNotSupported
+	}
+	ctx, cancel := callContext(args)
+	defer cancel()
+	return driver.ExtendSession(ctx, args.Device, args.Session, args.Minutes)
+}
+
+func (s *service) StartBreak(args *CallArgs, reply *Empty) error {
+	driver, ok := s.driver.(BreakableDriver)
+	if !ok {
+		return errNotSupported
+	}
+	ctx, cancel := callContext(args)
+	defer cancel()
+	return driver.StartBreak(ctx, args.Device, args.Session, args.Minutes)
+}
+
+func (s *service) EndBreak(args *CallArgs, reply *Empty) error {
+	driver, ok := s.driver.(BreakableDriver)
+	if !ok {
+		return errNotSupported
+	}
+	ctx, cancel := callContext(args)
+	defer cancel()
+	return driver.EndBreak(ctx, args.Device, args.Session)
+}
+
+func (s *service) HealthCheck(args *CallArgs, reply *Empty) error {
+	driver, ok := s.driver.(HealthCheckableDriver)
+	if !ok {
+		return errNotSupported
+	}
+	ctx, cancel := callContext(args)
+	defer cancel()
+	return driver.HealthCheck(ctx)
+}
+
+func (s *service) GetLiveState(args *CallArgs, reply *LiveStateReply) error {
+	driver, ok := s.driver.(LiveStateDriver)
+	if !ok {
+		return errNotSupported
+	}
+	ctx, cancel := callContext(args)
+	defer cancel()
+	state, err := driver.GetLiveState(ctx, args.Device)
+	reply.State = state
+	return err
+}
+
+func (s *service) Validate(args *CallArgs, reply *Empty) error {
+	driver, ok := s.driver.(ValidatingDriver)
+	if !ok {
+		return errNotSupported
+	}
+	return driver.Validate(args.Device)
+}
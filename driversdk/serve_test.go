@@ -0,0 +1,68 @@
+package driversdk
+
+import (
+	"context"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lamp starts and stops sessions and reports its deadline.
+type lamp struct {
+	deadline time.Time
+}
+
+func (l *lamp) StartSession(ctx context.Context, device Device, session Session) error {
+	l.deadline, _ = ctx.Deadline()
+	return nil
+}
+
+func (l *lamp) StopSession(ctx context.Context, device Device, session Session) error {
+	return nil
+}
+
+// warningLamp also shows warnings.
+type warningLamp struct {
+	lamp
+}
+
+func (l *warningLamp) ApplyWarning(ctx context.Context, device Device, session Session, minutesRemaining int) error {
+	return nil
+}
+
+func connect(t *testing.T, driver Driver) *rpc.Client {
+	t.Helper()
+	host, plugin := net.Pipe()
+	go ServeConn(driver, plugin)
+	client := jsonrpc.NewClient(host)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestServe_Info(t *testing.T) {
+	var reply InfoReply
+	require.NoError(t, connect(t, &warningLamp{}).Call("Plugin.Info", &InfoArgs{ProtocolVersion: ProtocolVersion}, &reply))
+
+	assert.Equal(t, ProtocolVersion, reply.ProtocolVersion)
+	assert.Equal(t, Capabilities{Warnings: true}, reply.Capabilities)
+}
+
+func TestServe_Deadline(t *testing.T) {
+	driver := &lamp{}
+	deadline := time.Now().Add(time.Minute).Truncate(time.Second)
+	args := &CallArgs{Device: Device{ID: "lamp"}, Session: Session{ID: "ses_1"}, Deadline: deadline}
+
+	require.NoError(t, connect(t, driver).Call("Plugin.StartSession", args, &Empty{}))
+	assert.True(t, deadline.Equal(driver.deadline))
+}
+
+func TestServe_NotSupported(t *testing.T) {
+	err := connect(t, &lamp{}).Call("Plugin.ApplyWarning", &CallArgs{Minutes: 5}, &Empty{})
+	require.Error(t, err)
+	assert.Equal(t, errNotSupported.Error(), err.Error())
+}
@@ -0,0 +1,225 @@
+// Package adb implements the client side of the Android Debug Bridge protocol over TCP
+// (network debugging enabled with "adb tcpip"), enough to run shell commands on Android
+// devices without the adb tool. Connections authenticate with an RSA key like adb does;
+// the device asks to allow the key the first time it is used.
+package adb
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// DefaultPort is the port devices listen on after "adb tcpip 5555"
+const DefaultPort = 5555
+
+// Protocol commands
+const (
+	cmdCNXN = 0x4e584e43
+	cmdAUTH = 0x48545541
+	cmdOPEN = 0x4e45504f
+	cmdOKAY = 0x59414b4f
+	cmdCLSE = 0x45534c43
+	cmdWRTE = 0x45545257
+)
+
+// AUTH message types
+const (
+	authToken        = 1
+	authSignature    = 2
+	authRSAPublicKey = 3
+)
+
+const (
+	protocolVersion = 0x01000000
+	maxData         = 256 * 1024
+	localID         = 1
+)
+
+// defaultTimeout bounds calls whose context has no deadline
+const defaultTimeout = 10 * time.Second
+
+// ErrUnauthorized is returned when the device has not allowed the key yet.
+var ErrUnauthorized = errors.New("device has not allowed this key: accept the debugging prompt on the device")
+
+// Client runs shell commands on one device.
+type Client struct {
+	addr string
+	key  *rsa.PrivateKey
+	name string // Shown on the device's debugging prompt
+}
+
+// NewClient creates a client for the device at addr (host:port).
+func NewClient(addr string, key *rsa.PrivateKey, name string) *Client {
+	return &Client{addr: addr, key: key, name: name}
+}
+
+// message is one protocol packet
+type message struct {
+	command uint32
+	arg0    uint32
+	arg1    uint32
+	data    []byte
+}
+
+// Shell runs a command with "sh -c" semantics and returns its output.
+// A connection is opened per call.
+func (c *Client) Shell(ctx context.Context, command string) (string, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultTimeout)
+	}
+
+	dialer := net.Dialer{Deadline: deadline}
+	conn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to %s: %w", c.addr, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return "", err
+	}
+
+	if err := c.handshake(conn); err != nil {
+		return "", err
+	}
+	return c.shell(conn, command)
+}
+
+// handshake sends CNXN and answers AUTH challenges until the device accepts the connection
+func (c *Client) handshake(conn net.Conn) error {
+	if err := writeMessage(conn, message{cmdCNXN, protocolVersion, maxData, []byte("host::\x00")}); err != nil {
+		return err
+	}
+
+	signed := false
+	for {
+		msg, err := readMessage(conn)
+		if err != nil {
+			var netErr net.Error
+			if signed && errors.As(err, &netErr) && netErr.Timeout() {
+				return ErrUnauthorized
+			}
+			return err
+		}
+
+		switch msg.command {
+		case cmdCNXN:
+			return nil
+		case cmdAUTH:
+			if msg.arg0 != authToken {
+				return fmt.Errorf("unexpected AUTH type %d", msg.arg0)
+			}
+			if !signed {
+				// The token is signed as a SHA-1 digest, as adb does
+				signature, err := rsa.SignPKCS1v15(nil, c.key, crypto.SHA1, msg.data)
+				if err != nil {
+					return fmt.Errorf("failed to sign auth token: %w", err)
+				}
+				if err := writeMessage(conn, message{cmdAUTH, authSignature, 0, signature}); err != nil {
+					return err
+				}
+				signed = true
+				continue
+			}
+
+			// Signature rejected: offer the public key, the device shows a prompt
+			publicKey, err := PublicKey(&c.key.PublicKey)
+			if err != nil {
+				return err
+			}
+			data := append([]byte(publicKey+" "+c.name), 0)
+			if err := writeMessage(conn, message{cmdAUTH, authRSAPublicKey, 0, data}); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("unexpected message %08x during handshake", msg.command)
+		}
+	}
+}
+
+// shell opens a shell stream and collects its output until the device closes it
+func (c *Client) shell(conn net.Conn, command string) (string, error) {
+	if err := writeMessage(conn, message{cmdOPEN, localID, 0, []byte("shell:" + command + "\x00")}); err != nil {
+		return "", err
+	}
+
+	var output bytes.Buffer
+	for {
+		msg, err := readMessage(conn)
+		if err != nil {
+			return "", err
+		}
+
+		switch msg.command {
+		case cmdOKAY:
+			// Stream opened (or write acknowledged)
+		case cmdWRTE:
+			output.Write(msg.data)
+			if err := writeMessage(conn, message{cmdOKAY, localID, msg.arg0, nil}); err != nil {
+				return "", err
+			}
+		case cmdCLSE:
+			if msg.arg0 == 0 {
+				return "", fmt.Errorf("device refused to open shell")
+			}
+			writeMessage(conn, message{cmdCLSE, localID, msg.arg0, nil})
+			return output.String(), nil
+		default:
+			return "", fmt.Errorf("unexpected message %08x from shell", msg.command)
+		}
+	}
+}
+
+func writeMessage(w io.Writer, msg message) error {
+	var checksum uint32
+	for _, b := range msg.data {
+		checksum += uint32(b)
+	}
+
+	header := make([]byte, 24)
+	binary.LittleEndian.PutUint32(header[0:], msg.command)
+	binary.LittleEndian.PutUint32(header[4:], msg.arg0)
+	binary.LittleEndian.PutUint32(header[8:], msg.arg1)
+	binary.LittleEndian.PutUint32(header[12:], uint32(len(msg.data)))
+	binary.LittleEndian.PutUint32(header[16:], checksum)
+	binary.LittleEndian.PutUint32(header[20:], msg.command^0xffffffff)
+
+	if _, err := w.Write(append(header, msg.data...)); err != nil {
+		return fmt.Errorf("failed to write adb message: %w", err)
+	}
+	return nil
+}
+
+func readMessage(r io.Reader) (message, error) {
+	header := make([]byte, 24)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return message{}, fmt.Errorf("failed to read adb message: %w", err)
+	}
+
+	msg := message{
+		command: binary.LittleEndian.Uint32(header[0:]),
+		arg0:    binary.LittleEndian.Uint32(header[4:]),
+		arg1:    binary.LittleEndian.Uint32(header[8:]),
+	}
+	length := binary.LittleEndian.Uint32(header[12:])
+	if binary.LittleEndian.Uint32(header[20:]) != msg.command^0xffffffff {
+		return message{}, fmt.Errorf("invalid adb message magic")
+	}
+	if length > maxData {
+		return message{}, fmt.Errorf("adb message too large (%d bytes)", length)
+	}
+
+	msg.data = make([]byte, length)
+	if _, err := io.ReadFull(r, msg.data); err != nil {
+		return message{}, fmt.Errorf("failed to read adb message: %w", err)
+	}
+	return msg, nil
+}
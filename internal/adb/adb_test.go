@@ -0,0 +1,170 @@
+package adb
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/binary"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testKey is shared because generating 2048-bit keys is slow
+var testKey = func() *rsa.PrivateKey {
+	key, err := GenerateKey()
+	if err != nil {
+		panic(err)
+	}
+	return key
+}()
+
+// fakeDevice accepts adb connections, authenticates one public key and answers shell commands.
+type fakeDevice struct {
+	allowed    *rsa.PublicKey
+	output     string
+	commands   chan string
+	offeredKey chan string
+}
+
+func newFakeDevice(t *testing.T, allowed *rsa.PublicKey, output string) (*fakeDevice, string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	device := &fakeDevice{allowed: allowed, output: output, commands: make(chan string, 1), offeredKey: make(chan string, 1)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go device.serve(conn)
+		}
+	}()
+	return device, listener.Addr().String()
+}
+
+func (d *fakeDevice) serve(conn net.Conn) {
+	defer conn.Close()
+
+	if msg, err := readMessage(conn); err != nil || msg.command != cmdCNXN {
+		return
+	}
+
+	token := make([]byte, 20)
+	rand.Read(token)
+	writeMessage(conn, message{cmdAUTH, authToken, 0, token})
+
+	for authenticated := false; !authenticated; {
+		msg, err := readMessage(conn)
+		if err != nil {
+			return
+		}
+		switch msg.arg0 {
+		case authSignature:
+			if d.allowed != nil && rsa.VerifyPKCS1v15(d.allowed, crypto.SHA1, token, msg.data) == nil {
+				authenticated = true
+			} else {
+				writeMessage(conn, message{cmdAUTH, authToken, 0, token})
+			}
+		case authRSAPublicKey:
+			// The prompt is shown; nobody accepts it
+			d.offeredKey <- strings.TrimRight(string(msg.data), "\x00")
+			time.Sleep(time.Second)
+			return
+		}
+	}
+	writeMessage(conn, message{cmdCNXN, protocolVersion, maxData, []byte("device::ro.product.name=atv;\x00")})
+
+	open, err := readMessage(conn)
+	if err != nil || open.command != cmdOPEN {
+		return
+	}
+	d.commands <- strings.TrimSuffix(string(open.data), "\x00")
+
+	const remoteID = 7
+	writeMessage(conn, message{cmdOKAY, remoteID, open.arg0, nil})
+	writeMessage(conn, message{cmdWRTE, remoteID, open.arg0, []byte(d.output)})
+	if msg, err := readMessage(conn); err != nil || msg.command != cmdOKAY {
+		return
+	}
+	writeMessage(conn, message{cmdCLSE, remoteID, open.arg0, nil})
+	readMessage(conn)
+}
+
+func TestClient_Shell(t *testing.T) {
+	device, addr := newFakeDevice(t, &testKey.PublicKey, "mScreenState=ON\n")
+	client := NewClient(addr, testKey, "metron@test")
+
+	output, err := client.Shell(context.Background(), "dumpsys power | grep mScreenState")
+	require.NoError(t, err)
+	assert.Equal(t, "mScreenState=ON\n", output)
+	assert.Equal(t, "shell:dumpsys power | grep mScreenState", <-device.commands)
+}
+
+func TestClient_UnknownKeyOffersPublicKey(t *testing.T) {
+	device, addr := newFakeDevice(t, nil, "")
+	client := NewClient(addr, testKey, "metron@test")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	_, err := client.Shell(ctx, "true")
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	publicKey, err := PublicKey(&testKey.PublicKey)
+	require.NoError(t, err)
+	assert.Equal(t, publicKey+" metron@test", <-device.offeredKey)
+}
+
+func TestPublicKey(t *testing.T) {
+	encoded, err := PublicKey(&testKey.PublicKey)
+	require.NoError(t, err)
+
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	require.NoError(t, err)
+	require.Len(t, raw, 4*(3+2*64))
+	assert.Equal(t, uint32(64), binary.LittleEndian.Uint32(raw[0:]))
+
+	// n0inv * n[0] = -1 mod 2^32
+	n0inv := binary.LittleEndian.Uint32(raw[4:])
+	n0 := binary.LittleEndian.Uint32(raw[8:])
+	assert.Equal(t, uint32(0xffffffff), n0inv*n0)
+
+	// Modulus words are little-endian, least significant first
+	modulus := new(big.Int)
+	for i := 63; i >= 0; i-- {
+		modulus.Lsh(modulus, 32)
+		modulus.Or(modulus, big.NewInt(int64(binary.LittleEndian.Uint32(raw[8+i*4:]))))
+	}
+	assert.Equal(t, 0, modulus.Cmp(testKey.N))
+	assert.Equal(t, uint32(testKey.E), binary.LittleEndian.Uint32(raw[len(raw)-4:]))
+}
+
+func TestLoadKey(t *testing.T) {
+	encoded, err := EncodeKey(testKey)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "adbkey")
+	require.NoError(t, os.WriteFile(path, encoded, 0600))
+
+	key, err := LoadKey(path)
+	require.NoError(t, err)
+	assert.True(t, key.Equal(testKey))
+
+	require.NoError(t, os.WriteFile(path, []byte("not a key"), 0600))
+	_, err = LoadKey(path)
+	assert.Error(t, err)
+}
@@ -0,0 +1,101 @@
+package adb
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"os"
+)
+
+// keyBits is the only RSA key size adb accepts
+const keyBits = 2048
+
+// GenerateKey creates a new adb key.
+func GenerateKey() (*rsa.PrivateKey, error) {
+	return rsa.GenerateKey(rand.Reader, keyBits)
+}
+
+// EncodeKey returns the key as PEM (PKCS#8), the format of ~/.android/adbkey.
+func EncodeKey(key *rsa.PrivateKey) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+// LoadKey reads a PEM RSA private key (PKCS#8 or PKCS#1), such as ~/.android/adbkey.
+func LoadKey(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read adb key: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("adb key %s is not PEM", path)
+	}
+
+	var key *rsa.PrivateKey
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, parseErr := x509.ParsePKCS8PrivateKey(block.Bytes)
+		err = parseErr
+		if rsaKey, ok := parsed.(*rsa.PrivateKey); ok {
+			key = rsaKey
+		} else if err == nil {
+			err = fmt.Errorf("not an RSA key")
+		}
+	default:
+		err = fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid adb key %s: %w", path, err)
+	}
+	if key.N.BitLen() != keyBits {
+		return nil, fmt.Errorf("invalid adb key %s: must be %d-bit RSA", path, keyBits)
+	}
+	return key, nil
+}
+
+// PublicKey encodes the key in the Android format sent to devices (and stored in
+// ~/.android/adbkey.pub): modulus words, Montgomery constants and exponent, base64.
+func PublicKey(key *rsa.PublicKey) (string, error) {
+	if key.N.BitLen() != keyBits {
+		return "", fmt.Errorf("adb keys must be %d-bit RSA", keyBits)
+	}
+	const words = keyBits / 32
+
+	r32 := new(big.Int).Lsh(big.NewInt(1), 32)
+	n0 := new(big.Int).Mod(key.N, r32)
+	n0inv := new(big.Int).ModInverse(n0, r32)
+	n0inv.Sub(r32, n0inv) // -1/n[0] mod 2^32
+
+	rr := new(big.Int).Lsh(big.NewInt(1), keyBits*2)
+	rr.Mod(rr, key.N)
+
+	buf := make([]byte, 0, 4*(3+2*words))
+	buf = binary.LittleEndian.AppendUint32(buf, words)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(n0inv.Uint64()))
+	buf = appendWords(buf, key.N, words)
+	buf = appendWords(buf, rr, words)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(key.E))
+
+	return base64.StdEncoding.EncodeToString(buf), nil
+}
+
+// appendWords appends n as little-endian 32-bit words, least significant first
+func appendWords(buf []byte, n *big.Int, words int) []byte {
+	bytes := n.FillBytes(make([]byte, words*4)) // big-endian
+	for i := words - 1; i >= 0; i-- {
+		buf = binary.LittleEndian.AppendUint32(buf, binary.BigEndian.Uint32(bytes[i*4:]))
+	}
+	return buf
+}
@@ -0,0 +1,85 @@
+package agentupdate
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	publicKey, privateKey, err := GenerateKey()
+	require.NoError(t, err)
+	public, err := ParsePublicKey(publicKey)
+	require.NoError(t, err)
+	private, err := ParsePrivateKey(privateKey)
+	require.NoError(t, err)
+
+	binary := []byte("agent binary v1.4.0")
+	release, err := Sign(private, "1.4.0", "metron-win-agent.exe", binary)
+	require.NoError(t, err)
+	assert.Equal(t, int64(len(binary)), release.Size)
+	require.NoError(t, Verify(public, release, binary))
+
+	// A modified binary fails the checksum
+	assert.ErrorIs(t, Verify(public, release, []byte("agent binary v1.4.0!")), ErrChecksumMismatch)
+
+	// A signed binary cannot be relabelled as another version
+	relabelled := *release
+	relabelled.Version = "9.0.0"
+	assert.ErrorIs(t, Verify(public, &relabelled, binary), ErrBadSignature)
+
+	// Another key's signature is rejected
+	otherKey, _, err := GenerateKey()
+	require.NoError(t, err)
+	other, err := ParsePublicKey(otherKey)
+	require.NoError(t, err)
+	assert.ErrorIs(t, Verify(other, release, binary), ErrBadSignature)
+
+	_, err = Sign(private, "latest", "metron-win-agent.exe", binary)
+	assert.ErrorIs(t, err, ErrInvalidVersion)
+}
+
+func TestWriteAndLoadRelease(t *testing.T) {
+	_, privateKey, err := GenerateKey()
+	require.NoError(t, err)
+	private, err := ParsePrivateKey(privateKey)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	_, err = LoadRelease(dir)
+	assert.True(t, errors.Is(err, os.ErrNotExist))
+
+	binary := []byte("agent binary")
+	release, err := Sign(private, "1.0.1", "metron-win-agent.exe", binary)
+	require.NoError(t, err)
+	require.NoError(t, WriteRelease(dir, release, binary))
+
+	loaded, err := LoadRelease(dir)
+	require.NoError(t, err)
+	assert.Equal(t, release, loaded)
+
+	stored, err := os.ReadFile(dir + "/metron-win-agent.exe")
+	require.NoError(t, err)
+	assert.Equal(t, binary, stored)
+}
+
+func TestIsNewer(t *testing.T) {
+	tests := []struct {
+		candidate, current string
+		want               bool
+	}{
+		{"1.4.0", "1.3.9", true},
+		{"v1.10.0", "1.9.0", true},
+		{"2.0.0", "1.99.99", true},
+		{"1.4.0", "1.4.0", false},
+		{"1.3.0", "1.4.0", false},
+		{"1.4.0", "dev", false},
+		{"latest", "1.0.0", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, IsNewer(tt.candidate, tt.current), "%s vs %s", tt.candidate, tt.current)
+	}
+}
@@ -0,0 +1,270 @@
+// Package apierror is the catalog of machine-readable error codes returned by the API.
+// Every error body has the form {"error": "...", "code": "..."}; the code determines the HTTP status.
+package apierror
+
+import (
+	"net/http"
+	"sort"
+)
+
+// Code is a machine-readable error code
+type Code string
+
+// General errors
+const (
+	InternalError         Code = "INTERNAL_ERROR"
+	InvalidRequest        Code = "INVALID_REQUEST"
+	InvalidContentType    Code = "INVALID_CONTENT_TYPE"
+	ValidationError       Code = "VALIDATION_ERROR"
+	NotFound              Code = "NOT_FOUND"
+	Forbidden             Code = "FORBIDDEN"
+	InvalidAction         Code = "INVALID_ACTION"
+	InvalidMinutes        Code = "INVALID_MINUTES"
+	InvalidChildIDs       Code = "INVALID_CHILD_IDS"
+	InvalidDevice         Code = "INVALID_DEVICE"
+	InvalidDate           Code = "INVALID_DATE"
+	InvalidDateFormat     Code = "INVALID_DATE_FORMAT"
+	InvalidDateRange      Code = "INVALID_DATE_RANGE"
+	DeviceIDRequired      Code = "DEVICE_ID_REQUIRED"
+	SkipDowntimeError     Code = "SKIP_DOWNTIME_ERROR"
+	InvalidID             Code = "INVALID_ID"
+	RequestTooLarge       Code = "REQUEST_TOO_LARGE"
+	RateLimited           Code = "RATE_LIMITED"
+	UnsupportedAPIVersion Code = "UNSUPPORTED_API_VERSION"
+	RouteSunset           Code = "ROUTE_SUNSET"
+)
+
+// Authentication errors
+const (
+	Unauthorized        Code = "UNAUTHORIZED"
+	AuthRequired        Code = "AUTH_REQUIRED"
+	InvalidAuthScheme   Code = "INVALID_AUTH_SCHEME"
+	InvalidToken        Code = "INVALID_TOKEN"
+	TokenRequired       Code = "TOKEN_REQUIRED"
+	InvalidCredentials  Code = "INVALID_CREDENTIALS"
+	MissingSession      Code = "MISSING_SESSION"
+	InvalidSession      Code = "INVALID_SESSION"
+	InvalidLinkCode     Code = "INVALID_LINK_CODE"
+	AgentDisabled       Code = "AGENT_DISABLED"
+	DeviceNotAuthorized Code = "DEVICE_NOT_AUTHORIZED"
+)
+
+// Child and session errors
+const (
+	ChildNotFound        Code = "CHILD_NOT_FOUND"
+	ChildExists          Code = "CHILD_EXISTS"
+	SessionNotFound      Code = "SESSION_NOT_FOUND"
+	SessionNotActive     Code = "SESSION_NOT_ACTIVE"
+	InsufficientTime     Code = "INSUFFICIENT_TIME"
+	ExtensionTooSoon     Code = "EXTENSION_TOO_SOON"
+	DowntimeActive       Code = "DOWNTIME_ACTIVE"
+	DeviceNotAllowed     Code = "DEVICE_NOT_ALLOWED"
+	SessionCreateFailed  Code = "SESSION_CREATE_FAILED"
+	SessionExtendFailed  Code = "SESSION_EXTEND_FAILED"
+	SessionStopFailed    Code = "SESSION_STOP_FAILED"
+	AddChildrenFailed    Code = "ADD_CHILDREN_FAILED"
+	ChildNotInSession    Code = "CHILD_NOT_IN_SESSION"
+	LastChildInSession   Code = "LAST_CHILD_IN_SESSION"
+	RemoveChildrenFailed Code = "REMOVE_CHILDREN_FAILED"
+	SessionBusy          Code = "SESSION_BUSY"
+	InitiatorLimit       Code = "INITIATOR_LIMIT"
+	PolicyBlocked        Code = "POLICY_BLOCKED"
+	FamilyBudgetUsedUp   Code = "FAMILY_BUDGET_USED_UP"
+	ActivityBudgetUsedUp Code = "ACTIVITY_BUDGET_USED_UP"
+	DeviceBusy           Code = "DEVICE_BUSY"
+	QueuedStartNotFound  Code = "QUEUED_START_NOT_FOUND"
+	NoMediaPlaying       Code = "NO_MEDIA_PLAYING"
+	MediaEndsInTime      Code = "MEDIA_ENDS_IN_TIME"
+)
+
+// Movie time errors
+const (
+	MovieTimeDisabled    Code = "MOVIE_TIME_DISABLED"
+	NotWeekend           Code = "NOT_WEEKEND"
+	AlreadyUsed          Code = "ALREADY_USED"
+	BreakNotMet          Code = "BREAK_NOT_MET"
+	MovieSessionActive   Code = "MOVIE_SESSION_ACTIVE"
+	MovieTimeStartFailed Code = "MOVIE_TIME_START_FAILED"
+)
+
+// Lockdown errors
+const (
+	LockdownActive    Code = "LOCKDOWN_ACTIVE"
+	LockdownNotActive Code = "LOCKDOWN_NOT_ACTIVE"
+)
+
+// Tracking pause (vacation mode) errors
+const (
+	TrackingAlreadyPaused Code = "TRACKING_ALREADY_PAUSED"
+	TrackingNotPaused     Code = "TRACKING_NOT_PAUSED"
+	InvalidResumeTime     Code = "INVALID_RESUME_TIME"
+)
+
+// Limit change errors
+const (
+	LimitChangeNotFound Code = "LIMIT_CHANGE_NOT_FOUND"
+	LimitChangeInPast   Code = "LIMIT_CHANGE_IN_PAST"
+	LimitChangeApplied  Code = "LIMIT_CHANGE_APPLIED"
+)
+
+// Limit profile errors
+const (
+	ProfileTransitionNotFound Code = "PROFILE_TRANSITION_NOT_FOUND"
+	ProfileTransitionResolved Code = "PROFILE_TRANSITION_RESOLVED"
+)
+
+// Agent token errors
+const (
+	AgentTokenNotFound Code = "AGENT_TOKEN_NOT_FOUND"
+	AgentTokenRevoked  Code = "AGENT_TOKEN_REVOKED"
+)
+
+// Child login errors
+const (
+	ChildLoginNotFound Code = "CHILD_LOGIN_NOT_FOUND"
+	ChildLoginRevoked  Code = "CHILD_LOGIN_REVOKED"
+)
+
+// Downtime override errors
+const (
+	DowntimeOverrideNotFound  Code = "DOWNTIME_OVERRIDE_NOT_FOUND"
+	DowntimeOverrideEnded     Code = "DOWNTIME_OVERRIDE_ENDED"
+	DowntimeAlreadyOverridden Code = "DOWNTIME_ALREADY_OVERRIDDEN"
+)
+
+// Push subscription errors
+const (
+	PushSubscriptionNotFound Code = "PUSH_SUBSCRIPTION_NOT_FOUND"
+)
+
+// Definition describes an error code and the HTTP status it is returned with
+type Definition struct {
+	Code        Code   `json:"code"`
+	Status      int    `json:"status"`
+	Description string `json:"description"`
+}
+
+var definitions = []Definition{
+	{InternalError, http.StatusInternalServerError, "Unexpected server error"},
+	{InvalidRequest, http.StatusBadRequest, "Malformed request body or parameters"},
+	{InvalidContentType, http.StatusUnsupportedMediaType, "POST/PATCH requests must use application/json"},
+	{ValidationError, http.StatusBadRequest, "Request is well-formed but fails validation"},
+	{NotFound, http.StatusNotFound, "Requested resource does not exist"},
+	{Forbidden, http.StatusForbidden, "Caller is not allowed to act on this resource"},
+	{InvalidAction, http.StatusBadRequest, "Unknown action specified"},
+	{InvalidMinutes, http.StatusBadRequest, "Minutes must be positive"},
+	{InvalidChildIDs, http.StatusBadRequest, "Child ID list is empty or invalid"},
+	{InvalidDevice, http.StatusBadRequest, "Device is unknown or not allowed for this operation"},
+	{InvalidDate, http.StatusBadRequest, "Date is invalid"},
+	{InvalidDateFormat, http.StatusBadRequest, "Date must use the YYYY-MM-DD format"},
+	{InvalidDateRange, http.StatusBadRequest, "Date range is invalid"},
+	{DeviceIDRequired, http.StatusBadRequest, "Missing device_id parameter"},
+	{SkipDowntimeError, http.StatusInternalServerError, "Failed to skip downtime for today"},
+	{InvalidID, http.StatusBadRequest, "ID in the path or query is too long or has invalid characters"},
+	{RequestTooLarge, http.StatusRequestEntityTooLarge, "Request body exceeds the size limit"},
+	{RateLimited, http.StatusTooManyRequests, "Too many requests to a rate-limited endpoint; retry after Retry-After seconds"},
+	{UnsupportedAPIVersion, http.StatusBadRequest, "X-Metron-API-Version names a version the server does not serve"},
+	{RouteSunset, http.StatusGone, "Unversioned route is past its sunset date; use the /api/v1 route in the Link header"},
+
+	{Unauthorized, http.StatusUnauthorized, "Missing or invalid API key"},
+	{AuthRequired, http.StatusUnauthorized, "Authorization header required"},
+	{InvalidAuthScheme, http.StatusUnauthorized, "Authorization header must use the Bearer scheme"},
+	{InvalidToken, http.StatusUnauthorized, "Token is invalid"},
+	{TokenRequired, http.StatusUnauthorized, "Token is required"},
+	{InvalidCredentials, http.StatusUnauthorized, "Child name or PIN is incorrect"},
+	{MissingSession, http.StatusUnauthorized, "Child session token is missing"},
+	{InvalidSession, http.StatusUnauthorized, "Child session token is invalid or expired"},
+	{InvalidLinkCode, http.StatusUnauthorized, "Child login link code is invalid, already used or expired"},
+	{AgentDisabled, http.StatusForbidden, "Agent token is disabled"},
+	{DeviceNotAuthorized, http.StatusForbidden, "Agent is not authorized for the requested device"},
+
+	{ChildNotFound, http.StatusNotFound, "Child ID does not exist"},
+	{ChildExists, http.StatusConflict, "Child ID already exists"},
+	{SessionNotFound, http.StatusNotFound, "Session ID does not exist"},
+	{SessionNotActive, http.StatusBadRequest, "Session is no longer active"},
+	{InsufficientTime, http.StatusBadRequest, "Child has no remaining time today (details describe the child)"},
+	{ExtensionTooSoon, http.StatusTooManyRequests, "Session was extended less than 30 seconds ago"},
+	{DowntimeActive, http.StatusForbidden, "Child is in downtime"},
+	{DeviceNotAllowed, http.StatusForbidden, "Child is not allowed to use the device"},
+	{SessionCreateFailed, http.StatusBadRequest, "Session could not be started"},
+	{SessionExtendFailed, http.StatusBadRequest, "Session could not be extended"},
+	{SessionStopFailed, http.StatusBadRequest, "Session could not be stopped"},
+	{AddChildrenFailed, http.StatusBadRequest, "Children could not be added to the session"},
+	{ChildNotInSession, http.StatusBadRequest, "Child is not in the session"},
+	{LastChildInSession, http.StatusConflict, "The last child cannot be removed; stop the session instead"},
+	{RemoveChildrenFailed, http.StatusBadRequest, "Children could not be removed from the session"},
+	{SessionBusy, http.StatusConflict, "Session is being changed by another process; retry"},
+	{InitiatorLimit, http.StatusConflict, "Session already runs as long as sessions started this way may (initiator_limits)"},
+	{PolicyBlocked, http.StatusForbidden, "A session policy blocks the request (details name the policy and rule)"},
+	{FamilyBudgetUsedUp, http.StatusBadRequest, "The family's shared screen time budget is used up for today"},
+	{ActivityBudgetUsedUp, http.StatusBadRequest, "The child used the exempt activity's daily budget"},
+	{DeviceBusy, http.StatusConflict, "Device is in use by another session (details say until when)"},
+	{QueuedStartNotFound, http.StatusNotFound, "Queued session start not found"},
+	{NoMediaPlaying, http.StatusConflict, "No media is known to be playing on the device"},
+	{MediaEndsInTime, http.StatusConflict, "The current media ends before the session does; no extension is needed"},
+
+	{MovieTimeDisabled, http.StatusNotFound, "Movie time feature is not enabled"},
+	{NotWeekend, http.StatusBadRequest, "Movie time is only available on weekends"},
+	{AlreadyUsed, http.StatusBadRequest, "Movie time was already used today"},
+	{BreakNotMet, http.StatusBadRequest, "Break period after the last personal session has not passed"},
+	{MovieSessionActive, http.StatusConflict, "A movie session is already active"},
+	{MovieTimeStartFailed, http.StatusBadRequest, "Movie time could not be started"},
+
+	{LockdownActive, http.StatusLocked, "Lockdown is active; sessions cannot be started or extended"},
+	{LockdownNotActive, http.StatusConflict, "No lockdown is active"},
+
+	{TrackingAlreadyPaused, http.StatusConflict, "Tracking is already paused for this child or globally"},
+	{TrackingNotPaused, http.StatusConflict, "Tracking is not paused for this child or globally"},
+	{InvalidResumeTime, http.StatusBadRequest, "Resume time must be in the future"},
+
+	{LimitChangeNotFound, http.StatusNotFound, "Limit change ID does not exist for this child"},
+	{LimitChangeInPast, http.StatusBadRequest, "Limit change cannot take effect before today"},
+	{LimitChangeApplied, http.StatusConflict, "Limit change has already taken effect"},
+
+	{ProfileTransitionNotFound, http.StatusNotFound, "Profile transition ID does not exist"},
+	{ProfileTransitionResolved, http.StatusConflict, "Profile transition has already been confirmed or dismissed"},
+
+	{AgentTokenNotFound, http.StatusNotFound, "Agent token ID does not exist"},
+	{AgentTokenRevoked, http.StatusConflict, "Agent token is already revoked"},
+
+	{ChildLoginNotFound, http.StatusNotFound, "Child session ID does not exist"},
+	{ChildLoginRevoked, http.StatusConflict, "Child session is already revoked"},
+
+	{DowntimeOverrideNotFound, http.StatusNotFound, "Downtime override ID does not exist"},
+	{DowntimeOverrideEnded, http.StatusConflict, "Downtime override has already ended"},
+	{DowntimeAlreadyOverridden, http.StatusConflict, "Downtime is already overridden for this child for the rest of the day"},
+
+	{PushSubscriptionNotFound, http.StatusNotFound, "Push subscription ID does not exist"},
+}
+
+var byCode = func() map[Code]Definition {
+	m := make(map[Code]Definition, len(definitions))
+	for _, def := range definitions {
+		m[def.Code] = def
+	}
+	return m
+}()
+
+// Lookup returns the definition of a code
+func Lookup(code Code) (Definition, bool) {
+	def, ok := byCode[code]
+	return def, ok
+}
+
+// Status returns the HTTP status for a code (500 for unknown codes)
+func Status(code Code) int {
+	if def, ok := byCode[code]; ok {
+		return def.Status
+	}
+	return http.StatusInternalServerError
+}
+
+// Catalog returns all error codes sorted by code
+func Catalog() []Definition {
+	catalog := make([]Definition, len(definitions))
+	copy(catalog, definitions)
+	sort.Slice(catalog, func(i, j int) bool {
+		return catalog[i].Code < catalog[j].Code
+	})
+	return catalog
+}
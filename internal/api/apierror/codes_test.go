@@ -0,0 +1,66 @@
+package apierror
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"metron/internal/core"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCatalog_UniqueCodes(t *testing.T) {
+	seen := make(map[Code]bool)
+	for _, def := range definitions {
+		assert.False(t, seen[def.Code], "duplicate code %s", def.Code)
+		seen[def.Code] = true
+
+		assert.NotEmpty(t, http.StatusText(def.Status), "code %s has invalid status %d", def.Code, def.Status)
+		assert.NotEmpty(t, def.Description, "code %s has no description", def.Code)
+	}
+}
+
+func TestCatalog_Sorted(t *testing.T) {
+	catalog := Catalog()
+	require.Len(t, catalog, len(definitions))
+	for i := 1; i < len(catalog); i++ {
+		assert.Less(t, catalog[i-1].Code, catalog[i].Code)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	assert.Equal(t, http.StatusNotFound, Status(ChildNotFound))
+	assert.Equal(t, http.StatusTooManyRequests, Status(ExtensionTooSoon))
+	assert.Equal(t, http.StatusInternalServerError, Status(Code("UNKNOWN_CODE")))
+}
+
+func TestFromError(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		want   Code
+		wantOK bool
+	}{
+		{"plain", core.ErrSessionNotFound, SessionNotFound, true},
+		{"wrapped", fmt.Errorf("extend: %w", core.ErrExtensionTooSoon), ExtensionTooSoon, true},
+		{"typed", &core.InsufficientTimeError{ChildName: "Alice"}, InsufficientTime, true},
+		{"unknown", fmt.Errorf("driver failed"), "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, ok := FromError(tt.err)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, code)
+		})
+	}
+}
+
+func TestFromError_CodesRegistered(t *testing.T) {
+	for _, mapping := range coreErrors {
+		_, ok := Lookup(mapping.code)
+		assert.True(t, ok, "core error %q maps to unregistered code %s", mapping.err, mapping.code)
+	}
+}
@@ -0,0 +1,89 @@
+package apierror
+
+import (
+	"errors"
+
+	"metron/internal/core"
+)
+
+// coreErrors maps domain errors to error codes
+// Matching uses errors.Is, so wrapped errors are recognised
+var coreErrors = []struct {
+	err  error
+	code Code
+}{
+	{core.ErrChildNotFound, ChildNotFound},
+	{core.ErrSessionNotFound, SessionNotFound},
+	{core.ErrSessionNotActive, SessionNotActive},
+	{core.ErrInsufficientTime, InsufficientTime},
+	{core.ErrExtensionTooSoon, ExtensionTooSoon},
+	{core.ErrDowntimeActive, DowntimeActive},
+	{core.ErrDeviceNotAllowed, DeviceNotAllowed},
+	{core.ErrChildNotInSession, ChildNotInSession},
+	{core.ErrLastChildInSession, LastChildInSession},
+	{core.ErrSessionBusy, SessionBusy},
+	{core.ErrInitiatorLimit, InitiatorLimit},
+	{core.ErrPolicyBlocked, PolicyBlocked},
+	{core.ErrFamilyBudgetUsedUp, FamilyBudgetUsedUp},
+	{core.ErrActivityBudgetUsedUp, ActivityBudgetUsedUp},
+	{core.ErrDeviceBusy, DeviceBusy},
+	{core.ErrQueuedStartNotFound, QueuedStartNotFound},
+	{core.ErrNoMediaPlaying, NoMediaPlaying},
+	{core.ErrMediaEndsInTime, MediaEndsInTime},
+	{core.ErrInvalidMediaState, ValidationError},
+	{core.ErrInvalidInitiator, ValidationError},
+	{core.ErrInvalidDuration, InvalidMinutes},
+	{core.ErrNoChildren, InvalidChildIDs},
+	{core.ErrInvalidChildID, ValidationError},
+	{core.ErrInvalidName, ValidationError},
+	{core.ErrInvalidWeekdayLimit, ValidationError},
+	{core.ErrInvalidWeekendLimit, ValidationError},
+	{core.ErrInvalidBreakRule, ValidationError},
+	{core.ErrInvalidTimezone, ValidationError},
+	{core.ErrInvalidBirthdate, ValidationError},
+	{core.ErrInvalidDeviceType, ValidationError},
+	{core.ErrMovieTimeDisabled, MovieTimeDisabled},
+	{core.ErrNotWeekend, NotWeekend},
+	{core.ErrMovieTimeAlreadyUsed, AlreadyUsed},
+	{core.ErrBreakNotMet, BreakNotMet},
+	{core.ErrMovieSessionActive, MovieSessionActive},
+	{core.ErrInvalidMovieDevice, InvalidDevice},
+	{core.ErrLockdownActive, LockdownActive},
+	{core.ErrLockdownNotActive, LockdownNotActive},
+	{core.ErrTrackingAlreadyPaused, TrackingAlreadyPaused},
+	{core.ErrTrackingNotPaused, TrackingNotPaused},
+	{core.ErrInvalidResumeTime, InvalidResumeTime},
+	{core.ErrLimitChangeNotFound, LimitChangeNotFound},
+	{core.ErrLimitChangeInPast, LimitChangeInPast},
+	{core.ErrLimitChangeApplied, LimitChangeApplied},
+	{core.ErrProfileTransitionNotFound, ProfileTransitionNotFound},
+	{core.ErrProfileTransitionResolved, ProfileTransitionResolved},
+	{core.ErrAgentTokenNotFound, AgentTokenNotFound},
+	{core.ErrAgentTokenRevoked, AgentTokenRevoked},
+	{core.ErrChildLoginNotFound, ChildLoginNotFound},
+	{core.ErrChildLoginRevoked, ChildLoginRevoked},
+	{core.ErrInvalidLinkCode, InvalidLinkCode},
+	{core.ErrDowntimeOverrideNotFound, DowntimeOverrideNotFound},
+	{core.ErrDowntimeOverrideEnded, DowntimeOverrideEnded},
+	{core.ErrDowntimeAlreadyOverridden, DowntimeAlreadyOverridden},
+	{core.ErrInvalidTamperEventType, ValidationError},
+	{core.ErrInvalidOverrideScope, ValidationError},
+	{core.ErrInvalidAllocationRange, InvalidDateRange},
+	{core.ErrInvalidReportRange, InvalidDateRange},
+	{core.ErrInvalidUsageCorrection, ValidationError},
+	{core.ErrInvalidAppUsage, ValidationError},
+	{core.ErrInvalidActivity, ValidationError},
+	{core.ErrPushSubscriptionNotFound, PushSubscriptionNotFound},
+	{core.ErrInvalidPushSubscription, ValidationError},
+}
+
+// FromError returns the code for a known core error
+// Returns false if err does not match any core error
+func FromError(err error) (Code, bool) {
+	for _, mapping := range coreErrors {
+		if errors.Is(err, mapping.err) {
+			return mapping.code, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,35 @@
+package apierror
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Body builds an error response body
+func Body(code Code, message string) gin.H {
+	return gin.H{
+		"error": message,
+		"code":  code,
+	}
+}
+
+// Respond writes an error response with the status registered for code
+func Respond(c *gin.Context, code Code, message string) {
+	c.JSON(Status(code), Body(code, message))
+}
+
+// RespondError maps err to its registered code, falling back to fallback for unknown errors
+func RespondError(c *gin.Context, err error, fallback Code) {
+	code, ok := FromError(err)
+	if !ok {
+		code = fallback
+	}
+	Respond(c, code, err.Error())
+}
+
+// CatalogHandler lists all error codes with their HTTP statuses
+// GET /api/v1/errors
+func CatalogHandler(c *gin.Context) {
+	c.JSON(http.StatusOK, Catalog())
+}
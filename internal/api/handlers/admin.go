@@ -2,6 +2,8 @@ package handlers
 
 import (
 	"log/slog"
+	"metron/internal/api/apierror"
+	"metron/internal/credentials"
 	"metron/internal/drivers/aqara"
 	"net/http"
 	"time"
@@ -11,15 +13,15 @@ import (
 
 // AdminHandler handles administrative operations for Aqara driver
 type AdminHandler struct {
-	storage aqara.AqaraTokenStorage
-	logger  *slog.Logger
+	tokens *aqara.TokenStore
+	logger *slog.Logger
 }
 
 // NewAdminHandler creates a new admin handler for Aqara operations
-func NewAdminHandler(storage aqara.AqaraTokenStorage, logger *slog.Logger) *AdminHandler {
+func NewAdminHandler(store credentials.Store, logger *slog.Logger) *AdminHandler {
 	return &AdminHandler{
-		storage: storage,
-		logger:  logger,
+		tokens: aqara.NewTokenStore(store),
+		logger: logger,
 	}
 }
 
@@ -30,17 +32,17 @@ func (h *AdminHandler) UpdateAqaraRefreshToken(c *gin.Context) {
 		RefreshToken string `json:"refresh_token" binding:"required"`
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Invalid request body",
-			"code":  "INVALID_REQUEST",
+			"code":  apierror.InvalidRequest,
 			"details": err.Error(),
 		})
 		return
 	}
 
 	// Get existing tokens (if any)
-	tokens, err := h.storage.GetAqaraTokens(c.Request.Context())
+	tokens, err := h.tokens.Get(c.Request.Context())
 	if err != nil {
 		h.logger.Error("Failed to get existing tokens",
 			"component", "api.admin",
@@ -48,7 +50,7 @@ func (h *AdminHandler) UpdateAqaraRefreshToken(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to retrieve existing tokens",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -69,14 +71,14 @@ func (h *AdminHandler) UpdateAqaraRefreshToken(c *gin.Context) {
 	}
 
 	// Save to database
-	if err := h.storage.SaveAqaraTokens(c.Request.Context(), tokens); err != nil {
+	if err := h.tokens.Save(c.Request.Context(), tokens); err != nil {
 		h.logger.Error("Failed to save refresh token",
 			"component", "api.admin",
 			"error", err,
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to save refresh token",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -94,7 +96,7 @@ func (h *AdminHandler) UpdateAqaraRefreshToken(c *gin.Context) {
 // GetAqaraTokenStatus returns the status of Aqara tokens
 // GET /admin/aqara/token-status
 func (h *AdminHandler) GetAqaraTokenStatus(c *gin.Context) {
-	tokens, err := h.storage.GetAqaraTokens(c.Request.Context())
+	tokens, err := h.tokens.Get(c.Request.Context())
 	if err != nil {
 		h.logger.Error("Failed to get tokens",
 			"component", "api.admin",
@@ -102,7 +104,7 @@ func (h *AdminHandler) GetAqaraTokenStatus(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to retrieve token status",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -110,7 +112,7 @@ func (h *AdminHandler) GetAqaraTokenStatus(c *gin.Context) {
 	if tokens == nil {
 		c.JSON(http.StatusOK, gin.H{
 			"configured": false,
-			"message": "No refresh token configured. Use POST /v1/admin/aqara/refresh-token to add one.",
+			"message": "No refresh token configured. Use POST /api/v1/admin/aqara/refresh-token to add one.",
 		})
 		return
 	}
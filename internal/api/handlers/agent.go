@@ -2,7 +2,9 @@ package handlers
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"metron/internal/api/apierror"
 	"metron/internal/api/middleware"
 	"metron/internal/core"
 	"metron/internal/storage"
@@ -16,9 +18,19 @@ const warningMinutes = 5
 
 // AgentHandler handles agent-related requests
 type AgentHandler struct {
-	storage storage.Storage
-	manager AgentSessionManager
-	logger  *slog.Logger
+	storage   storage.Storage
+	manager   AgentSessionManager
+	heartbeat HeartbeatRecorder // Optional: records when each agent last checked in
+	tamper    TamperReporter    // Optional: stores tamper events reported by agents
+	logger    *slog.Logger
+}
+
+// maxTamperEventsPerReport limits the events accepted in one report
+const maxTamperEventsPerReport = 50
+
+// HeartbeatRecorder records that a device checked in
+type HeartbeatRecorder interface {
+	Record(ctx context.Context, deviceID, source string) error
 }
 
 // AgentSessionManager interface for session operations needed by agents
@@ -35,38 +47,94 @@ func NewAgentHandler(storage storage.Storage, manager AgentSessionManager, logge
 	}
 }
 
+// SetHeartbeat enables last-seen tracking for agent requests
+func (h *AgentHandler) SetHeartbeat(heartbeat HeartbeatRecorder) {
+	h.heartbeat = heartbeat
+}
+
+// SetTamper enables tamper event reporting
+func (h *AgentHandler) SetTamper(tamper TamperReporter) {
+	h.tamper = tamper
+}
+
 // GetDeviceSession returns the session status for a specific device.
 // Used by external agents (e.g., Windows agent) to poll for active sessions.
-// GET /v1/agent/session?device_id=xxx
+// GET /api/v1/agent/session?device_id=xxx
 func (h *AgentHandler) GetDeviceSession(c *gin.Context) {
 	deviceID := c.Query("device_id")
 	if deviceID == "" {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "device_id query parameter required",
-			"code":  "DEVICE_ID_REQUIRED",
+			"code":  apierror.DeviceIDRequired,
 		})
 		return
 	}
 
-	// Verify the authenticated agent is authorized for this device
-	// The middleware sets the device_id from the token
-	authorizedDeviceID, exists := c.Get(middleware.AgentDeviceIDKey)
-	if !exists || authorizedDeviceID != deviceID {
-		h.logger.Warn("agent attempted to access unauthorized device",
-			"requested_device", deviceID,
-			"authorized_device", authorizedDeviceID,
-		)
-		c.JSON(http.StatusForbidden, gin.H{
-			"error": "Not authorized for this device",
-			"code":  "DEVICE_NOT_AUTHORIZED",
-		})
+	if !h.authorizeDevice(c, deviceID) {
 		return
 	}
 
 	ctx := c.Request.Context()
 	now := time.Now()
 
-	// Check bypass mode first
+	// Lockdown overrides everything, including bypass mode
+	lockdown, err := h.storage.GetActiveLockdown(ctx)
+	if err != nil {
+		h.logger.Error("failed to check lockdown",
+			"device_id", deviceID,
+			"error", err,
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to check lockdown status",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	if lockdown != nil {
+		h.logger.Debug("lockdown active, locking device",
+			"device_id", deviceID,
+			"lockdown_id", lockdown.ID,
+		)
+		c.JSON(http.StatusOK, gin.H{
+			"active":      false,
+			"bypass_mode": false,
+			"lockdown":    true,
+			"server_time": now.Format(time.RFC3339),
+		})
+		return
+	}
+
+	// A global tracking pause (vacation mode) leaves every device unlocked like bypass mode
+	pauses, err := h.storage.ListActiveTrackingPauses(ctx)
+	if err != nil {
+		h.logger.Error("failed to check tracking pause",
+			"device_id", deviceID,
+			"error", err,
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to check tracking pause status",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	if pause := core.FindTrackingPause(pauses, "", now); pause != nil {
+		h.logger.Debug("tracking paused, device unlocked",
+			"device_id", deviceID,
+			"pause_id", pause.ID,
+			"resumes_at", pause.ResumesAt,
+		)
+		c.JSON(http.StatusOK, gin.H{
+			"active":          false,
+			"bypass_mode":     true,
+			"tracking_paused": true,
+			"server_time":     now.Format(time.RFC3339),
+		})
+		return
+	}
+
+	// Check bypass mode next
 	bypass, err := h.storage.GetDeviceBypass(ctx, deviceID)
 	if err != nil {
 		h.logger.Error("failed to get device bypass",
@@ -75,7 +143,7 @@ func (h *AgentHandler) GetDeviceSession(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to check bypass status",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -114,7 +182,7 @@ func (h *AgentHandler) GetDeviceSession(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to retrieve sessions",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -152,8 +220,191 @@ func (h *AgentHandler) GetDeviceSession(c *gin.Context) {
 	})
 }
 
+// ReportActivity records how long the device has been idle on its active session.
+// The scheduler stops sessions that stay idle longer than the device's idle timeout.
+// POST /api/v1/agent/activity
+func (h *AgentHandler) ReportActivity(c *gin.Context) {
+	var req struct {
+		DeviceID    string `json:"device_id" binding:"required"`
+		IdleSeconds int    `json:"idle_seconds" binding:"min=0"`
+	}
+
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"code":    apierror.InvalidRequest,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if !h.authorizeDevice(c, req.DeviceID) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	now := time.Now()
+
+	sessions, err := h.manager.ListActiveSessions(ctx)
+	if err != nil {
+		h.logger.Error("failed to list active sessions",
+			"device_id", req.DeviceID,
+			"error", err,
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve sessions",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	var activeSession *core.Session
+	for _, session := range sessions {
+		if session.DeviceID == req.DeviceID && session.Status == core.SessionStatusActive {
+			activeSession = session
+			break
+		}
+	}
+
+	// Activity outside a session is not tracked
+	if activeSession == nil {
+		c.JSON(http.StatusOK, gin.H{
+			"recorded": false,
+		})
+		return
+	}
+
+	if activeSession.RecordActivity(time.Duration(req.IdleSeconds)*time.Second, now) {
+		if err := h.storage.UpdateSession(ctx, activeSession); err != nil {
+			h.logger.Error("failed to record device activity",
+				"device_id", req.DeviceID,
+				"session_id", activeSession.ID,
+				"error", err,
+			)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to record activity",
+				"code":  apierror.InternalError,
+			})
+			return
+		}
+	}
+
+	h.logger.Debug("device activity recorded",
+		"device_id", req.DeviceID,
+		"session_id", activeSession.ID,
+		"idle_seconds", req.IdleSeconds,
+		"last_activity_at", activeSession.LastActivityAt,
+	)
+
+	c.JSON(http.StatusOK, gin.H{
+		"recorded":         true,
+		"session_id":       activeSession.ID,
+		"last_activity_at": activeSession.LastActivityAt.Format(time.RFC3339),
+	})
+}
+
+// ReportEvents stores tamper events detected by the agent (clock changes, kill attempts, safe-mode boots).
+// Agents queue events while offline and send them in batches.
+// POST /api/v1/agent/events
+func (h *AgentHandler) ReportEvents(c *gin.Context) {
+	var req struct {
+		DeviceID string `json:"device_id" binding:"required"`
+		Events   []struct {
+			Type       string     `json:"type"`
+			Details    string     `json:"details"`
+			OccurredAt *time.Time `json:"occurred_at"`
+		} `json:"events" binding:"required,min=1"`
+	}
+
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"code":    apierror.InvalidRequest,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if len(req.Events) > maxTamperEventsPerReport {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("At most %d events per report", maxTamperEventsPerReport),
+			"code":  apierror.ValidationError,
+		})
+		return
+	}
+
+	// Reject the whole batch up front so agents never resend half-stored reports
+	for _, event := range req.Events {
+		if !core.IsValidTamperEventType(event.Type) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("Unknown event type %q", event.Type),
+				"code":  apierror.ValidationError,
+			})
+			return
+		}
+	}
+
+	if !h.authorizeDevice(c, req.DeviceID) {
+		return
+	}
+
+	ctx := c.Request.Context()
+	ids := make([]string, 0, len(req.Events))
+	for _, reported := range req.Events {
+		var occurredAt time.Time
+		if reported.OccurredAt != nil {
+			occurredAt = *reported.OccurredAt
+		}
+
+		event, err := h.tamper.Report(ctx, req.DeviceID, reported.Type, reported.Details, occurredAt)
+		if err != nil {
+			h.logger.Error("failed to store tamper event",
+				"device_id", req.DeviceID,
+				"type", reported.Type,
+				"error", err,
+			)
+			apierror.RespondError(c, err, apierror.InternalError)
+			return
+		}
+		ids = append(ids, event.ID)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"recorded":  len(ids),
+		"event_ids": ids,
+	})
+}
+
+// authorizeDevice verifies the authenticated agent is authorized for the device
+// The middleware sets the device_id from the token; a 403 is written on mismatch
+func (h *AgentHandler) authorizeDevice(c *gin.Context, deviceID string) bool {
+	authorizedDeviceID, exists := c.Get(middleware.AgentDeviceIDKey)
+	if !exists || authorizedDeviceID != deviceID {
+		h.logger.Warn("agent attempted to access unauthorized device",
+			"requested_device", deviceID,
+			"authorized_device", authorizedDeviceID,
+		)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Not authorized for this device",
+			"code":  apierror.DeviceNotAuthorized,
+		})
+		return false
+	}
+
+	// Every authorized request counts as a check-in
+	if h.heartbeat != nil {
+		if err := h.heartbeat.Record(c.Request.Context(), deviceID, core.HeartbeatSourceAgent); err != nil {
+			h.logger.Warn("failed to record heartbeat",
+				"device_id", deviceID,
+				"error", err,
+			)
+		}
+	}
+	return true
+}
+
 // SetDeviceBypass enables or disables bypass mode for a device.
-// POST /v1/devices/:id/bypass
+// POST /api/v1/devices/:id/bypass
 func (h *AgentHandler) SetDeviceBypass(c *gin.Context) {
 	deviceID := c.Param("id")
 
@@ -163,10 +414,10 @@ func (h *AgentHandler) SetDeviceBypass(c *gin.Context) {
 		ExpiresInMinutes *int   `json:"expires_in_minutes,omitempty"` // nil = indefinite
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Invalid request body",
-			"code":    "INVALID_REQUEST",
+			"code":    apierror.InvalidRequest,
 			"details": err.Error(),
 		})
 		return
@@ -184,7 +435,7 @@ func (h *AgentHandler) SetDeviceBypass(c *gin.Context) {
 			)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to clear bypass",
-				"code":  "INTERNAL_ERROR",
+				"code":  apierror.InternalError,
 			})
 			return
 		}
@@ -221,7 +472,7 @@ func (h *AgentHandler) SetDeviceBypass(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to set bypass",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -246,7 +497,7 @@ func (h *AgentHandler) SetDeviceBypass(c *gin.Context) {
 }
 
 // ClearDeviceBypass removes bypass mode for a device.
-// DELETE /v1/devices/:id/bypass
+// DELETE /api/v1/devices/:id/bypass
 func (h *AgentHandler) ClearDeviceBypass(c *gin.Context) {
 	deviceID := c.Param("id")
 	ctx := c.Request.Context()
@@ -258,7 +509,7 @@ func (h *AgentHandler) ClearDeviceBypass(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to clear bypass",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"metron/internal/api/apierror"
+	"metron/internal/core"
+	"metron/internal/devices"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AgentTokenService defines the agent token operations needed by the handler
+type AgentTokenService interface {
+	Issue(ctx context.Context, deviceID, label, issuedBy string) (*core.AgentToken, string, error)
+	List(ctx context.Context, deviceID string) ([]*core.AgentToken, error)
+	Rotate(ctx context.Context, id, rotatedBy string) (*core.AgentToken, string, error)
+	Revoke(ctx context.Context, id, revokedBy string) (*core.AgentToken, error)
+}
+
+// AgentTokensHandler handles agent token management requests
+type AgentTokensHandler struct {
+	tokens         AgentTokenService
+	deviceRegistry *devices.Registry
+	logger         *slog.Logger
+}
+
+// NewAgentTokensHandler creates a new agent tokens handler
+func NewAgentTokensHandler(tokens AgentTokenService, deviceRegistry *devices.Registry, logger *slog.Logger) *AgentTokensHandler {
+	return &AgentTokensHandler{
+		tokens:         tokens,
+		deviceRegistry: deviceRegistry,
+		logger:         logger,
+	}
+}
+
+// ListAgentTokens returns the issued agent tokens, optionally for one device
+// GET /admin/agents?device_id=xxx
+func (h *AgentTokensHandler) ListAgentTokens(c *gin.Context) {
+	deviceID := c.Query("device_id")
+
+	tokens, err := h.tokens.List(c.Request.Context(), deviceID)
+	if err != nil {
+		h.logger.Error("Failed to list agent tokens",
+			"component", "api.agent_tokens",
+			"device_id", deviceID,
+			"error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve agent tokens",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	response := make([]gin.H, len(tokens))
+	for i, token := range tokens {
+		response[i] = formatAgentTokenResponse(token)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"tokens": response,
+	})
+}
+
+// IssueAgentToken issues a new token for a device
+// The plain token is only returned by this call
+// POST /admin/agents
+func (h *AgentTokensHandler) IssueAgentToken(c *gin.Context) {
+	var req struct {
+		DeviceID string `json:"device_id"`
+		Label    string `json:"label"`
+		IssuedBy string `json:"issued_by"`
+	}
+
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"code":    apierror.InvalidRequest,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if req.DeviceID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "device_id is required",
+			"code":  apierror.DeviceIDRequired,
+		})
+		return
+	}
+
+	if _, err := h.deviceRegistry.Get(req.DeviceID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Unknown device",
+			"code":  apierror.InvalidDevice,
+		})
+		return
+	}
+
+	if req.IssuedBy == "" {
+		req.IssuedBy = "api"
+	}
+
+	token, plain, err := h.tokens.Issue(c.Request.Context(), req.DeviceID, req.Label, req.IssuedBy)
+	if err != nil {
+		h.logger.Error("Failed to issue agent token",
+			"component", "api.agent_tokens",
+			"device_id", req.DeviceID,
+			"issued_by", req.IssuedBy,
+			"error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to issue agent token",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	response := formatAgentTokenResponse(token)
+	response["token"] = plain
+	c.JSON(http.StatusCreated, response)
+}
+
+// RotateAgentToken revokes a token and issues a replacement for the same device
+// POST /admin/agents/:id/rotate
+func (h *AgentTokensHandler) RotateAgentToken(c *gin.Context) {
+	id := c.Param("id")
+
+	var req struct {
+		RotatedBy string `json:"rotated_by"`
+	}
+
+	// Body is optional
+	if c.Request.ContentLength > 0 {
+		if err := bindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"code":    apierror.InvalidRequest,
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	if req.RotatedBy == "" {
+		req.RotatedBy = "api"
+	}
+
+	token, plain, err := h.tokens.Rotate(c.Request.Context(), id, req.RotatedBy)
+	if err != nil {
+		if !errors.Is(err, core.ErrAgentTokenNotFound) && !errors.Is(err, core.ErrAgentTokenRevoked) {
+			h.logger.Error("Failed to rotate agent token",
+				"component", "api.agent_tokens",
+				"token_id", id,
+				"error", err)
+		}
+		apierror.RespondError(c, err, apierror.InternalError)
+		return
+	}
+
+	response := formatAgentTokenResponse(token)
+	response["token"] = plain
+	c.JSON(http.StatusCreated, response)
+}
+
+// RevokeAgentToken invalidates a token immediately
+// DELETE /admin/agents/:id
+func (h *AgentTokensHandler) RevokeAgentToken(c *gin.Context) {
+	id := c.Param("id")
+
+	var req struct {
+		RevokedBy string `json:"revoked_by"`
+	}
+
+	// Body is optional
+	if c.Request.ContentLength > 0 {
+		if err := bindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"code":    apierror.InvalidRequest,
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	if req.RevokedBy == "" {
+		req.RevokedBy = "api"
+	}
+
+	token, err := h.tokens.Revoke(c.Request.Context(), id, req.RevokedBy)
+	if err != nil {
+		if !errors.Is(err, core.ErrAgentTokenNotFound) && !errors.Is(err, core.ErrAgentTokenRevoked) {
+			h.logger.Error("Failed to revoke agent token",
+				"component", "api.agent_tokens",
+				"token_id", id,
+				"error", err)
+		}
+		apierror.RespondError(c, err, apierror.InternalError)
+		return
+	}
+
+	c.JSON(http.StatusOK, formatAgentTokenResponse(token))
+}
+
+// formatAgentTokenResponse formats a token for responses; the hash is never included
+func formatAgentTokenResponse(token *core.AgentToken) gin.H {
+	response := gin.H{
+		"id":         token.ID,
+		"device_id":  token.DeviceID,
+		"hint":       token.Hint,
+		"issued_by":  token.IssuedBy,
+		"created_at": token.CreatedAt.Format(time.RFC3339),
+		"revoked":    token.IsRevoked(),
+	}
+
+	if token.Label != "" {
+		response["label"] = token.Label
+	}
+	if token.LastUsedAt != nil {
+		response["last_used_at"] = token.LastUsedAt.Format(time.RFC3339)
+	}
+	if token.RevokedAt != nil {
+		response["revoked_at"] = token.RevokedAt.Format(time.RFC3339)
+		response["revoked_by"] = token.RevokedBy
+	}
+
+	return response
+}
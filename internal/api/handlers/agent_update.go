@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"metron/internal/agentupdate"
+	"metron/internal/api/apierror"
+	"metron/internal/api/middleware"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AgentUpdateHandler serves signed agent releases to device agents
+// The release directory is read on every request, so publishing a release needs no restart
+type AgentUpdateHandler struct {
+	dir    string
+	logger *slog.Logger
+}
+
+// NewAgentUpdateHandler creates a new agent update handler for a release directory
+func NewAgentUpdateHandler(dir string, logger *slog.Logger) *AgentUpdateHandler {
+	return &AgentUpdateHandler{
+		dir:    dir,
+		logger: logger.With("component", "agent-update"),
+	}
+}
+
+// CheckUpdate tells the agent whether a newer release is available
+// The agent verifies the signature itself; the server never holds the signing key
+// GET /api/v1/agent/update?version=1.2.0
+func (h *AgentUpdateHandler) CheckUpdate(c *gin.Context) {
+	current := c.Query("version")
+	deviceID, _ := c.Get(middleware.AgentDeviceIDKey)
+
+	release, ok := h.loadRelease(c)
+	if !ok {
+		return
+	}
+
+	if release == nil || !agentupdate.IsNewer(release.Version, current) {
+		c.JSON(http.StatusOK, gin.H{
+			"update_available": false,
+		})
+		return
+	}
+
+	h.logger.Info("agent update available",
+		"device_id", deviceID,
+		"current_version", current,
+		"version", release.Version,
+	)
+
+	// Next to the route the agent asked on (/api/v1/agent/update, or the legacy /api/v1/agent/update)
+	c.JSON(http.StatusOK, gin.H{
+		"update_available": true,
+		"version":          release.Version,
+		"size":             release.Size,
+		"sha256":           release.SHA256,
+		"signature":        release.Signature,
+		"download_path":    c.FullPath() + "/download",
+	})
+}
+
+// DownloadUpdate serves the binary of the published release
+// GET /api/v1/agent/update/download
+func (h *AgentUpdateHandler) DownloadUpdate(c *gin.Context) {
+	release, ok := h.loadRelease(c)
+	if !ok {
+		return
+	}
+	if release == nil {
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": "No agent release published",
+			"code":  apierror.NotFound,
+		})
+		return
+	}
+
+	deviceID, _ := c.Get(middleware.AgentDeviceIDKey)
+	h.logger.Info("agent update downloaded",
+		"device_id", deviceID,
+		"version", release.Version,
+	)
+
+	c.Header("X-Agent-Version", release.Version)
+	c.FileAttachment(filepath.Join(h.dir, release.File), release.File)
+}
+
+// loadRelease reads the published release; nil without error if none is published
+// A 500 is written if the release directory is unreadable
+func (h *AgentUpdateHandler) loadRelease(c *gin.Context) (*agentupdate.Release, bool) {
+	release, err := agentupdate.LoadRelease(h.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, true
+	}
+	if err != nil {
+		h.logger.Error("failed to load agent release",
+			"dir", h.dir,
+			"error", err,
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to load agent release",
+			"code":  apierror.InternalError,
+		})
+		return nil, false
+	}
+	return release, true
+}
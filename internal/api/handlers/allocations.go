@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"metron/internal/api/apierror"
+	"metron/internal/core"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AllocationHistory lists children's daily allocations
+type AllocationHistory interface {
+	History(ctx context.Context, childID string, from, to time.Time) ([]*core.DailyTimeAllocation, error)
+}
+
+// AllocationsHandler handles daily allocation history queries
+type AllocationsHandler struct {
+	allocations AllocationHistory
+	logger      *slog.Logger
+}
+
+// NewAllocationsHandler creates a new allocations handler
+func NewAllocationsHandler(allocations AllocationHistory, logger *slog.Logger) *AllocationsHandler {
+	return &AllocationsHandler{
+		allocations: allocations,
+		logger:      logger,
+	}
+}
+
+// ListAllocations returns the child's daily allocations from one day to another, oldest first
+// GET /api/v1/children/:id/allocations?from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *AllocationsHandler) ListAllocations(c *gin.Context) {
+	childID := c.Param("id")
+
+	// Dates are calendar days; without them the last days up to today are returned
+	from, ok := parseDateQuery(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseDateQuery(c, "to")
+	if !ok {
+		return
+	}
+
+	allocations, err := h.allocations.History(c.Request.Context(), childID, from, to)
+	if err != nil {
+		if errors.Is(err, core.ErrChildNotFound) {
+			apierror.Respond(c, apierror.ChildNotFound, "Child not found")
+			return
+		}
+		if _, ok := apierror.FromError(err); ok {
+			apierror.RespondError(c, err, apierror.InternalError)
+			return
+		}
+		h.logger.Error("Failed to list allocations",
+			"component", "api.allocations",
+			"child_id", childID,
+			"error", err)
+		apierror.Respond(c, apierror.InternalError, "Failed to retrieve allocations")
+		return
+	}
+
+	response := make([]gin.H, len(allocations))
+	for i, allocation := range allocations {
+		response[i] = formatAllocationResponse(allocation)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"child_id":    childID,
+		"allocations": response,
+	})
+}
+
+// parseDateQuery parses an optional YYYY-MM-DD query parameter (zero if absent)
+// Responds with an error and returns false if it is malformed
+func parseDateQuery(c *gin.Context, name string) (time.Time, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return time.Time{}, true
+	}
+	date, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		apierror.Respond(c, apierror.InvalidDateFormat, name+" must use the YYYY-MM-DD format")
+		return time.Time{}, false
+	}
+	return date, true
+}
+
+// formatAllocationResponse formats a daily allocation for responses
+func formatAllocationResponse(allocation *core.DailyTimeAllocation) gin.H {
+	return gin.H{
+		"date":            allocation.Date.Format("2006-01-02"),
+		"base_limit":      allocation.BaseLimit,
+		"bonus_granted":   allocation.BonusGranted,
+		"total_available": allocation.BaseLimit + allocation.BonusGranted,
+	}
+}
@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"log/slog"
+	"metron/internal/api/apierror"
+	"metron/internal/core"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AppUsageService defines the app usage operations needed by the handler
+type AppUsageService interface {
+	Record(ctx context.Context, source, childID string, date time.Time, apps []core.AppMinutes) ([]*core.AppUsage, error)
+	Report(ctx context.Context, childID string, from, to time.Time) (*core.AppUsageReport, error)
+}
+
+// UsageSource is an external agent allowed to report app usage
+type UsageSource struct {
+	Name     string
+	Token    string   // Bearer token the agent sends
+	ChildIDs []string // Children the source may report for (empty = all)
+}
+
+// AppUsageHandler handles app usage reported by usage sources, and the app usage report
+type AppUsageHandler struct {
+	usage   AppUsageService
+	sources []UsageSource
+	logger  *slog.Logger
+}
+
+// NewAppUsageHandler creates a new app usage handler
+func NewAppUsageHandler(usage AppUsageService, sources []UsageSource, logger *slog.Logger) *AppUsageHandler {
+	return &AppUsageHandler{
+		usage:   usage,
+		sources: sources,
+		logger:  logger,
+	}
+}
+
+// IngestUsage records the minutes a usage source reports for a child's apps on a day
+// Minutes are the app's total for the day so far: a later report replaces them.
+// POST /ingest/usage
+func (h *AppUsageHandler) IngestUsage(c *gin.Context) {
+	source, ok := h.authenticate(c)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		ChildID string `json:"child_id" binding:"required"`
+		Date    string `json:"date"` // YYYY-MM-DD, defaults to the child's today
+		Apps    []struct {
+			App     string `json:"app"`
+			Minutes int    `json:"minutes"`
+		} `json:"apps" binding:"required"`
+	}
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"code":    apierror.InvalidRequest,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if len(source.ChildIDs) > 0 && !slices.Contains(source.ChildIDs, req.ChildID) {
+		apierror.Respond(c, apierror.Forbidden, "Usage source "+source.Name+" may not report for this child")
+		return
+	}
+
+	var date time.Time
+	if req.Date != "" {
+		var err error
+		if date, err = time.Parse("2006-01-02", req.Date); err != nil {
+			apierror.Respond(c, apierror.InvalidDateFormat, "date must use the YYYY-MM-DD format")
+			return
+		}
+	}
+
+	apps := make([]core.AppMinutes, len(req.Apps))
+	for i, app := range req.Apps {
+		apps[i] = core.AppMinutes{App: app.App, Minutes: app.Minutes}
+	}
+
+	usage, err := h.usage.Record(c.Request.Context(), source.Name, req.ChildID, date, apps)
+	if err != nil {
+		if _, ok := apierror.FromError(err); ok {
+			apierror.RespondError(c, err, apierror.InternalError)
+			return
+		}
+		h.logger.Error("Failed to record app usage",
+			"component", "api",
+			"source", source.Name,
+			"child_id", req.ChildID,
+			"error", err,
+		)
+		apierror.Respond(c, apierror.InternalError, "Failed to record app usage")
+		return
+	}
+
+	recorded := make([]gin.H, len(usage))
+	for i, entry := range usage {
+		recorded[i] = gin.H{
+			"app":     entry.App,
+			"minutes": entry.Minutes,
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"source":   source.Name,
+		"child_id": req.ChildID,
+		"date":     usage[0].Date.Format("2006-01-02"),
+		"apps":     recorded,
+	})
+}
+
+// GetAppUsage returns the app usage reported by usage sources over a range of days:
+// each app's total and the apps of each day, optionally for one child
+// GET /reports/apps?from=YYYY-MM-DD&to=YYYY-MM-DD&child_id=...
+func (h *AppUsageHandler) GetAppUsage(c *gin.Context) {
+	// Dates are calendar days; without them the last 7 days up to today are returned
+	from, ok := parseDateQuery(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseDateQuery(c, "to")
+	if !ok {
+		return
+	}
+	childID := c.Query("child_id")
+
+	report, err := h.usage.Report(c.Request.Context(), childID, from, to)
+	if err != nil {
+		if _, ok := apierror.FromError(err); ok {
+			apierror.RespondError(c, err, apierror.InternalError)
+			return
+		}
+		h.logger.Error("Failed to compute app usage",
+			"component", "api",
+			"error", err,
+		)
+		apierror.Respond(c, apierror.InternalError, "Failed to retrieve app usage")
+		return
+	}
+
+	apps := make([]gin.H, len(report.Apps))
+	for i, app := range report.Apps {
+		apps[i] = gin.H{
+			"child_id":        app.ChildID,
+			"source":          app.Source,
+			"app":             app.App,
+			"minutes":         app.Minutes,
+			"days_used":       app.DaysUsed,
+			"average_minutes": roundTenth(app.AverageMinutes),
+		}
+	}
+
+	days := make([]gin.H, len(report.Days))
+	for i, day := range report.Days {
+		usage := make([]gin.H, len(day.Apps))
+		for j, entry := range day.Apps {
+			usage[j] = gin.H{
+				"child_id": entry.ChildID,
+				"source":   entry.Source,
+				"app":      entry.App,
+				"minutes":  entry.Minutes,
+			}
+		}
+		days[i] = gin.H{
+			"date": day.Date.Format("2006-01-02"),
+			"apps": usage,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from": report.From.Format("2006-01-02"),
+		"to":   report.To.Format("2006-01-02"),
+		"apps": apps,
+		"days": days,
+	})
+}
+
+// authenticate finds the usage source of the request's Bearer token, and writes the error
+// response if there is none
+func (h *AppUsageHandler) authenticate(c *gin.Context) (UsageSource, bool) {
+	authHeader := c.GetHeader("Authorization")
+	if authHeader == "" {
+		apierror.Respond(c, apierror.AuthRequired, "Authorization header required")
+		return UsageSource{}, false
+	}
+	token, found := strings.CutPrefix(authHeader, "Bearer ")
+	if !found {
+		apierror.Respond(c, apierror.InvalidAuthScheme, "Invalid authorization scheme. Use Bearer token.")
+		return UsageSource{}, false
+	}
+
+	for _, source := range h.sources {
+		if token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(source.Token)) == 1 {
+			return source, true
+		}
+	}
+	apierror.Respond(c, apierror.InvalidToken, "Invalid usage source token")
+	return UsageSource{}, false
+}
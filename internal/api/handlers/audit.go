@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"metron/internal/api/apierror"
+	"metron/internal/core"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultAuditLogLimit is the number of entries returned when no limit is given
+const defaultAuditLogLimit = 50
+
+// AuditService defines the audit log operations needed by the handlers
+type AuditService interface {
+	List(ctx context.Context, childID string, limit int) ([]*core.AuditEntry, error)
+}
+
+// AuditHandler handles audit log requests
+type AuditHandler struct {
+	audit  AuditService
+	logger *slog.Logger
+}
+
+// NewAuditHandler creates a new audit handler
+func NewAuditHandler(audit AuditService, logger *slog.Logger) *AuditHandler {
+	return &AuditHandler{
+		audit:  audit,
+		logger: logger,
+	}
+}
+
+// ListAuditLog returns recent audit entries, newest first, optionally for one child
+// GET /audit-log?child_id=&limit=
+func (h *AuditHandler) ListAuditLog(c *gin.Context) {
+	limit := defaultAuditLogLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "limit must be a positive integer",
+				"code":  apierror.InvalidRequest,
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	entries, err := h.audit.List(c.Request.Context(), c.Query("child_id"), limit)
+	if err != nil {
+		h.logger.Error("Failed to list audit log",
+			"component", "api.audit",
+			"error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve audit log",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	response := make([]gin.H, len(entries))
+	for i, entry := range entries {
+		response[i] = gin.H{
+			"id":         entry.ID,
+			"action":     entry.Action,
+			"child_id":   entry.ChildID,
+			"actor":      entry.Actor,
+			"details":    entry.Details,
+			"created_at": entry.CreatedAt.Format(time.RFC3339),
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries": response,
+	})
+}
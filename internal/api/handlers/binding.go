@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"metron/internal/api/middleware"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+)
+
+// bindJSON decodes the JSON request body into obj and validates its binding tags, like
+// ShouldBindJSON. On routes using middleware.StrictJSON, unknown fields are rejected.
+func bindJSON(c *gin.Context, obj interface{}) error {
+	if !middleware.IsStrictJSON(c) {
+		return c.ShouldBindJSON(obj)
+	}
+	if c.Request.Body == nil {
+		return errors.New("invalid request")
+	}
+
+	decoder := json.NewDecoder(c.Request.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(obj); err != nil {
+		return err
+	}
+	return binding.Validator.ValidateStruct(obj)
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"metron/internal/api/apierror"
+	"metron/internal/bundle"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BundleService exports and imports configuration and data bundles
+type BundleService interface {
+	Export(ctx context.Context, history bool) (*bundle.Bundle, error)
+	Import(ctx context.Context, b *bundle.Bundle) (*bundle.Result, error)
+}
+
+// BundleHandler handles configuration and data export and import
+type BundleHandler struct {
+	bundles BundleService
+	logger  *slog.Logger
+}
+
+// NewBundleHandler creates a new bundle handler
+func NewBundleHandler(bundles BundleService, logger *slog.Logger) *BundleHandler {
+	return &BundleHandler{
+		bundles: bundles,
+		logger:  logger,
+	}
+}
+
+// Export returns the configuration and data as a bundle
+// GET /admin/export?history=true
+func (h *BundleHandler) Export(c *gin.Context) {
+	b, err := h.bundles.Export(c.Request.Context(), c.Query("history") == "true")
+	if err != nil {
+		h.logger.Error("Failed to export bundle",
+			"component", "api.bundle",
+			"error", err)
+		apierror.Respond(c, apierror.InternalError, "Failed to export")
+		return
+	}
+
+	c.JSON(http.StatusOK, b)
+}
+
+// Import creates the children, limit changes and history of a bundle
+// POST /admin/import
+func (h *BundleHandler) Import(c *gin.Context) {
+	var b bundle.Bundle
+	if err := bindJSON(c, &b); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"code":    apierror.InvalidRequest,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	result, err := h.bundles.Import(c.Request.Context(), &b)
+	if err != nil {
+		switch {
+		case errors.Is(err, bundle.ErrInvalidBundle):
+			apierror.Respond(c, apierror.ValidationError, err.Error())
+		case errors.Is(err, bundle.ErrChildExists):
+			apierror.Respond(c, apierror.ChildExists, err.Error())
+		default:
+			h.logger.Error("Failed to import bundle",
+				"component", "api.bundle",
+				"error", err)
+			apierror.Respond(c, apierror.InternalError, "Failed to import")
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"imported":       result,
+		"config_applied": false, // Devices, downtime, movie time and limit profiles live in the configuration file
+	})
+}
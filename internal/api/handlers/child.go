@@ -1,12 +1,19 @@
 package handlers
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"metron/internal/api/apierror"
 	"metron/internal/api/middleware"
 	"metron/internal/core"
 	"metron/internal/devices"
 	"metron/internal/storage"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -18,18 +25,38 @@ type ChildHandler struct {
 	storage        storage.Storage
 	manager        FullSessionManager
 	deviceRegistry *devices.Registry
-	sessionManager *middleware.SessionManager
+	logins         ChildLoginService
 	downtime       *core.DowntimeService
 	movieTime      *core.MovieTimeService
+	activity       ChildActivityService
+	media          MediaService // Optional: enables extending until the current media ends
+	cookie         ChildCookieConfig
+	appURL         string // Public URL of the child web app, for login links; empty if unknown
 	logger         *slog.Logger
 }
 
+// ChildActivityService lists children's activity feeds
+type ChildActivityService interface {
+	List(ctx context.Context, childID string, limit int) ([]*core.ChildActivity, error)
+}
+
+// defaultChildActivityLimit is the number of activity entries returned when no limit is given
+const defaultChildActivityLimit = 50
+
+// ChildCookieConfig holds the attributes of the child session cookie
+// A child web app on another origin needs SameSite=None, which browsers only accept with Secure.
+type ChildCookieConfig struct {
+	SameSite http.SameSite // 0 leaves the attribute out (browsers treat it as Lax)
+	Secure   bool          // Only send the cookie over HTTPS
+	Domain   string        // Empty for the API host only
+}
+
 // NewChildHandler creates a new child handler
 func NewChildHandler(
 	storage storage.Storage,
 	manager FullSessionManager,
 	deviceRegistry *devices.Registry,
-	sessionManager *middleware.SessionManager,
+	logins ChildLoginService,
 	downtime *core.DowntimeService,
 	movieTime *core.MovieTimeService,
 	logger *slog.Logger,
@@ -38,15 +65,49 @@ func NewChildHandler(
 		storage:        storage,
 		manager:        manager,
 		deviceRegistry: deviceRegistry,
-		sessionManager: sessionManager,
+		logins:         logins,
 		downtime:       downtime,
 		movieTime:      movieTime,
 		logger:         logger,
 	}
 }
 
+// SetCookieConfig sets the attributes of the child session cookie
+func (h *ChildHandler) SetCookieConfig(cookie ChildCookieConfig) {
+	h.cookie = cookie
+}
+
+// SetAppURL sets the public URL of the child web app, which login links point to
+func (h *ChildHandler) SetAppURL(appURL string) {
+	h.appURL = strings.TrimRight(appURL, "/")
+}
+
+// SetMedia enables the "until_media_end" extension mode
+func (h *ChildHandler) SetMedia(media MediaService) {
+	h.media = media
+}
+
+// SetActivity sets the service the activity feed is read from
+func (h *ChildHandler) SetActivity(activity ChildActivityService) {
+	h.activity = activity
+}
+
+// setSessionCookie sets (or with maxAge -1 deletes) the child session cookie
+func (h *ChildHandler) setSessionCookie(c *gin.Context, sessionID string, maxAge int) {
+	c.SetSameSite(h.cookie.SameSite)
+	c.SetCookie(
+		middleware.ChildSessionCookie, // name
+		sessionID,                     // value
+		maxAge,                        // maxAge in seconds
+		"/",                           // path
+		h.cookie.Domain,               // domain (empty = current domain)
+		h.cookie.Secure,               // secure
+		true,                          // httpOnly
+	)
+}
+
 // ListChildrenForAuth returns all children for the login screen
-// GET /child/auth/children (PUBLIC - no auth required)
+// GET /api/v1/child/auth/children (PUBLIC - no auth required)
 func (h *ChildHandler) ListChildrenForAuth(c *gin.Context) {
 	children, err := h.storage.ListChildren(c.Request.Context())
 	if err != nil {
@@ -56,7 +117,7 @@ func (h *ChildHandler) ListChildrenForAuth(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to retrieve children",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -75,17 +136,17 @@ func (h *ChildHandler) ListChildrenForAuth(c *gin.Context) {
 }
 
 // Login handles child authentication
-// POST /child/auth/login (PUBLIC - no auth required)
+// POST /api/v1/child/auth/login (PUBLIC - no auth required)
 func (h *ChildHandler) Login(c *gin.Context) {
 	var req struct {
 		ChildID string `json:"child_id" binding:"required"`
 		PIN     string `json:"pin" binding:"required,len=4"`
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Invalid request body",
-			"code":    "INVALID_REQUEST",
+			"code":    apierror.InvalidRequest,
 			"details": err.Error(),
 		})
 		return
@@ -98,7 +159,7 @@ func (h *ChildHandler) Login(c *gin.Context) {
 			// Don't reveal if child exists or not
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "Invalid credentials",
-				"code":  "INVALID_CREDENTIALS",
+				"code":  apierror.InvalidCredentials,
 			})
 			return
 		}
@@ -108,7 +169,7 @@ func (h *ChildHandler) Login(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to authenticate",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -123,7 +184,7 @@ func (h *ChildHandler) Login(c *gin.Context) {
 			if err := bcrypt.CompareHashAndPassword([]byte(child.PIN), []byte(req.PIN)); err != nil {
 				c.JSON(http.StatusUnauthorized, gin.H{
 					"error": "Invalid credentials",
-					"code":  "INVALID_CREDENTIALS",
+					"code":  apierror.InvalidCredentials,
 				})
 				return
 			}
@@ -132,7 +193,7 @@ func (h *ChildHandler) Login(c *gin.Context) {
 			if child.PIN != req.PIN {
 				c.JSON(http.StatusUnauthorized, gin.H{
 					"error": "Invalid credentials",
-					"code":  "INVALID_CREDENTIALS",
+					"code":  apierror.InvalidCredentials,
 				})
 				return
 			}
@@ -140,22 +201,26 @@ func (h *ChildHandler) Login(c *gin.Context) {
 	}
 
 	// Create session
-	sessionID := h.sessionManager.CreateSession(child.ID)
+	login, sessionID, err := h.logins.Login(c.Request.Context(), child.ID, c.Request.UserAgent())
+	if err != nil {
+		h.logger.Error("Failed to create child login",
+			"component", "child-api",
+			"child_id", child.ID,
+			"error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to log in",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
 
-	// Set cookie
-	c.SetCookie(
-		"child_session", // name
-		sessionID,       // value
-		24*60*60,        // maxAge (24 hours)
-		"/",             // path
-		"",              // domain (empty = current domain)
-		false,           // secure (set to true in production with HTTPS)
-		true,            // httpOnly
-	)
+	// Set cookie (expires with the login)
+	h.setSessionCookie(c, sessionID, int(core.ChildLoginDuration.Seconds()))
 
 	// Return session info and child data
 	c.JSON(http.StatusOK, gin.H{
 		"session_id": sessionID,
+		"expires_at": login.ExpiresAt.Format(time.RFC3339),
 		"child": gin.H{
 			"id":            child.ID,
 			"name":          child.Name,
@@ -170,38 +235,134 @@ func (h *ChildHandler) Login(c *gin.Context) {
 	)
 }
 
+// CreateLoginLink creates a short-lived, single-use code that logs a child in without the PIN
+// Parents show it as a QR code of the returned url (or read the code out) on the child's tablet,
+// so siblings can't watch the PIN being typed.
+// GET /api/v1/child/auth/qr?child_id=xxx (parent API key required)
+func (h *ChildHandler) CreateLoginLink(c *gin.Context) {
+	childID := c.Query("child_id")
+	if childID == "" {
+		apierror.Respond(c, apierror.ValidationError, "child_id is required")
+		return
+	}
+
+	child, err := h.storage.GetChild(c.Request.Context(), childID)
+	if err != nil {
+		if !errors.Is(err, core.ErrChildNotFound) {
+			h.logger.Error("Failed to get child for login link",
+				"component", "child-api",
+				"child_id", childID,
+				"error", err)
+		}
+		apierror.RespondError(c, err, apierror.InternalError)
+		return
+	}
+
+	link, err := h.logins.CreateLinkCode(child.ID)
+	if err != nil {
+		h.logger.Error("Failed to create login link code",
+			"component", "child-api",
+			"child_id", child.ID,
+			"error", err)
+		apierror.Respond(c, apierror.InternalError, "Failed to create login link")
+		return
+	}
+
+	response := gin.H{
+		"code":       link.Code,
+		"child_id":   child.ID,
+		"child_name": child.Name,
+		"expires_at": link.ExpiresAt.Format(time.RFC3339),
+	}
+	if h.appURL != "" {
+		response["url"] = h.appURL + "/login?code=" + url.QueryEscape(link.Code)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// RedeemLoginLink logs a child in with a login link code
+// POST /api/v1/child/auth/link (PUBLIC - no auth required)
+func (h *ChildHandler) RedeemLoginLink(c *gin.Context) {
+	var req struct {
+		Code string `json:"code" binding:"required"`
+	}
+
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"code":    apierror.InvalidRequest,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	login, sessionID, err := h.logins.RedeemLinkCode(c.Request.Context(), req.Code, c.Request.UserAgent())
+	if err != nil {
+		if !errors.Is(err, core.ErrInvalidLinkCode) {
+			h.logger.Error("Failed to redeem login link code",
+				"component", "child-api",
+				"error", err)
+		}
+		apierror.RespondError(c, err, apierror.InternalError)
+		return
+	}
+
+	child, err := h.storage.GetChild(c.Request.Context(), login.ChildID)
+	if err != nil {
+		h.logger.Error("Failed to get child for login link",
+			"component", "child-api",
+			"child_id", login.ChildID,
+			"error", err)
+		apierror.RespondError(c, err, apierror.InternalError)
+		return
+	}
+
+	h.setSessionCookie(c, sessionID, int(core.ChildLoginDuration.Seconds()))
+
+	c.JSON(http.StatusOK, gin.H{
+		"session_id": sessionID,
+		"expires_at": login.ExpiresAt.Format(time.RFC3339),
+		"child": gin.H{
+			"id":            child.ID,
+			"name":          child.Name,
+			"weekday_limit": child.WeekdayLimit,
+			"weekend_limit": child.WeekendLimit,
+		},
+	})
+
+	h.logger.Info("Child logged in with a login link",
+		"child_id", child.ID,
+		"child_name", child.Name,
+	)
+}
+
 // Logout handles child logout
-// POST /child/auth/logout (PUBLIC - no auth required, but session ID needed)
+// POST /api/v1/child/auth/logout (PUBLIC - no auth required, but session ID needed)
 func (h *ChildHandler) Logout(c *gin.Context) {
 	// Get session ID from cookie or header
-	sessionID, err := c.Cookie("child_session")
-	if err != nil || sessionID == "" {
-		authHeader := c.GetHeader("Authorization")
-		if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
-			sessionID = authHeader[7:]
-		}
-	}
+	sessionID := middleware.ChildSessionToken(c)
 
 	if sessionID != "" {
-		h.sessionManager.DeleteSession(sessionID)
-
-		// Clear cookie
-		c.SetCookie(
-			"child_session",
-			"",
-			-1, // maxAge = -1 deletes the cookie
-			"/",
-			"",
-			false,
-			true,
-		)
+		if err := h.logins.Logout(c.Request.Context(), sessionID); err != nil {
+			h.logger.Error("Failed to revoke child login on logout",
+				"component", "child-api",
+				"error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to log out",
+				"code":  apierror.InternalError,
+			})
+			return
+		}
+
+		// Clear cookie (maxAge = -1 deletes the cookie)
+		h.setSessionCookie(c, "", -1)
 	}
 
 	c.Status(http.StatusNoContent)
 }
 
 // GetMe returns the authenticated child's profile
-// GET /child/me (PROTECTED)
+// GET /api/v1/child/me (PROTECTED)
 func (h *ChildHandler) GetMe(c *gin.Context) {
 	childID, _ := middleware.GetChildID(c)
 
@@ -210,7 +371,7 @@ func (h *ChildHandler) GetMe(c *gin.Context) {
 		if err == core.ErrChildNotFound {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error": "Child not found",
-				"code":  "CHILD_NOT_FOUND",
+				"code":  apierror.ChildNotFound,
 			})
 			return
 		}
@@ -220,7 +381,7 @@ func (h *ChildHandler) GetMe(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to retrieve profile",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -235,7 +396,7 @@ func (h *ChildHandler) GetMe(c *gin.Context) {
 }
 
 // GetToday returns today's usage stats for the authenticated child
-// GET /child/today (PROTECTED)
+// GET /api/v1/child/today (PROTECTED)
 func (h *ChildHandler) GetToday(c *gin.Context) {
 	childID, _ := middleware.GetChildID(c)
 
@@ -247,7 +408,7 @@ func (h *ChildHandler) GetToday(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to retrieve status",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -261,7 +422,7 @@ func (h *ChildHandler) GetToday(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to retrieve child",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -273,12 +434,14 @@ func (h *ChildHandler) GetToday(c *gin.Context) {
 		"sessions_count":    status.SessionsToday,
 		"downtime_enabled":  child.DowntimeEnabled,
 	}
+	formatChildTrackingPause(response, status.TrackingPause)
 
 	// Add downtime active status if downtime is enabled
 	if h.downtime != nil && child.DowntimeEnabled {
-		response["in_downtime"] = h.downtime.IsInDowntime(time.Now())
-		if h.downtime.IsInDowntime(time.Now()) {
-			downtimeEnd := h.downtime.GetCurrentDowntimeEnd(time.Now())
+		downtime := h.downtime.ForChild(child)
+		response["in_downtime"] = downtime.IsInDowntime(time.Now())
+		if downtime.IsInDowntime(time.Now()) {
+			downtimeEnd := downtime.GetCurrentDowntimeEnd(time.Now())
 			if !downtimeEnd.IsZero() {
 				response["downtime_end"] = downtimeEnd.Format("2006-01-02T15:04:05Z07:00")
 			}
@@ -290,13 +453,190 @@ func (h *ChildHandler) GetToday(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
-// ListDevices returns available devices
-// GET /child/devices (PROTECTED)
+// GetSuggestions returns suggested session durations for the authenticated child
+// GET /api/v1/child/suggestions (PROTECTED)
+func (h *ChildHandler) GetSuggestions(c *gin.Context) {
+	childID, _ := middleware.GetChildID(c)
+
+	suggestions, err := h.manager.GetDurationSuggestions(c.Request.Context(), childID)
+	if err != nil {
+		h.logger.Error("Failed to get duration suggestions",
+			"child_id", childID,
+			"error", err,
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve suggestions",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, formatDurationSuggestions(suggestions))
+}
+
+// GetExemptActivities returns the authenticated child's use of each exempt activity's budget today
+// GET /api/v1/child/exempt-activities (PROTECTED)
+func (h *ChildHandler) GetExemptActivities(c *gin.Context) {
+	childID, _ := middleware.GetChildID(c)
+
+	budgets, err := h.manager.ActivityBudgets(c.Request.Context(), childID)
+	if err != nil {
+		h.logger.Error("Failed to get exempt activity budgets",
+			"child_id", childID,
+			"error", err,
+		)
+		apierror.Respond(c, apierror.InternalError, "Failed to retrieve exempt activities")
+		return
+	}
+
+	c.JSON(http.StatusOK, formatActivityBudgets(budgets))
+}
+
+// GetActivity returns what happened to the authenticated child's time, newest first
+// GET /api/v1/child/activity?limit= (PROTECTED)
+func (h *ChildHandler) GetActivity(c *gin.Context) {
+	childID, _ := middleware.GetChildID(c)
+
+	limit := defaultChildActivityLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "limit must be a positive integer",
+				"code":  apierror.InvalidRequest,
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	activities, err := h.activity.List(c.Request.Context(), childID, limit)
+	if err != nil {
+		h.logger.Error("Failed to list child activity",
+			"child_id", childID,
+			"error", err,
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve activity",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	response := make([]gin.H, len(activities))
+	for i, activity := range activities {
+		item := gin.H{
+			"id":         activity.ID,
+			"kind":       activity.Kind,
+			"message":    activity.Message,
+			"minutes":    activity.Minutes,
+			"created_at": activity.CreatedAt.Format(time.RFC3339),
+		}
+		if activity.SessionID != "" {
+			item["session_id"] = activity.SessionID
+		}
+		response[i] = item
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"activity": response,
+	})
+}
+
+// GetDowntime returns the downtime schedule for the authenticated child and when the next downtime starts
+// GET /api/v1/child/downtime (PROTECTED)
+func (h *ChildHandler) GetDowntime(c *gin.Context) {
+	childID, _ := middleware.GetChildID(c)
+	ctx := c.Request.Context()
+
+	child, err := h.storage.GetChild(ctx, childID)
+	if err != nil {
+		h.logger.Error("Failed to get child for downtime schedule",
+			"child_id", childID,
+			"error", err,
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve child",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	response := gin.H{
+		"downtime_enabled": false,
+		"in_downtime":      false,
+		"skipped_today":    false,
+		"schedule":         []gin.H{},
+	}
+	if h.downtime == nil || !h.downtime.IsEnabled() || !child.DowntimeEnabled {
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	now := time.Now()
+	downtime := h.downtime.ForChild(child)
+	response["downtime_enabled"] = true
+	response["timezone"] = downtime.Location().String()
+	response["skipped_today"] = downtime.IsDowntimeSkippedToday(ctx, now)
+
+	// Monday first, as the child sees the week
+	schedule := []gin.H{}
+	for i := 1; i <= 7; i++ {
+		weekday := time.Weekday(i % 7)
+		day := downtime.ScheduleForWeekday(weekday)
+		if day == nil {
+			continue
+		}
+		schedule = append(schedule, gin.H{
+			"day":   strings.ToLower(weekday.String()),
+			"start": fmt.Sprintf("%02d:%02d", day.StartHour, day.StartMinute),
+			"end":   fmt.Sprintf("%02d:%02d", day.EndHour, day.EndMinute),
+		})
+	}
+	response["schedule"] = schedule
+
+	if downtime.IsInDowntimeWithContext(ctx, now) {
+		response["in_downtime"] = true
+		if end := downtime.GetCurrentDowntimeEnd(now); !end.IsZero() {
+			response["downtime_end"] = end.Format(time.RFC3339)
+		}
+	}
+
+	if start, end := downtime.NextDowntimeWindow(ctx, now); !start.IsZero() {
+		response["next_downtime_start"] = start.Format(time.RFC3339)
+		response["next_downtime_end"] = end.Format(time.RFC3339)
+		response["minutes_until_downtime"] = int(start.Sub(now).Minutes())
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ListDevices returns the devices the child is allowed to use
+// GET /api/v1/child/devices (PROTECTED)
 func (h *ChildHandler) ListDevices(c *gin.Context) {
+	childID, _ := middleware.GetChildID(c)
+
+	child, err := h.storage.GetChild(c.Request.Context(), childID)
+	if err != nil {
+		h.logger.Error("Failed to get child for device list",
+			"child_id", childID,
+			"error", err,
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve devices",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
 	deviceList := h.deviceRegistry.List()
 
 	response := make([]gin.H, 0, len(deviceList))
 	for _, device := range deviceList {
+		if !child.CanUseDevice(device.ID) {
+			continue
+		}
+
 		d := gin.H{
 			"id":   device.ID,
 			"name": device.Name,
@@ -312,7 +652,7 @@ func (h *ChildHandler) ListDevices(c *gin.Context) {
 }
 
 // ListSessions returns the child's active sessions
-// GET /child/sessions (PROTECTED)
+// GET /api/v1/child/sessions (PROTECTED)
 func (h *ChildHandler) ListSessions(c *gin.Context) {
 	childID, _ := middleware.GetChildID(c)
 
@@ -323,7 +663,7 @@ func (h *ChildHandler) ListSessions(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to retrieve sessions",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -341,43 +681,72 @@ func (h *ChildHandler) ListSessions(c *gin.Context) {
 
 	response := make([]gin.H, 0, len(childSessions))
 	for _, session := range childSessions {
-		response = append(response, gin.H{
+		item := gin.H{
 			"id":                session.ID,
 			"device_id":         session.DeviceID,
 			"device_type":       session.DeviceType,
 			"start_time":        session.StartTime.Format("2006-01-02T15:04:05Z07:00"),
 			"remaining_minutes": session.CalculateRemainingMinutes(),
 			"status":            string(session.Status),
-		})
+		}
+		if session.WarningSentAt != nil {
+			item["warning_sent_at"] = session.WarningSentAt.Format("2006-01-02T15:04:05Z07:00")
+		}
+		if session.GraceEndsAt != nil {
+			item["grace_ends_at"] = session.GraceEndsAt.Format("2006-01-02T15:04:05Z07:00")
+		}
+		if session.IsExempt() {
+			item["activity"] = session.Activity
+		}
+		response = append(response, item)
 	}
 
 	c.JSON(http.StatusOK, response)
 }
 
 // CreateSession starts a new session for the authenticated child
-// POST /child/sessions (PROTECTED)
+// POST /api/v1/child/sessions (PROTECTED)
 func (h *ChildHandler) CreateSession(c *gin.Context) {
 	childID, _ := middleware.GetChildID(c)
 
 	var req struct {
-		DeviceID string `json:"device_id" binding:"required"`
-		Minutes  int    `json:"minutes" binding:"required,gt=0"`
+		DeviceID    string `json:"device_id" binding:"required"`
+		Minutes     int    `json:"minutes" binding:"required,gt=0"`
+		BreakExempt bool   `json:"break_exempt"`
+		Activity    string `json:"activity"` // Exempt activity (e.g., "homework")
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Invalid request body",
-			"code":    "INVALID_REQUEST",
+			"code":    apierror.InvalidRequest,
 			"details": err.Error(),
 		})
 		return
 	}
 
+	// Skipping breaks needs parent approval, which the child API cannot give
+	if req.BreakExempt {
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Only a parent can start a session without breaks",
+			"code":  apierror.Forbidden,
+		})
+		return
+	}
+
 	// Session only for this child (shared sessions are handled via MovieTime feature)
 	childIDs := []string{childID}
 
-	// Start session
-	session, err := h.manager.StartSession(c.Request.Context(), req.DeviceID, childIDs, req.Minutes)
+	// Start session, recorded (and limited) as started by the child
+	ctx := core.WithInitiator(c.Request.Context(), core.Initiator{Type: core.InitiatorChild, ID: childID})
+	if req.Activity != "" {
+		ctx = core.WithActivity(ctx, req.Activity)
+	}
+	session, err := h.manager.StartSession(ctx, req.DeviceID, childIDs, req.Minutes)
+	if response, queued := queuedResponse(err); queued {
+		c.JSON(http.StatusAccepted, response)
+		return
+	}
 	if err != nil {
 		h.logger.Error("Failed to start session",
 			"child_id", childID,
@@ -386,33 +755,83 @@ func (h *ChildHandler) CreateSession(c *gin.Context) {
 			"error", err,
 		)
 
-		if err == core.ErrInsufficientTime {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": "Not enough time remaining",
-				"code":  "INSUFFICIENT_TIME",
-			})
+		if errors.Is(err, core.ErrInsufficientTime) {
+			c.JSON(http.StatusBadRequest, insufficientTimeResponse(err))
+			return
+		}
+		if errors.Is(err, core.ErrPolicyBlocked) {
+			c.JSON(http.StatusForbidden, policyResponse(err))
+			return
+		}
+		if errors.Is(err, core.ErrDeviceBusy) {
+			c.JSON(http.StatusConflict, deviceBusyResponse(err))
 			return
 		}
 
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-			"code":  "SESSION_CREATE_FAILED",
-		})
+		apierror.RespondError(c, err, apierror.SessionCreateFailed)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
+	response := gin.H{
 		"id":                session.ID,
 		"device_id":         session.DeviceID,
 		"device_type":       session.DeviceType,
 		"start_time":        session.StartTime.Format("2006-01-02T15:04:05Z07:00"),
 		"remaining_minutes": session.CalculateRemainingMinutes(),
 		"status":            string(session.Status),
-	})
+	}
+	if session.IsExempt() {
+		response["activity"] = session.Activity
+	}
+	addGrantFields(response, session.Grant)
+
+	// The child joined the session already running on the device
+	if session.Merged {
+		response["merged"] = true
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// PreflightSession reports whether the authenticated child could start a session
+// GET /api/v1/child/sessions/preflight?device_id=&minutes=&activity= (PROTECTED)
+func (h *ChildHandler) PreflightSession(c *gin.Context) {
+	childID, _ := middleware.GetChildID(c)
+
+	deviceID := c.Query("device_id")
+	minutes, err := strconv.Atoi(c.Query("minutes"))
+	if deviceID == "" || err != nil || minutes <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "device_id and positive minutes are required",
+			"code":  apierror.InvalidRequest,
+		})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if activity := c.Query("activity"); activity != "" {
+		ctx = core.WithActivity(ctx, activity)
+	}
+
+	preflight, err := h.manager.PreflightSession(ctx, deviceID, []string{childID}, minutes)
+	if err != nil {
+		h.logger.Error("Failed to run session preflight",
+			"child_id", childID,
+			"device_id", deviceID,
+			"minutes", minutes,
+			"error", err,
+		)
+		apierror.RespondError(c, err, apierror.SessionCreateFailed)
+		return
+	}
+
+	c.JSON(http.StatusOK, formatPreflightResponse(preflight))
 }
 
 // StopSession stops a session (validates ownership)
-// POST /child/sessions/:id/stop (PROTECTED)
+// POST /api/v1/child/sessions/:id/stop (PROTECTED)
 func (h *ChildHandler) StopSession(c *gin.Context) {
 	childID, _ := middleware.GetChildID(c)
 	sessionID := c.Param("id")
@@ -423,7 +842,7 @@ func (h *ChildHandler) StopSession(c *gin.Context) {
 		if err == core.ErrSessionNotFound {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error": "Session not found",
-				"code":  "SESSION_NOT_FOUND",
+				"code":  apierror.SessionNotFound,
 			})
 			return
 		}
@@ -433,7 +852,7 @@ func (h *ChildHandler) StopSession(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to retrieve session",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -450,23 +869,21 @@ func (h *ChildHandler) StopSession(c *gin.Context) {
 	if !isOwner {
 		c.JSON(http.StatusForbidden, gin.H{
 			"error": "You don't have permission to stop this session",
-			"code":  "FORBIDDEN",
+			"code":  apierror.Forbidden,
 		})
 		return
 	}
 
 	// Stop the session
-	if err := h.manager.StopSession(c.Request.Context(), sessionID); err != nil {
+	ctx := core.WithEndReason(c.Request.Context(), core.SessionEndChildStop)
+	if err := h.manager.StopSession(ctx, sessionID); err != nil {
 		h.logger.Error("Failed to stop session",
 			"child_id", childID,
 			"session_id", sessionID,
 			"error", err,
 		)
 
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-			"code":  "SESSION_STOP_FAILED",
-		})
+		apierror.RespondError(c, err, apierror.SessionStopFailed)
 		return
 	}
 
@@ -474,23 +891,27 @@ func (h *ChildHandler) StopSession(c *gin.Context) {
 }
 
 // ExtendSession extends a session (validates ownership)
-// POST /child/sessions/:id/extend (PROTECTED)
+// POST /api/v1/child/sessions/:id/extend (PROTECTED)
 func (h *ChildHandler) ExtendSession(c *gin.Context) {
 	childID, _ := middleware.GetChildID(c)
 	sessionID := c.Param("id")
 
 	// Parse request body
 	var req struct {
-		AdditionalMinutes int `json:"additional_minutes" binding:"required,min=1"`
+		AdditionalMinutes int    `json:"additional_minutes" binding:"omitempty,min=1"`
+		Mode              string `json:"mode"` // "until_media_end" instead of additional_minutes
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil || (req.Mode == "" && req.AdditionalMinutes == 0) {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Invalid request. additional_minutes must be a positive integer",
-			"code":  "INVALID_REQUEST",
+			"code":  apierror.InvalidRequest,
 		})
 		return
 	}
+	if !validExtendMode(c, req.Mode, h.media != nil) {
+		return
+	}
 
 	// Get session to validate ownership
 	session, err := h.manager.GetSession(c.Request.Context(), sessionID)
@@ -498,7 +919,7 @@ func (h *ChildHandler) ExtendSession(c *gin.Context) {
 		if err == core.ErrSessionNotFound {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error": "Session not found",
-				"code":  "SESSION_NOT_FOUND",
+				"code":  apierror.SessionNotFound,
 			})
 			return
 		}
@@ -508,7 +929,7 @@ func (h *ChildHandler) ExtendSession(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to retrieve session",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -525,55 +946,67 @@ func (h *ChildHandler) ExtendSession(c *gin.Context) {
 	if !isOwner {
 		c.JSON(http.StatusForbidden, gin.H{
 			"error": "You don't have permission to extend this session",
-			"code":  "FORBIDDEN",
+			"code":  apierror.Forbidden,
 		})
 		return
 	}
 
-	// Extend the session
-	extendedSession, err := h.manager.ExtendSession(c.Request.Context(), sessionID, req.AdditionalMinutes)
+	// Extend the session, limited as the child's request
+	ctx := core.WithInitiator(c.Request.Context(), core.Initiator{Type: core.InitiatorChild, ID: childID})
+	var extendedSession *core.Session
+	var media *core.MediaState
+	if req.Mode == extendModeUntilMediaEnd {
+		extendedSession, media, err = h.media.ExtendUntilMediaEnds(ctx, sessionID)
+	} else {
+		extendedSession, err = h.manager.ExtendSession(ctx, sessionID, req.AdditionalMinutes)
+	}
 	if err != nil {
 		h.logger.Error("Failed to extend session",
 			"child_id", childID,
 			"session_id", sessionID,
+			"mode", req.Mode,
 			"additional_minutes", req.AdditionalMinutes,
 			"error", err,
 		)
 
-		if err == core.ErrInsufficientTime {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": err.Error(),
-				"code":  "INSUFFICIENT_TIME",
-			})
+		if errors.Is(err, core.ErrInsufficientTime) {
+			c.JSON(http.StatusBadRequest, insufficientTimeResponse(err))
+			return
+		}
+		if errors.Is(err, core.ErrPolicyBlocked) {
+			c.JSON(http.StatusForbidden, policyResponse(err))
 			return
 		}
 
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-			"code":  "SESSION_EXTEND_FAILED",
-		})
+		apierror.RespondError(c, err, apierror.SessionExtendFailed)
 		return
 	}
 
 	// Return extended session
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"id":                extendedSession.ID,
 		"device_type":       extendedSession.DeviceType,
 		"device_id":         extendedSession.DeviceID,
 		"start_time":        extendedSession.StartTime.Format("2006-01-02T15:04:05Z07:00"),
 		"remaining_minutes": extendedSession.CalculateRemainingMinutes(),
 		"status":            string(extendedSession.Status),
-	})
+	}
+	addGrantFields(response, extendedSession.Grant)
+	if media != nil {
+		response["media"] = formatMediaState(media)
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 // GetMovieTimeAvailability returns the current movie time availability status
-// GET /child/movie-time (PROTECTED)
+// GET /api/v1/child/movie-time (PROTECTED)
 func (h *ChildHandler) GetMovieTimeAvailability(c *gin.Context) {
 	// Check if movie time feature is enabled
 	if h.movieTime == nil || !h.movieTime.IsEnabled() {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "Movie time feature is not enabled",
-			"code":  "MOVIE_TIME_DISABLED",
+			"code":  apierror.MovieTimeDisabled,
 		})
 		return
 	}
@@ -585,7 +1018,7 @@ func (h *ChildHandler) GetMovieTimeAvailability(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to retrieve movie time availability",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -594,7 +1027,7 @@ func (h *ChildHandler) GetMovieTimeAvailability(c *gin.Context) {
 }
 
 // StartMovieTime starts a new movie time session
-// POST /child/movie-time (PROTECTED)
+// POST /api/v1/child/movie-time (PROTECTED)
 func (h *ChildHandler) StartMovieTime(c *gin.Context) {
 	childID, _ := middleware.GetChildID(c)
 
@@ -602,7 +1035,7 @@ func (h *ChildHandler) StartMovieTime(c *gin.Context) {
 	if h.movieTime == nil || !h.movieTime.IsEnabled() {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "Movie time feature is not enabled",
-			"code":  "MOVIE_TIME_DISABLED",
+			"code":  apierror.MovieTimeDisabled,
 		})
 		return
 	}
@@ -611,16 +1044,17 @@ func (h *ChildHandler) StartMovieTime(c *gin.Context) {
 		DeviceID string `json:"device_id" binding:"required"`
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Invalid request body",
-			"code":    "INVALID_REQUEST",
+			"code":    apierror.InvalidRequest,
 			"details": err.Error(),
 		})
 		return
 	}
 
-	session, err := h.movieTime.StartMovieTime(c.Request.Context(), req.DeviceID, childID)
+	ctx := core.WithInitiator(c.Request.Context(), core.Initiator{Type: core.InitiatorChild, ID: childID})
+	session, err := h.movieTime.StartMovieTime(ctx, req.DeviceID, childID)
 	if err != nil {
 		h.logger.Error("Failed to start movie time",
 			"child_id", childID,
@@ -633,28 +1067,25 @@ func (h *ChildHandler) StartMovieTime(c *gin.Context) {
 		case core.ErrNotWeekend:
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": "Movie time is only available on weekends",
-				"code":  "NOT_WEEKEND",
+				"code":  apierror.NotWeekend,
 			})
 		case core.ErrMovieTimeAlreadyUsed:
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": "Movie time already used today",
-				"code":  "ALREADY_USED",
+				"code":  apierror.AlreadyUsed,
 			})
 		case core.ErrBreakNotMet:
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": "Break period after last session not yet completed",
-				"code":  "BREAK_NOT_MET",
+				"code":  apierror.BreakNotMet,
 			})
 		case core.ErrInvalidMovieDevice:
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": "Device is not allowed for movie time",
-				"code":  "INVALID_DEVICE",
+				"code":  apierror.InvalidDevice,
 			})
 		default:
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": err.Error(),
-				"code":  "MOVIE_TIME_START_FAILED",
-			})
+			apierror.RespondError(c, err, apierror.MovieTimeStartFailed)
 		}
 		return
 	}
@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"metron/internal/api/apierror"
+	"metron/internal/core"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ChildLoginService defines the child web app login operations needed by the handlers
+type ChildLoginService interface {
+	Login(ctx context.Context, childID, userAgent string) (*core.ChildLogin, string, error)
+	CreateLinkCode(childID string) (*core.ChildLinkCode, error)
+	RedeemLinkCode(ctx context.Context, code, userAgent string) (*core.ChildLogin, string, error)
+	Logout(ctx context.Context, token string) error
+	ListActive(ctx context.Context, childID string) ([]*core.ChildLogin, error)
+	Revoke(ctx context.Context, id, revokedBy string) (*core.ChildLogin, error)
+}
+
+// ChildLoginsHandler lets parents see and end children's logins to the child web app
+type ChildLoginsHandler struct {
+	logins ChildLoginService
+	logger *slog.Logger
+}
+
+// NewChildLoginsHandler creates a new child logins handler
+func NewChildLoginsHandler(logins ChildLoginService, logger *slog.Logger) *ChildLoginsHandler {
+	return &ChildLoginsHandler{
+		logins: logins,
+		logger: logger,
+	}
+}
+
+// ListChildLogins returns the active logins, optionally of one child, newest first
+// GET /api/v1/child/auth/sessions?child_id=xxx
+func (h *ChildLoginsHandler) ListChildLogins(c *gin.Context) {
+	childID := c.Query("child_id")
+
+	logins, err := h.logins.ListActive(c.Request.Context(), childID)
+	if err != nil {
+		h.logger.Error("Failed to list child logins",
+			"component", "api.child_logins",
+			"child_id", childID,
+			"error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve child sessions",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	response := make([]gin.H, len(logins))
+	for i, login := range logins {
+		response[i] = formatChildLoginResponse(login)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sessions": response,
+	})
+}
+
+// RevokeChildLogin logs a child out of one login immediately
+// DELETE /api/v1/child/auth/sessions/:id
+func (h *ChildLoginsHandler) RevokeChildLogin(c *gin.Context) {
+	id := c.Param("id")
+
+	var req struct {
+		RevokedBy string `json:"revoked_by"`
+	}
+
+	// Body is optional
+	if c.Request.ContentLength > 0 {
+		if err := bindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"code":    apierror.InvalidRequest,
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	if req.RevokedBy == "" {
+		req.RevokedBy = "api"
+	}
+
+	login, err := h.logins.Revoke(c.Request.Context(), id, req.RevokedBy)
+	if err != nil {
+		if !errors.Is(err, core.ErrChildLoginNotFound) && !errors.Is(err, core.ErrChildLoginRevoked) {
+			h.logger.Error("Failed to revoke child login",
+				"component", "api.child_logins",
+				"login_id", id,
+				"error", err)
+		}
+		apierror.RespondError(c, err, apierror.InternalError)
+		return
+	}
+
+	c.JSON(http.StatusOK, formatChildLoginResponse(login))
+}
+
+// formatChildLoginResponse formats a login for responses; the token hash is never included
+func formatChildLoginResponse(login *core.ChildLogin) gin.H {
+	response := gin.H{
+		"id":         login.ID,
+		"child_id":   login.ChildID,
+		"user_agent": login.UserAgent,
+		"created_at": login.CreatedAt.Format(time.RFC3339),
+		"expires_at": login.ExpiresAt.Format(time.RFC3339),
+		"revoked":    login.RevokedAt != nil,
+	}
+
+	if login.LastSeenAt != nil {
+		response["last_seen_at"] = login.LastSeenAt.Format(time.RFC3339)
+	}
+	if login.RevokedAt != nil {
+		response["revoked_at"] = login.RevokedAt.Format(time.RFC3339)
+		response["revoked_by"] = login.RevokedBy
+	}
+
+	return response
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"log/slog"
 	"math/rand"
+	"metron/internal/api/apierror"
 	"metron/internal/core"
 	"metron/internal/idgen"
 	"metron/internal/storage"
@@ -16,16 +17,21 @@ import (
 
 // ChildrenHandler handles children-related requests
 type ChildrenHandler struct {
-	storage storage.Storage
-	manager SessionManager
-	logger  *slog.Logger
+	storage      storage.Storage
+	manager      SessionManager
+	limits       LimitScheduleService // Optional: records limit changes in the history
+	familyBudget FamilyBudgetService  // Optional: today's family budget shown in the status
+	logger       *slog.Logger
 }
 
 // SessionManager interface for child status operations
 type SessionManager interface {
 	GetChildStatus(ctx context.Context, childID string) (*core.ChildStatus, error)
+	GetChildrenStatus(ctx context.Context) ([]*core.ChildStatus, error)
 	GrantRewardMinutes(ctx context.Context, childID string, minutes int) error
 	DeductFineMinutes(ctx context.Context, childID string, minutes int) error
+	GetDurationSuggestions(ctx context.Context, childID string) (*core.DurationSuggestions, error)
+	ActivityBudgets(ctx context.Context, childID string) ([]*core.ActivityBudget, error)
 }
 
 // NewChildrenHandler creates a new children handler
@@ -37,6 +43,16 @@ func NewChildrenHandler(storage storage.Storage, manager SessionManager, logger
 	}
 }
 
+// SetLimitSchedule enables recording of limit changes made through child updates
+func (h *ChildrenHandler) SetLimitSchedule(limits LimitScheduleService) {
+	h.limits = limits
+}
+
+// SetFamilyBudget adds the family budget's use today to the children status
+func (h *ChildrenHandler) SetFamilyBudget(familyBudget FamilyBudgetService) {
+	h.familyBudget = familyBudget
+}
+
 // getRandomEmoji returns a random emoji from a predefined list of child-appropriate emojis
 func getRandomEmoji() string {
 	emojis := []string{
@@ -87,7 +103,7 @@ func getRandomEmoji() string {
 }
 
 // ListChildren returns all children
-// GET /children
+// GET /api/v1/children
 func (h *ChildrenHandler) ListChildren(c *gin.Context) {
 	children, err := h.storage.ListChildren(c.Request.Context())
 	if err != nil {
@@ -97,7 +113,7 @@ func (h *ChildrenHandler) ListChildren(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to retrieve children",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -113,6 +129,10 @@ func (h *ChildrenHandler) ListChildren(c *gin.Context) {
 			"weekend_limit":    child.WeekendLimit,
 			"break_rule":       formatBreakRule(child.BreakRule),
 			"downtime_enabled": child.DowntimeEnabled,
+			"allowed_devices":  formatAllowedDevices(child.AllowedDevices),
+			"timezone":         child.Timezone,
+			"grace_minutes":    child.GraceMinutes,
+			"birthdate":        formatBirthdate(child.Birthdate),
 			"created_at":       child.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 			"updated_at":       child.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		})
@@ -122,7 +142,7 @@ func (h *ChildrenHandler) ListChildren(c *gin.Context) {
 }
 
 // GetChild returns a single child by ID
-// GET /children/:id
+// GET /api/v1/children/:id
 func (h *ChildrenHandler) GetChild(c *gin.Context) {
 	childID := c.Param("id")
 
@@ -131,7 +151,7 @@ func (h *ChildrenHandler) GetChild(c *gin.Context) {
 		if err == core.ErrChildNotFound {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error": "Child not found",
-				"code":  "CHILD_NOT_FOUND",
+				"code":  apierror.ChildNotFound,
 			})
 			return
 		}
@@ -143,7 +163,7 @@ func (h *ChildrenHandler) GetChild(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to retrieve child",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -158,12 +178,12 @@ func (h *ChildrenHandler) GetChild(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to retrieve child status",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"id":                   child.ID,
 		"name":                 child.Name,
 		"emoji":                child.Emoji,
@@ -172,6 +192,10 @@ func (h *ChildrenHandler) GetChild(c *gin.Context) {
 		"weekend_limit":        child.WeekendLimit,
 		"break_rule":           formatBreakRule(child.BreakRule),
 		"downtime_enabled":     child.DowntimeEnabled,
+		"allowed_devices":      formatAllowedDevices(child.AllowedDevices),
+		"timezone":             child.Timezone,
+		"grace_minutes":        child.GraceMinutes,
+		"birthdate":            formatBirthdate(child.Birthdate),
 		"created_at":           child.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		"updated_at":           child.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		"today_used":           status.TodayUsed,
@@ -179,11 +203,203 @@ func (h *ChildrenHandler) GetChild(c *gin.Context) {
 		"today_remaining":      status.TodayRemaining,
 		"today_limit":          status.TodayLimit,
 		"sessions_today":       status.SessionsToday,
-	})
+	}
+	formatChildTrackingPause(response, status.TrackingPause)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetChildrenStatus returns every child's status and active sessions in one call
+// GET /api/v1/children/status
+func (h *ChildrenHandler) GetChildrenStatus(c *gin.Context) {
+	statuses, err := h.manager.GetChildrenStatus(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to get children status",
+			"component", "api",
+			"error", err,
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve children status",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	activeSessions, err := h.storage.ListActiveSessions(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to list active sessions for children status",
+			"component", "api",
+			"error", err,
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve children status",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	// Shared sessions are listed under each of their children
+	sessionsByChild := make(map[string][]gin.H)
+	for _, session := range activeSessions {
+		formatted := formatSessionResponse(session)
+		for _, childID := range session.ChildIDs {
+			sessionsByChild[childID] = append(sessionsByChild[childID], formatted)
+		}
+	}
+
+	response := make([]gin.H, 0, len(statuses))
+	for _, status := range statuses {
+		child := status.Child
+		sessions := sessionsByChild[child.ID]
+		if sessions == nil {
+			sessions = []gin.H{}
+		}
+
+		childStatus := gin.H{
+			"id":                   child.ID,
+			"name":                 child.Name,
+			"emoji":                child.Emoji,
+			"weekday_limit":        child.WeekdayLimit,
+			"weekend_limit":        child.WeekendLimit,
+			"break_rule":           formatBreakRule(child.BreakRule),
+			"downtime_enabled":     child.DowntimeEnabled,
+			"allowed_devices":      formatAllowedDevices(child.AllowedDevices),
+			"timezone":             child.Timezone,
+			"grace_minutes":        child.GraceMinutes,
+			"birthdate":            formatBirthdate(child.Birthdate),
+			"created_at":           child.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			"updated_at":           child.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			"today_used":           status.TodayUsed,
+			"today_reward_granted": status.TodayRewardGranted,
+			"today_remaining":      status.TodayRemaining,
+			"today_limit":          status.TodayLimit,
+			"sessions_today":       status.SessionsToday,
+			"usage_percent":        calculateUsagePercent(status.TodayUsed, status.TodayLimit),
+			"active_sessions":      sessions,
+		}
+		formatChildTrackingPause(childStatus, status.TrackingPause)
+		response = append(response, childStatus)
+	}
+
+	result := gin.H{
+		"date":     time.Now().Format("2006-01-02"),
+		"children": response,
+	}
+	if h.familyBudget != nil {
+		today, err := h.familyBudget.Today(c.Request.Context(), time.Now())
+		if err != nil {
+			// The children's status is still useful without the family budget
+			h.logger.Error("Failed to compute family budget for children status",
+				"component", "api",
+				"error", err,
+			)
+		} else {
+			result["family_budget"] = formatFamilyBudgetDay(today)
+		}
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetSuggestions returns suggested session durations for a child
+// GET /api/v1/children/:id/suggestions
+func (h *ChildrenHandler) GetSuggestions(c *gin.Context) {
+	childID := c.Param("id")
+
+	suggestions, err := h.manager.GetDurationSuggestions(c.Request.Context(), childID)
+	if err != nil {
+		if err == core.ErrChildNotFound {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": "Child not found",
+				"code":  apierror.ChildNotFound,
+			})
+			return
+		}
+
+		h.logger.Error("Failed to get duration suggestions",
+			"component", "api",
+			"child_id", childID,
+			"error", err,
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve suggestions",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, formatDurationSuggestions(suggestions))
+}
+
+// GetExemptActivities returns the child's use of each exempt activity's budget today
+// GET /api/v1/children/:id/exempt-activities
+func (h *ChildrenHandler) GetExemptActivities(c *gin.Context) {
+	childID := c.Param("id")
+
+	budgets, err := h.manager.ActivityBudgets(c.Request.Context(), childID)
+	if err != nil {
+		if err == core.ErrChildNotFound {
+			apierror.Respond(c, apierror.ChildNotFound, "Child not found")
+			return
+		}
+
+		h.logger.Error("Failed to get exempt activity budgets",
+			"component", "api",
+			"child_id", childID,
+			"error", err,
+		)
+		apierror.Respond(c, apierror.InternalError, "Failed to retrieve exempt activities")
+		return
+	}
+
+	c.JSON(http.StatusOK, formatActivityBudgets(budgets))
+}
+
+// formatActivityBudgets converts exempt activity budgets to API response format
+// remaining_minutes is null for activities that are not limited
+func formatActivityBudgets(budgets []*core.ActivityBudget) []gin.H {
+	response := make([]gin.H, len(budgets))
+	for i, budget := range budgets {
+		var remaining *int
+		if budget.RemainingMinutes >= 0 {
+			remaining = &budget.RemainingMinutes
+		}
+		deviceIDs := budget.DeviceIDs
+		if deviceIDs == nil {
+			deviceIDs = []string{}
+		}
+		response[i] = gin.H{
+			"activity":          budget.Activity,
+			"device_ids":        deviceIDs,
+			"daily_minutes":     budget.DailyMinutes,
+			"used_minutes":      budget.UsedMinutes,
+			"remaining_minutes": remaining,
+		}
+	}
+	return response
+}
+
+// formatDurationSuggestions converts duration suggestions to API response format
+func formatDurationSuggestions(suggestions *core.DurationSuggestions) gin.H {
+	options := make([]gin.H, 0, len(suggestions.Options))
+	for _, option := range suggestions.Options {
+		options = append(options, gin.H{
+			"minutes": option.Minutes,
+			"kind":    option.Kind,
+		})
+	}
+
+	return gin.H{
+		"child_id":               suggestions.ChildID,
+		"remaining_minutes":      suggestions.RemainingMinutes,
+		"minutes_until_downtime": suggestions.MinutesUntilDowntime,
+		"max_minutes":            suggestions.MaxMinutes,
+		"options":                options,
+	}
 }
 
 // CreateChild creates a new child
-// POST /children
+// POST /api/v1/children
 func (h *ChildrenHandler) CreateChild(c *gin.Context) {
 	var req struct {
 		Name         string `json:"name" binding:"required"`
@@ -191,16 +407,25 @@ func (h *ChildrenHandler) CreateChild(c *gin.Context) {
 		PIN          string `json:"pin,omitempty"`   // Optional 4-digit PIN
 		WeekdayLimit int    `json:"weekday_limit" binding:"required,gt=0"`
 		WeekendLimit int    `json:"weekend_limit" binding:"required,gt=0"`
-		BreakRule    *struct {
-			BreakAfterMinutes    int `json:"break_after_minutes" binding:"required,gt=0"`
-			BreakDurationMinutes int `json:"break_duration_minutes" binding:"required,gt=0"`
+		// Optional device allow-list; empty means all devices
+		AllowedDevices []string `json:"allowed_devices,omitempty"`
+		// Optional IANA timezone override; empty uses the server timezone
+		Timezone string `json:"timezone,omitempty"`
+		// Optional grace allowance past the daily limit (0-30 minutes)
+		GraceMinutes int `json:"grace_minutes,omitempty"`
+		// Optional date of birth (YYYY-MM-DD) for age-based limit profiles
+		Birthdate string `json:"birthdate,omitempty"`
+		BreakRule *struct {
+			BreakAfterMinutes    int    `json:"break_after_minutes" binding:"required,gt=0"`
+			BreakDurationMinutes int    `json:"break_duration_minutes" binding:"required,gt=0"`
+			Action               string `json:"action,omitempty"`
 		} `json:"break_rule,omitempty"`
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Invalid request body",
-			"code":    "INVALID_REQUEST",
+			"code":    apierror.InvalidRequest,
 			"details": err.Error(),
 		})
 		return
@@ -212,14 +437,24 @@ func (h *ChildrenHandler) CreateChild(c *gin.Context) {
 		emoji = getRandomEmoji()
 	}
 
+	birthdate, err := parseBirthdate(req.Birthdate)
+	if err != nil {
+		apierror.Respond(c, apierror.InvalidDateFormat, "birthdate must use the YYYY-MM-DD format")
+		return
+	}
+
 	// Create child model
 	child := &core.Child{
-		ID:           idgen.NewChild(),
-		Name:         req.Name,
-		Emoji:        emoji,
-		PIN:          req.PIN, // Store PIN (can be empty string)
-		WeekdayLimit: req.WeekdayLimit,
-		WeekendLimit: req.WeekendLimit,
+		ID:             idgen.NewChild(),
+		Name:           req.Name,
+		Emoji:          emoji,
+		PIN:            req.PIN, // Store PIN (can be empty string)
+		WeekdayLimit:   req.WeekdayLimit,
+		WeekendLimit:   req.WeekendLimit,
+		AllowedDevices: req.AllowedDevices,
+		Timezone:       req.Timezone,
+		GraceMinutes:   req.GraceMinutes,
+		Birthdate:      birthdate,
 	}
 
 	// Add break rule if provided
@@ -227,6 +462,7 @@ func (h *ChildrenHandler) CreateChild(c *gin.Context) {
 		child.BreakRule = &core.BreakRule{
 			BreakAfterMinutes:    req.BreakRule.BreakAfterMinutes,
 			BreakDurationMinutes: req.BreakRule.BreakDurationMinutes,
+			Action:               req.BreakRule.Action,
 		}
 	}
 
@@ -234,7 +470,7 @@ func (h *ChildrenHandler) CreateChild(c *gin.Context) {
 	if err := child.Validate(); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
-			"code":  "VALIDATION_ERROR",
+			"code":  apierror.ValidationError,
 		})
 		return
 	}
@@ -248,7 +484,7 @@ func (h *ChildrenHandler) CreateChild(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to create child",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -262,13 +498,17 @@ func (h *ChildrenHandler) CreateChild(c *gin.Context) {
 		"weekend_limit":    child.WeekendLimit,
 		"break_rule":       formatBreakRule(child.BreakRule),
 		"downtime_enabled": child.DowntimeEnabled,
+		"allowed_devices":  formatAllowedDevices(child.AllowedDevices),
+		"timezone":         child.Timezone,
+		"grace_minutes":    child.GraceMinutes,
+		"birthdate":        formatBirthdate(child.Birthdate),
 		"created_at":       child.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		"updated_at":       child.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	})
 }
 
 // UpdateChild updates an existing child
-// PATCH /children/:id
+// PATCH /api/v1/children/:id
 func (h *ChildrenHandler) UpdateChild(c *gin.Context) {
 	childID := c.Param("id")
 
@@ -278,7 +518,7 @@ func (h *ChildrenHandler) UpdateChild(c *gin.Context) {
 		if err == core.ErrChildNotFound {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error": "Child not found",
-				"code":  "CHILD_NOT_FOUND",
+				"code":  apierror.ChildNotFound,
 			})
 			return
 		}
@@ -290,7 +530,7 @@ func (h *ChildrenHandler) UpdateChild(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to retrieve child",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -303,22 +543,32 @@ func (h *ChildrenHandler) UpdateChild(c *gin.Context) {
 		WeekdayLimit    *int    `json:"weekday_limit,omitempty"`
 		WeekendLimit    *int    `json:"weekend_limit,omitempty"`
 		DowntimeEnabled *bool   `json:"downtime_enabled,omitempty"`
-		BreakRule       *struct {
-			BreakAfterMinutes    int `json:"break_after_minutes" binding:"required,gt=0"`
-			BreakDurationMinutes int `json:"break_duration_minutes" binding:"required,gt=0"`
+		// Replaces the device allow-list; an empty list allows all devices
+		AllowedDevices *[]string `json:"allowed_devices,omitempty"`
+		// IANA timezone override; an empty string reverts to the server timezone
+		Timezone *string `json:"timezone,omitempty"`
+		// Grace allowance past the daily limit (0-30 minutes; 0 disables)
+		GraceMinutes *int `json:"grace_minutes,omitempty"`
+		// Date of birth (YYYY-MM-DD); an empty string removes it
+		Birthdate *string `json:"birthdate,omitempty"`
+		BreakRule *struct {
+			BreakAfterMinutes    int    `json:"break_after_minutes" binding:"required,gt=0"`
+			BreakDurationMinutes int    `json:"break_duration_minutes" binding:"required,gt=0"`
+			Action               string `json:"action,omitempty"`
 		} `json:"break_rule,omitempty"`
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Invalid request body",
-			"code":    "INVALID_REQUEST",
+			"code":    apierror.InvalidRequest,
 			"details": err.Error(),
 		})
 		return
 	}
 
 	// Update fields if provided
+	previousWeekday, previousWeekend := child.WeekdayLimit, child.WeekendLimit
 	if req.Name != nil {
 		child.Name = *req.Name
 	}
@@ -342,10 +592,28 @@ func (h *ChildrenHandler) UpdateChild(c *gin.Context) {
 	if req.DowntimeEnabled != nil {
 		child.DowntimeEnabled = *req.DowntimeEnabled
 	}
+	if req.AllowedDevices != nil {
+		child.AllowedDevices = *req.AllowedDevices
+	}
+	if req.Timezone != nil {
+		child.Timezone = *req.Timezone
+	}
+	if req.GraceMinutes != nil {
+		child.GraceMinutes = *req.GraceMinutes
+	}
+	if req.Birthdate != nil {
+		birthdate, err := parseBirthdate(*req.Birthdate)
+		if err != nil {
+			apierror.Respond(c, apierror.InvalidDateFormat, "birthdate must use the YYYY-MM-DD format")
+			return
+		}
+		child.Birthdate = birthdate
+	}
 	if req.BreakRule != nil {
 		child.BreakRule = &core.BreakRule{
 			BreakAfterMinutes:    req.BreakRule.BreakAfterMinutes,
 			BreakDurationMinutes: req.BreakRule.BreakDurationMinutes,
+			Action:               req.BreakRule.Action,
 		}
 	}
 
@@ -353,7 +621,7 @@ func (h *ChildrenHandler) UpdateChild(c *gin.Context) {
 	if err := child.Validate(); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": err.Error(),
-			"code":  "VALIDATION_ERROR",
+			"code":  apierror.ValidationError,
 		})
 		return
 	}
@@ -367,11 +635,22 @@ func (h *ChildrenHandler) UpdateChild(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to update child",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
 
+	// The update is saved; a failure to record its history is only logged
+	if h.limits != nil {
+		if err := h.limits.RecordChange(c.Request.Context(), child, previousWeekday, previousWeekend, "api"); err != nil {
+			h.logger.Error("Failed to record limit change",
+				"component", "api",
+				"child_id", childID,
+				"error", err,
+			)
+		}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"id":               child.ID,
 		"name":             child.Name,
@@ -381,13 +660,17 @@ func (h *ChildrenHandler) UpdateChild(c *gin.Context) {
 		"weekend_limit":    child.WeekendLimit,
 		"break_rule":       formatBreakRule(child.BreakRule),
 		"downtime_enabled": child.DowntimeEnabled,
+		"allowed_devices":  formatAllowedDevices(child.AllowedDevices),
+		"timezone":         child.Timezone,
+		"grace_minutes":    child.GraceMinutes,
+		"birthdate":        formatBirthdate(child.Birthdate),
 		"created_at":       child.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		"updated_at":       child.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	})
 }
 
 // GrantReward grants reward minutes to a child
-// POST /children/:id/rewards
+// POST /api/v1/children/:id/rewards
 func (h *ChildrenHandler) GrantReward(c *gin.Context) {
 	childID := c.Param("id")
 
@@ -395,10 +678,10 @@ func (h *ChildrenHandler) GrantReward(c *gin.Context) {
 		Minutes int `json:"minutes" binding:"required,gt=0"`
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Invalid request body",
-			"code":    "INVALID_REQUEST",
+			"code":    apierror.InvalidRequest,
 			"details": err.Error(),
 		})
 		return
@@ -409,7 +692,7 @@ func (h *ChildrenHandler) GrantReward(c *gin.Context) {
 	if !validMinutes[req.Minutes] {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Minutes must be one of: 15, 30, or 60",
-			"code":  "INVALID_MINUTES",
+			"code":  apierror.InvalidMinutes,
 		})
 		return
 	}
@@ -419,7 +702,7 @@ func (h *ChildrenHandler) GrantReward(c *gin.Context) {
 		if err == core.ErrChildNotFound {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error": "Child not found",
-				"code":  "CHILD_NOT_FOUND",
+				"code":  apierror.ChildNotFound,
 			})
 			return
 		}
@@ -432,7 +715,7 @@ func (h *ChildrenHandler) GrantReward(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to grant reward minutes",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -447,7 +730,7 @@ func (h *ChildrenHandler) GrantReward(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to retrieve updated child status",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -462,7 +745,7 @@ func (h *ChildrenHandler) GrantReward(c *gin.Context) {
 }
 
 // DeductFine deducts fine minutes from a child
-// POST /children/:id/fines
+// POST /api/v1/children/:id/fines
 func (h *ChildrenHandler) DeductFine(c *gin.Context) {
 	childID := c.Param("id")
 
@@ -470,10 +753,10 @@ func (h *ChildrenHandler) DeductFine(c *gin.Context) {
 		Minutes int `json:"minutes" binding:"required,gt=0"`
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Invalid request body",
-			"code":    "INVALID_REQUEST",
+			"code":    apierror.InvalidRequest,
 			"details": err.Error(),
 		})
 		return
@@ -484,7 +767,7 @@ func (h *ChildrenHandler) DeductFine(c *gin.Context) {
 	if !validMinutes[req.Minutes] {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Minutes must be one of: 15, 30, or 60",
-			"code":  "INVALID_MINUTES",
+			"code":  apierror.InvalidMinutes,
 		})
 		return
 	}
@@ -494,7 +777,7 @@ func (h *ChildrenHandler) DeductFine(c *gin.Context) {
 		if err == core.ErrChildNotFound {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error": "Child not found",
-				"code":  "CHILD_NOT_FOUND",
+				"code":  apierror.ChildNotFound,
 			})
 			return
 		}
@@ -503,7 +786,7 @@ func (h *ChildrenHandler) DeductFine(c *gin.Context) {
 		if strings.HasPrefix(err.Error(), "insufficient time") {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": err.Error(),
-				"code":  "INSUFFICIENT_TIME",
+				"code":  apierror.InsufficientTime,
 			})
 			return
 		}
@@ -516,7 +799,7 @@ func (h *ChildrenHandler) DeductFine(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to deduct fine minutes",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -531,7 +814,7 @@ func (h *ChildrenHandler) DeductFine(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to retrieve updated child status",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -552,7 +835,7 @@ func (h *ChildrenHandler) DeductFine(c *gin.Context) {
 }
 
 // DeleteChild deletes a child
-// DELETE /children/:id
+// DELETE /api/v1/children/:id
 func (h *ChildrenHandler) DeleteChild(c *gin.Context) {
 	childID := c.Param("id")
 
@@ -562,7 +845,7 @@ func (h *ChildrenHandler) DeleteChild(c *gin.Context) {
 		if err == core.ErrChildNotFound {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error": "Child not found",
-				"code":  "CHILD_NOT_FOUND",
+				"code":  apierror.ChildNotFound,
 			})
 			return
 		}
@@ -574,7 +857,7 @@ func (h *ChildrenHandler) DeleteChild(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to retrieve child",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -588,7 +871,7 @@ func (h *ChildrenHandler) DeleteChild(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to delete child",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -600,8 +883,41 @@ func formatBreakRule(rule *core.BreakRule) interface{} {
 	if rule == nil {
 		return nil
 	}
+	action := rule.Action
+	if action == "" {
+		action = core.BreakActionWarn
+	}
 	return gin.H{
 		"break_after_minutes":    rule.BreakAfterMinutes,
 		"break_duration_minutes": rule.BreakDurationMinutes,
+		"action":                 action,
+	}
+}
+
+// parseBirthdate parses an optional YYYY-MM-DD birthdate (nil when empty)
+func parseBirthdate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	birthdate, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	return &birthdate, nil
+}
+
+// formatBirthdate formats a birthdate as YYYY-MM-DD (null when unset)
+func formatBirthdate(birthdate *time.Time) interface{} {
+	if birthdate == nil {
+		return nil
+	}
+	return birthdate.Format("2006-01-02")
+}
+
+// formatAllowedDevices returns the device allow-list (an empty list means all devices)
+func formatAllowedDevices(deviceIDs []string) []string {
+	if deviceIDs == nil {
+		return []string{}
 	}
+	return deviceIDs
 }
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"metron/internal/api/apierror"
+	"metron/internal/core"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DayRolloverLister lists the days closed out at rollover
+type DayRolloverLister interface {
+	List(ctx context.Context, since time.Time, childID string) ([]*core.DayRollover, error)
+}
+
+// DayRolloversHandler handles day rollover (new day event) queries
+type DayRolloversHandler struct {
+	rollovers DayRolloverLister
+	logger    *slog.Logger
+}
+
+// NewDayRolloversHandler creates a new day rollovers handler
+func NewDayRolloversHandler(rollovers DayRolloverLister, logger *slog.Logger) *DayRolloversHandler {
+	return &DayRolloversHandler{
+		rollovers: rollovers,
+		logger:    logger,
+	}
+}
+
+// ListDayRollovers returns the days closed out after an optional time, oldest first
+// GET /day-rollovers?since=RFC3339&child_id=xxx
+func (h *DayRolloversHandler) ListDayRollovers(c *gin.Context) {
+	var since time.Time
+	if raw := c.Query("since"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "since must be an RFC3339 timestamp",
+				"code":  apierror.InvalidRequest,
+			})
+			return
+		}
+		since = parsed
+	}
+	childID := c.Query("child_id")
+
+	rollovers, err := h.rollovers.List(c.Request.Context(), since, childID)
+	if err != nil {
+		h.logger.Error("Failed to list day rollovers",
+			"component", "api.day_rollovers",
+			"child_id", childID,
+			"error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve day rollovers",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	response := make([]gin.H, len(rollovers))
+	for i, rollover := range rollovers {
+		response[i] = formatDayRolloverResponse(rollover)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"rollovers": response,
+	})
+}
+
+// formatDayRolloverResponse formats a day rollover for responses
+func formatDayRolloverResponse(rollover *core.DayRollover) gin.H {
+	return gin.H{
+		"id":             rollover.ID,
+		"child_id":       rollover.ChildID,
+		"date":           rollover.Date.Format("2006-01-02"),
+		"limit_minutes":  rollover.LimitMinutes,
+		"used_minutes":   rollover.UsedMinutes,
+		"unused_minutes": rollover.UnusedMinutes(),
+		"session_count":  rollover.SessionCount,
+		"created_at":     rollover.CreatedAt.Format(time.RFC3339Nano),
+	}
+}
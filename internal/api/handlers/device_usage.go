@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"metron/internal/api/apierror"
+	"metron/internal/core"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeviceUsageService defines the device usage operations needed by the handler
+type DeviceUsageService interface {
+	Report(ctx context.Context, from, to time.Time) (*core.DeviceReport, error)
+}
+
+// DeviceUsageHandler handles device usage report requests
+type DeviceUsageHandler struct {
+	usage  DeviceUsageService
+	logger *slog.Logger
+}
+
+// NewDeviceUsageHandler creates a new device usage handler
+func NewDeviceUsageHandler(usage DeviceUsageService, logger *slog.Logger) *DeviceUsageHandler {
+	return &DeviceUsageHandler{
+		usage:  usage,
+		logger: logger,
+	}
+}
+
+// GetDeviceUsage returns each device's use over a range of days: totals, peak hours and device
+// minutes per day, answering which device the children use most
+// GET /reports/devices?from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *DeviceUsageHandler) GetDeviceUsage(c *gin.Context) {
+	// Dates are calendar days; without them the last 7 days up to today are returned
+	from, ok := parseDateQuery(c, "from")
+	if !ok {
+		return
+	}
+	to, ok := parseDateQuery(c, "to")
+	if !ok {
+		return
+	}
+
+	report, err := h.usage.Report(c.Request.Context(), from, to)
+	if err != nil {
+		if _, ok := apierror.FromError(err); ok {
+			apierror.RespondError(c, err, apierror.InternalError)
+			return
+		}
+		h.logger.Error("Failed to compute device usage",
+			"component", "api",
+			"error", err,
+		)
+		apierror.Respond(c, apierror.InternalError, "Failed to retrieve device usage")
+		return
+	}
+
+	devices := make([]gin.H, len(report.Devices))
+	for i, device := range report.Devices {
+		var peakHour *int
+		if device.PeakHour >= 0 {
+			peakHour = &device.PeakHour
+		}
+		devices[i] = gin.H{
+			"device_id":       device.DeviceID,
+			"device_type":     device.DeviceType,
+			"device_minutes":  device.DeviceMinutes,
+			"child_minutes":   device.ChildMinutes,
+			"exempt_minutes":  device.ExemptMinutes,
+			"sessions":        device.Sessions,
+			"days_used":       device.DaysUsed,
+			"average_minutes": roundTenth(device.AverageMinutes),
+			"share_percent":   roundTenth(device.SharePercent),
+			"peak_hour":       peakHour,
+			"heatmap":         device.Heatmap,
+		}
+	}
+
+	days := make([]gin.H, len(report.Days))
+	for i, day := range report.Days {
+		usage := make([]gin.H, len(day.Devices))
+		for j, device := range day.Devices {
+			usage[j] = gin.H{
+				"device_id":      device.DeviceID,
+				"device_type":    device.DeviceType,
+				"device_minutes": device.DeviceMinutes,
+				"child_minutes":  device.ChildMinutes,
+				"exempt_minutes": device.ExemptMinutes,
+				"sessions":       device.Sessions,
+			}
+		}
+		days[i] = gin.H{
+			"date":    day.Date.Format("2006-01-02"),
+			"devices": usage,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"from":    report.From.Format("2006-01-02"),
+		"to":      report.To.Format("2006-01-02"),
+		"devices": devices,
+		"days":    days,
+	})
+}
@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"context"
 	"log/slog"
+	"metron/internal/core"
 	"metron/internal/devices"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -12,9 +15,21 @@ import (
 type DevicesHandler struct {
 	deviceRegistry *devices.Registry
 	driverRegistry DriverRegistry
+	heartbeats     HeartbeatLister     // Optional: adds last-seen times
+	sessions       ActiveSessionLister // Optional: adds when busy devices are free again
 	logger         *slog.Logger
 }
 
+// ActiveSessionLister returns the sessions that have not ended
+type ActiveSessionLister interface {
+	ListActiveSessions(ctx context.Context) ([]*core.Session, error)
+}
+
+// HeartbeatLister returns when devices last checked in, keyed by device ID
+type HeartbeatLister interface {
+	LastSeen(ctx context.Context) (map[string]*core.DeviceHeartbeat, error)
+}
+
 // DriverRegistry interface for accessing device drivers
 type DriverRegistry interface {
 	List() []string
@@ -30,11 +45,40 @@ func NewDevicesHandler(deviceRegistry *devices.Registry, driverRegistry DriverRe
 	}
 }
 
+// SetHeartbeats enables last-seen times in device responses
+func (h *DevicesHandler) SetHeartbeats(heartbeats HeartbeatLister) {
+	h.heartbeats = heartbeats
+}
+
+// SetSessions enables busy_until in device responses
+func (h *DevicesHandler) SetSessions(sessions ActiveSessionLister) {
+	h.sessions = sessions
+}
+
 // ListDevices returns all available devices
 // GET /devices
 func (h *DevicesHandler) ListDevices(c *gin.Context) {
 	deviceList := h.deviceRegistry.List()
 
+	// Last-seen times are informational; list devices without them on error
+	var lastSeen map[string]*core.DeviceHeartbeat
+	if h.heartbeats != nil {
+		var err error
+		lastSeen, err = h.heartbeats.LastSeen(c.Request.Context())
+		if err != nil {
+			h.logger.Error("Failed to get device heartbeats",
+				"component", "api",
+				"error", err,
+			)
+		}
+	}
+
+	// So is when busy devices are free again: the planned end of their last running session
+	var busy map[string]*core.Session
+	if h.sessions != nil {
+		busy = h.busySessions(c.Request.Context())
+	}
+
 	response := make([]gin.H, 0, len(deviceList))
 	for _, device := range deviceList {
 		deviceInfo := gin.H{
@@ -45,6 +89,17 @@ func (h *DevicesHandler) ListDevices(c *gin.Context) {
 		if device.Emoji != "" {
 			deviceInfo["emoji"] = device.Emoji
 		}
+		if heartbeat, ok := lastSeen[device.ID]; ok {
+			deviceInfo["last_seen_at"] = heartbeat.LastSeenAt.Format(time.RFC3339)
+			deviceInfo["last_seen_source"] = heartbeat.Source
+		}
+		if h.sessions != nil {
+			deviceInfo["busy_until"] = nil
+			if session, ok := busy[device.ID]; ok {
+				deviceInfo["busy_until"] = core.BusyUntil(session).Format(time.RFC3339)
+				deviceInfo["active_session_id"] = session.ID
+			}
+		}
 
 		// Get driver capabilities
 		driver, err := h.driverRegistry.Get(device.Driver)
@@ -60,14 +115,14 @@ func (h *DevicesHandler) ListDevices(c *gin.Context) {
 			continue
 		}
 
-		// Add driver capabilities
-		if capableDriver, ok := driver.(devices.CapableDriver); ok {
-			caps := capableDriver.Capabilities()
-			deviceInfo["capabilities"] = gin.H{
-				"supports_warnings":   caps.SupportsWarnings,
-				"supports_live_state": caps.SupportsLiveState,
-				"supports_scheduling": caps.SupportsScheduling,
-			}
+		// Add driver capabilities (the same ones core uses to decide which calls to make)
+		caps := core.CapabilitiesOf(driver)
+		deviceInfo["capabilities"] = gin.H{
+			"supports_warnings":   caps.SupportsWarnings,
+			"supports_live_state": caps.SupportsLiveState,
+			"supports_scheduling": caps.SupportsScheduling,
+			"supports_extension":  caps.SupportsExtension,
+			"supports_breaks":     caps.SupportsBreaks,
 		}
 
 		response = append(response, deviceInfo)
@@ -75,3 +130,26 @@ func (h *DevicesHandler) ListDevices(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+// busySessions returns the running session that ends last on each busy device
+func (h *DevicesHandler) busySessions(ctx context.Context) map[string]*core.Session {
+	active, err := h.sessions.ListActiveSessions(ctx)
+	if err != nil {
+		h.logger.Error("Failed to list active sessions",
+			"component", "api",
+			"error", err,
+		)
+		return nil
+	}
+
+	busy := make(map[string]*core.Session)
+	for _, session := range active {
+		if !session.IsRunning() {
+			continue
+		}
+		if current, ok := busy[session.DeviceID]; !ok || core.BusyUntil(session).After(core.BusyUntil(current)) {
+			busy[session.DeviceID] = session
+		}
+	}
+	return busy
+}
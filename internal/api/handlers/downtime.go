@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"log/slog"
+	"metron/internal/api/apierror"
 	"net/http"
 	"time"
 
@@ -28,7 +29,7 @@ func NewDowntimeHandler(storage core.DowntimeSkipStorage, downtime *core.Downtim
 }
 
 // SkipDowntimeToday skips downtime for today (all children)
-// POST /v1/downtime/skip-today
+// POST /api/v1/downtime/skip-today
 func (h *DowntimeHandler) SkipDowntimeToday(c *gin.Context) {
 	ctx := c.Request.Context()
 	today := time.Now()
@@ -37,7 +38,7 @@ func (h *DowntimeHandler) SkipDowntimeToday(c *gin.Context) {
 		h.logger.Error("Failed to set downtime skip date", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to skip downtime",
-			"code":  "SKIP_DOWNTIME_ERROR",
+			"code":  apierror.SkipDowntimeError,
 		})
 		return
 	}
@@ -52,7 +53,7 @@ func (h *DowntimeHandler) SkipDowntimeToday(c *gin.Context) {
 }
 
 // GetSkipStatus returns the current skip status for downtime
-// GET /v1/downtime/skip-status
+// GET /api/v1/downtime/skip-status
 func (h *DowntimeHandler) GetSkipStatus(c *gin.Context) {
 	ctx := c.Request.Context()
 	now := time.Now()
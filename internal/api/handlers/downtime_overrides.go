@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"metron/internal/api/apierror"
+	"metron/internal/core"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DowntimeOverrideService defines the downtime override operations needed by the handler
+type DowntimeOverrideService interface {
+	Active(ctx context.Context, childID string) ([]*core.DowntimeOverride, error)
+	GrantForDay(ctx context.Context, childID, grantedBy, reason string) (*core.DowntimeOverride, error)
+	Cancel(ctx context.Context, id, cancelledBy string) (*core.DowntimeOverride, error)
+}
+
+// DowntimeOverridesHandler lets parents lift a child's downtime until the end of the day
+// and see or cancel the overrides granted for sessions
+type DowntimeOverridesHandler struct {
+	overrides DowntimeOverrideService
+	logger    *slog.Logger
+}
+
+// NewDowntimeOverridesHandler creates a new downtime overrides handler
+func NewDowntimeOverridesHandler(overrides DowntimeOverrideService, logger *slog.Logger) *DowntimeOverridesHandler {
+	return &DowntimeOverridesHandler{
+		overrides: overrides,
+		logger:    logger,
+	}
+}
+
+// ListDowntimeOverrides returns the active overrides, optionally of one child, oldest first
+// GET /downtime/overrides?child_id=xxx
+func (h *DowntimeOverridesHandler) ListDowntimeOverrides(c *gin.Context) {
+	childID := c.Query("child_id")
+
+	overrides, err := h.overrides.Active(c.Request.Context(), childID)
+	if err != nil {
+		h.logger.Error("Failed to list downtime overrides",
+			"component", "api.downtime_overrides",
+			"child_id", childID,
+			"error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve downtime overrides",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	response := make([]gin.H, len(overrides))
+	for i, override := range overrides {
+		response[i] = formatDowntimeOverrideResponse(override)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"overrides": response,
+	})
+}
+
+// GrantDowntimeOverride lifts a child's downtime until the end of the child's day
+// POST /downtime/overrides
+func (h *DowntimeOverridesHandler) GrantDowntimeOverride(c *gin.Context) {
+	var req struct {
+		ChildID   string `json:"child_id" binding:"required"`
+		Reason    string `json:"reason"`
+		GrantedBy string `json:"granted_by"`
+	}
+
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"code":    apierror.InvalidRequest,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	if req.GrantedBy == "" {
+		req.GrantedBy = "api"
+	}
+
+	override, err := h.overrides.GrantForDay(c.Request.Context(), req.ChildID, req.GrantedBy, req.Reason)
+	if err != nil {
+		if errors.Is(err, core.ErrDowntimeAlreadyOverridden) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error":    "Downtime is already overridden for the rest of the day",
+				"code":     apierror.DowntimeAlreadyOverridden,
+				"override": formatDowntimeOverrideResponse(override),
+			})
+			return
+		}
+
+		if !errors.Is(err, core.ErrChildNotFound) {
+			h.logger.Error("Failed to override downtime",
+				"component", "api.downtime_overrides",
+				"child_id", req.ChildID,
+				"granted_by", req.GrantedBy,
+				"error", err)
+		}
+		apierror.RespondError(c, err, apierror.InternalError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, formatDowntimeOverrideResponse(override))
+}
+
+// CancelDowntimeOverride ends an override now, so downtime applies again
+// DELETE /downtime/overrides/:id
+func (h *DowntimeOverridesHandler) CancelDowntimeOverride(c *gin.Context) {
+	id := c.Param("id")
+
+	var req struct {
+		CancelledBy string `json:"cancelled_by"`
+	}
+
+	// Body is optional
+	if c.Request.ContentLength > 0 {
+		if err := bindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"code":    apierror.InvalidRequest,
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	if req.CancelledBy == "" {
+		req.CancelledBy = "api"
+	}
+
+	override, err := h.overrides.Cancel(c.Request.Context(), id, req.CancelledBy)
+	if err != nil {
+		if !errors.Is(err, core.ErrDowntimeOverrideNotFound) && !errors.Is(err, core.ErrDowntimeOverrideEnded) {
+			h.logger.Error("Failed to cancel downtime override",
+				"component", "api.downtime_overrides",
+				"override_id", id,
+				"error", err)
+		}
+		apierror.RespondError(c, err, apierror.InternalError)
+		return
+	}
+
+	c.JSON(http.StatusOK, formatDowntimeOverrideResponse(override))
+}
+
+func formatDowntimeOverrideResponse(override *core.DowntimeOverride) gin.H {
+	response := gin.H{
+		"id":         override.ID,
+		"child_id":   override.ChildID,
+		"reason":     override.Reason,
+		"granted_by": override.GrantedBy,
+		"started_at": override.StartedAt.Format(time.RFC3339),
+		"active":     override.IsActive(time.Now()),
+	}
+
+	if override.SessionID != "" {
+		response["session_id"] = override.SessionID
+	}
+	if override.ExpiresAt != nil {
+		response["expires_at"] = override.ExpiresAt.Format(time.RFC3339)
+	}
+	if override.EndedAt != nil {
+		response["ended_at"] = override.EndedAt.Format(time.RFC3339)
+		response["ended_by"] = override.EndedBy
+	}
+
+	return response
+}
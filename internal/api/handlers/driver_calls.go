@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"metron/internal/api/apierror"
+	"metron/internal/core"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// defaultDriverCallLimit is how many driver calls are returned without a limit
+const defaultDriverCallLimit = 100
+
+// DriverCallLister lists the recorded driver calls
+type DriverCallLister interface {
+	List(ctx context.Context, filter core.DriverCallFilter) ([]*core.DriverCall, error)
+}
+
+// DriverCallsHandler handles driver call history queries
+type DriverCallsHandler struct {
+	calls  DriverCallLister
+	logger *slog.Logger
+}
+
+// NewDriverCallsHandler creates a new driver calls handler
+func NewDriverCallsHandler(calls DriverCallLister, logger *slog.Logger) *DriverCallsHandler {
+	return &DriverCallsHandler{
+		calls:  calls,
+		logger: logger,
+	}
+}
+
+// ListDriverCalls returns the recorded driver calls, newest first
+// GET /admin/driver-calls?driver=xxx&device_id=xxx&session_id=xxx&result=ok|failed|timeout&limit=100
+func (h *DriverCallsHandler) ListDriverCalls(c *gin.Context) {
+	filter := core.DriverCallFilter{
+		Driver:    c.Query("driver"),
+		DeviceID:  c.Query("device_id"),
+		SessionID: c.Query("session_id"),
+		Result:    c.Query("result"),
+		Limit:     defaultDriverCallLimit,
+	}
+
+	switch filter.Result {
+	case "", core.DriverCallOK, core.DriverCallFailed, core.DriverCallTimedOut:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "result must be one of: ok, failed, timeout",
+			"code":  apierror.InvalidRequest,
+		})
+		return
+	}
+
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil || limit < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "limit must be a positive number",
+				"code":  apierror.InvalidRequest,
+			})
+			return
+		}
+		filter.Limit = limit
+	}
+
+	calls, err := h.calls.List(c.Request.Context(), filter)
+	if err != nil {
+		h.logger.Error("Failed to list driver calls",
+			"component", "api.driver_calls",
+			"error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve driver calls",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	response := make([]gin.H, len(calls))
+	for i, call := range calls {
+		response[i] = formatDriverCallResponse(call)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"calls": response,
+	})
+}
+
+// formatDriverCallResponse formats a driver call for responses
+func formatDriverCallResponse(call *core.DriverCall) gin.H {
+	return gin.H{
+		"id":          call.ID,
+		"driver":      call.Driver,
+		"device_id":   call.DeviceID,
+		"session_id":  call.SessionID,
+		"action":      call.Action,
+		"started_at":  call.StartedAt.Format(time.RFC3339Nano),
+		"duration_ms": call.Duration.Milliseconds(),
+		"result":      call.Result,
+		"error":       call.Error,
+	}
+}
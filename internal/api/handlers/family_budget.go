@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"metron/internal/api/apierror"
+	"metron/internal/core"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxFamilyBudgetDays is the longest history GET /reports/family-budget returns
+const maxFamilyBudgetDays = 90
+
+// FamilyBudgetService defines the family budget operations needed by the handlers
+type FamilyBudgetService interface {
+	Budget() core.FamilyBudget
+	Today(ctx context.Context, now time.Time) (*core.FamilyBudgetDay, error)
+	Days(ctx context.Context, now time.Time, n int) ([]*core.FamilyBudgetDay, error)
+}
+
+// FamilyBudgetHandler handles family budget report requests
+type FamilyBudgetHandler struct {
+	budget FamilyBudgetService
+	logger *slog.Logger
+}
+
+// NewFamilyBudgetHandler creates a new family budget handler
+func NewFamilyBudgetHandler(budget FamilyBudgetService, logger *slog.Logger) *FamilyBudgetHandler {
+	return &FamilyBudgetHandler{
+		budget: budget,
+		logger: logger,
+	}
+}
+
+// GetFamilyBudget returns the family budget and its use per day, oldest first
+// GET /reports/family-budget?days=7
+func (h *FamilyBudgetHandler) GetFamilyBudget(c *gin.Context) {
+	days, ok := parseFamilyBudgetDays(c)
+	if !ok {
+		return
+	}
+
+	history, err := h.budget.Days(c.Request.Context(), time.Now(), days)
+	if err != nil {
+		h.logger.Error("Failed to compute family budget",
+			"component", "api",
+			"error", err,
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve family budget",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	formatted := make([]gin.H, len(history))
+	for i, day := range history {
+		formatted[i] = formatFamilyBudgetDay(day)
+	}
+
+	budget := h.budget.Budget()
+	c.JSON(http.StatusOK, gin.H{
+		"limit_minutes": budget.Minutes,
+		"device_ids":    nonNilStrings(budget.DeviceIDs),
+		"device_types":  nonNilStrings(budget.DeviceTypes),
+		"today":         formatted[len(formatted)-1],
+		"days":          formatted,
+	})
+}
+
+func formatFamilyBudgetDay(day *core.FamilyBudgetDay) gin.H {
+	return gin.H{
+		"date":              day.Date.Format("2006-01-02"),
+		"limit_minutes":     day.LimitMinutes,
+		"used_minutes":      day.UsedMinutes,
+		"committed_minutes": day.CommittedMinutes,
+		"remaining_minutes": day.RemainingMinutes,
+		"sessions":          day.Sessions,
+	}
+}
+
+// nonNilStrings returns an empty slice for nil, so it is encoded as [] rather than null
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// parseFamilyBudgetDays reads the days query parameter of the family budget report (default 7)
+// Responds with 400 and returns false if it is invalid.
+func parseFamilyBudgetDays(c *gin.Context) (int, bool) {
+	raw := c.Query("days")
+	if raw == "" {
+		return 7, true
+	}
+	days, err := strconv.Atoi(raw)
+	if err != nil || days < 1 || days > maxFamilyBudgetDays {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "days must be a number between 1 and 90",
+			"code":  apierror.InvalidRequest,
+		})
+		return 0, false
+	}
+	return days, true
+}
@@ -0,0 +1,483 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"metron/internal/core"
+	"metron/internal/graphql"
+	"metron/internal/storage"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GraphQL query limits
+// The deepest useful query (children → sessions → children → today → limit) is 5 levels.
+const (
+	GraphQLMaxDepth      = 8
+	GraphQLMaxComplexity = 25000
+	graphQLMaxListLimit  = 500 // Highest "limit" argument of list fields
+)
+
+// ChildStatusProvider returns a child's time budget and usage today
+type ChildStatusProvider interface {
+	GetChildStatus(ctx context.Context, childID string) (*core.ChildStatus, error)
+}
+
+// GraphQLHandler serves read-only GraphQL queries joining children, sessions, allocations and rewards
+// It lets dashboards fetch what would take many REST requests in one round trip.
+type GraphQLHandler struct {
+	schema *graphql.Schema
+	limits graphql.Limits
+}
+
+// NewGraphQLHandler creates a new GraphQL handler
+// Children and sessions are read from storage (the read replica when there is one).
+func NewGraphQLHandler(storage storage.Reader, status ChildStatusProvider, allocations AllocationHistory, logger *slog.Logger) *GraphQLHandler {
+	resolvers := &graphQLResolvers{
+		storage:     storage,
+		status:      status,
+		allocations: allocations,
+		logger:      logger,
+	}
+	schema, err := graphql.NewSchema(resolvers.queryType())
+	if err != nil {
+		panic(err) // The schema is static, so this is a programming error
+	}
+	return &GraphQLHandler{
+		schema: schema,
+		limits: graphql.Limits{MaxDepth: GraphQLMaxDepth, MaxComplexity: GraphQLMaxComplexity},
+	}
+}
+
+// Query runs a GraphQL query
+// POST /graphql with {"query", "operationName", "variables"}
+// GET /graphql?query=&operationName=&variables= (variables as JSON)
+// Executed queries answer 200 with "data" and field "errors"; invalid ones answer 200 with
+// "errors" only, as GraphQL clients expect. Malformed requests get 400.
+func (h *GraphQLHandler) Query(c *gin.Context) {
+	var req graphql.Request
+	if c.Request.Method == http.MethodGet {
+		req.Query = c.Query("query")
+		req.OperationName = c.Query("operationName")
+		if variables := c.Query("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				h.badRequest(c, "variables must be a JSON object")
+				return
+			}
+		}
+	} else if err := bindJSON(c, &req); err != nil {
+		h.badRequest(c, "Invalid request body: "+err.Error())
+		return
+	}
+	if req.Query == "" {
+		h.badRequest(c, "query is required")
+		return
+	}
+
+	response := graphql.Execute(c.Request.Context(), h.schema, req, h.limits)
+	c.JSON(http.StatusOK, response)
+}
+
+// Schema returns the schema in the GraphQL schema definition language
+// GET /graphql/schema
+func (h *GraphQLHandler) Schema(c *gin.Context) {
+	c.String(http.StatusOK, h.schema.SDL())
+}
+
+// badRequest responds 400 with a GraphQL error
+func (h *GraphQLHandler) badRequest(c *gin.Context, message string) {
+	c.JSON(http.StatusBadRequest, &graphql.Response{Errors: []*graphql.Error{{Message: message}}})
+}
+
+// graphQLResolvers builds the schema and resolves its fields
+type graphQLResolvers struct {
+	storage     storage.Reader
+	status      ChildStatusProvider
+	allocations AllocationHistory
+	logger      *slog.Logger
+}
+
+// rewardDay is the net bonus of a child's day: rewards granted minus fines
+type rewardDay struct {
+	date    time.Time
+	minutes int
+}
+
+func (r *graphQLResolvers) queryType() *graphql.Object {
+	today := graphql.NewObject("Today", "A child's time budget and usage today").
+		AddField(property("limit", nonNull(graphql.Int), "Minutes available today, rewards included",
+			func(s *core.ChildStatus) any { return s.TodayLimit })).
+		AddField(property("used", nonNull(graphql.Int), "Minutes used today",
+			func(s *core.ChildStatus) any { return s.TodayUsed })).
+		AddField(property("remaining", nonNull(graphql.Int), "Minutes left today",
+			func(s *core.ChildStatus) any { return s.TodayRemaining })).
+		AddField(property("reward", nonNull(graphql.Int), "Bonus minutes granted for today",
+			func(s *core.ChildStatus) any { return s.TodayRewardGranted })).
+		AddField(property("sessionCount", nonNull(graphql.Int), "",
+			func(s *core.ChildStatus) any { return s.SessionsToday })).
+		AddField(property("trackingPaused", nonNull(graphql.Bool), "Whether usage is not tracked (vacation mode)",
+			func(s *core.ChildStatus) any { return s.TrackingPause != nil }))
+
+	allocation := graphql.NewObject("Allocation", "A child's time budget for a day").
+		AddField(property("date", nonNull(graphql.Date), "",
+			func(a *core.DailyTimeAllocation) any { return a.Date.Format("2006-01-02") })).
+		AddField(property("baseLimit", nonNull(graphql.Int), "Weekday or weekend limit in minutes",
+			func(a *core.DailyTimeAllocation) any { return a.BaseLimit })).
+		AddField(property("bonusGranted", nonNull(graphql.Int), "Net bonus minutes (rewards minus fines)",
+			func(a *core.DailyTimeAllocation) any { return a.BonusGranted })).
+		AddField(property("totalAvailable", nonNull(graphql.Int), "",
+			func(a *core.DailyTimeAllocation) any { return a.BaseLimit + a.BonusGranted }))
+
+	reward := graphql.NewObject("Reward", "Bonus minutes of a day; negative when fines exceed rewards").
+		AddField(property("date", nonNull(graphql.Date), "",
+			func(r rewardDay) any { return r.date.Format("2006-01-02") })).
+		AddField(property("minutes", nonNull(graphql.Int), "",
+			func(r rewardDay) any { return r.minutes }))
+
+	child := graphql.NewObject("Child", "").
+		AddField(property("id", nonNull(graphql.ID), "",
+			func(c *core.Child) any { return c.ID })).
+		AddField(property("name", nonNull(graphql.String), "",
+			func(c *core.Child) any { return c.Name })).
+		AddField(property("emoji", nonNull(graphql.String), "",
+			func(c *core.Child) any { return c.Emoji })).
+		AddField(property("weekdayLimit", nonNull(graphql.Int), "Minutes per weekday",
+			func(c *core.Child) any { return c.WeekdayLimit })).
+		AddField(property("weekendLimit", nonNull(graphql.Int), "Minutes per weekend day",
+			func(c *core.Child) any { return c.WeekendLimit })).
+		AddField(property("graceMinutes", nonNull(graphql.Int), "",
+			func(c *core.Child) any { return c.GraceMinutes })).
+		AddField(property("timezone", graphql.String, "IANA timezone; null uses the server's",
+			func(c *core.Child) any { return optionalString(c.Timezone) })).
+		AddField(property("birthdate", graphql.Date, "",
+			func(c *core.Child) any { return optionalDate(c.Birthdate) })).
+		AddField(property("allowedDevices", nonNull(graphql.List{Of: nonNull(graphql.String)}), "Device IDs the child may use; empty means all",
+			func(c *core.Child) any { return c.AllowedDevices })).
+		AddField(&graphql.Field{
+			Name:    "today",
+			Type:    nonNull(today),
+			Resolve: r.childToday,
+		})
+
+	session := graphql.NewObject("Session", "").
+		AddField(property("id", nonNull(graphql.ID), "",
+			func(s *core.Session) any { return s.ID })).
+		AddField(property("deviceId", nonNull(graphql.String), "",
+			func(s *core.Session) any { return s.DeviceID })).
+		AddField(property("deviceType", nonNull(graphql.String), "",
+			func(s *core.Session) any { return s.DeviceType })).
+		AddField(property("status", nonNull(graphql.String), "active, paused, completed or expired",
+			func(s *core.Session) any { return string(s.Status) })).
+		AddField(property("startTime", nonNull(graphql.DateTime), "",
+			func(s *core.Session) any { return s.StartTime })).
+		AddField(property("expectedDuration", nonNull(graphql.Int), "Planned minutes, extensions included",
+			func(s *core.Session) any { return s.ExpectedDuration })).
+		AddField(property("actualDuration", graphql.Int, "Minutes the session ran; null while running",
+			func(s *core.Session) any { return s.ActualDuration })).
+		AddField(property("endReason", graphql.String, "",
+			func(s *core.Session) any { return optionalString(s.EndReason) })).
+		AddField(property("activity", graphql.String, "Exempt activity the session is for",
+			func(s *core.Session) any { return optionalString(s.Activity) })).
+		AddField(property("isMovie", nonNull(graphql.Bool), "",
+			func(s *core.Session) any { return s.IsMovieSession })).
+		AddField(property("initiatorType", graphql.String, "",
+			func(s *core.Session) any { return optionalString(s.InitiatorType) })).
+		AddField(property("notes", nonNull(graphql.String), "",
+			func(s *core.Session) any { return s.Notes })).
+		AddField(&graphql.Field{
+			Name:    "children",
+			Type:    nonNull(graphql.List{Of: nonNull(child)}),
+			Resolve: r.sessionChildren,
+		})
+
+	child.
+		AddField(&graphql.Field{
+			Name:        "sessions",
+			Description: "Sessions, newest first",
+			Type:        nonNull(graphql.List{Of: nonNull(session)}),
+			Args: []*graphql.Argument{
+				{Name: "since", Type: graphql.DateTime},
+				{Name: "limit", Type: graphql.Int, Default: 20},
+			},
+			Resolve: r.childSessions,
+		}).
+		AddField(&graphql.Field{
+			Name:        "allocations",
+			Description: "Daily allocations from one day to another (the last 30 days by default), oldest first",
+			Type:        nonNull(graphql.List{Of: nonNull(allocation)}),
+			Args:        dateRangeArgs(),
+			Resolve:     r.childAllocations,
+			Complexity:  dateRangeComplexity,
+		}).
+		AddField(&graphql.Field{
+			Name:        "rewards",
+			Description: "Days with bonus minutes from one day to another (the last 30 days by default), oldest first",
+			Type:        nonNull(graphql.List{Of: nonNull(reward)}),
+			Args:        dateRangeArgs(),
+			Resolve:     r.childRewards,
+			Complexity:  dateRangeComplexity,
+		})
+
+	return graphql.NewObject("Query", "").
+		AddField(&graphql.Field{
+			Name:    "children",
+			Type:    nonNull(graphql.List{Of: nonNull(child)}),
+			Resolve: r.children,
+		}).
+		AddField(&graphql.Field{
+			Name:    "child",
+			Type:    child,
+			Args:    []*graphql.Argument{{Name: "id", Type: nonNull(graphql.ID)}},
+			Resolve: r.child,
+		}).
+		AddField(&graphql.Field{
+			Name:    "session",
+			Type:    session,
+			Args:    []*graphql.Argument{{Name: "id", Type: nonNull(graphql.ID)}},
+			Resolve: r.session,
+		}).
+		AddField(&graphql.Field{
+			Name:        "sessions",
+			Description: "Sessions, newest first",
+			Type:        nonNull(graphql.List{Of: nonNull(session)}),
+			Args: []*graphql.Argument{
+				{Name: "childId", Type: graphql.ID},
+				{Name: "status", Type: graphql.String},
+				{Name: "since", Type: graphql.DateTime},
+				{Name: "limit", Type: graphql.Int, Default: 50},
+			},
+			Resolve: r.sessions,
+		}).
+		AddField(&graphql.Field{
+			Name:    "activeSessions",
+			Type:    nonNull(graphql.List{Of: nonNull(session)}),
+			Resolve: r.activeSessions,
+		})
+}
+
+func (r *graphQLResolvers) children(ctx context.Context, _ any, _ map[string]any) (any, error) {
+	children, err := r.storage.ListChildren(ctx)
+	if err != nil {
+		return nil, r.failed("children", err)
+	}
+	return children, nil
+}
+
+func (r *graphQLResolvers) child(ctx context.Context, _ any, args map[string]any) (any, error) {
+	child, err := r.storage.GetChild(ctx, args["id"].(string))
+	if errors.Is(err, core.ErrChildNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, r.failed("child", err)
+	}
+	return child, nil
+}
+
+func (r *graphQLResolvers) session(ctx context.Context, _ any, args map[string]any) (any, error) {
+	session, err := r.storage.GetSession(ctx, args["id"].(string))
+	if errors.Is(err, core.ErrSessionNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, r.failed("session", err)
+	}
+	return session, nil
+}
+
+func (r *graphQLResolvers) sessions(ctx context.Context, _ any, args map[string]any) (any, error) {
+	limit, err := listLimit(args)
+	if err != nil {
+		return nil, err
+	}
+	since, _ := args["since"].(time.Time)
+
+	var sessions []*core.Session
+	switch childID, _ := args["childId"].(string); {
+	case childID != "":
+		sessions, err = r.storage.ListSessionsByChild(ctx, childID)
+	case !since.IsZero():
+		sessions, err = r.storage.ListSessionsSince(ctx, since)
+	default:
+		sessions, err = r.storage.ListAllSessions(ctx)
+	}
+	if err != nil {
+		return nil, r.failed("sessions", err)
+	}
+	status, _ := args["status"].(string)
+	return filterSessions(sessions, status, since, limit), nil
+}
+
+func (r *graphQLResolvers) activeSessions(ctx context.Context, _ any, _ map[string]any) (any, error) {
+	sessions, err := r.storage.ListActiveSessions(ctx)
+	if err != nil {
+		return nil, r.failed("active sessions", err)
+	}
+	return sessions, nil
+}
+
+func (r *graphQLResolvers) childToday(ctx context.Context, source any, _ map[string]any) (any, error) {
+	status, err := r.status.GetChildStatus(ctx, source.(*core.Child).ID)
+	if err != nil {
+		return nil, r.failed("child status", err)
+	}
+	return status, nil
+}
+
+func (r *graphQLResolvers) childSessions(ctx context.Context, source any, args map[string]any) (any, error) {
+	limit, err := listLimit(args)
+	if err != nil {
+		return nil, err
+	}
+	sessions, err := r.storage.ListSessionsByChild(ctx, source.(*core.Child).ID)
+	if err != nil {
+		return nil, r.failed("sessions", err)
+	}
+	since, _ := args["since"].(time.Time)
+	return filterSessions(sessions, "", since, limit), nil
+}
+
+func (r *graphQLResolvers) childAllocations(ctx context.Context, source any, args map[string]any) (any, error) {
+	return r.history(ctx, source.(*core.Child).ID, args)
+}
+
+func (r *graphQLResolvers) childRewards(ctx context.Context, source any, args map[string]any) (any, error) {
+	allocations, err := r.history(ctx, source.(*core.Child).ID, args)
+	if err != nil {
+		return nil, err
+	}
+	rewards := make([]rewardDay, 0, len(allocations))
+	for _, allocation := range allocations {
+		if allocation.BonusGranted != 0 {
+			rewards = append(rewards, rewardDay{date: allocation.Date, minutes: allocation.BonusGranted})
+		}
+	}
+	return rewards, nil
+}
+
+// history returns the allocations of the from/to range
+func (r *graphQLResolvers) history(ctx context.Context, childID string, args map[string]any) ([]*core.DailyTimeAllocation, error) {
+	from, _ := args["from"].(time.Time)
+	to, _ := args["to"].(time.Time)
+	allocations, err := r.allocations.History(ctx, childID, from, to)
+	if errors.Is(err, core.ErrInvalidAllocationRange) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, r.failed("allocations", err)
+	}
+	return allocations, nil
+}
+
+func (r *graphQLResolvers) sessionChildren(ctx context.Context, source any, _ map[string]any) (any, error) {
+	session := source.(*core.Session)
+	children := make([]*core.Child, 0, len(session.ChildIDs))
+	for _, childID := range session.ChildIDs {
+		child, err := r.storage.GetChild(ctx, childID)
+		if errors.Is(err, core.ErrChildNotFound) {
+			continue // Deleted since
+		}
+		if err != nil {
+			return nil, r.failed("children", err)
+		}
+		children = append(children, child)
+	}
+	return children, nil
+}
+
+// failed logs a storage error and returns the error shown in the response
+func (r *graphQLResolvers) failed(what string, err error) error {
+	r.logger.Error("Failed to resolve GraphQL field",
+		"component", "api.graphql",
+		"field", what,
+		"error", err)
+	return fmt.Errorf("failed to retrieve %s", what)
+}
+
+// filterSessions returns up to limit sessions, newest first, with the status that started at or after since
+func filterSessions(sessions []*core.Session, status string, since time.Time, limit int) []*core.Session {
+	sort.SliceStable(sessions, func(i, j int) bool {
+		return sessions[i].StartTime.After(sessions[j].StartTime)
+	})
+	filtered := make([]*core.Session, 0, min(len(sessions), limit))
+	for _, session := range sessions {
+		if len(filtered) == limit {
+			break
+		}
+		if status != "" && string(session.Status) != status {
+			continue
+		}
+		if session.StartTime.Before(since) {
+			break // Newest first, so the rest are older
+		}
+		filtered = append(filtered, session)
+	}
+	return filtered
+}
+
+// listLimit returns the "limit" argument of a list field
+func listLimit(args map[string]any) (int, error) {
+	limit := args["limit"].(int)
+	if limit < 1 || limit > graphQLMaxListLimit {
+		return 0, fmt.Errorf("limit must be between 1 and %d", graphQLMaxListLimit)
+	}
+	return limit, nil
+}
+
+func dateRangeArgs() []*graphql.Argument {
+	return []*graphql.Argument{
+		{Name: "from", Type: graphql.Date},
+		{Name: "to", Type: graphql.Date},
+	}
+}
+
+// dateRangeComplexity counts a from/to list field as one item per day of the range
+func dateRangeComplexity(args map[string]any, childComplexity int) int {
+	days := core.DefaultAllocationHistoryDays
+	from, hasFrom := args["from"].(time.Time)
+	to, hasTo := args["to"].(time.Time)
+	if hasFrom && hasTo {
+		days = min(max(int(to.Sub(from).Hours()/24)+1, 1), core.MaxAllocationHistoryDays)
+	} else if hasFrom {
+		days = core.MaxAllocationHistoryDays // Up to today, as far back as allowed
+	}
+	return 1 + days*childComplexity
+}
+
+// property returns a field read from the Go value of its object (e.g. *core.Child)
+func property[T any](name string, t graphql.Type, description string, get func(T) any) *graphql.Field {
+	return &graphql.Field{
+		Name:        name,
+		Description: description,
+		Type:        t,
+		Resolve: func(_ context.Context, source any, _ map[string]any) (any, error) {
+			return get(source.(T)), nil
+		},
+	}
+}
+
+func nonNull(t graphql.Type) graphql.Type {
+	return graphql.NonNull{Of: t}
+}
+
+// optionalString returns null for empty strings
+func optionalString(s string) any {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// optionalDate formats a date, or returns null
+func optionalDate(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.Format("2006-01-02")
+}
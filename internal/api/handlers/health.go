@@ -1,24 +1,99 @@
 package handlers
 
 import (
+	"context"
+	"log/slog"
 	"net/http"
+	"sort"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// readinessTimeout bounds how long all readiness checks may take together
+const readinessTimeout = 5 * time.Second
+
+// HealthCheck reports whether a dependency is ready (nil error means ready)
+type HealthCheck func(ctx context.Context) error
+
 // HealthHandler handles health check requests
-type HealthHandler struct{}
+type HealthHandler struct {
+	checks map[string]HealthCheck
+	logger *slog.Logger
+}
 
 // NewHealthHandler creates a new health handler
-func NewHealthHandler() *HealthHandler {
-	return &HealthHandler{}
+// checks maps a component name (e.g. "database", "scheduler") to its readiness check
+func NewHealthHandler(checks map[string]HealthCheck, logger *slog.Logger) *HealthHandler {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &HealthHandler{
+		checks: checks,
+		logger: logger,
+	}
 }
 
 // GetHealth returns the health status of the service
 // GET /health
 func (h *HealthHandler) GetHealth(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{
-		"status": "UP",
+		"status":  "UP",
+		"service": "metron",
+	})
+}
+
+// GetLiveness reports that the process is alive and serving HTTP
+// It never checks dependencies, so a restart is only triggered for a hung process
+// GET /healthz
+func (h *HealthHandler) GetLiveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"status":  "UP",
+		"service": "metron",
+	})
+}
+
+// GetReadiness runs all registered readiness checks
+// Returns 503 if any check fails
+// GET /readyz
+func (h *HealthHandler) GetReadiness(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), readinessTimeout)
+	defer cancel()
+
+	names := make([]string, 0, len(h.checks))
+	for name := range h.checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ready := true
+	checks := make([]gin.H, 0, len(names))
+	for _, name := range names {
+		result := gin.H{
+			"name":   name,
+			"status": "UP",
+		}
+		if err := h.checks[name](ctx); err != nil {
+			ready = false
+			result["status"] = "DOWN"
+			result["error"] = err.Error()
+			h.logger.Warn("Readiness check failed",
+				"check", name,
+				"error", err)
+		}
+		checks = append(checks, result)
+	}
+
+	status := "UP"
+	httpStatus := http.StatusOK
+	if !ready {
+		status = "DOWN"
+		httpStatus = http.StatusServiceUnavailable
+	}
+
+	c.JSON(httpStatus, gin.H{
+		"status":  status,
 		"service": "metron",
+		"checks":  checks,
 	})
 }
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"metron/internal/api/apierror"
+	"metron/internal/core"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LimitScheduleService defines the limit change operations needed by the handlers
+type LimitScheduleService interface {
+	Schedule(ctx context.Context, childID string, weekdayLimit, weekendLimit int, effectiveFrom time.Time, createdBy string) (*core.LimitChange, error)
+	RecordChange(ctx context.Context, child *core.Child, previousWeekday, previousWeekend int, createdBy string) error
+	History(ctx context.Context, childID string) ([]*core.LimitChange, error)
+	Cancel(ctx context.Context, childID, changeID, cancelledBy string) error
+}
+
+// LimitChangesHandler handles immediate and scheduled changes of children's limits
+type LimitChangesHandler struct {
+	limits LimitScheduleService
+	logger *slog.Logger
+}
+
+// NewLimitChangesHandler creates a new limit changes handler
+func NewLimitChangesHandler(limits LimitScheduleService, logger *slog.Logger) *LimitChangesHandler {
+	return &LimitChangesHandler{
+		limits: limits,
+		logger: logger,
+	}
+}
+
+// ListLimitChanges returns the child's limit changes, applied and pending, oldest effective date first
+// GET /api/v1/children/:id/limit-changes
+func (h *LimitChangesHandler) ListLimitChanges(c *gin.Context) {
+	childID := c.Param("id")
+
+	changes, err := h.limits.History(c.Request.Context(), childID)
+	if err != nil {
+		if errors.Is(err, core.ErrChildNotFound) {
+			apierror.Respond(c, apierror.ChildNotFound, "Child not found")
+			return
+		}
+		h.logger.Error("Failed to list limit changes",
+			"component", "api.limits",
+			"child_id", childID,
+			"error", err)
+		apierror.Respond(c, apierror.InternalError, "Failed to retrieve limit changes")
+		return
+	}
+
+	response := make([]gin.H, len(changes))
+	for i, change := range changes {
+		response[i] = formatLimitChangeResponse(change)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"limit_changes": response,
+	})
+}
+
+// CreateLimitChange changes the child's limits from a given day (today if omitted)
+// POST /api/v1/children/:id/limit-changes
+func (h *LimitChangesHandler) CreateLimitChange(c *gin.Context) {
+	childID := c.Param("id")
+
+	var req struct {
+		WeekdayLimit  int    `json:"weekday_limit" binding:"required,gt=0"`
+		WeekendLimit  int    `json:"weekend_limit" binding:"required,gt=0"`
+		EffectiveFrom string `json:"effective_from"` // YYYY-MM-DD in the child's timezone
+		CreatedBy     string `json:"created_by"`
+	}
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"code":    apierror.InvalidRequest,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Without a date the change applies today (in the child's timezone)
+	var effectiveFrom time.Time
+	if req.EffectiveFrom != "" {
+		date, err := time.Parse("2006-01-02", req.EffectiveFrom)
+		if err != nil {
+			apierror.Respond(c, apierror.InvalidDateFormat, "effective_from must use the YYYY-MM-DD format")
+			return
+		}
+		effectiveFrom = date
+	}
+	if req.CreatedBy == "" {
+		req.CreatedBy = "api"
+	}
+
+	change, err := h.limits.Schedule(c.Request.Context(), childID, req.WeekdayLimit, req.WeekendLimit, effectiveFrom, req.CreatedBy)
+	if err != nil {
+		if _, ok := apierror.FromError(err); ok {
+			apierror.RespondError(c, err, apierror.InternalError)
+			return
+		}
+		h.logger.Error("Failed to change limits",
+			"component", "api.limits",
+			"child_id", childID,
+			"error", err)
+		apierror.Respond(c, apierror.InternalError, "Failed to change limits")
+		return
+	}
+
+	c.JSON(http.StatusCreated, formatLimitChangeResponse(change))
+}
+
+// CancelLimitChange removes a pending limit change
+// DELETE /api/v1/children/:id/limit-changes/:changeId
+func (h *LimitChangesHandler) CancelLimitChange(c *gin.Context) {
+	childID := c.Param("id")
+	changeID := c.Param("changeId")
+
+	var req struct {
+		CancelledBy string `json:"cancelled_by"`
+	}
+
+	// Body is optional for DELETE
+	if c.Request.ContentLength > 0 {
+		if err := bindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"code":    apierror.InvalidRequest,
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+	if req.CancelledBy == "" {
+		req.CancelledBy = "api"
+	}
+
+	if err := h.limits.Cancel(c.Request.Context(), childID, changeID, req.CancelledBy); err != nil {
+		if _, ok := apierror.FromError(err); ok {
+			apierror.RespondError(c, err, apierror.InternalError)
+			return
+		}
+		h.logger.Error("Failed to cancel limit change",
+			"component", "api.limits",
+			"child_id", childID,
+			"change_id", changeID,
+			"error", err)
+		apierror.Respond(c, apierror.InternalError, "Failed to cancel limit change")
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func formatLimitChangeResponse(change *core.LimitChange) gin.H {
+	response := gin.H{
+		"id":             change.ID,
+		"child_id":       change.ChildID,
+		"weekday_limit":  change.WeekdayLimit,
+		"weekend_limit":  change.WeekendLimit,
+		"effective_from": change.EffectiveFrom.Format("2006-01-02"),
+		"created_by":     change.CreatedBy,
+		"created_at":     change.CreatedAt.Format(time.RFC3339),
+		"pending":        change.IsPending(),
+	}
+	if change.AppliedAt != nil {
+		response["applied_at"] = change.AppliedAt.Format(time.RFC3339)
+	}
+	return response
+}
@@ -0,0 +1,174 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"metron/internal/api/apierror"
+	"metron/internal/core"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LimitProfileService defines the limit profile operations needed by the handlers
+type LimitProfileService interface {
+	Profiles() []core.LimitProfile
+	List(ctx context.Context, status string) ([]*core.ProfileTransition, error)
+	Confirm(ctx context.Context, id, confirmedBy string) (*core.ProfileTransition, error)
+	Dismiss(ctx context.Context, id, dismissedBy string) (*core.ProfileTransition, error)
+}
+
+// LimitProfilesHandler handles age-based limit profiles and the transitions proposed on birthdays
+type LimitProfilesHandler struct {
+	profiles LimitProfileService
+	logger   *slog.Logger
+}
+
+// NewLimitProfilesHandler creates a new limit profiles handler
+func NewLimitProfilesHandler(profiles LimitProfileService, logger *slog.Logger) *LimitProfilesHandler {
+	return &LimitProfilesHandler{
+		profiles: profiles,
+		logger:   logger,
+	}
+}
+
+// ListProfiles returns the configured limit profiles
+// GET /limit-profiles
+func (h *LimitProfilesHandler) ListProfiles(c *gin.Context) {
+	profiles := h.profiles.Profiles()
+
+	response := make([]gin.H, len(profiles))
+	for i, profile := range profiles {
+		response[i] = gin.H{
+			"name":          profile.Name,
+			"min_age":       profile.MinAge,
+			"max_age":       profile.MaxAge,
+			"weekday_limit": profile.WeekdayLimit,
+			"weekend_limit": profile.WeekendLimit,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"profiles": response,
+	})
+}
+
+// ListTransitions returns profile transitions, oldest first, optionally by status
+// GET /profile-transitions?status=
+func (h *LimitProfilesHandler) ListTransitions(c *gin.Context) {
+	status := c.Query("status")
+	switch status {
+	case "", core.ProfileTransitionPending, core.ProfileTransitionConfirmed, core.ProfileTransitionDismissed:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "status must be pending, confirmed or dismissed",
+			"code":  apierror.InvalidRequest,
+		})
+		return
+	}
+
+	transitions, err := h.profiles.List(c.Request.Context(), status)
+	if err != nil {
+		h.logger.Error("Failed to list profile transitions",
+			"component", "api.profiles",
+			"error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve profile transitions",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	response := make([]gin.H, len(transitions))
+	for i, transition := range transitions {
+		response[i] = formatProfileTransitionResponse(transition)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"transitions": response,
+	})
+}
+
+// ConfirmTransition applies the limits of a pending profile transition
+// POST /profile-transitions/:id/confirm
+func (h *LimitProfilesHandler) ConfirmTransition(c *gin.Context) {
+	actor, ok := h.bindActor(c)
+	if !ok {
+		return
+	}
+
+	transition, err := h.profiles.Confirm(c.Request.Context(), c.Param("id"), actor)
+	h.respondResolved(c, transition, err)
+}
+
+// DismissTransition closes a pending profile transition without changing limits
+// POST /profile-transitions/:id/dismiss
+func (h *LimitProfilesHandler) DismissTransition(c *gin.Context) {
+	actor, ok := h.bindActor(c)
+	if !ok {
+		return
+	}
+
+	transition, err := h.profiles.Dismiss(c.Request.Context(), c.Param("id"), actor)
+	h.respondResolved(c, transition, err)
+}
+
+// bindActor reads the optional {"resolved_by": ...} body, defaulting to "api"
+func (h *LimitProfilesHandler) bindActor(c *gin.Context) (string, bool) {
+	var req struct {
+		ResolvedBy string `json:"resolved_by"`
+	}
+
+	// Body is optional
+	if c.Request.ContentLength > 0 {
+		if err := bindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"code":    apierror.InvalidRequest,
+				"details": err.Error(),
+			})
+			return "", false
+		}
+	}
+	if req.ResolvedBy == "" {
+		req.ResolvedBy = "api"
+	}
+	return req.ResolvedBy, true
+}
+
+// respondResolved writes the response of a confirm or dismiss request
+func (h *LimitProfilesHandler) respondResolved(c *gin.Context, transition *core.ProfileTransition, err error) {
+	if err != nil {
+		if _, ok := apierror.FromError(err); ok {
+			apierror.RespondError(c, err, apierror.InternalError)
+			return
+		}
+		h.logger.Error("Failed to resolve profile transition",
+			"component", "api.profiles",
+			"transition_id", c.Param("id"),
+			"error", err)
+		apierror.Respond(c, apierror.InternalError, "Failed to resolve profile transition")
+		return
+	}
+
+	c.JSON(http.StatusOK, formatProfileTransitionResponse(transition))
+}
+
+func formatProfileTransitionResponse(transition *core.ProfileTransition) gin.H {
+	response := gin.H{
+		"id":            transition.ID,
+		"child_id":      transition.ChildID,
+		"profile":       transition.Profile,
+		"age":           transition.Age,
+		"weekday_limit": transition.WeekdayLimit,
+		"weekend_limit": transition.WeekendLimit,
+		"status":        transition.Status,
+		"created_at":    transition.CreatedAt.Format(time.RFC3339),
+	}
+	if transition.ResolvedAt != nil {
+		response["resolved_by"] = transition.ResolvedBy
+		response["resolved_at"] = transition.ResolvedAt.Format(time.RFC3339)
+	}
+	return response
+}
@@ -0,0 +1,215 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"metron/internal/api/apierror"
+	"metron/internal/core"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const defaultLockdownHistoryLimit = 20
+
+// LockdownService defines the lockdown operations needed by the handler
+type LockdownService interface {
+	Active(ctx context.Context) (*core.Lockdown, error)
+	Activate(ctx context.Context, triggeredBy, reason string) (*core.Lockdown, error)
+	Lift(ctx context.Context, liftedBy string) (*core.Lockdown, error)
+	History(ctx context.Context, limit int) ([]*core.Lockdown, error)
+}
+
+// LockdownHandler handles lockdown (panic button) requests
+type LockdownHandler struct {
+	lockdown LockdownService
+	logger   *slog.Logger
+}
+
+// NewLockdownHandler creates a new lockdown handler
+func NewLockdownHandler(lockdown LockdownService, logger *slog.Logger) *LockdownHandler {
+	return &LockdownHandler{
+		lockdown: lockdown,
+		logger:   logger,
+	}
+}
+
+// GetLockdown returns the current lockdown status
+// GET /lockdown
+func (h *LockdownHandler) GetLockdown(c *gin.Context) {
+	lockdown, err := h.lockdown.Active(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to get lockdown status",
+			"component", "api.lockdown",
+			"error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve lockdown status",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	response := gin.H{
+		"active": lockdown != nil,
+	}
+	if lockdown != nil {
+		response["lockdown"] = formatLockdownResponse(lockdown)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// ActivateLockdown stops all sessions and blocks new ones until lifted
+// POST /lockdown
+func (h *LockdownHandler) ActivateLockdown(c *gin.Context) {
+	var req struct {
+		Reason      string `json:"reason"`
+		TriggeredBy string `json:"triggered_by"`
+	}
+
+	// Body is optional: an empty POST triggers a lockdown without a reason
+	if c.Request.ContentLength > 0 {
+		if err := bindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"code":    apierror.InvalidRequest,
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	if req.TriggeredBy == "" {
+		req.TriggeredBy = "api"
+	}
+
+	lockdown, err := h.lockdown.Activate(c.Request.Context(), req.TriggeredBy, req.Reason)
+	if err != nil {
+		if errors.Is(err, core.ErrLockdownActive) {
+			c.JSON(http.StatusLocked, gin.H{
+				"error":    "Lockdown is already active",
+				"code":     apierror.LockdownActive,
+				"lockdown": formatLockdownResponse(lockdown),
+			})
+			return
+		}
+
+		h.logger.Error("Failed to activate lockdown",
+			"component", "api.lockdown",
+			"triggered_by", req.TriggeredBy,
+			"error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to activate lockdown",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, formatLockdownResponse(lockdown))
+}
+
+// LiftLockdown ends the active lockdown
+// DELETE /lockdown
+func (h *LockdownHandler) LiftLockdown(c *gin.Context) {
+	var req struct {
+		LiftedBy string `json:"lifted_by"`
+	}
+
+	// Body is optional for DELETE
+	if c.Request.ContentLength > 0 {
+		if err := bindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"code":    apierror.InvalidRequest,
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	if req.LiftedBy == "" {
+		req.LiftedBy = "api"
+	}
+
+	lockdown, err := h.lockdown.Lift(c.Request.Context(), req.LiftedBy)
+	if err != nil {
+		if errors.Is(err, core.ErrLockdownNotActive) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "No lockdown is active",
+				"code":  apierror.LockdownNotActive,
+			})
+			return
+		}
+
+		h.logger.Error("Failed to lift lockdown",
+			"component", "api.lockdown",
+			"lifted_by", req.LiftedBy,
+			"error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to lift lockdown",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, formatLockdownResponse(lockdown))
+}
+
+// ListLockdownHistory returns recent lockdowns, newest first
+// GET /lockdown/history?limit=
+func (h *LockdownHandler) ListLockdownHistory(c *gin.Context) {
+	limit := defaultLockdownHistoryLimit
+	if limitStr := c.Query("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "limit must be a positive integer",
+				"code":  apierror.InvalidRequest,
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	lockdowns, err := h.lockdown.History(c.Request.Context(), limit)
+	if err != nil {
+		h.logger.Error("Failed to list lockdown history",
+			"component", "api.lockdown",
+			"error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve lockdown history",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	response := make([]gin.H, len(lockdowns))
+	for i, lockdown := range lockdowns {
+		response[i] = formatLockdownResponse(lockdown)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"lockdowns": response,
+	})
+}
+
+func formatLockdownResponse(lockdown *core.Lockdown) gin.H {
+	response := gin.H{
+		"id":               lockdown.ID,
+		"reason":           lockdown.Reason,
+		"triggered_by":     lockdown.TriggeredBy,
+		"started_at":       lockdown.StartedAt.Format(time.RFC3339),
+		"stopped_sessions": lockdown.StoppedSessions,
+		"active":           lockdown.IsActive(),
+	}
+
+	if lockdown.LiftedAt != nil {
+		response["lifted_at"] = lockdown.LiftedAt.Format(time.RFC3339)
+		response["lifted_by"] = lockdown.LiftedBy
+	}
+
+	return response
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"metron/internal/api/apierror"
+	"metron/internal/logging"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Number of log lines returned without a lines parameter, and at most
+const (
+	defaultLogLines = 200
+	maxLogLines     = 1000
+)
+
+// LogTail returns the recent log lines kept in memory
+type LogTail interface {
+	Lines(stream string, n int) ([]string, error)
+}
+
+// LogsHandler handles reading recent log lines
+type LogsHandler struct {
+	tail   LogTail
+	logger *slog.Logger
+}
+
+// NewLogsHandler creates a new logs handler
+func NewLogsHandler(tail LogTail, logger *slog.Logger) *LogsHandler {
+	return &LogsHandler{
+		tail:   tail,
+		logger: logger,
+	}
+}
+
+// GetLogs returns the recent log lines of a stream, oldest first
+// GET /admin/logs?stream=core&lines=200
+func (h *LogsHandler) GetLogs(c *gin.Context) {
+	stream := c.DefaultQuery("stream", logging.StreamAll)
+
+	lines := defaultLogLines
+	if linesStr := c.Query("lines"); linesStr != "" {
+		parsed, err := strconv.Atoi(linesStr)
+		if err != nil || parsed <= 0 || parsed > maxLogLines {
+			apierror.Respond(c, apierror.InvalidRequest, "lines must be between 1 and "+strconv.Itoa(maxLogLines))
+			return
+		}
+		lines = parsed
+	}
+
+	logLines, err := h.tail.Lines(stream, lines)
+	if err != nil {
+		if errors.Is(err, logging.ErrUnknownStream) {
+			apierror.Respond(c, apierror.InvalidRequest, "stream must be one of all, core, api, drivers")
+			return
+		}
+		h.logger.Error("Failed to read log lines",
+			"component", "api.admin",
+			"error", err)
+		apierror.Respond(c, apierror.InternalError, "Failed to read logs")
+		return
+	}
+	if logLines == nil {
+		logLines = []string{}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"stream": stream,
+		"lines":  logLines,
+	})
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"metron/internal/api/apierror"
+	"metron/internal/core"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// extendModeUntilMediaEnd extends a session until the media playing on its device ends
+const extendModeUntilMediaEnd = "until_media_end"
+
+// MediaService defines the media operations needed by the handlers
+type MediaService interface {
+	Report(ctx context.Context, state *core.MediaState) (*core.MediaState, error)
+	Clear(deviceID string)
+	Current(ctx context.Context, deviceID string) (*core.MediaState, error)
+	ExtendUntilMediaEnds(ctx context.Context, sessionID string) (*core.Session, *core.MediaState, error)
+}
+
+// MediaHandler handles media state reports from integrations (e.g., Home Assistant, Plex)
+type MediaHandler struct {
+	media  MediaService
+	logger *slog.Logger
+}
+
+// NewMediaHandler creates a new media handler
+func NewMediaHandler(media MediaService, logger *slog.Logger) *MediaHandler {
+	return &MediaHandler{
+		media:  media,
+		logger: logger,
+	}
+}
+
+// ReportMedia records what a device is playing and when it ends
+// Either remaining_seconds or ends_at is required while playing; playing false clears the report.
+// POST /devices/:id/media
+func (h *MediaHandler) ReportMedia(c *gin.Context) {
+	deviceID := c.Param("id")
+
+	var req struct {
+		Playing          *bool      `json:"playing"` // Defaults to true
+		Title            string     `json:"title"`
+		RemainingSeconds int        `json:"remaining_seconds"`
+		EndsAt           *time.Time `json:"ends_at"`
+		Source           string     `json:"source"`
+	}
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"code":    apierror.InvalidRequest,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	// Paused or stopped: nothing is playing anymore
+	if req.Playing != nil && !*req.Playing {
+		h.media.Clear(deviceID)
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	state := &core.MediaState{DeviceID: deviceID, Title: req.Title, Source: req.Source}
+	switch {
+	case req.EndsAt != nil:
+		state.EndsAt = *req.EndsAt
+	case req.RemainingSeconds > 0:
+		state.EndsAt = time.Now().Add(time.Duration(req.RemainingSeconds) * time.Second)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "remaining_seconds or ends_at is required while playing",
+			"code":  apierror.InvalidRequest,
+		})
+		return
+	}
+
+	reported, err := h.media.Report(c.Request.Context(), state)
+	if err != nil {
+		h.logger.Warn("Failed to record media state",
+			"component", "api",
+			"device_id", deviceID,
+			"error", err,
+		)
+		apierror.RespondError(c, err, apierror.InvalidDevice)
+		return
+	}
+
+	c.JSON(http.StatusOK, formatMediaState(reported))
+}
+
+// GetMedia returns what a device is playing, as reported or as its driver tells
+// GET /devices/:id/media
+func (h *MediaHandler) GetMedia(c *gin.Context) {
+	media, err := h.media.Current(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		apierror.RespondError(c, err, apierror.InvalidDevice)
+		return
+	}
+	c.JSON(http.StatusOK, formatMediaState(media))
+}
+
+// ClearMedia forgets what a device was playing
+// DELETE /devices/:id/media
+func (h *MediaHandler) ClearMedia(c *gin.Context) {
+	h.media.Clear(c.Param("id"))
+	c.Status(http.StatusNoContent)
+}
+
+// validExtendMode checks the mode of an extension request ("" or "until_media_end")
+// Writes the error response and returns false for unknown modes, or media modes without media tracking.
+func validExtendMode(c *gin.Context, mode string, mediaEnabled bool) bool {
+	switch {
+	case mode == "":
+		return true
+	case mode != extendModeUntilMediaEnd:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "mode must be empty or until_media_end",
+			"code":  apierror.InvalidRequest,
+		})
+		return false
+	case !mediaEnabled:
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "Media tracking is not enabled",
+			"code":  apierror.InvalidRequest,
+		})
+		return false
+	}
+	return true
+}
+
+// formatMediaState converts a media state to API response format
+func formatMediaState(media *core.MediaState) gin.H {
+	response := gin.H{
+		"device_id":         media.DeviceID,
+		"ends_at":           media.EndsAt.Format("2006-01-02T15:04:05Z07:00"),
+		"remaining_seconds": max(int(time.Until(media.EndsAt).Seconds()), 0),
+		"reported_at":       media.ReportedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if media.Title != "" {
+		response["title"] = media.Title
+	}
+	if media.Source != "" {
+		response["source"] = media.Source
+	}
+	return response
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"context"
+	"crypto/subtle"
+	"io"
+	"log/slog"
+	"metron/internal/api/apierror"
+	"metron/internal/mediaserver"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MediaServerWatcher checks media server playback against sessions (implemented by mediaserver.Watcher)
+type MediaServerWatcher interface {
+	Handle(ctx context.Context, event *mediaserver.Event) (*mediaserver.Result, error)
+}
+
+// MediaServerHandler handles Plex and Jellyfin playback webhooks
+// Media servers cannot send the API key, so the webhook URLs carry a token instead.
+type MediaServerHandler struct {
+	watcher MediaServerWatcher
+	token   string
+	logger  *slog.Logger
+}
+
+// NewMediaServerHandler creates a new media server webhook handler
+func NewMediaServerHandler(watcher MediaServerWatcher, token string, logger *slog.Logger) *MediaServerHandler {
+	return &MediaServerHandler{
+		watcher: watcher,
+		token:   token,
+		logger:  logger,
+	}
+}
+
+// PlexWebhook handles Plex webhooks (multipart form posts with a JSON "payload" field)
+// POST /integrations/plex?token=...
+func (h *MediaServerHandler) PlexWebhook(c *gin.Context) {
+	if !h.authorized(c) {
+		return
+	}
+
+	payload := c.PostForm("payload")
+	if payload == "" {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "payload is required",
+			"code":  apierror.InvalidRequest,
+		})
+		return
+	}
+	event, err := mediaserver.ParsePlex([]byte(payload))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid Plex payload",
+			"code":    apierror.InvalidRequest,
+			"details": err.Error(),
+		})
+		return
+	}
+	h.handle(c, event)
+}
+
+// JellyfinWebhook handles notifications of the Jellyfin Webhook plugin
+// POST /integrations/jellyfin?token=...
+func (h *MediaServerHandler) JellyfinWebhook(c *gin.Context) {
+	if !h.authorized(c) {
+		return
+	}
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"code":    apierror.InvalidRequest,
+			"details": err.Error(),
+		})
+		return
+	}
+	event, err := mediaserver.ParseJellyfin(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid Jellyfin payload",
+			"code":    apierror.InvalidRequest,
+			"details": err.Error(),
+		})
+		return
+	}
+	h.handle(c, event)
+}
+
+// authorized checks the token of the webhook URL, and writes the error response if it is wrong
+func (h *MediaServerHandler) authorized(c *gin.Context) bool {
+	if h.token == "" || subtle.ConstantTimeCompare([]byte(c.Query("token")), []byte(h.token)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Invalid webhook token",
+			"code":  apierror.Unauthorized,
+		})
+		return false
+	}
+	return true
+}
+
+// handle checks the event against the sessions and responds with what was done
+func (h *MediaServerHandler) handle(c *gin.Context, event *mediaserver.Event) {
+	result, err := h.watcher.Handle(c.Request.Context(), event)
+	if err != nil {
+		h.logger.Error("Failed to handle media server event",
+			"component", "api",
+			"server", event.Server,
+			"event", event.Type,
+			"error", err,
+		)
+		apierror.RespondError(c, err, apierror.InternalError)
+		return
+	}
+
+	response := gin.H{"outcome": result.Outcome}
+	if result.SessionID != "" {
+		response["session_id"] = result.SessionID
+	}
+	c.JSON(http.StatusOK, response)
+}
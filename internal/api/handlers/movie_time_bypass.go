@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"log/slog"
+	"metron/internal/api/apierror"
 	"metron/internal/core"
 	"metron/internal/idgen"
 	"net/http"
@@ -44,7 +45,7 @@ func (h *MovieTimeBypassHandler) ListBypasses(c *gin.Context) {
 			"error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to list bypasses",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -75,10 +76,10 @@ func (h *MovieTimeBypassHandler) CreateBypass(c *gin.Context) {
 		EndDate   string `json:"end_date" binding:"required"`   // YYYY-MM-DD format
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Invalid request body",
-			"code":    "INVALID_REQUEST",
+			"code":    apierror.InvalidRequest,
 			"details": err.Error(),
 		})
 		return
@@ -89,7 +90,7 @@ func (h *MovieTimeBypassHandler) CreateBypass(c *gin.Context) {
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Invalid start_date format, expected YYYY-MM-DD",
-			"code":  "INVALID_DATE",
+			"code":  apierror.InvalidDate,
 		})
 		return
 	}
@@ -98,7 +99,7 @@ func (h *MovieTimeBypassHandler) CreateBypass(c *gin.Context) {
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "Invalid end_date format, expected YYYY-MM-DD",
-			"code":  "INVALID_DATE",
+			"code":  apierror.InvalidDate,
 		})
 		return
 	}
@@ -107,7 +108,7 @@ func (h *MovieTimeBypassHandler) CreateBypass(c *gin.Context) {
 	if endDate.Before(startDate) {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error": "end_date must be on or after start_date",
-			"code":  "INVALID_DATE_RANGE",
+			"code":  apierror.InvalidDateRange,
 		})
 		return
 	}
@@ -128,7 +129,7 @@ func (h *MovieTimeBypassHandler) CreateBypass(c *gin.Context) {
 			"error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to create bypass",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -162,7 +163,7 @@ func (h *MovieTimeBypassHandler) GetBypass(c *gin.Context) {
 			"error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to get bypass",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -170,7 +171,7 @@ func (h *MovieTimeBypassHandler) GetBypass(c *gin.Context) {
 	if bypass == nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "Bypass not found",
-			"code":  "NOT_FOUND",
+			"code":  apierror.NotFound,
 		})
 		return
 	}
@@ -198,7 +199,7 @@ func (h *MovieTimeBypassHandler) DeleteBypass(c *gin.Context) {
 			"error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to check bypass",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -206,7 +207,7 @@ func (h *MovieTimeBypassHandler) DeleteBypass(c *gin.Context) {
 	if bypass == nil {
 		c.JSON(http.StatusNotFound, gin.H{
 			"error": "Bypass not found",
-			"code":  "NOT_FOUND",
+			"code":  apierror.NotFound,
 		})
 		return
 	}
@@ -218,7 +219,7 @@ func (h *MovieTimeBypassHandler) DeleteBypass(c *gin.Context) {
 			"error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to delete bypass",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
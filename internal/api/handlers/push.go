@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"metron/internal/api/apierror"
+	"metron/internal/api/middleware"
+	"metron/internal/core"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PushService defines the Web Push subscription operations needed by the handler
+type PushService interface {
+	PublicKey() string
+	Subscribe(ctx context.Context, subscription *core.PushSubscription) error
+	List(ctx context.Context, childID string) ([]*core.PushSubscription, error)
+	Unsubscribe(ctx context.Context, id, childID string) error
+}
+
+// PushHandler lets browsers subscribe to Web Push notifications: parents' browsers with the
+// API key, children's with their child web app login
+type PushHandler struct {
+	push   PushService
+	logger *slog.Logger
+}
+
+// NewPushHandler creates a new push handler
+func NewPushHandler(push PushService, logger *slog.Logger) *PushHandler {
+	return &PushHandler{
+		push:   push,
+		logger: logger,
+	}
+}
+
+// pushSubscriptionRequest is the subscription as serialized by the browser's PushSubscription.toJSON
+type pushSubscriptionRequest struct {
+	Endpoint string `json:"endpoint" binding:"required"`
+	Keys     struct {
+		P256dh string `json:"p256dh" binding:"required"`
+		Auth   string `json:"auth" binding:"required"`
+	} `json:"keys"`
+	ChildID string `json:"child_id"`
+	Label   string `json:"label"`
+}
+
+// GetPublicKey returns the VAPID public key to pass to PushManager.subscribe
+// GET /push/key
+func (h *PushHandler) GetPublicKey(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"public_key": h.push.PublicKey(),
+	})
+}
+
+// ListSubscriptions returns the subscriptions, optionally of one child, oldest first
+// GET /push/subscriptions?child_id=xxx
+func (h *PushHandler) ListSubscriptions(c *gin.Context) {
+	h.list(c, c.Query("child_id"))
+}
+
+// Subscribe saves a browser's subscription; without child_id it gets every child's notifications
+// POST /push/subscriptions
+func (h *PushHandler) Subscribe(c *gin.Context) {
+	var req pushSubscriptionRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"code":    apierror.InvalidRequest,
+			"details": err.Error(),
+		})
+		return
+	}
+	h.subscribe(c, &req, req.ChildID)
+}
+
+// Unsubscribe deletes a subscription
+// DELETE /push/subscriptions/:id
+func (h *PushHandler) Unsubscribe(c *gin.Context) {
+	h.unsubscribe(c, "")
+}
+
+// ListChildSubscriptions returns the logged-in child's subscriptions, oldest first
+// GET /api/v1/child/push/subscriptions
+func (h *PushHandler) ListChildSubscriptions(c *gin.Context) {
+	childID, _ := middleware.GetChildID(c)
+	h.list(c, childID)
+}
+
+// SubscribeChild saves a browser's subscription to the logged-in child's notifications
+// POST /api/v1/child/push/subscriptions
+func (h *PushHandler) SubscribeChild(c *gin.Context) {
+	childID, _ := middleware.GetChildID(c)
+
+	var req pushSubscriptionRequest
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"code":    apierror.InvalidRequest,
+			"details": err.Error(),
+		})
+		return
+	}
+	h.subscribe(c, &req, childID)
+}
+
+// UnsubscribeChild deletes one of the logged-in child's subscriptions
+// DELETE /api/v1/child/push/subscriptions/:id
+func (h *PushHandler) UnsubscribeChild(c *gin.Context) {
+	childID, _ := middleware.GetChildID(c)
+	h.unsubscribe(c, childID)
+}
+
+func (h *PushHandler) list(c *gin.Context, childID string) {
+	subscriptions, err := h.push.List(c.Request.Context(), childID)
+	if err != nil {
+		h.logger.Error("Failed to list push subscriptions",
+			"component", "api.push",
+			"child_id", childID,
+			"error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve push subscriptions",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	response := make([]gin.H, len(subscriptions))
+	for i, subscription := range subscriptions {
+		response[i] = formatPushSubscriptionResponse(subscription)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"subscriptions": response,
+	})
+}
+
+func (h *PushHandler) subscribe(c *gin.Context, req *pushSubscriptionRequest, childID string) {
+	subscription := &core.PushSubscription{
+		ChildID:  childID,
+		Endpoint: req.Endpoint,
+		P256dh:   req.Keys.P256dh,
+		Auth:     req.Keys.Auth,
+		Label:    req.Label,
+	}
+
+	if err := h.push.Subscribe(c.Request.Context(), subscription); err != nil {
+		if !errors.Is(err, core.ErrInvalidPushSubscription) && !errors.Is(err, core.ErrChildNotFound) {
+			h.logger.Error("Failed to save push subscription",
+				"component", "api.push",
+				"child_id", childID,
+				"error", err)
+		}
+		apierror.RespondError(c, err, apierror.InternalError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, formatPushSubscriptionResponse(subscription))
+}
+
+func (h *PushHandler) unsubscribe(c *gin.Context, childID string) {
+	id := c.Param("id")
+
+	if err := h.push.Unsubscribe(c.Request.Context(), id, childID); err != nil {
+		if !errors.Is(err, core.ErrPushSubscriptionNotFound) {
+			h.logger.Error("Failed to delete push subscription",
+				"component", "api.push",
+				"subscription_id", id,
+				"error", err)
+		}
+		apierror.RespondError(c, err, apierror.InternalError)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// formatPushSubscriptionResponse formats a subscription for responses; the browser's keys are never included
+func formatPushSubscriptionResponse(subscription *core.PushSubscription) gin.H {
+	response := gin.H{
+		"id":         subscription.ID,
+		"endpoint":   subscription.Endpoint,
+		"created_at": subscription.CreatedAt.Format(time.RFC3339),
+	}
+	if subscription.ChildID != "" {
+		response["child_id"] = subscription.ChildID
+	}
+	if subscription.Label != "" {
+		response["label"] = subscription.Label
+	}
+	return response
+}
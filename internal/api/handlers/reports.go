@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"metron/internal/api/apierror"
+	"metron/internal/core"
+	"metron/internal/storage"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrendsService defines the usage trend operations needed by the handler
+type TrendsService interface {
+	ChildTrends(ctx context.Context, childID string, now time.Time) (*core.ChildTrends, error)
+}
+
+// ReportsHandler handles usage report requests
+type ReportsHandler struct {
+	storage storage.Reader
+	trends  TrendsService
+	logger  *slog.Logger
+}
+
+// NewReportsHandler creates a new reports handler
+func NewReportsHandler(storage storage.Reader, trends TrendsService, logger *slog.Logger) *ReportsHandler {
+	return &ReportsHandler{
+		storage: storage,
+		trends:  trends,
+		logger:  logger,
+	}
+}
+
+// GetTrends returns rolling usage averages and week-over-week trends per child
+// GET /reports/trends?child_id=
+func (h *ReportsHandler) GetTrends(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var children []*core.Child
+	if childID := c.Query("child_id"); childID != "" {
+		child, err := h.storage.GetChild(ctx, childID)
+		if err != nil {
+			if err == core.ErrChildNotFound {
+				c.JSON(http.StatusNotFound, gin.H{
+					"error": "Child not found",
+					"code":  apierror.ChildNotFound,
+				})
+				return
+			}
+			h.logger.Error("Failed to get child for trends",
+				"component", "api",
+				"child_id", childID,
+				"error", err,
+			)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to retrieve trends",
+				"code":  apierror.InternalError,
+			})
+			return
+		}
+		children = []*core.Child{child}
+	} else {
+		var err error
+		children, err = h.storage.ListChildren(ctx)
+		if err != nil {
+			h.logger.Error("Failed to list children for trends",
+				"component", "api",
+				"error", err,
+			)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "Failed to retrieve trends",
+				"code":  apierror.InternalError,
+			})
+			return
+		}
+	}
+
+	now := time.Now()
+	childTrends := make([]gin.H, 0, len(children))
+	for _, child := range children {
+		trends, err := h.trends.ChildTrends(ctx, child.ID, now)
+		if err != nil {
+			h.logger.Error("Failed to compute trends",
+				"component", "api",
+				"child_id", child.ID,
+				"error", err,
+			)
+			continue
+		}
+
+		days := make([]gin.H, len(trends.Days))
+		for i, day := range trends.Days {
+			days[i] = gin.H{
+				"date":          day.Date.Format("2006-01-02"),
+				"minutes_used":  day.MinutesUsed,
+				"limit":         day.Limit,
+				"limit_percent": day.LimitPercent,
+			}
+		}
+
+		var weekChange *float64
+		if trends.WeekChange != nil {
+			change := roundTenth(*trends.WeekChange)
+			weekChange = &change
+		}
+
+		childTrends = append(childTrends, gin.H{
+			"child_id":            child.ID,
+			"child_name":          child.Name,
+			"child_emoji":         child.Emoji,
+			"from":                trends.From.Format("2006-01-02"),
+			"to":                  trends.To.Format("2006-01-02"),
+			"week":                formatUsageWindow(trends.Week),
+			"previous_week":       formatUsageWindow(trends.PreviousWeek),
+			"month":               formatUsageWindow(trends.Month),
+			"weekdays":            formatUsageWindow(trends.Weekdays),
+			"weekends":            formatUsageWindow(trends.Weekends),
+			"week_change_percent": weekChange,
+			"direction":           trends.Direction,
+			"days":                days,
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"children": childTrends,
+	})
+}
+
+func formatUsageWindow(window core.UsageWindow) gin.H {
+	return gin.H{
+		"days":            window.Days,
+		"total_minutes":   window.TotalMinutes,
+		"average_minutes": roundTenth(window.AverageMinutes),
+		"limit_percent":   roundTenth(window.LimitPercent),
+	}
+}
+
+// roundTenth rounds to one decimal place
+func roundTenth(value float64) float64 {
+	return math.Round(value*10) / 10
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"metron/internal/api/apierror"
+	"metron/internal/scheduler"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SchedulerPreviewer defines the scheduler operations needed by the handler
+type SchedulerPreviewer interface {
+	Preview(ctx context.Context, now time.Time) ([]*scheduler.SessionPreview, error)
+	LastTick() time.Time
+}
+
+// SchedulerHandler handles scheduler debugging requests
+type SchedulerHandler struct {
+	scheduler SchedulerPreviewer
+	logger    *slog.Logger
+}
+
+// NewSchedulerHandler creates a new scheduler handler
+func NewSchedulerHandler(scheduler SchedulerPreviewer, logger *slog.Logger) *SchedulerHandler {
+	return &SchedulerHandler{
+		scheduler: scheduler,
+		logger:    logger,
+	}
+}
+
+// GetPreview returns the next planned scheduler actions for each active session
+// GET /admin/scheduler/preview
+func (h *SchedulerHandler) GetPreview(c *gin.Context) {
+	now := time.Now()
+
+	previews, err := h.scheduler.Preview(c.Request.Context(), now)
+	if err != nil {
+		h.logger.Error("Failed to preview scheduler actions",
+			"component", "api.scheduler",
+			"error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to preview scheduler actions",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	sessions := make([]gin.H, len(previews))
+	for i, preview := range previews {
+		planned := make([]gin.H, len(preview.Actions))
+		for j := range preview.Actions {
+			planned[j] = formatPlannedAction(&preview.Actions[j])
+		}
+
+		session := gin.H{
+			"session_id":  preview.SessionID,
+			"device_id":   preview.DeviceID,
+			"child_ids":   preview.ChildIDs,
+			"status":      preview.Status,
+			"next_action": nil,
+			"planned":     planned,
+		}
+		if next := preview.Next(); next != nil {
+			session["next_action"] = formatPlannedAction(next)
+		}
+		sessions[i] = session
+	}
+
+	response := gin.H{
+		"generated_at": now.Format(time.RFC3339),
+		"last_tick_at": nil,
+		"sessions":     sessions,
+	}
+	if lastTick := h.scheduler.LastTick(); !lastTick.IsZero() {
+		response["last_tick_at"] = lastTick.Format(time.RFC3339)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func formatPlannedAction(action *scheduler.PlannedAction) gin.H {
+	response := gin.H{
+		"action": action.Action,
+		"at":     action.At.Format(time.RFC3339),
+	}
+
+	if action.ChildID != "" {
+		response["child_id"] = action.ChildID
+	}
+	if action.Detail != "" {
+		response["detail"] = action.Detail
+	}
+
+	return response
+}
@@ -2,21 +2,38 @@ package handlers
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
 	"log/slog"
+	"metron/internal/api/apierror"
 	"metron/internal/core"
 	"metron/internal/storage"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// OverrideKeyHeader carries the override key that parent overrides require when one is configured
+const OverrideKeyHeader = "X-Metron-Override-Key"
+
 // SessionsHandler handles session-related requests
 type SessionsHandler struct {
-	storage storage.Storage
-	manager FullSessionManager
-	logger  *slog.Logger
+	storage     storage.Storage
+	manager     FullSessionManager
+	overrideKey string       // Optional: required in OverrideKeyHeader for parent overrides
+	queue       SessionQueue // Optional: starts waiting for their device (session_conflicts: queue)
+	media       MediaService // Optional: enables extending until the current media ends
+	logger      *slog.Logger
+}
+
+// SessionQueue lists and cancels the session starts waiting for their device
+type SessionQueue interface {
+	List() []*core.QueuedStart
+	Cancel(id string) error
 }
 
 // FullSessionManager interface for all session operations
@@ -26,8 +43,12 @@ type FullSessionManager interface {
 	ExtendSession(ctx context.Context, sessionID string, additionalMinutes int) (*core.Session, error)
 	StopSession(ctx context.Context, sessionID string) error
 	AddChildrenToSession(ctx context.Context, sessionID string, childIDs []string) (*core.Session, error)
+	RemoveChildFromSession(ctx context.Context, sessionID string, childID string) (*core.Session, error)
 	GetSession(ctx context.Context, sessionID string) (*core.Session, error)
 	ListActiveSessions(ctx context.Context) ([]*core.Session, error)
+	GetDurationSuggestions(ctx context.Context, childID string) (*core.DurationSuggestions, error)
+	PreflightSession(ctx context.Context, deviceID string, childIDs []string, durationMinutes int) (*core.SessionPreflight, error)
+	ActivityBudgets(ctx context.Context, childID string) ([]*core.ActivityBudget, error)
 }
 
 // NewSessionsHandler creates a new sessions handler
@@ -39,6 +60,70 @@ func NewSessionsHandler(storage storage.Storage, manager FullSessionManager, log
 	}
 }
 
+// SetOverrideKey makes parent overrides require the key in the X-Metron-Override-Key header
+// Without it, the API key is enough to request an override.
+func (h *SessionsHandler) SetOverrideKey(key string) {
+	h.overrideKey = key
+}
+
+// SetQueue enables the endpoints listing and canceling queued session starts
+func (h *SessionsHandler) SetQueue(queue SessionQueue) {
+	h.queue = queue
+}
+
+// SetMedia enables the "until_media_end" extension mode
+func (h *SessionsHandler) SetMedia(media MediaService) {
+	h.media = media
+}
+
+// withOverride adds the requested parent override to the request context
+// Writes the error response and returns false if the scopes are invalid or the override key is missing
+func (h *SessionsHandler) withOverride(ctx context.Context, c *gin.Context, scopes []string, by, reason string) (context.Context, bool) {
+	if len(scopes) == 0 {
+		return ctx, true
+	}
+
+	override, err := core.ParseOverride(scopes, by, reason)
+	if err != nil {
+		apierror.RespondError(c, err, apierror.ValidationError)
+		return nil, false
+	}
+
+	if h.overrideKey != "" && subtle.ConstantTimeCompare([]byte(c.GetHeader(OverrideKeyHeader)), []byte(h.overrideKey)) != 1 {
+		h.logger.Warn("Parent override rejected: missing or wrong override key",
+			"component", "api",
+			"scopes", scopes,
+			"by", by)
+		apierror.Respond(c, apierror.Forbidden, "Overrides require the override key in the "+OverrideKeyHeader+" header")
+		return nil, false
+	}
+
+	if override.By == "" {
+		override.By = "api"
+	}
+	return core.WithOverride(ctx, override), true
+}
+
+// withInitiator adds who the request acts for to the request context
+// API clients name a parent (the bot sends "parent" and the Telegram user); without a type the
+// session is recorded as started through the API. Writes the error response and returns false
+// for an unknown type.
+func (h *SessionsHandler) withInitiator(ctx context.Context, c *gin.Context, initiatorType, initiatorID string) (context.Context, bool) {
+	if initiatorType == "" {
+		initiatorType = core.InitiatorAPI
+		if initiatorID == "" {
+			initiatorID = "api"
+		}
+	}
+
+	initiator, err := core.ParseInitiator(initiatorType, initiatorID)
+	if err != nil {
+		apierror.RespondError(c, err, apierror.ValidationError)
+		return nil, false
+	}
+	return core.WithInitiator(ctx, initiator), true
+}
+
 // ListSessions returns sessions with optional filtering
 // GET /sessions?childId=&active=&date=
 func (h *SessionsHandler) ListSessions(c *gin.Context) {
@@ -59,7 +144,7 @@ func (h *SessionsHandler) ListSessions(c *gin.Context) {
 			)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to retrieve sessions",
-				"code":  "INTERNAL_ERROR",
+				"code":  apierror.InternalError,
 			})
 			return
 		}
@@ -73,7 +158,7 @@ func (h *SessionsHandler) ListSessions(c *gin.Context) {
 			)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to retrieve sessions",
-				"code":  "INTERNAL_ERROR",
+				"code":  apierror.InternalError,
 			})
 			return
 		}
@@ -87,7 +172,7 @@ func (h *SessionsHandler) ListSessions(c *gin.Context) {
 			)
 			c.JSON(http.StatusInternalServerError, gin.H{
 				"error": "Failed to retrieve sessions",
-				"code":  "INTERNAL_ERROR",
+				"code":  apierror.InternalError,
 			})
 			return
 		}
@@ -99,7 +184,7 @@ func (h *SessionsHandler) ListSessions(c *gin.Context) {
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": "Invalid date format. Use YYYY-MM-DD",
-				"code":  "INVALID_DATE_FORMAT",
+				"code":  apierror.InvalidDateFormat,
 			})
 			return
 		}
@@ -126,21 +211,52 @@ func (h *SessionsHandler) ListSessions(c *gin.Context) {
 // POST /sessions
 func (h *SessionsHandler) CreateSession(c *gin.Context) {
 	var req struct {
-		DeviceID string   `json:"device_id" binding:"required"`
-		ChildIDs []string `json:"child_ids" binding:"required"`
-		Minutes  int      `json:"minutes" binding:"required,gt=0"`
+		DeviceID    string   `json:"device_id" binding:"required"`
+		ChildIDs    []string `json:"child_ids" binding:"required"`
+		Minutes     int      `json:"minutes" binding:"required,gt=0"`
+		BreakExempt bool     `json:"break_exempt"`
+		Activity    string   `json:"activity"` // Exempt activity (e.g., "homework"), not charged against daily time
+
+		// Who the session is started for (see withInitiator), e.g. "parent" and "telegram:alice"
+		InitiatorType string `json:"initiator_type"`
+		InitiatorID   string `json:"initiator_id"`
+
+		// Parent override: rules the session may ignore ("downtime", "limits"), audited with who and why
+		Override       []string `json:"override"`
+		OverrideBy     string   `json:"override_by"`
+		OverrideReason string   `json:"override_reason"`
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Invalid request body",
-			"code":    "INVALID_REQUEST",
+			"code":    apierror.InvalidRequest,
 			"details": err.Error(),
 		})
 		return
 	}
 
-	session, err := h.manager.StartSession(c.Request.Context(), req.DeviceID, req.ChildIDs, req.Minutes)
+	// Admin API requests are parent-authenticated, so they may skip break rules
+	ctx := c.Request.Context()
+	if req.BreakExempt {
+		ctx = core.WithBreakExempt(ctx)
+	}
+	ctx, ok := h.withOverride(ctx, c, req.Override, req.OverrideBy, req.OverrideReason)
+	if !ok {
+		return
+	}
+	if ctx, ok = h.withInitiator(ctx, c, req.InitiatorType, req.InitiatorID); !ok {
+		return
+	}
+	if req.Activity != "" {
+		ctx = core.WithActivity(ctx, req.Activity)
+	}
+
+	session, err := h.manager.StartSession(ctx, req.DeviceID, req.ChildIDs, req.Minutes)
+	if response, queued := queuedResponse(err); queued {
+		c.JSON(http.StatusAccepted, response)
+		return
+	}
 	if err != nil {
 		h.logger.Error("Failed to start session",
 			"component", "api",
@@ -151,22 +267,86 @@ func (h *SessionsHandler) CreateSession(c *gin.Context) {
 		)
 
 		// Map known errors to appropriate status codes
-		if err == core.ErrInsufficientTime {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": err.Error(),
-				"code":  "INSUFFICIENT_TIME",
-			})
+		if errors.Is(err, core.ErrInsufficientTime) {
+			c.JSON(http.StatusBadRequest, insufficientTimeResponse(err))
+			return
+		}
+		if errors.Is(err, core.ErrPolicyBlocked) {
+			c.JSON(http.StatusForbidden, policyResponse(err))
+			return
+		}
+		if errors.Is(err, core.ErrDeviceBusy) {
+			c.JSON(http.StatusConflict, deviceBusyResponse(err))
 			return
 		}
 
+		apierror.RespondError(c, err, apierror.SessionCreateFailed)
+		return
+	}
+
+	// The children joined the session already running on the device
+	if session.Merged {
+		c.JSON(http.StatusOK, formatSessionResponse(session))
+		return
+	}
+
+	c.JSON(http.StatusCreated, formatSessionResponse(session))
+}
+
+// ListQueuedSessions returns the session starts waiting for their device, oldest first
+// GET /sessions/queue
+func (h *SessionsHandler) ListQueuedSessions(c *gin.Context) {
+	starts := h.queue.List()
+	response := make([]gin.H, len(starts))
+	for i, start := range starts {
+		response[i] = formatQueuedStart(start)
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// CancelQueuedSession removes a session start from the queue
+// DELETE /sessions/queue/:id
+func (h *SessionsHandler) CancelQueuedSession(c *gin.Context) {
+	if err := h.queue.Cancel(c.Param("id")); err != nil {
+		apierror.RespondError(c, err, apierror.InternalError)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// PreflightSession reports whether a session could start, without starting it
+// GET /sessions/preflight?device_id=&child_ids=&minutes=&activity=
+func (h *SessionsHandler) PreflightSession(c *gin.Context) {
+	deviceID := c.Query("device_id")
+	childIDs := queryList(c, "child_ids")
+	minutes, err := strconv.Atoi(c.Query("minutes"))
+	if deviceID == "" || len(childIDs) == 0 || err != nil || minutes <= 0 {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-			"code":  "SESSION_CREATE_FAILED",
+			"error": "device_id, child_ids and positive minutes are required",
+			"code":  apierror.InvalidRequest,
 		})
 		return
 	}
 
-	c.JSON(http.StatusCreated, formatSessionResponse(session))
+	ctx := c.Request.Context()
+	if activity := c.Query("activity"); activity != "" {
+		ctx = core.WithActivity(ctx, activity)
+	}
+
+	preflight, err := h.manager.PreflightSession(ctx, deviceID, childIDs, minutes)
+	if err != nil {
+		h.logger.Error("Failed to run session preflight",
+			"component", "api",
+			"device_id", deviceID,
+			"child_ids", childIDs,
+			"minutes", minutes,
+			"error", err,
+		)
+		apierror.RespondError(c, err, apierror.SessionCreateFailed)
+		return
+	}
+
+	c.JSON(http.StatusOK, formatPreflightResponse(preflight))
 }
 
 // GetSession returns a single session by ID
@@ -179,7 +359,7 @@ func (h *SessionsHandler) GetSession(c *gin.Context) {
 		if err == core.ErrSessionNotFound {
 			c.JSON(http.StatusNotFound, gin.H{
 				"error": "Session not found",
-				"code":  "SESSION_NOT_FOUND",
+				"code":  apierror.SessionNotFound,
 			})
 			return
 		}
@@ -191,7 +371,7 @@ func (h *SessionsHandler) GetSession(c *gin.Context) {
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to retrieve session",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
@@ -199,21 +379,33 @@ func (h *SessionsHandler) GetSession(c *gin.Context) {
 	c.JSON(http.StatusOK, formatSessionResponse(session))
 }
 
-// UpdateSession updates a session (extend or stop)
+// UpdateSession updates a session (extend, stop, add or remove children)
 // PATCH /sessions/:id
 func (h *SessionsHandler) UpdateSession(c *gin.Context) {
 	sessionID := c.Param("id")
 
 	var req struct {
-		Action            string   `json:"action"` // "extend", "stop", or "add_children"
+		Action            string   `json:"action"` // "extend", "stop", "add_children", or "remove_children"
 		AdditionalMinutes int      `json:"additional_minutes,omitempty"`
 		ChildIDs          []string `json:"child_ids,omitempty"`
+
+		// "until_media_end" extends until the media playing on the device ends, instead of by additional_minutes
+		Mode string `json:"mode,omitempty"`
+
+		// Who "extend" is requested for (see CreateSession); initiator limits apply to it
+		InitiatorType string `json:"initiator_type,omitempty"`
+		InitiatorID   string `json:"initiator_id,omitempty"`
+
+		// Parent override for "extend" (see CreateSession)
+		Override       []string `json:"override,omitempty"`
+		OverrideBy     string   `json:"override_by,omitempty"`
+		OverrideReason string   `json:"override_reason,omitempty"`
 	}
 
-	if err := c.ShouldBindJSON(&req); err != nil {
+	if err := bindJSON(c, &req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{
 			"error":   "Invalid request body",
-			"code":    "INVALID_REQUEST",
+			"code":    apierror.InvalidRequest,
 			"details": err.Error(),
 		})
 		return
@@ -221,19 +413,38 @@ func (h *SessionsHandler) UpdateSession(c *gin.Context) {
 
 	switch strings.ToLower(req.Action) {
 	case "extend":
-		if req.AdditionalMinutes <= 0 {
+		if !validExtendMode(c, req.Mode, h.media != nil) {
+			return
+		}
+		if req.Mode == "" && req.AdditionalMinutes <= 0 {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": "additional_minutes must be positive",
-				"code":  "INVALID_MINUTES",
+				"code":  apierror.InvalidMinutes,
 			})
 			return
 		}
 
-		session, err := h.manager.ExtendSession(c.Request.Context(), sessionID, req.AdditionalMinutes)
+		ctx, ok := h.withOverride(c.Request.Context(), c, req.Override, req.OverrideBy, req.OverrideReason)
+		if !ok {
+			return
+		}
+		if ctx, ok = h.withInitiator(ctx, c, req.InitiatorType, req.InitiatorID); !ok {
+			return
+		}
+
+		var session *core.Session
+		var media *core.MediaState
+		var err error
+		if req.Mode == extendModeUntilMediaEnd {
+			session, media, err = h.media.ExtendUntilMediaEnds(ctx, sessionID)
+		} else {
+			session, err = h.manager.ExtendSession(ctx, sessionID, req.AdditionalMinutes)
+		}
 		if err != nil {
 			h.logger.Error("Failed to extend session",
 				"component", "api",
 				"session_id", sessionID,
+				"mode", req.Mode,
 				"additional_minutes", req.AdditionalMinutes,
 				"error", err,
 			)
@@ -241,27 +452,29 @@ func (h *SessionsHandler) UpdateSession(c *gin.Context) {
 			if err == core.ErrSessionNotFound {
 				c.JSON(http.StatusNotFound, gin.H{
 					"error": "Session not found",
-					"code":  "SESSION_NOT_FOUND",
+					"code":  apierror.SessionNotFound,
 				})
 				return
 			}
 
-			if err == core.ErrInsufficientTime {
-				c.JSON(http.StatusBadRequest, gin.H{
-					"error": err.Error(),
-					"code":  "INSUFFICIENT_TIME",
-				})
+			if errors.Is(err, core.ErrInsufficientTime) {
+				c.JSON(http.StatusBadRequest, insufficientTimeResponse(err))
+				return
+			}
+			if errors.Is(err, core.ErrPolicyBlocked) {
+				c.JSON(http.StatusForbidden, policyResponse(err))
 				return
 			}
 
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": err.Error(),
-				"code":  "SESSION_EXTEND_FAILED",
-			})
+			apierror.RespondError(c, err, apierror.SessionExtendFailed)
 			return
 		}
 
-		c.JSON(http.StatusOK, formatSessionResponse(session))
+		response := formatSessionResponse(session)
+		if media != nil {
+			response["media"] = formatMediaState(media)
+		}
+		c.JSON(http.StatusOK, response)
 
 	case "stop":
 		err := h.manager.StopSession(c.Request.Context(), sessionID)
@@ -275,15 +488,12 @@ func (h *SessionsHandler) UpdateSession(c *gin.Context) {
 			if err == core.ErrSessionNotFound {
 				c.JSON(http.StatusNotFound, gin.H{
 					"error": "Session not found",
-					"code":  "SESSION_NOT_FOUND",
+					"code":  apierror.SessionNotFound,
 				})
 				return
 			}
 
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error": err.Error(),
-				"code":  "SESSION_STOP_FAILED",
-			})
+			apierror.RespondError(c, err, apierror.SessionStopFailed)
 			return
 		}
 
@@ -293,7 +503,7 @@ func (h *SessionsHandler) UpdateSession(c *gin.Context) {
 		if len(req.ChildIDs) == 0 {
 			c.JSON(http.StatusBadRequest, gin.H{
 				"error": "child_ids must not be empty",
-				"code":  "INVALID_CHILD_IDS",
+				"code":  apierror.InvalidChildIDs,
 			})
 			return
 		}
@@ -310,7 +520,7 @@ func (h *SessionsHandler) UpdateSession(c *gin.Context) {
 			if err == core.ErrSessionNotFound {
 				c.JSON(http.StatusNotFound, gin.H{
 					"error": "Session not found",
-					"code":  "SESSION_NOT_FOUND",
+					"code":  apierror.SessionNotFound,
 				})
 				return
 			}
@@ -318,24 +528,48 @@ func (h *SessionsHandler) UpdateSession(c *gin.Context) {
 			if err == core.ErrSessionNotActive {
 				c.JSON(http.StatusBadRequest, gin.H{
 					"error": "Session is not active",
-					"code":  "SESSION_NOT_ACTIVE",
+					"code":  apierror.SessionNotActive,
 				})
 				return
 			}
 
+			apierror.RespondError(c, err, apierror.AddChildrenFailed)
+			return
+		}
+
+		c.JSON(http.StatusOK, formatSessionResponse(session))
+
+	case "remove_children":
+		if len(req.ChildIDs) == 0 {
 			c.JSON(http.StatusBadRequest, gin.H{
-				"error": err.Error(),
-				"code":  "ADD_CHILDREN_FAILED",
+				"error": "child_ids must not be empty",
+				"code":  apierror.InvalidChildIDs,
 			})
 			return
 		}
 
+		var session *core.Session
+		for _, childID := range req.ChildIDs {
+			var err error
+			session, err = h.manager.RemoveChildFromSession(c.Request.Context(), sessionID, childID)
+			if err != nil {
+				h.logger.Error("Failed to remove child from session",
+					"component", "api",
+					"session_id", sessionID,
+					"child_id", childID,
+					"error", err,
+				)
+				apierror.RespondError(c, err, apierror.RemoveChildrenFailed)
+				return
+			}
+		}
+
 		c.JSON(http.StatusOK, formatSessionResponse(session))
 
 	default:
 		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Invalid action. Must be 'extend', 'stop', or 'add_children'",
-			"code":  "INVALID_ACTION",
+			"error": "Invalid action. Must be 'extend', 'stop', 'add_children', or 'remove_children'",
+			"code":  apierror.InvalidAction,
 		})
 	}
 }
@@ -352,6 +586,7 @@ func formatSessionResponse(session *core.Session) gin.H {
 		"expected_duration": session.ExpectedDuration,
 		"remaining_minutes": session.CalculateRemainingMinutes(),
 		"status":            string(session.Status),
+		"break_exempt":      session.BreakExempt,
 		"created_at":        session.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		"updated_at":        session.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
@@ -362,8 +597,250 @@ func formatSessionResponse(session *core.Session) gin.H {
 
 	if session.BreakEndsAt != nil {
 		response["break_ends_at"] = session.BreakEndsAt.Format("2006-01-02T15:04:05Z07:00")
+		response["break_action"] = session.BreakAction
+	}
+
+	// The expiry warning went out; clients can offer a quick extension
+	if session.WarningSentAt != nil {
+		response["warning_sent_at"] = session.WarningSentAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	// Running past the daily limit on the child's grace allowance
+	if session.GraceEndsAt != nil {
+		response["grace_ends_at"] = session.GraceEndsAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	// Rules a parent let the session ignore
+	if scopes := session.OverrideScopes(); len(scopes) > 0 {
+		response["override"] = scopes
+	}
+
+	// StartSession added the children to the session already running on the device
+	if session.Merged {
+		response["merged"] = true
+	}
+
+	// Who started the session (not set for sessions started before it was recorded)
+	if session.InitiatorType != "" {
+		response["initiator"] = gin.H{
+			"type": session.InitiatorType,
+			"id":   session.InitiatorID,
+		}
+	}
+
+	// Why the session ended (not set for running sessions or sessions that ended before it was recorded)
+	if session.EndReason != "" {
+		response["end_reason"] = session.EndReason
+	}
+
+	// Minutes the session ran (not set for running sessions)
+	if session.ActualDuration != nil {
+		response["actual_duration"] = *session.ActualDuration
+	}
+
+	// Exempt activity the session is for, not charged against daily time (not set for regular sessions)
+	if session.IsExempt() {
+		response["activity"] = session.Activity
+	}
+
+	// Notes on the session, e.g. what was watched (not set for sessions without notes)
+	if notes := session.NoteLines(); len(notes) > 0 {
+		response["notes"] = notes
+	}
+
+	addGrantFields(response, session.Grant)
+
+	return response
+}
+
+// addGrantFields adds requested/granted minutes to start and extend responses
+// cap_reason is only present when the request was capped
+func addGrantFields(response gin.H, grant *core.DurationGrant) {
+	if grant == nil {
+		return
+	}
+
+	response["requested_minutes"] = grant.RequestedMinutes
+	response["granted_minutes"] = grant.GrantedMinutes
+	response["capped"] = grant.Capped
+	if grant.Capped {
+		response["cap_reason"] = grant.Reason
+	}
+	if grant.Policy != "" {
+		response["cap_policy"] = grant.Policy
+	}
+}
+
+// formatPreflightResponse converts a session preflight to API response format
+// Blocked starts carry the error and code that starting the session would return
+func formatPreflightResponse(preflight *core.SessionPreflight) gin.H {
+	if !preflight.Allowed {
+		response := gin.H{
+			"allowed":    false,
+			"blocked_by": preflight.BlockedBy,
+			"error":      preflight.Err.Error(),
+		}
+		if code, ok := apierror.FromError(preflight.Err); ok {
+			response["code"] = code
+		}
+		if preflight.ChildID != "" {
+			response["child_id"] = preflight.ChildID
+		}
+		if errors.Is(preflight.Err, core.ErrInsufficientTime) {
+			limit := insufficientTimeResponse(preflight.Err)
+			response["error"] = limit["error"]
+			if details, ok := limit["details"]; ok {
+				response["details"] = details
+			}
+		}
+		if errors.Is(preflight.Err, core.ErrPolicyBlocked) {
+			if details, ok := policyResponse(preflight.Err)["details"]; ok {
+				response["details"] = details
+			}
+		}
+		if errors.Is(preflight.Err, core.ErrDeviceBusy) {
+			if details, ok := deviceBusyResponse(preflight.Err)["details"]; ok {
+				response["details"] = details
+			}
+		}
+		return response
+	}
+
+	activeSessionIDs := preflight.ActiveSessionIDs
+	if activeSessionIDs == nil {
+		activeSessionIDs = []string{}
+	}
+	response := gin.H{
+		"allowed":            true,
+		"active_session_ids": activeSessionIDs,
+	}
+	addGrantFields(response, preflight.Grant)
+	return response
+}
+
+// queryList reads a list query parameter, given comma-separated or repeated
+func queryList(c *gin.Context, key string) []string {
+	var values []string
+	for _, raw := range c.QueryArray(key) {
+		for _, value := range strings.Split(raw, ",") {
+			if value = strings.TrimSpace(value); value != "" {
+				values = append(values, value)
+			}
+		}
+	}
+	return values
+}
+
+// insufficientTimeResponse builds the 400 body for ErrInsufficientTime
+// When the error names the limiting child, details explain how much time is left
+func insufficientTimeResponse(err error) gin.H {
+	var timeErr *core.InsufficientTimeError
+	if !errors.As(err, &timeErr) {
+		return gin.H{
+			"error": err.Error(),
+			"code":  apierror.InsufficientTime,
+		}
 	}
 
+	message := fmt.Sprintf("%s has only %d minutes left", timeErr.ChildName, timeErr.RemainingMinutes)
+	if timeErr.RemainingMinutes <= 0 {
+		message = fmt.Sprintf("%s has no time left today", timeErr.ChildName)
+	}
+
+	return gin.H{
+		"error": message,
+		"code":  apierror.InsufficientTime,
+		"details": gin.H{
+			"child_id":          timeErr.ChildID,
+			"child_name":        timeErr.ChildName,
+			"remaining_minutes": timeErr.RemainingMinutes,
+			"limit_minutes":     timeErr.LimitMinutes,
+			"used_minutes":      timeErr.UsedMinutes,
+			"requested_minutes": timeErr.RequestedMinutes,
+		},
+	}
+}
+
+// policyResponse builds the 403 body for ErrPolicyBlocked
+// Details name the policy and rule that blocked the request
+func policyResponse(err error) gin.H {
+	var policyErr *core.PolicyError
+	if !errors.As(err, &policyErr) {
+		return gin.H{
+			"error": err.Error(),
+			"code":  apierror.PolicyBlocked,
+		}
+	}
+
+	details := gin.H{
+		"policy": policyErr.Policy,
+		"rule":   policyErr.Rule,
+		"limit":  policyErr.Limit,
+	}
+	if policyErr.ChildID != "" {
+		details["child_id"] = policyErr.ChildID
+		details["child_name"] = policyErr.ChildName
+	}
+	if policyErr.RetryAt != nil {
+		details["retry_at"] = policyErr.RetryAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	return gin.H{
+		"error":   err.Error(),
+		"code":    apierror.PolicyBlocked,
+		"details": details,
+	}
+}
+
+// deviceBusyResponse builds the 409 body for ErrDeviceBusy
+// Details name the session using the device and when it is planned to end
+func deviceBusyResponse(err error) gin.H {
+	var busyErr *core.DeviceBusyError
+	if !errors.As(err, &busyErr) {
+		return gin.H{
+			"error": err.Error(),
+			"code":  apierror.DeviceBusy,
+		}
+	}
+
+	return gin.H{
+		"error": err.Error(),
+		"code":  apierror.DeviceBusy,
+		"details": gin.H{
+			"device_id":  busyErr.DeviceID,
+			"session_id": busyErr.SessionID,
+			"busy_until": busyErr.BusyUntil.Format("2006-01-02T15:04:05Z07:00"),
+		},
+	}
+}
+
+// queuedResponse builds the 202 body of a session start queued until its device is free
+// Returns false if err is not a queued start.
+func queuedResponse(err error) (gin.H, bool) {
+	var queuedErr *core.SessionQueuedError
+	if !errors.As(err, &queuedErr) {
+		return nil, false
+	}
+	response := formatQueuedStart(queuedErr.Start)
+	response["queued"] = true
+	response["position"] = queuedErr.Position
+	response["starts_at"] = queuedErr.StartsAt.Format("2006-01-02T15:04:05Z07:00")
+	return response, true
+}
+
+// formatQueuedStart converts a queued session start to API response format
+func formatQueuedStart(start *core.QueuedStart) gin.H {
+	response := gin.H{
+		"queue_id":  start.ID,
+		"device_id": start.DeviceID,
+		"child_ids": start.ChildIDs,
+		"minutes":   start.Minutes,
+		"queued_at": start.QueuedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if start.Initiator.Type != "" {
+		response["initiator_type"] = start.Initiator.Type
+		response["initiator_id"] = start.Initiator.ID
+	}
 	return response
 }
 
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"context"
+	_ "embed"
+	"log/slog"
+	"metron/internal/api/apierror"
+	"metron/internal/core"
+	"metron/internal/devices"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// setupCheckTimeout bounds how long the driver checks of the setup status may take
+const setupCheckTimeout = 10 * time.Second
+
+//go:embed setup/index.html
+var setupPage []byte
+
+// SetupStorage tells whether the first child was added
+type SetupStorage interface {
+	ListChildren(ctx context.Context) ([]*core.Child, error)
+}
+
+// DriverHealthChecker checks that the drivers can reach their devices or services,
+// returning an error (or nil) by driver name
+type DriverHealthChecker interface {
+	CheckHealth(ctx context.Context) map[string]error
+}
+
+// SetupHandler serves the first-run setup page: until the first child is added, it shows the
+// configured devices and whether their drivers can be reached, and adds the child
+type SetupHandler struct {
+	storage        SetupStorage
+	deviceRegistry *devices.Registry
+	drivers        DriverHealthChecker
+	logger         *slog.Logger
+}
+
+// NewSetupHandler creates a new setup handler
+func NewSetupHandler(storage SetupStorage, deviceRegistry *devices.Registry, drivers DriverHealthChecker, logger *slog.Logger) *SetupHandler {
+	return &SetupHandler{
+		storage:        storage,
+		deviceRegistry: deviceRegistry,
+		drivers:        drivers,
+		logger:         logger,
+	}
+}
+
+// Page serves the setup page while no children exist
+// The page itself asks for the API key; everything it does goes through the authenticated API.
+// GET /setup
+func (h *SetupHandler) Page(c *gin.Context) {
+	needed, err := h.setupNeeded(c.Request.Context())
+	if err != nil {
+		apierror.Respond(c, apierror.InternalError, "Failed to check setup status")
+		return
+	}
+	if !needed {
+		apierror.Respond(c, apierror.NotFound, "Setup is complete")
+		return
+	}
+	c.Data(http.StatusOK, "text/html; charset=utf-8", setupPage)
+}
+
+// GetStatus returns whether setup is needed, the configured devices and the driver checks
+// GET /api/v1/setup
+func (h *SetupHandler) GetStatus(c *gin.Context) {
+	needed, err := h.setupNeeded(c.Request.Context())
+	if err != nil {
+		apierror.Respond(c, apierror.InternalError, "Failed to check setup status")
+		return
+	}
+
+	deviceList := h.deviceRegistry.List()
+	sort.Slice(deviceList, func(i, j int) bool { return deviceList[i].ID < deviceList[j].ID })
+	deviceResponses := make([]gin.H, 0, len(deviceList))
+	for _, device := range deviceList {
+		deviceResponses = append(deviceResponses, gin.H{
+			"id":     device.ID,
+			"name":   device.Name,
+			"type":   device.Type,
+			"driver": device.Driver,
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), setupCheckTimeout)
+	defer cancel()
+	driverResponses := make(map[string]gin.H)
+	for name, err := range h.drivers.CheckHealth(ctx) {
+		if err != nil {
+			driverResponses[name] = gin.H{"status": "DOWN", "error": err.Error()}
+		} else {
+			driverResponses[name] = gin.H{"status": "UP"}
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"setup_needed": needed,
+		"devices":      deviceResponses,
+		"drivers":      driverResponses,
+	})
+}
+
+// setupNeeded reports whether no children exist yet
+func (h *SetupHandler) setupNeeded(ctx context.Context) (bool, error) {
+	children, err := h.storage.ListChildren(ctx)
+	if err != nil {
+		h.logger.Error("Failed to list children",
+			"component", "api.setup",
+			"error", err)
+		return false, err
+	}
+	return len(children) == 0, nil
+}
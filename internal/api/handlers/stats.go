@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"log/slog"
+	"metron/internal/api/apierror"
 	"metron/internal/core"
 	"metron/internal/storage"
 	"net/http"
@@ -13,18 +14,18 @@ import (
 
 // StatsHandler handles statistics-related requests
 type StatsHandler struct {
-	storage storage.Storage
+	storage storage.Reader
 	manager StatsSessionManager
 	logger  *slog.Logger
 }
 
 // StatsSessionManager interface for stats operations
 type StatsSessionManager interface {
-	GetChildStatus(ctx context.Context, childID string) (*core.ChildStatus, error)
+	GetChildrenStatus(ctx context.Context) ([]*core.ChildStatus, error)
 }
 
 // NewStatsHandler creates a new stats handler
-func NewStatsHandler(storage storage.Storage, manager StatsSessionManager, logger *slog.Logger) *StatsHandler {
+func NewStatsHandler(storage storage.Reader, manager StatsSessionManager, logger *slog.Logger) *StatsHandler {
 	return &StatsHandler{
 		storage: storage,
 		manager: manager,
@@ -35,34 +36,25 @@ func NewStatsHandler(storage storage.Storage, manager StatsSessionManager, logge
 // GetTodayStats returns today's statistics for all children
 // GET /stats/today
 func (h *StatsHandler) GetTodayStats(c *gin.Context) {
-	children, err := h.storage.ListChildren(c.Request.Context())
+	statuses, err := h.manager.GetChildrenStatus(c.Request.Context())
 	if err != nil {
-		h.logger.Error("Failed to list children for stats",
+		h.logger.Error("Failed to get children status for stats",
 			"component", "api",
 			"error", err,
 		)
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "Failed to retrieve statistics",
-			"code":  "INTERNAL_ERROR",
+			"code":  apierror.InternalError,
 		})
 		return
 	}
 
 	today := time.Now()
-	childStats := make([]gin.H, 0, len(children))
+	childStats := make([]gin.H, 0, len(statuses))
 
-	for _, child := range children {
-		status, err := h.manager.GetChildStatus(c.Request.Context(), child.ID)
-		if err != nil {
-			h.logger.Error("Failed to get child status for stats",
-				"component", "api",
-				"child_id", child.ID,
-				"error", err,
-			)
-			continue
-		}
-
-		childStats = append(childStats, gin.H{
+	for _, status := range statuses {
+		child := status.Child
+		childStat := gin.H{
 			"child_id":             child.ID,
 			"child_name":           child.Name,
 			"child_emoji":          child.Emoji,
@@ -72,7 +64,9 @@ func (h *StatsHandler) GetTodayStats(c *gin.Context) {
 			"today_limit":          status.TodayLimit,
 			"sessions_today":       status.SessionsToday,
 			"usage_percent":        calculateUsagePercent(status.TodayUsed, status.TodayLimit),
-		})
+		}
+		formatChildTrackingPause(childStat, status.TrackingPause)
+		childStats = append(childStats, childStat)
 	}
 
 	// Get active sessions
@@ -90,7 +84,7 @@ func (h *StatsHandler) GetTodayStats(c *gin.Context) {
 		"date":            today.Format("2006-01-02"),
 		"children":        childStats,
 		"active_sessions": len(activeSessions),
-		"total_children":  len(children),
+		"total_children":  len(statuses),
 	}
 
 	c.JSON(http.StatusOK, response)
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"metron/internal/api/apierror"
+	"metron/internal/core"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TamperReporter stores tamper events reported by device agents
+type TamperReporter interface {
+	Report(ctx context.Context, deviceID, eventType, details string, occurredAt time.Time) (*core.TamperEvent, error)
+}
+
+// TamperEventLister lists stored tamper events
+type TamperEventLister interface {
+	List(ctx context.Context, since time.Time, deviceID string) ([]*core.TamperEvent, error)
+}
+
+// TamperHandler handles tamper event queries
+type TamperHandler struct {
+	events TamperEventLister
+	logger *slog.Logger
+}
+
+// NewTamperHandler creates a new tamper handler
+func NewTamperHandler(events TamperEventLister, logger *slog.Logger) *TamperHandler {
+	return &TamperHandler{
+		events: events,
+		logger: logger,
+	}
+}
+
+// ListTamperEvents returns tamper events received after an optional time, oldest first
+// GET /tamper-events?since=RFC3339&device_id=xxx
+func (h *TamperHandler) ListTamperEvents(c *gin.Context) {
+	var since time.Time
+	if raw := c.Query("since"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "since must be an RFC3339 timestamp",
+				"code":  apierror.InvalidRequest,
+			})
+			return
+		}
+		since = parsed
+	}
+	deviceID := c.Query("device_id")
+
+	events, err := h.events.List(c.Request.Context(), since, deviceID)
+	if err != nil {
+		h.logger.Error("Failed to list tamper events",
+			"component", "api.tamper",
+			"device_id", deviceID,
+			"error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve tamper events",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	response := make([]gin.H, len(events))
+	for i, event := range events {
+		response[i] = formatTamperEventResponse(event)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"events": response,
+	})
+}
+
+// formatTamperEventResponse formats a tamper event for responses
+func formatTamperEventResponse(event *core.TamperEvent) gin.H {
+	response := gin.H{
+		"id":          event.ID,
+		"device_id":   event.DeviceID,
+		"type":        event.Type,
+		"occurred_at": event.OccurredAt.Format(time.RFC3339),
+		"received_at": event.ReceivedAt.Format(time.RFC3339Nano),
+	}
+
+	if event.Details != "" {
+		response["details"] = event.Details
+	}
+
+	return response
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"metron/internal/api/apierror"
+	"metron/internal/core"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TrackingPauseService defines the vacation mode operations needed by the handler
+type TrackingPauseService interface {
+	Active(ctx context.Context) ([]*core.TrackingPause, error)
+	Pause(ctx context.Context, childID, pausedBy, reason string, resumesAt *time.Time) (*core.TrackingPause, error)
+	Resume(ctx context.Context, childID, resumedBy string) (*core.TrackingPause, error)
+}
+
+// TrackingPauseHandler handles tracking pause (vacation mode) requests
+type TrackingPauseHandler struct {
+	trackingPause TrackingPauseService
+	logger        *slog.Logger
+}
+
+// NewTrackingPauseHandler creates a new tracking pause handler
+func NewTrackingPauseHandler(trackingPause TrackingPauseService, logger *slog.Logger) *TrackingPauseHandler {
+	return &TrackingPauseHandler{
+		trackingPause: trackingPause,
+		logger:        logger,
+	}
+}
+
+// GetTrackingPause returns the active tracking pauses
+// GET /tracking-pause
+func (h *TrackingPauseHandler) GetTrackingPause(c *gin.Context) {
+	pauses, err := h.trackingPause.Active(c.Request.Context())
+	if err != nil {
+		h.logger.Error("Failed to get tracking pauses",
+			"component", "api.tracking_pause",
+			"error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve tracking pause status",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	response := make([]gin.H, len(pauses))
+	for i, pause := range pauses {
+		response[i] = formatTrackingPauseResponse(pause)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"paused": core.FindTrackingPause(pauses, "", time.Now()) != nil,
+		"pauses": response,
+	})
+}
+
+// PauseTracking pauses tracking for a child, or for everyone if child_id is omitted
+// POST /tracking-pause
+func (h *TrackingPauseHandler) PauseTracking(c *gin.Context) {
+	var req struct {
+		ChildID   string     `json:"child_id"`
+		Reason    string     `json:"reason"`
+		PausedBy  string     `json:"paused_by"`
+		ResumesAt *time.Time `json:"resumes_at"`
+	}
+
+	// Body is optional: an empty POST pauses tracking for everyone until resumed
+	if c.Request.ContentLength > 0 {
+		if err := bindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"code":    apierror.InvalidRequest,
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	if req.PausedBy == "" {
+		req.PausedBy = "api"
+	}
+
+	pause, err := h.trackingPause.Pause(c.Request.Context(), req.ChildID, req.PausedBy, req.Reason, req.ResumesAt)
+	if err != nil {
+		if errors.Is(err, core.ErrTrackingAlreadyPaused) {
+			c.JSON(http.StatusConflict, gin.H{
+				"error": "Tracking is already paused",
+				"code":  apierror.TrackingAlreadyPaused,
+				"pause": formatTrackingPauseResponse(pause),
+			})
+			return
+		}
+
+		h.logger.Error("Failed to pause tracking",
+			"component", "api.tracking_pause",
+			"child_id", req.ChildID,
+			"paused_by", req.PausedBy,
+			"error", err)
+		apierror.RespondError(c, err, apierror.InternalError)
+		return
+	}
+
+	c.JSON(http.StatusCreated, formatTrackingPauseResponse(pause))
+}
+
+// ResumeTracking resumes tracking for a child, or for everyone if child_id is omitted
+// DELETE /tracking-pause
+func (h *TrackingPauseHandler) ResumeTracking(c *gin.Context) {
+	var req struct {
+		ChildID   string `json:"child_id"`
+		ResumedBy string `json:"resumed_by"`
+	}
+
+	// Body is optional for DELETE
+	if c.Request.ContentLength > 0 {
+		if err := bindJSON(c, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"code":    apierror.InvalidRequest,
+				"details": err.Error(),
+			})
+			return
+		}
+	}
+
+	if req.ResumedBy == "" {
+		req.ResumedBy = "api"
+	}
+
+	pause, err := h.trackingPause.Resume(c.Request.Context(), req.ChildID, req.ResumedBy)
+	if err != nil {
+		if !errors.Is(err, core.ErrTrackingNotPaused) {
+			h.logger.Error("Failed to resume tracking",
+				"component", "api.tracking_pause",
+				"child_id", req.ChildID,
+				"resumed_by", req.ResumedBy,
+				"error", err)
+		}
+		apierror.RespondError(c, err, apierror.InternalError)
+		return
+	}
+
+	c.JSON(http.StatusOK, formatTrackingPauseResponse(pause))
+}
+
+func formatTrackingPauseResponse(pause *core.TrackingPause) gin.H {
+	response := gin.H{
+		"id":         pause.ID,
+		"global":     pause.IsGlobal(),
+		"reason":     pause.Reason,
+		"paused_by":  pause.PausedBy,
+		"started_at": pause.StartedAt.Format(time.RFC3339),
+		"active":     pause.IsActive(time.Now()),
+	}
+
+	if pause.ChildID != "" {
+		response["child_id"] = pause.ChildID
+	}
+	if pause.ResumesAt != nil {
+		response["resumes_at"] = pause.ResumesAt.Format(time.RFC3339)
+	}
+	if pause.ResumedAt != nil {
+		response["resumed_at"] = pause.ResumedAt.Format(time.RFC3339)
+		response["resumed_by"] = pause.ResumedBy
+	}
+
+	return response
+}
+
+// formatChildTrackingPause adds the vacation mode fields to a child status response
+func formatChildTrackingPause(response gin.H, pause *core.TrackingPause) {
+	response["tracking_paused"] = pause != nil
+	if pause != nil && pause.ResumesAt != nil {
+		response["tracking_resumes_at"] = pause.ResumesAt.Format(time.RFC3339)
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"context"
+	"log/slog"
+	"metron/internal/api/apierror"
+	"metron/internal/core"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UsageAlertLister lists stored usage alerts
+type UsageAlertLister interface {
+	List(ctx context.Context, since time.Time, childID string) ([]*core.UsageAlert, error)
+}
+
+// UsageAlertsHandler handles usage alert queries
+type UsageAlertsHandler struct {
+	alerts UsageAlertLister
+	logger *slog.Logger
+}
+
+// NewUsageAlertsHandler creates a new usage alerts handler
+func NewUsageAlertsHandler(alerts UsageAlertLister, logger *slog.Logger) *UsageAlertsHandler {
+	return &UsageAlertsHandler{
+		alerts: alerts,
+		logger: logger,
+	}
+}
+
+// ListUsageAlerts returns usage alerts created after an optional time, oldest first
+// GET /usage-alerts?since=RFC3339&child_id=xxx
+func (h *UsageAlertsHandler) ListUsageAlerts(c *gin.Context) {
+	var since time.Time
+	if raw := c.Query("since"); raw != "" {
+		parsed, err := time.Parse(time.RFC3339, raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "since must be an RFC3339 timestamp",
+				"code":  apierror.InvalidRequest,
+			})
+			return
+		}
+		since = parsed
+	}
+	childID := c.Query("child_id")
+
+	alerts, err := h.alerts.List(c.Request.Context(), since, childID)
+	if err != nil {
+		h.logger.Error("Failed to list usage alerts",
+			"component", "api.usage_alerts",
+			"child_id", childID,
+			"error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "Failed to retrieve usage alerts",
+			"code":  apierror.InternalError,
+		})
+		return
+	}
+
+	response := make([]gin.H, len(alerts))
+	for i, alert := range alerts {
+		response[i] = formatUsageAlertResponse(alert)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"alerts": response,
+	})
+}
+
+// formatUsageAlertResponse formats a usage alert for responses
+func formatUsageAlertResponse(alert *core.UsageAlert) gin.H {
+	return gin.H{
+		"id":                alert.ID,
+		"child_id":          alert.ChildID,
+		"date":              alert.Date.Format("2006-01-02"),
+		"threshold":         alert.Threshold,
+		"used_minutes":      alert.UsedMinutes,
+		"limit_minutes":     alert.LimitMinutes,
+		"remaining_minutes": alert.RemainingMinutes(),
+		"created_at":        alert.CreatedAt.Format(time.RFC3339Nano),
+	}
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"metron/internal/api/apierror"
+	"metron/internal/core"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// UsageCorrector corrects the usage charged to children's days
+type UsageCorrector interface {
+	Correct(ctx context.Context, childID string, date time.Time, minutes int, reason, actor string) (*core.UsageCorrection, error)
+}
+
+// UsageCorrectionsHandler handles corrections of historical usage
+type UsageCorrectionsHandler struct {
+	corrections UsageCorrector
+	logger      *slog.Logger
+}
+
+// NewUsageCorrectionsHandler creates a new usage corrections handler
+func NewUsageCorrectionsHandler(corrections UsageCorrector, logger *slog.Logger) *UsageCorrectionsHandler {
+	return &UsageCorrectionsHandler{
+		corrections: corrections,
+		logger:      logger,
+	}
+}
+
+// CorrectUsage adds or refunds minutes charged to a child's day
+// PATCH /admin/usage
+func (h *UsageCorrectionsHandler) CorrectUsage(c *gin.Context) {
+	var req struct {
+		ChildID     string `json:"child_id" binding:"required"`
+		Date        string `json:"date" binding:"required"`    // YYYY-MM-DD in the child's timezone
+		Minutes     int    `json:"minutes" binding:"required"` // Negative minutes are refunded
+		Reason      string `json:"reason" binding:"required"`
+		CorrectedBy string `json:"corrected_by"`
+	}
+	if err := bindJSON(c, &req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"code":    apierror.InvalidRequest,
+			"details": err.Error(),
+		})
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", req.Date)
+	if err != nil {
+		apierror.Respond(c, apierror.InvalidDateFormat, "date must use the YYYY-MM-DD format")
+		return
+	}
+	if req.CorrectedBy == "" {
+		req.CorrectedBy = "api"
+	}
+
+	correction, err := h.corrections.Correct(c.Request.Context(), req.ChildID, date, req.Minutes, req.Reason, req.CorrectedBy)
+	if err != nil {
+		if errors.Is(err, core.ErrChildNotFound) {
+			apierror.Respond(c, apierror.ChildNotFound, "Child not found")
+			return
+		}
+		if _, ok := apierror.FromError(err); ok {
+			apierror.RespondError(c, err, apierror.InternalError)
+			return
+		}
+		h.logger.Error("Failed to correct usage",
+			"component", "api.usage",
+			"child_id", req.ChildID,
+			"error", err)
+		apierror.Respond(c, apierror.InternalError, "Failed to correct usage")
+		return
+	}
+
+	response := gin.H{
+		"child_id":         correction.ChildID,
+		"date":             correction.Date.Format("2006-01-02"),
+		"minutes":          correction.Minutes,
+		"previous_minutes": correction.PreviousMinutes,
+		"minutes_used":     correction.MinutesUsed,
+		"reason":           correction.Reason,
+		"corrected_by":     correction.Actor,
+	}
+	if correction.Rollover != nil {
+		response["rollover"] = formatDayRolloverResponse(correction.Rollover)
+	}
+	c.JSON(http.StatusOK, response)
+}
@@ -1,7 +1,11 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"metron/config"
+	"metron/internal/api/apierror"
+	"metron/internal/core"
 	"net/http"
 	"strings"
 
@@ -15,14 +19,22 @@ const (
 	AgentDeviceNameKey = "agent_device_name"
 )
 
+// AgentTokenAuthenticator validates server-issued agent tokens
+type AgentTokenAuthenticator interface {
+	Authenticate(ctx context.Context, token string) (*core.AgentToken, error)
+}
+
 // AgentAuth validates agent tokens from Authorization Bearer header.
-// Tokens are looked up from device parameters (agent_token field).
+// Tokens are looked up from device parameters (agent_token field), then among the
+// tokens issued through /api/v1/admin/agents (issued may be nil).
 // On success, sets device_id in context for handler use.
-func AgentAuth(devices []config.DeviceConfig) gin.HandlerFunc {
-	// Build a lookup map from token -> device for O(1) lookup
+func AgentAuth(devices []config.DeviceConfig, issued AgentTokenAuthenticator) gin.HandlerFunc {
+	// Build lookup maps from token -> device and ID -> device for O(1) lookup
 	tokenToDevice := make(map[string]*config.DeviceConfig)
+	deviceByID := make(map[string]*config.DeviceConfig)
 	for i := range devices {
 		device := &devices[i]
+		deviceByID[device.ID] = device
 		if token := getDeviceAgentToken(device); token != "" {
 			tokenToDevice[token] = device
 		}
@@ -34,7 +46,7 @@ func AgentAuth(devices []config.DeviceConfig) gin.HandlerFunc {
 		if authHeader == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "Authorization header required",
-				"code":  "AUTH_REQUIRED",
+				"code":  apierror.AuthRequired,
 			})
 			c.Abort()
 			return
@@ -45,7 +57,7 @@ func AgentAuth(devices []config.DeviceConfig) gin.HandlerFunc {
 		if !strings.HasPrefix(authHeader, bearerPrefix) {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "Invalid authorization scheme. Use Bearer token.",
-				"code":  "INVALID_AUTH_SCHEME",
+				"code":  apierror.InvalidAuthScheme,
 			})
 			c.Abort()
 			return
@@ -56,7 +68,7 @@ func AgentAuth(devices []config.DeviceConfig) gin.HandlerFunc {
 		if token == "" {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "Token required",
-				"code":  "TOKEN_REQUIRED",
+				"code":  apierror.TokenRequired,
 			})
 			c.Abort()
 			return
@@ -64,10 +76,25 @@ func AgentAuth(devices []config.DeviceConfig) gin.HandlerFunc {
 
 		// Find matching device by token
 		device, found := tokenToDevice[token]
+		if !found && issued != nil {
+			agentToken, err := issued.Authenticate(c.Request.Context(), token)
+			if err != nil && !errors.Is(err, core.ErrAgentTokenNotFound) && !errors.Is(err, core.ErrAgentTokenRevoked) {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to validate token",
+					"code":  apierror.InternalError,
+				})
+				c.Abort()
+				return
+			}
+			// Tokens of devices removed from the config are rejected
+			if err == nil {
+				device, found = deviceByID[agentToken.DeviceID]
+			}
+		}
 		if !found {
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "Invalid token",
-				"code":  "INVALID_TOKEN",
+				"code":  apierror.InvalidToken,
 			})
 			c.Abort()
 			return
@@ -77,7 +104,7 @@ func AgentAuth(devices []config.DeviceConfig) gin.HandlerFunc {
 		if !isAgentEnabled(device) {
 			c.JSON(http.StatusForbidden, gin.H{
 				"error": "Agent is disabled for this device",
-				"code":  "AGENT_DISABLED",
+				"code":  apierror.AgentDisabled,
 			})
 			c.Abort()
 			return
@@ -0,0 +1,236 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultCacheTTL is how long read-heavy responses are cached when no lifetime is configured
+const DefaultCacheTTL = 5 * time.Second
+
+// cachedResponse is a successful GET response kept by the ResponseCache
+type cachedResponse struct {
+	body        []byte
+	contentType string
+	etag        string
+	expiresAt   time.Time
+}
+
+// ResponseCache adds ETags to GET responses and keeps successful ones for a short time,
+// so clients that poll (the Telegram bot, the child web app) do not hit storage on every request.
+// Entries are per URL and API version and, on child routes, per child. Any successful write through the API
+// clears the whole cache (see InvalidateOnWrite); changes made by the scheduler show up
+// once the entry expires.
+type ResponseCache struct {
+	ttl     time.Duration
+	mu      sync.Mutex
+	entries map[string]*cachedResponse
+}
+
+// NewResponseCache creates a response cache; a ttl of 0 disables caching but keeps ETags
+func NewResponseCache(ttl time.Duration) *ResponseCache {
+	return &ResponseCache{
+		ttl:     ttl,
+		entries: make(map[string]*cachedResponse),
+	}
+}
+
+// Cached is route middleware that serves the response from the cache while it is fresh,
+// and answers 304 Not Modified when the client's If-None-Match matches the ETag
+func (rc *ResponseCache) Cached() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		key := RequestAPIVersion(c) + "|" + c.Request.URL.RequestURI()
+		if childID := c.GetString(ChildIDKey); childID != "" {
+			key = childID + "|" + key
+		}
+
+		if entry := rc.get(key); entry != nil {
+			c.Header("X-Cache", "HIT")
+			writeWithETag(c, http.StatusOK, entry.contentType, entry.etag, entry.body)
+			c.Abort()
+			return
+		}
+
+		// Buffer the handler's response to compute its ETag
+		original := c.Writer
+		buffered := &bufferedWriter{ResponseWriter: original, status: http.StatusOK}
+		c.Writer = buffered
+		c.Next()
+		c.Writer = original
+
+		if buffered.status != http.StatusOK {
+			original.WriteHeader(buffered.status)
+			original.Write(buffered.body.Bytes())
+			return
+		}
+
+		body := buffered.body.Bytes()
+		entry := &cachedResponse{
+			body:        body,
+			contentType: original.Header().Get("Content-Type"),
+			etag:        computeETag(body),
+		}
+		rc.put(key, entry)
+		c.Header("X-Cache", "MISS")
+		writeWithETag(c, http.StatusOK, entry.contentType, entry.etag, body)
+	}
+}
+
+// InvalidateOnWrite is global middleware that clears the cache after every successful
+// request that may change data (anything but GET, HEAD, OPTIONS and ReadOnly routes)
+func (rc *ResponseCache) InvalidateOnWrite() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			return
+		}
+		if c.GetBool(ReadOnlyKey) {
+			return
+		}
+		if c.Writer.Status() < http.StatusBadRequest {
+			rc.Clear()
+		}
+	}
+}
+
+// ReadOnlyKey marks requests that never change data, whatever their method
+const ReadOnlyKey = "read_only"
+
+// ReadOnly is route middleware for POST endpoints that only read (GraphQL queries),
+// so InvalidateOnWrite leaves the cache alone
+func ReadOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(ReadOnlyKey, true)
+		c.Next()
+	}
+}
+
+// Clear removes all cached responses
+func (rc *ResponseCache) Clear() {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.entries = make(map[string]*cachedResponse)
+}
+
+// get returns a fresh entry, or nil
+func (rc *ResponseCache) get(key string) *cachedResponse {
+	if rc.ttl <= 0 {
+		return nil
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	entry, ok := rc.entries[key]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil
+	}
+	return entry
+}
+
+// put stores an entry and drops expired ones
+func (rc *ResponseCache) put(key string, entry *cachedResponse) {
+	if rc.ttl <= 0 {
+		return
+	}
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	now := time.Now()
+	for existingKey, existing := range rc.entries {
+		if now.After(existing.expiresAt) {
+			delete(rc.entries, existingKey)
+		}
+	}
+	entry.expiresAt = now.Add(rc.ttl)
+	rc.entries[key] = entry
+}
+
+// writeWithETag writes a response with its ETag, or 304 Not Modified if the client has it
+func writeWithETag(c *gin.Context, status int, contentType, etag string, body []byte) {
+	// Clients must revalidate, so a poll never shows data older than the server's cache
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "private, no-cache")
+
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		c.Writer.WriteHeaderNow()
+		return
+	}
+
+	if contentType != "" {
+		c.Header("Content-Type", contentType)
+	}
+	c.Status(status)
+	c.Writer.Write(body)
+}
+
+// etagMatches reports whether an If-None-Match header lists the ETag
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// computeETag returns an ETag for a response body
+// It is weak because the same body may be sent compressed (see Compression).
+func computeETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `W/"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// bufferedWriter keeps the response body and status in memory instead of sending them
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body   bytes.Buffer
+	status int
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	w.status = code
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) Status() int {
+	return w.status
+}
+
+func (w *bufferedWriter) Size() int {
+	return w.body.Len()
+}
+
+func (w *bufferedWriter) Written() bool {
+	return w.body.Len() > 0
+}
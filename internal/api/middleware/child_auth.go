@@ -1,151 +1,74 @@
 package middleware
 
 import (
-	"crypto/rand"
-	"encoding/hex"
-	"sync"
-	"time"
+	"context"
+	"errors"
+	"metron/internal/api/apierror"
+	"metron/internal/core"
+	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
 const ChildIDKey = "child_id"
 
-// ChildSession represents an authenticated child session
-type ChildSession struct {
-	SessionID string
-	ChildID   string
-	ExpiresAt time.Time
-}
-
-// SessionManager manages child authentication sessions
-type SessionManager struct {
-	sessions map[string]*ChildSession
-	mu       sync.RWMutex
-	duration time.Duration
-}
-
-// NewSessionManager creates a new session manager
-func NewSessionManager() *SessionManager {
-	sm := &SessionManager{
-		sessions: make(map[string]*ChildSession),
-		duration: 24 * time.Hour, // 24 hour sessions
-	}
-
-	// Start background goroutine to clean up expired sessions
-	go sm.cleanupExpiredSessions()
-
-	return sm
-}
-
-// CreateSession creates a new session for a child
-func (sm *SessionManager) CreateSession(childID string) string {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
-
-	// Generate random session ID
-	sessionID := generateSessionID()
-
-	session := &ChildSession{
-		SessionID: sessionID,
-		ChildID:   childID,
-		ExpiresAt: time.Now().Add(sm.duration),
-	}
-
-	sm.sessions[sessionID] = session
-
-	return sessionID
-}
-
-// ValidateSession checks if a session is valid and returns the child ID
-func (sm *SessionManager) ValidateSession(sessionID string) (string, bool) {
-	sm.mu.RLock()
-	defer sm.mu.RUnlock()
-
-	session, exists := sm.sessions[sessionID]
-	if !exists {
-		return "", false
-	}
-
-	// Check if expired
-	if time.Now().After(session.ExpiresAt) {
-		return "", false
-	}
+// ChildSessionCookie is the cookie holding the child web app's login token
+const ChildSessionCookie = "child_session"
 
-	return session.ChildID, true
+// ChildLoginAuthenticator validates the login tokens of the child web app
+type ChildLoginAuthenticator interface {
+	Authenticate(ctx context.Context, token string) (*core.ChildLogin, error)
 }
 
-// DeleteSession removes a session (logout)
-func (sm *SessionManager) DeleteSession(sessionID string) {
-	sm.mu.Lock()
-	defer sm.mu.Unlock()
+// ChildSessionToken returns the login token from the session cookie or the Authorization header
+func ChildSessionToken(c *gin.Context) string {
+	// Try to get session ID from cookie first
+	sessionID, err := c.Cookie(ChildSessionCookie)
 
-	delete(sm.sessions, sessionID)
-}
-
-// cleanupExpiredSessions periodically removes expired sessions
-func (sm *SessionManager) cleanupExpiredSessions() {
-	ticker := time.NewTicker(1 * time.Hour)
-	defer ticker.Stop()
-
-	for range ticker.C {
-		sm.mu.Lock()
-		now := time.Now()
-		for sessionID, session := range sm.sessions {
-			if now.After(session.ExpiresAt) {
-				delete(sm.sessions, sessionID)
-			}
+	// If not in cookie, try Authorization header
+	if err != nil || sessionID == "" {
+		authHeader := c.GetHeader("Authorization")
+		if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
+			sessionID = authHeader[7:]
 		}
-		sm.mu.Unlock()
 	}
-}
-
-// generateSessionID generates a secure random session ID
-func generateSessionID() string {
-	bytes := make([]byte, 32) // 256 bits
-	if _, err := rand.Read(bytes); err != nil {
-		// Fallback to timestamp-based ID (should never happen)
-		return hex.EncodeToString([]byte(time.Now().String()))
-	}
-	return hex.EncodeToString(bytes)
+	return sessionID
 }
 
 // ChildAuth is middleware that validates child authentication
-func ChildAuth(sm *SessionManager) gin.HandlerFunc {
+// Logins are stored, so children stay logged in across restarts until they expire or are revoked.
+func ChildAuth(logins ChildLoginAuthenticator) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Try to get session ID from cookie first
-		sessionID, err := c.Cookie("child_session")
-
-		// If not in cookie, try Authorization header
-		if err != nil || sessionID == "" {
-			authHeader := c.GetHeader("Authorization")
-			if len(authHeader) > 7 && authHeader[:7] == "Bearer " {
-				sessionID = authHeader[7:]
-			}
-		}
-
 		// Validate session ID
+		sessionID := ChildSessionToken(c)
 		if sessionID == "" {
-			c.JSON(401, gin.H{
+			c.JSON(http.StatusUnauthorized, gin.H{
 				"error": "Authentication required",
-				"code":  "MISSING_SESSION",
+				"code":  apierror.MissingSession,
 			})
 			c.Abort()
 			return
 		}
 
-		childID, valid := sm.ValidateSession(sessionID)
-		if !valid {
-			c.JSON(401, gin.H{
-				"error": "Invalid or expired session",
-				"code":  "INVALID_SESSION",
-			})
+		login, err := logins.Authenticate(c.Request.Context(), sessionID)
+		if err != nil {
+			if errors.Is(err, core.ErrChildLoginNotFound) || errors.Is(err, core.ErrChildLoginRevoked) || errors.Is(err, core.ErrChildLoginExpired) {
+				c.JSON(http.StatusUnauthorized, gin.H{
+					"error": "Invalid or expired session",
+					"code":  apierror.InvalidSession,
+				})
+			} else {
+				c.JSON(http.StatusInternalServerError, gin.H{
+					"error": "Failed to validate session",
+					"code":  apierror.InternalError,
+				})
+			}
 			c.Abort()
 			return
 		}
 
 		// Store child ID in context
-		c.Set(ChildIDKey, childID)
+		c.Set(ChildIDKey, login.ChildID)
 		c.Next()
 	}
 }
@@ -25,8 +25,9 @@ func (w *responseBodyWriter) Write(b []byte) (int, error) {
 // ChildAPILogging logs Child API requests and responses
 func ChildAPILogging(logger *slog.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Only log /child/* routes
-		if !strings.HasPrefix(c.Request.URL.Path, "/child") {
+		// Only log /api/v1/child/* and legacy /child/* routes
+		path := c.Request.URL.Path
+		if !strings.HasPrefix(path, APIPrefix+"/child/") && !strings.HasPrefix(path, "/child") {
 			c.Next()
 			return
 		}
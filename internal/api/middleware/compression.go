@@ -0,0 +1,155 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// compressionMinSize is the smallest response body worth compressing, in bytes
+const compressionMinSize = 1024
+
+// brotliLevel trades compression ratio for CPU (0-11); 5 is close to gzip's speed
+const brotliLevel = 5
+
+// Compression compresses JSON and text responses with brotli or gzip, whichever the client
+// prefers (brotli on a tie). Small responses, range requests and binary downloads
+// (e.g. agent updates) are sent as they are.
+func Compression() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method == http.MethodHead || c.GetHeader("Range") != "" {
+			c.Next()
+			return
+		}
+
+		encoding := negotiateEncoding(c.GetHeader("Accept-Encoding"))
+		if encoding == "" {
+			c.Next()
+			return
+		}
+
+		writer := &compressWriter{ResponseWriter: c.Writer, encoding: encoding}
+		c.Writer = writer
+		defer writer.finish()
+
+		c.Next()
+	}
+}
+
+// negotiateEncoding picks "br" or "gzip" from an Accept-Encoding header, or "" for neither
+func negotiateEncoding(acceptEncoding string) string {
+	var brQuality, gzipQuality float64
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		quality := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "br":
+			brQuality = quality
+		case "gzip":
+			gzipQuality = quality
+		}
+	}
+
+	switch {
+	case brQuality > 0 && brQuality >= gzipQuality:
+		return "br"
+	case gzipQuality > 0:
+		return "gzip"
+	}
+	return ""
+}
+
+// compressWriter holds back the start of the body until it knows whether the response
+// is worth compressing, then compresses the rest as it is written
+type compressWriter struct {
+	gin.ResponseWriter
+	encoding string
+	pending  bytes.Buffer   // Start of the body, until compressionMinSize bytes are written
+	encoder  io.WriteCloser // Set once compression started
+	decided  bool
+}
+
+func (w *compressWriter) Write(data []byte) (int, error) {
+	if w.decided {
+		if w.encoder != nil {
+			return w.encoder.Write(data)
+		}
+		return w.ResponseWriter.Write(data)
+	}
+
+	if !w.compressible() {
+		w.decided = true
+		return w.ResponseWriter.Write(data)
+	}
+
+	w.pending.Write(data)
+	if w.pending.Len() < compressionMinSize {
+		return len(data), nil
+	}
+
+	w.decided = true
+	w.startEncoder()
+	if _, err := w.encoder.Write(w.pending.Bytes()); err != nil {
+		return 0, err
+	}
+	w.pending.Reset()
+	return len(data), nil
+}
+
+func (w *compressWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *compressWriter) Written() bool {
+	return w.ResponseWriter.Written() || w.pending.Len() > 0
+}
+
+// compressible returns true for uncompressed JSON and text responses
+func (w *compressWriter) compressible() bool {
+	header := w.Header()
+	if header.Get("Content-Encoding") != "" {
+		return false
+	}
+	contentType := header.Get("Content-Type")
+	return strings.HasPrefix(contentType, "application/json") || strings.HasPrefix(contentType, "text/")
+}
+
+// startEncoder switches the response to the negotiated encoding
+func (w *compressWriter) startEncoder() {
+	header := w.Header()
+	header.Set("Content-Encoding", w.encoding)
+	header.Add("Vary", "Accept-Encoding")
+	header.Del("Content-Length")
+
+	if w.encoding == "br" {
+		w.encoder = brotli.NewWriterLevel(w.ResponseWriter, brotliLevel)
+	} else {
+		w.encoder = gzip.NewWriter(w.ResponseWriter)
+	}
+}
+
+// finish sends a body too small to compress as it is, or ends the compressed stream
+func (w *compressWriter) finish() {
+	if w.encoder != nil {
+		w.encoder.Close()
+		return
+	}
+	if w.pending.Len() > 0 {
+		w.Header().Add("Vary", "Accept-Encoding")
+		w.ResponseWriter.Write(w.pending.Bytes())
+	}
+}
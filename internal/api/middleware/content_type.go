@@ -1,21 +1,28 @@
 package middleware
 
 import (
+	"metron/internal/api/apierror"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
 // ContentType enforces JSON content-type for POST and PATCH requests
-func ContentType() gin.HandlerFunc {
+// Requests to formPaths (webhooks of services that post forms, e.g. Plex) are not checked.
+func ContentType(formPaths ...string) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if slices.Contains(formPaths, c.Request.URL.Path) {
+			c.Next()
+			return
+		}
 		if c.Request.Method == http.MethodPost || c.Request.Method == http.MethodPatch {
 			contentType := c.GetHeader("Content-Type")
 			if !strings.Contains(contentType, "application/json") {
 				c.JSON(http.StatusUnsupportedMediaType, gin.H{
 					"error": "Content-Type must be application/json",
-					"code":  "INVALID_CONTENT_TYPE",
+					"code":  apierror.InvalidContentType,
 				})
 				c.Abort()
 				return
@@ -0,0 +1,67 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSConfig controls which browser origins may call the API, e.g. the child web app
+// served from its own host
+type CORSConfig struct {
+	AllowedOrigins   []string // Origins like "https://kids.example.com"; "*" allows any origin
+	AllowCredentials bool     // Whether browsers may send cookies (the child session cookie) cross-origin
+}
+
+// DefaultCORSConfig allows any origin with credentials, which is how the API behaved
+// before CORS became configurable
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowedOrigins:   []string{"*"},
+		AllowCredentials: true,
+	}
+}
+
+// CORS answers preflight requests and adds CORS headers for allowed origins
+// Requests from other origins get no CORS headers, so browsers block them.
+func CORS(config CORSConfig) gin.HandlerFunc {
+	anyOrigin := false
+	allowed := make(map[string]bool, len(config.AllowedOrigins))
+	for _, origin := range config.AllowedOrigins {
+		if origin == "*" {
+			anyOrigin = true
+			continue
+		}
+		allowed[strings.ToLower(strings.TrimSuffix(origin, "/"))] = true
+	}
+
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		origin := c.GetHeader("Origin")
+
+		switch {
+		case origin == "":
+			// Non-browser request
+			if anyOrigin {
+				header.Set("Access-Control-Allow-Origin", "*")
+			}
+		case anyOrigin || allowed[strings.ToLower(origin)]:
+			// Browsers only send credentials to an exact origin, never to "*"
+			header.Set("Access-Control-Allow-Origin", origin)
+			header.Add("Vary", "Origin")
+			if config.AllowCredentials {
+				header.Set("Access-Control-Allow-Credentials", "true")
+			}
+			header.Set("Access-Control-Allow-Methods", "GET, POST, PATCH, DELETE, OPTIONS")
+			header.Set("Access-Control-Allow-Headers", "Content-Type, Authorization, If-None-Match, "+APIVersionHeader)
+			header.Set("Access-Control-Expose-Headers", "ETag, X-Request-ID, Deprecation, Sunset, Link, "+APIVersionHeader)
+		}
+
+		if c.Request.Method == http.MethodOptions {
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"metron/internal/api/apierror"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DefaultMaxBodyBytes is the request body size limit when none is configured (1 MiB)
+const DefaultMaxBodyBytes = 1 << 20
+
+// StrictJSONKey marks requests whose JSON bodies must not contain unknown fields
+const StrictJSONKey = "strict_json"
+
+// idPattern is what IDs in paths and query parameters may look like: UUIDs, generated IDs
+// (e.g. "lim_..."), and device IDs from the config (e.g. "win-pc1")
+var idPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.:@-]{0,127}$`)
+
+// BodyLimit rejects request bodies larger than maxBytes (DefaultMaxBodyBytes if not positive)
+// Requests announcing a larger Content-Length get 413; bodies without one are cut off at the
+// limit, so they fail to decode.
+func BodyLimit(maxBytes int64) gin.HandlerFunc {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxBodyBytes
+	}
+	return func(c *gin.Context) {
+		if c.Request.ContentLength > maxBytes {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+				"error": "Request body is too large",
+				"code":  apierror.RequestTooLarge,
+			})
+			c.Abort()
+			return
+		}
+		if c.Request.Body != nil {
+			c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		}
+		c.Next()
+	}
+}
+
+// StrictJSON makes handlers reject JSON bodies with unknown fields (see IsStrictJSON)
+// It is used on the parent API, where a misspelled field would otherwise be silently ignored.
+func StrictJSON() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(StrictJSONKey, true)
+		c.Next()
+	}
+}
+
+// IsStrictJSON returns true if the request's JSON body must not contain unknown fields
+func IsStrictJSON(c *gin.Context) bool {
+	return c.GetBool(StrictJSONKey)
+}
+
+// ValidateIDs rejects requests whose path parameters, or query parameters named "id" or
+// ending with "_id"/"Id", are too long or contain characters no ID has
+func ValidateIDs() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		for _, param := range c.Params {
+			if !idPattern.MatchString(param.Value) {
+				rejectID(c, param.Key)
+				return
+			}
+		}
+
+		for key, values := range c.Request.URL.Query() {
+			if key != "id" && !strings.HasSuffix(key, "_id") && !strings.HasSuffix(key, "Id") {
+				continue
+			}
+			for _, value := range values {
+				if value != "" && !idPattern.MatchString(value) {
+					rejectID(c, key)
+					return
+				}
+			}
+		}
+		c.Next()
+	}
+}
+
+func rejectID(c *gin.Context, name string) {
+	c.JSON(http.StatusBadRequest, gin.H{
+		"error": "Invalid " + name,
+		"code":  apierror.InvalidID,
+	})
+	c.Abort()
+}
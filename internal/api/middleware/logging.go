@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"log/slog"
+	"net/url"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -30,7 +31,7 @@ func Logging(logger *slog.Logger) gin.HandlerFunc {
 		errorMessage := c.Errors.ByType(gin.ErrorTypePrivate).String()
 
 		if raw != "" {
-			path = path + "?" + raw
+			path = path + "?" + redactQuery(raw)
 		}
 
 		logger.Info("HTTP request",
@@ -45,3 +46,13 @@ func Logging(logger *slog.Logger) gin.HandlerFunc {
 		)
 	}
 }
+
+// redactQuery hides the token query parameter (e.g., of media server webhook URLs)
+func redactQuery(raw string) string {
+	values, err := url.ParseQuery(raw)
+	if err != nil || !values.Has("token") {
+		return raw
+	}
+	values.Set("token", "REDACTED")
+	return values.Encode()
+}
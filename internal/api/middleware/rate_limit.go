@@ -0,0 +1,53 @@
+package middleware
+
+import (
+	"math"
+	"metron/internal/api/apierror"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RateLimit allows each client (by IP) at most requests per window on the routes it guards;
+// further requests get 429 with a Retry-After header until the window is over.
+// It is meant for expensive endpoints, not as protection for the whole API.
+func RateLimit(requests int, window time.Duration) gin.HandlerFunc {
+	type clientWindow struct {
+		start time.Time
+		count int
+	}
+	var mu sync.Mutex
+	clients := make(map[string]*clientWindow)
+
+	return func(c *gin.Context) {
+		now := time.Now()
+		ip := c.ClientIP()
+
+		mu.Lock()
+		// Forget clients whose window is over, so the map stays small
+		for key, client := range clients {
+			if now.Sub(client.start) >= window {
+				delete(clients, key)
+			}
+		}
+		client, ok := clients[ip]
+		if !ok {
+			client = &clientWindow{start: now}
+			clients[ip] = client
+		}
+		client.count++
+		allowed := client.count <= requests
+		retryAfter := client.start.Add(window).Sub(now)
+		mu.Unlock()
+
+		if !allowed {
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			apierror.Respond(c, apierror.RateLimited, "Too many requests, try again later")
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"log/slog"
+	"metron/internal/api/apierror"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -21,7 +22,7 @@ func Recovery(logger *slog.Logger) gin.HandlerFunc {
 
 				c.JSON(http.StatusInternalServerError, gin.H{
 					"error": "Internal server error",
-					"code":  "INTERNAL_ERROR",
+					"code":  apierror.InternalError,
 				})
 				c.Abort()
 			}
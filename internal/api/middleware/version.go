@@ -0,0 +1,133 @@
+package middleware
+
+import (
+	"metron/internal/api/apierror"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// The path prefix (/api/v1) is the major API version; the X-Metron-API-Version header picks a
+// revision of its payloads, so they can change without breaking agents that never send it
+const (
+	APIVersionHeader  = "X-Metron-API-Version"
+	DefaultAPIVersion = "1" // Served to requests without the header
+
+	// APIPrefix is where the versioned routes are served
+	APIPrefix = "/api/v1"
+)
+
+// APIVersionKey holds the version negotiated for a request (see RequestAPIVersion)
+const APIVersionKey = "api_version"
+
+// APIVersionInfo is a payload version clients may ask for
+type APIVersionInfo struct {
+	Version    string
+	Deprecated time.Time // Zero unless deprecated; announced in the Deprecation header
+	Sunset     time.Time // Zero, or when requests for the version start failing
+}
+
+// APIVersions are the payload versions the server serves, oldest first
+var APIVersions = []APIVersionInfo{
+	{Version: "1"},
+}
+
+// LegacyRoutesDeprecated is when the unversioned routes (/v1/..., /child/...) were deprecated
+// in favor of the same routes under /api/v1
+var LegacyRoutesDeprecated = time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)
+
+// APIVersion negotiates the payload version of a request from X-Metron-API-Version
+// Requests without the header get DefaultAPIVersion. The negotiated version is echoed in
+// the response header; deprecated versions also get Deprecation and Sunset headers, and
+// versions that are unknown or past their sunset are rejected with 400.
+func APIVersion() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requested := strings.TrimSpace(c.GetHeader(APIVersionHeader))
+		if requested == "" {
+			requested = DefaultAPIVersion
+		}
+
+		info, ok := lookupAPIVersion(requested)
+		if !ok || (!info.Sunset.IsZero() && !time.Now().Before(info.Sunset)) {
+			apierror.Respond(c, apierror.UnsupportedAPIVersion,
+				"API version "+strconv.Quote(requested)+" is not supported (supported: "+supportedAPIVersions()+")")
+			c.Abort()
+			return
+		}
+
+		c.Set(APIVersionKey, info.Version)
+		header := c.Writer.Header()
+		header.Set(APIVersionHeader, info.Version)
+		header.Add("Vary", APIVersionHeader)
+		if !info.Deprecated.IsZero() {
+			setDeprecation(header, info.Deprecated, info.Sunset)
+		}
+		c.Next()
+	}
+}
+
+// RequestAPIVersion returns the payload version negotiated for the request
+func RequestAPIVersion(c *gin.Context) string {
+	if version := c.GetString(APIVersionKey); version != "" {
+		return version
+	}
+	return DefaultAPIVersion
+}
+
+// LegacyRoutes marks the unversioned routes of older clients as deprecated
+// Responses get a Deprecation header, a Sunset header once a sunset date is set, and a Link
+// to the route under /api/v1. From the sunset on, the routes answer 410 Gone.
+func LegacyRoutes(sunset time.Time) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.Writer.Header()
+		setDeprecation(header, LegacyRoutesDeprecated, sunset)
+		header.Add("Link", "<"+successorPath(c.Request.URL.Path)+`>; rel="successor-version"`)
+
+		if !sunset.IsZero() && !time.Now().Before(sunset) {
+			apierror.Respond(c, apierror.RouteSunset, "This route was removed; use "+successorPath(c.Request.URL.Path))
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// setDeprecation sets the Deprecation (RFC 9745) and Sunset (RFC 8594) headers
+func setDeprecation(header http.Header, deprecated, sunset time.Time) {
+	header.Set("Deprecation", "@"+strconv.FormatInt(deprecated.Unix(), 10))
+	if !sunset.IsZero() {
+		header.Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+	}
+}
+
+// successorPath returns the /api/v1 path of an unversioned route
+// (/v1/children -> /api/v1/children, /child/today -> /api/v1/child/today)
+func successorPath(path string) string {
+	if rest, ok := strings.CutPrefix(path, "/v1"); ok {
+		return APIPrefix + rest
+	}
+	return APIPrefix + path
+}
+
+func lookupAPIVersion(version string) (APIVersionInfo, bool) {
+	for _, info := range APIVersions {
+		if info.Version == version {
+			return info, true
+		}
+	}
+	return APIVersionInfo{}, false
+}
+
+func supportedAPIVersions() string {
+	var versions []string
+	now := time.Now()
+	for _, info := range APIVersions {
+		if info.Sunset.IsZero() || now.Before(info.Sunset) {
+			versions = append(versions, info.Version)
+		}
+	}
+	return strings.Join(versions, ", ")
+}
@@ -3,13 +3,17 @@ package api
 import (
 	"log/slog"
 	"metron/config"
+	"metron/internal/api/apierror"
 	"metron/internal/api/handlers"
 	"metron/internal/api/middleware"
+	"metron/internal/bundle"
 	"metron/internal/core"
+	"metron/internal/credentials"
 	"metron/internal/devices"
 	"metron/internal/drivers"
-	"metron/internal/drivers/aqara"
 	"metron/internal/storage"
+	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -17,16 +21,56 @@ import (
 // RouterConfig holds dependencies for the API router
 type RouterConfig struct {
 	Storage             storage.Storage
+	Reader              storage.Reader // Optional: storage for reporting endpoints (read replica or read-only connection); Storage if nil
 	Manager             core.SessionManagerInterface
 	DriverRegistry      *drivers.Registry
 	DeviceRegistry      *devices.Registry
 	Downtime            *core.DowntimeService
-	MovieTime           *core.MovieTimeService   // Optional: for weekend movie time feature
-	DowntimeSkipStorage core.DowntimeSkipStorage // For skip downtime feature
+	MovieTime           *core.MovieTimeService        // Optional: for weekend movie time feature
+	Lockdown            *core.LockdownService         // Optional: for the lockdown (panic button) feature
+	TrackingPause       *core.TrackingPauseService    // Optional: for vacation mode (tracking pause)
+	AgentTokens         *core.AgentTokenService       // Optional: for server-issued agent tokens
+	Heartbeat           *core.HeartbeatService        // Optional: for device last-seen tracking
+	Tamper              *core.TamperService           // Optional: for tamper events reported by agents
+	Trends              *core.TrendsService           // Optional: for usage trend reports
+	LimitSchedule       *core.LimitScheduleService    // Optional: for scheduled limit changes and their history
+	Audit               *core.AuditService            // Optional: for the audit log
+	LimitProfiles       *core.LimitProfileService     // Optional: for age-based limit profiles
+	ChildLogins         *core.ChildLoginService       // Logins to the child web app
+	ChildActivity       *core.ChildActivityService    // Optional: for the child web app's activity feed
+	Push                *core.PushService             // Optional: for Web Push subscriptions
+	DowntimeOverrides   *core.DowntimeOverrideService // Optional: for parent overrides of downtime
+	UsageAlerts         *core.UsageAlertService       // Optional: for alerts on daily time usage
+	DayRollover         *core.DayRolloverService      // Optional: for the days closed out at rollover
+	Allocations         *core.AllocationService       // Optional: for the daily allocation history
+	UsageCorrections    *core.UsageCorrectionService  // Optional: for corrections of historical usage
+	DriverCalls         *core.DriverCallLog           // Optional: for the driver call history
+	Bundles             *bundle.Service               // Optional: for configuration and data export and import
+	FamilyBudget        *core.FamilyBudgetService     // Optional: for the budget shared by all children
+	DeviceUsage         *core.DeviceUsageService      // Optional: for device minutes per device per day
+	SessionQueue        *core.SessionQueue            // Optional: for session starts waiting for their device
+	Media               *core.MediaService            // Optional: for media state reports and extending until media ends
+	MediaServers        handlers.MediaServerWatcher   // Optional: for Plex and Jellyfin playback webhooks
+	AppUsage            *core.AppUsageService         // Optional: for app usage reported by external usage sources
+	UsageSources        []handlers.UsageSource        // Agents allowed to report app usage to /ingest/usage (none = no ingestion)
+	DowntimeSkipStorage core.DowntimeSkipStorage      // For skip downtime feature
 	APIKey              string
+	OverrideKey         string // Optional: second key required for parent overrides (X-Metron-Override-Key)
+	MediaServerToken    string // Token the media server webhook URLs carry (?token=), required with MediaServers
 	Logger              *slog.Logger
-	AqaraTokenStorage   aqara.AqaraTokenStorage  // Optional: only needed if Aqara driver is used
+	Credentials         credentials.Store        // Optional: driver credentials (Aqara token admin endpoints)
 	Devices             []config.DeviceConfig    // All devices (used for agent auth)
+	ReadinessChecks     map[string]handlers.HealthCheck // Checks run by GET /readyz
+	Scheduler           handlers.SchedulerPreviewer     // Optional: for the scheduler preview (debugging) endpoint
+	AgentUpdateDir      string                          // Optional: signed agent release directory served to agents
+	CacheTTL            time.Duration                   // Lifetime of cached read-heavy responses (0 disables caching; ETags are always sent)
+	MaxBodyBytes        int64                           // Request body size limit (middleware.DefaultMaxBodyBytes if 0)
+	CORS                *middleware.CORSConfig          // Optional: allowed browser origins (middleware.DefaultCORSConfig if nil)
+	ChildCookie         handlers.ChildCookieConfig      // Attributes of the child session cookie
+	ChildAppURL         string                          // Optional: public URL of the child web app, for login links
+	Metrics             http.Handler                    // Optional: Prometheus metrics served at GET /metrics
+	Logs                handlers.LogTail                // Optional: recent log lines served at GET /api/v1/admin/logs
+	LegacySunset        time.Time                       // Optional: when the unversioned /v1 and /child routes stop working
 }
 
 // NewRouter creates and configures the Gin router
@@ -36,66 +80,144 @@ func NewRouter(config RouterConfig) *gin.Engine {
 
 	router := gin.New()
 
+	// Reporting endpoints read from the replica or read-only connection when there is one
+	reader := config.Reader
+	if reader == nil {
+		reader = config.Storage
+	}
+
 	// Apply global middleware
 	router.Use(middleware.RequestID())
 	router.Use(middleware.Recovery(config.Logger))
 	router.Use(middleware.NoiseFilter(config.Logger))
 	router.Use(middleware.Logging(config.Logger))
-	router.Use(middleware.ContentType())
+	router.Use(middleware.Compression())
+	router.Use(middleware.ContentType("/integrations/plex")) // Plex posts webhooks as forms
+	router.Use(middleware.BodyLimit(config.MaxBodyBytes))
+	router.Use(middleware.ValidateIDs())
+
+	// Short-lived cache with ETags for endpoints the bot and child web app poll
+	responseCache := middleware.NewResponseCache(config.CacheTTL)
+	router.Use(responseCache.InvalidateOnWrite())
 
 	// Apply child API logging middleware (adds detailed logging for child API routes)
 	childLogger := config.Logger.With("component", "child-api")
 	router.Use(middleware.ChildAPILogging(childLogger))
 
 	// CORS middleware for child web app
-	router.Use(func(c *gin.Context) {
-		origin := c.Request.Header.Get("Origin")
-		if origin != "" {
-			// Allow the requesting origin (supports credentials)
-			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
-		} else {
-			// Fallback for non-browser requests
-			c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
-		}
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PATCH, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-		c.Next()
-	})
+	corsConfig := middleware.DefaultCORSConfig()
+	if config.CORS != nil {
+		corsConfig = *config.CORS
+	}
+	router.Use(middleware.CORS(corsConfig))
 
-	// Health check (no auth)
-	healthHandler := handlers.NewHealthHandler()
+	// Health checks (no auth)
+	healthHandler := handlers.NewHealthHandler(config.ReadinessChecks, config.Logger)
 	router.GET("/health", healthHandler.GetHealth)
+	router.GET("/healthz", healthHandler.GetLiveness)
+	router.GET("/readyz", healthHandler.GetReadiness)
+
+	// Prometheus metrics (no auth, like the health checks; scraped from the local network)
+	if config.Metrics != nil {
+		router.GET("/metrics", gin.WrapH(config.Metrics))
+	}
+
+	// First-run setup page (no auth: it is only served while no children exist,
+	// and the page itself calls the authenticated API)
+	setupHandler := handlers.NewSetupHandler(config.Storage, config.DeviceRegistry, config.DriverRegistry, config.Logger)
+	router.GET("/setup", setupHandler.Page)
+
+	// Media server webhooks (no API key: Plex and Jellyfin cannot send it, the URLs carry a token)
+	if config.MediaServers != nil {
+		mediaServerHandler := handlers.NewMediaServerHandler(config.MediaServers, config.MediaServerToken, config.Logger)
+		router.POST("/integrations/plex", mediaServerHandler.PlexWebhook)
+		router.POST("/integrations/jellyfin", mediaServerHandler.JellyfinWebhook)
+	}
+
+	// App usage ingestion (no API key: each usage source has its own Bearer token)
+	if config.AppUsage != nil && len(config.UsageSources) > 0 {
+		appUsageHandler := handlers.NewAppUsageHandler(config.AppUsage, config.UsageSources, config.Logger)
+		router.POST("/ingest/usage", appUsageHandler.IngestUsage)
+	}
+
+	// Versioned routes live under /api/v1; the unversioned routes older bots, agents and
+	// child web apps call are served too, with Deprecation and Sunset headers
+	api := router.Group(middleware.APIPrefix)
+	api.Use(middleware.APIVersion())
+	legacy := router.Group("")
+	legacy.Use(middleware.APIVersion(), middleware.LegacyRoutes(config.LegacySunset))
 
 	// API v1 routes (with authentication)
-	v1 := router.Group("/v1")
+	v1 := routeGroups{api.Group(""), legacy.Group("/v1")}
 	v1.Use(authMiddleware(config.APIKey))
+	v1.Use(middleware.StrictJSON())
 	{
+		// Setup status (devices and driver checks shown by the setup page)
+		v1.GET("/setup", setupHandler.GetStatus)
+
 		// Children endpoints
 		childrenHandler := handlers.NewChildrenHandler(
 			config.Storage,
 			config.Manager,
 			config.Logger,
 		)
-		v1.GET("/children", childrenHandler.ListChildren)
+		if config.FamilyBudget != nil {
+			childrenHandler.SetFamilyBudget(config.FamilyBudget)
+		}
+		v1.GET("/children", responseCache.Cached(), childrenHandler.ListChildren)
 		v1.POST("/children", childrenHandler.CreateChild)
+		v1.GET("/children/status", childrenHandler.GetChildrenStatus)
 		v1.GET("/children/:id", childrenHandler.GetChild)
+		v1.GET("/children/:id/suggestions", childrenHandler.GetSuggestions)
+		v1.GET("/children/:id/exempt-activities", childrenHandler.GetExemptActivities)
 		v1.PATCH("/children/:id", childrenHandler.UpdateChild)
 		v1.DELETE("/children/:id", childrenHandler.DeleteChild)
 		v1.POST("/children/:id/rewards", childrenHandler.GrantReward)
 		v1.POST("/children/:id/fines", childrenHandler.DeductFine)
 
+		// Limit change endpoints (scheduled limits and their history)
+		if config.LimitSchedule != nil {
+			childrenHandler.SetLimitSchedule(config.LimitSchedule)
+			limitChangesHandler := handlers.NewLimitChangesHandler(
+				config.LimitSchedule,
+				config.Logger,
+			)
+			v1.GET("/children/:id/limit-changes", limitChangesHandler.ListLimitChanges)
+			v1.POST("/children/:id/limit-changes", limitChangesHandler.CreateLimitChange)
+			v1.DELETE("/children/:id/limit-changes/:changeId", limitChangesHandler.CancelLimitChange)
+		}
+
+		// Daily allocation history
+		if config.Allocations != nil {
+			allocationsHandler := handlers.NewAllocationsHandler(config.Allocations, config.Logger)
+			v1.GET("/children/:id/allocations", allocationsHandler.ListAllocations)
+
+			// Read-only GraphQL joining children, sessions, allocations and rewards for dashboards
+			// (POST queries do not clear the response cache)
+			graphQLHandler := handlers.NewGraphQLHandler(reader, config.Manager, config.Allocations, config.Logger)
+			v1.GET("/graphql", graphQLHandler.Query)
+			v1.POST("/graphql", middleware.ReadOnly(), graphQLHandler.Query)
+			v1.GET("/graphql/schema", graphQLHandler.Schema)
+		}
+
 		// Devices endpoints
 		devicesHandler := handlers.NewDevicesHandler(
 			config.DeviceRegistry,
 			config.DriverRegistry,
 			config.Logger,
 		)
-		v1.GET("/devices", devicesHandler.ListDevices)
+		if config.Heartbeat != nil {
+			devicesHandler.SetHeartbeats(config.Heartbeat)
+		}
+		devicesHandler.SetSessions(config.Manager)
+		v1.GET("/devices", responseCache.Cached(), devicesHandler.ListDevices)
+		if config.Media != nil {
+			// Integrations report what devices play, for extensions until the media ends
+			mediaHandler := handlers.NewMediaHandler(config.Media, config.Logger)
+			v1.GET("/devices/:id/media", mediaHandler.GetMedia)
+			v1.POST("/devices/:id/media", mediaHandler.ReportMedia)
+			v1.DELETE("/devices/:id/media", mediaHandler.ClearMedia)
+		}
 
 		// Sessions endpoints
 		sessionsHandler := handlers.NewSessionsHandler(
@@ -103,23 +225,102 @@ func NewRouter(config RouterConfig) *gin.Engine {
 			config.Manager,
 			config.Logger,
 		)
+		sessionsHandler.SetOverrideKey(config.OverrideKey)
+		if config.Media != nil {
+			sessionsHandler.SetMedia(config.Media)
+		}
 		v1.GET("/sessions", sessionsHandler.ListSessions)
 		v1.POST("/sessions", sessionsHandler.CreateSession)
+		v1.GET("/sessions/preflight", sessionsHandler.PreflightSession)
+		if config.SessionQueue != nil {
+			sessionsHandler.SetQueue(config.SessionQueue)
+			v1.GET("/sessions/queue", sessionsHandler.ListQueuedSessions)
+			v1.DELETE("/sessions/queue/:id", sessionsHandler.CancelQueuedSession)
+		}
 		v1.GET("/sessions/:id", sessionsHandler.GetSession)
 		v1.PATCH("/sessions/:id", sessionsHandler.UpdateSession)
 
 		// Stats endpoints
 		statsHandler := handlers.NewStatsHandler(
-			config.Storage,
+			reader,
 			config.Manager,
 			config.Logger,
 		)
 		v1.GET("/stats/today", statsHandler.GetTodayStats)
 
-		// Admin endpoints (only register if Aqara token storage is provided)
-		if config.AqaraTokenStorage != nil {
+		// Report endpoints
+		if config.Trends != nil {
+			reportsHandler := handlers.NewReportsHandler(
+				reader,
+				config.Trends,
+				config.Logger,
+			)
+			v1.GET("/reports/trends", reportsHandler.GetTrends)
+		}
+		if config.DeviceUsage != nil {
+			deviceUsageHandler := handlers.NewDeviceUsageHandler(config.DeviceUsage, config.Logger)
+			v1.GET("/reports/devices", deviceUsageHandler.GetDeviceUsage)
+		}
+		if config.AppUsage != nil {
+			appUsageHandler := handlers.NewAppUsageHandler(config.AppUsage, config.UsageSources, config.Logger)
+			v1.GET("/reports/apps", appUsageHandler.GetAppUsage)
+		}
+		if config.FamilyBudget != nil {
+			familyBudgetHandler := handlers.NewFamilyBudgetHandler(config.FamilyBudget, config.Logger)
+			v1.GET("/reports/family-budget", familyBudgetHandler.GetFamilyBudget)
+		}
+
+		// Limit profile endpoints (age-based limits proposed on birthdays)
+		if config.LimitProfiles != nil {
+			limitProfilesHandler := handlers.NewLimitProfilesHandler(
+				config.LimitProfiles,
+				config.Logger,
+			)
+			v1.GET("/limit-profiles", limitProfilesHandler.ListProfiles)
+			v1.GET("/profile-transitions", limitProfilesHandler.ListTransitions)
+			v1.POST("/profile-transitions/:id/confirm", limitProfilesHandler.ConfirmTransition)
+			v1.POST("/profile-transitions/:id/dismiss", limitProfilesHandler.DismissTransition)
+		}
+
+		// Audit log endpoints
+		if config.Audit != nil {
+			auditHandler := handlers.NewAuditHandler(
+				config.Audit,
+				config.Logger,
+			)
+			v1.GET("/audit-log", auditHandler.ListAuditLog)
+		}
+
+		// Lockdown endpoints (panic button)
+		if config.Lockdown != nil {
+			lockdownHandler := handlers.NewLockdownHandler(
+				config.Lockdown,
+				config.Logger,
+			)
+			v1.GET("/lockdown", lockdownHandler.GetLockdown)
+			v1.POST("/lockdown", lockdownHandler.ActivateLockdown)
+			v1.DELETE("/lockdown", lockdownHandler.LiftLockdown)
+			v1.GET("/lockdown/history", lockdownHandler.ListLockdownHistory)
+		}
+
+		// Tracking pause endpoints (vacation mode)
+		if config.TrackingPause != nil {
+			trackingPauseHandler := handlers.NewTrackingPauseHandler(
+				config.TrackingPause,
+				config.Logger,
+			)
+			v1.GET("/tracking-pause", trackingPauseHandler.GetTrackingPause)
+			v1.POST("/tracking-pause", trackingPauseHandler.PauseTracking)
+			v1.DELETE("/tracking-pause", trackingPauseHandler.ResumeTracking)
+		}
+
+		// Error code catalog (machine-readable list of codes and HTTP statuses)
+		v1.GET("/errors", apierror.CatalogHandler)
+
+		// Admin endpoints (only register if a credential store is provided)
+		if config.Credentials != nil {
 			adminHandler := handlers.NewAdminHandler(
-				config.AqaraTokenStorage,
+				config.Credentials,
 				config.Logger,
 			)
 			v1.POST("/admin/aqara/refresh-token", adminHandler.UpdateAqaraRefreshToken)
@@ -137,6 +338,64 @@ func NewRouter(config RouterConfig) *gin.Engine {
 			v1.GET("/downtime/skip-status", downtimeHandler.GetSkipStatus)
 		}
 
+		// Downtime override endpoints (downtime lifted for a session or the rest of the day)
+		if config.DowntimeOverrides != nil {
+			downtimeOverridesHandler := handlers.NewDowntimeOverridesHandler(
+				config.DowntimeOverrides,
+				config.Logger,
+			)
+			v1.GET("/downtime/overrides", downtimeOverridesHandler.ListDowntimeOverrides)
+			v1.POST("/downtime/overrides", downtimeOverridesHandler.GrantDowntimeOverride)
+			v1.DELETE("/downtime/overrides/:id", downtimeOverridesHandler.CancelDowntimeOverride)
+		}
+
+		// Scheduler preview endpoint (planned warnings, breaks and stops per active session)
+		if config.Scheduler != nil {
+			schedulerHandler := handlers.NewSchedulerHandler(
+				config.Scheduler,
+				config.Logger,
+			)
+			v1.GET("/admin/scheduler/preview", schedulerHandler.GetPreview)
+		}
+
+		// Driver call history (whether a device call was made, and how it went)
+		if config.DriverCalls != nil {
+			driverCallsHandler := handlers.NewDriverCallsHandler(config.DriverCalls, config.Logger)
+			v1.GET("/admin/driver-calls", driverCallsHandler.ListDriverCalls)
+		}
+
+		// Corrections of usage charged to past days (audited)
+		if config.UsageCorrections != nil {
+			usageCorrectionsHandler := handlers.NewUsageCorrectionsHandler(config.UsageCorrections, config.Logger)
+			v1.PATCH("/admin/usage", usageCorrectionsHandler.CorrectUsage)
+		}
+
+		// Recent log lines (debugging without a shell on the host), rate-limited as responses are large
+		if config.Logs != nil {
+			logsHandler := handlers.NewLogsHandler(config.Logs, config.Logger)
+			v1.GET("/admin/logs", middleware.RateLimit(10, time.Minute), logsHandler.GetLogs)
+		}
+
+		// Configuration and data export and import (migrating between hosts)
+		if config.Bundles != nil {
+			bundleHandler := handlers.NewBundleHandler(config.Bundles, config.Logger)
+			v1.GET("/admin/export", bundleHandler.Export)
+			v1.POST("/admin/import", bundleHandler.Import)
+		}
+
+		// Agent token endpoints (issue, rotate and revoke per-device agent tokens)
+		if config.AgentTokens != nil {
+			agentTokensHandler := handlers.NewAgentTokensHandler(
+				config.AgentTokens,
+				config.DeviceRegistry,
+				config.Logger,
+			)
+			v1.GET("/admin/agents", agentTokensHandler.ListAgentTokens)
+			v1.POST("/admin/agents", agentTokensHandler.IssueAgentToken)
+			v1.POST("/admin/agents/:id/rotate", agentTokensHandler.RotateAgentToken)
+			v1.DELETE("/admin/agents/:id", agentTokensHandler.RevokeAgentToken)
+		}
+
 		// Movie time bypass endpoints (for holiday/vacation periods)
 		if config.MovieTime != nil {
 			bypassHandler := handlers.NewMovieTimeBypassHandler(
@@ -151,34 +410,64 @@ func NewRouter(config RouterConfig) *gin.Engine {
 	}
 
 	// Child API routes (for child-facing web app)
-	sessionManager := middleware.NewSessionManager()
-
-	childGroup := router.Group("/child")
+	childGroup := routeGroups{api.Group("/child"), legacy.Group("/child")}
 	{
 		childHandler := handlers.NewChildHandler(
 			config.Storage,
 			config.Manager,
 			config.DeviceRegistry,
-			sessionManager,
+			config.ChildLogins,
 			config.Downtime,
 			config.MovieTime,
 			config.Logger,
 		)
+		childHandler.SetCookieConfig(config.ChildCookie)
+		childHandler.SetAppURL(config.ChildAppURL)
+		if config.Media != nil {
+			childHandler.SetMedia(config.Media)
+		}
 
 		// Public routes (no auth required)
 		authGroup := childGroup.Group("/auth")
 		authGroup.GET("/children", childHandler.ListChildrenForAuth)
 		authGroup.POST("/login", childHandler.Login)
 		authGroup.POST("/logout", childHandler.Logout)
+		authGroup.POST("/link", childHandler.RedeemLoginLink)
+
+		// Parents create login links for a child's tablet instead of the PIN (parent API key required)
+		authGroup.GET("/qr", authMiddleware(config.APIKey), childHandler.CreateLoginLink)
+
+		// Parents see and end children's logins (parent API key required)
+		childLoginsHandler := handlers.NewChildLoginsHandler(config.ChildLogins, config.Logger)
+		parentAuth := authGroup.Group("/sessions")
+		parentAuth.Use(authMiddleware(config.APIKey))
+		parentAuth.Use(middleware.StrictJSON())
+		parentAuth.GET("", childLoginsHandler.ListChildLogins)
+		parentAuth.DELETE("/:id", childLoginsHandler.RevokeChildLogin)
 
 		// Protected routes (require child session)
 		protected := childGroup.Group("")
-		protected.Use(middleware.ChildAuth(sessionManager))
+		protected.Use(middleware.ChildAuth(config.ChildLogins))
 		protected.GET("/me", childHandler.GetMe)
-		protected.GET("/today", childHandler.GetToday)
+		protected.GET("/today", responseCache.Cached(), childHandler.GetToday)
+		protected.GET("/suggestions", childHandler.GetSuggestions)
+		protected.GET("/exempt-activities", childHandler.GetExemptActivities)
+		protected.GET("/downtime", childHandler.GetDowntime)
+		if config.ChildActivity != nil {
+			childHandler.SetActivity(config.ChildActivity)
+			protected.GET("/activity", childHandler.GetActivity)
+		}
+		if config.Push != nil {
+			pushHandler := handlers.NewPushHandler(config.Push, config.Logger)
+			protected.GET("/push/key", pushHandler.GetPublicKey)
+			protected.GET("/push/subscriptions", pushHandler.ListChildSubscriptions)
+			protected.POST("/push/subscriptions", pushHandler.SubscribeChild)
+			protected.DELETE("/push/subscriptions/:id", pushHandler.UnsubscribeChild)
+		}
 		protected.GET("/devices", childHandler.ListDevices)
 		protected.GET("/sessions", childHandler.ListSessions)
 		protected.POST("/sessions", childHandler.CreateSession)
+		protected.GET("/sessions/preflight", childHandler.PreflightSession)
 		protected.POST("/sessions/:id/stop", childHandler.StopSession)
 		protected.POST("/sessions/:id/extend", childHandler.ExtendSession)
 
@@ -188,18 +477,68 @@ func NewRouter(config RouterConfig) *gin.Engine {
 	}
 
 	// Agent API routes (for external device agents like Windows agent)
-	// Only register if any devices have agent tokens configured
-	if middleware.HasAgentDevices(config.Devices) {
+	// Only register if any devices have agent tokens configured or tokens can be issued
+	if middleware.HasAgentDevices(config.Devices) || config.AgentTokens != nil {
 		agentHandler := handlers.NewAgentHandler(
 			config.Storage,
 			config.Manager,
 			config.Logger,
 		)
+		if config.Heartbeat != nil {
+			agentHandler.SetHeartbeat(config.Heartbeat)
+		}
+		if config.Tamper != nil {
+			agentHandler.SetTamper(config.Tamper)
+		}
+
+		// Avoid a non-nil interface holding a nil service
+		var issuedTokens middleware.AgentTokenAuthenticator
+		if config.AgentTokens != nil {
+			issuedTokens = config.AgentTokens
+		}
 
-		agentGroup := router.Group("/v1/agent")
-		agentGroup.Use(middleware.AgentAuth(config.Devices))
+		agentGroup := routeGroups{api.Group("/agent"), legacy.Group("/v1/agent")}
+		agentGroup.Use(middleware.AgentAuth(config.Devices, issuedTokens))
 		{
 			agentGroup.GET("/session", agentHandler.GetDeviceSession)
+			agentGroup.POST("/activity", agentHandler.ReportActivity)
+			if config.Tamper != nil {
+				agentGroup.POST("/events", agentHandler.ReportEvents)
+			}
+
+			// Agent self-update channel
+			if config.AgentUpdateDir != "" {
+				agentUpdateHandler := handlers.NewAgentUpdateHandler(config.AgentUpdateDir, config.Logger)
+				agentGroup.GET("/update", agentUpdateHandler.CheckUpdate)
+				agentGroup.GET("/update/download", agentUpdateHandler.DownloadUpdate)
+			}
+		}
+
+		// Tamper events reported by agents, for parents (admin auth)
+		if config.Tamper != nil {
+			tamperHandler := handlers.NewTamperHandler(config.Tamper, config.Logger)
+			v1.GET("/tamper-events", tamperHandler.ListTamperEvents)
+		}
+
+		// Usage alerts (children reaching a share of their daily time), polled by the bot
+		if config.UsageAlerts != nil {
+			usageAlertsHandler := handlers.NewUsageAlertsHandler(config.UsageAlerts, config.Logger)
+			v1.GET("/usage-alerts", usageAlertsHandler.ListUsageAlerts)
+		}
+
+		// Web Push subscriptions of parents' browsers (and children's, which parents may delete)
+		if config.Push != nil {
+			pushHandler := handlers.NewPushHandler(config.Push, config.Logger)
+			v1.GET("/push/key", pushHandler.GetPublicKey)
+			v1.GET("/push/subscriptions", pushHandler.ListSubscriptions)
+			v1.POST("/push/subscriptions", pushHandler.Subscribe)
+			v1.DELETE("/push/subscriptions/:id", pushHandler.Unsubscribe)
+		}
+
+		// Days closed out at rollover ("new day" events)
+		if config.DayRollover != nil {
+			dayRolloversHandler := handlers.NewDayRolloversHandler(config.DayRollover, config.Logger)
+			v1.GET("/day-rollovers", dayRolloversHandler.ListDayRollovers)
 		}
 
 		// Device bypass endpoints (admin auth, not agent auth)
@@ -211,6 +550,49 @@ func NewRouter(config RouterConfig) *gin.Engine {
 	return router
 }
 
+// routeGroups registers routes on several groups at once, so each route is served under
+// /api/v1 and at its legacy path with the same handlers
+type routeGroups []*gin.RouterGroup
+
+// Group creates a subgroup in each group
+func (g routeGroups) Group(path string, handlers ...gin.HandlerFunc) routeGroups {
+	groups := make(routeGroups, len(g))
+	for i, group := range g {
+		groups[i] = group.Group(path, handlers...)
+	}
+	return groups
+}
+
+// Use adds middleware to each group
+func (g routeGroups) Use(middleware ...gin.HandlerFunc) {
+	for _, group := range g {
+		group.Use(middleware...)
+	}
+}
+
+// Handle registers a route in each group
+func (g routeGroups) Handle(method, path string, handlers ...gin.HandlerFunc) {
+	for _, group := range g {
+		group.Handle(method, path, handlers...)
+	}
+}
+
+func (g routeGroups) GET(path string, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodGet, path, handlers...)
+}
+
+func (g routeGroups) POST(path string, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodPost, path, handlers...)
+}
+
+func (g routeGroups) PATCH(path string, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodPatch, path, handlers...)
+}
+
+func (g routeGroups) DELETE(path string, handlers ...gin.HandlerFunc) {
+	g.Handle(http.MethodDelete, path, handlers...)
+}
+
 // authMiddleware verifies API key authentication
 func authMiddleware(apiKey string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -218,7 +600,7 @@ func authMiddleware(apiKey string) gin.HandlerFunc {
 		if providedKey != apiKey {
 			c.JSON(401, gin.H{
 				"error": "Unauthorized",
-				"code":  "UNAUTHORIZED",
+				"code":  apierror.Unauthorized,
 			})
 			c.Abort()
 			return
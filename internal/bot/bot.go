@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log/slog"
 	"metron/config"
+	"metron/pkg/client"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -12,8 +14,9 @@ import (
 // Bot represents the Telegram bot
 type Bot struct {
 	api    *tgbotapi.BotAPI
-	client *MetronAPI
+	client *client.Client
 	config *config.BotConfig
+	stops  *stopUndos // Sessions stopped from the bot that can still be restarted
 	logger *slog.Logger
 }
 
@@ -26,16 +29,25 @@ func NewBot(cfg *config.BotConfig, logger *slog.Logger) (*Bot, error) {
 	}
 
 	// Create Metron API client
-	metronClient := NewMetronAPI(
-		cfg.Metron.BaseURL,
-		cfg.Metron.APIKey,
-		logger,
-	)
+	metronClient := client.New(cfg.Metron.BaseURL, cfg.Metron.APIKey)
+	metronClient.SetLogger(logger)
+	metronClient.SetCacheTTL(DefaultCacheTTL)
+	metronClient.SetOverrideKey(cfg.Metron.OverrideKey)
+	if cfg.Metron.TimeoutSeconds > 0 {
+		metronClient.SetTimeout(time.Duration(cfg.Metron.TimeoutSeconds) * time.Second)
+	}
+	if cfg.Metron.Retries != nil {
+		metronClient.SetRetries(*cfg.Metron.Retries)
+	}
+	if cfg.Metron.CacheSeconds != nil {
+		metronClient.SetCacheTTL(time.Duration(*cfg.Metron.CacheSeconds) * time.Second)
+	}
 
 	bot := &Bot{
 		api:    api,
 		client: metronClient,
 		config: cfg,
+		stops:  newStopUndos(),
 		logger: logger,
 	}
 
@@ -44,12 +56,14 @@ func NewBot(cfg *config.BotConfig, logger *slog.Logger) (*Bot, error) {
 
 // SetWebhook configures the webhook for the bot
 func (b *Bot) SetWebhook() error {
-	webhookConfig, _ := tgbotapi.NewWebhook(b.config.Telegram.WebhookURL)
-
-	// Note: SecretToken is not available in all versions of the telegram bot API
-	// It will be validated in the webhook handler instead
-
-	_, err := b.api.Request(webhookConfig)
+	// The Telegram library cannot register a secret token, so the request is made directly.
+	// Re-registering with a new webhook_secret rotates it; updates already on the way with the
+	// old secret are accepted through previous_webhook_secrets.
+	params := tgbotapi.Params{}
+	params.AddNonEmpty("url", b.config.Telegram.WebhookURL)
+	params.AddNonEmpty("secret_token", b.config.Telegram.WebhookSecret)
+
+	_, err := b.api.MakeRequest("setWebhook", params)
 	if err != nil {
 		return fmt.Errorf("failed to set webhook: %w", err)
 	}
@@ -67,6 +81,27 @@ func (b *Bot) SetWebhook() error {
 	return nil
 }
 
+// CheckWebhook verifies that Telegram has our webhook URL registered
+func (b *Bot) CheckWebhook() error {
+	info, err := b.api.GetWebhookInfo()
+	if err != nil {
+		return fmt.Errorf("failed to get webhook info: %w", err)
+	}
+
+	if info.URL != b.config.Telegram.WebhookURL {
+		return fmt.Errorf("webhook not registered (telegram has %q)", info.URL)
+	}
+
+	if info.LastErrorMessage != "" {
+		b.logger.Debug("Webhook reports last delivery error",
+			"error", info.LastErrorMessage,
+			"pending_updates", info.PendingUpdateCount,
+		)
+	}
+
+	return nil
+}
+
 // HandleUpdate processes a Telegram update
 func (b *Bot) HandleUpdate(update tgbotapi.Update) error {
 	ctx := context.Background()
@@ -114,11 +149,17 @@ func (b *Bot) handleMessage(ctx context.Context, message *tgbotapi.Message) erro
 		return nil
 	}
 
+	if !b.checkRole(message.Chat.ID, message.From, requiredRole(commandRoles, message.Command())) {
+		return b.sendMessage(message.Chat.ID, FormatPermissionDenied(b.config.UserRole(message.From.ID)), nil)
+	}
+
 	switch message.Command() {
 	case "start":
 		return b.handleStart(ctx, message)
 	case "today":
 		return b.handleToday(ctx, message)
+	case "weekly":
+		return b.handleWeekly(ctx, message)
 	case "newsession":
 		return b.handleNewSession(ctx, message)
 	case "extend":
@@ -129,12 +170,22 @@ func (b *Bot) handleMessage(ctx context.Context, message *tgbotapi.Message) erro
 		return b.handleReward(ctx, message)
 	case "fine":
 		return b.handleFine(ctx, message)
+	case "limits":
+		return b.handleLimits(ctx, message)
 	case "children":
 		return b.handleChildren(ctx, message)
 	case "devices":
 		return b.handleDevices(ctx, message)
+	case "dashboard":
+		return b.handleDashboard(ctx, message)
 	case "bypass":
 		return b.handleBypass(ctx, message)
+	case "lockdown":
+		return b.handleLockdown(ctx, message)
+	case "unlock":
+		return b.handleUnlock(ctx, message)
+	case "vacation":
+		return b.handleVacation(ctx, message)
 	default:
 		return b.sendMessage(message.Chat.ID,
 			"Unknown command. Use /start to see available commands.", nil)
@@ -187,6 +238,15 @@ func (b *Bot) handleCallback(ctx context.Context, callback *tgbotapi.CallbackQue
 		"duration", data.Duration,
 	)
 
+	if !b.checkRole(callback.Message.Chat.ID, callback.From, requiredRole(callbackRoles, data.Action)) {
+		return b.sendMessage(callback.Message.Chat.ID, FormatPermissionDenied(b.config.UserRole(callback.From.ID)), nil)
+	}
+
+	// A stop can only be undone from the stop screen; other buttons move the message on
+	if data.Action != "undo_stop" {
+		b.stops.drop(callback.Message.Chat.ID, callback.Message.MessageID)
+	}
+
 	// Route to flow handler
 	switch data.Action {
 	case "cancel":
@@ -195,15 +255,19 @@ func (b *Bot) handleCallback(ctx context.Context, callback *tgbotapi.CallbackQue
 		// No-op action for non-clickable buttons (just answer the callback)
 		return nil
 	case "newsession":
-		return b.handleNewSessionFlow(ctx, callback.Message, data)
+		return b.handleNewSessionFlow(ctx, callback.Message, callback.From, data)
 	case "extend":
 		return b.handleExtendFlow(ctx, callback.Message, data)
 	case "stop":
 		return b.handleStopFlow(ctx, callback.Message, data)
+	case "warning":
+		return b.handleWarningFlow(ctx, callback.Message, data)
 	case "reward":
 		return b.handleRewardFlow(ctx, callback.Message, data)
 	case "fine":
 		return b.handleFineFlow(ctx, callback.Message, data)
+	case "limits":
+		return b.handleLimitsFlow(ctx, callback.Message, callback.From, data)
 	case "manage":
 		return b.handleManageFlow(ctx, callback.Message, data)
 	case "downtime":
@@ -217,7 +281,13 @@ func (b *Bot) handleCallback(ctx context.Context, callback *tgbotapi.CallbackQue
 	case "skip_downtime":
 		return b.handleSkipDowntime(ctx, callback.Message)
 	case "stop_all":
-		return b.handleStopAll(ctx, callback.Message)
+		return b.handleStopAll(ctx, callback.Message, data)
+	case "undo_stop":
+		return b.handleUndoStop(ctx, callback.Message, callback.From)
+	case "lockdown":
+		return b.handleLockdownFlow(ctx, callback.Message, callback.From, data)
+	case "profile":
+		return b.handleProfileFlow(ctx, callback.Message, callback.From, data)
 	case "main_menu":
 		return b.handleMainMenu(ctx, callback.Message)
 	default:
@@ -226,6 +296,22 @@ func (b *Bot) handleCallback(ctx context.Context, callback *tgbotapi.CallbackQue
 	}
 }
 
+// checkRole reports whether the user's role allows an action needing required, logging denials
+func (b *Bot) checkRole(chatID int64, user *tgbotapi.User, required string) bool {
+	role := b.config.UserRole(user.ID)
+	if config.BotRoleAllows(role, required) {
+		return true
+	}
+
+	b.logger.Warn("Action denied by role",
+		"user_id", user.ID,
+		"chat_id", chatID,
+		"role", role,
+		"required_role", required,
+	)
+	return false
+}
+
 // sendMessage sends a text message
 func (b *Bot) sendMessage(chatID int64, text string, keyboard interface{}) error {
 	msg := tgbotapi.NewMessage(chatID, text)
@@ -249,6 +335,41 @@ func (b *Bot) sendMessage(chatID int64, text string, keyboard interface{}) error
 	return nil
 }
 
+// sendWebAppButton sends a message with a button opening a Telegram Web App
+// The Telegram library predates web app buttons, so the request is made directly.
+func (b *Bot) sendWebAppButton(chatID int64, text, buttonText, url string) error {
+	type webAppInfo struct {
+		URL string `json:"url"`
+	}
+	type webAppButton struct {
+		Text   string     `json:"text"`
+		WebApp webAppInfo `json:"web_app"`
+	}
+	markup := struct {
+		InlineKeyboard [][]webAppButton `json:"inline_keyboard"`
+	}{
+		InlineKeyboard: [][]webAppButton{{{Text: buttonText, WebApp: webAppInfo{URL: url}}}},
+	}
+
+	params := tgbotapi.Params{}
+	params.AddNonZero64("chat_id", chatID)
+	params.AddNonEmpty("text", text)
+	params.AddNonEmpty("parse_mode", "Markdown")
+	if err := params.AddInterface("reply_markup", markup); err != nil {
+		return fmt.Errorf("failed to encode web app button: %w", err)
+	}
+
+	if _, err := b.api.MakeRequest("sendMessage", params); err != nil {
+		b.logger.Error("Failed to send web app button",
+			"chat_id", chatID,
+			"error", err,
+		)
+		return fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return nil
+}
+
 // editMessage edits an existing message
 func (b *Bot) editMessage(chatID int64, messageID int, text string, keyboard interface{}) error {
 	msg := tgbotapi.NewEditMessageText(chatID, messageID, text)
@@ -10,7 +10,7 @@ import (
 // CallbackData represents the data embedded in callback buttons
 type CallbackData struct {
 	Action       string `json:"a"`             // Action type (newsession, manage, etc)
-	SubAction    string `json:"sa,omitempty"`  // Sub-action (extend, stop, add_kid)
+	SubAction    string `json:"sa,omitempty"`  // Sub-action (extend, stop, add_kid, remove_kid)
 	Step         int    `json:"s,omitempty"`   // Current step in flow
 	ChildID      string `json:"c,omitempty"`   // Child ID (resolved from index)
 	ChildIndex   int    `json:"ci,omitempty"`  // Child index in list (for compact callback)
@@ -119,35 +119,70 @@ func BuildDevicesButtons(devices []Device, action string, step int, childIndex i
 	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
 
-// BuildDurationButtons creates buttons for selecting duration
-func BuildDurationButtons(action string, step int, childIndex int, device string) tgbotapi.InlineKeyboardMarkup {
-	durations := []int{5, 15, 30, 60, 120}
+// durationPresets are the standard session lengths offered by the bot
+var durationPresets = []int{5, 15, 30, 60, 120}
+
+// feasibleDurations returns presets shorter than maxMinutes
+// maxMinutes <= 0 means the limit is unknown, so all presets are returned
+func feasibleDurations(presets []int, maxMinutes int) []int {
+	if maxMinutes <= 0 {
+		return presets
+	}
+
+	var durations []int
+	for _, duration := range presets {
+		if duration < maxMinutes {
+			durations = append(durations, duration)
+		}
+	}
+	return durations
+}
+
+// buildDurationRows lays out duration buttons three per row
+// When maxMinutes is known, a "Max (Nmin)" button is appended on its own row
+func buildDurationRows(durations []int, maxMinutes int, callback func(duration int) string) [][]tgbotapi.InlineKeyboardButton {
 	var rows [][]tgbotapi.InlineKeyboardButton
+	row := []tgbotapi.InlineKeyboardButton{}
 
-	// Create two rows: [5, 15, 30] and [60, 120]
-	row1 := []tgbotapi.InlineKeyboardButton{}
-	row2 := []tgbotapi.InlineKeyboardButton{}
+	for _, duration := range durations {
+		label := fmt.Sprintf("+%d", duration)
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(label, callback(duration)))
 
-	for i, duration := range durations {
-		callback := MarshalCallback(CallbackData{
+		if len(row) == 3 {
+			rows = append(rows, row)
+			row = []tgbotapi.InlineKeyboardButton{}
+		}
+	}
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+
+	if maxMinutes > 0 {
+		maxBtn := tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("⏱ Max (%dmin)", maxMinutes),
+			callback(maxMinutes),
+		)
+		rows = append(rows, []tgbotapi.InlineKeyboardButton{maxBtn})
+	}
+
+	return rows
+}
+
+// BuildDurationButtons creates buttons for selecting duration
+// Only durations that fit in maxMinutes are offered, plus a "Max" button
+// maxMinutes <= 0 means the remaining time is unknown and all presets are shown
+func BuildDurationButtons(action string, step int, childIndex int, device string, maxMinutes int) tgbotapi.InlineKeyboardMarkup {
+	durations := feasibleDurations(durationPresets, maxMinutes)
+
+	rows := buildDurationRows(durations, maxMinutes, func(duration int) string {
+		return MarshalCallback(CallbackData{
 			Action:     action,
 			Step:       step,
 			ChildIndex: childIndex, // Use index to keep callback data small
 			Device:     device,
 			Duration:   duration,
 		})
-
-		label := fmt.Sprintf("+%d", duration)
-		btn := tgbotapi.NewInlineKeyboardButtonData(label, callback)
-
-		if i < 3 {
-			row1 = append(row1, btn)
-		} else {
-			row2 = append(row2, btn)
-		}
-	}
-
-	rows = append(rows, row1, row2)
+	})
 
 	// Back and Cancel buttons
 	backBtn := tgbotapi.NewInlineKeyboardButtonData(
@@ -195,7 +230,7 @@ func BuildSessionsButtons(sessions []Session, action string) tgbotapi.InlineKeyb
 }
 
 // BuildSessionManagementButtons creates buttons for managing active sessions
-// Each session gets 3 action buttons: Extend, Stop, Add Kid
+// Each session gets action buttons: Extend, Stop, Add Kid (and Remove Kid when shared)
 func BuildSessionManagementButtons(sessions []Session, childrenMap map[string]Child) tgbotapi.InlineKeyboardMarkup {
 	var rows [][]tgbotapi.InlineKeyboardButton
 
@@ -219,7 +254,7 @@ func BuildSessionManagementButtons(sessions []Session, childrenMap map[string]Ch
 			sessionLabel += fmt.Sprintf(" · %d min", remaining)
 		}
 
-		// Action buttons row: [Extend] [Stop] [Add Kid]
+		// Action buttons row: [Extend] [Stop] [Add Kid] [Remove Kid]
 		extendBtn := tgbotapi.NewInlineKeyboardButtonData(
 			"⏱ Extend",
 			MarshalCallback(CallbackData{
@@ -257,8 +292,24 @@ func BuildSessionManagementButtons(sessions []Session, childrenMap map[string]Ch
 		)
 		rows = append(rows, []tgbotapi.InlineKeyboardButton{labelBtn})
 
+		actionRow := []tgbotapi.InlineKeyboardButton{extendBtn, stopBtn, addKidBtn}
+
+		// A child can only leave a shared session
+		if len(session.ChildIDs) > 1 {
+			removeKidBtn := tgbotapi.NewInlineKeyboardButtonData(
+				"👋 Remove Kid",
+				MarshalCallback(CallbackData{
+					Action:       "manage",
+					SubAction:    "remove_kid",
+					Step:         1,
+					SessionIndex: i,
+				}),
+			)
+			actionRow = append(actionRow, removeKidBtn)
+		}
+
 		// Add action buttons
-		rows = append(rows, []tgbotapi.InlineKeyboardButton{extendBtn, stopBtn, addKidBtn})
+		rows = append(rows, actionRow)
 	}
 
 	// Grant Reward button
@@ -302,12 +353,12 @@ func BuildAddKidButtons(sessionIndex int, availableChildren []Child, alreadyShar
 
 	// "Mark as Shared" button if not already shared
 	if !alreadyShared && len(availableChildren) > 0 {
+		// Asks for confirmation first (see BuildShareConfirmButtons)
 		callback := MarshalCallback(CallbackData{
 			Action:       "manage",
-			SubAction:    "add_kid",
-			Step:         2,
+			SubAction:    "share",
+			Step:         1,
 			SessionIndex: sessionIndex,
-			ChildIndex:   -1, // Special marker for "all available children"
 		})
 
 		btn := tgbotapi.NewInlineKeyboardButtonData("👨‍👩‍👧 Mark as Shared (All)", callback)
@@ -330,35 +381,146 @@ func BuildAddKidButtons(sessionIndex int, availableChildren []Child, alreadyShar
 	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
 
-// BuildExtendDurationButtons creates buttons for selecting extension duration
-func BuildExtendDurationButtons(sessionIndex int) tgbotapi.InlineKeyboardMarkup {
-	durations := []int{5, 15, 30, 60, 120}
-	var rows [][]tgbotapi.InlineKeyboardButton
+// BuildShareConfirmButtons asks to confirm sharing a session with all other children
+func BuildShareConfirmButtons(sessionIndex int) tgbotapi.InlineKeyboardMarkup {
+	confirmBtn := tgbotapi.NewInlineKeyboardButtonData(
+		"✅ Yes, share with all",
+		MarshalCallback(CallbackData{
+			Action:       "manage",
+			SubAction:    "share",
+			Step:         2,
+			SessionIndex: sessionIndex,
+		}),
+	)
+
+	backBtn := tgbotapi.NewInlineKeyboardButtonData(
+		"◀️ Back",
+		MarshalCallback(CallbackData{
+			Action:       "manage",
+			SubAction:    "add_kid",
+			Step:         1,
+			SessionIndex: sessionIndex,
+		}),
+	)
+
+	cancelBtn := tgbotapi.NewInlineKeyboardButtonData(
+		"❌ Cancel",
+		MarshalCallback(CallbackData{Action: "cancel"}),
+	)
+
+	return tgbotapi.NewInlineKeyboardMarkup(
+		[]tgbotapi.InlineKeyboardButton{confirmBtn},
+		[]tgbotapi.InlineKeyboardButton{backBtn, cancelBtn},
+	)
+}
+
+// BuildStopConfirmButtons asks to confirm stopping a session
+// The device is checked again when confirmed, in case the session list changed in between
+func BuildStopConfirmButtons(sessionIndex int, deviceID string) tgbotapi.InlineKeyboardMarkup {
+	confirmBtn := tgbotapi.NewInlineKeyboardButtonData(
+		"🛑 Yes, stop",
+		MarshalCallback(CallbackData{
+			Action:       "stop",
+			Step:         2,
+			SessionIndex: sessionIndex,
+			Device:       deviceID,
+		}),
+	)
 
-	// Create two rows
-	row1 := []tgbotapi.InlineKeyboardButton{}
-	row2 := []tgbotapi.InlineKeyboardButton{}
+	backBtn := tgbotapi.NewInlineKeyboardButtonData(
+		"◀️ Back",
+		MarshalCallback(CallbackData{Action: "manage", Step: 0}),
+	)
 
-	for i, duration := range durations {
+	cancelBtn := tgbotapi.NewInlineKeyboardButtonData(
+		"❌ Cancel",
+		MarshalCallback(CallbackData{Action: "cancel"}),
+	)
+
+	return tgbotapi.NewInlineKeyboardMarkup(
+		[]tgbotapi.InlineKeyboardButton{confirmBtn},
+		[]tgbotapi.InlineKeyboardButton{backBtn, cancelBtn},
+	)
+}
+
+// BuildStopAllConfirmButtons asks to confirm stopping all active sessions
+func BuildStopAllConfirmButtons() tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🛑 Yes, stop all",
+				MarshalCallback(CallbackData{Action: "stop_all", Step: 1})),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("◀️ Back",
+				MarshalCallback(CallbackData{Action: "sessions_menu"})),
+			tgbotapi.NewInlineKeyboardButtonData("❌ Cancel",
+				MarshalCallback(CallbackData{Action: "cancel"})),
+		),
+	)
+}
+
+// BuildStopUndoButtons creates the quick actions with an Undo button for a stopped session
+func BuildStopUndoButtons() tgbotapi.InlineKeyboardMarkup {
+	keyboard := BuildQuickActionsButtons()
+	undoRow := tgbotapi.NewInlineKeyboardRow(
+		tgbotapi.NewInlineKeyboardButtonData("↩️ Undo",
+			MarshalCallback(CallbackData{Action: "undo_stop"})),
+	)
+	keyboard.InlineKeyboard = append([][]tgbotapi.InlineKeyboardButton{undoRow}, keyboard.InlineKeyboard...)
+	return keyboard
+}
+
+// BuildRemoveKidButtons creates buttons for selecting which child to remove from a session
+func BuildRemoveKidButtons(sessionIndex int, sessionChildren []Child) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	for i, child := range sessionChildren {
 		callback := MarshalCallback(CallbackData{
 			Action:       "manage",
-			SubAction:    "extend",
+			SubAction:    "remove_kid",
 			Step:         2,
 			SessionIndex: sessionIndex,
-			Duration:     duration,
+			ChildIndex:   i,
 		})
 
-		label := fmt.Sprintf("+%d", duration)
-		btn := tgbotapi.NewInlineKeyboardButtonData(label, callback)
-
-		if i < 3 {
-			row1 = append(row1, btn)
-		} else {
-			row2 = append(row2, btn)
-		}
+		btn := tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("%s %s", child.Emoji, child.Name),
+			callback,
+		)
+		rows = append(rows, []tgbotapi.InlineKeyboardButton{btn})
 	}
 
-	rows = append(rows, row1, row2)
+	// Back and Cancel buttons
+	backBtn := tgbotapi.NewInlineKeyboardButtonData(
+		"◀️ Back",
+		MarshalCallback(CallbackData{Action: "manage", Step: 0}),
+	)
+
+	cancelBtn := tgbotapi.NewInlineKeyboardButtonData(
+		"❌ Cancel",
+		MarshalCallback(CallbackData{Action: "cancel"}),
+	)
+
+	rows = append(rows, []tgbotapi.InlineKeyboardButton{backBtn, cancelBtn})
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// BuildExtendDurationButtons creates buttons for selecting extension duration
+// Only durations that fit in maxMinutes are offered, plus a "Max" button
+// maxMinutes <= 0 means the remaining time is unknown and all presets are shown
+func BuildExtendDurationButtons(sessionIndex int, maxMinutes int) tgbotapi.InlineKeyboardMarkup {
+	durations := feasibleDurations(durationPresets, maxMinutes)
+
+	rows := buildDurationRows(durations, maxMinutes, func(duration int) string {
+		return MarshalCallback(CallbackData{
+			Action:       "manage",
+			SubAction:    "extend",
+			Step:         2,
+			SessionIndex: sessionIndex,
+			Duration:     duration,
+		})
+	})
 
 	// Back and Cancel buttons
 	backBtn := tgbotapi.NewInlineKeyboardButtonData(
@@ -376,6 +538,44 @@ func BuildExtendDurationButtons(sessionIndex int) tgbotapi.InlineKeyboardMarkup
 	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
 
+// warningExtensionPresets are the extensions offered on an expiry warning
+var warningExtensionPresets = []int{5, 10, 15}
+
+// BuildWarningButtons creates the buttons attached to an expiry warning
+// The device ID identifies the session: session IDs do not fit in callback data,
+// and a list index could point at another session by the time the button is pressed
+// Extensions longer than maxMinutes are replaced by maxMinutes; 0 offers only the stop button
+func BuildWarningButtons(deviceID string, maxMinutes int) tgbotapi.InlineKeyboardMarkup {
+	var durations []int
+	if maxMinutes > 0 {
+		durations = feasibleDurations(warningExtensionPresets, maxMinutes)
+		if maxMinutes <= warningExtensionPresets[len(warningExtensionPresets)-1] {
+			durations = append(durations, maxMinutes)
+		}
+	}
+
+	var row []tgbotapi.InlineKeyboardButton
+	for _, duration := range durations {
+		row = append(row, tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("+%d min", duration),
+			MarshalCallback(CallbackData{Action: "warning", SubAction: "extend", Device: deviceID, Duration: duration}),
+		))
+	}
+
+	stopBtn := tgbotapi.NewInlineKeyboardButtonData(
+		"⏹ Stop now",
+		MarshalCallback(CallbackData{Action: "warning", SubAction: "stop", Device: deviceID}),
+	)
+
+	var rows [][]tgbotapi.InlineKeyboardButton
+	if len(row) > 0 {
+		rows = append(rows, row)
+	}
+	rows = append(rows, []tgbotapi.InlineKeyboardButton{stopBtn})
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
 // BuildMainMenuButtons creates main menu shortcut buttons
 func BuildMainMenuButtons() tgbotapi.InlineKeyboardMarkup {
 	return tgbotapi.NewInlineKeyboardMarkup(
@@ -446,6 +646,10 @@ func BuildMoreMenuButtons(skipDowntimeActive bool) tgbotapi.InlineKeyboardMarkup
 			tgbotapi.NewInlineKeyboardButtonData("🔓 Bypass Mode",
 				MarshalCallback(CallbackData{Action: "bypass", Step: 0})),
 		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("🚨 Lockdown",
+				MarshalCallback(CallbackData{Action: "lockdown", Step: 0})),
+		),
 		tgbotapi.NewInlineKeyboardRow(
 			tgbotapi.NewInlineKeyboardButtonData("◀️ Back",
 				MarshalCallback(CallbackData{Action: "main_menu"})),
@@ -453,6 +657,38 @@ func BuildMoreMenuButtons(skipDowntimeActive bool) tgbotapi.InlineKeyboardMarkup
 	)
 }
 
+// BuildLockdownButtons creates the confirmation buttons for the lockdown screen
+// Step 1 activates the lockdown, step 2 lifts it
+func BuildLockdownButtons(active bool) tgbotapi.InlineKeyboardMarkup {
+	action := tgbotapi.NewInlineKeyboardButtonData("🚨 Confirm Lockdown",
+		MarshalCallback(CallbackData{Action: "lockdown", Step: 1}))
+	if active {
+		action = tgbotapi.NewInlineKeyboardButtonData("🔓 Lift Lockdown",
+			MarshalCallback(CallbackData{Action: "lockdown", Step: 2}))
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(action),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("◀️ Back",
+				MarshalCallback(CallbackData{Action: "more_menu"})),
+		),
+	)
+}
+
+// BuildProfileTransitionButtons creates buttons to apply or keep the limits after a birthday
+// The transition is identified by child index and age to fit Telegram's callback data limit.
+func BuildProfileTransitionButtons(childIndex, age int) tgbotapi.InlineKeyboardMarkup {
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("✅ Apply",
+				MarshalCallback(CallbackData{Action: "profile", SubAction: "confirm", ChildIndex: childIndex, Duration: age})),
+			tgbotapi.NewInlineKeyboardButtonData("Keep current",
+				MarshalCallback(CallbackData{Action: "profile", SubAction: "dismiss", ChildIndex: childIndex, Duration: age})),
+		),
+	)
+}
+
 // BuildRewardDurationButtons creates buttons for selecting reward duration
 func BuildRewardDurationButtons(childIndex int) tgbotapi.InlineKeyboardMarkup {
 	durations := []int{15, 30, 60}
@@ -492,6 +728,58 @@ func BuildRewardDurationButtons(childIndex int) tgbotapi.InlineKeyboardMarkup {
 	return tgbotapi.NewInlineKeyboardMarkup(rows...)
 }
 
+// limitStep is how much the limit buttons change a daily limit, in minutes
+const limitStep = 15
+
+// BuildLimitsChildrenButtons creates buttons for selecting the child whose limits to change
+// Limits are per child, so unlike BuildChildrenButtons there is no shared option
+func BuildLimitsChildrenButtons(children []Child) tgbotapi.InlineKeyboardMarkup {
+	var rows [][]tgbotapi.InlineKeyboardButton
+
+	for i, child := range children {
+		btn := tgbotapi.NewInlineKeyboardButtonData(
+			fmt.Sprintf("%s %s", child.Emoji, child.Name),
+			MarshalCallback(CallbackData{Action: "limits", Step: 1, ChildIndex: i}),
+		)
+		rows = append(rows, []tgbotapi.InlineKeyboardButton{btn})
+	}
+
+	rows = append(rows, []tgbotapi.InlineKeyboardButton{
+		tgbotapi.NewInlineKeyboardButtonData("❌ Cancel", MarshalCallback(CallbackData{Action: "cancel"})),
+	})
+
+	return tgbotapi.NewInlineKeyboardMarkup(rows...)
+}
+
+// BuildLimitsButtons creates the buttons adjusting a child's weekday and weekend limits
+// SubAction is "wd" (weekdays) or "we" (weekends); Duration is the signed change in minutes
+func BuildLimitsButtons(childIndex int) tgbotapi.InlineKeyboardMarkup {
+	adjust := func(label, subAction string, minutes int) tgbotapi.InlineKeyboardButton {
+		return tgbotapi.NewInlineKeyboardButtonData(label, MarshalCallback(CallbackData{
+			Action:     "limits",
+			SubAction:  subAction,
+			Step:       2,
+			ChildIndex: childIndex,
+			Duration:   minutes,
+		}))
+	}
+
+	return tgbotapi.NewInlineKeyboardMarkup(
+		tgbotapi.NewInlineKeyboardRow(
+			adjust(fmt.Sprintf("📅 Weekdays -%d", limitStep), "wd", -limitStep),
+			adjust(fmt.Sprintf("📅 Weekdays +%d", limitStep), "wd", limitStep),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			adjust(fmt.Sprintf("🎉 Weekends -%d", limitStep), "we", -limitStep),
+			adjust(fmt.Sprintf("🎉 Weekends +%d", limitStep), "we", limitStep),
+		),
+		tgbotapi.NewInlineKeyboardRow(
+			tgbotapi.NewInlineKeyboardButtonData("◀️ Back", MarshalCallback(CallbackData{Action: "limits", Step: 0})),
+			tgbotapi.NewInlineKeyboardButtonData("✅ Done", MarshalCallback(CallbackData{Action: "main_menu"})),
+		),
+	)
+}
+
 // BuildFineDurationButtons creates buttons for selecting fine duration
 func BuildFineDurationButtons(childIndex int) tgbotapi.InlineKeyboardMarkup {
 	durations := []int{15, 30, 60}
@@ -3,12 +3,13 @@ package bot
 import (
 	"context"
 	"fmt"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 // handleNewSessionFlow handles the multi-step flow for creating a new session
-func (b *Bot) handleNewSessionFlow(ctx context.Context, message *tgbotapi.Message, data *CallbackData) error {
+func (b *Bot) handleNewSessionFlow(ctx context.Context, message *tgbotapi.Message, user *tgbotapi.User, data *CallbackData) error {
 	b.logger.Info("New session flow",
 		"step", data.Step,
 		"child_index", data.ChildIndex,
@@ -37,7 +38,7 @@ func (b *Bot) handleNewSessionFlow(ctx context.Context, message *tgbotapi.Messag
 			)
 			return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), BuildQuickActionsButtons())
 		}
-		return b.newSessionCreate(ctx, message, childID, data.Device, data.Duration)
+		return b.newSessionCreate(ctx, message, user, childID, data.Device, data.Duration)
 	default:
 		return b.editMessage(message.Chat.ID, message.MessageID,
 			"❌ Invalid step in session creation flow.", nil)
@@ -97,25 +98,131 @@ func (b *Bot) newSessionStep2(ctx context.Context, message *tgbotapi.Message, ch
 			"❌ No devices configured.", nil)
 	}
 
+	// Only offer devices every selected child is allowed to use
+	children, err := b.client.ListChildren(ctx)
+	if err != nil {
+		return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), nil)
+	}
+	devices = allowedDevices(devices, selectedChildren(children, childIndex))
+
+	if len(devices) == 0 {
+		return b.editMessage(message.Chat.ID, message.MessageID,
+			"❌ No devices are allowed for the selected child.", BuildQuickActionsButtons())
+	}
+
 	text := "➕ *New Session*\n\n📺 Step 2/3: Select device"
 	keyboard := BuildDevicesButtons(devices, "newsession", 2, childIndex)
 
 	return b.editMessage(message.Chat.ID, message.MessageID, text, keyboard)
 }
 
+// selectedChildren returns the children picked in step 1 (all children for a shared session)
+func selectedChildren(children []Child, childIndex int) []Child {
+	if childIndex == -1 {
+		return children
+	}
+	if childIndex < 0 || childIndex >= len(children) {
+		return nil
+	}
+	return children[childIndex : childIndex+1]
+}
+
+// allowedDevices filters devices down to those every child may use
+func allowedDevices(devices []Device, children []Child) []Device {
+	var allowed []Device
+	for _, device := range devices {
+		usable := true
+		for _, child := range children {
+			if !child.CanUseDevice(device.ID) {
+				usable = false
+				break
+			}
+		}
+		if usable {
+			allowed = append(allowed, device)
+		}
+	}
+	return allowed
+}
+
 // newSessionStep3 shows duration selection
 func (b *Bot) newSessionStep3(ctx context.Context, message *tgbotapi.Message, childIndex int, device string) error {
 	emoji := getDeviceEmoji(device)
 	text := fmt.Sprintf("➕ *New Session*\n\n%s Device: *%s*\n\n⏱ Step 3/3: Select duration (minutes)",
 		emoji, device)
 
-	keyboard := BuildDurationButtons("newsession", 3, childIndex, device)
+	// Offer only durations that fit in the remaining time (0 means unknown)
+	maxMinutes, err := b.newSessionMaxMinutes(ctx, childIndex)
+	if err != nil {
+		b.logger.Warn("Failed to get remaining time, showing all durations",
+			"child_index", childIndex,
+			"error", err,
+		)
+		maxMinutes = 0
+	} else if maxMinutes == 0 {
+		text = fmt.Sprintf("➕ *New Session*\n\n%s Device: *%s*\n\n❌ No screen time left today.",
+			emoji, device)
+		return b.editMessage(message.Chat.ID, message.MessageID, text, BuildQuickActionsButtons())
+	}
+
+	keyboard := BuildDurationButtons("newsession", 3, childIndex, device, maxMinutes)
 
 	return b.editMessage(message.Chat.ID, message.MessageID, text, keyboard)
 }
 
+// newSessionMaxMinutes returns the longest session the selected child(ren) can start
+// For shared sessions this is the smallest remaining time among all children
+func (b *Bot) newSessionMaxMinutes(ctx context.Context, childIndex int) (int, error) {
+	var childIDs []string
+
+	if childIndex == -1 {
+		children, err := b.client.ListChildren(ctx)
+		if err != nil {
+			return 0, err
+		}
+		for _, child := range children {
+			childIDs = append(childIDs, child.ID)
+		}
+	} else {
+		childID, err := b.resolveChildIndex(ctx, childIndex)
+		if err != nil {
+			return 0, err
+		}
+		childIDs = []string{childID}
+	}
+
+	return b.minRemainingMinutes(ctx, childIDs, 0)
+}
+
+// minRemainingMinutes returns the smallest remaining daily time among the children
+// reserved is subtracted from each child's remaining time (e.g. the unused part of an active session)
+func (b *Bot) minRemainingMinutes(ctx context.Context, childIDs []string, reserved int) (int, error) {
+	if len(childIDs) == 0 {
+		return 0, fmt.Errorf("no children selected")
+	}
+
+	minRemaining := -1
+	for _, childID := range childIDs {
+		suggestions, err := b.client.GetDurationSuggestions(ctx, childID)
+		if err != nil {
+			return 0, err
+		}
+
+		// Parents can override downtime, so only the daily limit caps the duration
+		remaining := suggestions.RemainingMinutes - reserved
+		if remaining < 0 {
+			remaining = 0
+		}
+		if minRemaining == -1 || remaining < minRemaining {
+			minRemaining = remaining
+		}
+	}
+
+	return minRemaining, nil
+}
+
 // newSessionCreate creates the session
-func (b *Bot) newSessionCreate(ctx context.Context, message *tgbotapi.Message, childID, device string, duration int) error {
+func (b *Bot) newSessionCreate(ctx context.Context, message *tgbotapi.Message, user *tgbotapi.User, childID, device string, duration int) error {
 	// Get all children if "shared" was selected
 	var childIDs []string
 
@@ -133,15 +240,17 @@ func (b *Bot) newSessionCreate(ctx context.Context, message *tgbotapi.Message, c
 	}
 
 	// Create session request
+	// Telegram bot requests are always from a parent, so sessions may run during downtime
 	req := CreateSessionRequest{
-		DeviceID: device, // device parameter now holds device ID
-		ChildIDs: childIDs,
-		Minutes:  duration,
+		DeviceID:      device, // device parameter now holds device ID
+		ChildIDs:      childIDs,
+		Minutes:       duration,
+		Override:      []string{"downtime"},
+		OverrideBy:    telegramActor(user),
+		InitiatorType: "parent",
+		InitiatorID:   telegramActor(user),
 	}
 
-	// Add parent override context (Telegram bot requests are always from parent)
-	ctx = context.WithValue(ctx, "parent_override", true)
-
 	session, err := b.client.CreateSession(ctx, req)
 	if err != nil {
 		return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), BuildQuickActionsButtons())
@@ -235,12 +344,56 @@ func (b *Bot) extendStep1(ctx context.Context, message *tgbotapi.Message) error
 
 // extendStep2 shows duration selection for extension
 func (b *Bot) extendStep2(ctx context.Context, message *tgbotapi.Message, sessionIndex int) error {
+	return b.showExtendDurations(ctx, message, sessionIndex)
+}
+
+// maxExtensionMinutes mirrors the server-side cap on a single extension request
+const maxExtensionMinutes = 30
+
+// showExtendDurations shows extension durations that fit in the session children's remaining time
+func (b *Bot) showExtendDurations(ctx context.Context, message *tgbotapi.Message, sessionIndex int) error {
+	maxMinutes, err := b.extensionMaxMinutes(ctx, sessionIndex)
+	if err != nil {
+		b.logger.Warn("Failed to get remaining time, showing all durations",
+			"session_index", sessionIndex,
+			"error", err,
+		)
+		maxMinutes = 0
+	} else if maxMinutes == 0 {
+		return b.editMessage(message.Chat.ID, message.MessageID,
+			"⏱ *Extend Session*\n\n❌ No screen time left today to extend this session.", BuildQuickActionsButtons())
+	}
+
 	text := "⏱ *Extend Session*\n\nSelect additional minutes:"
-	keyboard := BuildExtendDurationButtons(sessionIndex)
+	keyboard := BuildExtendDurationButtons(sessionIndex, maxMinutes)
 
 	return b.editMessage(message.Chat.ID, message.MessageID, text, keyboard)
 }
 
+// extensionMaxMinutes returns the largest extension the session's children can get
+// The unused part of the session is already committed, so it is subtracted from remaining time
+func (b *Bot) extensionMaxMinutes(ctx context.Context, sessionIndex int) (int, error) {
+	sessions, err := b.client.ListSessions(ctx, true, "")
+	if err != nil {
+		return 0, err
+	}
+
+	if sessionIndex < 0 || sessionIndex >= len(sessions) {
+		return 0, fmt.Errorf("invalid session index: %d", sessionIndex)
+	}
+	session := sessions[sessionIndex]
+
+	maxMinutes, err := b.minRemainingMinutes(ctx, session.ChildIDs, session.RemainingMinutes)
+	if err != nil {
+		return 0, err
+	}
+
+	if maxMinutes > maxExtensionMinutes {
+		maxMinutes = maxExtensionMinutes
+	}
+	return maxMinutes, nil
+}
+
 // extendSession extends the session
 func (b *Bot) extendSession(ctx context.Context, message *tgbotapi.Message, sessionID string, additionalMinutes int) error {
 	session, err := b.client.ExtendSession(ctx, sessionID, additionalMinutes)
@@ -260,12 +413,11 @@ func (b *Bot) handleStopFlow(ctx context.Context, message *tgbotapi.Message, dat
 		// Step 0: Show session selection
 		return b.stopStep1(ctx, message)
 	case 1:
-		// Step 1: Session selected (by index), stop it immediately
-		sessionID, err := b.resolveSessionIndex(ctx, data.SessionIndex)
-		if err != nil {
-			return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), BuildQuickActionsButtons())
-		}
-		return b.stopSession(ctx, message, sessionID)
+		// Step 1: Session selected (by index), ask for confirmation
+		return b.confirmStop(ctx, message, data.SessionIndex)
+	case 2:
+		// Step 2: Stop confirmed
+		return b.stopConfirmed(ctx, message, data)
 	default:
 		return b.editMessage(message.Chat.ID, message.MessageID,
 			"❌ Invalid step in stop flow.", nil)
@@ -304,6 +456,51 @@ func (b *Bot) stopStep1(ctx context.Context, message *tgbotapi.Message) error {
 	return b.editMessage(message.Chat.ID, message.MessageID, text, keyboard)
 }
 
+// confirmStop shows the session at sessionIndex and asks whether to stop it
+func (b *Bot) confirmStop(ctx context.Context, message *tgbotapi.Message, sessionIndex int) error {
+	sessions, err := b.client.ListSessions(ctx, true, "")
+	if err != nil {
+		return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), BuildQuickActionsButtons())
+	}
+
+	if sessionIndex < 0 || sessionIndex >= len(sessions) {
+		return b.editMessage(message.Chat.ID, message.MessageID,
+			"❌ Session not found or already stopped.", BuildQuickActionsButtons())
+	}
+	session := sessions[sessionIndex]
+
+	children, err := b.client.ListChildren(ctx)
+	if err != nil {
+		return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), BuildQuickActionsButtons())
+	}
+
+	childrenMap := make(map[string]Child)
+	for _, child := range children {
+		childrenMap[child.ID] = child
+	}
+
+	text := FormatStopConfirmation(&session, childrenMap)
+	keyboard := BuildStopConfirmButtons(sessionIndex, session.DeviceID)
+
+	return b.editMessage(message.Chat.ID, message.MessageID, text, keyboard)
+}
+
+// stopConfirmed stops the session the parent confirmed
+// Sessions are addressed by index, so the device is checked in case the list changed since the confirmation
+func (b *Bot) stopConfirmed(ctx context.Context, message *tgbotapi.Message, data *CallbackData) error {
+	sessions, err := b.client.ListSessions(ctx, true, "")
+	if err != nil {
+		return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), BuildQuickActionsButtons())
+	}
+
+	if data.SessionIndex < 0 || data.SessionIndex >= len(sessions) || sessions[data.SessionIndex].DeviceID != data.Device {
+		return b.editMessage(message.Chat.ID, message.MessageID,
+			"❌ The sessions changed in the meantime. Please select the session again.", BuildSessionsMenuButtons())
+	}
+
+	return b.stopSession(ctx, message, sessions[data.SessionIndex].ID)
+}
+
 // handleManageFlow handles the unified session management flow
 func (b *Bot) handleManageFlow(ctx context.Context, message *tgbotapi.Message, data *CallbackData) error {
 	b.logger.Info("Manage session flow",
@@ -323,15 +520,17 @@ func (b *Bot) handleManageFlow(ctx context.Context, message *tgbotapi.Message, d
 			// Show duration selection
 			return b.manageExtendStep1(ctx, message, data.SessionIndex)
 		case "stop":
-			// Stop immediately
-			sessionID, err := b.resolveSessionIndex(ctx, data.SessionIndex)
-			if err != nil {
-				return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), BuildQuickActionsButtons())
-			}
-			return b.stopSession(ctx, message, sessionID)
+			// Ask for confirmation (confirmed stops continue in the stop flow)
+			return b.confirmStop(ctx, message, data.SessionIndex)
 		case "add_kid":
 			// Show available children to add
 			return b.manageAddKidStep1(ctx, message, data.SessionIndex)
+		case "share":
+			// Ask for confirmation before adding all other children
+			return b.manageShareStep1(ctx, message, data.SessionIndex)
+		case "remove_kid":
+			// Show children in the session
+			return b.manageRemoveKidStep1(ctx, message, data.SessionIndex)
 		default:
 			return b.editMessage(message.Chat.ID, message.MessageID,
 				"❌ Unknown action.", BuildQuickActionsButtons())
@@ -349,6 +548,12 @@ func (b *Bot) handleManageFlow(ctx context.Context, message *tgbotapi.Message, d
 		case "add_kid":
 			// Child selected, add to session
 			return b.manageAddKidStep2(ctx, message, data.SessionIndex, data.ChildIndex)
+		case "share":
+			// Sharing confirmed, add all other children
+			return b.manageAddKidStep2(ctx, message, data.SessionIndex, -1)
+		case "remove_kid":
+			// Child selected, remove from session
+			return b.manageRemoveKidStep2(ctx, message, data.SessionIndex, data.ChildIndex)
 		default:
 			return b.editMessage(message.Chat.ID, message.MessageID,
 				"❌ Unknown action.", BuildQuickActionsButtons())
@@ -386,7 +591,8 @@ func (b *Bot) manageStep0(ctx context.Context, message *tgbotapi.Message) error
 	text := "⏱ *Manage Sessions*\n\nSelect an action for each session:\n" +
 		"• ⏱ Extend - Add more minutes\n" +
 		"• 🛑 Stop - End session early\n" +
-		"• 👶 Add Kid - Share with another child\n"
+		"• 👶 Add Kid - Share with another child\n" +
+		"• 👋 Remove Kid - A child left a shared session\n"
 
 	keyboard := BuildSessionManagementButtons(sessions, childrenMap)
 
@@ -395,10 +601,7 @@ func (b *Bot) manageStep0(ctx context.Context, message *tgbotapi.Message) error
 
 // manageExtendStep1 shows duration selection for extending
 func (b *Bot) manageExtendStep1(ctx context.Context, message *tgbotapi.Message, sessionIndex int) error {
-	text := "⏱ *Extend Session*\n\nSelect additional minutes:"
-	keyboard := BuildExtendDurationButtons(sessionIndex)
-
-	return b.editMessage(message.Chat.ID, message.MessageID, text, keyboard)
+	return b.showExtendDurations(ctx, message, sessionIndex)
 }
 
 // manageAddKidStep1 shows child selection for adding to session
@@ -450,6 +653,45 @@ func (b *Bot) manageAddKidStep1(ctx context.Context, message *tgbotapi.Message,
 	return b.editMessage(message.Chat.ID, message.MessageID, text, keyboard)
 }
 
+// manageShareStep1 shows which children would join the session and asks for confirmation
+func (b *Bot) manageShareStep1(ctx context.Context, message *tgbotapi.Message, sessionIndex int) error {
+	sessions, err := b.client.ListSessions(ctx, true, "")
+	if err != nil {
+		return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), BuildQuickActionsButtons())
+	}
+
+	if sessionIndex < 0 || sessionIndex >= len(sessions) {
+		return b.editMessage(message.Chat.ID, message.MessageID,
+			"❌ Invalid session.", BuildQuickActionsButtons())
+	}
+	session := sessions[sessionIndex]
+
+	allChildren, err := b.client.ListChildren(ctx)
+	if err != nil {
+		return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), BuildQuickActionsButtons())
+	}
+
+	inSession := make(map[string]bool)
+	for _, childID := range session.ChildIDs {
+		inSession[childID] = true
+	}
+
+	var childrenToAdd []Child
+	for _, child := range allChildren {
+		if !inSession[child.ID] {
+			childrenToAdd = append(childrenToAdd, child)
+		}
+	}
+
+	if len(childrenToAdd) == 0 {
+		return b.editMessage(message.Chat.ID, message.MessageID,
+			"❌ No children to add.", BuildQuickActionsButtons())
+	}
+
+	text := FormatShareConfirmation(&session, childrenToAdd)
+	return b.editMessage(message.Chat.ID, message.MessageID, text, BuildShareConfirmButtons(sessionIndex))
+}
+
 // manageAddKidStep2 adds the selected child to the session
 func (b *Bot) manageAddKidStep2(ctx context.Context, message *tgbotapi.Message, sessionIndex int, childIndex int) error {
 	// Get session
@@ -535,6 +777,86 @@ func (b *Bot) manageAddKidStep2(ctx context.Context, message *tgbotapi.Message,
 	return b.editMessage(message.Chat.ID, message.MessageID, text, BuildQuickActionsButtons())
 }
 
+// manageRemoveKidStep1 shows child selection for removing from a session
+func (b *Bot) manageRemoveKidStep1(ctx context.Context, message *tgbotapi.Message, sessionIndex int) error {
+	session, sessionChildren, err := b.sessionChildren(ctx, sessionIndex)
+	if err != nil {
+		return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), BuildQuickActionsButtons())
+	}
+
+	if len(session.ChildIDs) < 2 {
+		return b.editMessage(message.Chat.ID, message.MessageID,
+			"❌ The only child in a session cannot be removed. Stop the session instead.", BuildQuickActionsButtons())
+	}
+
+	text := "👋 *Remove Child from Session*\n\nSelect the child who left. They are charged for the time used so far:"
+	keyboard := BuildRemoveKidButtons(sessionIndex, sessionChildren)
+
+	return b.editMessage(message.Chat.ID, message.MessageID, text, keyboard)
+}
+
+// manageRemoveKidStep2 removes the selected child from the session
+func (b *Bot) manageRemoveKidStep2(ctx context.Context, message *tgbotapi.Message, sessionIndex int, childIndex int) error {
+	session, sessionChildren, err := b.sessionChildren(ctx, sessionIndex)
+	if err != nil {
+		return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), BuildQuickActionsButtons())
+	}
+
+	if childIndex < 0 || childIndex >= len(sessionChildren) {
+		return b.editMessage(message.Chat.ID, message.MessageID,
+			"❌ Invalid child selection.", BuildQuickActionsButtons())
+	}
+
+	removed := sessionChildren[childIndex]
+
+	updatedSession, err := b.client.RemoveChildrenFromSession(ctx, session.ID, []string{removed.ID})
+	if err != nil {
+		return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), BuildQuickActionsButtons())
+	}
+
+	childrenMap := make(map[string]Child)
+	for _, child := range sessionChildren {
+		childrenMap[child.ID] = child
+	}
+
+	text := FormatChildRemovedFromSession(updatedSession, removed, childrenMap)
+
+	return b.editMessage(message.Chat.ID, message.MessageID, text, BuildQuickActionsButtons())
+}
+
+// sessionChildren resolves an active session by index and the children in it, in session order
+func (b *Bot) sessionChildren(ctx context.Context, sessionIndex int) (*Session, []Child, error) {
+	sessions, err := b.client.ListSessions(ctx, true, "")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if sessionIndex < 0 || sessionIndex >= len(sessions) {
+		return nil, nil, fmt.Errorf("invalid session index: %d", sessionIndex)
+	}
+
+	session := sessions[sessionIndex]
+
+	allChildren, err := b.client.ListChildren(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	childrenByID := make(map[string]Child)
+	for _, child := range allChildren {
+		childrenByID[child.ID] = child
+	}
+
+	var sessionChildren []Child
+	for _, childID := range session.ChildIDs {
+		if child, ok := childrenByID[childID]; ok {
+			sessionChildren = append(sessionChildren, child)
+		}
+	}
+
+	return &session, sessionChildren, nil
+}
+
 // handleRewardFlow handles the multi-step flow for granting rewards
 func (b *Bot) handleRewardFlow(ctx context.Context, message *tgbotapi.Message, data *CallbackData) error {
 	switch data.Step {
@@ -612,6 +934,109 @@ func (b *Bot) grantReward(ctx context.Context, message *tgbotapi.Message, childI
 	return b.editMessage(message.Chat.ID, message.MessageID, text, BuildQuickActionsButtons())
 }
 
+// maxLimitMinutes caps the daily limits set with the limit buttons (a whole day)
+const maxLimitMinutes = 24 * 60
+
+// handleLimitsFlow handles the flow for viewing and adjusting a child's daily limits
+func (b *Bot) handleLimitsFlow(ctx context.Context, message *tgbotapi.Message, user *tgbotapi.User, data *CallbackData) error {
+	switch data.Step {
+	case 0:
+		// Step 0: Back to child selection
+		return b.limitsStep0(ctx, message)
+	case 1:
+		// Step 1: Child selected (by index), show the limits
+		return b.limitsStep1(ctx, message, data.ChildIndex)
+	case 2:
+		// Step 2: Adjust a limit
+		return b.adjustLimit(ctx, message, user, data)
+	default:
+		return b.editMessage(message.Chat.ID, message.MessageID,
+			"❌ Invalid step in limits flow.", nil)
+	}
+}
+
+// limitsStep0 shows child selection
+func (b *Bot) limitsStep0(ctx context.Context, message *tgbotapi.Message) error {
+	children, err := b.client.ListChildren(ctx)
+	if err != nil {
+		return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), BuildQuickActionsButtons())
+	}
+
+	if len(children) == 0 {
+		return b.editMessage(message.Chat.ID, message.MessageID,
+			"❌ No children configured. Add children first using the API.", BuildQuickActionsButtons())
+	}
+
+	text := "📏 *Daily Limits*\n\n👶 Select child"
+	return b.editMessage(message.Chat.ID, message.MessageID, text, BuildLimitsChildrenButtons(children))
+}
+
+// limitsStep1 shows the selected child's limits with the adjustment buttons
+func (b *Bot) limitsStep1(ctx context.Context, message *tgbotapi.Message, childIndex int) error {
+	child, err := b.limitsChild(ctx, childIndex)
+	if err != nil {
+		return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), BuildQuickActionsButtons())
+	}
+
+	return b.editMessage(message.Chat.ID, message.MessageID, FormatChildLimits(*child, ""), BuildLimitsButtons(childIndex))
+}
+
+// adjustLimit changes the child's weekday or weekend limit by data.Duration minutes
+// The change goes through the limit history, so it is audit-logged with the Telegram user as actor
+func (b *Bot) adjustLimit(ctx context.Context, message *tgbotapi.Message, user *tgbotapi.User, data *CallbackData) error {
+	child, err := b.limitsChild(ctx, data.ChildIndex)
+	if err != nil {
+		return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), BuildQuickActionsButtons())
+	}
+
+	weekday, weekend := child.WeekdayLimit, child.WeekendLimit
+	var label string
+	var limit *int
+	switch data.SubAction {
+	case "wd":
+		label, limit = "Weekday", &weekday
+	case "we":
+		label, limit = "Weekend", &weekend
+	default:
+		return b.editMessage(message.Chat.ID, message.MessageID,
+			"❌ Unknown limit.", BuildQuickActionsButtons())
+	}
+
+	*limit += data.Duration
+	if *limit < limitStep || *limit > maxLimitMinutes {
+		text := FormatChildLimits(*child, fmt.Sprintf("%s limit must stay between %d and %d minutes.", label, limitStep, maxLimitMinutes))
+		return b.editMessage(message.Chat.ID, message.MessageID, text, BuildLimitsButtons(data.ChildIndex))
+	}
+
+	if _, err := b.client.ChangeLimits(ctx, child.ID, weekday, weekend, telegramActor(user)); err != nil {
+		return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), BuildLimitsButtons(data.ChildIndex))
+	}
+
+	b.logger.Info("Limits changed",
+		"child_id", child.ID,
+		"weekday_limit", weekday,
+		"weekend_limit", weekend,
+		"user_id", user.ID,
+	)
+
+	changed := fmt.Sprintf("%s limit changed from %d to %d min.", label, *limit-data.Duration, *limit)
+	child.WeekdayLimit, child.WeekendLimit = weekday, weekend
+	return b.editMessage(message.Chat.ID, message.MessageID, FormatChildLimits(*child, changed), BuildLimitsButtons(data.ChildIndex))
+}
+
+// limitsChild returns the child at childIndex with its current limits
+func (b *Bot) limitsChild(ctx context.Context, childIndex int) (*Child, error) {
+	children, err := b.client.ListChildren(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if childIndex < 0 || childIndex >= len(children) {
+		return nil, fmt.Errorf("invalid child index: %d", childIndex)
+	}
+	return &children[childIndex], nil
+}
+
 // handleFineFlow handles the multi-step flow for applying fines
 func (b *Bot) handleFineFlow(ctx context.Context, message *tgbotapi.Message, data *CallbackData) error {
 	switch data.Step {
@@ -728,9 +1153,126 @@ func (b *Bot) stopSession(ctx context.Context, message *tgbotapi.Message, sessio
 
 	text := FormatSessionStopped(stoppedSession, childrenMap)
 
+	// The stop can be undone for a while, restarting the session with the minutes it had left
+	_, remaining := calculateSessionEnd(*stoppedSession)
+	if remaining <= 0 {
+		return b.editMessage(message.Chat.ID, message.MessageID, text, BuildQuickActionsButtons())
+	}
+
+	b.stops.add(message.Chat.ID, message.MessageID, *stoppedSession, remaining)
+	text += fmt.Sprintf("\n\n↩️ Stopped by mistake? Tap Undo within %d minutes.", int(stopUndoWindow.Minutes()))
+	if err := b.editMessage(message.Chat.ID, message.MessageID, text, BuildStopUndoButtons()); err != nil {
+		return err
+	}
+
+	chatID, messageID := message.Chat.ID, message.MessageID
+	time.AfterFunc(stopUndoWindow, func() {
+		b.expireStopUndo(chatID, messageID)
+	})
+	return nil
+}
+
+// expireStopUndo removes the Undo button once the undo window has ended
+func (b *Bot) expireStopUndo(chatID int64, messageID int) {
+	if !b.stops.drop(chatID, messageID) {
+		// Undone already, or the message shows another screen now
+		return
+	}
+
+	edit := tgbotapi.NewEditMessageReplyMarkup(chatID, messageID, BuildQuickActionsButtons())
+	if _, err := b.api.Request(edit); err != nil {
+		b.logger.Warn("Failed to remove undo button",
+			"chat_id", chatID,
+			"error", err,
+		)
+	}
+}
+
+// handleUndoStop restarts the session stopped from this message with the minutes it had left
+func (b *Bot) handleUndoStop(ctx context.Context, message *tgbotapi.Message, user *tgbotapi.User) error {
+	stop, ok := b.stops.take(message.Chat.ID, message.MessageID)
+	if !ok {
+		return b.editMessage(message.Chat.ID, message.MessageID,
+			"↩️ The stop can no longer be undone. Start a new session instead.", BuildQuickActionsButtons())
+	}
+
+	// Restarted like any session started from the bot, so it may run during downtime
+	req := CreateSessionRequest{
+		DeviceID:      stop.session.DeviceID,
+		ChildIDs:      stop.session.ChildIDs,
+		Minutes:       stop.remaining,
+		Override:      []string{"downtime"},
+		OverrideBy:    telegramActor(user),
+		InitiatorType: "parent",
+		InitiatorID:   telegramActor(user),
+	}
+
+	session, err := b.client.CreateSession(ctx, req)
+	if err != nil {
+		return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), BuildQuickActionsButtons())
+	}
+
+	b.logger.Info("Session stop undone",
+		"stopped_session_id", stop.session.ID,
+		"session_id", session.ID,
+		"minutes", session.ExpectedDuration,
+	)
+
+	children, err := b.client.ListChildren(ctx)
+	if err != nil {
+		return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), BuildQuickActionsButtons())
+	}
+
+	childrenMap := make(map[string]Child)
+	for _, child := range children {
+		childrenMap[child.ID] = child
+	}
+
+	text := FormatStopUndone(session, childrenMap)
 	return b.editMessage(message.Chat.ID, message.MessageID, text, BuildQuickActionsButtons())
 }
 
+// handleWarningFlow handles the buttons of a forwarded expiry warning
+// The session is looked up by device when the button is pressed, since it may have
+// ended (or been extended from elsewhere) after the warning was sent
+func (b *Bot) handleWarningFlow(ctx context.Context, message *tgbotapi.Message, data *CallbackData) error {
+	sessions, err := b.client.ListSessions(ctx, true, "")
+	if err != nil {
+		return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), BuildQuickActionsButtons())
+	}
+
+	// With several sessions on the device, the one ending first is the one warned about
+	var session *Session
+	for i := range sessions {
+		if sessions[i].DeviceID != data.Device {
+			continue
+		}
+		if session == nil || sessions[i].RemainingMinutes < session.RemainingMinutes {
+			session = &sessions[i]
+		}
+	}
+	if session == nil {
+		return b.editMessage(message.Chat.ID, message.MessageID,
+			"⏰ *Time Almost Up*\n\n✅ The session has already ended.", BuildQuickActionsButtons())
+	}
+
+	switch data.SubAction {
+	case "extend":
+		return b.extendSession(ctx, message, session.ID, data.Duration)
+	case "stop":
+		for i := range sessions {
+			if sessions[i].ID == session.ID {
+				return b.confirmStop(ctx, message, i)
+			}
+		}
+		return b.editMessage(message.Chat.ID, message.MessageID,
+			"⏰ *Time Almost Up*\n\n✅ The session has already ended.", BuildQuickActionsButtons())
+	default:
+		return b.editMessage(message.Chat.ID, message.MessageID,
+			"❌ Unknown warning action.", nil)
+	}
+}
+
 // handleDowntimeFlow handles downtime toggle callbacks
 func (b *Bot) handleDowntimeFlow(ctx context.Context, message *tgbotapi.Message, data *CallbackData) error {
 	b.logger.Info("Downtime flow",
@@ -816,7 +1358,8 @@ func (b *Bot) handleMoreMenu(ctx context.Context, message *tgbotapi.Message) err
 	text := `⚙️ *Additional Features*
 
 • 🌙 Skip Downtime - Temporarily skip downtime for all children
-• 🔓 Bypass Mode - Disable enforcement for specific devices`
+• 🔓 Bypass Mode - Disable enforcement for specific devices
+• 🚨 Lockdown - Stop all sessions and lock every device`
 
 	keyboard := BuildMoreMenuButtons(skipActive)
 	return b.editMessage(message.Chat.ID, message.MessageID, text, keyboard)
@@ -853,7 +1396,8 @@ Downtime will automatically resume tomorrow.`
 }
 
 // handleStopAll stops all active sessions
-func (b *Bot) handleStopAll(ctx context.Context, message *tgbotapi.Message) error {
+// Step 0 lists the sessions and asks for confirmation, step 1 stops them
+func (b *Bot) handleStopAll(ctx context.Context, message *tgbotapi.Message, data *CallbackData) error {
 	// Get active sessions
 	sessions, err := b.client.ListSessions(ctx, true, "")
 	if err != nil {
@@ -865,6 +1409,21 @@ func (b *Bot) handleStopAll(ctx context.Context, message *tgbotapi.Message) erro
 			"❌ No active sessions to stop.", BuildSessionsMenuButtons())
 	}
 
+	if data.Step == 0 {
+		children, err := b.client.ListChildren(ctx)
+		if err != nil {
+			return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), BuildSessionsMenuButtons())
+		}
+
+		childrenMap := make(map[string]Child)
+		for _, child := range children {
+			childrenMap[child.ID] = child
+		}
+
+		text := FormatStopAllConfirmation(sessions, childrenMap)
+		return b.editMessage(message.Chat.ID, message.MessageID, text, BuildStopAllConfirmButtons())
+	}
+
 	// Stop all sessions
 	stoppedCount := 0
 	for _, session := range sessions {
@@ -879,6 +1438,84 @@ func (b *Bot) handleStopAll(ctx context.Context, message *tgbotapi.Message) erro
 	return b.editMessage(message.Chat.ID, message.MessageID, text, BuildQuickActionsButtons())
 }
 
+// handleLockdownFlow handles the lockdown (panic button) flow
+// Step 0 shows the status, step 1 activates and step 2 lifts the lockdown
+func (b *Bot) handleLockdownFlow(ctx context.Context, message *tgbotapi.Message, user *tgbotapi.User, data *CallbackData) error {
+	switch data.Step {
+	case 1:
+		lockdown, err := b.client.ActivateLockdown(ctx, telegramActor(user), "")
+		if err != nil {
+			return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), BuildLockdownButtons(true))
+		}
+		return b.editMessage(message.Chat.ID, message.MessageID, FormatLockdownActivated(lockdown), BuildLockdownButtons(true))
+
+	case 2:
+		lockdown, err := b.client.LiftLockdown(ctx, telegramActor(user))
+		if err != nil {
+			return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), BuildLockdownButtons(false))
+		}
+		return b.editMessage(message.Chat.ID, message.MessageID, FormatLockdownLifted(lockdown), BuildQuickActionsButtons())
+
+	default:
+		status, err := b.client.GetLockdown(ctx)
+		if err != nil {
+			return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), BuildMoreMenuButtons(false))
+		}
+		return b.editMessage(message.Chat.ID, message.MessageID, FormatLockdownStatus(status), BuildLockdownButtons(status.Active))
+	}
+}
+
+// handleProfileFlow applies or dismisses a birthday limit profile transition
+// The transition is the pending one for the child at data.ChildIndex and the age in data.Duration.
+func (b *Bot) handleProfileFlow(ctx context.Context, message *tgbotapi.Message, user *tgbotapi.User, data *CallbackData) error {
+	b.logger.Info("Profile flow",
+		"sub_action", data.SubAction,
+		"child_index", data.ChildIndex,
+		"age", data.Duration,
+	)
+
+	children, err := b.client.ListChildren(ctx)
+	if err != nil {
+		return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), nil)
+	}
+	if data.ChildIndex < 0 || data.ChildIndex >= len(children) {
+		return b.editMessage(message.Chat.ID, message.MessageID,
+			"❌ Invalid child selection.", nil)
+	}
+	child := children[data.ChildIndex]
+
+	transitions, err := b.client.ListProfileTransitions(ctx, "pending")
+	if err != nil {
+		return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), nil)
+	}
+	var pending *ProfileTransition
+	for i := range transitions {
+		if transitions[i].ChildID == child.ID && transitions[i].Age == data.Duration {
+			pending = &transitions[i]
+			break
+		}
+	}
+	if pending == nil {
+		return b.editMessage(message.Chat.ID, message.MessageID,
+			"ℹ️ This profile change was already handled.", nil)
+	}
+
+	var resolved *ProfileTransition
+	switch data.SubAction {
+	case "confirm":
+		resolved, err = b.client.ConfirmProfileTransition(ctx, pending.ID, telegramActor(user))
+	case "dismiss":
+		resolved, err = b.client.DismissProfileTransition(ctx, pending.ID, telegramActor(user))
+	default:
+		return b.editMessage(message.Chat.ID, message.MessageID,
+			"❌ Unknown profile action.", nil)
+	}
+	if err != nil {
+		return b.editMessage(message.Chat.ID, message.MessageID, FormatError(err), nil)
+	}
+	return b.editMessage(message.Chat.ID, message.MessageID, FormatProfileTransitionResolved(child, resolved), nil)
+}
+
 // handleBypassFlow handles the bypass mode flow for devices
 func (b *Bot) handleBypassFlow(ctx context.Context, message *tgbotapi.Message, data *CallbackData) error {
 	b.logger.Info("Bypass flow",
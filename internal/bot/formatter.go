@@ -1,9 +1,13 @@
 package bot
 
 import (
+	"errors"
 	"fmt"
+	"metron/internal/api/apierror"
 	"strings"
 	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
 
 // timezone is the IANA timezone for formatting times (set during bot initialization)
@@ -28,33 +32,28 @@ func formatTime(t time.Time, layout string) string {
 }
 
 // FormatTodayStats formats today's statistics into a Telegram message
-func FormatTodayStats(stats *TodayStats, activeSessions []Session, childrenMap map[string]Child) string {
+func FormatTodayStats(status *ChildrenStatus) string {
 	var sb strings.Builder
 
 	sb.WriteString("📊 *Today's Screen Time Summary*\n")
-	sb.WriteString(fmt.Sprintf("Date: %s\n\n", stats.Date))
+	sb.WriteString(fmt.Sprintf("Date: %s\n\n", status.Date))
 
-	if len(stats.Children) == 0 {
+	if len(status.Children) == 0 {
 		sb.WriteString("No children configured yet.\n")
 		return sb.String()
 	}
 
-	// Group sessions by child for shared time calculation
-	childSessionMap := make(map[string][]Session)
-	for _, session := range activeSessions {
-		for _, childID := range session.ChildIDs {
-			childSessionMap[childID] = append(childSessionMap[childID], session)
-		}
-	}
+	// Shared sessions are listed under each of their children but counted once
+	activeSessions := make(map[string]bool)
 
-	for _, child := range stats.Children {
-		emoji := child.ChildEmoji
+	for _, child := range status.Children {
+		emoji := child.Emoji
 
 		// For now, we can't distinguish personal from shared in the API response
 		// This would require additional API endpoint or session history
 		// So we'll just show the total with a note about shared sessions
 
-		sb.WriteString(fmt.Sprintf("%s *%s*\n", emoji, child.ChildName))
+		sb.WriteString(fmt.Sprintf("%s *%s*\n", emoji, child.Name))
 		sb.WriteString(fmt.Sprintf("   Used: %d min / %d min (%.0f%%)\n",
 			child.TodayUsed, child.TodayLimit, float64(child.UsagePercent)))
 		sb.WriteString(fmt.Sprintf("   Remaining: %d min\n", child.TodayRemaining))
@@ -63,11 +62,21 @@ func FormatTodayStats(stats *TodayStats, activeSessions []Session, childrenMap m
 			sb.WriteString(fmt.Sprintf("   Sessions: %d\n", child.SessionsToday))
 		}
 
+		if child.TrackingPaused {
+			sb.WriteString("   🏖 Tracking paused")
+			if child.TrackingResumesAt != nil {
+				if resumesAt, err := time.Parse(time.RFC3339, *child.TrackingResumesAt); err == nil {
+					sb.WriteString(fmt.Sprintf(" until %s", formatTime(resumesAt, "Mon 02 Jan")))
+				}
+			}
+			sb.WriteString("\n")
+		}
+
 		// Show active sessions for this child
-		activeSess := childSessionMap[child.ChildID]
-		if len(activeSess) > 0 {
+		if len(child.ActiveSessions) > 0 {
 			sb.WriteString("   🟢 *Active:*\n")
-			for _, sess := range activeSess {
+			for _, sess := range child.ActiveSessions {
+				activeSessions[sess.ID] = true
 				endTime, remaining := calculateSessionEnd(sess)
 				deviceEmoji := getDeviceEmoji(sess.DeviceType)
 
@@ -86,8 +95,13 @@ func FormatTodayStats(stats *TodayStats, activeSessions []Session, childrenMap m
 		sb.WriteString("\n")
 	}
 
-	if stats.ActiveSessions > 0 {
-		sb.WriteString(fmt.Sprintf("🎮 Active sessions: %d\n", stats.ActiveSessions))
+	if budget := status.FamilyBudget; budget != nil {
+		sb.WriteString(fmt.Sprintf("👪 Family budget: %d min / %d min (%d min left)\n",
+			budget.UsedMinutes, budget.LimitMinutes, budget.RemainingMinutes))
+	}
+
+	if len(activeSessions) > 0 {
+		sb.WriteString(fmt.Sprintf("🎮 Active sessions: %d\n", len(activeSessions)))
 	}
 
 	return sb.String()
@@ -157,16 +171,75 @@ func FormatDevices(devices []Device) string {
 		if device.Capabilities.SupportsScheduling {
 			features = append(features, "scheduling")
 		}
+		if device.Capabilities.SupportsExtension {
+			features = append(features, "extensions")
+		}
+		if device.Capabilities.SupportsBreaks {
+			features = append(features, "breaks")
+		}
 
 		if len(features) > 0 {
 			sb.WriteString(fmt.Sprintf("   Features: %s\n", strings.Join(features, ", ")))
 		}
+		if lastSeen, err := time.Parse(time.RFC3339, device.LastSeenAt); err == nil {
+			sb.WriteString(fmt.Sprintf("   Last seen: %s\n", formatTime(lastSeen, "Mon 02 Jan 15:04")))
+		}
 		sb.WriteString("\n")
 	}
 
 	return sb.String()
 }
 
+// FormatDeviceOffline formats the alert sent when a device stops checking in
+func FormatDeviceOffline(device Device, lastSeen time.Time) string {
+	return fmt.Sprintf("⚠️ *Device Offline*\n\n%s *%s* has not checked in since %s (%d min ago).\n\n"+
+		"The agent may have been stopped or the device unplugged.",
+		resolveDeviceEmoji(device), deviceLabel(device), formatTime(lastSeen, "15:04"), int(time.Since(lastSeen).Minutes()))
+}
+
+// FormatDeviceOnline formats the notice sent when an offline device checks in again
+func FormatDeviceOnline(device Device) string {
+	return fmt.Sprintf("✅ *Device Back Online*\n\n%s *%s* is checking in again.", resolveDeviceEmoji(device), deviceLabel(device))
+}
+
+// FormatTamperAlert formats the security alert sent when an agent reports tampering
+func FormatTamperAlert(device Device, event TamperEvent) string {
+	var sb strings.Builder
+
+	sb.WriteString("🛡 *Security Alert*\n\n")
+	sb.WriteString(fmt.Sprintf("%s *%s*: %s\n", resolveDeviceEmoji(device), deviceLabel(device), tamperEventDescription(event.Type)))
+	if event.Details != "" {
+		sb.WriteString(fmt.Sprintf("Details: %s\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, event.Details)))
+	}
+	if occurredAt, err := time.Parse(time.RFC3339, event.OccurredAt); err == nil {
+		sb.WriteString(fmt.Sprintf("Time (device clock): %s\n", formatTime(occurredAt, "Mon 02 Jan 15:04")))
+	}
+
+	return sb.String()
+}
+
+// tamperEventDescription describes a tamper event type for parents
+func tamperEventDescription(eventType string) string {
+	switch eventType {
+	case "clock_change":
+		return "the clock was changed"
+	case "process_kill":
+		return "the Metron agent was killed"
+	case "safe_mode_boot":
+		return "booted into safe mode"
+	default:
+		return tgbotapi.EscapeText(tgbotapi.ModeMarkdown, eventType)
+	}
+}
+
+// deviceLabel returns the device name, falling back to its ID
+func deviceLabel(device Device) string {
+	if device.Name != "" {
+		return tgbotapi.EscapeText(tgbotapi.ModeMarkdown, device.Name)
+	}
+	return tgbotapi.EscapeText(tgbotapi.ModeMarkdown, device.ID)
+}
+
 // FormatActiveSessions formats active sessions for selection
 func FormatActiveSessions(sessions []Session, childrenMap map[string]Child) string {
 	var sb strings.Builder
@@ -200,7 +273,11 @@ func FormatActiveSessions(sessions []Session, childrenMap map[string]Child) stri
 
 		sb.WriteString(fmt.Sprintf("%d. %s *%s*\n", i+1, deviceEmoji, displayName))
 		sb.WriteString(fmt.Sprintf("   Children: %s\n", strings.Join(childNames, ", ")))
-		sb.WriteString(fmt.Sprintf("   Started: %s\n", formatTime(startTime, "15:04")))
+		sb.WriteString(fmt.Sprintf("   Started: %s", formatTime(startTime, "15:04")))
+		if by := formatInitiator(sess.Initiator, childrenMap); by != "" {
+			sb.WriteString(" by " + by)
+		}
+		sb.WriteString("\n")
 		sb.WriteString(fmt.Sprintf("   Ends %s (+%d min left)\n\n",
 			formatTime(endTime, "15:04"), remaining))
 	}
@@ -209,14 +286,23 @@ func FormatActiveSessions(sessions []Session, childrenMap map[string]Child) stri
 }
 
 // FormatSessionCreated formats a success message for session creation
+// Starts on a busy device may have been queued or joined its running session instead
 func FormatSessionCreated(session *Session, childrenMap map[string]Child) string {
+	if session.Queued {
+		return formatSessionQueued(session, childrenMap)
+	}
+
 	var sb strings.Builder
 
 	deviceEmoji := getDeviceEmoji(session.DeviceType)
 	displayName := getDeviceDisplayName(session.DeviceType)
 	endTime, _ := calculateSessionEnd(*session)
 
-	sb.WriteString("✅ *Session Started*\n\n")
+	if session.Merged {
+		sb.WriteString("✅ *Joined Running Session*\n\n")
+	} else {
+		sb.WriteString("✅ *Session Started*\n\n")
+	}
 	sb.WriteString(fmt.Sprintf("%s Device: *%s*\n", deviceEmoji, displayName))
 
 	// Get child names
@@ -234,20 +320,98 @@ func FormatSessionCreated(session *Session, childrenMap map[string]Child) string
 
 	sb.WriteString(fmt.Sprintf("⏱ Duration: %d minutes\n", session.ExpectedDuration))
 	sb.WriteString(fmt.Sprintf("🏁 Ends at: %s\n", formatTime(endTime, "15:04")))
+	sb.WriteString(formatCappedNote(session))
+
+	return sb.String()
+}
+
+// formatSessionQueued formats the message for a start waiting until its device is free
+func formatSessionQueued(session *Session, childrenMap map[string]Child) string {
+	var sb strings.Builder
+
+	sb.WriteString("⏳ *Session Queued*\n\n")
+	sb.WriteString(fmt.Sprintf("📺 Device *%s* is in use\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, session.DeviceID)))
+
+	var childNames []string
+	for _, childID := range session.ChildIDs {
+		if child, ok := childrenMap[childID]; ok {
+			childNames = append(childNames, child.Emoji+" "+child.Name)
+		}
+	}
+	if len(childNames) > 0 {
+		sb.WriteString(fmt.Sprintf("👶 Children: %s\n", strings.Join(childNames, ", ")))
+	}
+
+	sb.WriteString(fmt.Sprintf("⏱ Duration: %d minutes\n", session.Minutes))
+	sb.WriteString(fmt.Sprintf("🔢 Position in queue: %d\n", session.Position))
+	if startsAt, err := time.Parse(time.RFC3339, session.StartsAt); err == nil {
+		sb.WriteString(fmt.Sprintf("🏁 Starts around: %s\n", formatTime(startsAt, "15:04")))
+	}
 
 	return sb.String()
 }
 
+// formatInitiator describes who started a session (e.g., "parent (telegram:alice)", "🧒 Alice")
+// Returns an empty string for sessions started before it was recorded
+func formatInitiator(initiator *SessionInitiator, childrenMap map[string]Child) string {
+	if initiator == nil {
+		return ""
+	}
+	switch initiator.Type {
+	case "child":
+		if child, ok := childrenMap[initiator.ID]; ok {
+			return child.Emoji + " " + child.Name
+		}
+		return "child"
+	case "parent", "automation":
+		if initiator.ID == "" {
+			return initiator.Type
+		}
+		return initiator.Type + " (" + tgbotapi.EscapeText(tgbotapi.ModeMarkdown, initiator.ID) + ")"
+	default:
+		return "API"
+	}
+}
+
+// formatCappedNote explains why fewer minutes were granted than requested
+// Returns an empty string if the request was not capped
+func formatCappedNote(session *Session) string {
+	if !session.Capped {
+		return ""
+	}
+
+	reason := "not enough time left today"
+	switch session.CapReason {
+	case "extension_limit":
+		reason = "maximum per extension"
+	case "initiator_limit":
+		reason = "longest session allowed when started this way"
+	case "policy":
+		reason = fmt.Sprintf("longest session allowed by policy %s", session.CapPolicy)
+	case "family_budget":
+		reason = "family budget left today"
+	}
+
+	return fmt.Sprintf("\n⚠️ Granted %d of %d requested minutes (%s)\n",
+		session.GrantedMinutes, session.RequestedMinutes, reason)
+}
+
 // FormatSessionExtended formats a success message for session extension
 func FormatSessionExtended(session *Session, additionalMinutes int) string {
 	var sb strings.Builder
 
 	endTime, remaining := calculateSessionEnd(*session)
 
+	// Prefer the amount actually granted by the server
+	if session.GrantedMinutes > 0 {
+		additionalMinutes = session.GrantedMinutes
+	}
+
 	sb.WriteString("✅ *Session Extended*\n\n")
 	sb.WriteString(fmt.Sprintf("➕ Added: %d minutes\n", additionalMinutes))
 	sb.WriteString(fmt.Sprintf("⏱ Remaining: %d minutes\n", remaining))
 	sb.WriteString(fmt.Sprintf("🏁 New end time: %s\n", formatTime(endTime, "15:04")))
+	sb.WriteString(formatCappedNote(session))
 
 	return sb.String()
 }
@@ -294,6 +458,61 @@ func FormatChildrenAddedToSession(session *Session, addedChildIDs []string, chil
 	return sb.String()
 }
 
+// FormatChildRemovedFromSession formats the result of removing a child from a session
+func FormatChildRemovedFromSession(session *Session, removed Child, childrenMap map[string]Child) string {
+	var sb strings.Builder
+
+	deviceEmoji := getDeviceEmoji(session.DeviceType)
+	displayName := getDeviceDisplayName(session.DeviceType)
+	_, remaining := calculateSessionEnd(*session)
+
+	sb.WriteString("✅ *Child Removed from Session*\n\n")
+	sb.WriteString(fmt.Sprintf("%s Device: *%s*\n", deviceEmoji, displayName))
+	sb.WriteString(fmt.Sprintf("➖ Removed: %s %s\n", removed.Emoji, removed.Name))
+
+	// Show children still in session
+	var names []string
+	for _, childID := range session.ChildIDs {
+		if child, ok := childrenMap[childID]; ok {
+			names = append(names, child.Emoji+" "+child.Name)
+		}
+	}
+
+	if len(names) > 0 {
+		sb.WriteString(fmt.Sprintf("👶 Still in Session: %s\n", strings.Join(names, ", ")))
+	}
+
+	sb.WriteString(fmt.Sprintf("⏱ Remaining: %d minutes\n", remaining))
+
+	return sb.String()
+}
+
+// FormatSessionWarning formats the notice sent when a session's expiry warning goes out
+func FormatSessionWarning(session Session, childrenMap map[string]Child) string {
+	var sb strings.Builder
+
+	deviceEmoji := getDeviceEmoji(session.DeviceType)
+	displayName := getDeviceDisplayName(session.DeviceType)
+	endTime, remaining := calculateSessionEnd(session)
+
+	sb.WriteString("⏰ *Time Almost Up*\n\n")
+	sb.WriteString(fmt.Sprintf("%s Device: *%s*\n", deviceEmoji, displayName))
+
+	var childNames []string
+	for _, childID := range session.ChildIDs {
+		if child, ok := childrenMap[childID]; ok {
+			childNames = append(childNames, child.Emoji+" "+child.Name)
+		}
+	}
+	if len(childNames) > 0 {
+		sb.WriteString(fmt.Sprintf("👶 Children: %s\n", strings.Join(childNames, ", ")))
+	}
+
+	sb.WriteString(fmt.Sprintf("⏱ Remaining: %d minutes (ends at %s)\n", remaining, formatTime(endTime, "15:04")))
+
+	return sb.String()
+}
+
 // FormatSessionStopped formats a success message for stopping a session early
 func FormatSessionStopped(session *Session, childrenMap map[string]Child) string {
 	var sb strings.Builder
@@ -327,6 +546,91 @@ func FormatSessionStopped(session *Session, childrenMap map[string]Child) string
 	return sb.String()
 }
 
+// FormatStopConfirmation asks whether to stop a session, showing what would be stopped
+func FormatStopConfirmation(session *Session, childrenMap map[string]Child) string {
+	var sb strings.Builder
+
+	deviceEmoji := getDeviceEmoji(session.DeviceType)
+	displayName := getDeviceDisplayName(session.DeviceType)
+	endTime, remaining := calculateSessionEnd(*session)
+
+	startTime, err := time.Parse(time.RFC3339, session.StartTime)
+	if err != nil {
+		startTime = time.Now()
+	}
+
+	sb.WriteString("🛑 *Stop Session?*\n\n")
+	sb.WriteString(fmt.Sprintf("%s Device: *%s*\n", deviceEmoji, displayName))
+
+	var childNames []string
+	for _, childID := range session.ChildIDs {
+		if child, ok := childrenMap[childID]; ok {
+			childNames = append(childNames, child.Emoji+" "+child.Name)
+		}
+	}
+	if len(childNames) > 0 {
+		sb.WriteString(fmt.Sprintf("👶 Children: %s\n", strings.Join(childNames, ", ")))
+	}
+
+	sb.WriteString(fmt.Sprintf("▶️ Started: %s\n", formatTime(startTime, "15:04")))
+	sb.WriteString(fmt.Sprintf("🏁 Ends at: %s (%d min left)\n", formatTime(endTime, "15:04"), remaining))
+	sb.WriteString(fmt.Sprintf("\nThe device will be locked. You can undo the stop for %d minutes.", int(stopUndoWindow.Minutes())))
+
+	return sb.String()
+}
+
+// FormatStopAllConfirmation asks whether to stop all active sessions
+func FormatStopAllConfirmation(sessions []Session, childrenMap map[string]Child) string {
+	return FormatActiveSessions(sessions, childrenMap) +
+		fmt.Sprintf("🛑 *Stop all %d session(s)?* All devices will be locked.", len(sessions))
+}
+
+// FormatShareConfirmation asks whether to share a session with the children not in it yet
+func FormatShareConfirmation(session *Session, childrenToAdd []Child) string {
+	var sb strings.Builder
+
+	deviceEmoji := getDeviceEmoji(session.DeviceType)
+	displayName := getDeviceDisplayName(session.DeviceType)
+	_, remaining := calculateSessionEnd(*session)
+
+	sb.WriteString("👨‍👩‍👧 *Mark as Shared?*\n\n")
+	sb.WriteString(fmt.Sprintf("%s Device: *%s* (%d min left)\n\n", deviceEmoji, displayName, remaining))
+	sb.WriteString("These children will join the session and use their own time:\n")
+	for _, child := range childrenToAdd {
+		sb.WriteString(fmt.Sprintf("• %s %s\n", child.Emoji, child.Name))
+	}
+
+	return sb.String()
+}
+
+// FormatStopUndone formats a success message for restarting a stopped session
+func FormatStopUndone(session *Session, childrenMap map[string]Child) string {
+	var sb strings.Builder
+
+	deviceEmoji := getDeviceEmoji(session.DeviceType)
+	displayName := getDeviceDisplayName(session.DeviceType)
+	endTime, _ := calculateSessionEnd(*session)
+
+	sb.WriteString("↩️ *Stop Undone*\n\n")
+	sb.WriteString(fmt.Sprintf("%s Device: *%s*\n", deviceEmoji, displayName))
+
+	var childNames []string
+	for _, childID := range session.ChildIDs {
+		if child, ok := childrenMap[childID]; ok {
+			childNames = append(childNames, child.Emoji+" "+child.Name)
+		}
+	}
+	if len(childNames) > 0 {
+		sb.WriteString(fmt.Sprintf("👶 Children: %s\n", strings.Join(childNames, ", ")))
+	}
+
+	sb.WriteString(fmt.Sprintf("⏱ Restarted with the %d minutes that were left\n", session.ExpectedDuration))
+	sb.WriteString(fmt.Sprintf("🏁 Ends at: %s\n", formatTime(endTime, "15:04")))
+	sb.WriteString(formatCappedNote(session))
+
+	return sb.String()
+}
+
 // FormatRewardGranted formats a success message for granting a reward
 func FormatRewardGranted(childName, childEmoji string, response *GrantRewardResponse) string {
 	var sb strings.Builder
@@ -341,6 +645,33 @@ func FormatRewardGranted(childName, childEmoji string, response *GrantRewardResp
 	return sb.String()
 }
 
+// FormatChildLimits formats a child's daily limits and break rule for the limits screen
+// changed describes the change just made, if any
+func FormatChildLimits(child Child, changed string) string {
+	var sb strings.Builder
+
+	sb.WriteString("📏 *Daily Limits*\n\n")
+	sb.WriteString(fmt.Sprintf("%s *%s*\n\n", child.Emoji, child.Name))
+	sb.WriteString(fmt.Sprintf("📅 Weekdays: %d min\n", child.WeekdayLimit))
+	sb.WriteString(fmt.Sprintf("🎉 Weekends: %d min\n", child.WeekendLimit))
+
+	if child.BreakRule != nil {
+		sb.WriteString(fmt.Sprintf("☕ Break: every %d min, %d min rest\n",
+			child.BreakRule.BreakAfterMinutes,
+			child.BreakRule.BreakDurationMinutes))
+	} else {
+		sb.WriteString("☕ Break: none\n")
+	}
+
+	if changed != "" {
+		sb.WriteString(fmt.Sprintf("\n✅ %s\n", changed))
+	}
+
+	sb.WriteString(fmt.Sprintf("\nChanges apply from today. Adjust in steps of %d minutes:", limitStep))
+
+	return sb.String()
+}
+
 // FormatFineApplied formats a success message for applying a fine
 func FormatFineApplied(childName, childEmoji string, response *DeductFineResponse) string {
 	var sb strings.Builder
@@ -355,9 +686,227 @@ func FormatFineApplied(childName, childEmoji string, response *DeductFineRespons
 	return sb.String()
 }
 
+// FormatPermissionDenied formats the reply to an action the user's role does not allow
+func FormatPermissionDenied(role string) string {
+	return fmt.Sprintf("🔒 *Not Allowed*\n\nYour role (%s) does not allow this. Ask a parent with more access.", role)
+}
+
 // FormatError formats an error message
+// API errors are matched by code so the message can explain what to do next
 func FormatError(err error) string {
-	return fmt.Sprintf("❌ *Error*\n\n%s", err.Error())
+	var reqErr *RequestError
+	if !errors.As(err, &reqErr) {
+		return fmt.Sprintf("❌ *Error*\n\n%s", err.Error())
+	}
+
+	switch apierror.Code(reqErr.Code) {
+	case apierror.InsufficientTime:
+		return fmt.Sprintf("⏳ *Not Enough Time*\n\n%s", reqErr.Message)
+	case apierror.ExtensionTooSoon:
+		return "⏳ *Too Soon*\n\nThis session was just extended. Try again in 30 seconds."
+	case apierror.DowntimeActive:
+		return "🌙 *Downtime*\n\nSessions cannot be extended during downtime."
+	case apierror.DeviceNotAllowed:
+		return "🚫 *Device Not Allowed*\n\nThis child is not allowed to use that device."
+	case apierror.PolicyBlocked:
+		return fmt.Sprintf("📏 *Session Policy*\n\n%s", reqErr.Message)
+	case apierror.FamilyBudgetUsedUp:
+		return "👪 *Family Budget Used Up*\n\nThe screen time shared by all children is used up for today."
+	case apierror.DeviceBusy:
+		return "📺 *Device Busy*\n\nAnother session is running on this device. Join it instead, or try again when it ends."
+	case apierror.LockdownActive:
+		return "🚨 *Lockdown Active*\n\nNo sessions can be started or extended. Use /unlock to lift the lockdown."
+	case apierror.TrackingAlreadyPaused:
+		return "🏖 *Already Paused*\n\nTracking is already paused. Use `/vacation off` to resume it."
+	case apierror.TrackingNotPaused:
+		return "ℹ️ *Not Paused*\n\nTracking is not paused."
+	case apierror.SessionNotFound, apierror.SessionNotActive:
+		return "ℹ️ *Session Ended*\n\nThis session is no longer active."
+	case apierror.ChildNotFound:
+		return "❌ *Error*\n\nChild not found. The list may be out of date, try again."
+	case apierror.ChildNotInSession:
+		return "❌ *Error*\n\nThis child is no longer in the session. The list may be out of date, try again."
+	case apierror.LastChildInSession:
+		return "ℹ️ *Last Child*\n\nThe only child in a session cannot be removed. Stop the session instead."
+	case apierror.Unauthorized:
+		return "❌ *Error*\n\nThe bot is not authorized by the Metron API. Check the API key."
+	case "":
+		return fmt.Sprintf("❌ *Error*\n\n%s", reqErr.Error())
+	default:
+		return fmt.Sprintf("❌ *Error*\n\n%s", reqErr.Message)
+	}
+}
+
+// FormatLockdownStatus formats the lockdown screen shown before confirming
+func FormatLockdownStatus(status *LockdownStatus) string {
+	if status.Active && status.Lockdown != nil {
+		return "🚨 *Lockdown Active*\n\n" + formatLockdownDetails(status.Lockdown) +
+			"\nNo sessions can be started until the lockdown is lifted."
+	}
+
+	return "🚨 *Lockdown*\n\n" +
+		"This immediately:\n" +
+		"• stops all active sessions\n" +
+		"• locks all agent-controlled devices\n" +
+		"• blocks new sessions until lifted\n\n" +
+		"Tip: use `/lockdown reason` to record why."
+}
+
+// FormatLockdownActivated formats the confirmation after a lockdown starts
+func FormatLockdownActivated(lockdown *Lockdown) string {
+	return "🚨 *Lockdown Activated*\n\n" + formatLockdownDetails(lockdown) +
+		fmt.Sprintf("Stopped %d active session(s).\n\nUse /unlock to lift the lockdown.", lockdown.StoppedSessions)
+}
+
+// FormatLockdownLifted formats the confirmation after a lockdown is lifted
+func FormatLockdownLifted(lockdown *Lockdown) string {
+	text := "🔓 *Lockdown Lifted*\n\nSessions can be started again."
+	if startedAt, err := time.Parse(time.RFC3339, lockdown.StartedAt); err == nil {
+		text += fmt.Sprintf("\nLockdown lasted %d min.", int(time.Since(startedAt).Minutes()))
+	}
+	return text
+}
+
+// FormatTrackingPaused formats the confirmation after tracking is paused (vacation mode)
+func FormatTrackingPaused(pause *TrackingPause) string {
+	var sb strings.Builder
+
+	sb.WriteString("🏖 *Vacation Mode On*\n\n")
+	sb.WriteString("Tracking is paused: usage is not recorded, limits and downtime are not enforced, ")
+	sb.WriteString("and agent-controlled devices stay unlocked.\n\n")
+	if pause.Reason != "" {
+		sb.WriteString(fmt.Sprintf("Reason: %s\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, pause.Reason)))
+	}
+	sb.WriteString(formatTrackingResume(pause.ResumesAt))
+	sb.WriteString("\nUse `/vacation off` to resume tracking now.")
+
+	return sb.String()
+}
+
+// FormatTrackingResumed formats the confirmation after tracking is resumed
+func FormatTrackingResumed(pause *TrackingPause) string {
+	text := "▶️ *Vacation Mode Off*\n\nTracking and limits apply again."
+	if startedAt, err := time.Parse(time.RFC3339, pause.StartedAt); err == nil {
+		text += fmt.Sprintf("\nTracking was paused for %s.", formatPauseDuration(time.Since(startedAt)))
+	}
+	return text
+}
+
+// FormatProfileTransition formats a birthday proposal to move a child to a new limit profile
+func FormatProfileTransition(child Child, transition ProfileTransition) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("🎂 *%s %s turned %d*\n\n", child.Emoji, child.Name, transition.Age))
+	sb.WriteString(fmt.Sprintf("The *%s* limit profile now applies:\n", transition.Profile))
+	sb.WriteString(fmt.Sprintf("   Weekdays: %d → %d min\n", child.WeekdayLimit, transition.WeekdayLimit))
+	sb.WriteString(fmt.Sprintf("   Weekends: %d → %d min\n", child.WeekendLimit, transition.WeekendLimit))
+	sb.WriteString("\nApply the new limits from today?")
+
+	return sb.String()
+}
+
+// FormatUsageAlert formats the notice sent when a child reaches a share of the day's time
+func FormatUsageAlert(child Child, alert UsageAlert) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("⏳ *%s %s used %d%% of today's time*\n\n", child.Emoji, tgbotapi.EscapeText(tgbotapi.ModeMarkdown, child.Name), alert.Threshold))
+	sb.WriteString(fmt.Sprintf("Used: %d of %d min\n", alert.UsedMinutes, alert.LimitMinutes))
+	if alert.RemainingMinutes > 0 {
+		sb.WriteString(fmt.Sprintf("Left: %d min", alert.RemainingMinutes))
+	} else {
+		sb.WriteString("No time left for today.")
+	}
+
+	return sb.String()
+}
+
+// FormatProfileTransitionResolved formats the outcome of a confirmed or dismissed profile transition
+func FormatProfileTransitionResolved(child Child, transition *ProfileTransition) string {
+	if transition.Status == "confirmed" {
+		return fmt.Sprintf("✅ *Limits Updated*\n\n%s %s is now on the *%s* profile: %d min on weekdays, %d min on weekends.",
+			child.Emoji, child.Name, transition.Profile, transition.WeekdayLimit, transition.WeekendLimit)
+	}
+	return fmt.Sprintf("👌 *Limits Kept*\n\n%s %s keeps %d min on weekdays and %d min on weekends.",
+		child.Emoji, child.Name, child.WeekdayLimit, child.WeekendLimit)
+}
+
+// FormatWeeklyDigest formats the weekly usage digest with each child's trend versus the previous week
+func FormatWeeklyDigest(report *TrendsReport) string {
+	var sb strings.Builder
+
+	sb.WriteString("📈 *Weekly Screen Time Digest*\n")
+
+	if len(report.Children) == 0 {
+		sb.WriteString("\nNo children configured yet.\n")
+		return sb.String()
+	}
+
+	if to, err := time.Parse("2006-01-02", report.Children[0].To); err == nil {
+		sb.WriteString(fmt.Sprintf("Week: %s – %s\n", to.AddDate(0, 0, -6).Format("Mon 02 Jan"), to.Format("Mon 02 Jan")))
+	}
+
+	for _, child := range report.Children {
+		sb.WriteString(fmt.Sprintf("\n%s *%s*\n", child.ChildEmoji, child.ChildName))
+		sb.WriteString(fmt.Sprintf("   This week: %.0f min/day (%.0f%% of limit)%s\n",
+			child.Week.AverageMinutes, child.Week.LimitPercent, formatTrend(child)))
+		if child.PreviousWeek.Days > 0 {
+			sb.WriteString(fmt.Sprintf("   Last week: %.0f min/day\n", child.PreviousWeek.AverageMinutes))
+		}
+		sb.WriteString(fmt.Sprintf("   30 days: %.0f min/day (weekdays %.0f, weekends %.0f)\n",
+			child.Month.AverageMinutes, child.Weekdays.AverageMinutes, child.Weekends.AverageMinutes))
+	}
+
+	return sb.String()
+}
+
+// formatTrend formats the week-over-week change as an arrow and a percentage (empty without a previous week)
+func formatTrend(child ChildTrends) string {
+	if child.WeekChangePercent == nil {
+		return ""
+	}
+	switch child.Direction {
+	case "up":
+		return fmt.Sprintf(" ↑ %.0f%%", *child.WeekChangePercent)
+	case "down":
+		return fmt.Sprintf(" ↓ %.0f%%", -*child.WeekChangePercent)
+	default:
+		return " →"
+	}
+}
+
+// formatTrackingResume formats when a tracking pause ends
+func formatTrackingResume(resumesAt *string) string {
+	if resumesAt == nil {
+		return "Resumes: when turned off\n"
+	}
+	t, err := time.Parse(time.RFC3339, *resumesAt)
+	if err != nil {
+		return ""
+	}
+	return fmt.Sprintf("Resumes: %s\n", formatTime(t, "Mon 02 Jan 15:04"))
+}
+
+// formatPauseDuration formats how long tracking was paused, in days once it exceeds one
+func formatPauseDuration(d time.Duration) string {
+	if d >= 24*time.Hour {
+		return fmt.Sprintf("%d day(s)", int(d.Hours()/24))
+	}
+	return fmt.Sprintf("%d min", int(d.Minutes()))
+}
+
+// formatLockdownDetails formats who triggered a lockdown, when and why
+func formatLockdownDetails(lockdown *Lockdown) string {
+	var sb strings.Builder
+
+	if startedAt, err := time.Parse(time.RFC3339, lockdown.StartedAt); err == nil {
+		sb.WriteString(fmt.Sprintf("Since: %s\n", formatTime(startedAt, "15:04")))
+	}
+	sb.WriteString(fmt.Sprintf("By: %s\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, lockdown.TriggeredBy)))
+	if lockdown.Reason != "" {
+		sb.WriteString(fmt.Sprintf("Reason: %s\n", tgbotapi.EscapeText(tgbotapi.ModeMarkdown, lockdown.Reason)))
+	}
+
+	return sb.String()
 }
 
 // calculateSessionEnd calculates when a session will end and how many minutes remain
@@ -2,6 +2,10 @@ package bot
 
 import (
 	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
 )
@@ -15,9 +19,15 @@ I help you manage your children's screen time across all devices.
 *Available Commands:*
 
 📊 /today - View today's screen time summary
+📈 /weekly - View this week's trends versus last week
 👶 /children - List all children and toggle downtime
+📏 /limits - View and adjust children's daily limits
 📺 /devices - List available devices
+📱 /dashboard - Open the live dashboard
 🔓 /bypass - Enable/disable bypass mode for devices
+🚨 /lockdown [reason] - Stop everything and lock all devices
+🔓 /unlock - Lift the lockdown
+🏖 /vacation [days] [reason] - Pause tracking for everyone (/vacation off to resume)
 
 *Quick Actions:*`
 
@@ -27,31 +37,24 @@ I help you manage your children's screen time across all devices.
 
 // handleToday handles the /today command
 func (b *Bot) handleToday(ctx context.Context, message *tgbotapi.Message) error {
-	// Get today's stats
-	stats, err := b.client.GetTodayStats(ctx)
+	// Today's status and active sessions of all children
+	status, err := b.client.GetChildrenStatus(ctx)
 	if err != nil {
 		return b.sendMessage(message.Chat.ID, FormatError(err), BuildQuickActionsButtons())
 	}
 
-	// Get active sessions
-	sessions, err := b.client.ListSessions(ctx, true, "")
-	if err != nil {
-		return b.sendMessage(message.Chat.ID, FormatError(err), BuildQuickActionsButtons())
-	}
+	text := FormatTodayStats(status)
+	return b.sendMessage(message.Chat.ID, text, BuildQuickActionsButtons())
+}
 
-	// Get children for mapping
-	children, err := b.client.ListChildren(ctx)
+// handleWeekly handles the /weekly command
+func (b *Bot) handleWeekly(ctx context.Context, message *tgbotapi.Message) error {
+	report, err := b.client.GetTrends(ctx)
 	if err != nil {
 		return b.sendMessage(message.Chat.ID, FormatError(err), BuildQuickActionsButtons())
 	}
 
-	childrenMap := make(map[string]Child)
-	for _, child := range children {
-		childrenMap[child.ID] = child
-	}
-
-	text := FormatTodayStats(stats, sessions, childrenMap)
-	return b.sendMessage(message.Chat.ID, text, BuildQuickActionsButtons())
+	return b.sendMessage(message.Chat.ID, FormatWeeklyDigest(report), BuildQuickActionsButtons())
 }
 
 // handleChildren handles the /children command
@@ -78,6 +81,18 @@ func (b *Bot) handleDevices(ctx context.Context, message *tgbotapi.Message) erro
 	return b.sendMessage(message.Chat.ID, text, BuildQuickActionsButtons())
 }
 
+// handleDashboard handles the /dashboard command - sends the button opening the web app dashboard
+func (b *Bot) handleDashboard(ctx context.Context, message *tgbotapi.Message) error {
+	if b.config.Telegram.WebAppURL == "" {
+		return b.sendMessage(message.Chat.ID,
+			"📱 The dashboard is not set up. Set `telegram.web_app_url` in the bot configuration.", nil)
+	}
+
+	return b.sendWebAppButton(message.Chat.ID,
+		"📱 *Dashboard*\n\nLive usage, session countdowns and quick actions for all children.",
+		"📱 Open Dashboard", b.config.Telegram.WebAppURL)
+}
+
 // handleNewSession handles the /newsession command (step 0)
 func (b *Bot) handleNewSession(ctx context.Context, message *tgbotapi.Message) error {
 	// Get children list
@@ -188,6 +203,24 @@ func (b *Bot) handleReward(ctx context.Context, message *tgbotapi.Message) error
 	return b.sendMessage(message.Chat.ID, text, keyboard)
 }
 
+// handleLimits handles the /limits command - shows and adjusts children's daily limits
+func (b *Bot) handleLimits(ctx context.Context, message *tgbotapi.Message) error {
+	children, err := b.client.ListChildren(ctx)
+	if err != nil {
+		return b.sendMessage(message.Chat.ID, FormatError(err), BuildQuickActionsButtons())
+	}
+
+	if len(children) == 0 {
+		return b.sendMessage(message.Chat.ID,
+			"❌ No children configured. Please add children first.", BuildQuickActionsButtons())
+	}
+
+	text := "📏 *Daily Limits*\n\n👶 Select child"
+	keyboard := BuildLimitsChildrenButtons(children)
+
+	return b.sendMessage(message.Chat.ID, text, keyboard)
+}
+
 // handleFine handles the /fine command - allows applying time fines to children
 func (b *Bot) handleFine(ctx context.Context, message *tgbotapi.Message) error {
 	// Get children list
@@ -240,3 +273,77 @@ func (b *Bot) handleBypass(ctx context.Context, message *tgbotapi.Message) error
 	keyboard := BuildBypassDevicesButtons(devicesWithBypass)
 	return b.sendMessage(message.Chat.ID, text, keyboard)
 }
+
+// handleLockdown handles the /lockdown command (panic button)
+// Usage: /lockdown [reason]
+func (b *Bot) handleLockdown(ctx context.Context, message *tgbotapi.Message) error {
+	reason := strings.TrimSpace(message.CommandArguments())
+
+	lockdown, err := b.client.ActivateLockdown(ctx, telegramActor(message.From), reason)
+	if err != nil {
+		return b.sendMessage(message.Chat.ID, FormatError(err), BuildQuickActionsButtons())
+	}
+
+	return b.sendMessage(message.Chat.ID, FormatLockdownActivated(lockdown), BuildQuickActionsButtons())
+}
+
+// handleUnlock handles the /unlock command
+func (b *Bot) handleUnlock(ctx context.Context, message *tgbotapi.Message) error {
+	lockdown, err := b.client.LiftLockdown(ctx, telegramActor(message.From))
+	if err != nil {
+		return b.sendMessage(message.Chat.ID, FormatError(err), BuildQuickActionsButtons())
+	}
+
+	return b.sendMessage(message.Chat.ID, FormatLockdownLifted(lockdown), BuildQuickActionsButtons())
+}
+
+// handleVacation handles the /vacation command (tracking pause)
+// Usage: /vacation [days] [reason] pauses tracking for everyone, optionally until the start of
+// the day that many days from now; /vacation off resumes tracking
+func (b *Bot) handleVacation(ctx context.Context, message *tgbotapi.Message) error {
+	args := strings.Fields(message.CommandArguments())
+
+	if len(args) > 0 && strings.EqualFold(args[0], "off") {
+		pause, err := b.client.ResumeTracking(ctx, "", telegramActor(message.From))
+		if err != nil {
+			return b.sendMessage(message.Chat.ID, FormatError(err), BuildQuickActionsButtons())
+		}
+		return b.sendMessage(message.Chat.ID, FormatTrackingResumed(pause), BuildQuickActionsButtons())
+	}
+
+	var resumesAt *time.Time
+	if len(args) > 0 {
+		if days, err := strconv.Atoi(args[0]); err == nil {
+			if days <= 0 {
+				return b.sendMessage(message.Chat.ID,
+					"❌ Days must be a positive number, e.g. `/vacation 7 grandma`", nil)
+			}
+			now := time.Now()
+			if timezone != nil {
+				now = now.In(timezone)
+			}
+			resume := time.Date(now.Year(), now.Month(), now.Day()+days, 0, 0, 0, 0, now.Location())
+			resumesAt = &resume
+			args = args[1:]
+		}
+	}
+	reason := strings.Join(args, " ")
+
+	pause, err := b.client.PauseTracking(ctx, "", telegramActor(message.From), reason, resumesAt)
+	if err != nil {
+		return b.sendMessage(message.Chat.ID, FormatError(err), BuildQuickActionsButtons())
+	}
+
+	return b.sendMessage(message.Chat.ID, FormatTrackingPaused(pause), BuildQuickActionsButtons())
+}
+
+// telegramActor identifies a Telegram user for audit fields like triggered_by
+func telegramActor(user *tgbotapi.User) string {
+	if user == nil {
+		return "telegram"
+	}
+	if user.UserName != "" {
+		return "telegram:" + user.UserName
+	}
+	return fmt.Sprintf("telegram:%d", user.ID)
+}
@@ -0,0 +1,420 @@
+package bot
+
+import (
+	"context"
+	"time"
+)
+
+// deviceMonitorInterval is how often the device monitor checks devices and tamper events
+const deviceMonitorInterval = time.Minute
+
+// sessionMonitorInterval is how often the session monitor checks for new expiry warnings
+const sessionMonitorInterval = 30 * time.Second
+
+// usageAlertMonitorInterval is how often the usage alert monitor checks for new usage alerts
+const usageAlertMonitorInterval = 30 * time.Second
+
+// profileMonitorInterval is how often the profile monitor checks for new birthday profile transitions
+const profileMonitorInterval = 5 * time.Minute
+
+// The weekly digest is sent on Monday morning in the bot's timezone
+const (
+	weeklyDigestDay  = time.Monday
+	weeklyDigestHour = 9
+)
+
+// deviceMonitorState is what the device monitor remembers between checks
+type deviceMonitorState struct {
+	offline     map[string]bool // Devices already reported offline
+	tamperSince time.Time       // Receive time of the last tamper event alerted on
+}
+
+// RunDeviceMonitor alerts allowed users about device problems until ctx is cancelled:
+//   - tamper events reported by agents (clock changes, kill attempts, safe-mode boots)
+//   - devices that stop checking in for offlineAfter, and again when they come back
+//     (only devices with a last-seen time are watched; 0 disables offline alerts)
+func (b *Bot) RunDeviceMonitor(ctx context.Context, offlineAfter time.Duration) {
+	b.logger.Info("Device monitor started", "offline_after", offlineAfter)
+
+	// Events received before the bot started are not alerted on
+	state := &deviceMonitorState{
+		offline:     make(map[string]bool),
+		tamperSince: time.Now(),
+	}
+	ticker := time.NewTicker(deviceMonitorInterval)
+	defer ticker.Stop()
+
+	for {
+		b.checkDevices(ctx, offlineAfter, state)
+
+		select {
+		case <-ctx.Done():
+			b.logger.Info("Device monitor stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkDevices alerts on new tamper events and on devices whose last-seen time crossed the threshold
+func (b *Bot) checkDevices(ctx context.Context, offlineAfter time.Duration, state *deviceMonitorState) {
+	devices, err := b.client.ListDevices(ctx)
+	if err != nil {
+		b.logger.Warn("Device monitor failed to list devices", "error", err)
+		return
+	}
+
+	b.checkTamperEvents(ctx, devices, state)
+
+	if offlineAfter <= 0 {
+		return
+	}
+
+	now := time.Now()
+	for _, device := range devices {
+		if device.LastSeenAt == "" {
+			continue
+		}
+		lastSeen, err := time.Parse(time.RFC3339, device.LastSeenAt)
+		if err != nil {
+			continue
+		}
+
+		stale := now.Sub(lastSeen) >= offlineAfter
+		switch {
+		case stale && !state.offline[device.ID]:
+			state.offline[device.ID] = true
+			b.logger.Warn("Device stopped checking in",
+				"device_id", device.ID,
+				"last_seen_at", device.LastSeenAt)
+			b.notifyAllowedUsers(FormatDeviceOffline(device, lastSeen))
+		case !stale && state.offline[device.ID]:
+			delete(state.offline, device.ID)
+			b.logger.Info("Device checking in again", "device_id", device.ID)
+			b.notifyAllowedUsers(FormatDeviceOnline(device))
+		}
+	}
+}
+
+// checkTamperEvents sends a security alert for each tamper event received since the last check
+func (b *Bot) checkTamperEvents(ctx context.Context, devices []Device, state *deviceMonitorState) {
+	events, err := b.client.ListTamperEvents(ctx, state.tamperSince)
+	if err != nil {
+		b.logger.Warn("Device monitor failed to list tamper events", "error", err)
+		return
+	}
+
+	byID := make(map[string]Device, len(devices))
+	for _, device := range devices {
+		byID[device.ID] = device
+	}
+
+	for _, event := range events {
+		device, ok := byID[event.DeviceID]
+		if !ok {
+			device = Device{ID: event.DeviceID}
+		}
+
+		b.logger.Warn("Tamper event",
+			"event_id", event.ID,
+			"device_id", event.DeviceID,
+			"type", event.Type)
+		b.notifyAllowedUsers(FormatTamperAlert(device, event))
+
+		if receivedAt, err := time.Parse(time.RFC3339, event.ReceivedAt); err == nil && receivedAt.After(state.tamperSince) {
+			state.tamperSince = receivedAt
+		}
+	}
+}
+
+// RunSessionMonitor forwards session expiry warnings to allowed users until ctx is cancelled
+// Each warning carries buttons to extend the session or stop it right away. A session is
+// warned again after an extension, since the server clears its warning time.
+func (b *Bot) RunSessionMonitor(ctx context.Context) {
+	b.logger.Info("Session monitor started")
+
+	// Warnings sent before the bot started are not forwarded
+	warned := b.warnedSessions(ctx)
+	ticker := time.NewTicker(sessionMonitorInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			b.logger.Info("Session monitor stopped")
+			return
+		case <-ticker.C:
+		}
+
+		b.checkSessionWarnings(ctx, warned)
+	}
+}
+
+// warnedSessions returns the warning time of every active session that was warned
+func (b *Bot) warnedSessions(ctx context.Context) map[string]string {
+	warned := make(map[string]string)
+	sessions, err := b.client.ListSessions(ctx, true, "")
+	if err != nil {
+		b.logger.Warn("Session monitor failed to list sessions", "error", err)
+		return warned
+	}
+	for _, session := range sessions {
+		if session.WarningSentAt != "" {
+			warned[session.ID] = session.WarningSentAt
+		}
+	}
+	return warned
+}
+
+// checkSessionWarnings notifies allowed users of warnings sent since the last check
+func (b *Bot) checkSessionWarnings(ctx context.Context, warned map[string]string) {
+	sessions, err := b.client.ListSessions(ctx, true, "")
+	if err != nil {
+		b.logger.Warn("Session monitor failed to list sessions", "error", err)
+		return
+	}
+
+	active := make(map[string]bool, len(sessions))
+	var fresh []Session
+	for _, session := range sessions {
+		active[session.ID] = true
+		if session.WarningSentAt != "" && warned[session.ID] != session.WarningSentAt {
+			warned[session.ID] = session.WarningSentAt
+			fresh = append(fresh, session)
+		}
+	}
+
+	// Forget sessions that ended
+	for id := range warned {
+		if !active[id] {
+			delete(warned, id)
+		}
+	}
+
+	if len(fresh) == 0 {
+		return
+	}
+
+	children, err := b.client.ListChildren(ctx)
+	if err != nil {
+		b.logger.Warn("Session monitor failed to list children", "error", err)
+	}
+	childrenMap := make(map[string]Child, len(children))
+	for _, child := range children {
+		childrenMap[child.ID] = child
+	}
+
+	for _, session := range fresh {
+		maxMinutes, err := b.minRemainingMinutes(ctx, session.ChildIDs, session.RemainingMinutes)
+		if err != nil {
+			// The server caps the extension anyway
+			maxMinutes = maxExtensionMinutes
+		}
+		if maxMinutes > maxExtensionMinutes {
+			maxMinutes = maxExtensionMinutes
+		}
+
+		b.logger.Info("Forwarding session warning",
+			"session_id", session.ID,
+			"device_id", session.DeviceID,
+			"warning_sent_at", session.WarningSentAt)
+		b.notifyAllowedUsersWithButtons(FormatSessionWarning(session, childrenMap), BuildWarningButtons(session.DeviceID, maxMinutes))
+	}
+}
+
+// RunWeeklyDigest sends allowed users the weekly usage digest every Monday morning until ctx is cancelled
+func (b *Bot) RunWeeklyDigest(ctx context.Context) {
+	b.logger.Info("Weekly digest started")
+
+	for {
+		next := nextWeeklyDigest(time.Now())
+		timer := time.NewTimer(time.Until(next))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			b.logger.Info("Weekly digest stopped")
+			return
+		case <-timer.C:
+		}
+
+		report, err := b.client.GetTrends(ctx)
+		if err != nil {
+			b.logger.Warn("Weekly digest failed to get trends", "error", err)
+			continue
+		}
+		b.logger.Info("Sending weekly digest", "children", len(report.Children))
+		b.notifyAllowedUsers(FormatWeeklyDigest(report))
+	}
+}
+
+// nextWeeklyDigest returns the first digest time after now
+func nextWeeklyDigest(now time.Time) time.Time {
+	loc := timezone
+	if loc == nil {
+		loc = time.Local
+	}
+	now = now.In(loc)
+
+	days := (int(weeklyDigestDay) - int(now.Weekday()) + 7) % 7
+	next := time.Date(now.Year(), now.Month(), now.Day()+days, weeklyDigestHour, 0, 0, 0, loc)
+	if !next.After(now) {
+		next = next.AddDate(0, 0, 7)
+	}
+	return next
+}
+
+// RunProfileMonitor asks allowed users to confirm birthday limit profile transitions until ctx is cancelled
+// Each pending transition is sent once per bot run, with buttons to apply the new limits or keep the current ones.
+func (b *Bot) RunProfileMonitor(ctx context.Context) {
+	b.logger.Info("Profile monitor started")
+
+	notified := make(map[string]bool)
+	b.checkProfileTransitions(ctx, notified)
+
+	ticker := time.NewTicker(profileMonitorInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			b.logger.Info("Profile monitor stopped")
+			return
+		case <-ticker.C:
+		}
+
+		b.checkProfileTransitions(ctx, notified)
+	}
+}
+
+// checkProfileTransitions notifies allowed users of pending transitions not sent yet
+func (b *Bot) checkProfileTransitions(ctx context.Context, notified map[string]bool) {
+	transitions, err := b.client.ListProfileTransitions(ctx, "pending")
+	if err != nil {
+		b.logger.Warn("Profile monitor failed to list transitions", "error", err)
+		return
+	}
+
+	pending := make(map[string]bool, len(transitions))
+	var fresh []ProfileTransition
+	for _, transition := range transitions {
+		pending[transition.ID] = true
+		if !notified[transition.ID] {
+			fresh = append(fresh, transition)
+		}
+	}
+
+	// Forget transitions resolved elsewhere
+	for id := range notified {
+		if !pending[id] {
+			delete(notified, id)
+		}
+	}
+
+	if len(fresh) == 0 {
+		return
+	}
+
+	children, err := b.client.ListChildren(ctx)
+	if err != nil {
+		b.logger.Warn("Profile monitor failed to list children", "error", err)
+		return
+	}
+
+	for _, transition := range fresh {
+		// Buttons refer to the child by index, as in the other flows
+		childIndex := -1
+		for i, child := range children {
+			if child.ID == transition.ChildID {
+				childIndex = i
+				break
+			}
+		}
+		if childIndex < 0 {
+			continue
+		}
+
+		notified[transition.ID] = true
+		b.logger.Info("Forwarding profile transition",
+			"transition_id", transition.ID,
+			"child_id", transition.ChildID,
+			"profile", transition.Profile)
+		b.notifyAllowedUsersWithButtons(FormatProfileTransition(children[childIndex], transition),
+			BuildProfileTransitionButtons(childIndex, transition.Age))
+	}
+}
+
+// RunUsageAlertMonitor notifies allowed users of usage alerts until ctx is cancelled
+// The server records an alert when a child reaches one of its thresholds (80% of the day's
+// time by default); alerts recorded before the bot started are not sent.
+func (b *Bot) RunUsageAlertMonitor(ctx context.Context) {
+	b.logger.Info("Usage alert monitor started")
+
+	since := time.Now()
+	ticker := time.NewTicker(usageAlertMonitorInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			b.logger.Info("Usage alert monitor stopped")
+			return
+		case <-ticker.C:
+		}
+
+		since = b.checkUsageAlerts(ctx, since)
+	}
+}
+
+// checkUsageAlerts notifies allowed users of the alerts created after since
+// and returns the creation time of the last alert sent
+func (b *Bot) checkUsageAlerts(ctx context.Context, since time.Time) time.Time {
+	alerts, err := b.client.ListUsageAlerts(ctx, since)
+	if err != nil {
+		b.logger.Warn("Usage alert monitor failed to list alerts", "error", err)
+		return since
+	}
+	if len(alerts) == 0 {
+		return since
+	}
+
+	children, err := b.client.ListChildren(ctx)
+	if err != nil {
+		b.logger.Warn("Usage alert monitor failed to list children", "error", err)
+	}
+	childrenMap := make(map[string]Child, len(children))
+	for _, child := range children {
+		childrenMap[child.ID] = child
+	}
+
+	for _, alert := range alerts {
+		child, ok := childrenMap[alert.ChildID]
+		if !ok {
+			child = Child{ID: alert.ChildID, Name: alert.ChildID}
+		}
+
+		b.logger.Info("Forwarding usage alert",
+			"alert_id", alert.ID,
+			"child_id", alert.ChildID,
+			"threshold", alert.Threshold)
+		b.notifyAllowedUsers(FormatUsageAlert(child, alert))
+
+		if createdAt, err := time.Parse(time.RFC3339, alert.CreatedAt); err == nil && createdAt.After(since) {
+			since = createdAt
+		}
+	}
+	return since
+}
+
+// notifyAllowedUsers sends a message to every allowed user's private chat
+func (b *Bot) notifyAllowedUsers(text string) {
+	b.notifyAllowedUsersWithButtons(text, nil)
+}
+
+// notifyAllowedUsersWithButtons sends a message with an inline keyboard to every allowed user's private chat
+func (b *Bot) notifyAllowedUsersWithButtons(text string, keyboard interface{}) {
+	for _, userID := range b.config.Telegram.AllowedUsers {
+		// Errors are logged by sendMessage; keep notifying the others
+		_ = b.sendMessage(userID, text, keyboard)
+	}
+}
@@ -0,0 +1,42 @@
+package bot
+
+import "sync"
+
+// replayWindow is how many recent update IDs are remembered to detect replays
+const replayWindow = 1000
+
+// replayGuard rejects webhook updates that were already handled
+// Telegram numbers updates sequentially and the webhook always answers 200, so an update ID seen
+// before is a replayed request. Updates older than the remembered window are rejected as well.
+type replayGuard struct {
+	seen  map[int]bool
+	order []int // Remembered IDs, oldest first
+	floor int   // Highest ID forgotten; IDs up to it are too old
+	mu    sync.Mutex
+}
+
+func newReplayGuard() *replayGuard {
+	return &replayGuard{seen: make(map[int]bool)}
+}
+
+// check records updateID and reports whether it is new
+func (g *replayGuard) check(updateID int) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.seen[updateID] || (g.floor > 0 && updateID <= g.floor) {
+		return false
+	}
+
+	g.seen[updateID] = true
+	g.order = append(g.order, updateID)
+	if len(g.order) > replayWindow {
+		oldest := g.order[0]
+		g.order = g.order[1:]
+		delete(g.seen, oldest)
+		if oldest > g.floor {
+			g.floor = oldest
+		}
+	}
+	return true
+}
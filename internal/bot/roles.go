@@ -0,0 +1,44 @@
+package bot
+
+import (
+	"metron/config"
+)
+
+// commandRoles is the least privileged role allowed to use each command
+// Commands not listed here need the admin role.
+var commandRoles = map[string]string{
+	"start":      config.BotRoleViewer,
+	"today":      config.BotRoleViewer,
+	"weekly":     config.BotRoleViewer,
+	"children":   config.BotRoleViewer,
+	"devices":    config.BotRoleViewer,
+	"dashboard":  config.BotRoleViewer,
+	"newsession": config.BotRoleOperator,
+	"extend":     config.BotRoleOperator,
+	"stop":       config.BotRoleOperator,
+}
+
+// callbackRoles is the least privileged role allowed to press each button action
+// Actions not listed here need the admin role.
+var callbackRoles = map[string]string{
+	"cancel":        config.BotRoleViewer,
+	"noop":          config.BotRoleViewer,
+	"main_menu":     config.BotRoleViewer,
+	"sessions_menu": config.BotRoleViewer,
+	"more_menu":     config.BotRoleViewer,
+	"newsession":    config.BotRoleOperator,
+	"extend":        config.BotRoleOperator,
+	"stop":          config.BotRoleOperator,
+	"stop_all":      config.BotRoleOperator,
+	"undo_stop":     config.BotRoleOperator,
+	"manage":        config.BotRoleOperator,
+	"warning":       config.BotRoleOperator,
+}
+
+// requiredRole returns the role needed for a command or button action
+func requiredRole(roles map[string]string, action string) string {
+	if role, ok := roles[action]; ok {
+		return role
+	}
+	return config.BotRoleAdmin
+}
@@ -2,15 +2,18 @@ package bot
 
 import (
 	"log/slog"
+	"net"
 
 	"github.com/gin-gonic/gin"
 )
 
 // RouterConfig holds dependencies for the bot router
 type RouterConfig struct {
-	Bot           *Bot
-	WebhookSecret string
-	Logger        *slog.Logger
+	Bot             *Bot
+	WebhookSecrets  []string     // Accepted webhook secrets, current first (see TelegramBotConfig.WebhookSecrets)
+	WebhookNetworks []*net.IPNet // Networks webhook requests may come from; nil accepts any
+	TrustedProxies  []string     // Proxies whose X-Forwarded-For header gives the client address
+	Logger          *slog.Logger
 }
 
 // NewRouter creates and configures the Gin router for the bot webhook
@@ -20,6 +23,13 @@ func NewRouter(config RouterConfig) *gin.Engine {
 
 	router := gin.New()
 
+	// Client addresses come from X-Forwarded-For only when set by a trusted proxy, so the
+	// webhook IP check cannot be bypassed with a forged header
+	if err := router.SetTrustedProxies(config.TrustedProxies); err != nil {
+		config.Logger.Error("Invalid trusted proxies, trusting none", "error", err)
+		_ = router.SetTrustedProxies(nil)
+	}
+
 	// Add middleware
 	router.Use(gin.Recovery())
 	router.Use(BotLoggingMiddleware(config.Logger))
@@ -27,7 +37,8 @@ func NewRouter(config RouterConfig) *gin.Engine {
 	// Create webhook handler
 	webhookHandler := NewWebhookHandler(
 		config.Bot,
-		config.WebhookSecret,
+		config.WebhookSecrets,
+		config.WebhookNetworks,
 		config.Logger,
 	)
 
@@ -39,8 +50,43 @@ func NewRouter(config RouterConfig) *gin.Engine {
 		})
 	})
 
+	// Liveness probe (process is up)
+	router.GET("/healthz", func(c *gin.Context) {
+		c.JSON(200, gin.H{
+			"status":  "UP",
+			"service": "metron-bot",
+		})
+	})
+
+	// Readiness probe (webhook registered with Telegram)
+	router.GET("/readyz", func(c *gin.Context) {
+		webhook := gin.H{"name": "webhook", "status": "UP"}
+		if err := config.Bot.CheckWebhook(); err != nil {
+			webhook["status"] = "DOWN"
+			webhook["error"] = err.Error()
+			c.JSON(503, gin.H{
+				"status":  "DOWN",
+				"service": "metron-bot",
+				"checks":  []gin.H{webhook},
+			})
+			return
+		}
+		c.JSON(200, gin.H{
+			"status":  "UP",
+			"service": "metron-bot",
+			"checks":  []gin.H{webhook},
+		})
+	})
+
 	// Webhook endpoint
 	router.POST("/telegram/webhook", webhookHandler.HandleWebhook)
 
+	// Telegram Web App dashboard, opened with /dashboard
+	webAppHandler := NewWebAppHandler(config.Bot, config.Logger)
+	router.GET("/webapp", webAppHandler.Page)
+	router.GET("/webapp/api/status", webAppHandler.Status)
+	router.POST("/webapp/api/sessions/:id/extend", webAppHandler.ExtendSession)
+	router.POST("/webapp/api/sessions/:id/stop", webAppHandler.StopSession)
+
 	return router
 }
@@ -0,0 +1,84 @@
+package bot
+
+import (
+	"sync"
+	"time"
+)
+
+// stopUndoWindow is how long a session stopped from the bot can be restarted from the stop message
+const stopUndoWindow = 2 * time.Minute
+
+// stoppedSession is a session stopped from the bot, kept so the stop can be undone
+type stoppedSession struct {
+	session   Session
+	remaining int // Minutes the session had left when it was stopped
+	expiresAt time.Time
+}
+
+// stopKey identifies the message showing a stop
+type stopKey struct {
+	chatID    int64
+	messageID int
+}
+
+// stopUndos keeps the sessions stopped from the bot during their undo window
+// Stops are keyed by their message, since callback data is too small for the session details.
+// They are lost when the bot restarts, which only shortens the undo window.
+type stopUndos struct {
+	stops map[stopKey]stoppedSession
+	mu    sync.Mutex
+}
+
+func newStopUndos() *stopUndos {
+	return &stopUndos{stops: make(map[stopKey]stoppedSession)}
+}
+
+// add keeps a stop shown in the given message until the undo window ends
+func (u *stopUndos) add(chatID int64, messageID int, session Session, remaining int) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	now := time.Now()
+	for key, stop := range u.stops {
+		if now.After(stop.expiresAt) {
+			delete(u.stops, key)
+		}
+	}
+
+	u.stops[stopKey{chatID: chatID, messageID: messageID}] = stoppedSession{
+		session:   session,
+		remaining: remaining,
+		expiresAt: now.Add(stopUndoWindow),
+	}
+}
+
+// take removes and returns the stop shown in the given message
+// Returns false if there is none or its undo window has ended
+func (u *stopUndos) take(chatID int64, messageID int) (stoppedSession, bool) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	key := stopKey{chatID: chatID, messageID: messageID}
+	stop, ok := u.stops[key]
+	if !ok {
+		return stoppedSession{}, false
+	}
+	delete(u.stops, key)
+
+	if time.Now().After(stop.expiresAt) {
+		return stoppedSession{}, false
+	}
+	return stop, true
+}
+
+// drop removes the stop shown in the given message and reports whether it was still there
+// (not undone yet, nor dropped because the message moved on to another screen)
+func (u *stopUndos) drop(chatID int64, messageID int) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	key := stopKey{chatID: chatID, messageID: messageID}
+	_, ok := u.stops[key]
+	delete(u.stops, key)
+	return ok
+}
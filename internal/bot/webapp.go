@@ -0,0 +1,306 @@
+package bot
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"metron/config"
+
+	"github.com/gin-gonic/gin"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// webAppPage is the mini-dashboard opened from the bot's Dashboard button
+//
+//go:embed webapp/index.html
+var webAppPage []byte
+
+// webAppInitDataMaxAge is how long the launch data Telegram signs for the dashboard is accepted
+const webAppInitDataMaxAge = 24 * time.Hour
+
+// webAppExtendMinutes are the extensions offered by the dashboard
+var webAppExtendMinutes = map[int]bool{5: true, 15: true, 30: true}
+
+// WebAppHandler serves the Telegram Web App dashboard and its API
+// Requests are authenticated with the launch data Telegram signs with the bot token
+// (sent as "Authorization: tma <initData>"), and the user's bot role applies as in chats.
+type WebAppHandler struct {
+	bot    *Bot
+	logger *slog.Logger
+}
+
+// NewWebAppHandler creates a new web app handler
+func NewWebAppHandler(bot *Bot, logger *slog.Logger) *WebAppHandler {
+	return &WebAppHandler{
+		bot:    bot,
+		logger: logger,
+	}
+}
+
+// webAppSession is an active session as shown by the dashboard
+type webAppSession struct {
+	ID          string   `json:"id"`
+	DeviceID    string   `json:"device_id"`
+	DeviceName  string   `json:"device_name"`
+	DeviceEmoji string   `json:"device_emoji"`
+	ChildIDs    []string `json:"child_ids"`
+	EndsAt      string   `json:"ends_at"` // RFC3339, for the countdown
+}
+
+// webAppChild is a child's gauge on the dashboard
+type webAppChild struct {
+	ID             string          `json:"id"`
+	Name           string          `json:"name"`
+	Emoji          string          `json:"emoji"`
+	TodayUsed      int             `json:"today_used"`
+	TodayRemaining int             `json:"today_remaining"`
+	TodayLimit     int             `json:"today_limit"`
+	UsagePercent   int             `json:"usage_percent"`
+	TrackingPaused bool            `json:"tracking_paused"`
+	ActiveSessions []webAppSession `json:"active_sessions"`
+}
+
+// Page serves the dashboard
+// GET /webapp
+func (h *WebAppHandler) Page(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", webAppPage)
+}
+
+// Status returns every child's usage today with the active sessions
+// GET /webapp/api/status
+func (h *WebAppHandler) Status(c *gin.Context) {
+	user, role, ok := h.authorize(c, config.BotRoleViewer)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	status, err := h.bot.client.GetChildrenStatus(ctx)
+	if err != nil {
+		h.respondAPIError(c, user, err)
+		return
+	}
+
+	devices, err := h.bot.client.ListDevices(ctx)
+	if err != nil {
+		h.respondAPIError(c, user, err)
+		return
+	}
+	devicesMap := make(map[string]Device)
+	for _, device := range devices {
+		devicesMap[device.ID] = device
+	}
+
+	children := make([]webAppChild, len(status.Children))
+	for i, child := range status.Children {
+		sessions := make([]webAppSession, len(child.ActiveSessions))
+		for j, session := range child.ActiveSessions {
+			endTime, _ := calculateSessionEnd(session)
+			device, found := devicesMap[session.DeviceID]
+			name, emoji := session.DeviceID, "📱"
+			if found {
+				name, emoji = device.Name, resolveDeviceEmoji(device)
+			}
+			sessions[j] = webAppSession{
+				ID:          session.ID,
+				DeviceID:    session.DeviceID,
+				DeviceName:  name,
+				DeviceEmoji: emoji,
+				ChildIDs:    session.ChildIDs,
+				EndsAt:      endTime.UTC().Format(time.RFC3339),
+			}
+		}
+
+		children[i] = webAppChild{
+			ID:             child.ID,
+			Name:           child.Name,
+			Emoji:          child.Emoji,
+			TodayUsed:      child.TodayUsed,
+			TodayRemaining: child.TodayRemaining,
+			TodayLimit:     child.TodayLimit,
+			UsagePercent:   child.UsagePercent,
+			TrackingPaused: child.TrackingPaused,
+			ActiveSessions: sessions,
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"date":     status.Date,
+		"role":     role,
+		"can_act":  config.BotRoleAllows(role, config.BotRoleOperator),
+		"children": children,
+	})
+}
+
+// ExtendSession extends an active session
+// POST /webapp/api/sessions/:id/extend {"minutes": 15}
+func (h *WebAppHandler) ExtendSession(c *gin.Context) {
+	user, _, ok := h.authorize(c, config.BotRoleOperator)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Minutes int `json:"minutes"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || !webAppExtendMinutes[req.Minutes] {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "minutes must be 5, 15 or 30"})
+		return
+	}
+
+	session, err := h.bot.client.ExtendSession(c.Request.Context(), c.Param("id"), req.Minutes)
+	if err != nil {
+		h.respondAPIError(c, user, err)
+		return
+	}
+
+	h.logger.Info("Session extended from web app",
+		"session_id", session.ID,
+		"minutes", req.Minutes,
+		"user_id", user.ID,
+	)
+
+	endTime, _ := calculateSessionEnd(*session)
+	c.JSON(http.StatusOK, gin.H{
+		"id":      session.ID,
+		"ends_at": endTime.UTC().Format(time.RFC3339),
+		"capped":  session.Capped,
+	})
+}
+
+// StopSession stops an active session
+// POST /webapp/api/sessions/:id/stop
+func (h *WebAppHandler) StopSession(c *gin.Context) {
+	user, _, ok := h.authorize(c, config.BotRoleOperator)
+	if !ok {
+		return
+	}
+
+	sessionID := c.Param("id")
+	if err := h.bot.client.StopSession(c.Request.Context(), sessionID); err != nil {
+		h.respondAPIError(c, user, err)
+		return
+	}
+
+	h.logger.Info("Session stopped from web app",
+		"session_id", sessionID,
+		"user_id", user.ID,
+	)
+
+	c.JSON(http.StatusOK, gin.H{"id": sessionID, "status": "completed"})
+}
+
+// authorize validates the launch data and checks the user's role, responding if it fails
+func (h *WebAppHandler) authorize(c *gin.Context, required string) (*tgbotapi.User, string, bool) {
+	initData, found := strings.CutPrefix(c.GetHeader("Authorization"), "tma ")
+	if !found {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Missing web app launch data"})
+		return nil, "", false
+	}
+
+	user, err := validateWebAppInitData(initData, h.bot.config.Telegram.Token, time.Now())
+	if err != nil {
+		h.logger.Warn("Invalid web app launch data",
+			"remote_addr", c.ClientIP(),
+			"error", err,
+		)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid web app launch data"})
+		return nil, "", false
+	}
+
+	role := h.bot.config.UserRole(user.ID)
+	if role == "" {
+		h.logger.Warn("Unauthorized web app access attempt",
+			"user_id", user.ID,
+		)
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to use this bot"})
+		return nil, "", false
+	}
+
+	if !config.BotRoleAllows(role, required) {
+		h.logger.Warn("Web app action denied by role",
+			"user_id", user.ID,
+			"role", role,
+			"required_role", required,
+		)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Your role (" + role + ") does not allow this"})
+		return nil, "", false
+	}
+
+	return user, role, true
+}
+
+// respondAPIError responds with a failed Metron API request, keeping its status for client errors
+func (h *WebAppHandler) respondAPIError(c *gin.Context, user *tgbotapi.User, err error) {
+	var reqErr *RequestError
+	if errors.As(err, &reqErr) && reqErr.StatusCode >= 400 && reqErr.StatusCode < 500 {
+		c.JSON(reqErr.StatusCode, gin.H{"error": reqErr.Message, "code": reqErr.Code})
+		return
+	}
+
+	h.logger.Error("Web app request failed",
+		"path", c.FullPath(),
+		"user_id", user.ID,
+		"error", err,
+	)
+	c.JSON(http.StatusBadGateway, gin.H{"error": "Metron API request failed"})
+}
+
+// validateWebAppInitData checks the signature and age of web app launch data and returns its user
+// See https://core.telegram.org/bots/webapps#validating-data-received-via-the-mini-app
+func validateWebAppInitData(initData, botToken string, now time.Time) (*tgbotapi.User, error) {
+	values, err := url.ParseQuery(initData)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := values.Get("hash")
+	if hash == "" {
+		return nil, errors.New("hash is missing")
+	}
+
+	// The data-check-string is every other field as key=value, sorted by key, one per line
+	pairs := make([]string, 0, len(values))
+	for key := range values {
+		if key != "hash" {
+			pairs = append(pairs, key+"="+values.Get(key))
+		}
+	}
+	sort.Strings(pairs)
+
+	secret := hmac.New(sha256.New, []byte("WebAppData"))
+	secret.Write([]byte(botToken))
+	mac := hmac.New(sha256.New, secret.Sum(nil))
+	mac.Write([]byte(strings.Join(pairs, "\n")))
+
+	expected, err := hex.DecodeString(hash)
+	if err != nil || !hmac.Equal(mac.Sum(nil), expected) {
+		return nil, errors.New("signature mismatch")
+	}
+
+	seconds, err := strconv.ParseInt(values.Get("auth_date"), 10, 64)
+	if err != nil {
+		return nil, errors.New("auth_date is invalid")
+	}
+	authDate := time.Unix(seconds, 0)
+	if now.Sub(authDate) > webAppInitDataMaxAge {
+		return nil, errors.New("launch data expired")
+	}
+
+	var user tgbotapi.User
+	if err := json.Unmarshal([]byte(values.Get("user")), &user); err != nil || user.ID == 0 {
+		return nil, errors.New("user is missing")
+	}
+	return &user, nil
+}
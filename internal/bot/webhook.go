@@ -1,9 +1,11 @@
 package bot
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"io"
 	"log/slog"
+	"net"
 	"net/http"
 
 	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
@@ -12,26 +14,42 @@ import (
 
 // WebhookHandler handles incoming webhook requests from Telegram
 type WebhookHandler struct {
-	bot    *Bot
-	logger *slog.Logger
-	secret string
+	bot      *Bot
+	logger   *slog.Logger
+	secrets  []string     // Accepted secret tokens, current first; empty disables the check
+	networks []*net.IPNet // Networks requests may come from; empty disables the check
+	replays  *replayGuard
 }
 
 // NewWebhookHandler creates a new webhook handler
-func NewWebhookHandler(bot *Bot, secret string, logger *slog.Logger) *WebhookHandler {
+// Several secrets are accepted so the secret can be rotated while updates signed with the old one arrive.
+func NewWebhookHandler(bot *Bot, secrets []string, networks []*net.IPNet, logger *slog.Logger) *WebhookHandler {
 	return &WebhookHandler{
-		bot:    bot,
-		logger: logger,
-		secret: secret,
+		bot:      bot,
+		logger:   logger,
+		secrets:  secrets,
+		networks: networks,
+		replays:  newReplayGuard(),
 	}
 }
 
 // HandleWebhook processes incoming webhook requests
 func (h *WebhookHandler) HandleWebhook(c *gin.Context) {
+	// Verify the source network if configured
+	if len(h.networks) > 0 && !h.allowedSource(c.ClientIP()) {
+		h.logger.Warn("Webhook request from outside the allowed networks",
+			"remote_addr", c.ClientIP(),
+		)
+		c.JSON(http.StatusForbidden, gin.H{
+			"error": "Source address not allowed",
+		})
+		return
+	}
+
 	// Verify secret token if configured
-	if h.secret != "" {
+	if len(h.secrets) > 0 {
 		token := c.GetHeader("X-Telegram-Bot-Api-Secret-Token")
-		if token != h.secret {
+		if !h.validSecret(token) {
 			h.logger.Warn("Invalid webhook secret token",
 				"remote_addr", c.ClientIP(),
 			)
@@ -66,6 +84,18 @@ func (h *WebhookHandler) HandleWebhook(c *gin.Context) {
 		"update_id", update.UpdateID,
 	)
 
+	// Ignore replayed updates; 200 so a genuine Telegram retry is not repeated either
+	if !h.replays.check(update.UpdateID) {
+		h.logger.Warn("Ignoring replayed webhook update",
+			"update_id", update.UpdateID,
+			"remote_addr", c.ClientIP(),
+		)
+		c.JSON(http.StatusOK, gin.H{
+			"ok": true,
+		})
+		return
+	}
+
 	// Handle update
 	if err := h.bot.HandleUpdate(update); err != nil {
 		h.logger.Error("Failed to handle update",
@@ -79,3 +109,29 @@ func (h *WebhookHandler) HandleWebhook(c *gin.Context) {
 		"ok": true,
 	})
 }
+
+// validSecret reports whether token matches one of the accepted secrets
+func (h *WebhookHandler) validSecret(token string) bool {
+	valid := false
+	for _, secret := range h.secrets {
+		// Every secret is compared, in constant time, so timing does not reveal which one matched
+		if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) == 1 {
+			valid = true
+		}
+	}
+	return valid
+}
+
+// allowedSource reports whether the client address is in one of the allowed networks
+func (h *WebhookHandler) allowedSource(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, network := range h.networks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
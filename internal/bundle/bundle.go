@@ -0,0 +1,603 @@
+// Package bundle exports Metron's configuration and data as a single JSON bundle and imports it
+// into another server, for migrating between hosts or seeding a second house.
+// A bundle holds the devices, downtime, movie time and limit profiles of the configuration file,
+// the children with their limits and limit change schedules and, optionally, their history
+// (ended sessions, daily usage and daily allocations).
+package bundle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"metron/config"
+	"metron/internal/core"
+	"sort"
+	"time"
+)
+
+// Format identifies Metron bundles
+const Format = "metron-bundle"
+
+// Version is the bundle version written by Export; Import rejects other versions
+const Version = 1
+
+// dateLayout is the layout of calendar days in bundles
+const dateLayout = "2006-01-02"
+
+var (
+	// ErrInvalidBundle is returned for bundles of another format or version, and for
+	// bundles with malformed or inconsistent contents
+	ErrInvalidBundle = errors.New("invalid bundle")
+
+	// ErrChildExists is returned when a child of the bundle already exists; nothing is imported
+	ErrChildExists = errors.New("child already exists")
+)
+
+// Bundle is an exported configuration and its data
+type Bundle struct {
+	Format       string        `json:"format"`
+	Version      int           `json:"version"`
+	ExportedAt   time.Time     `json:"exported_at"`
+	Config       *Config       `json:"config,omitempty"`
+	Children     []Child       `json:"children"`
+	LimitChanges []LimitChange `json:"limit_changes,omitempty"`
+	History      *History      `json:"history,omitempty"` // Only exported on request
+}
+
+// Config holds the sections of the configuration file that describe the house
+// They use the same format as the configuration file and are not stored in the database,
+// so importing a bundle never changes them (see "metron import -merge-config")
+type Config struct {
+	Timezone      string                      `json:"timezone"`
+	Devices       []config.DeviceConfig       `json:"devices,omitempty"`
+	Downtime      *config.DowntimeConfig      `json:"downtime,omitempty"`
+	MovieTime     *config.MovieTimeConfig     `json:"movie_time,omitempty"`
+	LimitProfiles []config.LimitProfileConfig `json:"limit_profiles,omitempty"`
+}
+
+// NewConfig copies the house sections of a configuration
+func NewConfig(cfg *config.Config) *Config {
+	return &Config{
+		Timezone:      cfg.Timezone,
+		Devices:       cfg.Devices,
+		Downtime:      cfg.Downtime,
+		MovieTime:     cfg.MovieTime,
+		LimitProfiles: cfg.LimitProfiles,
+	}
+}
+
+// Child is a child with its limits
+type Child struct {
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	Emoji           string     `json:"emoji,omitempty"`
+	PIN             string     `json:"pin,omitempty"` // As stored (bcrypt hash)
+	WeekdayLimit    int        `json:"weekday_limit"`
+	WeekendLimit    int        `json:"weekend_limit"`
+	BreakRule       *BreakRule `json:"break_rule,omitempty"`
+	DowntimeEnabled bool       `json:"downtime_enabled,omitempty"`
+	AllowedDevices  []string   `json:"allowed_devices,omitempty"`
+	Timezone        string     `json:"timezone,omitempty"`
+	GraceMinutes    int        `json:"grace_minutes,omitempty"`
+	Birthdate       string     `json:"birthdate,omitempty"`  // YYYY-MM-DD
+	CreatedAt       *time.Time `json:"created_at,omitempty"` // Kept on import, so reports include the imported history
+}
+
+// BreakRule uses the same fields as the API's break_rule
+type BreakRule struct {
+	BreakAfterMinutes    int    `json:"break_after_minutes"`
+	BreakDurationMinutes int    `json:"break_duration_minutes"`
+	Action               string `json:"action,omitempty"`
+}
+
+// LimitChange is an applied or scheduled change of a child's limits
+type LimitChange struct {
+	ID            string     `json:"id"`
+	ChildID       string     `json:"child_id"`
+	WeekdayLimit  int        `json:"weekday_limit"`
+	WeekendLimit  int        `json:"weekend_limit"`
+	EffectiveFrom string     `json:"effective_from"` // YYYY-MM-DD
+	CreatedBy     string     `json:"created_by,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	AppliedAt     *time.Time `json:"applied_at,omitempty"` // Nil while scheduled
+}
+
+// History holds the children's past usage
+type History struct {
+	Sessions    []Session    `json:"sessions,omitempty"` // Ended sessions, oldest first
+	Usage       []Usage      `json:"usage,omitempty"`
+	Allocations []Allocation `json:"allocations,omitempty"`
+}
+
+// Session is an ended session
+type Session struct {
+	ID               string    `json:"id"`
+	DeviceType       string    `json:"device_type"`
+	DeviceID         string    `json:"device_id"`
+	ChildIDs         []string  `json:"child_ids"`
+	StartTime        time.Time `json:"start_time"`
+	ExpectedMinutes  int       `json:"expected_minutes"`
+	ActualMinutes    *int      `json:"actual_minutes,omitempty"`
+	Status           string    `json:"status"`
+	BreakMinutes     int       `json:"break_minutes,omitempty"`
+	BreakExempt      bool      `json:"break_exempt,omitempty"`
+	OverrideDowntime bool      `json:"override_downtime,omitempty"`
+	OverrideLimits   bool      `json:"override_limits,omitempty"`
+	IsMovieSession   bool      `json:"is_movie_session,omitempty"`
+	EndReason        string    `json:"end_reason,omitempty"`
+	InitiatorType    string    `json:"initiator_type,omitempty"`
+	InitiatorID      string    `json:"initiator_id,omitempty"`
+	Notes            string    `json:"notes,omitempty"`
+	Activity         string    `json:"activity,omitempty"`
+}
+
+// Usage is the time a child used on a day
+type Usage struct {
+	ChildID      string `json:"child_id"`
+	Date         string `json:"date"` // YYYY-MM-DD
+	MinutesUsed  int    `json:"minutes_used"`
+	SessionCount int    `json:"session_count"`
+}
+
+// Allocation is the time a child had on a day
+type Allocation struct {
+	ChildID      string `json:"child_id"`
+	Date         string `json:"date"` // YYYY-MM-DD
+	BaseLimit    int    `json:"base_limit"`
+	BonusGranted int    `json:"bonus_granted"`
+}
+
+// Result counts what an import created
+type Result struct {
+	Children     int `json:"children"`
+	LimitChanges int `json:"limit_changes"`
+	Sessions     int `json:"sessions"`
+	UsageDays    int `json:"usage_days"`
+	Allocations  int `json:"allocations"`
+}
+
+// Storage defines the storage interface needed to export and import bundles
+type Storage interface {
+	ListChildren(ctx context.Context) ([]*core.Child, error)
+	GetChild(ctx context.Context, id string) (*core.Child, error)
+	CreateChild(ctx context.Context, child *core.Child) error
+	ListLimitChanges(ctx context.Context, childID string) ([]*core.LimitChange, error)
+	CreateLimitChange(ctx context.Context, change *core.LimitChange) error
+	ListAllSessions(ctx context.Context) ([]*core.Session, error)
+	CreateSession(ctx context.Context, session *core.Session) error
+	ListDailyUsageSummaries(ctx context.Context, date time.Time) ([]*core.DailyUsageSummary, error)
+	IncrementDailyUsageSummary(ctx context.Context, childID string, date time.Time, minutes int) error
+	IncrementSessionCountSummary(ctx context.Context, childID string, date time.Time) error
+	ListDailyAllocations(ctx context.Context, date time.Time) ([]*core.DailyTimeAllocation, error)
+	CreateDailyAllocation(ctx context.Context, allocation *core.DailyTimeAllocation) error
+}
+
+// Service exports and imports bundles
+type Service struct {
+	storage  Storage
+	config   *Config // Optional: house sections exported with the data
+	timezone *time.Location
+	logger   *slog.Logger
+}
+
+// NewService creates a new bundle service
+// Dates are day keys in the server timezone (see core.UsageDate)
+func NewService(storage Storage, cfg *Config, timezone *time.Location, logger *slog.Logger) *Service {
+	if timezone == nil {
+		timezone = time.UTC
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Service{
+		storage:  storage,
+		config:   cfg,
+		timezone: timezone,
+		logger:   logger,
+	}
+}
+
+// Read decodes a bundle and checks its format and version
+func Read(r io.Reader) (*Bundle, error) {
+	var b Bundle
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	if err := b.check(); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// check verifies the format and version of the bundle
+func (b *Bundle) check() error {
+	if b.Format != Format {
+		return fmt.Errorf("%w: format is %q, expected %q", ErrInvalidBundle, b.Format, Format)
+	}
+	if b.Version != Version {
+		return fmt.Errorf("%w: version %d is not supported (expected %d)", ErrInvalidBundle, b.Version, Version)
+	}
+	return nil
+}
+
+// Export returns the configuration and all children with their limit changes
+// With history, the ended sessions, daily usage and daily allocations are included.
+func (s *Service) Export(ctx context.Context, history bool) (*Bundle, error) {
+	children, err := s.storage.ListChildren(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list children: %w", err)
+	}
+	changes, err := s.storage.ListLimitChanges(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list limit changes: %w", err)
+	}
+
+	b := &Bundle{
+		Format:     Format,
+		Version:    Version,
+		ExportedAt: core.Now().UTC(),
+		Config:     s.config,
+		Children:   make([]Child, 0, len(children)),
+	}
+	for _, child := range children {
+		b.Children = append(b.Children, exportChild(child))
+	}
+	for _, change := range changes {
+		b.LimitChanges = append(b.LimitChanges, LimitChange{
+			ID:            change.ID,
+			ChildID:       change.ChildID,
+			WeekdayLimit:  change.WeekdayLimit,
+			WeekendLimit:  change.WeekendLimit,
+			EffectiveFrom: change.EffectiveFrom.Format(dateLayout),
+			CreatedBy:     change.CreatedBy,
+			CreatedAt:     change.CreatedAt,
+			AppliedAt:     change.AppliedAt,
+		})
+	}
+
+	if history {
+		if b.History, err = s.exportHistory(ctx, children); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// exportChild converts a child for the bundle
+func exportChild(child *core.Child) Child {
+	exported := Child{
+		ID:              child.ID,
+		Name:            child.Name,
+		Emoji:           child.Emoji,
+		PIN:             child.PIN,
+		WeekdayLimit:    child.WeekdayLimit,
+		WeekendLimit:    child.WeekendLimit,
+		DowntimeEnabled: child.DowntimeEnabled,
+		AllowedDevices:  child.AllowedDevices,
+		Timezone:        child.Timezone,
+		GraceMinutes:    child.GraceMinutes,
+	}
+	if child.BreakRule != nil {
+		exported.BreakRule = &BreakRule{
+			BreakAfterMinutes:    child.BreakRule.BreakAfterMinutes,
+			BreakDurationMinutes: child.BreakRule.BreakDurationMinutes,
+			Action:               child.BreakRule.Action,
+		}
+	}
+	if !child.CreatedAt.IsZero() {
+		createdAt := child.CreatedAt
+		exported.CreatedAt = &createdAt
+	}
+	if child.Birthdate != nil {
+		exported.Birthdate = child.Birthdate.Format(dateLayout)
+	}
+	return exported
+}
+
+// exportHistory collects the ended sessions, and the daily usage and allocations from the
+// first day a child or session was seen up to today
+func (s *Service) exportHistory(ctx context.Context, children []*core.Child) (*History, error) {
+	sessions, err := s.storage.ListAllSessions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].StartTime.Before(sessions[j].StartTime)
+	})
+
+	today := core.UsageDate(core.Now(), s.timezone, s.timezone)
+	first := today
+	for _, child := range children {
+		if !child.CreatedAt.IsZero() && child.CreatedAt.Before(first) {
+			first = child.CreatedAt
+		}
+	}
+
+	history := &History{}
+	for _, session := range sessions {
+		if session.IsRunning() {
+			continue
+		}
+		if session.StartTime.Before(first) {
+			first = session.StartTime
+		}
+		history.Sessions = append(history.Sessions, Session{
+			ID:               session.ID,
+			DeviceType:       session.DeviceType,
+			DeviceID:         session.DeviceID,
+			ChildIDs:         session.ChildIDs,
+			StartTime:        session.StartTime,
+			ExpectedMinutes:  session.ExpectedDuration,
+			ActualMinutes:    session.ActualDuration,
+			Status:           string(session.Status),
+			BreakMinutes:     session.BreakMinutes,
+			BreakExempt:      session.BreakExempt,
+			OverrideDowntime: session.OverrideDowntime,
+			OverrideLimits:   session.OverrideLimits,
+			IsMovieSession:   session.IsMovieSession,
+			EndReason:        session.EndReason,
+			InitiatorType:    session.InitiatorType,
+			InitiatorID:      session.InitiatorID,
+			Notes:            session.Notes,
+			Activity:         session.Activity,
+		})
+	}
+
+	for day := core.UsageDate(first, s.timezone, s.timezone); !day.After(today); day = day.AddDate(0, 0, 1) {
+		summaries, err := s.storage.ListDailyUsageSummaries(ctx, day)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list usage of %s: %w", day.Format(dateLayout), err)
+		}
+		for _, summary := range summaries {
+			history.Usage = append(history.Usage, Usage{
+				ChildID:      summary.ChildID,
+				Date:         day.Format(dateLayout),
+				MinutesUsed:  summary.MinutesUsed,
+				SessionCount: summary.SessionCount,
+			})
+		}
+
+		allocations, err := s.storage.ListDailyAllocations(ctx, day)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list allocations of %s: %w", day.Format(dateLayout), err)
+		}
+		for _, allocation := range allocations {
+			history.Allocations = append(history.Allocations, Allocation{
+				ChildID:      allocation.ChildID,
+				Date:         day.Format(dateLayout),
+				BaseLimit:    allocation.BaseLimit,
+				BonusGranted: allocation.BonusGranted,
+			})
+		}
+	}
+
+	return history, nil
+}
+
+// Import creates the bundle's children, limit changes and history
+// The bundle is checked before anything is written: if any of its children already exists,
+// ErrChildExists is returned and nothing is imported. The configuration sections are not applied.
+func (s *Service) Import(ctx context.Context, b *Bundle) (*Result, error) {
+	if err := b.check(); err != nil {
+		return nil, err
+	}
+
+	children, err := s.importChildren(b.Children)
+	if err != nil {
+		return nil, err
+	}
+	changes, err := s.importLimitChanges(b.LimitChanges, children)
+	if err != nil {
+		return nil, err
+	}
+	history := b.History
+	if history == nil {
+		history = &History{}
+	}
+	sessions, err := s.importSessions(history.Sessions, children)
+	if err != nil {
+		return nil, err
+	}
+	usageDays := make([]time.Time, len(history.Usage))
+	for i, usage := range history.Usage {
+		if usage.MinutesUsed < 0 || usage.SessionCount < 0 {
+			return nil, fmt.Errorf("%w: negative usage of child %s on %s", ErrInvalidBundle, usage.ChildID, usage.Date)
+		}
+		if usageDays[i], err = s.parseDay(usage.ChildID, usage.Date, children); err != nil {
+			return nil, err
+		}
+	}
+	allocationDays := make([]time.Time, len(history.Allocations))
+	for i, allocation := range history.Allocations {
+		if allocationDays[i], err = s.parseDay(allocation.ChildID, allocation.Date, children); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, child := range children {
+		if _, err := s.storage.GetChild(ctx, child.ID); err == nil {
+			return nil, fmt.Errorf("%w: %s", ErrChildExists, child.ID)
+		} else if !errors.Is(err, core.ErrChildNotFound) {
+			return nil, err
+		}
+	}
+
+	result := &Result{}
+	for _, child := range children {
+		if err := s.storage.CreateChild(ctx, child); err != nil {
+			return result, fmt.Errorf("failed to create child %s: %w", child.ID, err)
+		}
+		result.Children++
+	}
+	for _, change := range changes {
+		if err := s.storage.CreateLimitChange(ctx, change); err != nil {
+			return result, fmt.Errorf("failed to create limit change %s: %w", change.ID, err)
+		}
+		result.LimitChanges++
+	}
+	for _, session := range sessions {
+		if err := s.storage.CreateSession(ctx, session); err != nil {
+			return result, fmt.Errorf("failed to create session %s: %w", session.ID, err)
+		}
+		result.Sessions++
+	}
+	for i, day := range history.Usage {
+		if err := s.storage.IncrementDailyUsageSummary(ctx, day.ChildID, usageDays[i], day.MinutesUsed); err != nil {
+			return result, fmt.Errorf("failed to import usage of %s: %w", day.Date, err)
+		}
+		for n := 0; n < day.SessionCount; n++ {
+			if err := s.storage.IncrementSessionCountSummary(ctx, day.ChildID, usageDays[i]); err != nil {
+				return result, fmt.Errorf("failed to import usage of %s: %w", day.Date, err)
+			}
+		}
+		result.UsageDays++
+	}
+	for i, allocation := range history.Allocations {
+		if err := s.storage.CreateDailyAllocation(ctx, &core.DailyTimeAllocation{
+			ChildID:      allocation.ChildID,
+			Date:         allocationDays[i],
+			BaseLimit:    allocation.BaseLimit,
+			BonusGranted: allocation.BonusGranted,
+		}); err != nil {
+			return result, fmt.Errorf("failed to import allocation of %s: %w", allocation.Date, err)
+		}
+		result.Allocations++
+	}
+
+	s.logger.Info("Bundle imported",
+		"exported_at", b.ExportedAt,
+		"children", result.Children,
+		"limit_changes", result.LimitChanges,
+		"sessions", result.Sessions,
+		"usage_days", result.UsageDays,
+		"allocations", result.Allocations)
+	return result, nil
+}
+
+// importChildren converts and validates the bundle's children, by ID
+func (s *Service) importChildren(bundled []Child) (map[string]*core.Child, error) {
+	children := make(map[string]*core.Child, len(bundled))
+	for _, c := range bundled {
+		if c.ID == "" {
+			return nil, fmt.Errorf("%w: child %q has no ID", ErrInvalidBundle, c.Name)
+		}
+		if _, ok := children[c.ID]; ok {
+			return nil, fmt.Errorf("%w: child %s is listed twice", ErrInvalidBundle, c.ID)
+		}
+		child := &core.Child{
+			ID:              c.ID,
+			Name:            c.Name,
+			Emoji:           c.Emoji,
+			PIN:             c.PIN,
+			WeekdayLimit:    c.WeekdayLimit,
+			WeekendLimit:    c.WeekendLimit,
+			DowntimeEnabled: c.DowntimeEnabled,
+			AllowedDevices:  c.AllowedDevices,
+			Timezone:        c.Timezone,
+			GraceMinutes:    c.GraceMinutes,
+		}
+		if c.CreatedAt != nil {
+			child.CreatedAt = *c.CreatedAt
+		}
+		if c.BreakRule != nil {
+			child.BreakRule = &core.BreakRule{
+				BreakAfterMinutes:    c.BreakRule.BreakAfterMinutes,
+				BreakDurationMinutes: c.BreakRule.BreakDurationMinutes,
+				Action:               c.BreakRule.Action,
+			}
+		}
+		if c.Birthdate != "" {
+			birthdate, err := time.Parse(dateLayout, c.Birthdate)
+			if err != nil {
+				return nil, fmt.Errorf("%w: child %s has an invalid birthdate %q", ErrInvalidBundle, c.ID, c.Birthdate)
+			}
+			child.Birthdate = &birthdate
+		}
+		if err := child.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: child %s: %v", ErrInvalidBundle, c.ID, err)
+		}
+		children[c.ID] = child
+	}
+	return children, nil
+}
+
+// importLimitChanges converts the bundle's limit changes of known children
+func (s *Service) importLimitChanges(bundled []LimitChange, children map[string]*core.Child) ([]*core.LimitChange, error) {
+	changes := make([]*core.LimitChange, 0, len(bundled))
+	for _, c := range bundled {
+		if _, ok := children[c.ChildID]; !ok {
+			return nil, fmt.Errorf("%w: limit change %s is for unknown child %s", ErrInvalidBundle, c.ID, c.ChildID)
+		}
+		effectiveFrom, err := time.Parse(dateLayout, c.EffectiveFrom)
+		if err != nil {
+			return nil, fmt.Errorf("%w: limit change %s has an invalid effective_from %q", ErrInvalidBundle, c.ID, c.EffectiveFrom)
+		}
+		changes = append(changes, &core.LimitChange{
+			ID:            c.ID,
+			ChildID:       c.ChildID,
+			WeekdayLimit:  c.WeekdayLimit,
+			WeekendLimit:  c.WeekendLimit,
+			EffectiveFrom: effectiveFrom,
+			CreatedBy:     c.CreatedBy,
+			CreatedAt:     c.CreatedAt,
+			AppliedAt:     c.AppliedAt,
+		})
+	}
+	return changes, nil
+}
+
+// importSessions converts the bundle's ended sessions of known children
+func (s *Service) importSessions(bundled []Session, children map[string]*core.Child) ([]*core.Session, error) {
+	sessions := make([]*core.Session, 0, len(bundled))
+	for _, b := range bundled {
+		status := core.SessionStatus(b.Status)
+		if status != core.SessionStatusCompleted && status != core.SessionStatusExpired {
+			return nil, fmt.Errorf("%w: session %s has not ended (status %q)", ErrInvalidBundle, b.ID, b.Status)
+		}
+		for _, childID := range b.ChildIDs {
+			if _, ok := children[childID]; !ok {
+				return nil, fmt.Errorf("%w: session %s is for unknown child %s", ErrInvalidBundle, b.ID, childID)
+			}
+		}
+		session := &core.Session{
+			ID:               b.ID,
+			DeviceType:       b.DeviceType,
+			DeviceID:         b.DeviceID,
+			ChildIDs:         b.ChildIDs,
+			StartTime:        b.StartTime,
+			ExpectedDuration: b.ExpectedMinutes,
+			ActualDuration:   b.ActualMinutes,
+			Status:           status,
+			BreakMinutes:     b.BreakMinutes,
+			BreakExempt:      b.BreakExempt,
+			OverrideDowntime: b.OverrideDowntime,
+			OverrideLimits:   b.OverrideLimits,
+			IsMovieSession:   b.IsMovieSession,
+			EndReason:        b.EndReason,
+			InitiatorType:    b.InitiatorType,
+			InitiatorID:      b.InitiatorID,
+			Notes:            b.Notes,
+			Activity:         b.Activity,
+		}
+		if err := session.Validate(); err != nil {
+			return nil, fmt.Errorf("%w: session %s: %v", ErrInvalidBundle, b.ID, err)
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+// parseDay parses a day of a known child's history as a day key
+func (s *Service) parseDay(childID, date string, children map[string]*core.Child) (time.Time, error) {
+	if _, ok := children[childID]; !ok {
+		return time.Time{}, fmt.Errorf("%w: history of unknown child %s", ErrInvalidBundle, childID)
+	}
+	day, err := time.ParseInLocation(dateLayout, date, s.timezone)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: invalid date %q in the history of child %s", ErrInvalidBundle, date, childID)
+	}
+	return day, nil
+}
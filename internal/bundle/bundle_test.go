@@ -0,0 +1,115 @@
+package bundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"metron/config"
+	"metron/internal/core"
+	"metron/internal/storage/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setClock(t *testing.T, now time.Time) {
+	t.Helper()
+	original := core.Now
+	core.Now = func() time.Time { return now }
+	t.Cleanup(func() { core.Now = original })
+}
+
+func TestExportImport(t *testing.T) {
+	setClock(t, time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+	yesterday := time.Date(2026, time.March, 9, 0, 0, 0, 0, time.UTC)
+
+	source := memory.New(time.UTC)
+	birthdate := time.Date(2016, time.May, 4, 0, 0, 0, 0, time.UTC)
+	require.NoError(t, source.CreateChild(ctx, &core.Child{ID: "alice", Name: "Alice", PIN: "$2a$10$hash", WeekdayLimit: 60, WeekendLimit: 90,
+		BreakRule: &core.BreakRule{BreakAfterMinutes: 45, BreakDurationMinutes: 10}, Birthdate: &birthdate}))
+	require.NoError(t, source.CreateLimitChange(ctx, &core.LimitChange{ID: "lch_1", ChildID: "alice", WeekdayLimit: 75, WeekendLimit: 90,
+		EffectiveFrom: time.Date(2026, time.April, 1, 0, 0, 0, 0, time.UTC), CreatedBy: "api"}))
+	actual := 40
+	require.NoError(t, source.CreateSession(ctx, &core.Session{ID: "s1", DeviceType: "tv", DeviceID: "tv1", ChildIDs: []string{"alice"},
+		StartTime: yesterday.Add(17 * time.Hour), ExpectedDuration: 45, ActualDuration: &actual, Status: core.SessionStatusCompleted}))
+	require.NoError(t, source.CreateSession(ctx, &core.Session{ID: "s2", DeviceType: "tv", DeviceID: "tv1", ChildIDs: []string{"alice"},
+		StartTime: time.Date(2026, time.March, 10, 11, 0, 0, 0, time.UTC), ExpectedDuration: 30, Status: core.SessionStatusActive}))
+	require.NoError(t, source.IncrementDailyUsageSummary(ctx, "alice", yesterday, 40))
+	require.NoError(t, source.IncrementSessionCountSummary(ctx, "alice", yesterday))
+	require.NoError(t, source.CreateDailyAllocation(ctx, &core.DailyTimeAllocation{ChildID: "alice", Date: yesterday, BaseLimit: 60, BonusGranted: 15}))
+
+	cfg := NewConfig(&config.Config{Timezone: "UTC", Devices: []config.DeviceConfig{{ID: "tv1", Name: "TV", Type: "tv", Driver: "aqara"}}})
+	exported, err := NewService(source, cfg, time.UTC, nil).Export(ctx, true)
+	require.NoError(t, err)
+	require.Len(t, exported.Children, 1)
+	assert.Equal(t, "2016-05-04", exported.Children[0].Birthdate)
+	require.Len(t, exported.History.Sessions, 1, "running sessions are not exported")
+	require.Len(t, exported.History.Usage, 1)
+	assert.Equal(t, "2026-03-09", exported.History.Usage[0].Date)
+
+	// Round trip through JSON, as the CLI and API do
+	var buf bytes.Buffer
+	require.NoError(t, json.NewEncoder(&buf).Encode(exported))
+	b, err := Read(&buf)
+	require.NoError(t, err)
+
+	// Imported a week later, the child keeps its creation time
+	setClock(t, time.Date(2026, time.March, 17, 12, 0, 0, 0, time.UTC))
+	target := memory.New(time.UTC)
+	service := NewService(target, nil, time.UTC, nil)
+	result, err := service.Import(ctx, b)
+	require.NoError(t, err)
+	assert.Equal(t, Result{Children: 1, LimitChanges: 1, Sessions: 1, UsageDays: 1, Allocations: 1}, *result)
+
+	child, err := target.GetChild(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "$2a$10$hash", child.PIN)
+	assert.Equal(t, time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC), child.CreatedAt)
+	assert.Equal(t, 45, child.BreakRule.BreakAfterMinutes)
+	summary, err := target.GetDailyUsageSummary(ctx, "alice", yesterday)
+	require.NoError(t, err)
+	assert.Equal(t, 40, summary.MinutesUsed)
+	assert.Equal(t, 1, summary.SessionCount)
+	allocation, err := target.GetDailyAllocation(ctx, "alice", yesterday)
+	require.NoError(t, err)
+	assert.Equal(t, 15, allocation.BonusGranted)
+	changes, err := target.ListLimitChanges(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, "2026-04-01", changes[0].EffectiveFrom.Format("2006-01-02"))
+
+	// Importing again would duplicate the children
+	_, err = service.Import(ctx, b)
+	assert.ErrorIs(t, err, ErrChildExists)
+}
+
+func TestImport_Invalid(t *testing.T) {
+	ctx := context.Background()
+	service := NewService(memory.New(time.UTC), nil, time.UTC, nil)
+	child := Child{ID: "alice", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 90}
+
+	tests := map[string]*Bundle{
+		"format":          {Format: "other", Version: Version},
+		"version":         {Format: Format, Version: Version + 1},
+		"invalid child":   {Format: Format, Version: Version, Children: []Child{{ID: "bob", Name: "Bob"}}},
+		"duplicate child": {Format: Format, Version: Version, Children: []Child{child, child}},
+		"unknown child": {Format: Format, Version: Version, Children: []Child{child},
+			LimitChanges: []LimitChange{{ID: "lch_1", ChildID: "bob", WeekdayLimit: 60, WeekendLimit: 60, EffectiveFrom: "2026-04-01"}}},
+		"invalid date": {Format: Format, Version: Version, Children: []Child{child},
+			History: &History{Usage: []Usage{{ChildID: "alice", Date: "09.03.2026", MinutesUsed: 10}}}},
+		"running session": {Format: Format, Version: Version, Children: []Child{child},
+			History: &History{Sessions: []Session{{ID: "s1", DeviceType: "tv", ChildIDs: []string{"alice"}, ExpectedMinutes: 30, Status: "active"}}}},
+	}
+	for name, b := range tests {
+		_, err := service.Import(ctx, b)
+		assert.ErrorIs(t, err, ErrInvalidBundle, name)
+	}
+
+	// Nothing was written
+	_, err := service.storage.GetChild(ctx, "alice")
+	assert.ErrorIs(t, err, core.ErrChildNotFound)
+}
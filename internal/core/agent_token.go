@@ -0,0 +1,236 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"metron/internal/idgen"
+)
+
+// Agent token errors
+var (
+	ErrAgentTokenNotFound = errors.New("agent token not found")
+	ErrAgentTokenRevoked  = errors.New("agent token is revoked")
+)
+
+const (
+	// AgentTokenPrefix starts every issued agent token so leaked tokens are easy to recognise
+	AgentTokenPrefix = "mat_"
+
+	// agentTokenBytes is the amount of randomness in an issued token
+	agentTokenBytes = 32
+
+	// agentTokenHintLength is the number of trailing token characters kept for listings
+	agentTokenHintLength = 4
+
+	// agentTokenUsageInterval limits last-used updates: agents poll every few seconds
+	agentTokenUsageInterval = time.Minute
+)
+
+// AgentToken is a server-issued Bearer token for one device's agent
+// Only a SHA-256 hash of the token is stored; the token itself is shown once when issued
+type AgentToken struct {
+	ID         string
+	DeviceID   string
+	Label      string // Optional description (e.g., "Alice's laptop")
+	TokenHash  string // Hex SHA-256 of the token
+	Hint       string // Last characters of the token, to tell tokens apart
+	IssuedBy   string // Who issued the token (e.g., "telegram:12345", "api")
+	CreatedAt  time.Time
+	LastUsedAt *time.Time // nil until the agent first authenticates
+	RevokedAt  *time.Time // nil while the token is valid
+	RevokedBy  string
+}
+
+// IsRevoked returns true if the token can no longer be used
+func (t *AgentToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
+
+// HashAgentToken returns the hex SHA-256 of an agent token, as stored in AgentToken.TokenHash
+// Tokens carry 256 bits of randomness, so a fast unsalted hash is enough
+func HashAgentToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// AgentTokenStorage defines the interface for agent token persistence
+type AgentTokenStorage interface {
+	CreateAgentToken(ctx context.Context, token *AgentToken) error
+	GetAgentToken(ctx context.Context, id string) (*AgentToken, error)              // ErrAgentTokenNotFound if missing
+	GetAgentTokenByHash(ctx context.Context, tokenHash string) (*AgentToken, error) // ErrAgentTokenNotFound if missing
+	ListAgentTokens(ctx context.Context) ([]*AgentToken, error)                     // Newest first, revoked included
+	UpdateAgentToken(ctx context.Context, token *AgentToken) error                  // Updates last-used and revocation fields
+}
+
+// AgentTokenService issues, rotates, revokes and validates agent tokens
+type AgentTokenService struct {
+	storage AgentTokenStorage
+	logger  *slog.Logger
+	mu      sync.Mutex // Serializes rotate/revoke
+}
+
+// NewAgentTokenService creates a new agent token service
+func NewAgentTokenService(storage AgentTokenStorage, logger *slog.Logger) *AgentTokenService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &AgentTokenService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// Issue creates a new token for a device and returns it with the plain token
+// The plain token cannot be recovered later
+func (s *AgentTokenService) Issue(ctx context.Context, deviceID, label, issuedBy string) (*AgentToken, string, error) {
+	token, plain, err := newAgentToken(deviceID, label, issuedBy)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.storage.CreateAgentToken(ctx, token); err != nil {
+		return nil, "", err
+	}
+
+	s.logger.Info("Agent token issued",
+		"token_id", token.ID,
+		"device_id", deviceID,
+		"issued_by", issuedBy)
+
+	return token, plain, nil
+}
+
+// List returns the tokens of a device, or of all devices if deviceID is empty, newest first
+func (s *AgentTokenService) List(ctx context.Context, deviceID string) ([]*AgentToken, error) {
+	tokens, err := s.storage.ListAgentTokens(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if deviceID == "" {
+		return tokens, nil
+	}
+
+	filtered := make([]*AgentToken, 0, len(tokens))
+	for _, token := range tokens {
+		if token.DeviceID == deviceID {
+			filtered = append(filtered, token)
+		}
+	}
+	return filtered, nil
+}
+
+// Rotate revokes a token and issues a replacement for the same device and label
+// Returns ErrAgentTokenRevoked if the token was already revoked
+func (s *AgentTokenService) Rotate(ctx context.Context, id, rotatedBy string) (*AgentToken, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	old, err := s.revoke(ctx, id, rotatedBy)
+	if err != nil {
+		return nil, "", err
+	}
+
+	token, plain, err := newAgentToken(old.DeviceID, old.Label, rotatedBy)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.storage.CreateAgentToken(ctx, token); err != nil {
+		return nil, "", err
+	}
+
+	s.logger.Info("Agent token rotated",
+		"old_token_id", old.ID,
+		"token_id", token.ID,
+		"device_id", token.DeviceID,
+		"rotated_by", rotatedBy)
+
+	return token, plain, nil
+}
+
+// Revoke invalidates a token immediately
+// Returns the token with ErrAgentTokenRevoked if it was already revoked
+func (s *AgentTokenService) Revoke(ctx context.Context, id, revokedBy string) (*AgentToken, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	token, err := s.revoke(ctx, id, revokedBy)
+	if err != nil {
+		return token, err
+	}
+
+	s.logger.Info("Agent token revoked",
+		"token_id", token.ID,
+		"device_id", token.DeviceID,
+		"revoked_by", revokedBy)
+
+	return token, nil
+}
+
+// Authenticate returns the valid token matching a plain token presented by an agent
+// Returns ErrAgentTokenNotFound for unknown tokens and ErrAgentTokenRevoked for revoked ones
+func (s *AgentTokenService) Authenticate(ctx context.Context, plain string) (*AgentToken, error) {
+	token, err := s.storage.GetAgentTokenByHash(ctx, HashAgentToken(plain))
+	if err != nil {
+		return nil, err
+	}
+	if token.IsRevoked() {
+		return nil, ErrAgentTokenRevoked
+	}
+
+	now := Now()
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) >= agentTokenUsageInterval {
+		token.LastUsedAt = &now
+		if err := s.storage.UpdateAgentToken(ctx, token); err != nil {
+			// Usage tracking is informational; never reject a valid token over it
+			s.logger.Warn("Failed to record agent token use",
+				"token_id", token.ID,
+				"error", err)
+		}
+	}
+
+	return token, nil
+}
+
+// revoke marks a token revoked; the caller holds s.mu
+func (s *AgentTokenService) revoke(ctx context.Context, id, revokedBy string) (*AgentToken, error) {
+	token, err := s.storage.GetAgentToken(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if token.IsRevoked() {
+		return token, ErrAgentTokenRevoked
+	}
+
+	now := Now()
+	token.RevokedAt = &now
+	token.RevokedBy = revokedBy
+	if err := s.storage.UpdateAgentToken(ctx, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// newAgentToken generates a random token and its stored record
+func newAgentToken(deviceID, label, issuedBy string) (*AgentToken, string, error) {
+	random := make([]byte, agentTokenBytes)
+	if _, err := rand.Read(random); err != nil {
+		return nil, "", err
+	}
+	plain := AgentTokenPrefix + hex.EncodeToString(random)
+
+	return &AgentToken{
+		ID:        idgen.NewAgentToken(),
+		DeviceID:  deviceID,
+		Label:     label,
+		TokenHash: HashAgentToken(plain),
+		Hint:      plain[len(plain)-agentTokenHintLength:],
+		IssuedBy:  issuedBy,
+		CreatedAt: Now(),
+	}, plain, nil
+}
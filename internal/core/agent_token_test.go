@@ -0,0 +1,211 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAgentTokenStorage struct {
+	tokens []*AgentToken
+}
+
+func (m *mockAgentTokenStorage) CreateAgentToken(ctx context.Context, token *AgentToken) error {
+	copied := *token
+	m.tokens = append(m.tokens, &copied)
+	return nil
+}
+
+func (m *mockAgentTokenStorage) GetAgentToken(ctx context.Context, id string) (*AgentToken, error) {
+	for _, token := range m.tokens {
+		if token.ID == id {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, ErrAgentTokenNotFound
+}
+
+func (m *mockAgentTokenStorage) GetAgentTokenByHash(ctx context.Context, tokenHash string) (*AgentToken, error) {
+	for _, token := range m.tokens {
+		if token.TokenHash == tokenHash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, ErrAgentTokenNotFound
+}
+
+func (m *mockAgentTokenStorage) ListAgentTokens(ctx context.Context) ([]*AgentToken, error) {
+	tokens := make([]*AgentToken, len(m.tokens))
+	for i, token := range m.tokens {
+		copied := *token
+		tokens[len(m.tokens)-1-i] = &copied
+	}
+	return tokens, nil
+}
+
+func (m *mockAgentTokenStorage) UpdateAgentToken(ctx context.Context, token *AgentToken) error {
+	for i, existing := range m.tokens {
+		if existing.ID == token.ID {
+			copied := *token
+			m.tokens[i] = &copied
+		}
+	}
+	return nil
+}
+
+func TestAgentTokenService_Issue(t *testing.T) {
+	storage := &mockAgentTokenStorage{}
+	service := NewAgentTokenService(storage, nil)
+	ctx := context.Background()
+
+	token, plain, err := service.Issue(ctx, "win-pc1", "Alice's laptop", "api")
+	require.NoError(t, err)
+
+	assert.True(t, strings.HasPrefix(plain, AgentTokenPrefix))
+	assert.Equal(t, "win-pc1", token.DeviceID)
+	assert.Equal(t, "Alice's laptop", token.Label)
+	assert.Equal(t, plain[len(plain)-4:], token.Hint)
+	assert.False(t, token.IsRevoked())
+
+	// Only the hash is stored
+	require.Len(t, storage.tokens, 1)
+	assert.Equal(t, HashAgentToken(plain), storage.tokens[0].TokenHash)
+	assert.NotContains(t, storage.tokens[0].TokenHash, plain)
+
+	_, other, err := service.Issue(ctx, "win-pc1", "", "api")
+	require.NoError(t, err)
+	assert.NotEqual(t, plain, other, "each issued token is random")
+}
+
+func TestAgentTokenService_Authenticate(t *testing.T) {
+	storage := &mockAgentTokenStorage{}
+	service := NewAgentTokenService(storage, nil)
+	ctx := context.Background()
+
+	issued, plain, err := service.Issue(ctx, "win-pc1", "", "api")
+	require.NoError(t, err)
+
+	token, err := service.Authenticate(ctx, plain)
+	require.NoError(t, err)
+	assert.Equal(t, issued.ID, token.ID)
+
+	stored, err := storage.GetAgentToken(ctx, issued.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored.LastUsedAt, "use should be recorded")
+
+	_, err = service.Authenticate(ctx, plain+"x")
+	assert.ErrorIs(t, err, ErrAgentTokenNotFound)
+
+	_, err = service.Revoke(ctx, issued.ID, "api")
+	require.NoError(t, err)
+	_, err = service.Authenticate(ctx, plain)
+	assert.ErrorIs(t, err, ErrAgentTokenRevoked)
+}
+
+func TestAgentTokenService_AuthenticateThrottlesUsageUpdates(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	original := Now
+	Now = func() time.Time { return now }
+	t.Cleanup(func() { Now = original })
+
+	storage := &mockAgentTokenStorage{}
+	service := NewAgentTokenService(storage, nil)
+	ctx := context.Background()
+
+	issued, plain, err := service.Issue(ctx, "win-pc1", "", "api")
+	require.NoError(t, err)
+
+	_, err = service.Authenticate(ctx, plain)
+	require.NoError(t, err)
+
+	now = now.Add(30 * time.Second)
+	_, err = service.Authenticate(ctx, plain)
+	require.NoError(t, err)
+	stored, _ := storage.GetAgentToken(ctx, issued.ID)
+	assert.Equal(t, now.Add(-30*time.Second), *stored.LastUsedAt, "use within a minute is not recorded again")
+
+	now = now.Add(time.Minute)
+	_, err = service.Authenticate(ctx, plain)
+	require.NoError(t, err)
+	stored, _ = storage.GetAgentToken(ctx, issued.ID)
+	assert.Equal(t, now, *stored.LastUsedAt)
+}
+
+func TestAgentTokenService_Rotate(t *testing.T) {
+	storage := &mockAgentTokenStorage{}
+	service := NewAgentTokenService(storage, nil)
+	ctx := context.Background()
+
+	old, oldPlain, err := service.Issue(ctx, "win-pc1", "Alice's laptop", "api")
+	require.NoError(t, err)
+
+	token, plain, err := service.Rotate(ctx, old.ID, "telegram:1")
+	require.NoError(t, err)
+	assert.NotEqual(t, old.ID, token.ID)
+	assert.NotEqual(t, oldPlain, plain)
+	assert.Equal(t, "win-pc1", token.DeviceID)
+	assert.Equal(t, "Alice's laptop", token.Label)
+	assert.Equal(t, "telegram:1", token.IssuedBy)
+
+	_, err = service.Authenticate(ctx, oldPlain)
+	assert.ErrorIs(t, err, ErrAgentTokenRevoked)
+	_, err = service.Authenticate(ctx, plain)
+	assert.NoError(t, err)
+
+	// A revoked token cannot be rotated again
+	_, _, err = service.Rotate(ctx, old.ID, "api")
+	assert.ErrorIs(t, err, ErrAgentTokenRevoked)
+
+	_, _, err = service.Rotate(ctx, "agt_missing", "api")
+	assert.ErrorIs(t, err, ErrAgentTokenNotFound)
+}
+
+func TestAgentTokenService_Revoke(t *testing.T) {
+	storage := &mockAgentTokenStorage{}
+	service := NewAgentTokenService(storage, nil)
+	ctx := context.Background()
+
+	issued, _, err := service.Issue(ctx, "win-pc1", "", "api")
+	require.NoError(t, err)
+
+	revoked, err := service.Revoke(ctx, issued.ID, "api")
+	require.NoError(t, err)
+	assert.True(t, revoked.IsRevoked())
+	assert.Equal(t, "api", revoked.RevokedBy)
+
+	again, err := service.Revoke(ctx, issued.ID, "api")
+	assert.ErrorIs(t, err, ErrAgentTokenRevoked)
+	assert.Equal(t, issued.ID, again.ID, "the revoked token is returned with the error")
+
+	_, err = service.Revoke(ctx, "agt_missing", "api")
+	assert.ErrorIs(t, err, ErrAgentTokenNotFound)
+}
+
+func TestAgentTokenService_List(t *testing.T) {
+	storage := &mockAgentTokenStorage{}
+	service := NewAgentTokenService(storage, nil)
+	ctx := context.Background()
+
+	first, _, err := service.Issue(ctx, "win-pc1", "", "api")
+	require.NoError(t, err)
+	_, _, err = service.Issue(ctx, "win-pc2", "", "api")
+	require.NoError(t, err)
+	third, _, err := service.Issue(ctx, "win-pc1", "", "api")
+	require.NoError(t, err)
+
+	all, err := service.List(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	tokens, err := service.List(ctx, "win-pc1")
+	require.NoError(t, err)
+	require.Len(t, tokens, 2)
+	assert.Equal(t, third.ID, tokens[0].ID)
+	assert.Equal(t, first.ID, tokens[1].ID)
+}
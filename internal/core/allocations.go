@@ -0,0 +1,170 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// AllocationBackfillDays is how many past days Materialize fills in when a child's allocations are missing
+const AllocationBackfillDays = 31
+
+// Allocation history windows, in days
+const (
+	DefaultAllocationHistoryDays = 30
+	MaxAllocationHistoryDays     = 366
+)
+
+// ErrInvalidAllocationRange is returned for history ranges that end before they start or are too long
+var ErrInvalidAllocationRange = errors.New("invalid allocation date range")
+
+// AllocationStorage defines the storage interface needed for materializing and listing allocations
+type AllocationStorage interface {
+	GetChild(ctx context.Context, id string) (*Child, error)
+	ListChildren(ctx context.Context) ([]*Child, error)
+	ListChildAllocations(ctx context.Context, childID string, from, to time.Time) ([]*DailyTimeAllocation, error) // Days from..to inclusive, oldest first
+}
+
+// AllocationService materializes children's daily allocations and lists their history
+// Allocations are otherwise created lazily by the calculator, so days nobody queried
+// (e.g. while the server was down) would be missing from reports.
+type AllocationService struct {
+	storage    AllocationStorage
+	calculator *TimeCalculationService
+	current    map[string]time.Time // Day each child was last materialized for, so ticks skip storage
+	logger     *slog.Logger
+	mu         sync.Mutex
+}
+
+// NewAllocationService creates a new allocation service
+func NewAllocationService(storage AllocationStorage, calculator *TimeCalculationService, logger *slog.Logger) *AllocationService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &AllocationService{
+		storage:    storage,
+		calculator: calculator,
+		current:    make(map[string]time.Time),
+		logger:     logger,
+	}
+}
+
+// Materialize creates every child's allocation for today and for the missing days of the
+// last AllocationBackfillDays, and returns how many it created
+// It is called on every scheduler tick, so each child's allocations are materialized on
+// the first tick of their day (midnight in the child's timezone). Past days get the
+// child's current limits, since nothing recorded the limits they had. Errors are logged
+// per child and retried on the next call.
+func (s *AllocationService) Materialize(ctx context.Context) (int, error) {
+	children, err := s.storage.ListChildren(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := Now()
+	created := 0
+	for _, child := range children {
+		today := UsageDate(now, child.Location(s.calculator.timezone), s.calculator.timezone)
+		if current, ok := s.current[child.ID]; ok && current.Equal(today) {
+			continue
+		}
+
+		n, err := s.materialize(ctx, child, now)
+		created += n
+		if err != nil {
+			s.logger.Error("Failed to materialize allocations",
+				"child_id", child.ID,
+				"date", today.Format("2006-01-02"),
+				"error", err)
+			continue
+		}
+		s.current[child.ID] = today
+	}
+	return created, nil
+}
+
+// materialize creates the child's missing allocations up to today
+func (s *AllocationService) materialize(ctx context.Context, child *Child, now time.Time) (int, error) {
+	loc := child.Location(s.calculator.timezone)
+	local := now.In(loc)
+
+	// Noon of every day, so DST changes never skip or repeat one
+	days := make([]time.Time, 0, AllocationBackfillDays+1)
+	for i := AllocationBackfillDays; i >= 0; i-- {
+		day := time.Date(local.Year(), local.Month(), local.Day()-i, 12, 0, 0, 0, loc)
+		endOfDay := time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, loc)
+		// Days before the child was added have no allocation
+		if !child.CreatedAt.IsZero() && !child.CreatedAt.Before(endOfDay) {
+			continue
+		}
+		days = append(days, day)
+	}
+	if len(days) == 0 {
+		return 0, nil
+	}
+
+	from := UsageDate(days[0], loc, s.calculator.timezone)
+	to := UsageDate(days[len(days)-1], loc, s.calculator.timezone)
+	existing, err := s.storage.ListChildAllocations(ctx, child.ID, from, to)
+	if err != nil {
+		return 0, err
+	}
+	materialized := make(map[string]bool, len(existing))
+	for _, allocation := range existing {
+		materialized[allocation.Date.In(s.calculator.timezone).Format("2006-01-02")] = true
+	}
+
+	created := 0
+	for _, day := range days {
+		if materialized[UsageDate(day, loc, s.calculator.timezone).Format("2006-01-02")] {
+			continue
+		}
+		// Creates the allocation with the child's limit for the day
+		if _, err := s.calculator.GetAvailableTime(ctx, child.ID, day); err != nil {
+			return created, err
+		}
+		created++
+	}
+
+	if created > 0 {
+		s.logger.Info("Materialized allocations",
+			"child_id", child.ID,
+			"created", created)
+	}
+	return created, nil
+}
+
+// History returns a child's allocations from one calendar day to another (inclusive), oldest first
+// Only the dates of from and to are used. A zero to means the child's today and a zero from
+// the DefaultAllocationHistoryDays ending with to. Days without an allocation are missing.
+func (s *AllocationService) History(ctx context.Context, childID string, from, to time.Time) ([]*DailyTimeAllocation, error) {
+	child, err := s.storage.GetChild(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+
+	tz := s.calculator.timezone
+	if to.IsZero() {
+		to = UsageDate(Now(), child.Location(tz), tz)
+	}
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, tz)
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -(DefaultAllocationHistoryDays - 1))
+	}
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, tz)
+
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: %s is before %s", ErrInvalidAllocationRange, to.Format("2006-01-02"), from.Format("2006-01-02"))
+	}
+	if from.AddDate(0, 0, MaxAllocationHistoryDays).Before(to.AddDate(0, 0, 1)) {
+		return nil, fmt.Errorf("%w: at most %d days", ErrInvalidAllocationRange, MaxAllocationHistoryDays)
+	}
+
+	return s.storage.ListChildAllocations(ctx, childID, from, to)
+}
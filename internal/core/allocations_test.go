@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAllocationStorage struct {
+	*mockStorage
+	allocations map[string]*DailyTimeAllocation // By child ID and date
+}
+
+func newMockAllocationStorage() *mockAllocationStorage {
+	return &mockAllocationStorage{
+		mockStorage: newMockStorage(),
+		allocations: make(map[string]*DailyTimeAllocation),
+	}
+}
+
+func (m *mockAllocationStorage) GetDailyAllocation(ctx context.Context, childID string, date time.Time) (*DailyTimeAllocation, error) {
+	allocation, ok := m.allocations[childID+"/"+date.Format("2006-01-02")]
+	if !ok {
+		return nil, ErrAllocationNotFound
+	}
+	copied := *allocation
+	return &copied, nil
+}
+
+func (m *mockAllocationStorage) CreateDailyAllocation(ctx context.Context, allocation *DailyTimeAllocation) error {
+	copied := *allocation
+	m.allocations[allocation.ChildID+"/"+allocation.Date.Format("2006-01-02")] = &copied
+	return nil
+}
+
+func (m *mockAllocationStorage) ListChildAllocations(ctx context.Context, childID string, from, to time.Time) ([]*DailyTimeAllocation, error) {
+	var allocations []*DailyTimeAllocation
+	for _, allocation := range m.allocations {
+		if allocation.ChildID == childID && !allocation.Date.Before(from) && !allocation.Date.After(to) {
+			copied := *allocation
+			allocations = append(allocations, &copied)
+		}
+	}
+	sort.Slice(allocations, func(i, j int) bool {
+		return allocations[i].Date.Before(allocations[j].Date)
+	})
+	return allocations, nil
+}
+
+func TestAllocationService_Materialize(t *testing.T) {
+	// Tuesday; the child was added on Saturday and the server was down since
+	setClock := setDowntimeOverrideClock(t, time.Date(2026, time.March, 10, 0, 30, 0, 0, time.UTC))
+	ctx := context.Background()
+
+	storage := newMockAllocationStorage()
+	storage.CreateChild(ctx, &Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 90,
+		CreatedAt: time.Date(2026, time.March, 7, 18, 0, 0, 0, time.UTC)})
+	calculator := NewTimeCalculationService(storage, time.UTC)
+	service := NewAllocationService(storage, calculator, nil)
+
+	created, err := service.Materialize(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 4, created, "Saturday to Tuesday")
+
+	history, err := service.History(ctx, "child1", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, history, 4)
+	assert.Equal(t, "2026-03-07", history[0].Date.Format("2006-01-02"))
+	assert.Equal(t, 90, history[0].BaseLimit)
+	assert.Equal(t, "2026-03-10", history[3].Date.Format("2006-01-02"))
+	assert.Equal(t, 60, history[3].BaseLimit)
+
+	// Nothing more until the next day
+	created, err = service.Materialize(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, created)
+
+	setClock(time.Date(2026, time.March, 11, 0, 1, 0, 0, time.UTC))
+	created, err = service.Materialize(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, created)
+
+	// A restarted server finds nothing missing
+	created, err = NewAllocationService(storage, calculator, nil).Materialize(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, created)
+}
+
+func TestAllocationService_History(t *testing.T) {
+	setDowntimeOverrideClock(t, time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+
+	storage := newMockAllocationStorage()
+	storage.CreateChild(ctx, &Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 90})
+	calculator := NewTimeCalculationService(storage, time.UTC)
+	service := NewAllocationService(storage, calculator, nil)
+	for day := 1; day <= 10; day++ {
+		_, err := calculator.GetAvailableTime(ctx, "child1", time.Date(2026, time.March, day, 12, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+	}
+
+	history, err := service.History(ctx, "child1",
+		time.Date(2026, time.March, 3, 0, 0, 0, 0, time.UTC), time.Date(2026, time.March, 5, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, "2026-03-03", history[0].Date.Format("2006-01-02"))
+	assert.Equal(t, "2026-03-05", history[2].Date.Format("2006-01-02"))
+
+	_, err = service.History(ctx, "child1", time.Date(2026, time.March, 5, 0, 0, 0, 0, time.UTC), time.Date(2026, time.March, 3, 0, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, ErrInvalidAllocationRange)
+
+	_, err = service.History(ctx, "child1", time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2026, time.March, 3, 0, 0, 0, 0, time.UTC))
+	assert.ErrorIs(t, err, ErrInvalidAllocationRange)
+
+	_, err = service.History(ctx, "nobody", time.Time{}, time.Time{})
+	assert.ErrorIs(t, err, ErrChildNotFound)
+}
@@ -0,0 +1,242 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+)
+
+// App usage report windows, in days
+const (
+	DefaultAppReportDays = 7
+	MaxAppReportDays     = 92
+)
+
+// MaxAppNameLength is the longest app name accepted from usage sources
+const MaxAppNameLength = 100
+
+// ErrInvalidAppUsage is returned for app usage reports with a missing or too long app name,
+// minutes outside a day, or a date in the future
+var ErrInvalidAppUsage = errors.New("invalid app usage")
+
+// AppUsage is how long a child used an app on one day, as reported by an external usage source
+// (e.g., a YouTube history exporter or a phone agent)
+type AppUsage struct {
+	ChildID   string
+	Date      time.Time // Midnight in the server timezone, of the child's calendar day
+	Source    string    // Usage source that reported the minutes (e.g., "youtube")
+	App       string    // App name as reported (e.g., "YouTube", "Minecraft")
+	Minutes   int       // The day's total so far
+	UpdatedAt time.Time
+}
+
+// AppMinutes is the minutes of one app in a usage report
+type AppMinutes struct {
+	App     string
+	Minutes int
+}
+
+// AppUsageStorage defines the interface for app usage persistence
+type AppUsageStorage interface {
+	GetChild(ctx context.Context, id string) (*Child, error)
+	SaveAppUsage(ctx context.Context, usage *AppUsage) error                                   // Replaces the minutes of the child, day, source and app
+	ListAppUsage(ctx context.Context, childID string, from, to time.Time) ([]*AppUsage, error) // Days from..to inclusive, oldest first; empty childID lists all
+}
+
+// AppUsageTotal is how long a child used an app over a report's range
+type AppUsageTotal struct {
+	ChildID        string
+	Source         string
+	App            string
+	Minutes        int
+	DaysUsed       int
+	AverageMinutes float64 // Minutes per day of the range
+}
+
+// AppUsageDay is the app usage reported for one day
+type AppUsageDay struct {
+	Date time.Time   // Midnight in the server timezone
+	Apps []*AppUsage // Most minutes first
+}
+
+// AppUsageReport is the app usage over a range of days
+type AppUsageReport struct {
+	From time.Time
+	To   time.Time
+	Apps []*AppUsageTotal // Most minutes first
+	Days []*AppUsageDay   // Oldest first
+}
+
+// AppUsageService records app usage reported by external usage sources and reports on it
+// Sources report each app's total for the day, so a report replaces the app's earlier
+// minutes and retries are harmless. App usage is informational: it is not charged against
+// children's daily time, which sessions already account for.
+type AppUsageService struct {
+	storage  AppUsageStorage
+	timezone *time.Location
+	logger   *slog.Logger
+}
+
+// NewAppUsageService creates a new app usage service
+func NewAppUsageService(storage AppUsageStorage, timezone *time.Location, logger *slog.Logger) *AppUsageService {
+	if timezone == nil {
+		timezone = time.UTC
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &AppUsageService{
+		storage:  storage,
+		timezone: timezone,
+		logger:   logger,
+	}
+}
+
+// Record stores the minutes a source reports for a child's apps on a day
+// A zero date means the child's today. The report is validated as a whole: nothing is
+// stored if any app is invalid.
+func (s *AppUsageService) Record(ctx context.Context, source, childID string, date time.Time, apps []AppMinutes) ([]*AppUsage, error) {
+	child, err := s.storage.GetChild(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := Now()
+	today := UsageDate(now, child.Location(s.timezone), s.timezone)
+	if date.IsZero() {
+		date = today
+	}
+	date = time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, s.timezone)
+	if date.After(today) {
+		return nil, fmt.Errorf("%w: %s is in the future", ErrInvalidAppUsage, date.Format("2006-01-02"))
+	}
+	if len(apps) == 0 {
+		return nil, fmt.Errorf("%w: no apps", ErrInvalidAppUsage)
+	}
+
+	usage := make([]*AppUsage, 0, len(apps))
+	seen := make(map[string]bool, len(apps))
+	for _, app := range apps {
+		name := strings.TrimSpace(app.App)
+		switch {
+		case name == "":
+			return nil, fmt.Errorf("%w: app name is required", ErrInvalidAppUsage)
+		case len(name) > MaxAppNameLength:
+			return nil, fmt.Errorf("%w: app name is longer than %d characters", ErrInvalidAppUsage, MaxAppNameLength)
+		case app.Minutes < 0 || app.Minutes > 24*60:
+			return nil, fmt.Errorf("%w: %s minutes must be between 0 and 1440", ErrInvalidAppUsage, name)
+		case seen[strings.ToLower(name)]:
+			return nil, fmt.Errorf("%w: %s is reported twice", ErrInvalidAppUsage, name)
+		}
+		seen[strings.ToLower(name)] = true
+		usage = append(usage, &AppUsage{
+			ChildID:   childID,
+			Date:      date,
+			Source:    source,
+			App:       name,
+			Minutes:   app.Minutes,
+			UpdatedAt: now,
+		})
+	}
+
+	for _, entry := range usage {
+		if err := s.storage.SaveAppUsage(ctx, entry); err != nil {
+			return nil, fmt.Errorf("failed to save app usage: %w", err)
+		}
+	}
+
+	s.logger.Debug("App usage recorded",
+		"source", source,
+		"child_id", childID,
+		"date", date.Format("2006-01-02"),
+		"apps", len(usage))
+
+	return usage, nil
+}
+
+// Report returns the app usage from one day to another, inclusive, optionally for one child
+// A zero to means today (the child's today if childID is set), a zero from the
+// DefaultAppReportDays ending with to.
+func (s *AppUsageService) Report(ctx context.Context, childID string, from, to time.Time) (*AppUsageReport, error) {
+	loc := s.timezone
+	if childID != "" {
+		child, err := s.storage.GetChild(ctx, childID)
+		if err != nil {
+			return nil, err
+		}
+		loc = child.Location(s.timezone)
+	}
+
+	if to.IsZero() {
+		to = UsageDate(Now(), loc, s.timezone)
+	}
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, s.timezone)
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -(DefaultAppReportDays - 1))
+	}
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, s.timezone)
+
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: %s is before %s", ErrInvalidReportRange, to.Format("2006-01-02"), from.Format("2006-01-02"))
+	}
+	n := dayIndex(to, from) + 1
+	if n > MaxAppReportDays {
+		return nil, fmt.Errorf("%w: at most %d days", ErrInvalidReportRange, MaxAppReportDays)
+	}
+
+	usage, err := s.storage.ListAppUsage(ctx, childID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list app usage: %w", err)
+	}
+
+	type appKey struct{ childID, source, app string }
+	report := &AppUsageReport{From: from, To: to, Days: make([]*AppUsageDay, n)}
+	for i := range report.Days {
+		report.Days[i] = &AppUsageDay{Date: from.AddDate(0, 0, i), Apps: []*AppUsage{}}
+	}
+	totals := make(map[appKey]*AppUsageTotal)
+	for _, entry := range usage {
+		index := dayIndex(entry.Date, from)
+		if index < 0 || index >= n || entry.Minutes == 0 {
+			continue
+		}
+		day := report.Days[index]
+		day.Apps = append(day.Apps, entry)
+
+		key := appKey{entry.ChildID, entry.Source, entry.App}
+		total, ok := totals[key]
+		if !ok {
+			total = &AppUsageTotal{ChildID: entry.ChildID, Source: entry.Source, App: entry.App}
+			totals[key] = total
+		}
+		total.Minutes += entry.Minutes
+		total.DaysUsed++
+	}
+
+	for _, day := range report.Days {
+		slices.SortFunc(day.Apps, func(a, b *AppUsage) int {
+			if c := cmp.Compare(b.Minutes, a.Minutes); c != 0 {
+				return c
+			}
+			return cmp.Or(cmp.Compare(a.ChildID, b.ChildID), cmp.Compare(a.Source, b.Source), cmp.Compare(a.App, b.App))
+		})
+	}
+
+	report.Apps = make([]*AppUsageTotal, 0, len(totals))
+	for _, total := range totals {
+		total.AverageMinutes = float64(total.Minutes) / float64(n)
+		report.Apps = append(report.Apps, total)
+	}
+	slices.SortFunc(report.Apps, func(a, b *AppUsageTotal) int {
+		if c := cmp.Compare(b.Minutes, a.Minutes); c != 0 {
+			return c
+		}
+		return cmp.Or(cmp.Compare(a.ChildID, b.ChildID), cmp.Compare(a.Source, b.Source), cmp.Compare(a.App, b.App))
+	})
+	return report, nil
+}
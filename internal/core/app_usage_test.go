@@ -0,0 +1,150 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockAppUsageStorage struct {
+	*mockStorage
+	usage []*AppUsage
+}
+
+func (m *mockAppUsageStorage) SaveAppUsage(ctx context.Context, usage *AppUsage) error {
+	copied := *usage
+	for i, existing := range m.usage {
+		if existing.ChildID == usage.ChildID && existing.Date.Equal(usage.Date) &&
+			existing.Source == usage.Source && existing.App == usage.App {
+			m.usage[i] = &copied
+			return nil
+		}
+	}
+	m.usage = append(m.usage, &copied)
+	return nil
+}
+
+func (m *mockAppUsageStorage) ListAppUsage(ctx context.Context, childID string, from, to time.Time) ([]*AppUsage, error) {
+	var usage []*AppUsage
+	for _, entry := range m.usage {
+		if (childID == "" || entry.ChildID == childID) && !entry.Date.Before(from) && !entry.Date.After(to) {
+			copied := *entry
+			usage = append(usage, &copied)
+		}
+	}
+	return usage, nil
+}
+
+// newAppUsageTestService returns an app usage service with the clock at noon and two children
+func newAppUsageTestService(t *testing.T) (*AppUsageService, time.Time) {
+	now := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)
+	original := Now
+	Now = func() time.Time { return now }
+	t.Cleanup(func() { Now = original })
+
+	storage := &mockAppUsageStorage{mockStorage: newMockStorage()}
+	storage.CreateChild(context.Background(), &Child{ID: "child1", Name: "Alice", WeekdayLimit: 90, WeekendLimit: 90})
+	storage.CreateChild(context.Background(), &Child{ID: "child2", Name: "Bob", WeekdayLimit: 60, WeekendLimit: 60})
+	return NewAppUsageService(storage, time.UTC, nil), now
+}
+
+func TestAppUsageService_Record(t *testing.T) {
+	service, now := newAppUsageTestService(t)
+	ctx := context.Background()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	// Without a date the minutes count for the child's today
+	usage, err := service.Record(ctx, "youtube", "child1", time.Time{}, []AppMinutes{{App: " YouTube ", Minutes: 25}})
+	require.NoError(t, err)
+	require.Len(t, usage, 1)
+	assert.Equal(t, "YouTube", usage[0].App)
+	assert.Equal(t, "youtube", usage[0].Source)
+	assert.True(t, usage[0].Date.Equal(today))
+
+	// A later report replaces the day's minutes
+	_, err = service.Record(ctx, "youtube", "child1", today, []AppMinutes{{App: "YouTube", Minutes: 40}})
+	require.NoError(t, err)
+	report, err := service.Report(ctx, "child1", today, today)
+	require.NoError(t, err)
+	require.Len(t, report.Apps, 1)
+	assert.Equal(t, 40, report.Apps[0].Minutes)
+
+	_, err = service.Record(ctx, "youtube", "missing", today, []AppMinutes{{App: "YouTube", Minutes: 5}})
+	assert.ErrorIs(t, err, ErrChildNotFound)
+
+	tests := []struct {
+		name string
+		date time.Time
+		apps []AppMinutes
+	}{
+		{"no apps", today, nil},
+		{"empty app name", today, []AppMinutes{{App: " ", Minutes: 5}}},
+		{"negative minutes", today, []AppMinutes{{App: "YouTube", Minutes: -1}}},
+		{"more than a day", today, []AppMinutes{{App: "YouTube", Minutes: 1441}}},
+		{"app reported twice", today, []AppMinutes{{App: "YouTube", Minutes: 5}, {App: "youtube", Minutes: 10}}},
+		{"future date", today.AddDate(0, 0, 1), []AppMinutes{{App: "YouTube", Minutes: 5}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Record(ctx, "youtube", "child1", tt.date, tt.apps)
+			assert.ErrorIs(t, err, ErrInvalidAppUsage)
+		})
+	}
+
+	// Invalid reports store nothing
+	_, err = service.Record(ctx, "phone", "child1", today, []AppMinutes{{App: "Minecraft", Minutes: 10}, {App: "", Minutes: 5}})
+	assert.ErrorIs(t, err, ErrInvalidAppUsage)
+	report, err = service.Report(ctx, "child1", today, today)
+	require.NoError(t, err)
+	assert.Len(t, report.Apps, 1)
+}
+
+func TestAppUsageService_Report(t *testing.T) {
+	service, now := newAppUsageTestService(t)
+	ctx := context.Background()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	yesterday := today.AddDate(0, 0, -1)
+
+	_, err := service.Record(ctx, "youtube", "child1", yesterday, []AppMinutes{{App: "YouTube", Minutes: 50}})
+	require.NoError(t, err)
+	_, err = service.Record(ctx, "youtube", "child1", today, []AppMinutes{{App: "YouTube", Minutes: 20}})
+	require.NoError(t, err)
+	_, err = service.Record(ctx, "phone", "child2", today, []AppMinutes{{App: "Minecraft", Minutes: 30}, {App: "Chess", Minutes: 0}})
+	require.NoError(t, err)
+
+	// The last 7 days by default, most used apps first; apps without minutes are left out
+	report, err := service.Report(ctx, "", time.Time{}, time.Time{})
+	require.NoError(t, err)
+	assert.True(t, report.To.Equal(today))
+	assert.True(t, report.From.Equal(today.AddDate(0, 0, -6)))
+	require.Len(t, report.Days, 7)
+	require.Len(t, report.Apps, 2)
+	assert.Equal(t, "YouTube", report.Apps[0].App)
+	assert.Equal(t, 70, report.Apps[0].Minutes)
+	assert.Equal(t, 2, report.Apps[0].DaysUsed)
+	assert.InDelta(t, 10.0, report.Apps[0].AverageMinutes, 0.001)
+	assert.Equal(t, "Minecraft", report.Apps[1].App)
+	assert.Equal(t, "child2", report.Apps[1].ChildID)
+
+	last := report.Days[6]
+	assert.True(t, last.Date.Equal(today))
+	require.Len(t, last.Apps, 2)
+	assert.Equal(t, "Minecraft", last.Apps[0].App)
+	assert.Equal(t, "YouTube", last.Apps[1].App)
+
+	// One child
+	report, err = service.Report(ctx, "child2", today, today)
+	require.NoError(t, err)
+	require.Len(t, report.Apps, 1)
+	assert.Equal(t, "Minecraft", report.Apps[0].App)
+
+	_, err = service.Report(ctx, "missing", today, today)
+	assert.ErrorIs(t, err, ErrChildNotFound)
+	_, err = service.Report(ctx, "", today, yesterday)
+	assert.ErrorIs(t, err, ErrInvalidReportRange)
+	_, err = service.Report(ctx, "", today.AddDate(0, 0, -MaxAppReportDays), today)
+	assert.ErrorIs(t, err, ErrInvalidReportRange)
+}
@@ -0,0 +1,86 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"metron/internal/idgen"
+)
+
+// Audit actions
+const (
+	AuditLimitsChanged   = "limits.changed"   // A child's limits were changed with immediate effect
+	AuditLimitsScheduled = "limits.scheduled" // A limit change was scheduled for a later day
+	AuditLimitsApplied   = "limits.applied"   // A scheduled limit change took effect
+	AuditLimitsCancelled = "limits.cancelled" // A scheduled limit change was cancelled
+
+	AuditProfileProposed  = "profile.proposed"  // A birthday moved a child into a new limit profile
+	AuditProfileConfirmed = "profile.confirmed" // A parent applied a proposed limit profile
+	AuditProfileDismissed = "profile.dismissed" // A parent kept the child's limits instead
+
+	AuditSessionOverride = "session.override" // A parent let a session ignore downtime or limits
+
+	AuditUsageCorrected = "usage.corrected" // A parent corrected the minutes charged to a child's day
+)
+
+// AuditActorSchedule is recorded as the actor of changes made automatically when their time comes
+const AuditActorSchedule = "schedule"
+
+// AuditEntry records who changed what and when
+type AuditEntry struct {
+	ID        string
+	Action    string // One of the Audit* actions
+	ChildID   string // Empty for changes not about one child
+	Actor     string // Who made the change (e.g., "telegram:12345", "api", "schedule")
+	Details   string // Human-readable description of the change
+	CreatedAt time.Time
+}
+
+// AuditStorage defines the interface for audit log persistence
+type AuditStorage interface {
+	CreateAuditEntry(ctx context.Context, entry *AuditEntry) error
+	ListAuditEntries(ctx context.Context, childID string, limit int) ([]*AuditEntry, error) // Newest first; empty childID lists all
+}
+
+// AuditService records changes to the audit log
+// Recording is best effort: a failure is logged and does not fail the change itself.
+type AuditService struct {
+	storage AuditStorage
+	logger  *slog.Logger
+}
+
+// NewAuditService creates a new audit service
+func NewAuditService(storage AuditStorage, logger *slog.Logger) *AuditService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &AuditService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// Record adds an entry to the audit log
+func (s *AuditService) Record(ctx context.Context, action, childID, actor, details string) {
+	entry := &AuditEntry{
+		ID:        idgen.NewAuditEntry(),
+		Action:    action,
+		ChildID:   childID,
+		Actor:     actor,
+		Details:   details,
+		CreatedAt: Now(),
+	}
+	if err := s.storage.CreateAuditEntry(ctx, entry); err != nil {
+		s.logger.Error("Failed to record audit entry",
+			"action", action,
+			"child_id", childID,
+			"actor", actor,
+			"error", err)
+	}
+}
+
+// List returns the latest entries, newest first, optionally for one child
+func (s *AuditService) List(ctx context.Context, childID string, limit int) ([]*AuditEntry, error) {
+	return s.storage.ListAuditEntries(ctx, childID, limit)
+}
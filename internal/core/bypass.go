@@ -18,7 +18,7 @@ func (b *DeviceBypass) IsExpired() bool {
 	if b.ExpiresAt == nil {
 		return false
 	}
-	return time.Now().After(*b.ExpiresAt)
+	return Now().After(*b.ExpiresAt)
 }
 
 // IsActive returns true if the bypass is enabled and not expired
@@ -31,7 +31,7 @@ func (b *DeviceBypass) RemainingTime() *time.Duration {
 	if b.ExpiresAt == nil {
 		return nil
 	}
-	remaining := time.Until(*b.ExpiresAt)
+	remaining := b.ExpiresAt.Sub(Now())
 	if remaining < 0 {
 		remaining = 0
 	}
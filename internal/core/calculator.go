@@ -23,6 +23,7 @@ type TimeCalculationStorage interface {
 	// Allocation queries
 	GetDailyAllocation(ctx context.Context, childID string, date time.Time) (*DailyTimeAllocation, error)
 	CreateDailyAllocation(ctx context.Context, allocation *DailyTimeAllocation) error
+	UpdateDailyAllocation(ctx context.Context, allocation *DailyTimeAllocation) error
 
 	// Usage queries
 	GetDailyUsageSummary(ctx context.Context, childID string, date time.Time) (*DailyUsageSummary, error)
@@ -32,6 +33,13 @@ type TimeCalculationStorage interface {
 	GetChild(ctx context.Context, id string) (*Child, error)
 }
 
+// DailyBatchStorage lists a day's allocations and usage summaries of all children at once
+// Storage implementing it lets the calculator compute every child's time without queries per child
+type DailyBatchStorage interface {
+	ListDailyAllocations(ctx context.Context, date time.Time) ([]*DailyTimeAllocation, error)
+	ListDailyUsageSummaries(ctx context.Context, date time.Time) ([]*DailyUsageSummary, error) // Children without usage are missing
+}
+
 // AvailableTimeResult contains calculated available time
 type AvailableTimeResult struct {
 	BaseLimit      int // From schedule (weekday/weekend)
@@ -44,6 +52,7 @@ type ConsumedTimeResult struct {
 	FromCompletedSessions int // Minutes from daily_usage_summaries
 	FromActiveSessions    int // Minutes from active sessions (elapsed)
 	TotalConsumed         int // completed + active
+	SessionCount          int // Sessions started on the day, from daily_usage_summaries
 }
 
 // RemainingTimeResult contains calculated remaining time
@@ -65,8 +74,9 @@ func NewTimeCalculationService(storage TimeCalculationStorage, timezone *time.Lo
 }
 
 // GetAvailableTime calculates total time allocated for a child today
+// date is any instant of the day; it is mapped to the child's calendar day (see UsageDate)
 func (s *TimeCalculationService) GetAvailableTime(ctx context.Context, childID string, date time.Time) (*AvailableTimeResult, error) {
-	normalizedDate := s.normalizeDate(date)
+	normalizedDate := s.UsageDate(ctx, childID, date)
 
 	// Get or create allocation
 	allocation, err := s.getOrCreateAllocation(ctx, childID, normalizedDate)
@@ -83,7 +93,7 @@ func (s *TimeCalculationService) GetAvailableTime(ctx context.Context, childID s
 
 // GetConsumedTime calculates total time consumed by a child today
 func (s *TimeCalculationService) GetConsumedTime(ctx context.Context, childID string, date time.Time) (*ConsumedTimeResult, error) {
-	normalizedDate := s.normalizeDate(date)
+	normalizedDate := s.UsageDate(ctx, childID, date)
 
 	// Get completed session usage
 	summary, err := s.storage.GetDailyUsageSummary(ctx, childID, normalizedDate)
@@ -103,10 +113,15 @@ func (s *TimeCalculationService) GetConsumedTime(ctx context.Context, childID st
 		return nil, err
 	}
 
+	return s.consumedTime(summary, childID, activeSessions), nil
+}
+
+// consumedTime adds the elapsed minutes of the child's active sessions to the day's summary
+func (s *TimeCalculationService) consumedTime(summary *DailyUsageSummary, childID string, activeSessions []*SessionUsageRecord) *ConsumedTimeResult {
 	activeMinutes := 0
 	for _, session := range activeSessions {
-		// Skip movie sessions - they don't count against individual quotas
-		if session.IsMovieSession {
+		// Skip movie and exempt activity sessions - they don't count against individual quotas
+		if session.IsMovieSession || session.Activity != "" {
 			continue
 		}
 		// Check if this session includes the child
@@ -123,7 +138,8 @@ func (s *TimeCalculationService) GetConsumedTime(ctx context.Context, childID st
 		FromCompletedSessions: summary.MinutesUsed,
 		FromActiveSessions:    activeMinutes,
 		TotalConsumed:         summary.MinutesUsed + activeMinutes,
-	}, nil
+		SessionCount:          summary.SessionCount,
+	}
 }
 
 // GetRemainingTime calculates remaining time for a child today
@@ -138,6 +154,99 @@ func (s *TimeCalculationService) GetRemainingTime(ctx context.Context, childID s
 		return nil, err
 	}
 
+	return remainingTime(available, consumed), nil
+}
+
+// GetRemainingTimeForChildren calculates the remaining time of several children today, keyed by child ID
+// Results match GetRemainingTime, but active sessions are read once and, if storage implements
+// DailyBatchStorage, allocations and usage summaries once per calendar day instead of once per child
+func (s *TimeCalculationService) GetRemainingTimeForChildren(ctx context.Context, children []*Child, date time.Time) (map[string]*RemainingTimeResult, error) {
+	activeSessions, err := s.storage.ListActiveSessionRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	batch, _ := s.storage.(DailyBatchStorage)
+	days := make(map[string]*dailyRecords) // Children in other timezones can be on another day
+
+	results := make(map[string]*RemainingTimeResult, len(children))
+	for _, child := range children {
+		normalizedDate := UsageDate(date, child.Location(s.timezone), s.timezone)
+
+		var allocation *DailyTimeAllocation
+		var summary *DailyUsageSummary
+		if batch != nil {
+			key := normalizedDate.Format("2006-01-02")
+			day, ok := days[key]
+			if !ok {
+				day, err = listDailyRecords(ctx, batch, normalizedDate)
+				if err != nil {
+					return nil, err
+				}
+				days[key] = day
+			}
+			allocation = day.allocations[child.ID]
+			summary = day.summaries[child.ID]
+		}
+
+		if allocation == nil {
+			allocation, err = s.getOrCreateAllocation(ctx, child.ID, normalizedDate)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if summary == nil && batch == nil {
+			// A missing summary may be an error, as in GetConsumedTime
+			summary, _ = s.storage.GetDailyUsageSummary(ctx, child.ID, normalizedDate)
+		}
+		if summary == nil {
+			// No completed sessions yet
+			summary = &DailyUsageSummary{ChildID: child.ID, Date: normalizedDate}
+		}
+
+		available := &AvailableTimeResult{
+			BaseLimit:      allocation.BaseLimit,
+			BonusGranted:   allocation.BonusGranted,
+			TotalAvailable: allocation.BaseLimit + allocation.BonusGranted,
+		}
+		results[child.ID] = remainingTime(available, s.consumedTime(summary, child.ID, activeSessions))
+	}
+
+	return results, nil
+}
+
+// dailyRecords holds a day's allocations and usage summaries, keyed by child ID
+type dailyRecords struct {
+	allocations map[string]*DailyTimeAllocation
+	summaries   map[string]*DailyUsageSummary
+}
+
+// listDailyRecords reads a day's allocations and usage summaries of all children
+func listDailyRecords(ctx context.Context, storage DailyBatchStorage, date time.Time) (*dailyRecords, error) {
+	allocations, err := storage.ListDailyAllocations(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+	summaries, err := storage.ListDailyUsageSummaries(ctx, date)
+	if err != nil {
+		return nil, err
+	}
+
+	day := &dailyRecords{
+		allocations: make(map[string]*DailyTimeAllocation, len(allocations)),
+		summaries:   make(map[string]*DailyUsageSummary, len(summaries)),
+	}
+	for _, allocation := range allocations {
+		day.allocations[allocation.ChildID] = allocation
+	}
+	for _, summary := range summaries {
+		day.summaries[summary.ChildID] = summary
+	}
+	return day, nil
+}
+
+// remainingTime subtracts consumed from available time, never going below zero
+func remainingTime(available *AvailableTimeResult, consumed *ConsumedTimeResult) *RemainingTimeResult {
 	totalRemaining := available.TotalAvailable - consumed.TotalConsumed
 	if totalRemaining < 0 {
 		totalRemaining = 0
@@ -147,7 +256,7 @@ func (s *TimeCalculationService) GetRemainingTime(ctx context.Context, childID s
 		Available:      *available,
 		Consumed:       *consumed,
 		RemainingTotal: totalRemaining,
-	}, nil
+	}
 }
 
 // GetRemainingTimeForExtension calculates remaining time for extending a specific session
@@ -163,9 +272,9 @@ func (s *TimeCalculationService) GetRemainingTimeForExtension(
 	date time.Time,
 	currentSessionID string,
 ) (*RemainingTimeResult, error) {
-	normalizedDate := s.normalizeDate(date)
+	normalizedDate := s.UsageDate(ctx, childID, date)
 
-	available, err := s.GetAvailableTime(ctx, childID, normalizedDate)
+	available, err := s.GetAvailableTime(ctx, childID, date)
 	if err != nil {
 		return nil, err
 	}
@@ -190,8 +299,8 @@ func (s *TimeCalculationService) GetRemainingTimeForExtension(
 
 	activeMinutes := 0
 	for _, session := range activeSessions {
-		// Skip movie sessions - they don't count against individual quotas
-		if session.IsMovieSession {
+		// Skip movie and exempt activity sessions - they don't count against individual quotas
+		if session.IsMovieSession || session.Activity != "" {
 			continue
 		}
 		// Check if this session includes the child
@@ -228,6 +337,45 @@ func (s *TimeCalculationService) GetRemainingTimeForExtension(
 	}, nil
 }
 
+// Charge policy
+//
+// Every child in a session is charged the session's chargeable minutes when it ends:
+//   - The session is charged from StartTime to its end point:
+//     early stop (parent, API, lockdown) and downtime stop use the stop time,
+//     expiry uses the scheduler tick that noticed it,
+//     idle auto-stop uses the last activity reported by the device agent,
+//     and a child removed from a shared session is charged up to the removal time.
+//   - Time past the planned end (StartTime + ExpectedDuration) is never charged.
+//     This covers late scheduler ticks and sessions expired after a crash or restart.
+//   - Mandatory breaks are refunded: completed breaks (BreakMinutes) and the elapsed
+//     part of a break in progress are subtracted.
+//   - Movie sessions charge nothing, and neither do exempt activity sessions, which
+//     draw on the activity's own budget (see ExemptActivity).
+//   - Children whose tracking is paused (vacation mode) when the session ends are not
+//     charged; callers skip them (see TrackingPauseService).
+//   - Children who join a running session are charged like the original children,
+//     from StartTime; nothing is charged when they join.
+//
+// Active sessions count towards consumed time using the same rule with "now" as the end
+// point, so remaining time always matches what will be charged when the session ends.
+
+// ChargeableMinutes returns the minutes each child in the session is charged
+// if the session ends at end (see the charge policy above)
+func (s *TimeCalculationService) ChargeableMinutes(session *Session, end time.Time) int {
+	if session.IsMovieSession || session.IsExempt() {
+		return 0
+	}
+	return s.SessionMinutes(session, end)
+}
+
+// SessionMinutes returns the minutes the session ran if it ends at end, counted like
+// charged time (capped at the planned end, breaks excluded) but also for movie and exempt
+// activity sessions. It is stored as the session's ActualDuration when the session ends.
+func (s *TimeCalculationService) SessionMinutes(session *Session, end time.Time) int {
+	return chargeableMinutes(session.StartTime, session.ExpectedDuration, session.BreakMinutes,
+		session.LastBreakAt, session.BreakEndsAt, end)
+}
+
 // GetSessionElapsed calculates elapsed time for a session
 func (s *TimeCalculationService) GetSessionElapsed(session *SessionUsageRecord) int {
 	if session.Status != SessionStatusActive {
@@ -239,15 +387,37 @@ func (s *TimeCalculationService) GetSessionElapsed(session *SessionUsageRecord)
 		return session.ExpectedDuration
 	}
 
-	// For active sessions, calculate elapsed time
-	elapsed := int(time.Since(session.StartTime).Minutes())
+	// For active sessions, count what would be charged if the session ended now
+	return chargeableMinutes(session.StartTime, session.ExpectedDuration, session.BreakMinutes,
+		session.LastBreakAt, session.BreakEndsAt, Now())
+}
 
-	// Clamp to expected duration (don't count overtime)
-	if elapsed > session.ExpectedDuration {
-		elapsed = session.ExpectedDuration
+// chargeableMinutes implements the charge policy for a session ending at end
+func chargeableMinutes(start time.Time, expectedDuration, breakMinutes int, lastBreakAt, breakEndsAt *time.Time, end time.Time) int {
+	// Never charge past the planned end
+	plannedEnd := start.Add(time.Duration(expectedDuration) * time.Minute)
+	if end.After(plannedEnd) {
+		end = plannedEnd
+	}
+	if !end.After(start) {
+		return 0
+	}
+
+	charged := end.Sub(start) - time.Duration(breakMinutes)*time.Minute
+
+	// Refund the elapsed part of a break in progress
+	if lastBreakAt != nil && breakEndsAt != nil && lastBreakAt.Before(end) {
+		breakEnd := *breakEndsAt
+		if breakEnd.After(end) {
+			breakEnd = end
+		}
+		charged -= breakEnd.Sub(*lastBreakAt)
 	}
 
-	return elapsed
+	if charged < 0 {
+		return 0
+	}
+	return int(charged.Minutes())
 }
 
 // GetSessionRemaining calculates remaining time for a session
@@ -257,7 +427,7 @@ func (s *TimeCalculationService) GetSessionRemaining(session *SessionUsageRecord
 	}
 
 	endTime := session.StartTime.Add(time.Duration(session.ExpectedDuration) * time.Minute)
-	remaining := int(time.Until(endTime).Minutes())
+	remaining := int(endTime.Sub(Now()).Minutes())
 
 	if remaining < 0 {
 		return 0
@@ -296,8 +466,8 @@ func (s *TimeCalculationService) getOrCreateAllocation(ctx context.Context, chil
 		Date:         date,
 		BaseLimit:    baseLimit,
 		BonusGranted: 0,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		CreatedAt:    Now(),
+		UpdatedAt:    Now(),
 	}
 
 	// Store it
@@ -308,11 +478,23 @@ func (s *TimeCalculationService) getOrCreateAllocation(ctx context.Context, chil
 	return allocation, nil
 }
 
-// normalizeDate normalizes a date to start of day in the configured timezone
-func (s *TimeCalculationService) normalizeDate(t time.Time) time.Time {
-	inTZ := t.In(s.timezone)
-	year, month, day := inTZ.Date()
-	return time.Date(year, month, day, 0, 0, 0, 0, s.timezone)
+// UsageDate returns the date key for the child's calendar day containing t
+// The day is evaluated in the child's timezone override, falling back to the configured timezone
+func (s *TimeCalculationService) UsageDate(ctx context.Context, childID string, t time.Time) time.Time {
+	return UsageDate(t, s.ChildLocation(ctx, childID), s.timezone)
+}
+
+// ChildLocation returns the timezone used for the child's calendar day
+// Falls back to the configured timezone if the child has no override or cannot be loaded
+func (s *TimeCalculationService) ChildLocation(ctx context.Context, childID string) *time.Location {
+	if s.storage == nil {
+		return s.timezone
+	}
+	child, err := s.storage.GetChild(ctx, childID)
+	if err != nil {
+		return s.timezone
+	}
+	return child.Location(s.timezone)
 }
 
 // Additional error for allocation not found
@@ -0,0 +1,264 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"pgregory.net/rapid"
+)
+
+// Property-based tests for TimeCalculationService
+// rapid generates random children, allocations, completed usage, overlapping active sessions,
+// timezones and clock instants, and shrinks any failing case to a minimal one.
+// Run more cases with: go test ./internal/core -run Property -rapid.checks=10000
+
+var propertyTimezones = []string{
+	"UTC",
+	"Europe/Amsterdam",
+	"America/New_York",
+	"America/Los_Angeles",
+	"Asia/Kolkata",
+	"Asia/Tokyo",
+	"Pacific/Auckland",
+	"Pacific/Kiritimati",
+	"Pacific/Pago_Pago",
+}
+
+// propertyEpoch is the earliest generated instant; it spans DST changes in every zone above
+var propertyEpoch = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func drawLocation(t *rapid.T, label string) *time.Location {
+	name := rapid.SampledFrom(propertyTimezones).Draw(t, label)
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("load %s: %v", name, err)
+	}
+	return loc
+}
+
+func drawInstant(t *rapid.T, label string) time.Time {
+	minutes := rapid.IntRange(0, 366*24*60).Draw(t, label)
+	return propertyEpoch.Add(time.Duration(minutes) * time.Minute)
+}
+
+// drawSession generates an active session around now, possibly in a break
+func drawSession(t *rapid.T, i int, now time.Time, childID string) *SessionUsageRecord {
+	start := now.Add(-time.Duration(rapid.IntRange(-60, 600).Draw(t, fmt.Sprintf("session%d_started_ago", i))) * time.Minute)
+	session := &SessionUsageRecord{
+		ID:               fmt.Sprintf("session-%d", i),
+		DeviceID:         "tv1",
+		ChildIDs:         []string{"other"},
+		StartTime:        start,
+		ExpectedDuration: rapid.IntRange(1, 240).Draw(t, fmt.Sprintf("session%d_duration", i)),
+		Status:           SessionStatusActive,
+		BreakMinutes:     rapid.IntRange(0, 60).Draw(t, fmt.Sprintf("session%d_break_minutes", i)),
+		IsMovieSession:   rapid.Bool().Draw(t, fmt.Sprintf("session%d_movie", i)),
+	}
+	if rapid.Bool().Draw(t, fmt.Sprintf("session%d_has_child", i)) {
+		session.ChildIDs = append(session.ChildIDs, childID)
+	}
+	if rapid.Bool().Draw(t, fmt.Sprintf("session%d_in_break", i)) {
+		lastBreak := start.Add(time.Duration(rapid.IntRange(0, 240).Draw(t, fmt.Sprintf("session%d_break_at", i))) * time.Minute)
+		breakEnds := lastBreak.Add(time.Duration(rapid.IntRange(1, 30).Draw(t, fmt.Sprintf("session%d_break_length", i))) * time.Minute)
+		session.LastBreakAt = &lastBreak
+		session.BreakEndsAt = &breakEnds
+	}
+	return session
+}
+
+// setPropertyClock freezes Now for one generated case; the original clock is restored by the test
+func setPropertyClock(t *testing.T, now *time.Time) {
+	original := Now
+	Now = func() time.Time { return *now }
+	t.Cleanup(func() { Now = original })
+}
+
+func TestTimeCalculationService_Property_RemainingTime(t *testing.T) {
+	var now time.Time
+	setPropertyClock(t, &now)
+
+	rapid.Check(t, func(t *rapid.T) {
+		ctx := context.Background()
+		server := drawLocation(t, "server_timezone")
+		childLocation := drawLocation(t, "child_timezone")
+		now = drawInstant(t, "now")
+
+		child := &Child{
+			ID:           "child1",
+			Name:         "Alice",
+			WeekdayLimit: rapid.IntRange(0, 600).Draw(t, "weekday_limit"),
+			WeekendLimit: rapid.IntRange(0, 600).Draw(t, "weekend_limit"),
+			Timezone:     childLocation.String(),
+		}
+		storage := newMockTimeCalcStorage()
+		storage.children[child.ID] = child
+		calc := NewTimeCalculationService(storage, server)
+		date := calc.UsageDate(ctx, child.ID, now)
+
+		bonus := rapid.IntRange(0, 300).Draw(t, "bonus")
+		if bonus > 0 {
+			storage.allocations[child.ID+"-"+date.Format("2006-01-02")] = &DailyTimeAllocation{
+				ChildID:      child.ID,
+				Date:         date,
+				BaseLimit:    child.GetDailyLimit(date),
+				BonusGranted: bonus,
+			}
+		}
+		completed := rapid.IntRange(0, 900).Draw(t, "completed_minutes")
+		storage.summaries[child.ID+"-"+date.Format("2006-01-02")] = &DailyUsageSummary{
+			ChildID:     child.ID,
+			Date:        date,
+			MinutesUsed: completed,
+		}
+
+		// Sessions may overlap; the child may be in several of them
+		committed := 0
+		for i := range rapid.IntRange(0, 4).Draw(t, "sessions") {
+			session := drawSession(t, i, now, child.ID)
+			storage.sessions = append(storage.sessions, session)
+			if !session.IsMovieSession && len(session.ChildIDs) > 1 {
+				committed += session.ExpectedDuration
+			}
+		}
+
+		remaining, err := calc.GetRemainingTime(ctx, child.ID, now)
+		if err != nil {
+			t.Fatalf("GetRemainingTime: %v", err)
+		}
+
+		// Available time follows the child's calendar day
+		localYear, localMonth, localDay := now.In(childLocation).Date()
+		local := time.Date(localYear, localMonth, localDay, 12, 0, 0, 0, time.UTC)
+		if base := child.GetDailyLimit(local); remaining.Available.BaseLimit != base {
+			t.Fatalf("base limit %d, expected %d for %s", remaining.Available.BaseLimit, base, local.Weekday())
+		}
+		if remaining.Available.TotalAvailable != remaining.Available.BaseLimit+remaining.Available.BonusGranted {
+			t.Fatalf("total available %d != base %d + bonus %d",
+				remaining.Available.TotalAvailable, remaining.Available.BaseLimit, remaining.Available.BonusGranted)
+		}
+
+		if remaining.RemainingTotal < 0 {
+			t.Fatalf("remaining %d is negative", remaining.RemainingTotal)
+		}
+		if want := max(0, remaining.Available.TotalAvailable-remaining.Consumed.TotalConsumed); remaining.RemainingTotal != want {
+			t.Fatalf("remaining %d, expected %d", remaining.RemainingTotal, want)
+		}
+
+		consumed := remaining.Consumed
+		if consumed.FromCompletedSessions != completed {
+			t.Fatalf("completed %d, expected %d", consumed.FromCompletedSessions, completed)
+		}
+		if consumed.TotalConsumed != consumed.FromCompletedSessions+consumed.FromActiveSessions {
+			t.Fatalf("total consumed %d != completed %d + active %d",
+				consumed.TotalConsumed, consumed.FromCompletedSessions, consumed.FromActiveSessions)
+		}
+		// Overshoot bound: active sessions are never charged past their planned end
+		if consumed.FromActiveSessions < 0 || consumed.FromActiveSessions > committed {
+			t.Fatalf("active sessions charged %d, expected 0..%d", consumed.FromActiveSessions, committed)
+		}
+
+		// Extensions count the extended session's committed time, so they never see more time
+		for _, session := range storage.sessions {
+			forExtension, err := calc.GetRemainingTimeForExtension(ctx, child.ID, now, session.ID)
+			if err != nil {
+				t.Fatalf("GetRemainingTimeForExtension: %v", err)
+			}
+			if forExtension.RemainingTotal < 0 || forExtension.RemainingTotal > remaining.RemainingTotal {
+				t.Fatalf("remaining for extension of %s is %d, expected 0..%d",
+					session.ID, forExtension.RemainingTotal, remaining.RemainingTotal)
+			}
+		}
+	})
+}
+
+func TestTimeCalculationService_Property_ChargeableMinutes(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		start := drawInstant(t, "start")
+		session := &Session{
+			StartTime:        start,
+			ExpectedDuration: rapid.IntRange(0, 240).Draw(t, "duration"),
+			BreakMinutes:     rapid.IntRange(0, 60).Draw(t, "break_minutes"),
+			IsMovieSession:   rapid.Bool().Draw(t, "movie"),
+		}
+		if rapid.Bool().Draw(t, "in_break") {
+			lastBreak := start.Add(time.Duration(rapid.IntRange(0, 240).Draw(t, "break_at")) * time.Minute)
+			breakEnds := lastBreak.Add(time.Duration(rapid.IntRange(1, 30).Draw(t, "break_length")) * time.Minute)
+			session.LastBreakAt = &lastBreak
+			session.BreakEndsAt = &breakEnds
+		}
+
+		calc := NewTimeCalculationService(nil, time.UTC)
+		first := rapid.IntRange(-60, 600).Draw(t, "first_end")
+		second := rapid.IntRange(first, 600).Draw(t, "second_end")
+		earlier := calc.ChargeableMinutes(session, start.Add(time.Duration(first)*time.Minute))
+		later := calc.ChargeableMinutes(session, start.Add(time.Duration(second)*time.Minute))
+
+		if earlier < 0 || later > session.ExpectedDuration {
+			t.Fatalf("charged %d..%d, expected within 0..%d", earlier, later, session.ExpectedDuration)
+		}
+		if later < earlier {
+			t.Fatalf("charge decreased from %d to %d as the session went on", earlier, later)
+		}
+		if first <= 0 && earlier != 0 {
+			t.Fatalf("charged %d for a session that ended before it started", earlier)
+		}
+		if session.IsMovieSession && later != 0 {
+			t.Fatalf("movie session charged %d", later)
+		}
+	})
+}
+
+func TestTimeCalculationService_Property_UsageDateStable(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		ctx := context.Background()
+		server := drawLocation(t, "server_timezone")
+		childLocation := drawLocation(t, "child_timezone")
+		instant := drawInstant(t, "instant")
+
+		storage := newMockTimeCalcStorage()
+		storage.children["child1"] = &Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 120, Timezone: childLocation.String()}
+		calc := NewTimeCalculationService(storage, server)
+
+		date := calc.UsageDate(ctx, "child1", instant)
+
+		// The key is midnight in the server timezone of the child's calendar day
+		if date.Location() != server || date.Hour() != 0 || date.Minute() != 0 || date.Second() != 0 {
+			t.Fatalf("date key %s is not midnight in %s", date, server)
+		}
+		year, month, day := instant.In(childLocation).Date()
+		if y, m, d := date.Date(); y != year || m != month || d != day {
+			t.Fatalf("date key %s, expected %04d-%02d-%02d", date.Format("2006-01-02"), year, month, day)
+		}
+
+		// Every instant of the same local day maps to the same key
+		localMidnight := time.Date(year, month, day, 0, 0, 0, 0, childLocation)
+		nextMidnight := time.Date(year, month, day+1, 0, 0, 0, 0, childLocation)
+		offset := rapid.Int64Range(0, int64(nextMidnight.Sub(localMidnight))-1).Draw(t, "offset")
+		sameDay := localMidnight.Add(time.Duration(offset))
+		if other := calc.UsageDate(ctx, "child1", sameDay); !other.Equal(date) {
+			t.Fatalf("%s and %s are on the same local day but map to %s and %s",
+				instant, sameDay, date.Format("2006-01-02"), other.Format("2006-01-02"))
+		}
+		if next := calc.UsageDate(ctx, "child1", nextMidnight); next.Equal(date) {
+			t.Fatalf("next local day %s maps to the same key %s", nextMidnight, date.Format("2006-01-02"))
+		}
+
+		// Normalizing twice in the child's own timezone changes nothing
+		if UsageDate(UsageDate(instant, childLocation, childLocation), childLocation, childLocation) != UsageDate(instant, childLocation, childLocation) {
+			t.Fatalf("UsageDate is not idempotent for %s in %s", instant, childLocation)
+		}
+
+		// Queries on the same local day share one allocation
+		if _, err := calc.GetAvailableTime(ctx, "child1", instant); err != nil {
+			t.Fatalf("GetAvailableTime: %v", err)
+		}
+		if _, err := calc.GetAvailableTime(ctx, "child1", sameDay); err != nil {
+			t.Fatalf("GetAvailableTime: %v", err)
+		}
+		if len(storage.allocations) != 1 {
+			t.Fatalf("%d allocations created for one local day", len(storage.allocations))
+		}
+	})
+}
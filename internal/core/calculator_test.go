@@ -41,6 +41,12 @@ func (m *mockTimeCalcStorage) CreateDailyAllocation(ctx context.Context, allocat
 	return nil
 }
 
+func (m *mockTimeCalcStorage) UpdateDailyAllocation(ctx context.Context, allocation *DailyTimeAllocation) error {
+	key := allocation.ChildID + "-" + allocation.Date.Format("2006-01-02")
+	m.allocations[key] = allocation
+	return nil
+}
+
 func (m *mockTimeCalcStorage) GetDailyUsageSummary(ctx context.Context, childID string, date time.Time) (*DailyUsageSummary, error) {
 	key := childID + "-" + date.Format("2006-01-02")
 	summary, ok := m.summaries[key]
@@ -457,6 +463,81 @@ func TestTimeCalculationService_GetSessionElapsed_Completed(t *testing.T) {
 	assert.Equal(t, 25, elapsed, "Should use actual duration for completed sessions")
 }
 
+func TestTimeCalculationService_ChargeableMinutes(t *testing.T) {
+	service := NewTimeCalculationService(newMockTimeCalcStorage(), time.UTC)
+	start := time.Date(2025, 1, 6, 15, 0, 0, 0, time.UTC)
+	at := func(minutes int) *time.Time {
+		t := start.Add(time.Duration(minutes) * time.Minute)
+		return &t
+	}
+
+	tests := []struct {
+		name     string
+		session  *Session
+		end      time.Time
+		expected int
+	}{
+		{
+			name:     "early stop charges elapsed time",
+			session:  &Session{StartTime: start, ExpectedDuration: 60},
+			end:      *at(25),
+			expected: 25,
+		},
+		{
+			name:     "expiry charges the planned duration",
+			session:  &Session{StartTime: start, ExpectedDuration: 30},
+			end:      *at(31),
+			expected: 30,
+		},
+		{
+			name:     "crash recovery never charges past the planned end",
+			session:  &Session{StartTime: start, ExpectedDuration: 30},
+			end:      *at(8 * 60),
+			expected: 30,
+		},
+		{
+			name:     "completed breaks are refunded",
+			session:  &Session{StartTime: start, ExpectedDuration: 60, BreakMinutes: 10},
+			end:      *at(40),
+			expected: 30,
+		},
+		{
+			name:     "elapsed part of a break in progress is refunded",
+			session:  &Session{StartTime: start, ExpectedDuration: 60, LastBreakAt: at(30), BreakEndsAt: at(40)},
+			end:      *at(35),
+			expected: 30,
+		},
+		{
+			name:     "break in progress past the planned end is refunded up to the end",
+			session:  &Session{StartTime: start, ExpectedDuration: 60, BreakMinutes: 5, LastBreakAt: at(50), BreakEndsAt: at(70)},
+			end:      *at(90),
+			expected: 45,
+		},
+		{
+			name:     "movie sessions are never charged",
+			session:  &Session{StartTime: start, ExpectedDuration: 120, IsMovieSession: true},
+			end:      *at(90),
+			expected: 0,
+		},
+		{
+			name:     "end before start charges nothing",
+			session:  &Session{StartTime: start, ExpectedDuration: 30},
+			end:      *at(-5),
+			expected: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, service.ChargeableMinutes(tt.session, tt.end))
+		})
+	}
+
+	// Movie sessions still record how long they ran
+	movie := &Session{StartTime: start, ExpectedDuration: 120, IsMovieSession: true}
+	assert.Equal(t, 90, service.SessionMinutes(movie, *at(90)))
+}
+
 func TestTimeCalculationService_GetSessionRemaining_Active(t *testing.T) {
 	service := NewTimeCalculationService(newMockTimeCalcStorage(), time.UTC)
 
@@ -531,3 +612,129 @@ func TestTimeCalculationService_TimezoneHandling(t *testing.T) {
 
 	assert.NotNil(t, result)
 }
+
+func TestTimeCalculationService_ChildTimezoneOverride(t *testing.T) {
+	storage := newMockTimeCalcStorage()
+	storage.children["child1"] = &Child{
+		ID:           "child1",
+		Name:         "Local Child",
+		WeekdayLimit: 60,
+		WeekendLimit: 120,
+	}
+	storage.children["child2"] = &Child{
+		ID:           "child2",
+		Name:         "Away Child",
+		WeekdayLimit: 60,
+		WeekendLimit: 120,
+		Timezone:     "America/Los_Angeles",
+	}
+
+	riga, err := time.LoadLocation("Europe/Riga")
+	require.NoError(t, err)
+	service := NewTimeCalculationService(storage, riga)
+
+	// Saturday 05:00 in Riga is still Friday 19:00 in Los Angeles
+	instant := time.Date(2025, 1, 18, 5, 0, 0, 0, riga)
+
+	local, err := service.GetAvailableTime(context.Background(), "child1", instant)
+	require.NoError(t, err)
+	assert.Equal(t, 120, local.BaseLimit, "Server timezone child is on Saturday")
+
+	away, err := service.GetAvailableTime(context.Background(), "child2", instant)
+	require.NoError(t, err)
+	assert.Equal(t, 60, away.BaseLimit, "Overridden child is still on Friday")
+
+	// Usage dates are keyed as midnight in the server timezone
+	assert.Equal(t, time.Date(2025, 1, 18, 0, 0, 0, 0, riga), service.UsageDate(context.Background(), "child1", instant))
+	assert.Equal(t, time.Date(2025, 1, 17, 0, 0, 0, 0, riga), service.UsageDate(context.Background(), "child2", instant))
+	_, exists := storage.allocations["child2-2025-01-17"]
+	assert.True(t, exists, "Allocation should use the child's calendar day")
+
+	// Unknown children fall back to the server timezone
+	assert.Equal(t, riga, service.ChildLocation(context.Background(), "missing"))
+}
+
+// mockBatchTimeCalcStorage lists a day's records at once and counts per-child reads
+type mockBatchTimeCalcStorage struct {
+	*mockTimeCalcStorage
+	childReads int
+}
+
+func (m *mockBatchTimeCalcStorage) GetDailyAllocation(ctx context.Context, childID string, date time.Time) (*DailyTimeAllocation, error) {
+	m.childReads++
+	return m.mockTimeCalcStorage.GetDailyAllocation(ctx, childID, date)
+}
+
+func (m *mockBatchTimeCalcStorage) GetDailyUsageSummary(ctx context.Context, childID string, date time.Time) (*DailyUsageSummary, error) {
+	m.childReads++
+	return m.mockTimeCalcStorage.GetDailyUsageSummary(ctx, childID, date)
+}
+
+func (m *mockBatchTimeCalcStorage) ListDailyAllocations(ctx context.Context, date time.Time) ([]*DailyTimeAllocation, error) {
+	var allocations []*DailyTimeAllocation
+	for _, allocation := range m.allocations {
+		if allocation.Date.Equal(date) {
+			allocations = append(allocations, allocation)
+		}
+	}
+	return allocations, nil
+}
+
+func (m *mockBatchTimeCalcStorage) ListDailyUsageSummaries(ctx context.Context, date time.Time) ([]*DailyUsageSummary, error) {
+	var summaries []*DailyUsageSummary
+	for _, summary := range m.summaries {
+		if summary.Date.Equal(date) {
+			summaries = append(summaries, summary)
+		}
+	}
+	return summaries, nil
+}
+
+func TestTimeCalculationService_GetRemainingTimeForChildren(t *testing.T) {
+	storage := &mockBatchTimeCalcStorage{mockTimeCalcStorage: newMockTimeCalcStorage()}
+	date := makeWeekday()
+	alice := &Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 120}
+	bob := &Child{ID: "child2", Name: "Bob", WeekdayLimit: 90, WeekendLimit: 120}
+	storage.children[alice.ID] = alice
+	storage.children[bob.ID] = bob
+
+	storage.allocations["child1-"+date.Format("2006-01-02")] = &DailyTimeAllocation{ChildID: "child1", Date: date, BaseLimit: 60, BonusGranted: 15}
+	storage.summaries["child1-"+date.Format("2006-01-02")] = &DailyUsageSummary{ChildID: "child1", Date: date, MinutesUsed: 25, SessionCount: 2}
+	storage.sessions = []*SessionUsageRecord{
+		{
+			ID:               "session1",
+			ChildIDs:         []string{"child1", "child2"},
+			StartTime:        time.Now().Add(-10 * time.Minute),
+			ExpectedDuration: 30,
+			Status:           SessionStatusActive,
+		},
+	}
+
+	service := NewTimeCalculationService(storage, time.UTC)
+
+	results, err := service.GetRemainingTimeForChildren(context.Background(), []*Child{alice, bob}, date)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, 75, results["child1"].Available.TotalAvailable)
+	assert.Equal(t, 35, results["child1"].Consumed.TotalConsumed)
+	assert.Equal(t, 2, results["child1"].Consumed.SessionCount)
+	assert.Equal(t, 40, results["child1"].RemainingTotal)
+
+	// Bob had no allocation yet, so it is created from his limits
+	assert.Equal(t, 90, results["child2"].Available.TotalAvailable)
+	assert.Equal(t, 10, results["child2"].Consumed.TotalConsumed)
+	assert.Equal(t, 80, results["child2"].RemainingTotal)
+	_, exists := storage.allocations["child2-"+date.Format("2006-01-02")]
+	assert.True(t, exists, "Allocation should be created on first access")
+
+	// Only the missing allocation was read per child
+	assert.Equal(t, 1, storage.childReads)
+
+	// Results match the per-child calculation
+	for _, child := range []*Child{alice, bob} {
+		single, err := service.GetRemainingTime(context.Background(), child.ID, date)
+		require.NoError(t, err)
+		assert.Equal(t, single, results[child.ID], child.Name)
+	}
+}
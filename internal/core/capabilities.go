@@ -0,0 +1,54 @@
+package core
+
+import "context"
+
+// DriverCapabilities describes what features a driver supports.
+// Core consults them before optional calls, so drivers without a feature are skipped
+// instead of failing (see CapabilitiesOf).
+type DriverCapabilities struct {
+	SupportsWarnings   bool
+	SupportsLiveState  bool
+	SupportsScheduling bool
+	SupportsExtension  bool // ExtendSession is called when a session is extended
+	SupportsBreaks     bool // StartBreak/EndBreak are called for the "break" break action
+}
+
+// CapableDriver is implemented by drivers that declare their capabilities
+type CapableDriver interface {
+	Capabilities() DriverCapabilities
+}
+
+// ExtendableDriver is implemented by drivers that act on session extensions
+type ExtendableDriver interface {
+	ExtendSession(ctx context.Context, session *Session, additionalMinutes int) error
+}
+
+// BreakableDriver is implemented by drivers with a dedicated break action
+type BreakableDriver interface {
+	StartBreak(ctx context.Context, session *Session, breakMinutes int) error
+	EndBreak(ctx context.Context, session *Session) error
+}
+
+// CapabilitiesOf returns the capabilities of a driver. Declared capabilities win;
+// drivers that declare none are assumed to support warnings (ApplyWarning is part of
+// every driver) and whatever optional interfaces they implement. Extension and break
+// support also require the driver to implement the matching interface.
+func CapabilitiesOf(driver interface{}) DriverCapabilities {
+	_, extendable := driver.(ExtendableDriver)
+	_, breakable := driver.(BreakableDriver)
+
+	capable, ok := driver.(CapableDriver)
+	if !ok {
+		return DriverCapabilities{
+			SupportsWarnings:   true,
+			SupportsScheduling: true,
+			SupportsExtension:  extendable,
+			SupportsBreaks:     breakable,
+		}
+	}
+
+	caps := capable.Capabilities()
+	caps.SupportsExtension = caps.SupportsExtension && extendable
+	caps.SupportsBreaks = caps.SupportsBreaks && breakable
+	return caps
+}
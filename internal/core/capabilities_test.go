@@ -0,0 +1,59 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// plainDriver declares no capabilities and has no optional methods
+type plainDriver struct{}
+
+// breakDriver has a break action but declares no capabilities
+type breakDriver struct{}
+
+func (breakDriver) StartBreak(ctx context.Context, session *Session, breakMinutes int) error {
+	return nil
+}
+
+func (breakDriver) EndBreak(ctx context.Context, session *Session) error {
+	return nil
+}
+
+// declaringDriver declares extensions and breaks without implementing them
+type declaringDriver struct{}
+
+func (declaringDriver) Capabilities() DriverCapabilities {
+	return DriverCapabilities{SupportsLiveState: true, SupportsExtension: true, SupportsBreaks: true}
+}
+
+func TestCapabilitiesOf(t *testing.T) {
+	tests := []struct {
+		name   string
+		driver interface{}
+		want   DriverCapabilities
+	}{
+		{
+			name:   "undeclared driver supports warnings",
+			driver: plainDriver{},
+			want:   DriverCapabilities{SupportsWarnings: true, SupportsScheduling: true},
+		},
+		{
+			name:   "undeclared driver with break methods",
+			driver: breakDriver{},
+			want:   DriverCapabilities{SupportsWarnings: true, SupportsScheduling: true, SupportsBreaks: true},
+		},
+		{
+			name:   "declared capabilities need the methods",
+			driver: declaringDriver{},
+			want:   DriverCapabilities{SupportsLiveState: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, CapabilitiesOf(tt.driver))
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+
+	"metron/internal/idgen"
+)
+
+// Child activity kinds
+const (
+	ActivitySessionStarted  = "session.started"  // A session (or movie time) started for the child
+	ActivitySessionExtended = "session.extended" // The child's session got more time
+	ActivitySessionEnded    = "session.ended"    // The child's session ended (see Session.EndReason)
+	ActivityBreakStarted    = "break.started"    // A mandatory break started
+	ActivityBreakEnded      = "break.ended"      // The break is over and the session continues
+	ActivityReward          = "time.reward"      // A parent granted bonus minutes for today
+	ActivityFine            = "time.fine"        // A parent deducted minutes from today
+)
+
+// ChildActivity is an entry of a child's activity feed: what happened to their time,
+// in words the child web app shows as is
+type ChildActivity struct {
+	ID        string
+	ChildID   string
+	Kind      string // One of the Activity* kinds
+	Message   string // Human-readable, addressed to the child (e.g., "Your time on tv ran out after 30 minutes")
+	SessionID string // Empty for entries not about a session (rewards, fines)
+	Minutes   int    // Minutes the entry is about (started, extended, used, granted or deducted); 0 if none
+	CreatedAt time.Time
+}
+
+// ChildActivityStorage defines the interface for child activity persistence
+type ChildActivityStorage interface {
+	CreateChildActivity(ctx context.Context, activity *ChildActivity) error
+	ListChildActivity(ctx context.Context, childID string, limit int) ([]*ChildActivity, error) // Newest first
+}
+
+// ChildActivityService records children's activity feeds from session transitions and
+// time changes. Recording is best effort, like the audit log: a failure is logged and
+// does not fail the change itself.
+type ChildActivityService struct {
+	storage ChildActivityStorage
+	logger  *slog.Logger
+}
+
+// NewChildActivityService creates a new child activity service
+func NewChildActivityService(storage ChildActivityStorage, logger *slog.Logger) *ChildActivityService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &ChildActivityService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// List returns a child's latest activity, newest first
+func (s *ChildActivityService) List(ctx context.Context, childID string, limit int) ([]*ChildActivity, error) {
+	return s.storage.ListChildActivity(ctx, childID, limit)
+}
+
+// SessionStarted records a started session for each of its children
+func (s *ChildActivityService) SessionStarted(ctx context.Context, session *Session) {
+	message := fmt.Sprintf("Started %s on %s", pluralMinutes(session.ExpectedDuration), session.DeviceType)
+	if session.IsMovieSession {
+		message = fmt.Sprintf("Movie time started on %s", session.DeviceType)
+	}
+	if session.IsExempt() {
+		message = fmt.Sprintf("Started %s of %s on %s (not counted against your time)", pluralMinutes(session.ExpectedDuration), session.Activity, session.DeviceType)
+	}
+	s.recordSession(ctx, session, ActivitySessionStarted, message, session.ExpectedDuration)
+}
+
+// SessionExtended records the minutes added to a session for each of its children
+func (s *ChildActivityService) SessionExtended(ctx context.Context, session *Session, minutes int) {
+	if minutes <= 0 {
+		return
+	}
+	message := fmt.Sprintf("Got %s more on %s", pluralMinutes(minutes), session.DeviceType)
+	s.recordSession(ctx, session, ActivitySessionExtended, message, minutes)
+}
+
+// Reward records bonus minutes granted by a parent
+func (s *ChildActivityService) Reward(ctx context.Context, childID string, minutes int) {
+	s.record(ctx, &ChildActivity{
+		ChildID: childID,
+		Kind:    ActivityReward,
+		Message: fmt.Sprintf("A parent gave you %s of bonus time", pluralMinutes(minutes)),
+		Minutes: minutes,
+	})
+}
+
+// Fine records minutes deducted by a parent
+func (s *ChildActivityService) Fine(ctx context.Context, childID string, minutes int) {
+	s.record(ctx, &ChildActivity{
+		ChildID: childID,
+		Kind:    ActivityFine,
+		Message: fmt.Sprintf("A parent took away %s", pluralMinutes(minutes)),
+		Minutes: minutes,
+	})
+}
+
+// SessionHook returns a SessionStateMachine hook that records breaks and ended sessions
+func (s *ChildActivityService) SessionHook() SessionHook {
+	return func(ctx context.Context, session *Session, transition SessionTransition) {
+		switch transition.Event {
+		case SessionEventPause:
+			minutes := 0
+			if session.BreakEndsAt != nil {
+				minutes = int(math.Ceil(session.BreakEndsAt.Sub(transition.At).Minutes()))
+			}
+			s.recordSession(ctx, session, ActivityBreakStarted,
+				fmt.Sprintf("Break time: %s away from %s", pluralMinutes(minutes), session.DeviceType), minutes)
+		case SessionEventResume:
+			s.recordSession(ctx, session, ActivityBreakEnded,
+				fmt.Sprintf("Break is over, back to %s", session.DeviceType), 0)
+		case SessionEventExpire, SessionEventStop:
+			minutes := 0
+			if session.ActualDuration != nil {
+				minutes = *session.ActualDuration
+			}
+			s.recordSession(ctx, session, ActivitySessionEnded, sessionEndedMessage(session, minutes), minutes)
+		}
+	}
+}
+
+// sessionEndedMessage explains to the child why their session ended
+func sessionEndedMessage(session *Session, minutes int) string {
+	what := "session"
+	if session.IsMovieSession {
+		what = "movie time"
+	}
+	if session.IsExempt() {
+		what = session.Activity + " session"
+	}
+	used := pluralMinutes(minutes)
+	switch session.EndReason {
+	case SessionEndChildStop:
+		return fmt.Sprintf("You stopped your %s on %s after %s", what, session.DeviceType, used)
+	case SessionEndParentStop:
+		return fmt.Sprintf("A parent stopped your %s on %s after %s", what, session.DeviceType, used)
+	case SessionEndDowntime:
+		return fmt.Sprintf("Downtime started, so your %s on %s ended after %s", what, session.DeviceType, used)
+	case SessionEndIdle:
+		return fmt.Sprintf("Your %s on %s ended after %s because nobody was using it", what, session.DeviceType, used)
+	case SessionEndLockdown:
+		return fmt.Sprintf("Screens were locked, so your %s on %s ended after %s", what, session.DeviceType, used)
+	case SessionEndDriverFailure:
+		return fmt.Sprintf("Your %s on %s ended after %s because the device stopped responding", what, session.DeviceType, used)
+	default:
+		return fmt.Sprintf("Your %s on %s ran out after %s", what, session.DeviceType, used)
+	}
+}
+
+// recordSession records an entry about a session for each of its children
+func (s *ChildActivityService) recordSession(ctx context.Context, session *Session, kind, message string, minutes int) {
+	for _, childID := range session.ChildIDs {
+		s.record(ctx, &ChildActivity{
+			ChildID:   childID,
+			Kind:      kind,
+			Message:   message,
+			SessionID: session.ID,
+			Minutes:   minutes,
+		})
+	}
+}
+
+// record stores an entry, logging failures
+func (s *ChildActivityService) record(ctx context.Context, activity *ChildActivity) {
+	activity.ID = idgen.NewChildActivity()
+	activity.CreatedAt = Now()
+	if err := s.storage.CreateChildActivity(ctx, activity); err != nil {
+		s.logger.Error("Failed to record child activity",
+			"kind", activity.Kind,
+			"child_id", activity.ChildID,
+			"session_id", activity.SessionID,
+			"error", err)
+	}
+}
+
+// pluralMinutes formats a number of minutes ("1 minute", "30 minutes")
+func pluralMinutes(minutes int) string {
+	if minutes == 1 {
+		return "1 minute"
+	}
+	return fmt.Sprintf("%d minutes", minutes)
+}
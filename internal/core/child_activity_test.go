@@ -0,0 +1,151 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockChildActivityStorage struct {
+	activities []*ChildActivity
+}
+
+func (m *mockChildActivityStorage) CreateChildActivity(ctx context.Context, activity *ChildActivity) error {
+	copied := *activity
+	m.activities = append(m.activities, &copied)
+	return nil
+}
+
+func (m *mockChildActivityStorage) ListChildActivity(ctx context.Context, childID string, limit int) ([]*ChildActivity, error) {
+	var activities []*ChildActivity
+	for i := len(m.activities) - 1; i >= 0 && len(activities) < limit; i-- {
+		if m.activities[i].ChildID == childID {
+			activities = append(activities, m.activities[i])
+		}
+	}
+	return activities, nil
+}
+
+func TestChildActivityService_SessionLifecycle(t *testing.T) {
+	storage := newMockStorage()
+	deviceRegistry := newMockDeviceRegistry()
+	driverRegistry := newMockDriverRegistry()
+	manager := NewSessionManager(storage, deviceRegistry, driverRegistry, nil, nil, nil, nil)
+	activityStorage := &mockChildActivityStorage{}
+	activity := NewChildActivityService(activityStorage, nil)
+	manager.SetActivity(activity)
+	ctx := context.Background()
+
+	storage.CreateChild(ctx, &Child{ID: "child1", Name: "Alice", WeekdayLimit: 120, WeekendLimit: 120})
+	storage.CreateChild(ctx, &Child{ID: "child2", Name: "Bob", WeekdayLimit: 120, WeekendLimit: 120})
+	driverRegistry.addDriver(&mockDriver{name: "aqara"})
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", name: "Living Room TV", dtype: "tv", driver: "aqara"})
+
+	session, err := manager.StartSession(ctx, "tv1", []string{"child1", "child2"}, 30)
+	require.NoError(t, err)
+	_, err = manager.ExtendSession(ctx, session.ID, 15)
+	require.NoError(t, err)
+	require.NoError(t, manager.StopSession(WithEndReason(ctx, SessionEndChildStop), session.ID))
+
+	// Newest first, for each child of the session
+	for _, childID := range []string{"child1", "child2"} {
+		activities, err := activity.List(ctx, childID, 10)
+		require.NoError(t, err)
+		require.Len(t, activities, 3, childID)
+
+		assert.Equal(t, ActivitySessionEnded, activities[0].Kind)
+		assert.Equal(t, "You stopped your session on tv after 0 minutes", activities[0].Message)
+		assert.Equal(t, ActivitySessionExtended, activities[1].Kind)
+		assert.Equal(t, "Got 15 minutes more on tv", activities[1].Message)
+		assert.Equal(t, 15, activities[1].Minutes)
+		assert.Equal(t, ActivitySessionStarted, activities[2].Kind)
+		assert.Equal(t, "Started 30 minutes on tv", activities[2].Message)
+		assert.Equal(t, 30, activities[2].Minutes)
+		for _, entry := range activities {
+			assert.Equal(t, session.ID, entry.SessionID)
+			assert.Contains(t, entry.ID, "act_")
+		}
+	}
+}
+
+func TestChildActivityService_RewardAndFine(t *testing.T) {
+	storage := newMockStorage()
+	manager := NewSessionManager(storage, newMockDeviceRegistry(), newMockDriverRegistry(), nil, nil, nil, nil)
+	activityStorage := &mockChildActivityStorage{}
+	activity := NewChildActivityService(activityStorage, nil)
+	manager.SetActivity(activity)
+	ctx := context.Background()
+	storage.CreateChild(ctx, &Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 60})
+
+	require.NoError(t, manager.GrantRewardMinutes(ctx, "child1", 1))
+	require.NoError(t, manager.DeductFineMinutes(ctx, "child1", 10))
+
+	// Failed changes are not recorded
+	assert.Error(t, manager.DeductFineMinutes(ctx, "child1", 500))
+
+	activities, err := activity.List(ctx, "child1", 10)
+	require.NoError(t, err)
+	require.Len(t, activities, 2)
+	assert.Equal(t, ActivityFine, activities[0].Kind)
+	assert.Equal(t, "A parent took away 10 minutes", activities[0].Message)
+	assert.Equal(t, ActivityReward, activities[1].Kind)
+	assert.Equal(t, "A parent gave you 1 minute of bonus time", activities[1].Message)
+	assert.Empty(t, activities[1].SessionID)
+}
+
+func TestChildActivityService_SessionHook(t *testing.T) {
+	activityStorage := &mockChildActivityStorage{}
+	activity := NewChildActivityService(activityStorage, nil)
+	states := NewSessionStateMachine(nil)
+	states.AddHook(activity.SessionHook())
+	ctx := context.Background()
+	save := func(ctx context.Context, s *Session) error { return nil }
+
+	breakEnds := Now().Add(10 * time.Minute)
+	session := &Session{ID: "s1", DeviceType: "ps5", ChildIDs: []string{"child1"}, Status: SessionStatusActive, BreakEndsAt: &breakEnds}
+	_, err := states.Apply(ctx, session, SessionEventPause, save)
+	require.NoError(t, err)
+	_, err = states.Apply(ctx, session, SessionEventResume, save)
+	require.NoError(t, err)
+
+	used := 45
+	session.ActualDuration = &used
+	session.EndReason = SessionEndDowntime
+	_, err = states.Apply(ctx, session, SessionEventExpire, save)
+	require.NoError(t, err)
+
+	require.Len(t, activityStorage.activities, 3)
+	assert.Equal(t, ActivityBreakStarted, activityStorage.activities[0].Kind)
+	assert.Equal(t, "Break time: 10 minutes away from ps5", activityStorage.activities[0].Message)
+	assert.Equal(t, 10, activityStorage.activities[0].Minutes)
+	assert.Equal(t, ActivityBreakEnded, activityStorage.activities[1].Kind)
+	assert.Equal(t, "Break is over, back to ps5", activityStorage.activities[1].Message)
+	assert.Equal(t, ActivitySessionEnded, activityStorage.activities[2].Kind)
+	assert.Equal(t, "Downtime started, so your session on ps5 ended after 45 minutes", activityStorage.activities[2].Message)
+	assert.Equal(t, 45, activityStorage.activities[2].Minutes)
+}
+
+func TestSessionEndedMessage(t *testing.T) {
+	tests := []struct {
+		reason string
+		movie  bool
+		want   string
+	}{
+		{SessionEndExpired, false, "Your session on tv ran out after 30 minutes"},
+		{SessionEndExpired, true, "Your movie time on tv ran out after 30 minutes"},
+		{SessionEndParentStop, false, "A parent stopped your session on tv after 30 minutes"},
+		{SessionEndIdle, false, "Your session on tv ended after 30 minutes because nobody was using it"},
+		{SessionEndLockdown, false, "Screens were locked, so your session on tv ended after 30 minutes"},
+		{SessionEndDriverFailure, false, "Your session on tv ended after 30 minutes because the device stopped responding"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.reason, func(t *testing.T) {
+			session := &Session{DeviceType: "tv", EndReason: tt.reason, IsMovieSession: tt.movie}
+			assert.Equal(t, tt.want, sessionEndedMessage(session, 30))
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"metron/internal/idgen"
+)
+
+// Child login errors
+var (
+	ErrChildLoginNotFound = errors.New("child login not found")
+	ErrChildLoginRevoked  = errors.New("child login is revoked")
+	ErrChildLoginExpired  = errors.New("child login is expired")
+	ErrInvalidLinkCode    = errors.New("login link code is invalid, used or expired")
+)
+
+const (
+	// ChildLoginDuration is how long a child stays logged in to the child web app
+	ChildLoginDuration = 24 * time.Hour
+
+	// childLoginTokenBytes is the amount of randomness in a login token
+	childLoginTokenBytes = 32
+
+	// childLoginUsageInterval limits last-seen updates: the web app polls every few seconds
+	childLoginUsageInterval = time.Minute
+
+	// ChildLinkCodeDuration is how long a login link code can be redeemed
+	ChildLinkCodeDuration = 5 * time.Minute
+
+	// childLinkCodeLength is the length of a login link code; with childLinkCodeAlphabet
+	// that is about 40 bits, plenty for codes that live a few minutes and work once
+	childLinkCodeLength = 8
+
+	// childLinkCodeAlphabet leaves out look-alike characters (0/O, 1/I/L), so codes can be typed
+	childLinkCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+)
+
+// ChildLogin is a child's login to the child web app
+// The token (the session cookie or Bearer token) is only stored as a SHA-256 hash, so a
+// parent can list and revoke logins without seeing tokens that would let them in
+type ChildLogin struct {
+	ID         string
+	ChildID    string
+	TokenHash  string // Hex SHA-256 of the token
+	UserAgent  string // Browser that logged in, to tell logins apart
+	CreatedAt  time.Time
+	ExpiresAt  time.Time
+	LastSeenAt *time.Time // nil until the token is first used after login
+	RevokedAt  *time.Time // Set on logout or when a parent revokes the login
+	RevokedBy  string     // "logout", or who revoked it (e.g., "telegram:12345", "api")
+}
+
+// IsActive returns true if the login can be used at the given time
+func (l *ChildLogin) IsActive(now time.Time) bool {
+	return l.RevokedAt == nil && now.Before(l.ExpiresAt)
+}
+
+// HashChildLoginToken returns the hex SHA-256 of a login token, as stored in ChildLogin.TokenHash
+func HashChildLoginToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ChildLoginStorage defines the interface for child login persistence
+type ChildLoginStorage interface {
+	CreateChildLogin(ctx context.Context, login *ChildLogin) error
+	GetChildLogin(ctx context.Context, id string) (*ChildLogin, error)              // ErrChildLoginNotFound if missing
+	GetChildLoginByHash(ctx context.Context, tokenHash string) (*ChildLogin, error) // ErrChildLoginNotFound if missing
+	ListChildLogins(ctx context.Context, childID string) ([]*ChildLogin, error)     // Newest first; empty childID lists all
+	UpdateChildLogin(ctx context.Context, login *ChildLogin) error                  // Updates last-seen and revocation fields
+	DeleteExpiredChildLogins(ctx context.Context, before time.Time) (int, error)    // Deletes logins that expired before the time
+}
+
+// ChildLinkCode is a short-lived, single-use code a parent creates to log a child in on a
+// device (e.g., shown as a QR code), instead of the child typing a PIN others can watch
+type ChildLinkCode struct {
+	Code      string
+	ChildID   string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+// ChildLoginService logs children in to the child web app and keeps their logins across restarts
+// Link codes are only kept in memory: they live minutes, so a restart just means creating a new one.
+type ChildLoginService struct {
+	storage ChildLoginStorage
+	logger  *slog.Logger
+
+	mu        sync.Mutex                // Guards linkCodes
+	linkCodes map[string]*ChildLinkCode // By code
+}
+
+// NewChildLoginService creates a new child login service
+func NewChildLoginService(storage ChildLoginStorage, logger *slog.Logger) *ChildLoginService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &ChildLoginService{
+		storage:   storage,
+		logger:    logger,
+		linkCodes: make(map[string]*ChildLinkCode),
+	}
+}
+
+// Login creates a login for a child and returns it with the plain token
+func (s *ChildLoginService) Login(ctx context.Context, childID, userAgent string) (*ChildLogin, string, error) {
+	now := Now()
+
+	// Expired logins are useless; drop them here instead of in a background job
+	if _, err := s.storage.DeleteExpiredChildLogins(ctx, now); err != nil {
+		s.logger.Warn("Failed to delete expired child logins", "error", err)
+	}
+
+	random := make([]byte, childLoginTokenBytes)
+	if _, err := rand.Read(random); err != nil {
+		return nil, "", err
+	}
+	plain := hex.EncodeToString(random)
+
+	login := &ChildLogin{
+		ID:        idgen.NewChildLogin(),
+		ChildID:   childID,
+		TokenHash: HashChildLoginToken(plain),
+		UserAgent: userAgent,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ChildLoginDuration),
+	}
+	if err := s.storage.CreateChildLogin(ctx, login); err != nil {
+		return nil, "", err
+	}
+
+	return login, plain, nil
+}
+
+// Authenticate returns the active login matching a plain token
+// Returns ErrChildLoginNotFound, ErrChildLoginRevoked or ErrChildLoginExpired otherwise
+func (s *ChildLoginService) Authenticate(ctx context.Context, plain string) (*ChildLogin, error) {
+	login, err := s.storage.GetChildLoginByHash(ctx, HashChildLoginToken(plain))
+	if err != nil {
+		return nil, err
+	}
+
+	now := Now()
+	if login.RevokedAt != nil {
+		return nil, ErrChildLoginRevoked
+	}
+	if !now.Before(login.ExpiresAt) {
+		return nil, ErrChildLoginExpired
+	}
+
+	if login.LastSeenAt == nil || now.Sub(*login.LastSeenAt) >= childLoginUsageInterval {
+		login.LastSeenAt = &now
+		if err := s.storage.UpdateChildLogin(ctx, login); err != nil {
+			// Usage tracking is informational; never reject a valid login over it
+			s.logger.Warn("Failed to record child login use",
+				"login_id", login.ID,
+				"error", err)
+		}
+	}
+
+	return login, nil
+}
+
+// Logout revokes the login of a plain token; unknown and already revoked tokens are ignored
+func (s *ChildLoginService) Logout(ctx context.Context, plain string) error {
+	login, err := s.storage.GetChildLoginByHash(ctx, HashChildLoginToken(plain))
+	if errors.Is(err, ErrChildLoginNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if login.RevokedAt != nil {
+		return nil
+	}
+
+	now := Now()
+	login.RevokedAt = &now
+	login.RevokedBy = "logout"
+	return s.storage.UpdateChildLogin(ctx, login)
+}
+
+// ListActive returns the active logins of a child, or of all children if childID is empty, newest first
+func (s *ChildLoginService) ListActive(ctx context.Context, childID string) ([]*ChildLogin, error) {
+	logins, err := s.storage.ListChildLogins(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := Now()
+	active := make([]*ChildLogin, 0, len(logins))
+	for _, login := range logins {
+		if login.IsActive(now) {
+			active = append(active, login)
+		}
+	}
+	return active, nil
+}
+
+// Revoke logs a child out of one login immediately
+// Returns the login with ErrChildLoginRevoked if it was already revoked
+func (s *ChildLoginService) Revoke(ctx context.Context, id, revokedBy string) (*ChildLogin, error) {
+	login, err := s.storage.GetChildLogin(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if login.RevokedAt != nil {
+		return login, ErrChildLoginRevoked
+	}
+
+	now := Now()
+	login.RevokedAt = &now
+	login.RevokedBy = revokedBy
+	if err := s.storage.UpdateChildLogin(ctx, login); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Child login revoked",
+		"login_id", login.ID,
+		"child_id", login.ChildID,
+		"revoked_by", revokedBy)
+
+	return login, nil
+}
+
+// CreateLinkCode creates a login link code for a child, valid for ChildLinkCodeDuration
+func (s *ChildLoginService) CreateLinkCode(childID string) (*ChildLinkCode, error) {
+	code := make([]byte, childLinkCodeLength)
+	alphabetSize := big.NewInt(int64(len(childLinkCodeAlphabet)))
+	for i := range code {
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return nil, err
+		}
+		code[i] = childLinkCodeAlphabet[n.Int64()]
+	}
+
+	now := Now()
+	link := &ChildLinkCode{
+		Code:      string(code),
+		ChildID:   childID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(ChildLinkCodeDuration),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for existing, other := range s.linkCodes {
+		if !now.Before(other.ExpiresAt) {
+			delete(s.linkCodes, existing)
+		}
+	}
+	s.linkCodes[link.Code] = link
+
+	s.logger.Info("Child login link code created",
+		"child_id", childID,
+		"expires_at", link.ExpiresAt)
+
+	return link, nil
+}
+
+// RedeemLinkCode logs the child of a link code in and returns the login with the plain token
+// The code is used up even if the login fails. Returns ErrInvalidLinkCode for unknown,
+// used or expired codes; codes are matched case-insensitively.
+func (s *ChildLoginService) RedeemLinkCode(ctx context.Context, code, userAgent string) (*ChildLogin, string, error) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+
+	s.mu.Lock()
+	link, ok := s.linkCodes[code]
+	delete(s.linkCodes, code)
+	s.mu.Unlock()
+
+	if !ok || !Now().Before(link.ExpiresAt) {
+		return nil, "", ErrInvalidLinkCode
+	}
+	return s.Login(ctx, link.ChildID, userAgent)
+}
@@ -0,0 +1,208 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockChildLoginStorage struct {
+	logins []*ChildLogin
+}
+
+func (m *mockChildLoginStorage) CreateChildLogin(ctx context.Context, login *ChildLogin) error {
+	copied := *login
+	m.logins = append(m.logins, &copied)
+	return nil
+}
+
+func (m *mockChildLoginStorage) GetChildLogin(ctx context.Context, id string) (*ChildLogin, error) {
+	for _, login := range m.logins {
+		if login.ID == id {
+			copied := *login
+			return &copied, nil
+		}
+	}
+	return nil, ErrChildLoginNotFound
+}
+
+func (m *mockChildLoginStorage) GetChildLoginByHash(ctx context.Context, tokenHash string) (*ChildLogin, error) {
+	for _, login := range m.logins {
+		if login.TokenHash == tokenHash {
+			copied := *login
+			return &copied, nil
+		}
+	}
+	return nil, ErrChildLoginNotFound
+}
+
+func (m *mockChildLoginStorage) ListChildLogins(ctx context.Context, childID string) ([]*ChildLogin, error) {
+	var logins []*ChildLogin
+	for i := len(m.logins) - 1; i >= 0; i-- {
+		if childID == "" || m.logins[i].ChildID == childID {
+			copied := *m.logins[i]
+			logins = append(logins, &copied)
+		}
+	}
+	return logins, nil
+}
+
+func (m *mockChildLoginStorage) UpdateChildLogin(ctx context.Context, login *ChildLogin) error {
+	for i, existing := range m.logins {
+		if existing.ID == login.ID {
+			copied := *login
+			m.logins[i] = &copied
+			return nil
+		}
+	}
+	return ErrChildLoginNotFound
+}
+
+func (m *mockChildLoginStorage) DeleteExpiredChildLogins(ctx context.Context, before time.Time) (int, error) {
+	kept := m.logins[:0]
+	for _, login := range m.logins {
+		if !login.ExpiresAt.Before(before) {
+			kept = append(kept, login)
+		}
+	}
+	deleted := len(m.logins) - len(kept)
+	m.logins = kept
+	return deleted, nil
+}
+
+func TestChildLoginService(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	original := Now
+	Now = func() time.Time { return now }
+	t.Cleanup(func() { Now = original })
+
+	storage := &mockChildLoginStorage{}
+	service := NewChildLoginService(storage, nil)
+	ctx := context.Background()
+
+	login, plain, err := service.Login(ctx, "alice", "Mozilla/5.0")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", login.ChildID)
+	assert.Equal(t, now.Add(ChildLoginDuration), login.ExpiresAt)
+
+	// Only the hash is stored
+	require.Len(t, storage.logins, 1)
+	assert.Equal(t, HashChildLoginToken(plain), storage.logins[0].TokenHash)
+
+	t.Run("authenticate", func(t *testing.T) {
+		authenticated, err := service.Authenticate(ctx, plain)
+		require.NoError(t, err)
+		assert.Equal(t, login.ID, authenticated.ID)
+
+		stored, err := storage.GetChildLogin(ctx, login.ID)
+		require.NoError(t, err)
+		require.NotNil(t, stored.LastSeenAt, "use should be recorded")
+
+		_, err = service.Authenticate(ctx, plain+"x")
+		assert.ErrorIs(t, err, ErrChildLoginNotFound)
+	})
+
+	t.Run("parent revokes a login", func(t *testing.T) {
+		other, otherPlain, err := service.Login(ctx, "bob", "")
+		require.NoError(t, err)
+
+		active, err := service.ListActive(ctx, "")
+		require.NoError(t, err)
+		require.Len(t, active, 2)
+		assert.Equal(t, other.ID, active[0].ID, "newest first")
+
+		revoked, err := service.Revoke(ctx, other.ID, "telegram:1")
+		require.NoError(t, err)
+		assert.Equal(t, "telegram:1", revoked.RevokedBy)
+
+		_, err = service.Authenticate(ctx, otherPlain)
+		assert.ErrorIs(t, err, ErrChildLoginRevoked)
+		_, err = service.Revoke(ctx, other.ID, "api")
+		assert.ErrorIs(t, err, ErrChildLoginRevoked)
+		_, err = service.Revoke(ctx, "cls_missing", "api")
+		assert.ErrorIs(t, err, ErrChildLoginNotFound)
+
+		active, err = service.ListActive(ctx, "")
+		require.NoError(t, err)
+		require.Len(t, active, 1)
+		assert.Equal(t, login.ID, active[0].ID)
+	})
+
+	t.Run("logout", func(t *testing.T) {
+		_, logoutPlain, err := service.Login(ctx, "alice", "")
+		require.NoError(t, err)
+
+		require.NoError(t, service.Logout(ctx, logoutPlain))
+		_, err = service.Authenticate(ctx, logoutPlain)
+		assert.ErrorIs(t, err, ErrChildLoginRevoked)
+
+		// Unknown and already revoked tokens are ignored
+		require.NoError(t, service.Logout(ctx, logoutPlain))
+		require.NoError(t, service.Logout(ctx, "unknown"))
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		now = now.Add(ChildLoginDuration)
+		_, err := service.Authenticate(ctx, plain)
+		assert.ErrorIs(t, err, ErrChildLoginExpired)
+
+		active, err := service.ListActive(ctx, "alice")
+		require.NoError(t, err)
+		assert.Empty(t, active)
+
+		// Expired logins are deleted on the next login
+		now = now.Add(time.Minute)
+		_, _, err = service.Login(ctx, "alice", "")
+		require.NoError(t, err)
+		assert.Len(t, storage.logins, 1)
+	})
+}
+
+func TestChildLoginService_LinkCodes(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	original := Now
+	Now = func() time.Time { return now }
+	t.Cleanup(func() { Now = original })
+
+	storage := &mockChildLoginStorage{}
+	service := NewChildLoginService(storage, nil)
+	ctx := context.Background()
+
+	t.Run("redeem once", func(t *testing.T) {
+		link, err := service.CreateLinkCode("alice")
+		require.NoError(t, err)
+		assert.Len(t, link.Code, childLinkCodeLength)
+		assert.Equal(t, now.Add(ChildLinkCodeDuration), link.ExpiresAt)
+
+		// Typed codes may be lowercase
+		login, plain, err := service.RedeemLinkCode(ctx, " "+strings.ToLower(link.Code)+" ", "iPad")
+		require.NoError(t, err)
+		assert.Equal(t, "alice", login.ChildID)
+		assert.Equal(t, "iPad", login.UserAgent)
+
+		authenticated, err := service.Authenticate(ctx, plain)
+		require.NoError(t, err)
+		assert.Equal(t, login.ID, authenticated.ID)
+
+		_, _, err = service.RedeemLinkCode(ctx, link.Code, "iPad")
+		assert.ErrorIs(t, err, ErrInvalidLinkCode)
+	})
+
+	t.Run("unknown code", func(t *testing.T) {
+		_, _, err := service.RedeemLinkCode(ctx, "ABCDEFGH", "")
+		assert.ErrorIs(t, err, ErrInvalidLinkCode)
+	})
+
+	t.Run("expired code", func(t *testing.T) {
+		link, err := service.CreateLinkCode("bob")
+		require.NoError(t, err)
+
+		now = now.Add(ChildLinkCodeDuration)
+		_, _, err = service.RedeemLinkCode(ctx, link.Code, "")
+		assert.ErrorIs(t, err, ErrInvalidLinkCode)
+	})
+}
@@ -0,0 +1,8 @@
+package core
+
+import "time"
+
+// Now returns the current time
+// Session, limit, break and downtime logic in core and the scheduler reads the clock through Now
+// instead of time.Now, so the simulation harness (internal/simulation) can run it on a fake clock
+var Now = time.Now
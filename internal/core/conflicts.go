@@ -0,0 +1,342 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"metron/internal/idgen"
+	"slices"
+	"sync"
+	"time"
+)
+
+// What StartSession does when the device already has a running session (SetConflictPolicy)
+const (
+	ConflictAllow  = "allow"  // Start another session on the device (default)
+	ConflictReject = "reject" // Fail with a *DeviceBusyError
+	ConflictQueue  = "queue"  // Queue the start until the device is free (*SessionQueuedError)
+	ConflictMerge  = "merge"  // Add the children to the running session instead
+)
+
+var (
+	// ErrDeviceBusy is returned when a session is started on a device that is in use
+	ErrDeviceBusy = errors.New("device is busy")
+	// ErrSessionQueued is returned when a session start waits for its device to be free
+	ErrSessionQueued = errors.New("session start queued")
+	// ErrQueuedStartNotFound is returned when canceling a queued start that is not queued
+	ErrQueuedStartNotFound = errors.New("queued session start not found")
+)
+
+// DeviceBusyError reports the session that keeps a device busy
+type DeviceBusyError struct {
+	DeviceID  string
+	SessionID string
+	BusyUntil time.Time // When the session is planned to end
+}
+
+func (e *DeviceBusyError) Error() string {
+	return fmt.Sprintf("%s: %s is used by session %s until %s",
+		ErrDeviceBusy, e.DeviceID, e.SessionID, e.BusyUntil.Format(time.RFC3339))
+}
+
+func (e *DeviceBusyError) Unwrap() error {
+	return ErrDeviceBusy
+}
+
+// QueuedStart is a session start waiting for its device
+// The initiator, override and break exemption of the request apply when it starts.
+type QueuedStart struct {
+	ID          string
+	DeviceID    string
+	ChildIDs    []string
+	Minutes     int
+	Initiator   Initiator
+	Override    Override
+	BreakExempt bool
+	QueuedAt    time.Time
+}
+
+// SessionQueuedError reports where a start was queued
+type SessionQueuedError struct {
+	Start    *QueuedStart
+	Position int       // 1 for the next start on the device
+	StartsAt time.Time // Estimated from the planned end of the sessions ahead
+}
+
+func (e *SessionQueuedError) Error() string {
+	return fmt.Sprintf("%s: position %d on %s", ErrSessionQueued, e.Position, e.Start.DeviceID)
+}
+
+func (e *SessionQueuedError) Unwrap() error {
+	return ErrSessionQueued
+}
+
+// SessionQueue holds the session starts waiting for their device, oldest first
+// The queue is kept in memory: queued starts are lost on restart and only started
+// by the process that accepted them.
+type SessionQueue struct {
+	mu     sync.Mutex
+	starts []*QueuedStart
+}
+
+// NewSessionQueue creates an empty session queue
+func NewSessionQueue() *SessionQueue {
+	return &SessionQueue{}
+}
+
+// add queues a start and returns its position on its device
+func (q *SessionQueue) add(start *QueuedStart) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.starts = append(q.starts, start)
+	return len(q.ahead(start.DeviceID))
+}
+
+// ahead returns the starts queued on the device, oldest first (q.mu must be held)
+func (q *SessionQueue) ahead(deviceID string) []*QueuedStart {
+	var starts []*QueuedStart
+	for _, start := range q.starts {
+		if start.DeviceID == deviceID {
+			starts = append(starts, start)
+		}
+	}
+	return starts
+}
+
+// List returns the queued starts, oldest first
+func (q *SessionQueue) List() []*QueuedStart {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return slices.Clone(q.starts)
+}
+
+// Cancel removes a queued start
+func (q *SessionQueue) Cancel(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, start := range q.starts {
+		if start.ID == id {
+			q.starts = slices.Delete(q.starts, i, i+1)
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrQueuedStartNotFound, id)
+}
+
+// pop removes and returns the oldest start queued on the device, nil if there is none
+func (q *SessionQueue) pop(deviceID string) *QueuedStart {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for i, start := range q.starts {
+		if start.DeviceID == deviceID {
+			q.starts = slices.Delete(q.starts, i, i+1)
+			return start
+		}
+	}
+	return nil
+}
+
+// devices returns the devices with queued starts, in queue order
+func (q *SessionQueue) devices() []string {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var devices []string
+	for _, start := range q.starts {
+		if !slices.Contains(devices, start.DeviceID) {
+			devices = append(devices, start.DeviceID)
+		}
+	}
+	return devices
+}
+
+// queuedStartKey marks the context of StartSession calls made by StartQueued
+type queuedStartKey struct{}
+
+// BusyUntil returns when a running session is planned to end: its start plus its duration and breaks
+func BusyUntil(session *Session) time.Time {
+	end := session.StartTime.Add(time.Duration(session.ExpectedDuration+session.BreakMinutes) * time.Minute)
+	if session.BreakEndsAt != nil && session.BreakEndsAt.After(end) {
+		end = *session.BreakEndsAt
+	}
+	return end
+}
+
+// SetConflictPolicy sets what StartSession does on a device that already has a running session
+// (Conflict* constants). The queue holds the starts of ConflictQueue; a new one is created if nil.
+// The queue is in memory, so starts queued by this process are lost when it restarts.
+func (m *SessionManager) SetConflictPolicy(policy string, queue *SessionQueue) {
+	if policy == ConflictQueue && queue == nil {
+		queue = NewSessionQueue()
+	}
+	m.conflicts = policy
+	m.queue = queue
+}
+
+// SessionQueue returns the queue of starts waiting for their device, nil without ConflictQueue
+func (m *SessionManager) SessionQueue() *SessionQueue {
+	return m.queue
+}
+
+// lockDevice serializes starts on the device while a conflict policy is set, so two starts can't
+// both find it free before either session is saved. It uses the session locks (and their
+// storage claims) under a device key; without a policy it returns a no-op release.
+func (m *SessionManager) lockDevice(ctx context.Context, deviceID string) (release func(), err error) {
+	if m.conflicts == "" || m.conflicts == ConflictAllow {
+		return func() {}, nil
+	}
+	release, err = m.locks.Acquire(ctx, "device:"+deviceID)
+	if err != nil {
+		m.logger.Warn("Failed to lock device for session start",
+			"device_id", deviceID,
+			"error", err)
+		return nil, err
+	}
+	return release, nil
+}
+
+// busySession returns the running session on the device that ends last, nil if the device is free
+func (m *SessionManager) busySession(ctx context.Context, deviceID string) (*Session, error) {
+	active, err := m.storage.ListActiveSessions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list active sessions: %w", err)
+	}
+	var busy *Session
+	for _, session := range active {
+		if session.DeviceID != deviceID || !session.IsRunning() {
+			continue
+		}
+		if busy == nil || BusyUntil(session).After(BusyUntil(busy)) {
+			busy = session
+		}
+	}
+	return busy, nil
+}
+
+// checkDeviceFree returns a *DeviceBusyError if starts on busy devices are rejected and the device is busy
+func (m *SessionManager) checkDeviceFree(ctx context.Context, deviceID string) error {
+	if m.conflicts != ConflictReject {
+		return nil
+	}
+	busy, err := m.busySession(ctx, deviceID)
+	if err != nil {
+		return err
+	}
+	if busy == nil {
+		return nil
+	}
+	m.logger.Warn("Session start blocked: device is busy",
+		"device_id", deviceID,
+		"session_id", busy.ID)
+	return &DeviceBusyError{DeviceID: deviceID, SessionID: busy.ID, BusyUntil: BusyUntil(busy)}
+}
+
+// resolveConflict queues the start or merges it into the running session if the device is busy
+// and the policy says so. Returns handled = false if the session should be started as usual.
+func (m *SessionManager) resolveConflict(ctx context.Context, deviceID string, childIDs []string, durationMinutes int) (session *Session, handled bool, err error) {
+	if m.conflicts != ConflictQueue && m.conflicts != ConflictMerge {
+		return nil, false, nil
+	}
+	busy, err := m.busySession(ctx, deviceID)
+	if err != nil {
+		return nil, true, err
+	}
+
+	if m.conflicts == ConflictMerge {
+		if busy == nil {
+			return nil, false, nil
+		}
+		m.logger.Info("Device is busy, adding children to its running session",
+			"device_id", deviceID,
+			"session_id", busy.ID,
+			"child_ids", childIDs)
+		session, err := m.AddChildrenToSession(ctx, busy.ID, childIDs)
+		if err != nil {
+			return nil, true, err
+		}
+		session.Merged = true
+		return session, true, nil
+	}
+
+	start := &QueuedStart{
+		ID:          idgen.NewQueuedStart(),
+		DeviceID:    deviceID,
+		ChildIDs:    childIDs,
+		Minutes:     durationMinutes,
+		Initiator:   InitiatorFromContext(ctx),
+		Override:    OverrideFromContext(ctx),
+		BreakExempt: BreakExemptFromContext(ctx),
+		QueuedAt:    Now(),
+	}
+
+	// A free device still goes to the starts queued before this one first
+	m.queue.mu.Lock()
+	ahead := m.queue.ahead(deviceID)
+	if busy == nil && (len(ahead) == 0 || ctx.Value(queuedStartKey{}) != nil) {
+		m.queue.mu.Unlock()
+		return nil, false, nil
+	}
+
+	// Starts ahead run for their requested minutes, one after another
+	startsAt := start.QueuedAt
+	if busy != nil {
+		startsAt = BusyUntil(busy)
+	}
+	for _, queued := range ahead {
+		startsAt = startsAt.Add(time.Duration(queued.Minutes) * time.Minute)
+	}
+	m.queue.mu.Unlock()
+	position := m.queue.add(start)
+
+	m.logger.Info("Device is busy, session start queued",
+		"device_id", deviceID,
+		"queue_id", start.ID,
+		"position", position,
+		"child_ids", childIDs)
+	return nil, true, &SessionQueuedError{Start: start, Position: position, StartsAt: startsAt}
+}
+
+// StartQueued starts the oldest queued start of every device that is free, and returns how many started
+// Starts that fail (e.g., a child has no time left anymore) are dropped, so the next one gets its turn.
+func (m *SessionManager) StartQueued(ctx context.Context) (int, error) {
+	if m.queue == nil {
+		return 0, nil
+	}
+
+	started := 0
+	for _, deviceID := range m.queue.devices() {
+		busy, err := m.busySession(ctx, deviceID)
+		if err != nil {
+			return started, err
+		}
+		if busy != nil {
+			continue
+		}
+
+		for start := m.queue.pop(deviceID); start != nil; start = m.queue.pop(deviceID) {
+			startCtx := WithInitiator(context.WithValue(ctx, queuedStartKey{}, true), start.Initiator)
+			if start.Override.IsSet() {
+				startCtx = WithOverride(startCtx, start.Override)
+			}
+			if start.BreakExempt {
+				startCtx = WithBreakExempt(startCtx)
+			}
+
+			session, err := m.StartSession(startCtx, deviceID, start.ChildIDs, start.Minutes)
+			if err != nil {
+				m.logger.Warn("Failed to start queued session, dropping it",
+					"queue_id", start.ID,
+					"device_id", deviceID,
+					"child_ids", start.ChildIDs,
+					"error", err)
+				continue
+			}
+			m.logger.Info("Queued session started",
+				"queue_id", start.ID,
+				"session_id", session.ID,
+				"device_id", deviceID)
+			started++
+			break
+		}
+	}
+	return started, nil
+}
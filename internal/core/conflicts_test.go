@@ -0,0 +1,197 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newConflictTestManager returns a manager with the clock at noon, two children, a TV and a PS5,
+// and the conflict policy set
+func newConflictTestManager(t *testing.T, policy string) (*SessionManager, time.Time) {
+	now := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)
+	original := Now
+	Now = func() time.Time { return now }
+	t.Cleanup(func() { Now = original })
+
+	storage := newMockStorage()
+	deviceRegistry := newMockDeviceRegistry()
+	driverRegistry := newMockDriverRegistry()
+	manager := NewSessionManager(storage, deviceRegistry, driverRegistry, nil, nil, time.UTC, nil)
+	manager.SetConflictPolicy(policy, nil)
+
+	storage.CreateChild(context.Background(), &Child{ID: "child1", Name: "Alice", WeekdayLimit: 240, WeekendLimit: 240})
+	storage.CreateChild(context.Background(), &Child{ID: "child2", Name: "Bob", WeekdayLimit: 240, WeekendLimit: 240})
+	driverRegistry.addDriver(&mockDriver{name: "aqara"})
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", name: "TV", dtype: "tv", driver: "aqara"})
+	deviceRegistry.addDevice(&mockDevice{id: "ps5", name: "PS5", dtype: "ps5", driver: "aqara"})
+	return manager, now
+}
+
+func TestSessionManager_StartSession_ConflictAllow(t *testing.T) {
+	manager, _ := newConflictTestManager(t, ConflictAllow)
+	ctx := context.Background()
+
+	_, err := manager.StartSession(ctx, "tv1", []string{"child1"}, 60)
+	require.NoError(t, err)
+	_, err = manager.StartSession(ctx, "tv1", []string{"child2"}, 30)
+	require.NoError(t, err)
+}
+
+func TestSessionManager_StartSession_ConflictReject(t *testing.T) {
+	manager, now := newConflictTestManager(t, ConflictReject)
+	ctx := context.Background()
+
+	first, err := manager.StartSession(ctx, "tv1", []string{"child1"}, 60)
+	require.NoError(t, err)
+
+	_, err = manager.StartSession(ctx, "tv1", []string{"child2"}, 30)
+	require.ErrorIs(t, err, ErrDeviceBusy)
+	var busyErr *DeviceBusyError
+	require.ErrorAs(t, err, &busyErr)
+	assert.Equal(t, first.ID, busyErr.SessionID)
+	assert.Equal(t, now.Add(60*time.Minute), busyErr.BusyUntil)
+
+	preflight, err := manager.PreflightSession(ctx, "tv1", []string{"child2"}, 30)
+	require.NoError(t, err)
+	assert.False(t, preflight.Allowed)
+	assert.Equal(t, BlockRuleDeviceBusy, preflight.BlockedBy)
+
+	// Other devices are free
+	_, err = manager.StartSession(ctx, "ps5", []string{"child2"}, 30)
+	require.NoError(t, err)
+}
+
+// pausingStorage holds the first new session until a second one is saved (or briefly), so two
+// concurrent starts both check the device before either session is stored, unless one waits
+type pausingStorage struct {
+	*mockStorage
+	calls  atomic.Int32
+	second chan struct{}
+}
+
+func (s *pausingStorage) CreateSession(ctx context.Context, session *Session) error {
+	switch s.calls.Add(1) {
+	case 1:
+		select {
+		case <-s.second:
+		case <-time.After(50 * time.Millisecond):
+		}
+	case 2:
+		close(s.second)
+	}
+	return s.mockStorage.CreateSession(ctx, session)
+}
+
+func TestSessionManager_StartSession_ConflictReject_Concurrent(t *testing.T) {
+	manager, _ := newConflictTestManager(t, ConflictReject)
+	manager.storage = &pausingStorage{mockStorage: manager.storage.(*mockStorage), second: make(chan struct{})}
+	ctx := context.Background()
+
+	// Both starts find the device free unless the busy check and saving the session are one step
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for i, childID := range []string{"child1", "child2"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = manager.StartSession(ctx, "tv1", []string{childID}, 30)
+		}()
+	}
+	wg.Wait()
+
+	started := 0
+	for _, err := range errs {
+		if err == nil {
+			started++
+		} else {
+			assert.ErrorIs(t, err, ErrDeviceBusy)
+		}
+	}
+	assert.Equal(t, 1, started)
+
+	active, err := manager.storage.ListActiveSessions(ctx)
+	require.NoError(t, err)
+	assert.Len(t, active, 1)
+}
+
+func TestSessionManager_StartSession_ConflictQueue(t *testing.T) {
+	manager, now := newConflictTestManager(t, ConflictQueue)
+	ctx := context.Background()
+
+	first, err := manager.StartSession(ctx, "tv1", []string{"child1"}, 60)
+	require.NoError(t, err)
+
+	childCtx := WithInitiator(ctx, Initiator{Type: InitiatorChild, ID: "child2"})
+	_, err = manager.StartSession(childCtx, "tv1", []string{"child2"}, 30)
+	var queuedErr *SessionQueuedError
+	require.ErrorAs(t, err, &queuedErr)
+	assert.Equal(t, 1, queuedErr.Position)
+	assert.Equal(t, now.Add(60*time.Minute), queuedErr.StartsAt)
+
+	// The next start waits for the first one too
+	_, err = manager.StartSession(ctx, "tv1", []string{"child1"}, 20)
+	require.ErrorAs(t, err, &queuedErr)
+	assert.Equal(t, 2, queuedErr.Position)
+	assert.Equal(t, now.Add(90*time.Minute), queuedErr.StartsAt)
+	require.NoError(t, manager.SessionQueue().Cancel(queuedErr.Start.ID))
+	assert.ErrorIs(t, manager.SessionQueue().Cancel(queuedErr.Start.ID), ErrQueuedStartNotFound)
+
+	// Nothing starts while the device is busy
+	started, err := manager.StartQueued(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 0, started)
+
+	require.NoError(t, manager.StopSession(ctx, first.ID))
+	started, err = manager.StartQueued(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, started)
+	assert.Empty(t, manager.SessionQueue().List())
+
+	active, err := manager.ListActiveSessions(ctx)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, []string{"child2"}, active[0].ChildIDs)
+	assert.Equal(t, InitiatorChild, active[0].InitiatorType)
+}
+
+func TestSessionManager_StartSession_ConflictQueue_FreeDeviceKeepsOrder(t *testing.T) {
+	manager, _ := newConflictTestManager(t, ConflictQueue)
+	ctx := context.Background()
+
+	first, err := manager.StartSession(ctx, "tv1", []string{"child1"}, 60)
+	require.NoError(t, err)
+	_, err = manager.StartSession(ctx, "tv1", []string{"child2"}, 30)
+	require.ErrorIs(t, err, ErrSessionQueued)
+	require.NoError(t, manager.StopSession(ctx, first.ID))
+
+	// The device is free, but a start queued earlier gets it first
+	_, err = manager.StartSession(ctx, "tv1", []string{"child1"}, 30)
+	var queuedErr *SessionQueuedError
+	require.ErrorAs(t, err, &queuedErr)
+	assert.Equal(t, 2, queuedErr.Position)
+}
+
+func TestSessionManager_StartSession_ConflictMerge(t *testing.T) {
+	manager, _ := newConflictTestManager(t, ConflictMerge)
+	ctx := context.Background()
+
+	first, err := manager.StartSession(ctx, "tv1", []string{"child1"}, 60)
+	require.NoError(t, err)
+	assert.False(t, first.Merged)
+
+	merged, err := manager.StartSession(ctx, "tv1", []string{"child2"}, 30)
+	require.NoError(t, err)
+	assert.True(t, merged.Merged)
+	assert.Equal(t, first.ID, merged.ID)
+	assert.ElementsMatch(t, []string{"child1", "child2"}, merged.ChildIDs)
+
+	active, err := manager.ListActiveSessions(ctx)
+	require.NoError(t, err)
+	assert.Len(t, active, 1)
+}
@@ -0,0 +1,244 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"metron/internal/idgen"
+)
+
+// ErrDayRolloverNotFound is returned when a child's day has not been closed out
+var ErrDayRolloverNotFound = errors.New("day rollover not found")
+
+// DayRollover closes out a child's day once it has ended in the child's timezone
+// It is the DayRolledOver event: listeners (digests, banking unused time) get one per child and day.
+type DayRollover struct {
+	ID           string
+	ChildID      string
+	Date         time.Time // The day that ended (see TimeCalculationService.UsageDate)
+	LimitMinutes int       // The day's time, including rewards
+	UsedMinutes  int       // Minutes charged to the day
+	SessionCount int
+	CreatedAt    time.Time
+}
+
+// UnusedMinutes returns the minutes of the day's time that were not used
+func (r *DayRollover) UnusedMinutes() int {
+	if r.UsedMinutes >= r.LimitMinutes {
+		return 0
+	}
+	return r.LimitMinutes - r.UsedMinutes
+}
+
+// DayRolloverStorage defines the interface for day rollover persistence
+type DayRolloverStorage interface {
+	CreateDayRollover(ctx context.Context, rollover *DayRollover) error
+	GetDayRollover(ctx context.Context, childID string, date time.Time) (*DayRollover, error) // ErrDayRolloverNotFound if missing
+	UpdateDayRollover(ctx context.Context, rollover *DayRollover) error                       // Updates the used minutes and session count
+	ListDayRollovers(ctx context.Context, since time.Time) ([]*DayRollover, error)            // Created after since, oldest first
+	GetDailyUsageSummary(ctx context.Context, childID string, date time.Time) (*DailyUsageSummary, error)
+	ListChildren(ctx context.Context) ([]*Child, error)
+}
+
+// DayRolledOverListener is called after a child's day has been closed out
+type DayRolledOverListener func(ctx context.Context, rollover *DayRollover)
+
+// DayRolloverService runs the day rollover of every child
+// Days are otherwise only separated by date keys, so nothing happens at midnight. The rollover
+// closes out the day that ended (recording its time and usage), tells the listeners, and creates
+// the new day's allocation, so the day's limit is fixed before the first session.
+type DayRolloverService struct {
+	storage    DayRolloverStorage
+	calculator *TimeCalculationService
+	listeners  []DayRolledOverListener
+	current    map[string]time.Time // Day each child was last rolled over to, so ticks skip storage
+	logger     *slog.Logger
+	mu         sync.Mutex // Serializes rollovers so a day is closed out once
+}
+
+// NewDayRolloverService creates a new day rollover service
+func NewDayRolloverService(storage DayRolloverStorage, calculator *TimeCalculationService, logger *slog.Logger) *DayRolloverService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &DayRolloverService{
+		storage:    storage,
+		calculator: calculator,
+		current:    make(map[string]time.Time),
+		logger:     logger,
+	}
+}
+
+// AddListener calls listener for every day closed out from now on
+func (s *DayRolloverService) AddListener(listener DayRolledOverListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, listener)
+}
+
+// RollOver closes out the previous day of every child whose day changed and returns the new rollovers
+// It is called on every scheduler tick; each child's day ends at midnight in the child's timezone
+// (the configured timezone by default). Errors are logged per child and retried on the next call.
+func (s *DayRolloverService) RollOver(ctx context.Context) ([]*DayRollover, error) {
+	children, err := s.storage.ListChildren(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := Now()
+	var rollovers []*DayRollover
+	for _, child := range children {
+		today := s.calculator.UsageDate(ctx, child.ID, now)
+		if current, ok := s.current[child.ID]; ok && current.Equal(today) {
+			continue
+		}
+
+		rollover, err := s.rollOver(ctx, child, now)
+		if err != nil {
+			s.logger.Error("Failed to roll over day",
+				"child_id", child.ID,
+				"date", today.Format("2006-01-02"),
+				"error", err)
+			continue
+		}
+		s.current[child.ID] = today
+		if rollover != nil {
+			rollovers = append(rollovers, rollover)
+		}
+	}
+	return rollovers, nil
+}
+
+// rollOver closes out the day before today, if not done yet, and creates today's allocation
+func (s *DayRolloverService) rollOver(ctx context.Context, child *Child, now time.Time) (*DayRollover, error) {
+	var rollover *DayRollover
+
+	// Noon of the previous day in the child's timezone, since date keys are in the server's
+	loc := child.Location(s.calculator.timezone)
+	local := now.In(loc)
+	previousNoon := time.Date(local.Year(), local.Month(), local.Day()-1, 12, 0, 0, 0, loc)
+	yesterday := UsageDate(previousNoon, loc, s.calculator.timezone)
+
+	// Children added today had no previous day
+	startOfDay := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	if child.CreatedAt.IsZero() || child.CreatedAt.Before(startOfDay) {
+		_, err := s.storage.GetDayRollover(ctx, child.ID, yesterday)
+		switch {
+		case errors.Is(err, ErrDayRolloverNotFound):
+			rollover, err = s.closeOut(ctx, child.ID, yesterday, previousNoon, now)
+			if err != nil {
+				return nil, err
+			}
+		case err != nil:
+			return nil, err
+		}
+	}
+
+	// Creates the allocation with today's limit if it does not exist yet
+	if _, err := s.calculator.GetAvailableTime(ctx, child.ID, now); err != nil {
+		return nil, err
+	}
+
+	if rollover != nil {
+		for _, listener := range s.listeners {
+			listener(ctx, rollover)
+		}
+	}
+	return rollover, nil
+}
+
+// closeOut records the time and usage of a day that ended
+// date is the day's key and during any instant of the day in the child's timezone
+func (s *DayRolloverService) closeOut(ctx context.Context, childID string, date, during, now time.Time) (*DayRollover, error) {
+	available, err := s.calculator.GetAvailableTime(ctx, childID, during)
+	if err != nil {
+		return nil, err
+	}
+
+	used, sessions := 0, 0
+	if summary, err := s.storage.GetDailyUsageSummary(ctx, childID, date); err == nil {
+		used, sessions = summary.MinutesUsed, summary.SessionCount
+	}
+
+	rollover := &DayRollover{
+		ID:           idgen.NewDayRollover(),
+		ChildID:      childID,
+		Date:         date,
+		LimitMinutes: available.TotalAvailable,
+		UsedMinutes:  used,
+		SessionCount: sessions,
+		CreatedAt:    now,
+	}
+	if err := s.storage.CreateDayRollover(ctx, rollover); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Day rolled over",
+		"rollover_id", rollover.ID,
+		"child_id", childID,
+		"date", date.Format("2006-01-02"),
+		"limit_minutes", rollover.LimitMinutes,
+		"used_minutes", used,
+		"unused_minutes", rollover.UnusedMinutes())
+
+	return rollover, nil
+}
+
+// Recalculate updates the usage of a closed-out day from its usage summary, e.g. after a correction
+// Returns nil if the day has not been closed out. Listeners are not called again.
+func (s *DayRolloverService) Recalculate(ctx context.Context, childID string, date time.Time) (*DayRollover, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rollover, err := s.storage.GetDayRollover(ctx, childID, date)
+	if errors.Is(err, ErrDayRolloverNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	used, sessions := 0, 0
+	if summary, err := s.storage.GetDailyUsageSummary(ctx, childID, rollover.Date); err == nil {
+		used, sessions = summary.MinutesUsed, summary.SessionCount
+	}
+	if used == rollover.UsedMinutes && sessions == rollover.SessionCount {
+		return rollover, nil
+	}
+
+	rollover.UsedMinutes, rollover.SessionCount = used, sessions
+	if err := s.storage.UpdateDayRollover(ctx, rollover); err != nil {
+		return nil, err
+	}
+	s.logger.Info("Day rollover recalculated",
+		"rollover_id", rollover.ID,
+		"child_id", childID,
+		"date", rollover.Date.Format("2006-01-02"),
+		"used_minutes", used)
+	return rollover, nil
+}
+
+// List returns the rollovers created after since, oldest first, optionally for one child
+func (s *DayRolloverService) List(ctx context.Context, since time.Time, childID string) ([]*DayRollover, error) {
+	rollovers, err := s.storage.ListDayRollovers(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	if childID == "" {
+		return rollovers, nil
+	}
+
+	filtered := make([]*DayRollover, 0, len(rollovers))
+	for _, rollover := range rollovers {
+		if rollover.ChildID == childID {
+			filtered = append(filtered, rollover)
+		}
+	}
+	return filtered, nil
+}
@@ -0,0 +1,117 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockDayRolloverStorage struct {
+	*mockStorage
+	rollovers []*DayRollover
+}
+
+func (m *mockDayRolloverStorage) CreateDayRollover(ctx context.Context, rollover *DayRollover) error {
+	copied := *rollover
+	m.rollovers = append(m.rollovers, &copied)
+	return nil
+}
+
+func (m *mockDayRolloverStorage) GetDayRollover(ctx context.Context, childID string, date time.Time) (*DayRollover, error) {
+	for _, rollover := range m.rollovers {
+		if rollover.ChildID == childID && rollover.Date.Equal(date) {
+			copied := *rollover
+			return &copied, nil
+		}
+	}
+	return nil, ErrDayRolloverNotFound
+}
+
+func (m *mockDayRolloverStorage) UpdateDayRollover(ctx context.Context, rollover *DayRollover) error {
+	for _, existing := range m.rollovers {
+		if existing.ID == rollover.ID {
+			existing.UsedMinutes, existing.SessionCount = rollover.UsedMinutes, rollover.SessionCount
+			return nil
+		}
+	}
+	return ErrDayRolloverNotFound
+}
+
+func (m *mockDayRolloverStorage) ListDayRollovers(ctx context.Context, since time.Time) ([]*DayRollover, error) {
+	var rollovers []*DayRollover
+	for _, rollover := range m.rollovers {
+		if rollover.CreatedAt.After(since) {
+			copied := *rollover
+			rollovers = append(rollovers, &copied)
+		}
+	}
+	return rollovers, nil
+}
+
+func TestDayRolloverService_RollOver(t *testing.T) {
+	setClock := setDowntimeOverrideClock(t, time.Date(2026, time.March, 10, 0, 30, 0, 0, time.UTC))
+
+	storage := &mockDayRolloverStorage{mockStorage: newMockStorage()}
+	storage.CreateChild(context.Background(), &Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 90})
+	storage.IncrementDailyUsage(context.Background(), "child1", time.Date(2026, time.March, 9, 0, 0, 0, 0, time.UTC), 45)
+	calculator := NewTimeCalculationService(storage, time.UTC)
+	service := NewDayRolloverService(storage, calculator, nil)
+
+	var events []*DayRollover
+	service.AddListener(func(ctx context.Context, rollover *DayRollover) {
+		events = append(events, rollover)
+	})
+
+	rollovers, err := service.RollOver(context.Background())
+	require.NoError(t, err)
+	require.Len(t, rollovers, 1)
+	assert.Equal(t, "2026-03-09", rollovers[0].Date.Format("2006-01-02"))
+	assert.Equal(t, 60, rollovers[0].LimitMinutes)
+	assert.Equal(t, 45, rollovers[0].UsedMinutes)
+	assert.Equal(t, 15, rollovers[0].UnusedMinutes())
+	assert.Len(t, events, 1)
+
+	// A day is closed out once, also by a restarted server
+	rollovers, err = service.RollOver(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, rollovers)
+
+	restarted := NewDayRolloverService(storage, calculator, nil)
+	rollovers, err = restarted.RollOver(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, rollovers)
+	assert.Len(t, storage.rollovers, 1)
+
+	// The next midnight closes out the next day
+	setClock(time.Date(2026, time.March, 11, 0, 1, 0, 0, time.UTC))
+	rollovers, err = service.RollOver(context.Background())
+	require.NoError(t, err)
+	require.Len(t, rollovers, 1)
+	assert.Equal(t, "2026-03-10", rollovers[0].Date.Format("2006-01-02"))
+	assert.Equal(t, 0, rollovers[0].UsedMinutes)
+	assert.Len(t, events, 2)
+
+	listed, err := service.List(context.Background(), time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC), "child1")
+	require.NoError(t, err)
+	assert.Len(t, listed, 1)
+}
+
+func TestDayRolloverService_RollOver_ChildTimezone(t *testing.T) {
+	// 03:00 UTC is still the evening of March 9 in New York
+	setDowntimeOverrideClock(t, time.Date(2026, time.March, 10, 3, 0, 0, 0, time.UTC))
+
+	storage := &mockDayRolloverStorage{mockStorage: newMockStorage()}
+	storage.CreateChild(context.Background(), &Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 90, Timezone: "America/New_York"})
+	storage.CreateChild(context.Background(), &Child{ID: "child2", Name: "Bob", WeekdayLimit: 60, WeekendLimit: 90, CreatedAt: time.Date(2026, time.March, 10, 1, 0, 0, 0, time.UTC)})
+	service := NewDayRolloverService(storage, NewTimeCalculationService(storage, time.UTC), nil)
+
+	rollovers, err := service.RollOver(context.Background())
+	require.NoError(t, err)
+	require.Len(t, rollovers, 1, "a child added today has no previous day")
+	assert.Equal(t, "child1", rollovers[0].ChildID)
+	assert.Equal(t, "2026-03-08", rollovers[0].Date.Format("2006-01-02"))
+	assert.Equal(t, 90, rollovers[0].LimitMinutes, "March 8 is a Sunday")
+}
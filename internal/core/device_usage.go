@@ -0,0 +1,285 @@
+package core
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+)
+
+// Device report windows, in days
+const (
+	DefaultDeviceReportDays = 7
+	MaxDeviceReportDays     = 92
+)
+
+// ErrInvalidReportRange is returned for report ranges that end before they start or are too long
+var ErrInvalidReportRange = errors.New("invalid report date range")
+
+// DeviceUsageStorage lists the sessions counted in device usage
+type DeviceUsageStorage interface {
+	ListSessionsSince(ctx context.Context, since time.Time) ([]*Session, error)
+}
+
+// DeviceUsage is how long a device was used on one day
+// Child minutes charge every child of a shared session; device minutes count the time the device
+// was in use once, however many children or overlapping sessions it had.
+type DeviceUsage struct {
+	DeviceID      string
+	DeviceType    string
+	DeviceMinutes int
+	ChildMinutes  int
+	ExemptMinutes int // Child minutes of exempt activity sessions, included in ChildMinutes but not charged
+	Sessions      int
+}
+
+// DeviceUsageDay is the usage of every device used on one day
+type DeviceUsageDay struct {
+	Date    time.Time      // Midnight in the server timezone
+	Devices []*DeviceUsage // Sorted by device ID
+}
+
+// DeviceUtilization is how much a device was used over a report's range
+type DeviceUtilization struct {
+	DeviceUsage
+	DaysUsed       int
+	AverageMinutes float64    // Device minutes per day of the range
+	SharePercent   float64    // Share of all devices' device minutes
+	PeakHour       int        // Hour of the day with the most device minutes, -1 if unused
+	Heatmap        [7][24]int // Device minutes by weekday (Sunday first) and hour, in the server timezone
+}
+
+// DeviceReport is the device usage over a range of days
+type DeviceReport struct {
+	From    time.Time
+	To      time.Time
+	Days    []*DeviceUsageDay    // Oldest first
+	Devices []*DeviceUtilization // Most device minutes first
+}
+
+// DeviceUsageService computes device minutes and child minutes per device per day
+// Sessions count on the day they started, in the server timezone.
+type DeviceUsageService struct {
+	storage  DeviceUsageStorage
+	timezone *time.Location
+}
+
+// NewDeviceUsageService creates a new device usage service
+func NewDeviceUsageService(storage DeviceUsageStorage, timezone *time.Location) *DeviceUsageService {
+	if timezone == nil {
+		timezone = time.UTC
+	}
+	return &DeviceUsageService{
+		storage:  storage,
+		timezone: timezone,
+	}
+}
+
+// Report returns the device usage from one day to another, inclusive
+// A zero to means today, a zero from the DefaultDeviceReportDays ending with to.
+func (s *DeviceUsageService) Report(ctx context.Context, from, to time.Time) (*DeviceReport, error) {
+	now := Now()
+	if to.IsZero() {
+		to = UsageDate(now, s.timezone, s.timezone)
+	}
+	to = time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, s.timezone)
+	if from.IsZero() {
+		from = to.AddDate(0, 0, -(DefaultDeviceReportDays - 1))
+	}
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, s.timezone)
+
+	if to.Before(from) {
+		return nil, fmt.Errorf("%w: %s is before %s", ErrInvalidReportRange, to.Format("2006-01-02"), from.Format("2006-01-02"))
+	}
+	n := dayIndex(to, from) + 1
+	if n > MaxDeviceReportDays {
+		return nil, fmt.Errorf("%w: at most %d days", ErrInvalidReportRange, MaxDeviceReportDays)
+	}
+
+	sessions, err := s.storage.ListSessionsSince(ctx, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions for device usage: %w", err)
+	}
+
+	type deviceDay struct {
+		usage *DeviceUsage
+		spans []usageSpan
+	}
+	byDay := make([]map[string]*deviceDay, n)
+	for _, session := range sessions {
+		index := dayIndex(UsageDate(session.StartTime, s.timezone, s.timezone), from)
+		if index < 0 || index >= n {
+			continue
+		}
+		if byDay[index] == nil {
+			byDay[index] = make(map[string]*deviceDay)
+		}
+		entry, ok := byDay[index][session.DeviceID]
+		if !ok {
+			entry = &deviceDay{usage: &DeviceUsage{DeviceID: session.DeviceID, DeviceType: session.DeviceType}}
+			byDay[index][session.DeviceID] = entry
+		}
+
+		used, _ := sessionUsageSpans(session, now)
+		entry.spans = append(entry.spans, used)
+		entry.usage.ChildMinutes += used.minutes() * len(session.ChildIDs)
+		if session.IsExempt() {
+			entry.usage.ExemptMinutes += used.minutes() * len(session.ChildIDs)
+		}
+		entry.usage.Sessions++
+	}
+
+	report := &DeviceReport{From: from, To: to, Days: make([]*DeviceUsageDay, n)}
+	devices := make(map[string]*DeviceUtilization)
+	total := 0
+	for i := range report.Days {
+		day := &DeviceUsageDay{Date: from.AddDate(0, 0, i), Devices: []*DeviceUsage{}}
+		for deviceID, entry := range byDay[i] {
+			merged := mergeSpans(entry.spans)
+			entry.usage.DeviceMinutes = spansMinutes(merged)
+			day.Devices = append(day.Devices, entry.usage)
+			total += entry.usage.DeviceMinutes
+
+			device, ok := devices[deviceID]
+			if !ok {
+				device = &DeviceUtilization{DeviceUsage: DeviceUsage{DeviceID: deviceID, DeviceType: entry.usage.DeviceType}}
+				devices[deviceID] = device
+			}
+			device.DeviceMinutes += entry.usage.DeviceMinutes
+			device.ChildMinutes += entry.usage.ChildMinutes
+			device.ExemptMinutes += entry.usage.ExemptMinutes
+			device.Sessions += entry.usage.Sessions
+			device.DaysUsed++
+			s.addToHeatmap(&device.Heatmap, merged)
+		}
+		slices.SortFunc(day.Devices, func(a, b *DeviceUsage) int {
+			return cmp.Compare(a.DeviceID, b.DeviceID)
+		})
+		report.Days[i] = day
+	}
+
+	report.Devices = make([]*DeviceUtilization, 0, len(devices))
+	for _, device := range devices {
+		device.AverageMinutes = float64(device.DeviceMinutes) / float64(n)
+		if total > 0 {
+			device.SharePercent = float64(device.DeviceMinutes) * 100 / float64(total)
+		}
+		device.PeakHour = peakHour(&device.Heatmap)
+		report.Devices = append(report.Devices, device)
+	}
+	slices.SortFunc(report.Devices, func(a, b *DeviceUtilization) int {
+		if c := cmp.Compare(b.DeviceMinutes, a.DeviceMinutes); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.DeviceID, b.DeviceID)
+	})
+	return report, nil
+}
+
+// addToHeatmap adds the minutes of disjoint spans to the hours they fell in
+func (s *DeviceUsageService) addToHeatmap(heatmap *[7][24]int, spans []usageSpan) {
+	var buckets [7][24]time.Duration
+	for _, span := range spans {
+		for t := span.start.In(s.timezone); t.Before(span.end); {
+			next := time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.timezone)
+			if next.After(span.end) {
+				next = span.end
+			}
+			buckets[t.Weekday()][t.Hour()] += next.Sub(t)
+			t = next
+		}
+	}
+	for day := range buckets {
+		for hour, duration := range buckets[day] {
+			heatmap[day][hour] += int(math.Round(duration.Minutes()))
+		}
+	}
+}
+
+// peakHour returns the hour of the day with the most minutes over all weekdays, -1 if there are none
+func peakHour(heatmap *[7][24]int) int {
+	peak, peakMinutes := -1, 0
+	for hour := range 24 {
+		minutes := 0
+		for day := range heatmap {
+			minutes += heatmap[day][hour]
+		}
+		if minutes > peakMinutes {
+			peak, peakMinutes = hour, minutes
+		}
+	}
+	return peak
+}
+
+// usageSpan is the wall clock time a session used its device
+type usageSpan struct {
+	start time.Time
+	end   time.Time
+}
+
+func (s usageSpan) minutes() int {
+	return int(math.Round(s.end.Sub(s.start).Minutes()))
+}
+
+// sessionUsageSpans returns the span a session used its device so far, and the span it commits:
+// its planned duration while running, what it ran once ended
+// Breaks are not recorded minute by minute, so the minutes are counted from the start.
+func sessionUsageSpans(session *Session, now time.Time) (used, committed usageSpan) {
+	usedMinutes, committedMinutes := session.ExpectedDuration, session.ExpectedDuration
+	if session.IsRunning() {
+		usedMinutes = chargeableMinutes(session.StartTime, session.ExpectedDuration, session.BreakMinutes,
+			session.LastBreakAt, session.BreakEndsAt, now)
+		committedMinutes = max(usedMinutes, session.ExpectedDuration)
+	} else if session.ActualDuration != nil {
+		usedMinutes, committedMinutes = *session.ActualDuration, *session.ActualDuration
+	}
+	used = usageSpan{start: session.StartTime, end: session.StartTime.Add(time.Duration(usedMinutes) * time.Minute)}
+	committed = usageSpan{start: session.StartTime, end: session.StartTime.Add(time.Duration(committedMinutes) * time.Minute)}
+	return used, committed
+}
+
+// mergeSpans returns the spans merged into disjoint spans, oldest first
+func mergeSpans(spans []usageSpan) []usageSpan {
+	if len(spans) == 0 {
+		return nil
+	}
+	sorted := slices.Clone(spans)
+	slices.SortFunc(sorted, func(a, b usageSpan) int {
+		return a.start.Compare(b.start)
+	})
+
+	merged := []usageSpan{sorted[0]}
+	for _, span := range sorted[1:] {
+		current := &merged[len(merged)-1]
+		if span.start.After(current.end) {
+			merged = append(merged, span)
+			continue
+		}
+		if span.end.After(current.end) {
+			current.end = span.end
+		}
+	}
+	return merged
+}
+
+// spansMinutes returns the minutes of disjoint spans
+func spansMinutes(spans []usageSpan) int {
+	var total time.Duration
+	for _, span := range spans {
+		total += span.end.Sub(span.start)
+	}
+	return int(math.Round(total.Minutes()))
+}
+
+// unionMinutes returns the minutes covered by the spans, counting overlapping time once
+func unionMinutes(spans []usageSpan) int {
+	return spansMinutes(mergeSpans(spans))
+}
+
+// dayIndex returns the index of date in days counted from from (DST days are not exactly 24 hours)
+func dayIndex(date, from time.Time) int {
+	return int(math.Floor(date.Sub(from).Hours()/24 + 0.5))
+}
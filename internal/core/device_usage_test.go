@@ -0,0 +1,91 @@
+package core
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnionMinutes(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2026, time.March, 10, hour, minute, 0, 0, time.UTC)
+	}
+
+	assert.Equal(t, 0, unionMinutes(nil))
+	// Overlapping spans count once, in any order
+	assert.Equal(t, 90, unionMinutes([]usageSpan{
+		{start: at(16, 30), end: at(17, 30)},
+		{start: at(16, 0), end: at(17, 0)},
+	}))
+	// A span inside another adds nothing; separate spans add up
+	assert.Equal(t, 80, unionMinutes([]usageSpan{
+		{start: at(9, 0), end: at(10, 0)},
+		{start: at(9, 15), end: at(9, 45)},
+		{start: at(18, 0), end: at(18, 20)},
+	}))
+}
+
+func TestDeviceUsageService_Report(t *testing.T) {
+	now := time.Date(2026, time.March, 10, 20, 0, 0, 0, time.UTC) // A Tuesday
+	original := Now
+	Now = func() time.Time { return now }
+	t.Cleanup(func() { Now = original })
+
+	storage := newMockStorage()
+	addEndedSession(storage, "alice", "tv1", now.Add(-4*time.Hour), now.Add(-3*time.Hour))
+	addEndedSession(storage, "bob", "tv1", now.Add(-210*time.Minute), now.Add(-150*time.Minute))
+	addEndedSession(storage, "shared", "ps5", now.Add(-2*time.Hour), now.Add(-90*time.Minute))
+	addEndedSession(storage, "yesterday", "tv1", now.Add(-24*time.Hour), now.Add(-23*time.Hour))
+	addEndedSession(storage, "old", "tv1", now.AddDate(0, 0, -7), now.AddDate(0, 0, -7).Add(time.Hour))
+	storage.sessions["bob"].ChildIDs = []string{"child2"}
+	storage.sessions["shared"].ChildIDs = []string{"child1", "child2"}
+
+	service := NewDeviceUsageService(storage, time.UTC)
+	report, err := service.Report(context.Background(), now.AddDate(0, 0, -1), time.Time{})
+	require.NoError(t, err)
+	require.Len(t, report.Days, 2)
+
+	require.Len(t, report.Days[0].Devices, 1)
+	assert.Equal(t, 60, report.Days[0].Devices[0].DeviceMinutes)
+
+	today := report.Days[1].Devices
+	require.Len(t, today, 2)
+	// Two children shared the PS5 for 30 minutes
+	assert.Equal(t, "ps5", today[0].DeviceID)
+	assert.Equal(t, 30, today[0].DeviceMinutes)
+	assert.Equal(t, 60, today[0].ChildMinutes)
+	// Alice and Bob watched the TV in overlapping sessions: 16:00-17:00 and 16:30-17:30
+	assert.Equal(t, "tv1", today[1].DeviceID)
+	assert.Equal(t, 90, today[1].DeviceMinutes)
+	assert.Equal(t, 120, today[1].ChildMinutes)
+	assert.Equal(t, 2, today[1].Sessions)
+
+	// The TV is the most used device over the range
+	require.Len(t, report.Devices, 2)
+	tv := report.Devices[0]
+	assert.Equal(t, "tv1", tv.DeviceID)
+	assert.Equal(t, 150, tv.DeviceMinutes)
+	assert.Equal(t, 3, tv.Sessions)
+	assert.Equal(t, 2, tv.DaysUsed)
+	assert.Equal(t, 75.0, tv.AverageMinutes)
+	assert.Equal(t, 83.3, math.Round(tv.SharePercent*10)/10)
+	assert.Equal(t, 16, tv.PeakHour)
+	assert.Equal(t, 60, tv.Heatmap[time.Tuesday][16])
+	assert.Equal(t, 30, tv.Heatmap[time.Tuesday][17])
+	assert.Equal(t, 60, tv.Heatmap[time.Monday][20])
+}
+
+func TestDeviceUsageService_Report_InvalidRange(t *testing.T) {
+	service := NewDeviceUsageService(newMockStorage(), time.UTC)
+	day := time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC)
+
+	_, err := service.Report(context.Background(), day, day.AddDate(0, 0, -1))
+	assert.ErrorIs(t, err, ErrInvalidReportRange)
+
+	_, err = service.Report(context.Background(), day, day.AddDate(0, 0, MaxDeviceReportDays))
+	assert.ErrorIs(t, err, ErrInvalidReportRange)
+}
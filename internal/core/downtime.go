@@ -60,15 +60,47 @@ func (d *DowntimeService) SetSkipStorage(storage DowntimeSkipStorage) {
 	d.skipStorage = storage
 }
 
+// In returns a copy of the service that evaluates the schedule in loc
+// The copy shares the schedule and skip storage
+func (d *DowntimeService) In(loc *time.Location) *DowntimeService {
+	if loc == nil || loc == d.timezone {
+		return d
+	}
+	copied := *d
+	copied.timezone = loc
+	return &copied
+}
+
+// ForChild returns the service evaluating the schedule in the child's timezone override
+func (d *DowntimeService) ForChild(child *Child) *DowntimeService {
+	return d.In(d.locationFor(child, ""))
+}
+
+// OnDevice returns the service evaluating the schedule where the child uses the device
+// (device override > child override > configured timezone)
+func (d *DowntimeService) OnDevice(child *Child, deviceTimezone string) *DowntimeService {
+	return d.In(d.locationFor(child, deviceTimezone))
+}
+
+// locationFor returns the timezone downtime is evaluated in for a child on a device
+// Priority: device override > child override > configured timezone
+func (d *DowntimeService) locationFor(child *Child, deviceTimezone string) *time.Location {
+	return ResolveTimezone(d.timezone, deviceTimezone, child.Timezone)
+}
+
 // getScheduleForDay returns the appropriate schedule for the given day
 // Priority: per-day schedule > weekday/weekend schedule
 func (d *DowntimeService) getScheduleForDay(t time.Time) *DaySchedule {
+	return d.ScheduleForWeekday(t.In(d.timezone).Weekday())
+}
+
+// ScheduleForWeekday returns the downtime schedule of a day of the week, nil if none
+// Priority: per-day schedule > weekday/weekend schedule
+func (d *DowntimeService) ScheduleForWeekday(weekday time.Weekday) *DaySchedule {
 	if d.schedule == nil {
 		return nil
 	}
 
-	weekday := t.In(d.timezone).Weekday()
-
 	// First check explicit per-day schedule
 	switch weekday {
 	case time.Sunday:
@@ -108,6 +140,11 @@ func (d *DowntimeService) getScheduleForDay(t time.Time) *DaySchedule {
 	return d.schedule.Weekday
 }
 
+// Location returns the timezone the schedule is evaluated in
+func (d *DowntimeService) Location() *time.Location {
+	return d.timezone
+}
+
 // IsEnabled returns true if downtime schedule is configured
 func (d *DowntimeService) IsEnabled() bool {
 	if d.schedule == nil {
@@ -186,9 +223,16 @@ func (d *DowntimeService) IsInDowntimeWithContext(ctx context.Context, t time.Ti
 // IsChildInDowntime checks if downtime is active for a specific child
 // Returns true only if:
 // 1. Downtime schedule is configured
-// 2. Current time is in downtime period
+// 2. Current time is in downtime period (in the child's timezone)
 // 3. Child has downtime enabled
 func (d *DowntimeService) IsChildInDowntime(child *Child, now time.Time) bool {
+	return d.IsChildInDowntimeOnDevice(child, "", now)
+}
+
+// IsChildInDowntimeOnDevice checks if downtime is active for a child using a device
+// The schedule is evaluated in the device's timezone override if set, since that is where
+// the device is, then in the child's
+func (d *DowntimeService) IsChildInDowntimeOnDevice(child *Child, deviceTimezone string, now time.Time) bool {
 	if !d.IsEnabled() {
 		return false
 	}
@@ -197,7 +241,7 @@ func (d *DowntimeService) IsChildInDowntime(child *Child, now time.Time) bool {
 		return false
 	}
 
-	return d.IsInDowntime(now)
+	return d.OnDevice(child, deviceTimezone).IsInDowntime(now)
 }
 
 // GetCurrentDowntimeEnd returns when the current downtime period ends
@@ -276,3 +320,44 @@ func (d *DowntimeService) GetNextDowntimeStart(now time.Time) time.Time {
 	// Otherwise, return tomorrow's start time
 	return startTime.Add(24 * time.Hour)
 }
+
+// NextDowntimeWindow returns the start and end of the next downtime period starting after now
+// Unlike GetNextDowntimeStart it uses each day's own schedule and honors skip-today: a skipped
+// overnight period only starts at midnight, when the skip no longer applies.
+// Returns zero times if downtime is disabled or no day has a schedule.
+func (d *DowntimeService) NextDowntimeWindow(ctx context.Context, now time.Time) (time.Time, time.Time) {
+	if !d.IsEnabled() {
+		return time.Time{}, time.Time{}
+	}
+
+	localNow := now.In(d.timezone)
+	skipped := d.IsDowntimeSkippedToday(ctx, now)
+
+	// A week ahead covers every day of the schedule
+	for offset := 0; offset <= 7; offset++ {
+		day := time.Date(localNow.Year(), localNow.Month(), localNow.Day()+offset, 0, 0, 0, 0, d.timezone)
+		schedule := d.ScheduleForWeekday(day.Weekday())
+		if schedule == nil {
+			continue
+		}
+
+		start := time.Date(day.Year(), day.Month(), day.Day(), schedule.StartHour, schedule.StartMinute, 0, 0, d.timezone)
+		end := time.Date(day.Year(), day.Month(), day.Day(), schedule.EndHour, schedule.EndMinute, 0, 0, d.timezone)
+		if !end.After(start) {
+			// Overnight period ends the next morning
+			end = time.Date(day.Year(), day.Month(), day.Day()+1, schedule.EndHour, schedule.EndMinute, 0, 0, d.timezone)
+		}
+
+		if offset == 0 && skipped {
+			midnight := time.Date(day.Year(), day.Month(), day.Day()+1, 0, 0, 0, 0, d.timezone)
+			if !end.After(midnight) {
+				continue
+			}
+			start = midnight
+		}
+		if start.After(now) {
+			return start, end
+		}
+	}
+	return time.Time{}, time.Time{}
+}
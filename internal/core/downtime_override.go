@@ -0,0 +1,277 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"metron/internal/idgen"
+)
+
+// Downtime override errors
+var (
+	ErrDowntimeOverrideNotFound  = errors.New("downtime override not found")
+	ErrDowntimeOverrideEnded     = errors.New("downtime override has already ended")
+	ErrDowntimeAlreadyOverridden = errors.New("downtime is already overridden for the rest of the day")
+)
+
+// Who or what ended a downtime override, besides a parent cancelling it
+const (
+	DowntimeOverrideEndedBySession  = "session"  // The session it was granted for ended
+	DowntimeOverrideEndedBySchedule = "schedule" // The child's day ended
+)
+
+// DowntimeOverride lets a child use devices during downtime without changing the child's
+// downtime setting, so downtime re-arms by itself when the override ends
+// A session override (SessionID set) covers one session and ends with it; a day override
+// covers all of the child's sessions until ExpiresAt, the end of the child's day.
+type DowntimeOverride struct {
+	ID        string
+	ChildID   string
+	SessionID string // Session the override was granted for; empty for a day override
+	Reason    string
+	GrantedBy string // Who granted the override (e.g., "telegram:12345", "api")
+	StartedAt time.Time
+	ExpiresAt *time.Time // End of the child's day for day overrides; nil for session overrides
+	EndedAt   *time.Time // nil while the override applies
+	EndedBy   string     // DowntimeOverrideEndedBy*, or who cancelled it
+}
+
+// IsActive returns true if the override has not ended and has not expired
+// Session overrides also end when their session does (see DowntimeOverrideService.Active)
+func (o *DowntimeOverride) IsActive(now time.Time) bool {
+	if o.EndedAt != nil {
+		return false
+	}
+	return o.ExpiresAt == nil || now.Before(*o.ExpiresAt)
+}
+
+// Covers returns true if the override applies to the child's session (any session for day overrides)
+func (o *DowntimeOverride) Covers(childID, sessionID string) bool {
+	if o.ChildID != childID {
+		return false
+	}
+	return o.SessionID == "" || (sessionID != "" && o.SessionID == sessionID)
+}
+
+// DowntimeOverrideStorage defines the interface for downtime override persistence
+type DowntimeOverrideStorage interface {
+	ListActiveDowntimeOverrides(ctx context.Context) ([]*DowntimeOverride, error)  // Overrides not ended yet, oldest first
+	GetDowntimeOverride(ctx context.Context, id string) (*DowntimeOverride, error) // ErrDowntimeOverrideNotFound if missing
+	CreateDowntimeOverride(ctx context.Context, override *DowntimeOverride) error
+	UpdateDowntimeOverride(ctx context.Context, override *DowntimeOverride) error // Updates the end fields
+	GetChild(ctx context.Context, id string) (*Child, error)
+	GetSession(ctx context.Context, id string) (*Session, error)
+}
+
+// DowntimeOverrideService manages parent overrides of downtime
+type DowntimeOverrideService struct {
+	storage  DowntimeOverrideStorage
+	timezone *time.Location // Day boundaries for children without a timezone override
+	logger   *slog.Logger
+	mu       sync.Mutex // Serializes granting day overrides
+}
+
+// NewDowntimeOverrideService creates a new downtime override service
+func NewDowntimeOverrideService(storage DowntimeOverrideStorage, timezone *time.Location, logger *slog.Logger) *DowntimeOverrideService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if timezone == nil {
+		timezone = time.UTC
+	}
+	return &DowntimeOverrideService{
+		storage:  storage,
+		timezone: timezone,
+		logger:   logger,
+	}
+}
+
+// Active returns the overrides that still apply, optionally only a child's (empty childID lists all)
+// Overrides that expired or whose session ended are recorded as ended on the way, which is
+// what re-arms downtime
+func (s *DowntimeOverrideService) Active(ctx context.Context, childID string) ([]*DowntimeOverride, error) {
+	overrides, err := s.storage.ListActiveDowntimeOverrides(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := Now()
+	active := make([]*DowntimeOverride, 0, len(overrides))
+	for _, override := range overrides {
+		if childID != "" && override.ChildID != childID {
+			continue
+		}
+
+		endedAt, endedBy := s.endOf(ctx, override, now)
+		if endedBy == "" {
+			active = append(active, override)
+			continue
+		}
+
+		override.EndedAt = &endedAt
+		override.EndedBy = endedBy
+		if err := s.storage.UpdateDowntimeOverride(ctx, override); err != nil {
+			s.logger.Error("Failed to record end of downtime override",
+				"override_id", override.ID,
+				"error", err)
+			continue
+		}
+
+		s.logger.Info("Downtime override ended, downtime re-armed",
+			"override_id", override.ID,
+			"child_id", override.ChildID,
+			"session_id", override.SessionID,
+			"ended_by", endedBy)
+	}
+
+	return active, nil
+}
+
+// endOf returns when and why an override ended, or an empty reason if it still applies
+func (s *DowntimeOverrideService) endOf(ctx context.Context, override *DowntimeOverride, now time.Time) (time.Time, string) {
+	if !override.IsActive(now) {
+		return *override.ExpiresAt, DowntimeOverrideEndedBySchedule
+	}
+	if override.SessionID == "" {
+		return time.Time{}, ""
+	}
+
+	session, err := s.storage.GetSession(ctx, override.SessionID)
+	if errors.Is(err, ErrSessionNotFound) {
+		return now, DowntimeOverrideEndedBySession
+	}
+	if err != nil {
+		// Keep the override until the session can be checked
+		s.logger.Error("Failed to get session of downtime override",
+			"override_id", override.ID,
+			"session_id", override.SessionID,
+			"error", err)
+		return time.Time{}, ""
+	}
+	if !session.IsRunning() {
+		return session.UpdatedAt, DowntimeOverrideEndedBySession
+	}
+	return time.Time{}, ""
+}
+
+// OverrideFor returns the active override covering the child's session, or nil
+// An empty sessionID only matches day overrides, e.g. for a session that is about to start
+func (s *DowntimeOverrideService) OverrideFor(ctx context.Context, childID, sessionID string) (*DowntimeOverride, error) {
+	overrides, err := s.Active(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+	for _, override := range overrides {
+		if override.Covers(childID, sessionID) {
+			return override, nil
+		}
+	}
+	return nil, nil
+}
+
+// GrantForSession records the downtime override of a session a parent started or extended
+// It ends when the session ends; a session keeps a single override
+func (s *DowntimeOverrideService) GrantForSession(ctx context.Context, session *Session, childID, grantedBy, reason string) (*DowntimeOverride, error) {
+	current, err := s.OverrideFor(ctx, childID, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil && current.SessionID == session.ID {
+		return current, nil
+	}
+
+	override := &DowntimeOverride{
+		ID:        idgen.NewDowntimeOverride(),
+		ChildID:   childID,
+		SessionID: session.ID,
+		Reason:    reason,
+		GrantedBy: grantedBy,
+		StartedAt: Now(),
+	}
+	if err := s.storage.CreateDowntimeOverride(ctx, override); err != nil {
+		return nil, err
+	}
+	return override, nil
+}
+
+// GrantForDay lets a child use devices during downtime until the end of the child's day
+// Returns the current override with ErrDowntimeAlreadyOverridden if the child already has one
+func (s *DowntimeOverrideService) GrantForDay(ctx context.Context, childID, grantedBy, reason string) (*DowntimeOverride, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	child, err := s.storage.GetChild(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := s.OverrideFor(ctx, childID, "")
+	if err != nil {
+		return nil, err
+	}
+	if current != nil {
+		return current, ErrDowntimeAlreadyOverridden
+	}
+
+	now := Now()
+	local := now.In(child.Location(s.timezone))
+	endOfDay := time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, local.Location())
+
+	override := &DowntimeOverride{
+		ID:        idgen.NewDowntimeOverride(),
+		ChildID:   childID,
+		Reason:    reason,
+		GrantedBy: grantedBy,
+		StartedAt: now,
+		ExpiresAt: &endOfDay,
+	}
+	if err := s.storage.CreateDowntimeOverride(ctx, override); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Downtime overridden for the rest of the day",
+		"override_id", override.ID,
+		"child_id", childID,
+		"granted_by", grantedBy,
+		"expires_at", endOfDay)
+
+	return override, nil
+}
+
+// Cancel ends an override now, re-arming downtime for the child (and its session)
+// Returns the override with ErrDowntimeOverrideEnded if it has already ended
+func (s *DowntimeOverrideService) Cancel(ctx context.Context, id, cancelledBy string) (*DowntimeOverride, error) {
+	override, err := s.storage.GetDowntimeOverride(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	now := Now()
+	if endedAt, endedBy := s.endOf(ctx, override, now); override.EndedAt != nil || endedBy != "" {
+		if override.EndedAt == nil {
+			override.EndedAt = &endedAt
+			override.EndedBy = endedBy
+			if err := s.storage.UpdateDowntimeOverride(ctx, override); err != nil {
+				return nil, err
+			}
+		}
+		return override, ErrDowntimeOverrideEnded
+	}
+
+	override.EndedAt = &now
+	override.EndedBy = cancelledBy
+	if err := s.storage.UpdateDowntimeOverride(ctx, override); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Downtime override cancelled",
+		"override_id", override.ID,
+		"child_id", override.ChildID,
+		"session_id", override.SessionID,
+		"cancelled_by", cancelledBy)
+
+	return override, nil
+}
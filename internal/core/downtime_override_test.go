@@ -0,0 +1,210 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockDowntimeOverrideStorage struct {
+	*mockStorage
+	overrides []*DowntimeOverride
+}
+
+func newMockDowntimeOverrideStorage(storage *mockStorage) *mockDowntimeOverrideStorage {
+	return &mockDowntimeOverrideStorage{mockStorage: storage}
+}
+
+func (m *mockDowntimeOverrideStorage) ListActiveDowntimeOverrides(ctx context.Context) ([]*DowntimeOverride, error) {
+	var overrides []*DowntimeOverride
+	for _, override := range m.overrides {
+		if override.EndedAt == nil {
+			copied := *override
+			overrides = append(overrides, &copied)
+		}
+	}
+	return overrides, nil
+}
+
+func (m *mockDowntimeOverrideStorage) GetDowntimeOverride(ctx context.Context, id string) (*DowntimeOverride, error) {
+	for _, override := range m.overrides {
+		if override.ID == id {
+			copied := *override
+			return &copied, nil
+		}
+	}
+	return nil, ErrDowntimeOverrideNotFound
+}
+
+func (m *mockDowntimeOverrideStorage) CreateDowntimeOverride(ctx context.Context, override *DowntimeOverride) error {
+	copied := *override
+	m.overrides = append(m.overrides, &copied)
+	return nil
+}
+
+func (m *mockDowntimeOverrideStorage) UpdateDowntimeOverride(ctx context.Context, override *DowntimeOverride) error {
+	for i, existing := range m.overrides {
+		if existing.ID == override.ID {
+			copied := *override
+			m.overrides[i] = &copied
+		}
+	}
+	return nil
+}
+
+// setDowntimeOverrideClock sets the clock for the test and returns a function that moves it
+func setDowntimeOverrideClock(t *testing.T, now time.Time) func(time.Time) {
+	original := Now
+	Now = func() time.Time { return now }
+	t.Cleanup(func() { Now = original })
+	return func(next time.Time) { Now = func() time.Time { return next } }
+}
+
+func TestDowntimeOverrideService_GrantForDay(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	setClock := setDowntimeOverrideClock(t, time.Date(2026, time.March, 10, 20, 0, 0, 0, time.UTC))
+
+	storage := newMockStorage()
+	storage.CreateChild(context.Background(), &Child{ID: "child1", Name: "Alice", Timezone: "Europe/Berlin"})
+	overrides := newMockDowntimeOverrideStorage(storage)
+	service := NewDowntimeOverrideService(overrides, time.UTC, nil)
+
+	override, err := service.GrantForDay(context.Background(), "child1", "telegram:1", "birthday party")
+	require.NoError(t, err)
+	require.NotNil(t, override.ExpiresAt)
+	assert.True(t, override.ExpiresAt.Equal(time.Date(2026, time.March, 11, 0, 0, 0, 0, berlin)), "expires at the child's midnight")
+
+	current, err := service.OverrideFor(context.Background(), "child1", "")
+	require.NoError(t, err)
+	require.NotNil(t, current)
+	assert.True(t, current.Covers("child1", "session1"), "a day override covers any session")
+
+	again, err := service.GrantForDay(context.Background(), "child1", "api", "")
+	assert.ErrorIs(t, err, ErrDowntimeAlreadyOverridden)
+	assert.Equal(t, override.ID, again.ID)
+
+	_, err = service.GrantForDay(context.Background(), "missing", "api", "")
+	assert.ErrorIs(t, err, ErrChildNotFound)
+
+	// Past midnight in Berlin the override expires and downtime re-arms
+	setClock(time.Date(2026, time.March, 10, 23, 30, 0, 0, time.UTC))
+	current, err = service.OverrideFor(context.Background(), "child1", "")
+	require.NoError(t, err)
+	assert.Nil(t, current)
+
+	ended, err := overrides.GetDowntimeOverride(context.Background(), override.ID)
+	require.NoError(t, err)
+	require.NotNil(t, ended.EndedAt)
+	assert.Equal(t, DowntimeOverrideEndedBySchedule, ended.EndedBy)
+
+	// A new override can be granted for the new day
+	_, err = service.GrantForDay(context.Background(), "child1", "api", "")
+	assert.NoError(t, err)
+}
+
+func TestDowntimeOverrideService_GrantForSession(t *testing.T) {
+	setDowntimeOverrideClock(t, time.Date(2026, time.March, 10, 20, 0, 0, 0, time.UTC))
+
+	storage := newMockStorage()
+	storage.CreateChild(context.Background(), &Child{ID: "child1", Name: "Alice"})
+	session := &Session{ID: "session1", ChildIDs: []string{"child1"}, Status: SessionStatusActive}
+	storage.CreateSession(context.Background(), session)
+	overrides := newMockDowntimeOverrideStorage(storage)
+	service := NewDowntimeOverrideService(overrides, time.UTC, nil)
+
+	override, err := service.GrantForSession(context.Background(), session, "child1", "api", "movie night")
+	require.NoError(t, err)
+	assert.Nil(t, override.ExpiresAt)
+
+	// Granting again (e.g. on extension) keeps the session's override
+	again, err := service.GrantForSession(context.Background(), session, "child1", "api", "")
+	require.NoError(t, err)
+	assert.Equal(t, override.ID, again.ID)
+	assert.Len(t, overrides.overrides, 1)
+
+	current, err := service.OverrideFor(context.Background(), "child1", "session1")
+	require.NoError(t, err)
+	require.NotNil(t, current)
+
+	current, err = service.OverrideFor(context.Background(), "child1", "session2")
+	require.NoError(t, err)
+	assert.Nil(t, current, "a session override does not cover other sessions")
+
+	current, err = service.OverrideFor(context.Background(), "child1", "")
+	require.NoError(t, err)
+	assert.Nil(t, current, "a session override does not let new sessions start")
+
+	// The override ends with its session, re-arming downtime
+	session.Status = SessionStatusCompleted
+	active, err := service.Active(context.Background(), "child1")
+	require.NoError(t, err)
+	assert.Empty(t, active)
+
+	ended, err := overrides.GetDowntimeOverride(context.Background(), override.ID)
+	require.NoError(t, err)
+	assert.Equal(t, DowntimeOverrideEndedBySession, ended.EndedBy)
+}
+
+func TestDowntimeOverrideService_Cancel(t *testing.T) {
+	setDowntimeOverrideClock(t, time.Date(2026, time.March, 10, 20, 0, 0, 0, time.UTC))
+
+	storage := newMockStorage()
+	storage.CreateChild(context.Background(), &Child{ID: "child1", Name: "Alice"})
+	overrides := newMockDowntimeOverrideStorage(storage)
+	service := NewDowntimeOverrideService(overrides, time.UTC, nil)
+
+	override, err := service.GrantForDay(context.Background(), "child1", "api", "")
+	require.NoError(t, err)
+
+	cancelled, err := service.Cancel(context.Background(), override.ID, "telegram:1")
+	require.NoError(t, err)
+	require.NotNil(t, cancelled.EndedAt)
+	assert.Equal(t, "telegram:1", cancelled.EndedBy)
+
+	current, err := service.OverrideFor(context.Background(), "child1", "")
+	require.NoError(t, err)
+	assert.Nil(t, current)
+
+	_, err = service.Cancel(context.Background(), override.ID, "api")
+	assert.ErrorIs(t, err, ErrDowntimeOverrideEnded)
+
+	_, err = service.Cancel(context.Background(), "dto_missing", "api")
+	assert.ErrorIs(t, err, ErrDowntimeOverrideNotFound)
+}
+
+func TestSessionManager_DowntimeOverrideRecords(t *testing.T) {
+	manager, storage, _ := newOverrideTestManager(t)
+	overrides := newMockDowntimeOverrideStorage(storage)
+	service := NewDowntimeOverrideService(overrides, time.UTC, nil)
+	manager.SetDowntimeOverrides(service)
+
+	// A session started with a downtime override gets a record that ends with the session
+	ctx := WithOverride(context.Background(), Override{Downtime: true, By: "telegram:1", Reason: "movie night"})
+	session, err := manager.StartSession(ctx, "tv1", []string{"child1"}, 30)
+	require.NoError(t, err)
+	require.Len(t, overrides.overrides, 1)
+	assert.Equal(t, session.ID, overrides.overrides[0].SessionID)
+	assert.Equal(t, "telegram:1", overrides.overrides[0].GrantedBy)
+	assert.Equal(t, "movie night", overrides.overrides[0].Reason)
+
+	// It does not let other sessions start during downtime
+	_, err = manager.StartSession(context.Background(), "tv1", []string{"child1"}, 30)
+	assert.ErrorIs(t, err, ErrDowntimeActive)
+
+	// A day override lets the child start and extend sessions without a parent
+	_, err = service.GrantForDay(context.Background(), "child1", "api", "")
+	require.NoError(t, err)
+	err = manager.StopSession(context.Background(), session.ID)
+	require.NoError(t, err)
+
+	session, err = manager.StartSession(context.Background(), "tv1", []string{"child1"}, 20)
+	require.NoError(t, err)
+	assert.False(t, session.OverrideDowntime)
+
+	_, err = manager.ExtendSession(context.Background(), session.ID, 10)
+	assert.NoError(t, err)
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"testing"
 	"time"
 )
@@ -151,6 +152,55 @@ func TestIsChildInDowntime(t *testing.T) {
 	}
 }
 
+// TestIsChildInDowntimeOnDevice tests timezone overrides for downtime evaluation
+func TestIsChildInDowntimeOnDevice(t *testing.T) {
+	schedule := newUnifiedSchedule(22, 0, 10, 0)
+	service := NewDowntimeService(schedule, time.UTC)
+
+	// Monday 15:00 UTC: outside downtime in UTC, 23:00 in Singapore, 07:00 in Los Angeles
+	now := time.Date(2024, 1, 1, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		desc           string
+		childTimezone  string
+		deviceTimezone string
+		wantActive     bool
+	}{
+		{"server timezone", "", "", false},
+		{"child override", "Asia/Singapore", "", true},
+		{"device override", "", "Asia/Singapore", true},
+		{"device wins over child", "Asia/Singapore", "UTC", false},
+		{"device wins over child in downtime", "UTC", "America/Los_Angeles", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			child := &Child{
+				ID:              "test-child",
+				Name:            "Test",
+				DowntimeEnabled: true,
+				Timezone:        tt.childTimezone,
+			}
+			got := service.IsChildInDowntimeOnDevice(child, tt.deviceTimezone, now)
+			if got != tt.wantActive {
+				t.Errorf("IsChildInDowntimeOnDevice(child=%q, device=%q) = %v, want %v",
+					tt.childTimezone, tt.deviceTimezone, got, tt.wantActive)
+			}
+		})
+	}
+
+	// ForChild evaluates the rest of the schedule in the child's timezone
+	child := &Child{ID: "test-child", Name: "Test", DowntimeEnabled: true, Timezone: "Asia/Singapore"}
+	end := service.ForChild(child).GetCurrentDowntimeEnd(now)
+	singapore, _ := time.LoadLocation("Asia/Singapore")
+	if want := time.Date(2024, 1, 2, 10, 0, 0, 0, singapore); !end.Equal(want) {
+		t.Errorf("ForChild().GetCurrentDowntimeEnd() = %v, want %v", end, want)
+	}
+	if service.IsInDowntime(now) {
+		t.Error("ForChild() must not change the shared service")
+	}
+}
+
 // TestGetCurrentDowntimeEnd tests calculating when downtime ends
 func TestGetCurrentDowntimeEnd(t *testing.T) {
 	schedule := newUnifiedSchedule(22, 0, 10, 0)
@@ -484,3 +534,74 @@ func TestPerDaySchedules(t *testing.T) {
 		})
 	}
 }
+
+// fixedSkipStorage is a DowntimeSkipStorage with a fixed skip date
+type fixedSkipStorage struct {
+	date *time.Time
+}
+
+func (f *fixedSkipStorage) GetDowntimeSkipDate(ctx context.Context) (*time.Time, error) {
+	return f.date, nil
+}
+
+func (f *fixedSkipStorage) SetDowntimeSkipDate(ctx context.Context, date time.Time) error {
+	f.date = &date
+	return nil
+}
+
+// TestNextDowntimeWindow tests the next window with per-day schedules and skip-today
+func TestNextDowntimeWindow(t *testing.T) {
+	loc, _ := time.LoadLocation("UTC")
+	ctx := context.Background()
+
+	// School nights at 21:00, Friday night at 23:00, no downtime on Saturday
+	schedule := &DowntimeSchedule{
+		Weekday: &DaySchedule{StartHour: 21, StartMinute: 0, EndHour: 7, EndMinute: 0},
+		Friday:  &DaySchedule{StartHour: 23, StartMinute: 0, EndHour: 9, EndMinute: 0},
+		Sunday:  &DaySchedule{StartHour: 20, StartMinute: 30, EndHour: 7, EndMinute: 0},
+	}
+	service := NewDowntimeService(schedule, loc)
+
+	// 2024-01-05 is a Friday
+	tests := []struct {
+		now       time.Time
+		wantStart time.Time
+		wantEnd   time.Time
+		desc      string
+	}{
+		{time.Date(2024, 1, 1, 15, 0, 0, 0, loc), time.Date(2024, 1, 1, 21, 0, 0, 0, loc), time.Date(2024, 1, 2, 7, 0, 0, 0, loc), "Monday afternoon - tonight"},
+		{time.Date(2024, 1, 4, 22, 0, 0, 0, loc), time.Date(2024, 1, 5, 23, 0, 0, 0, loc), time.Date(2024, 1, 6, 9, 0, 0, 0, loc), "Thursday in downtime - Friday's own schedule"},
+		{time.Date(2024, 1, 6, 10, 0, 0, 0, loc), time.Date(2024, 1, 7, 20, 30, 0, 0, loc), time.Date(2024, 1, 8, 7, 0, 0, 0, loc), "Saturday without downtime - Sunday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.desc, func(t *testing.T) {
+			start, end := service.NextDowntimeWindow(ctx, tt.now)
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("NextDowntimeWindow(%v) = %v - %v, want %v - %v", tt.now.Format("Mon 15:04"),
+					start.Format("Mon 15:04"), end.Format("Mon 15:04"), tt.wantStart.Format("Mon 15:04"), tt.wantEnd.Format("Mon 15:04"))
+			}
+		})
+	}
+
+	t.Run("skipped today - starts at midnight", func(t *testing.T) {
+		now := time.Date(2024, 1, 1, 15, 0, 0, 0, loc)
+		service.SetSkipStorage(&fixedSkipStorage{date: &now})
+		defer service.SetSkipStorage(nil)
+
+		start, end := service.NextDowntimeWindow(ctx, now)
+		if want := time.Date(2024, 1, 2, 0, 0, 0, 0, loc); !start.Equal(want) {
+			t.Errorf("start = %v, want %v", start, want)
+		}
+		if want := time.Date(2024, 1, 2, 7, 0, 0, 0, loc); !end.Equal(want) {
+			t.Errorf("end = %v, want %v", end, want)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		start, _ := NewDowntimeService(nil, loc).NextDowntimeWindow(ctx, time.Now())
+		if !start.IsZero() {
+			t.Errorf("expected zero start for disabled downtime, got %v", start)
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"metron/internal/idgen"
+)
+
+// DefaultDriverCallHistory is how many driver calls are kept when no history size is configured
+const DefaultDriverCallHistory = 1000
+
+// Driver actions recorded in the driver call history
+const (
+	DriverActionStart      = "start"       // StartSession (unlocks the device, also after a locking break)
+	DriverActionStop       = "stop"        // StopSession (locks the device, also for a locking break)
+	DriverActionExtend     = "extend"      // ExtendSession
+	DriverActionWarning    = "warning"     // ApplyWarning
+	DriverActionStartBreak = "start_break" // StartBreak
+	DriverActionEndBreak   = "end_break"   // EndBreak
+)
+
+// Driver call results
+const (
+	DriverCallOK       = "ok"
+	DriverCallFailed   = "failed"
+	DriverCallTimedOut = "timeout" // Cut off by the driver's timeout or a canceled context
+)
+
+// DriverCall is one call to a device driver, recorded whatever its result
+type DriverCall struct {
+	ID        string
+	Driver    string
+	DeviceID  string
+	SessionID string
+	Action    string // DriverAction*
+	StartedAt time.Time
+	Duration  time.Duration
+	Result    string // DriverCallOK, DriverCallFailed or DriverCallTimedOut
+	Error     string
+}
+
+// DriverCallStorage defines the interface for driver call persistence
+type DriverCallStorage interface {
+	CreateDriverCall(ctx context.Context, call *DriverCall) error
+	ListDriverCalls(ctx context.Context) ([]*DriverCall, error) // Newest first
+	PruneDriverCalls(ctx context.Context, keep int) error       // Deletes all but the newest keep calls
+}
+
+// DriverCallFilter narrows the driver call history; empty fields match every call
+type DriverCallFilter struct {
+	Driver    string
+	DeviceID  string
+	SessionID string
+	Result    string
+	Limit     int // 0 = every kept call
+}
+
+// DriverCallLog records every driver call in a bounded history
+// It answers "did the TV fail to turn off, or was it never asked?": a call that was made has a
+// row with its duration and error, a call that was never made has none.
+type DriverCallLog struct {
+	storage DriverCallStorage
+	keep    int
+	logger  *slog.Logger
+}
+
+// NewDriverCallLog creates a new driver call log keeping the newest keep calls
+// (keep <= 0 uses DefaultDriverCallHistory)
+func NewDriverCallLog(storage DriverCallStorage, keep int, logger *slog.Logger) *DriverCallLog {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if keep <= 0 {
+		keep = DefaultDriverCallHistory
+	}
+	return &DriverCallLog{
+		storage: storage,
+		keep:    keep,
+		logger:  logger,
+	}
+}
+
+// Record stores a driver call and drops the oldest calls beyond the history size
+// Recording is best effort: failures are logged and never fail the call.
+func (l *DriverCallLog) Record(ctx context.Context, call *DriverCall) {
+	if call.ID == "" {
+		call.ID = idgen.NewDriverCall()
+	}
+
+	// The call already happened; record it even if the caller's request was canceled
+	ctx = context.WithoutCancel(ctx)
+	if err := l.storage.CreateDriverCall(ctx, call); err != nil {
+		l.logger.Error("Failed to record driver call",
+			"driver", call.Driver,
+			"action", call.Action,
+			"session_id", call.SessionID,
+			"error", err)
+		return
+	}
+	if err := l.storage.PruneDriverCalls(ctx, l.keep); err != nil {
+		l.logger.Warn("Failed to prune driver call history", "error", err)
+	}
+}
+
+// List returns the recorded driver calls matching the filter, newest first
+func (l *DriverCallLog) List(ctx context.Context, filter DriverCallFilter) ([]*DriverCall, error) {
+	calls, err := l.storage.ListDriverCalls(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make([]*DriverCall, 0, len(calls))
+	for _, call := range calls {
+		if (filter.Driver != "" && call.Driver != filter.Driver) ||
+			(filter.DeviceID != "" && call.DeviceID != filter.DeviceID) ||
+			(filter.SessionID != "" && call.SessionID != filter.SessionID) ||
+			(filter.Result != "" && call.Result != filter.Result) {
+			continue
+		}
+		matched = append(matched, call)
+		if filter.Limit > 0 && len(matched) == filter.Limit {
+			break
+		}
+	}
+	return matched, nil
+}
+
+// Keep returns how many driver calls are kept
+func (l *DriverCallLog) Keep() int {
+	return l.keep
+}
@@ -0,0 +1,142 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// callStorage is an in-memory DriverCallStorage, safe for abandoned driver calls
+type callStorage struct {
+	calls []*DriverCall // In insertion order
+	mu    sync.Mutex
+}
+
+func (s *callStorage) CreateDriverCall(ctx context.Context, call *DriverCall) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *call
+	s.calls = append(s.calls, &copied)
+	return nil
+}
+
+func (s *callStorage) ListDriverCalls(ctx context.Context) ([]*DriverCall, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	calls := make([]*DriverCall, len(s.calls))
+	for i, call := range s.calls {
+		copied := *call
+		calls[len(calls)-1-i] = &copied
+	}
+	sort.SliceStable(calls, func(i, j int) bool { return calls[i].StartedAt.After(calls[j].StartedAt) })
+	return calls, nil
+}
+
+func (s *callStorage) PruneDriverCalls(ctx context.Context, keep int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.calls) > keep {
+		s.calls = s.calls[len(s.calls)-keep:]
+	}
+	return nil
+}
+
+func TestDriverTimeouts_Call_RecordsCalls(t *testing.T) {
+	storage := &callStorage{}
+	log := NewDriverCallLog(storage, 10, nil)
+	timeouts := NewDriverTimeouts(time.Hour, map[string]time.Duration{"cloud": 20 * time.Millisecond})
+	timeouts.SetCallLog(log)
+	session := &Session{ID: "s1", DeviceID: "tv1"}
+	ctx := context.Background()
+
+	require.NoError(t, timeouts.Call(ctx, "cloud", DriverActionStart, session, func(ctx context.Context) error {
+		return nil
+	}))
+	require.Error(t, timeouts.Call(ctx, "cloud", DriverActionWarning, session, func(ctx context.Context) error {
+		return errors.New("scene not found")
+	}))
+
+	release := make(chan struct{})
+	defer close(release)
+	require.Error(t, timeouts.Call(ctx, "cloud", DriverActionStop, session, func(ctx context.Context) error {
+		<-release
+		return nil
+	}))
+
+	calls, err := log.List(ctx, DriverCallFilter{})
+	require.NoError(t, err)
+	require.Len(t, calls, 3)
+
+	byAction := make(map[string]*DriverCall)
+	for _, call := range calls {
+		assert.NotEmpty(t, call.ID)
+		assert.Equal(t, "cloud", call.Driver)
+		assert.Equal(t, "tv1", call.DeviceID)
+		assert.Equal(t, "s1", call.SessionID)
+		byAction[call.Action] = call
+	}
+	assert.Equal(t, DriverCallOK, byAction[DriverActionStart].Result)
+	assert.Empty(t, byAction[DriverActionStart].Error)
+	assert.Equal(t, DriverCallFailed, byAction[DriverActionWarning].Result)
+	assert.Equal(t, "scene not found", byAction[DriverActionWarning].Error)
+	assert.Equal(t, DriverCallTimedOut, byAction[DriverActionStop].Result)
+	assert.GreaterOrEqual(t, byAction[DriverActionStop].Duration, 20*time.Millisecond)
+}
+
+func TestDriverCallLog(t *testing.T) {
+	ctx := context.Background()
+	storage := &callStorage{}
+	log := NewDriverCallLog(storage, 3, nil)
+	assert.Equal(t, 3, log.Keep())
+	assert.Equal(t, DefaultDriverCallHistory, NewDriverCallLog(storage, 0, nil).Keep())
+
+	now := time.Now()
+	for i, call := range []*DriverCall{
+		{Driver: "aqara", DeviceID: "tv1", SessionID: "s1", Action: DriverActionStart, Result: DriverCallOK},
+		{Driver: "aqara", DeviceID: "tv1", SessionID: "s1", Action: DriverActionStop, Result: DriverCallOK},
+		{Driver: "kidslox", DeviceID: "ipad1", SessionID: "s2", Action: DriverActionStart, Result: DriverCallOK},
+		{Driver: "aqara", DeviceID: "tv1", SessionID: "s3", Action: DriverActionStart, Result: DriverCallFailed},
+	} {
+		call.StartedAt = now.Add(time.Duration(i) * time.Second)
+		log.Record(ctx, call)
+	}
+
+	t.Run("keeps the newest calls", func(t *testing.T) {
+		calls, err := log.List(ctx, DriverCallFilter{})
+		require.NoError(t, err)
+		require.Len(t, calls, 3)
+		assert.Equal(t, "s3", calls[0].SessionID)
+		assert.Equal(t, DriverActionStop, calls[2].Action)
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		calls, err := log.List(ctx, DriverCallFilter{Driver: "aqara"})
+		require.NoError(t, err)
+		assert.Len(t, calls, 2)
+
+		calls, err = log.List(ctx, DriverCallFilter{DeviceID: "ipad1"})
+		require.NoError(t, err)
+		require.Len(t, calls, 1)
+		assert.Equal(t, "s2", calls[0].SessionID)
+
+		calls, err = log.List(ctx, DriverCallFilter{SessionID: "s1"})
+		require.NoError(t, err)
+		assert.Len(t, calls, 1)
+
+		calls, err = log.List(ctx, DriverCallFilter{Result: DriverCallFailed})
+		require.NoError(t, err)
+		require.Len(t, calls, 1)
+		assert.Equal(t, "s3", calls[0].SessionID)
+
+		calls, err = log.List(ctx, DriverCallFilter{Driver: "aqara", Limit: 1})
+		require.NoError(t, err)
+		require.Len(t, calls, 1)
+		assert.Equal(t, "s3", calls[0].SessionID)
+	})
+}
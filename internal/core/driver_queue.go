@@ -0,0 +1,474 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"sync"
+	"time"
+
+	"metron/internal/idgen"
+
+	"github.com/google/uuid"
+)
+
+// ErrDriverJobNotFound is returned when a driver job does not exist
+var ErrDriverJobNotFound = errors.New("driver job not found")
+
+// Driver job kinds, one per driver call (the same as the call's DriverAction)
+const (
+	DriverJobStart   = "start"   // DeviceDriver.StartSession (unlocks the device)
+	DriverJobStop    = "stop"    // DeviceDriver.StopSession (locks the device)
+	DriverJobExtend  = "extend"  // ExtendableDriver.ExtendSession
+	DriverJobWarning = "warning" // DeviceDriver.ApplyWarning
+)
+
+// Driver queue defaults
+const (
+	DefaultDriverQueueWorkers     = 4
+	DefaultDriverQueueMaxAttempts = 5
+	driverJobRetryDelay           = 5 * time.Second  // First retry; doubled for every further attempt
+	driverJobClaimTTL             = 30 * time.Second // How long a queue's claim on its jobs lasts without renewal
+)
+
+// DriverJob is a driver call waiting to be made for a session
+// Jobs are stored until the call succeeds or fails for the last time, so calls survive a restart.
+type DriverJob struct {
+	ID        string
+	Kind      string // DriverJobStart, DriverJobStop, DriverJobExtend or DriverJobWarning
+	SessionID string
+	Minutes   int // Extension or minutes remaining for the warning; 0 otherwise
+	Attempts  int // Failed attempts so far
+	LastError string
+	CreatedAt time.Time
+
+	// Instances sharing a database only make the calls of the jobs they claimed
+	Owner        string    // Queue that claimed the job; empty until claimed
+	ClaimedUntil time.Time // The claim expires unless its owner renews it; expired jobs are taken over
+}
+
+// DriverJobStorage defines the interface for driver job persistence
+type DriverJobStorage interface {
+	CreateDriverJob(ctx context.Context, job *DriverJob) error
+	UpdateDriverJob(ctx context.Context, job *DriverJob) error // ErrDriverJobNotFound if missing
+	DeleteDriverJob(ctx context.Context, id string) error      // ErrDriverJobNotFound if missing
+	ListDriverJobs(ctx context.Context) ([]*DriverJob, error)  // Oldest first
+	// ClaimDriverJobs renews owner's claims until the given time and claims the jobs that are
+	// unclaimed or whose claim has expired; it returns the newly claimed jobs, oldest first
+	ClaimDriverJobs(ctx context.Context, owner string, until time.Time) ([]*DriverJob, error)
+	// ReleaseDriverJobs drops owner's claims, so other queues can take the jobs over at once
+	ReleaseDriverJobs(ctx context.Context, owner string) error
+	GetSession(ctx context.Context, id string) (*Session, error)
+}
+
+// DriverJobFailedHandler is called once a job has failed for the last time
+// It is the compensation path: the driver call will not be made.
+type DriverJobFailedHandler func(ctx context.Context, job *DriverJob, err error)
+
+// DriverQueue makes driver calls in the background with a bounded number of workers
+// Cloud drivers (Aqara, Kidslox) can take seconds to answer, so API requests only store a job.
+// Jobs of a session always go to the same worker, so its calls are made in the order they were
+// queued (a stop is never made before the start it follows). Failed calls are retried with a
+// growing delay; calls for sessions that are no longer running are dropped, except stops.
+// With several instances on one database, each queue claims the jobs it makes and renews the
+// claim while it runs, so a restarting instance never replays the jobs another one is making;
+// jobs of an instance that stopped without releasing them are taken over once the claim expires.
+type DriverQueue struct {
+	storage     DriverJobStorage
+	devices     DeviceRegistry
+	drivers     DriverRegistry
+	workers     []*driverWorker
+	owner       string // Identifies this queue in job claims
+	claimTTL    time.Duration
+	maxAttempts int
+	retryDelay  time.Duration
+	timeouts    *DriverTimeouts // Bounds each call, so a hung cloud call can't hold its worker
+	onFailed    DriverJobFailedHandler
+	logger      *slog.Logger
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+	mu      sync.Mutex
+	started bool
+}
+
+// NewDriverQueue creates a new driver queue (workers <= 0 or maxAttempts <= 0 use the defaults)
+func NewDriverQueue(storage DriverJobStorage, devices DeviceRegistry, drivers DriverRegistry, workers, maxAttempts int, logger *slog.Logger) *DriverQueue {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if workers <= 0 {
+		workers = DefaultDriverQueueWorkers
+	}
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultDriverQueueMaxAttempts
+	}
+
+	queues := make([]*driverWorker, workers)
+	for i := range queues {
+		queues[i] = &driverWorker{wake: make(chan struct{}, 1)}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &DriverQueue{
+		storage:     storage,
+		devices:     devices,
+		drivers:     drivers,
+		workers:     queues,
+		owner:       uuid.New().String(),
+		claimTTL:    driverJobClaimTTL,
+		maxAttempts: maxAttempts,
+		retryDelay:  driverJobRetryDelay,
+		logger:      logger,
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+}
+
+// Workers returns the number of driver calls made at once
+func (q *DriverQueue) Workers() int {
+	return len(q.workers)
+}
+
+// MaxAttempts returns the attempts made before a call is given up
+func (q *DriverQueue) MaxAttempts() int {
+	return q.maxAttempts
+}
+
+// SetTimeouts sets the per-driver timeouts of the queue's calls (DefaultDriverTimeout without)
+func (q *DriverQueue) SetTimeouts(timeouts *DriverTimeouts) {
+	q.timeouts = timeouts
+}
+
+// OnFailed sets the handler called for jobs that failed for the last time
+// Set it before Start.
+func (q *DriverQueue) OnFailed(handler DriverJobFailedHandler) {
+	q.onFailed = handler
+}
+
+// Enqueue stores a driver call for a session and hands it to its worker
+// The job is stored before Enqueue returns, so it is made even if the server restarts.
+func (q *DriverQueue) Enqueue(ctx context.Context, kind, sessionID string, minutes int) (*DriverJob, error) {
+	job := &DriverJob{
+		ID:        idgen.NewDriverJob(),
+		Kind:      kind,
+		SessionID: sessionID,
+		Minutes:   minutes,
+		CreatedAt: Now(),
+	}
+
+	// Before Start the job is stored unclaimed; Start claims every job nobody is making
+	q.mu.Lock()
+	started := q.started
+	q.mu.Unlock()
+	if started {
+		job.Owner = q.owner
+		job.ClaimedUntil = time.Now().Add(q.claimTTL)
+	}
+
+	if err := q.storage.CreateDriverJob(ctx, job); err != nil {
+		return nil, fmt.Errorf("failed to store driver job: %w", err)
+	}
+
+	q.logger.Debug("Driver job queued",
+		"job_id", job.ID,
+		"kind", kind,
+		"session_id", sessionID)
+
+	if started {
+		q.dispatch(job)
+	}
+	return job, nil
+}
+
+// Start claims the stored jobs nobody is making (e.g. queued before a restart) and starts the workers
+// Jobs claimed by another running instance are left to it.
+func (q *DriverQueue) Start(ctx context.Context) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.started {
+		return nil
+	}
+
+	jobs, err := q.storage.ClaimDriverJobs(ctx, q.owner, time.Now().Add(q.claimTTL))
+	if err != nil {
+		return fmt.Errorf("failed to claim driver jobs: %w", err)
+	}
+
+	for _, worker := range q.workers {
+		q.running.Add(1)
+		go q.work(worker)
+	}
+	q.running.Add(1)
+	go q.renewClaims()
+	q.started = true
+
+	if len(jobs) > 0 {
+		q.logger.Info("Resuming stored driver jobs", "count", len(jobs))
+	}
+	for _, job := range jobs {
+		q.dispatch(job)
+	}
+	return nil
+}
+
+// Stop stops the workers, waiting for the calls in flight until ctx is done
+// Jobs that were not made stay stored; their claims are released so the next Start (of this
+// or another instance) picks them up at once. If ctx ends first, the claims expire instead.
+func (q *DriverQueue) Stop(ctx context.Context) error {
+	q.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		q.running.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	q.mu.Lock()
+	started := q.started
+	q.mu.Unlock()
+	if !started {
+		return nil
+	}
+	if err := q.storage.ReleaseDriverJobs(ctx, q.owner); err != nil {
+		return fmt.Errorf("failed to release driver jobs: %w", err)
+	}
+	return nil
+}
+
+// renewClaims renews the queue's claims every third of the claim TTL until the queue stops,
+// and takes over the jobs of instances whose claims expired
+func (q *DriverQueue) renewClaims() {
+	defer q.running.Done()
+	ticker := time.NewTicker(q.claimTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-q.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		jobs, err := q.storage.ClaimDriverJobs(q.ctx, q.owner, time.Now().Add(q.claimTTL))
+		if err != nil {
+			if q.ctx.Err() == nil {
+				q.logger.Error("Failed to renew driver job claims", "error", err)
+			}
+			continue
+		}
+		if len(jobs) > 0 {
+			q.logger.Info("Taking over unclaimed driver jobs", "count", len(jobs))
+		}
+		for _, job := range jobs {
+			q.dispatch(job)
+		}
+	}
+}
+
+// driverWorker holds the jobs waiting for one worker, oldest first
+// The backlog is unbounded, so dispatching never blocks and never reorders a session's jobs.
+type driverWorker struct {
+	mu   sync.Mutex
+	jobs []*DriverJob
+	wake chan struct{} // Signalled when a job is added
+}
+
+// push adds a job to the end of the worker's backlog
+func (w *driverWorker) push(job *DriverJob) {
+	w.mu.Lock()
+	w.jobs = append(w.jobs, job)
+	w.mu.Unlock()
+
+	select {
+	case w.wake <- struct{}{}:
+	default:
+		// The worker has a wake-up pending already
+	}
+}
+
+// pop removes the oldest job from the worker's backlog (nil if it is empty)
+func (w *driverWorker) pop() *DriverJob {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.jobs) == 0 {
+		return nil
+	}
+	job := w.jobs[0]
+	w.jobs[0] = nil
+	w.jobs = w.jobs[1:]
+	return job
+}
+
+// dispatch hands a job to the worker of its session without blocking the caller
+func (q *DriverQueue) dispatch(job *DriverJob) {
+	hash := fnv.New32a()
+	hash.Write([]byte(job.SessionID))
+	q.workers[hash.Sum32()%uint32(len(q.workers))].push(job)
+}
+
+// work makes the calls handed to one worker, in the order they were handed, until the queue stops
+func (q *DriverQueue) work(worker *driverWorker) {
+	defer q.running.Done()
+	for {
+		if q.ctx.Err() != nil {
+			return
+		}
+		if job := worker.pop(); job != nil {
+			q.run(job)
+			continue
+		}
+
+		select {
+		case <-q.ctx.Done():
+			return
+		case <-worker.wake:
+		}
+	}
+}
+
+// run makes a job's driver call and retries, drops or fails the job
+func (q *DriverQueue) run(job *DriverJob) {
+	ctx := q.ctx
+
+	session, err := q.storage.GetSession(ctx, job.SessionID)
+	if errors.Is(err, ErrSessionNotFound) {
+		q.logger.Warn("Session of driver job no longer exists, dropping job",
+			"job_id", job.ID,
+			"kind", job.Kind,
+			"session_id", job.SessionID)
+		q.delete(job)
+		return
+	}
+
+	// A start retried after the session was stopped must not unlock the device again
+	if err == nil && job.Kind != DriverJobStop && !session.IsRunning() {
+		q.logger.Debug("Session is no longer running, dropping driver job",
+			"job_id", job.ID,
+			"kind", job.Kind,
+			"session_id", job.SessionID,
+			"status", session.Status)
+		q.delete(job)
+		return
+	}
+
+	if err == nil {
+		var driver DeviceDriver
+		if driver, err = q.driverFor(session); err != nil {
+			// The device or its driver is gone; retrying would not help
+			q.fail(job, err)
+			return
+		}
+
+		err = q.timeouts.Call(ctx, driver.Name(), job.Kind, session, func(ctx context.Context) error {
+			return q.call(ctx, driver, job, session)
+		})
+	}
+
+	if ctx.Err() != nil {
+		// Stopping: the job stays stored for the next start
+		return
+	}
+
+	if err == nil {
+		q.logger.Debug("Driver job done",
+			"job_id", job.ID,
+			"kind", job.Kind,
+			"session_id", job.SessionID)
+		q.delete(job)
+		return
+	}
+
+	job.Attempts++
+	job.LastError = err.Error()
+	if job.Attempts >= q.maxAttempts {
+		q.fail(job, err)
+		return
+	}
+
+	delay := q.retryDelay << (job.Attempts - 1)
+	q.logger.Warn("Driver job failed, retrying",
+		"job_id", job.ID,
+		"kind", job.Kind,
+		"session_id", job.SessionID,
+		"attempt", job.Attempts,
+		"retry_in", delay,
+		"error", err)
+
+	if err := q.storage.UpdateDriverJob(ctx, job); err != nil {
+		q.logger.Error("Failed to store driver job attempt",
+			"job_id", job.ID,
+			"error", err)
+	}
+	time.AfterFunc(delay, func() {
+		if q.ctx.Err() == nil {
+			q.dispatch(job)
+		}
+	})
+}
+
+// call makes the driver call of a job
+func (q *DriverQueue) call(ctx context.Context, driver DeviceDriver, job *DriverJob, session *Session) error {
+	switch job.Kind {
+	case DriverJobStart:
+		return driver.StartSession(ctx, session)
+	case DriverJobStop:
+		return driver.StopSession(ctx, session)
+	case DriverJobExtend:
+		extendable, ok := driver.(ExtendableDriver)
+		if !ok {
+			return nil
+		}
+		return extendable.ExtendSession(ctx, session, job.Minutes)
+	case DriverJobWarning:
+		return driver.ApplyWarning(ctx, session, job.Minutes)
+	default:
+		return fmt.Errorf("unknown driver job kind %q", job.Kind)
+	}
+}
+
+// driverFor returns the driver of a session's device
+func (q *DriverQueue) driverFor(session *Session) (DeviceDriver, error) {
+	device, err := q.devices.Get(session.DeviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device %s: %w", session.DeviceID, err)
+	}
+	driver, err := q.drivers.Get(device.GetDriver())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get driver %s for device %s: %w", device.GetDriver(), session.DeviceID, err)
+	}
+	return driver, nil
+}
+
+// fail drops a job that will not be retried and runs the compensation handler
+func (q *DriverQueue) fail(job *DriverJob, err error) {
+	q.logger.Error("Driver job failed, giving up",
+		"job_id", job.ID,
+		"kind", job.Kind,
+		"session_id", job.SessionID,
+		"attempts", job.Attempts,
+		"error", err)
+
+	q.delete(job)
+	if q.onFailed != nil {
+		q.onFailed(q.ctx, job, err)
+	}
+}
+
+// delete removes a job that is done or dropped
+func (q *DriverQueue) delete(job *DriverJob) {
+	if err := q.storage.DeleteDriverJob(q.ctx, job.ID); err != nil && !errors.Is(err, ErrDriverJobNotFound) {
+		q.logger.Error("Failed to delete driver job",
+			"job_id", job.ID,
+			"error", err)
+	}
+}
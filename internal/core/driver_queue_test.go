@@ -0,0 +1,367 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// queueStorage is a mockStorage safe for the queue's workers, with driver jobs
+// Sessions are copied in and out so workers never share them with the test
+type queueStorage struct {
+	*mockStorage
+	jobs []*DriverJob
+	mu   sync.Mutex
+}
+
+func newQueueStorage() *queueStorage {
+	return &queueStorage{mockStorage: newMockStorage()}
+}
+
+func (s *queueStorage) CreateSession(ctx context.Context, session *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *session
+	return s.mockStorage.CreateSession(ctx, &copied)
+}
+
+func (s *queueStorage) GetSession(ctx context.Context, id string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session, err := s.mockStorage.GetSession(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	copied := *session
+	return &copied, nil
+}
+
+func (s *queueStorage) UpdateSession(ctx context.Context, session *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *session
+	return s.mockStorage.UpdateSession(ctx, &copied)
+}
+
+func (s *queueStorage) DeleteSession(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.mockStorage.DeleteSession(ctx, id)
+}
+
+func (s *queueStorage) CreateDriverJob(ctx context.Context, job *DriverJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copied := *job
+	s.jobs = append(s.jobs, &copied)
+	return nil
+}
+
+func (s *queueStorage) UpdateDriverJob(ctx context.Context, job *DriverJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.jobs {
+		if existing.ID == job.ID {
+			copied := *job
+			s.jobs[i] = &copied
+			return nil
+		}
+	}
+	return ErrDriverJobNotFound
+}
+
+func (s *queueStorage) DeleteDriverJob(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.jobs {
+		if existing.ID == id {
+			s.jobs = append(s.jobs[:i], s.jobs[i+1:]...)
+			return nil
+		}
+	}
+	return ErrDriverJobNotFound
+}
+
+func (s *queueStorage) ListDriverJobs(ctx context.Context) ([]*DriverJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]*DriverJob, len(s.jobs))
+	for i, job := range s.jobs {
+		copied := *job
+		jobs[i] = &copied
+	}
+	return jobs, nil
+}
+
+func (s *queueStorage) ClaimDriverJobs(ctx context.Context, owner string, until time.Time) ([]*DriverJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var claimed []*DriverJob
+	for _, job := range s.jobs {
+		switch {
+		case job.Owner == owner:
+			job.ClaimedUntil = until
+		case !job.ClaimedUntil.After(time.Now()):
+			job.Owner = owner
+			job.ClaimedUntil = until
+			copied := *job
+			claimed = append(claimed, &copied)
+		}
+	}
+	return claimed, nil
+}
+
+func (s *queueStorage) ReleaseDriverJobs(ctx context.Context, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, job := range s.jobs {
+		if job.Owner == owner {
+			job.Owner = ""
+			job.ClaimedUntil = time.Time{}
+		}
+	}
+	return nil
+}
+
+func (s *queueStorage) jobCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.jobs)
+}
+
+// queueDriver records the calls the queue makes, safe for its workers
+type queueDriver struct {
+	calls     []string
+	failStart bool
+	holdStart chan struct{} // StartSession waits until it is closed, if set
+	mu        sync.Mutex
+}
+
+func (d *queueDriver) record(call string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls = append(d.calls, call)
+}
+
+func (d *queueDriver) Calls() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.calls...)
+}
+
+func (d *queueDriver) Name() string { return "cloud" }
+
+func (d *queueDriver) StartSession(ctx context.Context, session *Session) error {
+	if d.holdStart != nil {
+		<-d.holdStart
+	}
+	d.record(DriverJobStart)
+	if d.failStart {
+		return errors.New("cloud API unavailable")
+	}
+	return nil
+}
+
+func (d *queueDriver) StopSession(ctx context.Context, session *Session) error {
+	d.record(DriverJobStop)
+	return nil
+}
+
+func (d *queueDriver) ApplyWarning(ctx context.Context, session *Session, minutesRemaining int) error {
+	d.record(DriverJobWarning)
+	return nil
+}
+
+func (d *queueDriver) ExtendSession(ctx context.Context, session *Session, additionalMinutes int) error {
+	d.record(DriverJobExtend)
+	return nil
+}
+
+type queueDriverRegistry struct {
+	driver *queueDriver
+}
+
+func (r *queueDriverRegistry) Get(name string) (DeviceDriver, error) {
+	if name != r.driver.Name() {
+		return nil, errors.New("driver not found")
+	}
+	return r.driver, nil
+}
+
+func newTestDriverQueue(t *testing.T, storage *queueStorage, driver *queueDriver) *DriverQueue {
+	devices := newMockDeviceRegistry()
+	devices.addDevice(&mockDevice{id: "tv1", name: "TV", dtype: "tv", driver: "cloud"})
+
+	queue := NewDriverQueue(storage, devices, &queueDriverRegistry{driver: driver}, 2, 3, nil)
+	queue.retryDelay = time.Millisecond
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		queue.Stop(ctx)
+	})
+	return queue
+}
+
+func TestDriverQueue_MakesCallsInOrder(t *testing.T) {
+	ctx := context.Background()
+	storage := newQueueStorage()
+	driver := &queueDriver{}
+	queue := newTestDriverQueue(t, storage, driver)
+	require.NoError(t, queue.Start(ctx))
+
+	require.NoError(t, storage.CreateSession(ctx, &Session{ID: "s1", DeviceID: "tv1", Status: SessionStatusActive}))
+	for _, kind := range []string{DriverJobStart, DriverJobWarning, DriverJobExtend, DriverJobStop} {
+		_, err := queue.Enqueue(ctx, kind, "s1", 5)
+		require.NoError(t, err)
+	}
+
+	require.Eventually(t, func() bool { return storage.jobCount() == 0 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{DriverJobStart, DriverJobWarning, DriverJobExtend, DriverJobStop}, driver.Calls())
+}
+
+func TestDriverQueue_KeepsOrderWhenWorkerIsBehind(t *testing.T) {
+	ctx := context.Background()
+	storage := newQueueStorage()
+	driver := &queueDriver{holdStart: make(chan struct{})}
+	queue := newTestDriverQueue(t, storage, driver)
+	require.NoError(t, queue.Start(ctx))
+
+	// A slow start keeps the worker busy while far more jobs are queued behind it than it used to buffer
+	require.NoError(t, storage.CreateSession(ctx, &Session{ID: "s1", DeviceID: "tv1", Status: SessionStatusActive}))
+	want := []string{DriverJobStart}
+	_, err := queue.Enqueue(ctx, DriverJobStart, "s1", 0)
+	require.NoError(t, err)
+	for i := 0; i < 200; i++ {
+		_, err := queue.Enqueue(ctx, DriverJobWarning, "s1", 5)
+		require.NoError(t, err)
+		want = append(want, DriverJobWarning)
+	}
+	_, err = queue.Enqueue(ctx, DriverJobStop, "s1", 0)
+	require.NoError(t, err)
+	want = append(want, DriverJobStop)
+
+	close(driver.holdStart)
+	require.Eventually(t, func() bool { return storage.jobCount() == 0 }, 5*time.Second, time.Millisecond)
+	assert.Equal(t, want, driver.Calls())
+}
+
+func TestDriverQueue_ResumesStoredJobs(t *testing.T) {
+	ctx := context.Background()
+	storage := newQueueStorage()
+	driver := &queueDriver{}
+	queue := newTestDriverQueue(t, storage, driver)
+
+	// Jobs queued before Start (e.g. before a restart) are only stored
+	require.NoError(t, storage.CreateSession(ctx, &Session{ID: "s1", DeviceID: "tv1", Status: SessionStatusActive}))
+	_, err := queue.Enqueue(ctx, DriverJobStart, "s1", 0)
+	require.NoError(t, err)
+	assert.Empty(t, driver.Calls())
+	assert.Equal(t, 1, storage.jobCount())
+
+	require.NoError(t, queue.Start(ctx))
+	require.Eventually(t, func() bool { return storage.jobCount() == 0 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{DriverJobStart}, driver.Calls())
+}
+
+func TestDriverQueue_LeavesJobsClaimedByOtherInstances(t *testing.T) {
+	ctx := context.Background()
+	storage := newQueueStorage()
+	driver := &queueDriver{}
+	queue := newTestDriverQueue(t, storage, driver)
+	queue.claimTTL = 30 * time.Millisecond
+
+	require.NoError(t, storage.CreateSession(ctx, &Session{ID: "s1", DeviceID: "tv1", Status: SessionStatusActive}))
+	require.NoError(t, storage.CreateSession(ctx, &Session{ID: "s2", DeviceID: "tv1", Status: SessionStatusActive}))
+
+	// Another running instance is making s1's start; the instance that queued s2's stopped without releasing it
+	require.NoError(t, storage.CreateDriverJob(ctx, &DriverJob{ID: "drv_1", Kind: DriverJobStart, SessionID: "s1",
+		Owner: "other", ClaimedUntil: time.Now().Add(time.Hour)}))
+	require.NoError(t, storage.CreateDriverJob(ctx, &DriverJob{ID: "drv_2", Kind: DriverJobStart, SessionID: "s2",
+		Owner: "crashed", ClaimedUntil: time.Now().Add(50 * time.Millisecond)}))
+
+	require.NoError(t, queue.Start(ctx))
+	assert.Never(t, func() bool { return len(driver.Calls()) > 0 }, 20*time.Millisecond, time.Millisecond)
+
+	// The expired claim is taken over by the renewal loop; the live one is never touched
+	require.Eventually(t, func() bool { return len(driver.Calls()) == 1 }, time.Second, time.Millisecond)
+	assert.Never(t, func() bool { return len(driver.Calls()) > 1 }, 100*time.Millisecond, 5*time.Millisecond)
+	assert.Equal(t, 1, storage.jobCount())
+}
+
+func TestDriverQueue_DropsJobsOfEndedSessions(t *testing.T) {
+	ctx := context.Background()
+	storage := newQueueStorage()
+	driver := &queueDriver{}
+	queue := newTestDriverQueue(t, storage, driver)
+	require.NoError(t, queue.Start(ctx))
+
+	// A start made after the session was stopped would unlock the device again
+	require.NoError(t, storage.CreateSession(ctx, &Session{ID: "s1", DeviceID: "tv1", Status: SessionStatusCompleted}))
+	_, err := queue.Enqueue(ctx, DriverJobStart, "s1", 0)
+	require.NoError(t, err)
+	_, err = queue.Enqueue(ctx, DriverJobStop, "s1", 0)
+	require.NoError(t, err)
+
+	// Jobs of deleted sessions are dropped too
+	_, err = queue.Enqueue(ctx, DriverJobStop, "missing", 0)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool { return storage.jobCount() == 0 }, time.Second, time.Millisecond)
+	assert.Equal(t, []string{DriverJobStop}, driver.Calls())
+}
+
+func TestSessionManager_DriverQueue(t *testing.T) {
+	ctx := context.Background()
+	storage := newQueueStorage()
+	storage.mockStorage.CreateChild(ctx, &Child{ID: "child1", Name: "Alice", WeekdayLimit: 120, WeekendLimit: 120})
+
+	t.Run("start and stop are queued", func(t *testing.T) {
+		driver := &queueDriver{}
+		queue := newTestDriverQueue(t, storage, driver)
+		manager := NewSessionManager(storage, queue.devices, queue.drivers, nil, nil, nil, nil)
+		manager.SetDriverQueue(queue)
+		require.NoError(t, queue.Start(ctx))
+
+		session, err := manager.StartSession(ctx, "tv1", []string{"child1"}, 30)
+		require.NoError(t, err)
+		require.Eventually(t, func() bool { return len(driver.Calls()) == 1 }, time.Second, time.Millisecond)
+
+		// The session is completed at once; the device is locked in the background
+		require.NoError(t, manager.StopSession(ctx, session.ID))
+		stopped, err := storage.GetSession(ctx, session.ID)
+		require.NoError(t, err)
+		assert.Equal(t, SessionStatusCompleted, stopped.Status)
+
+		require.Eventually(t, func() bool { return storage.jobCount() == 0 }, time.Second, time.Millisecond)
+		assert.Equal(t, []string{DriverJobStart, DriverJobStop}, driver.Calls())
+	})
+
+	t.Run("failed start ends the session", func(t *testing.T) {
+		driver := &queueDriver{failStart: true}
+		queue := newTestDriverQueue(t, storage, driver)
+		manager := NewSessionManager(storage, queue.devices, queue.drivers, nil, nil, nil, nil)
+		manager.SetDriverQueue(queue)
+		require.NoError(t, queue.Start(ctx))
+
+		session, err := manager.StartSession(ctx, "tv1", []string{"child1"}, 30)
+		require.NoError(t, err, "the start is queued")
+
+		require.Eventually(t, func() bool {
+			ended, err := storage.GetSession(ctx, session.ID)
+			return err == nil && !ended.IsRunning()
+		}, time.Second, time.Millisecond)
+
+		ended, err := storage.GetSession(ctx, session.ID)
+		require.NoError(t, err)
+		assert.Equal(t, SessionEndDriverFailure, ended.EndReason)
+		require.NotNil(t, ended.ActualDuration)
+		assert.Equal(t, 0, *ended.ActualDuration)
+		assert.Equal(t, []string{DriverJobStart, DriverJobStart, DriverJobStart}, driver.Calls(), "retried up to max attempts")
+		assert.Equal(t, 0, storage.jobCount())
+	})
+}
@@ -0,0 +1,119 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultDriverTimeout bounds driver calls of drivers without a configured timeout
+const DefaultDriverTimeout = 30 * time.Second
+
+// ErrDriverTimeout is returned when a driver call did not finish within its timeout
+var ErrDriverTimeout = errors.New("driver call timed out")
+
+// DriverTimeouts bounds driver calls by a per-driver timeout
+// Calls get the caller's context with the driver's deadline added (an earlier deadline of the
+// caller, e.g. of an API request, is kept). Call returns once the deadline passes even if the
+// driver ignores its context, so a hung cloud call can't hold a session lock or transition.
+// Every call is recorded in the driver call log, if one is set (SetCallLog).
+// A nil *DriverTimeouts uses DefaultDriverTimeout for every driver and records nothing.
+type DriverTimeouts struct {
+	defaultTimeout time.Duration
+	drivers        map[string]time.Duration
+	log            *DriverCallLog
+}
+
+// NewDriverTimeouts creates driver timeouts (defaultTimeout <= 0 uses DefaultDriverTimeout)
+func NewDriverTimeouts(defaultTimeout time.Duration, drivers map[string]time.Duration) *DriverTimeouts {
+	if defaultTimeout <= 0 {
+		defaultTimeout = DefaultDriverTimeout
+	}
+	timeouts := &DriverTimeouts{
+		defaultTimeout: defaultTimeout,
+		drivers:        make(map[string]time.Duration, len(drivers)),
+	}
+	for name, timeout := range drivers {
+		if timeout > 0 {
+			timeouts.drivers[name] = timeout
+		}
+	}
+	return timeouts
+}
+
+// SetCallLog records every call made through the timeouts in the driver call history
+func (t *DriverTimeouts) SetCallLog(log *DriverCallLog) {
+	t.log = log
+}
+
+// For returns the timeout of calls to a driver
+func (t *DriverTimeouts) For(driver string) time.Duration {
+	if t == nil {
+		return DefaultDriverTimeout
+	}
+	if timeout, ok := t.drivers[driver]; ok {
+		return timeout
+	}
+	return t.defaultTimeout
+}
+
+// Call makes a driver action for a session, bounded by the driver's timeout
+// Returns ErrDriverTimeout if the deadline passed, or the context's error if the caller gave up.
+// The call is abandoned in both cases; drivers honoring their context stop with it.
+func (t *DriverTimeouts) Call(ctx context.Context, driver, action string, session *Session, call func(ctx context.Context) error) error {
+	started := time.Now()
+	err := t.call(ctx, driver, call)
+
+	if t != nil && t.log != nil {
+		record := &DriverCall{
+			Driver:    driver,
+			DeviceID:  session.DeviceID,
+			SessionID: session.ID,
+			Action:    action,
+			StartedAt: started,
+			Duration:  time.Since(started),
+			Result:    DriverCallOK,
+		}
+		if err != nil {
+			record.Result = DriverCallFailed
+			if IsDriverInterrupted(err) {
+				record.Result = DriverCallTimedOut
+			}
+			record.Error = err.Error()
+		}
+		t.log.Record(ctx, record)
+	}
+	return err
+}
+
+// call makes a driver call bounded by the driver's timeout
+func (t *DriverTimeouts) call(ctx context.Context, driver string, call func(ctx context.Context) error) error {
+	timeout := t.For(driver)
+	callCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- call(callCtx)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return fmt.Errorf("%w: %s after %s: %w", ErrDriverTimeout, driver, timeout, err)
+		}
+		return err
+	case <-callCtx.Done():
+		if ctx.Err() != nil {
+			return fmt.Errorf("driver %s call interrupted: %w", driver, ctx.Err())
+		}
+		return fmt.Errorf("%w: %s after %s", ErrDriverTimeout, driver, timeout)
+	}
+}
+
+// IsDriverInterrupted reports whether a driver call was cut off by its timeout or a canceled context
+// The device may then have been changed even though the call failed.
+func IsDriverInterrupted(err error) bool {
+	return errors.Is(err, ErrDriverTimeout) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
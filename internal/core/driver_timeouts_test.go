@@ -0,0 +1,133 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDriverTimeouts_For(t *testing.T) {
+	var none *DriverTimeouts
+	assert.Equal(t, DefaultDriverTimeout, none.For("aqara"))
+
+	timeouts := NewDriverTimeouts(0, map[string]time.Duration{"kidslox": 45 * time.Second, "roku": 0})
+	assert.Equal(t, DefaultDriverTimeout, timeouts.For("aqara"))
+	assert.Equal(t, 45*time.Second, timeouts.For("kidslox"))
+	assert.Equal(t, DefaultDriverTimeout, timeouts.For("roku"), "non-positive timeouts are ignored")
+
+	timeouts = NewDriverTimeouts(10*time.Second, nil)
+	assert.Equal(t, 10*time.Second, timeouts.For("aqara"))
+}
+
+func TestDriverTimeouts_Call(t *testing.T) {
+	timeouts := NewDriverTimeouts(time.Hour, map[string]time.Duration{"cloud": 20 * time.Millisecond})
+	session := &Session{ID: "s1", DeviceID: "tv1"}
+
+	t.Run("returns the call's result", func(t *testing.T) {
+		failed := errors.New("scene not found")
+		err := timeouts.Call(context.Background(), "cloud", DriverActionStart, session, func(ctx context.Context) error {
+			_, hasDeadline := ctx.Deadline()
+			assert.True(t, hasDeadline)
+			return failed
+		})
+		assert.ErrorIs(t, err, failed)
+		assert.False(t, IsDriverInterrupted(err))
+	})
+
+	t.Run("gives up on a hung driver", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+
+		start := time.Now()
+		err := timeouts.Call(context.Background(), "cloud", DriverActionStart, session, func(ctx context.Context) error {
+			<-release // Ignores its context
+			return nil
+		})
+		assert.ErrorIs(t, err, ErrDriverTimeout)
+		assert.True(t, IsDriverInterrupted(err))
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("keeps the caller's earlier deadline", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		defer cancel()
+
+		err := timeouts.Call(ctx, "other", DriverActionStart, session, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.NotErrorIs(t, err, ErrDriverTimeout, "the driver's own timeout did not pass")
+		assert.True(t, IsDriverInterrupted(err))
+	})
+
+	t.Run("stops when the caller cancels", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := timeouts.Call(ctx, "other", DriverActionStart, session, func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		})
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
+
+// hungDriver is a driver whose start never answers, safe for abandoned calls
+type hungDriver struct {
+	mockDriver
+	release chan struct{}
+	stops   int
+	mu      sync.Mutex
+}
+
+func (d *hungDriver) StartSession(ctx context.Context, session *Session) error {
+	<-d.release
+	return nil
+}
+
+func (d *hungDriver) StopSession(ctx context.Context, session *Session) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.stops++
+	return nil
+}
+
+type hungDriverRegistry struct {
+	driver *hungDriver
+}
+
+func (r *hungDriverRegistry) Get(name string) (DeviceDriver, error) {
+	return r.driver, nil
+}
+
+func TestSessionManager_StartSession_DriverTimeout(t *testing.T) {
+	ctx := context.Background()
+	storage := newMockStorage()
+	storage.CreateChild(ctx, &Child{ID: "child1", Name: "Alice", WeekdayLimit: 120, WeekendLimit: 120})
+	devices := newMockDeviceRegistry()
+	devices.addDevice(&mockDevice{id: "tv1", name: "TV", dtype: "tv", driver: "cloud"})
+
+	driver := &hungDriver{mockDriver: mockDriver{name: "cloud"}, release: make(chan struct{})}
+	defer close(driver.release)
+
+	manager := NewSessionManager(storage, devices, &hungDriverRegistry{driver: driver}, nil, nil, nil, nil)
+	manager.SetDriverTimeouts(NewDriverTimeouts(time.Hour, map[string]time.Duration{"cloud": 20 * time.Millisecond}))
+
+	_, err := manager.StartSession(ctx, "tv1", []string{"child1"}, 30)
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrDriverTimeout)
+
+	// The session is removed and the device locked again, in case the unlock still went through
+	sessions, err := storage.ListActiveSessions(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+	driver.mu.Lock()
+	assert.Equal(t, 1, driver.stops)
+	driver.mu.Unlock()
+}
@@ -0,0 +1,166 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Exempt activity errors
+var (
+	ErrInvalidActivity      = errors.New("invalid exempt activity")
+	ErrActivityBudgetUsedUp = errors.New("exempt activity budget is used up for today")
+)
+
+// ExemptActivity is an activity whose sessions are not charged against children's daily time
+// (e.g., homework on the PC, Duolingo on the tablet). Its sessions unlock the device like any
+// other, but draw on the activity's own daily budget instead.
+type ExemptActivity struct {
+	Name         string   // Requested when starting a session (e.g., "homework")
+	DeviceIDs    []string // Devices the activity may run on (empty = all)
+	DailyMinutes int      // Minutes per child per day (0 = not limited)
+}
+
+// AllowsDevice returns true if the activity may run on the device
+func (a *ExemptActivity) AllowsDevice(deviceID string) bool {
+	return len(a.DeviceIDs) == 0 || slices.Contains(a.DeviceIDs, deviceID)
+}
+
+// ActivityBudget is a child's use of an exempt activity's budget today
+type ActivityBudget struct {
+	Activity         string
+	DeviceIDs        []string
+	DailyMinutes     int // 0 if not limited
+	UsedMinutes      int // Minutes the child's sessions of the activity ran today (breaks excluded)
+	RemainingMinutes int // -1 if not limited
+}
+
+// IsExempt returns true if the session is for an exempt activity, which is not charged
+// against the children's daily time
+func (s *Session) IsExempt() bool {
+	return s.Activity != ""
+}
+
+type activityKey struct{}
+
+// WithActivity requests a session for an exempt activity (StartSession)
+func WithActivity(ctx context.Context, activity string) context.Context {
+	return context.WithValue(ctx, activityKey{}, activity)
+}
+
+// ActivityFromContext returns the exempt activity requested with WithActivity, "" if none
+func ActivityFromContext(ctx context.Context) string {
+	activity, _ := ctx.Value(activityKey{}).(string)
+	return activity
+}
+
+// SetExemptActivities sets the activities sessions can be started for without being charged
+func (m *SessionManager) SetExemptActivities(activities []ExemptActivity) {
+	m.activities = activities
+}
+
+// exemptActivity returns the configured activity with the name (case-insensitive)
+func (m *SessionManager) exemptActivity(name string) (*ExemptActivity, error) {
+	for i := range m.activities {
+		if strings.EqualFold(m.activities[i].Name, name) {
+			return &m.activities[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s is not an exempt activity", ErrInvalidActivity, name)
+}
+
+// startActivity returns the exempt activity requested for a session on the device, nil if none
+func (m *SessionManager) startActivity(ctx context.Context, deviceID string) (*ExemptActivity, error) {
+	name := ActivityFromContext(ctx)
+	if name == "" {
+		return nil, nil
+	}
+	activity, err := m.exemptActivity(name)
+	if err != nil {
+		return nil, err
+	}
+	if !activity.AllowsDevice(deviceID) {
+		return nil, fmt.Errorf("%w: %s is not allowed on %s", ErrInvalidActivity, activity.Name, deviceID)
+	}
+	return activity, nil
+}
+
+// activityUsed returns the minutes a child's sessions of the activity ran today
+// Running sessions count their elapsed time, except the one being extended (extendingID),
+// which counts its planned duration like remaining time does.
+func (m *SessionManager) activityUsed(ctx context.Context, activity *ExemptActivity, child *Child, now time.Time, extendingID string) (int, error) {
+	history, ok := m.storage.(PolicyStorage)
+	if !ok {
+		m.logger.Debug("Storage cannot list sessions, exempt activity budget not counted",
+			"child_id", child.ID)
+		return 0, nil
+	}
+	sessions, err := history.ListSessionsByChild(ctx, child.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list sessions of child %s: %w", child.ID, err)
+	}
+
+	loc := ResolveTimezone(m.timezone, child.Timezone)
+	today := UsageDate(now, loc, loc)
+	used := 0
+	for _, session := range sessions {
+		if !strings.EqualFold(session.Activity, activity.Name) || session.StartTime.Before(today) {
+			continue
+		}
+		switch {
+		case session.ID == extendingID:
+			used += session.ExpectedDuration
+		case !session.IsRunning() && session.ActualDuration != nil:
+			used += *session.ActualDuration
+		default:
+			used += m.calculator.SessionMinutes(session, now)
+		}
+	}
+	return used, nil
+}
+
+// activityRemaining returns the minutes left in a child's budget of the activity today,
+// -1 if the activity is not limited
+func (m *SessionManager) activityRemaining(ctx context.Context, activity *ExemptActivity, child *Child, now time.Time, extendingID string) (int, error) {
+	if activity.DailyMinutes <= 0 {
+		return -1, nil
+	}
+	used, err := m.activityUsed(ctx, activity, child, now, extendingID)
+	if err != nil {
+		return 0, err
+	}
+	return max(activity.DailyMinutes-used, 0), nil
+}
+
+// ActivityBudgets returns a child's use of each exempt activity's budget today
+func (m *SessionManager) ActivityBudgets(ctx context.Context, childID string) ([]*ActivityBudget, error) {
+	child, err := m.storage.GetChild(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := Now()
+	budgets := make([]*ActivityBudget, 0, len(m.activities))
+	for i := range m.activities {
+		activity := &m.activities[i]
+		used, err := m.activityUsed(ctx, activity, child, now, "")
+		if err != nil {
+			return nil, err
+		}
+		budget := &ActivityBudget{
+			Activity:         activity.Name,
+			DeviceIDs:        activity.DeviceIDs,
+			DailyMinutes:     activity.DailyMinutes,
+			UsedMinutes:      used,
+			RemainingMinutes: -1,
+		}
+		if activity.DailyMinutes > 0 {
+			budget.RemainingMinutes = max(activity.DailyMinutes-used, 0)
+		}
+		budgets = append(budgets, budget)
+	}
+	return budgets, nil
+}
@@ -0,0 +1,117 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newExemptTestManager returns a manager with the clock at noon, a PC and a PS5, a child
+// who used all 60 minutes of today, and two activities: homework on the PC (not limited)
+// and Duolingo anywhere (20 minutes a day)
+func newExemptTestManager(t *testing.T) (*SessionManager, *mockStorage, time.Time) {
+	now := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)
+	original := Now
+	Now = func() time.Time { return now }
+	t.Cleanup(func() { Now = original })
+
+	storage := newMockStorage()
+	deviceRegistry := newMockDeviceRegistry()
+	driverRegistry := newMockDriverRegistry()
+	manager := NewSessionManager(storage, deviceRegistry, driverRegistry, nil, nil, time.UTC, nil)
+	manager.SetExemptActivities([]ExemptActivity{
+		{Name: "homework", DeviceIDs: []string{"pc"}},
+		{Name: "duolingo", DailyMinutes: 20},
+	})
+
+	ctx := context.Background()
+	storage.CreateChild(ctx, &Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 60})
+	require.NoError(t, storage.IncrementDailyUsageSummary(ctx, "child1", UsageDate(now, time.UTC, time.UTC), 60))
+	driverRegistry.addDriver(&mockDriver{name: "aqara"})
+	deviceRegistry.addDevice(&mockDevice{id: "pc", name: "PC", dtype: "pc", driver: "aqara"})
+	deviceRegistry.addDevice(&mockDevice{id: "ps5", name: "PS5", dtype: "ps5", driver: "aqara"})
+	return manager, storage, now
+}
+
+func TestSessionManager_StartSession_ExemptActivity(t *testing.T) {
+	manager, storage, now := newExemptTestManager(t)
+	ctx := context.Background()
+
+	// The child's daily time is used up
+	_, err := manager.StartSession(ctx, "pc", []string{"child1"}, 30)
+	require.ErrorIs(t, err, ErrInsufficientTime)
+
+	// But homework still unlocks the PC, for as long as requested
+	session, err := manager.StartSession(WithActivity(ctx, "Homework"), "pc", []string{"child1"}, 45)
+	require.NoError(t, err)
+	assert.Equal(t, "homework", session.Activity)
+	assert.True(t, session.IsExempt())
+	assert.Equal(t, 45, session.ExpectedDuration)
+	assert.False(t, session.Grant.Capped)
+
+	// And nothing is charged when it ends
+	Now = func() time.Time { return now.Add(30 * time.Minute) }
+	require.NoError(t, manager.StopSession(ctx, session.ID))
+	usage, err := storage.GetDailyUsageSummary(ctx, "child1", UsageDate(now, time.UTC, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 60, usage.MinutesUsed)
+}
+
+func TestSessionManager_StartSession_ExemptActivityDevices(t *testing.T) {
+	manager, _, _ := newExemptTestManager(t)
+	ctx := context.Background()
+
+	_, err := manager.StartSession(WithActivity(ctx, "homework"), "ps5", []string{"child1"}, 30)
+	assert.ErrorIs(t, err, ErrInvalidActivity, "homework is only allowed on the PC")
+
+	_, err = manager.StartSession(WithActivity(ctx, "fortnite"), "ps5", []string{"child1"}, 30)
+	assert.ErrorIs(t, err, ErrInvalidActivity)
+}
+
+func TestSessionManager_ExemptActivityBudget(t *testing.T) {
+	manager, _, now := newExemptTestManager(t)
+	ctx := WithActivity(context.Background(), "duolingo")
+
+	// Capped to the activity's budget
+	session, err := manager.StartSession(ctx, "ps5", []string{"child1"}, 30)
+	require.NoError(t, err)
+	assert.Equal(t, 20, session.ExpectedDuration)
+	assert.Equal(t, CapReasonActivity, session.Grant.Reason)
+
+	// Extensions count the session's planned time, so there is nothing left
+	Now = func() time.Time { return now.Add(5 * time.Minute) }
+	_, err = manager.ExtendSession(context.Background(), session.ID, 10)
+	assert.ErrorIs(t, err, ErrActivityBudgetUsedUp)
+
+	budgets, err := manager.ActivityBudgets(context.Background(), "child1")
+	require.NoError(t, err)
+	require.Len(t, budgets, 2)
+	assert.Equal(t, "homework", budgets[0].Activity)
+	assert.Equal(t, -1, budgets[0].RemainingMinutes)
+	assert.Equal(t, "duolingo", budgets[1].Activity)
+	assert.Equal(t, 5, budgets[1].UsedMinutes, "running sessions count their elapsed time")
+	assert.Equal(t, 15, budgets[1].RemainingMinutes)
+
+	// Once the session ran its 20 minutes, no session can start
+	Now = func() time.Time { return now.Add(20 * time.Minute) }
+	require.NoError(t, manager.StopSession(context.Background(), session.ID))
+	_, err = manager.StartSession(ctx, "ps5", []string{"child1"}, 10)
+	assert.ErrorIs(t, err, ErrActivityBudgetUsedUp)
+
+	// Until tomorrow
+	Now = func() time.Time { return now.AddDate(0, 0, 1) }
+	_, err = manager.StartSession(ctx, "ps5", []string{"child1"}, 10)
+	assert.NoError(t, err)
+}
+
+func TestTimeCalculationService_ExemptSessionsNotCharged(t *testing.T) {
+	calculator := &TimeCalculationService{}
+	start := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)
+	session := &Session{StartTime: start, ExpectedDuration: 30, Activity: "homework"}
+
+	assert.Equal(t, 0, calculator.ChargeableMinutes(session, start.Add(20*time.Minute)))
+	assert.Equal(t, 20, calculator.SessionMinutes(session, start.Add(20*time.Minute)))
+}
@@ -0,0 +1,158 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ErrFamilyBudgetUsedUp is returned when the household's shared daily budget is used up
+var ErrFamilyBudgetUsedUp = errors.New("family screen time budget is used up for today")
+
+// FamilyBudget is a daily screen time budget shared by all children (e.g., 3 hours of TV),
+// enforced on top of each child's own limits
+type FamilyBudget struct {
+	Minutes     int      // Minutes per day across all children
+	DeviceIDs   []string // Devices the budget covers
+	DeviceTypes []string // Device types the budget covers (e.g., "tv"); all devices if neither is set
+}
+
+// Covers returns true if sessions on the device count against the budget
+func (b *FamilyBudget) Covers(deviceID, deviceType string) bool {
+	if len(b.DeviceIDs) == 0 && len(b.DeviceTypes) == 0 {
+		return true
+	}
+	return slices.Contains(b.DeviceIDs, deviceID) || slices.Contains(b.DeviceTypes, deviceType)
+}
+
+// FamilyBudgetStorage lists the sessions counted against the family budget
+type FamilyBudgetStorage interface {
+	ListSessionsSince(ctx context.Context, since time.Time) ([]*Session, error)
+}
+
+// FamilyBudgetDay is the family budget's use on one day
+// Sessions count on the day they started, in device minutes: shared sessions count once, however many
+// children join, and so do overlapping sessions on one device.
+type FamilyBudgetDay struct {
+	Date             time.Time // Midnight in the server timezone
+	LimitMinutes     int
+	UsedMinutes      int // Minutes covered sessions ran so far (breaks excluded)
+	CommittedMinutes int // Used minutes plus the planned rest of running sessions
+	RemainingMinutes int // Budget not committed yet: what a new session or an extension can get
+	Sessions         int
+}
+
+// FamilyBudgetService computes the family budget's use from the sessions on covered devices
+// Movie time and exempt activity sessions are not counted.
+type FamilyBudgetService struct {
+	storage  FamilyBudgetStorage
+	budget   FamilyBudget
+	timezone *time.Location
+}
+
+// NewFamilyBudgetService creates a new family budget service
+func NewFamilyBudgetService(storage FamilyBudgetStorage, budget FamilyBudget, timezone *time.Location) *FamilyBudgetService {
+	if timezone == nil {
+		timezone = time.UTC
+	}
+	return &FamilyBudgetService{
+		storage:  storage,
+		budget:   budget,
+		timezone: timezone,
+	}
+}
+
+// Budget returns the configured budget
+func (s *FamilyBudgetService) Budget() FamilyBudget {
+	return s.budget
+}
+
+// Today returns the budget's use today
+func (s *FamilyBudgetService) Today(ctx context.Context, now time.Time) (*FamilyBudgetDay, error) {
+	days, err := s.Days(ctx, now, 1)
+	if err != nil {
+		return nil, err
+	}
+	return days[0], nil
+}
+
+// Days returns the budget's use on the last n days, ending with today, oldest first
+func (s *FamilyBudgetService) Days(ctx context.Context, now time.Time, n int) ([]*FamilyBudgetDay, error) {
+	if n < 1 {
+		n = 1
+	}
+	today := UsageDate(now, s.timezone, s.timezone)
+	from := today.AddDate(0, 0, -(n - 1))
+
+	days := make([]*FamilyBudgetDay, n)
+	for i := range days {
+		days[i] = &FamilyBudgetDay{Date: from.AddDate(0, 0, i), LimitMinutes: s.budget.Minutes}
+	}
+
+	sessions, err := s.storage.ListSessionsSince(ctx, from)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions for the family budget: %w", err)
+	}
+
+	// Overlapping sessions on one device count once
+	type deviceSpans struct {
+		used      []usageSpan
+		committed []usageSpan
+	}
+	byDay := make([]map[string]*deviceSpans, n)
+	for _, session := range sessions {
+		if session.IsMovieSession || session.IsExempt() || !s.budget.Covers(session.DeviceID, session.DeviceType) {
+			continue
+		}
+		index := dayIndex(UsageDate(session.StartTime, s.timezone, s.timezone), from)
+		if index < 0 || index >= n {
+			continue
+		}
+		if byDay[index] == nil {
+			byDay[index] = make(map[string]*deviceSpans)
+		}
+		spans, ok := byDay[index][session.DeviceID]
+		if !ok {
+			spans = &deviceSpans{}
+			byDay[index][session.DeviceID] = spans
+		}
+
+		used, committed := sessionUsageSpans(session, now)
+		spans.used = append(spans.used, used)
+		spans.committed = append(spans.committed, committed)
+		days[index].Sessions++
+	}
+
+	for i, day := range days {
+		for _, spans := range byDay[i] {
+			day.UsedMinutes += unionMinutes(spans.used)
+			day.CommittedMinutes += unionMinutes(spans.committed)
+		}
+		day.RemainingMinutes = max(day.LimitMinutes-day.CommittedMinutes, 0)
+	}
+	return days, nil
+}
+
+// SetFamilyBudget sets the family budget that caps and blocks sessions on covered devices
+func (m *SessionManager) SetFamilyBudget(familyBudget *FamilyBudgetService) {
+	m.familyBudget = familyBudget
+}
+
+// familyBudgetRemaining returns the family budget left today for a session on the device,
+// or -1 if the device is not covered or there is no budget
+func (m *SessionManager) familyBudgetRemaining(ctx context.Context, deviceID, deviceType string, now time.Time) (int, error) {
+	if m.familyBudget == nil {
+		return -1, nil
+	}
+	budget := m.familyBudget.Budget()
+	if !budget.Covers(deviceID, deviceType) {
+		return -1, nil
+	}
+	today, err := m.familyBudget.Today(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	return today.RemainingMinutes, nil
+}
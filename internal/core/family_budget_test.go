@@ -0,0 +1,132 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFamilyBudget_Covers(t *testing.T) {
+	budget := FamilyBudget{Minutes: 180}
+	assert.True(t, budget.Covers("ps5", "ps5"))
+
+	budget = FamilyBudget{Minutes: 180, DeviceIDs: []string{"tv1"}, DeviceTypes: []string{"tv"}}
+	assert.True(t, budget.Covers("tv1", "projector"))
+	assert.True(t, budget.Covers("tv2", "tv"))
+	assert.False(t, budget.Covers("ps5", "ps5"))
+}
+
+// newFamilyBudgetTestManager returns a manager with the clock at noon, two children,
+// a TV and a PS5, and a 90 minute family budget on TVs
+func newFamilyBudgetTestManager(t *testing.T) (*SessionManager, *mockStorage, time.Time) {
+	now := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)
+	original := Now
+	Now = func() time.Time { return now }
+	t.Cleanup(func() { Now = original })
+
+	storage := newMockStorage()
+	deviceRegistry := newMockDeviceRegistry()
+	driverRegistry := newMockDriverRegistry()
+	manager := NewSessionManager(storage, deviceRegistry, driverRegistry, nil, nil, time.UTC, nil)
+	manager.SetFamilyBudget(NewFamilyBudgetService(storage, FamilyBudget{Minutes: 90, DeviceTypes: []string{"tv"}}, time.UTC))
+
+	storage.CreateChild(context.Background(), &Child{ID: "child1", Name: "Alice", WeekdayLimit: 240, WeekendLimit: 240})
+	storage.CreateChild(context.Background(), &Child{ID: "child2", Name: "Bob", WeekdayLimit: 240, WeekendLimit: 240})
+	driverRegistry.addDriver(&mockDriver{name: "aqara"})
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", name: "TV", dtype: "tv", driver: "aqara"})
+	deviceRegistry.addDevice(&mockDevice{id: "ps5", name: "PS5", dtype: "ps5", driver: "aqara"})
+	return manager, storage, now
+}
+
+func TestSessionManager_StartSession_FamilyBudget(t *testing.T) {
+	manager, storage, now := newFamilyBudgetTestManager(t)
+	ctx := context.Background()
+	addEndedSession(storage, "morning", "tv1", now.Add(-3*time.Hour), now.Add(-2*time.Hour))
+	storage.sessions["morning"].DeviceType = "tv"
+
+	// Alice watched an hour, so Bob gets the last 30 minutes
+	session, err := manager.StartSession(ctx, "tv1", []string{"child2"}, 60)
+	require.NoError(t, err)
+	assert.Equal(t, 30, session.ExpectedDuration)
+	assert.Equal(t, CapReasonFamilyBudget, session.Grant.Reason)
+
+	// The running session commits the rest of the budget
+	_, err = manager.ExtendSession(ctx, session.ID, 15)
+	assert.ErrorIs(t, err, ErrFamilyBudgetUsedUp)
+
+	preflight, err := manager.PreflightSession(ctx, "tv1", []string{"child1"}, 30)
+	require.NoError(t, err)
+	assert.False(t, preflight.Allowed)
+	assert.Equal(t, BlockRuleFamilyBudget, preflight.BlockedBy)
+
+	// Devices the budget does not cover are not limited
+	ps5, err := manager.StartSession(ctx, "ps5", []string{"child1"}, 60)
+	require.NoError(t, err)
+	assert.Equal(t, 60, ps5.ExpectedDuration)
+
+	// A limits override skips the budget
+	override := WithOverride(ctx, Override{Limits: true, By: "api"})
+	extended, err := manager.ExtendSession(override, session.ID, 15)
+	require.NoError(t, err)
+	assert.Equal(t, 45, extended.ExpectedDuration)
+}
+
+func TestSessionManager_ExtendSession_FamilyBudgetCaps(t *testing.T) {
+	manager, _, _ := newFamilyBudgetTestManager(t)
+	ctx := context.Background()
+
+	session, err := manager.StartSession(ctx, "tv1", []string{"child1", "child2"}, 75)
+	require.NoError(t, err)
+	assert.Equal(t, 75, session.ExpectedDuration)
+	assert.False(t, session.Grant.Capped)
+
+	// A shared session counts once against the budget
+	extended, err := manager.ExtendSession(ctx, session.ID, 20)
+	require.NoError(t, err)
+	assert.Equal(t, 90, extended.ExpectedDuration)
+	assert.Equal(t, 15, extended.Grant.GrantedMinutes)
+	assert.Equal(t, CapReasonFamilyBudget, extended.Grant.Reason)
+}
+
+func TestFamilyBudgetService_Days(t *testing.T) {
+	_, storage, now := newFamilyBudgetTestManager(t)
+	addEndedSession(storage, "yesterday", "tv1", now.Add(-25*time.Hour), now.Add(-24*time.Hour))
+	addEndedSession(storage, "today", "tv1", now.Add(-2*time.Hour), now.Add(-100*time.Minute))
+	addEndedSession(storage, "console", "ps5", now.Add(-2*time.Hour), now.Add(-time.Hour))
+	storage.sessions["yesterday"].DeviceType = "tv"
+	storage.sessions["today"].DeviceType = "tv"
+	storage.sessions["console"].DeviceType = "ps5"
+
+	service := NewFamilyBudgetService(storage, FamilyBudget{Minutes: 90, DeviceTypes: []string{"tv"}}, time.UTC)
+	days, err := service.Days(context.Background(), now, 2)
+	require.NoError(t, err)
+	require.Len(t, days, 2)
+
+	assert.Equal(t, time.Date(2026, time.March, 9, 0, 0, 0, 0, time.UTC), days[0].Date)
+	assert.Equal(t, 60, days[0].UsedMinutes)
+	assert.Equal(t, 1, days[0].Sessions)
+
+	assert.Equal(t, 20, days[1].UsedMinutes)
+	assert.Equal(t, 70, days[1].RemainingMinutes)
+	assert.Equal(t, 1, days[1].Sessions)
+}
+
+func TestFamilyBudgetService_Days_OverlappingSessions(t *testing.T) {
+	_, storage, now := newFamilyBudgetTestManager(t)
+	addEndedSession(storage, "alice", "tv1", now.Add(-4*time.Hour), now.Add(-3*time.Hour))
+	addEndedSession(storage, "bob", "tv1", now.Add(-210*time.Minute), now.Add(-150*time.Minute))
+	storage.sessions["alice"].DeviceType = "tv"
+	storage.sessions["bob"].DeviceType = "tv"
+	storage.sessions["bob"].ChildIDs = []string{"child2"}
+
+	// Two sessions on one TV at the same time use it once
+	service := NewFamilyBudgetService(storage, FamilyBudget{Minutes: 180, DeviceTypes: []string{"tv"}}, time.UTC)
+	today, err := service.Today(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 90, today.UsedMinutes)
+	assert.Equal(t, 90, today.RemainingMinutes)
+	assert.Equal(t, 2, today.Sessions)
+}
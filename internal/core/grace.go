@@ -0,0 +1,87 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// MaxGraceMinutes is the largest grace allowance a child can have
+const MaxGraceMinutes = 30
+
+// Grace overage
+//
+// A child with GraceMinutes > 0 does not get a hard stop the moment the daily limit is hit:
+//   - When a session reaches its planned end and a child in it has no time left, the session
+//     keeps running until GraceEndsAt (planned end + the smallest allowance of the children
+//     at the limit). If any of those children has no allowance, the session ends as usual.
+//   - Grace time is never charged to today (it is past the planned end, see the charge policy).
+//     Instead the overage, from the planned end to the actual end, is deducted from every
+//     charged child's allocation for the next day, like a fine.
+//   - Stopping the session during grace (e.g. once the game is saved) ends the overage.
+//   - Extending the session leaves grace; the extension is checked against the limit as usual.
+
+// GraceOverageMinutes returns the minutes the session ran past its planned end during grace
+// if it ends at end; 0 if the session never entered grace
+func (s *Session) GraceOverageMinutes(end time.Time) int {
+	if s.GraceEndsAt == nil {
+		return 0
+	}
+	if end.After(*s.GraceEndsAt) {
+		end = *s.GraceEndsAt
+	}
+	plannedEnd := s.StartTime.Add(time.Duration(s.ExpectedDuration) * time.Minute)
+	if !end.After(plannedEnd) {
+		return 0
+	}
+	return int(end.Sub(plannedEnd).Minutes())
+}
+
+// GraceMinutes returns the grace a session gets when it reaches its planned end:
+// the smallest allowance of the given children who have no time left today
+// Returns 0 if no child is at the limit or one of them has no allowance
+func (s *TimeCalculationService) GraceMinutes(ctx context.Context, session *Session, children []*Child) (int, error) {
+	if session.IsMovieSession || session.IsExempt() {
+		return 0, nil
+	}
+
+	grace := 0
+	for _, child := range children {
+		remaining, err := s.GetRemainingTime(ctx, child.ID, Now())
+		if err != nil {
+			return 0, err
+		}
+		if remaining.RemainingTotal > 0 {
+			continue
+		}
+		if child.GraceMinutes <= 0 {
+			return 0, nil
+		}
+		if grace == 0 || child.GraceMinutes < grace {
+			grace = child.GraceMinutes
+		}
+	}
+	return grace, nil
+}
+
+// ChargeGraceOverage deducts the grace overage of a session ending at end from the child's
+// allocation for the next day and returns the deducted minutes
+func (s *TimeCalculationService) ChargeGraceOverage(ctx context.Context, session *Session, childID string, end time.Time) (int, error) {
+	minutes := session.GraceOverageMinutes(end)
+	if minutes == 0 {
+		return 0, nil
+	}
+
+	tomorrow := s.UsageDate(ctx, childID, end).AddDate(0, 0, 1)
+	allocation, err := s.getOrCreateAllocation(ctx, childID, tomorrow)
+	if err != nil {
+		return 0, err
+	}
+
+	allocation.BonusGranted -= minutes
+	allocation.UpdatedAt = Now()
+	if err := s.storage.UpdateDailyAllocation(ctx, allocation); err != nil {
+		return 0, fmt.Errorf("failed to deduct grace overage: %w", err)
+	}
+	return minutes, nil
+}
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSession_GraceOverageMinutes(t *testing.T) {
+	start := time.Date(2026, 3, 2, 16, 0, 0, 0, time.UTC)
+	graceEnds := start.Add(35 * time.Minute)
+
+	tests := []struct {
+		name      string
+		graceEnds *time.Time
+		end       time.Time
+		want      int
+	}{
+		{"not in grace", nil, start.Add(40 * time.Minute), 0},
+		{"stopped before planned end", &graceEnds, start.Add(20 * time.Minute), 0},
+		{"stopped during grace", &graceEnds, start.Add(33 * time.Minute), 3},
+		{"capped at grace end", &graceEnds, start.Add(50 * time.Minute), 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := &Session{StartTime: start, ExpectedDuration: 30, GraceEndsAt: tt.graceEnds}
+			assert.Equal(t, tt.want, session.GraceOverageMinutes(tt.end))
+		})
+	}
+}
+
+func TestTimeCalculationService_GraceMinutes(t *testing.T) {
+	ctx := context.Background()
+	storage := newMockTimeCalcStorage()
+	service := NewTimeCalculationService(storage, time.UTC)
+
+	atLimit := &Child{ID: "child1", WeekdayLimit: 30, WeekendLimit: 30, GraceMinutes: 5}
+	atLimitLonger := &Child{ID: "child2", WeekdayLimit: 30, WeekendLimit: 30, GraceMinutes: 10}
+	atLimitNoGrace := &Child{ID: "child3", WeekdayLimit: 30, WeekendLimit: 30}
+	timeLeft := &Child{ID: "child4", WeekdayLimit: 120, WeekendLimit: 120}
+	for _, child := range []*Child{atLimit, atLimitLonger, atLimitNoGrace, timeLeft} {
+		storage.children[child.ID] = child
+	}
+
+	// A 30 minute session that just reached its planned end
+	storage.sessions = []*SessionUsageRecord{{
+		ID:               "s1",
+		ChildIDs:         []string{"child1", "child2", "child3", "child4"},
+		StartTime:        Now().Add(-31 * time.Minute),
+		ExpectedDuration: 30,
+		Status:           SessionStatusActive,
+	}}
+	session := &Session{ID: "s1"}
+
+	grace, err := service.GraceMinutes(ctx, session, []*Child{atLimit, atLimitLonger, timeLeft})
+	require.NoError(t, err)
+	assert.Equal(t, 5, grace, "smallest allowance of the children at the limit")
+
+	grace, err = service.GraceMinutes(ctx, session, []*Child{atLimit, atLimitNoGrace})
+	require.NoError(t, err)
+	assert.Equal(t, 0, grace, "a child at the limit without an allowance gets a hard stop")
+
+	grace, err = service.GraceMinutes(ctx, session, []*Child{timeLeft})
+	require.NoError(t, err)
+	assert.Equal(t, 0, grace, "no child at the limit")
+
+	grace, err = service.GraceMinutes(ctx, &Session{ID: "s1", IsMovieSession: true}, []*Child{atLimit})
+	require.NoError(t, err)
+	assert.Equal(t, 0, grace, "movie sessions do not use the limit")
+}
+
+func TestTimeCalculationService_ChargeGraceOverage(t *testing.T) {
+	ctx := context.Background()
+	storage := newMockTimeCalcStorage()
+	service := NewTimeCalculationService(storage, time.UTC)
+	storage.children["child1"] = &Child{ID: "child1", WeekdayLimit: 60, WeekendLimit: 90, GraceMinutes: 5}
+
+	// Monday session that ran 4 minutes into its grace
+	start := time.Date(2026, 3, 2, 18, 0, 0, 0, time.UTC)
+	graceEnds := start.Add(35 * time.Minute)
+	session := &Session{StartTime: start, ExpectedDuration: 30, GraceEndsAt: &graceEnds}
+
+	overage, err := service.ChargeGraceOverage(ctx, session, "child1", start.Add(34*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 4, overage)
+
+	tomorrow, err := service.GetAvailableTime(ctx, "child1", makeDate(2026, 3, 3))
+	require.NoError(t, err)
+	assert.Equal(t, 60, tomorrow.BaseLimit)
+	assert.Equal(t, -4, tomorrow.BonusGranted)
+	assert.Equal(t, 56, tomorrow.TotalAvailable)
+
+	today, err := service.GetAvailableTime(ctx, "child1", makeDate(2026, 3, 2))
+	require.NoError(t, err)
+	assert.Equal(t, 0, today.BonusGranted, "today is not charged")
+
+	// Sessions that never entered grace deduct nothing
+	overage, err = service.ChargeGraceOverage(ctx, &Session{StartTime: start, ExpectedDuration: 30}, "child1", start.Add(40*time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, 0, overage)
+}
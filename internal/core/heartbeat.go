@@ -0,0 +1,115 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Heartbeat sources
+const (
+	HeartbeatSourceAgent = "agent" // Device agent polled for its session or reported activity
+)
+
+// heartbeatPersistInterval limits heartbeat writes: agents poll every few seconds
+const heartbeatPersistInterval = time.Minute
+
+// DeviceHeartbeat records when a device last checked in
+type DeviceHeartbeat struct {
+	DeviceID   string
+	Source     string // What reported the device (an agent, or the name of a polling driver)
+	LastSeenAt time.Time
+}
+
+// HeartbeatStorage defines the interface for heartbeat persistence
+type HeartbeatStorage interface {
+	SaveDeviceHeartbeat(ctx context.Context, heartbeat *DeviceHeartbeat) error // Insert or replace
+	ListDeviceHeartbeats(ctx context.Context) ([]*DeviceHeartbeat, error)
+}
+
+// HeartbeatService tracks when each device last checked in
+// Heartbeats are kept in memory and written through to storage at most once a minute per device,
+// so a restart loses at most a minute of last-seen precision
+type HeartbeatService struct {
+	storage HeartbeatStorage
+	logger  *slog.Logger
+
+	mu        sync.Mutex
+	loaded    bool
+	lastSeen  map[string]*DeviceHeartbeat
+	persisted map[string]time.Time // LastSeenAt of the last stored heartbeat per device
+}
+
+// NewHeartbeatService creates a new heartbeat service
+func NewHeartbeatService(storage HeartbeatStorage, logger *slog.Logger) *HeartbeatService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &HeartbeatService{
+		storage:   storage,
+		logger:    logger,
+		lastSeen:  make(map[string]*DeviceHeartbeat),
+		persisted: make(map[string]time.Time),
+	}
+}
+
+// Record marks a device as seen now
+func (s *HeartbeatService) Record(ctx context.Context, deviceID, source string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(ctx); err != nil {
+		return err
+	}
+
+	now := Now()
+	heartbeat := &DeviceHeartbeat{DeviceID: deviceID, Source: source, LastSeenAt: now}
+	s.lastSeen[deviceID] = heartbeat
+
+	if persisted, ok := s.persisted[deviceID]; ok && now.Sub(persisted) < heartbeatPersistInterval {
+		return nil
+	}
+	if err := s.storage.SaveDeviceHeartbeat(ctx, heartbeat); err != nil {
+		return err
+	}
+	s.persisted[deviceID] = now
+	return nil
+}
+
+// LastSeen returns the latest heartbeat of every device that has checked in, keyed by device ID
+func (s *HeartbeatService) LastSeen(ctx context.Context) (map[string]*DeviceHeartbeat, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(ctx); err != nil {
+		return nil, err
+	}
+
+	heartbeats := make(map[string]*DeviceHeartbeat, len(s.lastSeen))
+	for deviceID, heartbeat := range s.lastSeen {
+		copied := *heartbeat
+		heartbeats[deviceID] = &copied
+	}
+	return heartbeats, nil
+}
+
+// load reads the stored heartbeats once; the caller holds s.mu
+func (s *HeartbeatService) load(ctx context.Context) error {
+	if s.loaded {
+		return nil
+	}
+
+	heartbeats, err := s.storage.ListDeviceHeartbeats(ctx)
+	if err != nil {
+		return err
+	}
+	for _, heartbeat := range heartbeats {
+		s.lastSeen[heartbeat.DeviceID] = heartbeat
+		s.persisted[heartbeat.DeviceID] = heartbeat.LastSeenAt
+	}
+	s.loaded = true
+
+	s.logger.Debug("Loaded device heartbeats", "devices", len(heartbeats))
+	return nil
+}
@@ -0,0 +1,84 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockHeartbeatStorage struct {
+	heartbeats map[string]*DeviceHeartbeat
+	saves      int
+}
+
+func newMockHeartbeatStorage() *mockHeartbeatStorage {
+	return &mockHeartbeatStorage{heartbeats: make(map[string]*DeviceHeartbeat)}
+}
+
+func (m *mockHeartbeatStorage) SaveDeviceHeartbeat(ctx context.Context, heartbeat *DeviceHeartbeat) error {
+	copied := *heartbeat
+	m.heartbeats[heartbeat.DeviceID] = &copied
+	m.saves++
+	return nil
+}
+
+func (m *mockHeartbeatStorage) ListDeviceHeartbeats(ctx context.Context) ([]*DeviceHeartbeat, error) {
+	heartbeats := make([]*DeviceHeartbeat, 0, len(m.heartbeats))
+	for _, heartbeat := range m.heartbeats {
+		copied := *heartbeat
+		heartbeats = append(heartbeats, &copied)
+	}
+	return heartbeats, nil
+}
+
+func TestHeartbeatService_Record(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	original := Now
+	Now = func() time.Time { return now }
+	t.Cleanup(func() { Now = original })
+
+	storage := newMockHeartbeatStorage()
+	service := NewHeartbeatService(storage, nil)
+	ctx := context.Background()
+
+	require.NoError(t, service.Record(ctx, "win-pc1", HeartbeatSourceAgent))
+	assert.Equal(t, 1, storage.saves)
+
+	// Heartbeats within a minute update memory only
+	now = now.Add(20 * time.Second)
+	require.NoError(t, service.Record(ctx, "win-pc1", HeartbeatSourceAgent))
+	assert.Equal(t, 1, storage.saves)
+
+	lastSeen, err := service.LastSeen(ctx)
+	require.NoError(t, err)
+	require.Contains(t, lastSeen, "win-pc1")
+	assert.Equal(t, now, lastSeen["win-pc1"].LastSeenAt)
+	assert.Equal(t, HeartbeatSourceAgent, lastSeen["win-pc1"].Source)
+
+	// After a minute the heartbeat is written through again
+	now = now.Add(time.Minute)
+	require.NoError(t, service.Record(ctx, "win-pc1", HeartbeatSourceAgent))
+	assert.Equal(t, 2, storage.saves)
+	assert.Equal(t, now, storage.heartbeats["win-pc1"].LastSeenAt)
+
+	// Each device is throttled separately
+	require.NoError(t, service.Record(ctx, "win-pc2", HeartbeatSourceAgent))
+	assert.Equal(t, 3, storage.saves)
+}
+
+func TestHeartbeatService_LoadsStoredHeartbeats(t *testing.T) {
+	storage := newMockHeartbeatStorage()
+	seen := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	storage.heartbeats["win-pc1"] = &DeviceHeartbeat{DeviceID: "win-pc1", Source: HeartbeatSourceAgent, LastSeenAt: seen}
+
+	// A restarted server still knows when devices were last seen
+	service := NewHeartbeatService(storage, nil)
+	lastSeen, err := service.LastSeen(context.Background())
+	require.NoError(t, err)
+	require.Contains(t, lastSeen, "win-pc1")
+	assert.Equal(t, seen, lastSeen["win-pc1"].LastSeenAt)
+	assert.NotContains(t, lastSeen, "tv1")
+}
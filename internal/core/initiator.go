@@ -0,0 +1,74 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidInitiator is returned for an initiator type other than the Initiator* types
+var ErrInvalidInitiator = errors.New("invalid session initiator")
+
+// ErrInitiatorLimit is returned when a session already runs as long as its initiator may make it
+var ErrInitiatorLimit = errors.New("session length limit for this initiator reached")
+
+// Types of who starts (or extends) a session
+const (
+	InitiatorParent     = "parent"     // A parent: Telegram bot (ID "telegram:<user>") or HomeKit (ID "homekit")
+	InitiatorChild      = "child"      // The child in the web app (ID is the child ID)
+	InitiatorAPI        = "api"        // A client of the API key that did not say who it acts for
+	InitiatorAutomation = "automation" // An integration starting sessions on its own (e.g., ID "steam")
+)
+
+// Initiator is who started a session: a type (Initiator* constants) and an identifier
+// Sessions started before initiators were recorded have an empty one.
+type Initiator struct {
+	Type string
+	ID   string
+}
+
+// ValidInitiatorType returns true for the Initiator* types
+func ValidInitiatorType(initiatorType string) bool {
+	switch initiatorType {
+	case InitiatorParent, InitiatorChild, InitiatorAPI, InitiatorAutomation:
+		return true
+	}
+	return false
+}
+
+// ParseInitiator builds an initiator from a type and an identifier
+// Returns ErrInvalidInitiator for unknown types
+func ParseInitiator(initiatorType, id string) (Initiator, error) {
+	if !ValidInitiatorType(initiatorType) {
+		return Initiator{}, fmt.Errorf("%w: %q (must be %s, %s, %s or %s)", ErrInvalidInitiator, initiatorType,
+			InitiatorParent, InitiatorChild, InitiatorAPI, InitiatorAutomation)
+	}
+	return Initiator{Type: initiatorType, ID: id}, nil
+}
+
+// String describes the initiator for logs (e.g., "parent:telegram:alice")
+func (i Initiator) String() string {
+	if i.ID == "" {
+		return i.Type
+	}
+	return i.Type + ":" + i.ID
+}
+
+type initiatorKey struct{}
+
+// WithInitiator returns a context under which StartSession records the initiator on the new
+// session, and StartSession and ExtendSession apply the initiator's session length limit
+func WithInitiator(ctx context.Context, initiator Initiator) context.Context {
+	return context.WithValue(ctx, initiatorKey{}, initiator)
+}
+
+// InitiatorFromContext returns the initiator set by WithInitiator, or an empty one
+func InitiatorFromContext(ctx context.Context) Initiator {
+	initiator, _ := ctx.Value(initiatorKey{}).(Initiator)
+	return initiator
+}
+
+// initiatorLimit returns the session length limit of the initiator in the context, or 0 if it has none
+func (m *SessionManager) initiatorLimit(ctx context.Context) int {
+	return m.initiatorCaps[InitiatorFromContext(ctx).Type]
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseInitiator(t *testing.T) {
+	initiator, err := ParseInitiator(InitiatorChild, "child1")
+	require.NoError(t, err)
+	assert.Equal(t, "child:child1", initiator.String())
+
+	initiator, err = ParseInitiator(InitiatorAPI, "")
+	require.NoError(t, err)
+	assert.Equal(t, "api", initiator.String())
+
+	_, err = ParseInitiator("robot", "r2")
+	assert.ErrorIs(t, err, ErrInvalidInitiator)
+}
+
+// newInitiatorTestManager returns a manager that caps child-initiated sessions at 30 minutes
+func newInitiatorTestManager(t *testing.T) (*SessionManager, *mockStorage) {
+	now := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)
+	original := Now
+	Now = func() time.Time { return now }
+	t.Cleanup(func() { Now = original })
+
+	storage := newMockStorage()
+	deviceRegistry := newMockDeviceRegistry()
+	driverRegistry := newMockDriverRegistry()
+	manager := NewSessionManager(storage, deviceRegistry, driverRegistry, nil, nil, time.UTC, nil)
+	manager.SetInitiatorLimits(map[string]int{InitiatorChild: 30})
+
+	storage.CreateChild(context.Background(), &Child{
+		ID:           "child1",
+		Name:         "Alice",
+		WeekdayLimit: 120,
+		WeekendLimit: 120,
+	})
+	driverRegistry.addDriver(&mockDriver{name: "aqara"})
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", name: "TV", dtype: "tv", driver: "aqara"})
+	return manager, storage
+}
+
+func TestSessionManager_StartSession_InitiatorLimit(t *testing.T) {
+	manager, storage := newInitiatorTestManager(t)
+
+	ctx := WithInitiator(context.Background(), Initiator{Type: InitiatorChild, ID: "child1"})
+	session, err := manager.StartSession(ctx, "tv1", []string{"child1"}, 60)
+	require.NoError(t, err)
+	assert.Equal(t, 30, session.ExpectedDuration)
+
+	stored, err := storage.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, InitiatorChild, stored.InitiatorType)
+	assert.Equal(t, "child1", stored.InitiatorID)
+
+	// Extending up to the limit is rejected, however often the child asks
+	_, err = manager.ExtendSession(ctx, session.ID, 15)
+	assert.ErrorIs(t, err, ErrInitiatorLimit)
+
+	// A parent is not limited
+	parent := WithInitiator(context.Background(), Initiator{Type: InitiatorParent, ID: "telegram:1"})
+	extended, err := manager.ExtendSession(parent, session.ID, 15)
+	require.NoError(t, err)
+	assert.Equal(t, 45, extended.ExpectedDuration)
+}
+
+func TestSessionManager_ExtendSession_InitiatorLimitCaps(t *testing.T) {
+	manager, _ := newInitiatorTestManager(t)
+
+	parent := WithInitiator(context.Background(), Initiator{Type: InitiatorParent, ID: "telegram:1"})
+	session, err := manager.StartSession(parent, "tv1", []string{"child1"}, 20)
+	require.NoError(t, err)
+	assert.Equal(t, 20, session.ExpectedDuration)
+
+	child := WithInitiator(context.Background(), Initiator{Type: InitiatorChild, ID: "child1"})
+	extended, err := manager.ExtendSession(child, session.ID, 30)
+	require.NoError(t, err)
+	assert.Equal(t, 30, extended.ExpectedDuration)
+}
@@ -8,9 +8,15 @@ type SessionManagerInterface interface {
 	StopSession(ctx context.Context, sessionID string) error
 	ExtendSession(ctx context.Context, sessionID string, additionalMinutes int) (*Session, error)
 	AddChildrenToSession(ctx context.Context, sessionID string, childIDs []string) (*Session, error)
+	RemoveChildFromSession(ctx context.Context, sessionID string, childID string) (*Session, error)
+	AddSessionNote(ctx context.Context, sessionID string, note string) (*Session, error)
 	GetSession(ctx context.Context, sessionID string) (*Session, error)
 	ListActiveSessions(ctx context.Context) ([]*Session, error)
 	GrantRewardMinutes(ctx context.Context, childID string, minutes int) error
 	DeductFineMinutes(ctx context.Context, childID string, minutes int) error
 	GetChildStatus(ctx context.Context, childID string) (*ChildStatus, error)
+	GetChildrenStatus(ctx context.Context) ([]*ChildStatus, error)
+	GetDurationSuggestions(ctx context.Context, childID string) (*DurationSuggestions, error)
+	PreflightSession(ctx context.Context, deviceID string, childIDs []string, durationMinutes int) (*SessionPreflight, error)
+	ActivityBudgets(ctx context.Context, childID string) ([]*ActivityBudget, error)
 }
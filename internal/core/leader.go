@@ -0,0 +1,187 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultLeaderLeaseTTL is how long a leader lease lasts without renewal
+const DefaultLeaderLeaseTTL = 30 * time.Second
+
+// leaderCheckInterval is how often RunWhileLeader checks whether this instance still leads
+const leaderCheckInterval = time.Second
+
+// LeaderLeaseStorage holds named leases that let one of several instances sharing a database lead
+// The SQLite and memory backends keep a lease row that expires unless renewed; a Postgres
+// backend can implement it with a session advisory lock (pg_try_advisory_lock).
+type LeaderLeaseStorage interface {
+	// AcquireLeaderLease takes or renews the named lease for owner until the given time
+	// It succeeds if the lease is free, has expired, or owner already holds it
+	AcquireLeaderLease(ctx context.Context, name, owner string, until time.Time) (bool, error)
+	// ReleaseLeaderLease gives up owner's lease; leases of other owners are kept
+	ReleaseLeaderLease(ctx context.Context, name, owner string) error
+}
+
+// LeaderElection elects one leader among instances sharing a database, e.g. two servers run
+// for redundancy: both serve the API, only the leader runs scheduler actions.
+// The leader renews its lease every third of the TTL; if it stops (crash, network split), another
+// instance takes over once the lease expires. An instance counts itself as leader only until its
+// last successful renewal's lease ends, so a stalled leader steps down before anyone takes over.
+type LeaderElection struct {
+	storage LeaderLeaseStorage
+	name    string
+	owner   string // Identifies this instance in the lease
+	ttl     time.Duration
+	check   time.Duration // RunWhileLeader's check interval
+	logger  *slog.Logger
+
+	leaseUntil atomic.Int64 // Unix nanoseconds; 0 = not leader
+	mu         sync.Mutex   // Serializes campaigns with Resign
+	resigned   bool
+}
+
+// NewLeaderElection creates an election for the named lease (ttl <= 0 uses DefaultLeaderLeaseTTL)
+func NewLeaderElection(storage LeaderLeaseStorage, name string, ttl time.Duration, logger *slog.Logger) *LeaderElection {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if ttl <= 0 {
+		ttl = DefaultLeaderLeaseTTL
+	}
+	return &LeaderElection{
+		storage: storage,
+		name:    name,
+		owner:   uuid.New().String(),
+		ttl:     ttl,
+		check:   leaderCheckInterval,
+		logger:  logger,
+	}
+}
+
+// Owner returns the ID this instance holds the lease under
+func (e *LeaderElection) Owner() string {
+	return e.owner
+}
+
+// IsLeader reports whether this instance holds an unexpired lease
+func (e *LeaderElection) IsLeader() bool {
+	return Now().UnixNano() < e.leaseUntil.Load()
+}
+
+// Run campaigns for the lease until ctx is done, renewing it while this instance leads
+func (e *LeaderElection) Run(ctx context.Context) {
+	ticker := time.NewTicker(e.ttl / 3)
+	defer ticker.Stop()
+
+	for {
+		e.campaign(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunWhileLeader runs fn while this instance leads, until ctx is done
+// fn gets a context that is cancelled when leadership is lost, and is started again each time
+// this instance becomes leader. RunWhileLeader returns once fn has returned.
+func (e *LeaderElection) RunWhileLeader(ctx context.Context, fn func(ctx context.Context)) {
+	ticker := time.NewTicker(e.check)
+	defer ticker.Stop()
+
+	var stop func() // Cancels fn and waits for it; nil while fn is not running
+	defer func() {
+		if stop != nil {
+			stop()
+		}
+	}()
+
+	for {
+		leading := e.IsLeader()
+		switch {
+		case leading && stop == nil:
+			stop = startLeaderWork(ctx, fn)
+		case !leading && stop != nil:
+			stop()
+			stop = nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// startLeaderWork runs fn in the background and returns a function that cancels it and waits for it
+func startLeaderWork(ctx context.Context, fn func(ctx context.Context)) func() {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		fn(ctx)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+// Resign gives up the lease, so another instance can take over without waiting for it to expire
+// Call it on shutdown, after ctx of Run is done; the election can't be won again afterwards.
+func (e *LeaderElection) Resign(ctx context.Context) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.resigned = true
+	wasLeader := e.IsLeader()
+	e.leaseUntil.Store(0)
+	if err := e.storage.ReleaseLeaderLease(ctx, e.name, e.owner); err != nil {
+		return err
+	}
+	if wasLeader {
+		e.logger.Info("Resigned leadership", "lease", e.name, "owner", e.owner)
+	}
+	return nil
+}
+
+// campaign tries once to take or renew the lease
+func (e *LeaderElection) campaign(ctx context.Context) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.resigned {
+		return
+	}
+
+	// The lease counts from before the call, so this instance never outlasts it
+	until := Now().Add(e.ttl)
+	wasLeader := e.IsLeader()
+
+	acquired, err := e.storage.AcquireLeaderLease(ctx, e.name, e.owner, until)
+	if err != nil {
+		// Keep leading until the current lease ends; the next renewal may succeed
+		e.logger.Error("Failed to renew leader lease", "lease", e.name, "error", err)
+		return
+	}
+
+	if !acquired {
+		e.leaseUntil.Store(0)
+		if wasLeader {
+			e.logger.Warn("Lost leadership", "lease", e.name, "owner", e.owner)
+		}
+		return
+	}
+
+	e.leaseUntil.Store(until.UnixNano())
+	if !wasLeader {
+		e.logger.Info("Became leader", "lease", e.name, "owner", e.owner, "ttl", e.ttl)
+	}
+}
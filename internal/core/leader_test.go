@@ -0,0 +1,156 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// leaseStorage is an in-memory LeaderLeaseStorage shared by the elections of a test
+type leaseStorage struct {
+	owner     string
+	expiresAt time.Time
+	err       error
+	mu        sync.Mutex
+}
+
+func (s *leaseStorage) AcquireLeaderLease(ctx context.Context, name, owner string, until time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return false, s.err
+	}
+	if s.owner != "" && s.owner != owner && s.expiresAt.After(time.Now()) {
+		return false, nil
+	}
+	s.owner, s.expiresAt = owner, until
+	return true, nil
+}
+
+func (s *leaseStorage) ReleaseLeaderLease(ctx context.Context, name, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.owner == owner {
+		s.owner = ""
+	}
+	return nil
+}
+
+func TestLeaderElection(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("one leader at a time", func(t *testing.T) {
+		storage := &leaseStorage{}
+		a := NewLeaderElection(storage, "scheduler", time.Minute, nil)
+		b := NewLeaderElection(storage, "scheduler", time.Minute, nil)
+		assert.NotEqual(t, a.Owner(), b.Owner())
+
+		a.campaign(ctx)
+		b.campaign(ctx)
+		assert.True(t, a.IsLeader())
+		assert.False(t, b.IsLeader())
+
+		// Resigning hands over at once and for good
+		require.NoError(t, a.Resign(ctx))
+		assert.False(t, a.IsLeader())
+		b.campaign(ctx)
+		a.campaign(ctx)
+		assert.True(t, b.IsLeader())
+		assert.False(t, a.IsLeader())
+	})
+
+	t.Run("takes over an expired lease", func(t *testing.T) {
+		storage := &leaseStorage{}
+		a := NewLeaderElection(storage, "scheduler", 20*time.Millisecond, nil)
+		b := NewLeaderElection(storage, "scheduler", time.Minute, nil)
+
+		a.campaign(ctx)
+		require.True(t, a.IsLeader())
+
+		// A leader that stops renewing steps down when its lease ends
+		require.Eventually(t, func() bool { return !a.IsLeader() }, time.Second, time.Millisecond)
+		b.campaign(ctx)
+		assert.True(t, b.IsLeader())
+
+		a.campaign(ctx)
+		assert.False(t, a.IsLeader(), "the new leader's lease is kept")
+	})
+
+	t.Run("keeps leading through a failed renewal", func(t *testing.T) {
+		storage := &leaseStorage{}
+		a := NewLeaderElection(storage, "scheduler", time.Minute, nil)
+		a.campaign(ctx)
+
+		storage.err = errors.New("database is locked")
+		a.campaign(ctx)
+		assert.True(t, a.IsLeader(), "until the current lease ends")
+	})
+
+	t.Run("runs work only while leading", func(t *testing.T) {
+		storage := &leaseStorage{}
+		a := NewLeaderElection(storage, "scheduler", 30*time.Millisecond, nil)
+		a.check = time.Millisecond
+
+		var starts atomic.Int32
+		var running atomic.Bool
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			a.RunWhileLeader(runCtx, func(ctx context.Context) {
+				starts.Add(1)
+				running.Store(true)
+				<-ctx.Done()
+				running.Store(false)
+			})
+			close(done)
+		}()
+		assert.Never(t, running.Load, 10*time.Millisecond, time.Millisecond)
+
+		a.campaign(ctx)
+		require.Eventually(t, running.Load, time.Second, time.Millisecond)
+
+		// Another instance takes over once the lease ends; the work stops until this one leads again
+		storage.mu.Lock()
+		storage.owner, storage.expiresAt = "other", time.Now().Add(time.Hour)
+		storage.mu.Unlock()
+		require.Eventually(t, func() bool { return !running.Load() }, time.Second, time.Millisecond)
+		a.campaign(ctx)
+		assert.False(t, a.IsLeader())
+
+		require.NoError(t, storage.ReleaseLeaderLease(ctx, "scheduler", "other"))
+		a.campaign(ctx)
+		require.Eventually(t, running.Load, time.Second, time.Millisecond)
+		assert.Equal(t, int32(2), starts.Load())
+
+		cancel()
+		<-done
+		assert.False(t, running.Load(), "the work has returned")
+	})
+
+	t.Run("run campaigns until done", func(t *testing.T) {
+		storage := &leaseStorage{}
+		a := NewLeaderElection(storage, "scheduler", 30*time.Millisecond, nil)
+
+		runCtx, cancel := context.WithCancel(ctx)
+		done := make(chan struct{})
+		go func() {
+			a.Run(runCtx)
+			close(done)
+		}()
+
+		require.Eventually(t, a.IsLeader, time.Second, time.Millisecond)
+		time.Sleep(60 * time.Millisecond)
+		assert.True(t, a.IsLeader(), "the lease is renewed")
+
+		cancel()
+		<-done
+		require.NoError(t, a.Resign(ctx))
+		assert.False(t, a.IsLeader())
+	})
+}
@@ -0,0 +1,258 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"metron/internal/idgen"
+)
+
+// Profile transition errors
+var (
+	ErrProfileTransitionNotFound = errors.New("profile transition not found")
+	ErrProfileTransitionResolved = errors.New("profile transition has already been confirmed or dismissed")
+)
+
+// profileTransitionWindow is how long after a birthday a transition is still proposed
+// (e.g., when the server was down on the day); birthdays further back are ignored
+const profileTransitionWindow = 7 * 24 * time.Hour
+
+// LimitProfile is a set of daily limits for children within an age range
+type LimitProfile struct {
+	Name         string
+	MinAge       int // Inclusive, in full years
+	MaxAge       int // Inclusive, in full years
+	WeekdayLimit int
+	WeekendLimit int
+}
+
+// Covers returns true if the profile applies at the given age
+func (p *LimitProfile) Covers(age int) bool {
+	return age >= p.MinAge && age <= p.MaxAge
+}
+
+// Profile transition statuses
+const (
+	ProfileTransitionPending   = "pending"   // Waiting for a parent to confirm
+	ProfileTransitionConfirmed = "confirmed" // The profile's limits were applied
+	ProfileTransitionDismissed = "dismissed" // The child keeps their current limits
+)
+
+// ProfileTransition proposes a child's new limit profile after a birthday
+// Limits only change once a parent confirms.
+type ProfileTransition struct {
+	ID           string
+	ChildID      string
+	Profile      string // Name of the new profile
+	Age          int    // Age the child turned
+	WeekdayLimit int
+	WeekendLimit int
+	Status       string // One of the ProfileTransition* statuses
+	CreatedAt    time.Time
+	ResolvedBy   string     // Who confirmed or dismissed it
+	ResolvedAt   *time.Time // nil while pending
+}
+
+// IsPending returns true while the transition waits for a parent
+func (t *ProfileTransition) IsPending() bool {
+	return t.Status == ProfileTransitionPending
+}
+
+// LimitProfileStorage defines the storage interface needed for limit profiles
+type LimitProfileStorage interface {
+	ListChildren(ctx context.Context) ([]*Child, error)
+
+	CreateProfileTransition(ctx context.Context, transition *ProfileTransition) error
+	UpdateProfileTransition(ctx context.Context, transition *ProfileTransition) error
+	GetProfileTransition(ctx context.Context, id string) (*ProfileTransition, error)
+	ListProfileTransitions(ctx context.Context, status string) ([]*ProfileTransition, error) // Oldest first; empty status lists all
+}
+
+// LimitProfileService proposes age-based limit profiles on children's birthdays
+type LimitProfileService struct {
+	storage    LimitProfileStorage
+	profiles   []LimitProfile
+	calculator *TimeCalculationService // For the child's calendar day
+	limits     *LimitScheduleService   // Applies confirmed profiles
+	audit      *AuditService           // Optional
+	logger     *slog.Logger
+}
+
+// NewLimitProfileService creates a new limit profile service
+func NewLimitProfileService(storage LimitProfileStorage, profiles []LimitProfile, calculator *TimeCalculationService, limits *LimitScheduleService, audit *AuditService, logger *slog.Logger) *LimitProfileService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &LimitProfileService{
+		storage:    storage,
+		profiles:   profiles,
+		calculator: calculator,
+		limits:     limits,
+		audit:      audit,
+		logger:     logger,
+	}
+}
+
+// Profiles returns the configured profiles
+func (s *LimitProfileService) Profiles() []LimitProfile {
+	return s.profiles
+}
+
+// ProfileFor returns the profile covering age, or nil if none does
+func (s *LimitProfileService) ProfileFor(age int) *LimitProfile {
+	for i := range s.profiles {
+		if s.profiles[i].Covers(age) {
+			return &s.profiles[i]
+		}
+	}
+	return nil
+}
+
+// CheckBirthdays proposes a transition for each child whose last birthday moved them into a
+// new profile, and returns how many were proposed. It is called on every scheduler tick.
+// A birthday is handled once; children already on the profile's limits are skipped.
+func (s *LimitProfileService) CheckBirthdays(ctx context.Context) (int, error) {
+	children, err := s.storage.ListChildren(ctx)
+	if err != nil {
+		return 0, err
+	}
+	transitions, err := s.storage.ListProfileTransitions(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+
+	handled := make(map[string]bool, len(transitions))
+	for _, transition := range transitions {
+		handled[fmt.Sprintf("%s/%d", transition.ChildID, transition.Age)] = true
+	}
+
+	proposed := 0
+	now := Now()
+	for _, child := range children {
+		if child.Birthdate == nil {
+			continue
+		}
+
+		today := LimitChangeDate(s.calculator.UsageDate(ctx, child.ID, now).Date())
+		age := child.AgeOn(today)
+		if today.Sub(child.Birthday(age)) > profileTransitionWindow || handled[fmt.Sprintf("%s/%d", child.ID, age)] {
+			continue
+		}
+
+		profile := s.ProfileFor(age)
+		if profile == nil {
+			continue
+		}
+		if previous := s.ProfileFor(age - 1); previous != nil && previous.Name == profile.Name {
+			continue
+		}
+		if child.WeekdayLimit == profile.WeekdayLimit && child.WeekendLimit == profile.WeekendLimit {
+			continue
+		}
+
+		transition := &ProfileTransition{
+			ID:           idgen.NewProfileTransition(),
+			ChildID:      child.ID,
+			Profile:      profile.Name,
+			Age:          age,
+			WeekdayLimit: profile.WeekdayLimit,
+			WeekendLimit: profile.WeekendLimit,
+			Status:       ProfileTransitionPending,
+			CreatedAt:    now,
+		}
+		if err := s.storage.CreateProfileTransition(ctx, transition); err != nil {
+			return proposed, err
+		}
+		proposed++
+
+		s.logger.Info("Limit profile transition proposed",
+			"child_id", child.ID,
+			"transition_id", transition.ID,
+			"age", age,
+			"profile", profile.Name)
+		s.record(ctx, AuditProfileProposed, child.ID, AuditActorSchedule,
+			fmt.Sprintf("profile %s at age %d: %s", profile.Name, age,
+				describeLimits(child.WeekdayLimit, child.WeekendLimit, profile.WeekdayLimit, profile.WeekendLimit)))
+	}
+	return proposed, nil
+}
+
+// List returns profile transitions with the given status (all if empty), oldest first
+func (s *LimitProfileService) List(ctx context.Context, status string) ([]*ProfileTransition, error) {
+	return s.storage.ListProfileTransitions(ctx, status)
+}
+
+// Confirm applies the limits of a pending transition from today
+func (s *LimitProfileService) Confirm(ctx context.Context, id, confirmedBy string) (*ProfileTransition, error) {
+	transition, err := s.pending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.limits.Schedule(ctx, transition.ChildID, transition.WeekdayLimit, transition.WeekendLimit, time.Time{}, confirmedBy); err != nil {
+		return nil, err
+	}
+	if err := s.resolve(ctx, transition, ProfileTransitionConfirmed, confirmedBy); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Limit profile transition confirmed",
+		"child_id", transition.ChildID,
+		"transition_id", id,
+		"profile", transition.Profile,
+		"confirmed_by", confirmedBy)
+	s.record(ctx, AuditProfileConfirmed, transition.ChildID, confirmedBy,
+		fmt.Sprintf("profile %s at age %d", transition.Profile, transition.Age))
+	return transition, nil
+}
+
+// Dismiss closes a pending transition; the child keeps their current limits
+func (s *LimitProfileService) Dismiss(ctx context.Context, id, dismissedBy string) (*ProfileTransition, error) {
+	transition, err := s.pending(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.resolve(ctx, transition, ProfileTransitionDismissed, dismissedBy); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Limit profile transition dismissed",
+		"child_id", transition.ChildID,
+		"transition_id", id,
+		"profile", transition.Profile,
+		"dismissed_by", dismissedBy)
+	s.record(ctx, AuditProfileDismissed, transition.ChildID, dismissedBy,
+		fmt.Sprintf("profile %s at age %d", transition.Profile, transition.Age))
+	return transition, nil
+}
+
+// pending returns the transition if it still waits for a parent
+func (s *LimitProfileService) pending(ctx context.Context, id string) (*ProfileTransition, error) {
+	transition, err := s.storage.GetProfileTransition(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if !transition.IsPending() {
+		return nil, ErrProfileTransitionResolved
+	}
+	return transition, nil
+}
+
+// resolve saves the outcome of a transition
+func (s *LimitProfileService) resolve(ctx context.Context, transition *ProfileTransition, status, resolvedBy string) error {
+	now := Now()
+	transition.Status = status
+	transition.ResolvedBy = resolvedBy
+	transition.ResolvedAt = &now
+	return s.storage.UpdateProfileTransition(ctx, transition)
+}
+
+// record adds an audit entry if the audit log is enabled
+func (s *LimitProfileService) record(ctx context.Context, action, childID, actor, details string) {
+	if s.audit != nil {
+		s.audit.Record(ctx, action, childID, actor, details)
+	}
+}
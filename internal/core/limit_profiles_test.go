@@ -0,0 +1,203 @@
+package core
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockLimitProfileStorage struct {
+	*mockLimitScheduleStorage
+	transitions []*ProfileTransition
+}
+
+func (m *mockLimitProfileStorage) ListChildren(ctx context.Context) ([]*Child, error) {
+	var children []*Child
+	for id := range m.children {
+		child, _ := m.GetChild(ctx, id)
+		children = append(children, child)
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].ID < children[j].ID })
+	return children, nil
+}
+
+func (m *mockLimitProfileStorage) CreateProfileTransition(ctx context.Context, transition *ProfileTransition) error {
+	copied := *transition
+	m.transitions = append(m.transitions, &copied)
+	return nil
+}
+
+func (m *mockLimitProfileStorage) UpdateProfileTransition(ctx context.Context, transition *ProfileTransition) error {
+	for i, existing := range m.transitions {
+		if existing.ID == transition.ID {
+			copied := *transition
+			m.transitions[i] = &copied
+			return nil
+		}
+	}
+	return ErrProfileTransitionNotFound
+}
+
+func (m *mockLimitProfileStorage) GetProfileTransition(ctx context.Context, id string) (*ProfileTransition, error) {
+	for _, transition := range m.transitions {
+		if transition.ID == id {
+			copied := *transition
+			return &copied, nil
+		}
+	}
+	return nil, ErrProfileTransitionNotFound
+}
+
+func (m *mockLimitProfileStorage) ListProfileTransitions(ctx context.Context, status string) ([]*ProfileTransition, error) {
+	var transitions []*ProfileTransition
+	for _, transition := range m.transitions {
+		if status == "" || transition.Status == status {
+			copied := *transition
+			transitions = append(transitions, &copied)
+		}
+	}
+	return transitions, nil
+}
+
+func TestChild_AgeOn(t *testing.T) {
+	birthdate := LimitChangeDate(2017, time.March, 10)
+	child := &Child{Birthdate: &birthdate}
+
+	assert.Equal(t, 8, child.AgeOn(LimitChangeDate(2026, time.March, 9)))
+	assert.Equal(t, 9, child.AgeOn(LimitChangeDate(2026, time.March, 10)), "birthday")
+	assert.Equal(t, 9, child.AgeOn(LimitChangeDate(2027, time.March, 9)))
+
+	// Only the calendar date of the day counts
+	riga, err := time.LoadLocation("Europe/Riga")
+	require.NoError(t, err)
+	assert.Equal(t, 9, child.AgeOn(time.Date(2026, 3, 10, 0, 0, 0, 0, riga)))
+
+	leap := LimitChangeDate(2016, time.February, 29)
+	child.Birthdate = &leap
+	assert.Equal(t, 9, child.AgeOn(LimitChangeDate(2026, time.February, 28)))
+	assert.Equal(t, 10, child.AgeOn(LimitChangeDate(2026, time.March, 1)), "February 29 birthdays fall on March 1 in common years")
+
+	assert.Zero(t, (&Child{}).AgeOn(LimitChangeDate(2026, time.March, 10)), "no birthdate")
+}
+
+func TestLimitProfileService(t *testing.T) {
+	now := time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC)
+	original := Now
+	Now = func() time.Time { return now }
+	t.Cleanup(func() { Now = original })
+
+	aliceBirthdate := LimitChangeDate(2017, time.March, 10) // Turns 9 today
+	bobBirthdate := LimitChangeDate(2018, time.January, 5)  // 8, birthday long gone
+	carolBirthdate := LimitChangeDate(2016, time.March, 9)  // Turned 10 yesterday, same profile as at 9
+
+	ctx := context.Background()
+	storage := &mockLimitProfileStorage{mockLimitScheduleStorage: newMockLimitScheduleStorage(
+		&Child{ID: "alice", WeekdayLimit: 45, WeekendLimit: 90, Birthdate: &aliceBirthdate},
+		&Child{ID: "bob", WeekdayLimit: 30, WeekendLimit: 60, Birthdate: &bobBirthdate},
+		&Child{ID: "carol", WeekdayLimit: 45, WeekendLimit: 90, Birthdate: &carolBirthdate},
+		&Child{ID: "dave", WeekdayLimit: 45, WeekendLimit: 90},
+	)}
+	audit := &mockAuditStorage{}
+	calculator := NewTimeCalculationService(nil, time.UTC)
+	limits := NewLimitScheduleService(storage, calculator, NewAuditService(audit, nil), nil)
+	profiles := []LimitProfile{
+		{Name: "6-8", MinAge: 6, MaxAge: 8, WeekdayLimit: 45, WeekendLimit: 90},
+		{Name: "9-12", MinAge: 9, MaxAge: 12, WeekdayLimit: 60, WeekendLimit: 120},
+	}
+	service := NewLimitProfileService(storage, profiles, calculator, limits, NewAuditService(audit, nil), nil)
+
+	assert.Equal(t, "9-12", service.ProfileFor(9).Name)
+	assert.Nil(t, service.ProfileFor(13))
+
+	t.Run("proposes a transition on the birthday", func(t *testing.T) {
+		proposed, err := service.CheckBirthdays(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, proposed)
+
+		pending, err := service.List(ctx, ProfileTransitionPending)
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+		assert.Equal(t, "alice", pending[0].ChildID)
+		assert.Equal(t, "9-12", pending[0].Profile)
+		assert.Equal(t, 9, pending[0].Age)
+		assert.Equal(t, 60, pending[0].WeekdayLimit)
+
+		// The limits only change once a parent confirms
+		alice, err := storage.GetChild(ctx, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, 45, alice.WeekdayLimit)
+
+		require.Len(t, audit.entries, 1)
+		assert.Equal(t, AuditProfileProposed, audit.entries[0].Action)
+		assert.Equal(t, "profile 9-12 at age 9: weekday 45 → 60 min, weekend 90 → 120 min", audit.entries[0].Details)
+
+		// A birthday is handled once
+		proposed, err = service.CheckBirthdays(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, proposed)
+	})
+
+	t.Run("confirm applies the profile", func(t *testing.T) {
+		pending, err := service.List(ctx, ProfileTransitionPending)
+		require.NoError(t, err)
+
+		transition, err := service.Confirm(ctx, pending[0].ID, "telegram:1")
+		require.NoError(t, err)
+		assert.Equal(t, ProfileTransitionConfirmed, transition.Status)
+		assert.Equal(t, "telegram:1", transition.ResolvedBy)
+
+		alice, err := storage.GetChild(ctx, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, 60, alice.WeekdayLimit)
+		assert.Equal(t, 120, alice.WeekendLimit)
+
+		// Applied through the limit schedule, so it is in the limit history
+		history, err := limits.History(ctx, "alice")
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		assert.Equal(t, "telegram:1", history[0].CreatedBy)
+		assert.Equal(t, AuditProfileConfirmed, audit.entries[len(audit.entries)-1].Action)
+
+		_, err = service.Confirm(ctx, pending[0].ID, "telegram:1")
+		assert.ErrorIs(t, err, ErrProfileTransitionResolved)
+		_, err = service.Dismiss(ctx, "prf_missing", "api")
+		assert.ErrorIs(t, err, ErrProfileTransitionNotFound)
+	})
+
+	t.Run("dismiss keeps the limits", func(t *testing.T) {
+		// Bob turns 9 a few days before the check, e.g. while the server was down
+		now = time.Date(2027, 1, 8, 8, 0, 0, 0, time.UTC)
+
+		proposed, err := service.CheckBirthdays(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, proposed)
+
+		pending, err := service.List(ctx, ProfileTransitionPending)
+		require.NoError(t, err)
+		require.Len(t, pending, 1)
+		assert.Equal(t, "bob", pending[0].ChildID)
+
+		transition, err := service.Dismiss(ctx, pending[0].ID, "api")
+		require.NoError(t, err)
+		assert.Equal(t, ProfileTransitionDismissed, transition.Status)
+
+		bob, err := storage.GetChild(ctx, "bob")
+		require.NoError(t, err)
+		assert.Equal(t, 30, bob.WeekdayLimit)
+		assert.Equal(t, AuditProfileDismissed, audit.entries[len(audit.entries)-1].Action)
+	})
+
+	t.Run("ignores birthdays past the window", func(t *testing.T) {
+		// Carol turns 13 with no profile; a new 13+ profile does not reach back to her birthday
+		now = time.Date(2029, 4, 1, 8, 0, 0, 0, time.UTC)
+		service.profiles = append(service.profiles, LimitProfile{Name: "13+", MinAge: 13, MaxAge: 99, WeekdayLimit: 90, WeekendLimit: 180})
+
+		proposed, err := service.CheckBirthdays(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, proposed)
+	})
+}
@@ -0,0 +1,272 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"metron/internal/idgen"
+)
+
+// Limit change errors
+var (
+	ErrLimitChangeNotFound = errors.New("limit change not found")
+	ErrLimitChangeInPast   = errors.New("limit change cannot take effect before today")
+	ErrLimitChangeApplied  = errors.New("limit change has already taken effect")
+)
+
+// limitChangeDateLayout formats the effective date of limit changes
+const limitChangeDateLayout = "2006-01-02"
+
+// LimitChange sets a child's weekday and weekend limits from a given day on
+// Changes are kept as history: pending ones are applied by GetDailyLimit for days from their
+// effective date, and written to the child once that day comes (LimitScheduleService.ApplyDue).
+type LimitChange struct {
+	ID            string
+	ChildID       string
+	WeekdayLimit  int
+	WeekendLimit  int
+	EffectiveFrom time.Time // Calendar day the limits apply from (only the date is used)
+	CreatedBy     string    // Who made the change (e.g., "telegram:12345", "api")
+	CreatedAt     time.Time
+	AppliedAt     *time.Time // When the limits were written to the child; nil while pending
+}
+
+// IsPending returns true if the change has not taken effect yet
+func (c *LimitChange) IsPending() bool {
+	return c.AppliedAt == nil
+}
+
+// EffectiveOn returns true if the change applies on date (a date key; only the date is compared)
+func (c *LimitChange) EffectiveOn(date time.Time) bool {
+	return c.EffectiveFrom.Format(limitChangeDateLayout) <= date.Format(limitChangeDateLayout)
+}
+
+// LimitChangeDate returns the date key stored as a limit change's EffectiveFrom
+func LimitChangeDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// LimitScheduleStorage defines the storage interface needed for limit changes
+type LimitScheduleStorage interface {
+	GetChild(ctx context.Context, id string) (*Child, error)
+	UpdateChild(ctx context.Context, child *Child) error
+
+	CreateLimitChange(ctx context.Context, change *LimitChange) error
+	UpdateLimitChange(ctx context.Context, change *LimitChange) error
+	DeleteLimitChange(ctx context.Context, id string) error
+	ListLimitChanges(ctx context.Context, childID string) ([]*LimitChange, error) // Oldest effective date first; empty childID lists all
+}
+
+// LimitScheduleService changes children's limits, immediately or from a later day
+type LimitScheduleService struct {
+	storage    LimitScheduleStorage
+	calculator *TimeCalculationService // For the child's calendar day
+	audit      *AuditService           // Optional
+	logger     *slog.Logger
+}
+
+// NewLimitScheduleService creates a new limit schedule service
+func NewLimitScheduleService(storage LimitScheduleStorage, calculator *TimeCalculationService, audit *AuditService, logger *slog.Logger) *LimitScheduleService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &LimitScheduleService{
+		storage:    storage,
+		calculator: calculator,
+		audit:      audit,
+		logger:     logger,
+	}
+}
+
+// Schedule changes the child's limits from effectiveFrom (a calendar day in the child's timezone)
+// A zero effectiveFrom means today. A change effective today is applied right away; earlier days are rejected.
+func (s *LimitScheduleService) Schedule(ctx context.Context, childID string, weekdayLimit, weekendLimit int, effectiveFrom time.Time, createdBy string) (*LimitChange, error) {
+	if weekdayLimit <= 0 {
+		return nil, ErrInvalidWeekdayLimit
+	}
+	if weekendLimit <= 0 {
+		return nil, ErrInvalidWeekendLimit
+	}
+
+	child, err := s.storage.GetChild(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := Now()
+	todayDate := s.calculator.UsageDate(ctx, childID, now)
+	if effectiveFrom.IsZero() {
+		effectiveFrom = todayDate
+	}
+	today := todayDate.Format(limitChangeDateLayout)
+	day := effectiveFrom.Format(limitChangeDateLayout)
+	if day < today {
+		return nil, ErrLimitChangeInPast
+	}
+
+	change := &LimitChange{
+		ID:            idgen.NewLimitChange(),
+		ChildID:       childID,
+		WeekdayLimit:  weekdayLimit,
+		WeekendLimit:  weekendLimit,
+		EffectiveFrom: LimitChangeDate(effectiveFrom.Date()),
+		CreatedBy:     createdBy,
+		CreatedAt:     now,
+	}
+
+	if day > today {
+		if err := s.storage.CreateLimitChange(ctx, change); err != nil {
+			return nil, err
+		}
+		s.logger.Info("Limit change scheduled",
+			"child_id", childID,
+			"change_id", change.ID,
+			"weekday_limit", weekdayLimit,
+			"weekend_limit", weekendLimit,
+			"effective_from", day,
+			"created_by", createdBy)
+		s.record(ctx, AuditLimitsScheduled, childID, createdBy,
+			fmt.Sprintf("%s from %s", describeLimits(child.WeekdayLimit, child.WeekendLimit, weekdayLimit, weekendLimit), day))
+		return change, nil
+	}
+
+	previousWeekday, previousWeekend := child.WeekdayLimit, child.WeekendLimit
+	child.WeekdayLimit = weekdayLimit
+	child.WeekendLimit = weekendLimit
+	if err := s.storage.UpdateChild(ctx, child); err != nil {
+		return nil, err
+	}
+
+	change.AppliedAt = &now
+	if err := s.storage.CreateLimitChange(ctx, change); err != nil {
+		return nil, err
+	}
+	s.logger.Info("Limits changed",
+		"child_id", childID,
+		"weekday_limit", weekdayLimit,
+		"weekend_limit", weekendLimit,
+		"created_by", createdBy)
+	s.record(ctx, AuditLimitsChanged, childID, createdBy, describeLimits(previousWeekday, previousWeekend, weekdayLimit, weekendLimit))
+	return change, nil
+}
+
+// RecordChange adds a limit change already saved to the child (e.g., by a child update) to the history
+// Nothing is recorded if the limits did not change.
+func (s *LimitScheduleService) RecordChange(ctx context.Context, child *Child, previousWeekday, previousWeekend int, createdBy string) error {
+	if child.WeekdayLimit == previousWeekday && child.WeekendLimit == previousWeekend {
+		return nil
+	}
+
+	now := Now()
+	change := &LimitChange{
+		ID:            idgen.NewLimitChange(),
+		ChildID:       child.ID,
+		WeekdayLimit:  child.WeekdayLimit,
+		WeekendLimit:  child.WeekendLimit,
+		EffectiveFrom: LimitChangeDate(s.calculator.UsageDate(ctx, child.ID, now).Date()),
+		CreatedBy:     createdBy,
+		CreatedAt:     now,
+		AppliedAt:     &now,
+	}
+	if err := s.storage.CreateLimitChange(ctx, change); err != nil {
+		return err
+	}
+	s.record(ctx, AuditLimitsChanged, child.ID, createdBy, describeLimits(previousWeekday, previousWeekend, child.WeekdayLimit, child.WeekendLimit))
+	return nil
+}
+
+// History returns the child's limit changes, applied and pending, oldest effective date first
+func (s *LimitScheduleService) History(ctx context.Context, childID string) ([]*LimitChange, error) {
+	if _, err := s.storage.GetChild(ctx, childID); err != nil {
+		return nil, err
+	}
+	return s.storage.ListLimitChanges(ctx, childID)
+}
+
+// Cancel removes a pending limit change of the child
+func (s *LimitScheduleService) Cancel(ctx context.Context, childID, changeID, cancelledBy string) error {
+	changes, err := s.History(ctx, childID)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		if change.ID != changeID {
+			continue
+		}
+		if !change.IsPending() {
+			return ErrLimitChangeApplied
+		}
+		if err := s.storage.DeleteLimitChange(ctx, changeID); err != nil {
+			return err
+		}
+		s.logger.Info("Limit change cancelled",
+			"child_id", childID,
+			"change_id", changeID,
+			"cancelled_by", cancelledBy)
+		s.record(ctx, AuditLimitsCancelled, childID, cancelledBy,
+			fmt.Sprintf("weekday %d min, weekend %d min from %s", change.WeekdayLimit, change.WeekendLimit, change.EffectiveFrom.Format(limitChangeDateLayout)))
+		return nil
+	}
+	return ErrLimitChangeNotFound
+}
+
+// ApplyDue writes pending changes whose day has come to their children and returns how many were applied
+// Limits are already used from the effective day through GetDailyLimit; this makes them the
+// child's current limits. It is called on every scheduler tick.
+func (s *LimitScheduleService) ApplyDue(ctx context.Context) (int, error) {
+	changes, err := s.storage.ListLimitChanges(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	now := Now()
+	for _, change := range changes {
+		if !change.IsPending() || !change.EffectiveOn(s.calculator.UsageDate(ctx, change.ChildID, now)) {
+			continue
+		}
+
+		child, err := s.storage.GetChild(ctx, change.ChildID)
+		if err != nil {
+			return applied, err
+		}
+		previousWeekday, previousWeekend := child.WeekdayLimit, child.WeekendLimit
+		child.WeekdayLimit = change.WeekdayLimit
+		child.WeekendLimit = change.WeekendLimit
+		if err := s.storage.UpdateChild(ctx, child); err != nil {
+			return applied, err
+		}
+
+		appliedAt := now
+		change.AppliedAt = &appliedAt
+		if err := s.storage.UpdateLimitChange(ctx, change); err != nil {
+			return applied, err
+		}
+		applied++
+
+		s.logger.Info("Scheduled limit change applied",
+			"child_id", change.ChildID,
+			"change_id", change.ID,
+			"weekday_limit", change.WeekdayLimit,
+			"weekend_limit", change.WeekendLimit)
+		s.record(ctx, AuditLimitsApplied, change.ChildID, AuditActorSchedule,
+			describeLimits(previousWeekday, previousWeekend, change.WeekdayLimit, change.WeekendLimit))
+	}
+	return applied, nil
+}
+
+// record adds an audit entry if the audit log is enabled
+func (s *LimitScheduleService) record(ctx context.Context, action, childID, actor, details string) {
+	if s.audit != nil {
+		s.audit.Record(ctx, action, childID, actor, details)
+	}
+}
+
+// describeLimits describes a change of limits for the audit log
+func describeLimits(fromWeekday, fromWeekend, toWeekday, toWeekend int) string {
+	return fmt.Sprintf("weekday %d → %d min, weekend %d → %d min", fromWeekday, toWeekday, fromWeekend, toWeekend)
+}
@@ -0,0 +1,241 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockLimitScheduleStorage struct {
+	children map[string]*Child
+	changes  []*LimitChange
+}
+
+func newMockLimitScheduleStorage(children ...*Child) *mockLimitScheduleStorage {
+	m := &mockLimitScheduleStorage{children: make(map[string]*Child)}
+	for _, child := range children {
+		m.children[child.ID] = child
+	}
+	return m
+}
+
+func (m *mockLimitScheduleStorage) GetChild(ctx context.Context, id string) (*Child, error) {
+	child, ok := m.children[id]
+	if !ok {
+		return nil, ErrChildNotFound
+	}
+	copied := *child
+	copied.ScheduledLimits = nil
+	for _, change := range m.changes {
+		if change.ChildID == id && change.IsPending() {
+			copied.ScheduledLimits = append(copied.ScheduledLimits, *change)
+		}
+	}
+	return &copied, nil
+}
+
+func (m *mockLimitScheduleStorage) UpdateChild(ctx context.Context, child *Child) error {
+	copied := *child
+	m.children[child.ID] = &copied
+	return nil
+}
+
+func (m *mockLimitScheduleStorage) CreateLimitChange(ctx context.Context, change *LimitChange) error {
+	copied := *change
+	m.changes = append(m.changes, &copied)
+	return nil
+}
+
+func (m *mockLimitScheduleStorage) UpdateLimitChange(ctx context.Context, change *LimitChange) error {
+	for i, existing := range m.changes {
+		if existing.ID == change.ID {
+			copied := *change
+			m.changes[i] = &copied
+			return nil
+		}
+	}
+	return ErrLimitChangeNotFound
+}
+
+func (m *mockLimitScheduleStorage) DeleteLimitChange(ctx context.Context, id string) error {
+	for i, existing := range m.changes {
+		if existing.ID == id {
+			m.changes = append(m.changes[:i], m.changes[i+1:]...)
+			return nil
+		}
+	}
+	return ErrLimitChangeNotFound
+}
+
+func (m *mockLimitScheduleStorage) ListLimitChanges(ctx context.Context, childID string) ([]*LimitChange, error) {
+	var changes []*LimitChange
+	for _, change := range m.changes {
+		if childID == "" || change.ChildID == childID {
+			copied := *change
+			changes = append(changes, &copied)
+		}
+	}
+	return changes, nil
+}
+
+type mockAuditStorage struct {
+	entries []*AuditEntry
+}
+
+func (m *mockAuditStorage) CreateAuditEntry(ctx context.Context, entry *AuditEntry) error {
+	copied := *entry
+	m.entries = append(m.entries, &copied)
+	return nil
+}
+
+func (m *mockAuditStorage) ListAuditEntries(ctx context.Context, childID string, limit int) ([]*AuditEntry, error) {
+	return m.entries, nil
+}
+
+func TestChild_GetDailyLimit_ScheduledLimits(t *testing.T) {
+	child := &Child{
+		WeekdayLimit: 60,
+		WeekendLimit: 120,
+		ScheduledLimits: []LimitChange{
+			{WeekdayLimit: 45, WeekendLimit: 90, EffectiveFrom: LimitChangeDate(2026, time.March, 9)},
+			{WeekdayLimit: 30, WeekendLimit: 60, EffectiveFrom: LimitChangeDate(2026, time.March, 16)},
+		},
+	}
+
+	// Dates are date keys in the server timezone; only the calendar date is compared
+	riga, err := time.LoadLocation("Europe/Riga")
+	require.NoError(t, err)
+
+	assert.Equal(t, 60, child.GetDailyLimit(time.Date(2026, 3, 6, 0, 0, 0, 0, riga)), "Friday before the first change")
+	assert.Equal(t, 120, child.GetDailyLimit(time.Date(2026, 3, 8, 0, 0, 0, 0, riga)), "Sunday before the first change")
+	assert.Equal(t, 45, child.GetDailyLimit(time.Date(2026, 3, 9, 0, 0, 0, 0, riga)), "effective day")
+	assert.Equal(t, 90, child.GetDailyLimit(time.Date(2026, 3, 14, 0, 0, 0, 0, riga)))
+	assert.Equal(t, 30, child.GetDailyLimit(time.Date(2026, 3, 20, 0, 0, 0, 0, riga)), "latest change wins")
+}
+
+func TestLimitScheduleService(t *testing.T) {
+	// Wednesday
+	now := time.Date(2026, 3, 4, 10, 0, 0, 0, time.UTC)
+	original := Now
+	Now = func() time.Time { return now }
+	t.Cleanup(func() { Now = original })
+
+	ctx := context.Background()
+	storage := newMockLimitScheduleStorage(&Child{ID: "alice", WeekdayLimit: 60, WeekendLimit: 120})
+	audit := &mockAuditStorage{}
+	service := NewLimitScheduleService(storage, NewTimeCalculationService(nil, time.UTC), NewAuditService(audit, nil), nil)
+
+	t.Run("rejects invalid changes", func(t *testing.T) {
+		_, err := service.Schedule(ctx, "alice", 0, 90, LimitChangeDate(2026, time.March, 9), "api")
+		assert.ErrorIs(t, err, ErrInvalidWeekdayLimit)
+		_, err = service.Schedule(ctx, "alice", 45, 90, LimitChangeDate(2026, time.March, 3), "api")
+		assert.ErrorIs(t, err, ErrLimitChangeInPast)
+		_, err = service.Schedule(ctx, "nobody", 45, 90, LimitChangeDate(2026, time.March, 9), "api")
+		assert.ErrorIs(t, err, ErrChildNotFound)
+		assert.Empty(t, storage.changes)
+		assert.Empty(t, audit.entries)
+	})
+
+	t.Run("schedules from next Monday", func(t *testing.T) {
+		change, err := service.Schedule(ctx, "alice", 45, 90, LimitChangeDate(2026, time.March, 9), "telegram:1")
+		require.NoError(t, err)
+		assert.True(t, change.IsPending())
+
+		// The current limits are kept until then, but the day's limit already uses the change
+		child, err := storage.GetChild(ctx, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, 60, child.WeekdayLimit)
+		assert.Equal(t, 60, child.GetDailyLimit(time.Date(2026, 3, 6, 0, 0, 0, 0, time.UTC)))
+		assert.Equal(t, 45, child.GetDailyLimit(time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC)))
+
+		require.Len(t, audit.entries, 1)
+		assert.Equal(t, AuditLimitsScheduled, audit.entries[0].Action)
+		assert.Equal(t, "telegram:1", audit.entries[0].Actor)
+		assert.Equal(t, "weekday 60 → 45 min, weekend 120 → 90 min from 2026-03-09", audit.entries[0].Details)
+
+		// Nothing is due yet
+		applied, err := service.ApplyDue(ctx)
+		require.NoError(t, err)
+		assert.Zero(t, applied)
+	})
+
+	t.Run("applies the change on its day", func(t *testing.T) {
+		now = time.Date(2026, 3, 9, 0, 1, 0, 0, time.UTC)
+
+		applied, err := service.ApplyDue(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, 1, applied)
+
+		child, err := storage.GetChild(ctx, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, 45, child.WeekdayLimit)
+		assert.Equal(t, 90, child.WeekendLimit)
+		assert.Empty(t, child.ScheduledLimits)
+
+		history, err := service.History(ctx, "alice")
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		require.NotNil(t, history[0].AppliedAt)
+		assert.Equal(t, now, *history[0].AppliedAt)
+
+		last := audit.entries[len(audit.entries)-1]
+		assert.Equal(t, AuditLimitsApplied, last.Action)
+		assert.Equal(t, AuditActorSchedule, last.Actor)
+
+		// Applied changes stay in the history and cannot be cancelled
+		assert.ErrorIs(t, service.Cancel(ctx, "alice", history[0].ID, "api"), ErrLimitChangeApplied)
+	})
+
+	t.Run("change effective today applies immediately", func(t *testing.T) {
+		change, err := service.Schedule(ctx, "alice", 50, 100, LimitChangeDate(2026, time.March, 9), "api")
+		require.NoError(t, err)
+		assert.False(t, change.IsPending())
+
+		child, err := storage.GetChild(ctx, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, 50, child.WeekdayLimit)
+		assert.Equal(t, AuditLimitsChanged, audit.entries[len(audit.entries)-1].Action)
+
+		// Without a date the change applies today
+		change, err = service.Schedule(ctx, "alice", 55, 100, time.Time{}, "api")
+		require.NoError(t, err)
+		assert.False(t, change.IsPending())
+		assert.Equal(t, LimitChangeDate(2026, time.March, 9), change.EffectiveFrom)
+	})
+
+	t.Run("cancels pending changes", func(t *testing.T) {
+		change, err := service.Schedule(ctx, "alice", 30, 60, LimitChangeDate(2026, time.March, 16), "api")
+		require.NoError(t, err)
+
+		assert.ErrorIs(t, service.Cancel(ctx, "alice", "lim_missing", "api"), ErrLimitChangeNotFound)
+		require.NoError(t, service.Cancel(ctx, "alice", change.ID, "api"))
+
+		child, err := storage.GetChild(ctx, "alice")
+		require.NoError(t, err)
+		assert.Empty(t, child.ScheduledLimits)
+		assert.Equal(t, AuditLimitsCancelled, audit.entries[len(audit.entries)-1].Action)
+	})
+
+	t.Run("records changes made elsewhere", func(t *testing.T) {
+		child, err := storage.GetChild(ctx, "alice")
+		require.NoError(t, err)
+		entries := len(audit.entries)
+
+		require.NoError(t, service.RecordChange(ctx, child, child.WeekdayLimit, child.WeekendLimit, "api"))
+		assert.Len(t, audit.entries, entries, "unchanged limits are not recorded")
+
+		child.WeekdayLimit = 75
+		require.NoError(t, service.RecordChange(ctx, child, 55, 100, "api"))
+		require.Len(t, audit.entries, entries+1)
+		assert.Equal(t, "weekday 55 → 75 min, weekend 100 → 100 min", audit.entries[entries].Details)
+
+		history, err := service.History(ctx, "alice")
+		require.NoError(t, err)
+		last := history[len(history)-1]
+		assert.Equal(t, 75, last.WeekdayLimit)
+		assert.False(t, last.IsPending())
+	})
+}
@@ -0,0 +1,204 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"metron/internal/idgen"
+)
+
+// Lockdown errors
+var (
+	ErrLockdownActive    = errors.New("lockdown is active")
+	ErrLockdownNotActive = errors.New("no lockdown is active")
+)
+
+// Lockdown is an emergency stop: all sessions are stopped, agent-controlled devices
+// are locked and no new sessions can start until it is lifted
+type Lockdown struct {
+	ID              string
+	Reason          string // Why the lockdown was triggered
+	TriggeredBy     string // Who triggered it (e.g., "telegram:12345", "api")
+	StartedAt       time.Time
+	StoppedSessions int        // Sessions stopped when the lockdown started
+	LiftedAt        *time.Time // nil while the lockdown is active
+	LiftedBy        string
+}
+
+// IsActive returns true if the lockdown has not been lifted
+func (l *Lockdown) IsActive() bool {
+	return l.LiftedAt == nil
+}
+
+// LockdownStorage defines the interface for lockdown persistence
+type LockdownStorage interface {
+	GetActiveLockdown(ctx context.Context) (*Lockdown, error) // Returns nil if no lockdown is active
+	CreateLockdown(ctx context.Context, lockdown *Lockdown) error
+	UpdateLockdown(ctx context.Context, lockdown *Lockdown) error
+	ListLockdowns(ctx context.Context, limit int) ([]*Lockdown, error)
+
+	// Device bypasses are cleared so agents lock their devices
+	ListActiveBypassDevices(ctx context.Context) ([]*DeviceBypass, error)
+	ClearDeviceBypass(ctx context.Context, deviceID string) error
+}
+
+// LockdownSessionStopper stops sessions when a lockdown starts
+type LockdownSessionStopper interface {
+	ListActiveSessions(ctx context.Context) ([]*Session, error)
+	StopSession(ctx context.Context, sessionID string) error
+}
+
+// LockdownService manages lockdown mode
+type LockdownService struct {
+	storage  LockdownStorage
+	sessions LockdownSessionStopper
+	logger   *slog.Logger
+	mu       sync.Mutex // Serializes activate/lift
+}
+
+// NewLockdownService creates a new lockdown service
+func NewLockdownService(storage LockdownStorage, sessions LockdownSessionStopper, logger *slog.Logger) *LockdownService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &LockdownService{
+		storage:  storage,
+		sessions: sessions,
+		logger:   logger,
+	}
+}
+
+// Active returns the current lockdown, or nil if none is active
+func (s *LockdownService) Active(ctx context.Context) (*Lockdown, error) {
+	return s.storage.GetActiveLockdown(ctx)
+}
+
+// Check returns ErrLockdownActive while a lockdown is active
+// Storage errors are returned too so callers fail closed
+func (s *LockdownService) Check(ctx context.Context) error {
+	lockdown, err := s.storage.GetActiveLockdown(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check lockdown: %w", err)
+	}
+	if lockdown != nil {
+		return ErrLockdownActive
+	}
+	return nil
+}
+
+// Activate starts a lockdown: it is recorded first so new sessions are blocked immediately,
+// then all active sessions are stopped and device bypasses cleared
+// Returns ErrLockdownActive if a lockdown is already active
+func (s *LockdownService) Activate(ctx context.Context, triggeredBy, reason string) (*Lockdown, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := s.storage.GetActiveLockdown(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil {
+		return current, ErrLockdownActive
+	}
+
+	lockdown := &Lockdown{
+		ID:          idgen.NewLockdown(),
+		Reason:      reason,
+		TriggeredBy: triggeredBy,
+		StartedAt:   Now(),
+	}
+	if err := s.storage.CreateLockdown(ctx, lockdown); err != nil {
+		return nil, err
+	}
+
+	s.logger.Warn("Lockdown activated",
+		"lockdown_id", lockdown.ID,
+		"triggered_by", triggeredBy,
+		"reason", reason)
+
+	// Stop every active session; keep going on failures so as much as possible gets locked
+	sessions, err := s.sessions.ListActiveSessions(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list sessions for lockdown",
+			"lockdown_id", lockdown.ID,
+			"error", err)
+	}
+	stopCtx := WithEndReason(ctx, SessionEndLockdown)
+	for _, session := range sessions {
+		if err := s.sessions.StopSession(stopCtx, session.ID); err != nil {
+			s.logger.Error("Failed to stop session for lockdown",
+				"lockdown_id", lockdown.ID,
+				"session_id", session.ID,
+				"error", err)
+			continue
+		}
+		lockdown.StoppedSessions++
+	}
+
+	// Clear bypasses so agent-controlled devices lock on their next poll
+	bypasses, err := s.storage.ListActiveBypassDevices(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list device bypasses for lockdown",
+			"lockdown_id", lockdown.ID,
+			"error", err)
+	}
+	for _, bypass := range bypasses {
+		if err := s.storage.ClearDeviceBypass(ctx, bypass.DeviceID); err != nil {
+			s.logger.Error("Failed to clear device bypass for lockdown",
+				"lockdown_id", lockdown.ID,
+				"device_id", bypass.DeviceID,
+				"error", err)
+		}
+	}
+
+	if err := s.storage.UpdateLockdown(ctx, lockdown); err != nil {
+		s.logger.Error("Failed to record stopped sessions for lockdown",
+			"lockdown_id", lockdown.ID,
+			"error", err)
+	}
+
+	s.logger.Info("Lockdown enforced",
+		"lockdown_id", lockdown.ID,
+		"stopped_sessions", lockdown.StoppedSessions,
+		"cleared_bypasses", len(bypasses))
+
+	return lockdown, nil
+}
+
+// Lift ends the active lockdown
+// Returns ErrLockdownNotActive if there is nothing to lift
+func (s *LockdownService) Lift(ctx context.Context, liftedBy string) (*Lockdown, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lockdown, err := s.storage.GetActiveLockdown(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if lockdown == nil {
+		return nil, ErrLockdownNotActive
+	}
+
+	now := Now()
+	lockdown.LiftedAt = &now
+	lockdown.LiftedBy = liftedBy
+	if err := s.storage.UpdateLockdown(ctx, lockdown); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Lockdown lifted",
+		"lockdown_id", lockdown.ID,
+		"lifted_by", liftedBy,
+		"duration", now.Sub(lockdown.StartedAt).Round(time.Second))
+
+	return lockdown, nil
+}
+
+// History returns the most recent lockdowns, newest first
+func (s *LockdownService) History(ctx context.Context, limit int) ([]*Lockdown, error) {
+	return s.storage.ListLockdowns(ctx, limit)
+}
@@ -0,0 +1,124 @@
+package core
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockLockdownStorage struct {
+	lockdowns []*Lockdown
+	bypasses  map[string]*DeviceBypass
+}
+
+func newMockLockdownStorage() *mockLockdownStorage {
+	return &mockLockdownStorage{
+		bypasses: make(map[string]*DeviceBypass),
+	}
+}
+
+func (m *mockLockdownStorage) GetActiveLockdown(ctx context.Context) (*Lockdown, error) {
+	for _, lockdown := range m.lockdowns {
+		if lockdown.IsActive() {
+			copied := *lockdown
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockLockdownStorage) CreateLockdown(ctx context.Context, lockdown *Lockdown) error {
+	copied := *lockdown
+	m.lockdowns = append(m.lockdowns, &copied)
+	return nil
+}
+
+func (m *mockLockdownStorage) UpdateLockdown(ctx context.Context, lockdown *Lockdown) error {
+	for i, existing := range m.lockdowns {
+		if existing.ID == lockdown.ID {
+			copied := *lockdown
+			m.lockdowns[i] = &copied
+		}
+	}
+	return nil
+}
+
+func (m *mockLockdownStorage) ListLockdowns(ctx context.Context, limit int) ([]*Lockdown, error) {
+	return m.lockdowns, nil
+}
+
+func (m *mockLockdownStorage) ListActiveBypassDevices(ctx context.Context) ([]*DeviceBypass, error) {
+	bypasses := make([]*DeviceBypass, 0, len(m.bypasses))
+	for _, bypass := range m.bypasses {
+		bypasses = append(bypasses, bypass)
+	}
+	return bypasses, nil
+}
+
+func (m *mockLockdownStorage) ClearDeviceBypass(ctx context.Context, deviceID string) error {
+	delete(m.bypasses, deviceID)
+	return nil
+}
+
+func TestLockdownService_ActivateAndLift(t *testing.T) {
+	ctx := context.Background()
+	storage := newMockStorage()
+	deviceRegistry := newMockDeviceRegistry()
+	driverRegistry := newMockDriverRegistry()
+	manager := NewSessionManager(storage, deviceRegistry, driverRegistry, nil, nil, nil, nil)
+
+	storage.CreateChild(ctx, &Child{ID: "child1", Name: "Alice", WeekdayLimit: 120, WeekendLimit: 120})
+	driver := &mockDriver{name: "aqara"}
+	driverRegistry.addDriver(driver)
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", name: "TV", dtype: "tv", driver: "aqara"})
+
+	session, err := manager.StartSession(ctx, "tv1", []string{"child1"}, 30)
+	require.NoError(t, err)
+
+	lockdownStorage := newMockLockdownStorage()
+	lockdownStorage.bypasses["pc1"] = &DeviceBypass{DeviceID: "pc1", Enabled: true}
+	lockdown := NewLockdownService(lockdownStorage, manager, nil)
+	manager.SetLockdown(lockdown)
+
+	// Activate stops the running session and clears bypasses
+	active, err := lockdown.Activate(ctx, "telegram:42", "bedtime")
+	require.NoError(t, err)
+	assert.Equal(t, "telegram:42", active.TriggeredBy)
+	assert.Equal(t, "bedtime", active.Reason)
+	assert.Equal(t, 1, active.StoppedSessions)
+	assert.True(t, active.IsActive())
+	assert.True(t, driver.stopCalled)
+	assert.Empty(t, lockdownStorage.bypasses)
+
+	stopped, err := storage.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, SessionStatusCompleted, stopped.Status)
+	assert.Equal(t, SessionEndLockdown, stopped.EndReason)
+
+	// A second activation is rejected
+	_, err = lockdown.Activate(ctx, "api", "again")
+	assert.ErrorIs(t, err, ErrLockdownActive)
+
+	// New sessions are blocked
+	_, err = manager.StartSession(ctx, "tv1", []string{"child1"}, 15)
+	assert.ErrorIs(t, err, ErrLockdownActive)
+
+	// Lift restores normal operation
+	lifted, err := lockdown.Lift(ctx, "api")
+	require.NoError(t, err)
+	assert.False(t, lifted.IsActive())
+	assert.Equal(t, "api", lifted.LiftedBy)
+
+	_, err = lockdown.Lift(ctx, "api")
+	assert.ErrorIs(t, err, ErrLockdownNotActive)
+
+	_, err = manager.StartSession(ctx, "tv1", []string{"child1"}, 15)
+	assert.NoError(t, err)
+
+	history, err := lockdown.History(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, 1, history[0].StoppedSessions)
+}
@@ -36,6 +36,7 @@ type Storage interface {
 type Device interface {
 	GetType() string
 	GetDriver() string
+	GetTimezone() string // IANA timezone override, or "" if not set
 }
 
 // DeviceRegistry interface defines device management operations
@@ -63,6 +64,21 @@ type SessionManager struct {
 	driverRegistry DriverRegistry
 	calculator     *TimeCalculationService
 	downtime       *DowntimeService
+	lockdown       *LockdownService         // Optional: blocks new sessions during a lockdown
+	trackingPause  *TrackingPauseService    // Optional: vacation mode, paused children are not limited or charged
+	audit          *AuditService            // Optional: records parent overrides
+	activity       *ChildActivityService    // Optional: records children's activity feeds
+	overrides      *DowntimeOverrideService // Optional: records downtime overrides so downtime re-arms when they end
+	driverQueue    *DriverQueue             // Optional: driver calls are made in the background instead of inline
+	timeouts       *DriverTimeouts          // Optional: per-driver call timeouts (DefaultDriverTimeout without)
+	initiatorCaps  map[string]int           // Optional: session length limits by initiator type (see SetInitiatorLimits)
+	policies       []SessionPolicy          // Optional: session length, gap and per-day rules (see SetPolicies)
+	familyBudget   *FamilyBudgetService     // Optional: daily budget shared by all children
+	activities     []ExemptActivity         // Optional: activities whose sessions are not charged (see SetExemptActivities)
+	conflicts      string                   // Optional: what to do on a device already in use (Conflict*, see SetConflictPolicy)
+	queue          *SessionQueue            // Optional: starts waiting for their device (ConflictQueue)
+	locks          *SessionLocks            // Shared with the scheduler (see SessionLocks)
+	states         *SessionStateMachine     // Shared with the scheduler (see SessionStateMachine)
 	timezone       *time.Location
 	logger         *slog.Logger
 }
@@ -82,160 +98,329 @@ func NewSessionManager(storage Storage, deviceRegistry DeviceRegistry, driverReg
 		calculator = NewTimeCalculationService(storage.(TimeCalculationStorage), timezone)
 	}
 
+	// Claims in storage extend the session locks to other processes using the same database
+	locks := NewSessionLocks()
+	if claims, ok := storage.(SessionClaimStorage); ok {
+		locks.SetClaims(claims)
+	}
+
 	return &SessionManager{
 		storage:        storage,
 		deviceRegistry: deviceRegistry,
 		driverRegistry: driverRegistry,
 		calculator:     calculator,
 		downtime:       downtime,
+		locks:          locks,
+		states:         NewSessionStateMachine(logger),
 		timezone:       timezone,
 		logger:         logger,
 	}
 }
 
-// StartSession starts a new session for one or more children
-func (m *SessionManager) StartSession(ctx context.Context, deviceID string, childIDs []string, durationMinutes int) (*Session, error) {
-	m.logger.Info("Starting new session",
-		"device_id", deviceID,
-		"child_ids", childIDs,
-		"duration_minutes", durationMinutes)
+// SessionLocks returns the per-session locks the manager takes while changing a session
+// The scheduler must use the same locks (scheduler.SetSessionLocks)
+func (m *SessionManager) SessionLocks() *SessionLocks {
+	return m.locks
+}
 
-	// Validate inputs
-	if deviceID == "" {
-		m.logger.Error("Session start failed: empty device ID")
-		return nil, fmt.Errorf("device ID cannot be empty")
+// SessionStates returns the state machine the manager changes session statuses with
+// The scheduler must use the same one (scheduler.SetSessionStates)
+func (m *SessionManager) SessionStates() *SessionStateMachine {
+	return m.states
+}
+
+// acquireSession takes the session's lock (and storage claim) for a transition
+func (m *SessionManager) acquireSession(ctx context.Context, sessionID string) (func(), error) {
+	release, err := m.locks.Acquire(ctx, sessionID)
+	if err != nil {
+		m.logger.Warn("Failed to acquire session",
+			"session_id", sessionID,
+			"error", err)
+		return nil, err
 	}
-	if len(childIDs) == 0 {
-		m.logger.Error("Session start failed: no children specified")
-		return nil, ErrNoChildren
+	return release, nil
+}
+
+// SetInitiatorLimits caps sessions by who starts or extends them, in minutes by initiator type
+// (e.g., {"child": 30}): starts are capped to the limit, and extensions to what keeps the
+// session within it. Types without a limit are only capped by the children's time.
+func (m *SessionManager) SetInitiatorLimits(limits map[string]int) {
+	m.initiatorCaps = limits
+}
+
+// SetLockdown sets the lockdown service that blocks new sessions and extensions
+func (m *SessionManager) SetLockdown(lockdown *LockdownService) {
+	m.lockdown = lockdown
+}
+
+// SetAudit sets the audit log that parent overrides are recorded in
+func (m *SessionManager) SetAudit(audit *AuditService) {
+	m.audit = audit
+}
+
+// SetActivity sets the service recording children's activity feeds
+// Breaks and ended sessions are recorded by its hook on SessionStates, so the scheduler's are too.
+func (m *SessionManager) SetActivity(activity *ChildActivityService) {
+	m.activity = activity
+	m.states.AddHook(activity.SessionHook())
+}
+
+// SetDowntimeOverrides sets the service that records downtime overrides
+// Day overrides let children start and extend sessions during downtime until the end of the day.
+func (m *SessionManager) SetDowntimeOverrides(overrides *DowntimeOverrideService) {
+	m.overrides = overrides
+}
+
+// SetDriverTimeouts sets the timeouts that bound the manager's driver calls
+func (m *SessionManager) SetDriverTimeouts(timeouts *DriverTimeouts) {
+	m.timeouts = timeouts
+}
+
+// SetDriverQueue makes the manager queue driver calls instead of making them inline
+// Starts then return once the session is stored and its start job is queued (the device is never
+// unlocked without a stored session); a start that fails for good ends the session without charging it.
+func (m *SessionManager) SetDriverQueue(queue *DriverQueue) {
+	m.driverQueue = queue
+	queue.OnFailed(m.driverJobFailed)
+}
+
+// queueDriverCall queues a driver call for a session, or makes it inline without a queue
+// The call is also made inline if the job can't be stored
+func (m *SessionManager) queueDriverCall(ctx context.Context, kind string, session *Session, minutes int, call func() error) error {
+	if m.driverQueue != nil {
+		_, err := m.driverQueue.Enqueue(ctx, kind, session.ID, minutes)
+		if err == nil {
+			return nil
+		}
+		m.logger.Warn("Failed to queue driver call, calling the driver inline",
+			"session_id", session.ID,
+			"kind", kind,
+			"error", err)
 	}
-	if durationMinutes <= 0 {
-		m.logger.Error("Session start failed: invalid duration",
-			"duration_minutes", durationMinutes)
-		return nil, ErrInvalidDuration
+	return call()
+}
+
+// driverJobFailed is the compensation of driver calls that failed for good (see DriverQueue)
+// A session whose device was never unlocked is ended as a driver failure, without charging anyone.
+func (m *SessionManager) driverJobFailed(ctx context.Context, job *DriverJob, jobErr error) {
+	if job.Kind != DriverJobStart {
+		m.logger.Error("Driver call failed for good, the device may not match the session",
+			"session_id", job.SessionID,
+			"kind", job.Kind,
+			"error", jobErr)
+		return
 	}
 
-	// Look up device from device registry
-	device, err := m.deviceRegistry.Get(deviceID)
+	release, err := m.acquireSession(ctx, job.SessionID)
 	if err != nil {
-		m.logger.Error("Failed to get device from registry",
-			"device_id", deviceID,
+		m.logger.Error("Failed to end session after its device could not be unlocked",
+			"session_id", job.SessionID,
 			"error", err)
-		return nil, fmt.Errorf("failed to get device %s: %w", deviceID, err)
+		return
 	}
+	defer release()
 
-	m.logger.Debug("Device found",
-		"device_id", deviceID,
-		"device_type", device.GetType(),
-		"driver", device.GetDriver())
+	session, err := m.storage.GetSession(ctx, job.SessionID)
+	if err != nil || !session.IsRunning() {
+		return
+	}
 
-	// Validate children exist and check time availability
-	now := time.Now()
-	today := now.In(m.timezone)
-	minRemainingTime := durationMinutes // Start with requested duration
+	none := 0
+	session.ActualDuration = &none
+	session.EndReason = SessionEndDriverFailure
+	if _, err := m.states.Apply(ctx, session, SessionEventExpire, m.storage.UpdateSession); err != nil {
+		m.logger.Error("Failed to end session after its device could not be unlocked",
+			"session_id", job.SessionID,
+			"error", err)
+		return
+	}
 
-	// Check for parent override context
-	isParentOverride := ctx.Value("parent_override") != nil
+	m.logger.Warn("Session ended, its device could not be unlocked",
+		"session_id", job.SessionID,
+		"device_id", session.DeviceID,
+		"error", jobErr)
+}
 
-	for _, childID := range childIDs {
-		child, err := m.storage.GetChild(ctx, childID)
-		if err != nil {
-			m.logger.Error("Failed to get child",
+// hasDayOverride returns true if a parent lifted the child's downtime for the rest of the day
+// Errors are logged and treated as no override so downtime stays enforced
+func (m *SessionManager) hasDayOverride(ctx context.Context, childID string) bool {
+	if m.overrides == nil {
+		return false
+	}
+	override, err := m.overrides.OverrideFor(ctx, childID, "")
+	if err != nil {
+		m.logger.Error("Failed to check downtime override",
+			"child_id", childID,
+			"error", err)
+		return false
+	}
+	return override != nil
+}
+
+// grantDowntimeOverride records a session's downtime override for each child
+// The session keeps running if this fails, but the scheduler then enforces downtime again
+func (m *SessionManager) grantDowntimeOverride(ctx context.Context, session *Session, override Override) {
+	if m.overrides == nil || !override.Downtime {
+		return
+	}
+	for _, childID := range session.ChildIDs {
+		if _, err := m.overrides.GrantForSession(ctx, session, childID, override.By, override.Reason); err != nil {
+			m.logger.Error("Failed to record downtime override",
+				"session_id", session.ID,
 				"child_id", childID,
 				"error", err)
-			return nil, fmt.Errorf("failed to get child %s: %w", childID, err)
 		}
+	}
+}
 
-		// Check downtime (unless parent override)
-		if !isParentOverride && m.downtime != nil && m.downtime.IsChildInDowntime(child, now) {
-			m.logger.Warn("Session start blocked by downtime",
-				"child_id", childID,
-				"child_name", child.Name,
-				"downtime_enabled", child.DowntimeEnabled)
-			return nil, ErrDowntimeActive
-		}
+// recordOverride adds an audit entry per child for a session started or extended with an override
+func (m *SessionManager) recordOverride(ctx context.Context, session *Session, override Override, details string) {
+	m.logger.Info("Parent override applied",
+		"session_id", session.ID,
+		"child_ids", session.ChildIDs,
+		"scopes", override.Scopes(),
+		"by", override.By,
+		"reason", override.Reason)
 
-		// Use calculator to check time availability
-		remaining, err := m.calculator.GetRemainingTime(ctx, childID, today)
-		if err != nil {
-			m.logger.Error("Failed to get remaining time",
-				"child_id", childID,
-				"error", err)
-			return nil, fmt.Errorf("failed to get remaining time for child %s: %w", childID, err)
-		}
+	if m.audit == nil {
+		return
+	}
+	actor := override.By
+	if actor == "" {
+		actor = "api"
+	}
+	for _, childID := range session.ChildIDs {
+		m.audit.Record(ctx, AuditSessionOverride, childID, actor, fmt.Sprintf("%s (%s, session %s)", details, override, session.ID))
+	}
+}
 
-		m.logger.Debug("Checking child time availability",
+// checkLockdown returns ErrLockdownActive while a lockdown is active
+func (m *SessionManager) checkLockdown(ctx context.Context) error {
+	if m.lockdown == nil {
+		return nil
+	}
+	if err := m.lockdown.Check(ctx); err != nil {
+		m.logger.Warn("Session change rejected by lockdown",
+			"error", err)
+		return err
+	}
+	return nil
+}
+
+// SetTrackingPause sets the vacation mode service; children with tracking paused
+// are not limited by remaining time or downtime and are not charged
+func (m *SessionManager) SetTrackingPause(trackingPause *TrackingPauseService) {
+	m.trackingPause = trackingPause
+}
+
+// isTrackingPaused returns true while tracking is paused for the child
+// Errors are logged and treated as tracked so limits stay enforced
+func (m *SessionManager) isTrackingPaused(ctx context.Context, childID string) bool {
+	if m.trackingPause == nil {
+		return false
+	}
+	pause, err := m.trackingPause.PauseFor(ctx, childID)
+	if err != nil {
+		m.logger.Error("Failed to check tracking pause",
 			"child_id", childID,
-			"child_name", child.Name,
-			"daily_limit", remaining.Available.BaseLimit,
-			"reward_granted", remaining.Available.BonusGranted,
-			"total_available", remaining.Available.TotalAvailable,
-			"used", remaining.Consumed.TotalConsumed,
-			"remaining", remaining.RemainingTotal,
-			"requested", durationMinutes)
+			"error", err)
+		return false
+	}
+	return pause != nil
+}
 
-		// If child has no time left, reject the session
-		if remaining.RemainingTotal == 0 {
-			m.logger.Warn("No time remaining for child",
-				"child_id", childID,
-				"child_name", child.Name)
-			return nil, fmt.Errorf("%w: child %s has no time remaining", ErrInsufficientTime, child.Name)
-		}
+// deviceTimezone returns the device's timezone override, or "" if it has none or is unknown
+func (m *SessionManager) deviceTimezone(deviceID string) string {
+	device, err := m.deviceRegistry.Get(deviceID)
+	if err != nil {
+		return ""
+	}
+	return device.GetTimezone()
+}
 
-		// Track minimum remaining time to cap the session
-		if remaining.RemainingTotal < minRemainingTime {
-			minRemainingTime = remaining.RemainingTotal
-			m.logger.Debug("Capping session duration to child's remaining time",
-				"child_id", childID,
-				"child_name", child.Name,
-				"remaining", remaining.RemainingTotal,
-				"original_duration", durationMinutes)
-		}
+// StartSession starts a new session for one or more children
+func (m *SessionManager) StartSession(ctx context.Context, deviceID string, childIDs []string, durationMinutes int) (*Session, error) {
+	m.logger.Info("Starting new session",
+		"device_id", deviceID,
+		"child_ids", childIDs,
+		"duration_minutes", durationMinutes)
+
+	// The device must not be taken by another start between the busy check and the end of this one
+	// (a session whose device fails to unlock is deleted again, so it must not turn others away)
+	unlockDevice, err := m.lockDevice(ctx, deviceID)
+	if err != nil {
+		return nil, err
 	}
+	defer unlockDevice()
 
-	// If parent override, disable downtime for all children
-	if isParentOverride {
-		for _, childID := range childIDs {
-			child, err := m.storage.GetChild(ctx, childID)
-			if err != nil {
-				m.logger.Error("Failed to get child for downtime override",
-					"child_id", childID,
-					"error", err)
-				continue
-			}
+	check, err := m.checkStart(ctx, deviceID, childIDs, durationMinutes)
+	if err != nil {
+		return nil, err
+	}
 
-			if child.DowntimeEnabled {
-				child.DowntimeEnabled = false
-				if err := m.storage.UpdateChild(ctx, child); err != nil {
-					m.logger.Error("Failed to disable downtime for child",
-						"child_id", childID,
-						"error", err)
-				} else {
-					m.logger.Info("Downtime disabled via parent override",
-						"child_id", childID,
-						"child_name", child.Name)
-				}
-			}
-		}
+	// A busy device may queue the start or take the children into its running session instead
+	if session, handled, err := m.resolveConflict(ctx, deviceID, childIDs, durationMinutes); handled {
+		return session, err
 	}
+	device := check.device
+	minRemainingTime := check.minutes
+	now := Now()
+
+	// Parent overrides only apply to this session; the children's settings stay unchanged
+	override := OverrideFromContext(ctx)
+
+	// Break-exempt sessions are only requested by parents (admin API)
+	isBreakExempt := BreakExemptFromContext(ctx)
 
 	// Cap the duration to the minimum remaining time
 	actualDuration := minRemainingTime
+	capReason := check.capReason
 	if actualDuration < durationMinutes {
 		m.logger.Info("Session duration capped to available time",
 			"requested", durationMinutes,
 			"actual", actualDuration)
 	}
 
+	// Then to the initiator's session length limit (e.g., child-started sessions)
+	initiator := InitiatorFromContext(ctx)
+	if limit := m.initiatorLimit(ctx); limit > 0 && actualDuration > limit {
+		m.logger.Info("Session duration capped to initiator limit",
+			"initiator", initiator.String(),
+			"requested", durationMinutes,
+			"actual", limit)
+		actualDuration = limit
+		capReason = CapReasonInitiatorLimit
+	}
+
 	// Create session
 	session := &Session{
 		ID:               idgen.NewSession(),
 		DeviceType:       device.GetType(), // Use device type from device registry
 		DeviceID:         deviceID,
 		ChildIDs:         childIDs,
-		StartTime:        time.Now(),
+		StartTime:        Now(),
 		ExpectedDuration: actualDuration,
 		Status:           SessionStatusActive,
+		BreakExempt:      isBreakExempt,
+		OverrideDowntime: override.Downtime,
+		OverrideLimits:   override.Limits,
+		InitiatorType:    initiator.Type,
+		InitiatorID:      initiator.ID,
+		Grant:            NewDurationGrant(durationMinutes, actualDuration, capReason),
+	}
+	if session.Grant.Capped && capReason == CapReasonPolicy {
+		session.Grant.Policy = check.policy
+	}
+	if check.activity != nil {
+		session.Activity = check.activity.Name
+		m.logger.Info("Session is for an exempt activity",
+			"session_id", session.ID,
+			"activity", session.Activity)
+	}
+	if isBreakExempt {
+		m.logger.Info("Session is exempt from break rules",
+			"session_id", session.ID,
+			"child_ids", childIDs)
 	}
 
 	// Get device driver
@@ -262,64 +447,329 @@ func (m *SessionManager) StartSession(ctx context.Context, deviceID string, chil
 	}
 
 	// Start session on device (unlock it) ONLY after successful database save
-	if err := driver.StartSession(ctx, session); err != nil {
+	// With a driver queue, the start job is stored and the device is unlocked in the background
+	if err := m.queueDriverCall(ctx, DriverJobStart, session, 0, func() error {
+		return m.timeouts.Call(ctx, driver.Name(), DriverActionStart, session, func(ctx context.Context) error {
+			return driver.StartSession(ctx, session)
+		})
+	}); err != nil {
 		m.logger.Error("Driver failed to start session",
 			"session_id", session.ID,
 			"driver", driver.Name(),
 			"error", err)
 
+		// The cleanup must happen even if the request was canceled
+		cleanupCtx := context.WithoutCancel(ctx)
+
 		// CRITICAL: Delete the saved session since device unlock failed
-		if delErr := m.storage.DeleteSession(ctx, session.ID); delErr != nil {
+		if delErr := m.storage.DeleteSession(cleanupCtx, session.ID); delErr != nil {
 			m.logger.Error("Failed to cleanup session after driver failure",
 				"session_id", session.ID,
 				"error", delErr)
 		}
 
-		return nil, fmt.Errorf("failed to start session on device: %w", err)
+		// A call cut off by its deadline may still have unlocked the device; lock it again
+		if IsDriverInterrupted(err) {
+			if stopErr := m.timeouts.Call(cleanupCtx, driver.Name(), DriverActionStop, session, func(ctx context.Context) error {
+				return driver.StopSession(ctx, session)
+			}); stopErr != nil {
+				m.logger.Error("Failed to lock device after interrupted start",
+					"session_id", session.ID,
+					"driver", driver.Name(),
+					"error", stopErr)
+			}
+		}
+
+		return nil, fmt.Errorf("failed to start session on device: %w", err)
+	}
+
+	// The device is unlocked (or its start queued), so the rest is recorded even if the request is canceled now
+	ctx = context.WithoutCancel(ctx)
+
+	// Check if immediate warning is needed (for short sessions <= 5 minutes)
+	// Drivers without warnings are skipped (the scheduler skips them too)
+	if durationMinutes <= 5 && CapabilitiesOf(driver).SupportsWarnings {
+		m.logger.Debug("Session duration is short, sending immediate warning",
+			"session_id", session.ID,
+			"duration_minutes", durationMinutes)
+
+		// Trigger warning immediately for sessions that start with 5 minutes or less
+		if err := m.queueDriverCall(ctx, DriverJobWarning, session, durationMinutes, func() error {
+			return m.timeouts.Call(ctx, driver.Name(), DriverActionWarning, session, func(ctx context.Context) error {
+				return driver.ApplyWarning(ctx, session, durationMinutes)
+			})
+		}); err != nil {
+			// Log but don't fail - session is already created
+			m.logger.Warn("Failed to send immediate warning for short session",
+				"session_id", session.ID,
+				"error", err)
+		} else {
+			// Mark warning as sent
+			now := Now()
+			session.WarningSentAt = &now
+			if err := m.storage.UpdateSession(ctx, session); err != nil {
+				m.logger.Warn("Failed to mark warning as sent",
+					"session_id", session.ID,
+					"error", err)
+			}
+		}
+	}
+
+	// Increment session count for all children in this session
+	// Exempt activity sessions are not counted in the children's daily usage
+	counted := childIDs
+	if session.IsExempt() {
+		counted = nil
+	}
+	for _, childID := range counted {
+		if err := m.storage.IncrementSessionCountSummary(ctx, childID, m.calculator.UsageDate(ctx, childID, now)); err != nil {
+			// Log but don't fail - session is already created
+			m.logger.Warn("Failed to increment session count summary",
+				"session_id", session.ID,
+				"child_id", childID,
+				"error", err)
+		}
+	}
+
+	if override.IsSet() {
+		m.grantDowntimeOverride(ctx, session, override)
+		m.recordOverride(ctx, session, override, "Session started")
+	}
+	if m.activity != nil {
+		m.activity.SessionStarted(ctx, session)
+	}
+
+	m.logger.Info("Session started successfully",
+		"session_id", session.ID,
+		"device_id", deviceID,
+		"child_ids", childIDs,
+		"duration_minutes", durationMinutes)
+
+	return session, nil
+}
+
+// startCheck is the result of the checks shared by StartSession and PreflightSession
+type startCheck struct {
+	device    Device
+	minutes   int    // Requested duration capped to the children's remaining time and policies
+	capReason string // Why minutes is below the requested duration (CapReason*)
+	policy    string // Policy that capped minutes (CapReasonPolicy only)
+	childID   string // Child whose rule blocked the start, if any
+
+	activity *ExemptActivity // Exempt activity the session is for, nil for regular sessions
+}
+
+// checkStart runs the checks that decide whether a session may start and how long it may run
+// Returns the blocking rule's error (e.g., ErrDowntimeActive) without side effects
+func (m *SessionManager) checkStart(ctx context.Context, deviceID string, childIDs []string, durationMinutes int) (*startCheck, error) {
+	// Validate inputs
+	if deviceID == "" {
+		m.logger.Error("Session start failed: empty device ID")
+		return nil, fmt.Errorf("device ID cannot be empty")
+	}
+	if len(childIDs) == 0 {
+		m.logger.Error("Session start failed: no children specified")
+		return nil, ErrNoChildren
+	}
+	if durationMinutes <= 0 {
+		m.logger.Error("Session start failed: invalid duration",
+			"duration_minutes", durationMinutes)
+		return nil, ErrInvalidDuration
+	}
+
+	if err := m.checkLockdown(ctx); err != nil {
+		return nil, err
+	}
+
+	// Look up device from device registry
+	device, err := m.deviceRegistry.Get(deviceID)
+	if err != nil {
+		m.logger.Error("Failed to get device from registry",
+			"device_id", deviceID,
+			"error", err)
+		return nil, fmt.Errorf("failed to get device %s: %w", deviceID, err)
+	}
+
+	m.logger.Debug("Device found",
+		"device_id", deviceID,
+		"device_type", device.GetType(),
+		"driver", device.GetDriver())
+
+	// Sessions for an exempt activity draw on its own budget instead of the children's daily time
+	activity, err := m.startActivity(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate children exist and check time availability
+	now := Now()
+	check := &startCheck{device: device, minutes: durationMinutes, capReason: CapReasonRemainingTime, activity: activity} // Start with requested duration
+	var maxLength policyCap
+	limited := false // Whether any child is limited (not paused or overridden)
+
+	override := OverrideFromContext(ctx)
+
+	for _, childID := range childIDs {
+		child, err := m.storage.GetChild(ctx, childID)
+		if err != nil {
+			m.logger.Error("Failed to get child",
+				"child_id", childID,
+				"error", err)
+			return nil, fmt.Errorf("failed to get child %s: %w", childID, err)
+		}
+
+		// Check device permissions
+		if !child.CanUseDevice(deviceID) {
+			m.logger.Warn("Session start blocked by device permissions",
+				"child_id", childID,
+				"child_name", child.Name,
+				"device_id", deviceID)
+			check.childID = childID
+			return check, fmt.Errorf("%w: %s cannot use %s", ErrDeviceNotAllowed, child.Name, deviceID)
+		}
+
+		// Limits and downtime are not enforced while tracking is paused
+		if m.isTrackingPaused(ctx, childID) {
+			m.logger.Debug("Tracking paused, skipping limit checks",
+				"child_id", childID,
+				"child_name", child.Name)
+			continue
+		}
+
+		// Check downtime (unless parent override)
+		if !override.Downtime && m.downtime != nil && m.downtime.IsChildInDowntimeOnDevice(child, device.GetTimezone(), now) && !m.hasDayOverride(ctx, childID) {
+			m.logger.Warn("Session start blocked by downtime",
+				"child_id", childID,
+				"child_name", child.Name,
+				"downtime_enabled", child.DowntimeEnabled)
+			check.childID = childID
+			return check, ErrDowntimeActive
+		}
+
+		// A limits override lets the session run for the requested duration
+		if override.Limits {
+			m.logger.Debug("Parent override, skipping remaining time check",
+				"child_id", childID,
+				"child_name", child.Name)
+			continue
+		}
+
+		// Exempt activities are only limited by their own budget: not by the child's remaining
+		// time, the session policies or the family budget
+		if activity != nil {
+			remaining, err := m.activityRemaining(ctx, activity, child, now, "")
+			if err != nil {
+				return nil, err
+			}
+			if remaining == 0 {
+				m.logger.Warn("Session start blocked by the exempt activity budget",
+					"child_id", childID,
+					"child_name", child.Name,
+					"activity", activity.Name)
+				check.childID = childID
+				return check, fmt.Errorf("%w: %s used the %d minutes of %s today", ErrActivityBudgetUsedUp, child.Name, activity.DailyMinutes, activity.Name)
+			}
+			if remaining > 0 && remaining < check.minutes {
+				m.logger.Debug("Capping session duration to the exempt activity budget",
+					"child_id", childID,
+					"activity", activity.Name,
+					"remaining", remaining,
+					"original_duration", durationMinutes)
+				check.minutes = remaining
+				check.capReason = CapReasonActivity
+			}
+			continue
+		}
+
+		limited = true
+
+		// Session policies covering the child on this device
+		childCap, err := m.checkStartPolicies(ctx, child, deviceID, now)
+		if err != nil {
+			check.childID = childID
+			return check, err
+		}
+		if childCap.minutes > 0 && (maxLength.minutes == 0 || childCap.minutes < maxLength.minutes) {
+			maxLength = childCap
+		}
+
+		// Use calculator to check time availability
+		remaining, err := m.calculator.GetRemainingTime(ctx, childID, now)
+		if err != nil {
+			m.logger.Error("Failed to get remaining time",
+				"child_id", childID,
+				"error", err)
+			return nil, fmt.Errorf("failed to get remaining time for child %s: %w", childID, err)
+		}
+
+		m.logger.Debug("Checking child time availability",
+			"child_id", childID,
+			"child_name", child.Name,
+			"daily_limit", remaining.Available.BaseLimit,
+			"reward_granted", remaining.Available.BonusGranted,
+			"total_available", remaining.Available.TotalAvailable,
+			"used", remaining.Consumed.TotalConsumed,
+			"remaining", remaining.RemainingTotal,
+			"requested", durationMinutes)
+
+		// If child has no time left, reject the session
+		if remaining.RemainingTotal == 0 {
+			m.logger.Warn("No time remaining for child",
+				"child_id", childID,
+				"child_name", child.Name)
+			check.childID = childID
+			return check, newInsufficientTimeError(child, remaining, durationMinutes)
+		}
+
+		// Track minimum remaining time to cap the session
+		if remaining.RemainingTotal < check.minutes {
+			check.minutes = remaining.RemainingTotal
+			m.logger.Debug("Capping session duration to child's remaining time",
+				"child_id", childID,
+				"child_name", child.Name,
+				"remaining", remaining.RemainingTotal,
+				"original_duration", durationMinutes)
+		}
 	}
 
-	// Check if immediate warning is needed (for short sessions <= 5 minutes)
-	if durationMinutes <= 5 {
-		m.logger.Debug("Session duration is short, sending immediate warning",
-			"session_id", session.ID,
-			"duration_minutes", durationMinutes)
-
-		// Trigger warning immediately for sessions that start with 5 minutes or less
-		if err := driver.ApplyWarning(ctx, session, durationMinutes); err != nil {
-			// Log but don't fail - session is already created
-			m.logger.Warn("Failed to send immediate warning for short session",
-				"session_id", session.ID,
-				"error", err)
-		} else {
-			// Mark warning as sent
-			now := time.Now()
-			session.WarningSentAt = &now
-			if err := m.storage.UpdateSession(ctx, session); err != nil {
-				m.logger.Warn("Failed to mark warning as sent",
-					"session_id", session.ID,
-					"error", err)
-			}
-		}
+	// Then to the shortest max session length of the policies
+	if maxLength.minutes > 0 && maxLength.minutes < check.minutes {
+		m.logger.Debug("Capping session duration to policy",
+			"policy", maxLength.policy,
+			"max_session_minutes", maxLength.minutes,
+			"original_duration", durationMinutes)
+		check.minutes = maxLength.minutes
+		check.capReason = CapReasonPolicy
+		check.policy = maxLength.policy
 	}
 
-	// Increment session count for all children in this session
-	for _, childID := range childIDs {
-		if err := m.storage.IncrementSessionCountSummary(ctx, childID, today); err != nil {
-			// Log but don't fail - session is already created
-			m.logger.Warn("Failed to increment session count summary",
-				"session_id", session.ID,
-				"child_id", childID,
-				"error", err)
+	// And to the family budget shared by all children
+	if limited {
+		remaining, err := m.familyBudgetRemaining(ctx, deviceID, device.GetType(), now)
+		if err != nil {
+			return nil, err
+		}
+		if remaining == 0 {
+			m.logger.Warn("Session start blocked by the family budget",
+				"device_id", deviceID)
+			return check, ErrFamilyBudgetUsedUp
+		}
+		if remaining > 0 && remaining < check.minutes {
+			m.logger.Debug("Capping session duration to the family budget",
+				"remaining", remaining,
+				"original_duration", durationMinutes)
+			check.minutes = remaining
+			check.capReason = CapReasonFamilyBudget
+			check.policy = ""
 		}
 	}
 
-	m.logger.Info("Session started successfully",
-		"session_id", session.ID,
-		"device_id", deviceID,
-		"child_ids", childIDs,
-		"duration_minutes", durationMinutes)
+	// And whether the device is free, if starts on busy devices are rejected
+	if err := m.checkDeviceFree(ctx, deviceID); err != nil {
+		return check, err
+	}
 
-	return session, nil
+	return check, nil
 }
 
 // ExtendSession extends an active session
@@ -335,9 +785,26 @@ func (m *SessionManager) ExtendSession(ctx context.Context, sessionID string, ad
 		return nil, ErrInvalidDuration
 	}
 
+	if err := m.checkLockdown(ctx); err != nil {
+		return nil, err
+	}
+
+	// Hold the session until the extension is persisted, so a scheduler tick cannot
+	// expire it from a copy read before the extension
+	release, err := m.acquireSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Keep the original request for the grant returned to the caller
+	requestedMinutes := additionalMinutes
+	capReason := CapReasonRemainingTime
+
 	// Cap individual extension requests to prevent excessive grants
 	const MaxExtensionPerRequest = 30
 	if additionalMinutes > MaxExtensionPerRequest {
+		capReason = CapReasonExtensionLimit
 		m.logger.Info("Extension request capped to maximum allowed",
 			"session_id", sessionID,
 			"requested", additionalMinutes,
@@ -364,7 +831,7 @@ func (m *SessionManager) ExtendSession(ctx context.Context, sessionID string, ad
 	// Rate limiting: Prevent rapid-fire extensions
 	const ExtensionCooldownSeconds = 30
 	if session.LastExtendedAt != nil {
-		timeSinceLastExtend := time.Since(*session.LastExtendedAt)
+		timeSinceLastExtend := Now().Sub(*session.LastExtendedAt)
 		if timeSinceLastExtend < ExtensionCooldownSeconds*time.Second {
 			m.logger.Warn("Extension rejected due to rate limiting",
 				"session_id", sessionID,
@@ -377,12 +844,27 @@ func (m *SessionManager) ExtendSession(ctx context.Context, sessionID string, ad
 	m.logger.Debug("Session validation passed",
 		"session_id", sessionID,
 		"current_duration", session.ExpectedDuration,
-		"elapsed", int(time.Since(session.StartTime).Minutes()))
+		"elapsed", int(Now().Sub(session.StartTime).Minutes()))
+
+	// Only an override requested with the extension skips its checks: children extending a
+	// session a parent started with an override are still limited
+	override := OverrideFromContext(ctx)
+
+	// Exempt activity sessions are extended within the activity's budget instead
+	var activity *ExemptActivity
+	if session.IsExempt() {
+		if activity, err = m.exemptActivity(session.Activity); err != nil {
+			return nil, err
+		}
+	}
 
 	// Calculate maximum extension allowed based on children's remaining time
 	// Cap the extension to what's actually available instead of rejecting it
-	today := time.Now().In(m.timezone)
+	now := Now()
+	deviceTimezone := m.deviceTimezone(session.DeviceID)
 	maxExtension := additionalMinutes // Start with requested amount
+	var limitingChild *InsufficientTimeError
+	limited := false // Whether any child's tracking is not paused
 
 	for _, childID := range session.ChildIDs {
 		child, err := m.storage.GetChild(ctx, childID)
@@ -394,8 +876,14 @@ func (m *SessionManager) ExtendSession(ctx context.Context, sessionID string, ad
 			return nil, fmt.Errorf("failed to get child %s: %w", childID, err)
 		}
 
-		// Check downtime (no parent override allowed for extensions)
-		if m.downtime != nil && m.downtime.IsChildInDowntime(child, time.Now()) {
+		// Limits and downtime are not enforced while tracking is paused
+		if m.isTrackingPaused(ctx, childID) {
+			continue
+		}
+		limited = true
+
+		// Check downtime (unless parent override)
+		if !override.Downtime && m.downtime != nil && m.downtime.IsChildInDowntimeOnDevice(child, deviceTimezone, now) && !m.hasDayOverride(ctx, childID) {
 			m.logger.Warn("Session extension blocked by downtime",
 				"session_id", sessionID,
 				"child_id", childID,
@@ -404,10 +892,34 @@ func (m *SessionManager) ExtendSession(ctx context.Context, sessionID string, ad
 			return nil, ErrDowntimeActive
 		}
 
+		// A limits override grants the requested extension
+		if override.Limits {
+			continue
+		}
+
+		if activity != nil {
+			remaining, err := m.activityRemaining(ctx, activity, child, now, sessionID)
+			if err != nil {
+				return nil, err
+			}
+			if remaining == 0 {
+				m.logger.Warn("Extension rejected: exempt activity budget used up",
+					"session_id", sessionID,
+					"child_id", childID,
+					"activity", activity.Name)
+				return nil, fmt.Errorf("%w: %s used the %d minutes of %s today", ErrActivityBudgetUsedUp, child.Name, activity.DailyMinutes, activity.Name)
+			}
+			if remaining > 0 && remaining < maxExtension {
+				capReason = CapReasonActivity
+				maxExtension = remaining
+			}
+			continue
+		}
+
 		// Use calculator to get accurate remaining time for extension validation
 		// CRITICAL: Use GetRemainingTimeForExtension which uses ExpectedDuration
 		// instead of elapsed time to prevent rapid-fire extension exploit
-		remaining, err := m.calculator.GetRemainingTimeForExtension(ctx, childID, today, sessionID)
+		remaining, err := m.calculator.GetRemainingTimeForExtension(ctx, childID, now, sessionID)
 		if err != nil {
 			m.logger.Error("Failed to get remaining time for extension validation",
 				"session_id", sessionID,
@@ -429,6 +941,7 @@ func (m *SessionManager) ExtendSession(ctx context.Context, sessionID string, ad
 
 		// Cap extension to this child's remaining time
 		if remaining.RemainingTotal < maxExtension {
+			capReason = CapReasonRemainingTime
 			m.logger.Warn("Extension capped due to insufficient remaining time",
 				"session_id", sessionID,
 				"child_id", childID,
@@ -438,6 +951,62 @@ func (m *SessionManager) ExtendSession(ctx context.Context, sessionID string, ad
 				"total_available_today", remaining.Available.TotalAvailable,
 				"total_consumed_today", remaining.Consumed.TotalConsumed)
 			maxExtension = remaining.RemainingTotal
+			limitingChild = newInsufficientTimeError(child, remaining, requestedMinutes)
+		}
+	}
+
+	// Keep the session within the session length limit of whoever extends it
+	if limit := m.initiatorLimit(ctx); limit > 0 && session.ExpectedDuration+maxExtension > limit {
+		room := limit - session.ExpectedDuration
+		if room <= 0 {
+			m.logger.Warn("Extension rejected: session reached the initiator limit",
+				"session_id", sessionID,
+				"initiator", InitiatorFromContext(ctx).String(),
+				"expected_duration", session.ExpectedDuration,
+				"limit", limit)
+			return nil, ErrInitiatorLimit
+		}
+		capReason = CapReasonInitiatorLimit
+		maxExtension = room
+	}
+
+	// And within the max session length of the policies covering its children
+	// (a limits override skips them, as at the start)
+	var capPolicy string
+	var maxLength policyCap
+	if !override.Limits && activity == nil {
+		maxLength = m.extendPolicyCap(ctx, session)
+	}
+	if maxLength.minutes > 0 && session.ExpectedDuration+maxExtension > maxLength.minutes {
+		room := maxLength.minutes - session.ExpectedDuration
+		if room <= 0 {
+			m.logger.Warn("Extension rejected by policy",
+				"session_id", sessionID,
+				"policy", maxLength.policy,
+				"expected_duration", session.ExpectedDuration,
+				"max_session_minutes", maxLength.minutes)
+			return nil, &PolicyError{Policy: maxLength.policy, Rule: PolicyRuleMaxSessionLength, Limit: maxLength.minutes}
+		}
+		capReason = CapReasonPolicy
+		capPolicy = maxLength.policy
+		maxExtension = room
+	}
+
+	// And within the family budget, which already counts the session's planned time
+	if !override.Limits && limited && activity == nil {
+		remaining, err := m.familyBudgetRemaining(ctx, session.DeviceID, session.DeviceType, now)
+		if err != nil {
+			return nil, err
+		}
+		if remaining == 0 {
+			m.logger.Warn("Extension rejected: family budget used up",
+				"session_id", sessionID)
+			return nil, ErrFamilyBudgetUsedUp
+		}
+		if remaining > 0 && remaining < maxExtension {
+			capReason = CapReasonFamilyBudget
+			capPolicy = ""
+			maxExtension = remaining
 		}
 	}
 
@@ -446,6 +1015,9 @@ func (m *SessionManager) ExtendSession(ctx context.Context, sessionID string, ad
 		m.logger.Warn("No time available for any child in session",
 			"session_id", sessionID,
 			"requested", additionalMinutes)
+		if limitingChild != nil {
+			return nil, limitingChild
+		}
 		return nil, ErrInsufficientTime
 	}
 
@@ -477,22 +1049,31 @@ func (m *SessionManager) ExtendSession(ctx context.Context, sessionID string, ad
 		return nil, fmt.Errorf("failed to get driver %s for device %s: %w", device.GetDriver(), session.DeviceID, err)
 	}
 
-	// If driver supports extension, call it before updating session
-	if extendable, ok := driver.(interface {
-		ExtendSession(ctx context.Context, session *Session, additionalMinutes int) error
-	}); ok {
+	// If driver supports extension, call it before updating session (queued calls are made after)
+	// Otherwise the extension only moves the planned end (the scheduler stops the device later)
+	extendDriver := CapabilitiesOf(driver).SupportsExtension
+	if extendDriver && m.driverQueue == nil {
 		m.logger.Debug("Calling driver ExtendSession method",
 			"session_id", sessionID,
 			"driver", driver.Name(),
 			"extension_minutes", actualExtension)
 
-		if err := extendable.ExtendSession(ctx, session, actualExtension); err != nil {
+		if err := m.timeouts.Call(ctx, driver.Name(), DriverActionExtend, session, func(ctx context.Context) error {
+			return driver.(ExtendableDriver).ExtendSession(ctx, session, actualExtension)
+		}); err != nil {
 			m.logger.Error("Driver failed to extend session",
 				"session_id", sessionID,
 				"driver", driver.Name(),
 				"error", err)
 			return nil, fmt.Errorf("driver failed to extend session: %w", err)
 		}
+
+		// The device is extended, so the extension is recorded even if the request is canceled now
+		ctx = context.WithoutCancel(ctx)
+	} else if !extendDriver {
+		m.logger.Debug("Driver does not support extensions, extending session in Metron only",
+			"session_id", sessionID,
+			"driver", driver.Name())
 	}
 
 	// Calculate values before extension for logging
@@ -502,12 +1083,19 @@ func (m *SessionManager) ExtendSession(ctx context.Context, sessionID string, ad
 	session.ExpectedDuration += actualExtension
 
 	// Update last extended timestamp for rate limiting
-	now := time.Now()
+	now = Now()
 	session.LastExtendedAt = &now
 
 	// Reset warning state so a new warning can be sent when time crosses 5 minutes again
 	session.WarningSentAt = nil
 
+	// An extended session is no longer running on grace
+	session.GraceEndsAt = nil
+
+	// The scheduler lets the rest of the session run during downtime after a downtime override
+	session.OverrideDowntime = session.OverrideDowntime || override.Downtime
+	session.OverrideLimits = session.OverrideLimits || override.Limits
+
 	m.logger.Debug("Session duration updated in memory",
 		"session_id", sessionID,
 		"old_duration", oldExpectedDuration,
@@ -522,13 +1110,36 @@ func (m *SessionManager) ExtendSession(ctx context.Context, sessionID string, ad
 		return nil, fmt.Errorf("failed to update session: %w", err)
 	}
 
+	if extendDriver && m.driverQueue != nil {
+		if _, err := m.driverQueue.Enqueue(ctx, DriverJobExtend, sessionID, actualExtension); err != nil {
+			// The scheduler still stops the device at the new planned end
+			m.logger.Error("Failed to queue driver extension, session extended in Metron only",
+				"session_id", sessionID,
+				"driver", driver.Name(),
+				"error", err)
+		}
+	}
+
 	m.logger.Info("Session extended successfully",
 		"session_id", sessionID,
 		"old_duration", oldExpectedDuration,
 		"new_duration", session.ExpectedDuration,
 		"requested_minutes", additionalMinutes,
 		"actual_minutes", actualExtension,
-		"was_capped", actualExtension < additionalMinutes)
+		"was_capped", actualExtension < requestedMinutes)
+
+	if override.IsSet() {
+		m.grantDowntimeOverride(ctx, session, override)
+		m.recordOverride(ctx, session, override, fmt.Sprintf("Session extended by %d minutes", actualExtension))
+	}
+	if m.activity != nil {
+		m.activity.SessionExtended(ctx, session, actualExtension)
+	}
+
+	session.Grant = NewDurationGrant(requestedMinutes, actualExtension, capReason)
+	if session.Grant.Capped && capReason == CapReasonPolicy {
+		session.Grant.Policy = capPolicy
+	}
 
 	return session, nil
 }
@@ -538,6 +1149,14 @@ func (m *SessionManager) StopSession(ctx context.Context, sessionID string) erro
 	m.logger.Info("Stopping session",
 		"session_id", sessionID)
 
+	// Hold the session so a concurrent stop (API or scheduler) finds it completed
+	// instead of stopping the device a second time
+	release, err := m.acquireSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	// Get session
 	session, err := m.storage.GetSession(ctx, sessionID)
 	if err != nil {
@@ -547,14 +1166,23 @@ func (m *SessionManager) StopSession(ctx context.Context, sessionID string) erro
 		return err
 	}
 
-	if !session.IsActive() {
+	// Sessions paused for a break can be stopped too
+	if !session.IsRunning() {
 		m.logger.Warn("Cannot stop inactive session",
 			"session_id", sessionID,
 			"status", session.Status)
 		return ErrSessionNotActive
 	}
 
-	elapsed := int(time.Since(session.StartTime).Minutes())
+	// Guards are checked before the device is locked
+	if err := m.states.Check(session, SessionEventStop); err != nil {
+		m.logger.Warn("Session stop rejected",
+			"session_id", sessionID,
+			"error", err)
+		return err
+	}
+
+	elapsed := m.calculator.ChargeableMinutes(session, Now())
 	m.logger.Debug("Session details",
 		"session_id", sessionID,
 		"device_id", session.DeviceID,
@@ -583,45 +1211,79 @@ func (m *SessionManager) StopSession(ctx context.Context, sessionID string) erro
 		return fmt.Errorf("failed to get driver %s for device %s: %w", device.GetDriver(), session.DeviceID, err)
 	}
 
+	// Recorded before the device is stopped so drivers can report it
+	actual := m.calculator.SessionMinutes(session, Now())
+	session.ActualDuration = &actual
+
 	m.logger.Debug("Stopping session on device via driver",
 		"session_id", sessionID,
 		"driver", driver.Name())
 
 	// Stop session on device
-	if err := driver.StopSession(ctx, session); err != nil {
-		m.logger.Error("Driver failed to stop session",
-			"session_id", sessionID,
-			"driver", driver.Name(),
-			"error", err)
-		return fmt.Errorf("failed to stop session on device: %w", err)
+	// With a driver queue, the session is completed first and its stop job locks the device afterwards
+	if m.driverQueue == nil {
+		if err := m.timeouts.Call(ctx, driver.Name(), DriverActionStop, session, func(ctx context.Context) error {
+			return driver.StopSession(ctx, session)
+		}); err != nil {
+			m.logger.Error("Driver failed to stop session",
+				"session_id", sessionID,
+				"driver", driver.Name(),
+				"error", err)
+			return fmt.Errorf("failed to stop session on device: %w", err)
+		}
+
+		// The device is locked, so the stop is recorded even if the request is canceled now
+		ctx = context.WithoutCancel(ctx)
 	}
 
 	// Update session status
-	session.Status = SessionStatusCompleted
-
-	if err := m.storage.UpdateSession(ctx, session); err != nil {
+	session.EndReason = EndReasonFrom(ctx)
+	if _, err := m.states.Apply(ctx, session, SessionEventStop, m.storage.UpdateSession); err != nil {
 		m.logger.Error("Failed to update session status",
 			"session_id", sessionID,
 			"error", err)
 		return fmt.Errorf("failed to update session: %w", err)
 	}
 
-	// Update daily usage summary for all children
-	today := time.Now().In(m.timezone)
+	if m.driverQueue != nil {
+		if err := m.queueDriverCall(ctx, DriverJobStop, session, 0, func() error {
+			return m.timeouts.Call(ctx, driver.Name(), DriverActionStop, session, func(ctx context.Context) error {
+				return driver.StopSession(ctx, session)
+			})
+		}); err != nil {
+			m.logger.Error("Driver failed to stop session, session completed in Metron only",
+				"session_id", sessionID,
+				"driver", driver.Name(),
+				"error", err)
+		}
+	}
+
+	// Update daily usage summary for all children (on each child's calendar day)
+	now := Now()
 
 	for _, childID := range session.ChildIDs {
+		if m.isTrackingPaused(ctx, childID) {
+			m.logger.Debug("Tracking paused, usage not recorded",
+				"session_id", sessionID,
+				"child_id", childID,
+				"elapsed_minutes", elapsed)
+			continue
+		}
+
 		m.logger.Debug("Updating daily usage summary for child",
 			"session_id", sessionID,
 			"child_id", childID,
 			"elapsed_minutes", elapsed)
 
-		if err := m.storage.IncrementDailyUsageSummary(ctx, childID, today, elapsed); err != nil {
+		if err := m.storage.IncrementDailyUsageSummary(ctx, childID, m.calculator.UsageDate(ctx, childID, now), elapsed); err != nil {
 			m.logger.Error("Failed to update daily usage summary",
 				"session_id", sessionID,
 				"child_id", childID,
 				"error", err)
 			return fmt.Errorf("failed to update daily usage summary for child %s: %w", childID, err)
 		}
+
+		m.chargeGraceOverage(ctx, session, childID, now)
 	}
 
 	m.logger.Info("Session stopped successfully",
@@ -632,12 +1294,41 @@ func (m *SessionManager) StopSession(ctx context.Context, sessionID string) erro
 	return nil
 }
 
+// chargeGraceOverage deducts the child's grace overage from tomorrow (see grace.go)
+// Errors are logged; the session has already ended or the child has already been removed
+func (m *SessionManager) chargeGraceOverage(ctx context.Context, session *Session, childID string, end time.Time) {
+	overage, err := m.calculator.ChargeGraceOverage(ctx, session, childID, end)
+	if err != nil {
+		m.logger.Error("Failed to deduct grace overage",
+			"session_id", session.ID,
+			"child_id", childID,
+			"error", err)
+		return
+	}
+	if overage > 0 {
+		m.logger.Info("Grace overage deducted from tomorrow",
+			"session_id", session.ID,
+			"child_id", childID,
+			"overage_minutes", overage)
+	}
+}
+
 // AddChildrenToSession adds one or more children to an active session
 func (m *SessionManager) AddChildrenToSession(ctx context.Context, sessionID string, childIDs []string) (*Session, error) {
 	m.logger.Info("Adding children to session",
 		"session_id", sessionID,
 		"child_ids", childIDs)
 
+	if err := m.checkLockdown(ctx); err != nil {
+		return nil, err
+	}
+
+	release, err := m.acquireSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
 	// Get session
 	session, err := m.storage.GetSession(ctx, sessionID)
 	if err != nil {
@@ -654,13 +1345,8 @@ func (m *SessionManager) AddChildrenToSession(ctx context.Context, sessionID str
 		return nil, ErrSessionNotActive
 	}
 
-	// Calculate elapsed time since session start
-	elapsed := int(time.Since(session.StartTime).Minutes())
-	if elapsed < 0 {
-		elapsed = 0
-	}
-
-	today := time.Now().In(m.timezone)
+	// Joining children are charged from the session start when it ends (see the charge policy)
+	now := Now()
 	newChildIDs := []string{}
 
 	for _, childID := range childIDs {
@@ -690,8 +1376,18 @@ func (m *SessionManager) AddChildrenToSession(ctx context.Context, sessionID str
 			return nil, fmt.Errorf("failed to get child %s: %w", childID, err)
 		}
 
+		// Check device permissions
+		if !child.CanUseDevice(session.DeviceID) {
+			m.logger.Warn("Cannot add child to session: device not allowed",
+				"session_id", sessionID,
+				"child_id", childID,
+				"child_name", child.Name,
+				"device_id", session.DeviceID)
+			return nil, fmt.Errorf("%w: %s cannot use %s", ErrDeviceNotAllowed, child.Name, session.DeviceID)
+		}
+
 		// Use calculator to get accurate remaining time (includes all active sessions)
-		remainingTime, err := m.calculator.GetRemainingTime(ctx, childID, today)
+		remainingTime, err := m.calculator.GetRemainingTime(ctx, childID, now)
 		if err != nil {
 			m.logger.Error("Failed to get remaining time",
 				"session_id", sessionID,
@@ -708,11 +1404,10 @@ func (m *SessionManager) AddChildrenToSession(ctx context.Context, sessionID str
 			"reward_granted", remainingTime.Available.BonusGranted,
 			"total_available", remainingTime.Available.TotalAvailable,
 			"total_consumed", remainingTime.Consumed.TotalConsumed,
-			"remaining", remainingTime.RemainingTotal,
-			"elapsed", elapsed)
+			"remaining", remainingTime.RemainingTotal)
 
-		// Check if child has any time left
-		if remainingTime.RemainingTotal == 0 {
+		// Check if child has any time left (not enforced while tracking is paused)
+		if remainingTime.RemainingTotal == 0 && !m.isTrackingPaused(ctx, childID) {
 			m.logger.Warn("Child has no time remaining",
 				"session_id", sessionID,
 				"child_id", childID,
@@ -720,32 +1415,8 @@ func (m *SessionManager) AddChildrenToSession(ctx context.Context, sessionID str
 			return nil, fmt.Errorf("child %s has no time remaining", child.Name)
 		}
 
-		// Cap the charged time to what the child has available
-		chargedTime := elapsed
-		if remainingTime.RemainingTotal < elapsed {
-			chargedTime = remainingTime.RemainingTotal
-			m.logger.Info("Capping charged time to child's remaining time",
-				"session_id", sessionID,
-				"child_id", childID,
-				"child_name", child.Name,
-				"remaining", remainingTime.RemainingTotal,
-				"elapsed", elapsed,
-				"charged", chargedTime)
-		}
-
-		// Update daily usage summary for charged time
-		if chargedTime > 0 {
-			if err := m.storage.IncrementDailyUsageSummary(ctx, childID, today, chargedTime); err != nil {
-				m.logger.Error("Failed to update daily usage summary",
-					"session_id", sessionID,
-					"child_id", childID,
-					"error", err)
-				return nil, fmt.Errorf("failed to update daily usage summary for child %s: %w", childID, err)
-			}
-		}
-
 		// Increment session count for this child
-		if err := m.storage.IncrementSessionCountSummary(ctx, childID, today); err != nil {
+		if err := m.storage.IncrementSessionCountSummary(ctx, childID, m.calculator.UsageDate(ctx, childID, now)); err != nil {
 			// Log but don't fail
 			m.logger.Warn("Failed to increment session count summary",
 				"session_id", sessionID,
@@ -781,6 +1452,94 @@ func (m *SessionManager) AddChildrenToSession(ctx context.Context, sessionID str
 	return session, nil
 }
 
+// RemoveChildFromSession removes a child from an active session
+// The child is charged for the time elapsed up to the removal; the remaining children
+// keep the session and are charged as usual when it stops
+func (m *SessionManager) RemoveChildFromSession(ctx context.Context, sessionID string, childID string) (*Session, error) {
+	m.logger.Info("Removing child from session",
+		"session_id", sessionID,
+		"child_id", childID)
+
+	release, err := m.acquireSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Get session
+	session, err := m.storage.GetSession(ctx, sessionID)
+	if err != nil {
+		m.logger.Error("Failed to get session",
+			"session_id", sessionID,
+			"error", err)
+		return nil, err
+	}
+
+	if !session.IsActive() {
+		m.logger.Warn("Cannot remove child from inactive session",
+			"session_id", sessionID,
+			"status", session.Status)
+		return nil, ErrSessionNotActive
+	}
+
+	remainingChildIDs := make([]string, 0, len(session.ChildIDs))
+	for _, existingID := range session.ChildIDs {
+		if existingID != childID {
+			remainingChildIDs = append(remainingChildIDs, existingID)
+		}
+	}
+
+	if len(remainingChildIDs) == len(session.ChildIDs) {
+		m.logger.Warn("Child is not in session",
+			"session_id", sessionID,
+			"child_id", childID)
+		return nil, ErrChildNotInSession
+	}
+
+	if len(remainingChildIDs) == 0 {
+		m.logger.Warn("Cannot remove the last child from session",
+			"session_id", sessionID,
+			"child_id", childID)
+		return nil, ErrLastChildInSession
+	}
+
+	// Update session first so the child is not charged again when the session stops
+	session.ChildIDs = remainingChildIDs
+
+	if err := m.storage.UpdateSession(ctx, session); err != nil {
+		m.logger.Error("Failed to update session",
+			"session_id", sessionID,
+			"error", err)
+		return nil, fmt.Errorf("failed to update session: %w", err)
+	}
+
+	// Charge the removed child for the time used so far
+	now := Now()
+	elapsed := m.calculator.ChargeableMinutes(session, now)
+
+	if elapsed > 0 && !m.isTrackingPaused(ctx, childID) {
+		today := m.calculator.UsageDate(ctx, childID, now)
+		if err := m.storage.IncrementDailyUsageSummary(ctx, childID, today, elapsed); err != nil {
+			m.logger.Error("Failed to update daily usage summary",
+				"session_id", sessionID,
+				"child_id", childID,
+				"error", err)
+			return nil, fmt.Errorf("failed to update daily usage summary for child %s: %w", childID, err)
+		}
+	}
+	if !m.isTrackingPaused(ctx, childID) {
+		m.chargeGraceOverage(ctx, session, childID, now)
+	}
+
+	m.logger.Info("Child removed from session successfully",
+		"session_id", sessionID,
+		"child_id", childID,
+		"charged_minutes", elapsed,
+		"remaining_child_ids", session.ChildIDs)
+
+	return session, nil
+}
+
 // GetSession retrieves a session by ID
 func (m *SessionManager) GetSession(ctx context.Context, sessionID string) (*Session, error) {
 	return m.storage.GetSession(ctx, sessionID)
@@ -814,10 +1573,10 @@ func (m *SessionManager) GrantRewardMinutes(ctx context.Context, childID string,
 	}
 
 	// Grant reward for today using new allocation system
-	today := time.Now().In(m.timezone)
+	now := Now()
 
 	// Get or create allocation for today
-	allocation, err := m.calculator.GetAvailableTime(ctx, childID, today)
+	allocation, err := m.calculator.GetAvailableTime(ctx, childID, now)
 	if err != nil {
 		m.logger.Error("Failed to get allocation for reward grant",
 			"child_id", childID,
@@ -826,19 +1585,19 @@ func (m *SessionManager) GrantRewardMinutes(ctx context.Context, childID string,
 	}
 
 	// Update the allocation with new bonus
-	normalizedDate := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, m.timezone)
+	normalizedDate := m.calculator.UsageDate(ctx, childID, now)
 	newAllocation := &DailyTimeAllocation{
 		ChildID:      childID,
 		Date:         normalizedDate,
 		BaseLimit:    allocation.BaseLimit,
 		BonusGranted: allocation.BonusGranted + minutes,
-		UpdatedAt:    time.Now(),
+		UpdatedAt:    Now(),
 	}
 
 	// Try to update first, create if it doesn't exist
 	if err := m.storage.UpdateDailyAllocation(ctx, newAllocation); err != nil {
 		// If update fails, try to create
-		newAllocation.CreatedAt = time.Now()
+		newAllocation.CreatedAt = Now()
 		if createErr := m.storage.CreateDailyAllocation(ctx, newAllocation); createErr != nil {
 			m.logger.Error("Failed to grant reward minutes",
 				"child_id", childID,
@@ -851,6 +1610,9 @@ func (m *SessionManager) GrantRewardMinutes(ctx context.Context, childID string,
 	m.logger.Info("Reward minutes granted successfully",
 		"child_id", childID,
 		"minutes", minutes)
+	if m.activity != nil {
+		m.activity.Reward(ctx, childID, minutes)
+	}
 
 	return nil
 }
@@ -877,10 +1639,10 @@ func (m *SessionManager) DeductFineMinutes(ctx context.Context, childID string,
 		return err
 	}
 
-	today := time.Now().In(m.timezone)
+	now := Now()
 
 	// Get remaining time to validate we can apply the fine
-	remaining, err := m.calculator.GetRemainingTime(ctx, childID, today)
+	remaining, err := m.calculator.GetRemainingTime(ctx, childID, now)
 	if err != nil {
 		m.logger.Error("Failed to get remaining time for fine deduction",
 			"child_id", childID,
@@ -898,7 +1660,7 @@ func (m *SessionManager) DeductFineMinutes(ctx context.Context, childID string,
 	}
 
 	// Get or create allocation for today
-	allocation, err := m.calculator.GetAvailableTime(ctx, childID, today)
+	allocation, err := m.calculator.GetAvailableTime(ctx, childID, now)
 	if err != nil {
 		m.logger.Error("Failed to get allocation for fine deduction",
 			"child_id", childID,
@@ -907,19 +1669,19 @@ func (m *SessionManager) DeductFineMinutes(ctx context.Context, childID string,
 	}
 
 	// Update the allocation with reduced bonus (subtract fine)
-	normalizedDate := time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, m.timezone)
+	normalizedDate := m.calculator.UsageDate(ctx, childID, now)
 	newAllocation := &DailyTimeAllocation{
 		ChildID:      childID,
 		Date:         normalizedDate,
 		BaseLimit:    allocation.BaseLimit,
 		BonusGranted: allocation.BonusGranted - minutes,
-		UpdatedAt:    time.Now(),
+		UpdatedAt:    Now(),
 	}
 
 	// Try to update first, create if it doesn't exist
 	if err := m.storage.UpdateDailyAllocation(ctx, newAllocation); err != nil {
 		// If update fails, try to create
-		newAllocation.CreatedAt = time.Now()
+		newAllocation.CreatedAt = Now()
 		if createErr := m.storage.CreateDailyAllocation(ctx, newAllocation); createErr != nil {
 			m.logger.Error("Failed to deduct fine minutes",
 				"child_id", childID,
@@ -932,6 +1694,9 @@ func (m *SessionManager) DeductFineMinutes(ctx context.Context, childID string,
 	m.logger.Info("Fine minutes deducted successfully",
 		"child_id", childID,
 		"minutes", minutes)
+	if m.activity != nil {
+		m.activity.Fine(ctx, childID, minutes)
+	}
 
 	return nil
 }
@@ -943,21 +1708,33 @@ func (m *SessionManager) GetChildStatus(ctx context.Context, childID string) (*C
 		return nil, err
 	}
 
-	today := time.Now().In(m.timezone)
+	now := Now()
 
 	// Use calculator for all time calculations
-	remaining, err := m.calculator.GetRemainingTime(ctx, childID, today)
+	remaining, err := m.calculator.GetRemainingTime(ctx, childID, now)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get session count from daily usage summary
-	summary, err := m.storage.GetDailyUsageSummary(ctx, childID, today)
+	summary, err := m.storage.GetDailyUsageSummary(ctx, childID, m.calculator.UsageDate(ctx, childID, now))
 	sessionCount := 0
 	if err == nil {
 		sessionCount = summary.SessionCount
 	}
 
+	// Report vacation mode; a failed check is logged and the child shown as tracked
+	var trackingPause *TrackingPause
+	if m.trackingPause != nil {
+		trackingPause, err = m.trackingPause.PauseFor(ctx, childID)
+		if err != nil {
+			m.logger.Error("Failed to check tracking pause",
+				"child_id", childID,
+				"error", err)
+			trackingPause = nil
+		}
+	}
+
 	return &ChildStatus{
 		Child:              child,
 		TodayUsed:          remaining.Consumed.TotalConsumed,
@@ -965,9 +1742,52 @@ func (m *SessionManager) GetChildStatus(ctx context.Context, childID string) (*C
 		TodayRemaining:     remaining.RemainingTotal,
 		TodayLimit:         remaining.Available.TotalAvailable,
 		SessionsToday:      sessionCount,
+		TrackingPause:      trackingPause,
 	}, nil
 }
 
+// GetChildrenStatus retrieves the current status of every child
+// Statuses match GetChildStatus, but are computed with one query per kind of record instead of per child
+func (m *SessionManager) GetChildrenStatus(ctx context.Context) ([]*ChildStatus, error) {
+	children, err := m.storage.ListChildren(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := Now()
+	remaining, err := m.calculator.GetRemainingTimeForChildren(ctx, children, now)
+	if err != nil {
+		return nil, err
+	}
+
+	// Report vacation mode; a failed check is logged and the children shown as tracked
+	var pauses []*TrackingPause
+	if m.trackingPause != nil {
+		pauses, err = m.trackingPause.Active(ctx)
+		if err != nil {
+			m.logger.Error("Failed to check tracking pauses",
+				"error", err)
+			pauses = nil
+		}
+	}
+
+	statuses := make([]*ChildStatus, 0, len(children))
+	for _, child := range children {
+		result := remaining[child.ID]
+		statuses = append(statuses, &ChildStatus{
+			Child:              child,
+			TodayUsed:          result.Consumed.TotalConsumed,
+			TodayRewardGranted: result.Available.BonusGranted,
+			TodayRemaining:     result.RemainingTotal,
+			TodayLimit:         result.Available.TotalAvailable,
+			SessionsToday:      result.Consumed.SessionCount,
+			TrackingPause:      FindTrackingPause(pauses, child.ID, now),
+		})
+	}
+
+	return statuses, nil
+}
+
 // ChildStatus represents a child's current status
 type ChildStatus struct {
 	Child               *Child
@@ -976,4 +1796,5 @@ type ChildStatus struct {
 	TodayRemaining      int // calculated as: limit + rewardGranted - used
 	TodayLimit          int // total available today (base + rewards)
 	SessionsToday       int
+	TrackingPause       *TrackingPause // non-nil while tracking is paused for the child (vacation mode)
 }
@@ -216,6 +216,16 @@ func (m *mockStorage) ListSessionsByChild(ctx context.Context, childID string) (
 	return sessions, nil
 }
 
+func (m *mockStorage) ListSessionsSince(ctx context.Context, since time.Time) ([]*Session, error) {
+	sessions := make([]*Session, 0)
+	for _, session := range m.sessions {
+		if !session.StartTime.Before(since) {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
 // TimeCalculationStorage interface methods
 func (m *mockStorage) GetDailyAllocation(ctx context.Context, childID string, date time.Time) (*DailyTimeAllocation, error) {
 	// For tests, we'll create a simple allocation on-demand
@@ -275,6 +285,7 @@ func (m *mockStorage) ListActiveSessionRecords(ctx context.Context) ([]*SessionU
 			LastBreakAt:      session.LastBreakAt,
 			BreakEndsAt:      session.BreakEndsAt,
 			WarningSentAt:    session.WarningSentAt,
+			Activity:         session.Activity,
 			CreatedAt:        session.CreatedAt,
 			UpdatedAt:        session.UpdatedAt,
 		}
@@ -308,6 +319,8 @@ type mockDriver struct {
 	warnCalled   bool
 	failStart    bool
 	failStop     bool
+	extendCalls  []int
+	capabilities *DriverCapabilities // Declared capabilities; nil means warnings only
 }
 
 func (m *mockDriver) Name() string {
@@ -335,17 +348,31 @@ func (m *mockDriver) ApplyWarning(ctx context.Context, session *Session, minutes
 	return nil
 }
 
+func (m *mockDriver) ExtendSession(ctx context.Context, session *Session, additionalMinutes int) error {
+	m.extendCalls = append(m.extendCalls, additionalMinutes)
+	return nil
+}
+
+func (m *mockDriver) Capabilities() DriverCapabilities {
+	if m.capabilities == nil {
+		return DriverCapabilities{SupportsWarnings: true, SupportsScheduling: true}
+	}
+	return *m.capabilities
+}
+
 type mockDevice struct {
-	id     string
-	name   string
-	dtype  string
-	driver string
+	id       string
+	name     string
+	dtype    string
+	driver   string
+	timezone string
 }
 
 func (m *mockDevice) GetID() string     { return m.id }
 func (m *mockDevice) GetName() string   { return m.name }
 func (m *mockDevice) GetType() string   { return m.dtype }
 func (m *mockDevice) GetDriver() string { return m.driver }
+func (m *mockDevice) GetTimezone() string { return m.timezone }
 func (m *mockDevice) GetParameter(key string) interface{} { return nil }
 func (m *mockDevice) GetParameters() map[string]interface{} { return nil }
 
@@ -434,6 +461,44 @@ func TestSessionManager_StartSession(t *testing.T) {
 	assert.LessOrEqual(t, session.CalculateRemainingMinutes(), 30)
 	assert.Equal(t, SessionStatusActive, session.Status)
 	assert.True(t, driver.startCalled)
+
+	// Nothing was capped
+	require.NotNil(t, session.Grant)
+	assert.Equal(t, 30, session.Grant.RequestedMinutes)
+	assert.Equal(t, 30, session.Grant.GrantedMinutes)
+	assert.False(t, session.Grant.Capped)
+	assert.Empty(t, session.Grant.Reason)
+}
+
+func TestSessionManager_StartSession_BreakExempt(t *testing.T) {
+	storage := newMockStorage()
+	deviceRegistry := newMockDeviceRegistry()
+	driverRegistry := newMockDriverRegistry()
+	manager := NewSessionManager(storage, deviceRegistry, driverRegistry, nil, nil, nil, nil)
+
+	storage.CreateChild(context.Background(), &Child{
+		ID:           "child1",
+		Name:         "Alice",
+		WeekdayLimit: 60,
+		WeekendLimit: 120,
+	})
+	driverRegistry.addDriver(&mockDriver{name: "aqara"})
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", name: "Living Room TV", dtype: "tv", driver: "aqara"})
+
+	// Regular session
+	session, err := manager.StartSession(context.Background(), "tv1", []string{"child1"}, 30)
+	require.NoError(t, err)
+	assert.False(t, session.BreakExempt)
+
+	// Parent-approved session skips breaks and is persisted that way
+	ctx := WithBreakExempt(context.Background())
+	session, err = manager.StartSession(ctx, "tv1", []string{"child1"}, 30)
+	require.NoError(t, err)
+	assert.True(t, session.BreakExempt)
+
+	stored, err := storage.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.BreakExempt)
 }
 
 func TestSessionManager_StartSession_InsufficientTime(t *testing.T) {
@@ -465,6 +530,98 @@ func TestSessionManager_StartSession_InsufficientTime(t *testing.T) {
 	_, err := manager.StartSession(context.Background(), "tv1", []string{"child1"}, 30)
 	assert.Error(t, err)
 	assert.ErrorIs(t, err, ErrInsufficientTime)
+
+	// Details identify the child and its usage
+	var timeErr *InsufficientTimeError
+	require.ErrorAs(t, err, &timeErr)
+	assert.Equal(t, "child1", timeErr.ChildID)
+	assert.Equal(t, "Alice", timeErr.ChildName)
+	assert.Equal(t, 0, timeErr.RemainingMinutes)
+	assert.Equal(t, 60, timeErr.LimitMinutes)
+	assert.Equal(t, 60, timeErr.UsedMinutes)
+	assert.Equal(t, 30, timeErr.RequestedMinutes)
+}
+
+func TestSessionManager_StartSession_DeviceNotAllowed(t *testing.T) {
+	ctx := context.Background()
+	storage := newMockStorage()
+	deviceRegistry := newMockDeviceRegistry()
+	driverRegistry := newMockDriverRegistry()
+	manager := NewSessionManager(storage, deviceRegistry, driverRegistry, nil, nil, nil, nil)
+
+	storage.CreateChild(ctx, &Child{ID: "child1", Name: "Alice", WeekdayLimit: 120, WeekendLimit: 120, AllowedDevices: []string{"tv1"}})
+	storage.CreateChild(ctx, &Child{ID: "child2", Name: "Bob", WeekdayLimit: 120, WeekendLimit: 120})
+
+	driver := &mockDriver{name: "aqara"}
+	driverRegistry.addDriver(driver)
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", name: "TV", dtype: "tv", driver: "aqara"})
+	deviceRegistry.addDevice(&mockDevice{id: "ps5", name: "PS5", dtype: "ps5", driver: "aqara"})
+
+	// Alice may not use the PS5
+	_, err := manager.StartSession(ctx, "ps5", []string{"child1"}, 30)
+	assert.ErrorIs(t, err, ErrDeviceNotAllowed)
+	assert.False(t, driver.startCalled)
+
+	// Bob has no allow-list and can use it
+	session, err := manager.StartSession(ctx, "ps5", []string{"child2"}, 30)
+	require.NoError(t, err)
+
+	// Alice cannot join Bob's PS5 session either
+	_, err = manager.AddChildrenToSession(ctx, session.ID, []string{"child1"})
+	assert.ErrorIs(t, err, ErrDeviceNotAllowed)
+
+	// The TV is on Alice's allow-list
+	_, err = manager.StartSession(ctx, "tv1", []string{"child1"}, 30)
+	assert.NoError(t, err)
+}
+
+func TestSessionManager_RemoveChildFromSession(t *testing.T) {
+	ctx := context.Background()
+	storage := newMockStorage()
+	deviceRegistry := newMockDeviceRegistry()
+	driverRegistry := newMockDriverRegistry()
+	manager := NewSessionManager(storage, deviceRegistry, driverRegistry, nil, nil, nil, nil)
+
+	storage.CreateChild(ctx, &Child{ID: "child1", Name: "Alice", WeekdayLimit: 120, WeekendLimit: 120})
+	storage.CreateChild(ctx, &Child{ID: "child2", Name: "Bob", WeekdayLimit: 120, WeekendLimit: 120})
+
+	driver := &mockDriver{name: "aqara"}
+	driverRegistry.addDriver(driver)
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", name: "TV", dtype: "tv", driver: "aqara"})
+
+	session, err := manager.StartSession(ctx, "tv1", []string{"child1", "child2"}, 60)
+	require.NoError(t, err)
+
+	// Simulate 20 minutes of shared usage
+	session.StartTime = time.Now().Add(-20 * time.Minute)
+
+	// Bob leaves and is charged for the time so far
+	updated, err := manager.RemoveChildFromSession(ctx, session.ID, "child2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"child1"}, updated.ChildIDs)
+
+	bobUsage, _ := storage.GetDailyUsage(ctx, "child2", time.Now())
+	assert.Equal(t, 20, bobUsage.MinutesUsed)
+
+	// Bob is no longer in the session
+	_, err = manager.RemoveChildFromSession(ctx, session.ID, "child2")
+	assert.ErrorIs(t, err, ErrChildNotInSession)
+
+	// The last child cannot be removed
+	_, err = manager.RemoveChildFromSession(ctx, session.ID, "child1")
+	assert.ErrorIs(t, err, ErrLastChildInSession)
+
+	// Stopping charges only the remaining child
+	require.NoError(t, manager.StopSession(ctx, session.ID))
+
+	aliceUsage, _ := storage.GetDailyUsage(ctx, "child1", time.Now())
+	assert.Equal(t, 20, aliceUsage.MinutesUsed)
+	bobUsage, _ = storage.GetDailyUsage(ctx, "child2", time.Now())
+	assert.Equal(t, 20, bobUsage.MinutesUsed)
+
+	// Inactive sessions cannot be changed
+	_, err = manager.RemoveChildFromSession(ctx, session.ID, "child1")
+	assert.ErrorIs(t, err, ErrSessionNotActive)
 }
 
 func TestSessionManager_StartSession_InvalidInputs(t *testing.T) {
@@ -552,6 +709,55 @@ func TestSessionManager_ExtendSession(t *testing.T) {
 	assert.LessOrEqual(t, extended.CalculateRemainingMinutes(), 30)
 }
 
+func TestSessionManager_DriverCapabilities(t *testing.T) {
+	setup := func(caps *DriverCapabilities) (*SessionManager, *mockDriver) {
+		storage := newMockStorage()
+		deviceRegistry := newMockDeviceRegistry()
+		driverRegistry := newMockDriverRegistry()
+		manager := NewSessionManager(storage, deviceRegistry, driverRegistry, nil, nil, nil, nil)
+
+		storage.CreateChild(context.Background(), &Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 120})
+		driver := &mockDriver{name: "kidslox", capabilities: caps}
+		driverRegistry.addDriver(driver)
+		deviceRegistry.addDevice(&mockDevice{id: "tablet1", name: "Tablet", dtype: "tablet", driver: "kidslox"})
+		return manager, driver
+	}
+
+	t.Run("extension is sent to drivers that support it", func(t *testing.T) {
+		manager, driver := setup(&DriverCapabilities{SupportsExtension: true})
+
+		session, err := manager.StartSession(context.Background(), "tablet1", []string{"child1"}, 20)
+		require.NoError(t, err)
+		extended, err := manager.ExtendSession(context.Background(), session.ID, 10)
+		require.NoError(t, err)
+
+		assert.Equal(t, []int{10}, driver.extendCalls)
+		assert.Equal(t, 30, extended.ExpectedDuration)
+	})
+
+	t.Run("extension without driver support only moves the end", func(t *testing.T) {
+		manager, driver := setup(nil)
+
+		session, err := manager.StartSession(context.Background(), "tablet1", []string{"child1"}, 20)
+		require.NoError(t, err)
+		extended, err := manager.ExtendSession(context.Background(), session.ID, 10)
+		require.NoError(t, err)
+
+		assert.Empty(t, driver.extendCalls)
+		assert.Equal(t, 30, extended.ExpectedDuration)
+	})
+
+	t.Run("short session warning is skipped without warning support", func(t *testing.T) {
+		manager, driver := setup(&DriverCapabilities{SupportsScheduling: true})
+
+		session, err := manager.StartSession(context.Background(), "tablet1", []string{"child1"}, 5)
+		require.NoError(t, err)
+
+		assert.False(t, driver.warnCalled)
+		assert.Nil(t, session.WarningSentAt)
+	})
+}
+
 func TestSessionManager_ExtendSession_InsufficientTime(t *testing.T) {
 	storage := newMockStorage()
 	deviceRegistry := newMockDeviceRegistry()
@@ -598,6 +804,47 @@ func TestSessionManager_ExtendSession_InsufficientTime(t *testing.T) {
 	// Original duration: 10, expected after extension: 10 + 10 = 20
 	// This prevents the exploit where children spam extend immediately after starting
 	assert.Equal(t, 20, extendedSession.ExpectedDuration, "Extension should be capped to remaining 10 minutes")
+
+	// Grant reports the capping to the caller
+	require.NotNil(t, extendedSession.Grant)
+	assert.Equal(t, 15, extendedSession.Grant.RequestedMinutes)
+	assert.Equal(t, 10, extendedSession.Grant.GrantedMinutes)
+	assert.True(t, extendedSession.Grant.Capped)
+	assert.Equal(t, CapReasonRemainingTime, extendedSession.Grant.Reason)
+}
+
+func TestSessionManager_ExtendSession_ExtensionLimit(t *testing.T) {
+	storage := newMockStorage()
+	deviceRegistry := newMockDeviceRegistry()
+	driverRegistry := newMockDriverRegistry()
+	manager := NewSessionManager(storage, deviceRegistry, driverRegistry, nil, nil, nil, nil)
+
+	child := &Child{
+		ID:           "child1",
+		Name:         "Alice",
+		WeekdayLimit: 180,
+		WeekendLimit: 180,
+	}
+	storage.CreateChild(context.Background(), child)
+
+	driver := &mockDriver{name: "aqara"}
+	driverRegistry.addDriver(driver)
+	device := &mockDevice{id: "tv1", name: "TV", dtype: "tv", driver: "aqara"}
+	deviceRegistry.addDevice(device)
+
+	session, err := manager.StartSession(context.Background(), "tv1", []string{"child1"}, 10)
+	require.NoError(t, err)
+
+	// Plenty of time left, but a single extension is limited to 30 minutes
+	extended, err := manager.ExtendSession(context.Background(), session.ID, 60)
+	require.NoError(t, err)
+	assert.Equal(t, 40, extended.ExpectedDuration)
+
+	require.NotNil(t, extended.Grant)
+	assert.Equal(t, 60, extended.Grant.RequestedMinutes)
+	assert.Equal(t, 30, extended.Grant.GrantedMinutes)
+	assert.True(t, extended.Grant.Capped)
+	assert.Equal(t, CapReasonExtensionLimit, extended.Grant.Reason)
 }
 
 func TestSessionManager_StopSession(t *testing.T) {
@@ -712,6 +959,47 @@ func TestSessionManager_GetChildStatus(t *testing.T) {
 	}
 }
 
+func TestSessionManager_GetChildrenStatus(t *testing.T) {
+	ctx := context.Background()
+	storage := newMockStorage()
+	manager := NewSessionManager(storage, newMockDeviceRegistry(), newMockDriverRegistry(), nil, nil, nil, nil)
+
+	storage.CreateChild(ctx, &Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 120})
+	storage.CreateChild(ctx, &Child{ID: "child2", Name: "Bob", WeekdayLimit: 90, WeekendLimit: 90})
+	storage.IncrementDailyUsage(ctx, "child1", time.Now(), 25)
+	storage.CreateSession(ctx, &Session{
+		ID:               "session1",
+		ChildIDs:         []string{"child1", "child2"},
+		StartTime:        time.Now().Add(-10 * time.Minute),
+		ExpectedDuration: 30,
+		Status:           SessionStatusActive,
+	})
+
+	trackingPause := NewTrackingPauseService(newMockTrackingPauseStorage(storage), nil)
+	manager.SetTrackingPause(trackingPause)
+	_, err := trackingPause.Pause(ctx, "child2", "api", "vacation", nil)
+	require.NoError(t, err)
+
+	statuses, err := manager.GetChildrenStatus(ctx)
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+
+	// Every status matches the child's own status
+	for _, status := range statuses {
+		single, err := manager.GetChildStatus(ctx, status.Child.ID)
+		require.NoError(t, err)
+		assert.Equal(t, single, status, status.Child.Name)
+
+		if status.Child.ID == "child1" {
+			assert.Equal(t, 35, status.TodayUsed)
+			assert.Nil(t, status.TrackingPause)
+		} else {
+			assert.Equal(t, 10, status.TodayUsed)
+			assert.NotNil(t, status.TrackingPause)
+		}
+	}
+}
+
 func TestSessionManager_MultipleChildren(t *testing.T) {
 	storage := newMockStorage()
 	deviceRegistry := newMockDeviceRegistry()
@@ -0,0 +1,196 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNoMediaPlaying is returned when nothing is known to be playing on a session's device
+	ErrNoMediaPlaying = errors.New("no media is playing on the device")
+	// ErrMediaEndsInTime is returned when the current media ends before the session does
+	ErrMediaEndsInTime = errors.New("current media ends before the session")
+	// ErrInvalidMediaState is returned for media reports without a future end
+	ErrInvalidMediaState = errors.New("invalid media state")
+)
+
+// MediaState is what a device is playing (e.g., the current episode) and when it ends
+type MediaState struct {
+	DeviceID   string
+	Title      string    // Optional (e.g., "S02E05")
+	EndsAt     time.Time // When the current item ends if playback continues
+	Source     string    // What reported it (e.g., "home_assistant", "plex", a driver name)
+	ReportedAt time.Time
+}
+
+// MediaDriver is implemented by drivers that know what their device is playing
+// MediaState returns nil if nothing is playing.
+type MediaDriver interface {
+	MediaState(ctx context.Context, deviceID string) (*MediaState, error)
+}
+
+// MediaSessions is the session access the media service needs to extend sessions
+type MediaSessions interface {
+	GetSession(ctx context.Context, sessionID string) (*Session, error)
+	ExtendSession(ctx context.Context, sessionID string, additionalMinutes int) (*Session, error)
+}
+
+// MediaService tracks what devices are playing, and extends sessions until the current media ends
+// Integrations (e.g., a Home Assistant automation on a media_player, a Plex webhook) report media
+// state; drivers implementing MediaDriver are asked when nothing was reported. Reports are kept in
+// memory until the media ends or the integration clears them (e.g., on pause or stop).
+type MediaService struct {
+	sessions       MediaSessions
+	deviceRegistry DeviceRegistry
+	driverRegistry DriverRegistry
+	logger         *slog.Logger
+
+	mu     sync.Mutex
+	states map[string]*MediaState // By device ID
+}
+
+// NewMediaService creates a new media service
+func NewMediaService(sessions MediaSessions, deviceRegistry DeviceRegistry, driverRegistry DriverRegistry, logger *slog.Logger) *MediaService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &MediaService{
+		sessions:       sessions,
+		deviceRegistry: deviceRegistry,
+		driverRegistry: driverRegistry,
+		logger:         logger,
+		states:         make(map[string]*MediaState),
+	}
+}
+
+// Report records what a device is playing, replacing its previous report
+func (s *MediaService) Report(ctx context.Context, state *MediaState) (*MediaState, error) {
+	if _, err := s.deviceRegistry.Get(state.DeviceID); err != nil {
+		return nil, fmt.Errorf("failed to get device %s: %w", state.DeviceID, err)
+	}
+	now := Now()
+	if !state.EndsAt.After(now) {
+		return nil, fmt.Errorf("%w: media must end in the future", ErrInvalidMediaState)
+	}
+
+	reported := *state
+	reported.ReportedAt = now
+	s.mu.Lock()
+	s.states[state.DeviceID] = &reported
+	s.mu.Unlock()
+
+	s.logger.Debug("Media state reported",
+		"device_id", state.DeviceID,
+		"title", state.Title,
+		"ends_at", state.EndsAt,
+		"source", state.Source)
+	return &reported, nil
+}
+
+// Clear forgets what a device was playing (e.g., playback was paused or stopped)
+func (s *MediaService) Clear(deviceID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.states, deviceID)
+}
+
+// Current returns what a device is playing: its latest report, or else what its driver says
+// Returns ErrNoMediaPlaying if neither knows of media that has not ended yet.
+func (s *MediaService) Current(ctx context.Context, deviceID string) (*MediaState, error) {
+	now := Now()
+	s.mu.Lock()
+	state, ok := s.states[deviceID]
+	if ok && !state.EndsAt.After(now) {
+		delete(s.states, deviceID)
+		ok = false
+	}
+	s.mu.Unlock()
+	if ok {
+		return state, nil
+	}
+
+	state, err := s.driverMediaState(ctx, deviceID)
+	if err != nil {
+		return nil, err
+	}
+	if state == nil || !state.EndsAt.After(now) {
+		return nil, fmt.Errorf("%w: %s", ErrNoMediaPlaying, deviceID)
+	}
+	return state, nil
+}
+
+// driverMediaState asks the device's driver what it is playing, nil if the driver cannot tell
+func (s *MediaService) driverMediaState(ctx context.Context, deviceID string) (*MediaState, error) {
+	device, err := s.deviceRegistry.Get(deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device %s: %w", deviceID, err)
+	}
+	driver, err := s.driverRegistry.Get(device.GetDriver())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get driver %s for device %s: %w", device.GetDriver(), deviceID, err)
+	}
+	mediaDriver, ok := driver.(MediaDriver)
+	if !ok {
+		return nil, nil
+	}
+
+	state, err := mediaDriver.MediaState(ctx, deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get media state from driver %s: %w", driver.Name(), err)
+	}
+	if state != nil {
+		state.DeviceID = deviceID
+		if state.Source == "" {
+			state.Source = driver.Name()
+		}
+		if state.ReportedAt.IsZero() {
+			state.ReportedAt = Now()
+		}
+	}
+	return state, nil
+}
+
+// ExtendUntilMediaEnds extends a session so it ends when the media on its device ends
+// The extension is an ordinary ExtendSession call, so it is capped like any other: by the
+// children's remaining time, the per-extension maximum, initiator limits, policies and the
+// family budget (see the returned session's Grant). Returns ErrMediaEndsInTime if the
+// session already runs past the media's end.
+func (s *MediaService) ExtendUntilMediaEnds(ctx context.Context, sessionID string) (*Session, *MediaState, error) {
+	session, err := s.sessions.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !session.IsRunning() {
+		return nil, nil, ErrSessionNotActive
+	}
+
+	media, err := s.Current(ctx, session.DeviceID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sessionEnd := BusyUntil(session)
+	if !media.EndsAt.After(sessionEnd) {
+		return nil, media, fmt.Errorf("%w: media ends at %s, the session at %s", ErrMediaEndsInTime,
+			media.EndsAt.Format(time.RFC3339), sessionEnd.Format(time.RFC3339))
+	}
+	minutes := int(math.Ceil(media.EndsAt.Sub(sessionEnd).Minutes()))
+
+	s.logger.Info("Extending session until the current media ends",
+		"session_id", sessionID,
+		"device_id", session.DeviceID,
+		"title", media.Title,
+		"media_ends_at", media.EndsAt,
+		"additional_minutes", minutes)
+
+	extended, err := s.sessions.ExtendSession(ctx, sessionID, minutes)
+	if err != nil {
+		return nil, media, err
+	}
+	return extended, media, nil
+}
@@ -0,0 +1,119 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mediaDriver is a driver that knows what its device is playing
+type mediaDriver struct {
+	mockDriver
+	state *MediaState
+}
+
+func (d *mediaDriver) MediaState(ctx context.Context, deviceID string) (*MediaState, error) {
+	return d.state, nil
+}
+
+type mediaDriverRegistry map[string]DeviceDriver
+
+func (r mediaDriverRegistry) Get(name string) (DeviceDriver, error) {
+	return r[name], nil
+}
+
+// newMediaTestService returns a media service on a manager with the clock at noon, one child,
+// a TV (aqara driver) and a Kodi box whose driver reports media, and Alice's session on the TV
+func newMediaTestService(t *testing.T) (*MediaService, *Session, *mediaDriver, time.Time) {
+	now := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)
+	original := Now
+	Now = func() time.Time { return now }
+	t.Cleanup(func() { Now = original })
+
+	storage := newMockStorage()
+	deviceRegistry := newMockDeviceRegistry()
+	kodi := &mediaDriver{mockDriver: mockDriver{name: "kodi"}}
+	driverRegistry := mediaDriverRegistry{"aqara": &mockDriver{name: "aqara"}, "kodi": kodi}
+	manager := NewSessionManager(storage, deviceRegistry, driverRegistry, nil, nil, time.UTC, nil)
+
+	storage.CreateChild(context.Background(), &Child{ID: "child1", Name: "Alice", WeekdayLimit: 90, WeekendLimit: 90})
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", name: "TV", dtype: "tv", driver: "aqara"})
+	deviceRegistry.addDevice(&mockDevice{id: "kodi1", name: "Kodi", dtype: "tv", driver: "kodi"})
+
+	session, err := manager.StartSession(context.Background(), "tv1", []string{"child1"}, 60)
+	require.NoError(t, err)
+	return NewMediaService(manager, deviceRegistry, driverRegistry, nil), session, kodi, now
+}
+
+func TestMediaService_ExtendUntilMediaEnds(t *testing.T) {
+	service, session, _, now := newMediaTestService(t)
+	ctx := context.Background()
+
+	_, _, err := service.ExtendUntilMediaEnds(ctx, session.ID)
+	assert.ErrorIs(t, err, ErrNoMediaPlaying)
+
+	// The episode ends 12.5 minutes after the session; minutes are rounded up
+	_, err = service.Report(ctx, &MediaState{DeviceID: "tv1", Title: "S02E05", EndsAt: now.Add(72*time.Minute + 30*time.Second), Source: "plex"})
+	require.NoError(t, err)
+	extended, media, err := service.ExtendUntilMediaEnds(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "S02E05", media.Title)
+	assert.Equal(t, 73, extended.ExpectedDuration)
+	assert.False(t, extended.Grant.Capped)
+
+	// Now the session outlasts the episode
+	_, _, err = service.ExtendUntilMediaEnds(ctx, session.ID)
+	assert.ErrorIs(t, err, ErrMediaEndsInTime)
+}
+
+func TestMediaService_ExtendUntilMediaEnds_CappedByRemainingTime(t *testing.T) {
+	service, session, _, now := newMediaTestService(t)
+	ctx := context.Background()
+
+	// Alice has 30 of her 90 minutes left: the first episode takes 20 of them
+	_, err := service.Report(ctx, &MediaState{DeviceID: "tv1", EndsAt: now.Add(80 * time.Minute)})
+	require.NoError(t, err)
+	extended, _, err := service.ExtendUntilMediaEnds(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 80, extended.ExpectedDuration)
+	assert.Equal(t, 20, extended.Grant.GrantedMinutes)
+
+	// And a movie ending in two hours only gets the last 10
+	_, err = service.Report(ctx, &MediaState{DeviceID: "tv1", EndsAt: now.Add(120 * time.Minute)})
+	require.NoError(t, err)
+	Now = func() time.Time { return now.Add(time.Minute) } // Past the extension cooldown
+	extended, _, err = service.ExtendUntilMediaEnds(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 90, extended.ExpectedDuration)
+	assert.True(t, extended.Grant.Capped)
+	assert.Equal(t, CapReasonRemainingTime, extended.Grant.Reason)
+}
+
+func TestMediaService_Current(t *testing.T) {
+	service, _, kodi, now := newMediaTestService(t)
+	ctx := context.Background()
+
+	_, err := service.Report(ctx, &MediaState{DeviceID: "tv1", EndsAt: now.Add(-time.Minute)})
+	assert.ErrorIs(t, err, ErrInvalidMediaState)
+
+	// Reports are forgotten once the media ends or they are cleared
+	_, err = service.Report(ctx, &MediaState{DeviceID: "tv1", EndsAt: now.Add(10 * time.Minute)})
+	require.NoError(t, err)
+	_, err = service.Current(ctx, "tv1")
+	require.NoError(t, err)
+	service.Clear("tv1")
+	_, err = service.Current(ctx, "tv1")
+	assert.ErrorIs(t, err, ErrNoMediaPlaying)
+
+	// Drivers implementing MediaDriver are asked when nothing was reported
+	_, err = service.Current(ctx, "kodi1")
+	assert.ErrorIs(t, err, ErrNoMediaPlaying)
+	kodi.state = &MediaState{Title: "Bluey", EndsAt: now.Add(7 * time.Minute)}
+	media, err := service.Current(ctx, "kodi1")
+	require.NoError(t, err)
+	assert.Equal(t, "kodi1", media.DeviceID)
+	assert.Equal(t, "kodi", media.Source)
+}
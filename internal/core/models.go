@@ -2,6 +2,7 @@ package core
 
 import (
 	"errors"
+	"fmt"
 	"time"
 )
 
@@ -24,15 +25,38 @@ type Child struct {
 	WeekdayLimit    int    // minutes per weekday
 	WeekendLimit    int    // minutes per weekend day
 	BreakRule       *BreakRule
-	DowntimeEnabled bool // whether downtime schedule is enforced for this child
+	DowntimeEnabled bool          // whether downtime schedule is enforced for this child
+	AllowedDevices  []string      // device IDs the child may use; empty means all devices
+	Timezone        string        // IANA timezone override (e.g., "America/New_York"); empty uses the server timezone
+	GraceMinutes    int           // minutes a session may run past the daily limit before the hard stop (0 = disabled)
+	ScheduledLimits []LimitChange // pending limit changes, oldest effective date first (loaded by storage, read-only)
+	Birthdate       *time.Time    // date of birth (date key; only the date is used); optional, for age-based limit profiles
 	CreatedAt       time.Time
 	UpdatedAt       time.Time
 }
 
 // BreakRule defines mandatory break periods
 type BreakRule struct {
-	BreakAfterMinutes    int // require break after this many minutes
-	BreakDurationMinutes int // break must last this many minutes
+	BreakAfterMinutes    int    // require break after this many minutes
+	BreakDurationMinutes int    // break must last this many minutes
+	Action               string // how the break is enforced (BreakAction*); empty means BreakActionWarn
+}
+
+// Break enforcement actions
+// A device can override the child's action with the "break_action" parameter
+const (
+	BreakActionWarn  = "warn"  // Trigger the driver's warning action (default)
+	BreakActionBreak = "break" // Trigger the driver's dedicated break action; falls back to warn if unsupported
+	BreakActionLock  = "lock"  // Lock the device (driver stop) for the break and unlock it (driver start) when it ends
+)
+
+// IsValidBreakAction returns true if action is a known break action or empty (default)
+func IsValidBreakAction(action string) bool {
+	switch action {
+	case "", BreakActionWarn, BreakActionBreak, BreakActionLock:
+		return true
+	}
+	return false
 }
 
 // Session represents an active or completed screen-time session
@@ -42,15 +66,68 @@ type Session struct {
 	DeviceID         string // specific device identifier
 	ChildIDs         []string
 	StartTime        time.Time
-	ExpectedDuration int // minutes
+	ExpectedDuration int  // minutes
+	ActualDuration   *int // minutes the session ran, set when it ends (nil while running)
 	Status           SessionStatus
 	LastBreakAt      *time.Time
 	BreakEndsAt      *time.Time
 	WarningSentAt    *time.Time // tracks when time-remaining warning was sent
 	LastExtendedAt   *time.Time // tracks when session was last extended (for rate limiting)
+	LastActivityAt   *time.Time // last user activity reported by the device agent (nil if never reported)
+	BreakMinutes     int        // total minutes of completed mandatory breaks (not charged)
+	BreakAction      string     // enforcement action applied to the break in progress ("" when not on a break)
+	BreakExempt      bool       // parent-approved opt-out of break rules for this session
+	OverrideDowntime bool       // parent override: the session may run during downtime
+	OverrideLimits   bool       // parent override: the session is not capped by the daily limit
+	GraceEndsAt      *time.Time // hard stop of a session running past the daily limit (nil when not in grace)
 	IsMovieSession   bool       // If true, does not count against individual quotas
+	Activity         string     // exempt activity the session is for (see ExemptActivity); empty for regular sessions
+	EndReason        string     // why the session ended (SessionEnd* constants); empty while running
+	InitiatorType    string     // who started the session (Initiator* constants); empty for older sessions
+	InitiatorID      string     // identifier of the initiator (e.g., "telegram:alice", a child ID)
+	Notes            string     // notes on the session, one per line (e.g., what was watched)
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
+
+	// Grant describes the result of the StartSession/ExtendSession call that returned this session
+	// It is not persisted and is nil for sessions loaded from storage
+	Grant *DurationGrant
+
+	// Merged is set when StartSession added the children to the session already running on the
+	// device instead of starting one (ConflictMerge). It is not persisted either.
+	Merged bool
+}
+
+// Reasons a requested duration can be capped
+const (
+	CapReasonRemainingTime  = "remaining_time"  // Child's remaining daily time is lower than requested
+	CapReasonExtensionLimit = "extension_limit" // Extension exceeds the per-request maximum
+	CapReasonInitiatorLimit = "initiator_limit" // Session would run longer than its initiator may make it
+	CapReasonPolicy         = "policy"          // Session would run longer than a session policy allows
+	CapReasonFamilyBudget   = "family_budget"   // Household's shared daily budget is lower than requested
+	CapReasonActivity       = "activity_budget" // Exempt activity's daily budget is lower than requested
+)
+
+// DurationGrant compares the requested and granted minutes of a start or extend request
+type DurationGrant struct {
+	RequestedMinutes int
+	GrantedMinutes   int
+	Capped           bool
+	Reason           string // Empty if not capped
+	Policy           string // Policy that capped the duration (CapReasonPolicy only)
+}
+
+// NewDurationGrant creates a grant, marking it capped when less than requested was granted
+func NewDurationGrant(requested, granted int, reason string) *DurationGrant {
+	grant := &DurationGrant{
+		RequestedMinutes: requested,
+		GrantedMinutes:   granted,
+	}
+	if granted < requested {
+		grant.Capped = true
+		grant.Reason = reason
+	}
+	return grant
 }
 
 // DailyUsage tracks a child's usage for a specific day
@@ -71,6 +148,9 @@ var (
 	ErrInvalidWeekdayLimit = errors.New("weekday limit must be positive")
 	ErrInvalidWeekendLimit = errors.New("weekend limit must be positive")
 	ErrInvalidBreakRule    = errors.New("invalid break rule configuration")
+	ErrInvalidTimezone     = errors.New("invalid timezone")
+	ErrInvalidGraceMinutes = fmt.Errorf("grace minutes must be between 0 and %d", MaxGraceMinutes)
+	ErrInvalidBirthdate    = errors.New("birthdate cannot be in the future")
 	ErrInvalidDuration     = errors.New("duration must be positive")
 	ErrInvalidDeviceType   = errors.New("device type cannot be empty")
 	ErrNoChildren          = errors.New("session must have at least one child")
@@ -80,8 +160,43 @@ var (
 	ErrChildNotFound       = errors.New("child not found")
 	ErrExtensionTooSoon    = errors.New("extension request too soon after previous extension")
 	ErrDowntimeActive      = errors.New("session cannot be started during downtime period")
+	ErrDeviceNotAllowed    = errors.New("child is not allowed to use this device")
+	ErrChildNotInSession   = errors.New("child is not in this session")
+	ErrLastChildInSession  = errors.New("cannot remove the last child from a session")
 )
 
+// InsufficientTimeError describes which child blocked a session because of the daily limit
+// It matches ErrInsufficientTime with errors.Is
+type InsufficientTimeError struct {
+	ChildID          string
+	ChildName        string
+	RemainingMinutes int // Remaining daily time (including committed time of the extended session)
+	LimitMinutes     int // Total available today (base limit plus rewards)
+	UsedMinutes      int
+	RequestedMinutes int
+}
+
+func (e *InsufficientTimeError) Error() string {
+	return fmt.Sprintf("%s: child %s has %d minutes remaining (requested %d)",
+		ErrInsufficientTime, e.ChildName, e.RemainingMinutes, e.RequestedMinutes)
+}
+
+func (e *InsufficientTimeError) Unwrap() error {
+	return ErrInsufficientTime
+}
+
+// newInsufficientTimeError builds an InsufficientTimeError from a remaining-time calculation
+func newInsufficientTimeError(child *Child, remaining *RemainingTimeResult, requested int) *InsufficientTimeError {
+	return &InsufficientTimeError{
+		ChildID:          child.ID,
+		ChildName:        child.Name,
+		RemainingMinutes: remaining.RemainingTotal,
+		LimitMinutes:     remaining.Available.TotalAvailable,
+		UsedMinutes:      remaining.Consumed.TotalConsumed,
+		RequestedMinutes: requested,
+	}
+}
+
 // Movie time errors
 var (
 	ErrNotWeekend           = errors.New("movie time is only available on weekends")
@@ -107,17 +222,78 @@ func (c *Child) Validate() error {
 		if c.BreakRule.BreakAfterMinutes <= 0 || c.BreakRule.BreakDurationMinutes <= 0 {
 			return ErrInvalidBreakRule
 		}
+		if !IsValidBreakAction(c.BreakRule.Action) {
+			return ErrInvalidBreakRule
+		}
+	}
+	if err := ValidateTimezone(c.Timezone); err != nil {
+		return err
+	}
+	if c.GraceMinutes < 0 || c.GraceMinutes > MaxGraceMinutes {
+		return ErrInvalidGraceMinutes
+	}
+	if c.Birthdate != nil && c.Birthdate.Format("2006-01-02") > Now().Format("2006-01-02") {
+		return ErrInvalidBirthdate
 	}
 	return nil
 }
 
+// Location returns the child's timezone, or fallback if the child has no override
+func (c *Child) Location(fallback *time.Location) *time.Location {
+	return ResolveTimezone(fallback, c.Timezone)
+}
+
 // GetDailyLimit returns the appropriate daily limit based on the day of week
+// Scheduled limit changes effective on the date take precedence over the current limits
 func (c *Child) GetDailyLimit(date time.Time) int {
+	weekdayLimit, weekendLimit := c.WeekdayLimit, c.WeekendLimit
+	for _, change := range c.ScheduledLimits {
+		if change.EffectiveOn(date) {
+			weekdayLimit, weekendLimit = change.WeekdayLimit, change.WeekendLimit
+		}
+	}
+
 	weekday := date.Weekday()
 	if weekday == time.Saturday || weekday == time.Sunday {
-		return c.WeekendLimit
+		return weekendLimit
+	}
+	return weekdayLimit
+}
+
+// AgeOn returns the child's age in full years on date (a date key), or 0 without a birthdate
+// Children born on February 29 turn a year older on March 1 in common years.
+func (c *Child) AgeOn(date time.Time) int {
+	if c.Birthdate == nil {
+		return 0
+	}
+	day := LimitChangeDate(date.Date())
+	age := day.Year() - c.Birthdate.Year()
+	if day.Before(c.Birthday(age)) {
+		age--
+	}
+	return age
+}
+
+// Birthday returns the date (a UTC date key) the child turns age
+func (c *Child) Birthday(age int) time.Time {
+	if c.Birthdate == nil {
+		return time.Time{}
+	}
+	return LimitChangeDate(c.Birthdate.Year()+age, c.Birthdate.Month(), c.Birthdate.Day())
+}
+
+// CanUseDevice returns true if the child is allowed to use the device
+// A child without an allow-list may use every device
+func (c *Child) CanUseDevice(deviceID string) bool {
+	if len(c.AllowedDevices) == 0 {
+		return true
+	}
+	for _, allowed := range c.AllowedDevices {
+		if allowed == deviceID {
+			return true
+		}
 	}
-	return c.WeekdayLimit
+	return false
 }
 
 // Validate validates a Session
@@ -139,17 +315,23 @@ func (s *Session) IsActive() bool {
 	return s.Status == SessionStatusActive
 }
 
+// IsRunning returns true if the session has not ended (active or paused for a break)
+func (s *Session) IsRunning() bool {
+	return s.Status == SessionStatusActive || s.Status == SessionStatusPaused
+}
+
 // IsInBreak returns true if the session is currently in a mandatory break
 func (s *Session) IsInBreak() bool {
 	if s.BreakEndsAt == nil {
 		return false
 	}
-	return time.Now().Before(*s.BreakEndsAt)
+	return Now().Before(*s.BreakEndsAt)
 }
 
 // NeedsBreak checks if a break is needed based on the break rule and last break time
+// Break-exempt sessions never need a break
 func (s *Session) NeedsBreak(breakRule *BreakRule) bool {
-	if breakRule == nil {
+	if breakRule == nil || s.BreakExempt {
 		return false
 	}
 
@@ -161,10 +343,34 @@ func (s *Session) NeedsBreak(breakRule *BreakRule) bool {
 		timeSince = s.StartTime
 	}
 
-	minutesSince := int(time.Since(timeSince).Minutes())
+	minutesSince := int(Now().Sub(timeSince).Minutes())
 	return minutesSince >= breakRule.BreakAfterMinutes
 }
 
+// RecordActivity updates LastActivityAt from an agent report of how long the device has been idle
+// Activity is never moved backwards or before the session start
+// Returns true if LastActivityAt changed
+func (s *Session) RecordActivity(idle time.Duration, now time.Time) bool {
+	lastActivity := now.Add(-idle)
+	if lastActivity.Before(s.StartTime) {
+		lastActivity = s.StartTime
+	}
+	if s.LastActivityAt != nil && !lastActivity.After(*s.LastActivityAt) {
+		return false
+	}
+	s.LastActivityAt = &lastActivity
+	return true
+}
+
+// IdleDuration returns how long the device has reported no activity
+// Returns 0 if the device never reported activity
+func (s *Session) IdleDuration(now time.Time) time.Duration {
+	if s.LastActivityAt == nil {
+		return 0
+	}
+	return now.Sub(*s.LastActivityAt)
+}
+
 // CalculateRemainingMinutes calculates remaining time dynamically
 // This is the authoritative calculation based on StartTime + ExpectedDuration
 func (s *Session) CalculateRemainingMinutes() int {
@@ -173,7 +379,7 @@ func (s *Session) CalculateRemainingMinutes() int {
 	}
 
 	endTime := s.StartTime.Add(time.Duration(s.ExpectedDuration) * time.Minute)
-	remaining := int(time.Until(endTime).Minutes())
+	remaining := int(endTime.Sub(Now()).Minutes())
 
 	if remaining < 0 {
 		return 0
@@ -220,7 +426,9 @@ type SessionUsageRecord struct {
 	LastBreakAt      *time.Time
 	BreakEndsAt      *time.Time
 	WarningSentAt    *time.Time
-	IsMovieSession   bool // If true, does not count against individual quotas
+	BreakMinutes     int    // Total minutes of completed mandatory breaks (not charged)
+	IsMovieSession   bool   // If true, does not count against individual quotas
+	Activity         string // Exempt activity, not charged either
 	CreatedAt        time.Time
 	UpdatedAt        time.Time
 }
@@ -235,7 +443,7 @@ func (s *SessionUsageRecord) IsInBreak() bool {
 	if s.BreakEndsAt == nil {
 		return false
 	}
-	return time.Now().Before(*s.BreakEndsAt)
+	return Now().Before(*s.BreakEndsAt)
 }
 
 // NeedsBreak checks if a break is needed based on the break rule and last break time
@@ -251,7 +459,7 @@ func (s *SessionUsageRecord) NeedsBreak(breakRule *BreakRule) bool {
 		timeSince = s.StartTime
 	}
 
-	minutesSince := int(time.Since(timeSince).Minutes())
+	minutesSince := int(Now().Sub(timeSince).Minutes())
 	return minutesSince >= breakRule.BreakAfterMinutes
 }
 
@@ -94,6 +94,65 @@ func TestChild_Validate(t *testing.T) {
 			},
 			wantErr: ErrInvalidBreakRule,
 		},
+		{
+			name: "invalid break rule - unknown action",
+			child: Child{
+				ID:           "child1",
+				Name:         "Alice",
+				WeekdayLimit: 60,
+				WeekendLimit: 120,
+				BreakRule: &BreakRule{
+					BreakAfterMinutes:    30,
+					BreakDurationMinutes: 10,
+					Action:               "shutdown",
+				},
+			},
+			wantErr: ErrInvalidBreakRule,
+		},
+		{
+			name: "valid timezone override",
+			child: Child{
+				ID:           "child1",
+				Name:         "Alice",
+				WeekdayLimit: 60,
+				WeekendLimit: 120,
+				Timezone:     "America/New_York",
+			},
+			wantErr: nil,
+		},
+		{
+			name: "invalid timezone override",
+			child: Child{
+				ID:           "child1",
+				Name:         "Alice",
+				WeekdayLimit: 60,
+				WeekendLimit: 120,
+				Timezone:     "Mars/Olympus_Mons",
+			},
+			wantErr: ErrInvalidTimezone,
+		},
+		{
+			name: "grace allowance",
+			child: Child{
+				ID:           "child1",
+				Name:         "Alice",
+				WeekdayLimit: 60,
+				WeekendLimit: 120,
+				GraceMinutes: 5,
+			},
+			wantErr: nil,
+		},
+		{
+			name: "grace allowance too long",
+			child: Child{
+				ID:           "child1",
+				Name:         "Alice",
+				WeekdayLimit: 60,
+				WeekendLimit: 120,
+				GraceMinutes: MaxGraceMinutes + 1,
+			},
+			wantErr: ErrInvalidGraceMinutes,
+		},
 	}
 
 	for _, tt := range tests {
@@ -146,6 +205,17 @@ func TestChild_GetDailyLimit(t *testing.T) {
 	}
 }
 
+func TestChild_CanUseDevice(t *testing.T) {
+	unrestricted := Child{ID: "child1", Name: "Alice"}
+	assert.True(t, unrestricted.CanUseDevice("tv1"))
+	assert.True(t, unrestricted.CanUseDevice("ps5"))
+
+	restricted := Child{ID: "child2", Name: "Bob", AllowedDevices: []string{"tv1", "ipad1"}}
+	assert.True(t, restricted.CanUseDevice("tv1"))
+	assert.True(t, restricted.CanUseDevice("ipad1"))
+	assert.False(t, restricted.CanUseDevice("ps5"))
+}
+
 func TestSession_Validate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -346,6 +416,15 @@ func TestSession_NeedsBreak(t *testing.T) {
 			breakRule: breakRule,
 			wantBreak: false,
 		},
+		{
+			name: "break-exempt session",
+			session: Session{
+				StartTime:   now.Add(-90 * time.Minute),
+				BreakExempt: true,
+			},
+			breakRule: breakRule,
+			wantBreak: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -356,6 +435,27 @@ func TestSession_NeedsBreak(t *testing.T) {
 	}
 }
 
+func TestSession_RecordActivity(t *testing.T) {
+	now := time.Now()
+	session := Session{StartTime: now.Add(-30 * time.Minute)}
+
+	// No activity reported yet
+	assert.Equal(t, time.Duration(0), session.IdleDuration(now))
+
+	// Idle for 5 minutes
+	assert.True(t, session.RecordActivity(5*time.Minute, now))
+	assert.Equal(t, 5*time.Minute, session.IdleDuration(now))
+
+	// An older report does not move activity backwards
+	assert.False(t, session.RecordActivity(10*time.Minute, now))
+	assert.Equal(t, 5*time.Minute, session.IdleDuration(now))
+
+	// Idle since before the session started counts from the start
+	other := Session{StartTime: now.Add(-10 * time.Minute)}
+	assert.True(t, other.RecordActivity(time.Hour, now))
+	assert.Equal(t, other.StartTime, *other.LastActivityAt)
+}
+
 func timePtr(t time.Time) *time.Time {
 	return &t
 }
@@ -27,6 +27,9 @@ type MovieTimeService struct {
 	deviceRegistry DeviceRegistry
 	driverRegistry DriverRegistry
 	config         *config.MovieTimeConfig
+	lockdown       *LockdownService      // Optional: blocks movie time during a lockdown
+	timeouts       *DriverTimeouts       // Bounds and records driver calls (DefaultDriverTimeout without)
+	activity       *ChildActivityService // Optional: records movie time in children's activity feeds
 	timezone       *time.Location
 	logger         *slog.Logger
 }
@@ -75,7 +78,7 @@ func NewMovieTimeService(
 
 // GetAvailability returns the current movie time availability status
 func (s *MovieTimeService) GetAvailability(ctx context.Context) (*MovieTimeAvailability, error) {
-	now := time.Now().In(s.timezone)
+	now := Now().In(s.timezone)
 	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, s.timezone)
 
 	result := &MovieTimeAvailability{
@@ -137,7 +140,7 @@ func (s *MovieTimeService) GetAvailability(ctx context.Context) (*MovieTimeAvail
 
 		if now.Before(breakEndTime) {
 			result.BreakRequired = true
-			result.BreakMinutesLeft = int(time.Until(breakEndTime).Minutes())
+			result.BreakMinutesLeft = int(breakEndTime.Sub(Now()).Minutes())
 			if result.BreakMinutesLeft < 0 {
 				result.BreakMinutesLeft = 0
 			}
@@ -153,12 +156,35 @@ func (s *MovieTimeService) GetAvailability(ctx context.Context) (*MovieTimeAvail
 	return result, nil
 }
 
+// SetLockdown sets the lockdown service that blocks movie time during a lockdown
+func (s *MovieTimeService) SetLockdown(lockdown *LockdownService) {
+	s.lockdown = lockdown
+}
+
+// SetDriverTimeouts sets the per-driver timeouts of movie time's driver calls
+func (s *MovieTimeService) SetDriverTimeouts(timeouts *DriverTimeouts) {
+	s.timeouts = timeouts
+}
+
+// SetActivity sets the service recording children's activity feeds
+func (s *MovieTimeService) SetActivity(activity *ChildActivityService) {
+	s.activity = activity
+}
+
 // StartMovieTime starts a new movie time session
 func (s *MovieTimeService) StartMovieTime(ctx context.Context, deviceID, initiatorChildID string) (*Session, error) {
 	s.logger.Info("Starting movie time",
 		"device_id", deviceID,
 		"initiator_child_id", initiatorChildID)
 
+	if s.lockdown != nil {
+		if err := s.lockdown.Check(ctx); err != nil {
+			s.logger.Warn("Movie time rejected by lockdown",
+				"error", err)
+			return nil, err
+		}
+	}
+
 	// Check availability first
 	availability, err := s.GetAvailability(ctx)
 	if err != nil {
@@ -218,10 +244,12 @@ func (s *MovieTimeService) StartMovieTime(ctx context.Context, deviceID, initiat
 		childIDs[i] = child.ID
 	}
 
-	now := time.Now()
+	now := Now()
 	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, s.timezone)
 
 	// Create session with IsMovieSession flag
+	// Movie time has its own duration, so the initiator is recorded but its limit does not apply
+	initiator := InitiatorFromContext(ctx)
 	session := &Session{
 		ID:               idgen.NewSession(),
 		DeviceType:       device.GetType(),
@@ -231,6 +259,8 @@ func (s *MovieTimeService) StartMovieTime(ctx context.Context, deviceID, initiat
 		ExpectedDuration: s.config.GetDuration(),
 		Status:           SessionStatusActive,
 		IsMovieSession:   true,
+		InitiatorType:    initiator.Type,
+		InitiatorID:      initiator.ID,
 		CreatedAt:        now,
 		UpdatedAt:        now,
 	}
@@ -254,7 +284,9 @@ func (s *MovieTimeService) StartMovieTime(ctx context.Context, deviceID, initiat
 	}
 
 	// Start session on device
-	if err := driver.StartSession(ctx, session); err != nil {
+	if err := s.timeouts.Call(ctx, driver.Name(), DriverActionStart, session, func(ctx context.Context) error {
+		return driver.StartSession(ctx, session)
+	}); err != nil {
 		s.logger.Error("Driver failed to start session",
 			"session_id", session.ID,
 			"driver", driver.Name(),
@@ -282,16 +314,20 @@ func (s *MovieTimeService) StartMovieTime(ctx context.Context, deviceID, initiat
 		// Don't fail the session - usage tracking is secondary
 	}
 
-	// Increment session count for all children
-	for _, childID := range childIDs {
-		if err := s.storage.IncrementSessionCountSummary(ctx, childID, today); err != nil {
+	// Increment session count for all children (on each child's calendar day)
+	for _, child := range allChildren {
+		if err := s.storage.IncrementSessionCountSummary(ctx, child.ID, UsageDate(now, child.Location(s.timezone), s.timezone)); err != nil {
 			s.logger.Warn("Failed to increment session count summary",
 				"session_id", session.ID,
-				"child_id", childID,
+				"child_id", child.ID,
 				"error", err)
 		}
 	}
 
+	if s.activity != nil {
+		s.activity.SessionStarted(ctx, session)
+	}
+
 	s.logger.Info("Movie time started successfully",
 		"session_id", session.ID,
 		"device_id", deviceID,
@@ -303,7 +339,7 @@ func (s *MovieTimeService) StartMovieTime(ctx context.Context, deviceID, initiat
 
 // MarkMovieTimeUsed marks movie time as used when the session ends
 func (s *MovieTimeService) MarkMovieTimeUsed(ctx context.Context, sessionID string) error {
-	now := time.Now().In(s.timezone)
+	now := Now().In(s.timezone)
 	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, s.timezone)
 
 	usage, err := s.storage.GetMovieTimeUsage(ctx, today)
@@ -0,0 +1,103 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrInvalidOverrideScope is returned for an override scope other than the Override* scopes
+var ErrInvalidOverrideScope = errors.New("invalid override scope")
+
+// Parent override scopes
+const (
+	OverrideDowntime = "downtime" // The session may start, be extended and keep running during downtime
+	OverrideLimits   = "limits"   // The session is not rejected or capped by the children's remaining time
+)
+
+// Override is a parent's decision to let a session ignore some of the children's rules
+// It only applies to the session it is requested for: the children's downtime and limit
+// settings are left unchanged.
+type Override struct {
+	Downtime bool
+	Limits   bool
+	By       string // Who requested the override (e.g., "telegram:12345", "api"), for the audit log
+	Reason   string // Optional explanation, for the audit log
+}
+
+// ParseOverride builds an override from scope names (OverrideDowntime, OverrideLimits)
+// Returns ErrInvalidOverrideScope for unknown scopes
+func ParseOverride(scopes []string, by, reason string) (Override, error) {
+	override := Override{By: by, Reason: reason}
+	for _, scope := range scopes {
+		switch scope {
+		case OverrideDowntime:
+			override.Downtime = true
+		case OverrideLimits:
+			override.Limits = true
+		default:
+			return Override{}, fmt.Errorf("%w: %q (must be %s or %s)", ErrInvalidOverrideScope, scope, OverrideDowntime, OverrideLimits)
+		}
+	}
+	return override, nil
+}
+
+// IsSet returns true if the override ignores at least one rule
+func (o Override) IsSet() bool {
+	return o.Downtime || o.Limits
+}
+
+// Scopes returns the names of the overridden rules
+func (o Override) Scopes() []string {
+	var scopes []string
+	if o.Downtime {
+		scopes = append(scopes, OverrideDowntime)
+	}
+	if o.Limits {
+		scopes = append(scopes, OverrideLimits)
+	}
+	return scopes
+}
+
+// String describes the override for logs and the audit log
+func (o Override) String() string {
+	description := "override of " + strings.Join(o.Scopes(), " and ")
+	if o.Reason != "" {
+		description += ": " + o.Reason
+	}
+	return description
+}
+
+type overrideKey struct{}
+
+// WithOverride returns a context that makes StartSession and ExtendSession apply the override
+// Only the parent API and parent bot flows may set it; child requests never carry one.
+func WithOverride(ctx context.Context, override Override) context.Context {
+	return context.WithValue(ctx, overrideKey{}, override)
+}
+
+// OverrideFromContext returns the override set by WithOverride, or an empty one
+func OverrideFromContext(ctx context.Context) Override {
+	override, _ := ctx.Value(overrideKey{}).(Override)
+	return override
+}
+
+type breakExemptKey struct{}
+
+// WithBreakExempt returns a context that makes StartSession create a session without mandatory breaks
+// Like WithOverride, only parent flows may set it.
+func WithBreakExempt(ctx context.Context) context.Context {
+	return context.WithValue(ctx, breakExemptKey{}, true)
+}
+
+// BreakExemptFromContext returns true if the context was set by WithBreakExempt
+func BreakExemptFromContext(ctx context.Context) bool {
+	exempt, _ := ctx.Value(breakExemptKey{}).(bool)
+	return exempt
+}
+
+// OverrideScopes returns the override scopes recorded on the session
+func (s *Session) OverrideScopes() []string {
+	return Override{Downtime: s.OverrideDowntime, Limits: s.OverrideLimits}.Scopes()
+}
@@ -0,0 +1,149 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOverride(t *testing.T) {
+	override, err := ParseOverride([]string{OverrideDowntime}, "telegram:1", "movie night")
+	require.NoError(t, err)
+	assert.True(t, override.Downtime)
+	assert.False(t, override.Limits)
+	assert.True(t, override.IsSet())
+	assert.Equal(t, []string{OverrideDowntime}, override.Scopes())
+	assert.Equal(t, "override of downtime: movie night", override.String())
+
+	override, err = ParseOverride([]string{OverrideLimits, OverrideDowntime}, "api", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{OverrideDowntime, OverrideLimits}, override.Scopes())
+
+	override, err = ParseOverride(nil, "api", "")
+	require.NoError(t, err)
+	assert.False(t, override.IsSet())
+
+	_, err = ParseOverride([]string{"everything"}, "api", "")
+	assert.ErrorIs(t, err, ErrInvalidOverrideScope)
+}
+
+func TestOverrideFromContext(t *testing.T) {
+	assert.False(t, OverrideFromContext(context.Background()).IsSet())
+
+	ctx := WithOverride(context.Background(), Override{Limits: true, By: "api"})
+	override := OverrideFromContext(ctx)
+	assert.True(t, override.Limits)
+	assert.Equal(t, "api", override.By)
+}
+
+// newOverrideTestManager returns a manager with downtime from 08:00 to 17:00 and the clock at noon
+func newOverrideTestManager(t *testing.T) (*SessionManager, *mockStorage, *mockAuditStorage) {
+	now := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)
+	original := Now
+	Now = func() time.Time { return now }
+	t.Cleanup(func() { Now = original })
+
+	storage := newMockStorage()
+	deviceRegistry := newMockDeviceRegistry()
+	driverRegistry := newMockDriverRegistry()
+	downtime := NewDowntimeService(newUnifiedSchedule(8, 0, 17, 0), time.UTC)
+	manager := NewSessionManager(storage, deviceRegistry, driverRegistry, nil, downtime, time.UTC, nil)
+	audit := &mockAuditStorage{}
+	manager.SetAudit(NewAuditService(audit, nil))
+
+	storage.CreateChild(context.Background(), &Child{
+		ID:              "child1",
+		Name:            "Alice",
+		WeekdayLimit:    60,
+		WeekendLimit:    60,
+		DowntimeEnabled: true,
+	})
+	driverRegistry.addDriver(&mockDriver{name: "aqara"})
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", name: "TV", dtype: "tv", driver: "aqara"})
+	return manager, storage, audit
+}
+
+func TestSessionManager_StartSession_DowntimeOverride(t *testing.T) {
+	manager, storage, audit := newOverrideTestManager(t)
+
+	_, err := manager.StartSession(context.Background(), "tv1", []string{"child1"}, 30)
+	assert.ErrorIs(t, err, ErrDowntimeActive)
+
+	// A limits override does not cover downtime
+	ctx := WithOverride(context.Background(), Override{Limits: true, By: "api"})
+	_, err = manager.StartSession(ctx, "tv1", []string{"child1"}, 30)
+	assert.ErrorIs(t, err, ErrDowntimeActive)
+
+	ctx = WithOverride(context.Background(), Override{Downtime: true, By: "telegram:1", Reason: "movie night"})
+	session, err := manager.StartSession(ctx, "tv1", []string{"child1"}, 30)
+	require.NoError(t, err)
+	assert.True(t, session.OverrideDowntime)
+	assert.False(t, session.OverrideLimits)
+
+	stored, err := storage.GetSession(context.Background(), session.ID)
+	require.NoError(t, err)
+	assert.True(t, stored.OverrideDowntime)
+
+	// The override only applies to the session: the child's downtime stays enabled
+	child, err := storage.GetChild(context.Background(), "child1")
+	require.NoError(t, err)
+	assert.True(t, child.DowntimeEnabled)
+
+	require.Len(t, audit.entries, 1)
+	assert.Equal(t, AuditSessionOverride, audit.entries[0].Action)
+	assert.Equal(t, "child1", audit.entries[0].ChildID)
+	assert.Equal(t, "telegram:1", audit.entries[0].Actor)
+	assert.Contains(t, audit.entries[0].Details, "movie night")
+
+	// Extending needs its own override, so a child cannot extend the session into downtime
+	Now = func() time.Time { return time.Date(2026, time.March, 10, 12, 5, 0, 0, time.UTC) }
+	_, err = manager.ExtendSession(context.Background(), session.ID, 10)
+	assert.ErrorIs(t, err, ErrDowntimeActive)
+
+	extended, err := manager.ExtendSession(ctx, session.ID, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 40, extended.ExpectedDuration)
+	assert.Len(t, audit.entries, 2)
+}
+
+func TestSessionManager_StartSession_LimitsOverride(t *testing.T) {
+	manager, storage, audit := newOverrideTestManager(t)
+	manager.downtime = nil
+
+	// All time used
+	storage.IncrementDailyUsage(context.Background(), "child1", Now(), 60)
+
+	_, err := manager.StartSession(context.Background(), "tv1", []string{"child1"}, 30)
+	assert.ErrorIs(t, err, ErrInsufficientTime)
+
+	ctx := WithOverride(context.Background(), Override{Limits: true, By: "api"})
+	session, err := manager.StartSession(ctx, "tv1", []string{"child1"}, 30)
+	require.NoError(t, err)
+	assert.Equal(t, 30, session.ExpectedDuration, "limits override is not capped by remaining time")
+	assert.True(t, session.OverrideLimits)
+	assert.Len(t, audit.entries, 1)
+}
+
+func TestSessionManager_ExtendSession_Override(t *testing.T) {
+	manager, storage, audit := newOverrideTestManager(t)
+	manager.downtime = nil
+
+	// 20 of 60 minutes left
+	storage.IncrementDailyUsage(context.Background(), "child1", Now(), 40)
+	session, err := manager.StartSession(context.Background(), "tv1", []string{"child1"}, 20)
+	require.NoError(t, err)
+
+	_, err = manager.ExtendSession(context.Background(), session.ID, 10)
+	assert.ErrorIs(t, err, ErrInsufficientTime)
+
+	ctx := WithOverride(context.Background(), Override{Limits: true, By: "api", Reason: "homework video"})
+	extended, err := manager.ExtendSession(ctx, session.ID, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 30, extended.ExpectedDuration)
+	assert.True(t, extended.OverrideLimits, "an extension override applies to the rest of the session")
+	require.Len(t, audit.entries, 1)
+	assert.Contains(t, audit.entries[0].Details, "Session extended by 10 minutes")
+}
@@ -0,0 +1,211 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// ErrPolicyBlocked is returned when a session policy blocks a start or extension
+var ErrPolicyBlocked = errors.New("blocked by session policy")
+
+// Rules of a session policy (PolicyError.Rule)
+const (
+	PolicyRuleMaxSessionLength  = "max_session_length"   // A single session may not run longer
+	PolicyRuleMinGap            = "min_gap"              // Too little time since the child's last session ended
+	PolicyRuleMaxSessionsPerDay = "max_sessions_per_day" // The child started enough sessions today
+)
+
+// SessionPolicy limits sessions of some children on some devices, beyond their daily limits
+// Zero rules are not enforced.
+type SessionPolicy struct {
+	Name              string
+	ChildIDs          []string // Children the policy applies to; empty means all
+	DeviceIDs         []string // Devices the policy applies to; empty means all
+	MaxSessionMinutes int      // Longest single session
+	MinGapMinutes     int      // Minutes between the end of a session and the start of the next
+	MaxSessionsPerDay int      // Sessions a child may start per day (in their timezone)
+}
+
+// Applies returns true if the policy covers the child on the device
+func (p *SessionPolicy) Applies(childID, deviceID string) bool {
+	return (len(p.ChildIDs) == 0 || slices.Contains(p.ChildIDs, childID)) &&
+		(len(p.DeviceIDs) == 0 || slices.Contains(p.DeviceIDs, deviceID))
+}
+
+// PolicyError describes which policy blocked a request and why
+// It matches ErrPolicyBlocked with errors.Is
+type PolicyError struct {
+	Policy    string
+	Rule      string // One of the PolicyRule* rules
+	ChildID   string
+	ChildName string
+	Limit     int        // The rule's value: minutes, or sessions for PolicyRuleMaxSessionsPerDay
+	RetryAt   *time.Time // When the request would be allowed (PolicyRuleMinGap only)
+}
+
+func (e *PolicyError) Error() string {
+	switch e.Rule {
+	case PolicyRuleMaxSessionLength:
+		return fmt.Sprintf("%s %s: sessions may run at most %d minutes", ErrPolicyBlocked, e.Policy, e.Limit)
+	case PolicyRuleMinGap:
+		return fmt.Sprintf("%s %s: %s needs a %d minute gap between sessions", ErrPolicyBlocked, e.Policy, e.ChildName, e.Limit)
+	case PolicyRuleMaxSessionsPerDay:
+		return fmt.Sprintf("%s %s: %s already started %d sessions today", ErrPolicyBlocked, e.Policy, e.ChildName, e.Limit)
+	}
+	return fmt.Sprintf("%s %s", ErrPolicyBlocked, e.Policy)
+}
+
+func (e *PolicyError) Unwrap() error {
+	return ErrPolicyBlocked
+}
+
+// PolicyStorage lists a child's sessions for the gap and per-day rules
+// Storage without it only has the max session length enforced
+type PolicyStorage interface {
+	ListSessionsByChild(ctx context.Context, childID string) ([]*Session, error)
+}
+
+// SetPolicies sets the session policies checked by StartSession and ExtendSession
+func (m *SessionManager) SetPolicies(policies []SessionPolicy) {
+	m.policies = policies
+}
+
+// policyCap is the shortest max session length of the policies covering a session
+type policyCap struct {
+	minutes int // 0 if no policy limits the length
+	policy  string
+}
+
+// limit lowers the cap to the policy's max session length, if shorter
+func (c *policyCap) limit(policy *SessionPolicy) {
+	if policy.MaxSessionMinutes > 0 && (c.minutes == 0 || policy.MaxSessionMinutes < c.minutes) {
+		c.minutes = policy.MaxSessionMinutes
+		c.policy = policy.Name
+	}
+}
+
+// checkStartPolicies checks the policies covering a child starting a session on a device
+// Returns a *PolicyError for a broken gap or per-day rule, and the max session length to cap the session to
+func (m *SessionManager) checkStartPolicies(ctx context.Context, child *Child, deviceID string, now time.Time) (policyCap, error) {
+	var maxLength policyCap
+	var covering []*SessionPolicy
+	for i := range m.policies {
+		if policy := &m.policies[i]; policy.Applies(child.ID, deviceID) {
+			maxLength.limit(policy)
+			if policy.MinGapMinutes > 0 || policy.MaxSessionsPerDay > 0 {
+				covering = append(covering, policy)
+			}
+		}
+	}
+	if len(covering) == 0 {
+		return maxLength, nil
+	}
+
+	history, ok := m.storage.(PolicyStorage)
+	if !ok {
+		m.logger.Debug("Storage cannot list sessions, skipping policy gap and per-day rules",
+			"child_id", child.ID)
+		return maxLength, nil
+	}
+	sessions, err := history.ListSessionsByChild(ctx, child.ID)
+	if err != nil {
+		return maxLength, fmt.Errorf("failed to list sessions of child %s: %w", child.ID, err)
+	}
+
+	for _, policy := range covering {
+		if err := m.checkPolicyHistory(policy, child, sessions, now); err != nil {
+			m.logger.Warn("Session start blocked by policy",
+				"child_id", child.ID,
+				"child_name", child.Name,
+				"device_id", deviceID,
+				"policy", policy.Name,
+				"rule", err.Rule)
+			return maxLength, err
+		}
+	}
+	return maxLength, nil
+}
+
+// checkPolicyHistory checks the gap and per-day rules of a policy against the child's sessions
+func (m *SessionManager) checkPolicyHistory(policy *SessionPolicy, child *Child, sessions []*Session, now time.Time) *PolicyError {
+	loc := ResolveTimezone(m.timezone, child.Timezone)
+	today := UsageDate(now, loc, loc)
+
+	startedToday := 0
+	var lastEnd time.Time
+	for _, session := range sessions {
+		// Movie time and exempt activities have their own rules
+		if session.IsMovieSession || session.IsExempt() || !policy.Applies(child.ID, session.DeviceID) {
+			continue
+		}
+		if !session.StartTime.Before(today) {
+			startedToday++
+		}
+		if !session.IsRunning() {
+			if end := sessionEndTime(session); end.After(lastEnd) {
+				lastEnd = end
+			}
+		}
+	}
+
+	if policy.MaxSessionsPerDay > 0 && startedToday >= policy.MaxSessionsPerDay {
+		return &PolicyError{
+			Policy:    policy.Name,
+			Rule:      PolicyRuleMaxSessionsPerDay,
+			ChildID:   child.ID,
+			ChildName: child.Name,
+			Limit:     policy.MaxSessionsPerDay,
+		}
+	}
+	if policy.MinGapMinutes > 0 && !lastEnd.IsZero() {
+		retryAt := lastEnd.Add(time.Duration(policy.MinGapMinutes) * time.Minute)
+		if now.Before(retryAt) {
+			return &PolicyError{
+				Policy:    policy.Name,
+				Rule:      PolicyRuleMinGap,
+				ChildID:   child.ID,
+				ChildName: child.Name,
+				Limit:     policy.MinGapMinutes,
+				RetryAt:   &retryAt,
+			}
+		}
+	}
+	return nil
+}
+
+// extendPolicyCap returns the max session length of the policies covering a session's children
+// Children whose tracking is paused are not limited
+func (m *SessionManager) extendPolicyCap(ctx context.Context, session *Session) policyCap {
+	var maxLength policyCap
+	if len(m.policies) == 0 {
+		return maxLength
+	}
+	for _, childID := range session.ChildIDs {
+		if m.isTrackingPaused(ctx, childID) {
+			continue
+		}
+		for i := range m.policies {
+			if policy := &m.policies[i]; policy.Applies(childID, session.DeviceID) {
+				maxLength.limit(policy)
+			}
+		}
+	}
+	return maxLength
+}
+
+// sessionEndTime returns when an ended session ended
+// Storage sets UpdatedAt when the session is completed; without it, the end is estimated
+// from the minutes it ran and its breaks
+func sessionEndTime(session *Session) time.Time {
+	if !session.UpdatedAt.IsZero() {
+		return session.UpdatedAt
+	}
+	minutes := session.ExpectedDuration
+	if session.ActualDuration != nil {
+		minutes = *session.ActualDuration
+	}
+	return session.StartTime.Add(time.Duration(minutes+session.BreakMinutes) * time.Minute)
+}
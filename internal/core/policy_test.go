@@ -0,0 +1,154 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionPolicy_Applies(t *testing.T) {
+	policy := SessionPolicy{Name: "consoles", DeviceIDs: []string{"ps5"}}
+	assert.True(t, policy.Applies("child1", "ps5"))
+	assert.False(t, policy.Applies("child1", "tv1"))
+
+	policy = SessionPolicy{Name: "alice", ChildIDs: []string{"child1"}}
+	assert.True(t, policy.Applies("child1", "tv1"))
+	assert.False(t, policy.Applies("child2", "tv1"))
+}
+
+// newPolicyTestManager returns a manager with the clock at noon, a TV and a PS5,
+// and a policy on the PS5: 45 minute sessions, 30 minutes apart, two per day
+func newPolicyTestManager(t *testing.T) (*SessionManager, *mockStorage, time.Time) {
+	now := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)
+	original := Now
+	Now = func() time.Time { return now }
+	t.Cleanup(func() { Now = original })
+
+	storage := newMockStorage()
+	deviceRegistry := newMockDeviceRegistry()
+	driverRegistry := newMockDriverRegistry()
+	manager := NewSessionManager(storage, deviceRegistry, driverRegistry, nil, nil, time.UTC, nil)
+	manager.SetPolicies([]SessionPolicy{{
+		Name:              "consoles",
+		DeviceIDs:         []string{"ps5"},
+		MaxSessionMinutes: 45,
+		MinGapMinutes:     30,
+		MaxSessionsPerDay: 2,
+	}})
+
+	storage.CreateChild(context.Background(), &Child{ID: "child1", Name: "Alice", WeekdayLimit: 240, WeekendLimit: 240})
+	driverRegistry.addDriver(&mockDriver{name: "aqara"})
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", name: "TV", dtype: "tv", driver: "aqara"})
+	deviceRegistry.addDevice(&mockDevice{id: "ps5", name: "PS5", dtype: "ps5", driver: "aqara"})
+	return manager, storage, now
+}
+
+// addEndedSession stores a completed session of child1 that ended at end
+func addEndedSession(storage *mockStorage, id, deviceID string, start, end time.Time) {
+	actual := int(end.Sub(start).Minutes())
+	storage.sessions[id] = &Session{
+		ID:               id,
+		DeviceID:         deviceID,
+		ChildIDs:         []string{"child1"},
+		StartTime:        start,
+		ExpectedDuration: actual,
+		ActualDuration:   &actual,
+		Status:           SessionStatusCompleted,
+		UpdatedAt:        end,
+	}
+}
+
+func TestSessionManager_StartSession_PolicyMaxLength(t *testing.T) {
+	manager, _, _ := newPolicyTestManager(t)
+	ctx := context.Background()
+
+	session, err := manager.StartSession(ctx, "ps5", []string{"child1"}, 60)
+	require.NoError(t, err)
+	assert.Equal(t, 45, session.ExpectedDuration)
+	assert.Equal(t, CapReasonPolicy, session.Grant.Reason)
+	assert.Equal(t, "consoles", session.Grant.Policy)
+
+	// Extending past the max is capped, and at the max rejected
+	Now = func() time.Time { return session.StartTime.Add(time.Minute) }
+	_, err = manager.ExtendSession(ctx, session.ID, 15)
+	var policyErr *PolicyError
+	require.ErrorAs(t, err, &policyErr)
+	assert.ErrorIs(t, err, ErrPolicyBlocked)
+	assert.Equal(t, "consoles", policyErr.Policy)
+	assert.Equal(t, PolicyRuleMaxSessionLength, policyErr.Rule)
+
+	// Other devices are not covered
+	tv, err := manager.StartSession(ctx, "tv1", []string{"child1"}, 60)
+	require.NoError(t, err)
+	assert.Equal(t, 60, tv.ExpectedDuration)
+	assert.False(t, tv.Grant.Capped)
+}
+
+func TestSessionManager_ExtendSession_PolicyCaps(t *testing.T) {
+	manager, _, _ := newPolicyTestManager(t)
+	ctx := context.Background()
+
+	session, err := manager.StartSession(ctx, "ps5", []string{"child1"}, 30)
+	require.NoError(t, err)
+
+	extended, err := manager.ExtendSession(ctx, session.ID, 30)
+	require.NoError(t, err)
+	assert.Equal(t, 45, extended.ExpectedDuration)
+	assert.Equal(t, 15, extended.Grant.GrantedMinutes)
+	assert.Equal(t, CapReasonPolicy, extended.Grant.Reason)
+	assert.Equal(t, "consoles", extended.Grant.Policy)
+}
+
+func TestSessionManager_StartSession_PolicyMinGap(t *testing.T) {
+	manager, storage, now := newPolicyTestManager(t)
+	ctx := context.Background()
+	addEndedSession(storage, "earlier", "ps5", now.Add(-50*time.Minute), now.Add(-10*time.Minute))
+
+	_, err := manager.StartSession(ctx, "ps5", []string{"child1"}, 30)
+	var policyErr *PolicyError
+	require.ErrorAs(t, err, &policyErr)
+	assert.Equal(t, PolicyRuleMinGap, policyErr.Rule)
+	assert.Equal(t, "child1", policyErr.ChildID)
+	require.NotNil(t, policyErr.RetryAt)
+	assert.Equal(t, now.Add(20*time.Minute), *policyErr.RetryAt)
+
+	// Preflight reports the same policy
+	preflight, err := manager.PreflightSession(ctx, "ps5", []string{"child1"}, 30)
+	require.NoError(t, err)
+	assert.False(t, preflight.Allowed)
+	assert.Equal(t, BlockRulePolicy, preflight.BlockedBy)
+	assert.Equal(t, "child1", preflight.ChildID)
+
+	// A limits override skips policies
+	override := WithOverride(ctx, Override{Limits: true, By: "api"})
+	session, err := manager.StartSession(override, "ps5", []string{"child1"}, 60)
+	require.NoError(t, err)
+	assert.Equal(t, 60, session.ExpectedDuration)
+
+	// Once the gap has passed the start is allowed
+	Now = func() time.Time { return now.Add(20 * time.Minute) }
+	require.NoError(t, storage.DeleteSession(ctx, session.ID))
+	_, err = manager.StartSession(ctx, "ps5", []string{"child1"}, 30)
+	assert.NoError(t, err)
+}
+
+func TestSessionManager_StartSession_PolicyMaxPerDay(t *testing.T) {
+	manager, storage, now := newPolicyTestManager(t)
+	ctx := context.Background()
+	addEndedSession(storage, "morning", "ps5", now.Add(-4*time.Hour), now.Add(-3*time.Hour))
+	addEndedSession(storage, "late", "ps5", now.Add(-2*time.Hour), now.Add(-90*time.Minute))
+	// Yesterday's sessions and sessions on other devices do not count
+	addEndedSession(storage, "yesterday", "ps5", now.Add(-24*time.Hour), now.Add(-23*time.Hour))
+
+	_, err := manager.StartSession(ctx, "ps5", []string{"child1"}, 30)
+	var policyErr *PolicyError
+	require.ErrorAs(t, err, &policyErr)
+	assert.Equal(t, PolicyRuleMaxSessionsPerDay, policyErr.Rule)
+	assert.Equal(t, 2, policyErr.Limit)
+
+	_, err = manager.StartSession(ctx, "tv1", []string{"child1"}, 30)
+	assert.NoError(t, err)
+}
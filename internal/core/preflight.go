@@ -0,0 +1,86 @@
+package core
+
+import (
+	"context"
+	"errors"
+)
+
+// Rules that can block a session start (SessionPreflight.BlockedBy)
+const (
+	BlockRuleLockdown         = "lockdown"          // A lockdown blocks all new sessions
+	BlockRuleDevicePermission = "device_permission" // A child may not use the device
+	BlockRuleDowntime         = "downtime"          // A child is in downtime
+	BlockRuleLimit            = "limit"             // A child has no time left today
+	BlockRulePolicy           = "policy"            // A session policy's gap or per-day rule
+	BlockRuleFamilyBudget     = "family_budget"     // The family budget is used up today
+	BlockRuleActivityBudget   = "activity_budget"   // A child used the exempt activity's budget today
+	BlockRuleDeviceBusy       = "device_busy"       // The device is in use and busy devices reject starts
+)
+
+// SessionPreflight describes what StartSession would do with the same arguments
+type SessionPreflight struct {
+	Allowed bool
+	Grant   *DurationGrant // Requested and granted minutes; nil when blocked
+
+	BlockedBy string // Blocking rule (BlockRule*); empty when allowed
+	ChildID   string // Child the rule applies to; empty for lockdown
+	Err       error  // The error StartSession would return
+
+	// Active sessions already on the device. They only block a start with ConflictReject
+	// (with ConflictQueue and ConflictMerge the start is queued or joins one), but UIs may
+	// offer to join one instead.
+	ActiveSessionIDs []string
+}
+
+// blockRules maps the errors of checkStart to the rule that caused them
+var blockRules = []struct {
+	err  error
+	rule string
+}{
+	{ErrLockdownActive, BlockRuleLockdown},
+	{ErrDeviceNotAllowed, BlockRuleDevicePermission},
+	{ErrDowntimeActive, BlockRuleDowntime},
+	{ErrInsufficientTime, BlockRuleLimit},
+	{ErrPolicyBlocked, BlockRulePolicy},
+	{ErrFamilyBudgetUsedUp, BlockRuleFamilyBudget},
+	{ErrActivityBudgetUsedUp, BlockRuleActivityBudget},
+	{ErrDeviceBusy, BlockRuleDeviceBusy},
+}
+
+// PreflightSession reports whether StartSession would start a session, what it would be
+// capped to, and which rule would block it, without starting anything.
+// Errors are returned for invalid arguments and unknown devices or children, as by StartSession.
+func (m *SessionManager) PreflightSession(ctx context.Context, deviceID string, childIDs []string, durationMinutes int) (*SessionPreflight, error) {
+	check, err := m.checkStart(ctx, deviceID, childIDs, durationMinutes)
+	if err != nil {
+		for _, block := range blockRules {
+			if errors.Is(err, block.err) {
+				preflight := &SessionPreflight{BlockedBy: block.rule, Err: err}
+				if check != nil {
+					preflight.ChildID = check.childID
+				}
+				return preflight, nil
+			}
+		}
+		return nil, err
+	}
+
+	preflight := &SessionPreflight{
+		Allowed: true,
+		Grant:   NewDurationGrant(durationMinutes, check.minutes, check.capReason),
+	}
+	if preflight.Grant.Capped && check.capReason == CapReasonPolicy {
+		preflight.Grant.Policy = check.policy
+	}
+
+	active, err := m.storage.ListActiveSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, session := range active {
+		if session.DeviceID == deviceID {
+			preflight.ActiveSessionIDs = append(preflight.ActiveSessionIDs, session.ID)
+		}
+	}
+	return preflight, nil
+}
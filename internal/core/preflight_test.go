@@ -0,0 +1,89 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionManager_PreflightSession(t *testing.T) {
+	ctx := context.Background()
+	storage := newMockStorage()
+	deviceRegistry := newMockDeviceRegistry()
+	driverRegistry := newMockDriverRegistry()
+	manager := NewSessionManager(storage, deviceRegistry, driverRegistry, nil, nil, nil, nil)
+
+	storage.CreateChild(ctx, &Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 60})
+	storage.CreateChild(ctx, &Child{ID: "child2", Name: "Bob", WeekdayLimit: 60, WeekendLimit: 60, AllowedDevices: []string{"tv1"}})
+	storage.IncrementDailyUsage(ctx, "child1", time.Now(), 40)
+
+	driver := &mockDriver{name: "aqara"}
+	driverRegistry.addDriver(driver)
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", name: "TV", dtype: "tv", driver: "aqara"})
+	deviceRegistry.addDevice(&mockDevice{id: "ps5", name: "PS5", dtype: "ps5", driver: "aqara"})
+
+	t.Run("allowed and capped to remaining time", func(t *testing.T) {
+		preflight, err := manager.PreflightSession(ctx, "tv1", []string{"child1"}, 30)
+		require.NoError(t, err)
+
+		assert.True(t, preflight.Allowed)
+		assert.Equal(t, 30, preflight.Grant.RequestedMinutes)
+		assert.Equal(t, 20, preflight.Grant.GrantedMinutes)
+		assert.Equal(t, CapReasonRemainingTime, preflight.Grant.Reason)
+		assert.Empty(t, preflight.BlockedBy)
+		assert.Empty(t, preflight.ActiveSessionIDs)
+
+		// Nothing was started
+		assert.False(t, driver.startCalled)
+		active, err := storage.ListActiveSessions(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, active)
+	})
+
+	t.Run("blocked by device permission", func(t *testing.T) {
+		preflight, err := manager.PreflightSession(ctx, "ps5", []string{"child1", "child2"}, 30)
+		require.NoError(t, err)
+
+		assert.False(t, preflight.Allowed)
+		assert.Equal(t, BlockRuleDevicePermission, preflight.BlockedBy)
+		assert.Equal(t, "child2", preflight.ChildID)
+		assert.ErrorIs(t, preflight.Err, ErrDeviceNotAllowed)
+		assert.Nil(t, preflight.Grant)
+	})
+
+	t.Run("blocked by limit", func(t *testing.T) {
+		storage.IncrementDailyUsage(ctx, "child2", time.Now(), 60)
+
+		preflight, err := manager.PreflightSession(ctx, "tv1", []string{"child1", "child2"}, 30)
+		require.NoError(t, err)
+
+		assert.False(t, preflight.Allowed)
+		assert.Equal(t, BlockRuleLimit, preflight.BlockedBy)
+		assert.Equal(t, "child2", preflight.ChildID)
+		assert.ErrorIs(t, preflight.Err, ErrInsufficientTime)
+	})
+
+	t.Run("reports active sessions on the device", func(t *testing.T) {
+		session, err := manager.StartSession(ctx, "ps5", []string{"child1"}, 10)
+		require.NoError(t, err)
+
+		preflight, err := manager.PreflightSession(ctx, "ps5", []string{"child1"}, 5)
+		require.NoError(t, err)
+		assert.True(t, preflight.Allowed)
+		assert.Equal(t, []string{session.ID}, preflight.ActiveSessionIDs)
+	})
+
+	t.Run("invalid arguments are errors", func(t *testing.T) {
+		_, err := manager.PreflightSession(ctx, "tv1", nil, 30)
+		assert.ErrorIs(t, err, ErrNoChildren)
+
+		_, err = manager.PreflightSession(ctx, "tv1", []string{"child1"}, 0)
+		assert.ErrorIs(t, err, ErrInvalidDuration)
+
+		_, err = manager.PreflightSession(ctx, "unknown", []string{"child1"}, 30)
+		assert.Error(t, err)
+	})
+}
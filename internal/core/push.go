@@ -0,0 +1,328 @@
+package core
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"metron/internal/idgen"
+)
+
+// Push notification kinds
+const (
+	PushSessionWarning = "session.warning" // A session is about to end
+	PushSessionEnded   = "session.ended"   // The scheduler ended a session (time ran out, downtime, idle)
+	PushUsageAlert     = "usage.alert"     // A child reached a usage alert threshold (parents only)
+)
+
+// How long push services keep a notification for a browser that is offline
+const (
+	pushWarningTTL = 5 * time.Minute // A warning is stale once the session ended
+	pushDefaultTTL = time.Hour
+)
+
+// Push errors
+var (
+	ErrInvalidPushSubscription  = errors.New("invalid push subscription")
+	ErrPushSubscriptionNotFound = errors.New("push subscription not found")
+	ErrPushSubscriptionGone     = errors.New("push subscription is gone") // Returned by senders when the push service dropped the subscription
+)
+
+// PushSubscription is a browser subscribed to Web Push notifications, as returned by the
+// browser's PushManager.subscribe (the endpoint and its keys)
+type PushSubscription struct {
+	ID        string
+	ChildID   string // Child whose notifications it receives; empty for parents, who receive every child's
+	Endpoint  string // Push service URL of the browser
+	P256dh    string // Browser's public key (base64url)
+	Auth      string // Browser's auth secret (base64url)
+	Label     string // Optional: shown to parents (e.g., "Alice's phone")
+	CreatedAt time.Time
+}
+
+// PushMessage is a notification sent to a subscribed browser
+type PushMessage struct {
+	Kind      string // One of the Push* kinds
+	Title     string
+	Body      string
+	ChildID   string        // Child the notification is about
+	SessionID string        // Empty for notifications not about a session
+	TTL       time.Duration // How long push services keep it for an offline browser
+}
+
+// PushSender delivers a message to a subscribed browser (see package webpush)
+// Senders return ErrPushSubscriptionGone when the push service no longer knows the subscription.
+type PushSender interface {
+	Send(ctx context.Context, subscription *PushSubscription, message *PushMessage) error
+}
+
+// PushStorage defines the interface for push subscription persistence
+type PushStorage interface {
+	GetChild(ctx context.Context, id string) (*Child, error)
+	SavePushSubscription(ctx context.Context, subscription *PushSubscription) error // Replaces a subscription with the same endpoint
+	ListPushSubscriptions(ctx context.Context) ([]*PushSubscription, error)         // Oldest first
+	DeletePushSubscription(ctx context.Context, id string) error                    // ErrPushSubscriptionNotFound if missing
+}
+
+// PushService sends session warnings and ends to browsers subscribed with Web Push, so the
+// child web app (and parents' browsers) are notified even when the page is closed.
+// Children's subscriptions get their own notifications, parents' get every child's.
+// Delivery is best effort, like the child activity feed: failures are logged, and
+// subscriptions the push service dropped are deleted.
+type PushService struct {
+	storage   PushStorage
+	sender    PushSender
+	publicKey string // VAPID public key browsers subscribe with (base64url)
+	logger    *slog.Logger
+}
+
+// NewPushService creates a new push service
+func NewPushService(storage PushStorage, sender PushSender, publicKey string, logger *slog.Logger) *PushService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &PushService{
+		storage:   storage,
+		sender:    sender,
+		publicKey: publicKey,
+		logger:    logger,
+	}
+}
+
+// PublicKey returns the VAPID public key browsers pass to PushManager.subscribe
+func (s *PushService) PublicKey() string {
+	return s.publicKey
+}
+
+// Subscribe validates and saves a subscription
+// Subscribing an endpoint again replaces its keys, child and label.
+func (s *PushService) Subscribe(ctx context.Context, subscription *PushSubscription) error {
+	if err := subscription.Validate(); err != nil {
+		return err
+	}
+	if subscription.ChildID != "" {
+		if _, err := s.storage.GetChild(ctx, subscription.ChildID); err != nil {
+			return err
+		}
+	}
+
+	subscription.ID = idgen.NewPushSubscription()
+	subscription.CreatedAt = Now()
+	if err := s.storage.SavePushSubscription(ctx, subscription); err != nil {
+		return fmt.Errorf("failed to save push subscription: %w", err)
+	}
+
+	s.logger.Info("Push subscription saved",
+		"subscription_id", subscription.ID,
+		"child_id", subscription.ChildID)
+	return nil
+}
+
+// List returns the subscriptions, oldest first
+// A childID lists only that child's subscriptions; empty lists all.
+func (s *PushService) List(ctx context.Context, childID string) ([]*PushSubscription, error) {
+	subscriptions, err := s.storage.ListPushSubscriptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if childID == "" {
+		return subscriptions, nil
+	}
+	var own []*PushSubscription
+	for _, subscription := range subscriptions {
+		if subscription.ChildID == childID {
+			own = append(own, subscription)
+		}
+	}
+	return own, nil
+}
+
+// Unsubscribe deletes a subscription
+// A childID only deletes that child's subscriptions: others are reported as not found.
+func (s *PushService) Unsubscribe(ctx context.Context, id, childID string) error {
+	if childID != "" {
+		own, err := s.List(ctx, childID)
+		if err != nil {
+			return err
+		}
+		found := false
+		for _, subscription := range own {
+			found = found || subscription.ID == id
+		}
+		if !found {
+			return ErrPushSubscriptionNotFound
+		}
+	}
+	return s.storage.DeletePushSubscription(ctx, id)
+}
+
+// SessionWarning notifies the session's children and parents that it ends in minutes
+// Returns the number of browsers notified.
+func (s *PushService) SessionWarning(ctx context.Context, session *Session, minutes int) int {
+	message := PushMessage{
+		Kind:      PushSessionWarning,
+		Title:     "Time is almost up",
+		Body:      fmt.Sprintf("%s left on %s", pluralMinutes(minutes), session.DeviceType),
+		SessionID: session.ID,
+		TTL:       pushWarningTTL,
+	}
+	return s.notifySession(ctx, session, message)
+}
+
+// SessionHook returns a SessionStateMachine hook that notifies of sessions the scheduler ended
+// Stops are not notified: whoever stopped the session already knows. Notifications are sent
+// in the background, so push services do not hold up the scheduler.
+func (s *PushService) SessionHook() SessionHook {
+	return func(ctx context.Context, session *Session, transition SessionTransition) {
+		if transition.Event != SessionEventExpire {
+			return
+		}
+		minutes := 0
+		if session.ActualDuration != nil {
+			minutes = *session.ActualDuration
+		}
+		ended := *session
+		message := PushMessage{
+			Kind:      PushSessionEnded,
+			Title:     "Time is up",
+			Body:      sessionEndedMessage(session, minutes),
+			SessionID: session.ID,
+			TTL:       pushDefaultTTL,
+		}
+		go s.notifySession(context.WithoutCancel(ctx), &ended, message)
+	}
+}
+
+// NotifyUsageAlert notifies parents that a child reached a usage alert threshold
+// It makes the service a UsageAlertNotifier.
+func (s *PushService) NotifyUsageAlert(ctx context.Context, alert *UsageAlert, child *Child) error {
+	s.notify(ctx, func(subscription *PushSubscription) *PushMessage {
+		if subscription.ChildID != "" {
+			return nil
+		}
+		return &PushMessage{
+			Kind:    PushUsageAlert,
+			Title:   fmt.Sprintf("%s used %d%% of today's time", child.Name, alert.Threshold),
+			Body:    fmt.Sprintf("%s left today", pluralMinutes(alert.RemainingMinutes())),
+			ChildID: child.ID,
+			TTL:     pushDefaultTTL,
+		}
+	})
+	return nil
+}
+
+// notifySession sends message to the session's children, and to parents with the child's name
+func (s *PushService) notifySession(ctx context.Context, session *Session, message PushMessage) int {
+	names := make([]string, 0, len(session.ChildIDs))
+	for _, childID := range session.ChildIDs {
+		child, err := s.storage.GetChild(ctx, childID)
+		if err != nil {
+			names = append(names, childID)
+			continue
+		}
+		names = append(names, child.Name)
+	}
+
+	return s.notify(ctx, func(subscription *PushSubscription) *PushMessage {
+		if subscription.ChildID == "" {
+			parent := message
+			parent.Title = fmt.Sprintf("%s: %s", strings.Join(names, ", "), message.Title)
+			if len(session.ChildIDs) == 1 {
+				parent.ChildID = session.ChildIDs[0]
+			}
+			return &parent
+		}
+		for _, childID := range session.ChildIDs {
+			if subscription.ChildID == childID {
+				own := message
+				own.ChildID = childID
+				return &own
+			}
+		}
+		return nil
+	})
+}
+
+// notify sends each subscription the message build returns for it (none if nil), in parallel
+// so a slow push service does not hold up the others. Returns the number of browsers notified.
+func (s *PushService) notify(ctx context.Context, build func(*PushSubscription) *PushMessage) int {
+	subscriptions, err := s.storage.ListPushSubscriptions(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list push subscriptions", "error", err)
+		return 0
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	sent := 0
+	for _, subscription := range subscriptions {
+		message := build(subscription)
+		if message == nil {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if s.send(ctx, subscription, message) {
+				mu.Lock()
+				sent++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return sent
+}
+
+// send delivers one message, deleting the subscription if the push service dropped it
+func (s *PushService) send(ctx context.Context, subscription *PushSubscription, message *PushMessage) bool {
+	err := s.sender.Send(ctx, subscription, message)
+	if errors.Is(err, ErrPushSubscriptionGone) {
+		s.logger.Info("Push subscription is gone, deleting it",
+			"subscription_id", subscription.ID,
+			"child_id", subscription.ChildID)
+		if err := s.storage.DeletePushSubscription(ctx, subscription.ID); err != nil && !errors.Is(err, ErrPushSubscriptionNotFound) {
+			s.logger.Error("Failed to delete push subscription",
+				"subscription_id", subscription.ID,
+				"error", err)
+		}
+		return false
+	}
+	if err != nil {
+		s.logger.Error("Failed to send push notification",
+			"subscription_id", subscription.ID,
+			"kind", message.Kind,
+			"error", err)
+		return false
+	}
+	return true
+}
+
+// Validate checks the endpoint is an HTTPS URL and the keys have the lengths Web Push uses
+func (p *PushSubscription) Validate() error {
+	endpoint, err := url.Parse(p.Endpoint)
+	if err != nil || endpoint.Scheme != "https" || endpoint.Host == "" {
+		return fmt.Errorf("%w: endpoint must be an https URL", ErrInvalidPushSubscription)
+	}
+	if key, err := decodePushKey(p.P256dh); err != nil || len(key) != 65 || key[0] != 0x04 {
+		return fmt.Errorf("%w: p256dh must be an uncompressed P-256 public key", ErrInvalidPushSubscription)
+	}
+	if secret, err := decodePushKey(p.Auth); err != nil || len(secret) != 16 {
+		return fmt.Errorf("%w: auth must be a 16-byte secret", ErrInvalidPushSubscription)
+	}
+	if len(p.Label) > 100 {
+		return fmt.Errorf("%w: label is longer than 100 characters", ErrInvalidPushSubscription)
+	}
+	return nil
+}
+
+// decodePushKey decodes a base64url key, padded or not (browsers send it unpadded)
+func decodePushKey(key string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(key, "="))
+}
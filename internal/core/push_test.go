@@ -0,0 +1,244 @@
+package core
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockPushStorage struct {
+	*mockStorage
+	subscriptions []*PushSubscription
+}
+
+func (m *mockPushStorage) SavePushSubscription(ctx context.Context, subscription *PushSubscription) error {
+	for i, existing := range m.subscriptions {
+		if existing.Endpoint == subscription.Endpoint {
+			m.subscriptions[i] = subscription
+			return nil
+		}
+	}
+	m.subscriptions = append(m.subscriptions, subscription)
+	return nil
+}
+
+func (m *mockPushStorage) ListPushSubscriptions(ctx context.Context) ([]*PushSubscription, error) {
+	return append([]*PushSubscription(nil), m.subscriptions...), nil
+}
+
+func (m *mockPushStorage) DeletePushSubscription(ctx context.Context, id string) error {
+	for i, existing := range m.subscriptions {
+		if existing.ID == id {
+			m.subscriptions = append(m.subscriptions[:i], m.subscriptions[i+1:]...)
+			return nil
+		}
+	}
+	return ErrPushSubscriptionNotFound
+}
+
+// mockPushSender records messages by endpoint; endpoints in gone are reported as dropped
+type mockPushSender struct {
+	mu   sync.Mutex
+	sent map[string][]*PushMessage
+	gone map[string]bool
+}
+
+func (m *mockPushSender) Send(ctx context.Context, subscription *PushSubscription, message *PushMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.gone[subscription.Endpoint] {
+		return ErrPushSubscriptionGone
+	}
+	m.sent[subscription.Endpoint] = append(m.sent[subscription.Endpoint], message)
+	return nil
+}
+
+// messages returns the messages sent to endpoint so far
+func (m *mockPushSender) messages(endpoint string) []*PushMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*PushMessage(nil), m.sent[endpoint]...)
+}
+
+func newPushTestService(t *testing.T) (*PushService, *mockPushStorage, *mockPushSender) {
+	storage := &mockPushStorage{mockStorage: newMockStorage()}
+	storage.CreateChild(context.Background(), &Child{ID: "child1", Name: "Alice"})
+	storage.CreateChild(context.Background(), &Child{ID: "child2", Name: "Bob"})
+	sender := &mockPushSender{sent: make(map[string][]*PushMessage), gone: make(map[string]bool)}
+	return NewPushService(storage, sender, "public-key", nil), storage, sender
+}
+
+func newPushTestSubscription(t *testing.T, endpoint, childID string) *PushSubscription {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return &PushSubscription{
+		ChildID:  childID,
+		Endpoint: endpoint,
+		P256dh:   base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(make([]byte, 16)),
+	}
+}
+
+func TestPushSubscription_Validate(t *testing.T) {
+	valid := newPushTestSubscription(t, "https://push.example.com/1", "")
+	require.NoError(t, valid.Validate())
+
+	// Padded keys are accepted too
+	padded := *valid
+	padded.Auth = base64.URLEncoding.EncodeToString(make([]byte, 16))
+	assert.NoError(t, padded.Validate())
+
+	tests := []struct {
+		name   string
+		modify func(*PushSubscription)
+	}{
+		{"http endpoint", func(s *PushSubscription) { s.Endpoint = "http://push.example.com/1" }},
+		{"no endpoint", func(s *PushSubscription) { s.Endpoint = "" }},
+		{"short p256dh", func(s *PushSubscription) { s.P256dh = base64.RawURLEncoding.EncodeToString(make([]byte, 33)) }},
+		{"compressed p256dh", func(s *PushSubscription) {
+			s.P256dh = base64.RawURLEncoding.EncodeToString(append([]byte{0x02}, make([]byte, 64)...))
+		}},
+		{"short auth", func(s *PushSubscription) { s.Auth = base64.RawURLEncoding.EncodeToString(make([]byte, 8)) }},
+		{"auth not base64", func(s *PushSubscription) { s.Auth = "not base64!" }},
+		{"long label", func(s *PushSubscription) { s.Label = strings.Repeat("a", 101) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subscription := *valid
+			tt.modify(&subscription)
+			assert.ErrorIs(t, subscription.Validate(), ErrInvalidPushSubscription)
+		})
+	}
+}
+
+func TestPushService_Subscribe(t *testing.T) {
+	service, storage, _ := newPushTestService(t)
+	ctx := context.Background()
+
+	subscription := newPushTestSubscription(t, "https://push.example.com/1", "child1")
+	require.NoError(t, service.Subscribe(ctx, subscription))
+	assert.True(t, strings.HasPrefix(subscription.ID, "psh_"))
+	assert.False(t, subscription.CreatedAt.IsZero())
+
+	// Subscribing the endpoint again replaces it
+	again := newPushTestSubscription(t, "https://push.example.com/1", "child2")
+	require.NoError(t, service.Subscribe(ctx, again))
+	require.Len(t, storage.subscriptions, 1)
+	assert.Equal(t, "child2", storage.subscriptions[0].ChildID)
+
+	// The child must exist
+	unknown := newPushTestSubscription(t, "https://push.example.com/2", "nobody")
+	assert.ErrorIs(t, service.Subscribe(ctx, unknown), ErrChildNotFound)
+
+	invalid := newPushTestSubscription(t, "http://push.example.com/3", "")
+	assert.ErrorIs(t, service.Subscribe(ctx, invalid), ErrInvalidPushSubscription)
+	assert.Len(t, storage.subscriptions, 1)
+}
+
+func TestPushService_Unsubscribe(t *testing.T) {
+	service, _, _ := newPushTestService(t)
+	ctx := context.Background()
+
+	alice := newPushTestSubscription(t, "https://push.example.com/alice", "child1")
+	bob := newPushTestSubscription(t, "https://push.example.com/bob", "child2")
+	parent := newPushTestSubscription(t, "https://push.example.com/parent", "")
+	for _, subscription := range []*PushSubscription{alice, bob, parent} {
+		require.NoError(t, service.Subscribe(ctx, subscription))
+	}
+
+	own, err := service.List(ctx, "child1")
+	require.NoError(t, err)
+	require.Len(t, own, 1)
+	assert.Equal(t, alice.ID, own[0].ID)
+
+	// A child cannot delete others' subscriptions
+	assert.ErrorIs(t, service.Unsubscribe(ctx, bob.ID, "child1"), ErrPushSubscriptionNotFound)
+	assert.ErrorIs(t, service.Unsubscribe(ctx, parent.ID, "child1"), ErrPushSubscriptionNotFound)
+	require.NoError(t, service.Unsubscribe(ctx, alice.ID, "child1"))
+
+	// Parents delete any
+	require.NoError(t, service.Unsubscribe(ctx, bob.ID, ""))
+	assert.ErrorIs(t, service.Unsubscribe(ctx, bob.ID, ""), ErrPushSubscriptionNotFound)
+
+	all, err := service.List(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	assert.Equal(t, parent.ID, all[0].ID)
+}
+
+func TestPushService_SessionNotifications(t *testing.T) {
+	service, storage, sender := newPushTestService(t)
+	ctx := context.Background()
+
+	for _, subscription := range []*PushSubscription{
+		newPushTestSubscription(t, "https://push.example.com/alice", "child1"),
+		newPushTestSubscription(t, "https://push.example.com/bob", "child2"),
+		newPushTestSubscription(t, "https://push.example.com/parent", ""),
+		newPushTestSubscription(t, "https://push.example.com/old", ""),
+	} {
+		require.NoError(t, service.Subscribe(ctx, subscription))
+	}
+	sender.gone["https://push.example.com/old"] = true
+
+	session := &Session{ID: "sess1", DeviceType: "tv", ChildIDs: []string{"child1"}}
+	assert.Equal(t, 2, service.SessionWarning(ctx, session, 5))
+
+	// Alice and parents are warned, Bob is not
+	require.Len(t, sender.sent["https://push.example.com/alice"], 1)
+	warning := sender.sent["https://push.example.com/alice"][0]
+	assert.Equal(t, PushSessionWarning, warning.Kind)
+	assert.Equal(t, "Time is almost up", warning.Title)
+	assert.Equal(t, "5 minutes left on tv", warning.Body)
+	assert.Equal(t, "child1", warning.ChildID)
+	assert.Equal(t, "sess1", warning.SessionID)
+	assert.Empty(t, sender.sent["https://push.example.com/bob"])
+	require.Len(t, sender.sent["https://push.example.com/parent"], 1)
+	assert.Equal(t, "Alice: Time is almost up", sender.sent["https://push.example.com/parent"][0].Title)
+
+	// Dropped subscriptions are deleted
+	assert.Len(t, storage.subscriptions, 3)
+
+	// Only sessions the scheduler ended are notified, in the background
+	hook := service.SessionHook()
+	duration := 30
+	ended := &Session{ID: "sess2", DeviceType: "tv", ChildIDs: []string{"child1", "child2"}, ActualDuration: &duration, EndReason: SessionEndExpired}
+	hook(ctx, ended, SessionTransition{Event: SessionEventStop})
+	hook(ctx, ended, SessionTransition{Event: SessionEventExpire})
+	require.Eventually(t, func() bool {
+		return len(sender.messages("https://push.example.com/bob")) == 1 && len(sender.messages("https://push.example.com/parent")) == 2
+	}, time.Second, 10*time.Millisecond)
+	bob := sender.messages("https://push.example.com/bob")[0]
+	assert.Equal(t, PushSessionEnded, bob.Kind)
+	assert.Equal(t, "child2", bob.ChildID)
+	assert.Equal(t, "Your session on tv ran out after 30 minutes", bob.Body)
+	assert.Equal(t, "Alice, Bob: Time is up", sender.messages("https://push.example.com/parent")[1].Title)
+	assert.Len(t, sender.messages("https://push.example.com/alice"), 2)
+}
+
+func TestPushService_UsageAlert(t *testing.T) {
+	service, _, sender := newPushTestService(t)
+	ctx := context.Background()
+
+	require.NoError(t, service.Subscribe(ctx, newPushTestSubscription(t, "https://push.example.com/alice", "child1")))
+	require.NoError(t, service.Subscribe(ctx, newPushTestSubscription(t, "https://push.example.com/parent", "")))
+
+	alert := &UsageAlert{ChildID: "child1", Threshold: 80, UsedMinutes: 96, LimitMinutes: 120}
+	require.NoError(t, service.NotifyUsageAlert(ctx, alert, &Child{ID: "child1", Name: "Alice"}))
+
+	// Parents only
+	assert.Empty(t, sender.sent["https://push.example.com/alice"])
+	require.Len(t, sender.sent["https://push.example.com/parent"], 1)
+	message := sender.sent["https://push.example.com/parent"][0]
+	assert.Equal(t, PushUsageAlert, message.Kind)
+	assert.Equal(t, "Alice used 80% of today's time", message.Title)
+	assert.Equal(t, "24 minutes left today", message.Body)
+	assert.Equal(t, "child1", message.ChildID)
+}
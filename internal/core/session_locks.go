@@ -0,0 +1,128 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrSessionBusy is returned when another process holds a session's claim for too long
+var ErrSessionBusy = errors.New("session is being changed by another process")
+
+// Session claim timing
+const (
+	sessionClaimTTL   = 2 * time.Minute        // A claim outlives its holder by at most this long (e.g. after a crash)
+	sessionClaimWait  = 5 * time.Second        // How long Acquire waits for another process's claim
+	sessionClaimRetry = 100 * time.Millisecond // Interval between claim attempts
+)
+
+// SessionClaimStorage persists short-lived claims on sessions, so processes sharing a database
+// (e.g. two servers, or a server and a CLI) do not change the same session at once
+type SessionClaimStorage interface {
+	// ClaimSession claims the session for owner until the given time
+	// It succeeds if the session is unclaimed, its claim expired, or owner already holds it
+	ClaimSession(ctx context.Context, sessionID, owner string, until time.Time) (bool, error)
+	// ReleaseSessionClaim drops owner's claim; claims of other owners are kept
+	ReleaseSessionClaim(ctx context.Context, sessionID, owner string) error
+}
+
+// SessionLocks serializes changes to the same session between API requests and scheduler ticks,
+// e.g. so an extension made as the warning fires is not undone by an expiry check that read
+// the session before it was extended, and a session stopped by both is only stopped on the
+// device once. Locks are per session ID and only cover this process; with claim storage
+// (SetClaims), Acquire also claims the session in storage to cover other processes.
+type SessionLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sessionLock
+
+	claims    SessionClaimStorage // Optional
+	owner     string              // Identifies this process in claims
+	claimWait time.Duration       // How long Acquire waits for another process's claim
+}
+
+// sessionLock is one session's lock; refs counts holders and waiters so idle locks can be dropped
+type sessionLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+// NewSessionLocks creates an empty lock set
+func NewSessionLocks() *SessionLocks {
+	return &SessionLocks{
+		locks:     make(map[string]*sessionLock),
+		owner:     uuid.New().String(),
+		claimWait: sessionClaimWait,
+	}
+}
+
+// SetClaims enables storage-level claims in Acquire
+func (l *SessionLocks) SetClaims(claims SessionClaimStorage) {
+	l.claims = claims
+}
+
+// Lock blocks until no one else in this process holds the session and returns the function that releases it
+func (l *SessionLocks) Lock(sessionID string) (unlock func()) {
+	l.mu.Lock()
+	lock, ok := l.locks[sessionID]
+	if !ok {
+		lock = &sessionLock{}
+		l.locks[sessionID] = lock
+	}
+	lock.refs++
+	l.mu.Unlock()
+
+	lock.mu.Lock()
+	return func() {
+		lock.mu.Unlock()
+
+		l.mu.Lock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(l.locks, sessionID)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// Acquire locks the session in this process, then claims it in storage (if enabled)
+// It returns ErrSessionBusy if another process keeps its claim for sessionClaimWait.
+// Callers must re-read the session after Acquire: it may have changed while they waited.
+func (l *SessionLocks) Acquire(ctx context.Context, sessionID string) (release func(), err error) {
+	unlock := l.Lock(sessionID)
+	if l.claims == nil {
+		return unlock, nil
+	}
+
+	// Claims use the wall clock: they coordinate processes, not session time
+	deadline := time.Now().Add(l.claimWait)
+	for {
+		claimed, err := l.claims.ClaimSession(ctx, sessionID, l.owner, time.Now().Add(sessionClaimTTL))
+		if err != nil {
+			unlock()
+			return nil, err
+		}
+		if claimed {
+			break
+		}
+		if time.Now().After(deadline) {
+			unlock()
+			return nil, ErrSessionBusy
+		}
+
+		select {
+		case <-ctx.Done():
+			unlock()
+			return nil, ctx.Err()
+		case <-time.After(sessionClaimRetry):
+		}
+	}
+
+	return func() {
+		// The caller's context may be cancelled by now; a claim that fails to release expires
+		_ = l.claims.ReleaseSessionClaim(context.Background(), sessionID, l.owner)
+		unlock()
+	}, nil
+}
@@ -0,0 +1,152 @@
+package core
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionLocks(t *testing.T) {
+	locks := NewSessionLocks()
+
+	t.Run("same session waits", func(t *testing.T) {
+		unlock := locks.Lock("s1")
+
+		acquired := make(chan struct{})
+		go func() {
+			release := locks.Lock("s1")
+			close(acquired)
+			release()
+		}()
+
+		select {
+		case <-acquired:
+			t.Fatal("second holder acquired a held session lock")
+		case <-time.After(20 * time.Millisecond):
+		}
+
+		unlock()
+		select {
+		case <-acquired:
+		case <-time.After(time.Second):
+			t.Fatal("lock was not handed over after unlock")
+		}
+	})
+
+	t.Run("other sessions do not wait", func(t *testing.T) {
+		unlock := locks.Lock("s1")
+		defer unlock()
+
+		done := make(chan struct{})
+		go func() {
+			locks.Lock("s2")()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("lock on another session blocked")
+		}
+	})
+
+	t.Run("idle locks are dropped", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				locks.Lock("s3")()
+			}()
+		}
+		wg.Wait()
+
+		locks.mu.Lock()
+		defer locks.mu.Unlock()
+		assert.Empty(t, locks.locks)
+	})
+}
+
+// mockClaimStorage keeps session claims in memory
+type mockClaimStorage struct {
+	mu     sync.Mutex
+	claims map[string]string // Owner by session ID; claims never expire
+}
+
+func (m *mockClaimStorage) ClaimSession(ctx context.Context, sessionID, owner string, until time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if holder, ok := m.claims[sessionID]; ok && holder != owner {
+		return false, nil
+	}
+	m.claims[sessionID] = owner
+	return true, nil
+}
+
+func (m *mockClaimStorage) ReleaseSessionClaim(ctx context.Context, sessionID, owner string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.claims[sessionID] == owner {
+		delete(m.claims, sessionID)
+	}
+	return nil
+}
+
+func TestSessionLocks_Acquire(t *testing.T) {
+	ctx := context.Background()
+	claims := &mockClaimStorage{claims: make(map[string]string)}
+
+	// Two processes sharing a database
+	server := NewSessionLocks()
+	server.SetClaims(claims)
+	other := NewSessionLocks()
+	other.SetClaims(claims)
+	other.claimWait = 20 * time.Millisecond
+
+	t.Run("claims and releases the session", func(t *testing.T) {
+		release, err := server.Acquire(ctx, "s1")
+		require.NoError(t, err)
+		assert.Equal(t, server.owner, claims.claims["s1"])
+
+		release()
+		assert.NotContains(t, claims.claims, "s1")
+	})
+
+	t.Run("another process gets ErrSessionBusy", func(t *testing.T) {
+		release, err := server.Acquire(ctx, "s1")
+		require.NoError(t, err)
+
+		_, err = other.Acquire(ctx, "s1")
+		assert.ErrorIs(t, err, ErrSessionBusy)
+
+		// Its in-process lock was released with the failed attempt
+		other.mu.Lock()
+		assert.Empty(t, other.locks)
+		other.mu.Unlock()
+
+		release()
+		otherRelease, err := other.Acquire(ctx, "s1")
+		require.NoError(t, err)
+		otherRelease()
+	})
+
+	t.Run("waits for a claim released in time", func(t *testing.T) {
+		release, err := other.Acquire(ctx, "s2")
+		require.NoError(t, err)
+		time.AfterFunc(20*time.Millisecond, release)
+
+		serverRelease, err := server.Acquire(ctx, "s2")
+		require.NoError(t, err)
+		serverRelease()
+	})
+
+	t.Run("without claim storage only locks in process", func(t *testing.T) {
+		release, err := NewSessionLocks().Acquire(ctx, "s1")
+		require.NoError(t, err)
+		release()
+	})
+}
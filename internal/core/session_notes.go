@@ -0,0 +1,53 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// AddSessionNote adds a line to a session's notes (e.g., "Watched: Bluey S02E05")
+// Notes the session already has are not added again, so repeated reports of the same
+// thing are noted once. Notes can be added to ended sessions too.
+func (m *SessionManager) AddSessionNote(ctx context.Context, sessionID string, note string) (*Session, error) {
+	note = strings.Join(strings.Fields(note), " ")
+
+	release, err := m.acquireSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	session, err := m.storage.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if note == "" || slices.Contains(session.NoteLines(), note) {
+		return session, nil
+	}
+
+	if session.Notes != "" {
+		session.Notes += "\n"
+	}
+	session.Notes += note
+	if err := m.storage.UpdateSession(ctx, session); err != nil {
+		m.logger.Error("Failed to update session",
+			"session_id", sessionID,
+			"error", err)
+		return nil, fmt.Errorf("failed to update session: %w", err)
+	}
+
+	m.logger.Debug("Session note added",
+		"session_id", sessionID,
+		"note", note)
+	return session, nil
+}
+
+// NoteLines returns the session's notes, one per line
+func (s *Session) NoteLines() []string {
+	if s.Notes == "" {
+		return nil
+	}
+	return strings.Split(s.Notes, "\n")
+}
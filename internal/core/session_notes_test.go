@@ -0,0 +1,41 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionManager_AddSessionNote(t *testing.T) {
+	storage := newMockStorage()
+	deviceRegistry := newMockDeviceRegistry()
+	driverRegistry := newMockDriverRegistry()
+	manager := NewSessionManager(storage, deviceRegistry, driverRegistry, nil, nil, time.UTC, nil)
+	ctx := context.Background()
+
+	storage.CreateChild(ctx, &Child{ID: "child1", Name: "Alice", WeekdayLimit: 120, WeekendLimit: 120})
+	driverRegistry.addDriver(&mockDriver{name: "aqara"})
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", name: "TV", dtype: "tv", driver: "aqara"})
+	session, err := manager.StartSession(ctx, "tv1", []string{"child1"}, 30)
+	require.NoError(t, err)
+
+	_, err = manager.AddSessionNote(ctx, session.ID, "Watched:  Bluey S02E05\n")
+	require.NoError(t, err)
+	_, err = manager.AddSessionNote(ctx, session.ID, "Watched: Bluey S02E05") // Noted once
+	require.NoError(t, err)
+	_, err = manager.AddSessionNote(ctx, session.ID, " ")
+	require.NoError(t, err)
+	noted, err := manager.AddSessionNote(ctx, session.ID, "Watched: Bluey S02E06")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Watched: Bluey S02E05", "Watched: Bluey S02E06"}, noted.NoteLines())
+
+	stored, err := manager.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Watched: Bluey S02E05\nWatched: Bluey S02E06", stored.Notes)
+
+	_, err = manager.AddSessionNote(ctx, "missing", "Watched: Bluey")
+	assert.ErrorIs(t, err, ErrSessionNotFound)
+}
@@ -0,0 +1,197 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// SessionEvent is a transition of the session state machine
+type SessionEvent string
+
+// Session events. Sessions are created active; stopped sessions are stored as completed.
+const (
+	SessionEventPause  SessionEvent = "pause"  // A break starts (active → paused)
+	SessionEventResume SessionEvent = "resume" // The break is over (paused → active)
+	SessionEventExpire SessionEvent = "expire" // The scheduler ends the session: planned or grace end, downtime, idle (→ expired)
+	SessionEventStop   SessionEvent = "stop"   // Stopped by a parent, a child or a lockdown (→ completed)
+)
+
+// Reasons a session ended, stored in Session.EndReason by the stop and expire transitions
+const (
+	SessionEndParentStop    = "parent_stop"    // Stopped by a parent (API, Telegram bot, HomeKit)
+	SessionEndChildStop     = "child_stop"     // Stopped by the child in the web app
+	SessionEndExpired       = "expired"        // The planned time, including any grace, ran out
+	SessionEndDowntime      = "downtime"       // Downtime started for one of the children
+	SessionEndIdle          = "idle"           // The device agent reported no activity for the idle timeout
+	SessionEndLockdown      = "lockdown"       // Stopped by an emergency lockdown
+	SessionEndDriverFailure = "driver_failure" // The device or its driver is no longer available
+)
+
+// defaultEndReasons is the end reason of a terminal event when the caller did not set one
+var defaultEndReasons = map[SessionEvent]string{
+	SessionEventExpire: SessionEndExpired,
+	SessionEventStop:   SessionEndParentStop,
+}
+
+// endReasonKey is the context key of the end reason set with WithEndReason
+type endReasonKey struct{}
+
+// WithEndReason returns a context under which SessionManager.StopSession records reason
+// as the session's end reason (e.g., SessionEndChildStop); without it stops count as parent stops
+func WithEndReason(ctx context.Context, reason string) context.Context {
+	return context.WithValue(ctx, endReasonKey{}, reason)
+}
+
+// EndReasonFrom returns the end reason set with WithEndReason, or SessionEndParentStop
+func EndReasonFrom(ctx context.Context) string {
+	if reason, ok := ctx.Value(endReasonKey{}).(string); ok && reason != "" {
+		return reason
+	}
+	return SessionEndParentStop
+}
+
+// ErrInvalidTransition is returned for an event the session's status does not allow
+var ErrInvalidTransition = errors.New("invalid session transition")
+
+// sessionTransitions lists the statuses each event is allowed from and the status it leads to
+var sessionTransitions = map[SessionEvent]struct {
+	from []SessionStatus
+	to   SessionStatus
+}{
+	SessionEventPause:  {from: []SessionStatus{SessionStatusActive}, to: SessionStatusPaused},
+	SessionEventResume: {from: []SessionStatus{SessionStatusPaused}, to: SessionStatusActive},
+	SessionEventExpire: {from: []SessionStatus{SessionStatusActive, SessionStatusPaused}, to: SessionStatusExpired},
+	SessionEventStop:   {from: []SessionStatus{SessionStatusActive, SessionStatusPaused}, to: SessionStatusCompleted},
+}
+
+// SessionTransition describes one status change of a session
+type SessionTransition struct {
+	SessionID string
+	Event     SessionEvent
+	From      SessionStatus
+	To        SessionStatus
+	At        time.Time
+}
+
+// SessionGuard can reject a transition the table allows by returning an error
+// Guards must not have side effects: they also run when a caller only checks a transition
+type SessionGuard func(session *Session, transition SessionTransition) error
+
+// SessionHook is called after a transition has been saved
+type SessionHook func(ctx context.Context, session *Session, transition SessionTransition)
+
+// SessionStateMachine is the only place session statuses change. The session manager and the
+// scheduler share one (see SessionManager.SessionStates), so guards and hooks see every transition.
+type SessionStateMachine struct {
+	mu     sync.RWMutex
+	guards []SessionGuard
+	hooks  []SessionHook
+	logger *slog.Logger
+}
+
+// NewSessionStateMachine creates a state machine without guards or hooks
+func NewSessionStateMachine(logger *slog.Logger) *SessionStateMachine {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &SessionStateMachine{logger: logger}
+}
+
+// AddGuard adds a guard checked before every transition
+func (m *SessionStateMachine) AddGuard(guard SessionGuard) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.guards = append(m.guards, guard)
+}
+
+// AddHook adds a hook called after every saved transition
+func (m *SessionStateMachine) AddHook(hook SessionHook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook)
+}
+
+// Check reports whether event can be applied to the session now, without changing it
+// Callers with side effects (e.g. stopping the device) check before them and Apply after.
+func (m *SessionStateMachine) Check(session *Session, event SessionEvent) error {
+	_, err := m.transition(session, event)
+	return err
+}
+
+// Apply moves the session to the status reached by event and saves it with save
+// (e.g. Storage.UpdateSession, with other changes made to the session beforehand).
+// Terminal events set Session.EndReason to the event's default unless the caller set it.
+// Rejected transitions and failed saves leave the status unchanged; hooks run only after a save.
+func (m *SessionStateMachine) Apply(ctx context.Context, session *Session, event SessionEvent, save func(context.Context, *Session) error) (*SessionTransition, error) {
+	transition, err := m.transition(session, event)
+	if err != nil {
+		return nil, err
+	}
+
+	endReason := session.EndReason
+	if reason, ok := defaultEndReasons[event]; ok && session.EndReason == "" {
+		session.EndReason = reason
+	}
+	session.Status = transition.To
+	if err := save(ctx, session); err != nil {
+		session.Status = transition.From
+		session.EndReason = endReason
+		return nil, err
+	}
+
+	m.logger.Info("Session transition",
+		"session_id", session.ID,
+		"event", event,
+		"from", transition.From,
+		"to", transition.To,
+		"end_reason", session.EndReason)
+
+	m.mu.RLock()
+	hooks := m.hooks
+	m.mu.RUnlock()
+	for _, hook := range hooks {
+		hook(ctx, session, transition)
+	}
+	return &transition, nil
+}
+
+// transition looks up the event in the transition table and runs the guards
+func (m *SessionStateMachine) transition(session *Session, event SessionEvent) (SessionTransition, error) {
+	rule, ok := sessionTransitions[event]
+	if !ok {
+		return SessionTransition{}, fmt.Errorf("%w: unknown event %q", ErrInvalidTransition, event)
+	}
+
+	allowed := false
+	for _, from := range rule.from {
+		if session.Status == from {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return SessionTransition{}, fmt.Errorf("%w: cannot %s a %s session", ErrInvalidTransition, event, session.Status)
+	}
+
+	transition := SessionTransition{
+		SessionID: session.ID,
+		Event:     event,
+		From:      session.Status,
+		To:        rule.to,
+		At:        Now(),
+	}
+
+	m.mu.RLock()
+	guards := m.guards
+	m.mu.RUnlock()
+	for _, guard := range guards {
+		if err := guard(session, transition); err != nil {
+			return SessionTransition{}, err
+		}
+	}
+	return transition, nil
+}
@@ -0,0 +1,206 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSessionStateMachine_Transitions(t *testing.T) {
+	states := NewSessionStateMachine(nil)
+
+	tests := []struct {
+		from  SessionStatus
+		event SessionEvent
+		to    SessionStatus // Empty when the transition is illegal
+	}{
+		{SessionStatusActive, SessionEventPause, SessionStatusPaused},
+		{SessionStatusPaused, SessionEventResume, SessionStatusActive},
+		{SessionStatusActive, SessionEventExpire, SessionStatusExpired},
+		{SessionStatusPaused, SessionEventExpire, SessionStatusExpired},
+		{SessionStatusActive, SessionEventStop, SessionStatusCompleted},
+		{SessionStatusPaused, SessionEventStop, SessionStatusCompleted},
+		{SessionStatusPaused, SessionEventPause, ""},
+		{SessionStatusActive, SessionEventResume, ""},
+		{SessionStatusCompleted, SessionEventStop, ""},
+		{SessionStatusExpired, SessionEventStop, ""},
+		{SessionStatusCompleted, SessionEventResume, ""},
+		{SessionStatusExpired, SessionEventExpire, ""},
+		{SessionStatusActive, SessionEvent("rewind"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.from)+"/"+string(tt.event), func(t *testing.T) {
+			session := &Session{ID: "s1", Status: tt.from}
+			saved := 0
+			save := func(ctx context.Context, s *Session) error {
+				saved++
+				return nil
+			}
+
+			transition, err := states.Apply(context.Background(), session, tt.event, save)
+			if tt.to == "" {
+				assert.ErrorIs(t, err, ErrInvalidTransition)
+				assert.Equal(t, tt.from, session.Status, "status unchanged")
+				assert.Zero(t, saved)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.to, session.Status)
+			assert.Equal(t, 1, saved)
+			assert.Equal(t, SessionTransition{SessionID: "s1", Event: tt.event, From: tt.from, To: tt.to, At: transition.At}, *transition)
+		})
+	}
+}
+
+func TestSessionStateMachine_EndReason(t *testing.T) {
+	states := NewSessionStateMachine(nil)
+	ctx := context.Background()
+	save := func(ctx context.Context, s *Session) error { return nil }
+
+	// Terminal events default the end reason
+	expired := &Session{ID: "s1", Status: SessionStatusActive}
+	_, err := states.Apply(ctx, expired, SessionEventExpire, save)
+	require.NoError(t, err)
+	assert.Equal(t, SessionEndExpired, expired.EndReason)
+
+	stopped := &Session{ID: "s2", Status: SessionStatusPaused}
+	_, err = states.Apply(ctx, stopped, SessionEventStop, save)
+	require.NoError(t, err)
+	assert.Equal(t, SessionEndParentStop, stopped.EndReason)
+
+	// A reason set by the caller is kept
+	downtime := &Session{ID: "s3", Status: SessionStatusActive, EndReason: SessionEndDowntime}
+	_, err = states.Apply(ctx, downtime, SessionEventExpire, save)
+	require.NoError(t, err)
+	assert.Equal(t, SessionEndDowntime, downtime.EndReason)
+
+	// Other events leave it empty
+	paused := &Session{ID: "s4", Status: SessionStatusActive}
+	_, err = states.Apply(ctx, paused, SessionEventPause, save)
+	require.NoError(t, err)
+	assert.Empty(t, paused.EndReason)
+
+	// A failed save restores it with the status
+	failed := &Session{ID: "s5", Status: SessionStatusActive}
+	_, err = states.Apply(ctx, failed, SessionEventStop, func(ctx context.Context, s *Session) error { return errors.New("disk full") })
+	require.Error(t, err)
+	assert.Equal(t, SessionStatusActive, failed.Status)
+	assert.Empty(t, failed.EndReason)
+
+	assert.Equal(t, SessionEndParentStop, EndReasonFrom(ctx))
+	assert.Equal(t, SessionEndChildStop, EndReasonFrom(WithEndReason(ctx, SessionEndChildStop)))
+}
+
+func TestSessionStateMachine_GuardsAndHooks(t *testing.T) {
+	ctx := context.Background()
+	states := NewSessionStateMachine(nil)
+	save := func(ctx context.Context, s *Session) error { return nil }
+
+	var seen []SessionTransition
+	states.AddHook(func(ctx context.Context, session *Session, transition SessionTransition) {
+		seen = append(seen, transition)
+	})
+
+	errMovie := errors.New("movie sessions have no breaks")
+	states.AddGuard(func(session *Session, transition SessionTransition) error {
+		if session.IsMovieSession && transition.Event == SessionEventPause {
+			return errMovie
+		}
+		return nil
+	})
+
+	t.Run("guard rejects", func(t *testing.T) {
+		session := &Session{ID: "movie", Status: SessionStatusActive, IsMovieSession: true}
+		assert.ErrorIs(t, states.Check(session, SessionEventPause), errMovie)
+
+		_, err := states.Apply(ctx, session, SessionEventPause, save)
+		assert.ErrorIs(t, err, errMovie)
+		assert.Equal(t, SessionStatusActive, session.Status)
+		assert.Empty(t, seen)
+	})
+
+	t.Run("check does not change the session", func(t *testing.T) {
+		session := &Session{ID: "s1", Status: SessionStatusActive}
+		require.NoError(t, states.Check(session, SessionEventStop))
+		assert.Equal(t, SessionStatusActive, session.Status)
+		assert.Empty(t, seen)
+	})
+
+	t.Run("failed save reverts and skips hooks", func(t *testing.T) {
+		session := &Session{ID: "s1", Status: SessionStatusActive}
+		errSave := errors.New("disk full")
+		_, err := states.Apply(ctx, session, SessionEventStop, func(ctx context.Context, s *Session) error {
+			assert.Equal(t, SessionStatusCompleted, s.Status, "saved with the new status")
+			return errSave
+		})
+		assert.ErrorIs(t, err, errSave)
+		assert.Equal(t, SessionStatusActive, session.Status)
+		assert.Empty(t, seen)
+	})
+
+	t.Run("hooks see saved transitions", func(t *testing.T) {
+		session := &Session{ID: "s1", Status: SessionStatusActive}
+		_, err := states.Apply(ctx, session, SessionEventPause, save)
+		require.NoError(t, err)
+		_, err = states.Apply(ctx, session, SessionEventStop, save)
+		require.NoError(t, err)
+
+		require.Len(t, seen, 2)
+		assert.Equal(t, SessionEventPause, seen[0].Event)
+		assert.Equal(t, SessionStatusPaused, seen[1].From)
+		assert.Equal(t, SessionStatusCompleted, seen[1].To)
+	})
+}
+
+func TestSessionManager_StopSession_StateMachine(t *testing.T) {
+	ctx := context.Background()
+	storage := newMockStorage()
+	deviceRegistry := newMockDeviceRegistry()
+	driverRegistry := newMockDriverRegistry()
+	manager := NewSessionManager(storage, deviceRegistry, driverRegistry, nil, nil, nil, nil)
+
+	storage.CreateChild(ctx, &Child{ID: "child1", Name: "Alice", WeekdayLimit: 120, WeekendLimit: 120})
+	driver := &mockDriver{name: "aqara"}
+	driverRegistry.addDriver(driver)
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", name: "TV", dtype: "tv", driver: "aqara"})
+
+	var events []SessionEvent
+	manager.SessionStates().AddHook(func(ctx context.Context, session *Session, transition SessionTransition) {
+		events = append(events, transition.Event)
+	})
+
+	session, err := manager.StartSession(ctx, "tv1", []string{"child1"}, 30)
+	require.NoError(t, err)
+
+	// A guard rejection happens before the device is touched
+	errHold := errors.New("on hold")
+	reject := true
+	manager.SessionStates().AddGuard(func(session *Session, transition SessionTransition) error {
+		if reject {
+			return errHold
+		}
+		return nil
+	})
+	assert.ErrorIs(t, manager.StopSession(ctx, session.ID), errHold)
+	assert.False(t, driver.stopCalled)
+
+	reject = false
+	require.NoError(t, manager.StopSession(WithEndReason(ctx, SessionEndChildStop), session.ID))
+	assert.True(t, driver.stopCalled)
+	assert.Equal(t, []SessionEvent{SessionEventStop}, events)
+
+	stopped, err := storage.GetSession(ctx, session.ID)
+	require.NoError(t, err)
+	assert.Equal(t, SessionEndChildStop, stopped.EndReason)
+	require.NotNil(t, stopped.ActualDuration)
+	assert.Zero(t, *stopped.ActualDuration, "stopped right after starting")
+
+	// Stopping again is not a transition
+	assert.ErrorIs(t, manager.StopSession(ctx, session.ID), ErrSessionNotActive)
+	assert.Len(t, events, 1)
+}
@@ -0,0 +1,126 @@
+package core
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Suggestion kinds describe why a duration was suggested
+const (
+	SuggestionKindPreset        = "preset"         // Standard preset (5, 15, 30, 60, 120)
+	SuggestionKindHalf          = "half"           // Half of the maximum available time
+	SuggestionKindRemaining     = "remaining"      // All remaining time for today
+	SuggestionKindUntilDowntime = "until_downtime" // Time left until downtime starts
+)
+
+// DefaultDurationPresets are the standard session lengths offered when they fit
+var DefaultDurationPresets = []int{5, 15, 30, 60, 120}
+
+// minHalfSuggestion is the smallest "half" suggestion worth offering
+const minHalfSuggestion = 5
+
+// DurationSuggestion is a single suggested session duration
+type DurationSuggestion struct {
+	Minutes int
+	Kind    string
+}
+
+// DurationSuggestions holds suggested session durations for a child
+type DurationSuggestions struct {
+	ChildID              string
+	RemainingMinutes     int  // Remaining daily time
+	MinutesUntilDowntime *int // nil if downtime does not apply to the child today
+	MaxMinutes           int  // Longest session that can be started right now
+	Options              []DurationSuggestion
+}
+
+// BuildDurationSuggestions computes duration options from remaining time
+// untilDowntime is nil when downtime does not limit the session
+// Options are sorted ascending, de-duplicated and never exceed the maximum
+func BuildDurationSuggestions(remaining int, untilDowntime *int) (int, []DurationSuggestion) {
+	maxMinutes := remaining
+	if untilDowntime != nil && *untilDowntime < maxMinutes {
+		maxMinutes = *untilDowntime
+	}
+	if maxMinutes <= 0 {
+		return 0, []DurationSuggestion{}
+	}
+
+	// Special kinds take precedence over presets with the same duration
+	byMinutes := make(map[int]string)
+	for _, preset := range DefaultDurationPresets {
+		if preset < maxMinutes {
+			byMinutes[preset] = SuggestionKindPreset
+		}
+	}
+
+	if half := maxMinutes / 2; half >= minHalfSuggestion {
+		byMinutes[half] = SuggestionKindHalf
+	}
+
+	if untilDowntime != nil && *untilDowntime == maxMinutes {
+		byMinutes[maxMinutes] = SuggestionKindUntilDowntime
+	} else {
+		byMinutes[maxMinutes] = SuggestionKindRemaining
+	}
+
+	options := make([]DurationSuggestion, 0, len(byMinutes))
+	for minutes, kind := range byMinutes {
+		options = append(options, DurationSuggestion{Minutes: minutes, Kind: kind})
+	}
+	sort.Slice(options, func(i, j int) bool {
+		return options[i].Minutes < options[j].Minutes
+	})
+
+	return maxMinutes, options
+}
+
+// GetDurationSuggestions returns sensible session durations for a child
+// based on remaining daily time and the next downtime window
+func (m *SessionManager) GetDurationSuggestions(ctx context.Context, childID string) (*DurationSuggestions, error) {
+	child, err := m.storage.GetChild(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := Now().In(m.timezone)
+
+	remaining, err := m.calculator.GetRemainingTime(ctx, childID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	untilDowntime := m.minutesUntilDowntime(ctx, child, now)
+	maxMinutes, options := BuildDurationSuggestions(remaining.RemainingTotal, untilDowntime)
+
+	return &DurationSuggestions{
+		ChildID:              childID,
+		RemainingMinutes:     remaining.RemainingTotal,
+		MinutesUntilDowntime: untilDowntime,
+		MaxMinutes:           maxMinutes,
+		Options:              options,
+	}, nil
+}
+
+// minutesUntilDowntime returns minutes until downtime starts for the child
+// Returns nil if downtime does not apply (disabled, child opted out, or skipped today)
+func (m *SessionManager) minutesUntilDowntime(ctx context.Context, child *Child, now time.Time) *int {
+	if m.downtime == nil || !m.downtime.IsEnabled() || !child.DowntimeEnabled {
+		return nil
+	}
+	downtime := m.downtime.ForChild(child)
+	if downtime.IsDowntimeSkippedToday(ctx, now) {
+		return nil
+	}
+
+	minutes := 0
+	if !downtime.IsInDowntimeWithContext(ctx, now) {
+		next := downtime.GetNextDowntimeStart(now)
+		if next.IsZero() {
+			return nil
+		}
+		minutes = int(next.Sub(now).Minutes())
+	}
+	return &minutes
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func intPtr(v int) *int {
+	return &v
+}
+
+func TestBuildDurationSuggestions(t *testing.T) {
+	tests := []struct {
+		name          string
+		remaining     int
+		untilDowntime *int
+		wantMax       int
+		want          []DurationSuggestion
+	}{
+		{
+			name:      "no time left",
+			remaining: 0,
+			wantMax:   0,
+			want:      []DurationSuggestion{},
+		},
+		{
+			name:      "12 minutes left",
+			remaining: 12,
+			wantMax:   12,
+			want: []DurationSuggestion{
+				{Minutes: 5, Kind: SuggestionKindPreset},
+				{Minutes: 6, Kind: SuggestionKindHalf},
+				{Minutes: 12, Kind: SuggestionKindRemaining},
+			},
+		},
+		{
+			name:      "half replaces matching preset",
+			remaining: 60,
+			wantMax:   60,
+			want: []DurationSuggestion{
+				{Minutes: 5, Kind: SuggestionKindPreset},
+				{Minutes: 15, Kind: SuggestionKindPreset},
+				{Minutes: 30, Kind: SuggestionKindHalf},
+				{Minutes: 60, Kind: SuggestionKindRemaining},
+			},
+		},
+		{
+			name:          "downtime comes before limit",
+			remaining:     90,
+			untilDowntime: intPtr(40),
+			wantMax:       40,
+			want: []DurationSuggestion{
+				{Minutes: 5, Kind: SuggestionKindPreset},
+				{Minutes: 15, Kind: SuggestionKindPreset},
+				{Minutes: 20, Kind: SuggestionKindHalf},
+				{Minutes: 30, Kind: SuggestionKindPreset},
+				{Minutes: 40, Kind: SuggestionKindUntilDowntime},
+			},
+		},
+		{
+			name:          "limit comes before downtime",
+			remaining:     8,
+			untilDowntime: intPtr(200),
+			wantMax:       8,
+			want: []DurationSuggestion{
+				{Minutes: 5, Kind: SuggestionKindPreset},
+				{Minutes: 8, Kind: SuggestionKindRemaining},
+			},
+		},
+		{
+			name:          "already in downtime",
+			remaining:     60,
+			untilDowntime: intPtr(0),
+			wantMax:       0,
+			want:          []DurationSuggestion{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			max, options := BuildDurationSuggestions(tt.remaining, tt.untilDowntime)
+			assert.Equal(t, tt.wantMax, max)
+			assert.Equal(t, tt.want, options)
+		})
+	}
+}
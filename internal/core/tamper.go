@@ -0,0 +1,119 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"metron/internal/idgen"
+)
+
+// Tamper event types reported by device agents
+const (
+	TamperClockChange  = "clock_change"   // The device clock jumped relative to server time
+	TamperProcessKill  = "process_kill"   // The agent's previous run ended without a clean shutdown (e.g., killed)
+	TamperSafeModeBoot = "safe_mode_boot" // The device booted into safe mode
+)
+
+// ErrInvalidTamperEventType is returned for unknown tamper event types
+var ErrInvalidTamperEventType = errors.New("invalid tamper event type")
+
+// tamperEventTypes lists the accepted tamper event types
+var tamperEventTypes = map[string]bool{
+	TamperClockChange:  true,
+	TamperProcessKill:  true,
+	TamperSafeModeBoot: true,
+}
+
+// IsValidTamperEventType returns true if eventType is a known tamper event type
+func IsValidTamperEventType(eventType string) bool {
+	return tamperEventTypes[eventType]
+}
+
+// TamperEvent is a possible tampering attempt reported by a device agent
+type TamperEvent struct {
+	ID         string
+	DeviceID   string
+	Type       string
+	Details    string    // Human-readable description from the agent
+	OccurredAt time.Time // Agent clock; may be wrong after a clock change
+	ReceivedAt time.Time // Server clock
+}
+
+// TamperEventStorage defines the interface for tamper event persistence
+type TamperEventStorage interface {
+	CreateTamperEvent(ctx context.Context, event *TamperEvent) error
+	ListTamperEvents(ctx context.Context, since time.Time) ([]*TamperEvent, error) // Received after since, oldest first
+}
+
+// TamperService records tamper events reported by agents
+type TamperService struct {
+	storage TamperEventStorage
+	logger  *slog.Logger
+}
+
+// NewTamperService creates a new tamper service
+func NewTamperService(storage TamperEventStorage, logger *slog.Logger) *TamperService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &TamperService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// Report stores a tamper event from a device agent
+// A zero occurredAt defaults to the time the event is received
+func (s *TamperService) Report(ctx context.Context, deviceID, eventType, details string, occurredAt time.Time) (*TamperEvent, error) {
+	if !IsValidTamperEventType(eventType) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTamperEventType, eventType)
+	}
+
+	now := Now()
+	if occurredAt.IsZero() {
+		occurredAt = now
+	}
+
+	event := &TamperEvent{
+		ID:         idgen.NewTamperEvent(),
+		DeviceID:   deviceID,
+		Type:       eventType,
+		Details:    details,
+		OccurredAt: occurredAt,
+		ReceivedAt: now,
+	}
+	if err := s.storage.CreateTamperEvent(ctx, event); err != nil {
+		return nil, err
+	}
+
+	s.logger.Warn("Tamper event reported",
+		"event_id", event.ID,
+		"device_id", deviceID,
+		"type", eventType,
+		"details", details,
+		"occurred_at", occurredAt)
+
+	return event, nil
+}
+
+// List returns the events received after since, oldest first, optionally for one device
+func (s *TamperService) List(ctx context.Context, since time.Time, deviceID string) ([]*TamperEvent, error) {
+	events, err := s.storage.ListTamperEvents(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	if deviceID == "" {
+		return events, nil
+	}
+
+	filtered := make([]*TamperEvent, 0, len(events))
+	for _, event := range events {
+		if event.DeviceID == deviceID {
+			filtered = append(filtered, event)
+		}
+	}
+	return filtered, nil
+}
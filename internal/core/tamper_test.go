@@ -0,0 +1,79 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockTamperEventStorage struct {
+	events []*TamperEvent
+}
+
+func (m *mockTamperEventStorage) CreateTamperEvent(ctx context.Context, event *TamperEvent) error {
+	copied := *event
+	m.events = append(m.events, &copied)
+	return nil
+}
+
+func (m *mockTamperEventStorage) ListTamperEvents(ctx context.Context, since time.Time) ([]*TamperEvent, error) {
+	var events []*TamperEvent
+	for _, event := range m.events {
+		if event.ReceivedAt.After(since) {
+			copied := *event
+			events = append(events, &copied)
+		}
+	}
+	return events, nil
+}
+
+func TestTamperService_Report(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	original := Now
+	Now = func() time.Time { return now }
+	t.Cleanup(func() { Now = original })
+
+	storage := &mockTamperEventStorage{}
+	service := NewTamperService(storage, nil)
+	ctx := context.Background()
+
+	occurredAt := now.Add(-3 * time.Hour)
+	event, err := service.Report(ctx, "win-pc1", TamperClockChange, "clock moved back 3h", occurredAt)
+	require.NoError(t, err)
+	assert.NotEmpty(t, event.ID)
+	assert.Equal(t, occurredAt, event.OccurredAt)
+	assert.Equal(t, now, event.ReceivedAt)
+	require.Len(t, storage.events, 1)
+
+	// A missing occurrence time defaults to the receive time
+	event, err = service.Report(ctx, "win-pc2", TamperSafeModeBoot, "", time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, now, event.OccurredAt)
+
+	_, err = service.Report(ctx, "win-pc1", "uninstall", "", time.Time{})
+	assert.ErrorIs(t, err, ErrInvalidTamperEventType)
+	assert.Len(t, storage.events, 2)
+}
+
+func TestTamperService_List(t *testing.T) {
+	now := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+	storage := &mockTamperEventStorage{events: []*TamperEvent{
+		{ID: "tmp_1", DeviceID: "win-pc1", Type: TamperProcessKill, ReceivedAt: now.Add(-time.Hour)},
+		{ID: "tmp_2", DeviceID: "win-pc2", Type: TamperProcessKill, ReceivedAt: now},
+		{ID: "tmp_3", DeviceID: "win-pc1", Type: TamperClockChange, ReceivedAt: now},
+	}}
+	service := NewTamperService(storage, nil)
+	ctx := context.Background()
+
+	events, err := service.List(ctx, time.Time{}, "")
+	require.NoError(t, err)
+	assert.Len(t, events, 3)
+
+	events, err = service.List(ctx, now.Add(-time.Hour), "win-pc1")
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "tmp_3", events[0].ID)
+}
@@ -0,0 +1,37 @@
+package core
+
+import "time"
+
+// ValidateTimezone checks that name is empty (no override) or a loadable IANA timezone
+func ValidateTimezone(name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, err := time.LoadLocation(name); err != nil {
+		return ErrInvalidTimezone
+	}
+	return nil
+}
+
+// ResolveTimezone returns the location of the first non-empty timezone name,
+// or fallback if none is set
+// Overrides are validated on input; a name that fails to load is skipped
+func ResolveTimezone(fallback *time.Location, names ...string) *time.Location {
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		if loc, err := time.LoadLocation(name); err == nil {
+			return loc
+		}
+	}
+	return fallback
+}
+
+// UsageDate returns the calendar day containing t in loc, as midnight in the server timezone
+// Allocations and usage summaries are keyed by this date, so a child living in another
+// timezone gets a fresh day at their own midnight while storage keys stay comparable
+func UsageDate(t time.Time, loc, server *time.Location) time.Time {
+	year, month, day := t.In(loc).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, server)
+}
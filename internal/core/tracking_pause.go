@@ -0,0 +1,236 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"metron/internal/idgen"
+)
+
+// Tracking pause errors
+var (
+	ErrTrackingAlreadyPaused = errors.New("tracking is already paused")
+	ErrTrackingNotPaused     = errors.New("tracking is not paused")
+	ErrInvalidResumeTime     = errors.New("resume time must be in the future")
+)
+
+// TrackingPauseResumedBySchedule is recorded as ResumedBy when a pause ends at its ResumesAt
+const TrackingPauseResumedBySchedule = "schedule"
+
+// TrackingPause suspends time tracking (vacation mode) for one child or for everyone:
+// usage is not recorded, limits and downtime are not enforced, and during a global
+// pause agent-controlled devices stay unlocked
+type TrackingPause struct {
+	ID        string
+	ChildID   string // Empty for a global pause covering all children
+	Reason    string
+	PausedBy  string // Who paused tracking (e.g., "telegram:12345", "api")
+	StartedAt time.Time
+	ResumesAt *time.Time // Automatic re-enable; nil pauses until resumed manually
+	ResumedAt *time.Time // nil until resumed
+	ResumedBy string
+}
+
+// IsGlobal returns true if the pause covers all children
+func (p *TrackingPause) IsGlobal() bool {
+	return p.ChildID == ""
+}
+
+// IsActive returns true if the pause has not been resumed and its resume time has not passed
+func (p *TrackingPause) IsActive(now time.Time) bool {
+	if p.ResumedAt != nil {
+		return false
+	}
+	return p.ResumesAt == nil || now.Before(*p.ResumesAt)
+}
+
+// Covers returns true if the pause applies to the child
+func (p *TrackingPause) Covers(childID string) bool {
+	return p.IsGlobal() || p.ChildID == childID
+}
+
+// FindTrackingPause returns the active pause covering the child, or nil
+// A child-specific pause is preferred over a global one; an empty childID matches global pauses only
+func FindTrackingPause(pauses []*TrackingPause, childID string, now time.Time) *TrackingPause {
+	var global *TrackingPause
+	for _, pause := range pauses {
+		if !pause.IsActive(now) {
+			continue
+		}
+		if pause.IsGlobal() {
+			if global == nil {
+				global = pause
+			}
+			continue
+		}
+		if childID != "" && pause.ChildID == childID {
+			return pause
+		}
+	}
+	return global
+}
+
+// TrackingPauseStorage defines the interface for tracking pause persistence
+type TrackingPauseStorage interface {
+	ListActiveTrackingPauses(ctx context.Context) ([]*TrackingPause, error) // Pauses not resumed yet
+	CreateTrackingPause(ctx context.Context, pause *TrackingPause) error
+	UpdateTrackingPause(ctx context.Context, pause *TrackingPause) error
+	GetChild(ctx context.Context, id string) (*Child, error)
+}
+
+// TrackingPauseService manages vacation mode
+type TrackingPauseService struct {
+	storage TrackingPauseStorage
+	logger  *slog.Logger
+	mu      sync.Mutex // Serializes pause/resume
+}
+
+// NewTrackingPauseService creates a new tracking pause service
+func NewTrackingPauseService(storage TrackingPauseStorage, logger *slog.Logger) *TrackingPauseService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &TrackingPauseService{
+		storage: storage,
+		logger:  logger,
+	}
+}
+
+// Active returns the active pauses
+// Pauses whose resume time has passed are recorded as resumed on the way
+func (s *TrackingPauseService) Active(ctx context.Context) ([]*TrackingPause, error) {
+	pauses, err := s.storage.ListActiveTrackingPauses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := Now()
+	active := make([]*TrackingPause, 0, len(pauses))
+	for _, pause := range pauses {
+		if pause.IsActive(now) {
+			active = append(active, pause)
+			continue
+		}
+
+		resumedAt := *pause.ResumesAt
+		pause.ResumedAt = &resumedAt
+		pause.ResumedBy = TrackingPauseResumedBySchedule
+		if err := s.storage.UpdateTrackingPause(ctx, pause); err != nil {
+			s.logger.Error("Failed to record scheduled tracking resume",
+				"pause_id", pause.ID,
+				"error", err)
+			continue
+		}
+
+		s.logger.Info("Tracking resumed on schedule",
+			"pause_id", pause.ID,
+			"child_id", pause.ChildID)
+	}
+
+	return active, nil
+}
+
+// PauseFor returns the active pause covering the child, or nil if the child is tracked
+func (s *TrackingPauseService) PauseFor(ctx context.Context, childID string) (*TrackingPause, error) {
+	pauses, err := s.Active(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return FindTrackingPause(pauses, childID, Now()), nil
+}
+
+// Pause stops tracking for a child, or for everyone if childID is empty, until resumesAt
+// (nil pauses until Resume is called)
+// Returns ErrTrackingAlreadyPaused if the same scope is already paused
+func (s *TrackingPauseService) Pause(ctx context.Context, childID, pausedBy, reason string, resumesAt *time.Time) (*TrackingPause, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := Now()
+	if resumesAt != nil && !resumesAt.After(now) {
+		return nil, ErrInvalidResumeTime
+	}
+
+	if childID != "" {
+		if _, err := s.storage.GetChild(ctx, childID); err != nil {
+			return nil, err
+		}
+	}
+
+	current, err := s.findScope(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil {
+		return current, ErrTrackingAlreadyPaused
+	}
+
+	pause := &TrackingPause{
+		ID:        idgen.NewTrackingPause(),
+		ChildID:   childID,
+		Reason:    reason,
+		PausedBy:  pausedBy,
+		StartedAt: now,
+		ResumesAt: resumesAt,
+	}
+	if err := s.storage.CreateTrackingPause(ctx, pause); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Tracking paused",
+		"pause_id", pause.ID,
+		"child_id", childID,
+		"paused_by", pausedBy,
+		"reason", reason,
+		"resumes_at", resumesAt)
+
+	return pause, nil
+}
+
+// Resume re-enables tracking for a child, or for everyone if childID is empty
+// Only the pause with exactly that scope is ended: resuming a child does not end a global pause
+// Returns ErrTrackingNotPaused if there is nothing to resume
+func (s *TrackingPauseService) Resume(ctx context.Context, childID, resumedBy string) (*TrackingPause, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	pause, err := s.findScope(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+	if pause == nil {
+		return nil, ErrTrackingNotPaused
+	}
+
+	now := Now()
+	pause.ResumedAt = &now
+	pause.ResumedBy = resumedBy
+	if err := s.storage.UpdateTrackingPause(ctx, pause); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Tracking resumed",
+		"pause_id", pause.ID,
+		"child_id", childID,
+		"resumed_by", resumedBy,
+		"duration", now.Sub(pause.StartedAt).Round(time.Second))
+
+	return pause, nil
+}
+
+// findScope returns the active pause for exactly the given scope (a child or global)
+func (s *TrackingPauseService) findScope(ctx context.Context, childID string) (*TrackingPause, error) {
+	pauses, err := s.Active(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, pause := range pauses {
+		if pause.ChildID == childID {
+			return pause, nil
+		}
+	}
+	return nil, nil
+}
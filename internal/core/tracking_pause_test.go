@@ -0,0 +1,176 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockTrackingPauseStorage struct {
+	*mockStorage
+	pauses []*TrackingPause
+}
+
+func newMockTrackingPauseStorage(storage *mockStorage) *mockTrackingPauseStorage {
+	return &mockTrackingPauseStorage{mockStorage: storage}
+}
+
+func (m *mockTrackingPauseStorage) ListActiveTrackingPauses(ctx context.Context) ([]*TrackingPause, error) {
+	var pauses []*TrackingPause
+	for _, pause := range m.pauses {
+		if pause.ResumedAt == nil {
+			copied := *pause
+			pauses = append(pauses, &copied)
+		}
+	}
+	return pauses, nil
+}
+
+func (m *mockTrackingPauseStorage) CreateTrackingPause(ctx context.Context, pause *TrackingPause) error {
+	copied := *pause
+	m.pauses = append(m.pauses, &copied)
+	return nil
+}
+
+func (m *mockTrackingPauseStorage) UpdateTrackingPause(ctx context.Context, pause *TrackingPause) error {
+	for i, existing := range m.pauses {
+		if existing.ID == pause.ID {
+			copied := *pause
+			m.pauses[i] = &copied
+		}
+	}
+	return nil
+}
+
+func TestFindTrackingPause(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Hour)
+	global := &TrackingPause{ID: "global"}
+	child := &TrackingPause{ID: "child", ChildID: "child1"}
+	expired := &TrackingPause{ID: "expired", ChildID: "child2", ResumesAt: &past}
+	pauses := []*TrackingPause{global, child, expired}
+
+	assert.Equal(t, child, FindTrackingPause(pauses, "child1", now), "child pause is preferred")
+	assert.Equal(t, global, FindTrackingPause(pauses, "child2", now), "expired child pause falls back to global")
+	assert.Equal(t, global, FindTrackingPause(pauses, "", now), "empty child matches global only")
+	assert.Nil(t, FindTrackingPause([]*TrackingPause{child}, "", now))
+	assert.Nil(t, FindTrackingPause([]*TrackingPause{child}, "child3", now))
+}
+
+func TestTrackingPauseService_PauseAndResume(t *testing.T) {
+	ctx := context.Background()
+	storage := newMockStorage()
+	storage.CreateChild(ctx, &Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 60})
+	pauseStorage := newMockTrackingPauseStorage(storage)
+	service := NewTrackingPauseService(pauseStorage, nil)
+
+	// Per-child pause
+	resumesAt := time.Now().Add(48 * time.Hour)
+	pause, err := service.Pause(ctx, "child1", "telegram:42", "grandma", &resumesAt)
+	require.NoError(t, err)
+	assert.Equal(t, "child1", pause.ChildID)
+	assert.False(t, pause.IsGlobal())
+
+	_, err = service.Pause(ctx, "child1", "api", "", nil)
+	assert.ErrorIs(t, err, ErrTrackingAlreadyPaused)
+
+	_, err = service.Pause(ctx, "missing", "api", "", nil)
+	assert.ErrorIs(t, err, ErrChildNotFound)
+
+	past := time.Now().Add(-time.Minute)
+	_, err = service.Pause(ctx, "", "api", "", &past)
+	assert.ErrorIs(t, err, ErrInvalidResumeTime)
+
+	// Global pause is a separate scope
+	global, err := service.Pause(ctx, "", "api", "holiday", nil)
+	require.NoError(t, err)
+	assert.True(t, global.IsGlobal())
+
+	found, err := service.PauseFor(ctx, "child1")
+	require.NoError(t, err)
+	assert.Equal(t, pause.ID, found.ID)
+
+	// Resuming the child leaves the global pause in place
+	resumed, err := service.Resume(ctx, "child1", "api")
+	require.NoError(t, err)
+	assert.NotNil(t, resumed.ResumedAt)
+
+	found, err = service.PauseFor(ctx, "child1")
+	require.NoError(t, err)
+	assert.Equal(t, global.ID, found.ID)
+
+	_, err = service.Resume(ctx, "child1", "api")
+	assert.ErrorIs(t, err, ErrTrackingNotPaused)
+
+	_, err = service.Resume(ctx, "", "api")
+	require.NoError(t, err)
+
+	found, err = service.PauseFor(ctx, "child1")
+	require.NoError(t, err)
+	assert.Nil(t, found)
+}
+
+func TestTrackingPauseService_ScheduledResume(t *testing.T) {
+	ctx := context.Background()
+	pauseStorage := newMockTrackingPauseStorage(newMockStorage())
+	service := NewTrackingPauseService(pauseStorage, nil)
+
+	resumesAt := time.Now().Add(-time.Minute)
+	pauseStorage.pauses = []*TrackingPause{
+		{ID: "tpz_1", PausedBy: "api", StartedAt: time.Now().Add(-7 * 24 * time.Hour), ResumesAt: &resumesAt},
+	}
+
+	active, err := service.Active(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, active)
+
+	// The pause is recorded as resumed at its resume time
+	require.Len(t, pauseStorage.pauses, 1)
+	require.NotNil(t, pauseStorage.pauses[0].ResumedAt)
+	assert.True(t, pauseStorage.pauses[0].ResumedAt.Equal(resumesAt))
+	assert.Equal(t, TrackingPauseResumedBySchedule, pauseStorage.pauses[0].ResumedBy)
+}
+
+func TestSessionManager_TrackingPaused(t *testing.T) {
+	ctx := context.Background()
+	storage := newMockStorage()
+	deviceRegistry := newMockDeviceRegistry()
+	driverRegistry := newMockDriverRegistry()
+	manager := NewSessionManager(storage, deviceRegistry, driverRegistry, nil, nil, nil, nil)
+
+	storage.CreateChild(ctx, &Child{ID: "child1", Name: "Alice", WeekdayLimit: 30, WeekendLimit: 30})
+	driverRegistry.addDriver(&mockDriver{name: "aqara"})
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", name: "TV", dtype: "tv", driver: "aqara"})
+
+	// The daily limit is used up
+	today := time.Now()
+	require.NoError(t, storage.IncrementDailyUsageSummary(ctx, "child1", today, 30))
+	_, err := manager.StartSession(ctx, "tv1", []string{"child1"}, 60)
+	assert.ErrorIs(t, err, ErrInsufficientTime)
+
+	trackingPause := NewTrackingPauseService(newMockTrackingPauseStorage(storage), nil)
+	manager.SetTrackingPause(trackingPause)
+	_, err = trackingPause.Pause(ctx, "child1", "api", "vacation", nil)
+	require.NoError(t, err)
+
+	// Limits are not enforced while paused
+	session, err := manager.StartSession(ctx, "tv1", []string{"child1"}, 60)
+	require.NoError(t, err)
+	assert.Equal(t, 60, session.ExpectedDuration)
+
+	status, err := manager.GetChildStatus(ctx, "child1")
+	require.NoError(t, err)
+	require.NotNil(t, status.TrackingPause)
+
+	// Usage is not recorded when the session stops
+	session.StartTime = time.Now().Add(-20 * time.Minute)
+	require.NoError(t, storage.UpdateSession(ctx, session))
+	require.NoError(t, manager.StopSession(ctx, session.ID))
+
+	summary, err := storage.GetDailyUsageSummary(ctx, "child1", today)
+	require.NoError(t, err)
+	assert.Equal(t, 30, summary.MinutesUsed)
+}
@@ -0,0 +1,203 @@
+package core
+
+import (
+	"context"
+	"time"
+)
+
+// Trend windows, in days
+const (
+	TrendShortWindow = 7
+	TrendLongWindow  = 30
+)
+
+// trendFlatPercent is the week-over-week change below which a trend is reported as flat
+const trendFlatPercent = 5
+
+// Trend directions
+const (
+	TrendUp   = "up"
+	TrendDown = "down"
+	TrendFlat = "flat"
+)
+
+// TrendsStorage defines the storage interface needed for usage trends
+type TrendsStorage interface {
+	GetChild(ctx context.Context, id string) (*Child, error)
+	GetDailyAllocation(ctx context.Context, childID string, date time.Time) (*DailyTimeAllocation, error)
+	GetDailyUsageSummary(ctx context.Context, childID string, date time.Time) (*DailyUsageSummary, error)
+}
+
+// TrendsService computes rolling usage statistics from the daily usage summaries
+// Only complete days are used: windows end with yesterday in the child's timezone,
+// so a half-used today does not pull the averages down.
+type TrendsService struct {
+	storage    TrendsStorage
+	calculator *TimeCalculationService
+}
+
+// UsageDay is one day of a child's usage
+type UsageDay struct {
+	Date         time.Time
+	MinutesUsed  int
+	Limit        int // Base limit plus bonus of the day
+	LimitPercent int // MinutesUsed as a percentage of Limit, 0 without a limit
+}
+
+// UsageWindow summarizes usage over a set of days
+type UsageWindow struct {
+	Days           int // Days counted (days before the child was added are skipped)
+	TotalMinutes   int
+	AverageMinutes float64 // Per day
+	LimitPercent   float64 // Average percentage of the daily limit, over days with a limit
+}
+
+// ChildTrends holds a child's usage trends
+type ChildTrends struct {
+	ChildID      string
+	From         time.Time   // First day of the long window
+	To           time.Time   // Last day (yesterday)
+	Week         UsageWindow // Last 7 days
+	PreviousWeek UsageWindow // The 7 days before
+	Month        UsageWindow // Last 30 days
+	Weekdays     UsageWindow // Weekdays of the last 30 days
+	Weekends     UsageWindow // Weekend days of the last 30 days
+	WeekChange   *float64    // Percent change of the weekly average vs the previous week; nil without a previous week to compare
+	Direction    string      // TrendUp, TrendDown or TrendFlat
+	Days         []UsageDay  // The last 30 days, oldest first
+}
+
+// NewTrendsService creates a new trends service
+func NewTrendsService(storage TrendsStorage, calculator *TimeCalculationService) *TrendsService {
+	return &TrendsService{
+		storage:    storage,
+		calculator: calculator,
+	}
+}
+
+// ChildTrends computes the trends of a child's usage for the days before now
+func (s *TrendsService) ChildTrends(ctx context.Context, childID string, now time.Time) (*ChildTrends, error) {
+	child, err := s.storage.GetChild(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+
+	today := s.calculator.UsageDate(ctx, childID, now)
+	var firstDay time.Time
+	if !child.CreatedAt.IsZero() {
+		firstDay = s.calculator.UsageDate(ctx, childID, child.CreatedAt)
+	}
+
+	trends := &ChildTrends{
+		ChildID: childID,
+		From:    today.AddDate(0, 0, -TrendLongWindow),
+		To:      today.AddDate(0, 0, -1),
+	}
+
+	var week, previousWeek, month, weekdays, weekends windowSum
+	for i := TrendLongWindow; i >= 1; i-- {
+		date := today.AddDate(0, 0, -i)
+		if date.Before(firstDay) {
+			continue
+		}
+
+		day, err := s.usageDay(ctx, child, date)
+		if err != nil {
+			return nil, err
+		}
+		trends.Days = append(trends.Days, *day)
+
+		month.add(day)
+		if weekday := date.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+			weekends.add(day)
+		} else {
+			weekdays.add(day)
+		}
+		switch {
+		case i <= TrendShortWindow:
+			week.add(day)
+		case i <= 2*TrendShortWindow:
+			previousWeek.add(day)
+		}
+	}
+
+	trends.Week = week.window()
+	trends.PreviousWeek = previousWeek.window()
+	trends.Month = month.window()
+	trends.Weekdays = weekdays.window()
+	trends.Weekends = weekends.window()
+	trends.WeekChange, trends.Direction = weekChange(trends.Week, trends.PreviousWeek)
+
+	return trends, nil
+}
+
+// usageDay reads one day of usage without creating an allocation for it
+func (s *TrendsService) usageDay(ctx context.Context, child *Child, date time.Time) (*UsageDay, error) {
+	day := &UsageDay{Date: date}
+
+	summary, err := s.storage.GetDailyUsageSummary(ctx, child.ID, date)
+	if err == nil {
+		day.MinutesUsed = summary.MinutesUsed
+	}
+
+	allocation, err := s.storage.GetDailyAllocation(ctx, child.ID, date)
+	switch {
+	case err == nil:
+		day.Limit = allocation.BaseLimit + allocation.BonusGranted
+	case err == ErrAllocationNotFound:
+		// No session started that day; the limit is the schedule's
+		day.Limit = child.GetDailyLimit(date)
+	default:
+		return nil, err
+	}
+
+	if day.Limit > 0 {
+		day.LimitPercent = day.MinutesUsed * 100 / day.Limit
+	}
+	return day, nil
+}
+
+// weekChange compares the weekly averages; without usage in the previous week there is nothing to compare
+func weekChange(week, previous UsageWindow) (*float64, string) {
+	if previous.Days == 0 || previous.AverageMinutes == 0 || week.Days == 0 {
+		return nil, TrendFlat
+	}
+
+	change := (week.AverageMinutes - previous.AverageMinutes) * 100 / previous.AverageMinutes
+	switch {
+	case change >= trendFlatPercent:
+		return &change, TrendUp
+	case change <= -trendFlatPercent:
+		return &change, TrendDown
+	default:
+		return &change, TrendFlat
+	}
+}
+
+// windowSum accumulates days into a UsageWindow
+type windowSum struct {
+	days          int
+	minutes       int
+	limitDays     int
+	limitPercents int
+}
+
+func (w *windowSum) add(day *UsageDay) {
+	w.days++
+	w.minutes += day.MinutesUsed
+	if day.Limit > 0 {
+		w.limitDays++
+		w.limitPercents += day.LimitPercent
+	}
+}
+
+func (w *windowSum) window() UsageWindow {
+	window := UsageWindow{Days: w.days, TotalMinutes: w.minutes}
+	if w.days > 0 {
+		window.AverageMinutes = float64(w.minutes) / float64(w.days)
+	}
+	if w.limitDays > 0 {
+		window.LimitPercent = float64(w.limitPercents) / float64(w.limitDays)
+	}
+	return window
+}
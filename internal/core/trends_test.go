@@ -0,0 +1,104 @@
+package core
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTrendsService_ChildTrends(t *testing.T) {
+	ctx := context.Background()
+	storage := newMockTimeCalcStorage()
+	service := NewTrendsService(storage, NewTimeCalculationService(storage, time.UTC))
+	storage.children["child1"] = &Child{ID: "child1", WeekdayLimit: 60, WeekendLimit: 120}
+
+	// Wednesday; the last complete day is Tuesday 2026-03-17
+	now := time.Date(2026, 3, 18, 10, 0, 0, 0, time.UTC)
+
+	// Weekend days use twice the weekday minutes; this week doubles the previous one
+	for i := 1; i <= 14; i++ {
+		date := makeDate(2026, 3, 18).AddDate(0, 0, -i)
+		minutes := 30
+		if i > 7 {
+			minutes = 15
+		}
+		if weekday := date.Weekday(); weekday == time.Saturday || weekday == time.Sunday {
+			minutes *= 2
+		}
+		storage.summaries["child1-"+date.Format("2006-01-02")] = &DailyUsageSummary{ChildID: "child1", Date: date, MinutesUsed: minutes}
+	}
+	// A bonus raised Tuesday's limit
+	storage.allocations["child1-2026-03-17"] = &DailyTimeAllocation{ChildID: "child1", Date: makeDate(2026, 3, 17), BaseLimit: 60, BonusGranted: 30}
+
+	trends, err := service.ChildTrends(ctx, "child1", now)
+	require.NoError(t, err)
+
+	assert.Equal(t, makeDate(2026, 2, 16), trends.From)
+	assert.Equal(t, makeDate(2026, 3, 17), trends.To)
+	require.Len(t, trends.Days, 30)
+	assert.Equal(t, makeDate(2026, 2, 16), trends.Days[0].Date, "oldest first")
+	assert.Equal(t, UsageDay{Date: makeDate(2026, 3, 17), MinutesUsed: 30, Limit: 90, LimitPercent: 33}, trends.Days[29])
+	assert.Len(t, storage.allocations, 1, "no allocations created for past days")
+
+	assert.Equal(t, UsageWindow{Days: 7, TotalMinutes: 270, AverageMinutes: 270.0 / 7, LimitPercent: trends.Week.LimitPercent}, trends.Week)
+	assert.Equal(t, 135, trends.PreviousWeek.TotalMinutes)
+	assert.Equal(t, 405, trends.Month.TotalMinutes)
+	assert.Equal(t, 30, trends.Month.Days)
+
+	assert.Equal(t, 22, trends.Weekdays.Days)
+	assert.Equal(t, 225, trends.Weekdays.TotalMinutes)
+	assert.Equal(t, 8, trends.Weekends.Days)
+	assert.Equal(t, 180, trends.Weekends.TotalMinutes)
+	assert.InDelta(t, 22.5, trends.Weekends.AverageMinutes, 0.001)
+
+	require.NotNil(t, trends.WeekChange)
+	assert.InDelta(t, 100, *trends.WeekChange, 0.001)
+	assert.Equal(t, TrendUp, trends.Direction)
+}
+
+func TestTrendsService_ChildTrends_NewChild(t *testing.T) {
+	ctx := context.Background()
+	storage := newMockTimeCalcStorage()
+	service := NewTrendsService(storage, NewTimeCalculationService(storage, time.UTC))
+	storage.children["child1"] = &Child{ID: "child1", WeekdayLimit: 60, WeekendLimit: 60, CreatedAt: time.Date(2026, 3, 13, 18, 0, 0, 0, time.UTC)}
+	storage.summaries["child1-2026-03-16"] = &DailyUsageSummary{ChildID: "child1", MinutesUsed: 45}
+
+	trends, err := service.ChildTrends(ctx, "child1", time.Date(2026, 3, 18, 10, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	// Days before the child was added are not counted as unused
+	assert.Len(t, trends.Days, 5)
+	assert.Equal(t, 5, trends.Month.Days)
+	assert.InDelta(t, 9, trends.Month.AverageMinutes, 0.001)
+	assert.InDelta(t, 15, trends.Month.LimitPercent, 0.001)
+	assert.Equal(t, 0, trends.PreviousWeek.Days)
+	assert.Nil(t, trends.WeekChange, "no previous week to compare")
+	assert.Equal(t, TrendFlat, trends.Direction)
+
+	_, err = service.ChildTrends(ctx, "missing", time.Now())
+	assert.ErrorIs(t, err, ErrChildNotFound)
+}
+
+func TestWeekChange(t *testing.T) {
+	tests := []struct {
+		name      string
+		week      float64
+		previous  float64
+		direction string
+	}{
+		{"up", 60, 40, TrendUp},
+		{"down", 30, 40, TrendDown},
+		{"small change is flat", 41, 40, TrendFlat},
+		{"no usage last week", 30, 0, TrendFlat},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, direction := weekChange(UsageWindow{Days: 7, AverageMinutes: tt.week}, UsageWindow{Days: 7, AverageMinutes: tt.previous})
+			assert.Equal(t, tt.direction, direction)
+		})
+	}
+}
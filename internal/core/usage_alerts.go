@@ -0,0 +1,228 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"metron/internal/idgen"
+)
+
+// DefaultUsageAlertThresholds are the usage alert percentages used when none are configured
+var DefaultUsageAlertThresholds = []int{80}
+
+// UsageAlert records that a child used a configured share of the day's time
+// Alerts give parents a heads-up before the time runs out; each threshold is alerted once per day
+type UsageAlert struct {
+	ID           string
+	ChildID      string
+	Date         time.Time // The child's calendar day (see TimeCalculationService.UsageDate)
+	Threshold    int       // Percentage of the day's time that was reached (e.g., 80)
+	UsedMinutes  int       // Minutes used when the threshold was reached, including running sessions
+	LimitMinutes int       // The day's time, including rewards
+	CreatedAt    time.Time
+}
+
+// RemainingMinutes returns the minutes that were left when the alert was created
+func (a *UsageAlert) RemainingMinutes() int {
+	if a.UsedMinutes >= a.LimitMinutes {
+		return 0
+	}
+	return a.LimitMinutes - a.UsedMinutes
+}
+
+// UsageAlertStorage defines the interface for usage alert persistence
+type UsageAlertStorage interface {
+	CreateUsageAlert(ctx context.Context, alert *UsageAlert) error
+	ListUsageAlerts(ctx context.Context, since time.Time) ([]*UsageAlert, error)             // Created after since, oldest first
+	MaxUsageAlertThreshold(ctx context.Context, childID string, date time.Time) (int, error) // Highest threshold alerted on the day, 0 if none
+	GetChild(ctx context.Context, id string) (*Child, error)
+	ListChildren(ctx context.Context) ([]*Child, error)
+}
+
+// UsageAlertNotifier delivers usage alerts outside the API (e.g., to a webhook)
+type UsageAlertNotifier interface {
+	NotifyUsageAlert(ctx context.Context, alert *UsageAlert, child *Child) error
+}
+
+// UsageAlertService alerts parents when children reach a share of their daily time
+// Usage is evaluated on scheduler ticks (so running sessions count) and after usage is
+// written outside sessions (e.g., imported usage). Only the highest newly reached threshold
+// is alerted, so a child jumping from 50% to 100% causes one alert, not two.
+type UsageAlertService struct {
+	storage    UsageAlertStorage
+	calculator *TimeCalculationService
+	thresholds []int // Ascending
+	notifiers  []UsageAlertNotifier
+	pauses     *TrackingPauseService // Optional: children with tracking paused are not alerted
+	logger     *slog.Logger
+	mu         sync.Mutex // Serializes checks so a threshold is alerted once
+}
+
+// NewUsageAlertService creates a new usage alert service
+// Thresholds are percentages of the day's time (1-100); DefaultUsageAlertThresholds if empty
+func NewUsageAlertService(storage UsageAlertStorage, calculator *TimeCalculationService, thresholds []int, logger *slog.Logger) *UsageAlertService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if len(thresholds) == 0 {
+		thresholds = DefaultUsageAlertThresholds
+	}
+	sorted := append([]int(nil), thresholds...)
+	sort.Ints(sorted)
+	return &UsageAlertService{
+		storage:    storage,
+		calculator: calculator,
+		thresholds: sorted,
+		logger:     logger,
+	}
+}
+
+// AddNotifier delivers new alerts to the notifier as well
+func (s *UsageAlertService) AddNotifier(notifier UsageAlertNotifier) {
+	s.notifiers = append(s.notifiers, notifier)
+}
+
+// SetTrackingPause sets the vacation mode service; children with tracking paused are not limited,
+// so they are not alerted either
+func (s *UsageAlertService) SetTrackingPause(pauses *TrackingPauseService) {
+	s.pauses = pauses
+}
+
+// Thresholds returns the alert percentages, ascending
+func (s *UsageAlertService) Thresholds() []int {
+	return append([]int(nil), s.thresholds...)
+}
+
+// CheckAll checks every child and returns the new alerts
+// Errors are logged per child so one child does not keep the others from being alerted
+func (s *UsageAlertService) CheckAll(ctx context.Context) ([]*UsageAlert, error) {
+	children, err := s.storage.ListChildren(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var alerts []*UsageAlert
+	for _, child := range children {
+		alert, err := s.check(ctx, child)
+		if err != nil {
+			s.logger.Error("Failed to check usage alerts",
+				"child_id", child.ID,
+				"error", err)
+			continue
+		}
+		if alert != nil {
+			alerts = append(alerts, alert)
+		}
+	}
+	return alerts, nil
+}
+
+// Check checks one child and returns the new alert, or nil if no new threshold was reached
+func (s *UsageAlertService) Check(ctx context.Context, childID string) (*UsageAlert, error) {
+	child, err := s.storage.GetChild(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+	return s.check(ctx, child)
+}
+
+// check records and delivers an alert for the highest threshold the child reached today, if new
+func (s *UsageAlertService) check(ctx context.Context, child *Child) (*UsageAlert, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.pauses != nil {
+		pause, err := s.pauses.PauseFor(ctx, child.ID)
+		if err != nil {
+			return nil, err
+		}
+		if pause != nil {
+			return nil, nil
+		}
+	}
+
+	now := Now()
+	remaining, err := s.calculator.GetRemainingTime(ctx, child.ID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := remaining.Available.TotalAvailable
+	if limit <= 0 {
+		// No time today, so there is nothing to warn about
+		return nil, nil
+	}
+	used := remaining.Consumed.TotalConsumed
+
+	reached := 0
+	for _, threshold := range s.thresholds {
+		if used*100 >= threshold*limit {
+			reached = threshold
+		}
+	}
+	if reached == 0 {
+		return nil, nil
+	}
+
+	date := s.calculator.UsageDate(ctx, child.ID, now)
+	alerted, err := s.storage.MaxUsageAlertThreshold(ctx, child.ID, date)
+	if err != nil {
+		return nil, err
+	}
+	if reached <= alerted {
+		return nil, nil
+	}
+
+	alert := &UsageAlert{
+		ID:           idgen.NewUsageAlert(),
+		ChildID:      child.ID,
+		Date:         date,
+		Threshold:    reached,
+		UsedMinutes:  used,
+		LimitMinutes: limit,
+		CreatedAt:    now,
+	}
+	if err := s.storage.CreateUsageAlert(ctx, alert); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Usage threshold reached",
+		"alert_id", alert.ID,
+		"child_id", child.ID,
+		"threshold", reached,
+		"used_minutes", used,
+		"limit_minutes", limit)
+
+	for _, notifier := range s.notifiers {
+		if err := notifier.NotifyUsageAlert(ctx, alert, child); err != nil {
+			// The alert is stored, so the bot still picks it up
+			s.logger.Warn("Failed to deliver usage alert",
+				"alert_id", alert.ID,
+				"error", err)
+		}
+	}
+
+	return alert, nil
+}
+
+// List returns the alerts created after since, oldest first, optionally for one child
+func (s *UsageAlertService) List(ctx context.Context, since time.Time, childID string) ([]*UsageAlert, error) {
+	alerts, err := s.storage.ListUsageAlerts(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+	if childID == "" {
+		return alerts, nil
+	}
+
+	filtered := make([]*UsageAlert, 0, len(alerts))
+	for _, alert := range alerts {
+		if alert.ChildID == childID {
+			filtered = append(filtered, alert)
+		}
+	}
+	return filtered, nil
+}
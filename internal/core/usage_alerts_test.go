@@ -0,0 +1,140 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockUsageAlertStorage struct {
+	*mockStorage
+	alerts []*UsageAlert
+}
+
+func (m *mockUsageAlertStorage) CreateUsageAlert(ctx context.Context, alert *UsageAlert) error {
+	copied := *alert
+	m.alerts = append(m.alerts, &copied)
+	return nil
+}
+
+func (m *mockUsageAlertStorage) ListUsageAlerts(ctx context.Context, since time.Time) ([]*UsageAlert, error) {
+	var alerts []*UsageAlert
+	for _, alert := range m.alerts {
+		if alert.CreatedAt.After(since) {
+			copied := *alert
+			alerts = append(alerts, &copied)
+		}
+	}
+	return alerts, nil
+}
+
+func (m *mockUsageAlertStorage) MaxUsageAlertThreshold(ctx context.Context, childID string, date time.Time) (int, error) {
+	max := 0
+	for _, alert := range m.alerts {
+		if alert.ChildID == childID && alert.Date.Equal(date) && alert.Threshold > max {
+			max = alert.Threshold
+		}
+	}
+	return max, nil
+}
+
+type mockUsageAlertNotifier struct {
+	alerts []*UsageAlert
+	err    error
+}
+
+func (m *mockUsageAlertNotifier) NotifyUsageAlert(ctx context.Context, alert *UsageAlert, child *Child) error {
+	m.alerts = append(m.alerts, alert)
+	return m.err
+}
+
+// newUsageAlertTestService returns a service with 50% and 80% thresholds and a child with 60 minutes a day
+func newUsageAlertTestService(t *testing.T) (*UsageAlertService, *mockUsageAlertStorage) {
+	now := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)
+	original := Now
+	Now = func() time.Time { return now }
+	t.Cleanup(func() { Now = original })
+
+	storage := &mockUsageAlertStorage{mockStorage: newMockStorage()}
+	storage.CreateChild(context.Background(), &Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 60})
+	calculator := NewTimeCalculationService(storage, time.UTC)
+	return NewUsageAlertService(storage, calculator, []int{80, 50}, nil), storage
+}
+
+func TestUsageAlertService_Check(t *testing.T) {
+	service, storage := newUsageAlertTestService(t)
+	notifier := &mockUsageAlertNotifier{}
+	service.AddNotifier(notifier)
+	assert.Equal(t, []int{50, 80}, service.Thresholds())
+
+	// 20 of 60 minutes is below every threshold
+	storage.IncrementDailyUsage(context.Background(), "child1", Now(), 20)
+	alert, err := service.Check(context.Background(), "child1")
+	require.NoError(t, err)
+	assert.Nil(t, alert)
+
+	// 30 of 60 minutes reaches 50%
+	storage.IncrementDailyUsage(context.Background(), "child1", Now(), 10)
+	alert, err = service.Check(context.Background(), "child1")
+	require.NoError(t, err)
+	require.NotNil(t, alert)
+	assert.Equal(t, 50, alert.Threshold)
+	assert.Equal(t, 30, alert.UsedMinutes)
+	assert.Equal(t, 60, alert.LimitMinutes)
+	assert.Equal(t, 30, alert.RemainingMinutes())
+	require.Len(t, notifier.alerts, 1)
+
+	// Each threshold is alerted once a day
+	alert, err = service.Check(context.Background(), "child1")
+	require.NoError(t, err)
+	assert.Nil(t, alert)
+	assert.Len(t, storage.alerts, 1)
+}
+
+func TestUsageAlertService_Check_HighestThresholdOnly(t *testing.T) {
+	service, storage := newUsageAlertTestService(t)
+
+	// Jumping past both thresholds alerts only the highest one
+	storage.IncrementDailyUsage(context.Background(), "child1", Now(), 60)
+	alerts, err := service.CheckAll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, 80, alerts[0].Threshold)
+	assert.Equal(t, 0, alerts[0].RemainingMinutes())
+
+	listed, err := service.List(context.Background(), time.Time{}, "child1")
+	require.NoError(t, err)
+	assert.Len(t, listed, 1)
+
+	listed, err = service.List(context.Background(), time.Time{}, "child2")
+	require.NoError(t, err)
+	assert.Empty(t, listed)
+}
+
+func TestUsageAlertService_Check_NotifierFailure(t *testing.T) {
+	service, storage := newUsageAlertTestService(t)
+	service.AddNotifier(&mockUsageAlertNotifier{err: errors.New("connection refused")})
+
+	// A failed delivery keeps the alert, so the bot still picks it up
+	storage.IncrementDailyUsage(context.Background(), "child1", Now(), 50)
+	alert, err := service.Check(context.Background(), "child1")
+	require.NoError(t, err)
+	require.NotNil(t, alert)
+	assert.Len(t, storage.alerts, 1)
+}
+
+func TestUsageAlertService_Check_NoLimit(t *testing.T) {
+	service, storage := newUsageAlertTestService(t)
+	storage.CreateChild(context.Background(), &Child{ID: "child2", Name: "Bob"})
+
+	alert, err := service.Check(context.Background(), "child2")
+	require.NoError(t, err)
+	assert.Nil(t, alert, "children without time today are not alerted")
+
+	_, err = service.Check(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrChildNotFound)
+}
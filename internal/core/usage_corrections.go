@@ -0,0 +1,147 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// ErrInvalidUsageCorrection is returned for corrections without minutes or a reason, for
+// future days, and for refunds of more minutes than were charged
+var ErrInvalidUsageCorrection = errors.New("invalid usage correction")
+
+// UsageCorrectionStorage defines the storage interface needed for usage corrections
+type UsageCorrectionStorage interface {
+	GetChild(ctx context.Context, id string) (*Child, error)
+	GetDailyUsageSummary(ctx context.Context, childID string, date time.Time) (*DailyUsageSummary, error)
+	IncrementDailyUsageSummary(ctx context.Context, childID string, date time.Time, minutes int) error
+}
+
+// UsageCorrection is a change of the minutes charged to a child's day
+type UsageCorrection struct {
+	ChildID         string
+	Date            time.Time // The corrected day (see TimeCalculationService.UsageDate)
+	Minutes         int       // Minutes added; negative minutes are refunded
+	PreviousMinutes int       // Minutes charged before the correction
+	MinutesUsed     int       // Minutes charged after the correction
+	Reason          string
+	Actor           string
+	Rollover        *DayRollover // The day's recalculated close-out; nil if the day has not ended or was not closed out
+}
+
+// UsageCorrectionService corrects the usage charged to children's days, e.g. a session the
+// scheduler expired late during an outage
+// Corrections change the daily usage summary that reports and trends are computed from,
+// recalculate the day's rollover and are recorded in the audit log with their reason.
+// Corrections of the same child's day are serialized, so two refunds can't both pass the
+// check against the minutes charged and take the day below zero.
+type UsageCorrectionService struct {
+	storage    UsageCorrectionStorage
+	calculator *TimeCalculationService
+	locks      *SessionLocks // Keyed by child and day
+	rollovers  *DayRolloverService // Optional: recalculates closed-out days
+	audit      *AuditService       // Optional: records corrections
+	logger     *slog.Logger
+}
+
+// NewUsageCorrectionService creates a new usage correction service
+func NewUsageCorrectionService(storage UsageCorrectionStorage, calculator *TimeCalculationService, rollovers *DayRolloverService, audit *AuditService, logger *slog.Logger) *UsageCorrectionService {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &UsageCorrectionService{
+		storage:    storage,
+		calculator: calculator,
+		locks:      NewSessionLocks(),
+		rollovers:  rollovers,
+		audit:      audit,
+		logger:     logger,
+	}
+}
+
+// SetSessionLocks shares the session manager's locks, whose storage claims also serialize
+// corrections made by other processes on the same database
+func (s *UsageCorrectionService) SetSessionLocks(locks *SessionLocks) {
+	s.locks = locks
+}
+
+// Correct adds minutes to the usage charged to the child's day; negative minutes are refunded
+// Only the calendar date of date is used, and it must not be after the child's today.
+func (s *UsageCorrectionService) Correct(ctx context.Context, childID string, date time.Time, minutes int, reason, actor string) (*UsageCorrection, error) {
+	reason = strings.TrimSpace(reason)
+	if minutes == 0 {
+		return nil, fmt.Errorf("%w: minutes must not be zero", ErrInvalidUsageCorrection)
+	}
+	if reason == "" {
+		return nil, fmt.Errorf("%w: a reason is required", ErrInvalidUsageCorrection)
+	}
+
+	child, err := s.storage.GetChild(ctx, childID)
+	if err != nil {
+		return nil, err
+	}
+
+	tz := s.calculator.timezone
+	day := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, tz)
+	today := UsageDate(Now(), child.Location(tz), tz)
+	if day.After(today) {
+		return nil, fmt.Errorf("%w: %s is in the future", ErrInvalidUsageCorrection, day.Format("2006-01-02"))
+	}
+
+	release, err := s.locks.Acquire(ctx, "usage:"+childID+":"+day.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	summary, err := s.storage.GetDailyUsageSummary(ctx, childID, day)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily usage: %w", err)
+	}
+	previous := summary.MinutesUsed
+	if previous+minutes < 0 {
+		return nil, fmt.Errorf("%w: only %d minutes were charged on %s", ErrInvalidUsageCorrection, previous, day.Format("2006-01-02"))
+	}
+
+	if err := s.storage.IncrementDailyUsageSummary(ctx, childID, day, minutes); err != nil {
+		return nil, err
+	}
+
+	correction := &UsageCorrection{
+		ChildID:         childID,
+		Date:            day,
+		Minutes:         minutes,
+		PreviousMinutes: previous,
+		MinutesUsed:     previous + minutes,
+		Reason:          reason,
+		Actor:           actor,
+	}
+
+	if s.rollovers != nil {
+		rollover, err := s.rollovers.Recalculate(ctx, childID, day)
+		if err != nil {
+			// The correction stands; the rollover keeps its old usage
+			s.logger.Error("Failed to recalculate day rollover",
+				"child_id", childID,
+				"date", day.Format("2006-01-02"),
+				"error", err)
+		}
+		correction.Rollover = rollover
+	}
+
+	s.logger.Info("Usage corrected",
+		"child_id", childID,
+		"date", day.Format("2006-01-02"),
+		"minutes", minutes,
+		"minutes_used", correction.MinutesUsed,
+		"actor", actor)
+	if s.audit != nil {
+		s.audit.Record(ctx, AuditUsageCorrected, childID, actor,
+			fmt.Sprintf("%s: %d → %d min (%+d): %s", day.Format("2006-01-02"), previous, correction.MinutesUsed, minutes, reason))
+	}
+
+	return correction, nil
+}
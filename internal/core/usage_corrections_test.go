@@ -0,0 +1,144 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUsageCorrectionService_Correct(t *testing.T) {
+	setDowntimeOverrideClock(t, time.Date(2026, time.March, 10, 9, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+	yesterday := time.Date(2026, time.March, 9, 0, 0, 0, 0, time.UTC)
+
+	storage := &mockDayRolloverStorage{mockStorage: newMockStorage()}
+	storage.CreateChild(ctx, &Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 90})
+	storage.IncrementDailyUsage(ctx, "child1", yesterday, 75)
+	calculator := NewTimeCalculationService(storage, time.UTC)
+	rollovers := NewDayRolloverService(storage, calculator, nil)
+	_, err := rollovers.RollOver(ctx)
+	require.NoError(t, err)
+	require.Len(t, storage.rollovers, 1)
+
+	audit := &mockAuditStorage{}
+	service := NewUsageCorrectionService(storage, calculator, rollovers, NewAuditService(audit, nil), nil)
+
+	// Refund a session the scheduler expired late
+	correction, err := service.Correct(ctx, "child1", yesterday.Add(15*time.Hour), -30, " expired late during an outage ", "telegram:42")
+	require.NoError(t, err)
+	assert.Equal(t, "2026-03-09", correction.Date.Format("2006-01-02"))
+	assert.Equal(t, 75, correction.PreviousMinutes)
+	assert.Equal(t, 45, correction.MinutesUsed)
+	assert.Equal(t, "expired late during an outage", correction.Reason)
+
+	summary, err := storage.GetDailyUsageSummary(ctx, "child1", yesterday)
+	require.NoError(t, err)
+	assert.Equal(t, 45, summary.MinutesUsed)
+
+	// The closed-out day is recalculated
+	require.NotNil(t, correction.Rollover)
+	assert.Equal(t, 45, correction.Rollover.UsedMinutes)
+	assert.Equal(t, 45, storage.rollovers[0].UsedMinutes)
+	assert.Equal(t, 15, storage.rollovers[0].UnusedMinutes())
+
+	require.Len(t, audit.entries, 1)
+	assert.Equal(t, AuditUsageCorrected, audit.entries[0].Action)
+	assert.Equal(t, "telegram:42", audit.entries[0].Actor)
+	assert.Contains(t, audit.entries[0].Details, "75 → 45 min (-30)")
+	assert.Contains(t, audit.entries[0].Details, "expired late during an outage")
+
+	// Today has no rollover yet
+	correction, err = service.Correct(ctx, "child1", time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC), 10, "played at a friend's", "api")
+	require.NoError(t, err)
+	assert.Equal(t, 10, correction.MinutesUsed)
+	assert.Nil(t, correction.Rollover)
+
+	t.Run("invalid corrections", func(t *testing.T) {
+		for name, tt := range map[string]struct {
+			date    time.Time
+			minutes int
+			reason  string
+		}{
+			"no minutes":       {yesterday, 0, "reason"},
+			"no reason":        {yesterday, 10, "  "},
+			"future day":       {time.Date(2026, time.March, 11, 0, 0, 0, 0, time.UTC), 10, "reason"},
+			"refund too large": {yesterday, -46, "reason"},
+		} {
+			_, err := service.Correct(ctx, "child1", tt.date, tt.minutes, tt.reason, "api")
+			assert.ErrorIs(t, err, ErrInvalidUsageCorrection, name)
+		}
+		assert.Len(t, audit.entries, 2)
+	})
+
+	_, err = service.Correct(ctx, "nobody", yesterday, 10, "reason", "api")
+	assert.ErrorIs(t, err, ErrChildNotFound)
+}
+
+// failingUsageSummaryStorage fails to read daily usage summaries
+type failingUsageSummaryStorage struct {
+	*mockStorage
+}
+
+func (s *failingUsageSummaryStorage) GetDailyUsageSummary(ctx context.Context, childID string, date time.Time) (*DailyUsageSummary, error) {
+	return nil, errors.New("database is locked")
+}
+
+func TestUsageCorrectionService_Correct_StorageError(t *testing.T) {
+	setDowntimeOverrideClock(t, time.Date(2026, time.March, 10, 9, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+	yesterday := time.Date(2026, time.March, 9, 0, 0, 0, 0, time.UTC)
+
+	storage := &failingUsageSummaryStorage{mockStorage: newMockStorage()}
+	storage.CreateChild(ctx, &Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 90})
+	storage.IncrementDailyUsage(ctx, "child1", yesterday, 75)
+	service := NewUsageCorrectionService(storage, NewTimeCalculationService(storage, time.UTC), nil, nil, nil)
+
+	// Without the charged minutes the refund can't be checked, so nothing is changed
+	_, err := service.Correct(ctx, "child1", yesterday, -30, "expired late", "api")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInvalidUsageCorrection)
+	usage, err := storage.GetDailyUsage(ctx, "child1", yesterday)
+	require.NoError(t, err)
+	assert.Equal(t, 75, usage.MinutesUsed)
+}
+
+func TestUsageCorrectionService_Correct_Concurrent(t *testing.T) {
+	setDowntimeOverrideClock(t, time.Date(2026, time.March, 10, 9, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+	yesterday := time.Date(2026, time.March, 9, 0, 0, 0, 0, time.UTC)
+
+	storage := newMockStorage()
+	storage.CreateChild(ctx, &Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 90})
+	storage.IncrementDailyUsage(ctx, "child1", yesterday, 75)
+	service := NewUsageCorrectionService(storage, NewTimeCalculationService(storage, time.UTC), nil, nil, nil)
+
+	// Two refunds that each fit the day, but not both
+	errs := make([]error, 2)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = service.Correct(ctx, "child1", yesterday, -40, "refund", "api")
+		}()
+	}
+	wg.Wait()
+
+	succeeded := 0
+	for _, err := range errs {
+		if err == nil {
+			succeeded++
+		} else {
+			assert.ErrorIs(t, err, ErrInvalidUsageCorrection)
+		}
+	}
+	assert.Equal(t, 1, succeeded)
+	summary, err := storage.GetDailyUsageSummary(ctx, "child1", yesterday)
+	require.NoError(t, err)
+	assert.Equal(t, 35, summary.MinutesUsed)
+}
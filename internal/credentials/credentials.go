@@ -0,0 +1,140 @@
+// Package credentials encrypts third-party credentials (driver tokens, API keys) at rest with
+// envelope encryption: every credential is sealed with its own random data key, and the data
+// key is sealed with the master key, which is kept outside the database.
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// KeySize is the size of master and data keys (AES-256)
+const KeySize = 32
+
+var (
+	// ErrInvalidKey is returned for master keys that are not KeySize bytes
+	ErrInvalidKey = errors.New("credentials key must be 32 bytes")
+	// ErrKeyMismatch is returned when a credential was sealed with another master key
+	ErrKeyMismatch = errors.New("credential was encrypted with a different key")
+	// ErrNoKey is returned when a credential is encrypted but no master key is configured
+	ErrNoKey = errors.New("credential is encrypted but no credentials key is configured")
+	// ErrDecrypt is returned when a sealed credential fails authentication (corrupted or tampered with)
+	ErrDecrypt = errors.New("failed to decrypt credential")
+)
+
+// Sealed is an encrypted credential as stored
+type Sealed struct {
+	KeyID      string // Master key the data key is sealed with (see Cipher.KeyID)
+	WrappedKey []byte // Data key sealed with the master key (nonce followed by ciphertext)
+	Data       []byte // Credential sealed with the data key (nonce followed by ciphertext)
+}
+
+// Cipher seals and opens credentials with a master key
+type Cipher struct {
+	master cipher.AEAD
+	keyID  string
+}
+
+// NewCipher creates a cipher for a KeySize master key
+func NewCipher(key []byte) (*Cipher, error) {
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	master, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(key)
+	return &Cipher{
+		master: master,
+		keyID:  hex.EncodeToString(sum[:4]),
+	}, nil
+}
+
+// KeyID identifies the master key without revealing it (first bytes of its SHA-256)
+func (c *Cipher) KeyID() string {
+	return c.keyID
+}
+
+// Seal encrypts a credential under a fresh data key
+// context (e.g. the driver name) is authenticated with it, so a sealed credential
+// can't be moved to another row.
+func (c *Cipher) Seal(plaintext, context []byte) (*Sealed, error) {
+	dataKey := make([]byte, KeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	sealedData, err := seal(data, plaintext, context)
+	if err != nil {
+		return nil, err
+	}
+	wrappedKey, err := seal(c.master, dataKey, context)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sealed{
+		KeyID:      c.keyID,
+		WrappedKey: wrappedKey,
+		Data:       sealedData,
+	}, nil
+}
+
+// Open decrypts a credential sealed with Seal and the same context
+func (c *Cipher) Open(sealed *Sealed, context []byte) ([]byte, error) {
+	if sealed.KeyID != c.keyID {
+		return nil, fmt.Errorf("%w (key %s, configured key %s)", ErrKeyMismatch, sealed.KeyID, c.keyID)
+	}
+
+	dataKey, err := open(c.master, sealed.WrappedKey, context)
+	if err != nil {
+		return nil, err
+	}
+	data, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	return open(data, sealed.Data, context)
+}
+
+// newAEAD creates an AES-GCM AEAD for a key
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext under a random nonce, returned in front of the ciphertext
+func seal(aead cipher.AEAD, plaintext, context []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, context), nil
+}
+
+// open decrypts the output of seal
+func open(aead cipher.AEAD, sealed, context []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, context)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plaintext, nil
+}
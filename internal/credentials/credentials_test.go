@@ -0,0 +1,97 @@
+package credentials
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, KeySize)
+}
+
+func TestCipher_SealOpen(t *testing.T) {
+	c, err := NewCipher(testKey(1))
+	require.NoError(t, err)
+	assert.Len(t, c.KeyID(), 8)
+
+	plaintext := []byte(`{"refresh_token":"secret"}`)
+	sealed, err := c.Seal(plaintext, []byte("aqara"))
+	require.NoError(t, err)
+	assert.Equal(t, c.KeyID(), sealed.KeyID)
+	assert.NotContains(t, string(sealed.Data), "secret")
+
+	opened, err := c.Open(sealed, []byte("aqara"))
+	require.NoError(t, err)
+	assert.Equal(t, plaintext, opened)
+
+	// Every seal uses a new data key
+	again, err := c.Seal(plaintext, []byte("aqara"))
+	require.NoError(t, err)
+	assert.NotEqual(t, sealed.WrappedKey, again.WrappedKey)
+	assert.NotEqual(t, sealed.Data, again.Data)
+
+	t.Run("bound to its context", func(t *testing.T) {
+		_, err := c.Open(sealed, []byte("kidslox"))
+		assert.ErrorIs(t, err, ErrDecrypt)
+	})
+
+	t.Run("tampering is detected", func(t *testing.T) {
+		tampered := *sealed
+		tampered.Data = append([]byte(nil), sealed.Data...)
+		tampered.Data[len(tampered.Data)-1] ^= 1
+		_, err := c.Open(&tampered, []byte("aqara"))
+		assert.ErrorIs(t, err, ErrDecrypt)
+	})
+
+	t.Run("other keys can't open it", func(t *testing.T) {
+		other, err := NewCipher(testKey(2))
+		require.NoError(t, err)
+		_, err = other.Open(sealed, []byte("aqara"))
+		assert.ErrorIs(t, err, ErrKeyMismatch)
+	})
+}
+
+func TestNewCipher_InvalidKey(t *testing.T) {
+	_, err := NewCipher([]byte("short"))
+	assert.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestLoadKey(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString(testKey(1))
+	keyring := ""
+	original := keyringLookup
+	keyringLookup = func() string { return keyring }
+	t.Cleanup(func() { keyringLookup = original })
+
+	t.Setenv(KeyEnv, "")
+	key, source, err := LoadKey("")
+	require.NoError(t, err)
+	assert.Nil(t, key, "no key anywhere")
+	assert.Empty(t, source)
+
+	keyring = base64.URLEncoding.EncodeToString(testKey(3))
+	key, source, err = LoadKey("")
+	require.NoError(t, err)
+	assert.Equal(t, testKey(3), key)
+	assert.Equal(t, SourceKeyring, source)
+
+	t.Setenv(KeyEnv, base64.StdEncoding.EncodeToString(testKey(2)))
+	key, source, err = LoadKey("")
+	require.NoError(t, err)
+	assert.Equal(t, testKey(2), key)
+	assert.Equal(t, SourceEnv, source)
+
+	key, source, err = LoadKey(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, testKey(1), key)
+	assert.Equal(t, SourceConfig, source)
+
+	_, _, err = LoadKey(base64.StdEncoding.EncodeToString([]byte("too short")))
+	assert.ErrorIs(t, err, ErrInvalidKey)
+	_, _, err = LoadKey("not base64!")
+	assert.Error(t, err)
+}
@@ -0,0 +1,88 @@
+package credentials
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// KeyEnv is the environment variable holding the base64 master key
+const KeyEnv = "METRON_CREDENTIALS_KEY"
+
+// Keyring entry of the master key (service and account)
+const (
+	keyringService = "metron"
+	keyringAccount = "credentials-key"
+)
+
+// Key sources reported by LoadKey
+const (
+	SourceConfig  = "config"
+	SourceEnv     = "env"
+	SourceKeyring = "keyring"
+)
+
+// keyringLookup reads the keyring entry; replaced in tests
+var keyringLookup = lookupKeyring
+
+// ParseKey decodes a base64 (standard or URL alphabet) master key
+func ParseKey(encoded string) ([]byte, error) {
+	encoded = strings.TrimSpace(encoded)
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		if key, err = base64.URLEncoding.DecodeString(encoded); err != nil {
+			return nil, fmt.Errorf("credentials key is not base64: %w", err)
+		}
+	}
+	if len(key) != KeySize {
+		return nil, ErrInvalidKey
+	}
+	return key, nil
+}
+
+// LoadKey finds the master key: configured (security.credentials_key), then the
+// METRON_CREDENTIALS_KEY environment variable, then the OS keyring
+// (secret-tool on Linux, the login keychain on macOS; service "metron", account "credentials-key").
+// Returns a nil key and no error if there is none.
+func LoadKey(configured string) (key []byte, source string, err error) {
+	if configured != "" {
+		key, err := ParseKey(configured)
+		return key, SourceConfig, err
+	}
+	if encoded := os.Getenv(KeyEnv); encoded != "" {
+		key, err := ParseKey(encoded)
+		return key, SourceEnv, err
+	}
+	if encoded := keyringLookup(); encoded != "" {
+		key, err := ParseKey(encoded)
+		return key, SourceKeyring, err
+	}
+	return nil, "", nil
+}
+
+// lookupKeyring reads the master key from the OS keyring ("" if unavailable or not stored)
+func lookupKeyring() string {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "linux":
+		cmd = exec.CommandContext(ctx, "secret-tool", "lookup", "service", keyringService, "account", keyringAccount)
+	case "darwin":
+		cmd = exec.CommandContext(ctx, "security", "find-generic-password", "-s", keyringService, "-a", keyringAccount, "-w")
+	default:
+		return ""
+	}
+
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
@@ -0,0 +1,39 @@
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// Store persists one credential per driver as an opaque blob (JSON by convention, see Get and Save)
+// Storage backends implement it, encrypting credentials at rest where they can, so drivers
+// need neither their own schema nor their own wiring.
+type Store interface {
+	// GetCredential returns a driver's credential (nil if none is stored)
+	GetCredential(ctx context.Context, driver string) ([]byte, error)
+	// SaveCredential stores or replaces a driver's credential
+	SaveCredential(ctx context.Context, driver string, data []byte) error
+}
+
+// Get decodes a driver's JSON credential into v
+// Reports false (and leaves v alone) if none is stored.
+func Get(ctx context.Context, store Store, driver string, v any) (bool, error) {
+	data, err := store.GetCredential(ctx, driver)
+	if err != nil || data == nil {
+		return false, err
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, fmt.Errorf("failed to decode %s credentials: %w", driver, err)
+	}
+	return true, nil
+}
+
+// Save stores v as a driver's JSON credential
+func Save(ctx context.Context, store Store, driver string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s credentials: %w", driver, err)
+	}
+	return store.SaveCredential(ctx, driver, data)
+}
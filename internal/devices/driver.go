@@ -36,11 +36,8 @@ type DeviceState struct {
 }
 
 // DriverCapabilities describes what features a driver supports
-type DriverCapabilities struct {
-	SupportsWarnings  bool
-	SupportsLiveState bool
-	SupportsScheduling bool
-}
+// Defined in core so the session manager and scheduler can consult them (see core.CapabilitiesOf)
+type DriverCapabilities = core.DriverCapabilities
 
 // CapableDriver is an optional interface that drivers can implement
 // to declare their capabilities
@@ -51,9 +48,52 @@ type CapableDriver interface {
 
 // ExtendableDriver is an optional interface that drivers can implement
 // to support session extensions (e.g., adding more time to a running session)
+// Drivers that implement CapableDriver must also set SupportsExtension
 type ExtendableDriver interface {
 	DeviceDriver
 	// ExtendSession extends an active session by adding more time
 	// Driver internally looks up device from session.DeviceID, merges config, and executes
 	ExtendSession(ctx context.Context, session *core.Session, additionalMinutes int) error
 }
+
+// HealthCheckableDriver is an optional interface that drivers can implement
+// to report whether they are ready to control devices (e.g., credentials present)
+type HealthCheckableDriver interface {
+	DeviceDriver
+	// HealthCheck returns an error if the driver cannot currently operate
+	HealthCheck(ctx context.Context) error
+}
+
+// BreakableDriver is an optional interface that drivers can implement
+// to run a dedicated action for mandatory breaks (e.g., lock the device and unlock it afterwards)
+// Used when the break action is "break"; drivers without it get a warning instead
+// Drivers that implement CapableDriver must also set SupportsBreaks
+type BreakableDriver interface {
+	DeviceDriver
+	// StartBreak is called when a mandatory break of breakMinutes starts
+	StartBreak(ctx context.Context, session *core.Session, breakMinutes int) error
+	// EndBreak is called when the break is over and the session resumes
+	EndBreak(ctx context.Context, session *core.Session) error
+}
+
+// ValidatingDriver is an optional interface that drivers can implement
+// to check a device's parameters at startup instead of at its first session
+type ValidatingDriver interface {
+	DeviceDriver
+	// Validate returns an error naming the device and the parameter to fix
+	Validate(device *Device) error
+}
+
+// HeartbeatRecorder records that a device checked in
+type HeartbeatRecorder interface {
+	// Record marks the device as seen now; source identifies the reporter (e.g., the driver name)
+	Record(ctx context.Context, deviceID, source string) error
+}
+
+// HeartbeatReportingDriver is an optional interface for drivers that poll their devices
+// The driver records a heartbeat whenever a device answers, so GET /api/v1/devices shows when it was last seen
+type HeartbeatReportingDriver interface {
+	DeviceDriver
+	// SetHeartbeatRecorder is called once at startup, before any session calls
+	SetHeartbeatRecorder(recorder HeartbeatRecorder)
+}
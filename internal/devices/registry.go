@@ -11,6 +11,7 @@ type Device struct {
 	Name       string                 // User-friendly name (e.g., "Living Room TV", "PlayStation 5")
 	Type       string                 // Device type for display/stats (e.g., "tv", "ps5", "ipad")
 	Emoji      string                 // Optional emoji override (default derived from type)
+	Timezone   string                 // Optional IANA timezone override for downtime (default server/child timezone)
 	Driver     string                 // Driver to use for control (e.g., "aqara", "mock")
 	Parameters map[string]interface{} // Driver-specific parameters (optional overrides)
 }
@@ -30,6 +31,11 @@ func (d *Device) GetType() string {
 	return d.Type
 }
 
+// GetTimezone returns the device timezone override, or "" if not set
+func (d *Device) GetTimezone() string {
+	return d.Timezone
+}
+
 // GetDriver returns the driver name
 func (d *Device) GetDriver() string {
 	return d.Driver
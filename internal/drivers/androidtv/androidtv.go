@@ -0,0 +1,344 @@
+// Package androidtv provides device drivers that control Android TV, Google TV and Fire TV
+// devices over ADB network debugging: they show warning notifications, return to the home
+// screen and put the device to sleep when a session ends, and optionally disable chosen apps
+// outside sessions.
+package androidtv
+
+import (
+	"context"
+	"crypto/rsa"
+	"fmt"
+	"log/slog"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"metron/internal/adb"
+	"metron/internal/core"
+	"metron/internal/devices"
+)
+
+const DriverName = "androidtv"
+
+// FireTVDriverName is the name of the same driver for Amazon Fire TV devices
+const FireTVDriverName = "firetv"
+
+// DefaultClientName is shown on the device's debugging prompt
+const DefaultClientName = "metron"
+
+// packagePattern matches Android package names; anything else is rejected
+// because package names are inserted into shell commands
+var packagePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*(\.[A-Za-z0-9_]+)+$`)
+
+// Shell runs shell commands on a device.
+type Shell interface {
+	Shell(ctx context.Context, command string) (string, error)
+}
+
+// Config contains Android TV driver configuration.
+type Config struct {
+	KeyFile    string // Default adb private key (PEM), e.g. ~/.android/adbkey
+	ClientName string // Name shown on the debugging prompt (default "metron")
+}
+
+// deviceConfig holds the ADB settings of a device from its parameters
+type deviceConfig struct {
+	Addr        string   // host:port
+	KeyFile     string   // adb private key
+	BlockedApps []string // Packages disabled outside sessions
+}
+
+// Driver implements the DeviceDriver interface by running ADB shell commands.
+type Driver struct {
+	name           string
+	config         Config
+	deviceRegistry *devices.Registry
+	logger         *slog.Logger
+	newShell       func(addr string, key *rsa.PrivateKey, name string) Shell
+
+	mu   sync.Mutex
+	keys map[string]*rsa.PrivateKey // Loaded keys by file
+}
+
+// NewDriver creates a new Android TV driver. The default key is loaded right away
+// so a wrong path fails at startup.
+func NewDriver(config Config, deviceRegistry *devices.Registry, logger *slog.Logger) (*Driver, error) {
+	return newDriver(DriverName, config, deviceRegistry, logger)
+}
+
+// NewFireTVDriver creates the driver for Fire TV devices. Fire OS is Android, so it runs
+// the same commands; a separate name keeps Fire TV devices apart in the configuration.
+func NewFireTVDriver(config Config, deviceRegistry *devices.Registry, logger *slog.Logger) (*Driver, error) {
+	return newDriver(FireTVDriverName, config, deviceRegistry, logger)
+}
+
+func newDriver(name string, config Config, deviceRegistry *devices.Registry, logger *slog.Logger) (*Driver, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if config.ClientName == "" {
+		config.ClientName = DefaultClientName
+	}
+
+	d := &Driver{
+		name:           name,
+		config:         config,
+		deviceRegistry: deviceRegistry,
+		logger:         logger.With("driver", name),
+		newShell: func(addr string, key *rsa.PrivateKey, name string) Shell {
+			return adb.NewClient(addr, key, name)
+		},
+		keys: make(map[string]*rsa.PrivateKey),
+	}
+	if config.KeyFile != "" {
+		if _, err := d.loadKey(config.KeyFile); err != nil {
+			return nil, err
+		}
+	}
+	return d, nil
+}
+
+// Name returns the driver name.
+func (d *Driver) Name() string {
+	return d.name
+}
+
+// Capabilities returns the driver capabilities.
+func (d *Driver) Capabilities() devices.DriverCapabilities {
+	return devices.DriverCapabilities{
+		SupportsWarnings:   true,
+		SupportsLiveState:  false,
+		SupportsScheduling: true,
+		SupportsBreaks:     true,
+	}
+}
+
+// Validate checks the device parameters and loads the device's adb key at startup.
+func (d *Driver) Validate(device *devices.Device) error {
+	cfg, err := d.readDeviceConfig(device)
+	if err != nil {
+		return err
+	}
+	if _, err := d.loadKey(cfg.KeyFile); err != nil {
+		return fmt.Errorf("device %s: %w", device.ID, err)
+	}
+	return nil
+}
+
+// StartSession enables the blocked apps again.
+func (d *Driver) StartSession(ctx context.Context, session *core.Session) error {
+	d.logger.Info("Starting Android TV session",
+		"session_id", session.ID,
+		"device_id", session.DeviceID,
+		"duration_minutes", session.ExpectedDuration)
+
+	if err := d.run(ctx, session.DeviceID, unblockCommands); err != nil {
+		d.logger.Error("Failed to unblock apps",
+			"session_id", session.ID,
+			"device_id", session.DeviceID,
+			"error", err)
+		return fmt.Errorf("failed to unblock apps: %w", err)
+	}
+	return nil
+}
+
+// StopSession closes and disables the blocked apps, returns to the home screen and
+// puts the device to sleep.
+func (d *Driver) StopSession(ctx context.Context, session *core.Session) error {
+	d.logger.Info("Stopping Android TV session",
+		"session_id", session.ID,
+		"device_id", session.DeviceID)
+
+	if err := d.run(ctx, session.DeviceID, blockCommands); err != nil {
+		d.logger.Error("Failed to put device to sleep",
+			"session_id", session.ID,
+			"device_id", session.DeviceID,
+			"error", err)
+		return fmt.Errorf("failed to put device to sleep: %w", err)
+	}
+	return nil
+}
+
+// ApplyWarning posts a notification with the remaining minutes.
+func (d *Driver) ApplyWarning(ctx context.Context, session *core.Session, minutesRemaining int) error {
+	message := fmt.Sprintf("⏱ %d min of screen time remaining", minutesRemaining)
+	warning := func(cfg *deviceConfig) []string {
+		return []string{"cmd notification post -S bigtext -t Metron metron_warning " + shellQuote(message)}
+	}
+
+	if err := d.run(ctx, session.DeviceID, warning); err != nil {
+		d.logger.Error("Failed to show Android TV warning",
+			"session_id", session.ID,
+			"device_id", session.DeviceID,
+			"error", err)
+		return fmt.Errorf("failed to show warning: %w", err)
+	}
+
+	d.logger.Info("Android TV warning shown",
+		"session_id", session.ID,
+		"minutes_remaining", minutesRemaining)
+	return nil
+}
+
+// StartBreak blocks apps and puts the device to sleep for a mandatory break.
+func (d *Driver) StartBreak(ctx context.Context, session *core.Session, breakMinutes int) error {
+	d.logger.Info("Starting Android TV break",
+		"session_id", session.ID,
+		"device_id", session.DeviceID,
+		"break_minutes", breakMinutes)
+
+	if err := d.run(ctx, session.DeviceID, blockCommands); err != nil {
+		d.logger.Error("Failed to put device to sleep for break",
+			"session_id", session.ID,
+			"error", err)
+		return fmt.Errorf("failed to put device to sleep: %w", err)
+	}
+	return nil
+}
+
+// EndBreak enables the blocked apps when the break is over.
+func (d *Driver) EndBreak(ctx context.Context, session *core.Session) error {
+	d.logger.Info("Ending Android TV break",
+		"session_id", session.ID,
+		"device_id", session.DeviceID)
+
+	if err := d.run(ctx, session.DeviceID, unblockCommands); err != nil {
+		d.logger.Error("Failed to unblock apps after break",
+			"session_id", session.ID,
+			"error", err)
+		return fmt.Errorf("failed to unblock apps: %w", err)
+	}
+	return nil
+}
+
+// GetLiveState is not supported.
+func (d *Driver) GetLiveState(ctx context.Context, deviceID string) (*devices.DeviceState, error) {
+	return nil, nil
+}
+
+// blockCommands closes and disables the blocked apps, then leaves the current app
+// and sleeps the device, so it wakes up on the home screen
+func blockCommands(cfg *deviceConfig) []string {
+	var commands []string
+	for _, app := range cfg.BlockedApps {
+		commands = append(commands, "am force-stop "+app, "pm disable-user --user 0 "+app)
+	}
+	return append(commands, "input keyevent KEYCODE_HOME", "input keyevent KEYCODE_SLEEP")
+}
+
+// unblockCommands enables the blocked apps
+func unblockCommands(cfg *deviceConfig) []string {
+	var commands []string
+	for _, app := range cfg.BlockedApps {
+		commands = append(commands, "pm enable "+app)
+	}
+	return commands
+}
+
+// run executes the commands for a device in one shell call
+func (d *Driver) run(ctx context.Context, deviceID string, commands func(cfg *deviceConfig) []string) error {
+	cfg, err := d.getDeviceConfig(deviceID)
+	if err != nil {
+		return err
+	}
+
+	list := commands(cfg)
+	if len(list) == 0 {
+		return nil
+	}
+
+	key, err := d.loadKey(cfg.KeyFile)
+	if err != nil {
+		return err
+	}
+
+	command := strings.Join(list, "; ")
+	output, err := d.newShell(cfg.Addr, key, d.config.ClientName).Shell(ctx, command)
+	if err != nil {
+		return err
+	}
+
+	d.logger.Debug("ADB command run",
+		"device_id", deviceID,
+		"command", command,
+		"output", strings.TrimSpace(output))
+	return nil
+}
+
+// loadKey returns the key in the file, loading it once
+func (d *Driver) loadKey(path string) (*rsa.PrivateKey, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if key, ok := d.keys[path]; ok {
+		return key, nil
+	}
+	key, err := adb.LoadKey(path)
+	if err != nil {
+		return nil, err
+	}
+	d.keys[path] = key
+	return key, nil
+}
+
+// getDeviceConfig looks up the device and reads its parameters
+func (d *Driver) getDeviceConfig(deviceID string) (*deviceConfig, error) {
+	device, err := d.deviceRegistry.Get(deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device %s: %w", deviceID, err)
+	}
+	return d.readDeviceConfig(device)
+}
+
+// readDeviceConfig reads the host, port, key and blocked_apps device parameters
+func (d *Driver) readDeviceConfig(device *devices.Device) (*deviceConfig, error) {
+	host, _ := device.GetParameter("host").(string)
+	if host == "" {
+		return nil, fmt.Errorf("device %s: host parameter is required", device.ID)
+	}
+
+	port := adb.DefaultPort
+	switch p := device.GetParameter("port").(type) {
+	case float64:
+		port = int(p)
+	case int:
+		port = p
+	}
+
+	cfg := &deviceConfig{
+		Addr:    net.JoinHostPort(host, strconv.Itoa(port)),
+		KeyFile: d.config.KeyFile,
+	}
+	if key, ok := device.GetParameter("key").(string); ok && key != "" {
+		cfg.KeyFile = key
+	}
+	if cfg.KeyFile == "" {
+		return nil, fmt.Errorf("device %s: key parameter is required when androidtv key_file is not set", device.ID)
+	}
+
+	switch apps := device.GetParameter("blocked_apps").(type) {
+	case []string:
+		cfg.BlockedApps = apps
+	case []interface{}:
+		for _, app := range apps {
+			s, ok := app.(string)
+			if !ok {
+				return nil, fmt.Errorf("device %s: blocked_apps must be a list of package names", device.ID)
+			}
+			cfg.BlockedApps = append(cfg.BlockedApps, s)
+		}
+	}
+	for _, app := range cfg.BlockedApps {
+		if !packagePattern.MatchString(app) {
+			return nil, fmt.Errorf("device %s: invalid package name %q", device.ID, app)
+		}
+	}
+	return cfg, nil
+}
+
+// shellQuote quotes s for the device shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,165 @@
+package androidtv
+
+import (
+	"context"
+	"crypto/rsa"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"metron/internal/adb"
+	"metron/internal/core"
+	"metron/internal/devices"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testKeyPEM is shared because generating 2048-bit keys is slow
+var testKeyPEM = func() []byte {
+	key, err := adb.GenerateKey()
+	if err != nil {
+		panic(err)
+	}
+	encoded, err := adb.EncodeKey(key)
+	if err != nil {
+		panic(err)
+	}
+	return encoded
+}()
+
+// mockShell records the commands run on each address.
+type mockShell struct {
+	addrs    []string
+	commands []string
+	failErr  error
+}
+
+func (m *mockShell) Shell(_ context.Context, command string) (string, error) {
+	if m.failErr != nil {
+		return "", m.failErr
+	}
+	m.commands = append(m.commands, command)
+	return "", nil
+}
+
+func writeTestKey(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "adbkey")
+	require.NoError(t, os.WriteFile(path, testKeyPEM, 0600))
+	return path
+}
+
+func setupTestDriver(t *testing.T, params map[string]interface{}) (*Driver, *mockShell) {
+	t.Helper()
+
+	registry := devices.NewRegistry()
+	require.NoError(t, registry.Register(&devices.Device{
+		ID:         "kids-tv",
+		Name:       "Kids TV",
+		Type:       "tv",
+		Driver:     DriverName,
+		Parameters: params,
+	}))
+
+	driver, err := NewDriver(Config{KeyFile: writeTestKey(t)}, registry, nil)
+	require.NoError(t, err)
+
+	shell := &mockShell{}
+	driver.newShell = func(addr string, key *rsa.PrivateKey, name string) Shell {
+		shell.addrs = append(shell.addrs, addr)
+		return shell
+	}
+	return driver, shell
+}
+
+func TestDriver_SessionLifecycle(t *testing.T) {
+	driver, shell := setupTestDriver(t, map[string]interface{}{
+		"host":         "192.168.1.60",
+		"blocked_apps": []interface{}{"com.google.android.youtube.tv"},
+	})
+	session := &core.Session{ID: "ses_1", DeviceID: "kids-tv"}
+	ctx := context.Background()
+
+	require.NoError(t, driver.StartSession(ctx, session))
+	require.NoError(t, driver.ApplyWarning(ctx, session, 5))
+	require.NoError(t, driver.StopSession(ctx, session))
+
+	assert.Equal(t, []string{
+		"pm enable com.google.android.youtube.tv",
+		"cmd notification post -S bigtext -t Metron metron_warning '⏱ 5 min of screen time remaining'",
+		"am force-stop com.google.android.youtube.tv; pm disable-user --user 0 com.google.android.youtube.tv; input keyevent KEYCODE_HOME; input keyevent KEYCODE_SLEEP",
+	}, shell.commands)
+	assert.Equal(t, "192.168.1.60:5555", shell.addrs[0])
+}
+
+func TestDriver_WithoutBlockedApps(t *testing.T) {
+	driver, shell := setupTestDriver(t, map[string]interface{}{"host": "192.168.1.60", "port": float64(5556)})
+	session := &core.Session{ID: "ses_1", DeviceID: "kids-tv"}
+	ctx := context.Background()
+
+	// Nothing to enable: no connection
+	require.NoError(t, driver.StartSession(ctx, session))
+	assert.Empty(t, shell.commands)
+
+	require.NoError(t, driver.StartBreak(ctx, session, 10))
+	require.NoError(t, driver.EndBreak(ctx, session))
+	assert.Equal(t, []string{"input keyevent KEYCODE_HOME; input keyevent KEYCODE_SLEEP"}, shell.commands)
+	assert.Equal(t, []string{"192.168.1.60:5556"}, shell.addrs)
+}
+
+func TestDriver_InvalidParameters(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+	}{
+		{"missing host", map[string]interface{}{}},
+		{"package with shell characters", map[string]interface{}{"host": "192.168.1.60", "blocked_apps": []interface{}{"com.app; reboot"}}},
+		{"missing key file", map[string]interface{}{"host": "192.168.1.60", "key": "/nonexistent/adbkey"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver, shell := setupTestDriver(t, tt.params)
+			err := driver.StopSession(context.Background(), &core.Session{ID: "ses_1", DeviceID: "kids-tv"})
+			assert.Error(t, err)
+			assert.Empty(t, shell.commands)
+
+			// Startup validation catches the same problems
+			device, err := driver.deviceRegistry.Get("kids-tv")
+			require.NoError(t, err)
+			assert.Error(t, driver.Validate(device))
+		})
+	}
+}
+
+func TestDriver_Validate(t *testing.T) {
+	driver, _ := setupTestDriver(t, map[string]interface{}{"host": "192.168.1.60"})
+	device, err := driver.deviceRegistry.Get("kids-tv")
+	require.NoError(t, err)
+	assert.NoError(t, driver.Validate(device))
+}
+
+func TestDriver_ShellError(t *testing.T) {
+	driver, shell := setupTestDriver(t, map[string]interface{}{"host": "192.168.1.60"})
+	shell.failErr = adb.ErrUnauthorized
+
+	err := driver.StopSession(context.Background(), &core.Session{ID: "ses_1", DeviceID: "kids-tv"})
+	assert.True(t, errors.Is(err, adb.ErrUnauthorized))
+}
+
+func TestNewFireTVDriver(t *testing.T) {
+	driver, err := NewFireTVDriver(Config{KeyFile: writeTestKey(t)}, devices.NewRegistry(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, FireTVDriverName, driver.Name())
+}
+
+func TestNewDriver_InvalidKeyFile(t *testing.T) {
+	_, err := NewDriver(Config{KeyFile: "/nonexistent/adbkey"}, devices.NewRegistry(), nil)
+	assert.Error(t, err)
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, `'it'\''s time'`, shellQuote("it's time"))
+}
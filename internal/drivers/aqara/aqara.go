@@ -11,6 +11,7 @@ import (
 	"io"
 	"log/slog"
 	"metron/internal/core"
+	"metron/internal/credentials"
 	"metron/internal/devices"
 	"net/http"
 	"strconv"
@@ -33,12 +34,13 @@ type Config struct {
 	PINSceneID  string
 	WarnSceneID string
 	OffSceneID  string
+	Timeout     time.Duration // HTTP client timeout (default 30s)
 }
 
 // Driver implements the DeviceDriver interface for Aqara Cloud
 type Driver struct {
 	config       Config
-	storage      AqaraTokenStorage
+	tokens       *TokenStore
 	httpClient   *http.Client
 	accessToken  string        // In-memory cached access token
 	tokenExpiry  time.Time     // When the access token expires
@@ -47,15 +49,20 @@ type Driver struct {
 }
 
 // NewDriver creates a new Aqara driver
-func NewDriver(config Config, storage AqaraTokenStorage, logger *slog.Logger) *Driver {
+// Tokens are kept in the "aqara" entry of the credential store.
+func NewDriver(config Config, store credentials.Store, logger *slog.Logger) *Driver {
 	if logger == nil {
 		logger = slog.Default()
 	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
 	return &Driver{
 		config:  config,
-		storage: storage,
+		tokens:  NewTokenStore(store),
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout: timeout,
 		},
 		logger: logger,
 	}
@@ -182,7 +189,7 @@ func (d *Driver) getAccessToken(ctx context.Context) (string, error) {
 	}
 
 	// Get tokens from storage
-	tokens, err := d.storage.GetAqaraTokens(ctx)
+	tokens, err := d.tokens.Get(ctx)
 	if err != nil {
 		return "", fmt.Errorf("failed to get tokens from storage: %w", err)
 	}
@@ -216,7 +223,7 @@ func (d *Driver) getAccessToken(ctx context.Context) (string, error) {
 	tokens.RefreshToken = newRefreshToken
 	tokens.AccessTokenExpiresAt = &expiryTime
 
-	if err := d.storage.SaveAqaraTokens(ctx, tokens); err != nil {
+	if err := d.tokens.Save(ctx, tokens); err != nil {
 		// Log error but don't fail - we have the token in memory
 		fmt.Printf("Warning: failed to save refreshed tokens to storage: %v\n", err)
 	}
@@ -314,6 +321,19 @@ func (d *Driver) Capabilities() devices.DriverCapabilities {
 	}
 }
 
+// HealthCheck verifies that a refresh token is available in storage
+// It does not call the Aqara Cloud API
+func (d *Driver) HealthCheck(ctx context.Context) error {
+	tokens, err := d.tokens.Get(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get tokens from storage: %w", err)
+	}
+	if tokens == nil || tokens.RefreshToken == "" {
+		return ErrNoRefreshToken
+	}
+	return nil
+}
+
 // triggerScene triggers an Aqara scene via the Cloud API
 func (d *Driver) triggerScene(ctx context.Context, sceneID string) error {
 	// Get valid access token (will refresh if necessary)
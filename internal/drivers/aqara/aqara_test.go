@@ -15,24 +15,31 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-// mockTokenStorage is a mock implementation of AqaraTokenStorage for testing
+// mockTokenStorage is a mock credential store holding the Aqara tokens for testing
 type mockTokenStorage struct {
 	tokens *AqaraTokens
 	err    error
 }
 
-func (m *mockTokenStorage) GetAqaraTokens(ctx context.Context) (*AqaraTokens, error) {
+func (m *mockTokenStorage) GetCredential(ctx context.Context, driver string) ([]byte, error) {
 	if m.err != nil {
 		return nil, m.err
 	}
-	return m.tokens, nil
+	if m.tokens == nil {
+		return nil, nil
+	}
+	return json.Marshal(m.tokens)
 }
 
-func (m *mockTokenStorage) SaveAqaraTokens(ctx context.Context, tokens *AqaraTokens) error {
+func (m *mockTokenStorage) SaveCredential(ctx context.Context, driver string, data []byte) error {
 	if m.err != nil {
 		return m.err
 	}
-	m.tokens = tokens
+	var tokens AqaraTokens
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return err
+	}
+	m.tokens = &tokens
 	return nil
 }
 
@@ -361,12 +368,25 @@ func TestDriver_GetLiveState(t *testing.T) {
 	assert.Nil(t, state)
 }
 
+func TestDriver_HealthCheck(t *testing.T) {
+	// Refresh token present
+	driver := NewDriver(Config{}, newMockStorage(), nil)
+	assert.NoError(t, driver.HealthCheck(context.Background()))
+
+	// No tokens stored
+	driver = NewDriver(Config{}, &mockTokenStorage{}, nil)
+	assert.ErrorIs(t, driver.HealthCheck(context.Background()), ErrNoRefreshToken)
+}
+
 func TestDriver_InterfaceImplementation(t *testing.T) {
 	// Verify that Driver implements DeviceDriver
 	var _ devices.DeviceDriver = (*Driver)(nil)
 
 	// Verify that Driver implements CapableDriver
 	var _ devices.CapableDriver = (*Driver)(nil)
+
+	// Verify that Driver implements HealthCheckableDriver
+	var _ devices.HealthCheckableDriver = (*Driver)(nil)
 }
 
 func TestGenerateSignature(t *testing.T) {
@@ -405,3 +425,26 @@ func TestGenerateNonce(t *testing.T) {
 	assert.NotEmpty(t, nonce2)
 	assert.NotEqual(t, nonce1, nonce2)
 }
+
+func TestTokenStore_KeepsCreatedAt(t *testing.T) {
+	ctx := context.Background()
+	storage := newMockStorage()
+	created := storage.tokens.CreatedAt.Add(-time.Hour)
+	storage.tokens.CreatedAt = created
+
+	store := NewTokenStore(storage)
+	tokens, err := store.Get(ctx)
+	require.NoError(t, err)
+	tokens.RefreshToken = "new-refresh-token"
+	require.NoError(t, store.Save(ctx, tokens))
+
+	tokens, err = store.Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "new-refresh-token", tokens.RefreshToken)
+	assert.True(t, tokens.CreatedAt.Equal(created))
+	assert.True(t, tokens.UpdatedAt.After(created))
+
+	tokens, err = NewTokenStore(&mockTokenStorage{}).Get(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, tokens)
+}
@@ -2,21 +2,57 @@ package aqara
 
 import (
 	"context"
+	"errors"
+	"metron/internal/credentials"
 	"time"
 )
 
+// credentialName is the driver's entry in the credential store
+const credentialName = "aqara"
+
 // AqaraTokens represents the Aqara Cloud API tokens
 type AqaraTokens struct {
-	RefreshToken         string
-	AccessToken          string
-	AccessTokenExpiresAt *time.Time
-	CreatedAt            time.Time
-	UpdatedAt            time.Time
+	RefreshToken         string     `json:"refresh_token"`
+	AccessToken          string     `json:"access_token,omitempty"`
+	AccessTokenExpiresAt *time.Time `json:"access_token_expires_at,omitempty"`
+	CreatedAt            time.Time  `json:"created_at"`
+	UpdatedAt            time.Time  `json:"updated_at"`
+}
+
+// TokenStore keeps the Aqara tokens in the "aqara" entry of a credential store
+type TokenStore struct {
+	store credentials.Store
+}
+
+// NewTokenStore creates a token store on top of a credential store
+func NewTokenStore(store credentials.Store) *TokenStore {
+	return &TokenStore{store: store}
 }
 
-// AqaraTokenStorage defines the interface for Aqara token persistence
-// This interface is implemented by the storage layer to avoid tight coupling
-type AqaraTokenStorage interface {
-	GetAqaraTokens(ctx context.Context) (*AqaraTokens, error)
-	SaveAqaraTokens(ctx context.Context, tokens *AqaraTokens) error
+// Get retrieves the stored tokens (nil if none)
+func (t *TokenStore) Get(ctx context.Context) (*AqaraTokens, error) {
+	var tokens AqaraTokens
+	found, err := credentials.Get(ctx, t.store, credentialName, &tokens)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &tokens, nil
+}
+
+// Save stores the tokens, keeping the creation time of the tokens they replace
+func (t *TokenStore) Save(ctx context.Context, tokens *AqaraTokens) error {
+	existing, err := t.Get(ctx)
+	if err != nil && !errors.Is(err, credentials.ErrNoKey) && !errors.Is(err, credentials.ErrKeyMismatch) {
+		return err
+	}
+
+	// Tokens that can't be decrypted any more (key lost or changed) are replaced
+	now := time.Now()
+	tokens.CreatedAt = now
+	if existing != nil {
+		tokens.CreatedAt = existing.CreatedAt
+	}
+	tokens.UpdatedAt = now
+
+	return credentials.Save(ctx, t.store, credentialName, tokens)
 }
@@ -0,0 +1,205 @@
+package familylink
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultBaseURL is the kidsmanagement API used by the Family Link web app (familylink.google.com)
+	// It is not a public API: requests mirror the web app and may break when Google changes it
+	defaultBaseURL = "https://kidsmanagement-pa.clients6.google.com/kidsmanagement/v1"
+
+	// origin is the web app origin the SAPISIDHASH authorization is computed for
+	origin = "https://familylink.google.com"
+)
+
+// Override actions applied to a supervised device
+const (
+	ActionLock      = "LOCK"       // Lock the device until unlocked
+	ActionUnlock    = "UNLOCK"     // Lift a lock
+	ActionBonusTime = "BONUS_TIME" // Add screen time for today on top of the daily limit
+)
+
+// Override is a time limit override for one device
+type Override struct {
+	Action   string
+	Duration time.Duration // Bonus time for ActionBonusTime
+}
+
+// Client talks to Family Link on behalf of the parent account
+type Client interface {
+	// ApplyOverride locks, unlocks or grants bonus time on a child's device
+	ApplyOverride(ctx context.Context, accountID, deviceID string, override Override) error
+
+	// DeviceUsage returns the screen time Family Link recorded for a child's device on a date
+	DeviceUsage(ctx context.Context, accountID, deviceID string, date time.Time) (time.Duration, error)
+}
+
+// httpClient calls the kidsmanagement API with the cookies of a signed-in parent browser session
+type httpClient struct {
+	baseURL string
+	cookies string
+	sapisid string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewHTTPClient creates a Family Link client from the configured browser cookies
+// Returns an error if the cookies do not include SAPISID, which signs every request
+func NewHTTPClient(config Config) (Client, error) {
+	return newHTTPClient(config, defaultBaseURL)
+}
+
+func newHTTPClient(config Config, baseURL string) (*httpClient, error) {
+	sapisid := cookieValue(config.Cookies, "SAPISID")
+	if sapisid == "" {
+		return nil, fmt.Errorf("family link cookies must include SAPISID")
+	}
+	return &httpClient{
+		baseURL: baseURL,
+		cookies: config.Cookies,
+		sapisid: sapisid,
+		apiKey:  config.APIKey,
+		client: &http.Client{
+			Timeout: 15 * time.Second,
+		},
+	}, nil
+}
+
+// timeLimitOverride is one override in a batchCreate request
+type timeLimitOverride struct {
+	DeviceID          string `json:"deviceId"`
+	Action            string `json:"action"`
+	BonusTimeDuration string `json:"bonusTimeDuration,omitempty"` // Protobuf duration, e.g. "1800s"
+}
+
+// ApplyOverride creates a time limit override for the device
+func (c *httpClient) ApplyOverride(ctx context.Context, accountID, deviceID string, override Override) error {
+	item := timeLimitOverride{
+		DeviceID: deviceID,
+		Action:   override.Action,
+	}
+	if override.Action == ActionBonusTime {
+		item.BonusTimeDuration = strconv.Itoa(int(override.Duration.Seconds())) + "s"
+	}
+	body := map[string]interface{}{
+		"timeLimitOverrides": []timeLimitOverride{item},
+	}
+
+	path := "/people/" + url.PathEscape(accountID) + "/timeLimitOverrides:batchCreate"
+	return c.do(ctx, http.MethodPost, path, body, nil)
+}
+
+// appsAndUsage is the part of the appsandusage response used for usage import
+type appsAndUsage struct {
+	AppUsageSessions []struct {
+		DeviceID string `json:"deviceId"`
+		Date     struct {
+			Year  int `json:"year"`
+			Month int `json:"month"`
+			Day   int `json:"day"`
+		} `json:"date"`
+		Usage string `json:"usage"` // Protobuf duration, e.g. "1234.5s"
+	} `json:"appUsageSessions"`
+}
+
+// DeviceUsage sums the app usage sessions of the device on the date
+// Sessions without a device ID are counted, as older responses omit it
+func (c *httpClient) DeviceUsage(ctx context.Context, accountID, deviceID string, date time.Time) (time.Duration, error) {
+	var result appsAndUsage
+	path := "/people/" + url.PathEscape(accountID) + "/appsandusage?capabilities=CAPABILITY_APP_USAGE_SESSION"
+	if err := c.do(ctx, http.MethodGet, path, nil, &result); err != nil {
+		return 0, err
+	}
+
+	year, month, day := date.Date()
+	var total time.Duration
+	for _, session := range result.AppUsageSessions {
+		if session.Date.Year != year || session.Date.Month != int(month) || session.Date.Day != day {
+			continue
+		}
+		if session.DeviceID != "" && session.DeviceID != deviceID {
+			continue
+		}
+		usage, err := time.ParseDuration(session.Usage)
+		if err != nil {
+			return 0, fmt.Errorf("invalid usage %q: %w", session.Usage, err)
+		}
+		total += usage
+	}
+	return total, nil
+}
+
+// do sends an authorized request and decodes the JSON response into result if given
+func (c *httpClient) do(ctx context.Context, method, path string, body, result interface{}) error {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal body: %w", err)
+		}
+		bodyReader = bytes.NewReader(bodyBytes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bodyReader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", sapisidHash(c.sapisid, time.Now()))
+	req.Header.Set("Cookie", c.cookies)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("X-Goog-Api-Key", c.apiKey)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden {
+			return fmt.Errorf("family link rejected the cookies (status %d), sign in again and update them", resp.StatusCode)
+		}
+		return fmt.Errorf("family link request failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	}
+
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("failed to decode family link response: %w", err)
+		}
+	}
+	return nil
+}
+
+// sapisidHash returns the Authorization header Google web apps derive from the SAPISID cookie
+func sapisidHash(sapisid string, now time.Time) string {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	sum := sha1.Sum([]byte(timestamp + " " + sapisid + " " + origin))
+	return "SAPISIDHASH " + timestamp + "_" + hex.EncodeToString(sum[:])
+}
+
+// cookieValue returns the value of a cookie in a Cookie header string
+func cookieValue(cookies, name string) string {
+	for _, part := range strings.Split(cookies, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok && key == name {
+			return value
+		}
+	}
+	return ""
+}
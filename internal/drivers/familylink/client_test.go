@@ -0,0 +1,92 @@
+package familylink
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testCookies = "SID=abc; SAPISID=sapisid-value; HSID=def"
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *httpClient {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := newHTTPClient(Config{Cookies: testCookies, APIKey: "api-key"}, server.URL)
+	require.NoError(t, err)
+	return client
+}
+
+func TestNewHTTPClient_RequiresSAPISID(t *testing.T) {
+	_, err := NewHTTPClient(Config{Cookies: "SID=abc; HSID=def", APIKey: "api-key"})
+	assert.Error(t, err)
+}
+
+func TestHTTPClient_ApplyOverride(t *testing.T) {
+	var body map[string]interface{}
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/people/child-account/timeLimitOverrides:batchCreate", r.URL.Path)
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "SAPISIDHASH "))
+		assert.Equal(t, testCookies, r.Header.Get("Cookie"))
+		assert.Equal(t, "api-key", r.Header.Get("X-Goog-Api-Key"))
+		assert.Equal(t, origin, r.Header.Get("Origin"))
+
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Write([]byte(`{}`))
+	})
+
+	err := client.ApplyOverride(context.Background(), "child-account", "fl-device", Override{Action: ActionBonusTime, Duration: 30 * time.Minute})
+	require.NoError(t, err)
+
+	overrides := body["timeLimitOverrides"].([]interface{})
+	require.Len(t, overrides, 1)
+	assert.Equal(t, map[string]interface{}{
+		"deviceId":          "fl-device",
+		"action":            ActionBonusTime,
+		"bonusTimeDuration": "1800s",
+	}, overrides[0])
+}
+
+func TestHTTPClient_DeviceUsage(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/people/child-account/appsandusage", r.URL.Path)
+		w.Write([]byte(`{"appUsageSessions": [
+			{"deviceId": "fl-device", "date": {"year": 2026, "month": 3, "day": 2}, "usage": "600.5s"},
+			{"deviceId": "fl-device", "date": {"year": 2026, "month": 3, "day": 2}, "usage": "300s"},
+			{"deviceId": "fl-tablet", "date": {"year": 2026, "month": 3, "day": 2}, "usage": "900s"},
+			{"deviceId": "fl-device", "date": {"year": 2026, "month": 3, "day": 1}, "usage": "900s"}
+		]}`))
+	})
+
+	usage, err := client.DeviceUsage(context.Background(), "child-account", "fl-device", time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.Equal(t, 900500*time.Millisecond, usage)
+}
+
+func TestHTTPClient_RejectedCookies(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	err := client.ApplyOverride(context.Background(), "child-account", "fl-device", Override{Action: ActionLock})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "sign in again")
+}
+
+func TestSAPISIDHash(t *testing.T) {
+	// SHA-1 of "1700000000 sapisid-value https://familylink.google.com"
+	hash := sapisidHash("sapisid-value", time.Unix(1700000000, 0))
+	assert.Regexp(t, `^SAPISIDHASH 1700000000_[0-9a-f]{40}$`, hash)
+	assert.Equal(t, hash, sapisidHash("sapisid-value", time.Unix(1700000000, 0)))
+	assert.NotEqual(t, hash, sapisidHash("other", time.Unix(1700000000, 0)))
+}
@@ -0,0 +1,179 @@
+// Package familylink provides a device driver that enforces sessions on Android devices
+// supervised with Google Family Link, and an importer that charges device usage recorded
+// by Family Link outside sessions to the child's daily time.
+//
+// Family Link has no public API. The client signs requests like the Family Link web app,
+// using the cookies of a signed-in parent browser session.
+package familylink
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"metron/internal/core"
+	"metron/internal/devices"
+)
+
+const DriverName = "familylink"
+
+// Config contains Family Link client configuration.
+type Config struct {
+	Cookies string // Cookie header of a signed-in familylink.google.com session (must include SAPISID)
+	APIKey  string // X-Goog-Api-Key sent by the Family Link web app
+}
+
+// deviceConfig holds the Family Link identifiers of a device
+type deviceConfig struct {
+	AccountID string // Family Link user ID of the child
+	DeviceID  string // Family Link device ID
+	ChildID   string // Metron child charged for imported usage (optional)
+}
+
+// Driver implements the DeviceDriver interface by applying Family Link overrides.
+// Devices should have a daily limit of zero (or be locked) in Family Link: the driver
+// unlocks them and grants bonus time for each session, and locks them again afterwards.
+type Driver struct {
+	client         Client
+	deviceRegistry *devices.Registry
+	logger         *slog.Logger
+}
+
+// NewDriver creates a new Family Link driver.
+func NewDriver(client Client, deviceRegistry *devices.Registry, logger *slog.Logger) *Driver {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Driver{
+		client:         client,
+		deviceRegistry: deviceRegistry,
+		logger:         logger.With("driver", DriverName),
+	}
+}
+
+// Name returns the driver name.
+func (d *Driver) Name() string {
+	return DriverName
+}
+
+// Capabilities returns the driver capabilities.
+func (d *Driver) Capabilities() devices.DriverCapabilities {
+	return devices.DriverCapabilities{
+		SupportsWarnings:   false, // Family Link warns on the device itself before bonus time runs out
+		SupportsLiveState:  false,
+		SupportsScheduling: true,
+		SupportsExtension:  true,
+		SupportsBreaks:     true,
+	}
+}
+
+// Validate checks the device parameters at startup.
+func (d *Driver) Validate(device *devices.Device) error {
+	_, err := readDeviceConfig(device)
+	return err
+}
+
+// StartSession unlocks the device and grants the session duration as bonus time.
+func (d *Driver) StartSession(ctx context.Context, session *core.Session) error {
+	d.logger.Info("Starting Family Link session",
+		"session_id", session.ID,
+		"device_id", session.DeviceID,
+		"duration_minutes", session.ExpectedDuration)
+
+	bonus := time.Duration(session.ExpectedDuration) * time.Minute
+	return d.apply(ctx, session, Override{Action: ActionUnlock}, Override{Action: ActionBonusTime, Duration: bonus})
+}
+
+// StopSession locks the device.
+func (d *Driver) StopSession(ctx context.Context, session *core.Session) error {
+	d.logger.Info("Stopping Family Link session",
+		"session_id", session.ID,
+		"device_id", session.DeviceID)
+
+	return d.apply(ctx, session, Override{Action: ActionLock})
+}
+
+// ApplyWarning is not supported: Family Link shows its own warning before time runs out.
+func (d *Driver) ApplyWarning(ctx context.Context, session *core.Session, minutesRemaining int) error {
+	d.logger.Debug("Family Link warning requested but not supported",
+		"session_id", session.ID,
+		"minutes_remaining", minutesRemaining)
+	return nil
+}
+
+// ExtendSession grants the additional minutes as bonus time.
+func (d *Driver) ExtendSession(ctx context.Context, session *core.Session, additionalMinutes int) error {
+	d.logger.Info("Extending Family Link session",
+		"session_id", session.ID,
+		"additional_minutes", additionalMinutes)
+
+	bonus := time.Duration(additionalMinutes) * time.Minute
+	return d.apply(ctx, session, Override{Action: ActionBonusTime, Duration: bonus})
+}
+
+// StartBreak locks the device for a mandatory break; bonus time not used yet is kept.
+func (d *Driver) StartBreak(ctx context.Context, session *core.Session, breakMinutes int) error {
+	d.logger.Info("Starting Family Link break",
+		"session_id", session.ID,
+		"break_minutes", breakMinutes)
+
+	return d.apply(ctx, session, Override{Action: ActionLock})
+}
+
+// EndBreak unlocks the device when the break is over.
+func (d *Driver) EndBreak(ctx context.Context, session *core.Session) error {
+	d.logger.Info("Ending Family Link break",
+		"session_id", session.ID)
+
+	return d.apply(ctx, session, Override{Action: ActionUnlock})
+}
+
+// GetLiveState is not supported.
+func (d *Driver) GetLiveState(ctx context.Context, deviceID string) (*devices.DeviceState, error) {
+	return nil, nil
+}
+
+// apply sends overrides for the session's device in order
+func (d *Driver) apply(ctx context.Context, session *core.Session, overrides ...Override) error {
+	cfg, err := d.getDeviceConfig(session.DeviceID)
+	if err != nil {
+		d.logger.Error("Failed to get Family Link device config",
+			"session_id", session.ID,
+			"error", err)
+		return err
+	}
+
+	for _, override := range overrides {
+		if err := d.client.ApplyOverride(ctx, cfg.AccountID, cfg.DeviceID, override); err != nil {
+			d.logger.Error("Failed to apply Family Link override",
+				"session_id", session.ID,
+				"action", override.Action,
+				"error", err)
+			return fmt.Errorf("failed to apply %s: %w", override.Action, err)
+		}
+	}
+	return nil
+}
+
+// getDeviceConfig reads the Family Link identifiers from the device parameters
+func (d *Driver) getDeviceConfig(deviceID string) (*deviceConfig, error) {
+	device, err := d.deviceRegistry.Get(deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device %s: %w", deviceID, err)
+	}
+	return readDeviceConfig(device)
+}
+
+// readDeviceConfig reads the account_id, device_id and child_id device parameters
+func readDeviceConfig(device *devices.Device) (*deviceConfig, error) {
+	cfg := &deviceConfig{}
+	cfg.AccountID, _ = device.GetParameter("account_id").(string)
+	cfg.DeviceID, _ = device.GetParameter("device_id").(string)
+	cfg.ChildID, _ = device.GetParameter("child_id").(string)
+
+	if cfg.AccountID == "" || cfg.DeviceID == "" {
+		return nil, fmt.Errorf("device %s: account_id and device_id parameters are required", device.ID)
+	}
+	return cfg, nil
+}
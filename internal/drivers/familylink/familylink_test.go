@@ -0,0 +1,109 @@
+package familylink
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"metron/internal/core"
+	"metron/internal/devices"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// appliedOverride is an override recorded by mockClient.
+type appliedOverride struct {
+	AccountID string
+	DeviceID  string
+	Override  Override
+}
+
+// mockClient records overrides and returns configured usage.
+type mockClient struct {
+	overrides []appliedOverride
+	usage     map[string]time.Duration // keyed by YYYY-MM-DD
+	failErr   error
+}
+
+func (m *mockClient) ApplyOverride(_ context.Context, accountID, deviceID string, override Override) error {
+	if m.failErr != nil {
+		return m.failErr
+	}
+	m.overrides = append(m.overrides, appliedOverride{AccountID: accountID, DeviceID: deviceID, Override: override})
+	return nil
+}
+
+func (m *mockClient) DeviceUsage(_ context.Context, accountID, deviceID string, date time.Time) (time.Duration, error) {
+	if m.failErr != nil {
+		return 0, m.failErr
+	}
+	return m.usage[date.Format("2006-01-02")], nil
+}
+
+func (m *mockClient) actions() []string {
+	actions := make([]string, len(m.overrides))
+	for i, applied := range m.overrides {
+		actions[i] = applied.Override.Action
+	}
+	return actions
+}
+
+func setupTestDriver(t *testing.T, params map[string]interface{}) (*Driver, *mockClient) {
+	t.Helper()
+
+	registry := devices.NewRegistry()
+	require.NoError(t, registry.Register(&devices.Device{
+		ID:         "phone1",
+		Name:       "Android Phone",
+		Type:       "phone",
+		Driver:     DriverName,
+		Parameters: params,
+	}))
+
+	client := &mockClient{}
+	return NewDriver(client, registry, nil), client
+}
+
+func TestDriver_SessionLifecycle(t *testing.T) {
+	driver, client := setupTestDriver(t, map[string]interface{}{
+		"account_id": "child-account",
+		"device_id":  "fl-device",
+	})
+	session := &core.Session{ID: "ses_1", DeviceID: "phone1", ExpectedDuration: 30}
+	ctx := context.Background()
+
+	require.NoError(t, driver.StartSession(ctx, session))
+	require.Len(t, client.overrides, 2)
+	assert.Equal(t, appliedOverride{"child-account", "fl-device", Override{Action: ActionUnlock}}, client.overrides[0])
+	assert.Equal(t, Override{Action: ActionBonusTime, Duration: 30 * time.Minute}, client.overrides[1].Override)
+
+	require.NoError(t, driver.ExtendSession(ctx, session, 15))
+	assert.Equal(t, Override{Action: ActionBonusTime, Duration: 15 * time.Minute}, client.overrides[2].Override)
+
+	require.NoError(t, driver.StartBreak(ctx, session, 10))
+	require.NoError(t, driver.EndBreak(ctx, session))
+	require.NoError(t, driver.StopSession(ctx, session))
+
+	assert.Equal(t, []string{ActionUnlock, ActionBonusTime, ActionBonusTime, ActionLock, ActionUnlock, ActionLock}, client.actions())
+}
+
+func TestDriver_MissingParameters(t *testing.T) {
+	driver, client := setupTestDriver(t, map[string]interface{}{"account_id": "child-account"})
+
+	err := driver.StartSession(context.Background(), &core.Session{ID: "ses_1", DeviceID: "phone1", ExpectedDuration: 30})
+	assert.Error(t, err)
+	assert.Empty(t, client.overrides)
+}
+
+func TestDriver_ClientError(t *testing.T) {
+	driver, client := setupTestDriver(t, map[string]interface{}{
+		"account_id": "child-account",
+		"device_id":  "fl-device",
+	})
+	client.failErr = errors.New("cookies expired")
+
+	err := driver.StopSession(context.Background(), &core.Session{ID: "ses_1", DeviceID: "phone1"})
+	assert.ErrorIs(t, err, client.failErr)
+}
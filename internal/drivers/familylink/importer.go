@@ -0,0 +1,164 @@
+package familylink
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"metron/internal/core"
+	"metron/internal/devices"
+)
+
+// UsageImportStorage defines the interface for imported usage persistence
+// This interface is implemented by the storage layer to avoid tight coupling
+type UsageImportStorage interface {
+	GetFamilyLinkUsage(ctx context.Context, deviceID string, date time.Time) (int, error) // Minutes already charged, 0 if none
+	SaveFamilyLinkUsage(ctx context.Context, deviceID string, date time.Time, minutes int) error
+}
+
+// ImporterStorage is the storage needed by the usage importer
+type ImporterStorage interface {
+	UsageImportStorage
+	ListSessionsByChild(ctx context.Context, childID string) ([]*core.Session, error)
+	IncrementDailyUsageSummary(ctx context.Context, childID string, date time.Time, minutes int) error
+}
+
+// UsageImporter charges device usage recorded by Family Link outside Metron sessions
+// to the child's daily usage summary.
+// Usage during sessions on the device is already charged by the scheduler and is subtracted.
+// The charged minutes are stored per device and day, so repeated imports only add the difference.
+type UsageImporter struct {
+	client         Client
+	storage        ImporterStorage
+	deviceRegistry *devices.Registry
+	location       *time.Location
+	logger         *slog.Logger
+}
+
+// NewUsageImporter creates a usage importer; days follow the given timezone.
+func NewUsageImporter(client Client, storage ImporterStorage, deviceRegistry *devices.Registry, location *time.Location, logger *slog.Logger) *UsageImporter {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &UsageImporter{
+		client:         client,
+		storage:        storage,
+		deviceRegistry: deviceRegistry,
+		location:       location,
+		logger:         logger.With("driver", DriverName, "component", "usage-import"),
+	}
+}
+
+// Run imports usage every interval until ctx is cancelled.
+func (i *UsageImporter) Run(ctx context.Context, interval time.Duration) {
+	i.logger.Info("Family Link usage import started", "interval", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := i.Import(ctx); err != nil && ctx.Err() == nil {
+			i.logger.Error("Family Link usage import failed", "error", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Import charges new usage of every Family Link device with a child_id parameter for
+// today and yesterday, so usage recorded shortly before midnight is not lost.
+func (i *UsageImporter) Import(ctx context.Context) error {
+	now := core.Now().In(i.location)
+	year, month, day := now.Date()
+	today := time.Date(year, month, day, 0, 0, 0, 0, i.location)
+
+	var errs []error
+	for _, device := range i.deviceRegistry.ListByDriver(DriverName) {
+		cfg, err := readDeviceConfig(device)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if cfg.ChildID == "" {
+			continue
+		}
+
+		for _, date := range []time.Time{today.AddDate(0, 0, -1), today} {
+			if err := i.importDay(ctx, device.ID, cfg, date, now); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// importDay charges the usage of one device on one day that was not charged yet
+func (i *UsageImporter) importDay(ctx context.Context, deviceID string, cfg *deviceConfig, date, now time.Time) error {
+	usage, err := i.client.DeviceUsage(ctx, cfg.AccountID, cfg.DeviceID, date)
+	if err != nil {
+		return err
+	}
+
+	sessions, err := i.storage.ListSessionsByChild(ctx, cfg.ChildID)
+	if err != nil {
+		return err
+	}
+
+	outside := int(usage.Minutes()) - sessionMinutes(sessions, deviceID, date, now)
+	charged, err := i.storage.GetFamilyLinkUsage(ctx, deviceID, date)
+	if err != nil {
+		return err
+	}
+	if outside <= charged {
+		return nil
+	}
+
+	delta := outside - charged
+	if err := i.storage.IncrementDailyUsageSummary(ctx, cfg.ChildID, date, delta); err != nil {
+		return err
+	}
+	if err := i.storage.SaveFamilyLinkUsage(ctx, deviceID, date, outside); err != nil {
+		return err
+	}
+
+	i.logger.Info("Imported Family Link usage",
+		"device_id", deviceID,
+		"child_id", cfg.ChildID,
+		"date", date.Format("2006-01-02"),
+		"usage_minutes", int(usage.Minutes()),
+		"charged_minutes", delta)
+	return nil
+}
+
+// sessionMinutes returns the minutes of sessions on the device that started on the day
+// Ended sessions count their recorded actual duration; running sessions count up to now,
+// with breaks excluded like in the scheduler
+func sessionMinutes(sessions []*core.Session, deviceID string, date, now time.Time) int {
+	dayEnd := date.AddDate(0, 0, 1)
+
+	total := 0
+	for _, session := range sessions {
+		if session.DeviceID != deviceID || session.StartTime.Before(date) || !session.StartTime.Before(dayEnd) {
+			continue
+		}
+		if session.ActualDuration != nil && !session.IsRunning() {
+			total += *session.ActualDuration
+			continue
+		}
+
+		end := session.UpdatedAt
+		if session.Status == core.SessionStatusActive || session.Status == core.SessionStatusPaused {
+			end = now
+		}
+		minutes := int(end.Sub(session.StartTime).Minutes()) - session.BreakMinutes
+		if minutes > 0 {
+			total += minutes
+		}
+	}
+	return total
+}
@@ -0,0 +1,127 @@
+package familylink
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"metron/internal/core"
+	"metron/internal/devices"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockImporterStorage keeps sessions, charged minutes and daily usage in maps keyed by YYYY-MM-DD.
+type mockImporterStorage struct {
+	sessions []*core.Session
+	imported map[string]int // device ID + date
+	used     map[string]int // child ID + date
+}
+
+func (m *mockImporterStorage) GetFamilyLinkUsage(_ context.Context, deviceID string, date time.Time) (int, error) {
+	return m.imported[deviceID+date.Format("2006-01-02")], nil
+}
+
+func (m *mockImporterStorage) SaveFamilyLinkUsage(_ context.Context, deviceID string, date time.Time, minutes int) error {
+	m.imported[deviceID+date.Format("2006-01-02")] = minutes
+	return nil
+}
+
+func (m *mockImporterStorage) ListSessionsByChild(_ context.Context, childID string) ([]*core.Session, error) {
+	return m.sessions, nil
+}
+
+func (m *mockImporterStorage) IncrementDailyUsageSummary(_ context.Context, childID string, date time.Time, minutes int) error {
+	m.used[childID+date.Format("2006-01-02")] += minutes
+	return nil
+}
+
+func setupTestImporter(t *testing.T, now time.Time) (*UsageImporter, *mockClient, *mockImporterStorage) {
+	t.Helper()
+
+	original := core.Now
+	core.Now = func() time.Time { return now }
+	t.Cleanup(func() { core.Now = original })
+
+	registry := devices.NewRegistry()
+	require.NoError(t, registry.Register(&devices.Device{
+		ID:     "phone1",
+		Name:   "Android Phone",
+		Type:   "phone",
+		Driver: DriverName,
+		Parameters: map[string]interface{}{
+			"account_id": "child-account",
+			"device_id":  "fl-device",
+			"child_id":   "alice",
+		},
+	}))
+	// Devices without child_id are not imported
+	require.NoError(t, registry.Register(&devices.Device{
+		ID:         "tablet1",
+		Name:       "Tablet",
+		Type:       "tablet",
+		Driver:     DriverName,
+		Parameters: map[string]interface{}{"account_id": "child-account", "device_id": "fl-tablet"},
+	}))
+
+	store := &mockImporterStorage{imported: make(map[string]int), used: make(map[string]int)}
+
+	client := &mockClient{usage: make(map[string]time.Duration)}
+	return NewUsageImporter(client, store, registry, time.UTC, nil), client, store
+}
+
+func TestUsageImporter_ChargesUsageOutsideSessions(t *testing.T) {
+	now := time.Date(2026, 3, 2, 18, 0, 0, 0, time.UTC)
+	importer, client, store := setupTestImporter(t, now)
+	ctx := context.Background()
+
+	// A 30 minute session on the phone is already charged by the scheduler
+	start := now.Add(-2 * time.Hour)
+	store.sessions = []*core.Session{{
+		ID: "ses_1", DeviceID: "phone1", ChildIDs: []string{"alice"},
+		StartTime: start, Status: core.SessionStatusCompleted, UpdatedAt: start.Add(30 * time.Minute),
+	}}
+
+	client.usage["2026-03-02"] = 50 * time.Minute
+	require.NoError(t, importer.Import(ctx))
+	assert.Equal(t, 20, store.used["alice2026-03-02"])
+
+	// Importing again only charges new usage
+	require.NoError(t, importer.Import(ctx))
+	client.usage["2026-03-02"] = 55 * time.Minute
+	require.NoError(t, importer.Import(ctx))
+	assert.Equal(t, 25, store.used["alice2026-03-02"])
+	assert.Equal(t, 25, store.imported["phone12026-03-02"])
+}
+
+func TestUsageImporter_ImportsYesterday(t *testing.T) {
+	now := time.Date(2026, 3, 3, 0, 10, 0, 0, time.UTC)
+	importer, client, store := setupTestImporter(t, now)
+	ctx := context.Background()
+
+	client.usage["2026-03-02"] = 15 * time.Minute
+	require.NoError(t, importer.Import(ctx))
+
+	assert.Equal(t, map[string]int{"alice2026-03-02": 15}, store.used)
+}
+
+func TestSessionMinutes(t *testing.T) {
+	day := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	now := day.Add(20 * time.Hour)
+	actual := 20
+
+	sessions := []*core.Session{
+		// Completed, 40 minutes with a 10 minute break
+		{DeviceID: "phone1", StartTime: day.Add(9 * time.Hour), UpdatedAt: day.Add(9*time.Hour + 40*time.Minute), BreakMinutes: 10, Status: core.SessionStatusCompleted},
+		// Expired after 20 minutes of use, updated again later
+		{DeviceID: "phone1", StartTime: day.Add(12 * time.Hour), UpdatedAt: day.Add(14 * time.Hour), ActualDuration: &actual, Status: core.SessionStatusExpired},
+		// Running for 15 minutes
+		{DeviceID: "phone1", StartTime: now.Add(-15 * time.Minute), Status: core.SessionStatusActive},
+		// Other device and other day
+		{DeviceID: "tv1", StartTime: day.Add(10 * time.Hour), UpdatedAt: day.Add(11 * time.Hour), Status: core.SessionStatusCompleted},
+		{DeviceID: "phone1", StartTime: day.Add(-2 * time.Hour), UpdatedAt: day.Add(-time.Hour), Status: core.SessionStatusCompleted},
+	}
+
+	assert.Equal(t, 65, sessionMinutes(sessions, "phone1", day, now))
+}
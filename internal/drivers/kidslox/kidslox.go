@@ -35,6 +35,8 @@ type Config struct {
 	// Default device parameters (can be overridden by device-specific parameters)
 	DeviceID  string // Default Kidslox device ID
 	ProfileID string // Default Kidslox profile ID
+
+	Timeout time.Duration // HTTP client timeout (default 30s)
 }
 
 // Driver implements the DeviceDriver interface for Kidslox
@@ -50,11 +52,15 @@ func NewDriver(config Config, deviceRegistry *devices.Registry, logger *slog.Log
 	if logger == nil {
 		logger = slog.Default()
 	}
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
 	return &Driver{
 		config:         config,
 		deviceRegistry: deviceRegistry,
 		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout: timeout,
 		},
 		logger: logger,
 	}
@@ -71,9 +77,17 @@ func (d *Driver) Capabilities() devices.DriverCapabilities {
 		SupportsWarnings:   false, // Kidslox doesn't support warnings
 		SupportsLiveState:  false, // Not implemented in this version
 		SupportsScheduling: true,  // Can schedule sessions
+		SupportsExtension:  true,  // Adds the extension to the profile time
+		SupportsBreaks:     true,  // Locks the profile during breaks
 	}
 }
 
+// Validate checks that the device has a Kidslox device and profile ID at startup
+func (d *Driver) Validate(device *devices.Device) error {
+	_, _, err := d.readDeviceConfig(device)
+	return err
+}
+
 // getDeviceConfig looks up device and merges driver config + device parameters
 // Device parameters override driver defaults
 func (d *Driver) getDeviceConfig(session *core.Session) (deviceID, profileID string, err error) {
@@ -82,7 +96,11 @@ func (d *Driver) getDeviceConfig(session *core.Session) (deviceID, profileID str
 	if err != nil {
 		return "", "", fmt.Errorf("failed to get device %s: %w", session.DeviceID, err)
 	}
+	return d.readDeviceConfig(device)
+}
 
+// readDeviceConfig merges driver config + device parameters
+func (d *Driver) readDeviceConfig(device *devices.Device) (deviceID, profileID string, err error) {
 	// Start with driver defaults
 	deviceID = d.config.DeviceID
 	profileID = d.config.ProfileID
@@ -97,10 +115,10 @@ func (d *Driver) getDeviceConfig(session *core.Session) (deviceID, profileID str
 
 	// Validate required parameters
 	if deviceID == "" {
-		return "", "", fmt.Errorf("device_id is required (set in driver config or device parameters)")
+		return "", "", fmt.Errorf("device %s: device_id is required (set in driver config or device parameters)", device.ID)
 	}
 	if profileID == "" {
-		return "", "", fmt.Errorf("profile_id is required (set in driver config or device parameters)")
+		return "", "", fmt.Errorf("device %s: profile_id is required (set in driver config or device parameters)", device.ID)
 	}
 
 	return deviceID, profileID, nil
@@ -204,6 +222,54 @@ func (d *Driver) ApplyWarning(ctx context.Context, session *core.Session, minute
 	return nil
 }
 
+// StartBreak locks the device for a mandatory break
+// Unlike StopSession the time restriction is kept, so EndBreak only needs to unlock
+func (d *Driver) StartBreak(ctx context.Context, session *core.Session, breakMinutes int) error {
+	d.logger.Info("Starting Kidslox break",
+		"session_id", session.ID,
+		"break_minutes", breakMinutes)
+
+	deviceID, _, err := d.getDeviceConfig(session)
+	if err != nil {
+		d.logger.Error("Failed to get Kidslox device config",
+			"session_id", session.ID,
+			"error", err)
+		return err
+	}
+
+	if err := d.lockDevice(ctx, deviceID); err != nil {
+		d.logger.Error("Failed to lock Kidslox device for break",
+			"session_id", session.ID,
+			"error", err)
+		return fmt.Errorf("failed to lock device: %w", err)
+	}
+
+	return nil
+}
+
+// EndBreak unlocks the device when the break is over
+func (d *Driver) EndBreak(ctx context.Context, session *core.Session) error {
+	d.logger.Info("Ending Kidslox break",
+		"session_id", session.ID)
+
+	deviceID, profileID, err := d.getDeviceConfig(session)
+	if err != nil {
+		d.logger.Error("Failed to get Kidslox device config",
+			"session_id", session.ID,
+			"error", err)
+		return err
+	}
+
+	if err := d.unlockDevice(ctx, deviceID, profileID); err != nil {
+		d.logger.Error("Failed to unlock Kidslox device after break",
+			"session_id", session.ID,
+			"error", err)
+		return fmt.Errorf("failed to unlock device: %w", err)
+	}
+
+	return nil
+}
+
 // ExtendSession extends an active session by adding more time
 func (d *Driver) ExtendSession(ctx context.Context, session *core.Session, additionalMinutes int) error {
 	d.logger.Info("Extending Kidslox session",
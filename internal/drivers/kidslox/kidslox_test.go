@@ -43,6 +43,19 @@ func TestDriver_Capabilities(t *testing.T) {
 	assert.False(t, caps.SupportsWarnings, "Kidslox doesn't support warnings")
 	assert.False(t, caps.SupportsLiveState, "Kidslox doesn't support live state")
 	assert.True(t, caps.SupportsScheduling, "Kidslox supports scheduling")
+	assert.True(t, caps.SupportsExtension, "Kidslox adds extensions to the profile time")
+	assert.True(t, caps.SupportsBreaks, "Kidslox locks the profile during breaks")
+}
+
+func TestDriver_Validate(t *testing.T) {
+	driver := NewDriver(Config{ProfileID: "default-profile"}, devices.NewRegistry(), nil)
+
+	// The driver config provides the profile ID
+	assert.NoError(t, driver.Validate(&devices.Device{ID: "ipad1", Parameters: map[string]interface{}{"device_id": "test-device"}}))
+
+	err := driver.Validate(&devices.Device{ID: "ipad2"})
+	require.Error(t, err)
+	assert.Equal(t, "device ipad2: device_id is required (set in driver config or device parameters)", err.Error())
 }
 
 func TestDriver_StartSession(t *testing.T) {
@@ -202,6 +215,43 @@ func TestDriver_StopSession(t *testing.T) {
 	assert.Equal(t, LockProfileID, action["profile"]) // Should use lock profile
 }
 
+func TestDriver_Break(t *testing.T) {
+	// Track assigned profiles
+	var profiles []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bodyBytes, _ := io.ReadAll(r.Body)
+		var body map[string]interface{}
+		json.Unmarshal(bodyBytes, &body)
+
+		action := body["action"].(map[string]interface{})
+		profiles = append(profiles, action["profile"].(string))
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"message": "Action sent"})
+	}))
+	defer server.Close()
+
+	registry := createTestRegistry("ipad1", map[string]interface{}{
+		"device_id":  "test-device-456",
+		"profile_id": "test-profile-123",
+	})
+	driver := NewDriver(Config{
+		BaseURL:   server.URL,
+		APIKey:    "test-api-key",
+		AccountID: "test-account-123",
+	}, registry, nil)
+
+	session := &core.Session{
+		ID:       "session1",
+		DeviceID: "ipad1",
+	}
+
+	// Break locks the device, and only unlocks it afterwards (no time restriction change)
+	require.NoError(t, driver.StartBreak(context.Background(), session, 10))
+	require.NoError(t, driver.EndBreak(context.Background(), session))
+	assert.Equal(t, []string{LockProfileID, "test-profile-123"}, profiles)
+}
+
 func TestDriver_ExtendSession(t *testing.T) {
 	// Track API call
 	var extendCalled bool
@@ -320,4 +370,7 @@ func TestDriver_InterfaceImplementation(t *testing.T) {
 
 	// Verify implements ExtendableDriver
 	var _ devices.ExtendableDriver = driver
+
+	// Verify implements BreakableDriver
+	var _ devices.BreakableDriver = driver
 }
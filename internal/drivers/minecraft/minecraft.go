@@ -0,0 +1,329 @@
+// Package minecraft provides a device driver for Minecraft servers (and other game servers
+// with an RCON console): children's players are added to the server whitelist when a
+// session starts, removed and kicked when it ends, and warned in game before time runs out.
+package minecraft
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"metron/internal/core"
+	"metron/internal/devices"
+	"metron/internal/rcon"
+)
+
+const DriverName = "minecraft"
+
+// Default Minecraft Java commands; {player} and {minutes} are replaced
+const (
+	DefaultAllowCommand   = "whitelist add {player}"
+	DefaultDenyCommand    = "whitelist remove {player}"
+	DefaultKickCommand    = "kick {player} Time is up"
+	DefaultWarningCommand = "say {player}: {minutes} min of play time remaining"
+)
+
+// playerPattern matches player names; anything else is rejected because names are
+// inserted into console commands
+var playerPattern = regexp.MustCompile(`^[A-Za-z0-9_.\-]{1,32}$`)
+
+// Console runs commands on a server console.
+type Console interface {
+	Run(ctx context.Context, commands ...string) ([]string, error)
+}
+
+// deviceConfig holds the server settings of a device from its parameters
+type deviceConfig struct {
+	Addr           string            // host:port
+	Password       string            // RCON password
+	Players        map[string]string // Player name by child ID
+	AllowCommand   string
+	DenyCommand    string
+	KickCommand    string // Empty = players are not kicked
+	WarningCommand string // Empty = no warnings
+}
+
+// Driver implements the DeviceDriver interface by running RCON console commands.
+type Driver struct {
+	deviceRegistry *devices.Registry
+	logger         *slog.Logger
+	newConsole     func(addr, password string) Console
+}
+
+// NewDriver creates a new Minecraft driver.
+func NewDriver(deviceRegistry *devices.Registry, logger *slog.Logger) *Driver {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Driver{
+		deviceRegistry: deviceRegistry,
+		logger:         logger.With("driver", DriverName),
+		newConsole: func(addr, password string) Console {
+			return rcon.NewClient(addr, password)
+		},
+	}
+}
+
+// Name returns the driver name.
+func (d *Driver) Name() string {
+	return DriverName
+}
+
+// Capabilities returns the driver capabilities.
+func (d *Driver) Capabilities() devices.DriverCapabilities {
+	return devices.DriverCapabilities{
+		SupportsWarnings:   true,
+		SupportsLiveState:  false,
+		SupportsScheduling: true,
+		SupportsBreaks:     true,
+	}
+}
+
+// Validate checks the device parameters at startup.
+func (d *Driver) Validate(device *devices.Device) error {
+	_, err := readDeviceConfig(device)
+	return err
+}
+
+// StartSession adds the session's players to the whitelist.
+func (d *Driver) StartSession(ctx context.Context, session *core.Session) error {
+	d.logger.Info("Starting Minecraft session",
+		"session_id", session.ID,
+		"device_id", session.DeviceID,
+		"child_ids", session.ChildIDs,
+		"duration_minutes", session.ExpectedDuration)
+
+	if err := d.run(ctx, session, allowCommands); err != nil {
+		d.logger.Error("Failed to whitelist players",
+			"session_id", session.ID,
+			"device_id", session.DeviceID,
+			"error", err)
+		return fmt.Errorf("failed to whitelist players: %w", err)
+	}
+	return nil
+}
+
+// StopSession removes the session's players from the whitelist and kicks them.
+func (d *Driver) StopSession(ctx context.Context, session *core.Session) error {
+	d.logger.Info("Stopping Minecraft session",
+		"session_id", session.ID,
+		"device_id", session.DeviceID)
+
+	if err := d.run(ctx, session, denyCommands); err != nil {
+		d.logger.Error("Failed to remove players",
+			"session_id", session.ID,
+			"device_id", session.DeviceID,
+			"error", err)
+		return fmt.Errorf("failed to remove players: %w", err)
+	}
+	return nil
+}
+
+// ApplyWarning sends the warning command for each of the session's players.
+func (d *Driver) ApplyWarning(ctx context.Context, session *core.Session, minutesRemaining int) error {
+	warning := func(cfg *deviceConfig, player string) []string {
+		if cfg.WarningCommand == "" {
+			return nil
+		}
+		return []string{expand(cfg.WarningCommand, player, minutesRemaining)}
+	}
+
+	if err := d.run(ctx, session, warning); err != nil {
+		d.logger.Error("Failed to send Minecraft warning",
+			"session_id", session.ID,
+			"device_id", session.DeviceID,
+			"error", err)
+		return fmt.Errorf("failed to send warning: %w", err)
+	}
+
+	d.logger.Info("Minecraft warning sent",
+		"session_id", session.ID,
+		"minutes_remaining", minutesRemaining)
+	return nil
+}
+
+// StartBreak removes and kicks the players for a mandatory break.
+func (d *Driver) StartBreak(ctx context.Context, session *core.Session, breakMinutes int) error {
+	d.logger.Info("Starting Minecraft break",
+		"session_id", session.ID,
+		"device_id", session.DeviceID,
+		"break_minutes", breakMinutes)
+
+	if err := d.run(ctx, session, denyCommands); err != nil {
+		d.logger.Error("Failed to remove players for break",
+			"session_id", session.ID,
+			"error", err)
+		return fmt.Errorf("failed to remove players: %w", err)
+	}
+	return nil
+}
+
+// EndBreak adds the players to the whitelist again.
+func (d *Driver) EndBreak(ctx context.Context, session *core.Session) error {
+	d.logger.Info("Ending Minecraft break",
+		"session_id", session.ID,
+		"device_id", session.DeviceID)
+
+	if err := d.run(ctx, session, allowCommands); err != nil {
+		d.logger.Error("Failed to whitelist players after break",
+			"session_id", session.ID,
+			"error", err)
+		return fmt.Errorf("failed to whitelist players: %w", err)
+	}
+	return nil
+}
+
+// GetLiveState is not supported.
+func (d *Driver) GetLiveState(ctx context.Context, deviceID string) (*devices.DeviceState, error) {
+	return nil, nil
+}
+
+// allowCommands whitelists a player
+func allowCommands(cfg *deviceConfig, player string) []string {
+	return []string{expand(cfg.AllowCommand, player, 0)}
+}
+
+// denyCommands removes a player from the whitelist, then kicks them, since removing
+// does not disconnect players who are online
+func denyCommands(cfg *deviceConfig, player string) []string {
+	commands := []string{expand(cfg.DenyCommand, player, 0)}
+	if cfg.KickCommand != "" {
+		commands = append(commands, expand(cfg.KickCommand, player, 0))
+	}
+	return commands
+}
+
+// run executes the commands for each player of the session's children in one connection.
+// Children without a player on the server are skipped.
+func (d *Driver) run(ctx context.Context, session *core.Session, commands func(cfg *deviceConfig, player string) []string) error {
+	cfg, err := d.getDeviceConfig(session.DeviceID)
+	if err != nil {
+		return err
+	}
+
+	var list []string
+	for _, childID := range session.ChildIDs {
+		player, ok := cfg.Players[childID]
+		if !ok {
+			d.logger.Warn("Child has no player on the server",
+				"session_id", session.ID,
+				"device_id", session.DeviceID,
+				"child_id", childID)
+			continue
+		}
+		list = append(list, commands(cfg, player)...)
+	}
+	if len(list) == 0 {
+		return nil
+	}
+
+	responses, err := d.newConsole(cfg.Addr, cfg.Password).Run(ctx, list...)
+	if err != nil {
+		return err
+	}
+
+	d.logger.Debug("RCON commands run",
+		"device_id", session.DeviceID,
+		"commands", list,
+		"responses", responses)
+	return nil
+}
+
+// expand replaces {player} and {minutes} in a command template
+func expand(template, player string, minutes int) string {
+	return strings.NewReplacer("{player}", player, "{minutes}", strconv.Itoa(minutes)).Replace(template)
+}
+
+// getDeviceConfig looks up the device and reads its parameters
+func (d *Driver) getDeviceConfig(deviceID string) (*deviceConfig, error) {
+	device, err := d.deviceRegistry.Get(deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device %s: %w", deviceID, err)
+	}
+	return readDeviceConfig(device)
+}
+
+// readDeviceConfig reads the host, port, password, players and command device parameters
+func readDeviceConfig(device *devices.Device) (*deviceConfig, error) {
+	host, _ := device.GetParameter("host").(string)
+	if host == "" {
+		return nil, fmt.Errorf("device %s: host parameter is required", device.ID)
+	}
+	port := rcon.DefaultPort
+	switch p := device.GetParameter("port").(type) {
+	case float64:
+		port = int(p)
+	case int:
+		port = p
+	}
+
+	password, _ := device.GetParameter("password").(string)
+	if password == "" {
+		return nil, fmt.Errorf("device %s: password parameter is required", device.ID)
+	}
+
+	cfg := &deviceConfig{
+		Addr:           net.JoinHostPort(host, strconv.Itoa(port)),
+		Password:       password,
+		Players:        make(map[string]string),
+		AllowCommand:   DefaultAllowCommand,
+		DenyCommand:    DefaultDenyCommand,
+		KickCommand:    DefaultKickCommand,
+		WarningCommand: DefaultWarningCommand,
+	}
+
+	switch players := device.GetParameter("players").(type) {
+	case map[string]string:
+		for childID, player := range players {
+			cfg.Players[childID] = player
+		}
+	case map[string]interface{}:
+		for childID, player := range players {
+			name, ok := player.(string)
+			if !ok {
+				return nil, fmt.Errorf("device %s: players must map child IDs to player names", device.ID)
+			}
+			cfg.Players[childID] = name
+		}
+	}
+	if len(cfg.Players) == 0 {
+		return nil, fmt.Errorf("device %s: players parameter is required", device.ID)
+	}
+	for _, childID := range sortedKeys(cfg.Players) {
+		if !playerPattern.MatchString(cfg.Players[childID]) {
+			return nil, fmt.Errorf("device %s: invalid player name %q for child %s", device.ID, cfg.Players[childID], childID)
+		}
+	}
+
+	// Set commands override the defaults; an empty kick or warning command disables it
+	commands := map[string]*string{
+		"allow_command":   &cfg.AllowCommand,
+		"deny_command":    &cfg.DenyCommand,
+		"kick_command":    &cfg.KickCommand,
+		"warning_command": &cfg.WarningCommand,
+	}
+	for name, field := range commands {
+		if command, ok := device.GetParameter(name).(string); ok {
+			*field = command
+		}
+	}
+	if cfg.AllowCommand == "" || cfg.DenyCommand == "" {
+		return nil, fmt.Errorf("device %s: allow_command and deny_command must not be empty", device.ID)
+	}
+	return cfg, nil
+}
+
+// sortedKeys returns the map keys in order, so errors are deterministic
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,139 @@
+package minecraft
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"metron/internal/core"
+	"metron/internal/devices"
+	"metron/internal/rcon"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockConsole records the commands run on each address.
+type mockConsole struct {
+	addrs    []string
+	commands [][]string
+	failErr  error
+}
+
+func (m *mockConsole) Run(_ context.Context, commands ...string) ([]string, error) {
+	if m.failErr != nil {
+		return nil, m.failErr
+	}
+	m.commands = append(m.commands, commands)
+	return make([]string, len(commands)), nil
+}
+
+func setupTestDriver(t *testing.T, params map[string]interface{}) (*Driver, *mockConsole) {
+	t.Helper()
+
+	registry := devices.NewRegistry()
+	require.NoError(t, registry.Register(&devices.Device{
+		ID:         "minecraft",
+		Name:       "Minecraft Server",
+		Type:       "game",
+		Driver:     DriverName,
+		Parameters: params,
+	}))
+
+	driver := NewDriver(registry, nil)
+	console := &mockConsole{}
+	driver.newConsole = func(addr, password string) Console {
+		console.addrs = append(console.addrs, addr)
+		return console
+	}
+	return driver, console
+}
+
+func TestDriver_SessionLifecycle(t *testing.T) {
+	driver, console := setupTestDriver(t, map[string]interface{}{
+		"host":     "192.168.1.70",
+		"password": "secret",
+		"players":  map[string]interface{}{"alice": "AliceCraft", "bob": "Bob_2015"},
+	})
+	session := &core.Session{ID: "ses_1", DeviceID: "minecraft", ChildIDs: []string{"alice", "bob"}}
+	ctx := context.Background()
+
+	require.NoError(t, driver.StartSession(ctx, session))
+	require.NoError(t, driver.ApplyWarning(ctx, session, 5))
+	require.NoError(t, driver.StopSession(ctx, session))
+
+	assert.Equal(t, [][]string{
+		{"whitelist add AliceCraft", "whitelist add Bob_2015"},
+		{"say AliceCraft: 5 min of play time remaining", "say Bob_2015: 5 min of play time remaining"},
+		{"whitelist remove AliceCraft", "kick AliceCraft Time is up", "whitelist remove Bob_2015", "kick Bob_2015 Time is up"},
+	}, console.commands)
+	assert.Equal(t, "192.168.1.70:25575", console.addrs[0])
+}
+
+func TestDriver_CustomCommands(t *testing.T) {
+	driver, console := setupTestDriver(t, map[string]interface{}{
+		"host":            "192.168.1.70",
+		"port":            float64(27015),
+		"password":        "secret",
+		"players":         map[string]interface{}{"alice": "alice"},
+		"allow_command":   "wl_add {player}",
+		"deny_command":    "wl_remove {player}",
+		"kick_command":    "",
+		"warning_command": "",
+	})
+	session := &core.Session{ID: "ses_1", DeviceID: "minecraft", ChildIDs: []string{"alice"}}
+	ctx := context.Background()
+
+	require.NoError(t, driver.StartBreak(ctx, session, 10))
+	require.NoError(t, driver.EndBreak(ctx, session))
+	// Disabled warnings do not connect
+	require.NoError(t, driver.ApplyWarning(ctx, session, 5))
+
+	assert.Equal(t, [][]string{{"wl_remove alice"}, {"wl_add alice"}}, console.commands)
+	assert.Equal(t, []string{"192.168.1.70:27015", "192.168.1.70:27015"}, console.addrs)
+}
+
+func TestDriver_ChildWithoutPlayer(t *testing.T) {
+	driver, console := setupTestDriver(t, map[string]interface{}{
+		"host":     "192.168.1.70",
+		"password": "secret",
+		"players":  map[string]interface{}{"alice": "AliceCraft"},
+	})
+
+	require.NoError(t, driver.StartSession(context.Background(), &core.Session{ID: "ses_1", DeviceID: "minecraft", ChildIDs: []string{"bob"}}))
+	assert.Empty(t, console.commands)
+}
+
+func TestDriver_InvalidParameters(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+	}{
+		{"missing host", map[string]interface{}{"password": "secret", "players": map[string]interface{}{"alice": "Alice"}}},
+		{"missing password", map[string]interface{}{"host": "192.168.1.70", "players": map[string]interface{}{"alice": "Alice"}}},
+		{"missing players", map[string]interface{}{"host": "192.168.1.70", "password": "secret"}},
+		{"player with command characters", map[string]interface{}{"host": "192.168.1.70", "password": "secret", "players": map[string]interface{}{"alice": "Alice; op Alice"}}},
+		{"empty allow command", map[string]interface{}{"host": "192.168.1.70", "password": "secret", "players": map[string]interface{}{"alice": "Alice"}, "allow_command": ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver, console := setupTestDriver(t, tt.params)
+			err := driver.StartSession(context.Background(), &core.Session{ID: "ses_1", DeviceID: "minecraft", ChildIDs: []string{"alice"}})
+			assert.Error(t, err)
+			assert.Empty(t, console.commands)
+		})
+	}
+}
+
+func TestDriver_ConsoleError(t *testing.T) {
+	driver, console := setupTestDriver(t, map[string]interface{}{
+		"host":     "192.168.1.70",
+		"password": "wrong",
+		"players":  map[string]interface{}{"alice": "AliceCraft"},
+	})
+	console.failErr = rcon.ErrAuthFailed
+
+	err := driver.StopSession(context.Background(), &core.Session{ID: "ses_1", DeviceID: "minecraft", ChildIDs: []string{"alice"}})
+	assert.True(t, errors.Is(err, rcon.ErrAuthFailed))
+}
@@ -87,10 +87,9 @@ func (d *Driver) StartSession(ctx context.Context, session *core.Session) error
 
 	endTime := session.StartTime.Add(time.Duration(session.ExpectedDuration) * time.Minute)
 
-	isParentOverride := ctx.Value("parent_override") != nil
-
+	// Sessions with a parent override were started by a parent, not requested by the child
 	var text string
-	if isParentOverride {
+	if session.OverrideDowntime || session.OverrideLimits {
 		text = fmt.Sprintf(
 			"%s *Session Started*\n\n%s %s \u2014 %d min on %s\n\U0001f3c1 Ends at: %s\n\nDon't forget to grant time in %s.",
 			deviceEmoji,
@@ -140,7 +139,11 @@ func (d *Driver) StopSession(ctx context.Context, session *core.Session) error {
 		deviceEmoji = "\U0001f4f1"
 	}
 
+	// The session manager and scheduler set the actual duration before stopping the device
 	usedMinutes := int(time.Since(session.StartTime).Minutes())
+	if session.ActualDuration != nil {
+		usedMinutes = *session.ActualDuration
+	}
 
 	text := fmt.Sprintf(
 		"%s *Session Ended*\n\n%s %s \u2014 %s (%d min used)\n\nRevoke bonus time in %s.",
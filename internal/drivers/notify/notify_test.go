@@ -133,9 +133,9 @@ func TestStartSession_ParentOverride(t *testing.T) {
 	driver, sender, _ := setupTestDriver(t)
 
 	session := testSession()
-	ctx := context.WithValue(context.Background(), "parent_override", true)
+	session.OverrideDowntime = true
 
-	err := driver.StartSession(ctx, session)
+	err := driver.StartSession(context.Background(), session)
 	assert.NoError(t, err)
 
 	assert.Len(t, sender.messages, 2)
@@ -0,0 +1,89 @@
+package plugin
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// defaultTimeout is how long a plugin may take to answer a call
+const defaultTimeout = 30 * time.Second
+
+// namePattern restricts plugin names to what device configs can reference safely
+var namePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,31}$`)
+
+// Manifest describes a driver plugin; one JSON file per plugin in the drivers directory.
+type Manifest struct {
+	Name           string            `json:"name"`                      // Driver name used by devices ("driver": "<name>")
+	Command        string            `json:"command"`                   // Executable; paths with a slash are relative to the drivers directory
+	Args           []string          `json:"args,omitempty"`            // Command arguments
+	Env            map[string]string `json:"env,omitempty"`             // Added to Metron's environment (e.g., credentials)
+	TimeoutSeconds int               `json:"timeout_seconds,omitempty"` // Per-call timeout (default 30)
+}
+
+// Timeout returns the per-call timeout, with default fallback
+func (m Manifest) Timeout() time.Duration {
+	if m.TimeoutSeconds <= 0 {
+		return defaultTimeout
+	}
+	return time.Duration(m.TimeoutSeconds) * time.Second
+}
+
+// LoadManifests reads the *.json manifests of a drivers directory in name order.
+// A missing directory is an error, an empty one is not.
+func LoadManifests(dir string) ([]Manifest, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read drivers directory: %w", err)
+	}
+
+	var manifests []Manifest
+	seen := make(map[string]string)
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".json" {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name())
+		manifest, err := loadManifest(path)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := seen[manifest.Name]; ok {
+			return nil, fmt.Errorf("%s: driver %q is already defined in %s", path, manifest.Name, other)
+		}
+		seen[manifest.Name] = path
+		manifests = append(manifests, *manifest)
+	}
+	return manifests, nil
+}
+
+func loadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("%s: invalid JSON: %w", path, err)
+	}
+
+	if !namePattern.MatchString(manifest.Name) {
+		return nil, fmt.Errorf("%s: name %q must be lowercase letters, digits, '-' or '_' (up to 32)", path, manifest.Name)
+	}
+	if manifest.Command == "" {
+		return nil, fmt.Errorf("%s: command is required", path)
+	}
+	if manifest.TimeoutSeconds < 0 {
+		return nil, fmt.Errorf("%s: timeout_seconds must not be negative", path)
+	}
+
+	// Bare names are looked up in PATH, other relative paths start at the drivers directory
+	if strings.ContainsRune(manifest.Command, '/') && !filepath.IsAbs(manifest.Command) {
+		manifest.Command = filepath.Join(filepath.Dir(path), manifest.Command)
+	}
+	return &manifest, nil
+}
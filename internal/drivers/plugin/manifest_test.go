@@ -0,0 +1,75 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeManifest(t *testing.T, dir, file, content string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644))
+}
+
+func TestLoadManifests(t *testing.T) {
+	dir := t.TempDir()
+	writeManifest(t, dir, "b-lamp.json", `{"name": "lamp", "command": "./bin/lamp-driver", "args": ["-v"], "env": {"LAMP_TOKEN": "secret"}}`)
+	writeManifest(t, dir, "a-blinds.json", `{"name": "blinds", "command": "blinds-driver", "timeout_seconds": 5}`)
+	writeManifest(t, dir, "README.md", "not a manifest")
+
+	manifests, err := LoadManifests(dir)
+	require.NoError(t, err)
+	require.Len(t, manifests, 2)
+
+	// In file name order; bare commands stay for PATH lookup
+	assert.Equal(t, "blinds", manifests[0].Name)
+	assert.Equal(t, "blinds-driver", manifests[0].Command)
+	assert.Equal(t, 5*time.Second, manifests[0].Timeout())
+
+	assert.Equal(t, "lamp", manifests[1].Name)
+	assert.Equal(t, filepath.Join(dir, "bin/lamp-driver"), manifests[1].Command)
+	assert.Equal(t, []string{"-v"}, manifests[1].Args)
+	assert.Equal(t, map[string]string{"LAMP_TOKEN": "secret"}, manifests[1].Env)
+	assert.Equal(t, defaultTimeout, manifests[1].Timeout())
+}
+
+func TestLoadManifests_Invalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+	}{
+		{"invalid json", `{"name": "lamp"`},
+		{"missing command", `{"name": "lamp"}`},
+		{"uppercase name", `{"name": "Lamp", "command": "lamp-driver"}`},
+		{"negative timeout", `{"name": "lamp", "command": "lamp-driver", "timeout_seconds": -1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			writeManifest(t, dir, "lamp.json", tt.manifest)
+
+			_, err := LoadManifests(dir)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadManifests_DuplicateName(t *testing.T) {
+	dir := t.TempDir()
+	writeManifest(t, dir, "lamp.json", `{"name": "lamp", "command": "lamp-driver"}`)
+	writeManifest(t, dir, "lamp2.json", `{"name": "lamp", "command": "other-driver"}`)
+
+	_, err := LoadManifests(dir)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "already defined")
+}
+
+func TestLoadManifests_MissingDirectory(t *testing.T) {
+	_, err := LoadManifests(filepath.Join(t.TempDir(), "drivers.d"))
+	assert.Error(t, err)
+}
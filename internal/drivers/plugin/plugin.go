@@ -0,0 +1,395 @@
+// Package plugin runs driver plugins: device drivers shipped outside the Metron tree as
+// executables built with the driversdk package. Each plugin is described by a manifest in
+// the drivers directory (drivers.d), started as a subprocess and called with JSON-RPC over
+// its stdin and stdout. A plugin that exits is started again on the next call.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"os/exec"
+	"sort"
+	"sync"
+	"time"
+
+	"metron/driversdk"
+	"metron/internal/core"
+	"metron/internal/devices"
+)
+
+// stopTimeout is how long a plugin may take to exit after its stdin is closed
+const stopTimeout = 5 * time.Second
+
+// Driver implements the DeviceDriver interface by calling a plugin process.
+type Driver struct {
+	manifest       Manifest
+	deviceRegistry *devices.Registry
+	logger         *slog.Logger
+	start          func() (io.ReadWriteCloser, error) // Starts the plugin (replaced in tests)
+
+	mu           sync.Mutex
+	client       *rpc.Client
+	capabilities driversdk.Capabilities
+}
+
+// NewDriver creates a driver for a plugin; the plugin is started by Start or the first call.
+func NewDriver(manifest Manifest, deviceRegistry *devices.Registry, logger *slog.Logger) *Driver {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	d := &Driver{
+		manifest:       manifest,
+		deviceRegistry: deviceRegistry,
+		logger:         logger.With("driver", manifest.Name),
+	}
+	d.start = d.startProcess
+	return d
+}
+
+// Name returns the driver name from the manifest.
+func (d *Driver) Name() string {
+	return d.manifest.Name
+}
+
+// Capabilities returns the capabilities the plugin reported, so core only makes
+// the optional calls the plugin supports.
+func (d *Driver) Capabilities() devices.DriverCapabilities {
+	caps := d.pluginCapabilities()
+	return devices.DriverCapabilities{
+		SupportsWarnings:   caps.Warnings,
+		SupportsLiveState:  caps.LiveState,
+		SupportsScheduling: true,
+		SupportsExtension:  caps.Extend,
+		SupportsBreaks:     caps.Breaks,
+	}
+}
+
+// Start starts the plugin and checks its protocol version, so broken plugins fail at startup.
+func (d *Driver) Start(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, d.manifest.Timeout())
+	defer cancel()
+
+	if _, err := d.connect(ctx); err != nil {
+		return err
+	}
+	caps := d.pluginCapabilities()
+	d.logger.Info("Driver plugin started",
+		"command", d.manifest.Command,
+		"warnings", caps.Warnings,
+		"extend", caps.Extend,
+		"breaks", caps.Breaks,
+		"health_check", caps.HealthCheck,
+		"live_state", caps.LiveState)
+	return nil
+}
+
+// Close stops the plugin process.
+func (d *Driver) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.client == nil {
+		return nil
+	}
+	err := d.client.Close()
+	d.client = nil
+	return err
+}
+
+// StartSession calls the plugin's StartSession.
+func (d *Driver) StartSession(ctx context.Context, session *core.Session) error {
+	return d.callSession(ctx, "StartSession", session, 0)
+}
+
+// StopSession calls the plugin's StopSession.
+func (d *Driver) StopSession(ctx context.Context, session *core.Session) error {
+	return d.callSession(ctx, "StopSession", session, 0)
+}
+
+// ApplyWarning calls the plugin's ApplyWarning; plugins without warnings ignore it.
+func (d *Driver) ApplyWarning(ctx context.Context, session *core.Session, minutesRemaining int) error {
+	if !d.pluginCapabilities().Warnings {
+		return nil
+	}
+	return d.callSession(ctx, "ApplyWarning", session, minutesRemaining)
+}
+
+// ExtendSession calls the plugin's ExtendSession; plugins without it need nothing done.
+func (d *Driver) ExtendSession(ctx context.Context, session *core.Session, additionalMinutes int) error {
+	if !d.pluginCapabilities().Extend {
+		return nil
+	}
+	return d.callSession(ctx, "ExtendSession", session, additionalMinutes)
+}
+
+// StartBreak calls the plugin's StartBreak; plugins without break actions ignore it.
+func (d *Driver) StartBreak(ctx context.Context, session *core.Session, breakMinutes int) error {
+	if !d.pluginCapabilities().Breaks {
+		return nil
+	}
+	return d.callSession(ctx, "StartBreak", session, breakMinutes)
+}
+
+// EndBreak calls the plugin's EndBreak.
+func (d *Driver) EndBreak(ctx context.Context, session *core.Session) error {
+	if !d.pluginCapabilities().Breaks {
+		return nil
+	}
+	return d.callSession(ctx, "EndBreak", session, 0)
+}
+
+// HealthCheck starts the plugin if it is not running and calls its HealthCheck.
+func (d *Driver) HealthCheck(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, d.manifest.Timeout())
+	defer cancel()
+
+	client, err := d.connect(ctx)
+	if err != nil {
+		return err
+	}
+	if !d.pluginCapabilities().HealthCheck {
+		return nil
+	}
+	return d.call(ctx, client, "HealthCheck", &driversdk.CallArgs{}, &driversdk.Empty{})
+}
+
+// GetLiveState calls the plugin's GetLiveState; nil if the plugin does not support it.
+func (d *Driver) GetLiveState(ctx context.Context, deviceID string) (*devices.DeviceState, error) {
+	if !d.pluginCapabilities().LiveState {
+		return nil, nil
+	}
+	device, err := d.deviceRegistry.Get(deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	var reply driversdk.LiveStateReply
+	if err := d.invoke(ctx, "GetLiveState", &driversdk.CallArgs{Device: sdkDevice(device)}, &reply); err != nil {
+		return nil, err
+	}
+	if reply.State == nil {
+		return nil, nil
+	}
+	return &devices.DeviceState{
+		DeviceID: deviceID,
+		IsActive: reply.State.IsActive,
+		Metadata: reply.State.Metadata,
+	}, nil
+}
+
+// Validate calls the plugin's Validate with the device; plugins without it accept every device.
+func (d *Driver) Validate(device *devices.Device) error {
+	if !d.pluginCapabilities().Validate {
+		return nil
+	}
+	return d.invoke(context.Background(), "Validate", &driversdk.CallArgs{Device: sdkDevice(device)}, &driversdk.Empty{})
+}
+
+func (d *Driver) pluginCapabilities() driversdk.Capabilities {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.capabilities
+}
+
+// callSession calls a session method with the session's device
+func (d *Driver) callSession(ctx context.Context, method string, session *core.Session, minutes int) error {
+	device, err := d.deviceRegistry.Get(session.DeviceID)
+	if err != nil {
+		return err
+	}
+	args := &driversdk.CallArgs{
+		Device:  sdkDevice(device),
+		Session: sdkSession(session),
+		Minutes: minutes,
+	}
+	return d.invoke(ctx, method, args, &driversdk.Empty{})
+}
+
+// invoke starts the plugin if needed and calls a method with the manifest timeout
+func (d *Driver) invoke(ctx context.Context, method string, args *driversdk.CallArgs, reply interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, d.manifest.Timeout())
+	defer cancel()
+
+	client, err := d.connect(ctx)
+	if err != nil {
+		return err
+	}
+	return d.call(ctx, client, method, args, reply)
+}
+
+// call calls a method on a started plugin. A broken connection means the plugin exited,
+// so it is forgotten and started again by the next call.
+func (d *Driver) call(ctx context.Context, client *rpc.Client, method string, args *driversdk.CallArgs, reply interface{}) error {
+	args.Deadline, _ = ctx.Deadline()
+
+	err := callClient(ctx, client, method, args, reply)
+	var serverErr rpc.ServerError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &serverErr):
+		return errors.New(string(serverErr))
+	case ctx.Err() != nil:
+		return fmt.Errorf("plugin %s did not answer %s in time: %w", d.manifest.Name, method, ctx.Err())
+	}
+
+	d.logger.Warn("Driver plugin connection lost, restarting on next call", "method", method, "error", err)
+	d.mu.Lock()
+	if d.client == client {
+		d.client.Close()
+		d.client = nil
+	}
+	d.mu.Unlock()
+	return fmt.Errorf("plugin %s stopped: %w", d.manifest.Name, err)
+}
+
+// callClient calls a method, giving up when ctx ends
+func callClient(ctx context.Context, client *rpc.Client, method string, args, reply interface{}) error {
+	call := client.Go(driversdk.ServiceName+"."+method, args, reply, make(chan *rpc.Call, 1))
+	select {
+	case <-call.Done:
+		return call.Error
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// connect returns the client of the running plugin, starting it if needed
+func (d *Driver) connect(ctx context.Context) (*rpc.Client, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.client != nil {
+		return d.client, nil
+	}
+
+	conn, err := d.start()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start plugin %s: %w", d.manifest.Name, err)
+	}
+	client := jsonrpc.NewClient(conn)
+
+	var info driversdk.InfoReply
+	if err := callClient(ctx, client, "Info", &driversdk.InfoArgs{ProtocolVersion: driversdk.ProtocolVersion}, &info); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("plugin %s did not answer: %w", d.manifest.Name, err)
+	}
+	if info.ProtocolVersion != driversdk.ProtocolVersion {
+		client.Close()
+		return nil, fmt.Errorf("plugin %s speaks protocol version %d, metron speaks %d: rebuild it with a matching driversdk",
+			d.manifest.Name, info.ProtocolVersion, driversdk.ProtocolVersion)
+	}
+
+	d.client = client
+	d.capabilities = info.Capabilities
+	return client, nil
+}
+
+// startProcess runs the plugin command with its stdin and stdout as the connection
+func (d *Driver) startProcess() (io.ReadWriteCloser, error) {
+	cmd := exec.Command(d.manifest.Command, d.manifest.Args...)
+	cmd.Env = os.Environ()
+	keys := make([]string, 0, len(d.manifest.Env))
+	for key := range d.manifest.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		cmd.Env = append(cmd.Env, key+"="+d.manifest.Env[key])
+	}
+	cmd.Stderr = &logWriter{logger: d.logger}
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	p := &process{cmd: cmd, stdin: stdin, stdout: stdout, done: make(chan struct{})}
+	go func() {
+		err := cmd.Wait()
+		d.logger.Debug("Driver plugin exited", "error", err)
+		close(p.done)
+	}()
+	return p, nil
+}
+
+// process is a plugin subprocess as a connection
+type process struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout io.ReadCloser
+	done   chan struct{} // Closed when the process exited
+}
+
+func (p *process) Read(b []byte) (int, error)  { return p.stdout.Read(b) }
+func (p *process) Write(b []byte) (int, error) { return p.stdin.Write(b) }
+
+// Close asks the plugin to exit by closing its stdin and kills it if it does not
+func (p *process) Close() error {
+	p.stdin.Close()
+	select {
+	case <-p.done:
+	case <-time.After(stopTimeout):
+		p.cmd.Process.Kill()
+		<-p.done
+	}
+	return nil
+}
+
+// logWriter logs each line a plugin writes to stderr
+type logWriter struct {
+	logger *slog.Logger
+	buf    []byte
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if line := bytes.TrimSpace(w.buf[:i]); len(line) > 0 {
+			w.logger.Info("Driver plugin output", "line", string(line))
+		}
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
+// sdkDevice converts a device for the plugin
+func sdkDevice(device *devices.Device) driversdk.Device {
+	return driversdk.Device{
+		ID:         device.ID,
+		Name:       device.Name,
+		Type:       device.Type,
+		Parameters: device.Parameters,
+	}
+}
+
+// sdkSession converts a session for the plugin
+func sdkSession(session *core.Session) driversdk.Session {
+	return driversdk.Session{
+		ID:               session.ID,
+		DeviceID:         session.DeviceID,
+		DeviceType:       session.DeviceType,
+		ChildIDs:         session.ChildIDs,
+		StartTime:        session.StartTime,
+		ExpectedDuration: session.ExpectedDuration,
+		RemainingMinutes: session.CalculateRemainingMinutes(),
+	}
+}
@@ -0,0 +1,291 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"metron/driversdk"
+	"metron/internal/core"
+	"metron/internal/devices"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// helperEnv makes the test binary act as a plugin (see TestMain)
+const helperEnv = "METRON_TEST_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(helperEnv) != "" {
+		fmt.Fprintln(os.Stderr, "helper plugin ready")
+		if err := driversdk.Serve(&fakePlugin{host: os.Getenv("LOCK_HOST")}); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// fakePlugin records calls; it supports warnings and breaks but not extensions.
+type fakePlugin struct {
+	host string
+
+	mu    sync.Mutex
+	calls []string
+	fail  error
+}
+
+func (p *fakePlugin) record(call string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, call)
+	return p.fail
+}
+
+func (p *fakePlugin) StartSession(_ context.Context, device driversdk.Device, session driversdk.Session) error {
+	if device.Parameters["host"] == "exit" {
+		os.Exit(3)
+	}
+	return p.record(fmt.Sprintf("start %s %s %v %d", device.ID, device.Parameters["host"], session.ChildIDs, session.ExpectedDuration))
+}
+
+func (p *fakePlugin) StopSession(_ context.Context, device driversdk.Device, session driversdk.Session) error {
+	return p.record("stop " + device.ID)
+}
+
+func (p *fakePlugin) ApplyWarning(_ context.Context, device driversdk.Device, session driversdk.Session, minutesRemaining int) error {
+	return p.record(fmt.Sprintf("warn %s %d", device.ID, minutesRemaining))
+}
+
+func (p *fakePlugin) StartBreak(_ context.Context, device driversdk.Device, session driversdk.Session, breakMinutes int) error {
+	return p.record(fmt.Sprintf("break %s %d", device.ID, breakMinutes))
+}
+
+func (p *fakePlugin) EndBreak(_ context.Context, device driversdk.Device, session driversdk.Session) error {
+	return p.record("resume " + device.ID)
+}
+
+func (p *fakePlugin) Validate(device driversdk.Device) error {
+	if _, ok := device.Parameters["host"].(string); !ok {
+		return fmt.Errorf("device %s: host parameter is required", device.ID)
+	}
+	return nil
+}
+
+func (p *fakePlugin) HealthCheck(ctx context.Context) error {
+	if p.host == "" {
+		return errors.New("LOCK_HOST is not set")
+	}
+	return nil
+}
+
+// basicPlugin only starts and stops sessions.
+type basicPlugin struct {
+	calls []string
+}
+
+func (p *basicPlugin) StartSession(_ context.Context, device driversdk.Device, session driversdk.Session) error {
+	p.calls = append(p.calls, "start "+device.ID)
+	return nil
+}
+
+func (p *basicPlugin) StopSession(_ context.Context, device driversdk.Device, session driversdk.Session) error {
+	p.calls = append(p.calls, "stop "+device.ID)
+	return nil
+}
+
+func newRegistry(t *testing.T, params map[string]interface{}) *devices.Registry {
+	t.Helper()
+	registry := devices.NewRegistry()
+	require.NoError(t, registry.Register(&devices.Device{
+		ID:         "lamp",
+		Name:       "Desk Lamp",
+		Type:       "lamp",
+		Driver:     "lamp",
+		Parameters: params,
+	}))
+	return registry
+}
+
+// setupPipeDriver connects a driver to an in-process plugin
+func setupPipeDriver(t *testing.T, plugin driversdk.Driver) *Driver {
+	t.Helper()
+	driver := NewDriver(Manifest{Name: "lamp", Command: "unused"}, newRegistry(t, map[string]interface{}{"host": "10.0.0.5"}), nil)
+	driver.start = func() (io.ReadWriteCloser, error) {
+		host, plug := net.Pipe()
+		go driversdk.ServeConn(plugin, plug)
+		return host, nil
+	}
+	require.NoError(t, driver.Start(context.Background()))
+	t.Cleanup(func() { driver.Close() })
+	return driver
+}
+
+func testSession() *core.Session {
+	return &core.Session{
+		ID:               "ses_1",
+		DeviceID:         "lamp",
+		ChildIDs:         []string{"alice"},
+		StartTime:        time.Now(),
+		ExpectedDuration: 30,
+		Status:           core.SessionStatusActive,
+	}
+}
+
+func TestDriver_SessionLifecycle(t *testing.T) {
+	plugin := &fakePlugin{}
+	driver := setupPipeDriver(t, plugin)
+	session := testSession()
+	ctx := context.Background()
+
+	require.NoError(t, driver.StartSession(ctx, session))
+	require.NoError(t, driver.ApplyWarning(ctx, session, 5))
+	require.NoError(t, driver.StartBreak(ctx, session, 10))
+	require.NoError(t, driver.EndBreak(ctx, session))
+	require.NoError(t, driver.ExtendSession(ctx, session, 15)) // Not supported: nothing to do
+	require.NoError(t, driver.StopSession(ctx, session))
+
+	assert.Equal(t, []string{
+		"start lamp 10.0.0.5 [alice] 30",
+		"warn lamp 5",
+		"break lamp 10",
+		"resume lamp",
+		"stop lamp",
+	}, plugin.calls)
+
+	caps := driver.Capabilities()
+	assert.True(t, caps.SupportsWarnings)
+	assert.True(t, caps.SupportsBreaks)
+	assert.False(t, caps.SupportsExtension)
+	assert.False(t, caps.SupportsLiveState)
+
+	state, err := driver.GetLiveState(ctx, "lamp")
+	require.NoError(t, err)
+	assert.Nil(t, state)
+}
+
+func TestDriver_BasicPluginFallbacks(t *testing.T) {
+	plugin := &basicPlugin{}
+	driver := setupPipeDriver(t, plugin)
+	session := testSession()
+	ctx := context.Background()
+
+	// Without warnings or breaks, both are skipped instead of failing (core also checks the capabilities)
+	require.NoError(t, driver.ApplyWarning(ctx, session, 5))
+	require.NoError(t, driver.StartBreak(ctx, session, 10))
+	require.NoError(t, driver.EndBreak(ctx, session))
+	require.NoError(t, driver.HealthCheck(ctx))
+
+	assert.Empty(t, plugin.calls)
+	assert.False(t, driver.Capabilities().SupportsWarnings)
+}
+
+func TestDriver_Validate(t *testing.T) {
+	driver := setupPipeDriver(t, &fakePlugin{})
+
+	require.NoError(t, driver.Validate(&devices.Device{ID: "lamp", Parameters: map[string]interface{}{"host": "10.0.0.5"}}))
+	err := driver.Validate(&devices.Device{ID: "lamp2"})
+	require.Error(t, err)
+	assert.Equal(t, "device lamp2: host parameter is required", err.Error())
+
+	// Plugins without Validate accept every device
+	assert.NoError(t, setupPipeDriver(t, &basicPlugin{}).Validate(&devices.Device{ID: "lamp2"}))
+}
+
+func TestDriver_PluginError(t *testing.T) {
+	plugin := &fakePlugin{fail: errors.New("lamp unreachable")}
+	driver := setupPipeDriver(t, plugin)
+
+	err := driver.StartSession(context.Background(), testSession())
+	require.Error(t, err)
+	assert.Equal(t, "lamp unreachable", err.Error())
+
+	// The plugin keeps running after errors
+	plugin.fail = nil
+	require.NoError(t, driver.StopSession(context.Background(), testSession()))
+}
+
+func TestDriver_UnknownDevice(t *testing.T) {
+	driver := setupPipeDriver(t, &fakePlugin{})
+	session := testSession()
+	session.DeviceID = "tv1"
+
+	assert.Error(t, driver.StartSession(context.Background(), session))
+}
+
+// helperManifest runs the test binary as a plugin
+func helperManifest(t *testing.T, env map[string]string) Manifest {
+	t.Helper()
+	executable, err := os.Executable()
+	require.NoError(t, err)
+	env[helperEnv] = "1"
+	return Manifest{Name: "lamp", Command: executable, Args: []string{"-test.run=^$"}, Env: env, TimeoutSeconds: 10}
+}
+
+func TestDriver_Process(t *testing.T) {
+	driver := NewDriver(helperManifest(t, map[string]string{"LOCK_HOST": "10.0.0.5"}), newRegistry(t, map[string]interface{}{"host": "10.0.0.5"}), nil)
+	require.NoError(t, driver.Start(context.Background()))
+	defer driver.Close()
+
+	ctx := context.Background()
+	require.NoError(t, driver.HealthCheck(ctx))
+	require.NoError(t, driver.StartSession(ctx, testSession()))
+}
+
+func TestDriver_ProcessHealthCheckFails(t *testing.T) {
+	driver := NewDriver(helperManifest(t, map[string]string{}), newRegistry(t, nil), nil)
+	defer driver.Close()
+
+	err := driver.HealthCheck(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "LOCK_HOST is not set")
+}
+
+func TestDriver_ProcessRestart(t *testing.T) {
+	registry := newRegistry(t, map[string]interface{}{"host": "exit"})
+	driver := NewDriver(helperManifest(t, map[string]string{"LOCK_HOST": "10.0.0.5"}), registry, nil)
+	require.NoError(t, driver.Start(context.Background()))
+	defer driver.Close()
+
+	// The plugin exits during the call
+	ctx := context.Background()
+	err := driver.StartSession(ctx, testSession())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "plugin lamp stopped")
+
+	// The next call starts it again
+	require.NoError(t, driver.StopSession(ctx, testSession()))
+}
+
+func TestDriver_StartFailure(t *testing.T) {
+	driver := NewDriver(Manifest{Name: "lamp", Command: filepath.Join(t.TempDir(), "missing")}, newRegistry(t, nil), nil)
+
+	err := driver.Start(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to start plugin lamp")
+}
+
+func TestDriver_ProtocolMismatch(t *testing.T) {
+	driver := NewDriver(Manifest{Name: "lamp", Command: "unused"}, newRegistry(t, nil), nil)
+	driver.start = func() (io.ReadWriteCloser, error) {
+		host, plug := net.Pipe()
+		go func() {
+			// Answer Info with a future protocol version
+			plug.Read(make([]byte, 1024))
+			fmt.Fprintf(plug, `{"id":0,"result":{"protocol_version":%d,"capabilities":{}},"error":null}`+"\n", driversdk.ProtocolVersion+1)
+		}()
+		return host, nil
+	}
+
+	err := driver.Start(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "protocol version")
+}
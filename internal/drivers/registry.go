@@ -1,8 +1,10 @@
 package drivers
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"metron/internal/core"
 	"metron/internal/devices"
 	"sync"
 )
@@ -76,3 +78,58 @@ func (r *Registry) Unregister(name string) error {
 	delete(r.drivers, name)
 	return nil
 }
+
+// ValidateDevice checks that the device's driver is registered, that its generic parameters
+// are valid and, for drivers that support it, that the driver accepts its parameters
+func (r *Registry) ValidateDevice(device *devices.Device) error {
+	driver, err := r.Get(device.Driver)
+	if err != nil {
+		return fmt.Errorf("device %s: driver %q is not available (configure the driver or fix the device's driver): %w", device.ID, device.Driver, err)
+	}
+
+	if action, ok := device.GetParameter("break_action").(string); ok && !core.IsValidBreakAction(action) {
+		return fmt.Errorf("device %s: break_action must be %q, %q or %q, got %q",
+			device.ID, core.BreakActionWarn, core.BreakActionBreak, core.BreakActionLock, action)
+	}
+
+	if validating, ok := driver.(devices.ValidatingDriver); ok {
+		return validating.Validate(device)
+	}
+	return nil
+}
+
+// CheckHealth runs HealthCheck on every registered driver that supports it
+// Returns a map of driver name to error (nil error means healthy)
+func (r *Registry) CheckHealth(ctx context.Context) map[string]error {
+	r.mu.RLock()
+	snapshot := make(map[string]devices.DeviceDriver, len(r.drivers))
+	for name, driver := range r.drivers {
+		snapshot[name] = driver
+	}
+	r.mu.RUnlock()
+
+	results := make(map[string]error, len(snapshot))
+	for name, driver := range snapshot {
+		results[name] = nil
+		if checkable, ok := driver.(devices.HealthCheckableDriver); ok {
+			results[name] = checkable.HealthCheck(ctx)
+		}
+	}
+	return results
+}
+
+// SetHeartbeatRecorder passes the recorder to every registered driver that polls its devices
+// Returns the names of the drivers that accepted it
+func (r *Registry) SetHeartbeatRecorder(recorder devices.HeartbeatRecorder) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var names []string
+	for name, driver := range r.drivers {
+		if reporting, ok := driver.(devices.HeartbeatReportingDriver); ok {
+			reporting.SetHeartbeatRecorder(recorder)
+			names = append(names, name)
+		}
+	}
+	return names
+}
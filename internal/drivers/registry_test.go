@@ -2,6 +2,8 @@ package drivers
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"metron/internal/core"
 	"metron/internal/devices"
 	"testing"
@@ -120,6 +122,119 @@ func TestRegistry_Unregister(t *testing.T) {
 	assert.ErrorIs(t, err, ErrDriverNotFound)
 }
 
+// unhealthyDriver is a mock driver that implements HealthCheckableDriver
+type unhealthyDriver struct {
+	mockDriver
+	err error
+}
+
+func (m *unhealthyDriver) HealthCheck(ctx context.Context) error {
+	return m.err
+}
+
+func TestRegistry_CheckHealth(t *testing.T) {
+	registry := NewRegistry()
+	checkErr := errors.New("credentials missing")
+
+	require.NoError(t, registry.Register(&mockDriver{name: "plain"}))
+	require.NoError(t, registry.Register(&unhealthyDriver{mockDriver: mockDriver{name: "broken"}, err: checkErr}))
+	require.NoError(t, registry.Register(&unhealthyDriver{mockDriver: mockDriver{name: "healthy"}}))
+
+	results := registry.CheckHealth(context.Background())
+	assert.Len(t, results, 3)
+	assert.NoError(t, results["plain"])
+	assert.NoError(t, results["healthy"])
+	assert.ErrorIs(t, results["broken"], checkErr)
+}
+
+// validatingDriver is a mock driver that implements ValidatingDriver
+type validatingDriver struct {
+	mockDriver
+}
+
+func (m *validatingDriver) Validate(device *devices.Device) error {
+	if device.GetParameter("host") == nil {
+		return fmt.Errorf("device %s: host parameter is required", device.ID)
+	}
+	return nil
+}
+
+func TestRegistry_ValidateDevice(t *testing.T) {
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(&mockDriver{name: "plain"}))
+	require.NoError(t, registry.Register(&validatingDriver{mockDriver: mockDriver{name: "lamp"}}))
+
+	tests := []struct {
+		name    string
+		device  *devices.Device
+		wantErr string
+	}{
+		{
+			name:   "valid device",
+			device: &devices.Device{ID: "lamp1", Driver: "lamp", Parameters: map[string]interface{}{"host": "10.0.0.5"}},
+		},
+		{
+			name:   "driver without validation",
+			device: &devices.Device{ID: "tv1", Driver: "plain"},
+		},
+		{
+			name:    "driver rejects parameters",
+			device:  &devices.Device{ID: "lamp1", Driver: "lamp"},
+			wantErr: "device lamp1: host parameter is required",
+		},
+		{
+			name:    "unknown driver",
+			device:  &devices.Device{ID: "tv1", Driver: "kidslox"},
+			wantErr: `device tv1: driver "kidslox" is not available`,
+		},
+		{
+			name:    "invalid break action",
+			device:  &devices.Device{ID: "tv1", Driver: "plain", Parameters: map[string]interface{}{"break_action": "pause"}},
+			wantErr: `device tv1: break_action must be "warn", "break" or "lock", got "pause"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := registry.ValidateDevice(tt.device)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+// pollingDriver is a mock driver that implements HeartbeatReportingDriver
+type pollingDriver struct {
+	mockDriver
+	recorder devices.HeartbeatRecorder
+}
+
+func (m *pollingDriver) SetHeartbeatRecorder(recorder devices.HeartbeatRecorder) {
+	m.recorder = recorder
+}
+
+type nopHeartbeatRecorder struct{}
+
+func (nopHeartbeatRecorder) Record(ctx context.Context, deviceID, source string) error {
+	return nil
+}
+
+func TestRegistry_SetHeartbeatRecorder(t *testing.T) {
+	registry := NewRegistry()
+	polling := &pollingDriver{mockDriver: mockDriver{name: "polling"}}
+
+	require.NoError(t, registry.Register(&mockDriver{name: "plain"}))
+	require.NoError(t, registry.Register(polling))
+
+	names := registry.SetHeartbeatRecorder(nopHeartbeatRecorder{})
+	assert.Equal(t, []string{"polling"}, names)
+	assert.NotNil(t, polling.recorder)
+}
+
 func TestRegistry_Concurrent(t *testing.T) {
 	registry := NewRegistry()
 
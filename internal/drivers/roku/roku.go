@@ -0,0 +1,242 @@
+// Package roku provides a device driver for Roku players and Roku TVs using the
+// External Control Protocol (ECP), the local HTTP API used by the Roku mobile app.
+// When a session ends the device returns to the home screen (or a "time's up" channel)
+// and Roku TVs are turned off.
+package roku
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"metron/internal/core"
+	"metron/internal/devices"
+)
+
+const DriverName = "roku"
+
+// DefaultPort is the ECP port
+const DefaultPort = 8060
+
+// deviceConfig holds the ECP settings of a device from its parameters
+type deviceConfig struct {
+	BaseURL       string // http://host:port
+	TimesUpApp    string // Channel launched when time is up (optional)
+	PowerOff      bool   // Turn Roku TVs off when time is up
+	SearchWarning bool   // Show warnings on the search screen
+}
+
+// Driver implements the DeviceDriver interface by sending ECP commands.
+type Driver struct {
+	deviceRegistry *devices.Registry
+	httpClient     *http.Client
+	logger         *slog.Logger
+}
+
+// NewDriver creates a new Roku driver.
+func NewDriver(deviceRegistry *devices.Registry, logger *slog.Logger) *Driver {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Driver{
+		deviceRegistry: deviceRegistry,
+		httpClient:     &http.Client{Timeout: 10 * time.Second},
+		logger:         logger.With("driver", DriverName),
+	}
+}
+
+// Name returns the driver name.
+func (d *Driver) Name() string {
+	return DriverName
+}
+
+// Capabilities returns the driver capabilities.
+func (d *Driver) Capabilities() devices.DriverCapabilities {
+	return devices.DriverCapabilities{
+		SupportsWarnings:   true, // Only for devices with search_warning
+		SupportsLiveState:  false,
+		SupportsScheduling: true,
+		SupportsBreaks:     true,
+	}
+}
+
+// Validate checks the device parameters at startup.
+func (d *Driver) Validate(device *devices.Device) error {
+	_, err := readDeviceConfig(device)
+	return err
+}
+
+// StartSession checks the device parameters. Roku devices cannot be locked,
+// so there is nothing to release.
+func (d *Driver) StartSession(ctx context.Context, session *core.Session) error {
+	d.logger.Info("Starting Roku session",
+		"session_id", session.ID,
+		"device_id", session.DeviceID,
+		"duration_minutes", session.ExpectedDuration)
+
+	_, err := d.getDeviceConfig(session.DeviceID)
+	return err
+}
+
+// StopSession shows the "time's up" screen.
+func (d *Driver) StopSession(ctx context.Context, session *core.Session) error {
+	d.logger.Info("Stopping Roku session",
+		"session_id", session.ID,
+		"device_id", session.DeviceID)
+
+	if err := d.timesUp(ctx, session.DeviceID); err != nil {
+		d.logger.Error("Failed to stop Roku",
+			"session_id", session.ID,
+			"device_id", session.DeviceID,
+			"error", err)
+		return fmt.Errorf("failed to stop roku: %w", err)
+	}
+	return nil
+}
+
+// ApplyWarning opens the search screen with the remaining minutes as the search text,
+// for devices with search_warning. ECP has no notification API, and searching
+// interrupts playback, so it is opt-in.
+func (d *Driver) ApplyWarning(ctx context.Context, session *core.Session, minutesRemaining int) error {
+	cfg, err := d.getDeviceConfig(session.DeviceID)
+	if err != nil {
+		return err
+	}
+	if !cfg.SearchWarning {
+		d.logger.Debug("Roku warning requested but search_warning is off",
+			"session_id", session.ID,
+			"minutes_remaining", minutesRemaining)
+		return nil
+	}
+
+	keyword := fmt.Sprintf("%d min left", minutesRemaining)
+	if err := d.post(ctx, cfg, "/search/browse?"+url.Values{"keyword": {keyword}}.Encode()); err != nil {
+		d.logger.Error("Failed to show Roku warning",
+			"session_id", session.ID,
+			"device_id", session.DeviceID,
+			"error", err)
+		return fmt.Errorf("failed to show warning: %w", err)
+	}
+
+	d.logger.Info("Roku warning shown",
+		"session_id", session.ID,
+		"minutes_remaining", minutesRemaining)
+	return nil
+}
+
+// StartBreak shows the "time's up" screen for a mandatory break.
+func (d *Driver) StartBreak(ctx context.Context, session *core.Session, breakMinutes int) error {
+	d.logger.Info("Starting Roku break",
+		"session_id", session.ID,
+		"device_id", session.DeviceID,
+		"break_minutes", breakMinutes)
+
+	if err := d.timesUp(ctx, session.DeviceID); err != nil {
+		d.logger.Error("Failed to stop Roku for break",
+			"session_id", session.ID,
+			"error", err)
+		return fmt.Errorf("failed to stop roku: %w", err)
+	}
+	return nil
+}
+
+// EndBreak does nothing: the child resumes with the remote.
+func (d *Driver) EndBreak(ctx context.Context, session *core.Session) error {
+	d.logger.Info("Ending Roku break",
+		"session_id", session.ID,
+		"device_id", session.DeviceID)
+	return nil
+}
+
+// GetLiveState is not supported.
+func (d *Driver) GetLiveState(ctx context.Context, deviceID string) (*devices.DeviceState, error) {
+	return nil, nil
+}
+
+// timesUp leaves the current channel, launches the "time's up" channel if configured
+// and turns Roku TVs off if configured
+func (d *Driver) timesUp(ctx context.Context, deviceID string) error {
+	cfg, err := d.getDeviceConfig(deviceID)
+	if err != nil {
+		return err
+	}
+
+	if err := d.post(ctx, cfg, "/keypress/Home"); err != nil {
+		return err
+	}
+	if cfg.TimesUpApp != "" {
+		if err := d.post(ctx, cfg, "/launch/"+url.PathEscape(cfg.TimesUpApp)); err != nil {
+			return err
+		}
+	}
+	if cfg.PowerOff {
+		if err := d.post(ctx, cfg, "/keypress/PowerOff"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// post sends an ECP command
+func (d *Driver) post(ctx context.Context, cfg *deviceConfig, path string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.BaseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach Roku: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// 403: "Control by mobile apps" is disabled or limited on the device
+	if resp.StatusCode == http.StatusForbidden {
+		return fmt.Errorf("roku refused %s: enable Settings > System > Advanced system settings > Control by mobile apps", path)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("roku %s returned status %d", path, resp.StatusCode)
+	}
+
+	d.logger.Debug("Roku command sent", "url", cfg.BaseURL, "path", path)
+	return nil
+}
+
+// getDeviceConfig looks up the device and reads its parameters
+func (d *Driver) getDeviceConfig(deviceID string) (*deviceConfig, error) {
+	device, err := d.deviceRegistry.Get(deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device %s: %w", deviceID, err)
+	}
+	return readDeviceConfig(device)
+}
+
+// readDeviceConfig reads the host, port, times_up_app, power_off and search_warning device parameters
+func readDeviceConfig(device *devices.Device) (*deviceConfig, error) {
+	host, _ := device.GetParameter("host").(string)
+	if host == "" {
+		return nil, fmt.Errorf("device %s: host parameter is required", device.ID)
+	}
+	port := DefaultPort
+	switch p := device.GetParameter("port").(type) {
+	case float64:
+		port = int(p)
+	case int:
+		port = p
+	}
+
+	cfg := &deviceConfig{BaseURL: "http://" + net.JoinHostPort(host, strconv.Itoa(port))}
+	switch app := device.GetParameter("times_up_app").(type) {
+	case string:
+		cfg.TimesUpApp = app
+	case float64: // Channel IDs are numeric and often written as JSON numbers
+		cfg.TimesUpApp = strconv.Itoa(int(app))
+	}
+	cfg.PowerOff, _ = device.GetParameter("power_off").(bool)
+	cfg.SearchWarning, _ = device.GetParameter("search_warning").(bool)
+	return cfg, nil
+}
@@ -0,0 +1,95 @@
+package roku
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"metron/internal/core"
+	"metron/internal/devices"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupTestDriver starts a fake ECP server and registers a device pointing to it.
+func setupTestDriver(t *testing.T, status int, params map[string]interface{}) (*Driver, *[]string) {
+	t.Helper()
+
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		requests = append(requests, r.URL.RequestURI())
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(serverURL.Port())
+	require.NoError(t, err)
+
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	params["host"] = serverURL.Hostname()
+	params["port"] = float64(port)
+
+	registry := devices.NewRegistry()
+	require.NoError(t, registry.Register(&devices.Device{
+		ID:         "kids-roku",
+		Name:       "Kids Roku",
+		Type:       "tv",
+		Driver:     DriverName,
+		Parameters: params,
+	}))
+	return NewDriver(registry, nil), &requests
+}
+
+func TestDriver_StopSession(t *testing.T) {
+	driver, requests := setupTestDriver(t, http.StatusOK, nil)
+
+	require.NoError(t, driver.StopSession(context.Background(), &core.Session{ID: "ses_1", DeviceID: "kids-roku"}))
+	assert.Equal(t, []string{"/keypress/Home"}, *requests)
+}
+
+func TestDriver_TimesUpAppAndPowerOff(t *testing.T) {
+	driver, requests := setupTestDriver(t, http.StatusOK, map[string]interface{}{
+		"times_up_app": float64(12345),
+		"power_off":    true,
+	})
+
+	require.NoError(t, driver.StartBreak(context.Background(), &core.Session{ID: "ses_1", DeviceID: "kids-roku"}, 10))
+	assert.Equal(t, []string{"/keypress/Home", "/launch/12345", "/keypress/PowerOff"}, *requests)
+}
+
+func TestDriver_ApplyWarning(t *testing.T) {
+	session := &core.Session{ID: "ses_1", DeviceID: "kids-roku"}
+
+	driver, requests := setupTestDriver(t, http.StatusOK, nil)
+	require.NoError(t, driver.ApplyWarning(context.Background(), session, 5))
+	assert.Empty(t, *requests)
+
+	driver, requests = setupTestDriver(t, http.StatusOK, map[string]interface{}{"search_warning": true})
+	require.NoError(t, driver.ApplyWarning(context.Background(), session, 5))
+	assert.Equal(t, []string{"/search/browse?keyword=5+min+left"}, *requests)
+}
+
+func TestDriver_ControlDisabled(t *testing.T) {
+	driver, _ := setupTestDriver(t, http.StatusForbidden, nil)
+
+	err := driver.StopSession(context.Background(), &core.Session{ID: "ses_1", DeviceID: "kids-roku"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "Control by mobile apps")
+}
+
+func TestDriver_MissingHost(t *testing.T) {
+	registry := devices.NewRegistry()
+	require.NoError(t, registry.Register(&devices.Device{ID: "kids-roku", Name: "Kids Roku", Type: "tv", Driver: DriverName}))
+	driver := NewDriver(registry, nil)
+
+	assert.Error(t, driver.StartSession(context.Background(), &core.Session{ID: "ses_1", DeviceID: "kids-roku"}))
+}
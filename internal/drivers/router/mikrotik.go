@@ -0,0 +1,142 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// mikroTikFirewall manages block rules through the RouterOS v7 REST API (/rest, served by
+// the www or www-ssl service). RouterOS filter rules match a single source MAC, so a device
+// gets one forward-chain drop rule per MAC, all tagged with the rule name as comment.
+type mikroTikFirewall struct {
+	baseURL  string
+	username string
+	password string
+	client   *http.Client
+}
+
+func newMikroTikFirewall(config Config, client *http.Client) *mikroTikFirewall {
+	return &mikroTikFirewall{
+		baseURL:  strings.TrimSuffix(config.URL, "/") + "/rest",
+		username: config.Username,
+		password: config.Password,
+		client:   client,
+	}
+}
+
+// filterRule is a RouterOS firewall filter rule as returned by the REST API
+type filterRule struct {
+	ID            string `json:".id"`
+	SrcMACAddress string `json:"src-mac-address"`
+	Disabled      string `json:"disabled"`
+}
+
+// SetBlocked enables or disables the rules tagged with rule, creating rules for new MACs
+// when blocking and removing rules for MACs no longer configured
+func (f *mikroTikFirewall) SetBlocked(ctx context.Context, rule string, macs []string, blocked bool) error {
+	var existing []filterRule
+	if err := f.do(ctx, http.MethodGet, "/ip/firewall/filter?comment="+url.QueryEscape(rule), nil, &existing); err != nil {
+		return err
+	}
+
+	disabled := "true"
+	if blocked {
+		disabled = "false"
+	}
+
+	wanted := make(map[string]bool, len(macs))
+	for _, mac := range macs {
+		wanted[mac] = true
+	}
+
+	for _, r := range existing {
+		mac := strings.ToUpper(r.SrcMACAddress)
+		if !wanted[mac] {
+			if err := f.do(ctx, http.MethodDelete, "/ip/firewall/filter/"+r.ID, nil, nil); err != nil {
+				return err
+			}
+			continue
+		}
+		delete(wanted, mac)
+
+		if r.Disabled == disabled {
+			continue
+		}
+		if err := f.do(ctx, http.MethodPatch, "/ip/firewall/filter/"+r.ID, map[string]string{
+			"disabled": disabled,
+		}, nil); err != nil {
+			return err
+		}
+	}
+
+	if !blocked {
+		return nil
+	}
+
+	// Keep the configured order when creating rules for new MACs
+	for _, mac := range macs {
+		if !wanted[mac] {
+			continue
+		}
+		if err := f.do(ctx, http.MethodPut, "/ip/firewall/filter", map[string]string{
+			"chain":           "forward",
+			"action":          "drop",
+			"src-mac-address": mac,
+			"comment":         rule,
+			"disabled":        disabled,
+		}, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Ping reads the router identity
+func (f *mikroTikFirewall) Ping(ctx context.Context) error {
+	return f.do(ctx, http.MethodGet, "/system/identity", nil, nil)
+}
+
+// do sends a REST request and decodes the JSON response into result if given
+func (f *mikroTikFirewall) do(ctx context.Context, method, path string, body, result interface{}) error {
+	var bodyReader io.Reader
+	if body != nil {
+		bodyBytes, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to marshal body: %w", err)
+		}
+		bodyReader = bytes.NewReader(bodyBytes)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, f.baseURL+path, bodyReader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(f.username, f.password)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		bodyBytes, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("routeros %s %s failed with status %d: %s", method, path, resp.StatusCode, string(bodyBytes))
+	}
+
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return fmt.Errorf("failed to decode routeros response: %w", err)
+		}
+	}
+	return nil
+}
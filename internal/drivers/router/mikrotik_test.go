@@ -0,0 +1,124 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRouterOS emulates /rest/ip/firewall/filter of the RouterOS REST API.
+type fakeRouterOS struct {
+	t      *testing.T
+	rules  []map[string]string
+	nextID int
+}
+
+func (f *fakeRouterOS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	user, pass, ok := r.BasicAuth()
+	if !ok || user != "metron" || pass != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/rest")
+	switch {
+	case r.Method == http.MethodGet && path == "/system/identity":
+		json.NewEncoder(w).Encode(map[string]string{"name": "MikroTik"})
+	case r.Method == http.MethodGet && path == "/ip/firewall/filter":
+		matched := []map[string]string{}
+		for _, rule := range f.rules {
+			if rule["comment"] == r.URL.Query().Get("comment") {
+				matched = append(matched, rule)
+			}
+		}
+		json.NewEncoder(w).Encode(matched)
+	case r.Method == http.MethodPut && path == "/ip/firewall/filter":
+		var rule map[string]string
+		require.NoError(f.t, json.NewDecoder(r.Body).Decode(&rule))
+		f.nextID++
+		rule[".id"] = fmt.Sprintf("*%X", f.nextID)
+		f.rules = append(f.rules, rule)
+		json.NewEncoder(w).Encode(rule)
+	case strings.HasPrefix(path, "/ip/firewall/filter/"):
+		id := strings.TrimPrefix(path, "/ip/firewall/filter/")
+		for i, rule := range f.rules {
+			if rule[".id"] != id {
+				continue
+			}
+			if r.Method == http.MethodDelete {
+				f.rules = append(f.rules[:i], f.rules[i+1:]...)
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			var changes map[string]string
+			require.NoError(f.t, json.NewDecoder(r.Body).Decode(&changes))
+			for key, value := range changes {
+				rule[key] = value
+			}
+			json.NewEncoder(w).Encode(rule)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func newTestMikroTik(t *testing.T, password string) (*mikroTikFirewall, *fakeRouterOS) {
+	t.Helper()
+
+	routerOS := &fakeRouterOS{t: t}
+	server := httptest.NewServer(routerOS)
+	t.Cleanup(server.Close)
+
+	config := Config{Type: TypeMikroTik, URL: server.URL, Username: "metron", Password: password}
+	return newMikroTikFirewall(config, server.Client()), routerOS
+}
+
+func TestMikroTikFirewall_SetBlocked(t *testing.T) {
+	firewall, routerOS := newTestMikroTik(t, "secret")
+	ctx := context.Background()
+	macs := []string{"AA:BB:CC:DD:EE:01", "AA:BB:CC:DD:EE:02"}
+
+	// Unblocking a device that was never blocked changes nothing
+	require.NoError(t, firewall.SetBlocked(ctx, "metron_phone1", macs, false))
+	assert.Empty(t, routerOS.rules)
+
+	// First block creates one drop rule per MAC
+	require.NoError(t, firewall.SetBlocked(ctx, "metron_phone1", macs, true))
+	require.Len(t, routerOS.rules, 2)
+	for i, rule := range routerOS.rules {
+		assert.Equal(t, "forward", rule["chain"])
+		assert.Equal(t, "drop", rule["action"])
+		assert.Equal(t, macs[i], rule["src-mac-address"])
+		assert.Equal(t, "metron_phone1", rule["comment"])
+		assert.Equal(t, "false", rule["disabled"])
+	}
+
+	// Unblocking disables the rules in place
+	require.NoError(t, firewall.SetBlocked(ctx, "metron_phone1", macs, false))
+	require.Len(t, routerOS.rules, 2)
+	assert.Equal(t, "true", routerOS.rules[0]["disabled"])
+	assert.Equal(t, "true", routerOS.rules[1]["disabled"])
+
+	// Rules of MACs removed from the device are deleted
+	require.NoError(t, firewall.SetBlocked(ctx, "metron_phone1", macs[1:], true))
+	require.Len(t, routerOS.rules, 1)
+	assert.Equal(t, "AA:BB:CC:DD:EE:02", routerOS.rules[0]["src-mac-address"])
+	assert.Equal(t, "false", routerOS.rules[0]["disabled"])
+}
+
+func TestMikroTikFirewall_BadCredentials(t *testing.T) {
+	firewall, _ := newTestMikroTik(t, "wrong")
+
+	err := firewall.Ping(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+}
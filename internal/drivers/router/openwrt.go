@@ -0,0 +1,239 @@
+package router
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ubus status codes (libubus UBUS_STATUS_*)
+const (
+	ubusStatusOK               = 0
+	ubusStatusNotFound         = 4
+	ubusStatusPermissionDenied = 6
+)
+
+// ubusAccessDenied is the JSON-RPC error rpcd returns for unknown or expired sessions
+const ubusAccessDenied = -32002
+
+// ubusNullSession is the session ID used to log in
+const ubusNullSession = "00000000000000000000000000000000"
+
+// errUbusSessionExpired triggers a new login
+var errUbusSessionExpired = errors.New("ubus session expired")
+
+// openWrtFirewall manages block rules in /etc/config/firewall through the ubus JSON-RPC
+// endpoint of uhttpd (uhttpd-mod-ubus, served at /ubus). The rpcd user needs read and write
+// access to the uci "firewall" config; committing the config makes procd reload the firewall.
+type openWrtFirewall struct {
+	endpoint string
+	username string
+	password string
+	client   *http.Client
+
+	mu      sync.Mutex
+	session string
+	nextID  int
+}
+
+func newOpenWrtFirewall(config Config, client *http.Client) *openWrtFirewall {
+	return &openWrtFirewall{
+		endpoint: strings.TrimSuffix(config.URL, "/") + "/ubus",
+		username: config.Username,
+		password: config.Password,
+		client:   client,
+	}
+}
+
+// SetBlocked creates or toggles a uci firewall rule rejecting LAN to WAN traffic from the MACs
+func (f *openWrtFirewall) SetBlocked(ctx context.Context, rule string, macs []string, blocked bool) error {
+	_, status, err := f.call(ctx, "uci", "get", map[string]interface{}{
+		"config":  "firewall",
+		"section": rule,
+	})
+	if err != nil {
+		return err
+	}
+
+	enabled := "0"
+	if blocked {
+		enabled = "1"
+	}
+
+	switch status {
+	case ubusStatusNotFound:
+		if !blocked {
+			return nil
+		}
+		if err := f.mustCall(ctx, "uci", "add", map[string]interface{}{
+			"config": "firewall",
+			"type":   "rule",
+			"name":   rule,
+			"values": map[string]interface{}{
+				"name":    "Metron " + strings.TrimPrefix(rule, rulePrefix),
+				"src":     "lan",
+				"dest":    "wan",
+				"src_mac": macs,
+				"proto":   "all",
+				"target":  "REJECT",
+				"enabled": enabled,
+			},
+		}); err != nil {
+			return err
+		}
+	case ubusStatusOK:
+		if err := f.mustCall(ctx, "uci", "set", map[string]interface{}{
+			"config":  "firewall",
+			"section": rule,
+			"values": map[string]interface{}{
+				"src_mac": macs,
+				"enabled": enabled,
+			},
+		}); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("ubus uci get failed with status %d", status)
+	}
+
+	return f.mustCall(ctx, "uci", "commit", map[string]interface{}{
+		"config": "firewall",
+	})
+}
+
+// Ping logs in and reads the firewall config
+func (f *openWrtFirewall) Ping(ctx context.Context) error {
+	return f.mustCall(ctx, "uci", "get", map[string]interface{}{
+		"config": "firewall",
+		"type":   "defaults",
+	})
+}
+
+// mustCall calls a ubus method and fails on any non-OK status
+func (f *openWrtFirewall) mustCall(ctx context.Context, object, method string, args map[string]interface{}) error {
+	_, status, err := f.call(ctx, object, method, args)
+	if err != nil {
+		return err
+	}
+	if status != ubusStatusOK {
+		return fmt.Errorf("ubus %s %s failed with status %d", object, method, status)
+	}
+	return nil
+}
+
+// call invokes a ubus method with the current session, logging in first if needed
+// and once more if the session expired
+func (f *openWrtFirewall) call(ctx context.Context, object, method string, args map[string]interface{}) (json.RawMessage, int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for attempt := 0; ; attempt++ {
+		if f.session == "" {
+			if err := f.login(ctx); err != nil {
+				return nil, 0, err
+			}
+		}
+
+		data, status, err := f.rpc(ctx, f.session, object, method, args)
+		if errors.Is(err, errUbusSessionExpired) || (err == nil && status == ubusStatusPermissionDenied) {
+			f.session = ""
+			if attempt == 0 {
+				continue
+			}
+			return nil, 0, fmt.Errorf("ubus %s %s: access denied (check rpcd ACL for uci firewall)", object, method)
+		}
+		return data, status, err
+	}
+}
+
+// login opens a ubus session; the caller holds f.mu
+func (f *openWrtFirewall) login(ctx context.Context) error {
+	data, status, err := f.rpc(ctx, ubusNullSession, "session", "login", map[string]interface{}{
+		"username": f.username,
+		"password": f.password,
+	})
+	if err != nil {
+		return fmt.Errorf("ubus login failed: %w", err)
+	}
+	if status != ubusStatusOK {
+		return fmt.Errorf("ubus login failed with status %d (check username and password)", status)
+	}
+
+	var result struct {
+		Session string `json:"ubus_rpc_session"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil || result.Session == "" {
+		return fmt.Errorf("ubus login returned no session")
+	}
+	f.session = result.Session
+	return nil
+}
+
+// ubusResponse is a JSON-RPC response from /ubus
+type ubusResponse struct {
+	Result []json.RawMessage `json:"result"`
+	Error  *struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// rpc sends one JSON-RPC "call" request and returns the ubus status and data; the caller holds f.mu
+func (f *openWrtFirewall) rpc(ctx context.Context, session, object, method string, args map[string]interface{}) (json.RawMessage, int, error) {
+	f.nextID++
+	body, err := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      f.nextID,
+		"method":  "call",
+		"params":  []interface{}{session, object, method, args},
+	})
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, 0, fmt.Errorf("ubus request failed with status %d", resp.StatusCode)
+	}
+
+	var result ubusResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, 0, fmt.Errorf("failed to decode ubus response: %w", err)
+	}
+	if result.Error != nil {
+		if result.Error.Code == ubusAccessDenied {
+			return nil, 0, errUbusSessionExpired
+		}
+		return nil, 0, fmt.Errorf("ubus error %d: %s", result.Error.Code, result.Error.Message)
+	}
+	if len(result.Result) == 0 {
+		return nil, 0, fmt.Errorf("empty ubus result")
+	}
+
+	var status int
+	if err := json.Unmarshal(result.Result[0], &status); err != nil {
+		return nil, 0, fmt.Errorf("invalid ubus status: %w", err)
+	}
+
+	var data json.RawMessage
+	if len(result.Result) > 1 {
+		data = result.Result[1]
+	}
+	return data, status, nil
+}
@@ -0,0 +1,153 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeUbus emulates the rpcd session and uci objects behind /ubus.
+type fakeUbus struct {
+	t        *testing.T
+	session  string
+	sections map[string]map[string]interface{}
+	commits  int
+	logins   int
+}
+
+func (u *fakeUbus) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	assert.Equal(u.t, "/ubus", r.URL.Path)
+
+	var req struct {
+		ID     int               `json:"id"`
+		Params []json.RawMessage `json:"params"`
+	}
+	require.NoError(u.t, json.NewDecoder(r.Body).Decode(&req))
+	require.Len(u.t, req.Params, 4)
+
+	var session, object, method string
+	var args map[string]interface{}
+	json.Unmarshal(req.Params[0], &session)
+	json.Unmarshal(req.Params[1], &object)
+	json.Unmarshal(req.Params[2], &method)
+	json.Unmarshal(req.Params[3], &args)
+
+	reply := func(result ...interface{}) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}
+
+	if object == "session" && method == "login" {
+		if args["password"] != "secret" {
+			reply(ubusStatusPermissionDenied)
+			return
+		}
+		u.logins++
+		u.session = "session-token"
+		reply(ubusStatusOK, map[string]string{"ubus_rpc_session": u.session})
+		return
+	}
+
+	if session != u.session {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req.ID,
+			"error":   map[string]interface{}{"code": ubusAccessDenied, "message": "Access denied"},
+		})
+		return
+	}
+
+	assert.Equal(u.t, "uci", object)
+	assert.Equal(u.t, "firewall", args["config"])
+
+	switch method {
+	case "get":
+		if args["type"] == "defaults" {
+			reply(ubusStatusOK, map[string]interface{}{"values": map[string]interface{}{}})
+			return
+		}
+		section, ok := u.sections[args["section"].(string)]
+		if !ok {
+			reply(ubusStatusNotFound)
+			return
+		}
+		reply(ubusStatusOK, map[string]interface{}{"values": section})
+	case "add":
+		assert.Equal(u.t, "rule", args["type"])
+		u.sections[args["name"].(string)] = args["values"].(map[string]interface{})
+		reply(ubusStatusOK, map[string]string{"section": args["name"].(string)})
+	case "set":
+		section := u.sections[args["section"].(string)]
+		for key, value := range args["values"].(map[string]interface{}) {
+			section[key] = value
+		}
+		reply(ubusStatusOK)
+	case "commit":
+		u.commits++
+		reply(ubusStatusOK)
+	default:
+		reply(3) // UBUS_STATUS_METHOD_NOT_FOUND
+	}
+}
+
+func newTestOpenWrt(t *testing.T, password string) (*openWrtFirewall, *fakeUbus) {
+	t.Helper()
+
+	ubus := &fakeUbus{t: t, sections: make(map[string]map[string]interface{})}
+	server := httptest.NewServer(ubus)
+	t.Cleanup(server.Close)
+
+	config := Config{Type: TypeOpenWrt, URL: server.URL + "/", Username: "metron", Password: password}
+	return newOpenWrtFirewall(config, server.Client()), ubus
+}
+
+func TestOpenWrtFirewall_SetBlocked(t *testing.T) {
+	firewall, ubus := newTestOpenWrt(t, "secret")
+	ctx := context.Background()
+	macs := []string{"AA:BB:CC:DD:EE:01"}
+
+	// Unblocking a device that was never blocked changes nothing
+	require.NoError(t, firewall.SetBlocked(ctx, "metron_phone1", macs, false))
+	assert.Empty(t, ubus.sections)
+	assert.Equal(t, 0, ubus.commits)
+
+	// First block creates the rule
+	require.NoError(t, firewall.SetBlocked(ctx, "metron_phone1", macs, true))
+	rule := ubus.sections["metron_phone1"]
+	require.NotNil(t, rule)
+	assert.Equal(t, "lan", rule["src"])
+	assert.Equal(t, "wan", rule["dest"])
+	assert.Equal(t, "REJECT", rule["target"])
+	assert.Equal(t, []interface{}{"AA:BB:CC:DD:EE:01"}, rule["src_mac"])
+	assert.Equal(t, "1", rule["enabled"])
+	assert.Equal(t, 1, ubus.commits)
+
+	// Later changes toggle the same rule
+	require.NoError(t, firewall.SetBlocked(ctx, "metron_phone1", macs, false))
+	assert.Equal(t, "0", ubus.sections["metron_phone1"]["enabled"])
+	assert.Equal(t, 2, ubus.commits)
+	assert.Equal(t, 1, ubus.logins)
+}
+
+func TestOpenWrtFirewall_RelogsInOnExpiredSession(t *testing.T) {
+	firewall, ubus := newTestOpenWrt(t, "secret")
+	ctx := context.Background()
+
+	require.NoError(t, firewall.Ping(ctx))
+	ubus.session = "rotated"
+
+	require.NoError(t, firewall.Ping(ctx))
+	assert.Equal(t, 2, ubus.logins)
+}
+
+func TestOpenWrtFirewall_BadCredentials(t *testing.T) {
+	firewall, _ := newTestOpenWrt(t, "wrong")
+
+	err := firewall.Ping(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "login failed")
+}
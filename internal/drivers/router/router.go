@@ -0,0 +1,277 @@
+// Package router provides a device driver that gates internet access on the home
+// router instead of device power. A firewall rule per device blocks its MAC addresses
+// while no session is running. Supports OpenWrt (ubus/uci) and MikroTik (RouterOS REST API).
+package router
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"metron/internal/core"
+	"metron/internal/devices"
+)
+
+const DriverName = "router"
+
+// Router types
+const (
+	TypeOpenWrt  = "openwrt"
+	TypeMikroTik = "mikrotik"
+)
+
+// rulePrefix starts the name of every firewall rule managed by the driver
+const rulePrefix = "metron_"
+
+// Firewall blocks and unblocks internet access for MAC addresses on a router.
+type Firewall interface {
+	// SetBlocked enables or disables the named block rule for the MAC addresses.
+	// The rule is created on first block; unblocking a missing rule is a no-op.
+	SetBlocked(ctx context.Context, rule string, macs []string, blocked bool) error
+
+	// Ping checks that the router is reachable and accepts the credentials.
+	Ping(ctx context.Context) error
+}
+
+// Config contains router driver configuration.
+type Config struct {
+	Type               string // TypeOpenWrt or TypeMikroTik
+	URL                string // Router base URL (e.g., "http://192.168.1.1")
+	Username           string
+	Password           string
+	InsecureSkipVerify bool // Accept self-signed router certificates
+}
+
+// Driver implements the DeviceDriver interface by toggling router firewall rules.
+type Driver struct {
+	firewall       Firewall
+	deviceRegistry *devices.Registry
+	logger         *slog.Logger
+	mu             sync.Mutex // Serializes rule changes
+}
+
+// NewDriver creates a new router driver for the configured router type.
+func NewDriver(config Config, deviceRegistry *devices.Registry, logger *slog.Logger) (*Driver, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	client := newHTTPClient(config.InsecureSkipVerify)
+
+	var firewall Firewall
+	switch config.Type {
+	case TypeOpenWrt:
+		firewall = newOpenWrtFirewall(config, client)
+	case TypeMikroTik:
+		firewall = newMikroTikFirewall(config, client)
+	default:
+		return nil, fmt.Errorf("unknown router type %q", config.Type)
+	}
+
+	return &Driver{
+		firewall:       firewall,
+		deviceRegistry: deviceRegistry,
+		logger:         logger.With("driver", DriverName, "router_type", config.Type),
+	}, nil
+}
+
+// Name returns the driver name.
+func (d *Driver) Name() string {
+	return DriverName
+}
+
+// Capabilities returns the driver capabilities.
+func (d *Driver) Capabilities() devices.DriverCapabilities {
+	return devices.DriverCapabilities{
+		SupportsWarnings:   false,
+		SupportsLiveState:  false,
+		SupportsScheduling: true,
+		SupportsBreaks:     true,
+	}
+}
+
+// Validate checks the mac or macs device parameter at startup.
+func (d *Driver) Validate(device *devices.Device) error {
+	_, err := deviceMACs(device)
+	return err
+}
+
+// StartSession unblocks internet access for the device.
+func (d *Driver) StartSession(ctx context.Context, session *core.Session) error {
+	d.logger.Info("Starting router session",
+		"session_id", session.ID,
+		"device_id", session.DeviceID,
+		"duration_minutes", session.ExpectedDuration)
+
+	if err := d.setBlocked(ctx, session.DeviceID, false); err != nil {
+		d.logger.Error("Failed to unblock device on router",
+			"session_id", session.ID,
+			"device_id", session.DeviceID,
+			"error", err)
+		return fmt.Errorf("failed to unblock device: %w", err)
+	}
+	return nil
+}
+
+// StopSession blocks internet access for the device.
+func (d *Driver) StopSession(ctx context.Context, session *core.Session) error {
+	d.logger.Info("Stopping router session",
+		"session_id", session.ID,
+		"device_id", session.DeviceID)
+
+	if err := d.setBlocked(ctx, session.DeviceID, true); err != nil {
+		d.logger.Error("Failed to block device on router",
+			"session_id", session.ID,
+			"device_id", session.DeviceID,
+			"error", err)
+		return fmt.Errorf("failed to block device: %w", err)
+	}
+	return nil
+}
+
+// ApplyWarning is not supported: the router cannot show anything on the device.
+func (d *Driver) ApplyWarning(ctx context.Context, session *core.Session, minutesRemaining int) error {
+	d.logger.Debug("Router warning requested but not supported",
+		"session_id", session.ID,
+		"minutes_remaining", minutesRemaining)
+	return nil
+}
+
+// StartBreak blocks internet access for a mandatory break.
+func (d *Driver) StartBreak(ctx context.Context, session *core.Session, breakMinutes int) error {
+	d.logger.Info("Starting router break",
+		"session_id", session.ID,
+		"device_id", session.DeviceID,
+		"break_minutes", breakMinutes)
+
+	if err := d.setBlocked(ctx, session.DeviceID, true); err != nil {
+		d.logger.Error("Failed to block device for break",
+			"session_id", session.ID,
+			"error", err)
+		return fmt.Errorf("failed to block device: %w", err)
+	}
+	return nil
+}
+
+// EndBreak unblocks internet access when the break is over.
+func (d *Driver) EndBreak(ctx context.Context, session *core.Session) error {
+	d.logger.Info("Ending router break",
+		"session_id", session.ID,
+		"device_id", session.DeviceID)
+
+	if err := d.setBlocked(ctx, session.DeviceID, false); err != nil {
+		d.logger.Error("Failed to unblock device after break",
+			"session_id", session.ID,
+			"error", err)
+		return fmt.Errorf("failed to unblock device: %w", err)
+	}
+	return nil
+}
+
+// GetLiveState is not supported.
+func (d *Driver) GetLiveState(ctx context.Context, deviceID string) (*devices.DeviceState, error) {
+	return nil, nil
+}
+
+// HealthCheck verifies the router is reachable and accepts the credentials.
+func (d *Driver) HealthCheck(ctx context.Context) error {
+	if err := d.firewall.Ping(ctx); err != nil {
+		return fmt.Errorf("router unreachable: %w", err)
+	}
+	return nil
+}
+
+// setBlocked applies the block rule of a device
+func (d *Driver) setBlocked(ctx context.Context, deviceID string, blocked bool) error {
+	device, err := d.deviceRegistry.Get(deviceID)
+	if err != nil {
+		return fmt.Errorf("failed to get device %s: %w", deviceID, err)
+	}
+
+	macs, err := deviceMACs(device)
+	if err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	rule := RuleName(deviceID)
+	if err := d.firewall.SetBlocked(ctx, rule, macs, blocked); err != nil {
+		return err
+	}
+
+	d.logger.Debug("Router rule applied",
+		"device_id", deviceID,
+		"rule", rule,
+		"macs", macs,
+		"blocked", blocked)
+	return nil
+}
+
+// RuleName returns the firewall rule name used for a device.
+// Characters other than letters, digits and underscores are replaced, as uci section names require.
+func RuleName(deviceID string) string {
+	var b strings.Builder
+	b.WriteString(rulePrefix)
+	for _, r := range deviceID {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			b.WriteRune(r)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
+
+// deviceMACs reads the "mac" or "macs" device parameter, normalized to upper-case colon form
+func deviceMACs(device *devices.Device) ([]string, error) {
+	var raw []string
+	if mac, ok := device.GetParameter("mac").(string); ok && mac != "" {
+		raw = append(raw, mac)
+	}
+	switch macs := device.GetParameter("macs").(type) {
+	case []string:
+		raw = append(raw, macs...)
+	case []interface{}:
+		for _, mac := range macs {
+			s, ok := mac.(string)
+			if !ok {
+				return nil, fmt.Errorf("device %s: macs must be a list of strings", device.ID)
+			}
+			raw = append(raw, s)
+		}
+	}
+
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("device %s: mac or macs parameter is required", device.ID)
+	}
+
+	macs := make([]string, 0, len(raw))
+	for _, mac := range raw {
+		hw, err := net.ParseMAC(mac)
+		if err != nil {
+			return nil, fmt.Errorf("device %s: invalid MAC address %q", device.ID, mac)
+		}
+		macs = append(macs, strings.ToUpper(hw.String()))
+	}
+	return macs, nil
+}
+
+// newHTTPClient creates the HTTP client used to talk to the router
+func newHTTPClient(insecureSkipVerify bool) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if insecureSkipVerify {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	return &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: transport,
+	}
+}
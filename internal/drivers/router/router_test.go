@@ -0,0 +1,130 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"metron/internal/core"
+	"metron/internal/devices"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockFirewall records the rule state applied by the driver.
+type mockFirewall struct {
+	blocked map[string]bool
+	macs    map[string][]string
+	failErr error
+}
+
+func newMockFirewall() *mockFirewall {
+	return &mockFirewall{
+		blocked: make(map[string]bool),
+		macs:    make(map[string][]string),
+	}
+}
+
+func (m *mockFirewall) SetBlocked(_ context.Context, rule string, macs []string, blocked bool) error {
+	if m.failErr != nil {
+		return m.failErr
+	}
+	m.blocked[rule] = blocked
+	m.macs[rule] = macs
+	return nil
+}
+
+func (m *mockFirewall) Ping(_ context.Context) error {
+	return m.failErr
+}
+
+func setupTestDriver(t *testing.T, params map[string]interface{}) (*Driver, *mockFirewall) {
+	t.Helper()
+
+	registry := devices.NewRegistry()
+	require.NoError(t, registry.Register(&devices.Device{
+		ID:         "alice-phone",
+		Name:       "Alice's Phone",
+		Type:       "phone",
+		Driver:     DriverName,
+		Parameters: params,
+	}))
+
+	firewall := newMockFirewall()
+	driver, err := NewDriver(Config{Type: TypeOpenWrt, URL: "http://192.168.1.1"}, registry, nil)
+	require.NoError(t, err)
+	driver.firewall = firewall
+	return driver, firewall
+}
+
+func TestNewDriver_UnknownType(t *testing.T) {
+	_, err := NewDriver(Config{Type: "tplink"}, devices.NewRegistry(), nil)
+	assert.Error(t, err)
+}
+
+func TestDriver_SessionTogglesBlock(t *testing.T) {
+	driver, firewall := setupTestDriver(t, map[string]interface{}{"mac": "aa-bb-cc-dd-ee-01"})
+	session := &core.Session{ID: "ses_1", DeviceID: "alice-phone", ExpectedDuration: 30}
+	ctx := context.Background()
+
+	require.NoError(t, driver.StartSession(ctx, session))
+	assert.False(t, firewall.blocked["metron_alice_phone"])
+	assert.Equal(t, []string{"AA:BB:CC:DD:EE:01"}, firewall.macs["metron_alice_phone"])
+
+	require.NoError(t, driver.StartBreak(ctx, session, 10))
+	assert.True(t, firewall.blocked["metron_alice_phone"])
+
+	require.NoError(t, driver.EndBreak(ctx, session))
+	assert.False(t, firewall.blocked["metron_alice_phone"])
+
+	require.NoError(t, driver.StopSession(ctx, session))
+	assert.True(t, firewall.blocked["metron_alice_phone"])
+}
+
+func TestDriver_MultipleMACs(t *testing.T) {
+	// JSON config decodes lists as []interface{}
+	driver, firewall := setupTestDriver(t, map[string]interface{}{
+		"mac":  "AA:BB:CC:DD:EE:01",
+		"macs": []interface{}{"aa:bb:cc:dd:ee:02"},
+	})
+
+	err := driver.StopSession(context.Background(), &core.Session{ID: "ses_1", DeviceID: "alice-phone"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"AA:BB:CC:DD:EE:01", "AA:BB:CC:DD:EE:02"}, firewall.macs["metron_alice_phone"])
+}
+
+func TestDriver_InvalidMACs(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+	}{
+		{"missing", nil},
+		{"invalid", map[string]interface{}{"mac": "not-a-mac"}},
+		{"not strings", map[string]interface{}{"macs": []interface{}{42}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver, firewall := setupTestDriver(t, tt.params)
+			err := driver.StopSession(context.Background(), &core.Session{ID: "ses_1", DeviceID: "alice-phone"})
+			assert.Error(t, err)
+			assert.Empty(t, firewall.blocked)
+		})
+	}
+}
+
+func TestDriver_FirewallError(t *testing.T) {
+	driver, firewall := setupTestDriver(t, map[string]interface{}{"mac": "AA:BB:CC:DD:EE:01"})
+	firewall.failErr = errors.New("connection refused")
+
+	err := driver.StartSession(context.Background(), &core.Session{ID: "ses_1", DeviceID: "alice-phone"})
+	assert.ErrorIs(t, err, firewall.failErr)
+	assert.Error(t, driver.HealthCheck(context.Background()))
+}
+
+func TestRuleName(t *testing.T) {
+	assert.Equal(t, "metron_win_pc1", RuleName("win-pc1"))
+	assert.Equal(t, "metron_tv_1", RuleName("tv.1"))
+	assert.Equal(t, "metron_phone_2", RuleName("phone_2"))
+}
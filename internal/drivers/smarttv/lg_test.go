@@ -0,0 +1,130 @@
+package smarttv
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+// fakeLGTV answers SSAP registration and requests like a webOS TV.
+type fakeLGTV struct {
+	clientKey  string // accepted key; registration with another key is rejected
+	powerState string
+	requests   chan lgMessage
+}
+
+func newFakeLGTV(t *testing.T, clientKey string) (*fakeLGTV, string) {
+	t.Helper()
+
+	fake := &fakeLGTV{clientKey: clientKey, powerState: "Active", requests: make(chan lgMessage, 10)}
+	server := httptest.NewServer(websocket.Handler(func(conn *websocket.Conn) {
+		var register struct {
+			Payload struct {
+				ClientKey string `json:"client-key"`
+			} `json:"payload"`
+		}
+		if err := websocket.JSON.Receive(conn, &register); err != nil {
+			return
+		}
+		if register.Payload.ClientKey != "" && register.Payload.ClientKey != fake.clientKey {
+			websocket.JSON.Send(conn, map[string]string{"type": "error", "id": "register_0", "error": "401 insufficient permissions"})
+			return
+		}
+		if register.Payload.ClientKey == "" {
+			websocket.JSON.Send(conn, map[string]interface{}{"type": "response", "id": "register_0", "payload": map[string]string{"pairingType": "PROMPT"}})
+		}
+		websocket.JSON.Send(conn, map[string]interface{}{"type": "registered", "id": "register_0", "payload": map[string]string{"client-key": fake.clientKey}})
+
+		for {
+			var msg lgMessage
+			if err := websocket.JSON.Receive(conn, &msg); err != nil {
+				return
+			}
+			fake.requests <- msg
+
+			payload := map[string]interface{}{"returnValue": true}
+			if msg.URI == "ssap://com.webos.service.tvpower/power/getPowerState" {
+				payload["state"] = fake.powerState
+			}
+			websocket.JSON.Send(conn, map[string]interface{}{"type": "response", "id": msg.ID, "payload": payload})
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return fake, "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func newTestLGTV(url, clientKey string) *lgTV {
+	tv := newLGTV("127.0.0.1", lgPlainPort, clientKey, "Metron")
+	tv.url = url
+	return tv
+}
+
+func TestNewLGTV(t *testing.T) {
+	assert.Equal(t, "wss://192.168.1.50:3001/", newLGTV("192.168.1.50", 0, "", "Metron").url)
+	assert.Equal(t, "ws://192.168.1.50:3000/", newLGTV("192.168.1.50", lgPlainPort, "", "Metron").url)
+}
+
+func TestLGTV_PowerOffAndToast(t *testing.T) {
+	fake, url := newFakeLGTV(t, "lg-key")
+	tv := newTestLGTV(url, "lg-key")
+	ctx := context.Background()
+
+	require.NoError(t, tv.PowerOff(ctx))
+	assert.Equal(t, "ssap://system/turnOff", (<-fake.requests).URI)
+
+	require.NoError(t, tv.ShowToast(ctx, "5 min left"))
+	toast := <-fake.requests
+	assert.Equal(t, "ssap://system.notifications/createToast", toast.URI)
+	var payload map[string]string
+	require.NoError(t, json.Unmarshal(toast.Payload, &payload))
+	assert.Equal(t, "5 min left", payload["message"])
+}
+
+func TestLGTV_IsOn(t *testing.T) {
+	fake, url := newFakeLGTV(t, "lg-key")
+	tv := newTestLGTV(url, "lg-key")
+	ctx := context.Background()
+
+	on, err := tv.IsOn(ctx)
+	require.NoError(t, err)
+	assert.True(t, on)
+
+	fake.powerState = "Active Standby"
+	on, err = tv.IsOn(ctx)
+	require.NoError(t, err)
+	assert.False(t, on)
+}
+
+func TestLGTV_RequiresClientKey(t *testing.T) {
+	_, url := newFakeLGTV(t, "lg-key")
+	tv := newTestLGTV(url, "")
+
+	err := tv.PowerOff(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "client_key")
+}
+
+func TestLGTV_RejectedClientKey(t *testing.T) {
+	_, url := newFakeLGTV(t, "lg-key")
+	tv := newTestLGTV(url, "stale-key")
+
+	err := tv.ShowToast(context.Background(), "hello")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "rejected registration")
+}
+
+func TestLGTV_Pair(t *testing.T) {
+	_, url := newFakeLGTV(t, "lg-key")
+	tv := newTestLGTV(url, "")
+
+	key, err := tv.pair(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "lg-key", key)
+}
@@ -0,0 +1,116 @@
+package smarttv
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+)
+
+// fakeSamsungTV serves the remote control channel and device info of a Tizen TV.
+type fakeSamsungTV struct {
+	powerState string
+	connectMsg map[string]interface{}
+	query      chan string
+	keys       chan string
+}
+
+func newFakeSamsungTV(t *testing.T) (*fakeSamsungTV, *samsungTV) {
+	t.Helper()
+
+	fake := &fakeSamsungTV{
+		powerState: "on",
+		connectMsg: map[string]interface{}{"event": "ms.channel.connect", "data": map[string]string{"token": "12345678"}},
+		query:      make(chan string, 1),
+		keys:       make(chan string, 1),
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/api/v2/channels/samsung.remote.control", websocket.Handler(func(conn *websocket.Conn) {
+		fake.query <- conn.Request().URL.RawQuery
+		if err := websocket.JSON.Send(conn, fake.connectMsg); err != nil {
+			return
+		}
+		var cmd struct {
+			Params struct {
+				DataOfCmd string `json:"DataOfCmd"`
+			} `json:"params"`
+		}
+		if err := websocket.JSON.Receive(conn, &cmd); err == nil {
+			fake.keys <- cmd.Params.DataOfCmd
+		}
+	}))
+	mux.HandleFunc("/api/v2/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"device": {"PowerState": "` + fake.powerState + `"}}`))
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	tv := newSamsungTV("127.0.0.1", samsungPlainPort, "12345678", "Metron")
+	tv.wsURL = strings.Replace(tv.wsURL, "127.0.0.1:8001", host, 1)
+	tv.infoURL = strings.Replace(tv.infoURL, "127.0.0.1:8001", host, 1)
+	return fake, tv
+}
+
+func TestNewSamsungTV(t *testing.T) {
+	tv := newSamsungTV("192.168.1.51", 0, "12345678", "Metron")
+	assert.Equal(t, "wss://192.168.1.51:8002/api/v2/channels/samsung.remote.control?name=TWV0cm9u&token=12345678", tv.wsURL)
+	assert.Equal(t, "http://192.168.1.51:8001/api/v2/", tv.infoURL)
+}
+
+func TestSamsungTV_PowerOff(t *testing.T) {
+	fake, tv := newFakeSamsungTV(t)
+
+	require.NoError(t, tv.PowerOff(context.Background()))
+	assert.Equal(t, "name=TWV0cm9u&token=12345678", <-fake.query)
+	assert.Equal(t, "KEY_POWER", <-fake.keys)
+}
+
+func TestSamsungTV_Unauthorized(t *testing.T) {
+	fake, tv := newFakeSamsungTV(t)
+	fake.connectMsg = map[string]interface{}{"event": "ms.channel.unauthorized"}
+
+	err := tv.PowerOff(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tv-pair")
+}
+
+func TestSamsungTV_IsOn(t *testing.T) {
+	fake, tv := newFakeSamsungTV(t)
+	ctx := context.Background()
+
+	on, err := tv.IsOn(ctx)
+	require.NoError(t, err)
+	assert.True(t, on)
+
+	fake.powerState = "standby"
+	on, err = tv.IsOn(ctx)
+	require.NoError(t, err)
+	assert.False(t, on)
+
+	// Unreachable TVs are off
+	tv.infoURL = "http://127.0.0.1:1/api/v2/"
+	on, err = tv.IsOn(ctx)
+	require.NoError(t, err)
+	assert.False(t, on)
+}
+
+func TestSamsungTV_Pair(t *testing.T) {
+	_, tv := newFakeSamsungTV(t)
+
+	token, err := tv.pair(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "12345678", token)
+}
+
+func TestSamsungTV_ShowToastNotSupported(t *testing.T) {
+	tv := newSamsungTV("192.168.1.51", 0, "", "Metron")
+	assert.ErrorIs(t, tv.ShowToast(context.Background(), "hello"), ErrNotSupported)
+}
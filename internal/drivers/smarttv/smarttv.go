@@ -0,0 +1,363 @@
+// Package smarttv provides a device driver that controls Samsung (Tizen) and LG (webOS)
+// smart TVs over the local network. The TV is turned off when a session ends or a break
+// starts, LG TVs show on-screen warning toasts, and TVs with the lock parameter are turned
+// off again whenever they are switched on outside a session.
+package smarttv
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"metron/internal/core"
+	"metron/internal/devices"
+)
+
+const DriverName = "smarttv"
+
+// TV brands
+const (
+	BrandSamsung = "samsung"
+	BrandLG      = "lg"
+)
+
+// DefaultClientName is shown on the TV when Metron asks to be paired
+const DefaultClientName = "Metron"
+
+// ErrNotSupported is returned by TV methods the TV cannot perform.
+var ErrNotSupported = errors.New("not supported by this TV")
+
+// TV controls one smart TV.
+type TV interface {
+	// PowerOff turns the TV off (standby).
+	PowerOff(ctx context.Context) error
+
+	// IsOn reports whether the TV is on. A TV that does not answer is off.
+	IsOn(ctx context.Context) (bool, error)
+
+	// ShowToast shows a short notification on screen, or returns ErrNotSupported.
+	ShowToast(ctx context.Context, message string) error
+}
+
+// Config contains smart TV driver configuration.
+type Config struct {
+	ClientName string // Name shown on the TV pairing prompt (default "Metron")
+}
+
+// deviceConfig holds the connection settings of a TV from its device parameters
+type deviceConfig struct {
+	Brand string // BrandSamsung or BrandLG
+	Host  string // TV address on the local network
+	Port  int    // 0 uses the brand default
+	Key   string // Samsung token or LG client key from pairing
+	Lock  bool   // Turn the TV off again when switched on outside a session
+}
+
+// Driver implements the DeviceDriver interface by controlling TVs over the local network.
+type Driver struct {
+	config         Config
+	deviceRegistry *devices.Registry
+	logger         *slog.Logger
+	newTV          func(cfg *deviceConfig, clientName string) (TV, error)
+
+	mu     sync.Mutex
+	locked map[string]bool // Device IDs locked outside a session
+}
+
+// NewDriver creates a new smart TV driver.
+func NewDriver(config Config, deviceRegistry *devices.Registry, logger *slog.Logger) *Driver {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if config.ClientName == "" {
+		config.ClientName = DefaultClientName
+	}
+	return &Driver{
+		config:         config,
+		deviceRegistry: deviceRegistry,
+		logger:         logger.With("driver", DriverName),
+		newTV:          newTV,
+		locked:         make(map[string]bool),
+	}
+}
+
+// Name returns the driver name.
+func (d *Driver) Name() string {
+	return DriverName
+}
+
+// Capabilities returns the driver capabilities.
+func (d *Driver) Capabilities() devices.DriverCapabilities {
+	return devices.DriverCapabilities{
+		SupportsWarnings:   true, // LG only; Samsung TVs have no toast API
+		SupportsLiveState:  false,
+		SupportsScheduling: true,
+		SupportsBreaks:     true,
+	}
+}
+
+// Validate checks the device parameters at startup; the LG client key may still be missing before pairing.
+func (d *Driver) Validate(device *devices.Device) error {
+	_, err := readDeviceConfig(device)
+	return err
+}
+
+// StartSession unlocks the TV. The TV cannot be turned on over the network,
+// so the child switches it on with the remote.
+func (d *Driver) StartSession(ctx context.Context, session *core.Session) error {
+	d.logger.Info("Starting smart TV session",
+		"session_id", session.ID,
+		"device_id", session.DeviceID,
+		"duration_minutes", session.ExpectedDuration)
+
+	if _, err := d.getDeviceConfig(session.DeviceID); err != nil {
+		return err
+	}
+	d.setLocked(session.DeviceID, false)
+	return nil
+}
+
+// StopSession turns the TV off and locks it if the device has the lock parameter.
+func (d *Driver) StopSession(ctx context.Context, session *core.Session) error {
+	d.logger.Info("Stopping smart TV session",
+		"session_id", session.ID,
+		"device_id", session.DeviceID)
+
+	if err := d.turnOff(ctx, session.DeviceID); err != nil {
+		d.logger.Error("Failed to turn TV off",
+			"session_id", session.ID,
+			"device_id", session.DeviceID,
+			"error", err)
+		return fmt.Errorf("failed to turn TV off: %w", err)
+	}
+	return nil
+}
+
+// ApplyWarning shows a toast with the remaining minutes.
+// TVs without a toast API (Samsung) are skipped.
+func (d *Driver) ApplyWarning(ctx context.Context, session *core.Session, minutesRemaining int) error {
+	tv, _, err := d.getTV(session.DeviceID)
+	if err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("⏱ %d min of TV time remaining", minutesRemaining)
+	err = tv.ShowToast(ctx, message)
+	if errors.Is(err, ErrNotSupported) {
+		d.logger.Debug("TV warning requested but not supported",
+			"session_id", session.ID,
+			"minutes_remaining", minutesRemaining)
+		return nil
+	}
+	if err != nil {
+		d.logger.Error("Failed to show TV warning",
+			"session_id", session.ID,
+			"device_id", session.DeviceID,
+			"error", err)
+		return fmt.Errorf("failed to show warning: %w", err)
+	}
+
+	d.logger.Info("TV warning shown",
+		"session_id", session.ID,
+		"minutes_remaining", minutesRemaining)
+	return nil
+}
+
+// StartBreak turns the TV off for a mandatory break.
+func (d *Driver) StartBreak(ctx context.Context, session *core.Session, breakMinutes int) error {
+	d.logger.Info("Starting smart TV break",
+		"session_id", session.ID,
+		"device_id", session.DeviceID,
+		"break_minutes", breakMinutes)
+
+	if err := d.turnOff(ctx, session.DeviceID); err != nil {
+		d.logger.Error("Failed to turn TV off for break",
+			"session_id", session.ID,
+			"error", err)
+		return fmt.Errorf("failed to turn TV off: %w", err)
+	}
+	return nil
+}
+
+// EndBreak unlocks the TV when the break is over.
+func (d *Driver) EndBreak(ctx context.Context, session *core.Session) error {
+	d.logger.Info("Ending smart TV break",
+		"session_id", session.ID,
+		"device_id", session.DeviceID)
+
+	d.setLocked(session.DeviceID, false)
+	return nil
+}
+
+// GetLiveState is not supported.
+func (d *Driver) GetLiveState(ctx context.Context, deviceID string) (*devices.DeviceState, error) {
+	return nil, nil
+}
+
+// RunLock turns locked TVs off again every interval until ctx is cancelled.
+// A TV is locked when a session stops or a break starts, until the next session or break end.
+// Locks are kept in memory, so TVs are not locked again after a restart until a session ends.
+func (d *Driver) RunLock(ctx context.Context, interval time.Duration) {
+	d.logger.Info("Smart TV lock enforcement started", "interval", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			d.EnforceLocks(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// EnforceLocks turns off every locked TV that is on.
+func (d *Driver) EnforceLocks(ctx context.Context) {
+	for _, deviceID := range d.lockedDevices() {
+		tv, _, err := d.getTV(deviceID)
+		if err != nil {
+			d.logger.Error("Failed to get locked TV", "device_id", deviceID, "error", err)
+			continue
+		}
+
+		on, err := tv.IsOn(ctx)
+		if err != nil {
+			d.logger.Error("Failed to check locked TV", "device_id", deviceID, "error", err)
+			continue
+		}
+		if !on {
+			continue
+		}
+
+		d.logger.Warn("Locked TV switched on outside a session, turning it off", "device_id", deviceID)
+		if err := tv.PowerOff(ctx); err != nil {
+			d.logger.Error("Failed to turn locked TV off", "device_id", deviceID, "error", err)
+		}
+	}
+}
+
+// turnOff turns the device's TV off if it is on and locks it if configured
+func (d *Driver) turnOff(ctx context.Context, deviceID string) error {
+	tv, cfg, err := d.getTV(deviceID)
+	if err != nil {
+		return err
+	}
+	if cfg.Lock {
+		d.setLocked(deviceID, true)
+	}
+
+	// Samsung's power key toggles, so a TV that is already off must not get it
+	on, err := tv.IsOn(ctx)
+	if err != nil {
+		return err
+	}
+	if !on {
+		d.logger.Debug("TV already off", "device_id", deviceID)
+		return nil
+	}
+	return tv.PowerOff(ctx)
+}
+
+func (d *Driver) setLocked(deviceID string, locked bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if locked {
+		d.locked[deviceID] = true
+	} else {
+		delete(d.locked, deviceID)
+	}
+}
+
+func (d *Driver) lockedDevices() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	ids := make([]string, 0, len(d.locked))
+	for id := range d.locked {
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// getTV creates the TV client of a device
+func (d *Driver) getTV(deviceID string) (TV, *deviceConfig, error) {
+	cfg, err := d.getDeviceConfig(deviceID)
+	if err != nil {
+		return nil, nil, err
+	}
+	tv, err := d.newTV(cfg, d.config.ClientName)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tv, cfg, nil
+}
+
+// getDeviceConfig looks up the device and reads its parameters
+func (d *Driver) getDeviceConfig(deviceID string) (*deviceConfig, error) {
+	device, err := d.deviceRegistry.Get(deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device %s: %w", deviceID, err)
+	}
+	return readDeviceConfig(device)
+}
+
+// readDeviceConfig reads the TV connection settings from the device parameters
+func readDeviceConfig(device *devices.Device) (*deviceConfig, error) {
+	cfg := &deviceConfig{}
+	cfg.Brand, _ = device.GetParameter("brand").(string)
+	cfg.Host, _ = device.GetParameter("host").(string)
+	cfg.Lock, _ = device.GetParameter("lock").(bool)
+	switch port := device.GetParameter("port").(type) {
+	case float64:
+		cfg.Port = int(port)
+	case int:
+		cfg.Port = port
+	}
+	if cfg.Brand == BrandLG {
+		cfg.Key, _ = device.GetParameter("client_key").(string)
+	} else {
+		cfg.Key, _ = device.GetParameter("token").(string)
+	}
+
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("device %s: host parameter is required", device.ID)
+	}
+	if cfg.Brand != BrandSamsung && cfg.Brand != BrandLG {
+		return nil, fmt.Errorf("device %s: brand must be %q or %q", device.ID, BrandSamsung, BrandLG)
+	}
+	return cfg, nil
+}
+
+// newTV creates the client for the TV brand
+func newTV(cfg *deviceConfig, clientName string) (TV, error) {
+	switch cfg.Brand {
+	case BrandSamsung:
+		return newSamsungTV(cfg.Host, cfg.Port, cfg.Key, clientName), nil
+	case BrandLG:
+		return newLGTV(cfg.Host, cfg.Port, cfg.Key, clientName), nil
+	default:
+		return nil, fmt.Errorf("unknown TV brand %q", cfg.Brand)
+	}
+}
+
+// Pair asks the TV for a Samsung token or LG client key. The TV shows a prompt that
+// must be accepted with the remote before ctx expires.
+func Pair(ctx context.Context, brand, host string, port int, clientName string) (string, error) {
+	if clientName == "" {
+		clientName = DefaultClientName
+	}
+	switch brand {
+	case BrandSamsung:
+		return newSamsungTV(host, port, "", clientName).pair(ctx)
+	case BrandLG:
+		return newLGTV(host, port, "", clientName).pair(ctx)
+	default:
+		return "", fmt.Errorf("unknown TV brand %q (use %s or %s)", brand, BrandSamsung, BrandLG)
+	}
+}
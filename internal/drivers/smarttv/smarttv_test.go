@@ -0,0 +1,150 @@
+package smarttv
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"metron/internal/core"
+	"metron/internal/devices"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockTV records power changes and toasts.
+type mockTV struct {
+	on       bool
+	powerOff int
+	toasts   []string
+	toastErr error
+}
+
+func (m *mockTV) PowerOff(_ context.Context) error {
+	m.on = false
+	m.powerOff++
+	return nil
+}
+
+func (m *mockTV) IsOn(_ context.Context) (bool, error) {
+	return m.on, nil
+}
+
+func (m *mockTV) ShowToast(_ context.Context, message string) error {
+	if m.toastErr != nil {
+		return m.toastErr
+	}
+	m.toasts = append(m.toasts, message)
+	return nil
+}
+
+func setupTestDriver(t *testing.T, params map[string]interface{}) (*Driver, *mockTV) {
+	t.Helper()
+
+	registry := devices.NewRegistry()
+	require.NoError(t, registry.Register(&devices.Device{
+		ID:         "living-tv",
+		Name:       "Living Room TV",
+		Type:       "tv",
+		Driver:     DriverName,
+		Parameters: params,
+	}))
+
+	tv := &mockTV{on: true}
+	driver := NewDriver(Config{}, registry, nil)
+	driver.newTV = func(cfg *deviceConfig, clientName string) (TV, error) {
+		return tv, nil
+	}
+	return driver, tv
+}
+
+func TestDriver_StopSessionTurnsOff(t *testing.T) {
+	driver, tv := setupTestDriver(t, map[string]interface{}{"brand": BrandLG, "host": "192.168.1.50", "client_key": "key"})
+	session := &core.Session{ID: "ses_1", DeviceID: "living-tv"}
+	ctx := context.Background()
+
+	require.NoError(t, driver.StopSession(ctx, session))
+	assert.Equal(t, 1, tv.powerOff)
+
+	// A TV that is already off does not get the (toggling) power command
+	require.NoError(t, driver.StopSession(ctx, session))
+	assert.Equal(t, 1, tv.powerOff)
+
+	// Without the lock parameter the TV is not locked
+	assert.Empty(t, driver.lockedDevices())
+}
+
+func TestDriver_ApplyWarning(t *testing.T) {
+	driver, tv := setupTestDriver(t, map[string]interface{}{"brand": BrandLG, "host": "192.168.1.50", "client_key": "key"})
+	session := &core.Session{ID: "ses_1", DeviceID: "living-tv"}
+
+	require.NoError(t, driver.ApplyWarning(context.Background(), session, 5))
+	assert.Equal(t, []string{"⏱ 5 min of TV time remaining"}, tv.toasts)
+
+	// TVs without toasts are skipped
+	tv.toastErr = ErrNotSupported
+	assert.NoError(t, driver.ApplyWarning(context.Background(), session, 1))
+
+	tv.toastErr = errors.New("connection refused")
+	assert.Error(t, driver.ApplyWarning(context.Background(), session, 1))
+}
+
+func TestDriver_Lock(t *testing.T) {
+	driver, tv := setupTestDriver(t, map[string]interface{}{"brand": BrandSamsung, "host": "192.168.1.51", "lock": true})
+	session := &core.Session{ID: "ses_1", DeviceID: "living-tv"}
+	ctx := context.Background()
+
+	require.NoError(t, driver.StopSession(ctx, session))
+	assert.Equal(t, []string{"living-tv"}, driver.lockedDevices())
+
+	// Switched on outside a session: turned off again
+	tv.on = true
+	driver.EnforceLocks(ctx)
+	assert.False(t, tv.on)
+	assert.Equal(t, 2, tv.powerOff)
+
+	// A new session unlocks the TV
+	require.NoError(t, driver.StartSession(ctx, session))
+	tv.on = true
+	driver.EnforceLocks(ctx)
+	assert.True(t, tv.on)
+
+	// Breaks lock it until they end
+	require.NoError(t, driver.StartBreak(ctx, session, 10))
+	assert.Equal(t, []string{"living-tv"}, driver.lockedDevices())
+	require.NoError(t, driver.EndBreak(ctx, session))
+	assert.Empty(t, driver.lockedDevices())
+}
+
+func TestDriver_InvalidParameters(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+	}{
+		{"missing host", map[string]interface{}{"brand": BrandLG}},
+		{"unknown brand", map[string]interface{}{"brand": "sony", "host": "192.168.1.52"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			driver, _ := setupTestDriver(t, tt.params)
+			err := driver.StartSession(context.Background(), &core.Session{ID: "ses_1", DeviceID: "living-tv"})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestGetDeviceConfig(t *testing.T) {
+	driver, _ := setupTestDriver(t, map[string]interface{}{
+		"brand":      BrandLG,
+		"host":       "192.168.1.50",
+		"port":       float64(3000), // JSON numbers decode as float64
+		"client_key": "lg-key",
+		"token":      "ignored",
+		"lock":       true,
+	})
+
+	cfg, err := driver.getDeviceConfig("living-tv")
+	require.NoError(t, err)
+	assert.Equal(t, &deviceConfig{Brand: BrandLG, Host: "192.168.1.50", Port: 3000, Key: "lg-key", Lock: true}, cfg)
+}
@@ -0,0 +1,40 @@
+package smarttv
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// defaultTimeout bounds TV calls whose context has no deadline
+const defaultTimeout = 10 * time.Second
+
+// dialWebSocket connects to a TV WebSocket. TVs use self-signed certificates,
+// so they are not verified. The connection deadline follows ctx.
+func dialWebSocket(ctx context.Context, url string) (*websocket.Conn, error) {
+	config, err := websocket.NewConfig(url, "http://localhost")
+	if err != nil {
+		return nil, fmt.Errorf("invalid TV address: %w", err)
+	}
+	config.TlsConfig = &tls.Config{InsecureSkipVerify: true}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultTimeout)
+	}
+	dialCtx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	conn, err := config.DialContext(dialCtx)
+	if err != nil {
+		return nil, err
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
@@ -0,0 +1,631 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+)
+
+// DefaultListSize is the number of items assumed for list fields without a "limit" argument
+// when estimating the complexity of a query
+const DefaultListSize = 10
+
+// Request is a GraphQL request
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+	Extensions    map[string]any `json:"extensions,omitempty"` // Accepted from clients that send it, and ignored
+}
+
+// Limits bound the cost of a query; zero disables a limit
+type Limits struct {
+	MaxDepth      int // Deepest nesting of fields (fields of the Query type are at depth 1)
+	MaxComplexity int // Highest estimated number of resolved fields (see Field.Complexity)
+}
+
+// Location is a position in the query
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Error is a syntax, validation or field error
+type Error struct {
+	Message   string     `json:"message"`
+	Locations []Location `json:"locations,omitempty"`
+	Path      []any      `json:"path,omitempty"` // Response keys and list indexes of the failed field
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// Response is the result of a request
+// Data is null when a non-nullable field failed up to the root and absent when the request
+// failed before execution (syntax errors, validation errors and exceeded limits).
+type Response struct {
+	Data     any
+	Errors   []*Error
+	executed bool
+}
+
+// MarshalJSON encodes the response as {"data": ..., "errors": [...]}
+func (r *Response) MarshalJSON() ([]byte, error) {
+	out := struct {
+		Data   *json.RawMessage `json:"data,omitempty"`
+		Errors []*Error         `json:"errors,omitempty"`
+	}{Errors: r.Errors}
+	if r.executed {
+		data, err := json.Marshal(r.Data)
+		if err != nil {
+			return nil, err
+		}
+		raw := json.RawMessage(data)
+		out.Data = &raw
+	}
+	return json.Marshal(out)
+}
+
+// Execute validates and runs a query
+// Mutations and subscriptions are rejected. Fields are resolved one after another.
+func Execute(ctx context.Context, schema *Schema, req Request, limits Limits) *Response {
+	doc, err := parse(req.Query)
+	if err != nil {
+		return failed(err)
+	}
+	op, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return failed(err)
+	}
+	if op.kind != "query" {
+		return failed(fmt.Errorf("%s operations are not supported: the API is read-only", op.kind))
+	}
+
+	e := &executor{schema: schema, fragments: doc.fragments}
+	if err := e.coerceVariables(op.variables, req.Variables); err != nil {
+		return failed(err)
+	}
+	fields, err := e.plan(schema.Query, op.selection)
+	if err != nil {
+		return failed(err)
+	}
+
+	if depth := planDepth(fields); limits.MaxDepth > 0 && depth > limits.MaxDepth {
+		return failed(fmt.Errorf("query depth %d exceeds the limit of %d", depth, limits.MaxDepth))
+	}
+	if complexity := planComplexity(fields); limits.MaxComplexity > 0 && complexity > limits.MaxComplexity {
+		return failed(fmt.Errorf("query complexity %d exceeds the limit of %d", complexity, limits.MaxComplexity))
+	}
+
+	response := &Response{executed: true}
+	if data, ok := e.executeFields(ctx, schema.Query, nil, fields, nil); ok {
+		response.Data = data
+	}
+	response.Errors = e.errors
+	return response
+}
+
+// failed returns the response of a request that could not be executed
+func failed(err error) *Response {
+	gqlErr, ok := err.(*Error)
+	if !ok {
+		gqlErr = &Error{Message: err.Error()}
+	}
+	return &Response{Errors: []*Error{gqlErr}}
+}
+
+func selectOperation(doc *document, name string) (*operation, error) {
+	if name == "" {
+		if len(doc.operations) > 1 {
+			return nil, fmt.Errorf("operationName is required for documents with several operations")
+		}
+		return doc.operations[0], nil
+	}
+	for _, op := range doc.operations {
+		if op.name == name {
+			return op, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown operation %q", name)
+}
+
+type executor struct {
+	schema    *Schema
+	fragments map[string]*fragment
+	declared  map[string]bool // Variables defined by the operation
+	variables map[string]any  // Values of the variables that were provided or have defaults
+	errors    []*Error
+}
+
+// plannedField is a field of the response, with the query fields merged under its key
+type plannedField struct {
+	key      string
+	field    *Field // Nil for __typename
+	nodes    []*field
+	args     map[string]any
+	children []*plannedField // Subfields of object fields
+}
+
+func (e *executor) coerceVariables(definitions []*variableDefinition, provided map[string]any) error {
+	e.declared = make(map[string]bool, len(definitions))
+	e.variables = make(map[string]any, len(definitions))
+	for _, def := range definitions {
+		if e.declared[def.name] {
+			return fmt.Errorf("there can be only one variable named $%s", def.name)
+		}
+		e.declared[def.name] = true
+
+		t, err := e.inputType(def.typ)
+		if err != nil {
+			return fmt.Errorf("variable $%s: %w", def.name, err)
+		}
+		raw, ok := provided[def.name]
+		if !ok && def.defaultValue != nil {
+			if raw, _, err = literalValue(def.defaultValue, nil); err != nil {
+				return fmt.Errorf("variable $%s: %w", def.name, err)
+			}
+			ok = true
+		}
+		if !ok {
+			if def.typ.nonNull {
+				return fmt.Errorf("variable $%s of required type %s was not provided", def.name, def.typ)
+			}
+			continue
+		}
+		// Checked against the variable's type here, coerced to the argument's type where it is used
+		if _, err := coerceInput(t, raw); err != nil {
+			return fmt.Errorf("variable $%s: %w", def.name, err)
+		}
+		e.variables[def.name] = raw
+	}
+	return nil
+}
+
+// inputType returns the schema type of a variable
+func (e *executor) inputType(ref typeRef) (Type, error) {
+	var t Type
+	if ref.of != nil {
+		of, err := e.inputType(*ref.of)
+		if err != nil {
+			return nil, err
+		}
+		t = List{Of: of}
+	} else {
+		named, ok := e.schema.types[ref.name]
+		if scalar, builtin := builtinScalars[ref.name]; !ok && builtin {
+			named, ok = scalar, true
+		}
+		if !ok {
+			return nil, fmt.Errorf("unknown type %s", ref.name)
+		}
+		if _, scalar := named.(*Scalar); !scalar {
+			return nil, fmt.Errorf("%s is not an input type", ref.name)
+		}
+		t = named
+	}
+	if ref.nonNull {
+		t = NonNull{Of: t}
+	}
+	return t, nil
+}
+
+// plan validates the selection of an object's fields and merges it by response key
+func (e *executor) plan(object *Object, selections []selection) ([]*plannedField, error) {
+	var fields []*plannedField
+	if err := e.collectFields(object, selections, make(map[string]bool), &fields); err != nil {
+		return nil, err
+	}
+	for _, f := range fields {
+		if f.field == nil {
+			continue
+		}
+		child, ok := namedType(f.field.Type).(*Object)
+		if !ok {
+			continue
+		}
+		var selections []selection
+		for _, node := range f.nodes {
+			selections = append(selections, node.selection...)
+		}
+		children, err := e.plan(child, selections)
+		if err != nil {
+			return nil, err
+		}
+		f.children = children
+	}
+	return fields, nil
+}
+
+func (e *executor) collectFields(object *Object, selections []selection, spreading map[string]bool, fields *[]*plannedField) error {
+	for _, sel := range selections {
+		include, err := e.included(sel.directiveList())
+		if err != nil {
+			return locate(err, sel)
+		}
+		if !include {
+			continue
+		}
+
+		switch sel := sel.(type) {
+		case *field:
+			planned, err := e.planField(object, sel)
+			if err != nil {
+				return locate(err, sel)
+			}
+			if err := mergeField(fields, planned); err != nil {
+				return locate(err, sel)
+			}
+		case *fragmentSpread:
+			frag, ok := e.fragments[sel.name]
+			if !ok {
+				return fmt.Errorf("unknown fragment %q", sel.name)
+			}
+			if spreading[sel.name] {
+				return fmt.Errorf("fragment %q spreads itself", sel.name)
+			}
+			if err := e.checkTypeCondition(object, frag.typeCondition); err != nil {
+				return fmt.Errorf("fragment %q: %w", sel.name, err)
+			}
+			spreading[sel.name] = true
+			if err := e.collectFields(object, frag.selection, spreading, fields); err != nil {
+				return err
+			}
+			delete(spreading, sel.name)
+		case *inlineFragment:
+			if sel.typeCondition != "" {
+				if err := e.checkTypeCondition(object, sel.typeCondition); err != nil {
+					return err
+				}
+			}
+			if err := e.collectFields(object, sel.selection, spreading, fields); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkTypeCondition checks a fragment applies to the object
+// Without interfaces and unions, a fragment on any other type could never match.
+func (e *executor) checkTypeCondition(object *Object, typeCondition string) error {
+	if typeCondition == object.Name {
+		return nil
+	}
+	if _, ok := e.schema.types[typeCondition]; !ok {
+		return fmt.Errorf("unknown type %s", typeCondition)
+	}
+	return fmt.Errorf("a fragment on %s cannot be spread within %s", typeCondition, object.Name)
+}
+
+func (e *executor) planField(object *Object, node *field) (*plannedField, error) {
+	if node.name == "__typename" {
+		if len(node.arguments) > 0 || node.selection != nil {
+			return nil, fmt.Errorf("__typename takes no arguments or subfields")
+		}
+		return &plannedField{key: node.responseKey(), nodes: []*field{node}}, nil
+	}
+
+	def, ok := object.byName[node.name]
+	if !ok {
+		if node.name == "__schema" || node.name == "__type" {
+			return nil, fmt.Errorf("introspection is not supported: use the schema SDL instead")
+		}
+		return nil, fmt.Errorf("cannot query field %q on type %s", node.name, object.Name)
+	}
+
+	_, isObject := namedType(def.Type).(*Object)
+	if isObject && node.selection == nil {
+		return nil, fmt.Errorf("field %q of type %s must have a selection of subfields", node.name, def.Type)
+	}
+	if !isObject && node.selection != nil {
+		return nil, fmt.Errorf("field %q of type %s cannot have a selection of subfields", node.name, def.Type)
+	}
+
+	args, err := e.arguments(def.Args, node.arguments, "field "+object.Name+"."+def.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &plannedField{key: node.responseKey(), field: def, nodes: []*field{node}, args: args}, nil
+}
+
+// mergeField adds a field to the selection, or merges it with the field of the same response key
+func mergeField(fields *[]*plannedField, planned *plannedField) error {
+	for _, existing := range *fields {
+		if existing.key != planned.key {
+			continue
+		}
+		if existing.field != planned.field || !reflect.DeepEqual(existing.args, planned.args) {
+			return fmt.Errorf("fields %q conflict: they select different fields or arguments", planned.key)
+		}
+		existing.nodes = append(existing.nodes, planned.nodes...)
+		return nil
+	}
+	*fields = append(*fields, planned)
+	return nil
+}
+
+// conditionArgs are the arguments of @skip and @include
+var conditionArgs = []*Argument{{Name: "if", Type: NonNull{Of: Bool}}}
+
+// included evaluates the @skip and @include directives of a selection
+func (e *executor) included(directives []*directive) (bool, error) {
+	for _, d := range directives {
+		if d.name != "skip" && d.name != "include" {
+			return false, fmt.Errorf("unknown directive @%s", d.name)
+		}
+		args, err := e.arguments(conditionArgs, d.arguments, "directive @"+d.name)
+		if err != nil {
+			return false, err
+		}
+		if args["if"].(bool) == (d.name == "skip") {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// arguments coerces the arguments given to a field or directive
+func (e *executor) arguments(definitions []*Argument, given []*argument, owner string) (map[string]any, error) {
+	byName := make(map[string]*argument, len(given))
+	for _, arg := range given {
+		if _, ok := byName[arg.name]; ok {
+			return nil, fmt.Errorf("there can be only one argument named %q", arg.name)
+		}
+		byName[arg.name] = arg
+		if !hasArgument(definitions, arg.name) {
+			return nil, fmt.Errorf("unknown argument %q on %s", arg.name, owner)
+		}
+		if err := e.checkVariables(arg.value); err != nil {
+			return nil, err
+		}
+	}
+
+	args := make(map[string]any, len(definitions))
+	for _, def := range definitions {
+		var raw any
+		defined := false
+		if arg, ok := byName[def.Name]; ok {
+			var err error
+			if raw, defined, err = literalValue(arg.value, e.variables); err != nil {
+				return nil, fmt.Errorf("argument %q on %s: %w", def.Name, owner, err)
+			}
+		}
+		if !defined {
+			if def.Default == nil {
+				if _, required := def.Type.(NonNull); required {
+					return nil, fmt.Errorf("argument %q of type %s is required on %s", def.Name, def.Type, owner)
+				}
+				continue
+			}
+			raw = def.Default
+		}
+		value, err := coerceInput(def.Type, raw)
+		if err != nil {
+			return nil, fmt.Errorf("argument %q on %s: %w", def.Name, owner, err)
+		}
+		args[def.Name] = value
+	}
+	return args, nil
+}
+
+func hasArgument(definitions []*Argument, name string) bool {
+	for _, def := range definitions {
+		if def.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// checkVariables checks the variables used in a value are defined by the operation
+func (e *executor) checkVariables(v value) error {
+	switch v := v.(type) {
+	case variableValue:
+		if !e.declared[string(v)] {
+			return fmt.Errorf("variable $%s is not defined", string(v))
+		}
+	case listValue:
+		for _, item := range v {
+			if err := e.checkVariables(item); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// locate adds the location of a field to an error
+func locate(err error, sel selection) error {
+	f, ok := sel.(*field)
+	if _, isGraphQL := err.(*Error); isGraphQL || !ok {
+		return err
+	}
+	return &Error{Message: err.Error(), Locations: []Location{{Line: f.line, Column: f.column}}}
+}
+
+func planDepth(fields []*plannedField) int {
+	depth := 0
+	for _, f := range fields {
+		depth = max(depth, 1+planDepth(f.children))
+	}
+	return depth
+}
+
+func planComplexity(fields []*plannedField) int {
+	total := 0
+	for _, f := range fields {
+		if f.field == nil {
+			continue // __typename
+		}
+		children := planComplexity(f.children)
+		switch {
+		case f.field.Complexity != nil:
+			total += f.field.Complexity(f.args, children)
+		case isList(f.field.Type):
+			size := DefaultListSize
+			if limit, ok := f.args["limit"].(int); ok {
+				size = max(limit, 1)
+			}
+			total += 1 + size*children
+		default:
+			total += 1 + children
+		}
+	}
+	return total
+}
+
+func isList(t Type) bool {
+	if nonNull, ok := t.(NonNull); ok {
+		t = nonNull.Of
+	}
+	_, ok := t.(List)
+	return ok
+}
+
+// executeFields resolves the fields of an object
+// It returns false when a non-nullable field is null, so the object is null too.
+func (e *executor) executeFields(ctx context.Context, object *Object, source any, fields []*plannedField, path []any) (any, bool) {
+	result := &resultMap{values: make(map[string]any, len(fields))}
+	for _, f := range fields {
+		if f.field == nil {
+			result.set(f.key, object.Name)
+			continue
+		}
+		fieldPath := append(path[:len(path):len(path)], f.key)
+		value, ok := e.executeField(ctx, source, f, fieldPath)
+		if !ok {
+			return nil, false
+		}
+		result.set(f.key, value)
+	}
+	return result, true
+}
+
+func (e *executor) executeField(ctx context.Context, source any, f *plannedField, path []any) (any, bool) {
+	var value any
+	var err error
+	if f.field.Resolve != nil {
+		value, err = f.field.Resolve(ctx, source, f.args)
+	} else if values, ok := source.(map[string]any); ok {
+		value = values[f.field.Name]
+	} else {
+		err = fmt.Errorf("field %s has no resolver", f.field.Name)
+	}
+	if err != nil {
+		e.fail(err.Error(), f, path)
+		_, nonNull := f.field.Type.(NonNull)
+		return nil, !nonNull
+	}
+	return e.completeNullable(ctx, f.field.Type, f, value, path)
+}
+
+// completeNullable completes a value, turning failures into null unless the type is non-nullable
+func (e *executor) completeNullable(ctx context.Context, t Type, f *plannedField, value any, path []any) (any, bool) {
+	result, ok := e.completeValue(ctx, t, f, value, path)
+	if _, nonNull := t.(NonNull); !ok && !nonNull {
+		return nil, true
+	}
+	return result, ok
+}
+
+// completeValue converts a resolved value to its type
+// It returns false, with the error recorded, when the value must be null because of a failure.
+func (e *executor) completeValue(ctx context.Context, t Type, f *plannedField, value any, path []any) (any, bool) {
+	if nonNull, ok := t.(NonNull); ok {
+		result, ok := e.completeValue(ctx, nonNull.Of, f, value, path)
+		if !ok {
+			return nil, false
+		}
+		if result == nil {
+			e.fail(fmt.Sprintf("cannot return null for non-nullable field %s", f.field.Name), f, path)
+			return nil, false
+		}
+		return result, true
+	}
+	if isNil(value) {
+		return nil, true
+	}
+
+	switch t := t.(type) {
+	case List:
+		list := reflect.ValueOf(value)
+		if list.Kind() != reflect.Slice && list.Kind() != reflect.Array {
+			e.fail(fmt.Sprintf("field %s resolved to %T instead of a list", f.field.Name, value), f, path)
+			return nil, false
+		}
+		items := make([]any, list.Len())
+		for i := range items {
+			item, ok := e.completeNullable(ctx, t.Of, f, list.Index(i).Interface(), append(path[:len(path):len(path)], i))
+			if !ok {
+				return nil, false
+			}
+			items[i] = item
+		}
+		return items, true
+	case *Object:
+		return e.executeFields(ctx, t, value, f.children, path)
+	default:
+		return value, true
+	}
+}
+
+// isNil reports whether a resolved value is null; nil slices are empty lists
+func isNil(value any) bool {
+	if value == nil {
+		return true
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Interface, reflect.Func:
+		return v.IsNil()
+	}
+	return false
+}
+
+func (e *executor) fail(message string, f *plannedField, path []any) {
+	locations := make([]Location, len(f.nodes))
+	for i, node := range f.nodes {
+		locations[i] = Location{Line: node.line, Column: node.column}
+	}
+	e.errors = append(e.errors, &Error{
+		Message:   message,
+		Locations: locations,
+		Path:      append([]any(nil), path...),
+	})
+}
+
+// resultMap is an object in the response, encoded with its fields in query order
+type resultMap struct {
+	keys   []string
+	values map[string]any
+}
+
+func (m *resultMap) set(key string, value any) {
+	m.keys = append(m.keys, key)
+	m.values[key] = value
+}
+
+// MarshalJSON encodes the fields in the order they were set
+func (m *resultMap) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, key := range m.keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		v, err := json.Marshal(m.values[key])
+		if err != nil {
+			return nil, err
+		}
+		b.Write(k)
+		b.WriteByte(':')
+		b.Write(v)
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
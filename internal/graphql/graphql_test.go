@@ -0,0 +1,272 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testSchema has authors with books:
+// author(id) and authors(limit) on Query; Author.books(limit); Book.title, Book.pages
+func testSchema(t *testing.T) *Schema {
+	t.Helper()
+
+	books := map[string][]any{
+		"1": {
+			map[string]any{"title": "Dune", "pages": 412},
+			map[string]any{"title": "Children of Dune", "pages": 444},
+		},
+		"2": {
+			map[string]any{"title": "Solaris", "pages": 204},
+		},
+	}
+	authors := []any{
+		map[string]any{"id": "1", "name": "Frank Herbert"},
+		map[string]any{"id": "2", "name": "Stanisław Lem"},
+		map[string]any{"id": "3", "name": nil}, // Breaks the non-null name
+	}
+
+	book := NewObject("Book", "A book").
+		AddField(&Field{Name: "title", Type: NonNull{Of: String}}).
+		AddField(&Field{Name: "pages", Type: Int})
+	author := NewObject("Author", "").
+		AddField(&Field{Name: "id", Type: NonNull{Of: ID}}).
+		AddField(&Field{Name: "name", Type: NonNull{Of: String}}).
+		AddField(&Field{
+			Name: "books",
+			Type: NonNull{Of: List{Of: NonNull{Of: book}}},
+			Args: []*Argument{{Name: "limit", Type: Int, Default: 20}},
+			Resolve: func(ctx context.Context, source any, args map[string]any) (any, error) {
+				list := books[source.(map[string]any)["id"].(string)]
+				return list[:min(len(list), args["limit"].(int))], nil
+			},
+		})
+	query := NewObject("Query", "").
+		AddField(&Field{
+			Name: "author",
+			Type: author,
+			Args: []*Argument{{Name: "id", Type: NonNull{Of: ID}}},
+			Resolve: func(ctx context.Context, source any, args map[string]any) (any, error) {
+				for _, a := range authors {
+					if a.(map[string]any)["id"] == args["id"] {
+						return a, nil
+					}
+				}
+				return nil, errors.New("author not found")
+			},
+		}).
+		AddField(&Field{
+			Name: "authors",
+			Type: List{Of: NonNull{Of: author}},
+			Args: []*Argument{{Name: "limit", Type: Int, Default: 2}},
+			Resolve: func(ctx context.Context, source any, args map[string]any) (any, error) {
+				return authors[:min(len(authors), args["limit"].(int))], nil
+			},
+		}).
+		AddField(&Field{
+			Name:        "published",
+			Description: "Books published between two days",
+			Type:        List{Of: book},
+			Args:        []*Argument{{Name: "from", Type: Date}, {Name: "to", Type: Date}},
+			Resolve: func(ctx context.Context, source any, args map[string]any) (any, error) {
+				if _, ok := args["from"]; !ok {
+					return nil, nil
+				}
+				return books["2"], nil
+			},
+			Complexity: func(args map[string]any, childComplexity int) int {
+				return 1 + 100*childComplexity
+			},
+		})
+
+	schema, err := NewSchema(query)
+	require.NoError(t, err)
+	return schema
+}
+
+// run executes a query and returns the response as JSON
+func run(t *testing.T, query string, variables map[string]any, limits Limits) string {
+	t.Helper()
+	response := Execute(context.Background(), testSchema(t), Request{Query: query, Variables: variables}, limits)
+	data, err := json.Marshal(response)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestExecute(t *testing.T) {
+	t.Run("fields are returned in query order", func(t *testing.T) {
+		got := run(t, `{ author(id: 1) { name id books(limit: 1) { pages title } } }`, nil, Limits{})
+		assert.Equal(t, `{"data":{"author":{"name":"Frank Herbert","id":"1","books":[{"pages":412,"title":"Dune"}]}}}`, got)
+	})
+
+	t.Run("aliases, fragments and __typename", func(t *testing.T) {
+		got := run(t, `
+			query Books {
+				dune: author(id: "1") { ...AuthorName books { title } }
+				lem: author(id: "2") { __typename ... on Author { books { title } } }
+			}
+			fragment AuthorName on Author { name }`, nil, Limits{})
+		assert.Equal(t, `{"data":{"dune":{"name":"Frank Herbert","books":[{"title":"Dune"},{"title":"Children of Dune"}]},"lem":{"__typename":"Author","books":[{"title":"Solaris"}]}}}`, got)
+	})
+
+	t.Run("fields with the same key are merged", func(t *testing.T) {
+		got := run(t, `{ author(id: 2) { books { title } books { pages } } }`, nil, Limits{})
+		assert.Equal(t, `{"data":{"author":{"books":[{"title":"Solaris","pages":204}]}}}`, got)
+	})
+
+	t.Run("variables and directives", func(t *testing.T) {
+		query := `query ($id: ID!, $withBooks: Boolean = false, $from: Date) {
+			author(id: $id) { name books @include(if: $withBooks) { title } }
+			published(from: $from) { title }
+		}`
+		got := run(t, query, map[string]any{"id": float64(2)}, Limits{})
+		assert.Equal(t, `{"data":{"author":{"name":"Stanisław Lem"},"published":null}}`, got)
+
+		got = run(t, query, map[string]any{"id": "2", "withBooks": true, "from": "2024-05-01"}, Limits{})
+		assert.Equal(t, `{"data":{"author":{"name":"Stanisław Lem","books":[{"title":"Solaris"}]},"published":[{"title":"Solaris"}]}}`, got)
+	})
+
+	t.Run("resolver errors null the field", func(t *testing.T) {
+		got := run(t, `{ author(id: 9) { name } authors(limit: 1) { name } }`, nil, Limits{})
+		assert.Equal(t, `{"data":{"author":null,"authors":[{"name":"Frank Herbert"}]},"errors":[{"message":"author not found","locations":[{"line":1,"column":3}],"path":["author"]}]}`, got)
+	})
+
+	t.Run("null non-null fields null their nullable parent", func(t *testing.T) {
+		got := run(t, `{ authors(limit: 3) { id name } }`, nil, Limits{})
+		assert.Equal(t, `{"data":{"authors":null},"errors":[{"message":"cannot return null for non-nullable field name","locations":[{"line":1,"column":26}],"path":["authors",2,"name"]}]}`, got)
+	})
+
+	t.Run("invalid requests are not executed", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			query   string
+			message string
+		}{
+			{"syntax error", `{ author(id: 1) { name }`, "syntax error: unexpected end of document"},
+			{"unknown field", `{ author(id: 1) { email } }`, `cannot query field "email" on type Author`},
+			{"introspection", `{ __schema { types { name } } }`, "introspection is not supported: use the schema SDL instead"},
+			{"missing argument", `{ author { name } }`, `argument "id" of type ID! is required on field Query.author`},
+			{"unknown argument", `{ authors(first: 1) { name } }`, `unknown argument "first" on field Query.authors`},
+			{"invalid argument", `{ authors(limit: "all") { name } }`, `argument "limit" on field Query.authors: Int cannot represent "all"`},
+			{"invalid date", `{ published(from: "May 1") { title } }`, `argument "from" on field Query.published: Date cannot represent "May 1": use the YYYY-MM-DD format`},
+			{"missing subfields", `{ author(id: 1) }`, `field "author" of type Author must have a selection of subfields`},
+			{"subfields of a scalar", `{ author(id: 1) { name { first } } }`, `field "name" of type String! cannot have a selection of subfields`},
+			{"undefined variable", `{ author(id: $id) { name } }`, "variable $id is not defined"},
+			{"required variable", `query ($id: ID!) { author(id: $id) { name } }`, "variable $id of required type ID! was not provided"},
+			{"conflicting fields", `{ author(id: 1) { name: id name } }`, `fields "name" conflict: they select different fields or arguments`},
+			{"unknown fragment", `{ author(id: 1) { ...Missing } }`, `unknown fragment "Missing"`},
+			{"fragment cycle", `{ author(id: 1) { ...A } } fragment A on Author { ...A }`, `fragment "A" spreads itself`},
+			{"fragment on another type", `{ author(id: 1) { ... on Book { title } } }`, "a fragment on Book cannot be spread within Author"},
+			{"mutation", `mutation { author(id: 1) { name } }`, "mutation operations are not supported: the API is read-only"},
+			{"unknown directive", `{ author(id: 1) { name @deprecated } }`, "unknown directive @deprecated"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				response := Execute(context.Background(), testSchema(t), Request{Query: tt.query}, Limits{})
+				require.Len(t, response.Errors, 1)
+				assert.Equal(t, tt.message, response.Errors[0].Message)
+
+				data, err := json.Marshal(response)
+				require.NoError(t, err)
+				assert.NotContains(t, string(data), `"data"`)
+			})
+		}
+	})
+
+	t.Run("operations are selected by name", func(t *testing.T) {
+		schema := testSchema(t)
+		query := `query A { author(id: 1) { name } } query B { author(id: 2) { name } }`
+
+		response := Execute(context.Background(), schema, Request{Query: query}, Limits{})
+		require.Len(t, response.Errors, 1)
+		assert.Contains(t, response.Errors[0].Message, "operationName is required")
+
+		data, err := json.Marshal(Execute(context.Background(), schema, Request{Query: query, OperationName: "B"}, Limits{}))
+		require.NoError(t, err)
+		assert.Equal(t, `{"data":{"author":{"name":"Stanisław Lem"}}}`, string(data))
+	})
+}
+
+func TestExecute_Limits(t *testing.T) {
+	query := `{ authors(limit: 2) { name books(limit: 3) { title } } }`
+
+	t.Run("depth", func(t *testing.T) {
+		got := run(t, query, nil, Limits{MaxDepth: 2})
+		assert.Equal(t, `{"errors":[{"message":"query depth 3 exceeds the limit of 2"}]}`, got)
+		assert.NotContains(t, run(t, query, nil, Limits{MaxDepth: 3}), "errors")
+	})
+
+	t.Run("complexity", func(t *testing.T) {
+		// authors: 1 + 2 × (name 1 + books (1 + 3 × title 1)) = 11
+		got := run(t, query, nil, Limits{MaxComplexity: 10})
+		assert.Equal(t, `{"errors":[{"message":"query complexity 11 exceeds the limit of 10"}]}`, got)
+		assert.NotContains(t, run(t, query, nil, Limits{MaxComplexity: 11}), "errors")
+	})
+
+	t.Run("custom complexity", func(t *testing.T) {
+		got := run(t, `{ published { title pages } }`, nil, Limits{MaxComplexity: 200})
+		assert.Equal(t, `{"errors":[{"message":"query complexity 201 exceeds the limit of 200"}]}`, got)
+	})
+}
+
+func TestParse(t *testing.T) {
+	doc, err := parse("\uFEFF" + `
+		# Comment
+		query Q($ids: [ID!] = ["a", "b"], $n: Int = -3) {
+			a: f(s: "tab\tquote\"é", b: """
+				block
+				  indented
+			""", f: 1.5e3, l: [1, 2], o: {x: null}, e: RED) @skip(if: false)
+		}`)
+	require.NoError(t, err)
+	require.Len(t, doc.operations, 1)
+
+	op := doc.operations[0]
+	assert.Equal(t, "Q", op.name)
+	require.Len(t, op.variables, 2)
+	assert.Equal(t, "[ID!]", op.variables[0].typ.String())
+	assert.Equal(t, listValue{"a", "b"}, op.variables[0].defaultValue)
+	assert.Equal(t, int64(-3), op.variables[1].defaultValue)
+
+	f := op.selection[0].(*field)
+	assert.Equal(t, "a", f.responseKey())
+	assert.Equal(t, 4, f.line)
+	values := make(map[string]value)
+	for _, arg := range f.arguments {
+		values[arg.name] = arg.value
+	}
+	assert.Equal(t, "tab\tquote\"é", values["s"])
+	assert.Equal(t, "block\n  indented", values["b"])
+	assert.Equal(t, 1500.0, values["f"])
+	assert.Equal(t, listValue{int64(1), int64(2)}, values["l"])
+	assert.Equal(t, objectValue{{name: "x", value: nullValue{}}}, values["o"])
+	assert.Equal(t, enumValue("RED"), values["e"])
+	assert.Equal(t, "skip", f.directives[0].name)
+
+	for _, source := range []string{"", "{}", `{ f(s: "open) }`, "{ f } }", "fragment on on T { f } { f }", "query ($v: Int = $w) { f }"} {
+		_, err := parse(source)
+		assert.Error(t, err, source)
+	}
+}
+
+func TestSchema_SDL(t *testing.T) {
+	sdl := testSchema(t).SDL()
+
+	assert.True(t, strings.HasPrefix(sdl, "\"Calendar day in the YYYY-MM-DD format\"\nscalar Date\n\ntype Query {\n"), sdl)
+	assert.Contains(t, sdl, "  author(id: ID!): Author\n")
+	assert.Contains(t, sdl, "  authors(limit: Int = 2): [Author!]\n")
+	assert.Contains(t, sdl, "  \"Books published between two days\"\n  published(from: Date, to: Date): [Book]\n")
+	assert.Contains(t, sdl, "type Author {\n  id: ID!\n  name: String!\n  books(limit: Int = 20): [Book!]!\n}\n")
+	assert.Contains(t, sdl, "\"A book\"\ntype Book {\n")
+	assert.NotContains(t, sdl, "scalar String")
+
+	_, err := NewSchema(NewObject("Query", "").
+		AddField(&Field{Name: "a", Type: NewObject("Thing", "").AddField(&Field{Name: "x", Type: Int})}).
+		AddField(&Field{Name: "b", Type: NewObject("Thing", "").AddField(&Field{Name: "y", Type: Int})}))
+	assert.ErrorContains(t, err, "two types are named Thing")
+}
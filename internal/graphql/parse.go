@@ -0,0 +1,603 @@
+package graphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// document is a parsed request: its operations and fragments
+type document struct {
+	operations []*operation
+	fragments  map[string]*fragment
+}
+
+type operation struct {
+	kind      string // "query", "mutation" or "subscription"
+	name      string
+	variables []*variableDefinition
+	selection []selection
+}
+
+type variableDefinition struct {
+	name         string
+	typ          typeRef
+	defaultValue value // nil if none
+}
+
+// typeRef is a type as written in a variable definition (e.g., [ID!]!)
+type typeRef struct {
+	name    string   // Named type; empty for lists
+	of      *typeRef // Element type of lists
+	nonNull bool
+}
+
+func (t typeRef) String() string {
+	s := t.name
+	if t.of != nil {
+		s = "[" + t.of.String() + "]"
+	}
+	if t.nonNull {
+		s += "!"
+	}
+	return s
+}
+
+type fragment struct {
+	name          string
+	typeCondition string
+	selection     []selection
+}
+
+// selection is a field, a fragment spread or an inline fragment
+type selection interface {
+	directiveList() []*directive
+}
+
+type field struct {
+	alias      string
+	name       string
+	arguments  []*argument
+	directives []*directive
+	selection  []selection
+	line       int
+	column     int
+}
+
+type fragmentSpread struct {
+	name       string
+	directives []*directive
+}
+
+type inlineFragment struct {
+	typeCondition string // Empty if none
+	directives    []*directive
+	selection     []selection
+}
+
+func (f *field) directiveList() []*directive          { return f.directives }
+func (f *fragmentSpread) directiveList() []*directive { return f.directives }
+func (f *inlineFragment) directiveList() []*directive { return f.directives }
+
+// responseKey is the key of the field in the response: its alias or its name
+func (f *field) responseKey() string {
+	if f.alias != "" {
+		return f.alias
+	}
+	return f.name
+}
+
+type argument struct {
+	name  string
+	value value
+}
+
+type directive struct {
+	name      string
+	arguments []*argument
+}
+
+// value is a literal or a variable in a query
+type value interface{}
+
+type (
+	variableValue string         // $name
+	enumValue     string         // Unquoted name other than true, false and null
+	listValue     []value        // [a, b]
+	objectValue   []*objectField // {a: 1}
+	nullValue     struct{}
+)
+
+type objectField struct {
+	name  string
+	value value
+}
+
+// Token kinds
+const (
+	tokenEOF = iota
+	tokenPunctuator
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind   int
+	value  string
+	line   int
+	column int
+}
+
+// parser is a recursive descent parser of executable GraphQL documents
+type parser struct {
+	source string
+	pos    int
+	line   int
+	lineAt int // Position of the current line's start
+	token  token
+}
+
+// parse parses a query document
+func parse(source string) (doc *document, err error) {
+	p := &parser{source: strings.TrimPrefix(source, "\uFEFF"), line: 1}
+	defer func() {
+		if r := recover(); r != nil {
+			syntaxErr, ok := r.(*Error)
+			if !ok {
+				panic(r)
+			}
+			doc, err = nil, syntaxErr
+		}
+	}()
+
+	p.next()
+	doc = &document{fragments: make(map[string]*fragment)}
+	for p.token.kind != tokenEOF {
+		switch {
+		case p.peek(tokenPunctuator, "{"):
+			doc.operations = append(doc.operations, &operation{kind: "query", selection: p.parseSelectionSet()})
+		case p.peek(tokenName, "query"), p.peek(tokenName, "mutation"), p.peek(tokenName, "subscription"):
+			doc.operations = append(doc.operations, p.parseOperation())
+		case p.peek(tokenName, "fragment"):
+			fragment := p.parseFragment()
+			if _, ok := doc.fragments[fragment.name]; ok {
+				p.fail("there can be only one fragment named %q", fragment.name)
+			}
+			doc.fragments[fragment.name] = fragment
+		default:
+			p.unexpected()
+		}
+	}
+	if len(doc.operations) == 0 {
+		return nil, &Error{Message: "document contains no operation"}
+	}
+	return doc, nil
+}
+
+func (p *parser) parseOperation() *operation {
+	op := &operation{kind: p.token.value}
+	p.next()
+	if p.token.kind == tokenName {
+		op.name = p.expectName()
+	}
+	if p.skip(tokenPunctuator, "(") {
+		for !p.skip(tokenPunctuator, ")") {
+			op.variables = append(op.variables, p.parseVariableDefinition())
+		}
+	}
+	p.parseDirectives() // Operation directives are accepted and ignored
+	op.selection = p.parseSelectionSet()
+	return op
+}
+
+func (p *parser) parseVariableDefinition() *variableDefinition {
+	p.expect(tokenPunctuator, "$")
+	def := &variableDefinition{name: p.expectName()}
+	p.expect(tokenPunctuator, ":")
+	def.typ = p.parseTypeRef()
+	if p.skip(tokenPunctuator, "=") {
+		def.defaultValue = p.parseValue(true)
+	}
+	return def
+}
+
+func (p *parser) parseTypeRef() typeRef {
+	var t typeRef
+	if p.skip(tokenPunctuator, "[") {
+		of := p.parseTypeRef()
+		p.expect(tokenPunctuator, "]")
+		t.of = &of
+	} else {
+		t.name = p.expectName()
+	}
+	t.nonNull = p.skip(tokenPunctuator, "!")
+	return t
+}
+
+func (p *parser) parseFragment() *fragment {
+	p.next() // fragment
+	f := &fragment{name: p.expectName()}
+	if f.name == "on" {
+		p.fail("fragment cannot be named \"on\"")
+	}
+	if !p.skip(tokenName, "on") {
+		p.unexpected()
+	}
+	f.typeCondition = p.expectName()
+	p.parseDirectives()
+	f.selection = p.parseSelectionSet()
+	return f
+}
+
+func (p *parser) parseSelectionSet() []selection {
+	p.expect(tokenPunctuator, "{")
+	var selections []selection
+	for !p.skip(tokenPunctuator, "}") {
+		selections = append(selections, p.parseSelection())
+	}
+	if len(selections) == 0 {
+		p.fail("selection set must not be empty")
+	}
+	return selections
+}
+
+func (p *parser) parseSelection() selection {
+	if p.skip(tokenPunctuator, "...") {
+		if p.token.kind == tokenName && p.token.value != "on" {
+			return &fragmentSpread{name: p.expectName(), directives: p.parseDirectives()}
+		}
+		inline := &inlineFragment{}
+		if p.skip(tokenName, "on") {
+			inline.typeCondition = p.expectName()
+		}
+		inline.directives = p.parseDirectives()
+		inline.selection = p.parseSelectionSet()
+		return inline
+	}
+
+	f := &field{line: p.token.line, column: p.token.column}
+	f.name = p.expectName()
+	if p.skip(tokenPunctuator, ":") {
+		f.alias = f.name
+		f.name = p.expectName()
+	}
+	f.arguments = p.parseArguments(false)
+	f.directives = p.parseDirectives()
+	if p.peek(tokenPunctuator, "{") {
+		f.selection = p.parseSelectionSet()
+	}
+	return f
+}
+
+func (p *parser) parseArguments(constant bool) []*argument {
+	if !p.skip(tokenPunctuator, "(") {
+		return nil
+	}
+	var arguments []*argument
+	for !p.skip(tokenPunctuator, ")") {
+		arg := &argument{name: p.expectName()}
+		p.expect(tokenPunctuator, ":")
+		arg.value = p.parseValue(constant)
+		arguments = append(arguments, arg)
+	}
+	return arguments
+}
+
+func (p *parser) parseDirectives() []*directive {
+	var directives []*directive
+	for p.skip(tokenPunctuator, "@") {
+		directives = append(directives, &directive{name: p.expectName(), arguments: p.parseArguments(false)})
+	}
+	return directives
+}
+
+// parseValue parses a value; constant values (variable defaults) cannot contain variables
+func (p *parser) parseValue(constant bool) value {
+	tok := p.token
+	switch tok.kind {
+	case tokenPunctuator:
+		switch tok.value {
+		case "$":
+			if constant {
+				p.fail("unexpected variable in a constant value")
+			}
+			p.next()
+			return variableValue(p.expectName())
+		case "[":
+			p.next()
+			list := listValue{}
+			for !p.skip(tokenPunctuator, "]") {
+				list = append(list, p.parseValue(constant))
+			}
+			return list
+		case "{":
+			p.next()
+			object := objectValue{}
+			for !p.skip(tokenPunctuator, "}") {
+				name := p.expectName()
+				p.expect(tokenPunctuator, ":")
+				object = append(object, &objectField{name: name, value: p.parseValue(constant)})
+			}
+			return object
+		}
+	case tokenInt:
+		p.next()
+		n, err := strconv.ParseInt(tok.value, 10, 64)
+		if err != nil {
+			p.fail("integer %s is out of range", tok.value)
+		}
+		return n
+	case tokenFloat:
+		p.next()
+		f, _ := strconv.ParseFloat(tok.value, 64)
+		return f
+	case tokenString:
+		p.next()
+		return tok.value
+	case tokenName:
+		p.next()
+		switch tok.value {
+		case "true":
+			return true
+		case "false":
+			return false
+		case "null":
+			return nullValue{}
+		}
+		return enumValue(tok.value)
+	}
+	p.unexpected()
+	return nil
+}
+
+func (p *parser) peek(kind int, value string) bool {
+	return p.token.kind == kind && p.token.value == value
+}
+
+// skip consumes the token if it matches
+func (p *parser) skip(kind int, value string) bool {
+	if p.peek(kind, value) {
+		p.next()
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(kind int, value string) {
+	if !p.skip(kind, value) {
+		p.unexpected()
+	}
+}
+
+func (p *parser) expectName() string {
+	if p.token.kind != tokenName {
+		p.unexpected()
+	}
+	name := p.token.value
+	p.next()
+	return name
+}
+
+func (p *parser) unexpected() {
+	if p.token.kind == tokenEOF {
+		p.fail("unexpected end of document")
+	}
+	p.fail("unexpected %q", p.token.value)
+}
+
+func (p *parser) fail(format string, args ...any) {
+	panic(&Error{
+		Message:   "syntax error: " + fmt.Sprintf(format, args...),
+		Locations: []Location{{Line: p.token.line, Column: p.token.column}},
+	})
+}
+
+// next reads the next token, skipping whitespace, commas and comments
+func (p *parser) next() {
+	for p.pos < len(p.source) {
+		c := p.source[p.pos]
+		switch {
+		case c == '\n':
+			p.pos++
+			p.line++
+			p.lineAt = p.pos
+		case c == ' ' || c == '\t' || c == '\r' || c == ',':
+			p.pos++
+		case c == '#':
+			for p.pos < len(p.source) && p.source[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			p.token = p.readToken()
+			return
+		}
+	}
+	p.token = token{kind: tokenEOF, line: p.line, column: p.pos - p.lineAt + 1}
+}
+
+func (p *parser) readToken() token {
+	start := p.pos
+	tok := token{line: p.line, column: start - p.lineAt + 1}
+	c := p.source[start]
+
+	switch {
+	case strings.HasPrefix(p.source[start:], "..."):
+		p.pos += 3
+		tok.kind, tok.value = tokenPunctuator, "..."
+	case strings.IndexByte("!$():=@[]{}|&", c) >= 0:
+		p.pos++
+		tok.kind, tok.value = tokenPunctuator, string(c)
+	case c == '_' || isLetter(c):
+		for p.pos < len(p.source) && (p.source[p.pos] == '_' || isLetter(p.source[p.pos]) || isDigit(p.source[p.pos])) {
+			p.pos++
+		}
+		tok.kind, tok.value = tokenName, p.source[start:p.pos]
+	case c == '-' || isDigit(c):
+		tok.kind, tok.value = p.readNumber()
+	case strings.HasPrefix(p.source[start:], `"""`):
+		tok.kind, tok.value = tokenString, p.readBlockString()
+	case c == '"':
+		tok.kind, tok.value = tokenString, p.readString()
+	default:
+		p.token = tok
+		p.fail("unexpected character %q", c)
+	}
+	return tok
+}
+
+func (p *parser) readNumber() (int, string) {
+	start := p.pos
+	kind := tokenInt
+	if p.source[p.pos] == '-' {
+		p.pos++
+	}
+	digits := p.readDigits()
+	if digits == 0 {
+		p.fail("invalid number")
+	}
+	if p.pos < len(p.source) && p.source[p.pos] == '.' {
+		kind = tokenFloat
+		p.pos++
+		if p.readDigits() == 0 {
+			p.fail("invalid number")
+		}
+	}
+	if p.pos < len(p.source) && (p.source[p.pos] == 'e' || p.source[p.pos] == 'E') {
+		kind = tokenFloat
+		p.pos++
+		if p.pos < len(p.source) && (p.source[p.pos] == '+' || p.source[p.pos] == '-') {
+			p.pos++
+		}
+		if p.readDigits() == 0 {
+			p.fail("invalid number")
+		}
+	}
+	return kind, p.source[start:p.pos]
+}
+
+func (p *parser) readDigits() int {
+	start := p.pos
+	for p.pos < len(p.source) && isDigit(p.source[p.pos]) {
+		p.pos++
+	}
+	return p.pos - start
+}
+
+func (p *parser) readString() string {
+	p.pos++ // Opening quote
+	var b strings.Builder
+	for p.pos < len(p.source) {
+		c := p.source[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return b.String()
+		case c == '\n':
+			p.fail("unterminated string")
+		case c == '\\':
+			if p.pos+1 >= len(p.source) {
+				p.fail("unterminated string")
+			}
+			escape := p.source[p.pos+1]
+			p.pos += 2
+			switch escape {
+			case '"', '\\', '/':
+				b.WriteByte(escape)
+			case 'b':
+				b.WriteByte('\b')
+			case 'f':
+				b.WriteByte('\f')
+			case 'n':
+				b.WriteByte('\n')
+			case 'r':
+				b.WriteByte('\r')
+			case 't':
+				b.WriteByte('\t')
+			case 'u':
+				if p.pos+4 > len(p.source) {
+					p.fail("invalid unicode escape")
+				}
+				r, err := strconv.ParseUint(p.source[p.pos:p.pos+4], 16, 32)
+				if err != nil {
+					p.fail("invalid unicode escape")
+				}
+				b.WriteRune(rune(r))
+				p.pos += 4
+			default:
+				p.fail("invalid escape \\%c", escape)
+			}
+		default:
+			r, size := utf8.DecodeRuneInString(p.source[p.pos:])
+			b.WriteRune(r)
+			p.pos += size
+		}
+	}
+	p.fail("unterminated string")
+	return ""
+}
+
+// readBlockString reads a """block string""", removing its common indentation
+func (p *parser) readBlockString() string {
+	p.pos += 3
+	end := strings.Index(p.source[p.pos:], `"""`)
+	for end > 0 && p.source[p.pos+end-1] == '\\' {
+		next := strings.Index(p.source[p.pos+end+3:], `"""`)
+		if next < 0 {
+			end = -1
+			break
+		}
+		end += 3 + next
+	}
+	if end < 0 {
+		p.fail("unterminated block string")
+	}
+	raw := p.source[p.pos : p.pos+end]
+	p.line += strings.Count(raw, "\n")
+	if i := strings.LastIndexByte(raw, '\n'); i >= 0 {
+		p.lineAt = p.pos + i + 1
+	}
+	p.pos += end + 3
+	return blockStringValue(strings.ReplaceAll(raw, `\"""`, `"""`))
+}
+
+// blockStringValue removes the common indentation and blank first and last lines
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.ReplaceAll(raw, "\r\n", "\n"), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+func isLetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
@@ -0,0 +1,247 @@
+// Package graphql executes read-only GraphQL queries against a schema of Go resolvers.
+// It implements the parts of the GraphQL spec a reporting API needs: queries with fragments,
+// aliases, variables and the @skip/@include directives, over object, list and scalar types.
+// Mutations, subscriptions, interfaces, unions, input objects and introspection are not
+// supported; Schema.SDL describes the schema instead. Queries are checked against depth and
+// complexity limits before anything is resolved.
+package graphql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Type is an output or input type: *Scalar, *Object, List or NonNull
+type Type interface {
+	String() string
+}
+
+// Scalar is a leaf type
+// Resolvers return any value that encodes to JSON as the scalar; ParseValue coerces
+// argument and variable values (JSON-decoded, or literals from the query).
+type Scalar struct {
+	Name        string
+	Description string
+	ParseValue  func(v any) (any, error)
+}
+
+func (s *Scalar) String() string { return s.Name }
+
+// Object is a type with fields
+type Object struct {
+	Name        string
+	Description string
+	fields      []*Field
+	byName      map[string]*Field
+}
+
+func (o *Object) String() string { return o.Name }
+
+// NewObject creates an object type; fields are added with AddField, so types can refer to each other
+func NewObject(name, description string) *Object {
+	return &Object{Name: name, Description: description, byName: make(map[string]*Field)}
+}
+
+// AddField adds a field to the object
+func (o *Object) AddField(field *Field) *Object {
+	if _, ok := o.byName[field.Name]; ok {
+		panic(fmt.Sprintf("graphql: field %s.%s is defined twice", o.Name, field.Name))
+	}
+	o.fields = append(o.fields, field)
+	o.byName[field.Name] = field
+	return o
+}
+
+// List is a list of another type
+type List struct{ Of Type }
+
+func (l List) String() string { return "[" + l.Of.String() + "]" }
+
+// NonNull is a type whose values are never null
+type NonNull struct{ Of Type }
+
+func (n NonNull) String() string { return n.Of.String() + "!" }
+
+// ResolveFunc returns a field's value from the value of its parent object (nil for Query fields)
+// Arguments are coerced to their types; missing optional arguments without defaults are absent.
+type ResolveFunc func(ctx context.Context, source any, args map[string]any) (any, error)
+
+// Field is a field of an object
+// Without Resolve, the value is looked up by name in a map[string]any source.
+type Field struct {
+	Name        string
+	Description string
+	Type        Type
+	Args        []*Argument
+	Resolve     ResolveFunc
+
+	// Complexity estimates the cost of resolving the field once, given the cost of its
+	// selected subfields. Nil counts 1 plus the subfields, times the "limit" argument
+	// (or DefaultListSize) for list fields.
+	Complexity func(args map[string]any, childComplexity int) int
+}
+
+// Argument is an argument of a field
+type Argument struct {
+	Name        string
+	Description string
+	Type        Type // Scalars, lists of them and NonNull
+	Default     any  // Used when the argument is missing (nil for none)
+}
+
+// Built-in scalars
+var (
+	String = &Scalar{Name: "String", ParseValue: parseString}
+	ID     = &Scalar{Name: "ID", ParseValue: parseID}
+	Int    = &Scalar{Name: "Int", ParseValue: parseInt}
+	Float  = &Scalar{Name: "Float", ParseValue: parseFloat}
+	Bool   = &Scalar{Name: "Boolean", ParseValue: parseBool}
+)
+
+var builtinScalars = map[string]*Scalar{"String": String, "ID": ID, "Int": Int, "Float": Float, "Boolean": Bool}
+
+// Schema is a set of types starting from the Query type
+type Schema struct {
+	Query *Object
+	types map[string]Type // Named types reachable from Query
+	order []string        // Named types in the order they were found
+}
+
+// NewSchema creates a schema for the query type and checks its type names are unique
+func NewSchema(query *Object) (*Schema, error) {
+	s := &Schema{Query: query, types: make(map[string]Type)}
+	if err := s.collect(query); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Schema) collect(t Type) error {
+	switch t := t.(type) {
+	case List:
+		return s.collect(t.Of)
+	case NonNull:
+		return s.collect(t.Of)
+	case *Scalar:
+		return s.add(t.Name, t)
+	case *Object:
+		if existing, ok := s.types[t.Name]; ok {
+			if existing != Type(t) {
+				return fmt.Errorf("graphql: two types are named %s", t.Name)
+			}
+			return nil
+		}
+		if err := s.add(t.Name, t); err != nil {
+			return err
+		}
+		for _, field := range t.fields {
+			if err := s.collect(field.Type); err != nil {
+				return err
+			}
+			for _, arg := range field.Args {
+				if _, ok := namedType(arg.Type).(*Scalar); !ok {
+					return fmt.Errorf("graphql: argument %s.%s(%s) must be a scalar or a list of them", t.Name, field.Name, arg.Name)
+				}
+				if err := s.collect(arg.Type); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Schema) add(name string, t Type) error {
+	if existing, ok := s.types[name]; ok {
+		if existing != t {
+			return fmt.Errorf("graphql: two types are named %s", name)
+		}
+		return nil
+	}
+	s.types[name] = t
+	s.order = append(s.order, name)
+	return nil
+}
+
+// SDL returns the schema in the GraphQL schema definition language
+func (s *Schema) SDL() string {
+	var b strings.Builder
+	for _, name := range s.order {
+		if scalar, ok := s.types[name].(*Scalar); ok && builtinScalars[name] == nil {
+			writeDescription(&b, scalar.Description, "")
+			fmt.Fprintf(&b, "scalar %s\n\n", name)
+		}
+	}
+
+	for _, name := range s.order {
+		object, ok := s.types[name].(*Object)
+		if !ok {
+			continue
+		}
+		writeDescription(&b, object.Description, "")
+		fmt.Fprintf(&b, "type %s {\n", name)
+		for _, field := range object.fields {
+			writeDescription(&b, field.Description, "  ")
+			b.WriteString("  " + field.Name)
+			if len(field.Args) > 0 {
+				args := make([]string, len(field.Args))
+				for i, arg := range field.Args {
+					args[i] = arg.Name + ": " + arg.Type.String()
+					if arg.Default != nil {
+						args[i] += " = " + formatLiteral(arg.Default)
+					}
+				}
+				b.WriteString("(" + strings.Join(args, ", ") + ")")
+			}
+			b.WriteString(": " + field.Type.String() + "\n")
+		}
+		b.WriteString("}\n\n")
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
+
+func writeDescription(b *strings.Builder, description, indent string) {
+	if description == "" {
+		return
+	}
+	if !strings.Contains(description, "\n") {
+		fmt.Fprintf(b, "%s%q\n", indent, description)
+		return
+	}
+	fmt.Fprintf(b, "%s\"\"\"\n", indent)
+	for _, line := range strings.Split(description, "\n") {
+		fmt.Fprintf(b, "%s%s\n", indent, line)
+	}
+	fmt.Fprintf(b, "%s\"\"\"\n", indent)
+}
+
+// formatLiteral formats a default value as a GraphQL literal
+func formatLiteral(v any) string {
+	switch v := v.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	case []any:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = formatLiteral(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// namedType returns the scalar or object a type wraps
+func namedType(t Type) Type {
+	for {
+		switch wrapped := t.(type) {
+		case List:
+			t = wrapped.Of
+		case NonNull:
+			t = wrapped.Of
+		default:
+			return t
+		}
+	}
+}
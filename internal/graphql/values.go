@@ -0,0 +1,188 @@
+package graphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// DateTime is an RFC 3339 timestamp; resolvers return time.Time or *time.Time and arguments are time.Time
+var DateTime = &Scalar{
+	Name:        "DateTime",
+	Description: "RFC 3339 timestamp, e.g. 2024-05-01T18:30:00+02:00",
+	ParseValue: func(v any) (any, error) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("DateTime cannot represent %s", describe(v))
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return nil, fmt.Errorf("DateTime cannot represent %q: use the RFC 3339 format", s)
+		}
+		return t, nil
+	},
+}
+
+// Date is a calendar day (YYYY-MM-DD); arguments are time.Time at midnight UTC
+var Date = &Scalar{
+	Name:        "Date",
+	Description: "Calendar day in the YYYY-MM-DD format",
+	ParseValue: func(v any) (any, error) {
+		s, ok := v.(string)
+		if !ok {
+			return nil, fmt.Errorf("Date cannot represent %s", describe(v))
+		}
+		t, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return nil, fmt.Errorf("Date cannot represent %q: use the YYYY-MM-DD format", s)
+		}
+		return t, nil
+	},
+}
+
+func parseString(v any) (any, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	return nil, fmt.Errorf("String cannot represent %s", describe(v))
+}
+
+// parseID accepts strings and integers, which are returned as strings
+func parseID(v any) (any, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	if n, ok := toInt64(v); ok {
+		return strconv.FormatInt(n, 10), nil
+	}
+	return nil, fmt.Errorf("ID cannot represent %s", describe(v))
+}
+
+// parseInt accepts 32-bit integers, which are returned as int
+func parseInt(v any) (any, error) {
+	n, ok := toInt64(v)
+	if !ok || n < math.MinInt32 || n > math.MaxInt32 {
+		return nil, fmt.Errorf("Int cannot represent %s", describe(v))
+	}
+	return int(n), nil
+}
+
+func parseFloat(v any) (any, error) {
+	switch v := v.(type) {
+	case float64:
+		return v, nil
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return f, nil
+		}
+	}
+	if n, ok := toInt64(v); ok {
+		return float64(n), nil
+	}
+	return nil, fmt.Errorf("Float cannot represent %s", describe(v))
+}
+
+func parseBool(v any) (any, error) {
+	if b, ok := v.(bool); ok {
+		return b, nil
+	}
+	return nil, fmt.Errorf("Boolean cannot represent %s", describe(v))
+}
+
+// toInt64 converts integers from query literals (int64), decoded JSON (float64 or json.Number)
+// and coerced values (int)
+func toInt64(v any) (int64, bool) {
+	switch v := v.(type) {
+	case int:
+		return int64(v), true
+	case int64:
+		return v, true
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) <= 1<<53 {
+			return int64(v), true
+		}
+	case json.Number:
+		n, err := v.Int64()
+		return n, err == nil
+	}
+	return 0, false
+}
+
+// describe formats a rejected input value for error messages
+func describe(v any) string {
+	switch v := v.(type) {
+	case string:
+		return strconv.Quote(v)
+	case enumValue:
+		return string(v)
+	case []any:
+		return "a list"
+	case map[string]any, objectValue:
+		return "an object"
+	}
+	return fmt.Sprint(v)
+}
+
+// coerceInput coerces an argument or variable value to its input type
+// Values are already Go values: variables are decoded JSON and literals are converted by literalValue.
+func coerceInput(t Type, v any) (any, error) {
+	switch t := t.(type) {
+	case NonNull:
+		if v == nil {
+			return nil, fmt.Errorf("expected a non-null %s", t.Of)
+		}
+		return coerceInput(t.Of, v)
+	case List:
+		if v == nil {
+			return nil, nil
+		}
+		items, ok := v.([]any)
+		if !ok {
+			items = []any{v} // A single value is a list of one
+		}
+		list := make([]any, len(items))
+		for i, item := range items {
+			coerced, err := coerceInput(t.Of, item)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = coerced
+		}
+		return list, nil
+	case *Scalar:
+		if v == nil {
+			return nil, nil
+		}
+		return t.ParseValue(v)
+	}
+	return nil, fmt.Errorf("%s is not an input type", t)
+}
+
+// literalValue converts a value from the query to a Go value
+// Variables are looked up in vars; defined is false for variables that were not provided,
+// so the argument's default applies.
+func literalValue(v value, vars map[string]any) (result any, defined bool, err error) {
+	switch v := v.(type) {
+	case variableValue:
+		result, defined = vars[string(v)]
+		return result, defined, nil
+	case nullValue:
+		return nil, true, nil
+	case listValue:
+		list := make([]any, len(v))
+		for i, item := range v {
+			list[i], _, err = literalValue(item, vars) // Missing variables in lists are null
+			if err != nil {
+				return nil, false, err
+			}
+		}
+		return list, true, nil
+	case enumValue:
+		return nil, false, fmt.Errorf("enum value %s is not supported", string(v))
+	case objectValue:
+		return nil, false, fmt.Errorf("input objects are not supported")
+	}
+	return v, true, nil
+}
@@ -0,0 +1,584 @@
+package homekit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"metron/internal/core"
+)
+
+// HAP status codes
+const (
+	statusSuccess                = 0
+	statusInsufficientPrivileges = -70401
+	statusCommunicationFailure   = -70402
+	statusReadOnly               = -70404
+	statusWriteOnly              = -70405
+	statusNotificationsNotSupp   = -70406
+	statusResourceDoesNotExist   = -70409
+	statusInvalidValue           = -70410
+)
+
+// Service and characteristic types (short forms of the Apple-defined UUIDs)
+const (
+	typeAccessoryInformation = "3E"
+	typeProtocolInformation  = "A2"
+	typeSwitch               = "49"
+	typeLightSensor          = "84"
+
+	typeIdentify          = "14"
+	typeManufacturer      = "20"
+	typeModel             = "21"
+	typeName              = "23"
+	typeSerialNumber      = "30"
+	typeFirmwareRevision  = "52"
+	typeVersion           = "37"
+	typeOn                = "25"
+	typeCurrentLightLevel = "6B"
+)
+
+// firmwareRevision is reported for every accessory
+const firmwareRevision = "1.0.0"
+
+// Permissions
+const (
+	permRead   = "pr"
+	permWrite  = "pw"
+	permEvents = "ev"
+)
+
+// characteristicID addresses a characteristic by accessory and instance ID
+type characteristicID struct {
+	aid uint64
+	iid uint64
+}
+
+// sessionState maps device IDs to their running session
+type sessionState map[string]*core.Session
+
+// characteristic is one value of a service
+type characteristic struct {
+	iid      uint64
+	typ      string
+	perms    []string
+	format   string
+	unit     string
+	minValue *float64
+	maxValue *float64
+
+	read  func(state sessionState) interface{}               // nil for write-only characteristics
+	write func(ctx context.Context, value interface{}) error // nil for read-only characteristics
+}
+
+// service groups characteristics
+type service struct {
+	iid             uint64
+	typ             string
+	primary         bool
+	characteristics []*characteristic
+}
+
+// accessory is the bridge itself or one device
+type accessory struct {
+	aid      uint64
+	services []*service
+}
+
+// hasPerm reports whether the characteristic has a permission
+func (c *characteristic) hasPerm(perm string) bool {
+	for _, p := range c.perms {
+		if p == perm {
+			return true
+		}
+	}
+	return false
+}
+
+// buildAccessories creates the bridge accessory and one accessory per device.
+// Accessory IDs are derived from device IDs, so they stay stable when devices are added
+// or removed and Home keeps its rooms and automations.
+func (b *Bridge) buildAccessories() []*accessory {
+	bridge := &accessory{aid: 1}
+	bridge.services = []*service{
+		informationService(b.config.Name, "Bridge", "metron", b.identify("bridge")),
+		{iid: 8, typ: typeProtocolInformation, characteristics: []*characteristic{
+			staticCharacteristic(9, typeVersion, "1.1.0"),
+		}},
+	}
+	accessories := []*accessory{bridge}
+
+	devices := append([]Device(nil), b.config.Devices...)
+	sort.Slice(devices, func(i, j int) bool { return devices[i].ID < devices[j].ID })
+
+	used := map[uint64]bool{1: true}
+	for _, device := range devices {
+		aid := accessoryID(device.ID)
+		for used[aid] {
+			aid++
+		}
+		used[aid] = true
+		accessories = append(accessories, b.deviceAccessory(aid, device))
+	}
+	return accessories
+}
+
+// accessoryID hashes a device ID into an accessory ID above 1
+func accessoryID(deviceID string) uint64 {
+	h := fnv.New32a()
+	h.Write([]byte(deviceID))
+	return uint64(h.Sum32()) + 2
+}
+
+// deviceAccessory exposes a device as a session switch and a remaining minutes sensor
+func (b *Bridge) deviceAccessory(aid uint64, device Device) *accessory {
+	deviceID := device.ID
+
+	on := &characteristic{
+		iid:    10,
+		typ:    typeOn,
+		perms:  []string{permRead, permEvents},
+		format: "bool",
+		read: func(state sessionState) interface{} {
+			return state[deviceID] != nil
+		},
+	}
+	if len(device.ChildIDs) > 0 {
+		on.perms = []string{permRead, permWrite, permEvents}
+		on.write = func(ctx context.Context, value interface{}) error {
+			wanted, ok := boolValue(value)
+			if !ok {
+				return errInvalidValue
+			}
+			return b.setSession(ctx, device, wanted)
+		}
+	}
+
+	zero, maxMinutes := 0.0, 1440.0
+	remaining := &characteristic{
+		iid:      13,
+		typ:      typeCurrentLightLevel,
+		perms:    []string{permRead, permEvents},
+		format:   "float",
+		unit:     "lux",
+		minValue: &zero,
+		maxValue: &maxMinutes,
+		read: func(state sessionState) interface{} {
+			if session := state[deviceID]; session != nil {
+				return session.CalculateRemainingMinutes()
+			}
+			return 0
+		},
+	}
+
+	return &accessory{aid: aid, services: []*service{
+		informationService(device.Name, device.Type, device.ID, b.identify(device.ID)),
+		{iid: 8, typ: typeSwitch, primary: true, characteristics: []*characteristic{
+			staticCharacteristic(9, typeName, "Session"),
+			on,
+		}},
+		{iid: 11, typ: typeLightSensor, characteristics: []*characteristic{
+			staticCharacteristic(12, typeName, "Remaining Minutes"),
+			remaining,
+		}},
+	}}
+}
+
+// informationService is the accessory information service every accessory has
+func informationService(name, model, serial string, identify func(ctx context.Context, value interface{}) error) *service {
+	if model == "" {
+		model = "Device"
+	}
+	return &service{iid: 1, typ: typeAccessoryInformation, characteristics: []*characteristic{
+		{iid: 2, typ: typeIdentify, perms: []string{permWrite}, format: "bool", write: identify},
+		staticCharacteristic(3, typeManufacturer, "Metron"),
+		staticCharacteristic(4, typeModel, model),
+		staticCharacteristic(5, typeName, name),
+		staticCharacteristic(6, typeSerialNumber, serial),
+		staticCharacteristic(7, typeFirmwareRevision, firmwareRevision),
+	}}
+}
+
+// staticCharacteristic is a read-only string
+func staticCharacteristic(iid uint64, typ, value string) *characteristic {
+	return &characteristic{
+		iid:    iid,
+		typ:    typ,
+		perms:  []string{permRead},
+		format: "string",
+		read:   func(sessionState) interface{} { return value },
+	}
+}
+
+// identify logs identify requests; there is nothing to blink
+func (b *Bridge) identify(name string) func(ctx context.Context, value interface{}) error {
+	return func(ctx context.Context, value interface{}) error {
+		b.logger.Info("HomeKit identify requested", "accessory", name)
+		return nil
+	}
+}
+
+// errInvalidValue is returned by writes of values of the wrong type
+var errInvalidValue = fmt.Errorf("invalid value")
+
+// boolValue accepts true/false and 1/0, which Home uses for booleans
+func boolValue(value interface{}) (bool, bool) {
+	switch v := value.(type) {
+	case bool:
+		return v, true
+	case float64:
+		return v != 0, v == 0 || v == 1
+	}
+	return false, false
+}
+
+// setSession starts a session on the device with its start settings, or stops the running one
+func (b *Bridge) setSession(ctx context.Context, device Device, on bool) error {
+	state, err := b.state(ctx)
+	if err != nil {
+		return err
+	}
+	session := state[device.ID]
+
+	switch {
+	case on && session == nil:
+		ctx := core.WithInitiator(ctx, core.Initiator{Type: core.InitiatorParent, ID: "homekit"})
+		session, err := b.sessions.StartSession(ctx, device.ID, device.ChildIDs, device.Minutes)
+		if err != nil {
+			b.logger.Warn("HomeKit session start refused", "device_id", device.ID, "error", err)
+			return err
+		}
+		b.logger.Info("Session started from HomeKit", "device_id", device.ID, "session_id", session.ID)
+	case !on && session != nil:
+		if err := b.sessions.StopSession(ctx, session.ID); err != nil {
+			b.logger.Warn("HomeKit session stop failed", "device_id", device.ID, "error", err)
+			return err
+		}
+		b.logger.Info("Session stopped from HomeKit", "device_id", device.ID, "session_id", session.ID)
+	}
+	return nil
+}
+
+// state returns the running session of each device
+func (b *Bridge) state(ctx context.Context) (sessionState, error) {
+	sessions, err := b.sessions.ListActiveSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	state := make(sessionState)
+	for _, session := range sessions {
+		if session.IsRunning() {
+			state[session.DeviceID] = session
+		}
+	}
+	return state, nil
+}
+
+// find returns a characteristic by ID
+func (b *Bridge) find(id characteristicID) *characteristic {
+	for _, a := range b.accessories {
+		if a.aid != id.aid {
+			continue
+		}
+		for _, s := range a.services {
+			for _, c := range s.characteristics {
+				if c.iid == id.iid {
+					return c
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// characteristicJSON is a characteristic in the accessory database
+type characteristicJSON struct {
+	AID      uint64      `json:"aid,omitempty"`
+	IID      uint64      `json:"iid"`
+	Type     string      `json:"type,omitempty"`
+	Perms    []string    `json:"perms,omitempty"`
+	Format   string      `json:"format,omitempty"`
+	Unit     string      `json:"unit,omitempty"`
+	MinValue *float64    `json:"minValue,omitempty"`
+	MaxValue *float64    `json:"maxValue,omitempty"`
+	Value    interface{} `json:"value,omitempty"`
+	Events   *bool       `json:"ev,omitempty"`
+	Status   *int        `json:"status,omitempty"`
+}
+
+type serviceJSON struct {
+	IID             uint64                `json:"iid"`
+	Type            string                `json:"type"`
+	Primary         bool                  `json:"primary,omitempty"`
+	Characteristics []*characteristicJSON `json:"characteristics"`
+}
+
+type accessoryJSON struct {
+	AID      uint64         `json:"aid"`
+	Services []*serviceJSON `json:"services"`
+}
+
+// database returns the accessory database; values are omitted without state
+func (b *Bridge) database(state sessionState) []*accessoryJSON {
+	var out []*accessoryJSON
+	for _, a := range b.accessories {
+		aj := &accessoryJSON{AID: a.aid}
+		for _, s := range a.services {
+			sj := &serviceJSON{IID: s.iid, Type: s.typ, Primary: s.primary}
+			for _, c := range s.characteristics {
+				cj := &characteristicJSON{
+					IID:      c.iid,
+					Type:     c.typ,
+					Perms:    c.perms,
+					Format:   c.format,
+					Unit:     c.unit,
+					MinValue: c.minValue,
+					MaxValue: c.maxValue,
+				}
+				if state != nil && c.read != nil {
+					cj.Value = c.read(state)
+				}
+				sj.Characteristics = append(sj.Characteristics, cj)
+			}
+			aj.Services = append(aj.Services, sj)
+		}
+		out = append(out, aj)
+	}
+	return out
+}
+
+// databaseHash identifies the accessory database structure for the configuration number
+func databaseHash(accessories []*accessory) string {
+	b := &Bridge{accessories: accessories}
+	data, _ := json.Marshal(b.database(nil))
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// jsonResponse returns a HAP JSON response
+func jsonResponse(status int, v interface{}) *response {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return &response{status: http.StatusInternalServerError, contentType: contentTypeJSON}
+	}
+	return &response{status: status, contentType: contentTypeJSON, body: body}
+}
+
+// jsonStatus returns a HAP error status
+func jsonStatus(status, hapStatus int) *response {
+	return jsonResponse(status, map[string]int{"status": hapStatus})
+}
+
+// getAccessories returns the accessory database with current values
+func (b *Bridge) getAccessories(ctx context.Context) *response {
+	state, err := b.state(ctx)
+	if err != nil {
+		b.logger.Error("Failed to read sessions for HomeKit", "error", err)
+		return jsonStatus(http.StatusServiceUnavailable, statusCommunicationFailure)
+	}
+	return jsonResponse(http.StatusOK, map[string]interface{}{"accessories": b.database(state)})
+}
+
+// getCharacteristics reads characteristics given as id=aid.iid,aid.iid
+func (b *Bridge) getCharacteristics(ctx context.Context, c *conn, query url.Values) *response {
+	var ids []characteristicID
+	for _, part := range strings.Split(query.Get("id"), ",") {
+		aid, iid, ok := strings.Cut(part, ".")
+		a, errA := strconv.ParseUint(aid, 10, 64)
+		i, errI := strconv.ParseUint(iid, 10, 64)
+		if !ok || errA != nil || errI != nil {
+			return jsonStatus(http.StatusBadRequest, statusInvalidValue)
+		}
+		ids = append(ids, characteristicID{a, i})
+	}
+
+	state, err := b.state(ctx)
+	if err != nil {
+		b.logger.Error("Failed to read sessions for HomeKit", "error", err)
+		return jsonStatus(http.StatusServiceUnavailable, statusCommunicationFailure)
+	}
+
+	failed := false
+	results := make([]*characteristicJSON, 0, len(ids))
+	for _, id := range ids {
+		result := &characteristicJSON{AID: id.aid, IID: id.iid}
+		ch := b.find(id)
+		switch {
+		case ch == nil:
+			result.Status = intPtr(statusResourceDoesNotExist)
+		case ch.read == nil:
+			result.Status = intPtr(statusWriteOnly)
+		default:
+			result.Value = ch.read(state)
+			if query.Get("meta") == "1" {
+				result.Format, result.Unit, result.MinValue, result.MaxValue = ch.format, ch.unit, ch.minValue, ch.maxValue
+			}
+			if query.Get("perms") == "1" {
+				result.Perms = ch.perms
+			}
+			if query.Get("type") == "1" {
+				result.Type = ch.typ
+			}
+			if query.Get("ev") == "1" {
+				events := c.eventsOn(id)
+				result.Events = &events
+			}
+		}
+		if result.Status != nil {
+			failed = true
+		}
+		results = append(results, result)
+	}
+
+	if !failed {
+		return jsonResponse(http.StatusOK, map[string]interface{}{"characteristics": results})
+	}
+	for _, result := range results {
+		if result.Status == nil {
+			result.Status = intPtr(statusSuccess)
+		}
+	}
+	return jsonResponse(http.StatusMultiStatus, map[string]interface{}{"characteristics": results})
+}
+
+// putCharacteristics writes values and turns notifications on or off
+func (b *Bridge) putCharacteristics(ctx context.Context, c *conn, body []byte) *response {
+	var request struct {
+		Characteristics []struct {
+			AID    uint64      `json:"aid"`
+			IID    uint64      `json:"iid"`
+			Value  interface{} `json:"value"`
+			Events *bool       `json:"ev"`
+		} `json:"characteristics"`
+	}
+	if err := json.Unmarshal(body, &request); err != nil {
+		return jsonStatus(http.StatusBadRequest, statusInvalidValue)
+	}
+
+	failed, written := false, false
+	results := make([]*characteristicJSON, 0, len(request.Characteristics))
+	for _, item := range request.Characteristics {
+		id := characteristicID{item.AID, item.IID}
+		status := statusSuccess
+
+		ch := b.find(id)
+		switch {
+		case ch == nil:
+			status = statusResourceDoesNotExist
+		case item.Events != nil && !ch.hasPerm(permEvents):
+			status = statusNotificationsNotSupp
+		case item.Value != nil && ch.write == nil:
+			status = statusReadOnly
+		default:
+			if item.Events != nil {
+				c.setEvents(id, *item.Events)
+			}
+			if item.Value != nil {
+				if err := ch.write(ctx, item.Value); err == errInvalidValue {
+					status = statusInvalidValue
+				} else if err != nil {
+					status = statusCommunicationFailure
+				} else {
+					written = true
+				}
+			}
+		}
+
+		if status != statusSuccess {
+			failed = true
+		}
+		results = append(results, &characteristicJSON{AID: id.aid, IID: id.iid, Status: intPtr(status)})
+	}
+
+	if written {
+		// Tell other controllers right away instead of at the next refresh
+		go b.notify(context.WithoutCancel(ctx), c)
+	}
+	if !failed {
+		return &response{status: http.StatusNoContent}
+	}
+	return jsonResponse(http.StatusMultiStatus, map[string]interface{}{"characteristics": results})
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+// watch sends notifications for changed values until ctx is cancelled
+func (b *Bridge) watch(ctx context.Context) {
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			b.notify(ctx, nil)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// notify sends changed event values to the connections that asked for them.
+// The connection that wrote a change is not notified of it.
+func (b *Bridge) notify(ctx context.Context, writer *conn) {
+	state, err := b.state(ctx)
+	if err != nil {
+		b.logger.Warn("Failed to read sessions for HomeKit notifications", "error", err)
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var changed []*characteristicJSON
+	for _, a := range b.accessories {
+		for _, s := range a.services {
+			for _, ch := range s.characteristics {
+				if !ch.hasPerm(permEvents) {
+					continue
+				}
+				id := characteristicID{a.aid, ch.iid}
+				value := ch.read(state)
+				if previous, ok := b.values[id]; ok && previous == value {
+					continue
+				}
+				b.values[id] = value
+				changed = append(changed, &characteristicJSON{AID: a.aid, IID: ch.iid, Value: value})
+			}
+		}
+	}
+	if len(changed) == 0 {
+		return
+	}
+
+	for c := range b.conns {
+		if c == writer || !c.verified() {
+			continue
+		}
+		var events []*characteristicJSON
+		for _, event := range changed {
+			if c.eventsOn(characteristicID{event.AID, event.IID}) {
+				events = append(events, event)
+			}
+		}
+		if len(events) == 0 {
+			continue
+		}
+
+		body, _ := json.Marshal(map[string]interface{}{"characteristics": events})
+		message := fmt.Sprintf("EVENT/1.0 200 OK\r\nContent-Type: %s\r\nContent-Length: %d\r\n\r\n", contentTypeJSON, len(body))
+		if _, err := c.Write(append([]byte(message), body...)); err != nil {
+			b.logger.Debug("Failed to send HomeKit event", "remote", c.RemoteAddr(), "error", err)
+		}
+	}
+}
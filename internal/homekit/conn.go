@@ -0,0 +1,198 @@
+package homekit
+
+import (
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// maxFrame is the largest plaintext of an encrypted frame
+const maxFrame = 1024
+
+// deriveKey derives a 32-byte key with HKDF-SHA-512
+func deriveKey(secret []byte, salt, info string) ([]byte, error) {
+	return hkdf.Key(sha512.New, secret, []byte(salt), info, 32)
+}
+
+// labelNonce builds the 12-byte nonce used by pairing messages from an 8-byte label
+func labelNonce(label string) []byte {
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	copy(nonce[4:], label)
+	return nonce
+}
+
+// counterNonce builds the nonce of the nth frame
+func counterNonce(n uint64) []byte {
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	binary.LittleEndian.PutUint64(nonce[4:], n)
+	return nonce
+}
+
+// sealLabel encrypts a pairing message
+func sealLabel(key []byte, label string, plaintext []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nil, labelNonce(label), plaintext, nil), nil
+}
+
+// openLabel decrypts a pairing message
+func openLabel(key []byte, label string, ciphertext []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(key)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Open(nil, labelNonce(label), ciphertext, nil)
+}
+
+// conn is a controller connection. After pair-verify all traffic is split into
+// frames of a 2-byte length (also the authenticated data), the ciphertext and a tag.
+type conn struct {
+	net.Conn
+
+	writeMu    sync.Mutex
+	writeKey   cipher.AEAD // Accessory to controller
+	writeCount uint64
+
+	readKey   cipher.AEAD // Controller to accessory
+	readCount uint64
+	readBuf   []byte
+
+	verifyState *verifyState // Pair-verify in progress
+
+	mu           sync.Mutex
+	controllerID string                    // Set by pair-verify
+	events       map[characteristicID]bool // Characteristics with notifications on
+}
+
+func newConn(c net.Conn) *conn {
+	return &conn{Conn: c, events: make(map[characteristicID]bool)}
+}
+
+// verified reports whether the connection completed pair-verify
+func (c *conn) verified() bool {
+	return c.controller() != ""
+}
+
+// controller returns the ID of the verified controller
+func (c *conn) controller() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.controllerID
+}
+
+// setEvents turns notifications of a characteristic on or off
+func (c *conn) setEvents(id characteristicID, on bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if on {
+		c.events[id] = true
+	} else {
+		delete(c.events, id)
+	}
+}
+
+// eventsOn reports whether notifications of a characteristic are on
+func (c *conn) eventsOn(id characteristicID) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.events[id]
+}
+
+// upgrade starts encryption with the pair-verify shared secret
+func (c *conn) upgrade(sharedSecret []byte, controllerID string) error {
+	readKey, err := deriveKey(sharedSecret, "Control-Salt", "Control-Write-Encryption-Key")
+	if err != nil {
+		return err
+	}
+	writeKey, err := deriveKey(sharedSecret, "Control-Salt", "Control-Read-Encryption-Key")
+	if err != nil {
+		return err
+	}
+	readAEAD, err := chacha20poly1305.New(readKey)
+	if err != nil {
+		return err
+	}
+	writeAEAD, err := chacha20poly1305.New(writeKey)
+	if err != nil {
+		return err
+	}
+
+	c.writeMu.Lock()
+	c.writeKey = writeAEAD
+	c.writeMu.Unlock()
+	c.readKey = readAEAD
+
+	c.mu.Lock()
+	c.controllerID = controllerID
+	c.mu.Unlock()
+	return nil
+}
+
+// Read returns decrypted data once the connection is encrypted.
+// Only the connection's request loop reads, and it also starts encryption.
+func (c *conn) Read(p []byte) (int, error) {
+	if c.readKey == nil {
+		return c.Conn.Read(p)
+	}
+
+	if len(c.readBuf) == 0 {
+		header := make([]byte, 2)
+		if _, err := io.ReadFull(c.Conn, header); err != nil {
+			return 0, err
+		}
+		length := int(binary.LittleEndian.Uint16(header))
+		if length > maxFrame {
+			return 0, errors.New("encrypted frame too long")
+		}
+
+		frame := make([]byte, length+c.readKey.Overhead())
+		if _, err := io.ReadFull(c.Conn, frame); err != nil {
+			return 0, err
+		}
+		plaintext, err := c.readKey.Open(nil, counterNonce(c.readCount), frame, header)
+		if err != nil {
+			return 0, errors.New("failed to decrypt frame")
+		}
+		c.readCount++
+		c.readBuf = plaintext
+	}
+
+	n := copy(p, c.readBuf)
+	c.readBuf = c.readBuf[n:]
+	return n, nil
+}
+
+// Write sends p in one piece, encrypted once the connection is encrypted.
+// Responses and events are written whole, so they never interleave.
+func (c *conn) Write(p []byte) (int, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	if c.writeKey == nil {
+		return c.Conn.Write(p)
+	}
+
+	var out []byte
+	for rest := p; len(rest) > 0; {
+		n := min(len(rest), maxFrame)
+		header := make([]byte, 2)
+		binary.LittleEndian.PutUint16(header, uint16(n))
+		out = append(out, header...)
+		out = c.writeKey.Seal(out, counterNonce(c.writeCount), rest[:n], header)
+		c.writeCount++
+		rest = rest[n:]
+	}
+	if _, err := c.Conn.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,384 @@
+// Package homekit exposes Metron devices to Apple Home as a HomeKit bridge (HomeKit
+// Accessory Protocol over IP). Every device is an accessory with a switch that is on while
+// a session runs and a light sensor whose level is the session's remaining minutes, so Home
+// automations and Siri can observe sessions. Switches of devices with start settings also
+// start and stop sessions.
+package homekit
+
+import (
+	"bufio"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"metron/internal/core"
+)
+
+// DefaultPort is the TCP port of the bridge
+const DefaultPort = 51826
+
+// DefaultName is the bridge name shown in the Home app
+const DefaultName = "Metron"
+
+// refreshInterval is how often session state is checked for notifications
+const refreshInterval = 10 * time.Second
+
+// maxSetupAttempts is the number of wrong setup codes accepted before pairing is refused until restart
+const maxSetupAttempts = 100
+
+// setupCodePattern matches setup codes like "123-45-678"
+var setupCodePattern = regexp.MustCompile(`^\d{3}-\d{2}-\d{3}$`)
+
+// Identity is the bridge's long-term pairing identity.
+type Identity struct {
+	PairingID    string             // Accessory pairing ID, e.g. "1A:2B:3C:4D:5E:6F"
+	PrivateKey   ed25519.PrivateKey // Long-term signing key
+	ConfigHash   string             // Hash of the accessory database the config number belongs to
+	ConfigNumber int                // Incremented when the accessory database changes
+}
+
+// Pairing is a controller (an iOS device or home hub) paired with the bridge.
+type Pairing struct {
+	ControllerID string
+	PublicKey    ed25519.PublicKey
+	Admin        bool
+}
+
+// Storage defines the interface for HomeKit pairing persistence
+// This interface is implemented by the storage layer to avoid tight coupling
+type Storage interface {
+	GetHomeKitIdentity(ctx context.Context) (*Identity, error) // nil if none was saved
+	SaveHomeKitIdentity(ctx context.Context, identity *Identity) error
+	ListHomeKitPairings(ctx context.Context) ([]*Pairing, error)
+	SaveHomeKitPairing(ctx context.Context, pairing *Pairing) error // Adds or replaces by controller ID
+	DeleteHomeKitPairing(ctx context.Context, controllerID string) error
+}
+
+// Sessions lists, starts and stops sessions (implemented by the session manager)
+type Sessions interface {
+	ListActiveSessions(ctx context.Context) ([]*core.Session, error)
+	StartSession(ctx context.Context, deviceID string, childIDs []string, durationMinutes int) (*core.Session, error)
+	StopSession(ctx context.Context, sessionID string) error
+}
+
+// Device is a Metron device exposed as an accessory.
+type Device struct {
+	ID       string
+	Name     string
+	Type     string
+	ChildIDs []string // Children of sessions started from the switch (none = the switch is read-only)
+	Minutes  int      // Duration of sessions started from the switch
+}
+
+// Config contains the bridge settings.
+type Config struct {
+	Name      string // Bridge name (default "Metron")
+	SetupCode string // Code entered when adding the bridge, "XXX-XX-XXX"
+	Port      int    // TCP port (default 51826)
+	Devices   []Device
+}
+
+// Bridge is a HomeKit bridge accessory serving Metron devices.
+type Bridge struct {
+	config      Config
+	storage     Storage
+	sessions    Sessions
+	logger      *slog.Logger
+	accessories []*accessory
+
+	mu           sync.Mutex
+	identity     *Identity
+	conns        map[*conn]bool
+	setup        *setupState
+	failedSetups int
+	values       map[characteristicID]interface{} // Last notified values
+	onPairing    func()                           // Called when the paired state may have changed
+}
+
+// NewBridge creates a bridge for the devices.
+func NewBridge(config Config, storage Storage, sessions Sessions, logger *slog.Logger) (*Bridge, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	if !setupCodePattern.MatchString(config.SetupCode) {
+		return nil, fmt.Errorf("invalid homekit setup code %q: use the format 123-45-678", config.SetupCode)
+	}
+	if config.Name == "" {
+		config.Name = DefaultName
+	}
+	if config.Port == 0 {
+		config.Port = DefaultPort
+	}
+
+	b := &Bridge{
+		config:   config,
+		storage:  storage,
+		sessions: sessions,
+		logger:   logger.With("component", "homekit"),
+		conns:    make(map[*conn]bool),
+		values:   make(map[characteristicID]interface{}),
+	}
+	b.accessories = b.buildAccessories()
+	return b, nil
+}
+
+// Run advertises the bridge over mDNS and serves controllers until ctx is cancelled.
+func (b *Bridge) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", fmt.Sprintf(":%d", b.config.Port))
+	if err != nil {
+		return fmt.Errorf("failed to listen on port %d: %w", b.config.Port, err)
+	}
+	if err := b.load(ctx); err != nil {
+		listener.Close()
+		return err
+	}
+
+	responder, err := newResponder(b.config.Name, b.config.Port, b.txtRecords)
+	if err != nil {
+		listener.Close()
+		return err
+	}
+	b.mu.Lock()
+	b.onPairing = responder.announce
+	b.mu.Unlock()
+	go func() {
+		if err := responder.run(ctx); err != nil && ctx.Err() == nil {
+			b.logger.Error("mDNS responder stopped", "error", err)
+		}
+	}()
+
+	b.logger.Info("HomeKit bridge started",
+		"name", b.config.Name,
+		"port", b.config.Port,
+		"accessories", len(b.accessories),
+		"paired", b.paired(ctx))
+	return b.serve(ctx, listener)
+}
+
+// serve accepts controller connections and pushes notifications until ctx is cancelled
+func (b *Bridge) serve(ctx context.Context, listener net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+		b.mu.Lock()
+		for c := range b.conns {
+			c.Close()
+		}
+		b.mu.Unlock()
+	}()
+	go b.watch(ctx)
+
+	for {
+		raw, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		c := newConn(raw)
+		b.mu.Lock()
+		b.conns[c] = true
+		b.mu.Unlock()
+		go b.serveConn(ctx, c)
+	}
+}
+
+// load reads the identity, creating it on first start, and bumps the configuration
+// number when the accessory database changed
+func (b *Bridge) load(ctx context.Context) error {
+	identity, err := b.storage.GetHomeKitIdentity(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load homekit identity: %w", err)
+	}
+	if identity == nil {
+		identity, err = newIdentity()
+		if err != nil {
+			return err
+		}
+	}
+
+	if hash := databaseHash(b.accessories); hash != identity.ConfigHash {
+		identity.ConfigHash = hash
+		identity.ConfigNumber = identity.ConfigNumber%65535 + 1
+		if err := b.storage.SaveHomeKitIdentity(ctx, identity); err != nil {
+			return fmt.Errorf("failed to save homekit identity: %w", err)
+		}
+	}
+
+	b.mu.Lock()
+	b.identity = identity
+	b.mu.Unlock()
+	return nil
+}
+
+// newIdentity generates a pairing ID and signing key
+func newIdentity() (*Identity, error) {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 6)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+
+	parts := make([]string, len(id))
+	for i, octet := range id {
+		parts[i] = fmt.Sprintf("%02X", octet)
+	}
+	return &Identity{PairingID: strings.Join(parts, ":"), PrivateKey: privateKey}, nil
+}
+
+// paired reports whether any controller is paired
+func (b *Bridge) paired(ctx context.Context) bool {
+	pairings, err := b.storage.ListHomeKitPairings(ctx)
+	return err == nil && len(pairings) > 0
+}
+
+// txtRecords returns the mDNS TXT record of the bridge
+func (b *Bridge) txtRecords() []string {
+	b.mu.Lock()
+	identity := b.identity
+	b.mu.Unlock()
+
+	statusFlags := "1" // Not paired
+	if b.paired(context.Background()) {
+		statusFlags = "0"
+	}
+	return []string{
+		fmt.Sprintf("c#=%d", identity.ConfigNumber),
+		"ff=0",
+		"id=" + identity.PairingID,
+		"md=" + b.config.Name,
+		"pv=1.1",
+		"s#=1",
+		"sf=" + statusFlags,
+		"ci=2", // Bridge
+	}
+}
+
+// pairingChanged re-announces the bridge after pairings were added or removed
+func (b *Bridge) pairingChanged() {
+	b.mu.Lock()
+	onPairing := b.onPairing
+	b.mu.Unlock()
+	if onPairing != nil {
+		onPairing()
+	}
+}
+
+// response is an HTTP response written in one piece
+type response struct {
+	status      int
+	contentType string
+	body        []byte
+	after       func() error // Run after the response was written (e.g. start encryption)
+}
+
+// Content types
+const (
+	contentTypeTLV8 = "application/pairing+tlv8"
+	contentTypeJSON = "application/hap+json"
+)
+
+// statusText covers the HAP-specific status codes
+func statusText(status int) string {
+	if status == 470 {
+		return "Connection Authorization Required"
+	}
+	return http.StatusText(status)
+}
+
+// bytes serializes the response
+func (r *response) bytes() []byte {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "HTTP/1.1 %d %s\r\n", r.status, statusText(r.status))
+	if r.status != http.StatusNoContent {
+		fmt.Fprintf(&sb, "Content-Type: %s\r\nContent-Length: %d\r\n", r.contentType, len(r.body))
+	}
+	sb.WriteString("\r\n")
+	return append([]byte(sb.String()), r.body...)
+}
+
+// serveConn reads requests from one controller until it disconnects
+func (b *Bridge) serveConn(ctx context.Context, c *conn) {
+	defer func() {
+		b.mu.Lock()
+		delete(b.conns, c)
+		if b.setup != nil && b.setup.conn == c {
+			b.setup = nil
+		}
+		b.mu.Unlock()
+		c.Close()
+	}()
+
+	reader := bufio.NewReader(c)
+	for {
+		req, err := http.ReadRequest(reader)
+		if err != nil {
+			if !errors.Is(err, io.EOF) && ctx.Err() == nil {
+				b.logger.Debug("HomeKit connection closed", "remote", c.RemoteAddr(), "error", err)
+			}
+			return
+		}
+		body, err := io.ReadAll(req.Body)
+		if err != nil {
+			return
+		}
+
+		resp := b.handle(ctx, c, req, body)
+		if _, err := c.Write(resp.bytes()); err != nil {
+			return
+		}
+		if resp.after != nil {
+			if err := resp.after(); err != nil {
+				b.logger.Warn("HomeKit connection failed", "remote", c.RemoteAddr(), "error", err)
+				return
+			}
+		}
+	}
+}
+
+// handle routes a request
+func (b *Bridge) handle(ctx context.Context, c *conn, req *http.Request, body []byte) *response {
+	switch {
+	case req.Method == http.MethodPost && req.URL.Path == "/pair-setup":
+		return b.pairSetup(ctx, c, body)
+	case req.Method == http.MethodPost && req.URL.Path == "/pair-verify":
+		return b.pairVerify(ctx, c, body)
+	case req.Method == http.MethodPost && req.URL.Path == "/identify":
+		if b.paired(ctx) {
+			return jsonStatus(http.StatusBadRequest, statusInsufficientPrivileges)
+		}
+		b.logger.Info("HomeKit identify requested")
+		return &response{status: http.StatusNoContent}
+	}
+
+	if !c.verified() {
+		return jsonStatus(470, statusInsufficientPrivileges)
+	}
+
+	switch {
+	case req.Method == http.MethodGet && req.URL.Path == "/accessories":
+		return b.getAccessories(ctx)
+	case req.Method == http.MethodGet && req.URL.Path == "/characteristics":
+		return b.getCharacteristics(ctx, c, req.URL.Query())
+	case req.Method == http.MethodPut && req.URL.Path == "/characteristics":
+		return b.putCharacteristics(ctx, c, body)
+	case req.Method == http.MethodPost && req.URL.Path == "/pairings":
+		return b.pairings(ctx, c, body)
+	}
+	return &response{status: http.StatusNotFound, contentType: contentTypeJSON}
+}
@@ -0,0 +1,494 @@
+package homekit
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"metron/internal/core"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSetupCode = "031-45-154"
+
+// mockStorage keeps the identity and pairings in memory.
+type mockStorage struct {
+	mu       sync.Mutex
+	identity *Identity
+	pairings []*Pairing
+}
+
+func (m *mockStorage) GetHomeKitIdentity(_ context.Context) (*Identity, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.identity, nil
+}
+
+func (m *mockStorage) SaveHomeKitIdentity(_ context.Context, identity *Identity) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.identity = identity
+	return nil
+}
+
+func (m *mockStorage) ListHomeKitPairings(_ context.Context) ([]*Pairing, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*Pairing(nil), m.pairings...), nil
+}
+
+func (m *mockStorage) SaveHomeKitPairing(_ context.Context, pairing *Pairing) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, existing := range m.pairings {
+		if existing.ControllerID == pairing.ControllerID {
+			m.pairings[i] = pairing
+			return nil
+		}
+	}
+	m.pairings = append(m.pairings, pairing)
+	return nil
+}
+
+func (m *mockStorage) DeleteHomeKitPairing(_ context.Context, controllerID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, existing := range m.pairings {
+		if existing.ControllerID == controllerID {
+			m.pairings = append(m.pairings[:i], m.pairings[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// mockSessions keeps running sessions and records start requests.
+type mockSessions struct {
+	mu      sync.Mutex
+	active  []*core.Session
+	started []string
+}
+
+func (m *mockSessions) ListActiveSessions(_ context.Context) ([]*core.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*core.Session(nil), m.active...), nil
+}
+
+func (m *mockSessions) StartSession(_ context.Context, deviceID string, childIDs []string, minutes int) (*core.Session, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	session := &core.Session{
+		ID:               fmt.Sprintf("ses_%d", len(m.started)+1),
+		DeviceID:         deviceID,
+		ChildIDs:         childIDs,
+		StartTime:        time.Now(),
+		ExpectedDuration: minutes,
+		Status:           core.SessionStatusActive,
+	}
+	m.active = append(m.active, session)
+	m.started = append(m.started, fmt.Sprintf("%s %v %d", deviceID, childIDs, minutes))
+	return session, nil
+}
+
+func (m *mockSessions) StopSession(_ context.Context, sessionID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, session := range m.active {
+		if session.ID == sessionID {
+			m.active = append(m.active[:i], m.active[i+1:]...)
+		}
+	}
+	return nil
+}
+
+// controller is a test HomeKit controller.
+type controller struct {
+	t      *testing.T
+	id     string
+	key    ed25519.PrivateKey
+	conn   *conn
+	reader *bufio.Reader
+}
+
+func newController(t *testing.T, addr string) *controller {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return &controller{t: t, id: "controller-1", key: key, conn: dial(t, addr)}
+}
+
+func dial(t *testing.T, addr string) *conn {
+	t.Helper()
+	raw, err := net.Dial("tcp", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { raw.Close() })
+	return newConn(raw)
+}
+
+// reconnect opens a new connection for the same controller
+func (c *controller) reconnect(addr string) {
+	c.conn = dial(c.t, addr)
+	c.reader = nil
+}
+
+// do sends a request and returns the status and body
+func (c *controller) do(method, path, contentType string, body []byte) (int, []byte) {
+	c.t.Helper()
+	request := fmt.Sprintf("%s %s HTTP/1.1\r\nHost: bridge\r\nContent-Type: %s\r\nContent-Length: %d\r\n\r\n", method, path, contentType, len(body))
+	_, err := c.conn.Write(append([]byte(request), body...))
+	require.NoError(c.t, err)
+
+	if c.reader == nil {
+		c.reader = bufio.NewReader(c.conn)
+	}
+	resp, err := http.ReadResponse(c.reader, nil)
+	require.NoError(c.t, err)
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(c.t, err)
+	return resp.StatusCode, data
+}
+
+// tlv sends a pairing request and decodes the response
+func (c *controller) tlv(path string, request tlv8) tlv8 {
+	c.t.Helper()
+	status, body := c.do(http.MethodPost, path, contentTypeTLV8, request.encode())
+	require.Equal(c.t, http.StatusOK, status)
+	response, err := decodeTLV8(body)
+	require.NoError(c.t, err)
+	return response
+}
+
+// pairSetup pairs with the setup code and returns the bridge's long-term public key
+func (c *controller) pairSetup(setupCode string) (ed25519.PublicKey, byte) {
+	c.t.Helper()
+
+	var m1 tlv8
+	m1.addByte(tlvState, 1)
+	m1.addByte(tlvMethod, 0)
+	m2 := c.tlv("/pair-setup", m1)
+	if code, ok := m2.getByte(tlvError); ok {
+		return nil, code
+	}
+	salt, _ := m2.get(tlvSalt)
+	B, _ := m2.get(tlvPublicKey)
+
+	A, M1, key := srpClient(c.t, setupCode, salt, B)
+	var m3 tlv8
+	m3.addByte(tlvState, 3)
+	m3.add(tlvPublicKey, A)
+	m3.add(tlvProof, M1)
+	m4 := c.tlv("/pair-setup", m3)
+	if code, ok := m4.getByte(tlvError); ok {
+		return nil, code
+	}
+	M2, _ := m4.get(tlvProof)
+	require.Equal(c.t, srpHash(A, M1, key), M2)
+
+	encryptKey, err := deriveKey(key, "Pair-Setup-Encrypt-Salt", "Pair-Setup-Encrypt-Info")
+	require.NoError(c.t, err)
+	signKey, err := deriveKey(key, "Pair-Setup-Controller-Sign-Salt", "Pair-Setup-Controller-Sign-Info")
+	require.NoError(c.t, err)
+	publicKey := c.key.Public().(ed25519.PublicKey)
+
+	var sub tlv8
+	sub.add(tlvIdentifier, []byte(c.id))
+	sub.add(tlvPublicKey, publicKey)
+	sub.add(tlvSignature, ed25519.Sign(c.key, concat(signKey, []byte(c.id), publicKey)))
+	sealed, err := sealLabel(encryptKey, "PS-Msg05", sub.encode())
+	require.NoError(c.t, err)
+
+	var m5 tlv8
+	m5.addByte(tlvState, 5)
+	m5.add(tlvEncryptedData, sealed)
+	m6 := c.tlv("/pair-setup", m5)
+	if code, ok := m6.getByte(tlvError); ok {
+		return nil, code
+	}
+
+	encrypted, _ := m6.get(tlvEncryptedData)
+	plaintext, err := openLabel(encryptKey, "PS-Msg06", encrypted)
+	require.NoError(c.t, err)
+	reply, err := decodeTLV8(plaintext)
+	require.NoError(c.t, err)
+
+	accessoryID, _ := reply.get(tlvIdentifier)
+	accessoryKey, _ := reply.get(tlvPublicKey)
+	signature, _ := reply.get(tlvSignature)
+	accessoryX, err := deriveKey(key, "Pair-Setup-Accessory-Sign-Salt", "Pair-Setup-Accessory-Sign-Info")
+	require.NoError(c.t, err)
+	require.True(c.t, ed25519.Verify(accessoryKey, concat(accessoryX, accessoryID, accessoryKey), signature))
+	return accessoryKey, 0
+}
+
+// pairVerify starts an encrypted session
+func (c *controller) pairVerify(accessoryKey ed25519.PublicKey) byte {
+	c.t.Helper()
+
+	private, err := ecdh.X25519().GenerateKey(rand.Reader)
+	require.NoError(c.t, err)
+	var m1 tlv8
+	m1.addByte(tlvState, 1)
+	m1.add(tlvPublicKey, private.PublicKey().Bytes())
+	m2 := c.tlv("/pair-verify", m1)
+
+	accessoryPublic, _ := m2.get(tlvPublicKey)
+	peer, err := ecdh.X25519().NewPublicKey(accessoryPublic)
+	require.NoError(c.t, err)
+	sharedSecret, err := private.ECDH(peer)
+	require.NoError(c.t, err)
+	sessionKey, err := deriveKey(sharedSecret, "Pair-Verify-Encrypt-Salt", "Pair-Verify-Encrypt-Info")
+	require.NoError(c.t, err)
+
+	encrypted, _ := m2.get(tlvEncryptedData)
+	plaintext, err := openLabel(sessionKey, "PV-Msg02", encrypted)
+	require.NoError(c.t, err)
+	sub, err := decodeTLV8(plaintext)
+	require.NoError(c.t, err)
+	accessoryID, _ := sub.get(tlvIdentifier)
+	signature, _ := sub.get(tlvSignature)
+	require.True(c.t, ed25519.Verify(accessoryKey, concat(accessoryPublic, accessoryID, private.PublicKey().Bytes()), signature))
+
+	var proof tlv8
+	proof.add(tlvIdentifier, []byte(c.id))
+	proof.add(tlvSignature, ed25519.Sign(c.key, concat(private.PublicKey().Bytes(), []byte(c.id), accessoryPublic)))
+	sealed, err := sealLabel(sessionKey, "PV-Msg03", proof.encode())
+	require.NoError(c.t, err)
+
+	var m3 tlv8
+	m3.addByte(tlvState, 3)
+	m3.add(tlvEncryptedData, sealed)
+	m4 := c.tlv("/pair-verify", m3)
+	if code, ok := m4.getByte(tlvError); ok {
+		return code
+	}
+
+	// The controller's keys are the accessory's, swapped
+	require.NoError(c.t, c.conn.upgrade(sharedSecret, "accessory"))
+	c.conn.readKey, c.conn.writeKey = c.conn.writeKey, c.conn.readKey
+	return 0
+}
+
+// readEvent reads one EVENT message
+func (c *controller) readEvent() []byte {
+	c.t.Helper()
+	require.NoError(c.t, c.conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	line, err := c.reader.ReadString('\n')
+	require.NoError(c.t, err)
+	require.Equal(c.t, "EVENT/1.0 200 OK\r\n", line)
+
+	resp, err := http.ReadResponse(bufio.NewReader(io.MultiReader(strings.NewReader("HTTP/1.1 200 OK\r\n"), c.reader)), nil)
+	require.NoError(c.t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(c.t, err)
+	return body
+}
+
+func startBridge(t *testing.T, devices []Device) (*Bridge, *mockStorage, *mockSessions, string) {
+	t.Helper()
+
+	storage := &mockStorage{}
+	sessions := &mockSessions{}
+	bridge, err := NewBridge(Config{SetupCode: testSetupCode, Devices: devices}, storage, sessions, nil)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	require.NoError(t, bridge.load(ctx))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go bridge.serve(ctx, listener)
+	return bridge, storage, sessions, listener.Addr().String()
+}
+
+var testDevices = []Device{
+	{ID: "tv1", Name: "Living Room TV", Type: "tv", ChildIDs: []string{"alice"}, Minutes: 30},
+	{ID: "ps5", Name: "PlayStation", Type: "ps5"},
+}
+
+func TestBridge_PairAndControl(t *testing.T) {
+	bridge, storage, sessions, addr := startBridge(t, testDevices)
+	client := newController(t, addr)
+
+	// Not verified yet
+	status, _ := client.do(http.MethodGet, "/accessories", contentTypeJSON, nil)
+	assert.Equal(t, 470, status)
+
+	accessoryKey, code := client.pairSetup(testSetupCode)
+	require.Zero(t, code)
+	require.Len(t, storage.pairings, 1)
+	assert.True(t, storage.pairings[0].Admin)
+	assert.Contains(t, bridge.txtRecords(), "sf=0")
+
+	client.reconnect(addr)
+	require.Zero(t, client.pairVerify(accessoryKey))
+
+	status, body := client.do(http.MethodGet, "/accessories", contentTypeJSON, nil)
+	require.Equal(t, http.StatusOK, status)
+	var database struct {
+		Accessories []accessoryJSON `json:"accessories"`
+	}
+	require.NoError(t, json.Unmarshal(body, &database))
+	require.Len(t, database.Accessories, 3)
+	assert.Equal(t, uint64(1), database.Accessories[0].AID)
+
+	tvAID := accessoryID("tv1")
+	status, _ = client.do(http.MethodPut, "/characteristics", contentTypeJSON,
+		[]byte(fmt.Sprintf(`{"characteristics":[{"aid":%d,"iid":10,"value":1}]}`, tvAID)))
+	require.Equal(t, http.StatusNoContent, status)
+	assert.Equal(t, []string{"tv1 [alice] 30"}, sessions.started)
+
+	status, body = client.do(http.MethodGet, fmt.Sprintf("/characteristics?id=%d.10,%d.13", tvAID, tvAID), contentTypeJSON, nil)
+	require.Equal(t, http.StatusOK, status)
+	assert.JSONEq(t, fmt.Sprintf(`{"characteristics":[{"aid":%d,"iid":10,"value":true},{"aid":%d,"iid":13,"value":29}]}`, tvAID, tvAID), string(body))
+
+	status, _ = client.do(http.MethodPut, "/characteristics", contentTypeJSON,
+		[]byte(fmt.Sprintf(`{"characteristics":[{"aid":%d,"iid":10,"value":false}]}`, tvAID)))
+	require.Equal(t, http.StatusNoContent, status)
+	assert.Empty(t, sessions.active)
+}
+
+func TestBridge_CharacteristicErrors(t *testing.T) {
+	_, _, _, addr := startBridge(t, testDevices)
+	client := newController(t, addr)
+	accessoryKey, code := client.pairSetup(testSetupCode)
+	require.Zero(t, code)
+	client.reconnect(addr)
+	require.Zero(t, client.pairVerify(accessoryKey))
+
+	psAID := accessoryID("ps5")
+	// The PlayStation has no start settings, so its switch is read-only
+	status, body := client.do(http.MethodPut, "/characteristics", contentTypeJSON,
+		[]byte(fmt.Sprintf(`{"characteristics":[{"aid":%d,"iid":10,"value":true},{"aid":%d,"iid":5,"ev":true}]}`, psAID, psAID)))
+	require.Equal(t, http.StatusMultiStatus, status)
+	assert.JSONEq(t, fmt.Sprintf(`{"characteristics":[{"aid":%d,"iid":10,"status":%d},{"aid":%d,"iid":5,"status":%d}]}`,
+		psAID, statusReadOnly, psAID, statusNotificationsNotSupp), string(body))
+
+	status, body = client.do(http.MethodGet, fmt.Sprintf("/characteristics?id=%d.10,%d.2,99.1", psAID, psAID), contentTypeJSON, nil)
+	require.Equal(t, http.StatusMultiStatus, status)
+	assert.JSONEq(t, fmt.Sprintf(`{"characteristics":[{"aid":%d,"iid":10,"value":false,"status":0},{"aid":%d,"iid":2,"status":%d},{"aid":99,"iid":1,"status":%d}]}`,
+		psAID, psAID, statusWriteOnly, statusResourceDoesNotExist), string(body))
+}
+
+func TestBridge_Events(t *testing.T) {
+	bridge, storage, sessions, addr := startBridge(t, testDevices)
+	client := newController(t, addr)
+	accessoryKey, code := client.pairSetup(testSetupCode)
+	require.Zero(t, code)
+	client.reconnect(addr)
+	require.Zero(t, client.pairVerify(accessoryKey))
+
+	// A second controller, added by the admin
+	_, secondKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	var add tlv8
+	add.addByte(tlvState, 1)
+	add.addByte(tlvMethod, methodAddPairing)
+	add.add(tlvIdentifier, []byte("controller-2"))
+	add.add(tlvPublicKey, secondKey.Public().(ed25519.PublicKey))
+	add.addByte(tlvPermissions, 0)
+	client.tlv("/pairings", add)
+	require.Len(t, storage.pairings, 2)
+
+	second := &controller{t: t, id: "controller-2", key: secondKey, conn: dial(t, addr)}
+	require.Zero(t, second.pairVerify(accessoryKey))
+
+	// Record current values, then subscribe
+	bridge.notify(context.Background(), nil)
+	psAID := accessoryID("ps5")
+	status, _ := second.do(http.MethodPut, "/characteristics", contentTypeJSON,
+		[]byte(fmt.Sprintf(`{"characteristics":[{"aid":%d,"iid":10,"ev":true}]}`, psAID)))
+	require.Equal(t, http.StatusNoContent, status)
+
+	_, err = sessions.StartSession(context.Background(), "ps5", []string{"bob"}, 60)
+	require.NoError(t, err)
+	bridge.notify(context.Background(), nil)
+
+	assert.JSONEq(t, fmt.Sprintf(`{"characteristics":[{"aid":%d,"iid":10,"value":true}]}`, psAID), string(second.readEvent()))
+}
+
+func TestBridge_PairSetupRefusals(t *testing.T) {
+	_, _, _, addr := startBridge(t, testDevices)
+
+	client := newController(t, addr)
+	_, code := client.pairSetup("111-22-333")
+	assert.Equal(t, byte(tlvErrorAuthentication), code)
+
+	client.reconnect(addr)
+	accessoryKey, code := client.pairSetup(testSetupCode)
+	require.Zero(t, code)
+
+	// Paired bridges refuse pair-setup
+	other := newController(t, addr)
+	_, code = other.pairSetup(testSetupCode)
+	assert.Equal(t, byte(tlvErrorUnavailable), code)
+
+	// Unknown controllers fail pair-verify
+	other.reconnect(addr)
+	assert.Equal(t, byte(tlvErrorAuthentication), other.pairVerify(accessoryKey))
+}
+
+func TestBridge_RemoveLastAdmin(t *testing.T) {
+	bridge, storage, _, addr := startBridge(t, testDevices)
+	client := newController(t, addr)
+	accessoryKey, code := client.pairSetup(testSetupCode)
+	require.Zero(t, code)
+	client.reconnect(addr)
+	require.Zero(t, client.pairVerify(accessoryKey))
+
+	var remove tlv8
+	remove.addByte(tlvState, 1)
+	remove.addByte(tlvMethod, methodRemovePairing)
+	remove.add(tlvIdentifier, []byte(client.id))
+	response := client.tlv("/pairings", remove)
+	_, failed := response.getByte(tlvError)
+	assert.False(t, failed)
+
+	assert.Empty(t, storage.pairings)
+	assert.Contains(t, bridge.txtRecords(), "sf=1")
+}
+
+func TestBridge_ConfigNumber(t *testing.T) {
+	storage := &mockStorage{}
+	ctx := context.Background()
+
+	bridge, err := NewBridge(Config{SetupCode: testSetupCode, Devices: testDevices}, storage, &mockSessions{}, nil)
+	require.NoError(t, err)
+	require.NoError(t, bridge.load(ctx))
+	assert.Equal(t, 1, storage.identity.ConfigNumber)
+	pairingID := storage.identity.PairingID
+
+	// Same devices keep the number
+	bridge, err = NewBridge(Config{SetupCode: testSetupCode, Devices: testDevices}, storage, &mockSessions{}, nil)
+	require.NoError(t, err)
+	require.NoError(t, bridge.load(ctx))
+	assert.Equal(t, 1, storage.identity.ConfigNumber)
+
+	// A new device bumps it, the identity stays
+	bridge, err = NewBridge(Config{SetupCode: testSetupCode, Devices: testDevices[:1]}, storage, &mockSessions{}, nil)
+	require.NoError(t, err)
+	require.NoError(t, bridge.load(ctx))
+	assert.Equal(t, 2, storage.identity.ConfigNumber)
+	assert.Equal(t, pairingID, storage.identity.PairingID)
+}
+
+func TestNewBridge_InvalidSetupCode(t *testing.T) {
+	_, err := NewBridge(Config{SetupCode: "12345678"}, &mockStorage{}, &mockSessions{}, nil)
+	assert.Error(t, err)
+}
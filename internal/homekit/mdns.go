@@ -0,0 +1,266 @@
+package homekit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// mDNS group and port
+var mdnsGroup = &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+
+// Record TTLs recommended by RFC 6762
+const (
+	hostTTL    = 120
+	serviceTTL = 4500
+)
+
+// hapService is the DNS-SD service type of HomeKit accessories
+const hapService = "_hap._tcp.local."
+
+// servicesQuery lists service types (DNS-SD service type enumeration)
+const servicesQuery = "_services._dns-sd._udp.local."
+
+// responder advertises the bridge with multicast DNS so controllers can find it
+type responder struct {
+	instance string // e.g. "Metron._hap._tcp.local."
+	host     string // e.g. "nas.local."
+	port     int
+	txt      func() []string
+
+	mu   sync.Mutex
+	conn *net.UDPConn
+}
+
+// newResponder creates a responder for the service instance name
+func newResponder(name string, port int, txt func() []string) (*responder, error) {
+	if name == "" || strings.ContainsAny(name, ".") {
+		return nil, fmt.Errorf("invalid homekit name %q: must not be empty or contain dots", name)
+	}
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get hostname: %w", err)
+	}
+	hostname, _, _ = strings.Cut(hostname, ".")
+
+	return &responder{
+		instance: name + "." + hapService,
+		host:     hostname + ".local.",
+		port:     port,
+		txt:      txt,
+	}, nil
+}
+
+// run answers queries until ctx is cancelled, then says goodbye
+func (r *responder) run(ctx context.Context) error {
+	conn, err := net.ListenMulticastUDP("udp4", nil, mdnsGroup)
+	if err != nil {
+		return fmt.Errorf("failed to join mDNS group: %w", err)
+	}
+	r.mu.Lock()
+	r.conn = conn
+	r.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		r.send(r.announcement(0), mdnsGroup)
+		conn.Close()
+	}()
+
+	// Announce twice, one second apart (RFC 6762 section 8.3)
+	r.announce()
+	time.AfterFunc(time.Second, r.announce)
+
+	buf := make([]byte, 9000)
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if ctx.Err() != nil || errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		answer := r.answer(buf[:n], from.Port != mdnsGroup.Port)
+		if answer == nil {
+			continue
+		}
+		to := mdnsGroup
+		if from.Port != mdnsGroup.Port {
+			to = from // Legacy unicast query (RFC 6762 section 6.7)
+		}
+		r.send(answer, to)
+	}
+}
+
+// announce sends all records unsolicited, e.g. after the TXT record changed
+func (r *responder) announce() {
+	r.send(r.announcement(1), mdnsGroup)
+}
+
+func (r *responder) send(message []byte, to *net.UDPAddr) {
+	r.mu.Lock()
+	conn := r.conn
+	r.mu.Unlock()
+	if conn == nil || message == nil {
+		return
+	}
+	conn.WriteToUDP(message, to)
+}
+
+// announcement is a response with every record; ttlScale 0 makes it a goodbye
+func (r *responder) announcement(ttlScale uint32) []byte {
+	var answers []dnsmessage.Resource
+	answers = append(answers, r.ptr(ttlScale), r.srv(ttlScale), r.txtRecord(ttlScale))
+	answers = append(answers, r.addresses(ttlScale)...)
+	return r.pack(0, nil, answers, nil)
+}
+
+// answer returns the response to a query, or nil if it asks for nothing of ours.
+// Legacy unicast responses repeat the question and use the query ID.
+func (r *responder) answer(query []byte, legacy bool) []byte {
+	var parser dnsmessage.Parser
+	header, err := parser.Start(query)
+	if err != nil || header.Response {
+		return nil
+	}
+	questions, err := parser.AllQuestions()
+	if err != nil {
+		return nil
+	}
+
+	var answers, additionals []dnsmessage.Resource
+	var asked []dnsmessage.Question
+	for _, q := range questions {
+		name := strings.ToLower(q.Name.String())
+		matched := true
+		switch {
+		case name == hapService && wants(q, dnsmessage.TypePTR):
+			answers = append(answers, r.ptr(1))
+			additionals = append(additionals, r.srv(1), r.txtRecord(1))
+			additionals = append(additionals, r.addresses(1)...)
+		case name == servicesQuery && wants(q, dnsmessage.TypePTR):
+			answers = append(answers, r.serviceType())
+		case name == strings.ToLower(r.instance) && (wants(q, dnsmessage.TypeSRV) || wants(q, dnsmessage.TypeTXT)):
+			if wants(q, dnsmessage.TypeSRV) {
+				answers = append(answers, r.srv(1))
+				additionals = append(additionals, r.addresses(1)...)
+			}
+			if wants(q, dnsmessage.TypeTXT) {
+				answers = append(answers, r.txtRecord(1))
+			}
+		case name == strings.ToLower(r.host) && wants(q, dnsmessage.TypeA):
+			answers = append(answers, r.addresses(1)...)
+		default:
+			matched = false
+		}
+		if matched {
+			asked = append(asked, q)
+		}
+	}
+	if len(answers) == 0 {
+		return nil
+	}
+
+	if !legacy {
+		return r.pack(0, nil, answers, additionals)
+	}
+	for i := range asked {
+		asked[i].Class &^= 1 << 15 // Clear the unicast-response bit
+	}
+	for _, records := range [][]dnsmessage.Resource{answers, additionals} {
+		for i := range records {
+			records[i].Header.Class &^= 1 << 15 // No cache-flush bit in legacy responses
+			records[i].Header.TTL = min(records[i].Header.TTL, 10)
+		}
+	}
+	return r.pack(header.ID, asked, answers, additionals)
+}
+
+// wants reports whether a question asks for a record type
+func wants(q dnsmessage.Question, typ dnsmessage.Type) bool {
+	return q.Type == typ || q.Type == dnsmessage.TypeALL
+}
+
+func (r *responder) pack(id uint16, questions []dnsmessage.Question, answers, additionals []dnsmessage.Resource) []byte {
+	message := dnsmessage.Message{
+		Header:      dnsmessage.Header{ID: id, Response: true, Authoritative: true},
+		Questions:   questions,
+		Answers:     answers,
+		Additionals: additionals,
+	}
+	packed, err := message.Pack()
+	if err != nil {
+		return nil
+	}
+	return packed
+}
+
+// header builds a resource header; unique records get the cache-flush bit
+func header(name string, typ dnsmessage.Type, ttl uint32, unique bool) dnsmessage.ResourceHeader {
+	class := dnsmessage.ClassINET
+	if unique {
+		class |= 1 << 15
+	}
+	return dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName(name), Type: typ, Class: class, TTL: ttl}
+}
+
+func (r *responder) ptr(ttlScale uint32) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: header(hapService, dnsmessage.TypePTR, serviceTTL*ttlScale, false),
+		Body:   &dnsmessage.PTRResource{PTR: dnsmessage.MustNewName(r.instance)},
+	}
+}
+
+func (r *responder) serviceType() dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: header(servicesQuery, dnsmessage.TypePTR, serviceTTL, false),
+		Body:   &dnsmessage.PTRResource{PTR: dnsmessage.MustNewName(hapService)},
+	}
+}
+
+func (r *responder) srv(ttlScale uint32) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: header(r.instance, dnsmessage.TypeSRV, hostTTL*ttlScale, true),
+		Body:   &dnsmessage.SRVResource{Port: uint16(r.port), Target: dnsmessage.MustNewName(r.host)},
+	}
+}
+
+func (r *responder) txtRecord(ttlScale uint32) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: header(r.instance, dnsmessage.TypeTXT, serviceTTL*ttlScale, true),
+		Body:   &dnsmessage.TXTResource{TXT: r.txt()},
+	}
+}
+
+// addresses returns an A record for every non-loopback IPv4 address
+func (r *responder) addresses(ttlScale uint32) []dnsmessage.Resource {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	var records []dnsmessage.Resource
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() {
+			continue
+		}
+		ip4 := ipNet.IP.To4()
+		if ip4 == nil {
+			continue
+		}
+		var a [4]byte
+		copy(a[:], ip4)
+		records = append(records, dnsmessage.Resource{
+			Header: header(r.host, dnsmessage.TypeA, hostTTL*ttlScale, true),
+			Body:   &dnsmessage.AResource{A: a},
+		})
+	}
+	return records
+}
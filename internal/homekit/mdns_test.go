@@ -0,0 +1,93 @@
+package homekit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func testResponder(t *testing.T) *responder {
+	t.Helper()
+	r, err := newResponder("Metron", 51826, func() []string { return []string{"id=1A:2B:3C:4D:5E:6F", "sf=1"} })
+	require.NoError(t, err)
+	r.host = "nas.local."
+	return r
+}
+
+func query(t *testing.T, id uint16, name string, typ dnsmessage.Type) []byte {
+	t.Helper()
+	message := dnsmessage.Message{
+		Header:    dnsmessage.Header{ID: id},
+		Questions: []dnsmessage.Question{{Name: dnsmessage.MustNewName(name), Type: typ, Class: dnsmessage.ClassINET}},
+	}
+	packed, err := message.Pack()
+	require.NoError(t, err)
+	return packed
+}
+
+func TestResponder_BrowseAnswer(t *testing.T) {
+	r := testResponder(t)
+
+	var message dnsmessage.Message
+	require.NoError(t, message.Unpack(r.answer(query(t, 0, "_hap._tcp.local.", dnsmessage.TypePTR), false)))
+
+	assert.True(t, message.Header.Response)
+	require.Len(t, message.Answers, 1)
+	assert.Equal(t, "Metron._hap._tcp.local.", message.Answers[0].Body.(*dnsmessage.PTRResource).PTR.String())
+	assert.Empty(t, message.Questions)
+
+	var srv *dnsmessage.SRVResource
+	var txt *dnsmessage.TXTResource
+	for _, record := range message.Additionals {
+		switch body := record.Body.(type) {
+		case *dnsmessage.SRVResource:
+			srv = body
+			assert.Equal(t, dnsmessage.ClassINET|1<<15, record.Header.Class)
+		case *dnsmessage.TXTResource:
+			txt = body
+		}
+	}
+	require.NotNil(t, srv)
+	assert.Equal(t, uint16(51826), srv.Port)
+	assert.Equal(t, "nas.local.", srv.Target.String())
+	require.NotNil(t, txt)
+	assert.Equal(t, []string{"id=1A:2B:3C:4D:5E:6F", "sf=1"}, txt.TXT)
+}
+
+func TestResponder_LegacyUnicast(t *testing.T) {
+	r := testResponder(t)
+
+	var message dnsmessage.Message
+	require.NoError(t, message.Unpack(r.answer(query(t, 42, "metron._hap._tcp.local.", dnsmessage.TypeTXT), true)))
+
+	assert.Equal(t, uint16(42), message.Header.ID)
+	require.Len(t, message.Questions, 1)
+	require.Len(t, message.Answers, 1)
+	assert.Equal(t, dnsmessage.ClassINET, message.Answers[0].Header.Class)
+	assert.LessOrEqual(t, message.Answers[0].Header.TTL, uint32(10))
+}
+
+func TestResponder_IgnoresOtherQueries(t *testing.T) {
+	r := testResponder(t)
+
+	assert.Nil(t, r.answer(query(t, 0, "_airplay._tcp.local.", dnsmessage.TypePTR), false))
+	assert.Nil(t, r.answer(query(t, 0, "Metron._hap._tcp.local.", dnsmessage.TypeA), false))
+}
+
+func TestResponder_Goodbye(t *testing.T) {
+	r := testResponder(t)
+
+	var message dnsmessage.Message
+	require.NoError(t, message.Unpack(r.announcement(0)))
+	require.NotEmpty(t, message.Answers)
+	for _, record := range message.Answers {
+		assert.Zero(t, record.Header.TTL)
+	}
+}
+
+func TestNewResponder_RejectsDots(t *testing.T) {
+	_, err := newResponder("Metron.Home", 51826, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,450 @@
+package homekit
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdh"
+	"crypto/ed25519"
+	"crypto/rand"
+	"net/http"
+)
+
+// Pairing methods
+const (
+	methodAddPairing    = 3
+	methodRemovePairing = 4
+	methodListPairings  = 5
+)
+
+// Permissions of a pairing
+const permissionAdmin = 0x01
+
+// setupState is the pair-setup in progress; only one controller may pair at a time
+type setupState struct {
+	conn *conn
+	srp  *srpServer
+}
+
+// verifyState is the pair-verify in progress on a connection
+type verifyState struct {
+	sharedSecret     []byte
+	sessionKey       []byte
+	accessoryPublic  []byte
+	controllerPublic []byte
+}
+
+// tlvResponse returns a pairing response
+func tlvResponse(t tlv8) *response {
+	return &response{status: http.StatusOK, contentType: contentTypeTLV8, body: t.encode()}
+}
+
+// pairingError returns a pairing error for the state
+func pairingError(state, code byte) *response {
+	var t tlv8
+	t.addByte(tlvState, state)
+	t.addByte(tlvError, code)
+	return tlvResponse(t)
+}
+
+// pairSetup handles the SRP exchange (M1-M4) and the key exchange (M5-M6) that pair
+// a new controller with the setup code
+func (b *Bridge) pairSetup(ctx context.Context, c *conn, body []byte) *response {
+	request, err := decodeTLV8(body)
+	if err != nil {
+		return pairingError(2, tlvErrorUnknown)
+	}
+	state, _ := request.getByte(tlvState)
+
+	switch state {
+	case 1:
+		return b.pairSetupStart(ctx, c)
+	case 3:
+		return b.pairSetupVerify(c, request)
+	case 5:
+		return b.pairSetupExchange(ctx, c, request)
+	}
+	return pairingError(state+1, tlvErrorUnknown)
+}
+
+// pairSetupStart answers M1 with the salt and the SRP public key
+func (b *Bridge) pairSetupStart(ctx context.Context, c *conn) *response {
+	if b.paired(ctx) {
+		return pairingError(2, tlvErrorUnavailable)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failedSetups >= maxSetupAttempts {
+		return pairingError(2, tlvErrorMaxTries)
+	}
+	if b.setup != nil && b.setup.conn != c {
+		return pairingError(2, tlvErrorBusy)
+	}
+
+	srp, err := newSRPServer(b.config.SetupCode)
+	if err != nil {
+		return pairingError(2, tlvErrorUnknown)
+	}
+	b.setup = &setupState{conn: c, srp: srp}
+
+	var t tlv8
+	t.addByte(tlvState, 2)
+	t.add(tlvSalt, srp.salt)
+	t.add(tlvPublicKey, srp.B)
+	return tlvResponse(t)
+}
+
+// pairSetupVerify checks the controller's proof of the setup code (M3) and answers with
+// the accessory proof
+func (b *Bridge) pairSetupVerify(c *conn, request tlv8) *response {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.setup == nil || b.setup.conn != c {
+		return pairingError(4, tlvErrorUnknown)
+	}
+	publicKey, _ := request.get(tlvPublicKey)
+	proof, _ := request.get(tlvProof)
+
+	serverProof, err := b.setup.srp.verify(publicKey, proof)
+	if err != nil {
+		b.failedSetups++
+		b.setup = nil
+		b.logger.Warn("HomeKit pairing attempt with a wrong setup code", "remote", c.RemoteAddr())
+		return pairingError(4, tlvErrorAuthentication)
+	}
+
+	var t tlv8
+	t.addByte(tlvState, 4)
+	t.add(tlvProof, serverProof)
+	return tlvResponse(t)
+}
+
+// pairSetupExchange stores the controller's long-term key (M5) and answers with the
+// bridge's signed identity
+func (b *Bridge) pairSetupExchange(ctx context.Context, c *conn, request tlv8) *response {
+	b.mu.Lock()
+	setup := b.setup
+	identity := b.identity
+	b.mu.Unlock()
+
+	if setup == nil || setup.conn != c || setup.srp.key == nil {
+		return pairingError(6, tlvErrorUnknown)
+	}
+	defer func() {
+		b.mu.Lock()
+		b.setup = nil
+		b.mu.Unlock()
+	}()
+
+	key := setup.srp.key
+	encryptKey, err := deriveKey(key, "Pair-Setup-Encrypt-Salt", "Pair-Setup-Encrypt-Info")
+	if err != nil {
+		return pairingError(6, tlvErrorUnknown)
+	}
+	encrypted, _ := request.get(tlvEncryptedData)
+	plaintext, err := openLabel(encryptKey, "PS-Msg05", encrypted)
+	if err != nil {
+		return pairingError(6, tlvErrorAuthentication)
+	}
+	sub, err := decodeTLV8(plaintext)
+	if err != nil {
+		return pairingError(6, tlvErrorUnknown)
+	}
+
+	controllerID, _ := sub.get(tlvIdentifier)
+	controllerKey, _ := sub.get(tlvPublicKey)
+	signature, _ := sub.get(tlvSignature)
+	if len(controllerKey) != ed25519.PublicKeySize {
+		return pairingError(6, tlvErrorAuthentication)
+	}
+
+	controllerX, err := deriveKey(key, "Pair-Setup-Controller-Sign-Salt", "Pair-Setup-Controller-Sign-Info")
+	if err != nil {
+		return pairingError(6, tlvErrorUnknown)
+	}
+	if !ed25519.Verify(controllerKey, concat(controllerX, controllerID, controllerKey), signature) {
+		return pairingError(6, tlvErrorAuthentication)
+	}
+
+	pairing := &Pairing{ControllerID: string(controllerID), PublicKey: controllerKey, Admin: true}
+	if err := b.storage.SaveHomeKitPairing(ctx, pairing); err != nil {
+		b.logger.Error("Failed to save HomeKit pairing", "error", err)
+		return pairingError(6, tlvErrorUnknown)
+	}
+
+	accessoryX, err := deriveKey(key, "Pair-Setup-Accessory-Sign-Salt", "Pair-Setup-Accessory-Sign-Info")
+	if err != nil {
+		return pairingError(6, tlvErrorUnknown)
+	}
+	publicKey := identity.PrivateKey.Public().(ed25519.PublicKey)
+	accessorySignature := ed25519.Sign(identity.PrivateKey, concat(accessoryX, []byte(identity.PairingID), publicKey))
+
+	var reply tlv8
+	reply.add(tlvIdentifier, []byte(identity.PairingID))
+	reply.add(tlvPublicKey, publicKey)
+	reply.add(tlvSignature, accessorySignature)
+	sealed, err := sealLabel(encryptKey, "PS-Msg06", reply.encode())
+	if err != nil {
+		return pairingError(6, tlvErrorUnknown)
+	}
+
+	b.logger.Info("HomeKit controller paired", "controller_id", pairing.ControllerID)
+	b.pairingChanged()
+
+	var t tlv8
+	t.addByte(tlvState, 6)
+	t.add(tlvEncryptedData, sealed)
+	return tlvResponse(t)
+}
+
+// pairVerify handles the key agreement (M1-M2) and the controller's signed proof (M3-M4)
+// that start an encrypted session with a paired controller
+func (b *Bridge) pairVerify(ctx context.Context, c *conn, body []byte) *response {
+	request, err := decodeTLV8(body)
+	if err != nil {
+		return pairingError(2, tlvErrorUnknown)
+	}
+	state, _ := request.getByte(tlvState)
+
+	switch state {
+	case 1:
+		return b.pairVerifyStart(c, request)
+	case 3:
+		return b.pairVerifyFinish(ctx, c, request)
+	}
+	return pairingError(state+1, tlvErrorUnknown)
+}
+
+// pairVerifyStart answers M1 with an ephemeral key and the bridge's signed identity
+func (b *Bridge) pairVerifyStart(c *conn, request tlv8) *response {
+	controllerPublic, _ := request.get(tlvPublicKey)
+	peer, err := ecdh.X25519().NewPublicKey(controllerPublic)
+	if err != nil {
+		return pairingError(2, tlvErrorUnknown)
+	}
+	private, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return pairingError(2, tlvErrorUnknown)
+	}
+	sharedSecret, err := private.ECDH(peer)
+	if err != nil {
+		return pairingError(2, tlvErrorUnknown)
+	}
+	sessionKey, err := deriveKey(sharedSecret, "Pair-Verify-Encrypt-Salt", "Pair-Verify-Encrypt-Info")
+	if err != nil {
+		return pairingError(2, tlvErrorUnknown)
+	}
+
+	b.mu.Lock()
+	identity := b.identity
+	b.mu.Unlock()
+
+	accessoryPublic := private.PublicKey().Bytes()
+	signature := ed25519.Sign(identity.PrivateKey, concat(accessoryPublic, []byte(identity.PairingID), controllerPublic))
+
+	var sub tlv8
+	sub.add(tlvIdentifier, []byte(identity.PairingID))
+	sub.add(tlvSignature, signature)
+	sealed, err := sealLabel(sessionKey, "PV-Msg02", sub.encode())
+	if err != nil {
+		return pairingError(2, tlvErrorUnknown)
+	}
+
+	c.verifyState = &verifyState{
+		sharedSecret:     sharedSecret,
+		sessionKey:       sessionKey,
+		accessoryPublic:  accessoryPublic,
+		controllerPublic: controllerPublic,
+	}
+
+	var t tlv8
+	t.addByte(tlvState, 2)
+	t.add(tlvPublicKey, accessoryPublic)
+	t.add(tlvEncryptedData, sealed)
+	return tlvResponse(t)
+}
+
+// pairVerifyFinish checks the controller's signature (M3) and starts encryption after M4
+func (b *Bridge) pairVerifyFinish(ctx context.Context, c *conn, request tlv8) *response {
+	verify := c.verifyState
+	c.verifyState = nil
+	if verify == nil {
+		return pairingError(4, tlvErrorUnknown)
+	}
+
+	encrypted, _ := request.get(tlvEncryptedData)
+	plaintext, err := openLabel(verify.sessionKey, "PV-Msg03", encrypted)
+	if err != nil {
+		return pairingError(4, tlvErrorAuthentication)
+	}
+	sub, err := decodeTLV8(plaintext)
+	if err != nil {
+		return pairingError(4, tlvErrorUnknown)
+	}
+	controllerID, _ := sub.get(tlvIdentifier)
+	signature, _ := sub.get(tlvSignature)
+
+	pairing, err := b.findPairing(ctx, string(controllerID))
+	if err != nil || pairing == nil {
+		return pairingError(4, tlvErrorAuthentication)
+	}
+	if !ed25519.Verify(pairing.PublicKey, concat(verify.controllerPublic, controllerID, verify.accessoryPublic), signature) {
+		return pairingError(4, tlvErrorAuthentication)
+	}
+
+	var t tlv8
+	t.addByte(tlvState, 4)
+	resp := tlvResponse(t)
+	resp.after = func() error {
+		return c.upgrade(verify.sharedSecret, pairing.ControllerID)
+	}
+	return resp
+}
+
+// pairings adds, removes and lists pairings for admin controllers
+func (b *Bridge) pairings(ctx context.Context, c *conn, body []byte) *response {
+	request, err := decodeTLV8(body)
+	if err != nil {
+		return pairingError(2, tlvErrorUnknown)
+	}
+
+	current, err := b.findPairing(ctx, c.controller())
+	if err != nil || current == nil || !current.Admin {
+		return pairingError(2, tlvErrorAuthentication)
+	}
+
+	method, _ := request.getByte(tlvMethod)
+	identifier, _ := request.get(tlvIdentifier)
+	switch method {
+	case methodAddPairing:
+		publicKey, _ := request.get(tlvPublicKey)
+		permissions, _ := request.getByte(tlvPermissions)
+		existing, err := b.findPairing(ctx, string(identifier))
+		if err != nil {
+			return pairingError(2, tlvErrorUnknown)
+		}
+		if existing != nil && !bytes.Equal(existing.PublicKey, publicKey) {
+			return pairingError(2, tlvErrorUnknown)
+		}
+		if len(publicKey) != ed25519.PublicKeySize {
+			return pairingError(2, tlvErrorUnknown)
+		}
+		pairing := &Pairing{ControllerID: string(identifier), PublicKey: publicKey, Admin: permissions&permissionAdmin != 0}
+		if err := b.storage.SaveHomeKitPairing(ctx, pairing); err != nil {
+			return pairingError(2, tlvErrorUnknown)
+		}
+		b.logger.Info("HomeKit pairing added", "controller_id", pairing.ControllerID, "admin", pairing.Admin)
+
+	case methodRemovePairing:
+		if err := b.removePairing(ctx, string(identifier)); err != nil {
+			b.logger.Error("Failed to remove HomeKit pairing", "error", err)
+			return pairingError(2, tlvErrorUnknown)
+		}
+
+		var t tlv8
+		t.addByte(tlvState, 2)
+		resp := tlvResponse(t)
+		resp.after = func() error {
+			b.closeRemovedControllers(ctx)
+			return nil
+		}
+		return resp
+
+	case methodListPairings:
+		list, err := b.storage.ListHomeKitPairings(ctx)
+		if err != nil {
+			return pairingError(2, tlvErrorUnknown)
+		}
+		var t tlv8
+		t.addByte(tlvState, 2)
+		for i, pairing := range list {
+			if i > 0 {
+				t.add(tlvSeparator, nil)
+			}
+			permissions := byte(0)
+			if pairing.Admin {
+				permissions = permissionAdmin
+			}
+			t.add(tlvIdentifier, []byte(pairing.ControllerID))
+			t.add(tlvPublicKey, pairing.PublicKey)
+			t.addByte(tlvPermissions, permissions)
+		}
+		return tlvResponse(t)
+
+	default:
+		return pairingError(2, tlvErrorUnknown)
+	}
+
+	var t tlv8
+	t.addByte(tlvState, 2)
+	return tlvResponse(t)
+}
+
+// removePairing deletes a pairing; without admins left every pairing is removed,
+// which makes the bridge available for pairing again
+func (b *Bridge) removePairing(ctx context.Context, controllerID string) error {
+	if err := b.storage.DeleteHomeKitPairing(ctx, controllerID); err != nil {
+		return err
+	}
+	b.logger.Info("HomeKit pairing removed", "controller_id", controllerID)
+
+	remaining, err := b.storage.ListHomeKitPairings(ctx)
+	if err != nil {
+		return err
+	}
+	for _, pairing := range remaining {
+		if pairing.Admin {
+			return nil
+		}
+	}
+	for _, pairing := range remaining {
+		if err := b.storage.DeleteHomeKitPairing(ctx, pairing.ControllerID); err != nil {
+			return err
+		}
+	}
+	if len(remaining) > 0 {
+		b.logger.Info("Removed all HomeKit pairings: no admin left")
+	}
+	b.pairingChanged()
+	return nil
+}
+
+// closeRemovedControllers closes connections of controllers that are no longer paired
+func (b *Bridge) closeRemovedControllers(ctx context.Context) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for c := range b.conns {
+		if !c.verified() {
+			continue
+		}
+		if pairing, err := b.findPairing(ctx, c.controller()); err == nil && pairing == nil {
+			c.Close()
+		}
+	}
+}
+
+// findPairing returns the pairing of a controller, nil if there is none
+func (b *Bridge) findPairing(ctx context.Context, controllerID string) (*Pairing, error) {
+	pairings, err := b.storage.ListHomeKitPairings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, pairing := range pairings {
+		if pairing.ControllerID == controllerID {
+			return pairing, nil
+		}
+	}
+	return nil, nil
+}
+
+// concat joins byte slices
+func concat(parts ...[]byte) []byte {
+	var out []byte
+	for _, part := range parts {
+		out = append(out, part...)
+	}
+	return out
+}
@@ -0,0 +1,119 @@
+package homekit
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/subtle"
+	"errors"
+	"math/big"
+)
+
+// srpUsername is the SRP identity used by pair-setup
+const srpUsername = "Pair-Setup"
+
+// srpN is the 3072-bit group from RFC 5054, used with generator 5 and SHA-512
+var srpN, _ = new(big.Int).SetString(""+
+	"FFFFFFFFFFFFFFFFC90FDAA22168C234C4C6628B80DC1CD129024E08"+
+	"8A67CC74020BBEA63B139B22514A08798E3404DDEF9519B3CD3A431B"+
+	"302B0A6DF25F14374FE1356D6D51C245E485B576625E7EC6F44C42E9"+
+	"A637ED6B0BFF5CB6F406B7EDEE386BFB5A899FA5AE9F24117C4B1FE6"+
+	"49286651ECE45B3DC2007CB8A163BF0598DA48361C55D39A69163FA8"+
+	"FD24CF5F83655D23DCA3AD961C62F356208552BB9ED529077096966D"+
+	"670C354E4ABC9804F1746C08CA18217C32905E462E36CE3BE39E772C"+
+	"180E86039B2783A2EC07A28FB5C55DF06F4C52C9DE2BCBF695581718"+
+	"3995497CEA956AE515D2261898FA051015728E5A8AAAC42DAD33170D"+
+	"04507A33A85521ABDF1CBA64ECFB850458DBEF0A8AEA71575D060C7D"+
+	"B3970F85A6E1E4C7ABF5AE8CDB0933D71E8C94E04A25619DCEE3D226"+
+	"1AD2EE6BF12FFA06D98A0864D87602733EC86A64521F2B18177B200C"+
+	"BBE117577A615D6C770988C0BAD946E208E24FA074E5AB3143DB5BFC"+
+	"E0FD108E4B82D120A93AD2CAFFFFFFFFFFFFFFFF", 16)
+
+var srpG = big.NewInt(5)
+
+// srpLen is the byte length of the group; numbers are padded to it
+const srpLen = 384
+
+// srpServer is the accessory side of one SRP-6a exchange
+type srpServer struct {
+	salt []byte
+	v    *big.Int
+	b    *big.Int
+	B    []byte
+	key  []byte // Session key K, set by verify
+}
+
+// newSRPServer creates the verifier for the setup code and the server's public key
+func newSRPServer(setupCode string) (*srpServer, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+	return newSRPServerWith(setupCode, salt, new(big.Int).SetBytes(b)), nil
+}
+
+func newSRPServerWith(setupCode string, salt []byte, b *big.Int) *srpServer {
+	x := new(big.Int).SetBytes(srpHash(salt, srpHash([]byte(srpUsername+":"+setupCode))))
+	v := new(big.Int).Exp(srpG, x, srpN)
+
+	// B = k*v + g^b
+	B := new(big.Int).Mul(srpK(), v)
+	B.Add(B, new(big.Int).Exp(srpG, b, srpN))
+	B.Mod(B, srpN)
+
+	return &srpServer{salt: salt, v: v, b: b, B: srpPad(B)}
+}
+
+// verify checks the client's public key A and proof M1, and returns the server proof M2
+func (s *srpServer) verify(A, M1 []byte) ([]byte, error) {
+	a := new(big.Int).SetBytes(A)
+	if new(big.Int).Mod(a, srpN).Sign() == 0 {
+		return nil, errors.New("invalid srp public key")
+	}
+
+	// S = (A * v^u) ^ b
+	u := new(big.Int).SetBytes(srpHash(srpPad(a), s.B))
+	S := new(big.Int).Exp(s.v, u, srpN)
+	S.Mul(S, a)
+	S.Exp(S, s.b, srpN)
+	key := srpHash(srpPad(S))
+
+	expected := srpProof(A, s.B, s.salt, key)
+	if subtle.ConstantTimeCompare(expected, M1) != 1 {
+		return nil, errors.New("srp proof mismatch")
+	}
+
+	s.key = key
+	return srpHash(A, M1, key), nil
+}
+
+// srpProof computes M1 = H(H(N) xor H(g) | H(I) | s | A | B | K)
+func srpProof(A, B, salt, key []byte) []byte {
+	hN := srpHash(srpN.Bytes())
+	hG := srpHash(srpG.Bytes())
+	for i := range hN {
+		hN[i] ^= hG[i]
+	}
+	return srpHash(hN, srpHash([]byte(srpUsername)), salt, A, B, key)
+}
+
+// srpK computes k = H(N | PAD(g))
+func srpK() *big.Int {
+	return new(big.Int).SetBytes(srpHash(srpN.Bytes(), srpPad(srpG)))
+}
+
+func srpHash(parts ...[]byte) []byte {
+	h := sha512.New()
+	for _, part := range parts {
+		h.Write(part)
+	}
+	return h.Sum(nil)
+}
+
+// srpPad returns n as a big-endian number of the group's length
+func srpPad(n *big.Int) []byte {
+	return n.FillBytes(make([]byte, srpLen))
+}
@@ -0,0 +1,66 @@
+package homekit
+
+import (
+	"crypto/rand"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// srpClient computes the controller side of pair-setup M3 and returns A, M1 and the session key.
+func srpClient(t *testing.T, setupCode string, salt, B []byte) (A, M1, key []byte) {
+	t.Helper()
+
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	require.NoError(t, err)
+	a := new(big.Int).SetBytes(secret)
+	A = srpPad(new(big.Int).Exp(srpG, a, srpN))
+
+	x := new(big.Int).SetBytes(srpHash(salt, srpHash([]byte(srpUsername+":"+setupCode))))
+	u := new(big.Int).SetBytes(srpHash(A, B))
+
+	// S = (B - k*g^x) ^ (a + u*x)
+	base := new(big.Int).Exp(srpG, x, srpN)
+	base.Mul(base, srpK())
+	base.Sub(new(big.Int).SetBytes(B), base)
+	base.Mod(base, srpN)
+	exponent := new(big.Int).Mul(u, x)
+	exponent.Add(exponent, a)
+	S := new(big.Int).Exp(base, exponent, srpN)
+
+	key = srpHash(srpPad(S))
+	return A, srpProof(A, B, salt, key), key
+}
+
+func TestSRP_Verify(t *testing.T) {
+	server, err := newSRPServer("123-45-679")
+	require.NoError(t, err)
+
+	A, M1, key := srpClient(t, "123-45-679", server.salt, server.B)
+	M2, err := server.verify(A, M1)
+	require.NoError(t, err)
+
+	assert.Equal(t, key, server.key)
+	assert.Equal(t, srpHash(A, M1, key), M2)
+}
+
+func TestSRP_WrongCode(t *testing.T) {
+	server, err := newSRPServer("123-45-679")
+	require.NoError(t, err)
+
+	A, M1, _ := srpClient(t, "111-22-333", server.salt, server.B)
+	_, err = server.verify(A, M1)
+	assert.Error(t, err)
+	assert.Nil(t, server.key)
+}
+
+func TestSRP_RejectsZeroPublicKey(t *testing.T) {
+	server, err := newSRPServer("123-45-679")
+	require.NoError(t, err)
+
+	_, err = server.verify(srpN.Bytes(), make([]byte, 64))
+	assert.Error(t, err)
+}
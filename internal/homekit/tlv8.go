@@ -0,0 +1,111 @@
+package homekit
+
+import (
+	"errors"
+)
+
+// TLV types used by pairing
+const (
+	tlvMethod        = 0x00
+	tlvIdentifier    = 0x01
+	tlvSalt          = 0x02
+	tlvPublicKey     = 0x03
+	tlvProof         = 0x04
+	tlvEncryptedData = 0x05
+	tlvState         = 0x06
+	tlvError         = 0x07
+	tlvSignature     = 0x0a
+	tlvPermissions   = 0x0b
+	tlvSeparator     = 0xff
+)
+
+// TLV error codes
+const (
+	tlvErrorUnknown        = 0x01
+	tlvErrorAuthentication = 0x02
+	tlvErrorMaxTries       = 0x05
+	tlvErrorUnavailable    = 0x06
+	tlvErrorBusy           = 0x07
+)
+
+// tlvItem is one type-value pair
+type tlvItem struct {
+	typ   byte
+	value []byte
+}
+
+// tlv8 is an ordered list of items; values longer than 255 bytes are
+// split into consecutive fragments of the same type when encoded
+type tlv8 []tlvItem
+
+// add appends an item
+func (t *tlv8) add(typ byte, value []byte) {
+	*t = append(*t, tlvItem{typ, value})
+}
+
+// addByte appends a one-byte item
+func (t *tlv8) addByte(typ byte, value byte) {
+	t.add(typ, []byte{value})
+}
+
+// get returns the first value of a type
+func (t tlv8) get(typ byte) ([]byte, bool) {
+	for _, item := range t {
+		if item.typ == typ {
+			return item.value, true
+		}
+	}
+	return nil, false
+}
+
+// getByte returns the first value of a type if it is one byte long
+func (t tlv8) getByte(typ byte) (byte, bool) {
+	value, ok := t.get(typ)
+	if !ok || len(value) != 1 {
+		return 0, false
+	}
+	return value[0], true
+}
+
+// encode serializes the items
+func (t tlv8) encode() []byte {
+	var out []byte
+	for _, item := range t {
+		value := item.value
+		if len(value) == 0 {
+			out = append(out, item.typ, 0)
+			continue
+		}
+		for len(value) > 0 {
+			n := min(len(value), 255)
+			out = append(out, item.typ, byte(n))
+			out = append(out, value[:n]...)
+			value = value[n:]
+		}
+	}
+	return out
+}
+
+// decodeTLV8 parses items, joining fragments
+func decodeTLV8(data []byte) (tlv8, error) {
+	var t tlv8
+	for len(data) > 0 {
+		if len(data) < 2 {
+			return nil, errors.New("truncated tlv8 item")
+		}
+		typ, n := data[0], int(data[1])
+		if len(data) < 2+n {
+			return nil, errors.New("truncated tlv8 value")
+		}
+		value := data[2 : 2+n]
+		data = data[2+n:]
+
+		// A fragment continues the previous item of the same type
+		if last := len(t) - 1; last >= 0 && t[last].typ == typ && len(t[last].value)%255 == 0 && len(t[last].value) > 0 {
+			t[last].value = append(t[last].value, value...)
+			continue
+		}
+		t = append(t, tlvItem{typ, append([]byte(nil), value...)})
+	}
+	return t, nil
+}
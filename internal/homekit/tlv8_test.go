@@ -0,0 +1,42 @@
+package homekit
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLV8_RoundTrip(t *testing.T) {
+	long := bytes.Repeat([]byte{0xab}, 600)
+
+	var original tlv8
+	original.addByte(tlvState, 2)
+	original.add(tlvPublicKey, long)
+	original.add(tlvSeparator, nil)
+	original.add(tlvIdentifier, []byte("controller"))
+
+	encoded := original.encode()
+	// 600 bytes are split into fragments of 255, 255 and 90 bytes
+	assert.Equal(t, 3+(2+255)*2+(2+90)+2+(2+10), len(encoded))
+
+	decoded, err := decodeTLV8(encoded)
+	require.NoError(t, err)
+	assert.Equal(t, original, decoded)
+
+	state, ok := decoded.getByte(tlvState)
+	assert.True(t, ok)
+	assert.Equal(t, byte(2), state)
+	value, ok := decoded.get(tlvPublicKey)
+	assert.True(t, ok)
+	assert.Equal(t, long, value)
+}
+
+func TestTLV8_Truncated(t *testing.T) {
+	_, err := decodeTLV8([]byte{tlvState})
+	assert.Error(t, err)
+
+	_, err = decodeTLV8([]byte{tlvState, 4, 1})
+	assert.Error(t, err)
+}
@@ -6,9 +6,25 @@ import (
 
 // ID prefixes for different models
 const (
-	PrefixChild   = "kid_"
-	PrefixSession = "sess_"
-	PrefixBypass  = "byp_"
+	PrefixChild             = "kid_"
+	PrefixSession           = "sess_"
+	PrefixBypass            = "byp_"
+	PrefixLockdown          = "lck_"
+	PrefixTrackingPause     = "tpz_"
+	PrefixAgentToken        = "agt_"
+	PrefixTamperEvent       = "tmp_"
+	PrefixLimitChange       = "lim_"
+	PrefixAuditEntry        = "aud_"
+	PrefixProfileTransition = "prf_"
+	PrefixChildLogin        = "cls_"
+	PrefixDowntimeOverride  = "dto_"
+	PrefixUsageAlert        = "ual_"
+	PrefixDayRollover       = "dro_"
+	PrefixDriverJob         = "drv_"
+	PrefixDriverCall        = "dcl_"
+	PrefixChildActivity     = "act_"
+	PrefixQueuedStart       = "que_"
+	PrefixPushSubscription  = "psh_"
 )
 
 // NewChild generates a new child ID with kid_ prefix
@@ -26,6 +42,86 @@ func NewBypass() string {
 	return PrefixBypass + uuid.New().String()
 }
 
+// NewLockdown generates a new lockdown ID with lck_ prefix
+func NewLockdown() string {
+	return PrefixLockdown + uuid.New().String()
+}
+
+// NewTrackingPause generates a new tracking pause ID with tpz_ prefix
+func NewTrackingPause() string {
+	return PrefixTrackingPause + uuid.New().String()
+}
+
+// NewAgentToken generates a new agent token ID with agt_ prefix
+func NewAgentToken() string {
+	return PrefixAgentToken + uuid.New().String()
+}
+
+// NewTamperEvent generates a new tamper event ID with tmp_ prefix
+func NewTamperEvent() string {
+	return PrefixTamperEvent + uuid.New().String()
+}
+
+// NewLimitChange generates a new limit change ID with lim_ prefix
+func NewLimitChange() string {
+	return PrefixLimitChange + uuid.New().String()
+}
+
+// NewAuditEntry generates a new audit entry ID with aud_ prefix
+func NewAuditEntry() string {
+	return PrefixAuditEntry + uuid.New().String()
+}
+
+// NewProfileTransition generates a new limit profile transition ID with prf_ prefix
+func NewProfileTransition() string {
+	return PrefixProfileTransition + uuid.New().String()
+}
+
+// NewChildLogin generates a new child web app login ID with cls_ prefix
+func NewChildLogin() string {
+	return PrefixChildLogin + uuid.New().String()
+}
+
+// NewDowntimeOverride generates a new downtime override ID with dto_ prefix
+func NewDowntimeOverride() string {
+	return PrefixDowntimeOverride + uuid.New().String()
+}
+
+// NewUsageAlert generates a new usage alert ID with ual_ prefix
+func NewUsageAlert() string {
+	return PrefixUsageAlert + uuid.New().String()
+}
+
+// NewDayRollover generates a new day rollover ID with dro_ prefix
+func NewDayRollover() string {
+	return PrefixDayRollover + uuid.New().String()
+}
+
+// NewDriverJob generates a new driver job ID with drv_ prefix
+func NewDriverJob() string {
+	return PrefixDriverJob + uuid.New().String()
+}
+
+// NewDriverCall generates a new driver call ID with dcl_ prefix
+func NewDriverCall() string {
+	return PrefixDriverCall + uuid.New().String()
+}
+
+// NewChildActivity generates a new child activity entry ID with act_ prefix
+func NewChildActivity() string {
+	return PrefixChildActivity + uuid.New().String()
+}
+
+// NewQueuedStart generates a new queued session start ID with que_ prefix
+func NewQueuedStart() string {
+	return PrefixQueuedStart + uuid.New().String()
+}
+
+// NewPushSubscription generates a new push subscription ID with psh_ prefix
+func NewPushSubscription() string {
+	return PrefixPushSubscription + uuid.New().String()
+}
+
 // New generates a generic UUID without prefix (for internal use only)
 func New() string {
 	return uuid.New().String()
@@ -2,6 +2,7 @@ package logging
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"metron/internal/core"
 	"time"
@@ -31,6 +32,16 @@ func (l *SessionManagerLogger) StartSession(ctx context.Context, deviceID string
 	session, err := l.manager.StartSession(ctx, deviceID, childIDs, durationMinutes)
 	duration := time.Since(start)
 
+	// A start queued until the device is free is not a failure
+	if errors.Is(err, core.ErrSessionQueued) {
+		l.logger.Info("StartSession queued",
+			"device_id", deviceID,
+			"child_ids", childIDs,
+			"duration_minutes", durationMinutes,
+			"duration", duration)
+		return nil, err
+	}
+
 	if err != nil {
 		l.logger.Error("StartSession failed",
 			"device_id", deviceID,
@@ -128,6 +139,57 @@ func (l *SessionManagerLogger) AddChildrenToSession(ctx context.Context, session
 	return session, nil
 }
 
+func (l *SessionManagerLogger) RemoveChildFromSession(ctx context.Context, sessionID string, childID string) (*core.Session, error) {
+	start := time.Now()
+	l.logger.Info("RemoveChildFromSession called",
+		"session_id", sessionID,
+		"child_id", childID)
+
+	session, err := l.manager.RemoveChildFromSession(ctx, sessionID, childID)
+	duration := time.Since(start)
+
+	if err != nil {
+		l.logger.Error("RemoveChildFromSession failed",
+			"session_id", sessionID,
+			"child_id", childID,
+			"duration", duration,
+			"error", err)
+		return nil, err
+	}
+
+	l.logger.Info("RemoveChildFromSession completed",
+		"session_id", sessionID,
+		"child_id", childID,
+		"total_children", len(session.ChildIDs),
+		"duration", duration)
+
+	return session, nil
+}
+
+func (l *SessionManagerLogger) AddSessionNote(ctx context.Context, sessionID string, note string) (*core.Session, error) {
+	start := time.Now()
+	l.logger.Debug("AddSessionNote called",
+		"session_id", sessionID,
+		"note", note)
+
+	session, err := l.manager.AddSessionNote(ctx, sessionID, note)
+	duration := time.Since(start)
+
+	if err != nil {
+		l.logger.Error("AddSessionNote failed",
+			"session_id", sessionID,
+			"duration", duration,
+			"error", err)
+		return nil, err
+	}
+
+	l.logger.Debug("AddSessionNote completed",
+		"session_id", sessionID,
+		"duration", duration)
+
+	return session, nil
+}
+
 func (l *SessionManagerLogger) GetSession(ctx context.Context, sessionID string) (*core.Session, error) {
 	start := time.Now()
 	l.logger.Debug("GetSession called",
@@ -250,3 +312,102 @@ func (l *SessionManagerLogger) GetChildStatus(ctx context.Context, childID strin
 
 	return status, nil
 }
+
+func (l *SessionManagerLogger) GetChildrenStatus(ctx context.Context) ([]*core.ChildStatus, error) {
+	start := time.Now()
+	l.logger.Debug("GetChildrenStatus called")
+
+	statuses, err := l.manager.GetChildrenStatus(ctx)
+	duration := time.Since(start)
+
+	if err != nil {
+		l.logger.Error("GetChildrenStatus failed",
+			"duration", duration,
+			"error", err)
+		return nil, err
+	}
+
+	l.logger.Debug("GetChildrenStatus completed",
+		"children", len(statuses),
+		"duration", duration)
+
+	return statuses, nil
+}
+
+func (l *SessionManagerLogger) GetDurationSuggestions(ctx context.Context, childID string) (*core.DurationSuggestions, error) {
+	start := time.Now()
+	l.logger.Debug("GetDurationSuggestions called",
+		"child_id", childID)
+
+	suggestions, err := l.manager.GetDurationSuggestions(ctx, childID)
+	duration := time.Since(start)
+
+	if err != nil {
+		l.logger.Error("GetDurationSuggestions failed",
+			"child_id", childID,
+			"duration", duration,
+			"error", err)
+		return nil, err
+	}
+
+	l.logger.Debug("GetDurationSuggestions completed",
+		"child_id", childID,
+		"max_minutes", suggestions.MaxMinutes,
+		"options", len(suggestions.Options),
+		"duration", duration)
+
+	return suggestions, nil
+}
+
+func (l *SessionManagerLogger) PreflightSession(ctx context.Context, deviceID string, childIDs []string, durationMinutes int) (*core.SessionPreflight, error) {
+	start := time.Now()
+	l.logger.Debug("PreflightSession called",
+		"device_id", deviceID,
+		"child_ids", childIDs,
+		"duration_minutes", durationMinutes)
+
+	preflight, err := l.manager.PreflightSession(ctx, deviceID, childIDs, durationMinutes)
+	duration := time.Since(start)
+
+	if err != nil {
+		l.logger.Error("PreflightSession failed",
+			"device_id", deviceID,
+			"child_ids", childIDs,
+			"duration", duration,
+			"error", err)
+		return nil, err
+	}
+
+	l.logger.Debug("PreflightSession completed",
+		"device_id", deviceID,
+		"child_ids", childIDs,
+		"allowed", preflight.Allowed,
+		"blocked_by", preflight.BlockedBy,
+		"duration", duration)
+
+	return preflight, nil
+}
+
+func (l *SessionManagerLogger) ActivityBudgets(ctx context.Context, childID string) ([]*core.ActivityBudget, error) {
+	start := time.Now()
+	l.logger.Debug("ActivityBudgets called",
+		"child_id", childID)
+
+	budgets, err := l.manager.ActivityBudgets(ctx, childID)
+	duration := time.Since(start)
+
+	if err != nil {
+		l.logger.Error("ActivityBudgets failed",
+			"child_id", childID,
+			"duration", duration,
+			"error", err)
+		return nil, err
+	}
+
+	l.logger.Debug("ActivityBudgets completed",
+		"child_id", childID,
+		"activities", len(budgets),
+		"duration", duration)
+
+	return budgets, nil
+}
@@ -1,6 +1,7 @@
 package logging
 
 import (
+	"io"
 	"log/slog"
 	"os"
 )
@@ -9,10 +10,16 @@ import (
 type LoggerConfig struct {
 	Format string     // "json" or "text"
 	Level  slog.Level // Log level
+	Tail   *Tail      // Optional: also keep the recent lines in memory (GET /api/v1/admin/logs)
 }
 
-// NewLogger creates a new slog.Logger that writes to stdout
+// NewLogger creates a new slog.Logger that writes to stdout (and the tail, if set)
 func NewLogger(config LoggerConfig) *slog.Logger {
+	var out io.Writer = os.Stdout
+	if config.Tail != nil {
+		out = io.MultiWriter(os.Stdout, config.Tail)
+	}
+
 	opts := &slog.HandlerOptions{
 		Level: config.Level,
 		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
@@ -26,9 +33,9 @@ func NewLogger(config LoggerConfig) *slog.Logger {
 
 	var handler slog.Handler
 	if config.Format == "text" {
-		handler = slog.NewTextHandler(os.Stdout, opts)
+		handler = slog.NewTextHandler(out, opts)
 	} else {
-		handler = slog.NewJSONHandler(os.Stdout, opts)
+		handler = slog.NewJSONHandler(out, opts)
 	}
 
 	return slog.New(handler)
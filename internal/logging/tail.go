@@ -0,0 +1,115 @@
+package logging
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Log streams served by GET /api/v1/admin/logs, by the component of the log line
+const (
+	StreamAll     = "all"     // Every line
+	StreamCore    = "core"    // Session manager, scheduler and core services (everything not in another stream)
+	StreamAPI     = "api"     // HTTP handlers of the parent, child and agent APIs
+	StreamDrivers = "drivers" // Device drivers and their call queue and history
+)
+
+// DefaultTailLines is the number of recent log lines kept in memory
+const DefaultTailLines = 2000
+
+// ErrUnknownStream is returned for a stream other than the Stream* constants
+var ErrUnknownStream = errors.New("unknown log stream")
+
+// componentPattern finds the component of a JSON ("component":"api") or text (component=api) log line
+var componentPattern = regexp.MustCompile(`"component":"([^"]*)"|\bcomponent=("[^"]*"|\S*)`)
+
+// Tail keeps the most recent log lines in memory, so they can be read over the API
+// without a shell on the host (the logs themselves go to stdout, e.g. the systemd journal).
+// It is an io.Writer for the log handler: slog handlers write each record with one Write.
+type Tail struct {
+	mu    sync.Mutex
+	lines []tailLine // Ring buffer
+	next  int        // Index the next line is written to
+	full  bool       // The buffer has wrapped around
+}
+
+type tailLine struct {
+	stream string
+	text   string
+}
+
+// NewTail creates a tail keeping the last capacity lines (DefaultTailLines if not positive)
+func NewTail(capacity int) *Tail {
+	if capacity <= 0 {
+		capacity = DefaultTailLines
+	}
+	return &Tail{lines: make([]tailLine, capacity)}
+}
+
+// Write stores a log line
+func (t *Tail) Write(p []byte) (int, error) {
+	text := strings.TrimRight(string(p), "\n")
+	line := tailLine{stream: streamOf(text), text: text}
+
+	t.mu.Lock()
+	t.lines[t.next] = line
+	t.next = (t.next + 1) % len(t.lines)
+	if t.next == 0 {
+		t.full = true
+	}
+	t.mu.Unlock()
+	return len(p), nil
+}
+
+// Lines returns the last n lines of a stream, oldest first
+func (t *Tail) Lines(stream string, n int) ([]string, error) {
+	switch stream {
+	case StreamAll, StreamCore, StreamAPI, StreamDrivers:
+	default:
+		return nil, ErrUnknownStream
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Walk back from the newest line until n lines of the stream are found
+	count := t.next
+	if t.full {
+		count = len(t.lines)
+	}
+	var lines []string
+	for i := 0; i < count && len(lines) < n; i++ {
+		line := t.lines[(t.next-1-i+len(t.lines))%len(t.lines)]
+		if stream == StreamAll || line.stream == stream {
+			lines = append(lines, line.text)
+		}
+	}
+
+	for i, j := 0, len(lines)-1; i < j; i, j = i+1, j-1 {
+		lines[i], lines[j] = lines[j], lines[i]
+	}
+	return lines, nil
+}
+
+// streamOf returns the stream of a log line from its (last) component
+func streamOf(line string) string {
+	matches := componentPattern.FindAllStringSubmatch(line, -1)
+	if len(matches) == 0 {
+		return StreamCore
+	}
+	last := matches[len(matches)-1]
+	component := last[1]
+	if component == "" {
+		component = strings.Trim(last[2], `"`)
+	}
+
+	switch {
+	case component == "api" || strings.HasPrefix(component, "api.") || component == "child-api" || component == "agent-api":
+		return StreamAPI
+	case strings.HasPrefix(component, "driver"):
+		return StreamDrivers
+	default:
+		return StreamCore
+	}
+}
@@ -0,0 +1,79 @@
+package logging
+
+import (
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTail_Streams(t *testing.T) {
+	tail := NewTail(10)
+	logger := slog.New(slog.NewJSONHandler(tail, nil))
+
+	logger.With("component", "scheduler").Info("Tick")
+	logger.With("component", "api").Info("Request", "component", "api.bundle")
+	logger.With("component", "driver.aqara").Warn("Token expires soon")
+	logger.Info("No component")
+
+	lines, err := tail.Lines(StreamAll, 10)
+	require.NoError(t, err)
+	assert.Len(t, lines, 4)
+
+	lines, err = tail.Lines(StreamCore, 10)
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"msg":"Tick"`)
+	assert.Contains(t, lines[1], `"msg":"No component"`)
+
+	lines, err = tail.Lines(StreamAPI, 10)
+	require.NoError(t, err)
+	require.Len(t, lines, 1)
+	assert.Contains(t, lines[0], `"msg":"Request"`)
+
+	lines, err = tail.Lines(StreamDrivers, 10)
+	require.NoError(t, err)
+	require.Len(t, lines, 1)
+	assert.NotContains(t, lines[0], "\n")
+
+	_, err = tail.Lines("secrets", 10)
+	assert.ErrorIs(t, err, ErrUnknownStream)
+}
+
+func TestTail_TextFormat(t *testing.T) {
+	tail := NewTail(10)
+	logger := slog.New(slog.NewTextHandler(tail, nil))
+
+	logger.Info("Request", "component", "child-api")
+	logger.Info("Call", "component", "driver-queue")
+
+	lines, err := tail.Lines(StreamAPI, 10)
+	require.NoError(t, err)
+	assert.Len(t, lines, 1)
+	lines, err = tail.Lines(StreamDrivers, 10)
+	require.NoError(t, err)
+	assert.Len(t, lines, 1)
+}
+
+func TestTail_KeepsLastLines(t *testing.T) {
+	tail := NewTail(5)
+	logger := slog.New(slog.NewTextHandler(tail, nil))
+	for i := 1; i <= 12; i++ {
+		logger.Info(fmt.Sprintf("line %d", i))
+	}
+
+	// Oldest first, only the last lines fit
+	lines, err := tail.Lines(StreamAll, 100)
+	require.NoError(t, err)
+	require.Len(t, lines, 5)
+	assert.Contains(t, lines[0], `"line 8"`)
+	assert.Contains(t, lines[4], `"line 12"`)
+
+	lines, err = tail.Lines(StreamAll, 2)
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `"line 11"`)
+	assert.Contains(t, lines[1], `"line 12"`)
+}
@@ -0,0 +1,139 @@
+package mediaserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Media servers that send playback webhooks
+const (
+	ServerPlex     = "plex"
+	ServerJellyfin = "jellyfin"
+)
+
+// Playback event types
+const (
+	EventPlay     = "play"     // Playback started or resumed
+	EventProgress = "progress" // Playback is still going
+	EventPause    = "pause"    // Playback paused
+	EventStop     = "stop"     // Playback stopped
+)
+
+// Event is a playback event of a media server profile
+type Event struct {
+	Server    string        // ServerPlex or ServerJellyfin
+	Type      string        // Event* constant; empty for events that are not about playback
+	User      string        // Plex account title or Jellyfin user name
+	Player    string        // Client the media plays on (e.g., "Living Room TV")
+	Title     string        // What plays (e.g., "Bluey S02E05 - Dance Mode")
+	Remaining time.Duration // Time left in the item; 0 if unknown
+}
+
+// plexPayload is the JSON "payload" field of Plex webhooks (multipart form posts)
+type plexPayload struct {
+	Event   string `json:"event"`
+	Account struct {
+		Title string `json:"title"`
+	} `json:"Account"`
+	Player struct {
+		Title string `json:"title"`
+	} `json:"Player"`
+	Metadata struct {
+		Type             string `json:"type"`
+		Title            string `json:"title"`
+		GrandparentTitle string `json:"grandparentTitle"`
+		ParentIndex      int    `json:"parentIndex"`
+		Index            int    `json:"index"`
+		Duration         int64  `json:"duration"`   // Milliseconds
+		ViewOffset       int64  `json:"viewOffset"` // Milliseconds
+	} `json:"Metadata"`
+}
+
+// ParsePlex parses the JSON payload of a Plex webhook
+func ParsePlex(payload []byte) (*Event, error) {
+	var p plexPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, fmt.Errorf("invalid plex payload: %w", err)
+	}
+
+	event := &Event{
+		Server:    ServerPlex,
+		User:      p.Account.Title,
+		Player:    p.Player.Title,
+		Title:     title(p.Metadata.Type, p.Metadata.Title, p.Metadata.GrandparentTitle, p.Metadata.ParentIndex, p.Metadata.Index),
+		Remaining: remaining(p.Metadata.Duration, p.Metadata.ViewOffset, time.Millisecond),
+	}
+	switch p.Event {
+	case "media.play", "media.resume":
+		event.Type = EventPlay
+	case "media.pause":
+		event.Type = EventPause
+	case "media.stop":
+		event.Type = EventStop
+	}
+	return event, nil
+}
+
+// jellyfinPayload is a notification of the Jellyfin Webhook plugin
+// The plugin's body is a template, so only the fields of the documented template are read.
+type jellyfinPayload struct {
+	NotificationType      string `json:"NotificationType"`
+	NotificationUsername  string `json:"NotificationUsername"`
+	DeviceName            string `json:"DeviceName"`
+	ItemType              string `json:"ItemType"`
+	Name                  string `json:"Name"`
+	SeriesName            string `json:"SeriesName"`
+	SeasonNumber          int    `json:"SeasonNumber"`
+	EpisodeNumber         int    `json:"EpisodeNumber"`
+	RunTimeTicks          int64  `json:"RunTimeTicks"`          // 100 ns
+	PlaybackPositionTicks int64  `json:"PlaybackPositionTicks"` // 100 ns
+	IsPaused              bool   `json:"IsPaused"`
+}
+
+// ParseJellyfin parses a notification of the Jellyfin Webhook plugin
+func ParseJellyfin(body []byte) (*Event, error) {
+	var p jellyfinPayload
+	if err := json.Unmarshal(body, &p); err != nil {
+		return nil, fmt.Errorf("invalid jellyfin payload: %w", err)
+	}
+
+	event := &Event{
+		Server:    ServerJellyfin,
+		User:      p.NotificationUsername,
+		Player:    p.DeviceName,
+		Title:     title(p.ItemType, p.Name, p.SeriesName, p.SeasonNumber, p.EpisodeNumber),
+		Remaining: remaining(p.RunTimeTicks, p.PlaybackPositionTicks, 100*time.Nanosecond),
+	}
+	switch {
+	case p.NotificationType == "PlaybackStart":
+		event.Type = EventPlay
+	case p.NotificationType == "PlaybackProgress" && p.IsPaused:
+		event.Type = EventPause
+	case p.NotificationType == "PlaybackProgress":
+		event.Type = EventProgress
+	case p.NotificationType == "PlaybackStop":
+		event.Type = EventStop
+	}
+	return event, nil
+}
+
+// title names an item: episodes as "Series S01E02 - Name", anything else by its name
+func title(itemType, name, series string, season, episode int) string {
+	if (itemType == "episode" || itemType == "Episode") && series != "" {
+		episodeTitle := fmt.Sprintf("%s S%02dE%02d", series, season, episode)
+		if name == "" {
+			return episodeTitle
+		}
+		return episodeTitle + " - " + name
+	}
+	return name
+}
+
+// remaining returns the time left in an item from its duration and position in the given unit
+func remaining(duration, position int64, unit time.Duration) time.Duration {
+	if duration <= 0 || position >= duration {
+		return 0
+	}
+	return time.Duration(duration-position) * unit
+}
@@ -0,0 +1,77 @@
+package mediaserver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePlex(t *testing.T) {
+	payload := `{
+		"event": "media.resume",
+		"user": true,
+		"owner": false,
+		"Account": {"id": 2, "title": "Alice"},
+		"Player": {"local": true, "title": "Living Room TV", "uuid": "abc"},
+		"Metadata": {"type": "episode", "title": "Dance Mode", "grandparentTitle": "Bluey",
+			"parentIndex": 2, "index": 5, "duration": 420000, "viewOffset": 60000}
+	}`
+
+	event, err := ParsePlex([]byte(payload))
+	require.NoError(t, err)
+	assert.Equal(t, ServerPlex, event.Server)
+	assert.Equal(t, EventPlay, event.Type)
+	assert.Equal(t, "Alice", event.User)
+	assert.Equal(t, "Living Room TV", event.Player)
+	assert.Equal(t, "Bluey S02E05 - Dance Mode", event.Title)
+	assert.Equal(t, 6*time.Minute, event.Remaining)
+
+	event, err = ParsePlex([]byte(`{"event": "library.new", "Metadata": {"type": "movie", "title": "Moana"}}`))
+	require.NoError(t, err)
+	assert.Empty(t, event.Type, "not a playback event")
+	assert.Equal(t, "Moana", event.Title)
+	assert.Zero(t, event.Remaining)
+
+	_, err = ParsePlex([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestParseJellyfin(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantType string
+	}{
+		{"start", `{"NotificationType": "PlaybackStart"}`, EventPlay},
+		{"progress", `{"NotificationType": "PlaybackProgress", "IsPaused": false}`, EventProgress},
+		{"paused", `{"NotificationType": "PlaybackProgress", "IsPaused": true}`, EventPause},
+		{"stop", `{"NotificationType": "PlaybackStop"}`, EventStop},
+		{"other", `{"NotificationType": "ItemAdded"}`, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := ParseJellyfin([]byte(tt.body))
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantType, event.Type)
+		})
+	}
+
+	body := `{
+		"NotificationType": "PlaybackStart",
+		"NotificationUsername": "bob",
+		"DeviceName": "Kids iPad",
+		"ItemType": "Movie",
+		"Name": "Moana",
+		"RunTimeTicks": 64200000000,
+		"PlaybackPositionTicks": 0
+	}`
+	event, err := ParseJellyfin([]byte(body))
+	require.NoError(t, err)
+	assert.Equal(t, ServerJellyfin, event.Server)
+	assert.Equal(t, "bob", event.User)
+	assert.Equal(t, "Kids iPad", event.Player)
+	assert.Equal(t, "Moana", event.Title)
+	assert.Equal(t, 107*time.Minute, event.Remaining)
+}
@@ -0,0 +1,202 @@
+package mediaserver
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"metron/internal/core"
+)
+
+// Sessions lists, starts and annotates sessions (implemented by the session manager)
+type Sessions interface {
+	ListActiveSessions(ctx context.Context) ([]*core.Session, error)
+	StartSession(ctx context.Context, deviceID string, childIDs []string, durationMinutes int) (*core.Session, error)
+	AddSessionNote(ctx context.Context, sessionID string, note string) (*core.Session, error)
+}
+
+// Media records what devices play (implemented by core.MediaService)
+type Media interface {
+	Report(ctx context.Context, state *core.MediaState) (*core.MediaState, error)
+	Clear(deviceID string)
+}
+
+// Profile links a media server profile to a child.
+type Profile struct {
+	Server           string // ServerPlex or ServerJellyfin
+	User             string // Plex account title or Jellyfin user name (case-insensitive)
+	ChildID          string
+	DeviceID         string // Only sessions on this device cover playback (empty = any session of the child)
+	AutoStartMinutes int    // Start a session of this length on DeviceID when playback starts without one (0 = off)
+}
+
+// What Handle did with an event
+const (
+	OutcomeIgnored   = "ignored"   // Not a linked profile, or not playing
+	OutcomeCovered   = "covered"   // A running session of the child covers the playback
+	OutcomeStarted   = "started"   // A session was started for the playback
+	OutcomeUncovered = "uncovered" // The child watches without a session
+)
+
+// Result is what Handle did with an event
+type Result struct {
+	Outcome   string
+	SessionID string // Session covering the playback (covered or started)
+}
+
+// Watcher checks media server playback of children against their sessions.
+// Playback of a linked profile is covered by a running session of the child, or starts one
+// if the profile auto-starts sessions; playback without a session is logged. What was watched
+// is noted on the session, and reported as the device's media so sessions can be extended
+// until the item ends.
+type Watcher struct {
+	sessions Sessions
+	media    Media
+	profiles []Profile
+	logger   *slog.Logger
+}
+
+// NewWatcher creates a watcher for the linked profiles
+func NewWatcher(sessions Sessions, profiles []Profile, logger *slog.Logger) *Watcher {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Watcher{
+		sessions: sessions,
+		profiles: profiles,
+		logger:   logger.With("component", "mediaserver"),
+	}
+}
+
+// SetMedia makes the watcher report what profiles play on their device
+func (w *Watcher) SetMedia(media Media) {
+	w.media = media
+}
+
+// Handle checks a playback event against the child's sessions
+func (w *Watcher) Handle(ctx context.Context, event *Event) (*Result, error) {
+	profile, ok := w.profile(event)
+	if !ok || event.Type == "" {
+		return &Result{Outcome: OutcomeIgnored}, nil
+	}
+
+	active, err := w.sessions.ListActiveSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	session := childSession(active, profile)
+
+	if event.Type == EventPause || event.Type == EventStop {
+		if w.media != nil && session != nil {
+			w.media.Clear(session.DeviceID)
+		}
+		return &Result{Outcome: OutcomeIgnored}, nil
+	}
+
+	result := &Result{Outcome: OutcomeCovered}
+	if session == nil {
+		// Progress of playback that was already checked when it started
+		if event.Type == EventProgress {
+			return &Result{Outcome: OutcomeUncovered}, nil
+		}
+
+		session = w.autoStart(ctx, profile, event)
+		if session == nil {
+			w.logger.Warn("Playback without a session",
+				"server", event.Server,
+				"user", event.User,
+				"child_id", profile.ChildID,
+				"player", event.Player,
+				"title", event.Title)
+			return &Result{Outcome: OutcomeUncovered}, nil
+		}
+		result.Outcome = OutcomeStarted
+	}
+	result.SessionID = session.ID
+
+	if event.Title != "" {
+		if _, err := w.sessions.AddSessionNote(ctx, session.ID, "Watched: "+event.Title); err != nil {
+			w.logger.Warn("Failed to note what was watched",
+				"session_id", session.ID,
+				"title", event.Title,
+				"error", err)
+		}
+	}
+	w.reportMedia(ctx, session.DeviceID, event)
+
+	w.logger.Debug("Playback checked",
+		"server", event.Server,
+		"child_id", profile.ChildID,
+		"session_id", session.ID,
+		"outcome", result.Outcome)
+	return result, nil
+}
+
+// profile returns the profile the event belongs to
+func (w *Watcher) profile(event *Event) (Profile, bool) {
+	for _, profile := range w.profiles {
+		if profile.Server == event.Server && strings.EqualFold(profile.User, event.User) {
+			return profile, true
+		}
+	}
+	return Profile{}, false
+}
+
+// autoStart starts a session for the playback if the profile auto-starts them, nil otherwise
+func (w *Watcher) autoStart(ctx context.Context, profile Profile, event *Event) *core.Session {
+	if profile.AutoStartMinutes <= 0 {
+		return nil
+	}
+
+	ctx = core.WithInitiator(ctx, core.Initiator{Type: core.InitiatorAutomation, ID: event.Server})
+	session, err := w.sessions.StartSession(ctx, profile.DeviceID, []string{profile.ChildID}, profile.AutoStartMinutes)
+	if err != nil {
+		// Usually no time left or downtime
+		w.logger.Warn("Failed to auto-start session for playback",
+			"child_id", profile.ChildID,
+			"device_id", profile.DeviceID,
+			"title", event.Title,
+			"error", err)
+		return nil
+	}
+
+	w.logger.Info("Auto-started session for playback",
+		"session_id", session.ID,
+		"child_id", profile.ChildID,
+		"device_id", profile.DeviceID,
+		"title", event.Title)
+	return session
+}
+
+// reportMedia reports the item as the device's media, if its remaining time is known
+func (w *Watcher) reportMedia(ctx context.Context, deviceID string, event *Event) {
+	if w.media == nil || event.Remaining <= 0 {
+		return
+	}
+	state := &core.MediaState{
+		DeviceID: deviceID,
+		Title:    event.Title,
+		EndsAt:   core.Now().Add(event.Remaining),
+		Source:   event.Server,
+	}
+	if _, err := w.media.Report(ctx, state); err != nil {
+		w.logger.Warn("Failed to report media state",
+			"device_id", deviceID,
+			"error", err)
+	}
+}
+
+// childSession returns the running session of the profile's child on its device
+func childSession(active []*core.Session, profile Profile) *core.Session {
+	for _, session := range active {
+		if profile.DeviceID != "" && session.DeviceID != profile.DeviceID {
+			continue
+		}
+		for _, childID := range session.ChildIDs {
+			if childID == profile.ChildID {
+				return session
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package mediaserver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"metron/internal/core"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockSessions keeps active sessions and records started sessions and notes.
+type mockSessions struct {
+	active   []*core.Session
+	started  []*core.Session
+	notes    map[string][]string
+	startErr error
+}
+
+func (m *mockSessions) ListActiveSessions(_ context.Context) ([]*core.Session, error) {
+	return m.active, nil
+}
+
+func (m *mockSessions) StartSession(ctx context.Context, deviceID string, childIDs []string, durationMinutes int) (*core.Session, error) {
+	if m.startErr != nil {
+		return nil, m.startErr
+	}
+	initiator := core.InitiatorFromContext(ctx)
+	session := &core.Session{ID: "ses_auto", DeviceID: deviceID, ChildIDs: childIDs, ExpectedDuration: durationMinutes,
+		InitiatorType: initiator.Type, InitiatorID: initiator.ID}
+	m.started = append(m.started, session)
+	m.active = append(m.active, session)
+	return session, nil
+}
+
+func (m *mockSessions) AddSessionNote(_ context.Context, sessionID string, note string) (*core.Session, error) {
+	m.notes[sessionID] = append(m.notes[sessionID], note)
+	return &core.Session{ID: sessionID}, nil
+}
+
+// mockMedia keeps reported media by device ID.
+type mockMedia struct {
+	states map[string]*core.MediaState
+}
+
+func (m *mockMedia) Report(_ context.Context, state *core.MediaState) (*core.MediaState, error) {
+	m.states[state.DeviceID] = state
+	return state, nil
+}
+
+func (m *mockMedia) Clear(deviceID string) {
+	delete(m.states, deviceID)
+}
+
+func setupTestWatcher(t *testing.T, profile Profile) (*Watcher, *mockSessions, *mockMedia, time.Time) {
+	t.Helper()
+
+	now := time.Date(2026, time.March, 10, 17, 0, 0, 0, time.UTC)
+	original := core.Now
+	core.Now = func() time.Time { return now }
+	t.Cleanup(func() { core.Now = original })
+
+	sessions := &mockSessions{notes: make(map[string][]string)}
+	media := &mockMedia{states: make(map[string]*core.MediaState)}
+	watcher := NewWatcher(sessions, []Profile{profile}, nil)
+	watcher.SetMedia(media)
+	return watcher, sessions, media, now
+}
+
+func TestWatcher_Covered(t *testing.T) {
+	watcher, sessions, media, now := setupTestWatcher(t, Profile{Server: ServerPlex, User: "alice", ChildID: "alice", DeviceID: "tv1"})
+	sessions.active = []*core.Session{
+		{ID: "ses_ps5", DeviceID: "ps5", ChildIDs: []string{"alice"}},
+		{ID: "ses_tv", DeviceID: "tv1", ChildIDs: []string{"bob", "alice"}},
+	}
+	ctx := context.Background()
+
+	result, err := watcher.Handle(ctx, &Event{Server: ServerPlex, Type: EventPlay, User: "Alice", Title: "Bluey S02E05", Remaining: 7 * time.Minute})
+	require.NoError(t, err)
+	assert.Equal(t, OutcomeCovered, result.Outcome)
+	assert.Equal(t, "ses_tv", result.SessionID)
+	assert.Equal(t, []string{"Watched: Bluey S02E05"}, sessions.notes["ses_tv"])
+	require.Contains(t, media.states, "tv1")
+	assert.Equal(t, now.Add(7*time.Minute), media.states["tv1"].EndsAt)
+	assert.Equal(t, ServerPlex, media.states["tv1"].Source)
+
+	// Pausing clears the media, so the session is not extended for it
+	result, err = watcher.Handle(ctx, &Event{Server: ServerPlex, Type: EventPause, User: "alice"})
+	require.NoError(t, err)
+	assert.Equal(t, OutcomeIgnored, result.Outcome)
+	assert.NotContains(t, media.states, "tv1")
+	assert.Empty(t, sessions.started)
+}
+
+func TestWatcher_AutoStart(t *testing.T) {
+	watcher, sessions, _, _ := setupTestWatcher(t, Profile{Server: ServerJellyfin, User: "alice", ChildID: "alice", DeviceID: "tv1", AutoStartMinutes: 30})
+	ctx := context.Background()
+
+	// Progress without a session does not start one
+	result, err := watcher.Handle(ctx, &Event{Server: ServerJellyfin, Type: EventProgress, User: "alice", Title: "Moana"})
+	require.NoError(t, err)
+	assert.Equal(t, OutcomeUncovered, result.Outcome)
+	assert.Empty(t, sessions.started)
+
+	result, err = watcher.Handle(ctx, &Event{Server: ServerJellyfin, Type: EventPlay, User: "alice", Title: "Moana"})
+	require.NoError(t, err)
+	assert.Equal(t, OutcomeStarted, result.Outcome)
+	require.Len(t, sessions.started, 1)
+	started := sessions.started[0]
+	assert.Equal(t, "tv1", started.DeviceID)
+	assert.Equal(t, []string{"alice"}, started.ChildIDs)
+	assert.Equal(t, 30, started.ExpectedDuration)
+	assert.Equal(t, core.InitiatorAutomation, started.InitiatorType)
+	assert.Equal(t, ServerJellyfin, started.InitiatorID)
+	assert.Equal(t, []string{"Watched: Moana"}, sessions.notes[started.ID])
+
+	// Later progress is covered by the started session
+	result, err = watcher.Handle(ctx, &Event{Server: ServerJellyfin, Type: EventProgress, User: "alice", Title: "Moana"})
+	require.NoError(t, err)
+	assert.Equal(t, OutcomeCovered, result.Outcome)
+	assert.Len(t, sessions.started, 1)
+}
+
+func TestWatcher_Uncovered(t *testing.T) {
+	watcher, sessions, _, _ := setupTestWatcher(t, Profile{Server: ServerPlex, User: "alice", ChildID: "alice", DeviceID: "tv1", AutoStartMinutes: 30})
+	sessions.startErr = errors.New("insufficient time")
+	ctx := context.Background()
+
+	result, err := watcher.Handle(ctx, &Event{Server: ServerPlex, Type: EventPlay, User: "alice", Title: "Moana"})
+	require.NoError(t, err)
+	assert.Equal(t, OutcomeUncovered, result.Outcome)
+	assert.Empty(t, sessions.notes)
+
+	// Other profiles and servers are ignored
+	result, err = watcher.Handle(ctx, &Event{Server: ServerPlex, Type: EventPlay, User: "dad", Title: "Moana"})
+	require.NoError(t, err)
+	assert.Equal(t, OutcomeIgnored, result.Outcome)
+	result, err = watcher.Handle(ctx, &Event{Server: ServerJellyfin, Type: EventPlay, User: "alice", Title: "Moana"})
+	require.NoError(t, err)
+	assert.Equal(t, OutcomeIgnored, result.Outcome)
+}
@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistry_WriteTo(t *testing.T) {
+	registry := NewRegistry()
+	calls := registry.Counter("test_calls_total", "Calls.", "method")
+	latency := registry.Histogram("test_duration_seconds", "Latency.", []float64{0.1, 1}, "method")
+
+	calls.Inc("Get")
+	calls.Inc("Get")
+	calls.Add(3, "List")
+	latency.Observe(0.05, "Get")
+	latency.Observe(0.5, "Get")
+	latency.Observe(5, "Get")
+
+	var b strings.Builder
+	_, err := registry.WriteTo(&b)
+	require.NoError(t, err)
+
+	assert.Equal(t, `# HELP test_calls_total Calls.
+# TYPE test_calls_total counter
+test_calls_total{method="Get"} 2
+test_calls_total{method="List"} 3
+# HELP test_duration_seconds Latency.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{method="Get",le="0.1"} 1
+test_duration_seconds_bucket{method="Get",le="1"} 2
+test_duration_seconds_bucket{method="Get",le="+Inf"} 3
+test_duration_seconds_sum{method="Get"} 5.55
+test_duration_seconds_count{method="Get"} 3
+`, b.String())
+}
+
+func TestRegistry_Handler(t *testing.T) {
+	registry := NewRegistry()
+	registry.Counter("test_total", "Total.").Inc()
+
+	rec := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain; version=0.0.4")
+	assert.Contains(t, rec.Body.String(), "test_total 1\n")
+}
@@ -0,0 +1,335 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"metron/internal/core"
+	"metron/internal/storage"
+	"time"
+)
+
+// notFoundErrors are expected answers rather than failures, so they don't count as errors
+var notFoundErrors = []error{
+	core.ErrChildNotFound,
+	core.ErrSessionNotFound,
+	core.ErrAllocationNotFound,
+	core.ErrLimitChangeNotFound,
+	core.ErrProfileTransitionNotFound,
+}
+
+// StorageMetrics wraps a Storage and records call counts, latencies and errors per method
+type StorageMetrics struct {
+	inner    storage.Storage
+	calls    *CounterVec
+	errors   *CounterVec
+	duration *HistogramVec
+}
+
+// NewStorageMetrics creates a new metrics decorator for Storage, registering its metrics in registry
+func NewStorageMetrics(inner storage.Storage, registry *Registry) storage.Storage {
+	return &StorageMetrics{
+		inner:    inner,
+		calls:    registry.Counter("metron_storage_calls_total", "Storage calls by method.", "method"),
+		errors:   registry.Counter("metron_storage_errors_total", "Failed storage calls by method (not found is not a failure).", "method"),
+		duration: registry.Histogram("metron_storage_call_duration_seconds", "Storage call latency by method.", nil, "method"),
+	}
+}
+
+// observe records a call of method that started at start and returned *err
+func (s *StorageMetrics) observe(method string, start time.Time, err *error) {
+	s.duration.Observe(time.Since(start).Seconds(), method)
+	s.calls.Inc(method)
+	if *err == nil {
+		return
+	}
+	for _, notFound := range notFoundErrors {
+		if errors.Is(*err, notFound) {
+			return
+		}
+	}
+	s.errors.Inc(method)
+}
+
+func (s *StorageMetrics) GetChild(ctx context.Context, id string) (result *core.Child, err error) {
+	defer s.observe("GetChild", time.Now(), &err)
+	return s.inner.GetChild(ctx, id)
+}
+
+func (s *StorageMetrics) ListChildren(ctx context.Context) (result []*core.Child, err error) {
+	defer s.observe("ListChildren", time.Now(), &err)
+	return s.inner.ListChildren(ctx)
+}
+
+func (s *StorageMetrics) GetSession(ctx context.Context, id string) (result *core.Session, err error) {
+	defer s.observe("GetSession", time.Now(), &err)
+	return s.inner.GetSession(ctx, id)
+}
+
+func (s *StorageMetrics) ListActiveSessions(ctx context.Context) (result []*core.Session, err error) {
+	defer s.observe("ListActiveSessions", time.Now(), &err)
+	return s.inner.ListActiveSessions(ctx)
+}
+
+func (s *StorageMetrics) ListAllSessions(ctx context.Context) (result []*core.Session, err error) {
+	defer s.observe("ListAllSessions", time.Now(), &err)
+	return s.inner.ListAllSessions(ctx)
+}
+
+func (s *StorageMetrics) ListSessionsByChild(ctx context.Context, childID string) (result []*core.Session, err error) {
+	defer s.observe("ListSessionsByChild", time.Now(), &err)
+	return s.inner.ListSessionsByChild(ctx, childID)
+}
+
+func (s *StorageMetrics) ListSessionsSince(ctx context.Context, since time.Time) (result []*core.Session, err error) {
+	defer s.observe("ListSessionsSince", time.Now(), &err)
+	return s.inner.ListSessionsSince(ctx, since)
+}
+
+func (s *StorageMetrics) GetDailyAllocation(ctx context.Context, childID string, date time.Time) (result *core.DailyTimeAllocation, err error) {
+	defer s.observe("GetDailyAllocation", time.Now(), &err)
+	return s.inner.GetDailyAllocation(ctx, childID, date)
+}
+
+func (s *StorageMetrics) ListDailyAllocations(ctx context.Context, date time.Time) (result []*core.DailyTimeAllocation, err error) {
+	defer s.observe("ListDailyAllocations", time.Now(), &err)
+	return s.inner.ListDailyAllocations(ctx, date)
+}
+
+func (s *StorageMetrics) GetDailyUsageSummary(ctx context.Context, childID string, date time.Time) (result *core.DailyUsageSummary, err error) {
+	defer s.observe("GetDailyUsageSummary", time.Now(), &err)
+	return s.inner.GetDailyUsageSummary(ctx, childID, date)
+}
+
+func (s *StorageMetrics) ListDailyUsageSummaries(ctx context.Context, date time.Time) (result []*core.DailyUsageSummary, err error) {
+	defer s.observe("ListDailyUsageSummaries", time.Now(), &err)
+	return s.inner.ListDailyUsageSummaries(ctx, date)
+}
+
+func (s *StorageMetrics) ListActiveSessionRecords(ctx context.Context) (result []*core.SessionUsageRecord, err error) {
+	defer s.observe("ListActiveSessionRecords", time.Now(), &err)
+	return s.inner.ListActiveSessionRecords(ctx)
+}
+
+func (s *StorageMetrics) GetDeviceBypass(ctx context.Context, deviceID string) (result *core.DeviceBypass, err error) {
+	defer s.observe("GetDeviceBypass", time.Now(), &err)
+	return s.inner.GetDeviceBypass(ctx, deviceID)
+}
+
+func (s *StorageMetrics) ListActiveBypassDevices(ctx context.Context) (result []*core.DeviceBypass, err error) {
+	defer s.observe("ListActiveBypassDevices", time.Now(), &err)
+	return s.inner.ListActiveBypassDevices(ctx)
+}
+
+func (s *StorageMetrics) GetMovieTimeUsage(ctx context.Context, date time.Time) (result *core.MovieTimeUsage, err error) {
+	defer s.observe("GetMovieTimeUsage", time.Now(), &err)
+	return s.inner.GetMovieTimeUsage(ctx, date)
+}
+
+func (s *StorageMetrics) GetMovieTimeBypass(ctx context.Context, id string) (result *core.MovieTimeBypass, err error) {
+	defer s.observe("GetMovieTimeBypass", time.Now(), &err)
+	return s.inner.GetMovieTimeBypass(ctx, id)
+}
+
+func (s *StorageMetrics) ListMovieTimeBypasses(ctx context.Context) (result []*core.MovieTimeBypass, err error) {
+	defer s.observe("ListMovieTimeBypasses", time.Now(), &err)
+	return s.inner.ListMovieTimeBypasses(ctx)
+}
+
+func (s *StorageMetrics) ListActiveMovieTimeBypasses(ctx context.Context, date time.Time) (result []*core.MovieTimeBypass, err error) {
+	defer s.observe("ListActiveMovieTimeBypasses", time.Now(), &err)
+	return s.inner.ListActiveMovieTimeBypasses(ctx, date)
+}
+
+func (s *StorageMetrics) GetActiveLockdown(ctx context.Context) (result *core.Lockdown, err error) {
+	defer s.observe("GetActiveLockdown", time.Now(), &err)
+	return s.inner.GetActiveLockdown(ctx)
+}
+
+func (s *StorageMetrics) ListLockdowns(ctx context.Context, limit int) (result []*core.Lockdown, err error) {
+	defer s.observe("ListLockdowns", time.Now(), &err)
+	return s.inner.ListLockdowns(ctx, limit)
+}
+
+func (s *StorageMetrics) ListActiveTrackingPauses(ctx context.Context) (result []*core.TrackingPause, err error) {
+	defer s.observe("ListActiveTrackingPauses", time.Now(), &err)
+	return s.inner.ListActiveTrackingPauses(ctx)
+}
+
+func (s *StorageMetrics) ListDeviceHeartbeats(ctx context.Context) (result []*core.DeviceHeartbeat, err error) {
+	defer s.observe("ListDeviceHeartbeats", time.Now(), &err)
+	return s.inner.ListDeviceHeartbeats(ctx)
+}
+
+func (s *StorageMetrics) ListTamperEvents(ctx context.Context, since time.Time) (result []*core.TamperEvent, err error) {
+	defer s.observe("ListTamperEvents", time.Now(), &err)
+	return s.inner.ListTamperEvents(ctx, since)
+}
+
+func (s *StorageMetrics) ListLimitChanges(ctx context.Context, childID string) (result []*core.LimitChange, err error) {
+	defer s.observe("ListLimitChanges", time.Now(), &err)
+	return s.inner.ListLimitChanges(ctx, childID)
+}
+
+func (s *StorageMetrics) ListAuditEntries(ctx context.Context, childID string, limit int) (result []*core.AuditEntry, err error) {
+	defer s.observe("ListAuditEntries", time.Now(), &err)
+	return s.inner.ListAuditEntries(ctx, childID, limit)
+}
+
+func (s *StorageMetrics) GetProfileTransition(ctx context.Context, id string) (result *core.ProfileTransition, err error) {
+	defer s.observe("GetProfileTransition", time.Now(), &err)
+	return s.inner.GetProfileTransition(ctx, id)
+}
+
+func (s *StorageMetrics) ListProfileTransitions(ctx context.Context, status string) (result []*core.ProfileTransition, err error) {
+	defer s.observe("ListProfileTransitions", time.Now(), &err)
+	return s.inner.ListProfileTransitions(ctx, status)
+}
+
+func (s *StorageMetrics) CreateChild(ctx context.Context, child *core.Child) (err error) {
+	defer s.observe("CreateChild", time.Now(), &err)
+	return s.inner.CreateChild(ctx, child)
+}
+
+func (s *StorageMetrics) UpdateChild(ctx context.Context, child *core.Child) (err error) {
+	defer s.observe("UpdateChild", time.Now(), &err)
+	return s.inner.UpdateChild(ctx, child)
+}
+
+func (s *StorageMetrics) DeleteChild(ctx context.Context, id string) (err error) {
+	defer s.observe("DeleteChild", time.Now(), &err)
+	return s.inner.DeleteChild(ctx, id)
+}
+
+func (s *StorageMetrics) CreateSession(ctx context.Context, session *core.Session) (err error) {
+	defer s.observe("CreateSession", time.Now(), &err)
+	return s.inner.CreateSession(ctx, session)
+}
+
+func (s *StorageMetrics) UpdateSession(ctx context.Context, session *core.Session) (err error) {
+	defer s.observe("UpdateSession", time.Now(), &err)
+	return s.inner.UpdateSession(ctx, session)
+}
+
+func (s *StorageMetrics) DeleteSession(ctx context.Context, id string) (err error) {
+	defer s.observe("DeleteSession", time.Now(), &err)
+	return s.inner.DeleteSession(ctx, id)
+}
+
+func (s *StorageMetrics) CreateDailyAllocation(ctx context.Context, allocation *core.DailyTimeAllocation) (err error) {
+	defer s.observe("CreateDailyAllocation", time.Now(), &err)
+	return s.inner.CreateDailyAllocation(ctx, allocation)
+}
+
+func (s *StorageMetrics) UpdateDailyAllocation(ctx context.Context, allocation *core.DailyTimeAllocation) (err error) {
+	defer s.observe("UpdateDailyAllocation", time.Now(), &err)
+	return s.inner.UpdateDailyAllocation(ctx, allocation)
+}
+
+func (s *StorageMetrics) IncrementDailyUsageSummary(ctx context.Context, childID string, date time.Time, minutes int) (err error) {
+	defer s.observe("IncrementDailyUsageSummary", time.Now(), &err)
+	return s.inner.IncrementDailyUsageSummary(ctx, childID, date, minutes)
+}
+
+func (s *StorageMetrics) IncrementSessionCountSummary(ctx context.Context, childID string, date time.Time) (err error) {
+	defer s.observe("IncrementSessionCountSummary", time.Now(), &err)
+	return s.inner.IncrementSessionCountSummary(ctx, childID, date)
+}
+
+func (s *StorageMetrics) SetDeviceBypass(ctx context.Context, bypass *core.DeviceBypass) (err error) {
+	defer s.observe("SetDeviceBypass", time.Now(), &err)
+	return s.inner.SetDeviceBypass(ctx, bypass)
+}
+
+func (s *StorageMetrics) ClearDeviceBypass(ctx context.Context, deviceID string) (err error) {
+	defer s.observe("ClearDeviceBypass", time.Now(), &err)
+	return s.inner.ClearDeviceBypass(ctx, deviceID)
+}
+
+func (s *StorageMetrics) SaveMovieTimeUsage(ctx context.Context, usage *core.MovieTimeUsage) (err error) {
+	defer s.observe("SaveMovieTimeUsage", time.Now(), &err)
+	return s.inner.SaveMovieTimeUsage(ctx, usage)
+}
+
+func (s *StorageMetrics) CreateMovieTimeBypass(ctx context.Context, bypass *core.MovieTimeBypass) (err error) {
+	defer s.observe("CreateMovieTimeBypass", time.Now(), &err)
+	return s.inner.CreateMovieTimeBypass(ctx, bypass)
+}
+
+func (s *StorageMetrics) DeleteMovieTimeBypass(ctx context.Context, id string) (err error) {
+	defer s.observe("DeleteMovieTimeBypass", time.Now(), &err)
+	return s.inner.DeleteMovieTimeBypass(ctx, id)
+}
+
+func (s *StorageMetrics) CreateLockdown(ctx context.Context, lockdown *core.Lockdown) (err error) {
+	defer s.observe("CreateLockdown", time.Now(), &err)
+	return s.inner.CreateLockdown(ctx, lockdown)
+}
+
+func (s *StorageMetrics) UpdateLockdown(ctx context.Context, lockdown *core.Lockdown) (err error) {
+	defer s.observe("UpdateLockdown", time.Now(), &err)
+	return s.inner.UpdateLockdown(ctx, lockdown)
+}
+
+func (s *StorageMetrics) CreateTrackingPause(ctx context.Context, pause *core.TrackingPause) (err error) {
+	defer s.observe("CreateTrackingPause", time.Now(), &err)
+	return s.inner.CreateTrackingPause(ctx, pause)
+}
+
+func (s *StorageMetrics) UpdateTrackingPause(ctx context.Context, pause *core.TrackingPause) (err error) {
+	defer s.observe("UpdateTrackingPause", time.Now(), &err)
+	return s.inner.UpdateTrackingPause(ctx, pause)
+}
+
+func (s *StorageMetrics) SaveDeviceHeartbeat(ctx context.Context, heartbeat *core.DeviceHeartbeat) (err error) {
+	defer s.observe("SaveDeviceHeartbeat", time.Now(), &err)
+	return s.inner.SaveDeviceHeartbeat(ctx, heartbeat)
+}
+
+func (s *StorageMetrics) CreateTamperEvent(ctx context.Context, event *core.TamperEvent) (err error) {
+	defer s.observe("CreateTamperEvent", time.Now(), &err)
+	return s.inner.CreateTamperEvent(ctx, event)
+}
+
+func (s *StorageMetrics) CreateLimitChange(ctx context.Context, change *core.LimitChange) (err error) {
+	defer s.observe("CreateLimitChange", time.Now(), &err)
+	return s.inner.CreateLimitChange(ctx, change)
+}
+
+func (s *StorageMetrics) UpdateLimitChange(ctx context.Context, change *core.LimitChange) (err error) {
+	defer s.observe("UpdateLimitChange", time.Now(), &err)
+	return s.inner.UpdateLimitChange(ctx, change)
+}
+
+func (s *StorageMetrics) DeleteLimitChange(ctx context.Context, id string) (err error) {
+	defer s.observe("DeleteLimitChange", time.Now(), &err)
+	return s.inner.DeleteLimitChange(ctx, id)
+}
+
+func (s *StorageMetrics) CreateAuditEntry(ctx context.Context, entry *core.AuditEntry) (err error) {
+	defer s.observe("CreateAuditEntry", time.Now(), &err)
+	return s.inner.CreateAuditEntry(ctx, entry)
+}
+
+func (s *StorageMetrics) CreateProfileTransition(ctx context.Context, transition *core.ProfileTransition) (err error) {
+	defer s.observe("CreateProfileTransition", time.Now(), &err)
+	return s.inner.CreateProfileTransition(ctx, transition)
+}
+
+func (s *StorageMetrics) UpdateProfileTransition(ctx context.Context, transition *core.ProfileTransition) (err error) {
+	defer s.observe("UpdateProfileTransition", time.Now(), &err)
+	return s.inner.UpdateProfileTransition(ctx, transition)
+}
+
+func (s *StorageMetrics) ClaimSession(ctx context.Context, sessionID, owner string, until time.Time) (result bool, err error) {
+	defer s.observe("ClaimSession", time.Now(), &err)
+	return s.inner.ClaimSession(ctx, sessionID, owner, until)
+}
+
+func (s *StorageMetrics) ReleaseSessionClaim(ctx context.Context, sessionID, owner string) (err error) {
+	defer s.observe("ReleaseSessionClaim", time.Now(), &err)
+	return s.inner.ReleaseSessionClaim(ctx, sessionID, owner)
+}
+
+func (s *StorageMetrics) Close() error {
+	return s.inner.Close()
+}
@@ -0,0 +1,42 @@
+package metrics
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"metron/internal/core"
+	"metron/internal/storage/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStorageMetrics(t *testing.T) {
+	registry := NewRegistry()
+	storage := NewStorageMetrics(memory.New(nil), registry)
+	ctx := context.Background()
+
+	require.NoError(t, storage.CreateChild(ctx, &core.Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 90}))
+	child, err := storage.GetChild(ctx, "child1")
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", child.Name)
+
+	// Not found is an answer, not a failure
+	_, err = storage.GetChild(ctx, "missing")
+	assert.ErrorIs(t, err, core.ErrChildNotFound)
+
+	// Creating the same child twice fails
+	assert.Error(t, storage.CreateChild(ctx, &core.Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 90}))
+
+	var b strings.Builder
+	_, err = registry.WriteTo(&b)
+	require.NoError(t, err)
+	out := b.String()
+
+	assert.Contains(t, out, `metron_storage_calls_total{method="CreateChild"} 2`)
+	assert.Contains(t, out, `metron_storage_calls_total{method="GetChild"} 2`)
+	assert.Contains(t, out, `metron_storage_errors_total{method="CreateChild"} 1`)
+	assert.NotContains(t, out, `metron_storage_errors_total{method="GetChild"}`)
+	assert.Contains(t, out, `metron_storage_call_duration_seconds_count{method="GetChild"} 2`)
+}
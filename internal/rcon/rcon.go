@@ -0,0 +1,167 @@
+// Package rcon implements the client side of the Source RCON protocol, the remote console
+// of Minecraft Java servers (enable-rcon in server.properties) and many other game servers.
+package rcon
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"time"
+)
+
+// DefaultPort is the Minecraft RCON port (rcon.port in server.properties)
+const DefaultPort = 25575
+
+// Packet types
+const (
+	typeResponse     = 0
+	typeCommand      = 2
+	typeAuthResponse = 2
+	typeAuth         = 3
+)
+
+const (
+	// maxBody is the longest command Minecraft accepts
+	maxBody = 1446
+	// maxPacket bounds responses (Minecraft sends at most 4096 bytes of body)
+	maxPacket = 4096 + 10
+)
+
+// defaultTimeout bounds calls whose context has no deadline
+const defaultTimeout = 10 * time.Second
+
+// ErrAuthFailed is returned when the server rejects the password.
+var ErrAuthFailed = errors.New("rcon password rejected")
+
+// Client runs console commands on one server.
+type Client struct {
+	addr     string
+	password string
+}
+
+// NewClient creates a client for the server at addr (host:port).
+func NewClient(addr, password string) *Client {
+	return &Client{addr: addr, password: password}
+}
+
+// packet is one protocol packet
+type packet struct {
+	id   int32
+	kind int32
+	body string
+}
+
+// Run authenticates and runs the commands in order, returning their responses.
+// A connection is opened per call; it stops at the first failed command.
+func (c *Client) Run(ctx context.Context, commands ...string) ([]string, error) {
+	for _, command := range commands {
+		if len(command) > maxBody {
+			return nil, fmt.Errorf("rcon command is longer than %d bytes", maxBody)
+		}
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultTimeout)
+	}
+
+	dialer := net.Dialer{Deadline: deadline}
+	conn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %w", c.addr, err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	if err := c.authenticate(conn); err != nil {
+		return nil, err
+	}
+
+	responses := make([]string, 0, len(commands))
+	for i, command := range commands {
+		id := int32(i + 2)
+		if err := writePacket(conn, packet{id, typeCommand, command}); err != nil {
+			return responses, err
+		}
+		response, err := readResponse(conn, id)
+		if err != nil {
+			return responses, fmt.Errorf("rcon command %q failed: %w", command, err)
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// authenticate sends the password; the server answers with the request ID, or -1 if
+// the password is wrong. Some servers send an empty response packet first.
+func (c *Client) authenticate(conn net.Conn) error {
+	if err := writePacket(conn, packet{1, typeAuth, c.password}); err != nil {
+		return err
+	}
+
+	for {
+		p, err := readPacket(conn)
+		if err != nil {
+			return err
+		}
+		if p.kind != typeAuthResponse {
+			continue
+		}
+		if p.id == -1 {
+			return ErrAuthFailed
+		}
+		return nil
+	}
+}
+
+// readResponse reads packets until the response to the request ID
+func readResponse(r io.Reader, id int32) (string, error) {
+	for {
+		p, err := readPacket(r)
+		if err != nil {
+			return "", err
+		}
+		if p.id == id && p.kind == typeResponse {
+			return p.body, nil
+		}
+	}
+}
+
+func writePacket(w io.Writer, p packet) error {
+	buf := make([]byte, 14+len(p.body))
+	binary.LittleEndian.PutUint32(buf[0:], uint32(10+len(p.body)))
+	binary.LittleEndian.PutUint32(buf[4:], uint32(p.id))
+	binary.LittleEndian.PutUint32(buf[8:], uint32(p.kind))
+	copy(buf[12:], p.body)
+	// The body and the packet end with a null byte each
+
+	if _, err := w.Write(buf); err != nil {
+		return fmt.Errorf("failed to write rcon packet: %w", err)
+	}
+	return nil
+}
+
+func readPacket(r io.Reader) (packet, error) {
+	var size int32
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return packet{}, fmt.Errorf("failed to read rcon packet: %w", err)
+	}
+	if size < 10 || size > maxPacket {
+		return packet{}, fmt.Errorf("invalid rcon packet size %d", size)
+	}
+
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return packet{}, fmt.Errorf("failed to read rcon packet: %w", err)
+	}
+	return packet{
+		id:   int32(binary.LittleEndian.Uint32(buf[0:])),
+		kind: int32(binary.LittleEndian.Uint32(buf[4:])),
+		body: string(buf[8 : size-2]),
+	}, nil
+}
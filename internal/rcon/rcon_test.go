@@ -0,0 +1,95 @@
+package rcon
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeServer accepts RCON connections with one password and echoes commands.
+type fakeServer struct {
+	password string
+	commands chan string
+}
+
+func newFakeServer(t *testing.T, password string) (*fakeServer, string) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	server := &fakeServer{password: password, commands: make(chan string, 10)}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+	return server, listener.Addr().String()
+}
+
+func (s *fakeServer) serve(conn net.Conn) {
+	defer conn.Close()
+
+	auth, err := readPacket(conn)
+	if err != nil || auth.kind != typeAuth {
+		return
+	}
+	// Like Source servers, send an empty response before the auth response
+	writePacket(conn, packet{auth.id, typeResponse, ""})
+	if auth.body != s.password {
+		writePacket(conn, packet{-1, typeAuthResponse, ""})
+		return
+	}
+	writePacket(conn, packet{auth.id, typeAuthResponse, ""})
+
+	for {
+		p, err := readPacket(conn)
+		if err != nil {
+			return
+		}
+		s.commands <- p.body
+		writePacket(conn, packet{p.id, typeResponse, "ran " + p.body})
+	}
+}
+
+func TestClient_Run(t *testing.T) {
+	server, addr := newFakeServer(t, "secret")
+
+	responses, err := NewClient(addr, "secret").Run(context.Background(), "whitelist add Alice", "say hi")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ran whitelist add Alice", "ran say hi"}, responses)
+	assert.Equal(t, "whitelist add Alice", <-server.commands)
+	assert.Equal(t, "say hi", <-server.commands)
+}
+
+func TestClient_WrongPassword(t *testing.T) {
+	_, addr := newFakeServer(t, "secret")
+
+	_, err := NewClient(addr, "wrong").Run(context.Background(), "list")
+	assert.True(t, errors.Is(err, ErrAuthFailed))
+}
+
+func TestClient_CommandTooLong(t *testing.T) {
+	_, err := NewClient("127.0.0.1:1", "secret").Run(context.Background(), strings.Repeat("a", maxBody+1))
+	assert.Error(t, err)
+}
+
+func TestClient_Unreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	listener.Close()
+
+	_, err = NewClient(addr, "secret").Run(context.Background(), "list")
+	assert.Error(t, err)
+}
@@ -0,0 +1,154 @@
+package scheduler
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// Actions the scheduler can plan for a session
+const (
+	PlannedWarning      = "warning"       // time-remaining warning
+	PlannedBreak        = "break"         // mandatory break starts
+	PlannedBreakEnd     = "break_end"     // break in progress ends, session resumes
+	PlannedIdleStop     = "idle_stop"     // stop after the device's idle timeout
+	PlannedDowntimeStop = "downtime_stop" // stop because a child's downtime starts
+	PlannedExpiry       = "expiry"        // planned end of the session
+	PlannedGraceEnd     = "grace_end"     // hard stop of a session running on grace past the daily limit
+)
+
+// PlannedAction is something the scheduler will do to a session if nothing changes
+// The action runs on the first tick at or after At
+type PlannedAction struct {
+	Action  string
+	At      time.Time
+	ChildID string // child whose rule triggers the action ("" for session-wide actions)
+	Detail  string // break action for breaks, otherwise empty
+}
+
+// SessionPreview lists the planned actions for an active session, earliest first
+type SessionPreview struct {
+	SessionID string
+	DeviceID  string
+	ChildIDs  []string
+	Status    string
+	Actions   []PlannedAction
+}
+
+// Next returns the earliest planned action, or nil if none
+func (p *SessionPreview) Next() *PlannedAction {
+	if len(p.Actions) == 0 {
+		return nil
+	}
+	return &p.Actions[0]
+}
+
+// Preview computes the upcoming actions for every active session from the current rules
+// It mirrors processSession without changing anything. Actions already due are reported at now,
+// and actions after the planned end are dropped since the session will be over by then
+func (s *Scheduler) Preview(ctx context.Context, now time.Time) ([]*SessionPreview, error) {
+	sessions, err := s.storage.ListActiveSessions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	previews := make([]*SessionPreview, 0, len(sessions))
+	for _, session := range sessions {
+		expiry := session.StartTime.Add(time.Duration(session.ExpectedDuration) * time.Minute)
+		var actions []PlannedAction
+		add := func(action PlannedAction) {
+			if action.At.After(expiry) {
+				return
+			}
+			if action.At.Before(now) {
+				action.At = now
+			}
+			actions = append(actions, action)
+		}
+
+		// Sessions on grace stop at GraceEndsAt and get no breaks or warnings
+		inGrace := session.GraceEndsAt != nil
+		if inGrace {
+			expiry = *session.GraceEndsAt
+		}
+
+		deviceTimezone := s.deviceTimezone(session.DeviceID)
+		inBreak := session.BreakEndsAt != nil
+		var nextBreak *PlannedAction
+
+		for _, childID := range session.ChildIDs {
+			child, err := s.storage.GetChild(ctx, childID)
+			if err != nil {
+				s.logger.Error("Failed to get child for preview",
+					"session_id", session.ID,
+					"child_id", childID,
+					"error", err)
+				continue
+			}
+
+			// Downtime stop: now if already in downtime, otherwise at the next downtime start
+			if s.downtime != nil && !s.isTrackingPaused(ctx, childID) && !s.isDowntimeOverridden(ctx, session, childID) {
+				if s.downtime.IsChildInDowntimeOnDevice(child, deviceTimezone, now) {
+					add(PlannedAction{Action: PlannedDowntimeStop, At: now, ChildID: childID})
+				} else if child.DowntimeEnabled {
+					start := s.downtime.OnDevice(child, deviceTimezone).GetNextDowntimeStart(now)
+					// Skipped downtime (skip-today) does not start
+					if !start.IsZero() && s.downtime.IsChildInDowntimeOnDevice(child, deviceTimezone, start) {
+						add(PlannedAction{Action: PlannedDowntimeStop, At: start, ChildID: childID})
+					}
+				}
+			}
+
+			// Earliest break among the children's rules
+			if !inBreak && !inGrace && child.BreakRule != nil && !session.BreakExempt {
+				since := session.StartTime
+				if session.LastBreakAt != nil {
+					since = *session.LastBreakAt
+				}
+				at := since.Add(time.Duration(child.BreakRule.BreakAfterMinutes) * time.Minute)
+				if nextBreak == nil || at.Before(nextBreak.At) {
+					nextBreak = &PlannedAction{
+						Action:  PlannedBreak,
+						At:      at,
+						ChildID: childID,
+						Detail:  s.breakAction(session, child.BreakRule),
+					}
+				}
+			}
+		}
+
+		if inBreak {
+			add(PlannedAction{Action: PlannedBreakEnd, At: *session.BreakEndsAt, Detail: session.BreakAction})
+		} else {
+			if nextBreak != nil {
+				add(*nextBreak)
+			}
+			if timeout := s.idleTimeout(session); timeout > 0 && session.LastActivityAt != nil {
+				add(PlannedAction{Action: PlannedIdleStop, At: session.LastActivityAt.Add(timeout)})
+			}
+			// An expired session is stopped without a warning
+			if !inGrace && session.WarningSentAt == nil && expiry.After(now) {
+				add(PlannedAction{Action: PlannedWarning, At: expiry.Add(-warningMinutes * time.Minute)})
+			}
+		}
+		if inGrace {
+			add(PlannedAction{Action: PlannedGraceEnd, At: expiry})
+		} else {
+			add(PlannedAction{Action: PlannedExpiry, At: expiry})
+		}
+
+		sort.SliceStable(actions, func(i, j int) bool {
+			return actions[i].At.Before(actions[j].At)
+		})
+
+		previews = append(previews, &SessionPreview{
+			SessionID: session.ID,
+			DeviceID:  session.DeviceID,
+			ChildIDs:  session.ChildIDs,
+			Status:    string(session.Status),
+			Actions:   actions,
+		})
+	}
+
+	return previews, nil
+}
@@ -0,0 +1,194 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"metron/internal/core"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduler_Preview(t *testing.T) {
+	storage := newMockStorage(t)
+	deviceRegistry := newMockDeviceRegistry()
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "aqara", params: map[string]interface{}{"break_action": "lock"}})
+	deviceRegistry.addDevice(&mockDevice{id: "pc1", driver: "passive", params: map[string]interface{}{"idle_timeout_minutes": float64(10)}})
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	scheduler := NewScheduler(storage, deviceRegistry, &mockDriverRegistry{driver: newMockDriver()}, nil, nil, time.Minute, nil, logger)
+
+	storage.addChild(&core.Child{
+		ID:           "child1",
+		Name:         "Alice",
+		WeekdayLimit: 60,
+		WeekendLimit: 120,
+		BreakRule: &core.BreakRule{
+			BreakAfterMinutes:    30,
+			BreakDurationMinutes: 10,
+		},
+	})
+
+	now := time.Now()
+	start := now.Add(-10 * time.Minute)
+	lastActivity := now.Add(-2 * time.Minute)
+	storage.addSession(&core.Session{
+		ID:               "tv-session",
+		DeviceType:       "tv",
+		DeviceID:         "tv1",
+		ChildIDs:         []string{"child1"},
+		StartTime:        start,
+		ExpectedDuration: 60,
+		Status:           core.SessionStatusActive,
+	})
+	storage.addSession(&core.Session{
+		ID:               "pc-session",
+		DeviceType:       "computer",
+		DeviceID:         "pc1",
+		ChildIDs:         []string{"child1"},
+		StartTime:        start,
+		ExpectedDuration: 30,
+		Status:           core.SessionStatusActive,
+		LastActivityAt:   &lastActivity,
+		BreakExempt:      true,
+	})
+
+	previews, err := scheduler.Preview(context.Background(), now)
+	require.NoError(t, err)
+	require.Len(t, previews, 2)
+
+	byID := make(map[string]*SessionPreview)
+	for _, preview := range previews {
+		byID[preview.SessionID] = preview
+	}
+
+	// Break after 30 minutes (device overrides the action), warning 5 minutes before the end, then expiry
+	tv := byID["tv-session"]
+	require.NotNil(t, tv)
+	require.Len(t, tv.Actions, 3)
+	assert.Equal(t, PlannedBreak, tv.Next().Action)
+	assert.Equal(t, start.Add(30*time.Minute), tv.Next().At)
+	assert.Equal(t, "child1", tv.Next().ChildID)
+	assert.Equal(t, core.BreakActionLock, tv.Next().Detail)
+	assert.Equal(t, PlannedWarning, tv.Actions[1].Action)
+	assert.Equal(t, start.Add(55*time.Minute), tv.Actions[1].At)
+	assert.Equal(t, PlannedExpiry, tv.Actions[2].Action)
+	assert.Equal(t, start.Add(60*time.Minute), tv.Actions[2].At)
+
+	// Break-exempt, so only the idle stop, the warning and the expiry
+	pc := byID["pc-session"]
+	require.NotNil(t, pc)
+	require.Len(t, pc.Actions, 3)
+	assert.Equal(t, PlannedIdleStop, pc.Next().Action)
+	assert.Equal(t, lastActivity.Add(10*time.Minute), pc.Next().At)
+	assert.Equal(t, PlannedWarning, pc.Actions[1].Action)
+	assert.Equal(t, PlannedExpiry, pc.Actions[2].Action)
+}
+
+func TestScheduler_Preview_BreakAndExpiry(t *testing.T) {
+	storage := newMockStorage(t)
+	deviceRegistry := newMockDeviceRegistry()
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "aqara"})
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	scheduler := NewScheduler(storage, deviceRegistry, &mockDriverRegistry{driver: newMockDriver()}, nil, nil, time.Minute, nil, logger)
+
+	storage.addChild(&core.Child{
+		ID:           "child1",
+		Name:         "Alice",
+		WeekdayLimit: 60,
+		WeekendLimit: 120,
+	})
+
+	now := time.Now()
+	breakStarted := now.Add(-2 * time.Minute)
+	breakEnds := now.Add(8 * time.Minute)
+	storage.addSession(&core.Session{
+		ID:               "on-break",
+		DeviceType:       "tv",
+		DeviceID:         "tv1",
+		ChildIDs:         []string{"child1"},
+		StartTime:        now.Add(-40 * time.Minute),
+		ExpectedDuration: 60,
+		Status:           core.SessionStatusPaused,
+		LastBreakAt:      &breakStarted,
+		BreakEndsAt:      &breakEnds,
+		BreakAction:      core.BreakActionWarn,
+	})
+	storage.addSession(&core.Session{
+		ID:               "expired",
+		DeviceType:       "tv",
+		DeviceID:         "tv1",
+		ChildIDs:         []string{"child1"},
+		StartTime:        now.Add(-40 * time.Minute),
+		ExpectedDuration: 30,
+		Status:           core.SessionStatusActive,
+	})
+
+	previews, err := scheduler.Preview(context.Background(), now)
+	require.NoError(t, err)
+
+	byID := make(map[string]*SessionPreview)
+	for _, preview := range previews {
+		byID[preview.SessionID] = preview
+	}
+
+	// Break in progress ends first; no warning or new break is planned during a break
+	onBreak := byID["on-break"]
+	require.NotNil(t, onBreak)
+	require.Len(t, onBreak.Actions, 2)
+	assert.Equal(t, PlannedBreakEnd, onBreak.Next().Action)
+	assert.Equal(t, breakEnds, onBreak.Next().At)
+	assert.Equal(t, core.BreakActionWarn, onBreak.Next().Detail)
+	assert.Equal(t, PlannedExpiry, onBreak.Actions[1].Action)
+
+	// Past the planned end: expiry is due now, without a warning
+	expired := byID["expired"]
+	require.NotNil(t, expired)
+	require.Len(t, expired.Actions, 1)
+	assert.Equal(t, PlannedExpiry, expired.Next().Action)
+	assert.Equal(t, now, expired.Next().At)
+}
+
+func TestScheduler_Preview_Grace(t *testing.T) {
+	storage := newMockStorage(t)
+	deviceRegistry := newMockDeviceRegistry()
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "aqara"})
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	scheduler := NewScheduler(storage, deviceRegistry, &mockDriverRegistry{driver: newMockDriver()}, nil, nil, time.Minute, nil, logger)
+
+	storage.addChild(&core.Child{
+		ID:           "child1",
+		Name:         "Alice",
+		WeekdayLimit: 30,
+		WeekendLimit: 30,
+		GraceMinutes: 5,
+		BreakRule:    &core.BreakRule{BreakAfterMinutes: 30, BreakDurationMinutes: 10},
+	})
+
+	now := time.Now()
+	graceEnds := now.Add(3 * time.Minute)
+	storage.addSession(&core.Session{
+		ID:               "on-grace",
+		DeviceType:       "tv",
+		DeviceID:         "tv1",
+		ChildIDs:         []string{"child1"},
+		StartTime:        now.Add(-32 * time.Minute),
+		ExpectedDuration: 30,
+		Status:           core.SessionStatusActive,
+		GraceEndsAt:      &graceEnds,
+	})
+
+	previews, err := scheduler.Preview(context.Background(), now)
+	require.NoError(t, err)
+	require.Len(t, previews, 1)
+
+	// Only the grace end is planned: no break or warning during grace
+	require.Len(t, previews[0].Actions, 1)
+	assert.Equal(t, PlannedGraceEnd, previews[0].Next().Action)
+	assert.Equal(t, graceEnds, previews[0].Next().At)
+}
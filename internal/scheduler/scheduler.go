@@ -2,11 +2,21 @@ package scheduler
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"math/rand/v2"
 	"metron/internal/core"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+var (
+	ErrSchedulerNotRunning = errors.New("scheduler is not running")
+	ErrSchedulerStalled    = errors.New("scheduler has not ticked recently")
+)
+
 // Storage interface for scheduler operations
 type Storage interface {
 	ListActiveSessions(ctx context.Context) ([]*core.Session, error)
@@ -22,10 +32,22 @@ type Storage interface {
 // Device interface for accessing device information
 type Device interface {
 	GetDriver() string
+	GetTimezone() string // IANA timezone override, or "" if not set
+	GetParameter(key string) interface{}
 }
 
+// idleTimeoutParameter is the device parameter that enables auto-stop of idle sessions
+const idleTimeoutParameter = "idle_timeout_minutes"
+
+// breakActionParameter is the device parameter that overrides the child's break action
+const breakActionParameter = "break_action"
+
+// warningMinutes is how long before the planned end the time-remaining warning is sent
+const warningMinutes = 5
+
 // DeviceDriver interface for device control
 type DeviceDriver interface {
+	StartSession(ctx context.Context, session *core.Session) error
 	StopSession(ctx context.Context, session *core.Session) error
 	ApplyWarning(ctx context.Context, session *core.Session, minutesRemaining int) error
 }
@@ -40,48 +62,240 @@ type DeviceRegistry interface {
 	Get(id string) (Device, error)
 }
 
+// Leader reports whether this instance leads the instances sharing the database
+type Leader interface {
+	IsLeader() bool
+}
+
+// QueuedStarter starts the session starts queued until their device is free
+type QueuedStarter interface {
+	StartQueued(ctx context.Context) (int, error)
+}
+
 // Scheduler manages periodic session updates
 type Scheduler struct {
 	storage        Storage
 	deviceRegistry DeviceRegistry
 	driverRegistry DriverRegistry
+	calculator     *core.TimeCalculationService
 	downtime       *core.DowntimeService
+	trackingPause  *core.TrackingPauseService    // Optional: vacation mode
+	overrides      *core.DowntimeOverrideService // Optional: parent overrides of downtime
+	locks          *core.SessionLocks            // Per-session locks shared with the session manager
+	states         *core.SessionStateMachine     // Session status changes, shared with the session manager
+	limits         *core.LimitScheduleService    // Optional: applies scheduled limit changes
+	profiles       *core.LimitProfileService     // Optional: proposes age-based limit profiles on birthdays
+	usageAlerts    *core.UsageAlertService       // Optional: alerts parents when children reach a share of their time
+	push           *core.PushService             // Optional: sends expiry warnings to browsers subscribed with Web Push
+	dayRollover    *core.DayRolloverService      // Optional: closes out children's days after midnight
+	allocations    *core.AllocationService       // Optional: materializes children's daily allocations
+	queued         QueuedStarter                 // Optional: starts queued sessions once their device is free
+	timeouts       *core.DriverTimeouts          // Optional: per-driver call timeouts (core.DefaultDriverTimeout without)
+	leader         Leader                        // Optional: ticks only run while this instance leads
 	interval       time.Duration
+	jitter         time.Duration            // Optional: up to this much random delay per tick
+	deviceTicks    map[string]time.Duration // Optional: faster tick intervals by device ID
+	lastFullTick   time.Time                // Loop only: when the sessions of every device were last processed
+	lastDeviceTick map[string]time.Time     // Loop only: when the sessions of each faster device were last processed
 	timezone       *time.Location
 	stopChan       chan struct{}
+	doneChan       chan struct{} // closed when a started loop returns
+	runMu          sync.Mutex    // guards running and stopped
+	running        bool
+	stopped        bool
 	logger         *slog.Logger
+
+	// Liveness tracking (unix nanoseconds, 0 = never)
+	startedAt atomic.Int64
+	lastTick  atomic.Int64
 }
 
 // NewScheduler creates a new scheduler
-func NewScheduler(storage Storage, deviceRegistry DeviceRegistry, driverRegistry DriverRegistry, downtime *core.DowntimeService, interval time.Duration, timezone *time.Location, logger *slog.Logger) *Scheduler {
+func NewScheduler(storage Storage, deviceRegistry DeviceRegistry, driverRegistry DriverRegistry, calculator *core.TimeCalculationService, downtime *core.DowntimeService, interval time.Duration, timezone *time.Location, logger *slog.Logger) *Scheduler {
 	if logger == nil {
 		logger = slog.Default()
 	}
 	if timezone == nil {
 		timezone = time.UTC
 	}
+	if calculator == nil {
+		// Charging only needs the charge policy, not calculator storage
+		calculator = core.NewTimeCalculationService(nil, timezone)
+	}
 	return &Scheduler{
 		storage:        storage,
 		deviceRegistry: deviceRegistry,
 		driverRegistry: driverRegistry,
+		calculator:     calculator,
 		downtime:       downtime,
+		locks:          core.NewSessionLocks(),
+		states:         core.NewSessionStateMachine(logger),
 		interval:       interval,
+		lastDeviceTick: make(map[string]time.Time),
 		timezone:       timezone,
 		stopChan:       make(chan struct{}),
+		doneChan:       make(chan struct{}),
 		logger:         logger,
 	}
 }
 
+// SetJitter adds up to jitter of random delay to every tick, so instances sharing a database
+// don't tick at the same moment (capped at half the shortest tick interval)
+func (s *Scheduler) SetJitter(jitter time.Duration) {
+	s.jitter = jitter
+}
+
+// SetDeviceTicks sets faster tick intervals for devices, by device ID
+// Between the scheduler's ticks, sessions on these devices are processed on their own interval
+// (e.g. every 15s for agent-backed PCs). Intervals of at least the scheduler interval are ignored.
+// Set them before Start.
+func (s *Scheduler) SetDeviceTicks(intervals map[string]time.Duration) {
+	s.deviceTicks = make(map[string]time.Duration, len(intervals))
+	for deviceID, interval := range intervals {
+		if interval > 0 && interval < s.interval {
+			s.deviceTicks[deviceID] = interval
+		}
+	}
+}
+
+// SetLeader makes the loop tick only while this instance is the leader, so of several instances
+// sharing a database only one expires sessions and sends warnings (the others keep serving the API)
+func (s *Scheduler) SetLeader(leader Leader) {
+	s.leader = leader
+}
+
+// SetTrackingPause sets the vacation mode service; children with tracking paused
+// are not stopped by downtime and are not charged when their sessions end
+func (s *Scheduler) SetTrackingPause(trackingPause *core.TrackingPauseService) {
+	s.trackingPause = trackingPause
+}
+
+// SetDowntimeOverrides sets the downtime override service; children with an active override
+// are not stopped by downtime, and downtime applies again as soon as the override ends
+func (s *Scheduler) SetDowntimeOverrides(overrides *core.DowntimeOverrideService) {
+	s.overrides = overrides
+}
+
+// SetDriverTimeouts sets the timeouts that bound driver calls, so a hung cloud call
+// can't hold a session lock or the tick
+func (s *Scheduler) SetDriverTimeouts(timeouts *core.DriverTimeouts) {
+	s.timeouts = timeouts
+}
+
+// SetSessionLocks shares the session manager's per-session locks, so a tick never acts on a
+// session while an API request is changing it (e.g. extending it as it is about to expire)
+func (s *Scheduler) SetSessionLocks(locks *core.SessionLocks) {
+	s.locks = locks
+}
+
+// SetSessionStates shares the session manager's state machine, so its guards and hooks
+// see the breaks and expiries applied by the scheduler
+func (s *Scheduler) SetSessionStates(states *core.SessionStateMachine) {
+	s.states = states
+}
+
+// SetLimitSchedule sets the limit schedule service; scheduled limit changes are
+// written to their children on the first tick of their effective day
+func (s *Scheduler) SetLimitSchedule(limits *core.LimitScheduleService) {
+	s.limits = limits
+}
+
+// SetLimitProfiles sets the limit profile service; birthdays that move a child into a
+// new profile are checked on every tick and proposed to parents for confirmation
+func (s *Scheduler) SetLimitProfiles(profiles *core.LimitProfileService) {
+	s.profiles = profiles
+}
+
+// SetUsageAlerts sets the usage alert service; usage is checked against the alert thresholds
+// after sessions are processed on every tick, so running sessions count toward the alerts
+func (s *Scheduler) SetUsageAlerts(alerts *core.UsageAlertService) {
+	s.usageAlerts = alerts
+}
+
+// SetPush sets the Web Push service; expiry warnings are also sent to subscribed browsers,
+// including for devices whose driver cannot warn
+func (s *Scheduler) SetPush(push *core.PushService) {
+	s.push = push
+}
+
+// SetQueuedStarts sets what starts queued sessions; they are started after sessions are
+// processed on every tick, so a device freed by an ending session goes to the next in line
+func (s *Scheduler) SetQueuedStarts(queued QueuedStarter) {
+	s.queued = queued
+}
+
+// SetDayRollover sets the day rollover service; children's days are closed out and the new
+// day's allocations created on the first tick after midnight in each child's timezone
+func (s *Scheduler) SetDayRollover(rollover *core.DayRolloverService) {
+	s.dayRollover = rollover
+}
+
+// SetAllocations sets the allocation service; children's allocations for the day (and days
+// missed while the server was down) are created on the first tick after midnight
+func (s *Scheduler) SetAllocations(allocations *core.AllocationService) {
+	s.allocations = allocations
+}
+
+// isTrackingPaused returns true while tracking is paused for the child
+// Errors are logged and treated as tracked so enforcement continues
+func (s *Scheduler) isTrackingPaused(ctx context.Context, childID string) bool {
+	if s.trackingPause == nil {
+		return false
+	}
+	pause, err := s.trackingPause.PauseFor(ctx, childID)
+	if err != nil {
+		s.logger.Error("Failed to check tracking pause", "child_id", childID, "error", err)
+		return false
+	}
+	return pause != nil
+}
+
+// isDowntimeOverridden returns true if a parent override lets the child's session run during downtime
+// Without the override service the session's own flag decides; errors are logged and
+// treated as not overridden so downtime is enforced
+func (s *Scheduler) isDowntimeOverridden(ctx context.Context, session *core.Session, childID string) bool {
+	if s.overrides == nil {
+		return session.OverrideDowntime
+	}
+	override, err := s.overrides.OverrideFor(ctx, childID, session.ID)
+	if err != nil {
+		s.logger.Error("Failed to check downtime override", "session_id", session.ID, "child_id", childID, "error", err)
+		return false
+	}
+	return override != nil
+}
+
 // Start begins the scheduler loop
 func (s *Scheduler) Start() {
+	s.runMu.Lock()
+	if s.stopped || s.running {
+		// Stop was called before the loop started, or the loop is already running
+		s.runMu.Unlock()
+		return
+	}
+	s.running = true
+	s.runMu.Unlock()
+	defer close(s.doneChan)
+
 	s.logger.Info("Scheduler started")
-	ticker := time.NewTicker(s.interval)
-	defer ticker.Stop()
+	s.startedAt.Store(time.Now().UnixNano())
+	defer s.startedAt.Store(0)
+
+	s.lastFullTick = time.Now()
+	timer := time.NewTimer(s.nextWait())
+	defer timer.Stop()
 
 	for {
 		select {
-		case <-ticker.C:
-			s.tick()
+		case <-timer.C:
+			if s.leader == nil || s.leader.IsLeader() {
+				s.tickDue(time.Now())
+			} else {
+				s.logger.Debug("Not the leader, skipping tick")
+			}
+			// A follower's loop is alive too; it counts as ticking for the health check
+			s.lastTick.Store(time.Now().UnixNano())
+			timer.Reset(s.nextWait())
 		case <-s.stopChan:
 			s.logger.Info("Scheduler stopped")
 			return
@@ -89,9 +303,104 @@ func (s *Scheduler) Start() {
 	}
 }
 
-// Stop stops the scheduler
-func (s *Scheduler) Stop() {
-	close(s.stopChan)
+// step returns how often the loop wakes up: the interval, or the fastest device tick
+func (s *Scheduler) step() time.Duration {
+	step := s.interval
+	for _, interval := range s.deviceTicks {
+		step = min(step, interval)
+	}
+	return step
+}
+
+// nextWait returns the time until the loop wakes up next: the step plus a random jitter
+func (s *Scheduler) nextWait() time.Duration {
+	step := s.step()
+	jitter := min(s.jitter, step/2)
+	if jitter <= 0 {
+		return step
+	}
+	return step + rand.N(jitter)
+}
+
+// tickDue runs the ticks due at now: a full tick once the interval has passed, otherwise
+// only the sessions of devices whose faster tick is due
+// A tick counts as due half a step early, so one wake-up late is never a whole step late.
+func (s *Scheduler) tickDue(now time.Time) {
+	slack := s.step() / 2
+
+	if now.Sub(s.lastFullTick) >= s.interval-slack {
+		s.lastFullTick = now
+		for deviceID := range s.deviceTicks {
+			s.lastDeviceTick[deviceID] = now
+		}
+		s.tick()
+		return
+	}
+
+	due := make(map[string]bool)
+	for deviceID, interval := range s.deviceTicks {
+		if now.Sub(s.lastDeviceTick[deviceID]) >= interval-slack {
+			due[deviceID] = true
+			s.lastDeviceTick[deviceID] = now
+		}
+	}
+	if len(due) > 0 {
+		s.tickDevices(due)
+	}
+}
+
+// LastTick returns when the scheduler last completed a tick (zero if never)
+func (s *Scheduler) LastTick() time.Time {
+	nanos := s.lastTick.Load()
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// CheckHealth reports whether the scheduler loop is running and ticking
+// A scheduler is considered stalled if it has not ticked within two intervals
+func (s *Scheduler) CheckHealth(ctx context.Context) error {
+	started := s.startedAt.Load()
+	if started == 0 {
+		return ErrSchedulerNotRunning
+	}
+
+	lastActivity := time.Unix(0, started)
+	if last := s.LastTick(); last.After(lastActivity) {
+		lastActivity = last
+	}
+
+	if since := time.Since(lastActivity); since > 2*s.interval {
+		return fmt.Errorf("%w: last activity %s ago", ErrSchedulerStalled, since.Round(time.Second))
+	}
+	return nil
+}
+
+// Stop stops the scheduler and waits for an in-flight tick to finish
+// A tick is never interrupted, so driver stop calls already in progress complete
+// Returns ctx.Err() if the tick does not finish before ctx is done
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.runMu.Lock()
+	if !s.stopped {
+		s.stopped = true
+		close(s.stopChan)
+	}
+	running := s.running
+	s.runMu.Unlock()
+
+	// Nothing to wait for if the loop was never started
+	if !running {
+		return nil
+	}
+
+	select {
+	case <-s.doneChan:
+		return nil
+	case <-ctx.Done():
+		s.logger.Warn("Scheduler did not stop in time, in-flight tick abandoned", "error", ctx.Err())
+		return ctx.Err()
+	}
 }
 
 // getDriverForSession looks up the driver for a session
@@ -109,10 +418,56 @@ func (s *Scheduler) getDriverForSession(session *core.Session) (DeviceDriver, er
 	return s.driverRegistry.Get(driverName)
 }
 
+// callDriver makes a driver action for a session, bounded by its driver's timeout
+func (s *Scheduler) callDriver(ctx context.Context, session *core.Session, action string, call func(ctx context.Context) error) error {
+	driverName := ""
+	if device, err := s.deviceRegistry.Get(session.DeviceID); err == nil {
+		driverName = device.GetDriver()
+	}
+	return s.timeouts.Call(ctx, driverName, action, session, call)
+}
+
+// deviceTimezone returns the device's timezone override, or "" if it has none or is unknown
+func (s *Scheduler) deviceTimezone(deviceID string) string {
+	device, err := s.deviceRegistry.Get(deviceID)
+	if err != nil {
+		return ""
+	}
+	return device.GetTimezone()
+}
+
+// Tick runs one scheduler cycle immediately, outside the loop
+// Used by the simulation harness, which drives the scheduler with a fake clock
+func (s *Scheduler) Tick() {
+	s.tick()
+}
+
 // tick performs one cycle of the scheduler
 func (s *Scheduler) tick() {
 	ctx := context.Background()
 
+	if s.limits != nil {
+		if _, err := s.limits.ApplyDue(ctx); err != nil {
+			s.logger.Error("Failed to apply scheduled limit changes", "error", err)
+		}
+	}
+	if s.profiles != nil {
+		if _, err := s.profiles.CheckBirthdays(ctx); err != nil {
+			s.logger.Error("Failed to check birthdays for limit profiles", "error", err)
+		}
+	}
+	if s.dayRollover != nil {
+		// After scheduled limit changes, so the new day's allocation gets the new limits
+		if _, err := s.dayRollover.RollOver(ctx); err != nil {
+			s.logger.Error("Failed to roll over days", "error", err)
+		}
+	}
+	if s.allocations != nil {
+		if _, err := s.allocations.Materialize(ctx); err != nil {
+			s.logger.Error("Failed to materialize allocations", "error", err)
+		}
+	}
+
 	sessions, err := s.storage.ListActiveSessions(ctx)
 	if err != nil {
 		s.logger.Error("Failed to list active sessions", "error", err)
@@ -129,17 +484,77 @@ func (s *Scheduler) tick() {
 			"expected_duration", session.ExpectedDuration,
 			"remaining_minutes", session.CalculateRemainingMinutes())
 
-		if err := s.processSession(ctx, session); err != nil {
+		if err := s.processSessionLocked(ctx, session.ID); err != nil {
+			s.logger.Error("Failed to process session", "session_id", session.ID, "error", err)
+		}
+	}
+
+	if s.queued != nil {
+		if _, err := s.queued.StartQueued(ctx); err != nil {
+			s.logger.Error("Failed to start queued sessions", "error", err)
+		}
+	}
+
+	if s.usageAlerts != nil {
+		if _, err := s.usageAlerts.CheckAll(ctx); err != nil {
+			s.logger.Error("Failed to check usage alerts", "error", err)
+		}
+	}
+}
+
+// tickDevices processes the sessions of the given devices only (a faster device tick)
+// Limit changes, birthdays, day rollovers and usage alerts are left to the full ticks.
+func (s *Scheduler) tickDevices(due map[string]bool) {
+	ctx := context.Background()
+
+	sessions, err := s.storage.ListActiveSessions(ctx)
+	if err != nil {
+		s.logger.Error("Failed to list active sessions", "error", err)
+		return
+	}
+
+	for _, session := range sessions {
+		if !due[session.DeviceID] {
+			continue
+		}
+		if err := s.processSessionLocked(ctx, session.ID); err != nil {
 			s.logger.Error("Failed to process session", "session_id", session.ID, "error", err)
 		}
 	}
 }
 
+// processSessionLocked processes a session while holding its lock
+// The session is read again under the lock: the list at the start of the tick can be stale
+// if an API request changed the session in the meantime (extended or stopped it)
+func (s *Scheduler) processSessionLocked(ctx context.Context, sessionID string) error {
+	release, err := s.locks.Acquire(ctx, sessionID)
+	if errors.Is(err, core.ErrSessionBusy) {
+		// Another process is changing the session; the next tick sees the result
+		s.logger.Info("Session claimed by another process, skipping", "session_id", sessionID)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	session, err := s.storage.GetSession(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	if !session.IsRunning() {
+		s.logger.Debug("Session ended since the tick started, skipping", "session_id", sessionID)
+		return nil
+	}
+	return s.processSession(ctx, session)
+}
+
 // processSession processes a single session
 func (s *Scheduler) processSession(ctx context.Context, session *core.Session) error {
-	// Check if any child is in downtime period
+	// Check if any child is in downtime period (unless a parent overrode downtime for the child)
 	if s.downtime != nil {
-		now := time.Now()
+		now := core.Now()
+		deviceTimezone := s.deviceTimezone(session.DeviceID)
 		for _, childID := range session.ChildIDs {
 			child, err := s.storage.GetChild(ctx, childID)
 			if err != nil {
@@ -150,30 +565,67 @@ func (s *Scheduler) processSession(ctx context.Context, session *core.Session) e
 				continue
 			}
 
-			if s.downtime.IsChildInDowntime(child, now) {
+			if s.downtime.IsChildInDowntimeOnDevice(child, deviceTimezone, now) && !s.isTrackingPaused(ctx, childID) && !s.isDowntimeOverridden(ctx, session, childID) {
 				s.logger.Info("Session stopped due to downtime",
 					"session_id", session.ID,
 					"child_id", childID,
 					"child_name", child.Name)
-				return s.endSession(ctx, session)
+				return s.endSession(ctx, session, core.SessionEndDowntime)
 			}
 		}
 	}
 
+	// Stop the session if the device agent reports no activity for longer than the idle timeout
+	// (not during a break, when the device is expected to be idle)
+	if timeout := s.idleTimeout(session); timeout > 0 && session.BreakEndsAt == nil {
+		now := core.Now()
+		if idle := session.IdleDuration(now); idle >= timeout {
+			s.logger.Info("Session stopped due to inactivity",
+				"session_id", session.ID,
+				"device_id", session.DeviceID,
+				"idle", idle.Round(time.Second),
+				"idle_timeout", timeout)
+			// Idle time is refunded: children are charged up to the last reported activity
+			return s.endSessionAt(ctx, session, *session.LastActivityAt, core.SessionEndIdle)
+		}
+	}
+
 	// Check if session has a break time set
 	if session.BreakEndsAt != nil {
-		if time.Now().After(*session.BreakEndsAt) {
-			// Break has ended, resume session
+		if core.Now().After(*session.BreakEndsAt) {
+			// Break has ended, resume session; the break time is refunded when charging
+			if session.LastBreakAt != nil {
+				session.BreakMinutes += int(session.BreakEndsAt.Sub(*session.LastBreakAt).Minutes())
+			}
+			action := session.BreakAction
 			session.BreakEndsAt = nil
-			session.Status = core.SessionStatusActive
-			s.logger.Info("Session break ended, resuming", "session_id", session.ID)
-			return s.storage.UpdateSession(ctx, session)
+			session.BreakAction = ""
+			// The device was idle on purpose during the break; restart the idle clock
+			if session.LastActivityAt != nil {
+				now := core.Now()
+				session.LastActivityAt = &now
+			}
+			s.logger.Info("Session break ended, resuming", "session_id", session.ID, "break_action", action)
+			if _, err := s.states.Apply(ctx, session, core.SessionEventResume, s.storage.UpdateSession); err != nil {
+				return err
+			}
+			s.endBreak(ctx, session, action)
+			return nil
 		} else {
 			// Still in break
 			return nil
 		}
 	}
 
+	// Session running on grace past the daily limit: the hard stop is GraceEndsAt
+	if session.GraceEndsAt != nil {
+		if core.Now().Before(*session.GraceEndsAt) {
+			return nil
+		}
+		s.logger.Info("Grace period over, stopping", "session_id", session.ID)
+		return s.endSession(ctx, session, core.SessionEndExpired)
+	}
+
 	// Check if any child needs a break
 	for _, childID := range session.ChildIDs {
 		child, err := s.storage.GetChild(ctx, childID)
@@ -182,65 +634,87 @@ func (s *Scheduler) processSession(ctx context.Context, session *core.Session) e
 		}
 
 		if child.BreakRule != nil && session.NeedsBreak(child.BreakRule) {
+			// Guards are checked before the break action runs on the device
+			if err := s.states.Check(session, core.SessionEventPause); err != nil {
+				return err
+			}
+
 			// Enforce break
-			now := time.Now()
+			now := core.Now()
 			breakEnds := now.Add(time.Duration(child.BreakRule.BreakDurationMinutes) * time.Minute)
 			session.LastBreakAt = &now
 			session.BreakEndsAt = &breakEnds
-			session.Status = core.SessionStatusPaused
 
+			action := s.breakAction(session, child.BreakRule)
 			s.logger.Info("Enforcing mandatory break",
 				"session_id", session.ID,
 				"break_duration", child.BreakRule.BreakDurationMinutes,
+				"break_action", action,
 				"child", child.Name)
 
-			// Get driver and trigger warning/pause
-			driver, err := s.getDriverForSession(session)
-			if err != nil {
-				s.logger.Error("Failed to get driver", "session_id", session.ID, "error", err)
-			} else {
-				// Use warning mechanism to notify about break (driver internally looks up device)
-				driver.ApplyWarning(ctx, session, 0)
-			}
+			session.BreakAction = s.startBreak(ctx, session, action, child.BreakRule.BreakDurationMinutes)
 
-			return s.storage.UpdateSession(ctx, session)
+			_, err := s.states.Apply(ctx, session, core.SessionEventPause, s.storage.UpdateSession)
+			return err
 		}
 	}
 
 	// Calculate remaining time for logic (but don't store it)
-	minutesElapsed := int(time.Since(session.StartTime).Minutes())
+	minutesElapsed := int(core.Now().Sub(session.StartTime).Minutes())
 	expectedRemaining := session.ExpectedDuration - minutesElapsed
 
 	if expectedRemaining <= 0 {
+		// Children at the daily limit with a grace allowance get a soft stop
+		if grace := s.graceMinutes(ctx, session); grace > 0 {
+			return s.startGrace(ctx, session, grace)
+		}
+
 		// Session time expired
 		s.logger.Info("Session time expired, stopping", "session_id", session.ID)
-		return s.endSession(ctx, session)
+		return s.endSession(ctx, session, core.SessionEndExpired)
 	}
 
 	// Trigger warning if less than 5 minutes remaining (only once)
-	if expectedRemaining <= 5 && expectedRemaining > 0 && session.WarningSentAt == nil {
+	if expectedRemaining <= warningMinutes && expectedRemaining > 0 && session.WarningSentAt == nil {
+		warned := false
 		driver, err := s.getDriverForSession(session)
-		if err == nil {
+		// Drivers without warnings are skipped; the session still ends on time
+		canWarn := err == nil && core.CapabilitiesOf(driver).SupportsWarnings
+		if canWarn {
 			s.logger.Info("Sending time remaining warning",
 				"session_id", session.ID,
 				"minutes_remaining", expectedRemaining)
 
-			if err := driver.ApplyWarning(ctx, session, expectedRemaining); err != nil {
+			if err := s.callDriver(ctx, session, core.DriverActionWarning, func(ctx context.Context) error {
+				return driver.ApplyWarning(ctx, session, expectedRemaining)
+			}); err != nil {
 				s.logger.Error("Failed to apply warning",
 					"session_id", session.ID,
 					"error", err)
 			} else {
-				// Mark warning as sent and persist
-				now := time.Now()
-				session.WarningSentAt = &now
-				s.logger.Info("Warning sent and marked",
-					"session_id", session.ID,
-					"minutes_remaining", expectedRemaining)
-				// Update session to persist WarningSentAt
-				return s.storage.UpdateSession(ctx, session)
+				warned = true
+			}
+		}
+
+		// Subscribed browsers get the warning with the device (a failed device warning is retried
+		// on the next tick, so they wait for it), or instead of it if the driver cannot warn
+		if s.push != nil && (warned || !canWarn) {
+			if s.push.SessionWarning(ctx, session, expectedRemaining) > 0 {
+				warned = true
 			}
 		}
-	} else if expectedRemaining <= 5 && session.WarningSentAt != nil {
+
+		if warned {
+			// Mark warning as sent and persist
+			now := core.Now()
+			session.WarningSentAt = &now
+			s.logger.Info("Warning sent and marked",
+				"session_id", session.ID,
+				"minutes_remaining", expectedRemaining)
+			// Update session to persist WarningSentAt
+			return s.storage.UpdateSession(ctx, session)
+		}
+	} else if expectedRemaining <= warningMinutes && session.WarningSentAt != nil {
 		s.logger.Debug("Warning already sent, skipping",
 			"session_id", session.ID,
 			"warning_sent_at", session.WarningSentAt,
@@ -251,33 +725,236 @@ func (s *Scheduler) processSession(ctx context.Context, session *core.Session) e
 	return nil
 }
 
-// endSession ends a session and updates usage
-func (s *Scheduler) endSession(ctx context.Context, session *core.Session) error {
-	// Get driver
+// graceMinutes returns the grace the session gets at its planned end (0 = stop now)
+// Children with tracking paused are ignored; errors are logged and end the session as usual
+func (s *Scheduler) graceMinutes(ctx context.Context, session *core.Session) int {
+	var children []*core.Child
+	hasGrace := false
+	for _, childID := range session.ChildIDs {
+		if s.isTrackingPaused(ctx, childID) {
+			continue
+		}
+		child, err := s.storage.GetChild(ctx, childID)
+		if err != nil {
+			s.logger.Error("Failed to get child for grace check",
+				"session_id", session.ID,
+				"child_id", childID,
+				"error", err)
+			return 0
+		}
+		children = append(children, child)
+		hasGrace = hasGrace || child.GraceMinutes > 0
+	}
+	if !hasGrace {
+		return 0
+	}
+
+	grace, err := s.calculator.GraceMinutes(ctx, session, children)
+	if err != nil {
+		s.logger.Error("Failed to calculate grace", "session_id", session.ID, "error", err)
+		return 0
+	}
+	return grace
+}
+
+// startGrace keeps an expired session running until its planned end plus grace and warns the device
+// A session noticed after the grace has passed (e.g. after a restart) is ended right away
+func (s *Scheduler) startGrace(ctx context.Context, session *core.Session, grace int) error {
+	plannedEnd := session.StartTime.Add(time.Duration(session.ExpectedDuration) * time.Minute)
+	graceEnds := plannedEnd.Add(time.Duration(grace) * time.Minute)
+	now := core.Now()
+	if !now.Before(graceEnds) {
+		s.logger.Info("Session time expired, grace already over, stopping", "session_id", session.ID)
+		return s.endSession(ctx, session, core.SessionEndExpired)
+	}
+
+	session.GraceEndsAt = &graceEnds
+	if err := s.storage.UpdateSession(ctx, session); err != nil {
+		return err
+	}
+
+	minutesLeft := int(graceEnds.Sub(now).Round(time.Minute).Minutes())
+	s.logger.Info("Daily limit reached, session running on grace",
+		"session_id", session.ID,
+		"grace_minutes", grace,
+		"grace_ends_at", graceEnds)
+
+	driver, err := s.getDriverForSession(session)
+	if err == nil && core.CapabilitiesOf(driver).SupportsWarnings {
+		if err := s.callDriver(ctx, session, core.DriverActionWarning, func(ctx context.Context) error {
+			return driver.ApplyWarning(ctx, session, minutesLeft)
+		}); err != nil {
+			s.logger.Error("Failed to apply grace warning",
+				"session_id", session.ID,
+				"error", err)
+		}
+	}
+	return nil
+}
+
+// breakAction returns the break action for the session: the device's "break_action"
+// parameter if set and valid, otherwise the child's rule, defaulting to warn
+func (s *Scheduler) breakAction(session *core.Session, rule *core.BreakRule) string {
+	if device, err := s.deviceRegistry.Get(session.DeviceID); err == nil {
+		if action, ok := device.GetParameter(breakActionParameter).(string); ok && action != "" {
+			if core.IsValidBreakAction(action) {
+				return action
+			}
+			s.logger.Warn("Ignoring invalid break_action device parameter",
+				"device_id", session.DeviceID,
+				"break_action", action)
+		}
+	}
+	if rule.Action != "" {
+		return rule.Action
+	}
+	return core.BreakActionWarn
+}
+
+// startBreak runs the break action on the device and returns the action actually applied
+// Driver errors are logged; the break itself is enforced by the paused session either way
+func (s *Scheduler) startBreak(ctx context.Context, session *core.Session, action string, breakMinutes int) string {
+	driver, err := s.getDriverForSession(session)
+	if err != nil {
+		s.logger.Error("Failed to get driver", "session_id", session.ID, "error", err)
+		return action
+	}
+
+	caps := core.CapabilitiesOf(driver)
+	if action == core.BreakActionBreak && !caps.SupportsBreaks {
+		s.logger.Debug("Driver has no break action, sending warning instead", "session_id", session.ID)
+		action = core.BreakActionWarn
+	}
+
+	switch action {
+	case core.BreakActionLock:
+		err = s.callDriver(ctx, session, core.DriverActionStop, func(ctx context.Context) error {
+			return driver.StopSession(ctx, session)
+		})
+	case core.BreakActionBreak:
+		err = s.callDriver(ctx, session, core.DriverActionStartBreak, func(ctx context.Context) error {
+			return driver.(core.BreakableDriver).StartBreak(ctx, session, breakMinutes)
+		})
+	default:
+		// Use warning mechanism to notify about break (driver internally looks up device)
+		// Drivers without warnings get nothing; the paused session still enforces the break
+		if !caps.SupportsWarnings {
+			s.logger.Debug("Driver does not support warnings, skipping break warning", "session_id", session.ID)
+			break
+		}
+		err = s.callDriver(ctx, session, core.DriverActionWarning, func(ctx context.Context) error {
+			return driver.ApplyWarning(ctx, session, 0)
+		})
+	}
+
+	if err != nil {
+		s.logger.Error("Failed to apply break action",
+			"session_id", session.ID,
+			"break_action", action,
+			"error", err)
+	}
+	return action
+}
+
+// endBreak undoes the break action when the session resumes: unlocks a locked device
+// or ends the driver's break action
+func (s *Scheduler) endBreak(ctx context.Context, session *core.Session, action string) {
+	if action != core.BreakActionLock && action != core.BreakActionBreak {
+		return
+	}
+
 	driver, err := s.getDriverForSession(session)
 	if err != nil {
+		s.logger.Error("Failed to get driver", "session_id", session.ID, "error", err)
+		return
+	}
+
+	if action == core.BreakActionLock {
+		err = s.callDriver(ctx, session, core.DriverActionStart, func(ctx context.Context) error {
+			return driver.StartSession(ctx, session)
+		})
+	} else if core.CapabilitiesOf(driver).SupportsBreaks {
+		err = s.callDriver(ctx, session, core.DriverActionEndBreak, func(ctx context.Context) error {
+			return driver.(core.BreakableDriver).EndBreak(ctx, session)
+		})
+	}
+
+	if err != nil {
+		s.logger.Error("Failed to resume device after break",
+			"session_id", session.ID,
+			"break_action", action,
+			"error", err)
+	}
+}
+
+// idleTimeout returns the idle timeout configured for the session's device (0 = disabled)
+func (s *Scheduler) idleTimeout(session *core.Session) time.Duration {
+	device, err := s.deviceRegistry.Get(session.DeviceID)
+	if err != nil {
+		return 0
+	}
+
+	var minutes float64
+	switch v := device.GetParameter(idleTimeoutParameter).(type) {
+	case float64:
+		minutes = v
+	case int:
+		minutes = float64(v)
+	default:
+		return 0
+	}
+
+	if minutes <= 0 {
+		return 0
+	}
+	return time.Duration(minutes * float64(time.Minute))
+}
+
+// endSession ends a session for the given reason (core.SessionEnd*) and updates usage
+func (s *Scheduler) endSession(ctx context.Context, session *core.Session, reason string) error {
+	return s.endSessionAt(ctx, session, core.Now(), reason)
+}
+
+// endSessionAt ends a session for the given reason and charges usage up to the given time
+func (s *Scheduler) endSessionAt(ctx context.Context, session *core.Session, chargeUntil time.Time, reason string) error {
+	// Guards are checked before the device is locked
+	if err := s.states.Check(session, core.SessionEventExpire); err != nil {
 		return err
 	}
 
+	// Recorded before the device is stopped so drivers can report it
+	actual := s.calculator.SessionMinutes(session, chargeUntil)
+	session.ActualDuration = &actual
+
 	// Stop session on device (driver internally looks up device and merges config)
-	if err := driver.StopSession(ctx, session); err != nil {
+	driver, err := s.getDriverForSession(session)
+	if err != nil {
+		// The device or its driver is gone (e.g., removed from the configuration), so the session
+		// could never be stopped on it; end the record instead of retrying on every tick
+		s.logger.Error("Failed to get driver for session, ending it without the device",
+			"session_id", session.ID,
+			"device_id", session.DeviceID,
+			"error", err)
+		reason = core.SessionEndDriverFailure
+	} else if err := s.callDriver(ctx, session, core.DriverActionStop, func(ctx context.Context) error {
+		return driver.StopSession(ctx, session)
+	}); err != nil {
 		s.logger.Error("Failed to stop session on device", "session_id", session.ID, "error", err)
 		// Continue anyway to update session status
 	}
 
 	// Update session status
-	session.Status = core.SessionStatusExpired
-
-	if err := s.storage.UpdateSession(ctx, session); err != nil {
+	session.EndReason = reason
+	if _, err := s.states.Apply(ctx, session, core.SessionEventExpire, s.storage.UpdateSession); err != nil {
 		return err
 	}
 
-	elapsed := int(time.Since(session.StartTime).Minutes())
-	today := time.Now().In(s.timezone)
+	now := core.Now()
+	today := now.In(s.timezone)
 
 	// Handle movie session - don't update individual quotas, just mark as used
 	if session.IsMovieSession {
-		s.logger.Info("Movie session ended", "session_id", session.ID, "duration_minutes", elapsed)
+		s.logger.Info("Movie session ended", "session_id", session.ID, "duration_minutes", actual)
 		// Mark movie time as used
 		if err := s.markMovieTimeUsed(ctx, session.ID, today); err != nil {
 			s.logger.Error("Failed to mark movie time as used",
@@ -288,13 +965,25 @@ func (s *Scheduler) endSession(ctx context.Context, session *core.Session) error
 	}
 
 	// Update daily usage summary for all children (only for non-movie sessions)
+	charged := s.calculator.ChargeableMinutes(session, chargeUntil)
 	for _, childID := range session.ChildIDs {
-		if err := s.storage.IncrementDailyUsageSummary(ctx, childID, today, elapsed); err != nil {
+		if s.isTrackingPaused(ctx, childID) {
+			s.logger.Debug("Tracking paused, usage not recorded", "session_id", session.ID, "child_id", childID)
+			continue
+		}
+		if err := s.storage.IncrementDailyUsageSummary(ctx, childID, s.calculator.UsageDate(ctx, childID, now), charged); err != nil {
 			s.logger.Error("Failed to update daily usage summary", "child_id", childID, "error", err)
 		}
+
+		// Time past the planned end during grace is deducted from tomorrow
+		if overage, err := s.calculator.ChargeGraceOverage(ctx, session, childID, chargeUntil); err != nil {
+			s.logger.Error("Failed to deduct grace overage", "session_id", session.ID, "child_id", childID, "error", err)
+		} else if overage > 0 {
+			s.logger.Info("Grace overage deducted from tomorrow", "session_id", session.ID, "child_id", childID, "overage_minutes", overage)
+		}
 	}
 
-	s.logger.Info("Session ended", "session_id", session.ID, "duration_minutes", elapsed)
+	s.logger.Info("Session ended", "session_id", session.ID, "duration_minutes", charged, "end_reason", session.EndReason)
 	return nil
 }
 
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"log/slog"
 	"metron/internal/core"
+	"metron/internal/storage/memory"
 	"os"
 	"testing"
 	"time"
@@ -15,92 +16,33 @@ import (
 
 // Mock implementations
 
+// mockStorage is the in-memory backend with helpers for seeding and inspecting test data
 type mockStorage struct {
-	sessions       map[string]*core.Session
-	children       map[string]*core.Child
-	dailyUsage     map[string]int
-	movieTimeUsage map[string]*core.MovieTimeUsage // keyed by date
+	*memory.Storage
+	t *testing.T
 }
 
-func newMockStorage() *mockStorage {
-	return &mockStorage{
-		sessions:       make(map[string]*core.Session),
-		children:       make(map[string]*core.Child),
-		dailyUsage:     make(map[string]int),
-		movieTimeUsage: make(map[string]*core.MovieTimeUsage),
-	}
-}
-
-func (m *mockStorage) ListActiveSessions(ctx context.Context) ([]*core.Session, error) {
-	sessions := make([]*core.Session, 0)
-	for _, session := range m.sessions {
-		if session.Status == core.SessionStatusActive || session.Status == core.SessionStatusPaused {
-			sessions = append(sessions, session)
-		}
-	}
-	return sessions, nil
-}
-
-func (m *mockStorage) GetSession(ctx context.Context, id string) (*core.Session, error) {
-	session, ok := m.sessions[id]
-	if !ok {
-		return nil, core.ErrSessionNotFound
-	}
-	return session, nil
-}
-
-func (m *mockStorage) UpdateSession(ctx context.Context, session *core.Session) error {
-	m.sessions[session.ID] = session
-	return nil
-}
-
-func (m *mockStorage) GetChild(ctx context.Context, id string) (*core.Child, error) {
-	child, ok := m.children[id]
-	if !ok {
-		return nil, core.ErrChildNotFound
-	}
-	return child, nil
-}
-
-func (m *mockStorage) IncrementDailyUsage(ctx context.Context, childID string, date time.Time, minutes int) error {
-	key := childID + date.Format("2006-01-02")
-	m.dailyUsage[key] += minutes
-	return nil
-}
-
-func (m *mockStorage) IncrementDailyUsageSummary(ctx context.Context, childID string, date time.Time, minutes int) error {
-	key := childID + date.Format("2006-01-02")
-	m.dailyUsage[key] += minutes
-	return nil
-}
-
-func (m *mockStorage) IncrementSessionCount(ctx context.Context, childID string, date time.Time) error {
-	return nil
-}
-
-func (m *mockStorage) GetMovieTimeUsage(ctx context.Context, date time.Time) (*core.MovieTimeUsage, error) {
-	key := date.Format("2006-01-02")
-	if usage, ok := m.movieTimeUsage[key]; ok {
-		return usage, nil
-	}
-	return nil, nil
-}
-
-func (m *mockStorage) SaveMovieTimeUsage(ctx context.Context, usage *core.MovieTimeUsage) error {
-	key := usage.Date.Format("2006-01-02")
-	m.movieTimeUsage[key] = usage
-	return nil
+func newMockStorage(t *testing.T) *mockStorage {
+	return &mockStorage{Storage: memory.New(time.Local), t: t}
 }
 
 func (m *mockStorage) addSession(session *core.Session) {
-	m.sessions[session.ID] = session
+	require.NoError(m.t, m.CreateSession(context.Background(), session))
 }
 
 func (m *mockStorage) addChild(child *core.Child) {
-	m.children[child.ID] = child
+	require.NoError(m.t, m.CreateChild(context.Background(), child))
+}
+
+// minutesUsed returns the minutes charged to a child on the day of date
+func (m *mockStorage) minutesUsed(childID string, date time.Time) int {
+	summary, err := m.GetDailyUsageSummary(context.Background(), childID, date)
+	require.NoError(m.t, err)
+	return summary.MinutesUsed
 }
 
 type mockDriver struct {
+	startCalls   []string
 	stopCalls    []string
 	warnCalls    []string
 	failStop     bool
@@ -109,11 +51,17 @@ type mockDriver struct {
 
 func newMockDriver() *mockDriver {
 	return &mockDriver{
-		stopCalls: make([]string, 0),
-		warnCalls: make([]string, 0),
+		startCalls: make([]string, 0),
+		stopCalls:  make([]string, 0),
+		warnCalls:  make([]string, 0),
 	}
 }
 
+func (m *mockDriver) StartSession(ctx context.Context, session *core.Session) error {
+	m.startCalls = append(m.startCalls, session.ID)
+	return nil
+}
+
 func (m *mockDriver) StopSession(ctx context.Context, session *core.Session) error {
 	m.stopCalls = append(m.stopCalls, session.ID)
 	if m.failStop {
@@ -131,14 +79,24 @@ func (m *mockDriver) ApplyWarning(ctx context.Context, session *core.Session, mi
 }
 
 type mockDevice struct {
-	id     string
-	driver string
+	id       string
+	driver   string
+	timezone string
+	params   map[string]interface{}
 }
 
 func (m *mockDevice) GetDriver() string {
 	return m.driver
 }
 
+func (m *mockDevice) GetTimezone() string {
+	return m.timezone
+}
+
+func (m *mockDevice) GetParameter(key string) interface{} {
+	return m.params[key]
+}
+
 type mockDeviceRegistry struct {
 	devices map[string]*mockDevice
 }
@@ -175,7 +133,7 @@ func (m *mockDriverRegistry) Get(name string) (DeviceDriver, error) {
 // Tests
 
 func TestScheduler_ProcessSession_Expired(t *testing.T) {
-	storage := newMockStorage()
+	storage := newMockStorage(t)
 	driver := newMockDriver()
 	deviceRegistry := newMockDeviceRegistry()
 	driverRegistry := &mockDriverRegistry{driver: driver}
@@ -184,7 +142,7 @@ func TestScheduler_ProcessSession_Expired(t *testing.T) {
 	deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "aqara"})
 
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	scheduler := NewScheduler(storage, deviceRegistry, driverRegistry, nil, time.Minute, nil, logger)
+	scheduler := NewScheduler(storage, deviceRegistry, driverRegistry, nil, nil, time.Minute, nil, logger)
 
 	// Create child
 	child := &core.Child{
@@ -217,16 +175,257 @@ func TestScheduler_ProcessSession_Expired(t *testing.T) {
 	// Verify session status updated
 	updated, _ := storage.GetSession(context.Background(), "session1")
 	assert.Equal(t, core.SessionStatusExpired, updated.Status)
+	assert.Equal(t, core.SessionEndExpired, updated.EndReason)
+	require.NotNil(t, updated.ActualDuration)
+	assert.Equal(t, 30, *updated.ActualDuration, "time past the planned end is not counted")
 	assert.Equal(t, 0, updated.CalculateRemainingMinutes())
 
 	// Verify daily usage was updated
-	today := time.Now()
-	key := "child1" + today.Format("2006-01-02")
-	assert.GreaterOrEqual(t, storage.dailyUsage[key], 30)
+	assert.Equal(t, 30, storage.minutesUsed("child1", time.Now()), "expired sessions charge the planned duration, not overtime")
+}
+
+func TestScheduler_ProcessSession_ExpiredWithBreak(t *testing.T) {
+	storage := newMockStorage(t)
+	driver := newMockDriver()
+	deviceRegistry := newMockDeviceRegistry()
+	driverRegistry := &mockDriverRegistry{driver: driver}
+
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "aqara"})
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	scheduler := NewScheduler(storage, deviceRegistry, driverRegistry, nil, nil, time.Minute, nil, logger)
+
+	storage.addChild(&core.Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 120})
+
+	// Expired session that already took a 10 minute break
+	session := &core.Session{
+		ID:               "session1",
+		DeviceType:       "tv",
+		DeviceID:         "tv1",
+		ChildIDs:         []string{"child1"},
+		StartTime:        time.Now().Add(-31 * time.Minute),
+		ExpectedDuration: 30,
+		BreakMinutes:     10,
+		Status:           core.SessionStatusActive,
+	}
+	storage.addSession(session)
+
+	err := scheduler.processSession(context.Background(), session)
+	require.NoError(t, err)
+
+	assert.Equal(t, 20, storage.minutesUsed("child1", time.Now()), "break time should be refunded on expiry")
+}
+
+func TestScheduler_ProcessSession_ExpiredTrackingPaused(t *testing.T) {
+	storage := newMockStorage(t)
+	driver := newMockDriver()
+	deviceRegistry := newMockDeviceRegistry()
+	driverRegistry := &mockDriverRegistry{driver: driver}
+
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "aqara"})
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	scheduler := NewScheduler(storage, deviceRegistry, driverRegistry, nil, nil, time.Minute, nil, logger)
+
+	storage.addChild(&core.Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 120})
+	storage.addChild(&core.Child{ID: "child2", Name: "Bob", WeekdayLimit: 60, WeekendLimit: 120})
+
+	// Tracking is paused for child1 only
+	trackingPause := core.NewTrackingPauseService(storage, logger)
+	_, err := trackingPause.Pause(context.Background(), "child1", "api", "vacation", nil)
+	require.NoError(t, err)
+	scheduler.SetTrackingPause(trackingPause)
+
+	session := &core.Session{
+		ID:               "session1",
+		DeviceType:       "tv",
+		DeviceID:         "tv1",
+		ChildIDs:         []string{"child1", "child2"},
+		StartTime:        time.Now().Add(-31 * time.Minute),
+		ExpectedDuration: 30,
+		Status:           core.SessionStatusActive,
+	}
+	storage.addSession(session)
+
+	err = scheduler.processSession(context.Background(), session)
+	require.NoError(t, err)
+
+	assert.Equal(t, 0, storage.minutesUsed("child1", time.Now()), "paused child should not be charged")
+	assert.Equal(t, 30, storage.minutesUsed("child2", time.Now()))
+}
+
+func TestScheduler_ProcessSession_Grace(t *testing.T) {
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	setup := func(t *testing.T, child *core.Child) (*Scheduler, *mockStorage, *mockDriver, *core.TimeCalculationService) {
+		storage := newMockStorage(t)
+		driver := newMockDriver()
+		deviceRegistry := newMockDeviceRegistry()
+		deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "aqara"})
+		calculator := core.NewTimeCalculationService(storage, time.Local)
+		scheduler := NewScheduler(storage, deviceRegistry, &mockDriverRegistry{driver: driver}, calculator, nil, time.Minute, time.Local, logger)
+		storage.addChild(child)
+		return scheduler, storage, driver, calculator
+	}
+
+	newSession := func(start time.Time) *core.Session {
+		return &core.Session{
+			ID:               "session1",
+			DeviceType:       "tv",
+			DeviceID:         "tv1",
+			ChildIDs:         []string{"child1"},
+			StartTime:        start,
+			ExpectedDuration: 30,
+			Status:           core.SessionStatusActive,
+		}
+	}
+
+	t.Run("limit reached starts grace", func(t *testing.T) {
+		scheduler, storage, driver, _ := setup(t, &core.Child{ID: "child1", Name: "Alice", WeekdayLimit: 30, WeekendLimit: 30, GraceMinutes: 5})
+		session := newSession(time.Now().Add(-31 * time.Minute))
+		storage.addSession(session)
+
+		require.NoError(t, scheduler.processSession(ctx, session))
+
+		assert.Empty(t, driver.stopCalls, "session keeps running on grace")
+		assert.Equal(t, []string{"session1"}, driver.warnCalls)
+		updated, err := storage.GetSession(ctx, "session1")
+		require.NoError(t, err)
+		assert.Equal(t, core.SessionStatusActive, updated.Status)
+		require.NotNil(t, updated.GraceEndsAt)
+		assert.WithinDuration(t, session.StartTime.Add(35*time.Minute), *updated.GraceEndsAt, 0)
+
+		// Later ticks during grace change nothing
+		require.NoError(t, scheduler.processSession(ctx, updated))
+		assert.Empty(t, driver.stopCalls)
+		assert.Len(t, driver.warnCalls, 1)
+	})
+
+	t.Run("grace over stops and deducts overage from tomorrow", func(t *testing.T) {
+		scheduler, storage, driver, calculator := setup(t, &core.Child{ID: "child1", Name: "Alice", WeekdayLimit: 30, WeekendLimit: 30, GraceMinutes: 5})
+		session := newSession(time.Now().Add(-36 * time.Minute))
+		graceEnds := session.StartTime.Add(35 * time.Minute)
+		session.GraceEndsAt = &graceEnds
+		storage.addSession(session)
+
+		require.NoError(t, scheduler.processSession(ctx, session))
+
+		assert.Contains(t, driver.stopCalls, "session1")
+		assert.Equal(t, 30, storage.minutesUsed("child1", time.Now()), "grace is not charged today")
+		tomorrow, err := calculator.GetAvailableTime(ctx, "child1", time.Now().AddDate(0, 0, 1))
+		require.NoError(t, err)
+		assert.Equal(t, 25, tomorrow.TotalAvailable, "grace overage is deducted from tomorrow")
+	})
+
+	t.Run("time left ends the session as planned", func(t *testing.T) {
+		scheduler, storage, driver, _ := setup(t, &core.Child{ID: "child1", Name: "Alice", WeekdayLimit: 120, WeekendLimit: 120, GraceMinutes: 5})
+		session := newSession(time.Now().Add(-31 * time.Minute))
+		storage.addSession(session)
+
+		require.NoError(t, scheduler.processSession(ctx, session))
+
+		assert.Contains(t, driver.stopCalls, "session1")
+		updated, err := storage.GetSession(ctx, "session1")
+		require.NoError(t, err)
+		assert.Nil(t, updated.GraceEndsAt)
+	})
+}
+
+func TestScheduler_ProcessSession_IdleTimeout(t *testing.T) {
+	storage := newMockStorage(t)
+	driver := newMockDriver()
+	deviceRegistry := newMockDeviceRegistry()
+	driverRegistry := &mockDriverRegistry{driver: driver}
+
+	// PC agent reports activity; TV has no idle timeout
+	deviceRegistry.addDevice(&mockDevice{id: "pc1", driver: "winagent", params: map[string]interface{}{"idle_timeout_minutes": float64(10)}})
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "aqara"})
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	scheduler := NewScheduler(storage, deviceRegistry, driverRegistry, nil, nil, time.Minute, nil, logger)
+
+	storage.addChild(&core.Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 120})
+
+	// Started 30 minutes ago, last activity 15 minutes ago
+	now := time.Now()
+	startTime := now.Add(-30 * time.Minute)
+	lastActivity := now.Add(-15 * time.Minute)
+	idleSession := &core.Session{
+		ID:               "session1",
+		DeviceType:       "pc",
+		DeviceID:         "pc1",
+		ChildIDs:         []string{"child1"},
+		StartTime:        startTime,
+		ExpectedDuration: 60,
+		Status:           core.SessionStatusActive,
+		LastActivityAt:   &lastActivity,
+	}
+	storage.addSession(idleSession)
+
+	// Same idle time on a device without an idle timeout
+	tvSession := &core.Session{
+		ID:               "session2",
+		DeviceType:       "tv",
+		DeviceID:         "tv1",
+		ChildIDs:         []string{"child1"},
+		StartTime:        startTime,
+		ExpectedDuration: 60,
+		Status:           core.SessionStatusActive,
+		LastActivityAt:   &lastActivity,
+	}
+	storage.addSession(tvSession)
+
+	require.NoError(t, scheduler.processSession(context.Background(), idleSession))
+	require.NoError(t, scheduler.processSession(context.Background(), tvSession))
+
+	// Only the idle PC session is stopped
+	assert.Equal(t, []string{"session1"}, driver.stopCalls)
+	assert.Equal(t, core.SessionStatusExpired, idleSession.Status)
+	assert.Equal(t, core.SessionEndIdle, idleSession.EndReason)
+	require.NotNil(t, idleSession.ActualDuration)
+	assert.Equal(t, 15, *idleSession.ActualDuration)
+	assert.Equal(t, core.SessionStatusActive, tvSession.Status)
+
+	// Idle minutes are refunded: only the 15 active minutes are charged
+	assert.Equal(t, 15, storage.minutesUsed("child1", time.Now()))
+}
+
+func TestScheduler_ProcessSession_DriverFailure(t *testing.T) {
+	storage := newMockStorage(t)
+	driver := newMockDriver()
+	deviceRegistry := newMockDeviceRegistry()
+	driverRegistry := &mockDriverRegistry{driver: driver}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	scheduler := NewScheduler(storage, deviceRegistry, driverRegistry, nil, nil, time.Minute, nil, logger)
+
+	storage.addChild(&core.Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 120})
+
+	// The session's device was removed from the configuration
+	session := &core.Session{
+		ID:               "session1",
+		DeviceType:       "tv",
+		DeviceID:         "removed-tv",
+		ChildIDs:         []string{"child1"},
+		StartTime:        time.Now().Add(-31 * time.Minute),
+		ExpectedDuration: 30,
+		Status:           core.SessionStatusActive,
+	}
+	storage.addSession(session)
+
+	require.NoError(t, scheduler.processSession(context.Background(), session))
+
+	// The record is ended instead of failing on every tick
+	assert.Empty(t, driver.stopCalls)
+	updated, _ := storage.GetSession(context.Background(), "session1")
+	assert.Equal(t, core.SessionStatusExpired, updated.Status)
+	assert.Equal(t, core.SessionEndDriverFailure, updated.EndReason)
+	assert.Equal(t, 30, storage.minutesUsed("child1", time.Now()))
 }
 
 func TestScheduler_ProcessSession_Warning(t *testing.T) {
-	storage := newMockStorage()
+	storage := newMockStorage(t)
 	driver := newMockDriver()
 	deviceRegistry := newMockDeviceRegistry()
 	driverRegistry := &mockDriverRegistry{driver: driver}
@@ -235,7 +434,7 @@ func TestScheduler_ProcessSession_Warning(t *testing.T) {
 	deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "aqara"})
 
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	scheduler := NewScheduler(storage, deviceRegistry, driverRegistry, nil, time.Minute, nil, logger)
+	scheduler := NewScheduler(storage, deviceRegistry, driverRegistry, nil, nil, time.Minute, nil, logger)
 
 	// Create child
 	child := &core.Child{
@@ -272,7 +471,7 @@ func TestScheduler_ProcessSession_Warning(t *testing.T) {
 }
 
 func TestScheduler_ProcessSession_NoWarning(t *testing.T) {
-	storage := newMockStorage()
+	storage := newMockStorage(t)
 	driver := newMockDriver()
 	deviceRegistry := newMockDeviceRegistry()
 	driverRegistry := &mockDriverRegistry{driver: driver}
@@ -281,7 +480,7 @@ func TestScheduler_ProcessSession_NoWarning(t *testing.T) {
 	deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "aqara"})
 
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	scheduler := NewScheduler(storage, deviceRegistry, driverRegistry, nil, time.Minute, nil, logger)
+	scheduler := NewScheduler(storage, deviceRegistry, driverRegistry, nil, nil, time.Minute, nil, logger)
 
 	// Create child
 	child := &core.Child{
@@ -317,8 +516,134 @@ func TestScheduler_ProcessSession_NoWarning(t *testing.T) {
 	assert.GreaterOrEqual(t, updated.CalculateRemainingMinutes(), 14)
 }
 
+// mockCapableDriver declares its capabilities (e.g., no warnings)
+type mockCapableDriver struct {
+	*mockDriver
+	caps core.DriverCapabilities
+}
+
+func (d *mockCapableDriver) Capabilities() core.DriverCapabilities {
+	return d.caps
+}
+
+type capableDriverRegistry struct {
+	driver *mockCapableDriver
+}
+
+func (m *capableDriverRegistry) Get(name string) (DeviceDriver, error) {
+	return m.driver, nil
+}
+
+// mockPushSender records the Web Push messages sent
+type mockPushSender struct {
+	messages []*core.PushMessage
+}
+
+func (m *mockPushSender) Send(ctx context.Context, subscription *core.PushSubscription, message *core.PushMessage) error {
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+func TestScheduler_ProcessSession_DriverWithoutWarnings(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	driver := &mockCapableDriver{mockDriver: newMockDriver(), caps: core.DriverCapabilities{SupportsScheduling: true}}
+	deviceRegistry := newMockDeviceRegistry()
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "kidslox"})
+
+	t.Run("time remaining warning is skipped", func(t *testing.T) {
+		storage := newMockStorage(t)
+		scheduler := NewScheduler(storage, deviceRegistry, &capableDriverRegistry{driver: driver}, nil, nil, time.Minute, nil, logger)
+		storage.addChild(&core.Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 120})
+
+		session := &core.Session{
+			ID:               "session1",
+			DeviceType:       "tv",
+			DeviceID:         "tv1",
+			ChildIDs:         []string{"child1"},
+			StartTime:        time.Now().Add(-26 * time.Minute),
+			ExpectedDuration: 30,
+			Status:           core.SessionStatusActive,
+		}
+		storage.addSession(session)
+
+		require.NoError(t, scheduler.processSession(context.Background(), session))
+
+		assert.Empty(t, driver.warnCalls)
+		updated, _ := storage.GetSession(context.Background(), "session1")
+		assert.Equal(t, core.SessionStatusActive, updated.Status)
+		assert.Nil(t, updated.WarningSentAt)
+	})
+
+	t.Run("subscribed browsers are warned instead", func(t *testing.T) {
+		storage := newMockStorage(t)
+		scheduler := NewScheduler(storage, deviceRegistry, &capableDriverRegistry{driver: driver}, nil, nil, time.Minute, nil, logger)
+		sender := &mockPushSender{}
+		scheduler.SetPush(core.NewPushService(storage, sender, "public-key", logger))
+		storage.addChild(&core.Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 120})
+
+		session := &core.Session{
+			ID:               "session1",
+			DeviceType:       "tv",
+			DeviceID:         "tv1",
+			ChildIDs:         []string{"child1"},
+			StartTime:        time.Now().Add(-26 * time.Minute),
+			ExpectedDuration: 30,
+			Status:           core.SessionStatusActive,
+		}
+		storage.addSession(session)
+
+		// Nobody subscribed: the warning is not marked as sent
+		require.NoError(t, scheduler.processSession(context.Background(), session))
+		updated, _ := storage.GetSession(context.Background(), "session1")
+		assert.Nil(t, updated.WarningSentAt)
+
+		require.NoError(t, storage.SavePushSubscription(context.Background(), &core.PushSubscription{
+			ID: "psh_1", ChildID: "child1", Endpoint: "https://push.example.com/1", CreatedAt: time.Now(),
+		}))
+		require.NoError(t, scheduler.processSession(context.Background(), updated))
+
+		assert.Empty(t, driver.warnCalls)
+		require.Len(t, sender.messages, 1)
+		assert.Equal(t, core.PushSessionWarning, sender.messages[0].Kind)
+		assert.Equal(t, "session1", sender.messages[0].SessionID)
+		updated, _ = storage.GetSession(context.Background(), "session1")
+		assert.NotNil(t, updated.WarningSentAt)
+	})
+
+	t.Run("break still pauses the session", func(t *testing.T) {
+		storage := newMockStorage(t)
+		scheduler := NewScheduler(storage, deviceRegistry, &capableDriverRegistry{driver: driver}, nil, nil, time.Minute, nil, logger)
+		storage.addChild(&core.Child{
+			ID:           "child1",
+			Name:         "Alice",
+			WeekdayLimit: 60,
+			WeekendLimit: 120,
+			BreakRule:    &core.BreakRule{BreakAfterMinutes: 30, BreakDurationMinutes: 10, Action: core.BreakActionBreak},
+		})
+
+		session := &core.Session{
+			ID:               "session1",
+			DeviceType:       "tv",
+			DeviceID:         "tv1",
+			ChildIDs:         []string{"child1"},
+			StartTime:        time.Now().Add(-31 * time.Minute),
+			ExpectedDuration: 60,
+			Status:           core.SessionStatusActive,
+		}
+		storage.addSession(session)
+
+		require.NoError(t, scheduler.processSession(context.Background(), session))
+
+		// Neither a break action nor a warning is available
+		assert.Empty(t, driver.warnCalls)
+		updated, _ := storage.GetSession(context.Background(), "session1")
+		assert.Equal(t, core.SessionStatusPaused, updated.Status)
+		assert.Equal(t, core.BreakActionWarn, updated.BreakAction)
+	})
+}
+
 func TestScheduler_ProcessSession_BreakRule(t *testing.T) {
-	storage := newMockStorage()
+	storage := newMockStorage(t)
 	driver := newMockDriver()
 	deviceRegistry := newMockDeviceRegistry()
 	driverRegistry := &mockDriverRegistry{driver: driver}
@@ -327,7 +652,7 @@ func TestScheduler_ProcessSession_BreakRule(t *testing.T) {
 	deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "aqara"})
 
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	scheduler := NewScheduler(storage, deviceRegistry, driverRegistry, nil, time.Minute, nil, logger)
+	scheduler := NewScheduler(storage, deviceRegistry, driverRegistry, nil, nil, time.Minute, nil, logger)
 
 	// Create child with break rule
 	child := &core.Child{
@@ -368,8 +693,140 @@ func TestScheduler_ProcessSession_BreakRule(t *testing.T) {
 	assert.Contains(t, driver.warnCalls, "session1")
 }
 
+func TestScheduler_ProcessSession_StateMachine(t *testing.T) {
+	ctx := context.Background()
+	storage := newMockStorage(t)
+	driver := newMockDriver()
+	deviceRegistry := newMockDeviceRegistry()
+	driverRegistry := &mockDriverRegistry{driver: driver}
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "aqara"})
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	scheduler := NewScheduler(storage, deviceRegistry, driverRegistry, nil, nil, time.Minute, nil, logger)
+	states := core.NewSessionStateMachine(logger)
+	scheduler.SetSessionStates(states)
+
+	var transitions []core.SessionTransition
+	states.AddHook(func(ctx context.Context, session *core.Session, transition core.SessionTransition) {
+		transitions = append(transitions, transition)
+	})
+
+	storage.addChild(&core.Child{
+		ID:           "child1",
+		Name:         "Alice",
+		WeekdayLimit: 120,
+		WeekendLimit: 120,
+		BreakRule:    &core.BreakRule{BreakAfterMinutes: 30, BreakDurationMinutes: 10},
+	})
+	storage.addSession(&core.Session{
+		ID:               "session1",
+		DeviceType:       "tv",
+		DeviceID:         "tv1",
+		ChildIDs:         []string{"child1"},
+		StartTime:        time.Now().Add(-31 * time.Minute),
+		ExpectedDuration: 60,
+		Status:           core.SessionStatusActive,
+	})
+
+	process := func() *core.Session {
+		session, err := storage.GetSession(ctx, "session1")
+		require.NoError(t, err)
+		require.NoError(t, scheduler.processSession(ctx, session))
+		updated, err := storage.GetSession(ctx, "session1")
+		require.NoError(t, err)
+		return updated
+	}
+
+	// Break starts
+	session := process()
+	assert.Equal(t, core.SessionStatusPaused, session.Status)
+
+	// Break is over
+	ended := time.Now().Add(-time.Minute)
+	session.BreakEndsAt = &ended
+	require.NoError(t, storage.UpdateSession(ctx, session))
+	session = process()
+	assert.Equal(t, core.SessionStatusActive, session.Status)
+
+	// Planned end reached
+	session.ExpectedDuration = 30
+	require.NoError(t, storage.UpdateSession(ctx, session))
+	session = process()
+	assert.Equal(t, core.SessionStatusExpired, session.Status)
+
+	var events []core.SessionEvent
+	for _, transition := range transitions {
+		events = append(events, transition.Event)
+	}
+	assert.Equal(t, []core.SessionEvent{core.SessionEventPause, core.SessionEventResume, core.SessionEventExpire}, events)
+
+	// A guard keeps the scheduler from touching the device
+	storage.addSession(&core.Session{
+		ID:               "session2",
+		DeviceType:       "tv",
+		DeviceID:         "tv1",
+		ChildIDs:         []string{"child1"},
+		StartTime:        time.Now().Add(-31 * time.Minute),
+		ExpectedDuration: 30,
+		Status:           core.SessionStatusActive,
+	})
+	errHold := errors.New("on hold")
+	states.AddGuard(func(session *core.Session, transition core.SessionTransition) error {
+		return errHold
+	})
+	driver.stopCalls = nil
+	session2, err := storage.GetSession(ctx, "session2")
+	require.NoError(t, err)
+	assert.ErrorIs(t, scheduler.processSession(ctx, session2), errHold)
+	assert.Empty(t, driver.stopCalls)
+}
+
+func TestScheduler_ProcessSession_BreakExempt(t *testing.T) {
+	storage := newMockStorage(t)
+	driver := newMockDriver()
+	deviceRegistry := newMockDeviceRegistry()
+	driverRegistry := &mockDriverRegistry{driver: driver}
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "aqara"})
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	scheduler := NewScheduler(storage, deviceRegistry, driverRegistry, nil, nil, time.Minute, nil, logger)
+
+	storage.addChild(&core.Child{
+		ID:           "child1",
+		Name:         "Alice",
+		WeekdayLimit: 60,
+		WeekendLimit: 120,
+		BreakRule: &core.BreakRule{
+			BreakAfterMinutes:    30,
+			BreakDurationMinutes: 10,
+		},
+	})
+
+	// Parent-approved session past the break threshold
+	session := &core.Session{
+		ID:               "session1",
+		DeviceType:       "tv",
+		DeviceID:         "tv1",
+		ChildIDs:         []string{"child1"},
+		StartTime:        time.Now().Add(-31 * time.Minute),
+		ExpectedDuration: 90,
+		Status:           core.SessionStatusActive,
+		BreakExempt:      true,
+	}
+	storage.addSession(session)
+
+	err := scheduler.processSession(context.Background(), session)
+	require.NoError(t, err)
+
+	// No break was enforced
+	updated, _ := storage.GetSession(context.Background(), "session1")
+	assert.Equal(t, core.SessionStatusActive, updated.Status)
+	assert.Nil(t, updated.BreakEndsAt)
+	assert.Empty(t, driver.warnCalls)
+}
+
 func TestScheduler_ProcessSession_InBreak(t *testing.T) {
-	storage := newMockStorage()
+	storage := newMockStorage(t)
 	driver := newMockDriver()
 	deviceRegistry := newMockDeviceRegistry()
 	driverRegistry := &mockDriverRegistry{driver: driver}
@@ -378,7 +835,7 @@ func TestScheduler_ProcessSession_InBreak(t *testing.T) {
 	deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "aqara"})
 
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	scheduler := NewScheduler(storage, deviceRegistry, driverRegistry, nil, time.Minute, nil, logger)
+	scheduler := NewScheduler(storage, deviceRegistry, driverRegistry, nil, nil, time.Minute, nil, logger)
 
 	// Create child
 	child := &core.Child{
@@ -418,7 +875,7 @@ func TestScheduler_ProcessSession_InBreak(t *testing.T) {
 }
 
 func TestScheduler_ProcessSession_BreakEnded(t *testing.T) {
-	storage := newMockStorage()
+	storage := newMockStorage(t)
 	driver := newMockDriver()
 	deviceRegistry := newMockDeviceRegistry()
 	driverRegistry := &mockDriverRegistry{driver: driver}
@@ -427,7 +884,7 @@ func TestScheduler_ProcessSession_BreakEnded(t *testing.T) {
 	deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "aqara"})
 
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	scheduler := NewScheduler(storage, deviceRegistry, driverRegistry, nil, time.Minute, nil, logger)
+	scheduler := NewScheduler(storage, deviceRegistry, driverRegistry, nil, nil, time.Minute, nil, logger)
 
 	// Create child
 	child := &core.Child{
@@ -439,7 +896,8 @@ func TestScheduler_ProcessSession_BreakEnded(t *testing.T) {
 	storage.addChild(child)
 
 	// Create session with break that has ended
-	breakEnds := time.Now().Add(-1 * time.Minute)
+	breakStarted := time.Now().Add(-6 * time.Minute)
+	breakEnds := breakStarted.Add(5 * time.Minute)
 	session := &core.Session{
 		ID:               "session1",
 		DeviceType:       "tv",
@@ -448,6 +906,7 @@ func TestScheduler_ProcessSession_BreakEnded(t *testing.T) {
 		StartTime:        time.Now().Add(-20 * time.Minute),
 		ExpectedDuration: 60,
 		Status:           core.SessionStatusPaused,
+		LastBreakAt:      &breakStarted,
 		BreakEndsAt:      &breakEnds,
 	}
 	storage.addSession(session)
@@ -456,13 +915,184 @@ func TestScheduler_ProcessSession_BreakEnded(t *testing.T) {
 	err := scheduler.processSession(context.Background(), session)
 	require.NoError(t, err)
 
-	// Verify break ended
+	// Verify break ended and its length was recorded for the refund
 	updated, _ := storage.GetSession(context.Background(), "session1")
 	assert.Nil(t, updated.BreakEndsAt)
+	assert.Equal(t, 5, updated.BreakMinutes)
+}
+
+// mockBreakDriver implements the optional core.BreakableDriver interface
+type mockBreakDriver struct {
+	*mockDriver
+	startBreakCalls []int
+	endBreakCalls   []string
+}
+
+func (d *mockBreakDriver) StartBreak(ctx context.Context, session *core.Session, breakMinutes int) error {
+	d.startBreakCalls = append(d.startBreakCalls, breakMinutes)
+	return nil
+}
+
+func (d *mockBreakDriver) EndBreak(ctx context.Context, session *core.Session) error {
+	d.endBreakCalls = append(d.endBreakCalls, session.ID)
+	return nil
+}
+
+type breakDriverRegistry struct {
+	driver *mockBreakDriver
+}
+
+func (m *breakDriverRegistry) Get(name string) (DeviceDriver, error) {
+	return m.driver, nil
+}
+
+func TestScheduler_ProcessSession_BreakActions(t *testing.T) {
+	newSession := func() *core.Session {
+		return &core.Session{
+			ID:               "session1",
+			DeviceType:       "tv",
+			DeviceID:         "tv1",
+			ChildIDs:         []string{"child1"},
+			StartTime:        time.Now().Add(-31 * time.Minute),
+			ExpectedDuration: 60,
+			Status:           core.SessionStatusActive,
+		}
+	}
+	newChild := func(action string) *core.Child {
+		return &core.Child{
+			ID:           "child1",
+			Name:         "Alice",
+			WeekdayLimit: 60,
+			WeekendLimit: 120,
+			BreakRule: &core.BreakRule{
+				BreakAfterMinutes:    30,
+				BreakDurationMinutes: 10,
+				Action:               action,
+			},
+		}
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	t.Run("lock stops the device and restarts it after the break", func(t *testing.T) {
+		storage := newMockStorage(t)
+		driver := newMockDriver()
+		deviceRegistry := newMockDeviceRegistry()
+		deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "aqara"})
+		scheduler := NewScheduler(storage, deviceRegistry, &mockDriverRegistry{driver: driver}, nil, nil, time.Minute, nil, logger)
+
+		storage.addChild(newChild(core.BreakActionLock))
+		session := newSession()
+		storage.addSession(session)
+
+		require.NoError(t, scheduler.processSession(context.Background(), session))
+
+		updated, _ := storage.GetSession(context.Background(), "session1")
+		assert.Equal(t, core.SessionStatusPaused, updated.Status)
+		assert.Equal(t, core.BreakActionLock, updated.BreakAction)
+		assert.Equal(t, []string{"session1"}, driver.stopCalls)
+		assert.Empty(t, driver.warnCalls)
+
+		// End the break
+		breakEnds := time.Now().Add(-time.Second)
+		updated.BreakEndsAt = &breakEnds
+		require.NoError(t, scheduler.processSession(context.Background(), updated))
+
+		resumed, _ := storage.GetSession(context.Background(), "session1")
+		assert.Equal(t, core.SessionStatusActive, resumed.Status)
+		assert.Empty(t, resumed.BreakAction)
+		assert.Equal(t, []string{"session1"}, driver.startCalls)
+	})
+
+	t.Run("break uses the driver's break action", func(t *testing.T) {
+		storage := newMockStorage(t)
+		driver := &mockBreakDriver{mockDriver: newMockDriver()}
+		deviceRegistry := newMockDeviceRegistry()
+		deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "aqara"})
+		scheduler := NewScheduler(storage, deviceRegistry, &breakDriverRegistry{driver: driver}, nil, nil, time.Minute, nil, logger)
+
+		storage.addChild(newChild(core.BreakActionBreak))
+		session := newSession()
+		storage.addSession(session)
+
+		require.NoError(t, scheduler.processSession(context.Background(), session))
+
+		updated, _ := storage.GetSession(context.Background(), "session1")
+		assert.Equal(t, core.BreakActionBreak, updated.BreakAction)
+		assert.Equal(t, []int{10}, driver.startBreakCalls)
+		assert.Empty(t, driver.warnCalls)
+
+		breakEnds := time.Now().Add(-time.Second)
+		updated.BreakEndsAt = &breakEnds
+		require.NoError(t, scheduler.processSession(context.Background(), updated))
+		assert.Equal(t, []string{"session1"}, driver.endBreakCalls)
+	})
+
+	t.Run("break falls back to warn without driver support", func(t *testing.T) {
+		storage := newMockStorage(t)
+		driver := newMockDriver()
+		deviceRegistry := newMockDeviceRegistry()
+		deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "aqara"})
+		scheduler := NewScheduler(storage, deviceRegistry, &mockDriverRegistry{driver: driver}, nil, nil, time.Minute, nil, logger)
+
+		storage.addChild(newChild(core.BreakActionBreak))
+		session := newSession()
+		storage.addSession(session)
+
+		require.NoError(t, scheduler.processSession(context.Background(), session))
+
+		updated, _ := storage.GetSession(context.Background(), "session1")
+		assert.Equal(t, core.BreakActionWarn, updated.BreakAction)
+		assert.Equal(t, []string{"session1"}, driver.warnCalls)
+	})
+
+	t.Run("device parameter overrides the child's action", func(t *testing.T) {
+		storage := newMockStorage(t)
+		driver := newMockDriver()
+		deviceRegistry := newMockDeviceRegistry()
+		deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "aqara", params: map[string]interface{}{"break_action": "lock"}})
+		scheduler := NewScheduler(storage, deviceRegistry, &mockDriverRegistry{driver: driver}, nil, nil, time.Minute, nil, logger)
+
+		storage.addChild(newChild(core.BreakActionWarn))
+		session := newSession()
+		storage.addSession(session)
+
+		require.NoError(t, scheduler.processSession(context.Background(), session))
+
+		updated, _ := storage.GetSession(context.Background(), "session1")
+		assert.Equal(t, core.BreakActionLock, updated.BreakAction)
+		assert.Equal(t, []string{"session1"}, driver.stopCalls)
+		assert.Empty(t, driver.warnCalls)
+	})
+
+	t.Run("idle timeout is not applied during a break", func(t *testing.T) {
+		storage := newMockStorage(t)
+		driver := newMockDriver()
+		deviceRegistry := newMockDeviceRegistry()
+		deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "winagent", params: map[string]interface{}{"idle_timeout_minutes": float64(1)}})
+		scheduler := NewScheduler(storage, deviceRegistry, &mockDriverRegistry{driver: driver}, nil, nil, time.Minute, nil, logger)
+
+		storage.addChild(newChild(core.BreakActionLock))
+		lastActivity := time.Now().Add(-5 * time.Minute)
+		breakStarted := time.Now().Add(-5 * time.Minute)
+		breakEnds := time.Now().Add(5 * time.Minute)
+		session := newSession()
+		session.Status = core.SessionStatusPaused
+		session.LastActivityAt = &lastActivity
+		session.LastBreakAt = &breakStarted
+		session.BreakEndsAt = &breakEnds
+		session.BreakAction = core.BreakActionLock
+		storage.addSession(session)
+
+		require.NoError(t, scheduler.processSession(context.Background(), session))
+
+		updated, _ := storage.GetSession(context.Background(), "session1")
+		assert.Equal(t, core.SessionStatusPaused, updated.Status)
+		assert.Empty(t, driver.stopCalls)
+	})
 }
 
 func TestScheduler_Tick(t *testing.T) {
-	storage := newMockStorage()
+	storage := newMockStorage(t)
 	driver := newMockDriver()
 	deviceRegistry := newMockDeviceRegistry()
 	driverRegistry := &mockDriverRegistry{driver: driver}
@@ -471,7 +1101,7 @@ func TestScheduler_Tick(t *testing.T) {
 	deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "aqara"})
 
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	scheduler := NewScheduler(storage, deviceRegistry, driverRegistry, nil, time.Minute, nil, logger)
+	scheduler := NewScheduler(storage, deviceRegistry, driverRegistry, nil, nil, time.Minute, nil, logger)
 
 	// Create child
 	child := &core.Child{
@@ -515,8 +1145,54 @@ func TestScheduler_Tick(t *testing.T) {
 	assert.Equal(t, core.SessionStatusActive, updated.Status)
 }
 
+func TestScheduler_Tick_WaitsForSessionLock(t *testing.T) {
+	storage := newMockStorage(t)
+	driver := newMockDriver()
+	deviceRegistry := newMockDeviceRegistry()
+	driverRegistry := &mockDriverRegistry{driver: driver}
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "aqara"})
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	scheduler := NewScheduler(storage, deviceRegistry, driverRegistry, nil, nil, time.Minute, nil, logger)
+	locks := core.NewSessionLocks()
+	scheduler.SetSessionLocks(locks)
+
+	storage.addChild(&core.Child{ID: "child1", Name: "Alice", WeekdayLimit: 120, WeekendLimit: 120})
+	storage.addSession(&core.Session{
+		ID:               "session1",
+		DeviceType:       "tv",
+		DeviceID:         "tv1",
+		ChildIDs:         []string{"child1"},
+		StartTime:        time.Now().Add(-31 * time.Minute),
+		ExpectedDuration: 30,
+		Status:           core.SessionStatusActive,
+	})
+
+	// An extension holds the lock while the tick sees the session as expired
+	unlock := locks.Lock("session1")
+	done := make(chan struct{})
+	go func() {
+		scheduler.tick()
+		close(done)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	session, err := storage.GetSession(context.Background(), "session1")
+	require.NoError(t, err)
+	session.ExpectedDuration = 60
+	require.NoError(t, storage.UpdateSession(context.Background(), session))
+	unlock()
+	<-done
+
+	// The tick re-read the extended session instead of stopping it
+	assert.Empty(t, driver.stopCalls)
+	updated, err := storage.GetSession(context.Background(), "session1")
+	require.NoError(t, err)
+	assert.Equal(t, core.SessionStatusActive, updated.Status)
+}
+
 func TestScheduler_StartStop(t *testing.T) {
-	storage := newMockStorage()
+	storage := newMockStorage(t)
 	driver := newMockDriver()
 	deviceRegistry := newMockDeviceRegistry()
 	driverRegistry := &mockDriverRegistry{driver: driver}
@@ -525,17 +1201,266 @@ func TestScheduler_StartStop(t *testing.T) {
 	deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "aqara"})
 
 	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
-	scheduler := NewScheduler(storage, deviceRegistry, driverRegistry, nil, 100*time.Millisecond, nil, logger)
+	scheduler := NewScheduler(storage, deviceRegistry, driverRegistry, nil, nil, 100*time.Millisecond, nil, logger)
 
 	// Start scheduler in goroutine
 	go scheduler.Start()
 
-	// Let it run for a bit
-	time.Sleep(250 * time.Millisecond)
+	// Let it tick
+	require.Eventually(t, func() bool { return !scheduler.LastTick().IsZero() }, time.Second, time.Millisecond)
 
 	// Stop scheduler
-	scheduler.Stop()
+	require.NoError(t, scheduler.Stop(context.Background()))
+
+	// Stopping twice is safe
+	require.NoError(t, scheduler.Stop(context.Background()))
+}
+
+// blockingStopDriver blocks in StopSession until release is closed, to simulate a slow device
+type blockingStopDriver struct {
+	*mockDriver
+	entered chan struct{} // Receives a value when StopSession is called
+	release chan struct{}
+}
+
+func newBlockingStopDriver() *blockingStopDriver {
+	return &blockingStopDriver{
+		mockDriver: newMockDriver(),
+		entered:    make(chan struct{}, 1),
+		release:    make(chan struct{}),
+	}
+}
+
+func (d *blockingStopDriver) StopSession(ctx context.Context, session *core.Session) error {
+	select {
+	case d.entered <- struct{}{}:
+	default:
+	}
+	<-d.release
+	return d.mockDriver.StopSession(ctx, session)
+}
 
-	// Wait a bit to ensure it stopped
-	time.Sleep(100 * time.Millisecond)
+type blockingDriverRegistry struct {
+	driver *blockingStopDriver
+}
+
+func (m *blockingDriverRegistry) Get(name string) (DeviceDriver, error) {
+	return m.driver, nil
+}
+
+func TestScheduler_StopWaitsForInFlightTick(t *testing.T) {
+	storage := newMockStorage(t)
+	driver := newBlockingStopDriver()
+	deviceRegistry := newMockDeviceRegistry()
+	driverRegistry := &blockingDriverRegistry{driver: driver}
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "aqara"})
+
+	storage.addChild(&core.Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 60})
+	storage.addSession(&core.Session{
+		ID:               "session1",
+		DeviceType:       "tv",
+		DeviceID:         "tv1",
+		ChildIDs:         []string{"child1"},
+		StartTime:        time.Now().Add(-31 * time.Minute),
+		ExpectedDuration: 30,
+		Status:           core.SessionStatusActive,
+	})
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	scheduler := NewScheduler(storage, deviceRegistry, driverRegistry, nil, nil, 20*time.Millisecond, nil, logger)
+
+	go scheduler.Start()
+
+	// Wait for the first tick to block in the driver
+	<-driver.entered
+
+	stopped := make(chan error, 1)
+	go func() { stopped <- scheduler.Stop(context.Background()) }()
+
+	select {
+	case err := <-stopped:
+		t.Fatalf("Stop returned while a tick was in flight: %v", err)
+	default:
+	}
+
+	// Stop returns once the tick finishes
+	close(driver.release)
+	require.NoError(t, <-stopped)
+
+	// The in-flight stop call completed and the session was marked expired
+	assert.Contains(t, driver.stopCalls, "session1")
+	updated, _ := storage.GetSession(context.Background(), "session1")
+	assert.Equal(t, core.SessionStatusExpired, updated.Status)
+}
+
+func TestScheduler_StopTimeout(t *testing.T) {
+	storage := newMockStorage(t)
+	driver := newBlockingStopDriver()
+	deviceRegistry := newMockDeviceRegistry()
+	driverRegistry := &blockingDriverRegistry{driver: driver}
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "aqara"})
+
+	storage.addChild(&core.Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 60})
+	storage.addSession(&core.Session{
+		ID:               "session1",
+		DeviceType:       "tv",
+		DeviceID:         "tv1",
+		ChildIDs:         []string{"child1"},
+		StartTime:        time.Now().Add(-31 * time.Minute),
+		ExpectedDuration: 30,
+		Status:           core.SessionStatusActive,
+	})
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	scheduler := NewScheduler(storage, deviceRegistry, driverRegistry, nil, nil, 20*time.Millisecond, nil, logger)
+
+	go scheduler.Start()
+	<-driver.entered
+
+	// Finish the abandoned tick and wait for it, so the loop does not outlive the test
+	t.Cleanup(func() {
+		close(driver.release)
+		assert.NoError(t, scheduler.Stop(context.Background()))
+	})
+
+	// The tick is blocked, so Stop gives up when its context is done
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, scheduler.Stop(ctx), context.Canceled)
+}
+
+func TestScheduler_CheckHealth(t *testing.T) {
+	storage := newMockStorage(t)
+	driverRegistry := &mockDriverRegistry{driver: newMockDriver()}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	scheduler := NewScheduler(storage, newMockDeviceRegistry(), driverRegistry, nil, nil, 50*time.Millisecond, nil, logger)
+
+	// Not started yet
+	assert.ErrorIs(t, scheduler.CheckHealth(context.Background()), ErrSchedulerNotRunning)
+	assert.True(t, scheduler.LastTick().IsZero())
+
+	go scheduler.Start()
+	require.Eventually(t, func() bool { return !scheduler.LastTick().IsZero() }, time.Second, time.Millisecond)
+
+	// Running and ticking
+	require.NoError(t, scheduler.CheckHealth(context.Background()))
+	assert.False(t, scheduler.LastTick().IsZero())
+
+	require.NoError(t, scheduler.Stop(context.Background()))
+
+	// Stopped
+	assert.ErrorIs(t, scheduler.CheckHealth(context.Background()), ErrSchedulerNotRunning)
+}
+
+// setClock fixes core.Now for the rest of the test
+// The clock is global, so tests that start the scheduler loop must wait for it to exit (see
+// TestScheduler_StopTimeout) and clock tests must not run in parallel.
+func setClock(t *testing.T, now time.Time) {
+	t.Helper()
+	original := core.Now
+	core.Now = func() time.Time { return now }
+	t.Cleanup(func() { core.Now = original })
+}
+
+func TestScheduler_ProcessSession_DowntimeOverride(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.Local)
+	setClock(t, now)
+
+	storage := newMockStorage(t)
+	driver := newMockDriver()
+	deviceRegistry := newMockDeviceRegistry()
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "aqara"})
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	day := &core.DaySchedule{StartHour: 8, EndHour: 17}
+	downtime := core.NewDowntimeService(&core.DowntimeSchedule{Weekday: day, Weekend: day}, time.Local)
+	scheduler := NewScheduler(storage, deviceRegistry, &mockDriverRegistry{driver: driver}, nil, downtime, time.Minute, time.Local, logger)
+	overrides := core.NewDowntimeOverrideService(storage, time.Local, logger)
+	scheduler.SetDowntimeOverrides(overrides)
+
+	storage.addChild(&core.Child{ID: "child1", Name: "Alice", WeekdayLimit: 120, WeekendLimit: 120, DowntimeEnabled: true})
+	session := &core.Session{
+		ID:               "session1",
+		DeviceType:       "tv",
+		DeviceID:         "tv1",
+		ChildIDs:         []string{"child1"},
+		StartTime:        now.Add(-10 * time.Minute),
+		ExpectedDuration: 60,
+		OverrideDowntime: true,
+		Status:           core.SessionStatusActive,
+	}
+	storage.addSession(session)
+	override, err := overrides.GrantForSession(ctx, session, "child1", "api", "")
+	require.NoError(t, err)
+
+	require.NoError(t, scheduler.processSession(ctx, session))
+	assert.Empty(t, driver.stopCalls, "overridden session keeps running during downtime")
+
+	// Cancelling the override re-arms downtime on the next tick, though the session still has the flag
+	_, err = overrides.Cancel(ctx, override.ID, "api")
+	require.NoError(t, err)
+
+	require.NoError(t, scheduler.processSession(ctx, session))
+	assert.Contains(t, driver.stopCalls, "session1")
+	updated, err := storage.GetSession(ctx, "session1")
+	require.NoError(t, err)
+	assert.Equal(t, core.SessionEndDowntime, updated.EndReason)
+}
+
+func TestScheduler_TickDue_DeviceTicks(t *testing.T) {
+	storage := newMockStorage(t)
+	driver := newMockDriver()
+	deviceRegistry := newMockDeviceRegistry()
+	deviceRegistry.addDevice(&mockDevice{id: "tv1", driver: "aqara"})
+	deviceRegistry.addDevice(&mockDevice{id: "pc1", driver: "aqara"})
+
+	scheduler := NewScheduler(storage, deviceRegistry, &mockDriverRegistry{driver: driver}, nil, nil, time.Minute, nil, nil)
+	scheduler.SetDeviceTicks(map[string]time.Duration{"pc1": 15 * time.Second, "tv1": 2 * time.Minute})
+	assert.Equal(t, 15*time.Second, scheduler.step(), "device ticks slower than the interval are ignored")
+
+	storage.addChild(&core.Child{ID: "child1", Name: "Alice", WeekdayLimit: 120, WeekendLimit: 120})
+	for _, deviceID := range []string{"tv1", "pc1"} {
+		storage.addSession(&core.Session{
+			ID:               "session-" + deviceID,
+			DeviceType:       "tv",
+			DeviceID:         deviceID,
+			ChildIDs:         []string{"child1"},
+			StartTime:        time.Now().Add(-31 * time.Minute),
+			ExpectedDuration: 30,
+			Status:           core.SessionStatusActive,
+		})
+	}
+
+	start := time.Now()
+	scheduler.lastFullTick = start
+
+	// Between full ticks only the PC's sessions are processed
+	scheduler.tickDue(start.Add(15 * time.Second))
+	assert.Equal(t, []string{"session-pc1"}, driver.stopCalls)
+
+	// A full tick processes every session
+	scheduler.tickDue(start.Add(time.Minute))
+	assert.ElementsMatch(t, []string{"session-pc1", "session-tv1"}, driver.stopCalls)
+}
+
+func TestScheduler_NextWait(t *testing.T) {
+	scheduler := NewScheduler(nil, nil, nil, nil, nil, time.Minute, nil, nil)
+	assert.Equal(t, time.Minute, scheduler.nextWait())
+
+	scheduler.SetJitter(10 * time.Second)
+	for range 100 {
+		wait := scheduler.nextWait()
+		assert.GreaterOrEqual(t, wait, time.Minute)
+		assert.Less(t, wait, time.Minute+10*time.Second)
+	}
+
+	// The jitter is capped at half the fastest device tick
+	scheduler.SetDeviceTicks(map[string]time.Duration{"pc1": 15 * time.Second})
+	for range 100 {
+		wait := scheduler.nextWait()
+		assert.GreaterOrEqual(t, wait, 15*time.Second)
+		assert.Less(t, wait, 15*time.Second+7500*time.Millisecond)
+	}
 }
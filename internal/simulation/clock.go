@@ -0,0 +1,23 @@
+package simulation
+
+import "time"
+
+// Clock is a fake clock that only moves when advanced
+type Clock struct {
+	now time.Time
+}
+
+// NewClock creates a clock stopped at start
+func NewClock(start time.Time) *Clock {
+	return &Clock{now: start}
+}
+
+// Now returns the simulated time
+func (c *Clock) Now() time.Time {
+	return c.now
+}
+
+// Advance moves the clock forward by d
+func (c *Clock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
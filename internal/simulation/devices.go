@@ -0,0 +1,179 @@
+package simulation
+
+import (
+	"context"
+	"fmt"
+	"metron/internal/core"
+	"metron/internal/scheduler"
+	"time"
+)
+
+// DriverName is the driver every simulated device uses
+const DriverName = "simulated"
+
+// Driver call names recorded by the simulated driver
+const (
+	CallStart      = "start"
+	CallStop       = "stop"
+	CallWarning    = "warning"
+	CallExtend     = "extend"
+	CallBreakStart = "break_start"
+	CallBreakEnd   = "break_end"
+)
+
+// Event is a driver call recorded at simulated time
+type Event struct {
+	At        time.Time
+	DeviceID  string
+	SessionID string
+	Call      string
+	Minutes   int // minutes remaining (warning), added (extend) or break length (break_start)
+}
+
+// driver records every call instead of controlling a device
+// It implements the optional extend and break interfaces, like the most capable real drivers
+type driver struct {
+	clock  *Clock
+	events []Event
+}
+
+func (d *driver) record(session *core.Session, call string, minutes int) {
+	d.events = append(d.events, Event{
+		At:        d.clock.Now(),
+		DeviceID:  session.DeviceID,
+		SessionID: session.ID,
+		Call:      call,
+		Minutes:   minutes,
+	})
+}
+
+// calls returns the call names recorded for a device, in order
+func (d *driver) calls(deviceID string) []string {
+	calls := []string{}
+	for _, event := range d.events {
+		if event.DeviceID == deviceID {
+			calls = append(calls, event.Call)
+		}
+	}
+	return calls
+}
+
+func (d *driver) Name() string {
+	return DriverName
+}
+
+func (d *driver) StartSession(ctx context.Context, session *core.Session) error {
+	d.record(session, CallStart, session.ExpectedDuration)
+	return nil
+}
+
+func (d *driver) StopSession(ctx context.Context, session *core.Session) error {
+	d.record(session, CallStop, 0)
+	return nil
+}
+
+func (d *driver) ApplyWarning(ctx context.Context, session *core.Session, minutesRemaining int) error {
+	d.record(session, CallWarning, minutesRemaining)
+	return nil
+}
+
+func (d *driver) ExtendSession(ctx context.Context, session *core.Session, additionalMinutes int) error {
+	d.record(session, CallExtend, additionalMinutes)
+	return nil
+}
+
+func (d *driver) StartBreak(ctx context.Context, session *core.Session, breakMinutes int) error {
+	d.record(session, CallBreakStart, breakMinutes)
+	return nil
+}
+
+func (d *driver) EndBreak(ctx context.Context, session *core.Session) error {
+	d.record(session, CallBreakEnd, 0)
+	return nil
+}
+
+// device is a simulated device from the scenario
+type device struct {
+	Device
+}
+
+func (d *device) GetType() string {
+	return d.Type
+}
+
+func (d *device) GetDriver() string {
+	return DriverName
+}
+
+func (d *device) GetTimezone() string {
+	return d.Timezone
+}
+
+func (d *device) GetParameter(key string) interface{} {
+	return d.Parameters[key]
+}
+
+// registry serves the simulated devices and driver to the manager and the scheduler
+type registry struct {
+	devices map[string]*device
+	driver  *driver
+}
+
+func (r *registry) device(id string) (*device, error) {
+	d, ok := r.devices[id]
+	if !ok {
+		return nil, fmt.Errorf("device not found: %s", id)
+	}
+	return d, nil
+}
+
+func (r *registry) driverFor(name string) (*driver, error) {
+	if name != DriverName {
+		return nil, fmt.Errorf("driver not found: %s", name)
+	}
+	return r.driver, nil
+}
+
+// coreDevices adapts the registry to core.DeviceRegistry
+type coreDevices struct{ *registry }
+
+func (r coreDevices) Get(id string) (core.Device, error) {
+	v, err := r.device(id)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// coreDrivers adapts the registry to core.DriverRegistry
+type coreDrivers struct{ *registry }
+
+func (r coreDrivers) Get(name string) (core.DeviceDriver, error) {
+	v, err := r.driverFor(name)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// schedulerDevices adapts the registry to scheduler.DeviceRegistry
+type schedulerDevices struct{ *registry }
+
+func (r schedulerDevices) Get(id string) (scheduler.Device, error) {
+	v, err := r.device(id)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// schedulerDrivers adapts the registry to scheduler.DriverRegistry
+type schedulerDrivers struct{ *registry }
+
+func (r schedulerDrivers) Get(name string) (scheduler.DeviceDriver, error) {
+	v, err := r.driverFor(name)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
@@ -0,0 +1,229 @@
+// Package simulation replays scenario files against the real session manager, scheduler and
+// time calculator, using a fake clock and recording drivers, and checks the expected outcomes.
+// It protects the limit, break and downtime logic from regressions without real devices or waiting.
+package simulation
+
+import (
+	"encoding/json"
+	"fmt"
+	"metron/config"
+	"metron/internal/core"
+	"os"
+	"time"
+)
+
+// Scenario describes the setup and the steps of a simulation
+type Scenario struct {
+	Name        string                 `json:"name"`
+	Start       time.Time              `json:"start"`                  // Fake clock start (RFC3339)
+	Timezone    string                 `json:"timezone,omitempty"`     // Server timezone (default UTC)
+	TickMinutes int                    `json:"tick_minutes,omitempty"` // Scheduler interval (default 1)
+	Downtime    *config.DowntimeConfig `json:"downtime,omitempty"`     // Same format as the configuration file
+	Devices     []Device               `json:"devices"`
+	Children    []Child                `json:"children"`
+	Steps       []Step                 `json:"steps"`
+}
+
+// Device is a simulated device; every device uses the recording driver
+type Device struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	Timezone   string                 `json:"timezone,omitempty"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// Child is a child created before the first step
+type Child struct {
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	WeekdayLimit    int        `json:"weekday_limit"`
+	WeekendLimit    int        `json:"weekend_limit"`
+	BreakRule       *BreakRule `json:"break_rule,omitempty"`
+	DowntimeEnabled bool       `json:"downtime_enabled,omitempty"`
+	Timezone        string     `json:"timezone,omitempty"`
+	AllowedDevices  []string   `json:"allowed_devices,omitempty"`
+	GraceMinutes    int        `json:"grace_minutes,omitempty"`
+}
+
+// BreakRule uses the same fields as the API's break_rule
+type BreakRule struct {
+	BreakAfterMinutes    int    `json:"break_after_minutes"`
+	BreakDurationMinutes int    `json:"break_duration_minutes"`
+	Action               string `json:"action,omitempty"`
+}
+
+// Step is a single scenario step; exactly one field must be set
+type Step struct {
+	Advance       string         `json:"advance,omitempty"` // Go duration, e.g. "31m"; the scheduler ticks along the way
+	StartSession  *StartSession  `json:"start_session,omitempty"`
+	ExtendSession *ExtendSession `json:"extend_session,omitempty"`
+	StopSession   *StopSession   `json:"stop_session,omitempty"`
+	GrantReward   *GrantReward   `json:"grant_reward,omitempty"`
+	Expect        *Expectation   `json:"expect,omitempty"`
+}
+
+// StartSession starts a session and names it for later steps
+type StartSession struct {
+	Session     string   `json:"session"` // Name used by later steps (session IDs are generated)
+	DeviceID    string   `json:"device_id"`
+	ChildIDs    []string `json:"child_ids"`
+	Minutes     int      `json:"minutes"`
+	Parent      bool     `json:"parent,omitempty"`       // Start as a parent (overrides downtime, like the bot)
+	BreakExempt bool     `json:"break_exempt,omitempty"` // Parent-approved session without breaks
+	ExpectError string   `json:"expect_error,omitempty"` // API error code the start must fail with
+}
+
+// ExtendSession extends a named session
+type ExtendSession struct {
+	Session     string `json:"session"`
+	Minutes     int    `json:"minutes"`
+	ExpectError string `json:"expect_error,omitempty"`
+}
+
+// StopSession stops a named session
+type StopSession struct {
+	Session     string `json:"session"`
+	ExpectError string `json:"expect_error,omitempty"`
+}
+
+// GrantReward grants reward minutes for today
+type GrantReward struct {
+	ChildID string `json:"child_id"`
+	Minutes int    `json:"minutes"`
+}
+
+// Expectation checks the state at the current simulated time; only set fields are checked
+type Expectation struct {
+	Session          string   `json:"session,omitempty"`
+	Status           string   `json:"status,omitempty"` // Session status (active, paused, completed, expired)
+	ChildID          string   `json:"child_id,omitempty"`
+	UsedMinutes      *int     `json:"used_minutes,omitempty"`      // Child's consumed minutes today
+	RemainingMinutes *int     `json:"remaining_minutes,omitempty"` // Child's remaining minutes today
+	DeviceID         string   `json:"device_id,omitempty"`
+	DriverCalls      []string `json:"driver_calls,omitempty"` // All driver calls for the device so far, in order
+}
+
+// LoadScenario reads and validates a scenario file
+func LoadScenario(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario: %w", err)
+	}
+
+	var scenario Scenario
+	if err := json.Unmarshal(data, &scenario); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario: %w", err)
+	}
+	if scenario.Name == "" {
+		scenario.Name = path
+	}
+
+	if err := scenario.Validate(); err != nil {
+		return nil, err
+	}
+	return &scenario, nil
+}
+
+// Validate checks the scenario structure; rule values are validated by the core when applied
+func (s *Scenario) Validate() error {
+	if s.Start.IsZero() {
+		return fmt.Errorf("start is required")
+	}
+	if s.TickMinutes < 0 {
+		return fmt.Errorf("tick_minutes must not be negative")
+	}
+	if _, err := time.LoadLocation(s.timezone()); err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", s.Timezone, err)
+	}
+
+	for i, step := range s.Steps {
+		set := 0
+		if step.Advance != "" {
+			set++
+			if d, err := time.ParseDuration(step.Advance); err != nil || d <= 0 {
+				return fmt.Errorf("step %d: advance must be a positive duration, got %q", i+1, step.Advance)
+			}
+		}
+		if step.StartSession != nil {
+			set++
+			if step.StartSession.Session == "" {
+				return fmt.Errorf("step %d: start_session needs a session name", i+1)
+			}
+		}
+		if step.ExtendSession != nil {
+			set++
+		}
+		if step.StopSession != nil {
+			set++
+		}
+		if step.GrantReward != nil {
+			set++
+		}
+		if step.Expect != nil {
+			set++
+		}
+		if set != 1 {
+			return fmt.Errorf("step %d: exactly one action must be set, got %d", i+1, set)
+		}
+	}
+	return nil
+}
+
+func (s *Scenario) timezone() string {
+	if s.Timezone == "" {
+		return "UTC"
+	}
+	return s.Timezone
+}
+
+func (s *Scenario) tickInterval() time.Duration {
+	if s.TickMinutes == 0 {
+		return time.Minute
+	}
+	return time.Duration(s.TickMinutes) * time.Minute
+}
+
+// downtimeSchedule converts the configuration format to the core schedule
+func downtimeSchedule(cfg *config.DowntimeConfig) (*core.DowntimeSchedule, error) {
+	var parseErr error
+	parse := func(name string, day *config.DayScheduleConfig) *core.DaySchedule {
+		if day == nil || parseErr != nil {
+			return nil
+		}
+		start, err := time.Parse("15:04", day.StartTime)
+		if err != nil {
+			parseErr = fmt.Errorf("downtime %s: invalid start_time %q", name, day.StartTime)
+			return nil
+		}
+		end, err := time.Parse("15:04", day.EndTime)
+		if err != nil {
+			parseErr = fmt.Errorf("downtime %s: invalid end_time %q", name, day.EndTime)
+			return nil
+		}
+		return &core.DaySchedule{
+			StartHour:   start.Hour(),
+			StartMinute: start.Minute(),
+			EndHour:     end.Hour(),
+			EndMinute:   end.Minute(),
+		}
+	}
+
+	weekday, weekend := cfg.Weekday, cfg.Weekend
+	if cfg.IsLegacyFormat() {
+		legacy := &config.DayScheduleConfig{StartTime: cfg.StartTime, EndTime: cfg.EndTime}
+		weekday, weekend = legacy, legacy
+	}
+
+	schedule := &core.DowntimeSchedule{
+		Weekday:   parse("weekday", weekday),
+		Weekend:   parse("weekend", weekend),
+		Sunday:    parse("sunday", cfg.Sunday),
+		Monday:    parse("monday", cfg.Monday),
+		Tuesday:   parse("tuesday", cfg.Tuesday),
+		Wednesday: parse("wednesday", cfg.Wednesday),
+		Thursday:  parse("thursday", cfg.Thursday),
+		Friday:    parse("friday", cfg.Friday),
+		Saturday:  parse("saturday", cfg.Saturday),
+	}
+	return schedule, parseErr
+}
@@ -0,0 +1,296 @@
+package simulation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"metron/internal/api/apierror"
+	"metron/internal/core"
+	"metron/internal/scheduler"
+	"metron/internal/storage/memory"
+	"reflect"
+	"time"
+)
+
+// Failure is a step whose outcome did not match the scenario
+type Failure struct {
+	Step    int // 1-based step number
+	At      time.Time
+	Message string
+}
+
+// Result is the outcome of a simulation run
+type Result struct {
+	Scenario string
+	Steps    int
+	End      time.Time // Simulated time after the last step
+	Failures []Failure
+	Events   []Event // Driver calls, in order
+}
+
+// Passed reports whether every step matched the scenario
+func (r *Result) Passed() bool {
+	return len(r.Failures) == 0
+}
+
+// Run replays the scenario against a fresh in-memory storage
+// The fake clock replaces core.Now for the duration of the run, so runs must not overlap
+// with each other or with a live server in the same process
+func Run(ctx context.Context, scenario *Scenario, logger *slog.Logger) (*Result, error) {
+	if err := scenario.Validate(); err != nil {
+		return nil, err
+	}
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	timezone, err := time.LoadLocation(scenario.timezone())
+	if err != nil {
+		return nil, err
+	}
+
+	db := memory.New(timezone)
+	defer db.Close()
+
+	clock := NewClock(scenario.Start)
+	previousNow := core.Now
+	core.Now = clock.Now
+	defer func() { core.Now = previousNow }()
+
+	sim, err := newSimulation(ctx, scenario, db, clock, timezone, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Scenario: scenario.Name,
+		Steps:    len(scenario.Steps),
+	}
+	for i, step := range scenario.Steps {
+		if msg := sim.runStep(ctx, step); msg != "" {
+			result.Failures = append(result.Failures, Failure{
+				Step:    i + 1,
+				At:      clock.Now(),
+				Message: msg,
+			})
+		}
+	}
+
+	result.End = clock.Now()
+	result.Events = sim.registry.driver.events
+	return result, nil
+}
+
+// simulation is the wiring of one run, mirroring cmd/metron
+type simulation struct {
+	clock      *Clock
+	interval   time.Duration
+	storage    *memory.Storage
+	registry   *registry
+	calculator *core.TimeCalculationService
+	manager    *core.SessionManager
+	scheduler  *scheduler.Scheduler
+	sessions   map[string]string // scenario session name -> session ID
+}
+
+func newSimulation(ctx context.Context, scenario *Scenario, db *memory.Storage, clock *Clock, timezone *time.Location, logger *slog.Logger) (*simulation, error) {
+	reg := &registry{
+		devices: make(map[string]*device),
+		driver:  &driver{clock: clock},
+	}
+	for _, d := range scenario.Devices {
+		if d.ID == "" || d.Type == "" {
+			return nil, fmt.Errorf("device needs an id and a type")
+		}
+		if err := core.ValidateTimezone(d.Timezone); err != nil {
+			return nil, fmt.Errorf("device %s: %w", d.ID, err)
+		}
+		reg.devices[d.ID] = &device{Device: d}
+	}
+
+	for _, c := range scenario.Children {
+		child := &core.Child{
+			ID:              c.ID,
+			Name:            c.Name,
+			WeekdayLimit:    c.WeekdayLimit,
+			WeekendLimit:    c.WeekendLimit,
+			DowntimeEnabled: c.DowntimeEnabled,
+			Timezone:        c.Timezone,
+			AllowedDevices:  c.AllowedDevices,
+			GraceMinutes:    c.GraceMinutes,
+		}
+		if c.BreakRule != nil {
+			child.BreakRule = &core.BreakRule{
+				BreakAfterMinutes:    c.BreakRule.BreakAfterMinutes,
+				BreakDurationMinutes: c.BreakRule.BreakDurationMinutes,
+				Action:               c.BreakRule.Action,
+			}
+		}
+		if err := db.CreateChild(ctx, child); err != nil {
+			return nil, fmt.Errorf("child %s: %w", c.ID, err)
+		}
+	}
+
+	var schedule *core.DowntimeSchedule
+	if scenario.Downtime != nil {
+		var err error
+		if schedule, err = downtimeSchedule(scenario.Downtime); err != nil {
+			return nil, err
+		}
+	}
+	downtime := core.NewDowntimeService(schedule, timezone)
+	downtime.SetSkipStorage(db)
+
+	calculator := core.NewTimeCalculationService(db, timezone)
+	manager := core.NewSessionManager(db, coreDevices{reg}, coreDrivers{reg}, calculator, downtime, timezone, logger.With("component", "manager"))
+	sched := scheduler.NewScheduler(db, schedulerDevices{reg}, schedulerDrivers{reg}, calculator, downtime, scenario.tickInterval(), timezone, logger.With("component", "scheduler"))
+	sched.SetSessionLocks(manager.SessionLocks())
+	sched.SetSessionStates(manager.SessionStates())
+
+	return &simulation{
+		clock:      clock,
+		interval:   scenario.tickInterval(),
+		storage:    db,
+		registry:   reg,
+		calculator: calculator,
+		manager:    manager,
+		scheduler:  sched,
+		sessions:   make(map[string]string),
+	}, nil
+}
+
+// runStep runs one step and returns a failure message, or "" if it matched the scenario
+func (s *simulation) runStep(ctx context.Context, step Step) string {
+	switch {
+	case step.Advance != "":
+		d, _ := time.ParseDuration(step.Advance) // checked by Validate
+		s.advance(d)
+		return ""
+
+	case step.StartSession != nil:
+		start := step.StartSession
+		startCtx := core.WithInitiator(ctx, core.Initiator{Type: core.InitiatorAPI, ID: "simulation"})
+		if start.Parent {
+			startCtx = core.WithOverride(startCtx, core.Override{Downtime: true, By: "parent"})
+			startCtx = core.WithInitiator(startCtx, core.Initiator{Type: core.InitiatorParent, ID: "simulation"})
+		}
+		if start.BreakExempt {
+			startCtx = core.WithBreakExempt(startCtx)
+		}
+		session, err := s.manager.StartSession(startCtx, start.DeviceID, start.ChildIDs, start.Minutes)
+		if err == nil {
+			s.sessions[start.Session] = session.ID
+		}
+		return checkError("start_session "+start.Session, err, start.ExpectError)
+
+	case step.ExtendSession != nil:
+		extend := step.ExtendSession
+		id, msg := s.sessionID(extend.Session)
+		if msg != "" {
+			return msg
+		}
+		_, err := s.manager.ExtendSession(ctx, id, extend.Minutes)
+		return checkError("extend_session "+extend.Session, err, extend.ExpectError)
+
+	case step.StopSession != nil:
+		stop := step.StopSession
+		id, msg := s.sessionID(stop.Session)
+		if msg != "" {
+			return msg
+		}
+		return checkError("stop_session "+stop.Session, s.manager.StopSession(ctx, id), stop.ExpectError)
+
+	case step.GrantReward != nil:
+		err := s.manager.GrantRewardMinutes(ctx, step.GrantReward.ChildID, step.GrantReward.Minutes)
+		return checkError("grant_reward "+step.GrantReward.ChildID, err, "")
+
+	case step.Expect != nil:
+		return s.check(ctx, step.Expect)
+	}
+	return ""
+}
+
+// advance moves the clock forward, running the scheduler on every tick along the way
+func (s *simulation) advance(d time.Duration) {
+	for d >= s.interval {
+		s.clock.Advance(s.interval)
+		s.scheduler.Tick()
+		d -= s.interval
+	}
+	s.clock.Advance(d)
+}
+
+func (s *simulation) sessionID(name string) (string, string) {
+	id, ok := s.sessions[name]
+	if !ok {
+		return "", fmt.Sprintf("unknown session %q (not started)", name)
+	}
+	return id, ""
+}
+
+// checkError compares an action's error with the expected API error code ("" = success)
+func checkError(action string, err error, expected string) string {
+	if err == nil {
+		if expected != "" {
+			return fmt.Sprintf("%s: expected error %s, got success", action, expected)
+		}
+		return ""
+	}
+
+	code, ok := apierror.FromError(err)
+	if !ok {
+		code = apierror.InternalError
+	}
+	if expected == "" {
+		return fmt.Sprintf("%s: unexpected error %s: %v", action, code, err)
+	}
+	if string(code) != expected {
+		return fmt.Sprintf("%s: expected error %s, got %s: %v", action, expected, code, err)
+	}
+	return ""
+}
+
+// check compares the current state with an expectation; all mismatches are reported together
+func (s *simulation) check(ctx context.Context, expect *Expectation) string {
+	var mismatches []error
+
+	if expect.Session != "" {
+		id, msg := s.sessionID(expect.Session)
+		if msg != "" {
+			return msg
+		}
+		session, err := s.storage.GetSession(ctx, id)
+		if err != nil {
+			return fmt.Sprintf("session %s: %v", expect.Session, err)
+		}
+		if expect.Status != "" && string(session.Status) != expect.Status {
+			mismatches = append(mismatches, fmt.Errorf("session %s: status %s, expected %s", expect.Session, session.Status, expect.Status))
+		}
+	}
+
+	if expect.ChildID != "" && (expect.UsedMinutes != nil || expect.RemainingMinutes != nil) {
+		remaining, err := s.calculator.GetRemainingTime(ctx, expect.ChildID, s.clock.Now())
+		if err != nil {
+			return fmt.Sprintf("child %s: %v", expect.ChildID, err)
+		}
+		if expect.UsedMinutes != nil && remaining.Consumed.TotalConsumed != *expect.UsedMinutes {
+			mismatches = append(mismatches, fmt.Errorf("child %s: used %d minutes, expected %d", expect.ChildID, remaining.Consumed.TotalConsumed, *expect.UsedMinutes))
+		}
+		if expect.RemainingMinutes != nil && remaining.RemainingTotal != *expect.RemainingMinutes {
+			mismatches = append(mismatches, fmt.Errorf("child %s: %d minutes remaining, expected %d", expect.ChildID, remaining.RemainingTotal, *expect.RemainingMinutes))
+		}
+	}
+
+	if expect.DeviceID != "" && expect.DriverCalls != nil {
+		if calls := s.registry.driver.calls(expect.DeviceID); !reflect.DeepEqual(calls, expect.DriverCalls) {
+			mismatches = append(mismatches, fmt.Errorf("device %s: driver calls %v, expected %v", expect.DeviceID, calls, expect.DriverCalls))
+		}
+	}
+
+	if err := errors.Join(mismatches...); err != nil {
+		return err.Error()
+	}
+	return ""
+}
@@ -0,0 +1,115 @@
+package simulation
+
+import (
+	"context"
+	"metron/internal/core"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun_Scenarios(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("testdata", "*.json"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			scenario, err := LoadScenario(file)
+			require.NoError(t, err)
+
+			result, err := Run(context.Background(), scenario, nil)
+			require.NoError(t, err)
+			for _, failure := range result.Failures {
+				t.Errorf("step %d at %s: %s", failure.Step, failure.At.Format(time.RFC3339), failure.Message)
+			}
+		})
+	}
+}
+
+func TestRun_ReportsFailures(t *testing.T) {
+	used := 5
+	scenario := &Scenario{
+		Name:     "wrong expectations",
+		Start:    time.Date(2026, 1, 5, 16, 0, 0, 0, time.UTC),
+		Devices:  []Device{{ID: "tv1", Type: "tv"}},
+		Children: []Child{{ID: "alice", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 60}},
+		Steps: []Step{
+			{StartSession: &StartSession{Session: "s", DeviceID: "tv1", ChildIDs: []string{"alice"}, Minutes: 30}},
+			{Advance: "10m"},
+			{Expect: &Expectation{Session: "s", Status: "completed"}},
+			{Expect: &Expectation{ChildID: "alice", UsedMinutes: &used}},
+			{StopSession: &StopSession{Session: "missing"}},
+			{ExtendSession: &ExtendSession{Session: "s", Minutes: 10, ExpectError: "INSUFFICIENT_TIME"}},
+		},
+	}
+
+	result, err := Run(context.Background(), scenario, nil)
+	require.NoError(t, err)
+
+	assert.False(t, result.Passed())
+	require.Len(t, result.Failures, 4)
+	assert.Equal(t, 3, result.Failures[0].Step)
+	assert.Contains(t, result.Failures[0].Message, "status active, expected completed")
+	assert.Equal(t, 4, result.Failures[1].Step)
+	assert.Contains(t, result.Failures[1].Message, "used 10 minutes, expected 5")
+	assert.Contains(t, result.Failures[2].Message, `unknown session "missing"`)
+	assert.Contains(t, result.Failures[3].Message, "expected error INSUFFICIENT_TIME, got success")
+	assert.Equal(t, scenario.Start.Add(10*time.Minute), result.End)
+}
+
+func TestRun_RestoresClock(t *testing.T) {
+	scenario := &Scenario{
+		Start: time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC),
+		Steps: []Step{{Advance: "1h"}},
+	}
+
+	_, err := Run(context.Background(), scenario, nil)
+	require.NoError(t, err)
+
+	assert.WithinDuration(t, time.Now(), core.Now(), time.Minute)
+}
+
+func TestScenario_Validate(t *testing.T) {
+	start := time.Date(2026, 1, 5, 16, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		scenario Scenario
+		wantErr  string
+	}{
+		{"missing start", Scenario{}, "start is required"},
+		{"invalid timezone", Scenario{Start: start, Timezone: "Mars/Base"}, "invalid timezone"},
+		{"empty step", Scenario{Start: start, Steps: []Step{{}}}, "exactly one action"},
+		{"two actions", Scenario{Start: start, Steps: []Step{{Advance: "1m", Expect: &Expectation{}}}}, "exactly one action"},
+		{"negative advance", Scenario{Start: start, Steps: []Step{{Advance: "-5m"}}}, "positive duration"},
+		{"unnamed session", Scenario{Start: start, Steps: []Step{{StartSession: &StartSession{DeviceID: "tv1"}}}}, "session name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.scenario.Validate()
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestDriver_RecordsCalls(t *testing.T) {
+	clock := NewClock(time.Date(2026, 1, 5, 16, 0, 0, 0, time.UTC))
+	d := &driver{clock: clock}
+	session := &core.Session{ID: "s1", DeviceID: "tv1", ExpectedDuration: 30}
+
+	require.NoError(t, d.StartSession(context.Background(), session))
+	clock.Advance(25 * time.Minute)
+	require.NoError(t, d.ApplyWarning(context.Background(), session, 5))
+
+	assert.Equal(t, []string{CallStart, CallWarning}, d.calls("tv1"))
+	assert.Empty(t, d.calls("tv2"))
+	require.Len(t, d.events, 2)
+	assert.Equal(t, clock.Now(), d.events[1].At)
+	assert.Equal(t, 5, d.events[1].Minutes)
+}
@@ -0,0 +1,146 @@
+// Package steam polls the Steam Web API for the playtime of children's Steam accounts and
+// converts play that no Metron session covered into daily usage. It can also start a
+// session when a child is in a game without one. The integration is read-only on Steam.
+package steam
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// defaultBaseURL is the Steam Web API
+const defaultBaseURL = "https://api.steampowered.com"
+
+// Game is a recently played game with its playtimes in minutes.
+type Game struct {
+	AppID           string
+	Name            string
+	Playtime2Weeks  int
+	PlaytimeForever int
+}
+
+// Player is the current state of an account.
+type Player struct {
+	SteamID string
+	Name    string
+	GameID  string // App ID of the game being played, empty if none
+	Game    string // Name of the game being played
+}
+
+// Client reads account data from Steam.
+type Client interface {
+	// RecentlyPlayedGames returns the games played in the last two weeks.
+	RecentlyPlayedGames(ctx context.Context, steamID string) ([]Game, error)
+
+	// Player returns the account's current state.
+	Player(ctx context.Context, steamID string) (*Player, error)
+}
+
+// httpClient is the Steam Web API client
+type httpClient struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+// NewHTTPClient creates a Steam Web API client with a key from https://steamcommunity.com/dev/apikey.
+func NewHTTPClient(apiKey string) Client {
+	return newHTTPClient(apiKey, defaultBaseURL)
+}
+
+func newHTTPClient(apiKey, baseURL string) *httpClient {
+	return &httpClient{
+		apiKey:     apiKey,
+		baseURL:    baseURL,
+		httpClient: &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// RecentlyPlayedGames calls IPlayerService/GetRecentlyPlayedGames.
+// Private game details return no games.
+func (c *httpClient) RecentlyPlayedGames(ctx context.Context, steamID string) ([]Game, error) {
+	var result struct {
+		Response struct {
+			Games []struct {
+				AppID           int    `json:"appid"`
+				Name            string `json:"name"`
+				Playtime2Weeks  int    `json:"playtime_2weeks"`
+				PlaytimeForever int    `json:"playtime_forever"`
+			} `json:"games"`
+		} `json:"response"`
+	}
+	if err := c.get(ctx, "/IPlayerService/GetRecentlyPlayedGames/v1/", url.Values{"steamid": {steamID}}, &result); err != nil {
+		return nil, err
+	}
+
+	games := make([]Game, 0, len(result.Response.Games))
+	for _, game := range result.Response.Games {
+		games = append(games, Game{
+			AppID:           strconv.Itoa(game.AppID),
+			Name:            game.Name,
+			Playtime2Weeks:  game.Playtime2Weeks,
+			PlaytimeForever: game.PlaytimeForever,
+		})
+	}
+	return games, nil
+}
+
+// Player calls ISteamUser/GetPlayerSummaries for one account.
+func (c *httpClient) Player(ctx context.Context, steamID string) (*Player, error) {
+	var result struct {
+		Response struct {
+			Players []struct {
+				SteamID       string `json:"steamid"`
+				PersonaName   string `json:"personaname"`
+				GameID        string `json:"gameid"`
+				GameExtraInfo string `json:"gameextrainfo"`
+			} `json:"players"`
+		} `json:"response"`
+	}
+	if err := c.get(ctx, "/ISteamUser/GetPlayerSummaries/v2/", url.Values{"steamids": {steamID}}, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Response.Players) == 0 {
+		return nil, fmt.Errorf("steam account %s not found", steamID)
+	}
+
+	player := result.Response.Players[0]
+	return &Player{
+		SteamID: player.SteamID,
+		Name:    player.PersonaName,
+		GameID:  player.GameID,
+		Game:    player.GameExtraInfo,
+	}, nil
+}
+
+// get calls an API method and decodes the JSON response
+func (c *httpClient) get(ctx context.Context, path string, query url.Values, result interface{}) error {
+	query.Set("key", c.apiKey)
+	query.Set("format", "json")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path+"?"+query.Encode(), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("steam request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("steam rejected the API key (status %d)", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("steam %s returned status %d", path, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+		return fmt.Errorf("failed to decode steam response: %w", err)
+	}
+	return nil
+}
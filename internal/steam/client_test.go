@@ -0,0 +1,81 @@
+package steam
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *httpClient {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return newHTTPClient("api-key", server.URL)
+}
+
+func TestHTTPClient_RecentlyPlayedGames(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/IPlayerService/GetRecentlyPlayedGames/v1/", r.URL.Path)
+		assert.Equal(t, "api-key", r.URL.Query().Get("key"))
+		assert.Equal(t, "76561198000000000", r.URL.Query().Get("steamid"))
+		w.Write([]byte(`{"response": {"total_count": 2, "games": [
+			{"appid": 730, "name": "Counter-Strike 2", "playtime_2weeks": 340, "playtime_forever": 5040},
+			{"appid": 570, "name": "Dota 2", "playtime_2weeks": 15, "playtime_forever": 15}
+		]}}`))
+	})
+
+	games, err := client.RecentlyPlayedGames(context.Background(), "76561198000000000")
+	require.NoError(t, err)
+	assert.Equal(t, []Game{
+		{AppID: "730", Name: "Counter-Strike 2", Playtime2Weeks: 340, PlaytimeForever: 5040},
+		{AppID: "570", Name: "Dota 2", Playtime2Weeks: 15, PlaytimeForever: 15},
+	}, games)
+}
+
+func TestHTTPClient_RecentlyPlayedGamesPrivate(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"response": {}}`))
+	})
+
+	games, err := client.RecentlyPlayedGames(context.Background(), "76561198000000000")
+	require.NoError(t, err)
+	assert.Empty(t, games)
+}
+
+func TestHTTPClient_Player(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/ISteamUser/GetPlayerSummaries/v2/", r.URL.Path)
+		assert.Equal(t, "76561198000000000", r.URL.Query().Get("steamids"))
+		w.Write([]byte(`{"response": {"players": [
+			{"steamid": "76561198000000000", "personaname": "alice", "gameid": "730", "gameextrainfo": "Counter-Strike 2"}
+		]}}`))
+	})
+
+	player, err := client.Player(context.Background(), "76561198000000000")
+	require.NoError(t, err)
+	assert.Equal(t, &Player{SteamID: "76561198000000000", Name: "alice", GameID: "730", Game: "Counter-Strike 2"}, player)
+}
+
+func TestHTTPClient_PlayerNotFound(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"response": {"players": []}}`))
+	})
+
+	_, err := client.Player(context.Background(), "76561198000000000")
+	assert.Error(t, err)
+}
+
+func TestHTTPClient_RejectedKey(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	_, err := client.RecentlyPlayedGames(context.Background(), "76561198000000000")
+	assert.ErrorContains(t, err, "API key")
+}
@@ -0,0 +1,211 @@
+package steam
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"metron/internal/core"
+)
+
+// PlaytimeStorage defines the interface for Steam playtime persistence
+// This interface is implemented by the storage layer to avoid tight coupling
+type PlaytimeStorage interface {
+	GetSteamPlaytime(ctx context.Context, steamID string) (map[string]int, error) // Last seen playtime_forever minutes by app ID
+	SaveSteamPlaytime(ctx context.Context, steamID, appID string, minutes int) error
+}
+
+// PollerStorage is the storage needed by the poller
+type PollerStorage interface {
+	PlaytimeStorage
+	IncrementDailyUsageSummary(ctx context.Context, childID string, date time.Time, minutes int) error
+}
+
+// Sessions lists and starts sessions (implemented by the session manager)
+type Sessions interface {
+	ListActiveSessions(ctx context.Context) ([]*core.Session, error)
+	StartSession(ctx context.Context, deviceID string, childIDs []string, durationMinutes int) (*core.Session, error)
+}
+
+// Account links a Steam account to a child.
+type Account struct {
+	SteamID          string // 64-bit SteamID
+	ChildID          string
+	DeviceID         string // Only sessions on this device cover play (empty = any session of the child)
+	AutoStartMinutes int    // Start a session of this length on DeviceID when play is detected without one (0 = off)
+}
+
+// Poller converts Steam playtime into Metron usage.
+// Steam reports total playtime per game; the poller stores the last seen totals and charges
+// increases to the child's daily usage unless a session of the child was running, since
+// sessions are already charged. Playtime is only as fresh as Steam updates it, so usage
+// may be charged a few minutes late.
+type Poller struct {
+	client     Client
+	storage    PollerStorage
+	sessions   Sessions
+	accounts   []Account
+	calculator *core.TimeCalculationService
+	logger     *slog.Logger
+
+	baselined map[string]bool // Accounts polled since startup
+}
+
+// NewPoller creates a poller; play is charged to the child's usage date from the calculator.
+func NewPoller(client Client, storage PollerStorage, sessions Sessions, accounts []Account, calculator *core.TimeCalculationService, logger *slog.Logger) *Poller {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Poller{
+		client:     client,
+		storage:    storage,
+		sessions:   sessions,
+		accounts:   accounts,
+		calculator: calculator,
+		logger:     logger.With("component", "steam"),
+		baselined:  make(map[string]bool),
+	}
+}
+
+// Run polls every interval until ctx is cancelled.
+func (p *Poller) Run(ctx context.Context, interval time.Duration) {
+	p.logger.Info("Steam playtime polling started", "interval", interval, "accounts", len(p.accounts))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.Poll(ctx); err != nil && ctx.Err() == nil {
+			p.logger.Error("Steam playtime poll failed", "error", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Poll checks every account once.
+func (p *Poller) Poll(ctx context.Context) error {
+	active, err := p.sessions.ListActiveSessions(ctx)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, account := range p.accounts {
+		if err := p.pollAccount(ctx, account, active); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// pollAccount charges new playtime and auto-starts a session if configured
+func (p *Poller) pollAccount(ctx context.Context, account Account, active []*core.Session) error {
+	played, err := p.newPlaytime(ctx, account.SteamID)
+	if err != nil {
+		return err
+	}
+
+	covered := hasSession(active, account)
+	if played > 0 {
+		if covered {
+			p.logger.Debug("Steam play covered by a session",
+				"child_id", account.ChildID,
+				"minutes", played)
+		} else {
+			date := p.calculator.UsageDate(ctx, account.ChildID, core.Now())
+			if err := p.storage.IncrementDailyUsageSummary(ctx, account.ChildID, date, played); err != nil {
+				return err
+			}
+			p.logger.Info("Charged Steam play outside sessions",
+				"child_id", account.ChildID,
+				"steam_id", account.SteamID,
+				"minutes", played)
+		}
+	}
+
+	if account.AutoStartMinutes <= 0 || covered {
+		return nil
+	}
+
+	player, err := p.client.Player(ctx, account.SteamID)
+	if err != nil {
+		return err
+	}
+	if player.GameID == "" {
+		return nil
+	}
+
+	ctx = core.WithInitiator(ctx, core.Initiator{Type: core.InitiatorAutomation, ID: "steam"})
+	session, err := p.sessions.StartSession(ctx, account.DeviceID, []string{account.ChildID}, account.AutoStartMinutes)
+	if err != nil {
+		// Usually no time left: play keeps being charged as usage
+		p.logger.Warn("Failed to auto-start session for Steam play",
+			"child_id", account.ChildID,
+			"game", player.Game,
+			"error", err)
+		return nil
+	}
+
+	p.logger.Info("Auto-started session for Steam play",
+		"session_id", session.ID,
+		"child_id", account.ChildID,
+		"device_id", account.DeviceID,
+		"game", player.Game)
+	return nil
+}
+
+// newPlaytime returns the minutes played since the last poll and stores the new totals.
+// Games seen for the first time since startup only set the baseline, because their
+// playtime may predate the integration. Games that appear later were not played before
+// the previous poll, so their two-week playtime is new.
+func (p *Poller) newPlaytime(ctx context.Context, steamID string) (int, error) {
+	games, err := p.client.RecentlyPlayedGames(ctx, steamID)
+	if err != nil {
+		return 0, err
+	}
+	known, err := p.storage.GetSteamPlaytime(ctx, steamID)
+	if err != nil {
+		return 0, err
+	}
+
+	played := 0
+	for _, game := range games {
+		previous, ok := known[game.AppID]
+		switch {
+		case ok && game.PlaytimeForever > previous:
+			played += game.PlaytimeForever - previous
+		case !ok && p.baselined[steamID]:
+			played += game.Playtime2Weeks
+		}
+
+		if !ok || game.PlaytimeForever != previous {
+			if err := p.storage.SaveSteamPlaytime(ctx, steamID, game.AppID, game.PlaytimeForever); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	p.baselined[steamID] = true
+	return played, nil
+}
+
+// hasSession reports whether a session of the account's child is running on its device
+func hasSession(active []*core.Session, account Account) bool {
+	for _, session := range active {
+		if account.DeviceID != "" && session.DeviceID != account.DeviceID {
+			continue
+		}
+		for _, childID := range session.ChildIDs {
+			if childID == account.ChildID {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,176 @@
+package steam
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"metron/internal/core"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockClient returns the configured games and player state.
+type mockClient struct {
+	games  []Game
+	player Player
+}
+
+func (m *mockClient) RecentlyPlayedGames(_ context.Context, steamID string) ([]Game, error) {
+	return m.games, nil
+}
+
+func (m *mockClient) Player(_ context.Context, steamID string) (*Player, error) {
+	player := m.player
+	return &player, nil
+}
+
+// mockStorage keeps playtime by Steam ID + app ID and daily usage by child ID + date.
+type mockStorage struct {
+	playtime map[string]map[string]int
+	used     map[string]int
+}
+
+func (m *mockStorage) GetSteamPlaytime(_ context.Context, steamID string) (map[string]int, error) {
+	result := make(map[string]int)
+	for appID, minutes := range m.playtime[steamID] {
+		result[appID] = minutes
+	}
+	return result, nil
+}
+
+func (m *mockStorage) SaveSteamPlaytime(_ context.Context, steamID, appID string, minutes int) error {
+	if m.playtime[steamID] == nil {
+		m.playtime[steamID] = make(map[string]int)
+	}
+	m.playtime[steamID][appID] = minutes
+	return nil
+}
+
+func (m *mockStorage) IncrementDailyUsageSummary(_ context.Context, childID string, date time.Time, minutes int) error {
+	m.used[childID+date.Format("2006-01-02")] += minutes
+	return nil
+}
+
+// mockSessions keeps active sessions and records started ones.
+type mockSessions struct {
+	active   []*core.Session
+	started  []*core.Session
+	startErr error
+}
+
+func (m *mockSessions) ListActiveSessions(_ context.Context) ([]*core.Session, error) {
+	return m.active, nil
+}
+
+func (m *mockSessions) StartSession(_ context.Context, deviceID string, childIDs []string, durationMinutes int) (*core.Session, error) {
+	if m.startErr != nil {
+		return nil, m.startErr
+	}
+	session := &core.Session{ID: "ses_auto", DeviceID: deviceID, ChildIDs: childIDs, ExpectedDuration: durationMinutes}
+	m.started = append(m.started, session)
+	return session, nil
+}
+
+func setupTestPoller(t *testing.T, account Account) (*Poller, *mockClient, *mockStorage, *mockSessions) {
+	t.Helper()
+
+	original := core.Now
+	core.Now = func() time.Time { return time.Date(2026, 3, 2, 18, 0, 0, 0, time.UTC) }
+	t.Cleanup(func() { core.Now = original })
+
+	client := &mockClient{}
+	store := &mockStorage{playtime: make(map[string]map[string]int), used: make(map[string]int)}
+	sessions := &mockSessions{}
+	return NewPoller(client, store, sessions, []Account{account}, core.NewTimeCalculationService(nil, time.UTC), nil), client, store, sessions
+}
+
+func TestPoller_ChargesPlayOutsideSessions(t *testing.T) {
+	poller, client, store, _ := setupTestPoller(t, Account{SteamID: "7656", ChildID: "alice"})
+	ctx := context.Background()
+
+	// The first poll only records the playtime so far
+	client.games = []Game{{AppID: "730", Playtime2Weeks: 300, PlaytimeForever: 5000}}
+	require.NoError(t, poller.Poll(ctx))
+	assert.Empty(t, store.used)
+	assert.Equal(t, 5000, store.playtime["7656"]["730"])
+
+	// Later increases are charged
+	client.games = []Game{{AppID: "730", Playtime2Weeks: 340, PlaytimeForever: 5040}}
+	require.NoError(t, poller.Poll(ctx))
+	assert.Equal(t, 40, store.used["alice2026-03-02"])
+
+	// A game that appears after the first poll is new play
+	client.games = append(client.games, Game{AppID: "570", Playtime2Weeks: 15, PlaytimeForever: 15})
+	require.NoError(t, poller.Poll(ctx))
+	assert.Equal(t, 55, store.used["alice2026-03-02"])
+}
+
+func TestPoller_StoredPlaytimeSurvivesRestart(t *testing.T) {
+	poller, client, store, _ := setupTestPoller(t, Account{SteamID: "7656", ChildID: "alice"})
+	store.playtime["7656"] = map[string]int{"730": 5000}
+
+	client.games = []Game{{AppID: "730", Playtime2Weeks: 320, PlaytimeForever: 5020}}
+	require.NoError(t, poller.Poll(context.Background()))
+	assert.Equal(t, 20, store.used["alice2026-03-02"])
+}
+
+func TestPoller_SessionCoversPlay(t *testing.T) {
+	tests := []struct {
+		name     string
+		deviceID string
+		session  *core.Session
+		charged  int
+	}{
+		{"any session of the child", "", &core.Session{DeviceID: "tv", ChildIDs: []string{"alice"}}, 0},
+		{"session on the gaming device", "pc", &core.Session{DeviceID: "pc", ChildIDs: []string{"alice"}}, 0},
+		{"session on another device", "pc", &core.Session{DeviceID: "tv", ChildIDs: []string{"alice"}}, 30},
+		{"session of another child", "", &core.Session{DeviceID: "pc", ChildIDs: []string{"bob"}}, 30},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			poller, client, store, sessions := setupTestPoller(t, Account{SteamID: "7656", ChildID: "alice", DeviceID: tt.deviceID})
+			store.playtime["7656"] = map[string]int{"730": 100}
+			sessions.active = []*core.Session{tt.session}
+
+			client.games = []Game{{AppID: "730", Playtime2Weeks: 130, PlaytimeForever: 130}}
+			require.NoError(t, poller.Poll(context.Background()))
+			assert.Equal(t, tt.charged, store.used["alice2026-03-02"])
+			assert.Equal(t, 130, store.playtime["7656"]["730"])
+		})
+	}
+}
+
+func TestPoller_AutoStart(t *testing.T) {
+	poller, client, _, sessions := setupTestPoller(t, Account{SteamID: "7656", ChildID: "alice", DeviceID: "pc", AutoStartMinutes: 60})
+	ctx := context.Background()
+
+	// Not in a game
+	require.NoError(t, poller.Poll(ctx))
+	assert.Empty(t, sessions.started)
+
+	client.player = Player{SteamID: "7656", GameID: "730", Game: "Counter-Strike 2"}
+	require.NoError(t, poller.Poll(ctx))
+	require.Len(t, sessions.started, 1)
+	assert.Equal(t, "pc", sessions.started[0].DeviceID)
+	assert.Equal(t, []string{"alice"}, sessions.started[0].ChildIDs)
+	assert.Equal(t, 60, sessions.started[0].ExpectedDuration)
+
+	// Already covered by a session
+	sessions.active = sessions.started
+	require.NoError(t, poller.Poll(ctx))
+	assert.Len(t, sessions.started, 1)
+}
+
+func TestPoller_AutoStartRefused(t *testing.T) {
+	poller, client, _, sessions := setupTestPoller(t, Account{SteamID: "7656", ChildID: "alice", DeviceID: "pc", AutoStartMinutes: 60})
+	client.player = Player{SteamID: "7656", GameID: "730"}
+	sessions.startErr = errors.New("daily limit reached")
+
+	// Refused sessions are not poll errors
+	assert.NoError(t, poller.Poll(context.Background()))
+	assert.Empty(t, sessions.started)
+}
@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"metron/internal/core"
+	"sort"
+)
+
+// CreateAgentToken records a newly issued agent token
+func (s *Storage) CreateAgentToken(ctx context.Context, token *core.AgentToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.agentTokens[token.ID]; ok {
+		return fmt.Errorf("agent token %s: %w", token.ID, ErrDuplicateID)
+	}
+	for _, existing := range s.agentTokens {
+		if existing.TokenHash == token.TokenHash {
+			return fmt.Errorf("agent token hash: %w", ErrDuplicateID)
+		}
+	}
+	s.agentTokens[token.ID] = cloneAgentToken(token)
+	return nil
+}
+
+// GetAgentToken retrieves an agent token by ID
+func (s *Storage) GetAgentToken(ctx context.Context, id string) (*core.AgentToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	token, ok := s.agentTokens[id]
+	if !ok {
+		return nil, core.ErrAgentTokenNotFound
+	}
+	return cloneAgentToken(token), nil
+}
+
+// GetAgentTokenByHash retrieves an agent token by the hash of its token
+func (s *Storage) GetAgentTokenByHash(ctx context.Context, tokenHash string) (*core.AgentToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, token := range s.agentTokens {
+		if token.TokenHash == tokenHash {
+			return cloneAgentToken(token), nil
+		}
+	}
+	return nil, core.ErrAgentTokenNotFound
+}
+
+// ListAgentTokens retrieves all agent tokens, newest first
+func (s *Storage) ListAgentTokens(ctx context.Context) ([]*core.AgentToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tokens := make([]*core.AgentToken, 0, len(s.agentTokens))
+	for _, token := range s.agentTokens {
+		tokens = append(tokens, cloneAgentToken(token))
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.After(tokens[j].CreatedAt)
+	})
+	return tokens, nil
+}
+
+// UpdateAgentToken updates the last-used and revocation details of an agent token
+func (s *Storage) UpdateAgentToken(ctx context.Context, token *core.AgentToken) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.agentTokens[token.ID]
+	if !ok {
+		return core.ErrAgentTokenNotFound
+	}
+	existing.LastUsedAt = copyTime(token.LastUsedAt)
+	existing.RevokedAt = copyTime(token.RevokedAt)
+	existing.RevokedBy = token.RevokedBy
+	return nil
+}
+
+func cloneAgentToken(token *core.AgentToken) *core.AgentToken {
+	copied := *token
+	copied.LastUsedAt = copyTime(token.LastUsedAt)
+	copied.RevokedAt = copyTime(token.RevokedAt)
+	return &copied
+}
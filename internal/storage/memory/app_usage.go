@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"context"
+	"metron/internal/core"
+	"sort"
+	"time"
+)
+
+// SaveAppUsage replaces the minutes of a child's app on a day, as reported by a source
+func (s *Storage) SaveAppUsage(ctx context.Context, usage *core.AppUsage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copied := *usage
+	copied.Date = s.normalizeDate(usage.Date)
+	for i, existing := range s.appUsage {
+		if existing.ChildID == copied.ChildID && existing.Date.Equal(copied.Date) &&
+			existing.Source == copied.Source && existing.App == copied.App {
+			s.appUsage[i] = &copied
+			return nil
+		}
+	}
+	s.appUsage = append(s.appUsage, &copied)
+	return nil
+}
+
+// ListAppUsage retrieves the app usage of the days from..to (inclusive), oldest first
+// An empty childID lists every child's usage
+func (s *Storage) ListAppUsage(ctx context.Context, childID string, from, to time.Time) ([]*core.AppUsage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	from, to = s.normalizeDate(from), s.normalizeDate(to)
+	var usage []*core.AppUsage
+	for _, entry := range s.appUsage {
+		if childID != "" && entry.ChildID != childID {
+			continue
+		}
+		if entry.Date.Before(from) || entry.Date.After(to) {
+			continue
+		}
+		copied := *entry
+		usage = append(usage, &copied)
+	}
+	sort.SliceStable(usage, func(i, j int) bool {
+		return usage[i].Date.Before(usage[j].Date)
+	})
+	return usage, nil
+}
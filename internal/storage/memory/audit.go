@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"metron/internal/core"
+	"sort"
+)
+
+// CreateAuditEntry stores an audit log entry
+func (s *Storage) CreateAuditEntry(ctx context.Context, entry *core.AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.auditLog {
+		if existing.ID == entry.ID {
+			return fmt.Errorf("audit entry %s: %w", entry.ID, ErrDuplicateID)
+		}
+	}
+
+	copied := *entry
+	s.auditLog = append(s.auditLog, &copied)
+	return nil
+}
+
+// ListAuditEntries retrieves the latest audit log entries, newest first, for one child or all (empty childID)
+func (s *Storage) ListAuditEntries(ctx context.Context, childID string, limit int) ([]*core.AuditEntry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var entries []*core.AuditEntry
+	for _, entry := range s.auditLog {
+		if childID == "" || entry.ChildID == childID {
+			copied := *entry
+			entries = append(entries, &copied)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		if !entries[i].CreatedAt.Equal(entries[j].CreatedAt) {
+			return entries[i].CreatedAt.After(entries[j].CreatedAt)
+		}
+		return entries[i].ID > entries[j].ID
+	})
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries, nil
+}
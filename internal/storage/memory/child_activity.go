@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"metron/internal/core"
+	"sort"
+)
+
+// CreateChildActivity stores an entry of a child's activity feed
+func (s *Storage) CreateChildActivity(ctx context.Context, activity *core.ChildActivity) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.childActivity {
+		if existing.ID == activity.ID {
+			return fmt.Errorf("child activity %s: %w", activity.ID, ErrDuplicateID)
+		}
+	}
+
+	copied := *activity
+	s.childActivity = append(s.childActivity, &copied)
+	return nil
+}
+
+// ListChildActivity retrieves a child's latest activity, newest first
+func (s *Storage) ListChildActivity(ctx context.Context, childID string, limit int) ([]*core.ChildActivity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var activities []*core.ChildActivity
+	for _, activity := range s.childActivity {
+		if activity.ChildID == childID {
+			copied := *activity
+			activities = append(activities, &copied)
+		}
+	}
+	sort.SliceStable(activities, func(i, j int) bool {
+		if !activities[i].CreatedAt.Equal(activities[j].CreatedAt) {
+			return activities[i].CreatedAt.After(activities[j].CreatedAt)
+		}
+		return activities[i].ID > activities[j].ID
+	})
+	if len(activities) > limit {
+		activities = activities[:limit]
+	}
+	return activities, nil
+}
@@ -0,0 +1,108 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"metron/internal/core"
+	"sort"
+	"time"
+)
+
+// CreateChildLogin records a child's login to the child web app
+func (s *Storage) CreateChildLogin(ctx context.Context, login *core.ChildLogin) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.children[login.ChildID]; !ok {
+		return core.ErrChildNotFound
+	}
+	if _, ok := s.childLogins[login.ID]; ok {
+		return fmt.Errorf("child login %s: %w", login.ID, ErrDuplicateID)
+	}
+	for _, existing := range s.childLogins {
+		if existing.TokenHash == login.TokenHash {
+			return fmt.Errorf("child login hash: %w", ErrDuplicateID)
+		}
+	}
+	s.childLogins[login.ID] = cloneChildLogin(login)
+	return nil
+}
+
+// GetChildLogin retrieves a child login by ID
+func (s *Storage) GetChildLogin(ctx context.Context, id string) (*core.ChildLogin, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	login, ok := s.childLogins[id]
+	if !ok {
+		return nil, core.ErrChildLoginNotFound
+	}
+	return cloneChildLogin(login), nil
+}
+
+// GetChildLoginByHash retrieves a child login by the hash of its token
+func (s *Storage) GetChildLoginByHash(ctx context.Context, tokenHash string) (*core.ChildLogin, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, login := range s.childLogins {
+		if login.TokenHash == tokenHash {
+			return cloneChildLogin(login), nil
+		}
+	}
+	return nil, core.ErrChildLoginNotFound
+}
+
+// ListChildLogins retrieves the logins of a child (all children if childID is empty), newest first
+func (s *Storage) ListChildLogins(ctx context.Context, childID string) ([]*core.ChildLogin, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	logins := make([]*core.ChildLogin, 0, len(s.childLogins))
+	for _, login := range s.childLogins {
+		if childID == "" || login.ChildID == childID {
+			logins = append(logins, cloneChildLogin(login))
+		}
+	}
+	sort.Slice(logins, func(i, j int) bool {
+		return logins[i].CreatedAt.After(logins[j].CreatedAt)
+	})
+	return logins, nil
+}
+
+// UpdateChildLogin updates the last-seen and revocation details of a child login
+func (s *Storage) UpdateChildLogin(ctx context.Context, login *core.ChildLogin) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.childLogins[login.ID]
+	if !ok {
+		return core.ErrChildLoginNotFound
+	}
+	existing.LastSeenAt = copyTime(login.LastSeenAt)
+	existing.RevokedAt = copyTime(login.RevokedAt)
+	existing.RevokedBy = login.RevokedBy
+	return nil
+}
+
+// DeleteExpiredChildLogins deletes the logins that expired before the given time
+func (s *Storage) DeleteExpiredChildLogins(ctx context.Context, before time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	deleted := 0
+	for id, login := range s.childLogins {
+		if login.ExpiresAt.Before(before) {
+			delete(s.childLogins, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func cloneChildLogin(login *core.ChildLogin) *core.ChildLogin {
+	copied := *login
+	copied.LastSeenAt = copyTime(login.LastSeenAt)
+	copied.RevokedAt = copyTime(login.RevokedAt)
+	return &copied
+}
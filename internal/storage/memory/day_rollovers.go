@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"metron/internal/core"
+	"sort"
+	"time"
+)
+
+// CreateDayRollover stores the close-out of a child's day
+// A day is closed out once per child, like the unique index of the SQLite backend
+func (s *Storage) CreateDayRollover(ctx context.Context, rollover *core.DayRollover) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	date := s.normalizeDate(rollover.Date)
+	for _, existing := range s.dayRollovers {
+		if existing.ID == rollover.ID {
+			return fmt.Errorf("day rollover %s: %w", rollover.ID, ErrDuplicateID)
+		}
+		if existing.ChildID == rollover.ChildID && existing.Date.Equal(date) {
+			return fmt.Errorf("day rollover for %s: %w", date.Format("2006-01-02"), ErrDuplicateID)
+		}
+	}
+
+	copied := *rollover
+	copied.Date = date
+	s.dayRollovers = append(s.dayRollovers, &copied)
+	return nil
+}
+
+// GetDayRollover retrieves the close-out of a child's day
+func (s *Storage) GetDayRollover(ctx context.Context, childID string, date time.Time) (*core.DayRollover, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	date = s.normalizeDate(date)
+	for _, rollover := range s.dayRollovers {
+		if rollover.ChildID == childID && rollover.Date.Equal(date) {
+			copied := *rollover
+			return &copied, nil
+		}
+	}
+	return nil, core.ErrDayRolloverNotFound
+}
+
+// UpdateDayRollover updates the used minutes and session count of a closed-out day
+func (s *Storage) UpdateDayRollover(ctx context.Context, rollover *core.DayRollover) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.dayRollovers {
+		if existing.ID == rollover.ID {
+			existing.UsedMinutes = rollover.UsedMinutes
+			existing.SessionCount = rollover.SessionCount
+			return nil
+		}
+	}
+	return core.ErrDayRolloverNotFound
+}
+
+// ListDayRollovers retrieves the day rollovers created after since, oldest first
+func (s *Storage) ListDayRollovers(ctx context.Context, since time.Time) ([]*core.DayRollover, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var rollovers []*core.DayRollover
+	for _, rollover := range s.dayRollovers {
+		if rollover.CreatedAt.After(since) {
+			copied := *rollover
+			rollovers = append(rollovers, &copied)
+		}
+	}
+	sort.SliceStable(rollovers, func(i, j int) bool {
+		if !rollovers[i].CreatedAt.Equal(rollovers[j].CreatedAt) {
+			return rollovers[i].CreatedAt.Before(rollovers[j].CreatedAt)
+		}
+		return rollovers[i].ID < rollovers[j].ID
+	})
+	return rollovers, nil
+}
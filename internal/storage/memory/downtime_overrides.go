@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"metron/internal/core"
+	"sort"
+)
+
+// ListActiveDowntimeOverrides retrieves the downtime overrides that have not ended yet, oldest first
+// Overrides past their ExpiresAt are included; the caller decides whether they still apply
+func (s *Storage) ListActiveDowntimeOverrides(ctx context.Context) ([]*core.DowntimeOverride, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var overrides []*core.DowntimeOverride
+	for _, override := range s.downtimeOverrides {
+		if override.EndedAt == nil {
+			overrides = append(overrides, cloneDowntimeOverride(override))
+		}
+	}
+	sort.Slice(overrides, func(i, j int) bool {
+		return overrides[i].StartedAt.Before(overrides[j].StartedAt)
+	})
+	return overrides, nil
+}
+
+// GetDowntimeOverride retrieves a downtime override by ID
+func (s *Storage) GetDowntimeOverride(ctx context.Context, id string) (*core.DowntimeOverride, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	override, ok := s.downtimeOverrides[id]
+	if !ok {
+		return nil, core.ErrDowntimeOverrideNotFound
+	}
+	return cloneDowntimeOverride(override), nil
+}
+
+// CreateDowntimeOverride records a new downtime override
+func (s *Storage) CreateDowntimeOverride(ctx context.Context, override *core.DowntimeOverride) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.children[override.ChildID]; !ok {
+		return core.ErrChildNotFound
+	}
+	if _, ok := s.downtimeOverrides[override.ID]; ok {
+		return fmt.Errorf("downtime override %s: %w", override.ID, ErrDuplicateID)
+	}
+	s.downtimeOverrides[override.ID] = cloneDowntimeOverride(override)
+	return nil
+}
+
+// UpdateDowntimeOverride updates the end details of a downtime override
+func (s *Storage) UpdateDowntimeOverride(ctx context.Context, override *core.DowntimeOverride) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.downtimeOverrides[override.ID]
+	if !ok {
+		return core.ErrDowntimeOverrideNotFound
+	}
+	existing.EndedAt = copyTime(override.EndedAt)
+	existing.EndedBy = override.EndedBy
+	return nil
+}
+
+func cloneDowntimeOverride(override *core.DowntimeOverride) *core.DowntimeOverride {
+	copied := *override
+	copied.ExpiresAt = copyTime(override.ExpiresAt)
+	copied.EndedAt = copyTime(override.EndedAt)
+	return &copied
+}
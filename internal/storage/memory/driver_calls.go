@@ -0,0 +1,74 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"metron/internal/core"
+	"sort"
+)
+
+// CreateDriverCall records a driver call
+func (s *Storage) CreateDriverCall(ctx context.Context, call *core.DriverCall) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.driverCalls {
+		if existing.ID == call.ID {
+			return fmt.Errorf("driver call %s: %w", call.ID, ErrDuplicateID)
+		}
+	}
+
+	copied := *call
+	s.driverCalls = append(s.driverCalls, &copied)
+	return nil
+}
+
+// ListDriverCalls retrieves the recorded driver calls, newest first
+func (s *Storage) ListDriverCalls(ctx context.Context) ([]*core.DriverCall, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	calls := s.newestDriverCalls()
+	for i, call := range calls {
+		copied := *call
+		calls[i] = &copied
+	}
+	return calls, nil
+}
+
+// PruneDriverCalls deletes all but the newest keep driver calls
+func (s *Storage) PruneDriverCalls(ctx context.Context, keep int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.driverCalls) <= keep {
+		return nil
+	}
+
+	kept := make(map[string]bool, keep)
+	for _, call := range s.newestDriverCalls()[:keep] {
+		kept[call.ID] = true
+	}
+
+	remaining := s.driverCalls[:0]
+	for _, call := range s.driverCalls {
+		if kept[call.ID] {
+			remaining = append(remaining, call)
+		}
+	}
+	s.driverCalls = remaining
+	return nil
+}
+
+// newestDriverCalls returns the driver calls by start time, newest first (ties: last recorded first)
+// The caller must hold the lock.
+func (s *Storage) newestDriverCalls() []*core.DriverCall {
+	calls := make([]*core.DriverCall, len(s.driverCalls))
+	for i, call := range s.driverCalls {
+		calls[len(calls)-1-i] = call
+	}
+	sort.SliceStable(calls, func(i, j int) bool {
+		return calls[i].StartedAt.After(calls[j].StartedAt)
+	})
+	return calls
+}
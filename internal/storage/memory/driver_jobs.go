@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"metron/internal/core"
+	"time"
+)
+
+// CreateDriverJob stores a driver call waiting to be made
+func (s *Storage) CreateDriverJob(ctx context.Context, job *core.DriverJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.driverJobs {
+		if existing.ID == job.ID {
+			return fmt.Errorf("driver job %s: %w", job.ID, ErrDuplicateID)
+		}
+	}
+
+	copied := *job
+	s.driverJobs = append(s.driverJobs, &copied)
+	return nil
+}
+
+// UpdateDriverJob records a failed attempt of a driver job
+func (s *Storage) UpdateDriverJob(ctx context.Context, job *core.DriverJob) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.driverJobs {
+		if existing.ID == job.ID {
+			existing.Attempts = job.Attempts
+			existing.LastError = job.LastError
+			return nil
+		}
+	}
+	return core.ErrDriverJobNotFound
+}
+
+// DeleteDriverJob removes a driver job that is done or dropped
+func (s *Storage) DeleteDriverJob(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, existing := range s.driverJobs {
+		if existing.ID == id {
+			s.driverJobs = append(s.driverJobs[:i], s.driverJobs[i+1:]...)
+			return nil
+		}
+	}
+	return core.ErrDriverJobNotFound
+}
+
+// ListDriverJobs retrieves the stored driver jobs, oldest first
+func (s *Storage) ListDriverJobs(ctx context.Context) ([]*core.DriverJob, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	jobs := make([]*core.DriverJob, 0, len(s.driverJobs))
+	for _, job := range s.driverJobs {
+		copied := *job
+		jobs = append(jobs, &copied)
+	}
+	return jobs, nil
+}
+
+// ClaimDriverJobs renews owner's claims and claims the unclaimed and expired jobs for owner
+// Implements core.DriverJobStorage interface
+func (s *Storage) ClaimDriverJobs(ctx context.Context, owner string, until time.Time) ([]*core.DriverJob, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	var claimed []*core.DriverJob
+	for _, job := range s.driverJobs {
+		switch {
+		case job.Owner == owner:
+			job.ClaimedUntil = until
+		case !job.ClaimedUntil.After(now):
+			job.Owner = owner
+			job.ClaimedUntil = until
+			copied := *job
+			claimed = append(claimed, &copied)
+		}
+	}
+	return claimed, nil
+}
+
+// ReleaseDriverJobs drops owner's claims
+// Implements core.DriverJobStorage interface
+func (s *Storage) ReleaseDriverJobs(ctx context.Context, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, job := range s.driverJobs {
+		if job.Owner == owner {
+			job.Owner = ""
+			job.ClaimedUntil = time.Time{}
+		}
+	}
+	return nil
+}
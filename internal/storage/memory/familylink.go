@@ -0,0 +1,25 @@
+package memory
+
+import (
+	"context"
+	"time"
+)
+
+// GetFamilyLinkUsage returns the Family Link minutes already charged for a device on a date
+// Implements familylink.UsageImportStorage interface
+func (s *Storage) GetFamilyLinkUsage(ctx context.Context, deviceID string, date time.Time) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.familyLink[s.dayKey(deviceID, date)], nil
+}
+
+// SaveFamilyLinkUsage records the Family Link minutes charged for a device on a date
+// Implements familylink.UsageImportStorage interface
+func (s *Storage) SaveFamilyLinkUsage(ctx context.Context, deviceID string, date time.Time, minutes int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.familyLink[s.dayKey(deviceID, date)] = minutes
+	return nil
+}
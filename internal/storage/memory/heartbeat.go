@@ -0,0 +1,33 @@
+package memory
+
+import (
+	"context"
+	"metron/internal/core"
+	"sort"
+)
+
+// SaveDeviceHeartbeat inserts or replaces the heartbeat of a device
+func (s *Storage) SaveDeviceHeartbeat(ctx context.Context, heartbeat *core.DeviceHeartbeat) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copied := *heartbeat
+	s.heartbeats[heartbeat.DeviceID] = &copied
+	return nil
+}
+
+// ListDeviceHeartbeats retrieves the heartbeats of all devices that have checked in, by device ID
+func (s *Storage) ListDeviceHeartbeats(ctx context.Context) ([]*core.DeviceHeartbeat, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	heartbeats := make([]*core.DeviceHeartbeat, 0, len(s.heartbeats))
+	for _, heartbeat := range s.heartbeats {
+		copied := *heartbeat
+		heartbeats = append(heartbeats, &copied)
+	}
+	sort.Slice(heartbeats, func(i, j int) bool {
+		return heartbeats[i].DeviceID < heartbeats[j].DeviceID
+	})
+	return heartbeats, nil
+}
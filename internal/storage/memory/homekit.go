@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"metron/internal/homekit"
+	"slices"
+)
+
+// GetHomeKitIdentity returns the HomeKit bridge identity, nil if none was saved
+// Implements homekit.Storage interface
+func (s *Storage) GetHomeKitIdentity(ctx context.Context) (*homekit.Identity, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.homekitID == nil {
+		return nil, nil
+	}
+	identity := *s.homekitID
+	identity.PrivateKey = slices.Clone(identity.PrivateKey)
+	return &identity, nil
+}
+
+// SaveHomeKitIdentity stores the HomeKit bridge identity
+// Implements homekit.Storage interface
+func (s *Storage) SaveHomeKitIdentity(ctx context.Context, identity *homekit.Identity) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := *identity
+	stored.PrivateKey = slices.Clone(identity.PrivateKey)
+	s.homekitID = &stored
+	return nil
+}
+
+// ListHomeKitPairings returns the controllers paired with the HomeKit bridge
+// Implements homekit.Storage interface
+func (s *Storage) ListHomeKitPairings(ctx context.Context) ([]*homekit.Pairing, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pairings := make([]*homekit.Pairing, 0, len(s.homekitPairs))
+	for _, pairing := range s.homekitPairs {
+		pairings = append(pairings, copyPairing(pairing))
+	}
+	return pairings, nil
+}
+
+// SaveHomeKitPairing adds a pairing or replaces the pairing of the same controller
+// Implements homekit.Storage interface
+func (s *Storage) SaveHomeKitPairing(ctx context.Context, pairing *homekit.Pairing) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, existing := range s.homekitPairs {
+		if existing.ControllerID == pairing.ControllerID {
+			s.homekitPairs[i] = copyPairing(pairing)
+			return nil
+		}
+	}
+	s.homekitPairs = append(s.homekitPairs, copyPairing(pairing))
+	return nil
+}
+
+// DeleteHomeKitPairing removes the pairing of a controller
+// Implements homekit.Storage interface
+func (s *Storage) DeleteHomeKitPairing(ctx context.Context, controllerID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.homekitPairs = slices.DeleteFunc(s.homekitPairs, func(pairing *homekit.Pairing) bool {
+		return pairing.ControllerID == controllerID
+	})
+	return nil
+}
+
+func copyPairing(pairing *homekit.Pairing) *homekit.Pairing {
+	copied := *pairing
+	copied.PublicKey = slices.Clone(pairing.PublicKey)
+	return &copied
+}
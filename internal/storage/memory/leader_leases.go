@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"context"
+	"time"
+)
+
+// leaderLease is a leader lease held by one process
+type leaderLease struct {
+	owner     string
+	expiresAt time.Time
+}
+
+// AcquireLeaderLease takes or renews the named lease for owner until the given time
+// Implements core.LeaderLeaseStorage interface
+func (s *Storage) AcquireLeaderLease(ctx context.Context, name, owner string, until time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if lease, ok := s.leaderLeases[name]; ok && lease.owner != owner && lease.expiresAt.After(time.Now()) {
+		return false, nil
+	}
+	s.leaderLeases[name] = leaderLease{owner: owner, expiresAt: until}
+	return true, nil
+}
+
+// ReleaseLeaderLease gives up owner's lease
+// Implements core.LeaderLeaseStorage interface
+func (s *Storage) ReleaseLeaderLease(ctx context.Context, name, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if lease, ok := s.leaderLeases[name]; ok && lease.owner == owner {
+		delete(s.leaderLeases, name)
+	}
+	return nil
+}
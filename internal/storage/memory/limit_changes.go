@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"metron/internal/core"
+	"sort"
+)
+
+// CreateLimitChange stores a limit change
+// Changes for unknown children are rejected, like foreign keys in SQLite
+func (s *Storage) CreateLimitChange(ctx context.Context, change *core.LimitChange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.children[change.ChildID]; !ok {
+		return core.ErrChildNotFound
+	}
+	for _, existing := range s.limitChanges {
+		if existing.ID == change.ID {
+			return fmt.Errorf("limit change %s: %w", change.ID, ErrDuplicateID)
+		}
+	}
+
+	s.limitChanges = append(s.limitChanges, cloneLimitChange(change))
+	return nil
+}
+
+// UpdateLimitChange updates a limit change (e.g., when it takes effect)
+func (s *Storage) UpdateLimitChange(ctx context.Context, change *core.LimitChange) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, existing := range s.limitChanges {
+		if existing.ID == change.ID {
+			s.limitChanges[i] = cloneLimitChange(change)
+			return nil
+		}
+	}
+	return core.ErrLimitChangeNotFound
+}
+
+// DeleteLimitChange deletes a limit change
+func (s *Storage) DeleteLimitChange(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, existing := range s.limitChanges {
+		if existing.ID == id {
+			s.limitChanges = append(s.limitChanges[:i], s.limitChanges[i+1:]...)
+			return nil
+		}
+	}
+	return core.ErrLimitChangeNotFound
+}
+
+// ListLimitChanges retrieves the limit changes of a child (all children if childID is empty), oldest effective date first
+func (s *Storage) ListLimitChanges(ctx context.Context, childID string) ([]*core.LimitChange, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var changes []*core.LimitChange
+	for _, change := range s.limitChanges {
+		if childID == "" || change.ChildID == childID {
+			changes = append(changes, cloneLimitChange(change))
+		}
+	}
+	sortLimitChanges(changes)
+	return changes, nil
+}
+
+// withScheduledLimits sets the child's pending limit changes; the caller holds the lock
+func (s *Storage) withScheduledLimits(child *core.Child) *core.Child {
+	var pending []*core.LimitChange
+	for _, change := range s.limitChanges {
+		if change.ChildID == child.ID && change.IsPending() {
+			pending = append(pending, change)
+		}
+	}
+	sortLimitChanges(pending)
+
+	for _, change := range pending {
+		child.ScheduledLimits = append(child.ScheduledLimits, *cloneLimitChange(change))
+	}
+	return child
+}
+
+// sortLimitChanges orders changes like the SQLite backend: effective date, creation time, ID
+func sortLimitChanges(changes []*core.LimitChange) {
+	sort.SliceStable(changes, func(i, j int) bool {
+		if !changes[i].EffectiveFrom.Equal(changes[j].EffectiveFrom) {
+			return changes[i].EffectiveFrom.Before(changes[j].EffectiveFrom)
+		}
+		if !changes[i].CreatedAt.Equal(changes[j].CreatedAt) {
+			return changes[i].CreatedAt.Before(changes[j].CreatedAt)
+		}
+		return changes[i].ID < changes[j].ID
+	})
+}
+
+func cloneLimitChange(change *core.LimitChange) *core.LimitChange {
+	copied := *change
+	copied.AppliedAt = copyTime(change.AppliedAt)
+	return &copied
+}
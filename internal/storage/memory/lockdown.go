@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"metron/internal/core"
+	"sort"
+)
+
+// GetActiveLockdown returns the most recent lockdown that has not been lifted (nil if none)
+func (s *Storage) GetActiveLockdown(ctx context.Context) (*core.Lockdown, error) {
+	for _, lockdown := range s.sortedLockdowns() {
+		if lockdown.IsActive() {
+			return lockdown, nil
+		}
+	}
+	return nil, nil
+}
+
+// CreateLockdown records a new lockdown
+func (s *Storage) CreateLockdown(ctx context.Context, lockdown *core.Lockdown) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.lockdowns[lockdown.ID]; ok {
+		return fmt.Errorf("lockdown %s: %w", lockdown.ID, ErrDuplicateID)
+	}
+	s.lockdowns[lockdown.ID] = cloneLockdown(lockdown)
+	return nil
+}
+
+// UpdateLockdown updates the stopped session count and lift details of a lockdown
+func (s *Storage) UpdateLockdown(ctx context.Context, lockdown *core.Lockdown) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.lockdowns[lockdown.ID]
+	if !ok {
+		return nil
+	}
+	existing.StoppedSessions = lockdown.StoppedSessions
+	existing.LiftedAt = copyTime(lockdown.LiftedAt)
+	existing.LiftedBy = lockdown.LiftedBy
+	return nil
+}
+
+// ListLockdowns retrieves the most recent lockdowns, newest first
+func (s *Storage) ListLockdowns(ctx context.Context, limit int) ([]*core.Lockdown, error) {
+	lockdowns := s.sortedLockdowns()
+	if limit >= 0 && len(lockdowns) > limit {
+		lockdowns = lockdowns[:limit]
+	}
+	return lockdowns, nil
+}
+
+// sortedLockdowns returns copies of all lockdowns, newest first
+func (s *Storage) sortedLockdowns() []*core.Lockdown {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	lockdowns := make([]*core.Lockdown, 0, len(s.lockdowns))
+	for _, lockdown := range s.lockdowns {
+		lockdowns = append(lockdowns, cloneLockdown(lockdown))
+	}
+	sort.Slice(lockdowns, func(i, j int) bool {
+		return lockdowns[i].StartedAt.After(lockdowns[j].StartedAt)
+	})
+	return lockdowns
+}
+
+func cloneLockdown(lockdown *core.Lockdown) *core.Lockdown {
+	copied := *lockdown
+	copied.LiftedAt = copyTime(lockdown.LiftedAt)
+	return &copied
+}
@@ -0,0 +1,801 @@
+// Package memory is an in-memory storage backend.
+// It implements storage.Storage with the same semantics as the SQLite backend (see
+// internal/storage/storagetest) and keeps nothing on disk, so it suits unit tests,
+// the simulator and quick demos (metron -storage memory). All data is lost on exit.
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"metron/internal/core"
+	"metron/internal/homekit"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrDuplicateID is returned when creating a record whose ID already exists
+	ErrDuplicateID = errors.New("duplicate id")
+	// ErrDuplicateChild is returned when a session lists the same child twice
+	ErrDuplicateChild = errors.New("duplicate child in session")
+	// ErrClosed is returned by Ping after Close
+	ErrClosed = errors.New("storage closed")
+)
+
+// dayKey identifies a per-child daily record
+type dayKey struct {
+	childID string
+	date    string // YYYY-MM-DD in the storage timezone
+}
+
+// Storage implements storage.Storage, credentials.Store, core.DowntimeSkipStorage,
+// core.AgentTokenStorage, core.ChildLoginStorage, familylink.UsageImportStorage, steam.PlaytimeStorage and homekit.Storage in memory
+// Records are copied on the way in and out, so callers never share state with the store
+type Storage struct {
+	mu       sync.RWMutex
+	timezone *time.Location
+	closed   bool
+
+	children          map[string]*core.Child
+	sessions          map[string]*core.Session
+	allocations       map[dayKey]*core.DailyTimeAllocation
+	summaries         map[dayKey]*core.DailyUsageSummary
+	bypasses          map[string]*core.DeviceBypass
+	movieTime         map[string]*core.MovieTimeUsage // keyed by date
+	movieBypasses     map[string]*core.MovieTimeBypass
+	lockdowns         map[string]*core.Lockdown
+	trackingPauses    map[string]*core.TrackingPause
+	agentTokens       map[string]*core.AgentToken
+	childLogins       map[string]*core.ChildLogin
+	downtimeOverrides map[string]*core.DowntimeOverride
+	heartbeats        map[string]*core.DeviceHeartbeat
+	tamperEvents      []*core.TamperEvent       // In insertion order
+	familyLink        map[dayKey]int            // Imported Family Link minutes, keyed by device ID and day
+	steamPlaytime     map[string]map[string]int // Last seen Steam minutes by Steam ID and app ID
+	sessionClaims     map[string]sessionClaim   // By session ID
+	leaderLeases      map[string]leaderLease    // By lease name
+	limitChanges      []*core.LimitChange       // In insertion order
+	auditLog          []*core.AuditEntry        // In insertion order; kept when a child is deleted
+	transitions       []*core.ProfileTransition // In insertion order
+	usageAlerts       []*core.UsageAlert        // In insertion order
+	dayRollovers      []*core.DayRollover       // In insertion order
+	driverJobs        []*core.DriverJob         // In insertion order
+	driverCalls       []*core.DriverCall        // In insertion order
+	childActivity     []*core.ChildActivity     // In insertion order
+	appUsage          []*core.AppUsage          // In insertion order
+	pushSubscriptions []*core.PushSubscription  // In subscription order
+	homekitID         *homekit.Identity
+	homekitPairs      []*homekit.Pairing // In pairing order
+	credentials       map[string][]byte  // By driver name
+	downtimeSkip      *time.Time
+}
+
+// New creates an empty in-memory storage
+// Dates are normalized to midnight in timezone, like the SQLite backend (nil = UTC)
+func New(timezone *time.Location) *Storage {
+	if timezone == nil {
+		timezone = time.UTC
+	}
+	return &Storage{
+		timezone:          timezone,
+		children:          make(map[string]*core.Child),
+		sessions:          make(map[string]*core.Session),
+		allocations:       make(map[dayKey]*core.DailyTimeAllocation),
+		summaries:         make(map[dayKey]*core.DailyUsageSummary),
+		bypasses:          make(map[string]*core.DeviceBypass),
+		movieTime:         make(map[string]*core.MovieTimeUsage),
+		movieBypasses:     make(map[string]*core.MovieTimeBypass),
+		lockdowns:         make(map[string]*core.Lockdown),
+		trackingPauses:    make(map[string]*core.TrackingPause),
+		agentTokens:       make(map[string]*core.AgentToken),
+		childLogins:       make(map[string]*core.ChildLogin),
+		downtimeOverrides: make(map[string]*core.DowntimeOverride),
+		heartbeats:        make(map[string]*core.DeviceHeartbeat),
+		familyLink:        make(map[dayKey]int),
+		steamPlaytime:     make(map[string]map[string]int),
+		sessionClaims:     make(map[string]sessionClaim),
+		leaderLeases:      make(map[string]leaderLease),
+		credentials:       make(map[string][]byte),
+	}
+}
+
+// Ping reports whether the storage is open
+func (s *Storage) Ping(ctx context.Context) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return ErrClosed
+	}
+	return nil
+}
+
+// Close marks the storage as closed; the data stays readable
+func (s *Storage) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func (s *Storage) normalizeDate(t time.Time) time.Time {
+	year, month, day := t.In(s.timezone).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, s.timezone)
+}
+
+func (s *Storage) dayKey(childID string, date time.Time) dayKey {
+	return dayKey{childID: childID, date: s.normalizeDate(date).Format("2006-01-02")}
+}
+
+// ============================================================================
+// Children
+// ============================================================================
+
+// CreateChild creates a new child
+func (s *Storage) CreateChild(ctx context.Context, child *core.Child) error {
+	if err := child.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.children[child.ID]; ok {
+		return fmt.Errorf("child %s: %w", child.ID, ErrDuplicateID)
+	}
+
+	now := core.Now()
+	if child.CreatedAt.IsZero() { // Imported children keep their creation time
+		child.CreatedAt = now
+	}
+	child.UpdatedAt = now
+	s.children[child.ID] = cloneChild(child)
+	return nil
+}
+
+// GetChild retrieves a child by ID
+func (s *Storage) GetChild(ctx context.Context, id string) (*core.Child, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	child, ok := s.children[id]
+	if !ok {
+		return nil, core.ErrChildNotFound
+	}
+	return s.withScheduledLimits(cloneChild(child)), nil
+}
+
+// ListChildren retrieves all children, ordered by name
+func (s *Storage) ListChildren(ctx context.Context) ([]*core.Child, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	children := make([]*core.Child, 0, len(s.children))
+	for _, child := range s.children {
+		children = append(children, s.withScheduledLimits(cloneChild(child)))
+	}
+	sort.Slice(children, func(i, j int) bool {
+		if children[i].Name != children[j].Name {
+			return children[i].Name < children[j].Name
+		}
+		return children[i].ID < children[j].ID
+	})
+	return children, nil
+}
+
+// UpdateChild updates an existing child
+func (s *Storage) UpdateChild(ctx context.Context, child *core.Child) error {
+	if err := child.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.children[child.ID]
+	if !ok {
+		return core.ErrChildNotFound
+	}
+
+	child.UpdatedAt = core.Now()
+	updated := cloneChild(child)
+	updated.CreatedAt = existing.CreatedAt
+	s.children[child.ID] = updated
+	return nil
+}
+
+// DeleteChild deletes a child with its session memberships and daily records
+func (s *Storage) DeleteChild(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.children[id]; !ok {
+		return core.ErrChildNotFound
+	}
+	delete(s.children, id)
+
+	for _, session := range s.sessions {
+		session.ChildIDs = removeID(session.ChildIDs, id)
+	}
+	for key := range s.allocations {
+		if key.childID == id {
+			delete(s.allocations, key)
+		}
+	}
+	for key := range s.summaries {
+		if key.childID == id {
+			delete(s.summaries, key)
+		}
+	}
+	kept := s.limitChanges[:0]
+	for _, change := range s.limitChanges {
+		if change.ChildID != id {
+			kept = append(kept, change)
+		}
+	}
+	s.limitChanges = kept
+	keptTransitions := s.transitions[:0]
+	for _, transition := range s.transitions {
+		if transition.ChildID != id {
+			keptTransitions = append(keptTransitions, transition)
+		}
+	}
+	s.transitions = keptTransitions
+	keptAlerts := s.usageAlerts[:0]
+	for _, alert := range s.usageAlerts {
+		if alert.ChildID != id {
+			keptAlerts = append(keptAlerts, alert)
+		}
+	}
+	s.usageAlerts = keptAlerts
+	keptRollovers := s.dayRollovers[:0]
+	for _, rollover := range s.dayRollovers {
+		if rollover.ChildID != id {
+			keptRollovers = append(keptRollovers, rollover)
+		}
+	}
+	s.dayRollovers = keptRollovers
+	keptAppUsage := s.appUsage[:0]
+	for _, usage := range s.appUsage {
+		if usage.ChildID != id {
+			keptAppUsage = append(keptAppUsage, usage)
+		}
+	}
+	s.appUsage = keptAppUsage
+	keptPush := s.pushSubscriptions[:0]
+	for _, subscription := range s.pushSubscriptions {
+		if subscription.ChildID != id {
+			keptPush = append(keptPush, subscription)
+		}
+	}
+	s.pushSubscriptions = keptPush
+	keptActivity := s.childActivity[:0]
+	for _, activity := range s.childActivity {
+		if activity.ChildID != id {
+			keptActivity = append(keptActivity, activity)
+		}
+	}
+	s.childActivity = keptActivity
+	for loginID, login := range s.childLogins {
+		if login.ChildID == id {
+			delete(s.childLogins, loginID)
+		}
+	}
+	for overrideID, override := range s.downtimeOverrides {
+		if override.ChildID == id {
+			delete(s.downtimeOverrides, overrideID)
+		}
+	}
+	return nil
+}
+
+// ============================================================================
+// Sessions
+// ============================================================================
+
+// CreateSession creates a new session
+// Nothing is stored if the session or any of its children is invalid
+func (s *Storage) CreateSession(ctx context.Context, session *core.Session) error {
+	if err := session.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sessions[session.ID]; ok {
+		return fmt.Errorf("session %s: %w", session.ID, ErrDuplicateID)
+	}
+	if err := s.checkSessionChildren(session.ChildIDs); err != nil {
+		return err
+	}
+
+	now := core.Now()
+	session.CreatedAt = now
+	session.UpdatedAt = now
+	s.sessions[session.ID] = cloneSession(session)
+	return nil
+}
+
+// checkSessionChildren verifies that every child exists and is listed once
+func (s *Storage) checkSessionChildren(childIDs []string) error {
+	seen := make(map[string]bool, len(childIDs))
+	for _, childID := range childIDs {
+		if seen[childID] {
+			return fmt.Errorf("child %s: %w", childID, ErrDuplicateChild)
+		}
+		seen[childID] = true
+		if _, ok := s.children[childID]; !ok {
+			return fmt.Errorf("child %s: %w", childID, core.ErrChildNotFound)
+		}
+	}
+	return nil
+}
+
+// GetSession retrieves a session by ID
+func (s *Storage) GetSession(ctx context.Context, id string) (*core.Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return nil, core.ErrSessionNotFound
+	}
+	return cloneSession(session), nil
+}
+
+// ListActiveSessions retrieves all running sessions, including sessions paused for a break
+func (s *Storage) ListActiveSessions(ctx context.Context) ([]*core.Session, error) {
+	return s.listSessions(func(session *core.Session) bool {
+		return session.IsRunning()
+	}), nil
+}
+
+// ListAllSessions retrieves all sessions regardless of status
+func (s *Storage) ListAllSessions(ctx context.Context) ([]*core.Session, error) {
+	return s.listSessions(func(*core.Session) bool { return true }), nil
+}
+
+// ListSessionsByChild retrieves all sessions for a specific child
+func (s *Storage) ListSessionsByChild(ctx context.Context, childID string) ([]*core.Session, error) {
+	return s.listSessions(func(session *core.Session) bool {
+		return containsID(session.ChildIDs, childID)
+	}), nil
+}
+
+// ListSessionsSince retrieves the sessions started at or after since, newest first
+func (s *Storage) ListSessionsSince(ctx context.Context, since time.Time) ([]*core.Session, error) {
+	return s.listSessions(func(session *core.Session) bool {
+		return !session.StartTime.Before(since)
+	}), nil
+}
+
+// listSessions returns copies of the matching sessions, newest first
+func (s *Storage) listSessions(match func(*core.Session) bool) []*core.Session {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sessions := make([]*core.Session, 0)
+	for _, session := range s.sessions {
+		if match(session) {
+			sessions = append(sessions, cloneSession(session))
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		if !sessions[i].StartTime.Equal(sessions[j].StartTime) {
+			return sessions[i].StartTime.After(sessions[j].StartTime)
+		}
+		return sessions[i].ID < sessions[j].ID
+	})
+	return sessions
+}
+
+// UpdateSession updates an existing session
+// Like the SQLite backend, the start time, creation time, movie and break-exempt flags and initiator are fixed at creation
+func (s *Storage) UpdateSession(ctx context.Context, session *core.Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.sessions[session.ID]
+	if !ok {
+		return core.ErrSessionNotFound
+	}
+	if err := s.checkSessionChildren(session.ChildIDs); err != nil {
+		return err
+	}
+
+	session.UpdatedAt = core.Now()
+	updated := cloneSession(session)
+	updated.StartTime = existing.StartTime
+	updated.CreatedAt = existing.CreatedAt
+	updated.IsMovieSession = existing.IsMovieSession
+	updated.Activity = existing.Activity
+	updated.BreakExempt = existing.BreakExempt
+	updated.InitiatorType = existing.InitiatorType
+	updated.InitiatorID = existing.InitiatorID
+	s.sessions[session.ID] = updated
+	return nil
+}
+
+// DeleteSession deletes a session
+func (s *Storage) DeleteSession(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sessions[id]; !ok {
+		return core.ErrSessionNotFound
+	}
+	delete(s.sessions, id)
+	return nil
+}
+
+// ListActiveSessionRecords retrieves usage records for sessions with status active
+func (s *Storage) ListActiveSessionRecords(ctx context.Context) ([]*core.SessionUsageRecord, error) {
+	active := s.listSessions(func(session *core.Session) bool {
+		return session.Status == core.SessionStatusActive
+	})
+
+	records := make([]*core.SessionUsageRecord, 0, len(active))
+	for _, session := range active {
+		records = append(records, &core.SessionUsageRecord{
+			ID:               session.ID,
+			DeviceType:       session.DeviceType,
+			DeviceID:         session.DeviceID,
+			ChildIDs:         session.ChildIDs,
+			StartTime:        session.StartTime,
+			ExpectedDuration: session.ExpectedDuration,
+			Status:           session.Status,
+			LastBreakAt:      session.LastBreakAt,
+			BreakEndsAt:      session.BreakEndsAt,
+			WarningSentAt:    session.WarningSentAt,
+			BreakMinutes:     session.BreakMinutes,
+			IsMovieSession:   session.IsMovieSession,
+			Activity:         session.Activity,
+			CreatedAt:        session.CreatedAt,
+			UpdatedAt:        session.UpdatedAt,
+		})
+	}
+	return records, nil
+}
+
+// ============================================================================
+// Daily allocations and usage
+// ============================================================================
+
+// GetDailyAllocation retrieves the daily time allocation for a child
+func (s *Storage) GetDailyAllocation(ctx context.Context, childID string, date time.Time) (*core.DailyTimeAllocation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	allocation, ok := s.allocations[s.dayKey(childID, date)]
+	if !ok {
+		return nil, core.ErrAllocationNotFound
+	}
+	copied := *allocation
+	return &copied, nil
+}
+
+// CreateDailyAllocation creates a new daily time allocation
+func (s *Storage) CreateDailyAllocation(ctx context.Context, allocation *core.DailyTimeAllocation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.children[allocation.ChildID]; !ok {
+		return core.ErrChildNotFound
+	}
+	key := s.dayKey(allocation.ChildID, allocation.Date)
+	if _, ok := s.allocations[key]; ok {
+		return fmt.Errorf("allocation %s %s: %w", key.childID, key.date, ErrDuplicateID)
+	}
+
+	now := core.Now()
+	allocation.Date = s.normalizeDate(allocation.Date)
+	allocation.CreatedAt = now
+	allocation.UpdatedAt = now
+	copied := *allocation
+	s.allocations[key] = &copied
+	return nil
+}
+
+// UpdateDailyAllocation updates an existing daily time allocation; a missing allocation is ignored
+func (s *Storage) UpdateDailyAllocation(ctx context.Context, allocation *core.DailyTimeAllocation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	allocation.Date = s.normalizeDate(allocation.Date)
+	allocation.UpdatedAt = core.Now()
+
+	existing, ok := s.allocations[s.dayKey(allocation.ChildID, allocation.Date)]
+	if !ok {
+		return nil
+	}
+	existing.BaseLimit = allocation.BaseLimit
+	existing.BonusGranted = allocation.BonusGranted
+	existing.UpdatedAt = allocation.UpdatedAt
+	return nil
+}
+
+// ListDailyAllocations retrieves the allocations of all children for a day
+func (s *Storage) ListDailyAllocations(ctx context.Context, date time.Time) ([]*core.DailyTimeAllocation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	day := s.dayKey("", date).date
+	var allocations []*core.DailyTimeAllocation
+	for key, allocation := range s.allocations {
+		if key.date == day {
+			copied := *allocation
+			allocations = append(allocations, &copied)
+		}
+	}
+	return allocations, nil
+}
+
+// ListChildAllocations retrieves a child's allocations from one day to another (inclusive), oldest first
+// Implements core.AllocationStorage interface
+func (s *Storage) ListChildAllocations(ctx context.Context, childID string, from, to time.Time) ([]*core.DailyTimeAllocation, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	first, last := s.dayKey(childID, from).date, s.dayKey(childID, to).date
+	var allocations []*core.DailyTimeAllocation
+	for key, allocation := range s.allocations {
+		if key.childID == childID && key.date >= first && key.date <= last {
+			copied := *allocation
+			allocations = append(allocations, &copied)
+		}
+	}
+	sort.Slice(allocations, func(i, j int) bool {
+		return allocations[i].Date.Before(allocations[j].Date)
+	})
+	return allocations, nil
+}
+
+// GetDailyUsageSummary retrieves the daily usage summary for a child
+// A missing summary is returned as an empty one
+func (s *Storage) GetDailyUsageSummary(ctx context.Context, childID string, date time.Time) (*core.DailyUsageSummary, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	summary, ok := s.summaries[s.dayKey(childID, date)]
+	if !ok {
+		now := core.Now()
+		return &core.DailyUsageSummary{
+			ChildID:   childID,
+			Date:      s.normalizeDate(date),
+			CreatedAt: now,
+			UpdatedAt: now,
+		}, nil
+	}
+	copied := *summary
+	return &copied, nil
+}
+
+// ListDailyUsageSummaries retrieves the stored usage summaries of all children for a day
+// Children without usage on the day have no summary
+func (s *Storage) ListDailyUsageSummaries(ctx context.Context, date time.Time) ([]*core.DailyUsageSummary, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	day := s.dayKey("", date).date
+	var summaries []*core.DailyUsageSummary
+	for key, summary := range s.summaries {
+		if key.date == day {
+			copied := *summary
+			summaries = append(summaries, &copied)
+		}
+	}
+	return summaries, nil
+}
+
+// IncrementDailyUsageSummary adds used minutes to a child's day
+func (s *Storage) IncrementDailyUsageSummary(ctx context.Context, childID string, date time.Time, minutes int) error {
+	return s.updateSummary(childID, date, func(summary *core.DailyUsageSummary) {
+		summary.MinutesUsed += minutes
+	})
+}
+
+// IncrementSessionCountSummary adds a session to a child's day
+func (s *Storage) IncrementSessionCountSummary(ctx context.Context, childID string, date time.Time) error {
+	return s.updateSummary(childID, date, func(summary *core.DailyUsageSummary) {
+		summary.SessionCount++
+	})
+}
+
+// updateSummary creates the summary if needed, then applies update
+func (s *Storage) updateSummary(childID string, date time.Time, update func(*core.DailyUsageSummary)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.children[childID]; !ok {
+		return core.ErrChildNotFound
+	}
+
+	now := core.Now()
+	key := s.dayKey(childID, date)
+	summary, ok := s.summaries[key]
+	if !ok {
+		summary = &core.DailyUsageSummary{
+			ChildID:   childID,
+			Date:      s.normalizeDate(date),
+			CreatedAt: now,
+		}
+		s.summaries[key] = summary
+	}
+	update(summary)
+	summary.UpdatedAt = now
+	return nil
+}
+
+// ============================================================================
+// Device bypass
+// ============================================================================
+
+// GetDeviceBypass retrieves the bypass status for a device (nil if none)
+func (s *Storage) GetDeviceBypass(ctx context.Context, deviceID string) (*core.DeviceBypass, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	bypass, ok := s.bypasses[deviceID]
+	if !ok {
+		return nil, nil
+	}
+	return cloneBypass(bypass), nil
+}
+
+// SetDeviceBypass sets or replaces the bypass for a device
+func (s *Storage) SetDeviceBypass(ctx context.Context, bypass *core.DeviceBypass) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.bypasses[bypass.DeviceID] = cloneBypass(bypass)
+	return nil
+}
+
+// ClearDeviceBypass removes the bypass for a device
+func (s *Storage) ClearDeviceBypass(ctx context.Context, deviceID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.bypasses, deviceID)
+	return nil
+}
+
+// ListActiveBypassDevices retrieves all enabled, unexpired bypasses, most recently enabled first
+func (s *Storage) ListActiveBypassDevices(ctx context.Context) ([]*core.DeviceBypass, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	now := core.Now()
+	bypasses := make([]*core.DeviceBypass, 0)
+	for _, bypass := range s.bypasses {
+		if bypass.Enabled && (bypass.ExpiresAt == nil || bypass.ExpiresAt.After(now)) {
+			bypasses = append(bypasses, cloneBypass(bypass))
+		}
+	}
+	sort.Slice(bypasses, func(i, j int) bool {
+		return bypasses[i].EnabledAt.After(bypasses[j].EnabledAt)
+	})
+	return bypasses, nil
+}
+
+// ============================================================================
+// Driver and downtime storage
+// ============================================================================
+
+// GetCredential returns a driver's credential (nil if none)
+// Implements credentials.Store interface
+func (s *Storage) GetCredential(ctx context.Context, driver string) ([]byte, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	data, ok := s.credentials[driver]
+	if !ok {
+		return nil, nil
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// SaveCredential stores or replaces a driver's credential
+// Implements credentials.Store interface
+func (s *Storage) SaveCredential(ctx context.Context, driver string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.credentials[driver] = append([]byte(nil), data...)
+	return nil
+}
+
+// GetDowntimeSkipDate retrieves the stored skip date for downtime (nil if none)
+// Implements core.DowntimeSkipStorage interface
+func (s *Storage) GetDowntimeSkipDate(ctx context.Context) (*time.Time, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return copyTime(s.downtimeSkip), nil
+}
+
+// SetDowntimeSkipDate sets the skip date for downtime
+func (s *Storage) SetDowntimeSkipDate(ctx context.Context, date time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	normalized := s.normalizeDate(date)
+	s.downtimeSkip = &normalized
+	return nil
+}
+
+// ============================================================================
+// Copy helpers
+// ============================================================================
+
+func cloneChild(child *core.Child) *core.Child {
+	copied := *child
+	if child.BreakRule != nil {
+		rule := *child.BreakRule
+		copied.BreakRule = &rule
+	}
+	if len(child.AllowedDevices) > 0 {
+		copied.AllowedDevices = append([]string(nil), child.AllowedDevices...)
+	} else {
+		copied.AllowedDevices = nil
+	}
+	copied.ScheduledLimits = nil // Loaded from the limit changes on the way out
+	if child.Birthdate != nil {
+		// Only the date is stored, like the SQLite backend
+		birthdate := core.LimitChangeDate(child.Birthdate.Date())
+		copied.Birthdate = &birthdate
+	}
+	return &copied
+}
+
+func cloneSession(session *core.Session) *core.Session {
+	copied := *session
+	copied.ChildIDs = append([]string(nil), session.ChildIDs...)
+	copied.LastBreakAt = copyTime(session.LastBreakAt)
+	copied.BreakEndsAt = copyTime(session.BreakEndsAt)
+	copied.WarningSentAt = copyTime(session.WarningSentAt)
+	copied.LastExtendedAt = copyTime(session.LastExtendedAt)
+	copied.LastActivityAt = copyTime(session.LastActivityAt)
+	copied.GraceEndsAt = copyTime(session.GraceEndsAt)
+	if session.ActualDuration != nil {
+		duration := *session.ActualDuration
+		copied.ActualDuration = &duration
+	}
+	copied.Grant = nil // not persisted
+	return &copied
+}
+
+func cloneBypass(bypass *core.DeviceBypass) *core.DeviceBypass {
+	copied := *bypass
+	copied.ExpiresAt = copyTime(bypass.ExpiresAt)
+	return &copied
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	copied := *t
+	return &copied
+}
+
+func containsID(ids []string, id string) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
+
+func removeID(ids []string, id string) []string {
+	kept := ids[:0]
+	for _, existing := range ids {
+		if existing != id {
+			kept = append(kept, existing)
+		}
+	}
+	return kept
+}
@@ -0,0 +1,101 @@
+package memory
+
+import (
+	"context"
+	"metron/internal/core"
+	"metron/internal/credentials"
+	"metron/internal/drivers/familylink"
+	"metron/internal/homekit"
+	"metron/internal/steam"
+	"metron/internal/storage"
+	"metron/internal/storage/storagetest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Compile-time interface checks
+var (
+	_ storage.Storage          = (*Storage)(nil)
+	_ credentials.Store        = (*Storage)(nil)
+	_ core.DowntimeSkipStorage = (*Storage)(nil)
+	_ core.AgentTokenStorage   = (*Storage)(nil)
+
+	_ familylink.UsageImportStorage = (*Storage)(nil)
+	_ steam.PlaytimeStorage         = (*Storage)(nil)
+	_ homekit.Storage               = (*Storage)(nil)
+)
+
+func TestStorage_Conformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Storage {
+		s := New(nil)
+		t.Cleanup(func() { s.Close() })
+		return s
+	})
+}
+
+func TestStorage_CopiesRecords(t *testing.T) {
+	s := New(nil)
+	ctx := context.Background()
+
+	child := &core.Child{ID: "alice", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 120, AllowedDevices: []string{"tv1"}}
+	require.NoError(t, s.CreateChild(ctx, child))
+	session := &core.Session{ID: "s1", DeviceType: "tv", DeviceID: "tv1", ChildIDs: []string{"alice"}, StartTime: time.Now(), ExpectedDuration: 30, Status: core.SessionStatusActive}
+	require.NoError(t, s.CreateSession(ctx, session))
+
+	// Changing the caller's values does not change the stored records
+	child.AllowedDevices[0] = "ps5"
+	session.Status = core.SessionStatusCompleted
+	session.ChildIDs[0] = "bob"
+
+	got, err := s.GetChild(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tv1"}, got.AllowedDevices)
+	stored, err := s.GetSession(ctx, "s1")
+	require.NoError(t, err)
+	assert.Equal(t, core.SessionStatusActive, stored.Status)
+	assert.Equal(t, []string{"alice"}, stored.ChildIDs)
+
+	// Neither does changing a returned value
+	stored.Status = core.SessionStatusExpired
+	again, err := s.GetSession(ctx, "s1")
+	require.NoError(t, err)
+	assert.Equal(t, core.SessionStatusActive, again.Status)
+}
+
+func TestStorage_DeleteChildCascades(t *testing.T) {
+	s := New(nil)
+	ctx := context.Background()
+	day := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+
+	for _, id := range []string{"alice", "bob"} {
+		require.NoError(t, s.CreateChild(ctx, &core.Child{ID: id, Name: id, WeekdayLimit: 60, WeekendLimit: 120}))
+	}
+	require.NoError(t, s.CreateSession(ctx, &core.Session{ID: "s1", DeviceType: "tv", ChildIDs: []string{"alice", "bob"}, StartTime: time.Now(), ExpectedDuration: 30, Status: core.SessionStatusActive}))
+	require.NoError(t, s.CreateDailyAllocation(ctx, &core.DailyTimeAllocation{ChildID: "alice", Date: day, BaseLimit: 60}))
+	require.NoError(t, s.IncrementDailyUsageSummary(ctx, "alice", day, 20))
+
+	require.NoError(t, s.DeleteChild(ctx, "alice"))
+
+	session, err := s.GetSession(ctx, "s1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob"}, session.ChildIDs)
+	_, err = s.GetDailyAllocation(ctx, "alice", day)
+	assert.ErrorIs(t, err, core.ErrAllocationNotFound)
+	summary, err := s.GetDailyUsageSummary(ctx, "alice", day)
+	require.NoError(t, err)
+	assert.Equal(t, 0, summary.MinutesUsed)
+
+	// Records for unknown children are rejected, like foreign keys in SQLite
+	assert.ErrorIs(t, s.IncrementDailyUsageSummary(ctx, "alice", day, 5), core.ErrChildNotFound)
+	assert.ErrorIs(t, s.CreateSession(ctx, &core.Session{ID: "s2", DeviceType: "tv", ChildIDs: []string{"alice"}, ExpectedDuration: 30}), core.ErrChildNotFound)
+}
+
+func TestStorage_Ping(t *testing.T) {
+	s := New(nil)
+	require.NoError(t, s.Ping(context.Background()))
+	require.NoError(t, s.Close())
+	assert.ErrorIs(t, s.Ping(context.Background()), ErrClosed)
+}
@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"metron/internal/core"
+	"sort"
+	"time"
+)
+
+// GetMovieTimeUsage retrieves movie time usage for a specific date (nil if none)
+func (s *Storage) GetMovieTimeUsage(ctx context.Context, date time.Time) (*core.MovieTimeUsage, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	usage, ok := s.movieTime[s.normalizeDate(date).Format("2006-01-02")]
+	if !ok {
+		return nil, nil
+	}
+	copied := *usage
+	copied.StartedAt = copyTime(usage.StartedAt)
+	return &copied, nil
+}
+
+// SaveMovieTimeUsage saves or updates movie time usage for a date
+func (s *Storage) SaveMovieTimeUsage(ctx context.Context, usage *core.MovieTimeUsage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := core.Now()
+	date := s.normalizeDate(usage.Date)
+	key := date.Format("2006-01-02")
+
+	saved := *usage
+	saved.Date = date
+	saved.StartedAt = copyTime(usage.StartedAt)
+	saved.CreatedAt = now
+	if existing, ok := s.movieTime[key]; ok {
+		saved.CreatedAt = existing.CreatedAt
+	}
+	saved.UpdatedAt = now
+	s.movieTime[key] = &saved
+	return nil
+}
+
+// CreateMovieTimeBypass creates a new movie time bypass period
+func (s *Storage) CreateMovieTimeBypass(ctx context.Context, bypass *core.MovieTimeBypass) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.movieBypasses[bypass.ID]; ok {
+		return fmt.Errorf("movie time bypass %s: %w", bypass.ID, ErrDuplicateID)
+	}
+
+	now := core.Now()
+	saved := *bypass
+	saved.StartDate = s.normalizeDate(bypass.StartDate)
+	saved.EndDate = s.normalizeDate(bypass.EndDate)
+	saved.CreatedAt = now
+	saved.UpdatedAt = now
+	s.movieBypasses[bypass.ID] = &saved
+	return nil
+}
+
+// GetMovieTimeBypass retrieves a movie time bypass by ID (nil if none)
+func (s *Storage) GetMovieTimeBypass(ctx context.Context, id string) (*core.MovieTimeBypass, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	bypass, ok := s.movieBypasses[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *bypass
+	return &copied, nil
+}
+
+// ListMovieTimeBypasses retrieves all movie time bypass periods, latest start first
+func (s *Storage) ListMovieTimeBypasses(ctx context.Context) ([]*core.MovieTimeBypass, error) {
+	return s.listMovieTimeBypasses(func(*core.MovieTimeBypass) bool { return true }), nil
+}
+
+// ListActiveMovieTimeBypasses retrieves bypass periods that include the date
+func (s *Storage) ListActiveMovieTimeBypasses(ctx context.Context, date time.Time) ([]*core.MovieTimeBypass, error) {
+	day := s.normalizeDate(date)
+	return s.listMovieTimeBypasses(func(bypass *core.MovieTimeBypass) bool {
+		return !bypass.StartDate.After(day) && !bypass.EndDate.Before(day)
+	}), nil
+}
+
+func (s *Storage) listMovieTimeBypasses(match func(*core.MovieTimeBypass) bool) []*core.MovieTimeBypass {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	bypasses := make([]*core.MovieTimeBypass, 0)
+	for _, bypass := range s.movieBypasses {
+		if match(bypass) {
+			copied := *bypass
+			bypasses = append(bypasses, &copied)
+		}
+	}
+	sort.Slice(bypasses, func(i, j int) bool {
+		return bypasses[i].StartDate.After(bypasses[j].StartDate)
+	})
+	return bypasses
+}
+
+// DeleteMovieTimeBypass deletes a movie time bypass
+func (s *Storage) DeleteMovieTimeBypass(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.movieBypasses, id)
+	return nil
+}
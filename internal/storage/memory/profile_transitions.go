@@ -0,0 +1,80 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"metron/internal/core"
+	"sort"
+)
+
+// CreateProfileTransition stores a limit profile transition
+// Transitions for unknown children are rejected, like foreign keys in SQLite
+func (s *Storage) CreateProfileTransition(ctx context.Context, transition *core.ProfileTransition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.children[transition.ChildID]; !ok {
+		return core.ErrChildNotFound
+	}
+	for _, existing := range s.transitions {
+		if existing.ID == transition.ID {
+			return fmt.Errorf("profile transition %s: %w", transition.ID, ErrDuplicateID)
+		}
+	}
+
+	s.transitions = append(s.transitions, cloneProfileTransition(transition))
+	return nil
+}
+
+// UpdateProfileTransition updates a limit profile transition (e.g., when it is confirmed)
+func (s *Storage) UpdateProfileTransition(ctx context.Context, transition *core.ProfileTransition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, existing := range s.transitions {
+		if existing.ID == transition.ID {
+			s.transitions[i] = cloneProfileTransition(transition)
+			return nil
+		}
+	}
+	return core.ErrProfileTransitionNotFound
+}
+
+// GetProfileTransition retrieves a limit profile transition by ID
+func (s *Storage) GetProfileTransition(ctx context.Context, id string) (*core.ProfileTransition, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, transition := range s.transitions {
+		if transition.ID == id {
+			return cloneProfileTransition(transition), nil
+		}
+	}
+	return nil, core.ErrProfileTransitionNotFound
+}
+
+// ListProfileTransitions retrieves limit profile transitions with a status (all if empty), oldest first
+func (s *Storage) ListProfileTransitions(ctx context.Context, status string) ([]*core.ProfileTransition, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var transitions []*core.ProfileTransition
+	for _, transition := range s.transitions {
+		if status == "" || transition.Status == status {
+			transitions = append(transitions, cloneProfileTransition(transition))
+		}
+	}
+	sort.SliceStable(transitions, func(i, j int) bool {
+		if !transitions[i].CreatedAt.Equal(transitions[j].CreatedAt) {
+			return transitions[i].CreatedAt.Before(transitions[j].CreatedAt)
+		}
+		return transitions[i].ID < transitions[j].ID
+	})
+	return transitions, nil
+}
+
+func cloneProfileTransition(transition *core.ProfileTransition) *core.ProfileTransition {
+	copied := *transition
+	copied.ResolvedAt = copyTime(transition.ResolvedAt)
+	return &copied
+}
@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"context"
+	"metron/internal/core"
+)
+
+// SavePushSubscription stores a subscription, replacing one with the same endpoint
+func (s *Storage) SavePushSubscription(ctx context.Context, subscription *core.PushSubscription) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copied := *subscription
+	kept := s.pushSubscriptions[:0]
+	for _, existing := range s.pushSubscriptions {
+		if existing.Endpoint != copied.Endpoint {
+			kept = append(kept, existing)
+		}
+	}
+	s.pushSubscriptions = append(kept, &copied)
+	return nil
+}
+
+// ListPushSubscriptions retrieves all subscriptions, oldest first
+func (s *Storage) ListPushSubscriptions(ctx context.Context) ([]*core.PushSubscription, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	subscriptions := make([]*core.PushSubscription, 0, len(s.pushSubscriptions))
+	for _, subscription := range s.pushSubscriptions {
+		copied := *subscription
+		subscriptions = append(subscriptions, &copied)
+	}
+	return subscriptions, nil
+}
+
+// DeletePushSubscription deletes a subscription
+func (s *Storage) DeletePushSubscription(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, subscription := range s.pushSubscriptions {
+		if subscription.ID == id {
+			s.pushSubscriptions = append(s.pushSubscriptions[:i], s.pushSubscriptions[i+1:]...)
+			return nil
+		}
+	}
+	return core.ErrPushSubscriptionNotFound
+}
@@ -0,0 +1,37 @@
+package memory
+
+import (
+	"context"
+	"time"
+)
+
+// sessionClaim is a claim on a session held by one process
+type sessionClaim struct {
+	owner     string
+	expiresAt time.Time
+}
+
+// ClaimSession claims a session for owner until the given time
+// Implements core.SessionClaimStorage interface
+func (s *Storage) ClaimSession(ctx context.Context, sessionID, owner string, until time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if claim, ok := s.sessionClaims[sessionID]; ok && claim.owner != owner && claim.expiresAt.After(time.Now()) {
+		return false, nil
+	}
+	s.sessionClaims[sessionID] = sessionClaim{owner: owner, expiresAt: until}
+	return true, nil
+}
+
+// ReleaseSessionClaim drops owner's claim on a session
+// Implements core.SessionClaimStorage interface
+func (s *Storage) ReleaseSessionClaim(ctx context.Context, sessionID, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if claim, ok := s.sessionClaims[sessionID]; ok && claim.owner == owner {
+		delete(s.sessionClaims, sessionID)
+	}
+	return nil
+}
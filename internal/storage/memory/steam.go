@@ -0,0 +1,31 @@
+package memory
+
+import (
+	"context"
+)
+
+// GetSteamPlaytime returns the last seen playtime minutes of a Steam account by app ID
+// Implements steam.PlaytimeStorage interface
+func (s *Storage) GetSteamPlaytime(ctx context.Context, steamID string) (map[string]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	playtime := make(map[string]int, len(s.steamPlaytime[steamID]))
+	for appID, minutes := range s.steamPlaytime[steamID] {
+		playtime[appID] = minutes
+	}
+	return playtime, nil
+}
+
+// SaveSteamPlaytime records the playtime minutes of a Steam account in a game
+// Implements steam.PlaytimeStorage interface
+func (s *Storage) SaveSteamPlaytime(ctx context.Context, steamID, appID string, minutes int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.steamPlaytime[steamID] == nil {
+		s.steamPlaytime[steamID] = make(map[string]int)
+	}
+	s.steamPlaytime[steamID][appID] = minutes
+	return nil
+}
@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"metron/internal/core"
+	"sort"
+	"time"
+)
+
+// CreateTamperEvent stores a tamper event reported by a device agent
+func (s *Storage) CreateTamperEvent(ctx context.Context, event *core.TamperEvent) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.tamperEvents {
+		if existing.ID == event.ID {
+			return ErrDuplicateID
+		}
+	}
+
+	copied := *event
+	s.tamperEvents = append(s.tamperEvents, &copied)
+	return nil
+}
+
+// ListTamperEvents retrieves the tamper events received after since, oldest first
+func (s *Storage) ListTamperEvents(ctx context.Context, since time.Time) ([]*core.TamperEvent, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var events []*core.TamperEvent
+	for _, event := range s.tamperEvents {
+		if event.ReceivedAt.After(since) {
+			copied := *event
+			events = append(events, &copied)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		if !events[i].ReceivedAt.Equal(events[j].ReceivedAt) {
+			return events[i].ReceivedAt.Before(events[j].ReceivedAt)
+		}
+		return events[i].ID < events[j].ID
+	})
+	return events, nil
+}
@@ -0,0 +1,59 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"metron/internal/core"
+	"sort"
+)
+
+// ListActiveTrackingPauses retrieves pauses that have not been resumed, newest first
+func (s *Storage) ListActiveTrackingPauses(ctx context.Context) ([]*core.TrackingPause, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pauses := make([]*core.TrackingPause, 0)
+	for _, pause := range s.trackingPauses {
+		if pause.ResumedAt == nil {
+			pauses = append(pauses, cloneTrackingPause(pause))
+		}
+	}
+	sort.Slice(pauses, func(i, j int) bool {
+		return pauses[i].StartedAt.After(pauses[j].StartedAt)
+	})
+	return pauses, nil
+}
+
+// CreateTrackingPause records a new tracking pause
+func (s *Storage) CreateTrackingPause(ctx context.Context, pause *core.TrackingPause) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.trackingPauses[pause.ID]; ok {
+		return fmt.Errorf("tracking pause %s: %w", pause.ID, ErrDuplicateID)
+	}
+	s.trackingPauses[pause.ID] = cloneTrackingPause(pause)
+	return nil
+}
+
+// UpdateTrackingPause updates the resume details of a tracking pause
+func (s *Storage) UpdateTrackingPause(ctx context.Context, pause *core.TrackingPause) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.trackingPauses[pause.ID]
+	if !ok {
+		return nil
+	}
+	existing.ResumesAt = copyTime(pause.ResumesAt)
+	existing.ResumedAt = copyTime(pause.ResumedAt)
+	existing.ResumedBy = pause.ResumedBy
+	return nil
+}
+
+func cloneTrackingPause(pause *core.TrackingPause) *core.TrackingPause {
+	copied := *pause
+	copied.ResumesAt = copyTime(pause.ResumesAt)
+	copied.ResumedAt = copyTime(pause.ResumedAt)
+	return &copied
+}
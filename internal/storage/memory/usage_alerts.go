@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"metron/internal/core"
+	"sort"
+	"time"
+)
+
+// CreateUsageAlert stores a usage threshold alert
+// A child is alerted once per threshold and day, like the unique index of the SQLite backend
+func (s *Storage) CreateUsageAlert(ctx context.Context, alert *core.UsageAlert) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	date := s.normalizeDate(alert.Date)
+	for _, existing := range s.usageAlerts {
+		if existing.ID == alert.ID {
+			return fmt.Errorf("usage alert %s: %w", alert.ID, ErrDuplicateID)
+		}
+		if existing.ChildID == alert.ChildID && existing.Date.Equal(date) && existing.Threshold == alert.Threshold {
+			return fmt.Errorf("usage alert for threshold %d: %w", alert.Threshold, ErrDuplicateID)
+		}
+	}
+
+	copied := *alert
+	copied.Date = date
+	s.usageAlerts = append(s.usageAlerts, &copied)
+	return nil
+}
+
+// ListUsageAlerts retrieves the usage alerts created after since, oldest first
+func (s *Storage) ListUsageAlerts(ctx context.Context, since time.Time) ([]*core.UsageAlert, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var alerts []*core.UsageAlert
+	for _, alert := range s.usageAlerts {
+		if alert.CreatedAt.After(since) {
+			copied := *alert
+			alerts = append(alerts, &copied)
+		}
+	}
+	sort.SliceStable(alerts, func(i, j int) bool {
+		if !alerts[i].CreatedAt.Equal(alerts[j].CreatedAt) {
+			return alerts[i].CreatedAt.Before(alerts[j].CreatedAt)
+		}
+		return alerts[i].ID < alerts[j].ID
+	})
+	return alerts, nil
+}
+
+// MaxUsageAlertThreshold returns the highest threshold alerted for a child on a date, 0 if none
+func (s *Storage) MaxUsageAlertThreshold(ctx context.Context, childID string, date time.Time) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	date = s.normalizeDate(date)
+	threshold := 0
+	for _, alert := range s.usageAlerts {
+		if alert.ChildID == childID && alert.Date.Equal(date) && alert.Threshold > threshold {
+			threshold = alert.Threshold
+		}
+	}
+	return threshold, nil
+}
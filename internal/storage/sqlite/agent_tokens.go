@@ -0,0 +1,123 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"metron/internal/core"
+)
+
+// CreateAgentToken records a newly issued agent token
+func (s *SQLiteStorage) CreateAgentToken(ctx context.Context, token *core.AgentToken) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO agent_tokens (id, device_id, label, token_hash, hint, issued_by, created_at, last_used_at, revoked_at, revoked_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, token.ID, token.DeviceID, token.Label, token.TokenHash, token.Hint, token.IssuedBy, token.CreatedAt,
+		nullTime(token.LastUsedAt), nullTime(token.RevokedAt), token.RevokedBy)
+
+	return err
+}
+
+// GetAgentToken retrieves an agent token by ID
+func (s *SQLiteStorage) GetAgentToken(ctx context.Context, id string) (*core.AgentToken, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT id, device_id, label, token_hash, hint, issued_by, created_at, last_used_at, revoked_at, revoked_by
+		FROM agent_tokens
+		WHERE id = ?
+	`, id)
+
+	token, err := scanAgentToken(row)
+	if err == sql.ErrNoRows {
+		return nil, core.ErrAgentTokenNotFound
+	}
+	return token, err
+}
+
+// GetAgentTokenByHash retrieves an agent token by the hash of its token
+func (s *SQLiteStorage) GetAgentTokenByHash(ctx context.Context, tokenHash string) (*core.AgentToken, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT id, device_id, label, token_hash, hint, issued_by, created_at, last_used_at, revoked_at, revoked_by
+		FROM agent_tokens
+		WHERE token_hash = ?
+	`, tokenHash)
+
+	token, err := scanAgentToken(row)
+	if err == sql.ErrNoRows {
+		return nil, core.ErrAgentTokenNotFound
+	}
+	return token, err
+}
+
+// ListAgentTokens retrieves all agent tokens, newest first
+func (s *SQLiteStorage) ListAgentTokens(ctx context.Context) ([]*core.AgentToken, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, device_id, label, token_hash, hint, issued_by, created_at, last_used_at, revoked_at, revoked_by
+		FROM agent_tokens
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []*core.AgentToken
+	for rows.Next() {
+		token, err := scanAgentToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// UpdateAgentToken updates the last-used and revocation details of an agent token
+func (s *SQLiteStorage) UpdateAgentToken(ctx context.Context, token *core.AgentToken) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE agent_tokens
+		SET last_used_at = ?, revoked_at = ?, revoked_by = ?
+		WHERE id = ?
+	`, nullTime(token.LastUsedAt), nullTime(token.RevokedAt), token.RevokedBy, token.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return core.ErrAgentTokenNotFound
+	}
+
+	return nil
+}
+
+// scanAgentToken scans an agent token row from either *sql.Row or *sql.Rows
+func scanAgentToken(scanner interface{ Scan(dest ...any) error }) (*core.AgentToken, error) {
+	var token core.AgentToken
+	var label sql.NullString
+	var lastUsedAt sql.NullTime
+	var revokedAt sql.NullTime
+	var revokedBy sql.NullString
+
+	if err := scanner.Scan(&token.ID, &token.DeviceID, &label, &token.TokenHash, &token.Hint, &token.IssuedBy,
+		&token.CreatedAt, &lastUsedAt, &revokedAt, &revokedBy); err != nil {
+		return nil, err
+	}
+
+	if label.Valid {
+		token.Label = label.String
+	}
+	if lastUsedAt.Valid {
+		token.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	if revokedBy.Valid {
+		token.RevokedBy = revokedBy.String
+	}
+
+	return &token, nil
+}
@@ -0,0 +1,50 @@
+package sqlite
+
+import (
+	"context"
+	"metron/internal/core"
+	"time"
+)
+
+// SaveAppUsage replaces the minutes of a child's app on a day, as reported by a source
+func (s *SQLiteStorage) SaveAppUsage(ctx context.Context, usage *core.AppUsage) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO app_usage (child_id, date, source, app, minutes, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(child_id, date, source, app) DO UPDATE SET
+			minutes = excluded.minutes,
+			updated_at = excluded.updated_at
+	`, usage.ChildID, s.normalizeDate(usage.Date).Format("2006-01-02"), usage.Source, usage.App, usage.Minutes, usage.UpdatedAt.UTC())
+
+	return err
+}
+
+// ListAppUsage retrieves the app usage of the days from..to (inclusive), oldest first
+// An empty childID lists every child's usage
+func (s *SQLiteStorage) ListAppUsage(ctx context.Context, childID string, from, to time.Time) ([]*core.AppUsage, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT child_id, date, source, app, minutes, updated_at
+		FROM app_usage
+		WHERE (? = '' OR child_id = ?) AND date >= ? AND date <= ?
+		ORDER BY date, child_id, source, app
+	`, childID, childID, s.normalizeDate(from).Format("2006-01-02"), s.normalizeDate(to).Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var usage []*core.AppUsage
+	for rows.Next() {
+		var entry core.AppUsage
+		var date string
+		if err := rows.Scan(&entry.ChildID, &date, &entry.Source, &entry.App, &entry.Minutes, &entry.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if entry.Date, err = time.ParseInLocation("2006-01-02", date, s.timezone); err != nil {
+			return nil, err
+		}
+		usage = append(usage, &entry)
+	}
+
+	return usage, rows.Err()
+}
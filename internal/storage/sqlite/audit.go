@@ -0,0 +1,49 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"metron/internal/core"
+)
+
+// CreateAuditEntry stores an audit log entry
+func (s *SQLiteStorage) CreateAuditEntry(ctx context.Context, entry *core.AuditEntry) error {
+	// Times are stored in UTC so created_at sorts correctly as text
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO audit_log (id, action, child_id, actor, details, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, entry.ID, entry.Action, entry.ChildID, entry.Actor, entry.Details, entry.CreatedAt.UTC())
+
+	return err
+}
+
+// ListAuditEntries retrieves the latest audit log entries, newest first, for one child or all (empty childID)
+func (s *SQLiteStorage) ListAuditEntries(ctx context.Context, childID string, limit int) ([]*core.AuditEntry, error) {
+	query := `SELECT id, action, child_id, actor, details, created_at FROM audit_log`
+	var args []interface{}
+	if childID != "" {
+		query += ` WHERE child_id = ?`
+		args = append(args, childID)
+	}
+	query += ` ORDER BY created_at DESC, id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*core.AuditEntry
+	for rows.Next() {
+		var entry core.AuditEntry
+		var details sql.NullString
+		if err := rows.Scan(&entry.ID, &entry.Action, &entry.ChildID, &entry.Actor, &details, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+		entry.Details = details.String
+		entries = append(entries, &entry)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,148 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"metron/internal/core"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Benchmarks of the hot paths, with their prepared statements and with the same SQL
+// parsed on every call (as before the statements were prepared)
+// Run with: go test ./internal/storage/sqlite -run '^$' -bench . -benchmem
+
+// setupBenchDB creates a database with a few children and a history of ended sessions,
+// plus two active sessions
+func setupBenchDB(b *testing.B) *SQLiteStorage {
+	b.Helper()
+	storage, err := New(filepath.Join(b.TempDir(), "bench.db"), nil)
+	require.NoError(b, err)
+	b.Cleanup(func() { storage.Close() })
+
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		require.NoError(b, storage.CreateChild(ctx, &core.Child{ID: fmt.Sprintf("child%d", i), Name: "Child", WeekdayLimit: 60, WeekendLimit: 90}))
+	}
+	start := time.Date(2026, time.January, 1, 17, 0, 0, 0, time.UTC)
+	for i := 0; i < 500; i++ {
+		status := core.SessionStatusCompleted
+		if i >= 498 {
+			status = core.SessionStatusActive
+		}
+		require.NoError(b, storage.CreateSession(ctx, &core.Session{
+			ID:               fmt.Sprintf("session%d", i),
+			DeviceType:       "tv",
+			DeviceID:         "tv1",
+			ChildIDs:         []string{fmt.Sprintf("child%d", i%3)},
+			StartTime:        start.Add(time.Duration(i) * 6 * time.Hour),
+			ExpectedDuration: 30,
+			Status:           status,
+		}))
+	}
+	return storage
+}
+
+func BenchmarkGetSession(b *testing.B) {
+	storage := setupBenchDB(b)
+	ctx := context.Background()
+
+	b.Run("prepared", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := storage.GetSession(ctx, "session250"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("unprepared", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			var id string
+			var ignored [21]any
+			dest := []any{&id}
+			for j := range ignored {
+				dest = append(dest, &ignored[j])
+			}
+			if err := storage.db.QueryRowContext(ctx, getSessionQuery, "session250").Scan(dest...); err != nil {
+				b.Fatal(err)
+			}
+			rows, err := storage.db.QueryContext(ctx, listSessionChildrenQuery, id)
+			if err != nil {
+				b.Fatal(err)
+			}
+			for rows.Next() {
+			}
+			rows.Close()
+		}
+	})
+}
+
+func BenchmarkListActiveSessions(b *testing.B) {
+	storage := setupBenchDB(b)
+	ctx := context.Background()
+
+	b.Run("prepared", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			sessions, err := storage.ListActiveSessions(ctx)
+			if err != nil || len(sessions) != 2 {
+				b.Fatal(len(sessions), err)
+			}
+		}
+	})
+	b.Run("unprepared", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			rows, err := storage.db.QueryContext(ctx, listActiveSessionsQuery, core.SessionStatusActive, core.SessionStatusPaused)
+			if err != nil {
+				b.Fatal(err)
+			}
+			var ids []string
+			for rows.Next() {
+				var id string
+				var ignored [21]any
+				dest := []any{&id}
+				for j := range ignored {
+					dest = append(dest, &ignored[j])
+				}
+				if err := rows.Scan(dest...); err != nil {
+					b.Fatal(err)
+				}
+				ids = append(ids, id)
+			}
+			rows.Close()
+			for _, id := range ids {
+				childRows, err := storage.db.QueryContext(ctx, listSessionChildrenQuery, id)
+				if err != nil {
+					b.Fatal(err)
+				}
+				for childRows.Next() {
+				}
+				childRows.Close()
+			}
+		}
+	})
+}
+
+func BenchmarkIncrementDailyUsageSummary(b *testing.B) {
+	storage := setupBenchDB(b)
+	ctx := context.Background()
+	date := time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC)
+
+	b.Run("prepared", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if err := storage.IncrementDailyUsageSummary(ctx, "child0", date, 1); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("unprepared", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			now := time.Now()
+			if _, err := storage.db.ExecContext(ctx, incrementDailyUsageQuery, "child1", storage.normalizeDate(date), 1, now, now, 1, now); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package sqlite
+
+import (
+	"context"
+	"metron/internal/core"
+)
+
+// CreateChildActivity stores an entry of a child's activity feed
+func (s *SQLiteStorage) CreateChildActivity(ctx context.Context, activity *core.ChildActivity) error {
+	// Times are stored in UTC so created_at sorts correctly as text
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO child_activity (id, child_id, kind, message, session_id, minutes, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, activity.ID, activity.ChildID, activity.Kind, activity.Message, activity.SessionID, activity.Minutes, activity.CreatedAt.UTC())
+
+	return err
+}
+
+// ListChildActivity retrieves a child's latest activity, newest first
+func (s *SQLiteStorage) ListChildActivity(ctx context.Context, childID string, limit int) ([]*core.ChildActivity, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, child_id, kind, message, session_id, minutes, created_at
+		FROM child_activity
+		WHERE child_id = ?
+		ORDER BY created_at DESC, id DESC
+		LIMIT ?
+	`, childID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var activities []*core.ChildActivity
+	for rows.Next() {
+		var activity core.ChildActivity
+		if err := rows.Scan(&activity.ID, &activity.ChildID, &activity.Kind, &activity.Message, &activity.SessionID, &activity.Minutes, &activity.CreatedAt); err != nil {
+			return nil, err
+		}
+		activities = append(activities, &activity)
+	}
+
+	return activities, rows.Err()
+}
@@ -0,0 +1,128 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"metron/internal/core"
+	"time"
+)
+
+// CreateChildLogin records a child's login to the child web app
+func (s *SQLiteStorage) CreateChildLogin(ctx context.Context, login *core.ChildLogin) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO child_logins (id, child_id, token_hash, user_agent, created_at, expires_at, last_seen_at, revoked_at, revoked_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, login.ID, login.ChildID, login.TokenHash, login.UserAgent, login.CreatedAt.UTC(), login.ExpiresAt.UTC(),
+		nullTime(login.LastSeenAt), nullTime(login.RevokedAt), login.RevokedBy)
+
+	return err
+}
+
+// GetChildLogin retrieves a child login by ID
+func (s *SQLiteStorage) GetChildLogin(ctx context.Context, id string) (*core.ChildLogin, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT id, child_id, token_hash, user_agent, created_at, expires_at, last_seen_at, revoked_at, revoked_by
+		FROM child_logins
+		WHERE id = ?
+	`, id)
+
+	login, err := scanChildLogin(row)
+	if err == sql.ErrNoRows {
+		return nil, core.ErrChildLoginNotFound
+	}
+	return login, err
+}
+
+// GetChildLoginByHash retrieves a child login by the hash of its token
+func (s *SQLiteStorage) GetChildLoginByHash(ctx context.Context, tokenHash string) (*core.ChildLogin, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT id, child_id, token_hash, user_agent, created_at, expires_at, last_seen_at, revoked_at, revoked_by
+		FROM child_logins
+		WHERE token_hash = ?
+	`, tokenHash)
+
+	login, err := scanChildLogin(row)
+	if err == sql.ErrNoRows {
+		return nil, core.ErrChildLoginNotFound
+	}
+	return login, err
+}
+
+// ListChildLogins retrieves the logins of a child (all children if childID is empty), newest first
+func (s *SQLiteStorage) ListChildLogins(ctx context.Context, childID string) ([]*core.ChildLogin, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, child_id, token_hash, user_agent, created_at, expires_at, last_seen_at, revoked_at, revoked_by
+		FROM child_logins
+		WHERE ? = '' OR child_id = ?
+		ORDER BY created_at DESC
+	`, childID, childID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var logins []*core.ChildLogin
+	for rows.Next() {
+		login, err := scanChildLogin(rows)
+		if err != nil {
+			return nil, err
+		}
+		logins = append(logins, login)
+	}
+
+	return logins, rows.Err()
+}
+
+// UpdateChildLogin updates the last-seen and revocation details of a child login
+func (s *SQLiteStorage) UpdateChildLogin(ctx context.Context, login *core.ChildLogin) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE child_logins
+		SET last_seen_at = ?, revoked_at = ?, revoked_by = ?
+		WHERE id = ?
+	`, nullTime(login.LastSeenAt), nullTime(login.RevokedAt), login.RevokedBy, login.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return core.ErrChildLoginNotFound
+	}
+
+	return nil
+}
+
+// DeleteExpiredChildLogins deletes the logins that expired before the given time
+func (s *SQLiteStorage) DeleteExpiredChildLogins(ctx context.Context, before time.Time) (int, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM child_logins WHERE expires_at < ?`, before.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := result.RowsAffected()
+	return int(rows), err
+}
+
+// scanChildLogin scans a child login row from either *sql.Row or *sql.Rows
+func scanChildLogin(scanner interface{ Scan(dest ...any) error }) (*core.ChildLogin, error) {
+	var login core.ChildLogin
+	var lastSeenAt sql.NullTime
+	var revokedAt sql.NullTime
+
+	if err := scanner.Scan(&login.ID, &login.ChildID, &login.TokenHash, &login.UserAgent, &login.CreatedAt,
+		&login.ExpiresAt, &lastSeenAt, &revokedAt, &login.RevokedBy); err != nil {
+		return nil, err
+	}
+
+	if lastSeenAt.Valid {
+		login.LastSeenAt = &lastSeenAt.Time
+	}
+	if revokedAt.Valid {
+		login.RevokedAt = &revokedAt.Time
+	}
+
+	return &login, nil
+}
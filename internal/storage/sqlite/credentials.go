@@ -0,0 +1,151 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"metron/internal/credentials"
+	"metron/internal/drivers/aqara"
+	"time"
+)
+
+// aqaraCredential is the credentials row of the Aqara driver's tokens (see aqara.TokenStore)
+const aqaraCredential = "aqara"
+
+// SetCredentialCipher encrypts credentials with the cipher from now on and encrypts the
+// credentials still stored in plaintext (e.g. saved before a key was configured)
+// Without a cipher, credentials are stored in plaintext.
+func (s *SQLiteStorage) SetCredentialCipher(ctx context.Context, cipher *credentials.Cipher) error {
+	s.cipher = cipher
+	if cipher == nil {
+		return nil
+	}
+
+	rows, err := s.db.QueryContext(ctx, `SELECT driver FROM credentials WHERE key_id = ''`)
+	if err != nil {
+		return err
+	}
+	var plaintext []string
+	for rows.Next() {
+		var driver string
+		if err := rows.Scan(&driver); err != nil {
+			rows.Close()
+			return err
+		}
+		plaintext = append(plaintext, driver)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, driver := range plaintext {
+		data, err := s.GetCredential(ctx, driver)
+		if err != nil {
+			return err
+		}
+		if err := s.SaveCredential(ctx, driver, data); err != nil {
+			return fmt.Errorf("failed to encrypt %s credentials: %w", driver, err)
+		}
+	}
+	return nil
+}
+
+// GetCredential returns a driver's decrypted credential (nil if none)
+// Implements credentials.Store interface
+func (s *SQLiteStorage) GetCredential(ctx context.Context, driver string) ([]byte, error) {
+	var sealed credentials.Sealed
+	err := s.db.QueryRowContext(ctx, `
+		SELECT key_id, wrapped_key, data FROM credentials WHERE driver = ?
+	`, driver).Scan(&sealed.KeyID, &sealed.WrappedKey, &sealed.Data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if sealed.KeyID == "" {
+		return sealed.Data, nil
+	}
+	if s.cipher == nil {
+		return nil, fmt.Errorf("%s credentials: %w", driver, credentials.ErrNoKey)
+	}
+	return s.cipher.Open(&sealed, []byte(driver))
+}
+
+// SaveCredential stores a driver's credential, encrypted if a cipher is set
+// Implements credentials.Store interface
+func (s *SQLiteStorage) SaveCredential(ctx context.Context, driver string, data []byte) error {
+	sealed := &credentials.Sealed{Data: data, WrappedKey: []byte{}}
+	if s.cipher != nil {
+		var err error
+		if sealed, err = s.cipher.Seal(data, []byte(driver)); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now().UTC()
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO credentials (driver, key_id, wrapped_key, data, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(driver) DO UPDATE SET
+			key_id = excluded.key_id,
+			wrapped_key = excluded.wrapped_key,
+			data = excluded.data,
+			updated_at = excluded.updated_at
+	`, driver, sealed.KeyID, sealed.WrappedKey, sealed.Data, now, now)
+
+	return err
+}
+
+// migrateAqaraTokens moves the tokens of the old aqara_tokens table into credentials
+// They are stored in plaintext until SetCredentialCipher encrypts them.
+func (s *SQLiteStorage) migrateAqaraTokens() error {
+	ctx := context.Background()
+
+	var tokens aqara.AqaraTokens
+	var accessToken sql.NullString
+	var expiresAt sql.NullTime
+	err := s.db.QueryRowContext(ctx, `
+		SELECT refresh_token, access_token, access_token_expires_at, created_at, updated_at
+		FROM aqara_tokens WHERE id = 1
+	`).Scan(&tokens.RefreshToken, &accessToken, &expiresAt, &tokens.CreatedAt, &tokens.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	tokens.AccessToken = accessToken.String
+	if expiresAt.Valid {
+		tokens.AccessTokenExpiresAt = &expiresAt.Time
+	}
+
+	data, err := json.Marshal(&tokens)
+	if err != nil {
+		return err
+	}
+
+	// Tokens saved since (already in credentials) win over the old row
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO credentials (driver, key_id, wrapped_key, data, created_at, updated_at)
+		VALUES (?, '', X'', ?, ?, ?)
+		ON CONFLICT(driver) DO NOTHING
+	`, aqaraCredential, data, now, now); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM aqara_tokens`); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,92 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"metron/internal/core"
+	"time"
+)
+
+// CreateDayRollover stores the close-out of a child's day
+func (s *SQLiteStorage) CreateDayRollover(ctx context.Context, rollover *core.DayRollover) error {
+	// Times are stored in UTC so created_at compares correctly as text
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO day_rollovers (id, child_id, date, limit_minutes, used_minutes, session_count, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, rollover.ID, rollover.ChildID, s.normalizeDate(rollover.Date).Format("2006-01-02"), rollover.LimitMinutes, rollover.UsedMinutes, rollover.SessionCount, rollover.CreatedAt.UTC())
+
+	return err
+}
+
+// GetDayRollover retrieves the close-out of a child's day
+func (s *SQLiteStorage) GetDayRollover(ctx context.Context, childID string, date time.Time) (*core.DayRollover, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT id, child_id, date, limit_minutes, used_minutes, session_count, created_at
+		FROM day_rollovers
+		WHERE child_id = ? AND date = ?
+	`, childID, s.normalizeDate(date).Format("2006-01-02"))
+
+	rollover, err := s.scanDayRollover(row)
+	if err == sql.ErrNoRows {
+		return nil, core.ErrDayRolloverNotFound
+	}
+	return rollover, err
+}
+
+// UpdateDayRollover updates the used minutes and session count of a closed-out day
+func (s *SQLiteStorage) UpdateDayRollover(ctx context.Context, rollover *core.DayRollover) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE day_rollovers SET used_minutes = ?, session_count = ? WHERE id = ?
+	`, rollover.UsedMinutes, rollover.SessionCount, rollover.ID)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return core.ErrDayRolloverNotFound
+	}
+	return nil
+}
+
+// ListDayRollovers retrieves the day rollovers created after since, oldest first
+func (s *SQLiteStorage) ListDayRollovers(ctx context.Context, since time.Time) ([]*core.DayRollover, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, child_id, date, limit_minutes, used_minutes, session_count, created_at
+		FROM day_rollovers
+		WHERE created_at > ?
+		ORDER BY created_at, id
+	`, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rollovers []*core.DayRollover
+	for rows.Next() {
+		rollover, err := s.scanDayRollover(rows)
+		if err != nil {
+			return nil, err
+		}
+		rollovers = append(rollovers, rollover)
+	}
+
+	return rollovers, rows.Err()
+}
+
+// scanDayRollover scans a day_rollovers row
+func (s *SQLiteStorage) scanDayRollover(scanner interface{ Scan(dest ...any) error }) (*core.DayRollover, error) {
+	var rollover core.DayRollover
+	var date string
+	if err := scanner.Scan(&rollover.ID, &rollover.ChildID, &date, &rollover.LimitMinutes, &rollover.UsedMinutes, &rollover.SessionCount, &rollover.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	var err error
+	if rollover.Date, err = time.ParseInLocation("2006-01-02", date, s.timezone); err != nil {
+		return nil, err
+	}
+	return &rollover, nil
+}
@@ -0,0 +1,102 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"metron/internal/core"
+)
+
+// ListActiveDowntimeOverrides retrieves the downtime overrides that have not ended yet, oldest first
+// Overrides past their expires_at are included; the caller decides whether they still apply
+func (s *SQLiteStorage) ListActiveDowntimeOverrides(ctx context.Context) ([]*core.DowntimeOverride, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, child_id, session_id, reason, granted_by, started_at, expires_at, ended_at, ended_by
+		FROM downtime_overrides
+		WHERE ended_at IS NULL
+		ORDER BY started_at ASC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var overrides []*core.DowntimeOverride
+	for rows.Next() {
+		override, err := scanDowntimeOverride(rows)
+		if err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, override)
+	}
+
+	return overrides, rows.Err()
+}
+
+// GetDowntimeOverride retrieves a downtime override by ID
+func (s *SQLiteStorage) GetDowntimeOverride(ctx context.Context, id string) (*core.DowntimeOverride, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT id, child_id, session_id, reason, granted_by, started_at, expires_at, ended_at, ended_by
+		FROM downtime_overrides
+		WHERE id = ?
+	`, id)
+
+	override, err := scanDowntimeOverride(row)
+	if err == sql.ErrNoRows {
+		return nil, core.ErrDowntimeOverrideNotFound
+	}
+	return override, err
+}
+
+// CreateDowntimeOverride records a new downtime override
+func (s *SQLiteStorage) CreateDowntimeOverride(ctx context.Context, override *core.DowntimeOverride) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO downtime_overrides (id, child_id, session_id, reason, granted_by, started_at, expires_at, ended_at, ended_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, override.ID, override.ChildID, override.SessionID, override.Reason, override.GrantedBy, override.StartedAt.UTC(),
+		nullTime(override.ExpiresAt), nullTime(override.EndedAt), override.EndedBy)
+
+	return err
+}
+
+// UpdateDowntimeOverride updates the end details of a downtime override
+func (s *SQLiteStorage) UpdateDowntimeOverride(ctx context.Context, override *core.DowntimeOverride) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE downtime_overrides
+		SET ended_at = ?, ended_by = ?
+		WHERE id = ?
+	`, nullTime(override.EndedAt), override.EndedBy, override.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return core.ErrDowntimeOverrideNotFound
+	}
+
+	return nil
+}
+
+// scanDowntimeOverride scans a downtime override row from either *sql.Row or *sql.Rows
+func scanDowntimeOverride(scanner interface{ Scan(dest ...any) error }) (*core.DowntimeOverride, error) {
+	var override core.DowntimeOverride
+	var expiresAt sql.NullTime
+	var endedAt sql.NullTime
+
+	if err := scanner.Scan(&override.ID, &override.ChildID, &override.SessionID, &override.Reason, &override.GrantedBy,
+		&override.StartedAt, &expiresAt, &endedAt, &override.EndedBy); err != nil {
+		return nil, err
+	}
+
+	if expiresAt.Valid {
+		override.ExpiresAt = &expiresAt.Time
+	}
+	if endedAt.Valid {
+		override.EndedAt = &endedAt.Time
+	}
+
+	return &override, nil
+}
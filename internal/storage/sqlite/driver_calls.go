@@ -0,0 +1,58 @@
+package sqlite
+
+import (
+	"context"
+	"metron/internal/core"
+	"time"
+)
+
+// CreateDriverCall records a driver call
+func (s *SQLiteStorage) CreateDriverCall(ctx context.Context, call *core.DriverCall) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO driver_calls (id, driver, device_id, session_id, action, started_at, duration_ms, result, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, call.ID, call.Driver, call.DeviceID, call.SessionID, call.Action, call.StartedAt.UTC(),
+		call.Duration.Milliseconds(), call.Result, call.Error)
+
+	return err
+}
+
+// ListDriverCalls retrieves the recorded driver calls, newest first
+func (s *SQLiteStorage) ListDriverCalls(ctx context.Context) ([]*core.DriverCall, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, driver, device_id, session_id, action, started_at, duration_ms, result, error
+		FROM driver_calls
+		ORDER BY started_at DESC, rowid DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var calls []*core.DriverCall
+	for rows.Next() {
+		var call core.DriverCall
+		var durationMs int64
+		if err := rows.Scan(&call.ID, &call.Driver, &call.DeviceID, &call.SessionID, &call.Action,
+			&call.StartedAt, &durationMs, &call.Result, &call.Error); err != nil {
+			return nil, err
+		}
+		call.Duration = time.Duration(durationMs) * time.Millisecond
+		calls = append(calls, &call)
+	}
+
+	return calls, rows.Err()
+}
+
+// PruneDriverCalls deletes all but the newest keep driver calls
+func (s *SQLiteStorage) PruneDriverCalls(ctx context.Context, keep int) error {
+	_, err := s.db.ExecContext(ctx, `
+		DELETE FROM driver_calls WHERE rowid IN (
+			SELECT rowid FROM driver_calls
+			ORDER BY started_at DESC, rowid DESC
+			LIMIT -1 OFFSET ?
+		)
+	`, keep)
+
+	return err
+}
@@ -0,0 +1,136 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"metron/internal/core"
+	"slices"
+	"time"
+)
+
+// CreateDriverJob stores a driver call waiting to be made
+func (s *SQLiteStorage) CreateDriverJob(ctx context.Context, job *core.DriverJob) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO driver_jobs (id, kind, session_id, minutes, attempts, last_error, created_at, owner, claimed_until)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, job.ID, job.Kind, job.SessionID, job.Minutes, job.Attempts, job.LastError, job.CreatedAt.UTC(), job.Owner, job.ClaimedUntil.UTC())
+
+	return err
+}
+
+// UpdateDriverJob records a failed attempt of a driver job
+func (s *SQLiteStorage) UpdateDriverJob(ctx context.Context, job *core.DriverJob) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE driver_jobs SET attempts = ?, last_error = ? WHERE id = ?
+	`, job.Attempts, job.LastError, job.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return core.ErrDriverJobNotFound
+	}
+	return nil
+}
+
+// DeleteDriverJob removes a driver job that is done or dropped
+func (s *SQLiteStorage) DeleteDriverJob(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM driver_jobs WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return core.ErrDriverJobNotFound
+	}
+	return nil
+}
+
+// ListDriverJobs retrieves the stored driver jobs, oldest first
+func (s *SQLiteStorage) ListDriverJobs(ctx context.Context) ([]*core.DriverJob, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, kind, session_id, minutes, attempts, last_error, created_at, owner, claimed_until
+		FROM driver_jobs
+		ORDER BY created_at, rowid
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanDriverJobs(rows)
+}
+
+// ClaimDriverJobs renews owner's claims and claims the unclaimed and expired jobs for owner
+// Implements core.DriverJobStorage interface
+func (s *SQLiteStorage) ClaimDriverJobs(ctx context.Context, owner string, until time.Time) ([]*core.DriverJob, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Times are stored in UTC so claimed_until compares correctly as text
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE driver_jobs SET claimed_until = ? WHERE owner = ?
+	`, until.UTC(), owner); err != nil {
+		return nil, err
+	}
+
+	// A single UPDATE takes each job, so two instances never claim the same one
+	rows, err := tx.QueryContext(ctx, `
+		UPDATE driver_jobs SET owner = ?, claimed_until = ?
+		WHERE owner <> ? AND claimed_until <= ?
+		RETURNING id, kind, session_id, minutes, attempts, last_error, created_at, owner, claimed_until
+	`, owner, until.UTC(), owner, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := scanDriverJobs(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING has no order
+	slices.SortStableFunc(jobs, func(a, b *core.DriverJob) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return jobs, nil
+}
+
+// ReleaseDriverJobs drops owner's claims
+// Implements core.DriverJobStorage interface
+func (s *SQLiteStorage) ReleaseDriverJobs(ctx context.Context, owner string) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE driver_jobs SET owner = '', claimed_until = ? WHERE owner = ?
+	`, time.Time{}.UTC(), owner)
+
+	return err
+}
+
+func scanDriverJobs(rows *sql.Rows) ([]*core.DriverJob, error) {
+	var jobs []*core.DriverJob
+	for rows.Next() {
+		var job core.DriverJob
+		if err := rows.Scan(&job.ID, &job.Kind, &job.SessionID, &job.Minutes, &job.Attempts, &job.LastError, &job.CreatedAt,
+			&job.Owner, &job.ClaimedUntil); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, &job)
+	}
+
+	return jobs, rows.Err()
+}
@@ -0,0 +1,35 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+// GetFamilyLinkUsage returns the Family Link minutes already charged for a device on a date
+// Implements familylink.UsageImportStorage interface
+func (s *SQLiteStorage) GetFamilyLinkUsage(ctx context.Context, deviceID string, date time.Time) (int, error) {
+	var minutes int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT minutes FROM familylink_usage WHERE device_id = ? AND date = ?
+	`, deviceID, s.normalizeDate(date).Format("2006-01-02")).Scan(&minutes)
+
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return minutes, err
+}
+
+// SaveFamilyLinkUsage records the Family Link minutes charged for a device on a date
+// Implements familylink.UsageImportStorage interface
+func (s *SQLiteStorage) SaveFamilyLinkUsage(ctx context.Context, deviceID string, date time.Time, minutes int) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO familylink_usage (device_id, date, minutes, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(device_id, date) DO UPDATE SET
+			minutes = excluded.minutes,
+			updated_at = excluded.updated_at
+	`, deviceID, s.normalizeDate(date).Format("2006-01-02"), minutes, time.Now())
+
+	return err
+}
@@ -0,0 +1,43 @@
+package sqlite
+
+import (
+	"context"
+	"metron/internal/core"
+)
+
+// SaveDeviceHeartbeat inserts or replaces the heartbeat of a device
+func (s *SQLiteStorage) SaveDeviceHeartbeat(ctx context.Context, heartbeat *core.DeviceHeartbeat) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO device_heartbeats (device_id, source, last_seen_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(device_id) DO UPDATE SET
+			source = excluded.source,
+			last_seen_at = excluded.last_seen_at
+	`, heartbeat.DeviceID, heartbeat.Source, heartbeat.LastSeenAt)
+
+	return err
+}
+
+// ListDeviceHeartbeats retrieves the heartbeats of all devices that have checked in
+func (s *SQLiteStorage) ListDeviceHeartbeats(ctx context.Context) ([]*core.DeviceHeartbeat, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT device_id, source, last_seen_at
+		FROM device_heartbeats
+		ORDER BY device_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var heartbeats []*core.DeviceHeartbeat
+	for rows.Next() {
+		var heartbeat core.DeviceHeartbeat
+		if err := rows.Scan(&heartbeat.DeviceID, &heartbeat.Source, &heartbeat.LastSeenAt); err != nil {
+			return nil, err
+		}
+		heartbeats = append(heartbeats, &heartbeat)
+	}
+
+	return heartbeats, rows.Err()
+}
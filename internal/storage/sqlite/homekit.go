@@ -0,0 +1,87 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"metron/internal/homekit"
+	"time"
+)
+
+// GetHomeKitIdentity returns the HomeKit bridge identity, nil if none was saved
+// Implements homekit.Storage interface
+func (s *SQLiteStorage) GetHomeKitIdentity(ctx context.Context) (*homekit.Identity, error) {
+	var identity homekit.Identity
+	var privateKey []byte
+	err := s.db.QueryRowContext(ctx, `
+		SELECT pairing_id, private_key, config_hash, config_number FROM homekit_identity WHERE id = 1
+	`).Scan(&identity.PairingID, &privateKey, &identity.ConfigHash, &identity.ConfigNumber)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	identity.PrivateKey = privateKey
+	return &identity, nil
+}
+
+// SaveHomeKitIdentity stores the HomeKit bridge identity
+// Implements homekit.Storage interface
+func (s *SQLiteStorage) SaveHomeKitIdentity(ctx context.Context, identity *homekit.Identity) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO homekit_identity (id, pairing_id, private_key, config_hash, config_number)
+		VALUES (1, ?, ?, ?, ?)
+		ON CONFLICT(id) DO UPDATE SET
+			pairing_id = excluded.pairing_id,
+			private_key = excluded.private_key,
+			config_hash = excluded.config_hash,
+			config_number = excluded.config_number
+	`, identity.PairingID, []byte(identity.PrivateKey), identity.ConfigHash, identity.ConfigNumber)
+
+	return err
+}
+
+// ListHomeKitPairings returns the controllers paired with the HomeKit bridge
+// Implements homekit.Storage interface
+func (s *SQLiteStorage) ListHomeKitPairings(ctx context.Context) ([]*homekit.Pairing, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT controller_id, public_key, admin FROM homekit_pairings ORDER BY created_at, controller_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pairings []*homekit.Pairing
+	for rows.Next() {
+		var pairing homekit.Pairing
+		var publicKey []byte
+		if err := rows.Scan(&pairing.ControllerID, &publicKey, &pairing.Admin); err != nil {
+			return nil, err
+		}
+		pairing.PublicKey = publicKey
+		pairings = append(pairings, &pairing)
+	}
+	return pairings, rows.Err()
+}
+
+// SaveHomeKitPairing adds a pairing or replaces the pairing of the same controller
+// Implements homekit.Storage interface
+func (s *SQLiteStorage) SaveHomeKitPairing(ctx context.Context, pairing *homekit.Pairing) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO homekit_pairings (controller_id, public_key, admin, created_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(controller_id) DO UPDATE SET
+			public_key = excluded.public_key,
+			admin = excluded.admin
+	`, pairing.ControllerID, []byte(pairing.PublicKey), pairing.Admin, time.Now())
+
+	return err
+}
+
+// DeleteHomeKitPairing removes the pairing of a controller
+// Implements homekit.Storage interface
+func (s *SQLiteStorage) DeleteHomeKitPairing(ctx context.Context, controllerID string) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM homekit_pairings WHERE controller_id = ?`, controllerID)
+	return err
+}
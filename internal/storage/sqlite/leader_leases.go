@@ -0,0 +1,40 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+)
+
+// AcquireLeaderLease takes or renews the named lease for owner until the given time
+// Implements core.LeaderLeaseStorage interface
+func (s *SQLiteStorage) AcquireLeaderLease(ctx context.Context, name, owner string, until time.Time) (bool, error) {
+	// Times are stored in UTC so expires_at compares correctly as text
+	// The upsert only takes over the lease if it is ours or has expired
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO leader_leases (name, owner, expires_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(name) DO UPDATE SET
+			owner = excluded.owner,
+			expires_at = excluded.expires_at
+		WHERE leader_leases.owner = excluded.owner OR leader_leases.expires_at <= ?
+	`, name, owner, until.UTC(), time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+// ReleaseLeaderLease gives up owner's lease
+// Implements core.LeaderLeaseStorage interface
+func (s *SQLiteStorage) ReleaseLeaderLease(ctx context.Context, name, owner string) error {
+	_, err := s.db.ExecContext(ctx, `
+		DELETE FROM leader_leases WHERE name = ? AND owner = ?
+	`, name, owner)
+
+	return err
+}
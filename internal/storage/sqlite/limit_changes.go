@@ -0,0 +1,123 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"metron/internal/core"
+	"time"
+)
+
+// limitChangeDateLayout is the format of effective_from
+const limitChangeDateLayout = "2006-01-02"
+
+// CreateLimitChange stores a limit change
+func (s *SQLiteStorage) CreateLimitChange(ctx context.Context, change *core.LimitChange) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO limit_changes (id, child_id, weekday_limit, weekend_limit, effective_from, created_by, created_at, applied_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, change.ID, change.ChildID, change.WeekdayLimit, change.WeekendLimit, change.EffectiveFrom.Format(limitChangeDateLayout),
+		change.CreatedBy, change.CreatedAt.UTC(), nullTime(change.AppliedAt))
+
+	return err
+}
+
+// UpdateLimitChange updates a limit change (e.g., when it takes effect)
+func (s *SQLiteStorage) UpdateLimitChange(ctx context.Context, change *core.LimitChange) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE limit_changes
+		SET weekday_limit = ?, weekend_limit = ?, effective_from = ?, applied_at = ?
+		WHERE id = ?
+	`, change.WeekdayLimit, change.WeekendLimit, change.EffectiveFrom.Format(limitChangeDateLayout), nullTime(change.AppliedAt), change.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return core.ErrLimitChangeNotFound
+	}
+	return nil
+}
+
+// DeleteLimitChange deletes a limit change
+func (s *SQLiteStorage) DeleteLimitChange(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM limit_changes WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return core.ErrLimitChangeNotFound
+	}
+	return nil
+}
+
+// ListLimitChanges retrieves the limit changes of a child (all children if childID is empty), oldest effective date first
+func (s *SQLiteStorage) ListLimitChanges(ctx context.Context, childID string) ([]*core.LimitChange, error) {
+	if childID == "" {
+		return s.queryLimitChanges(ctx, `ORDER BY effective_from, created_at, id`)
+	}
+	return s.queryLimitChanges(ctx, `WHERE child_id = ? ORDER BY effective_from, created_at, id`, childID)
+}
+
+// loadScheduledLimits sets the pending limit changes of the children
+func (s *SQLiteStorage) loadScheduledLimits(ctx context.Context, children ...*core.Child) error {
+	if len(children) == 0 {
+		return nil
+	}
+
+	changes, err := s.queryLimitChanges(ctx, `WHERE applied_at IS NULL ORDER BY effective_from, created_at, id`)
+	if err != nil {
+		return err
+	}
+
+	byChild := make(map[string][]core.LimitChange)
+	for _, change := range changes {
+		byChild[change.ChildID] = append(byChild[change.ChildID], *change)
+	}
+	for _, child := range children {
+		child.ScheduledLimits = byChild[child.ID]
+	}
+	return nil
+}
+
+// queryLimitChanges runs a limit change query with the given WHERE/ORDER BY clause
+func (s *SQLiteStorage) queryLimitChanges(ctx context.Context, clause string, args ...interface{}) ([]*core.LimitChange, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, child_id, weekday_limit, weekend_limit, effective_from, created_by, created_at, applied_at
+		FROM limit_changes `+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []*core.LimitChange
+	for rows.Next() {
+		var change core.LimitChange
+		var effectiveFrom string
+		var appliedAt sql.NullTime
+		if err := rows.Scan(&change.ID, &change.ChildID, &change.WeekdayLimit, &change.WeekendLimit, &effectiveFrom,
+			&change.CreatedBy, &change.CreatedAt, &appliedAt); err != nil {
+			return nil, err
+		}
+
+		date, err := time.Parse(limitChangeDateLayout, effectiveFrom)
+		if err != nil {
+			return nil, err
+		}
+		change.EffectiveFrom = date
+		if appliedAt.Valid {
+			change.AppliedAt = &appliedAt.Time
+		}
+		changes = append(changes, &change)
+	}
+
+	return changes, rows.Err()
+}
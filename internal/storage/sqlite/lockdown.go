@@ -0,0 +1,109 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"metron/internal/core"
+	"time"
+)
+
+// GetActiveLockdown retrieves the lockdown that has not been lifted yet
+func (s *SQLiteStorage) GetActiveLockdown(ctx context.Context) (*core.Lockdown, error) {
+	row := s.db.QueryRowContext(ctx, `
+		SELECT id, reason, triggered_by, started_at, stopped_sessions, lifted_at, lifted_by
+		FROM lockdowns
+		WHERE lifted_at IS NULL
+		ORDER BY started_at DESC
+		LIMIT 1
+	`)
+
+	lockdown, err := scanLockdown(row)
+	if err == sql.ErrNoRows {
+		return nil, nil // No active lockdown
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return lockdown, nil
+}
+
+// CreateLockdown records a new lockdown
+func (s *SQLiteStorage) CreateLockdown(ctx context.Context, lockdown *core.Lockdown) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO lockdowns (id, reason, triggered_by, started_at, stopped_sessions, lifted_at, lifted_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, lockdown.ID, lockdown.Reason, lockdown.TriggeredBy, lockdown.StartedAt, lockdown.StoppedSessions,
+		nullTime(lockdown.LiftedAt), lockdown.LiftedBy)
+
+	return err
+}
+
+// UpdateLockdown updates the stopped session count and lift details of a lockdown
+func (s *SQLiteStorage) UpdateLockdown(ctx context.Context, lockdown *core.Lockdown) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE lockdowns
+		SET stopped_sessions = ?, lifted_at = ?, lifted_by = ?
+		WHERE id = ?
+	`, lockdown.StoppedSessions, nullTime(lockdown.LiftedAt), lockdown.LiftedBy, lockdown.ID)
+
+	return err
+}
+
+// ListLockdowns retrieves the most recent lockdowns, newest first
+func (s *SQLiteStorage) ListLockdowns(ctx context.Context, limit int) ([]*core.Lockdown, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, reason, triggered_by, started_at, stopped_sessions, lifted_at, lifted_by
+		FROM lockdowns
+		ORDER BY started_at DESC
+		LIMIT ?
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var lockdowns []*core.Lockdown
+	for rows.Next() {
+		lockdown, err := scanLockdown(rows)
+		if err != nil {
+			return nil, err
+		}
+		lockdowns = append(lockdowns, lockdown)
+	}
+
+	return lockdowns, rows.Err()
+}
+
+// scanLockdown scans a lockdown row from either *sql.Row or *sql.Rows
+func scanLockdown(scanner interface{ Scan(dest ...any) error }) (*core.Lockdown, error) {
+	var lockdown core.Lockdown
+	var reason sql.NullString
+	var liftedAt sql.NullTime
+	var liftedBy sql.NullString
+
+	if err := scanner.Scan(&lockdown.ID, &reason, &lockdown.TriggeredBy, &lockdown.StartedAt,
+		&lockdown.StoppedSessions, &liftedAt, &liftedBy); err != nil {
+		return nil, err
+	}
+
+	if reason.Valid {
+		lockdown.Reason = reason.String
+	}
+	if liftedAt.Valid {
+		lockdown.LiftedAt = &liftedAt.Time
+	}
+	if liftedBy.Valid {
+		lockdown.LiftedBy = liftedBy.String
+	}
+
+	return &lockdown, nil
+}
+
+// nullTime converts an optional timestamp to a nullable column value
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
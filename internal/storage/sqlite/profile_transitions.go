@@ -0,0 +1,87 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"metron/internal/core"
+)
+
+// CreateProfileTransition stores a limit profile transition
+func (s *SQLiteStorage) CreateProfileTransition(ctx context.Context, transition *core.ProfileTransition) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO profile_transitions (id, child_id, profile, age, weekday_limit, weekend_limit, status, created_at, resolved_by, resolved_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, transition.ID, transition.ChildID, transition.Profile, transition.Age, transition.WeekdayLimit, transition.WeekendLimit,
+		transition.Status, transition.CreatedAt.UTC(), transition.ResolvedBy, nullTime(transition.ResolvedAt))
+
+	return err
+}
+
+// UpdateProfileTransition updates a limit profile transition (e.g., when it is confirmed)
+func (s *SQLiteStorage) UpdateProfileTransition(ctx context.Context, transition *core.ProfileTransition) error {
+	result, err := s.db.ExecContext(ctx, `
+		UPDATE profile_transitions
+		SET status = ?, resolved_by = ?, resolved_at = ?
+		WHERE id = ?
+	`, transition.Status, transition.ResolvedBy, nullTime(transition.ResolvedAt), transition.ID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return core.ErrProfileTransitionNotFound
+	}
+	return nil
+}
+
+// GetProfileTransition retrieves a limit profile transition by ID
+func (s *SQLiteStorage) GetProfileTransition(ctx context.Context, id string) (*core.ProfileTransition, error) {
+	transitions, err := s.queryProfileTransitions(ctx, `WHERE id = ?`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(transitions) == 0 {
+		return nil, core.ErrProfileTransitionNotFound
+	}
+	return transitions[0], nil
+}
+
+// ListProfileTransitions retrieves limit profile transitions with a status (all if empty), oldest first
+func (s *SQLiteStorage) ListProfileTransitions(ctx context.Context, status string) ([]*core.ProfileTransition, error) {
+	if status == "" {
+		return s.queryProfileTransitions(ctx, `ORDER BY created_at, id`)
+	}
+	return s.queryProfileTransitions(ctx, `WHERE status = ? ORDER BY created_at, id`, status)
+}
+
+// queryProfileTransitions runs a profile transition query with the given WHERE/ORDER BY clause
+func (s *SQLiteStorage) queryProfileTransitions(ctx context.Context, clause string, args ...interface{}) ([]*core.ProfileTransition, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, child_id, profile, age, weekday_limit, weekend_limit, status, created_at, resolved_by, resolved_at
+		FROM profile_transitions `+clause, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transitions []*core.ProfileTransition
+	for rows.Next() {
+		var transition core.ProfileTransition
+		var resolvedAt sql.NullTime
+		if err := rows.Scan(&transition.ID, &transition.ChildID, &transition.Profile, &transition.Age,
+			&transition.WeekdayLimit, &transition.WeekendLimit, &transition.Status, &transition.CreatedAt,
+			&transition.ResolvedBy, &resolvedAt); err != nil {
+			return nil, err
+		}
+		if resolvedAt.Valid {
+			transition.ResolvedAt = &resolvedAt.Time
+		}
+		transitions = append(transitions, &transition)
+	}
+
+	return transitions, rows.Err()
+}
@@ -0,0 +1,71 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"metron/internal/core"
+)
+
+// SavePushSubscription stores a subscription, replacing one with the same endpoint
+func (s *SQLiteStorage) SavePushSubscription(ctx context.Context, subscription *core.PushSubscription) error {
+	childID := sql.NullString{String: subscription.ChildID, Valid: subscription.ChildID != ""}
+
+	// Times are stored in UTC so created_at sorts correctly as text
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO push_subscriptions (id, child_id, endpoint, p256dh, auth, label, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(endpoint) DO UPDATE SET
+			id = excluded.id,
+			child_id = excluded.child_id,
+			p256dh = excluded.p256dh,
+			auth = excluded.auth,
+			label = excluded.label,
+			created_at = excluded.created_at
+	`, subscription.ID, childID, subscription.Endpoint, subscription.P256dh, subscription.Auth, subscription.Label, subscription.CreatedAt.UTC())
+
+	return err
+}
+
+// ListPushSubscriptions retrieves all subscriptions, oldest first
+func (s *SQLiteStorage) ListPushSubscriptions(ctx context.Context) ([]*core.PushSubscription, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, child_id, endpoint, p256dh, auth, label, created_at
+		FROM push_subscriptions
+		ORDER BY created_at, id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subscriptions []*core.PushSubscription
+	for rows.Next() {
+		var subscription core.PushSubscription
+		var childID sql.NullString
+		if err := rows.Scan(&subscription.ID, &childID, &subscription.Endpoint, &subscription.P256dh, &subscription.Auth, &subscription.Label, &subscription.CreatedAt); err != nil {
+			return nil, err
+		}
+		subscription.ChildID = childID.String
+		subscriptions = append(subscriptions, &subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+// DeletePushSubscription deletes a subscription
+func (s *SQLiteStorage) DeletePushSubscription(ctx context.Context, id string) error {
+	result, err := s.db.ExecContext(ctx, "DELETE FROM push_subscriptions WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return core.ErrPushSubscriptionNotFound
+	}
+
+	return nil
+}
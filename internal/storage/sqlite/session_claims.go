@@ -0,0 +1,40 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+)
+
+// ClaimSession claims a session for owner until the given time
+// Implements core.SessionClaimStorage interface
+func (s *SQLiteStorage) ClaimSession(ctx context.Context, sessionID, owner string, until time.Time) (bool, error) {
+	// Times are stored in UTC so expires_at compares correctly as text
+	// The upsert only takes over the claim if it is ours or has expired
+	result, err := s.db.ExecContext(ctx, `
+		INSERT INTO session_claims (session_id, owner, expires_at)
+		VALUES (?, ?, ?)
+		ON CONFLICT(session_id) DO UPDATE SET
+			owner = excluded.owner,
+			expires_at = excluded.expires_at
+		WHERE session_claims.owner = excluded.owner OR session_claims.expires_at <= ?
+	`, sessionID, owner, until.UTC(), time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows == 1, nil
+}
+
+// ReleaseSessionClaim drops owner's claim on a session
+// Implements core.SessionClaimStorage interface
+func (s *SQLiteStorage) ReleaseSessionClaim(ctx context.Context, sessionID, owner string) error {
+	_, err := s.db.ExecContext(ctx, `
+		DELETE FROM session_claims WHERE session_id = ? AND owner = ?
+	`, sessionID, owner)
+
+	return err
+}
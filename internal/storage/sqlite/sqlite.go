@@ -6,16 +6,22 @@ import (
 	"encoding/json"
 	"fmt"
 	"metron/internal/core"
-	"metron/internal/drivers/aqara"
+	"metron/internal/credentials"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// SchemaVersion is the schema version written to PRAGMA user_version after migrations
+// Bump it whenever runMigrations gains a new step
+const SchemaVersion = 36
+
 // SQLiteStorage implements storage.Storage using SQLite
 type SQLiteStorage struct {
 	db       *sql.DB
 	timezone *time.Location
+	cipher   *credentials.Cipher // Optional: encrypts the credentials table (see SetCredentialCipher)
+	stmts    *statements         // Prepared statements of the hot paths
 }
 
 // New creates a new SQLite storage instance
@@ -46,6 +52,11 @@ func New(dbPath string, timezone *time.Location) (*SQLiteStorage, error) {
 		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
+	if storage.stmts, err = prepareStatements(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
 	return storage, nil
 }
 
@@ -116,7 +127,16 @@ func (s *SQLiteStorage) migrate() error {
 	}
 
 	// Run migrations for schema changes
-	return s.runMigrations()
+	if err := s.runMigrations(); err != nil {
+		return err
+	}
+
+	// Record schema version so tooling (metron doctor) can detect stale databases
+	if _, err := s.db.Exec(fmt.Sprintf("PRAGMA user_version = %d", SchemaVersion)); err != nil {
+		return fmt.Errorf("failed to set schema version: %w", err)
+	}
+
+	return nil
 }
 
 // runMigrations applies incremental schema changes
@@ -350,44 +370,621 @@ func (s *SQLiteStorage) runMigrations() error {
 		return fmt.Errorf("failed to create device_bypass table: %w", err)
 	}
 
-	// Create movie_time_usage table for weekend shared movie time
+	// Create movie_time_usage table for weekend shared movie time
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS movie_time_usage (
+			date DATE PRIMARY KEY,
+			session_id TEXT,
+			started_at DATETIME,
+			started_by TEXT,
+			status TEXT NOT NULL DEFAULT 'available',
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create movie_time_usage table: %w", err)
+	}
+
+	// Add is_movie_session column to sessions table
+	_, err = s.db.Exec(`
+		ALTER TABLE sessions ADD COLUMN is_movie_session INTEGER NOT NULL DEFAULT 0;
+	`)
+	// Ignore error if column already exists
+	if err != nil && err.Error() != "duplicate column name: is_movie_session" {
+		// Column might already exist, which is fine
+	}
+
+	// Create movie_time_bypass table for holiday/vacation bypass periods
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS movie_time_bypass (
+			id TEXT PRIMARY KEY,
+			reason TEXT NOT NULL,
+			start_date DATE NOT NULL,
+			end_date DATE NOT NULL,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create movie_time_bypass table: %w", err)
+	}
+
+	// Add allowed_devices column to children table (JSON array; NULL means all devices)
+	_, err = s.db.Exec(`
+		ALTER TABLE children ADD COLUMN allowed_devices TEXT;
+	`)
+	// Ignore error if column already exists
+	if err != nil && err.Error() != "duplicate column name: allowed_devices" {
+		// Column might already exist, which is fine
+	}
+
+	// Create lockdowns table for the emergency lockdown mode
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS lockdowns (
+			id TEXT PRIMARY KEY,
+			reason TEXT,
+			triggered_by TEXT NOT NULL,
+			started_at DATETIME NOT NULL,
+			stopped_sessions INTEGER NOT NULL DEFAULT 0,
+			lifted_at DATETIME,
+			lifted_by TEXT
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create lockdowns table: %w", err)
+	}
+
+	// Add last_activity_at column to sessions table (reported by agents for idle detection)
+	_, err = s.db.Exec(`
+		ALTER TABLE sessions ADD COLUMN last_activity_at DATETIME;
+	`)
+	// Ignore error if column already exists
+	if err != nil && err.Error() != "duplicate column name: last_activity_at" {
+		// Column might already exist, which is fine
+	}
+
+	// Add break_minutes column to sessions table (completed break time, refunded when charging)
+	_, err = s.db.Exec(`
+		ALTER TABLE sessions ADD COLUMN break_minutes INTEGER NOT NULL DEFAULT 0;
+	`)
+	// Ignore error if column already exists
+	if err != nil && err.Error() != "duplicate column name: break_minutes" {
+		// Column might already exist, which is fine
+	}
+
+	// Create tracking_pauses table for vacation mode (child_id is empty for a global pause)
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS tracking_pauses (
+			id TEXT PRIMARY KEY,
+			child_id TEXT NOT NULL DEFAULT '',
+			reason TEXT,
+			paused_by TEXT NOT NULL,
+			started_at DATETIME NOT NULL,
+			resumes_at DATETIME,
+			resumed_at DATETIME,
+			resumed_by TEXT
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create tracking_pauses table: %w", err)
+	}
+
+	// Add timezone column to children table (IANA override; empty uses the server timezone)
+	_, err = s.db.Exec(`
+		ALTER TABLE children ADD COLUMN timezone TEXT NOT NULL DEFAULT '';
+	`)
+	// Ignore error if column already exists
+	if err != nil && err.Error() != "duplicate column name: timezone" {
+		// Column might already exist, which is fine
+	}
+
+	// Add break_action column to sessions table (enforcement action of the break in progress)
+	_, err = s.db.Exec(`
+		ALTER TABLE sessions ADD COLUMN break_action TEXT NOT NULL DEFAULT '';
+	`)
+	// Ignore error if column already exists
+	if err != nil && err.Error() != "duplicate column name: break_action" {
+		// Column might already exist, which is fine
+	}
+
+	// Add break_exempt column to sessions table (parent-approved opt-out of break rules)
+	_, err = s.db.Exec(`
+		ALTER TABLE sessions ADD COLUMN break_exempt INTEGER NOT NULL DEFAULT 0;
+	`)
+	// Ignore error if column already exists
+	if err != nil && err.Error() != "duplicate column name: break_exempt" {
+		// Column might already exist, which is fine
+	}
+
+	// Add grace_minutes column to children table (grace allowance past the daily limit)
+	_, err = s.db.Exec(`
+		ALTER TABLE children ADD COLUMN grace_minutes INTEGER NOT NULL DEFAULT 0;
+	`)
+	// Ignore error if column already exists
+	if err != nil && err.Error() != "duplicate column name: grace_minutes" {
+		// Column might already exist, which is fine
+	}
+
+	// Add birthdate column to children table (YYYY-MM-DD, for age-based limit profiles)
+	_, err = s.db.Exec(`
+		ALTER TABLE children ADD COLUMN birthdate TEXT;
+	`)
+	// Ignore error if column already exists
+	if err != nil && err.Error() != "duplicate column name: birthdate" {
+		// Column might already exist, which is fine
+	}
+
+	// Add grace_ends_at column to sessions table (hard stop of a session running on grace)
+	_, err = s.db.Exec(`
+		ALTER TABLE sessions ADD COLUMN grace_ends_at DATETIME;
+	`)
+	// Ignore error if column already exists
+	if err != nil && err.Error() != "duplicate column name: grace_ends_at" {
+		// Column might already exist, which is fine
+	}
+
+	// Add end_reason column to sessions table (why the session ended; empty while running)
+	_, err = s.db.Exec(`
+		ALTER TABLE sessions ADD COLUMN end_reason TEXT NOT NULL DEFAULT '';
+	`)
+	// Ignore error if column already exists
+	if err != nil && err.Error() != "duplicate column name: end_reason" {
+		// Column might already exist, which is fine
+	}
+
+	// Add override columns to sessions table (parent lifted downtime or limits for the session)
+	_, err = s.db.Exec(`
+		ALTER TABLE sessions ADD COLUMN override_downtime INTEGER NOT NULL DEFAULT 0;
+	`)
+	// Ignore error if column already exists
+	if err != nil && err.Error() != "duplicate column name: override_downtime" {
+		// Column might already exist, which is fine
+	}
+	_, err = s.db.Exec(`
+		ALTER TABLE sessions ADD COLUMN override_limits INTEGER NOT NULL DEFAULT 0;
+	`)
+	// Ignore error if column already exists
+	if err != nil && err.Error() != "duplicate column name: override_limits" {
+		// Column might already exist, which is fine
+	}
+
+	// Backfill actual_duration of sessions that ended before it was recorded
+	// A session is last updated when it ends, so updated_at is its end time; like the charge
+	// policy, time past the planned end is not counted and completed breaks are subtracted
+	_, err = s.db.Exec(`
+		UPDATE sessions
+		SET actual_duration = MAX(0, MIN(expected_duration,
+			(CAST(strftime('%s', updated_at) AS INTEGER) - CAST(strftime('%s', start_time) AS INTEGER)) / 60) - break_minutes)
+		WHERE actual_duration IS NULL AND status IN ('completed', 'expired')
+			AND strftime('%s', updated_at) IS NOT NULL AND strftime('%s', start_time) IS NOT NULL;
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to backfill session actual_duration: %w", err)
+	}
+
+	// Create agent_tokens table for server-issued agent tokens (only the SHA-256 hash is stored)
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS agent_tokens (
+			id TEXT PRIMARY KEY,
+			device_id TEXT NOT NULL,
+			label TEXT,
+			token_hash TEXT NOT NULL UNIQUE,
+			hint TEXT NOT NULL,
+			issued_by TEXT NOT NULL,
+			created_at DATETIME NOT NULL,
+			last_used_at DATETIME,
+			revoked_at DATETIME,
+			revoked_by TEXT
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create agent_tokens table: %w", err)
+	}
+
+	// Create device_heartbeats table (when each agent or polled device last checked in)
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS device_heartbeats (
+			device_id TEXT PRIMARY KEY,
+			source TEXT NOT NULL,
+			last_seen_at DATETIME NOT NULL
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create device_heartbeats table: %w", err)
+	}
+
+	// Create tamper_events table (possible tampering reported by device agents)
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS tamper_events (
+			id TEXT PRIMARY KEY,
+			device_id TEXT NOT NULL,
+			type TEXT NOT NULL,
+			details TEXT,
+			occurred_at DATETIME NOT NULL,
+			received_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_tamper_events_received_at ON tamper_events(received_at);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create tamper_events table: %w", err)
+	}
+
+	// Create familylink_usage table (Family Link usage already charged to daily summaries)
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS familylink_usage (
+			device_id TEXT NOT NULL,
+			date TEXT NOT NULL,
+			minutes INTEGER NOT NULL,
+			updated_at DATETIME NOT NULL,
+			PRIMARY KEY (device_id, date)
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create familylink_usage table: %w", err)
+	}
+
+	// Create steam_playtime table (last seen Steam playtime per account and game)
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS steam_playtime (
+			steam_id TEXT NOT NULL,
+			app_id TEXT NOT NULL,
+			minutes INTEGER NOT NULL,
+			updated_at DATETIME NOT NULL,
+			PRIMARY KEY (steam_id, app_id)
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create steam_playtime table: %w", err)
+	}
+
+	// Create homekit_identity table (single row with the HomeKit bridge identity)
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS homekit_identity (
+			id INTEGER PRIMARY KEY CHECK (id = 1),
+			pairing_id TEXT NOT NULL,
+			private_key BLOB NOT NULL,
+			config_hash TEXT NOT NULL,
+			config_number INTEGER NOT NULL
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create homekit_identity table: %w", err)
+	}
+
+	// Create homekit_pairings table (controllers paired with the HomeKit bridge)
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS homekit_pairings (
+			controller_id TEXT PRIMARY KEY,
+			public_key BLOB NOT NULL,
+			admin BOOLEAN NOT NULL,
+			created_at DATETIME NOT NULL
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create homekit_pairings table: %w", err)
+	}
+
+	// Create session_claims table (short-lived claims serializing session changes across processes)
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS session_claims (
+			session_id TEXT PRIMARY KEY,
+			owner TEXT NOT NULL,
+			expires_at DATETIME NOT NULL
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create session_claims table: %w", err)
+	}
+
+	// Create limit_changes table (history of limit changes; pending ones have no applied_at)
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS limit_changes (
+			id TEXT PRIMARY KEY,
+			child_id TEXT NOT NULL,
+			weekday_limit INTEGER NOT NULL,
+			weekend_limit INTEGER NOT NULL,
+			effective_from TEXT NOT NULL,
+			created_by TEXT NOT NULL,
+			created_at DATETIME NOT NULL,
+			applied_at DATETIME,
+			FOREIGN KEY (child_id) REFERENCES children(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS idx_limit_changes_child ON limit_changes(child_id, effective_from);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create limit_changes table: %w", err)
+	}
+
+	// Create audit_log table (kept when a child is deleted)
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS audit_log (
+			id TEXT PRIMARY KEY,
+			action TEXT NOT NULL,
+			child_id TEXT NOT NULL DEFAULT '',
+			actor TEXT NOT NULL,
+			details TEXT,
+			created_at DATETIME NOT NULL
+		);
+		CREATE INDEX IF NOT EXISTS idx_audit_log_created_at ON audit_log(created_at);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create audit_log table: %w", err)
+	}
+
+	// Create profile_transitions table (limit profiles proposed on birthdays)
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS profile_transitions (
+			id TEXT PRIMARY KEY,
+			child_id TEXT NOT NULL,
+			profile TEXT NOT NULL,
+			age INTEGER NOT NULL,
+			weekday_limit INTEGER NOT NULL,
+			weekend_limit INTEGER NOT NULL,
+			status TEXT NOT NULL,
+			created_at DATETIME NOT NULL,
+			resolved_by TEXT NOT NULL DEFAULT '',
+			resolved_at DATETIME,
+			FOREIGN KEY (child_id) REFERENCES children(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS idx_profile_transitions_status ON profile_transitions(status, created_at);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create profile_transitions table: %w", err)
+	}
+
+	// Create child_logins table (child web app logins; only the SHA-256 hash of the token is stored)
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS child_logins (
+			id TEXT PRIMARY KEY,
+			child_id TEXT NOT NULL,
+			token_hash TEXT NOT NULL UNIQUE,
+			user_agent TEXT NOT NULL DEFAULT '',
+			created_at DATETIME NOT NULL,
+			expires_at DATETIME NOT NULL,
+			last_seen_at DATETIME,
+			revoked_at DATETIME,
+			revoked_by TEXT NOT NULL DEFAULT '',
+			FOREIGN KEY (child_id) REFERENCES children(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS idx_child_logins_child ON child_logins(child_id, created_at);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create child_logins table: %w", err)
+	}
+
+	// Create downtime_overrides table (session_id is empty for overrides lasting until the end of the day)
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS downtime_overrides (
+			id TEXT PRIMARY KEY,
+			child_id TEXT NOT NULL,
+			session_id TEXT NOT NULL DEFAULT '',
+			reason TEXT NOT NULL DEFAULT '',
+			granted_by TEXT NOT NULL,
+			started_at DATETIME NOT NULL,
+			expires_at DATETIME,
+			ended_at DATETIME,
+			ended_by TEXT NOT NULL DEFAULT '',
+			FOREIGN KEY (child_id) REFERENCES children(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS idx_downtime_overrides_active ON downtime_overrides(ended_at, started_at);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create downtime_overrides table: %w", err)
+	}
+
+	// Sessions started with a downtime override before override records existed keep it until they end
+	_, err = s.db.Exec(`
+		INSERT OR IGNORE INTO downtime_overrides (id, child_id, session_id, granted_by, started_at)
+		SELECT 'dto_' || s.id || '_' || sc.child_id, sc.child_id, s.id, 'migration', s.start_time
+		FROM sessions s
+		JOIN session_children sc ON s.id = sc.session_id
+		WHERE s.override_downtime = 1 AND s.status IN ('active', 'paused')
+		AND NOT EXISTS (
+			SELECT 1 FROM downtime_overrides o WHERE o.session_id = s.id AND o.child_id = sc.child_id
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to migrate session downtime overrides: %w", err)
+	}
+
+	// Create usage_alerts table (children reaching a share of the day's time)
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS usage_alerts (
+			id TEXT PRIMARY KEY,
+			child_id TEXT NOT NULL,
+			date TEXT NOT NULL,
+			threshold INTEGER NOT NULL,
+			used_minutes INTEGER NOT NULL,
+			limit_minutes INTEGER NOT NULL,
+			created_at DATETIME NOT NULL,
+			UNIQUE(child_id, date, threshold),
+			FOREIGN KEY (child_id) REFERENCES children(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS idx_usage_alerts_created_at ON usage_alerts(created_at);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create usage_alerts table: %w", err)
+	}
+
+	// Create day_rollovers table (children's days closed out after midnight)
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS day_rollovers (
+			id TEXT PRIMARY KEY,
+			child_id TEXT NOT NULL,
+			date TEXT NOT NULL,
+			limit_minutes INTEGER NOT NULL,
+			used_minutes INTEGER NOT NULL,
+			session_count INTEGER NOT NULL,
+			created_at DATETIME NOT NULL,
+			UNIQUE(child_id, date),
+			FOREIGN KEY (child_id) REFERENCES children(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS idx_day_rollovers_created_at ON day_rollovers(created_at);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create day_rollovers table: %w", err)
+	}
+
+	// Create app_usage table (app minutes reported by external usage sources, see core.AppUsageService)
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS app_usage (
+			child_id TEXT NOT NULL,
+			date TEXT NOT NULL,
+			source TEXT NOT NULL,
+			app TEXT NOT NULL,
+			minutes INTEGER NOT NULL,
+			updated_at DATETIME NOT NULL,
+			PRIMARY KEY (child_id, date, source, app),
+			FOREIGN KEY (child_id) REFERENCES children(id) ON DELETE CASCADE
+		);
+		CREATE INDEX IF NOT EXISTS idx_app_usage_date ON app_usage(date);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create app_usage table: %w", err)
+	}
+
+	// Create push_subscriptions table (browsers receiving Web Push notifications, see core.PushService)
+	// Parents' subscriptions have no child_id
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS push_subscriptions (
+			id TEXT PRIMARY KEY,
+			child_id TEXT,
+			endpoint TEXT NOT NULL UNIQUE,
+			p256dh TEXT NOT NULL,
+			auth TEXT NOT NULL,
+			label TEXT NOT NULL DEFAULT '',
+			created_at DATETIME NOT NULL,
+			FOREIGN KEY (child_id) REFERENCES children(id) ON DELETE CASCADE
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create push_subscriptions table: %w", err)
+	}
+
+	// Create driver_jobs table (driver calls waiting to be made, see core.DriverQueue)
+	// Jobs keep no foreign key: a job whose session is gone is dropped when it runs
+	// owner and claimed_until hold the claim of the instance making the job; unclaimed jobs have an empty owner
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS driver_jobs (
+			id TEXT PRIMARY KEY,
+			kind TEXT NOT NULL,
+			session_id TEXT NOT NULL,
+			minutes INTEGER NOT NULL DEFAULT 0,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT NOT NULL DEFAULT '',
+			created_at DATETIME NOT NULL,
+			owner TEXT NOT NULL DEFAULT '',
+			claimed_until DATETIME NOT NULL DEFAULT '0001-01-01 00:00:00+00:00'
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create driver_jobs table: %w", err)
+	}
+
+	// Create driver_calls table (bounded history of driver calls, see core.DriverCallLog)
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS driver_calls (
+			id TEXT PRIMARY KEY,
+			driver TEXT NOT NULL,
+			device_id TEXT NOT NULL,
+			session_id TEXT NOT NULL,
+			action TEXT NOT NULL,
+			started_at DATETIME NOT NULL,
+			duration_ms INTEGER NOT NULL DEFAULT 0,
+			result TEXT NOT NULL,
+			error TEXT NOT NULL DEFAULT ''
+		);
+		CREATE INDEX IF NOT EXISTS idx_driver_calls_started_at ON driver_calls(started_at);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create driver_calls table: %w", err)
+	}
+
+	// Create leader_leases table (leader election between instances sharing the database)
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS leader_leases (
+			name TEXT PRIMARY KEY,
+			owner TEXT NOT NULL,
+			expires_at DATETIME NOT NULL
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create leader_leases table: %w", err)
+	}
+
+	// Create credentials table (third-party credentials by driver, see SetCredentialCipher)
+	// key_id is empty for plaintext rows; otherwise data is sealed with a data key that
+	// wrapped_key holds sealed with the master key
+	_, err = s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS credentials (
+			driver TEXT PRIMARY KEY,
+			key_id TEXT NOT NULL DEFAULT '',
+			wrapped_key BLOB NOT NULL,
+			data BLOB NOT NULL,
+			created_at DATETIME NOT NULL,
+			updated_at DATETIME NOT NULL
+		);
+	`)
+	if err != nil {
+		return fmt.Errorf("failed to create credentials table: %w", err)
+	}
+
+	// Move the Aqara tokens out of the plaintext aqara_tokens table
+	if err := s.migrateAqaraTokens(); err != nil {
+		return fmt.Errorf("failed to migrate aqara tokens: %w", err)
+	}
+
+	// Create child_activity table (children's activity feeds shown in the child web app)
 	_, err = s.db.Exec(`
-		CREATE TABLE IF NOT EXISTS movie_time_usage (
-			date DATE PRIMARY KEY,
-			session_id TEXT,
-			started_at DATETIME,
-			started_by TEXT,
-			status TEXT NOT NULL DEFAULT 'available',
+		CREATE TABLE IF NOT EXISTS child_activity (
+			id TEXT PRIMARY KEY,
+			child_id TEXT NOT NULL,
+			kind TEXT NOT NULL,
+			message TEXT NOT NULL,
+			session_id TEXT NOT NULL DEFAULT '',
+			minutes INTEGER NOT NULL DEFAULT 0,
 			created_at DATETIME NOT NULL,
-			updated_at DATETIME NOT NULL
+			FOREIGN KEY (child_id) REFERENCES children(id) ON DELETE CASCADE
 		);
+		CREATE INDEX IF NOT EXISTS idx_child_activity_child ON child_activity(child_id, created_at);
 	`)
 	if err != nil {
-		return fmt.Errorf("failed to create movie_time_usage table: %w", err)
+		return fmt.Errorf("failed to create child_activity table: %w", err)
 	}
 
-	// Add is_movie_session column to sessions table
+	// Add initiator columns to sessions table (who started the session; empty for older sessions)
 	_, err = s.db.Exec(`
-		ALTER TABLE sessions ADD COLUMN is_movie_session INTEGER NOT NULL DEFAULT 0;
+		ALTER TABLE sessions ADD COLUMN initiator_type TEXT NOT NULL DEFAULT '';
 	`)
 	// Ignore error if column already exists
-	if err != nil && err.Error() != "duplicate column name: is_movie_session" {
+	if err != nil && err.Error() != "duplicate column name: initiator_type" {
+		// Column might already exist, which is fine
+	}
+	_, err = s.db.Exec(`
+		ALTER TABLE sessions ADD COLUMN initiator_id TEXT NOT NULL DEFAULT '';
+	`)
+	// Ignore error if column already exists
+	if err != nil && err.Error() != "duplicate column name: initiator_id" {
 		// Column might already exist, which is fine
 	}
 
-	// Create movie_time_bypass table for holiday/vacation bypass periods
+	// Add notes column to sessions table (e.g., what was watched during the session)
 	_, err = s.db.Exec(`
-		CREATE TABLE IF NOT EXISTS movie_time_bypass (
-			id TEXT PRIMARY KEY,
-			reason TEXT NOT NULL,
-			start_date DATE NOT NULL,
-			end_date DATE NOT NULL,
-			created_at DATETIME NOT NULL,
-			updated_at DATETIME NOT NULL
-		);
+		ALTER TABLE sessions ADD COLUMN notes TEXT NOT NULL DEFAULT '';
 	`)
-	if err != nil {
-		return fmt.Errorf("failed to create movie_time_bypass table: %w", err)
+	// Ignore error if column already exists
+	if err != nil && err.Error() != "duplicate column name: notes" {
+		// Column might already exist, which is fine
+	}
+
+	// Add activity column to sessions table (exempt activity the session is for)
+	_, err = s.db.Exec(`
+		ALTER TABLE sessions ADD COLUMN activity TEXT NOT NULL DEFAULT '';
+	`)
+	// Ignore error if column already exists
+	if err != nil && err.Error() != "duplicate column name: activity" {
+		// Column might already exist, which is fine
 	}
 
 	return nil
@@ -400,7 +997,9 @@ func (s *SQLiteStorage) CreateChild(ctx context.Context, child *core.Child) erro
 	}
 
 	now := time.Now()
-	child.CreatedAt = now
+	if child.CreatedAt.IsZero() { // Imported children keep their creation time
+		child.CreatedAt = now
+	}
 	child.UpdatedAt = now
 
 	var breakRuleJSON sql.NullString
@@ -412,10 +1011,15 @@ func (s *SQLiteStorage) CreateChild(ctx context.Context, child *core.Child) erro
 		breakRuleJSON = sql.NullString{String: string(data), Valid: true}
 	}
 
-	_, err := s.db.ExecContext(ctx, `
-		INSERT INTO children (id, name, emoji, pin, weekday_limit, weekend_limit, break_rule, downtime_enabled, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, child.ID, child.Name, child.Emoji, child.PIN, child.WeekdayLimit, child.WeekendLimit, breakRuleJSON, child.DowntimeEnabled, child.CreatedAt, child.UpdatedAt)
+	allowedDevicesJSON, err := marshalAllowedDevices(child.AllowedDevices)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx, `
+		INSERT INTO children (id, name, emoji, pin, weekday_limit, weekend_limit, break_rule, downtime_enabled, allowed_devices, timezone, grace_minutes, birthdate, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, child.ID, child.Name, child.Emoji, child.PIN, child.WeekdayLimit, child.WeekendLimit, breakRuleJSON, child.DowntimeEnabled, allowedDevicesJSON, child.Timezone, child.GraceMinutes, marshalBirthdate(child.Birthdate), child.CreatedAt, child.UpdatedAt)
 
 	return err
 }
@@ -424,12 +1028,14 @@ func (s *SQLiteStorage) CreateChild(ctx context.Context, child *core.Child) erro
 func (s *SQLiteStorage) GetChild(ctx context.Context, id string) (*core.Child, error) {
 	var child core.Child
 	var breakRuleJSON sql.NullString
+	var allowedDevicesJSON sql.NullString
+	var birthdate sql.NullString
 
 	err := s.db.QueryRowContext(ctx, `
-		SELECT id, name, emoji, pin, weekday_limit, weekend_limit, break_rule, downtime_enabled, created_at, updated_at
+		SELECT id, name, emoji, pin, weekday_limit, weekend_limit, break_rule, downtime_enabled, allowed_devices, timezone, grace_minutes, birthdate, created_at, updated_at
 		FROM children WHERE id = ?
 	`, id).Scan(&child.ID, &child.Name, &child.Emoji, &child.PIN, &child.WeekdayLimit, &child.WeekendLimit,
-		&breakRuleJSON, &child.DowntimeEnabled, &child.CreatedAt, &child.UpdatedAt)
+		&breakRuleJSON, &child.DowntimeEnabled, &allowedDevicesJSON, &child.Timezone, &child.GraceMinutes, &birthdate, &child.CreatedAt, &child.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, core.ErrChildNotFound
@@ -446,13 +1052,25 @@ func (s *SQLiteStorage) GetChild(ctx context.Context, id string) (*core.Child, e
 		child.BreakRule = &breakRule
 	}
 
+	if child.AllowedDevices, err = unmarshalAllowedDevices(allowedDevicesJSON); err != nil {
+		return nil, err
+	}
+
+	if child.Birthdate, err = unmarshalBirthdate(birthdate); err != nil {
+		return nil, err
+	}
+
+	if err := s.loadScheduledLimits(ctx, &child); err != nil {
+		return nil, err
+	}
+
 	return &child, nil
 }
 
 // ListChildren retrieves all children
 func (s *SQLiteStorage) ListChildren(ctx context.Context) ([]*core.Child, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT id, name, emoji, pin, weekday_limit, weekend_limit, break_rule, downtime_enabled, created_at, updated_at
+		SELECT id, name, emoji, pin, weekday_limit, weekend_limit, break_rule, downtime_enabled, allowed_devices, timezone, grace_minutes, birthdate, created_at, updated_at
 		FROM children ORDER BY name
 	`)
 	if err != nil {
@@ -464,9 +1082,11 @@ func (s *SQLiteStorage) ListChildren(ctx context.Context) ([]*core.Child, error)
 	for rows.Next() {
 		var child core.Child
 		var breakRuleJSON sql.NullString
+		var allowedDevicesJSON sql.NullString
+		var birthdate sql.NullString
 
 		if err := rows.Scan(&child.ID, &child.Name, &child.Emoji, &child.PIN, &child.WeekdayLimit, &child.WeekendLimit,
-			&breakRuleJSON, &child.DowntimeEnabled, &child.CreatedAt, &child.UpdatedAt); err != nil {
+			&breakRuleJSON, &child.DowntimeEnabled, &allowedDevicesJSON, &child.Timezone, &child.GraceMinutes, &birthdate, &child.CreatedAt, &child.UpdatedAt); err != nil {
 			return nil, err
 		}
 
@@ -478,10 +1098,27 @@ func (s *SQLiteStorage) ListChildren(ctx context.Context) ([]*core.Child, error)
 			child.BreakRule = &breakRule
 		}
 
+		allowedDevices, err := unmarshalAllowedDevices(allowedDevicesJSON)
+		if err != nil {
+			return nil, err
+		}
+		child.AllowedDevices = allowedDevices
+
+		if child.Birthdate, err = unmarshalBirthdate(birthdate); err != nil {
+			return nil, err
+		}
+
 		children = append(children, &child)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
 
-	return children, rows.Err()
+	if err := s.loadScheduledLimits(ctx, children...); err != nil {
+		return nil, err
+	}
+
+	return children, nil
 }
 
 // UpdateChild updates an existing child
@@ -501,11 +1138,16 @@ func (s *SQLiteStorage) UpdateChild(ctx context.Context, child *core.Child) erro
 		breakRuleJSON = sql.NullString{String: string(data), Valid: true}
 	}
 
+	allowedDevicesJSON, err := marshalAllowedDevices(child.AllowedDevices)
+	if err != nil {
+		return err
+	}
+
 	result, err := s.db.ExecContext(ctx, `
 		UPDATE children
-		SET name = ?, emoji = ?, pin = ?, weekday_limit = ?, weekend_limit = ?, break_rule = ?, downtime_enabled = ?, updated_at = ?
+		SET name = ?, emoji = ?, pin = ?, weekday_limit = ?, weekend_limit = ?, break_rule = ?, downtime_enabled = ?, allowed_devices = ?, timezone = ?, grace_minutes = ?, birthdate = ?, updated_at = ?
 		WHERE id = ?
-	`, child.Name, child.Emoji, child.PIN, child.WeekdayLimit, child.WeekendLimit, breakRuleJSON, child.DowntimeEnabled, child.UpdatedAt, child.ID)
+	`, child.Name, child.Emoji, child.PIN, child.WeekdayLimit, child.WeekendLimit, breakRuleJSON, child.DowntimeEnabled, allowedDevicesJSON, child.Timezone, child.GraceMinutes, marshalBirthdate(child.Birthdate), child.UpdatedAt, child.ID)
 
 	if err != nil {
 		return err
@@ -522,6 +1164,50 @@ func (s *SQLiteStorage) UpdateChild(ctx context.Context, child *core.Child) erro
 	return nil
 }
 
+// marshalAllowedDevices encodes a child's device allow-list (NULL when unrestricted)
+func marshalAllowedDevices(deviceIDs []string) (sql.NullString, error) {
+	if len(deviceIDs) == 0 {
+		return sql.NullString{}, nil
+	}
+	data, err := json.Marshal(deviceIDs)
+	if err != nil {
+		return sql.NullString{}, fmt.Errorf("failed to marshal allowed devices: %w", err)
+	}
+	return sql.NullString{String: string(data), Valid: true}, nil
+}
+
+// unmarshalAllowedDevices decodes a child's device allow-list
+func unmarshalAllowedDevices(value sql.NullString) ([]string, error) {
+	if !value.Valid || value.String == "" {
+		return nil, nil
+	}
+	var deviceIDs []string
+	if err := json.Unmarshal([]byte(value.String), &deviceIDs); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal allowed devices: %w", err)
+	}
+	return deviceIDs, nil
+}
+
+// marshalBirthdate encodes a child's birthdate as YYYY-MM-DD (NULL when unset)
+func marshalBirthdate(birthdate *time.Time) sql.NullString {
+	if birthdate == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: birthdate.Format(limitChangeDateLayout), Valid: true}
+}
+
+// unmarshalBirthdate decodes a child's birthdate
+func unmarshalBirthdate(value sql.NullString) (*time.Time, error) {
+	if !value.Valid || value.String == "" {
+		return nil, nil
+	}
+	birthdate, err := time.Parse(limitChangeDateLayout, value.String)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse birthdate: %w", err)
+	}
+	return &birthdate, nil
+}
+
 // DeleteChild deletes a child
 func (s *SQLiteStorage) DeleteChild(ctx context.Context, id string) error {
 	result, err := s.db.ExecContext(ctx, "DELETE FROM children WHERE id = ?", id)
@@ -556,7 +1242,7 @@ func (s *SQLiteStorage) CreateSession(ctx context.Context, session *core.Session
 	}
 	defer tx.Rollback()
 
-	var lastBreakAt, breakEndsAt, warningSentAt, lastExtendedAt sql.NullTime
+	var lastBreakAt, breakEndsAt, warningSentAt, lastExtendedAt, lastActivityAt, graceEndsAt sql.NullTime
 	if session.LastBreakAt != nil {
 		lastBreakAt = sql.NullTime{Time: *session.LastBreakAt, Valid: true}
 	}
@@ -569,13 +1255,19 @@ func (s *SQLiteStorage) CreateSession(ctx context.Context, session *core.Session
 	if session.LastExtendedAt != nil {
 		lastExtendedAt = sql.NullTime{Time: *session.LastExtendedAt, Valid: true}
 	}
+	if session.LastActivityAt != nil {
+		lastActivityAt = sql.NullTime{Time: *session.LastActivityAt, Valid: true}
+	}
+	if session.GraceEndsAt != nil {
+		graceEndsAt = sql.NullTime{Time: *session.GraceEndsAt, Valid: true}
+	}
 
 	_, err = tx.ExecContext(ctx, `
-		INSERT INTO sessions (id, device_type, device_id, start_time, expected_duration,
-			status, last_break_at, break_ends_at, warning_sent_at, last_extended_at, is_movie_session, created_at, updated_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, session.ID, session.DeviceType, session.DeviceID, session.StartTime, session.ExpectedDuration,
-		session.Status, lastBreakAt, breakEndsAt, warningSentAt, lastExtendedAt, session.IsMovieSession, session.CreatedAt, session.UpdatedAt)
+		INSERT INTO sessions (id, device_type, device_id, start_time, expected_duration, actual_duration,
+			status, last_break_at, break_ends_at, warning_sent_at, last_extended_at, last_activity_at, break_minutes, break_action, break_exempt, override_downtime, override_limits, grace_ends_at, is_movie_session, end_reason, initiator_type, initiator_id, notes, activity, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, session.ID, session.DeviceType, session.DeviceID, session.StartTime, session.ExpectedDuration, nullableMinutes(session.ActualDuration),
+		session.Status, lastBreakAt, breakEndsAt, warningSentAt, lastExtendedAt, lastActivityAt, session.BreakMinutes, session.BreakAction, session.BreakExempt, session.OverrideDowntime, session.OverrideLimits, graceEndsAt, session.IsMovieSession, session.EndReason, session.InitiatorType, session.InitiatorID, session.Notes, session.Activity, session.CreatedAt, session.UpdatedAt)
 
 	if err != nil {
 		return err
@@ -597,15 +1289,12 @@ func (s *SQLiteStorage) CreateSession(ctx context.Context, session *core.Session
 // GetSession retrieves a session by ID
 func (s *SQLiteStorage) GetSession(ctx context.Context, id string) (*core.Session, error) {
 	var session core.Session
-	var lastBreakAt, breakEndsAt, warningSentAt, lastExtendedAt sql.NullTime
+	var actualDuration sql.NullInt64
+	var lastBreakAt, breakEndsAt, warningSentAt, lastExtendedAt, lastActivityAt, graceEndsAt sql.NullTime
 
-	err := s.db.QueryRowContext(ctx, `
-		SELECT id, device_type, device_id, start_time, expected_duration,
-			status, last_break_at, break_ends_at, warning_sent_at, last_extended_at, is_movie_session, created_at, updated_at
-		FROM sessions WHERE id = ?
-	`, id).Scan(&session.ID, &session.DeviceType, &session.DeviceID, &session.StartTime,
-		&session.ExpectedDuration, &session.Status,
-		&lastBreakAt, &breakEndsAt, &warningSentAt, &lastExtendedAt, &session.IsMovieSession, &session.CreatedAt, &session.UpdatedAt)
+	err := s.stmts.getSession.QueryRowContext(ctx, id).Scan(&session.ID, &session.DeviceType, &session.DeviceID, &session.StartTime,
+		&session.ExpectedDuration, &actualDuration, &session.Status,
+		&lastBreakAt, &breakEndsAt, &warningSentAt, &lastExtendedAt, &lastActivityAt, &session.BreakMinutes, &session.BreakAction, &session.BreakExempt, &session.OverrideDowntime, &session.OverrideLimits, &graceEndsAt, &session.IsMovieSession, &session.EndReason, &session.InitiatorType, &session.InitiatorID, &session.Notes, &session.Activity, &session.CreatedAt, &session.UpdatedAt)
 
 	if err == sql.ErrNoRows {
 		return nil, core.ErrSessionNotFound
@@ -614,6 +1303,10 @@ func (s *SQLiteStorage) GetSession(ctx context.Context, id string) (*core.Sessio
 		return nil, err
 	}
 
+	if actualDuration.Valid {
+		duration := int(actualDuration.Int64)
+		session.ActualDuration = &duration
+	}
 	if lastBreakAt.Valid {
 		session.LastBreakAt = &lastBreakAt.Time
 	}
@@ -626,11 +1319,15 @@ func (s *SQLiteStorage) GetSession(ctx context.Context, id string) (*core.Sessio
 	if lastExtendedAt.Valid {
 		session.LastExtendedAt = &lastExtendedAt.Time
 	}
+	if lastActivityAt.Valid {
+		session.LastActivityAt = &lastActivityAt.Time
+	}
+	if graceEndsAt.Valid {
+		session.GraceEndsAt = &graceEndsAt.Time
+	}
 
 	// Load child IDs
-	rows, err := s.db.QueryContext(ctx, `
-		SELECT child_id FROM session_children WHERE session_id = ?
-	`, id)
+	rows, err := s.stmts.listSessionChildren.QueryContext(ctx, id)
 	if err != nil {
 		return nil, err
 	}
@@ -647,9 +1344,16 @@ func (s *SQLiteStorage) GetSession(ctx context.Context, id string) (*core.Sessio
 	return &session, rows.Err()
 }
 
-// ListActiveSessions retrieves all active sessions
+// ListActiveSessions retrieves all running sessions, including sessions paused for a break
+// Callers that need only sessions with the device unlocked check Session.IsActive
 func (s *SQLiteStorage) ListActiveSessions(ctx context.Context) ([]*core.Session, error) {
-	return s.listSessionsByCondition(ctx, "status = ?", core.SessionStatusActive)
+	rows, err := s.stmts.listActiveSessions.QueryContext(ctx, core.SessionStatusActive, core.SessionStatusPaused)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return s.scanSessions(ctx, rows)
 }
 
 // ListAllSessions retrieves all sessions regardless of status
@@ -660,8 +1364,8 @@ func (s *SQLiteStorage) ListAllSessions(ctx context.Context) ([]*core.Session, e
 // ListSessionsByChild retrieves all sessions for a specific child
 func (s *SQLiteStorage) ListSessionsByChild(ctx context.Context, childID string) ([]*core.Session, error) {
 	rows, err := s.db.QueryContext(ctx, `
-		SELECT s.id, s.device_type, s.device_id, s.start_time, s.expected_duration,
-			s.status, s.last_break_at, s.break_ends_at, s.warning_sent_at, s.last_extended_at, s.is_movie_session, s.created_at, s.updated_at
+		SELECT s.id, s.device_type, s.device_id, s.start_time, s.expected_duration, s.actual_duration,
+			s.status, s.last_break_at, s.break_ends_at, s.warning_sent_at, s.last_extended_at, s.last_activity_at, s.break_minutes, s.break_action, s.break_exempt, s.override_downtime, s.override_limits, s.grace_ends_at, s.is_movie_session, s.end_reason, s.initiator_type, s.initiator_id, s.notes, s.activity, s.created_at, s.updated_at
 		FROM sessions s
 		JOIN session_children sc ON s.id = sc.session_id
 		WHERE sc.child_id = ?
@@ -675,11 +1379,17 @@ func (s *SQLiteStorage) ListSessionsByChild(ctx context.Context, childID string)
 	return s.scanSessions(ctx, rows)
 }
 
+// ListSessionsSince retrieves the sessions started at or after since, newest first
+// Start times are stored with their zone offset, so they are compared as julian days
+func (s *SQLiteStorage) ListSessionsSince(ctx context.Context, since time.Time) ([]*core.Session, error) {
+	return s.listSessionsByCondition(ctx, "julianday(start_time) >= julianday(?)", since.UTC())
+}
+
 // UpdateSession updates an existing session
 func (s *SQLiteStorage) UpdateSession(ctx context.Context, session *core.Session) error {
 	session.UpdatedAt = time.Now()
 
-	var lastBreakAt, breakEndsAt, warningSentAt, lastExtendedAt sql.NullTime
+	var lastBreakAt, breakEndsAt, warningSentAt, lastExtendedAt, lastActivityAt, graceEndsAt sql.NullTime
 	if session.LastBreakAt != nil {
 		lastBreakAt = sql.NullTime{Time: *session.LastBreakAt, Valid: true}
 	}
@@ -692,14 +1402,26 @@ func (s *SQLiteStorage) UpdateSession(ctx context.Context, session *core.Session
 	if session.LastExtendedAt != nil {
 		lastExtendedAt = sql.NullTime{Time: *session.LastExtendedAt, Valid: true}
 	}
+	if session.LastActivityAt != nil {
+		lastActivityAt = sql.NullTime{Time: *session.LastActivityAt, Valid: true}
+	}
+	if session.GraceEndsAt != nil {
+		graceEndsAt = sql.NullTime{Time: *session.GraceEndsAt, Valid: true}
+	}
 
-	result, err := s.db.ExecContext(ctx, `
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
 		UPDATE sessions
-		SET device_type = ?, device_id = ?, expected_duration = ?, status = ?,
-			last_break_at = ?, break_ends_at = ?, warning_sent_at = ?, last_extended_at = ?, updated_at = ?
+		SET device_type = ?, device_id = ?, expected_duration = ?, actual_duration = ?, status = ?,
+			last_break_at = ?, break_ends_at = ?, warning_sent_at = ?, last_extended_at = ?, last_activity_at = ?, break_minutes = ?, break_action = ?, override_downtime = ?, override_limits = ?, grace_ends_at = ?, end_reason = ?, notes = ?, updated_at = ?
 		WHERE id = ?
-	`, session.DeviceType, session.DeviceID, session.ExpectedDuration, session.Status,
-		lastBreakAt, breakEndsAt, warningSentAt, lastExtendedAt, session.UpdatedAt, session.ID)
+	`, session.DeviceType, session.DeviceID, session.ExpectedDuration, nullableMinutes(session.ActualDuration), session.Status,
+		lastBreakAt, breakEndsAt, warningSentAt, lastExtendedAt, lastActivityAt, session.BreakMinutes, session.BreakAction, session.OverrideDowntime, session.OverrideLimits, graceEndsAt, session.EndReason, session.Notes, session.UpdatedAt, session.ID)
 
 	if err != nil {
 		return err
@@ -713,7 +1435,20 @@ func (s *SQLiteStorage) UpdateSession(ctx context.Context, session *core.Session
 		return core.ErrSessionNotFound
 	}
 
-	return nil
+	// Replace session-child associations (children can join or leave a running session)
+	if _, err := tx.ExecContext(ctx, "DELETE FROM session_children WHERE session_id = ?", session.ID); err != nil {
+		return err
+	}
+	for _, childID := range session.ChildIDs {
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO session_children (session_id, child_id) VALUES (?, ?)
+		`, session.ID, childID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
 // DeleteSession deletes a session
@@ -883,6 +1618,56 @@ func (s *SQLiteStorage) UpdateDailyAllocation(ctx context.Context, allocation *c
 	return err
 }
 
+// ListDailyAllocations retrieves the allocations of all children for a day
+func (s *SQLiteStorage) ListDailyAllocations(ctx context.Context, date time.Time) ([]*core.DailyTimeAllocation, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT child_id, date, base_limit, bonus_granted, created_at, updated_at
+		FROM daily_time_allocations WHERE date = ?
+	`, s.normalizeDate(date))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var allocations []*core.DailyTimeAllocation
+	for rows.Next() {
+		var allocation core.DailyTimeAllocation
+		if err := rows.Scan(&allocation.ChildID, &allocation.Date, &allocation.BaseLimit,
+			&allocation.BonusGranted, &allocation.CreatedAt, &allocation.UpdatedAt); err != nil {
+			return nil, err
+		}
+		allocations = append(allocations, &allocation)
+	}
+
+	return allocations, rows.Err()
+}
+
+// ListChildAllocations retrieves a child's allocations from one day to another (inclusive), oldest first
+// Implements core.AllocationStorage interface
+func (s *SQLiteStorage) ListChildAllocations(ctx context.Context, childID string, from, to time.Time) ([]*core.DailyTimeAllocation, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT child_id, date, base_limit, bonus_granted, created_at, updated_at
+		FROM daily_time_allocations WHERE child_id = ? AND date >= ? AND date <= ?
+		ORDER BY date
+	`, childID, s.normalizeDate(from), s.normalizeDate(to))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var allocations []*core.DailyTimeAllocation
+	for rows.Next() {
+		var allocation core.DailyTimeAllocation
+		if err := rows.Scan(&allocation.ChildID, &allocation.Date, &allocation.BaseLimit,
+			&allocation.BonusGranted, &allocation.CreatedAt, &allocation.UpdatedAt); err != nil {
+			return nil, err
+		}
+		allocations = append(allocations, &allocation)
+	}
+
+	return allocations, rows.Err()
+}
+
 // GrantRewardMinutesNew grants reward minutes to a child's daily allocation
 // This updates the daily_time_allocations table
 func (s *SQLiteStorage) GrantRewardMinutesNew(ctx context.Context, childID string, date time.Time, minutes int) error {
@@ -957,18 +1742,37 @@ func (s *SQLiteStorage) GetDailyUsageSummary(ctx context.Context, childID string
 	return &summary, nil
 }
 
+// ListDailyUsageSummaries retrieves the stored usage summaries of all children for a day
+// Children without usage on the day have no summary
+func (s *SQLiteStorage) ListDailyUsageSummaries(ctx context.Context, date time.Time) ([]*core.DailyUsageSummary, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT child_id, date, minutes_used, session_count, created_at, updated_at
+		FROM daily_usage_summaries WHERE date = ?
+	`, s.normalizeDate(date))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []*core.DailyUsageSummary
+	for rows.Next() {
+		var summary core.DailyUsageSummary
+		if err := rows.Scan(&summary.ChildID, &summary.Date, &summary.MinutesUsed,
+			&summary.SessionCount, &summary.CreatedAt, &summary.UpdatedAt); err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, &summary)
+	}
+
+	return summaries, rows.Err()
+}
+
 // IncrementDailyUsageSummary increments the daily usage summary
 func (s *SQLiteStorage) IncrementDailyUsageSummary(ctx context.Context, childID string, date time.Time, minutes int) error {
 	normalizedDate := s.normalizeDate(date)
 	now := time.Now()
 
-	_, err := s.db.ExecContext(ctx, `
-		INSERT INTO daily_usage_summaries (child_id, date, minutes_used, session_count, created_at, updated_at)
-		VALUES (?, ?, ?, 0, ?, ?)
-		ON CONFLICT(child_id, date) DO UPDATE SET
-			minutes_used = minutes_used + ?,
-			updated_at = ?
-	`, childID, normalizedDate, minutes, now, now, minutes, now)
+	_, err := s.stmts.incrementDailyUsage.ExecContext(ctx, childID, normalizedDate, minutes, now, now, minutes, now)
 
 	return err
 }
@@ -993,7 +1797,7 @@ func (s *SQLiteStorage) IncrementSessionCountSummary(ctx context.Context, childI
 func (s *SQLiteStorage) ListActiveSessionRecords(ctx context.Context) ([]*core.SessionUsageRecord, error) {
 	rows, err := s.db.QueryContext(ctx, `
 		SELECT id, device_type, device_id, start_time, expected_duration, actual_duration, status,
-			last_break_at, break_ends_at, warning_sent_at, is_movie_session, created_at, updated_at
+			last_break_at, break_ends_at, warning_sent_at, break_minutes, is_movie_session, activity, created_at, updated_at
 		FROM sessions WHERE status = ?
 	`, core.SessionStatusActive)
 
@@ -1009,7 +1813,7 @@ func (s *SQLiteStorage) ListActiveSessionRecords(ctx context.Context) ([]*core.S
 
 		err := rows.Scan(&session.ID, &session.DeviceType, &session.DeviceID, &session.StartTime,
 			&session.ExpectedDuration, &actualDuration, &session.Status, &session.LastBreakAt,
-			&session.BreakEndsAt, &session.WarningSentAt, &session.IsMovieSession, &session.CreatedAt, &session.UpdatedAt)
+			&session.BreakEndsAt, &session.WarningSentAt, &session.BreakMinutes, &session.IsMovieSession, &session.Activity, &session.CreatedAt, &session.UpdatedAt)
 
 		if err != nil {
 			return nil, err
@@ -1022,9 +1826,7 @@ func (s *SQLiteStorage) ListActiveSessionRecords(ctx context.Context) ([]*core.S
 		}
 
 		// Get child IDs for this session
-		childRows, err := s.db.QueryContext(ctx, `
-			SELECT child_id FROM session_children WHERE session_id = ?
-		`, session.ID)
+		childRows, err := s.stmts.listSessionChildren.QueryContext(ctx, session.ID)
 		if err != nil {
 			return nil, err
 		}
@@ -1047,8 +1849,89 @@ func (s *SQLiteStorage) ListActiveSessionRecords(ctx context.Context) ([]*core.S
 	return sessions, rows.Err()
 }
 
+// Ping verifies the database connection is alive
+func (s *SQLiteStorage) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// GetSchemaVersion returns the schema version recorded in the database
+func (s *SQLiteStorage) GetSchemaVersion(ctx context.Context) (int, error) {
+	return readSchemaVersion(ctx, s.db)
+}
+
+// NewReadOnly opens the database at dbPath read-only, e.g. a replica or a second connection
+// to the main database for reporting queries (use it as a storage.Reader)
+// It does not run migrations: the schema must already be at SchemaVersion.
+func NewReadOnly(dbPath string, timezone *time.Location) (*SQLiteStorage, error) {
+	if timezone == nil {
+		timezone = time.UTC // Fallback to UTC
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+dbPath+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	version, err := readSchemaVersion(context.Background(), db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	if version != SchemaVersion {
+		db.Close()
+		return nil, fmt.Errorf("%s has schema version %d, expected %d", dbPath, version, SchemaVersion)
+	}
+
+	stmts, err := prepareStatements(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &SQLiteStorage{
+		db:       db,
+		timezone: timezone,
+		stmts:    stmts,
+	}, nil
+}
+
+// EnableWAL switches the database to write-ahead logging, so that read-only connections
+// (see NewReadOnly) read while the scheduler writes instead of waiting for each other
+// The journal mode is stored in the database file.
+func (s *SQLiteStorage) EnableWAL(ctx context.Context) error {
+	var mode string
+	if err := s.db.QueryRowContext(ctx, "PRAGMA journal_mode = WAL").Scan(&mode); err != nil {
+		return fmt.Errorf("failed to enable WAL: %w", err)
+	}
+	if mode != "wal" {
+		return fmt.Errorf("failed to enable WAL: journal mode is %s", mode)
+	}
+	return nil
+}
+
+// ReadSchemaVersion opens the database at dbPath read-only and returns its schema version
+// Unlike New, it does not run migrations, so it is safe for diagnostics
+func ReadSchemaVersion(ctx context.Context, dbPath string) (int, error) {
+	db, err := sql.Open("sqlite3", "file:"+dbPath+"?mode=ro")
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	return readSchemaVersion(ctx, db)
+}
+
+func readSchemaVersion(ctx context.Context, db *sql.DB) (int, error) {
+	var version int
+	if err := db.QueryRowContext(ctx, "PRAGMA user_version").Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	return version, nil
+}
+
 // Close closes the database connection
 func (s *SQLiteStorage) Close() error {
+	s.stmts.close()
 	return s.db.Close()
 }
 
@@ -1056,8 +1939,7 @@ func (s *SQLiteStorage) Close() error {
 
 func (s *SQLiteStorage) listSessionsByCondition(ctx context.Context, condition string, args ...interface{}) ([]*core.Session, error) {
 	query := `
-		SELECT id, device_type, device_id, start_time, expected_duration,
-			status, last_break_at, break_ends_at, warning_sent_at, last_extended_at, is_movie_session, created_at, updated_at
+		SELECT ` + sessionColumns + `
 		FROM sessions WHERE ` + condition + ` ORDER BY start_time DESC
 	`
 
@@ -1075,14 +1957,19 @@ func (s *SQLiteStorage) scanSessions(ctx context.Context, rows *sql.Rows) ([]*co
 
 	for rows.Next() {
 		var session core.Session
-		var lastBreakAt, breakEndsAt, warningSentAt, lastExtendedAt sql.NullTime
+		var actualDuration sql.NullInt64
+		var lastBreakAt, breakEndsAt, warningSentAt, lastExtendedAt, lastActivityAt, graceEndsAt sql.NullTime
 
 		if err := rows.Scan(&session.ID, &session.DeviceType, &session.DeviceID, &session.StartTime,
-			&session.ExpectedDuration, &session.Status,
-			&lastBreakAt, &breakEndsAt, &warningSentAt, &lastExtendedAt, &session.IsMovieSession, &session.CreatedAt, &session.UpdatedAt); err != nil {
+			&session.ExpectedDuration, &actualDuration, &session.Status,
+			&lastBreakAt, &breakEndsAt, &warningSentAt, &lastExtendedAt, &lastActivityAt, &session.BreakMinutes, &session.BreakAction, &session.BreakExempt, &session.OverrideDowntime, &session.OverrideLimits, &graceEndsAt, &session.IsMovieSession, &session.EndReason, &session.InitiatorType, &session.InitiatorID, &session.Notes, &session.Activity, &session.CreatedAt, &session.UpdatedAt); err != nil {
 			return nil, err
 		}
 
+		if actualDuration.Valid {
+			duration := int(actualDuration.Int64)
+			session.ActualDuration = &duration
+		}
 		if lastBreakAt.Valid {
 			session.LastBreakAt = &lastBreakAt.Time
 		}
@@ -1095,11 +1982,12 @@ func (s *SQLiteStorage) scanSessions(ctx context.Context, rows *sql.Rows) ([]*co
 		if lastExtendedAt.Valid {
 			session.LastExtendedAt = &lastExtendedAt.Time
 		}
+		if lastActivityAt.Valid {
+			session.LastActivityAt = &lastActivityAt.Time
+		}
 
 		// Load child IDs
-		childRows, err := s.db.QueryContext(ctx, `
-			SELECT child_id FROM session_children WHERE session_id = ?
-		`, session.ID)
+		childRows, err := s.stmts.listSessionChildren.QueryContext(ctx, session.ID)
 		if err != nil {
 			return nil, err
 		}
@@ -1120,6 +2008,14 @@ func (s *SQLiteStorage) scanSessions(ctx context.Context, rows *sql.Rows) ([]*co
 	return sessions, rows.Err()
 }
 
+// nullableMinutes converts an optional number of minutes to a nullable column value
+func nullableMinutes(minutes *int) sql.NullInt64 {
+	if minutes == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: int64(*minutes), Valid: true}
+}
+
 func (s *SQLiteStorage) normalizeDate(t time.Time) time.Time {
 	// Convert to configured timezone and normalize to midnight
 	// This ensures dates match the user's local calendar day
@@ -1128,68 +2024,6 @@ func (s *SQLiteStorage) normalizeDate(t time.Time) time.Time {
 	return time.Date(year, month, day, 0, 0, 0, 0, s.timezone)
 }
 
-// GetAqaraTokens retrieves the stored Aqara tokens
-// Implements aqara.AqaraTokenStorage interface
-func (s *SQLiteStorage) GetAqaraTokens(ctx context.Context) (*aqara.AqaraTokens, error) {
-	var tokens aqara.AqaraTokens
-	var expiresAt sql.NullTime
-
-	err := s.db.QueryRowContext(ctx, `
-		SELECT refresh_token, access_token, access_token_expires_at, created_at, updated_at
-		FROM aqara_tokens WHERE id = 1
-	`).Scan(&tokens.RefreshToken, &tokens.AccessToken, &expiresAt, &tokens.CreatedAt, &tokens.UpdatedAt)
-
-	if err == sql.ErrNoRows {
-		return nil, nil // No tokens stored yet
-	}
-	if err != nil {
-		return nil, err
-	}
-
-	if expiresAt.Valid {
-		tokens.AccessTokenExpiresAt = &expiresAt.Time
-	}
-
-	return &tokens, nil
-}
-
-// SaveAqaraTokens saves or updates the Aqara tokens
-// Implements aqara.AqaraTokenStorage interface
-func (s *SQLiteStorage) SaveAqaraTokens(ctx context.Context, tokens *aqara.AqaraTokens) error {
-	now := time.Now()
-	tokens.UpdatedAt = now
-
-	var expiresAt sql.NullTime
-	if tokens.AccessTokenExpiresAt != nil {
-		expiresAt = sql.NullTime{Time: *tokens.AccessTokenExpiresAt, Valid: true}
-	}
-
-	// Check if tokens exist
-	var exists bool
-	err := s.db.QueryRowContext(ctx, "SELECT EXISTS(SELECT 1 FROM aqara_tokens WHERE id = 1)").Scan(&exists)
-	if err != nil {
-		return err
-	}
-
-	if exists {
-		// Update existing tokens
-		_, err = s.db.ExecContext(ctx, `
-			UPDATE aqara_tokens
-			SET refresh_token = ?, access_token = ?, access_token_expires_at = ?, updated_at = ?
-			WHERE id = 1
-		`, tokens.RefreshToken, tokens.AccessToken, expiresAt, tokens.UpdatedAt)
-	} else {
-		// Insert new tokens
-		tokens.CreatedAt = now
-		_, err = s.db.ExecContext(ctx, `
-			INSERT INTO aqara_tokens (id, refresh_token, access_token, access_token_expires_at, created_at, updated_at)
-			VALUES (1, ?, ?, ?, ?, ?)
-		`, tokens.RefreshToken, tokens.AccessToken, expiresAt, tokens.CreatedAt, tokens.UpdatedAt)
-	}
-
-	return err
-}
-
 // ============================================================================
 // DOWNTIME SKIP STORAGE - Implements core.DowntimeSkipStorage interface
 // ============================================================================
@@ -3,6 +3,13 @@ package sqlite
 import (
 	"context"
 	"metron/internal/core"
+	"metron/internal/credentials"
+	"metron/internal/drivers/aqara"
+	"metron/internal/drivers/familylink"
+	"metron/internal/homekit"
+	"metron/internal/steam"
+	"metron/internal/storage"
+	"metron/internal/storage/storagetest"
 	"path/filepath"
 	"testing"
 	"time"
@@ -11,6 +18,18 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// Compile-time interface checks
+var (
+	_ storage.Storage          = (*SQLiteStorage)(nil)
+	_ credentials.Store        = (*SQLiteStorage)(nil)
+	_ core.DowntimeSkipStorage = (*SQLiteStorage)(nil)
+	_ core.AgentTokenStorage   = (*SQLiteStorage)(nil)
+
+	_ familylink.UsageImportStorage = (*SQLiteStorage)(nil)
+	_ steam.PlaytimeStorage         = (*SQLiteStorage)(nil)
+	_ homekit.Storage               = (*SQLiteStorage)(nil)
+)
+
 func setupTestDB(t *testing.T) *SQLiteStorage {
 	tmpDir := t.TempDir()
 	dbPath := filepath.Join(tmpDir, "test.db")
@@ -82,6 +101,32 @@ func TestSQLiteStorage_Children(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, "Alice Updated", updated.Name)
 	assert.Equal(t, 70, updated.WeekdayLimit)
+	assert.Empty(t, updated.AllowedDevices)
+
+	// Test device allow-list round trip
+	updated.AllowedDevices = []string{"tv1", "ipad1"}
+	require.NoError(t, storage.UpdateChild(ctx, updated))
+
+	restricted, err := storage.GetChild(ctx, "child1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"tv1", "ipad1"}, restricted.AllowedDevices)
+
+	// Clearing the allow-list allows all devices again
+	restricted.AllowedDevices = nil
+	require.NoError(t, storage.UpdateChild(ctx, restricted))
+
+	unrestricted, err := storage.GetChild(ctx, "child1")
+	require.NoError(t, err)
+	assert.Empty(t, unrestricted.AllowedDevices)
+	assert.Empty(t, unrestricted.Timezone)
+
+	// Test timezone override round trip
+	unrestricted.Timezone = "America/New_York"
+	require.NoError(t, storage.UpdateChild(ctx, unrestricted))
+
+	overridden, err := storage.GetChild(ctx, "child1")
+	require.NoError(t, err)
+	assert.Equal(t, "America/New_York", overridden.Timezone)
 
 	// Test UpdateChild - not found
 	nonExistent := &core.Child{
@@ -138,6 +183,7 @@ func TestSQLiteStorage_Sessions(t *testing.T) {
 		StartTime:        now,
 		ExpectedDuration: 30,
 		Status:           core.SessionStatusActive,
+		BreakExempt:      true,
 	}
 
 	err = storage.CreateSession(ctx, session)
@@ -146,6 +192,7 @@ func TestSQLiteStorage_Sessions(t *testing.T) {
 	// Test GetSession
 	retrieved, err := storage.GetSession(ctx, "session1")
 	require.NoError(t, err)
+	assert.True(t, retrieved.BreakExempt)
 	assert.Equal(t, session.ID, retrieved.ID)
 	assert.Equal(t, session.DeviceType, retrieved.DeviceType)
 	assert.Equal(t, session.DeviceID, retrieved.DeviceID)
@@ -196,6 +243,7 @@ func TestSQLiteStorage_Sessions(t *testing.T) {
 	retrieved.Status = core.SessionStatusPaused
 	breakTime := time.Now()
 	retrieved.LastBreakAt = &breakTime
+	retrieved.BreakAction = core.BreakActionLock
 	err = storage.UpdateSession(ctx, retrieved)
 	require.NoError(t, err)
 
@@ -203,6 +251,22 @@ func TestSQLiteStorage_Sessions(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, core.SessionStatusPaused, updated.Status)
 	require.NotNil(t, updated.LastBreakAt)
+	assert.Equal(t, core.BreakActionLock, updated.BreakAction)
+
+	// Sessions paused for a break are still listed as active
+	activeSessions, err = storage.ListActiveSessions(ctx)
+	require.NoError(t, err)
+	require.Len(t, activeSessions, 1)
+	assert.Equal(t, core.BreakActionLock, activeSessions[0].BreakAction)
+
+	// Test UpdateSession - children are replaced
+	updated.ChildIDs = []string{"child2"}
+	err = storage.UpdateSession(ctx, updated)
+	require.NoError(t, err)
+
+	updated, err = storage.GetSession(ctx, "session1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"child2"}, updated.ChildIDs)
 
 	// Test UpdateSession - not found
 	nonExistent := &core.Session{
@@ -330,3 +394,321 @@ func TestSQLiteStorage_ForeignKeyConstraints(t *testing.T) {
 	assert.Len(t, retrieved.ChildIDs, 1)
 	assert.Equal(t, "child2", retrieved.ChildIDs[0])
 }
+
+func TestSQLiteStorage_SchemaVersion(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	ctx := context.Background()
+
+	storage, err := New(dbPath, nil)
+	require.NoError(t, err)
+
+	require.NoError(t, storage.Ping(ctx))
+
+	version, err := storage.GetSchemaVersion(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, SchemaVersion, version)
+	require.NoError(t, storage.Close())
+
+	// Read-only inspection without migrations
+	version, err = ReadSchemaVersion(ctx, dbPath)
+	require.NoError(t, err)
+	assert.Equal(t, SchemaVersion, version)
+}
+
+func TestSQLiteStorage_ReadOnly(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	ctx := context.Background()
+
+	writer, err := New(dbPath, nil)
+	require.NoError(t, err)
+	defer writer.Close()
+	require.NoError(t, writer.EnableWAL(ctx))
+
+	reader, err := NewReadOnly(dbPath, nil)
+	require.NoError(t, err)
+	defer reader.Close()
+	var _ storage.Reader = reader
+
+	require.NoError(t, writer.CreateChild(ctx, &core.Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 90}))
+	child, err := reader.GetChild(ctx, "child1")
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", child.Name)
+
+	// Writes are rejected
+	err = reader.CreateChild(ctx, &core.Child{ID: "child2", Name: "Bob", WeekdayLimit: 60, WeekendLimit: 90})
+	assert.Error(t, err)
+
+	_, err = NewReadOnly(filepath.Join(tmpDir, "missing.db"), nil)
+	assert.Error(t, err)
+}
+
+func TestSQLiteStorage_AqaraTokens(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	ctx := context.Background()
+
+	// Tokens of the old plaintext table are moved to credentials on open
+	storage, err := New(dbPath, nil)
+	require.NoError(t, err)
+	expires := time.Date(2026, 3, 10, 18, 0, 0, 0, time.UTC)
+	_, err = storage.db.ExecContext(ctx, `
+		INSERT INTO aqara_tokens (id, refresh_token, access_token, access_token_expires_at, created_at, updated_at)
+		VALUES (1, 'refresh-secret', 'access-secret', ?, ?, ?)
+	`, expires, expires.Add(-time.Hour), expires.Add(-time.Hour))
+	require.NoError(t, err)
+	require.NoError(t, storage.Close())
+
+	storage, err = New(dbPath, nil)
+	require.NoError(t, err)
+	tokens, err := aqara.NewTokenStore(storage).Get(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, tokens)
+	assert.Equal(t, "refresh-secret", tokens.RefreshToken)
+	assert.Equal(t, "access-secret", tokens.AccessToken)
+	require.NotNil(t, tokens.AccessTokenExpiresAt)
+	assert.True(t, tokens.AccessTokenExpiresAt.Equal(expires))
+
+	var legacyRows int
+	require.NoError(t, storage.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM aqara_tokens").Scan(&legacyRows))
+	assert.Equal(t, 0, legacyRows)
+
+	// Setting a key encrypts the plaintext row
+	key := make([]byte, credentials.KeySize)
+	key[0] = 1
+	cipher, err := credentials.NewCipher(key)
+	require.NoError(t, err)
+	require.NoError(t, storage.SetCredentialCipher(ctx, cipher))
+
+	var keyID string
+	var data []byte
+	require.NoError(t, storage.db.QueryRowContext(ctx, "SELECT key_id, data FROM credentials WHERE driver = 'aqara'").Scan(&keyID, &data))
+	assert.Equal(t, cipher.KeyID(), keyID)
+	assert.NotContains(t, string(data), "secret")
+
+	tokens.AccessToken = "new-access-secret"
+	require.NoError(t, aqara.NewTokenStore(storage).Save(ctx, tokens))
+	require.NoError(t, storage.Close())
+
+	// Encrypted tokens need the same key
+	storage, err = New(dbPath, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { storage.Close() })
+
+	_, err = aqara.NewTokenStore(storage).Get(ctx)
+	assert.ErrorIs(t, err, credentials.ErrNoKey)
+
+	other := make([]byte, credentials.KeySize)
+	otherCipher, err := credentials.NewCipher(other)
+	require.NoError(t, err)
+	require.NoError(t, storage.SetCredentialCipher(ctx, otherCipher))
+	_, err = aqara.NewTokenStore(storage).Get(ctx)
+	assert.ErrorIs(t, err, credentials.ErrKeyMismatch)
+
+	require.NoError(t, storage.SetCredentialCipher(ctx, cipher))
+	tokens, err = aqara.NewTokenStore(storage).Get(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "new-access-secret", tokens.AccessToken)
+	assert.Equal(t, "refresh-secret", tokens.RefreshToken)
+}
+
+func TestSQLiteStorage_BackfillActualDuration(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	ctx := context.Background()
+
+	storage, err := New(dbPath, nil)
+	require.NoError(t, err)
+	require.NoError(t, storage.CreateChild(ctx, &core.Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 120}))
+
+	start := time.Date(2026, 3, 10, 17, 0, 0, 0, time.UTC)
+	sessions := []struct {
+		id           string
+		status       core.SessionStatus
+		ended        time.Time
+		breakMinutes int
+	}{
+		{"stopped", core.SessionStatusCompleted, start.Add(25 * time.Minute), 5},
+		{"expired_late", core.SessionStatusExpired, start.Add(2 * time.Hour), 0}, // Ended long after the planned end
+		{"running", core.SessionStatusActive, start.Add(10 * time.Minute), 0},
+	}
+	for _, session := range sessions {
+		require.NoError(t, storage.CreateSession(ctx, &core.Session{
+			ID: session.id, DeviceType: "tv", DeviceID: "tv1", ChildIDs: []string{"child1"},
+			StartTime: start, ExpectedDuration: 30, Status: core.SessionStatusActive,
+		}))
+		// Sessions that ended before actual_duration was recorded
+		_, err := storage.db.ExecContext(ctx, "UPDATE sessions SET status = ?, break_minutes = ?, updated_at = ? WHERE id = ?",
+			session.status, session.breakMinutes, session.ended, session.id)
+		require.NoError(t, err)
+	}
+	require.NoError(t, storage.Close())
+
+	// Reopening runs the migrations again
+	storage, err = New(dbPath, nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { storage.Close() })
+
+	stopped, err := storage.GetSession(ctx, "stopped")
+	require.NoError(t, err)
+	require.NotNil(t, stopped.ActualDuration)
+	assert.Equal(t, 20, *stopped.ActualDuration, "25 minutes minus a 5 minute break")
+
+	expired, err := storage.GetSession(ctx, "expired_late")
+	require.NoError(t, err)
+	require.NotNil(t, expired.ActualDuration)
+	assert.Equal(t, 30, *expired.ActualDuration, "capped at the planned duration")
+
+	running, err := storage.GetSession(ctx, "running")
+	require.NoError(t, err)
+	assert.Nil(t, running.ActualDuration)
+}
+
+func TestSQLiteStorage_MigrateSessionDowntimeOverrides(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+	ctx := context.Background()
+
+	storage, err := New(dbPath, nil)
+	require.NoError(t, err)
+	require.NoError(t, storage.CreateChild(ctx, &core.Child{ID: "child1", Name: "Alice", WeekdayLimit: 60, WeekendLimit: 120}))
+
+	start := time.Date(2026, 3, 10, 21, 0, 0, 0, time.UTC)
+	sessions := []struct {
+		id       string
+		status   core.SessionStatus
+		override bool
+	}{
+		{"overridden", core.SessionStatusActive, true},
+		{"ended", core.SessionStatusCompleted, true},
+		{"regular", core.SessionStatusActive, false},
+	}
+	for _, session := range sessions {
+		require.NoError(t, storage.CreateSession(ctx, &core.Session{
+			ID: session.id, DeviceType: "tv", DeviceID: "tv1", ChildIDs: []string{"child1"},
+			StartTime: start, ExpectedDuration: 30, Status: session.status, OverrideDowntime: session.override,
+		}))
+	}
+	require.NoError(t, storage.Close())
+
+	// Reopening runs the migrations again, and once more to check they are not duplicated
+	for i := 0; i < 2; i++ {
+		storage, err = New(dbPath, nil)
+		require.NoError(t, err)
+		if i == 0 {
+			require.NoError(t, storage.Close())
+		}
+	}
+	t.Cleanup(func() { storage.Close() })
+
+	overrides, err := storage.ListActiveDowntimeOverrides(ctx)
+	require.NoError(t, err)
+	require.Len(t, overrides, 1, "only running sessions with a downtime override keep it")
+	assert.Equal(t, "overridden", overrides[0].SessionID)
+	assert.Equal(t, "child1", overrides[0].ChildID)
+	assert.Equal(t, "migration", overrides[0].GrantedBy)
+	assert.True(t, start.Equal(overrides[0].StartedAt))
+	assert.Nil(t, overrides[0].ExpiresAt)
+}
+
+func TestSQLiteStorage_Lockdowns(t *testing.T) {
+	storage := setupTestDB(t)
+	ctx := context.Background()
+
+	// No lockdown yet
+	active, err := storage.GetActiveLockdown(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, active)
+
+	lockdown := &core.Lockdown{
+		ID:          "lck_1",
+		Reason:      "homework",
+		TriggeredBy: "api",
+		StartedAt:   time.Now(),
+	}
+	require.NoError(t, storage.CreateLockdown(ctx, lockdown))
+
+	active, err = storage.GetActiveLockdown(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, active)
+	assert.Equal(t, "lck_1", active.ID)
+	assert.Equal(t, "homework", active.Reason)
+	assert.Equal(t, "api", active.TriggeredBy)
+	assert.True(t, active.IsActive())
+
+	// Lift
+	liftedAt := time.Now()
+	lockdown.StoppedSessions = 2
+	lockdown.LiftedAt = &liftedAt
+	lockdown.LiftedBy = "telegram:42"
+	require.NoError(t, storage.UpdateLockdown(ctx, lockdown))
+
+	active, err = storage.GetActiveLockdown(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, active)
+
+	history, err := storage.ListLockdowns(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, 2, history[0].StoppedSessions)
+	assert.Equal(t, "telegram:42", history[0].LiftedBy)
+	assert.False(t, history[0].IsActive())
+}
+
+func TestSQLiteStorage_TrackingPauses(t *testing.T) {
+	storage := setupTestDB(t)
+	ctx := context.Background()
+
+	pauses, err := storage.ListActiveTrackingPauses(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, pauses)
+
+	resumesAt := time.Now().Add(7 * 24 * time.Hour)
+	global := &core.TrackingPause{
+		ID:        "tpz_1",
+		Reason:    "grandma",
+		PausedBy:  "api",
+		StartedAt: time.Now(),
+		ResumesAt: &resumesAt,
+	}
+	require.NoError(t, storage.CreateTrackingPause(ctx, global))
+	require.NoError(t, storage.CreateTrackingPause(ctx, &core.TrackingPause{
+		ID:        "tpz_2",
+		ChildID:   "child1",
+		PausedBy:  "telegram:42",
+		StartedAt: time.Now(),
+	}))
+
+	pauses, err = storage.ListActiveTrackingPauses(ctx)
+	require.NoError(t, err)
+	require.Len(t, pauses, 2)
+
+	byID := map[string]*core.TrackingPause{}
+	for _, pause := range pauses {
+		byID[pause.ID] = pause
+	}
+	assert.True(t, byID["tpz_1"].IsGlobal())
+	assert.Equal(t, "grandma", byID["tpz_1"].Reason)
+	require.NotNil(t, byID["tpz_1"].ResumesAt)
+	assert.True(t, byID["tpz_1"].ResumesAt.Equal(resumesAt))
+	assert.Equal(t, "child1", byID["tpz_2"].ChildID)
+	assert.Nil(t, byID["tpz_2"].ResumesAt)
+
+	// Resume the global pause
+	resumedAt := time.Now()
+	global.ResumedAt = &resumedAt
+	global.ResumedBy = "telegram:42"
+	require.NoError(t, storage.UpdateTrackingPause(ctx, global))
+
+	pauses, err = storage.ListActiveTrackingPauses(ctx)
+	require.NoError(t, err)
+	require.Len(t, pauses, 1)
+	assert.Equal(t, "tpz_2", pauses[0].ID)
+}
+
+func TestSQLiteStorage_Conformance(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Storage {
+		return setupTestDB(t)
+	})
+}
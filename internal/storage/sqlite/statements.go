@@ -0,0 +1,77 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// Queries of the hot paths: the scheduler loads the active sessions and the API loads
+// sessions on every tick and request, and usage is charged for every ended session
+const (
+	sessionColumns = `id, device_type, device_id, start_time, expected_duration, actual_duration,
+			status, last_break_at, break_ends_at, warning_sent_at, last_extended_at, last_activity_at, break_minutes, break_action, break_exempt, override_downtime, override_limits, grace_ends_at, is_movie_session, end_reason, initiator_type, initiator_id, notes, activity, created_at, updated_at`
+
+	getSessionQuery = `
+		SELECT ` + sessionColumns + `
+		FROM sessions WHERE id = ?
+	`
+
+	listSessionChildrenQuery = `
+		SELECT child_id FROM session_children WHERE session_id = ?
+	`
+
+	listActiveSessionsQuery = `
+		SELECT ` + sessionColumns + `
+		FROM sessions WHERE status IN (?, ?) ORDER BY start_time DESC
+	`
+
+	incrementDailyUsageQuery = `
+		INSERT INTO daily_usage_summaries (child_id, date, minutes_used, session_count, created_at, updated_at)
+		VALUES (?, ?, ?, 0, ?, ?)
+		ON CONFLICT(child_id, date) DO UPDATE SET
+			minutes_used = minutes_used + ?,
+			updated_at = ?
+	`
+)
+
+// statements are prepared once when the database is opened, so the hot paths don't
+// re-parse their SQL on every call (see BenchmarkGetSession)
+// database/sql prepares them again on each pooled connection as needed.
+type statements struct {
+	getSession          *sql.Stmt
+	listSessionChildren *sql.Stmt
+	listActiveSessions  *sql.Stmt
+	incrementDailyUsage *sql.Stmt
+}
+
+// prepareStatements prepares the statements of the hot paths
+// The schema must exist: statements are checked against it.
+func prepareStatements(db *sql.DB) (*statements, error) {
+	stmts := &statements{}
+	for _, prepared := range []struct {
+		stmt  **sql.Stmt
+		query string
+	}{
+		{&stmts.getSession, getSessionQuery},
+		{&stmts.listSessionChildren, listSessionChildrenQuery},
+		{&stmts.listActiveSessions, listActiveSessionsQuery},
+		{&stmts.incrementDailyUsage, incrementDailyUsageQuery},
+	} {
+		stmt, err := db.Prepare(prepared.query)
+		if err != nil {
+			stmts.close()
+			return nil, fmt.Errorf("failed to prepare statement: %w", err)
+		}
+		*prepared.stmt = stmt
+	}
+	return stmts, nil
+}
+
+// close closes the prepared statements
+func (s *statements) close() {
+	for _, stmt := range []*sql.Stmt{s.getSession, s.listSessionChildren, s.listActiveSessions, s.incrementDailyUsage} {
+		if stmt != nil {
+			stmt.Close()
+		}
+	}
+}
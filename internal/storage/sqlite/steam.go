@@ -0,0 +1,43 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+)
+
+// GetSteamPlaytime returns the last seen playtime minutes of a Steam account by app ID
+// Implements steam.PlaytimeStorage interface
+func (s *SQLiteStorage) GetSteamPlaytime(ctx context.Context, steamID string) (map[string]int, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT app_id, minutes FROM steam_playtime WHERE steam_id = ?
+	`, steamID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	playtime := make(map[string]int)
+	for rows.Next() {
+		var appID string
+		var minutes int
+		if err := rows.Scan(&appID, &minutes); err != nil {
+			return nil, err
+		}
+		playtime[appID] = minutes
+	}
+	return playtime, rows.Err()
+}
+
+// SaveSteamPlaytime records the playtime minutes of a Steam account in a game
+// Implements steam.PlaytimeStorage interface
+func (s *SQLiteStorage) SaveSteamPlaytime(ctx context.Context, steamID, appID string, minutes int) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO steam_playtime (steam_id, app_id, minutes, updated_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(steam_id, app_id) DO UPDATE SET
+			minutes = excluded.minutes,
+			updated_at = excluded.updated_at
+	`, steamID, appID, minutes, time.Now())
+
+	return err
+}
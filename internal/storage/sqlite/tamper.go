@@ -0,0 +1,46 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"metron/internal/core"
+	"time"
+)
+
+// CreateTamperEvent stores a tamper event reported by a device agent
+func (s *SQLiteStorage) CreateTamperEvent(ctx context.Context, event *core.TamperEvent) error {
+	// Times are stored in UTC so received_at compares correctly as text
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO tamper_events (id, device_id, type, details, occurred_at, received_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, event.ID, event.DeviceID, event.Type, event.Details, event.OccurredAt.UTC(), event.ReceivedAt.UTC())
+
+	return err
+}
+
+// ListTamperEvents retrieves the tamper events received after since, oldest first
+func (s *SQLiteStorage) ListTamperEvents(ctx context.Context, since time.Time) ([]*core.TamperEvent, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, device_id, type, details, occurred_at, received_at
+		FROM tamper_events
+		WHERE received_at > ?
+		ORDER BY received_at, id
+	`, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []*core.TamperEvent
+	for rows.Next() {
+		var event core.TamperEvent
+		var details sql.NullString
+		if err := rows.Scan(&event.ID, &event.DeviceID, &event.Type, &details, &event.OccurredAt, &event.ReceivedAt); err != nil {
+			return nil, err
+		}
+		event.Details = details.String
+		events = append(events, &event)
+	}
+
+	return events, rows.Err()
+}
@@ -0,0 +1,84 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"metron/internal/core"
+)
+
+// ListActiveTrackingPauses retrieves the tracking pauses that have not been resumed yet
+// Pauses past their resumes_at are included; the caller decides whether they still apply
+func (s *SQLiteStorage) ListActiveTrackingPauses(ctx context.Context) ([]*core.TrackingPause, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, child_id, reason, paused_by, started_at, resumes_at, resumed_at, resumed_by
+		FROM tracking_pauses
+		WHERE resumed_at IS NULL
+		ORDER BY started_at DESC
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pauses []*core.TrackingPause
+	for rows.Next() {
+		pause, err := scanTrackingPause(rows)
+		if err != nil {
+			return nil, err
+		}
+		pauses = append(pauses, pause)
+	}
+
+	return pauses, rows.Err()
+}
+
+// CreateTrackingPause records a new tracking pause
+func (s *SQLiteStorage) CreateTrackingPause(ctx context.Context, pause *core.TrackingPause) error {
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO tracking_pauses (id, child_id, reason, paused_by, started_at, resumes_at, resumed_at, resumed_by)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, pause.ID, pause.ChildID, pause.Reason, pause.PausedBy, pause.StartedAt,
+		nullTime(pause.ResumesAt), nullTime(pause.ResumedAt), pause.ResumedBy)
+
+	return err
+}
+
+// UpdateTrackingPause updates the resume details of a tracking pause
+func (s *SQLiteStorage) UpdateTrackingPause(ctx context.Context, pause *core.TrackingPause) error {
+	_, err := s.db.ExecContext(ctx, `
+		UPDATE tracking_pauses
+		SET resumes_at = ?, resumed_at = ?, resumed_by = ?
+		WHERE id = ?
+	`, nullTime(pause.ResumesAt), nullTime(pause.ResumedAt), pause.ResumedBy, pause.ID)
+
+	return err
+}
+
+// scanTrackingPause scans a tracking pause row from either *sql.Row or *sql.Rows
+func scanTrackingPause(scanner interface{ Scan(dest ...any) error }) (*core.TrackingPause, error) {
+	var pause core.TrackingPause
+	var reason sql.NullString
+	var resumesAt sql.NullTime
+	var resumedAt sql.NullTime
+	var resumedBy sql.NullString
+
+	if err := scanner.Scan(&pause.ID, &pause.ChildID, &reason, &pause.PausedBy, &pause.StartedAt,
+		&resumesAt, &resumedAt, &resumedBy); err != nil {
+		return nil, err
+	}
+
+	if reason.Valid {
+		pause.Reason = reason.String
+	}
+	if resumesAt.Valid {
+		pause.ResumesAt = &resumesAt.Time
+	}
+	if resumedAt.Valid {
+		pause.ResumedAt = &resumedAt.Time
+	}
+	if resumedBy.Valid {
+		pause.ResumedBy = resumedBy.String
+	}
+
+	return &pause, nil
+}
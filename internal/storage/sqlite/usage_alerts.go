@@ -0,0 +1,57 @@
+package sqlite
+
+import (
+	"context"
+	"metron/internal/core"
+	"time"
+)
+
+// CreateUsageAlert stores a usage threshold alert
+func (s *SQLiteStorage) CreateUsageAlert(ctx context.Context, alert *core.UsageAlert) error {
+	// Times are stored in UTC so created_at compares correctly as text
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO usage_alerts (id, child_id, date, threshold, used_minutes, limit_minutes, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, alert.ID, alert.ChildID, s.normalizeDate(alert.Date).Format("2006-01-02"), alert.Threshold, alert.UsedMinutes, alert.LimitMinutes, alert.CreatedAt.UTC())
+
+	return err
+}
+
+// ListUsageAlerts retrieves the usage alerts created after since, oldest first
+func (s *SQLiteStorage) ListUsageAlerts(ctx context.Context, since time.Time) ([]*core.UsageAlert, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT id, child_id, date, threshold, used_minutes, limit_minutes, created_at
+		FROM usage_alerts
+		WHERE created_at > ?
+		ORDER BY created_at, id
+	`, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []*core.UsageAlert
+	for rows.Next() {
+		var alert core.UsageAlert
+		var date string
+		if err := rows.Scan(&alert.ID, &alert.ChildID, &date, &alert.Threshold, &alert.UsedMinutes, &alert.LimitMinutes, &alert.CreatedAt); err != nil {
+			return nil, err
+		}
+		if alert.Date, err = time.ParseInLocation("2006-01-02", date, s.timezone); err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, &alert)
+	}
+
+	return alerts, rows.Err()
+}
+
+// MaxUsageAlertThreshold returns the highest threshold alerted for a child on a date, 0 if none
+func (s *SQLiteStorage) MaxUsageAlertThreshold(ctx context.Context, childID string, date time.Time) (int, error) {
+	var threshold int
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COALESCE(MAX(threshold), 0) FROM usage_alerts WHERE child_id = ? AND date = ?
+	`, childID, s.normalizeDate(date).Format("2006-01-02")).Scan(&threshold)
+
+	return threshold, err
+}
@@ -9,21 +9,27 @@ import (
 // Storage defines the interface for core data persistence
 // Driver-specific storage needs (like Aqara tokens) should use separate interfaces
 type Storage interface {
+	Reader
+	Writer
+
+	// Lifecycle
+	Close() error
+}
+
+// Reader defines the queries of Storage
+// Reporting endpoints only need a Reader, so they can be pointed at a read replica or a
+// read-only connection (see sqlite.NewReadOnly) instead of contending with the scheduler's writes.
+type Reader interface {
 	// Children
-	CreateChild(ctx context.Context, child *core.Child) error
 	GetChild(ctx context.Context, id string) (*core.Child, error)
 	ListChildren(ctx context.Context) ([]*core.Child, error)
-	UpdateChild(ctx context.Context, child *core.Child) error
-	DeleteChild(ctx context.Context, id string) error
 
 	// Sessions
-	CreateSession(ctx context.Context, session *core.Session) error
 	GetSession(ctx context.Context, id string) (*core.Session, error)
 	ListActiveSessions(ctx context.Context) ([]*core.Session, error)
 	ListAllSessions(ctx context.Context) ([]*core.Session, error)
 	ListSessionsByChild(ctx context.Context, childID string) ([]*core.Session, error)
-	UpdateSession(ctx context.Context, session *core.Session) error
-	DeleteSession(ctx context.Context, id string) error
+	ListSessionsSince(ctx context.Context, since time.Time) ([]*core.Session, error) // Sessions started at or after since, newest first
 
 	// ============================================================================
 	// Storage Methods - Refactored Architecture
@@ -31,34 +37,109 @@ type Storage interface {
 
 	// Daily Time Allocation - stores what time is available
 	GetDailyAllocation(ctx context.Context, childID string, date time.Time) (*core.DailyTimeAllocation, error)
-	CreateDailyAllocation(ctx context.Context, allocation *core.DailyTimeAllocation) error
-	UpdateDailyAllocation(ctx context.Context, allocation *core.DailyTimeAllocation) error
+	ListDailyAllocations(ctx context.Context, date time.Time) ([]*core.DailyTimeAllocation, error) // All children's allocations for the day
 
 	// Daily Usage Summary - stores what time was consumed
 	GetDailyUsageSummary(ctx context.Context, childID string, date time.Time) (*core.DailyUsageSummary, error)
-	IncrementDailyUsageSummary(ctx context.Context, childID string, date time.Time, minutes int) error
-	IncrementSessionCountSummary(ctx context.Context, childID string, date time.Time) error
+	ListDailyUsageSummaries(ctx context.Context, date time.Time) ([]*core.DailyUsageSummary, error) // Stored summaries for the day; children without usage are missing
 
 	// Session Usage Records - stores session history
 	ListActiveSessionRecords(ctx context.Context) ([]*core.SessionUsageRecord, error)
 
 	// Device Bypass - stores bypass mode for agent-controlled devices
 	GetDeviceBypass(ctx context.Context, deviceID string) (*core.DeviceBypass, error)
-	SetDeviceBypass(ctx context.Context, bypass *core.DeviceBypass) error
-	ClearDeviceBypass(ctx context.Context, deviceID string) error
 	ListActiveBypassDevices(ctx context.Context) ([]*core.DeviceBypass, error)
 
 	// Movie Time Usage - stores weekend shared movie time usage
 	GetMovieTimeUsage(ctx context.Context, date time.Time) (*core.MovieTimeUsage, error)
-	SaveMovieTimeUsage(ctx context.Context, usage *core.MovieTimeUsage) error
 
 	// Movie Time Bypass - stores bypass periods for holidays/vacations
-	CreateMovieTimeBypass(ctx context.Context, bypass *core.MovieTimeBypass) error
 	GetMovieTimeBypass(ctx context.Context, id string) (*core.MovieTimeBypass, error)
 	ListMovieTimeBypasses(ctx context.Context) ([]*core.MovieTimeBypass, error)
 	ListActiveMovieTimeBypasses(ctx context.Context, date time.Time) ([]*core.MovieTimeBypass, error)
+
+	// Lockdown - stores emergency lockdown history
+	GetActiveLockdown(ctx context.Context) (*core.Lockdown, error)
+	ListLockdowns(ctx context.Context, limit int) ([]*core.Lockdown, error)
+
+	// Tracking Pause - stores vacation mode pauses (global or per child)
+	ListActiveTrackingPauses(ctx context.Context) ([]*core.TrackingPause, error)
+
+	// Device Heartbeats - stores when each device last checked in
+	ListDeviceHeartbeats(ctx context.Context) ([]*core.DeviceHeartbeat, error)
+
+	// Tamper Events - possible tampering reported by device agents
+	ListTamperEvents(ctx context.Context, since time.Time) ([]*core.TamperEvent, error) // Received after since, oldest first
+
+	// Limit Changes - immediate and scheduled changes of children's limits (history)
+	ListLimitChanges(ctx context.Context, childID string) ([]*core.LimitChange, error) // Oldest effective date first; empty childID lists all
+
+	// Audit Log - who changed what and when
+	ListAuditEntries(ctx context.Context, childID string, limit int) ([]*core.AuditEntry, error) // Newest first; empty childID lists all
+
+	// Profile Transitions - age-based limit profiles proposed on birthdays
+	GetProfileTransition(ctx context.Context, id string) (*core.ProfileTransition, error)
+	ListProfileTransitions(ctx context.Context, status string) ([]*core.ProfileTransition, error) // Oldest first; empty status lists all
+}
+
+// Writer defines the changes of Storage
+type Writer interface {
+	// Children
+	CreateChild(ctx context.Context, child *core.Child) error
+	UpdateChild(ctx context.Context, child *core.Child) error
+	DeleteChild(ctx context.Context, id string) error
+
+	// Sessions
+	CreateSession(ctx context.Context, session *core.Session) error
+	UpdateSession(ctx context.Context, session *core.Session) error
+	DeleteSession(ctx context.Context, id string) error
+
+	// Daily Time Allocation
+	CreateDailyAllocation(ctx context.Context, allocation *core.DailyTimeAllocation) error
+	UpdateDailyAllocation(ctx context.Context, allocation *core.DailyTimeAllocation) error
+
+	// Daily Usage Summary
+	IncrementDailyUsageSummary(ctx context.Context, childID string, date time.Time, minutes int) error
+	IncrementSessionCountSummary(ctx context.Context, childID string, date time.Time) error
+
+	// Device Bypass
+	SetDeviceBypass(ctx context.Context, bypass *core.DeviceBypass) error
+	ClearDeviceBypass(ctx context.Context, deviceID string) error
+
+	// Movie Time Usage
+	SaveMovieTimeUsage(ctx context.Context, usage *core.MovieTimeUsage) error
+
+	// Movie Time Bypass
+	CreateMovieTimeBypass(ctx context.Context, bypass *core.MovieTimeBypass) error
 	DeleteMovieTimeBypass(ctx context.Context, id string) error
 
-	// Lifecycle
-	Close() error
+	// Lockdown
+	CreateLockdown(ctx context.Context, lockdown *core.Lockdown) error
+	UpdateLockdown(ctx context.Context, lockdown *core.Lockdown) error
+
+	// Tracking Pause
+	CreateTrackingPause(ctx context.Context, pause *core.TrackingPause) error
+	UpdateTrackingPause(ctx context.Context, pause *core.TrackingPause) error
+
+	// Device Heartbeats
+	SaveDeviceHeartbeat(ctx context.Context, heartbeat *core.DeviceHeartbeat) error
+
+	// Tamper Events
+	CreateTamperEvent(ctx context.Context, event *core.TamperEvent) error
+
+	// Limit Changes
+	CreateLimitChange(ctx context.Context, change *core.LimitChange) error
+	UpdateLimitChange(ctx context.Context, change *core.LimitChange) error
+	DeleteLimitChange(ctx context.Context, id string) error
+
+	// Audit Log
+	CreateAuditEntry(ctx context.Context, entry *core.AuditEntry) error
+
+	// Profile Transitions
+	CreateProfileTransition(ctx context.Context, transition *core.ProfileTransition) error
+	UpdateProfileTransition(ctx context.Context, transition *core.ProfileTransition) error
+
+	// Session Claims - short-lived claims serializing session changes across processes
+	ClaimSession(ctx context.Context, sessionID, owner string, until time.Time) (bool, error)
+	ReleaseSessionClaim(ctx context.Context, sessionID, owner string) error
 }
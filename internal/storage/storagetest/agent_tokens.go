@@ -0,0 +1,86 @@
+package storagetest
+
+import (
+	"context"
+	"metron/internal/core"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newAgentToken(id, deviceID, hash string, createdAt time.Time) *core.AgentToken {
+	return &core.AgentToken{
+		ID:        id,
+		DeviceID:  deviceID,
+		TokenHash: hash,
+		Hint:      hash[len(hash)-4:],
+		IssuedBy:  "api",
+		CreatedAt: createdAt,
+	}
+}
+
+func testAgentTokens(t *testing.T, s core.AgentTokenStorage) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	tokens, err := s.ListAgentTokens(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, tokens)
+
+	first := newAgentToken("agt_1", "win-pc1", core.HashAgentToken("first"), now.Add(-time.Hour))
+	first.Label = "Alice's laptop"
+	require.NoError(t, s.CreateAgentToken(ctx, first))
+	require.NoError(t, s.CreateAgentToken(ctx, newAgentToken("agt_2", "win-pc2", core.HashAgentToken("second"), now)))
+
+	token, err := s.GetAgentToken(ctx, "agt_1")
+	require.NoError(t, err)
+	assert.Equal(t, "win-pc1", token.DeviceID)
+	assert.Equal(t, "Alice's laptop", token.Label)
+	assert.Equal(t, first.Hint, token.Hint)
+	assert.Equal(t, "api", token.IssuedBy)
+	assert.Nil(t, token.LastUsedAt)
+	assert.Nil(t, token.RevokedAt)
+
+	token, err = s.GetAgentTokenByHash(ctx, core.HashAgentToken("second"))
+	require.NoError(t, err)
+	assert.Equal(t, "agt_2", token.ID)
+
+	// Newest first
+	tokens, err = s.ListAgentTokens(ctx)
+	require.NoError(t, err)
+	require.Len(t, tokens, 2)
+	assert.Equal(t, "agt_2", tokens[0].ID)
+	assert.Equal(t, "agt_1", tokens[1].ID)
+
+	// Usage and revocation are updated in place
+	lastUsed := now.Add(-time.Minute)
+	revoked := now
+	first.LastUsedAt = &lastUsed
+	first.RevokedAt = &revoked
+	first.RevokedBy = "telegram:42"
+	require.NoError(t, s.UpdateAgentToken(ctx, first))
+
+	token, err = s.GetAgentTokenByHash(ctx, core.HashAgentToken("first"))
+	require.NoError(t, err)
+	require.NotNil(t, token.LastUsedAt)
+	assert.True(t, token.LastUsedAt.Equal(lastUsed))
+	require.NotNil(t, token.RevokedAt)
+	assert.True(t, token.RevokedAt.Equal(revoked))
+	assert.Equal(t, "telegram:42", token.RevokedBy)
+	assert.True(t, token.IsRevoked())
+}
+
+func testAgentTokenNotFound(t *testing.T, s core.AgentTokenStorage) {
+	ctx := context.Background()
+
+	_, err := s.GetAgentToken(ctx, "agt_missing")
+	assert.ErrorIs(t, err, core.ErrAgentTokenNotFound)
+
+	_, err = s.GetAgentTokenByHash(ctx, core.HashAgentToken("missing"))
+	assert.ErrorIs(t, err, core.ErrAgentTokenNotFound)
+
+	err = s.UpdateAgentToken(ctx, newAgentToken("agt_missing", "win-pc1", core.HashAgentToken("missing"), time.Now()))
+	assert.ErrorIs(t, err, core.ErrAgentTokenNotFound)
+}
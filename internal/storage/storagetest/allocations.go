@@ -0,0 +1,55 @@
+package storagetest
+
+import (
+	"context"
+	"metron/internal/core"
+	"metron/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// AllocationStorage is a storage backend that also lists allocation history
+type AllocationStorage interface {
+	storage.Storage
+	core.AllocationStorage
+}
+
+func testAllocations(t *testing.T, s AllocationStorage) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	createChildren(t, s, newChild("alice", "Alice"), newChild("bob", "Bob"))
+
+	// Created out of order, the history is oldest first
+	for _, offset := range []int{0, -3, -1, -2} {
+		require.NoError(t, s.CreateDailyAllocation(ctx, &core.DailyTimeAllocation{
+			ChildID: "alice", Date: today.AddDate(0, 0, offset), BaseLimit: 60 - offset, CreatedAt: now, UpdatedAt: now,
+		}))
+	}
+	require.NoError(t, s.CreateDailyAllocation(ctx, &core.DailyTimeAllocation{
+		ChildID: "bob", Date: today.AddDate(0, 0, -1), BaseLimit: 120, CreatedAt: now, UpdatedAt: now,
+	}))
+
+	// Both ends are included, any time of the day matches
+	allocations, err := s.ListChildAllocations(ctx, "alice", today.AddDate(0, 0, -2).Add(15*time.Hour), today.Add(time.Hour))
+	require.NoError(t, err)
+	require.Len(t, allocations, 3)
+	for i, offset := range []int{-2, -1, 0} {
+		assert.Equal(t, "alice", allocations[i].ChildID)
+		assert.Equal(t, today.AddDate(0, 0, offset).Format("2006-01-02"), allocations[i].Date.Format("2006-01-02"))
+		assert.Equal(t, 60-offset, allocations[i].BaseLimit)
+	}
+
+	// Other children are separate
+	allocations, err = s.ListChildAllocations(ctx, "bob", today.AddDate(0, 0, -3), today)
+	require.NoError(t, err)
+	require.Len(t, allocations, 1)
+	assert.Equal(t, 120, allocations[0].BaseLimit)
+
+	allocations, err = s.ListChildAllocations(ctx, "alice", today.AddDate(0, 0, 1), today.AddDate(0, 0, 7))
+	require.NoError(t, err)
+	assert.Empty(t, allocations)
+}
@@ -0,0 +1,71 @@
+package storagetest
+
+import (
+	"context"
+	"metron/internal/core"
+	"metron/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// AppUsageStorage is a storage backend that also stores app usage
+type AppUsageStorage interface {
+	storage.Storage
+	core.AppUsageStorage
+}
+
+func testAppUsage(t *testing.T, s AppUsageStorage) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	yesterday := today.AddDate(0, 0, -1)
+	createChildren(t, s, newChild("alice", "Alice"), newChild("bob", "Bob"))
+
+	usage, err := s.ListAppUsage(ctx, "", yesterday, today)
+	require.NoError(t, err)
+	assert.Empty(t, usage)
+
+	require.NoError(t, s.SaveAppUsage(ctx, &core.AppUsage{
+		ChildID: "alice", Date: today, Source: "youtube", App: "YouTube", Minutes: 20, UpdatedAt: now,
+	}))
+	require.NoError(t, s.SaveAppUsage(ctx, &core.AppUsage{
+		ChildID: "alice", Date: yesterday.Add(18 * time.Hour), Source: "youtube", App: "YouTube", Minutes: 45, UpdatedAt: now,
+	}))
+	require.NoError(t, s.SaveAppUsage(ctx, &core.AppUsage{
+		ChildID: "bob", Date: today, Source: "phone", App: "Minecraft", Minutes: 30, UpdatedAt: now,
+	}))
+
+	// Reports replace the day's minutes of the app
+	require.NoError(t, s.SaveAppUsage(ctx, &core.AppUsage{
+		ChildID: "alice", Date: today, Source: "youtube", App: "YouTube", Minutes: 35, UpdatedAt: now,
+	}))
+
+	// Oldest first
+	usage, err = s.ListAppUsage(ctx, "", yesterday, today)
+	require.NoError(t, err)
+	require.Len(t, usage, 3)
+	assert.Equal(t, yesterday.Format("2006-01-02"), usage[0].Date.Format("2006-01-02"), "any time of the day matches")
+	assert.Equal(t, 45, usage[0].Minutes)
+	assert.Equal(t, today.Format("2006-01-02"), usage[1].Date.Format("2006-01-02"))
+	assert.Equal(t, today.Format("2006-01-02"), usage[2].Date.Format("2006-01-02"))
+
+	usage, err = s.ListAppUsage(ctx, "alice", today, today)
+	require.NoError(t, err)
+	require.Len(t, usage, 1)
+	assert.Equal(t, "alice", usage[0].ChildID)
+	assert.Equal(t, "youtube", usage[0].Source)
+	assert.Equal(t, "YouTube", usage[0].App)
+	assert.Equal(t, 35, usage[0].Minutes)
+	assert.True(t, usage[0].UpdatedAt.Equal(now))
+
+	// Deleting a child deletes its app usage
+	require.NoError(t, s.DeleteChild(ctx, "alice"))
+	usage, err = s.ListAppUsage(ctx, "", yesterday, today)
+	require.NoError(t, err)
+	require.Len(t, usage, 1)
+	assert.Equal(t, "bob", usage[0].ChildID)
+	assert.Equal(t, "Minecraft", usage[0].App)
+}
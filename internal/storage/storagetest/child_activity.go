@@ -0,0 +1,75 @@
+package storagetest
+
+import (
+	"context"
+	"metron/internal/core"
+	"metron/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ChildActivityStorage is a storage backend that also stores children's activity feeds
+type ChildActivityStorage interface {
+	storage.Storage
+	core.ChildActivityStorage
+}
+
+func testChildActivity(t *testing.T, s ChildActivityStorage) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	createChildren(t, s, newChild("alice", "Alice"), newChild("bob", "Bob"))
+
+	activities, err := s.ListChildActivity(ctx, "alice", 10)
+	require.NoError(t, err)
+	assert.Empty(t, activities)
+
+	require.NoError(t, s.CreateChildActivity(ctx, &core.ChildActivity{
+		ID: "act_1", ChildID: "alice", Kind: core.ActivitySessionStarted, Message: "Started 30 minutes on tv",
+		SessionID: "sess_1", Minutes: 30, CreatedAt: now.Add(-time.Hour),
+	}))
+	require.NoError(t, s.CreateChildActivity(ctx, &core.ChildActivity{
+		ID: "act_2", ChildID: "alice", Kind: core.ActivitySessionEnded, Message: "Your session on tv ran out after 30 minutes",
+		SessionID: "sess_1", Minutes: 30, CreatedAt: now,
+	}))
+	require.NoError(t, s.CreateChildActivity(ctx, &core.ChildActivity{
+		ID: "act_3", ChildID: "alice", Kind: core.ActivityReward, Message: "A parent gave you 15 minutes of bonus time",
+		Minutes: 15, CreatedAt: now.Add(-30 * time.Minute),
+	}))
+	require.NoError(t, s.CreateChildActivity(ctx, &core.ChildActivity{
+		ID: "act_4", ChildID: "bob", Kind: core.ActivityFine, Message: "A parent took away 10 minutes",
+		Minutes: 10, CreatedAt: now,
+	}))
+
+	// Newest first, only the child's
+	activities, err = s.ListChildActivity(ctx, "alice", 10)
+	require.NoError(t, err)
+	require.Len(t, activities, 3)
+	assert.Equal(t, "act_2", activities[0].ID)
+	assert.Equal(t, "act_3", activities[1].ID)
+	assert.Equal(t, "act_1", activities[2].ID)
+	assert.Equal(t, core.ActivitySessionEnded, activities[0].Kind)
+	assert.Equal(t, "Your session on tv ran out after 30 minutes", activities[0].Message)
+	assert.Equal(t, "sess_1", activities[0].SessionID)
+	assert.Equal(t, 30, activities[0].Minutes)
+	assert.True(t, activities[0].CreatedAt.Equal(now))
+	assert.Empty(t, activities[1].SessionID)
+
+	// Limited to the latest
+	activities, err = s.ListChildActivity(ctx, "alice", 2)
+	require.NoError(t, err)
+	require.Len(t, activities, 2)
+	assert.Equal(t, "act_2", activities[0].ID)
+	assert.Equal(t, "act_3", activities[1].ID)
+
+	// Deleting a child deletes its activity
+	require.NoError(t, s.DeleteChild(ctx, "alice"))
+	activities, err = s.ListChildActivity(ctx, "alice", 10)
+	require.NoError(t, err)
+	assert.Empty(t, activities)
+	activities, err = s.ListChildActivity(ctx, "bob", 10)
+	require.NoError(t, err)
+	assert.Len(t, activities, 1)
+}
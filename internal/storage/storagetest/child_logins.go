@@ -0,0 +1,111 @@
+package storagetest
+
+import (
+	"context"
+	"metron/internal/core"
+	"metron/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// ChildLoginStorage is a storage backend that also stores child web app logins
+type ChildLoginStorage interface {
+	storage.Storage
+	core.ChildLoginStorage
+}
+
+func newChildLogin(id, childID, token string, createdAt time.Time) *core.ChildLogin {
+	return &core.ChildLogin{
+		ID:        id,
+		ChildID:   childID,
+		TokenHash: core.HashChildLoginToken(token),
+		UserAgent: "Mozilla/5.0",
+		CreatedAt: createdAt,
+		ExpiresAt: createdAt.Add(core.ChildLoginDuration),
+	}
+}
+
+func testChildLogins(t *testing.T, s ChildLoginStorage) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	createChildren(t, s, newChild("alice", "Alice"), newChild("bob", "Bob"))
+
+	logins, err := s.ListChildLogins(ctx, "")
+	require.NoError(t, err)
+	assert.Empty(t, logins)
+
+	first := newChildLogin("cls_1", "alice", "first", now.Add(-2*time.Hour))
+	require.NoError(t, s.CreateChildLogin(ctx, first))
+	require.NoError(t, s.CreateChildLogin(ctx, newChildLogin("cls_2", "alice", "second", now.Add(-time.Hour))))
+	require.NoError(t, s.CreateChildLogin(ctx, newChildLogin("cls_3", "bob", "third", now)))
+	// Expired two days ago
+	require.NoError(t, s.CreateChildLogin(ctx, newChildLogin("cls_4", "bob", "fourth", now.Add(-3*core.ChildLoginDuration))))
+
+	login, err := s.GetChildLogin(ctx, "cls_1")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", login.ChildID)
+	assert.Equal(t, "Mozilla/5.0", login.UserAgent)
+	assert.True(t, login.ExpiresAt.Equal(first.ExpiresAt))
+	assert.Nil(t, login.LastSeenAt)
+	assert.Nil(t, login.RevokedAt)
+
+	login, err = s.GetChildLoginByHash(ctx, core.HashChildLoginToken("third"))
+	require.NoError(t, err)
+	assert.Equal(t, "cls_3", login.ID)
+
+	// Newest first, per child or for all children
+	logins, err = s.ListChildLogins(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, logins, 2)
+	assert.Equal(t, "cls_2", logins[0].ID)
+	assert.Equal(t, "cls_1", logins[1].ID)
+
+	logins, err = s.ListChildLogins(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, logins, 4)
+
+	// Last seen and revocation are updated in place
+	lastSeen := now.Add(-time.Minute)
+	revoked := now
+	first.LastSeenAt = &lastSeen
+	first.RevokedAt = &revoked
+	first.RevokedBy = "telegram:42"
+	require.NoError(t, s.UpdateChildLogin(ctx, first))
+
+	login, err = s.GetChildLoginByHash(ctx, core.HashChildLoginToken("first"))
+	require.NoError(t, err)
+	require.NotNil(t, login.LastSeenAt)
+	assert.True(t, login.LastSeenAt.Equal(lastSeen))
+	require.NotNil(t, login.RevokedAt)
+	assert.True(t, login.RevokedAt.Equal(revoked))
+	assert.Equal(t, "telegram:42", login.RevokedBy)
+
+	// Only expired logins are deleted
+	deleted, err := s.DeleteExpiredChildLogins(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1, deleted)
+	_, err = s.GetChildLogin(ctx, "cls_4")
+	assert.ErrorIs(t, err, core.ErrChildLoginNotFound)
+
+	// Deleting a child deletes its logins
+	require.NoError(t, s.DeleteChild(ctx, "bob"))
+	logins, err = s.ListChildLogins(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, logins, 2)
+}
+
+func testChildLoginNotFound(t *testing.T, s ChildLoginStorage) {
+	ctx := context.Background()
+
+	_, err := s.GetChildLogin(ctx, "cls_missing")
+	assert.ErrorIs(t, err, core.ErrChildLoginNotFound)
+
+	_, err = s.GetChildLoginByHash(ctx, core.HashChildLoginToken("missing"))
+	assert.ErrorIs(t, err, core.ErrChildLoginNotFound)
+
+	err = s.UpdateChildLogin(ctx, newChildLogin("cls_missing", "alice", "missing", time.Now()))
+	assert.ErrorIs(t, err, core.ErrChildLoginNotFound)
+}
@@ -0,0 +1,40 @@
+package storagetest
+
+import (
+	"context"
+	"metron/internal/credentials"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testCredentials(t *testing.T, s credentials.Store) {
+	ctx := context.Background()
+
+	data, err := s.GetCredential(ctx, "kidslox")
+	require.NoError(t, err)
+	assert.Nil(t, data, "nothing stored yet")
+
+	// Saves replace the driver's previous credential
+	require.NoError(t, s.SaveCredential(ctx, "kidslox", []byte(`{"token":"one"}`)))
+	require.NoError(t, s.SaveCredential(ctx, "kidslox", []byte(`{"token":"two"}`)))
+	require.NoError(t, s.SaveCredential(ctx, "steam", []byte(`{"api_key":"k"}`)))
+
+	data, err = s.GetCredential(ctx, "kidslox")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"token":"two"}`, string(data))
+
+	// Drivers are separate
+	var steamKey struct {
+		APIKey string `json:"api_key"`
+	}
+	found, err := credentials.Get(ctx, s, "steam", &steamKey)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "k", steamKey.APIKey)
+
+	found, err = credentials.Get(ctx, s, "familylink", &steamKey)
+	require.NoError(t, err)
+	assert.False(t, found)
+}
@@ -0,0 +1,86 @@
+package storagetest
+
+import (
+	"context"
+	"metron/internal/core"
+	"metron/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// DayRolloverStorage is a storage backend that also stores day rollovers
+type DayRolloverStorage interface {
+	storage.Storage
+	core.DayRolloverStorage
+}
+
+func testDayRollovers(t *testing.T, s DayRolloverStorage) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	yesterday := today.AddDate(0, 0, -1)
+	createChildren(t, s, newChild("alice", "Alice"), newChild("bob", "Bob"))
+
+	_, err := s.GetDayRollover(ctx, "alice", yesterday)
+	assert.ErrorIs(t, err, core.ErrDayRolloverNotFound)
+
+	require.NoError(t, s.CreateDayRollover(ctx, &core.DayRollover{
+		ID: "dro_2", ChildID: "alice", Date: yesterday, LimitMinutes: 90,
+		UsedMinutes: 75, SessionCount: 3, CreatedAt: now,
+	}))
+	require.NoError(t, s.CreateDayRollover(ctx, &core.DayRollover{
+		ID: "dro_1", ChildID: "bob", Date: yesterday, LimitMinutes: 60,
+		UsedMinutes: 0, CreatedAt: now.Add(-time.Hour),
+	}))
+
+	// A day is closed out once per child
+	err = s.CreateDayRollover(ctx, &core.DayRollover{
+		ID: "dro_3", ChildID: "alice", Date: yesterday.Add(12 * time.Hour), LimitMinutes: 90, CreatedAt: now,
+	})
+	assert.Error(t, err)
+
+	rollover, err := s.GetDayRollover(ctx, "alice", yesterday.Add(15*time.Hour))
+	require.NoError(t, err, "any time of the day matches")
+	assert.Equal(t, "dro_2", rollover.ID)
+	assert.Equal(t, yesterday.Format("2006-01-02"), rollover.Date.Format("2006-01-02"))
+	assert.Equal(t, 90, rollover.LimitMinutes)
+	assert.Equal(t, 75, rollover.UsedMinutes)
+	assert.Equal(t, 3, rollover.SessionCount)
+	assert.True(t, rollover.CreatedAt.Equal(now))
+
+	_, err = s.GetDayRollover(ctx, "alice", today)
+	assert.ErrorIs(t, err, core.ErrDayRolloverNotFound)
+
+	// Corrections update the day's usage
+	rollover.UsedMinutes, rollover.SessionCount = 40, 2
+	require.NoError(t, s.UpdateDayRollover(ctx, rollover))
+	rollover, err = s.GetDayRollover(ctx, "alice", yesterday)
+	require.NoError(t, err)
+	assert.Equal(t, 40, rollover.UsedMinutes)
+	assert.Equal(t, 2, rollover.SessionCount)
+	assert.Equal(t, 90, rollover.LimitMinutes)
+	assert.ErrorIs(t, s.UpdateDayRollover(ctx, &core.DayRollover{ID: "dro_missing"}), core.ErrDayRolloverNotFound)
+
+	// Oldest first
+	rollovers, err := s.ListDayRollovers(ctx, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, rollovers, 2)
+	assert.Equal(t, "dro_1", rollovers[0].ID)
+	assert.Equal(t, "dro_2", rollovers[1].ID)
+
+	// Only rollovers created after since
+	rollovers, err = s.ListDayRollovers(ctx, now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, rollovers, 1)
+	assert.Equal(t, "dro_2", rollovers[0].ID)
+
+	// Deleting a child deletes its rollovers
+	require.NoError(t, s.DeleteChild(ctx, "alice"))
+	rollovers, err = s.ListDayRollovers(ctx, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, rollovers, 1)
+	assert.Equal(t, "dro_1", rollovers[0].ID)
+}
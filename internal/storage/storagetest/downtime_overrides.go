@@ -0,0 +1,99 @@
+package storagetest
+
+import (
+	"context"
+	"metron/internal/core"
+	"metron/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// DowntimeOverrideStorage is a storage backend that also stores downtime overrides
+type DowntimeOverrideStorage interface {
+	storage.Storage
+	core.DowntimeOverrideStorage
+}
+
+func testDowntimeOverrides(t *testing.T, s DowntimeOverrideStorage) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	createChildren(t, s, newChild("alice", "Alice"), newChild("bob", "Bob"))
+
+	overrides, err := s.ListActiveDowntimeOverrides(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, overrides)
+
+	endOfDay := now.Add(3 * time.Hour)
+	day := &core.DowntimeOverride{
+		ID:        "dto_day",
+		ChildID:   "alice",
+		Reason:    "Sleepover",
+		GrantedBy: "telegram:42",
+		StartedAt: now,
+		ExpiresAt: &endOfDay,
+	}
+	require.NoError(t, s.CreateDowntimeOverride(ctx, day))
+	require.NoError(t, s.CreateDowntimeOverride(ctx, &core.DowntimeOverride{
+		ID:        "dto_session",
+		ChildID:   "bob",
+		SessionID: "sess_1",
+		GrantedBy: "api",
+		StartedAt: now.Add(-time.Hour),
+	}))
+
+	override, err := s.GetDowntimeOverride(ctx, "dto_day")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", override.ChildID)
+	assert.Empty(t, override.SessionID)
+	assert.Equal(t, "Sleepover", override.Reason)
+	assert.Equal(t, "telegram:42", override.GrantedBy)
+	assert.True(t, override.StartedAt.Equal(now))
+	require.NotNil(t, override.ExpiresAt)
+	assert.True(t, override.ExpiresAt.Equal(endOfDay))
+	assert.Nil(t, override.EndedAt)
+
+	// Oldest first
+	overrides, err = s.ListActiveDowntimeOverrides(ctx)
+	require.NoError(t, err)
+	require.Len(t, overrides, 2)
+	assert.Equal(t, "dto_session", overrides[0].ID)
+	assert.Equal(t, "sess_1", overrides[0].SessionID)
+	assert.Nil(t, overrides[0].ExpiresAt)
+	assert.Equal(t, "dto_day", overrides[1].ID)
+
+	// Ended overrides are no longer listed
+	ended := now.Add(time.Minute)
+	day.EndedAt = &ended
+	day.EndedBy = "api"
+	require.NoError(t, s.UpdateDowntimeOverride(ctx, day))
+
+	override, err = s.GetDowntimeOverride(ctx, "dto_day")
+	require.NoError(t, err)
+	require.NotNil(t, override.EndedAt)
+	assert.True(t, override.EndedAt.Equal(ended))
+	assert.Equal(t, "api", override.EndedBy)
+
+	overrides, err = s.ListActiveDowntimeOverrides(ctx)
+	require.NoError(t, err)
+	require.Len(t, overrides, 1)
+	assert.Equal(t, "dto_session", overrides[0].ID)
+
+	// Deleting a child deletes its overrides
+	require.NoError(t, s.DeleteChild(ctx, "bob"))
+	overrides, err = s.ListActiveDowntimeOverrides(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, overrides)
+}
+
+func testDowntimeOverrideNotFound(t *testing.T, s DowntimeOverrideStorage) {
+	ctx := context.Background()
+
+	_, err := s.GetDowntimeOverride(ctx, "dto_missing")
+	assert.ErrorIs(t, err, core.ErrDowntimeOverrideNotFound)
+
+	err = s.UpdateDowntimeOverride(ctx, &core.DowntimeOverride{ID: "dto_missing"})
+	assert.ErrorIs(t, err, core.ErrDowntimeOverrideNotFound)
+}
@@ -0,0 +1,73 @@
+package storagetest
+
+import (
+	"context"
+	"metron/internal/core"
+	"metron/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// DriverCallStorage is a storage backend that also records driver calls
+type DriverCallStorage interface {
+	storage.Storage
+	core.DriverCallStorage
+}
+
+func testDriverCalls(t *testing.T, s DriverCallStorage) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	calls, err := s.ListDriverCalls(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, calls)
+
+	require.NoError(t, s.CreateDriverCall(ctx, &core.DriverCall{
+		ID: "dcl_1", Driver: "aqara", DeviceID: "tv1", SessionID: "sess_1", Action: core.DriverActionStart,
+		StartedAt: now.Add(-2 * time.Minute), Duration: 850 * time.Millisecond, Result: core.DriverCallOK,
+	}))
+	require.NoError(t, s.CreateDriverCall(ctx, &core.DriverCall{
+		ID: "dcl_3", Driver: "aqara", DeviceID: "tv1", SessionID: "sess_1", Action: core.DriverActionStop,
+		StartedAt: now, Duration: 30 * time.Second, Result: core.DriverCallTimedOut, Error: "driver call timed out",
+	}))
+	require.NoError(t, s.CreateDriverCall(ctx, &core.DriverCall{
+		ID: "dcl_2", Driver: "kidslox", DeviceID: "ipad1", SessionID: "sess_2", Action: core.DriverActionWarning,
+		StartedAt: now.Add(-time.Minute), Duration: 120 * time.Millisecond, Result: core.DriverCallFailed, Error: "profile not found",
+	}))
+	assert.Error(t, s.CreateDriverCall(ctx, &core.DriverCall{
+		ID: "dcl_1", Driver: "aqara", Action: core.DriverActionStop, StartedAt: now, Result: core.DriverCallOK,
+	}), "IDs are unique")
+
+	// Newest first by start time
+	calls, err = s.ListDriverCalls(ctx)
+	require.NoError(t, err)
+	require.Len(t, calls, 3)
+	assert.Equal(t, "dcl_3", calls[0].ID)
+	assert.Equal(t, "aqara", calls[0].Driver)
+	assert.Equal(t, "tv1", calls[0].DeviceID)
+	assert.Equal(t, "sess_1", calls[0].SessionID)
+	assert.Equal(t, core.DriverActionStop, calls[0].Action)
+	assert.True(t, calls[0].StartedAt.Equal(now))
+	assert.Equal(t, 30*time.Second, calls[0].Duration)
+	assert.Equal(t, core.DriverCallTimedOut, calls[0].Result)
+	assert.Equal(t, "driver call timed out", calls[0].Error)
+	assert.Equal(t, "dcl_2", calls[1].ID)
+	assert.Equal(t, "dcl_1", calls[2].ID)
+	assert.Equal(t, 850*time.Millisecond, calls[2].Duration)
+
+	// Pruning keeps the newest calls
+	require.NoError(t, s.PruneDriverCalls(ctx, 5))
+	calls, err = s.ListDriverCalls(ctx)
+	require.NoError(t, err)
+	assert.Len(t, calls, 3)
+
+	require.NoError(t, s.PruneDriverCalls(ctx, 2))
+	calls, err = s.ListDriverCalls(ctx)
+	require.NoError(t, err)
+	require.Len(t, calls, 2)
+	assert.Equal(t, "dcl_3", calls[0].ID)
+	assert.Equal(t, "dcl_2", calls[1].ID)
+}
@@ -0,0 +1,112 @@
+package storagetest
+
+import (
+	"context"
+	"metron/internal/core"
+	"metron/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// DriverJobStorage is a storage backend that also stores driver jobs
+type DriverJobStorage interface {
+	storage.Storage
+	core.DriverJobStorage
+}
+
+func testDriverJobs(t *testing.T, s DriverJobStorage) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	jobs, err := s.ListDriverJobs(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+
+	require.NoError(t, s.CreateDriverJob(ctx, &core.DriverJob{
+		ID: "drv_1", Kind: core.DriverJobStart, SessionID: "sess_1", CreatedAt: now.Add(-time.Minute),
+	}))
+	require.NoError(t, s.CreateDriverJob(ctx, &core.DriverJob{
+		ID: "drv_2", Kind: core.DriverJobExtend, SessionID: "sess_1", Minutes: 15, CreatedAt: now,
+	}))
+	assert.Error(t, s.CreateDriverJob(ctx, &core.DriverJob{
+		ID: "drv_1", Kind: core.DriverJobStop, SessionID: "sess_2", CreatedAt: now,
+	}), "IDs are unique")
+
+	// Failed attempts are recorded
+	require.NoError(t, s.UpdateDriverJob(ctx, &core.DriverJob{
+		ID: "drv_1", Kind: core.DriverJobStart, SessionID: "sess_1", Attempts: 2, LastError: "timeout", CreatedAt: now.Add(-time.Minute),
+	}))
+	assert.ErrorIs(t, s.UpdateDriverJob(ctx, &core.DriverJob{ID: "drv_missing"}), core.ErrDriverJobNotFound)
+
+	// Oldest first
+	jobs, err = s.ListDriverJobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, "drv_1", jobs[0].ID)
+	assert.Equal(t, core.DriverJobStart, jobs[0].Kind)
+	assert.Equal(t, "sess_1", jobs[0].SessionID)
+	assert.Equal(t, 2, jobs[0].Attempts)
+	assert.Equal(t, "timeout", jobs[0].LastError)
+	assert.True(t, jobs[0].CreatedAt.Equal(now.Add(-time.Minute)))
+	assert.Equal(t, "drv_2", jobs[1].ID)
+	assert.Equal(t, 15, jobs[1].Minutes)
+
+	require.NoError(t, s.DeleteDriverJob(ctx, "drv_1"))
+	assert.ErrorIs(t, s.DeleteDriverJob(ctx, "drv_1"), core.ErrDriverJobNotFound)
+
+	jobs, err = s.ListDriverJobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, jobs, 1)
+	assert.Equal(t, "drv_2", jobs[0].ID)
+}
+
+func testDriverJobClaims(t *testing.T, s DriverJobStorage) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	require.NoError(t, s.CreateDriverJob(ctx, &core.DriverJob{
+		ID: "drv_1", Kind: core.DriverJobStart, SessionID: "sess_1", CreatedAt: now.Add(-time.Minute),
+	}))
+	require.NoError(t, s.CreateDriverJob(ctx, &core.DriverJob{
+		ID: "drv_2", Kind: core.DriverJobStop, SessionID: "sess_2", CreatedAt: now,
+		Owner: "crashed", ClaimedUntil: now.Add(-time.Minute),
+	}))
+	require.NoError(t, s.CreateDriverJob(ctx, &core.DriverJob{
+		ID: "drv_3", Kind: core.DriverJobStop, SessionID: "sess_3", CreatedAt: now,
+		Owner: "other", ClaimedUntil: now.Add(time.Hour),
+	}))
+
+	// Unclaimed and expired jobs are claimed, oldest first; live claims of others are kept
+	jobs, err := s.ClaimDriverJobs(ctx, "a", now.Add(time.Minute))
+	require.NoError(t, err)
+	require.Len(t, jobs, 2)
+	assert.Equal(t, "drv_1", jobs[0].ID)
+	assert.Equal(t, "drv_2", jobs[1].ID)
+	assert.Equal(t, "a", jobs[1].Owner)
+	assert.True(t, jobs[1].ClaimedUntil.Equal(now.Add(time.Minute)))
+
+	// Claims are not handed out twice; their owner renews them
+	jobs, err = s.ClaimDriverJobs(ctx, "b", now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Empty(t, jobs)
+	jobs, err = s.ClaimDriverJobs(ctx, "a", now.Add(time.Hour))
+	require.NoError(t, err)
+	assert.Empty(t, jobs, "renewed, not claimed again")
+
+	// Failed attempts keep the claim
+	require.NoError(t, s.UpdateDriverJob(ctx, &core.DriverJob{ID: "drv_1", Attempts: 1, LastError: "timeout"}))
+	stored, err := s.ListDriverJobs(ctx)
+	require.NoError(t, err)
+	require.Len(t, stored, 3)
+	assert.Equal(t, "a", stored[0].Owner)
+	assert.True(t, stored[0].ClaimedUntil.Equal(now.Add(time.Hour)))
+
+	// Released jobs can be claimed at once
+	require.NoError(t, s.ReleaseDriverJobs(ctx, "a"))
+	jobs, err = s.ClaimDriverJobs(ctx, "b", now.Add(time.Minute))
+	require.NoError(t, err)
+	assert.Len(t, jobs, 2)
+}
@@ -0,0 +1,37 @@
+package storagetest
+
+import (
+	"context"
+	"metron/internal/drivers/familylink"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testFamilyLinkUsage(t *testing.T, s familylink.UsageImportStorage) {
+	ctx := context.Background()
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	minutes, err := s.GetFamilyLinkUsage(ctx, "phone1", monday)
+	require.NoError(t, err)
+	assert.Zero(t, minutes)
+
+	// Any time of the day addresses the same record, and saves replace it
+	require.NoError(t, s.SaveFamilyLinkUsage(ctx, "phone1", monday.Add(9*time.Hour), 20))
+	require.NoError(t, s.SaveFamilyLinkUsage(ctx, "phone1", monday.Add(17*time.Hour), 35))
+
+	minutes, err = s.GetFamilyLinkUsage(ctx, "phone1", monday.Add(12*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 35, minutes)
+
+	// Other devices and days are separate
+	minutes, err = s.GetFamilyLinkUsage(ctx, "phone2", monday)
+	require.NoError(t, err)
+	assert.Zero(t, minutes)
+
+	minutes, err = s.GetFamilyLinkUsage(ctx, "phone1", monday.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.Zero(t, minutes)
+}
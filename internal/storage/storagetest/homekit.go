@@ -0,0 +1,63 @@
+package storagetest
+
+import (
+	"context"
+	"crypto/ed25519"
+	"metron/internal/homekit"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testHomeKitIdentity(t *testing.T, s homekit.Storage) {
+	ctx := context.Background()
+
+	identity, err := s.GetHomeKitIdentity(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, identity)
+
+	_, privateKey, err := ed25519.GenerateKey(nil)
+	require.NoError(t, err)
+	saved := &homekit.Identity{PairingID: "1A:2B:3C:4D:5E:6F", PrivateKey: privateKey, ConfigHash: "abc", ConfigNumber: 1}
+	require.NoError(t, s.SaveHomeKitIdentity(ctx, saved))
+
+	// Saves replace the identity
+	saved.ConfigHash = "def"
+	saved.ConfigNumber = 2
+	require.NoError(t, s.SaveHomeKitIdentity(ctx, saved))
+
+	identity, err = s.GetHomeKitIdentity(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, identity)
+	assert.Equal(t, saved, identity)
+}
+
+func testHomeKitPairings(t *testing.T, s homekit.Storage) {
+	ctx := context.Background()
+
+	pairings, err := s.ListHomeKitPairings(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, pairings)
+
+	admin := &homekit.Pairing{ControllerID: "controller-1", PublicKey: ed25519.PublicKey(make([]byte, 32)), Admin: true}
+	user := &homekit.Pairing{ControllerID: "controller-2", PublicKey: ed25519.PublicKey(make([]byte, 32)), Admin: false}
+	require.NoError(t, s.SaveHomeKitPairing(ctx, admin))
+	require.NoError(t, s.SaveHomeKitPairing(ctx, user))
+
+	// Saving a controller again replaces its pairing
+	user.Admin = true
+	require.NoError(t, s.SaveHomeKitPairing(ctx, user))
+
+	pairings, err = s.ListHomeKitPairings(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*homekit.Pairing{admin, user}, pairings)
+
+	require.NoError(t, s.DeleteHomeKitPairing(ctx, "controller-1"))
+	// Deleting an unknown controller is not an error
+	require.NoError(t, s.DeleteHomeKitPairing(ctx, "controller-9"))
+
+	pairings, err = s.ListHomeKitPairings(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*homekit.Pairing{user}, pairings)
+}
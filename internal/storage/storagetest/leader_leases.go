@@ -0,0 +1,60 @@
+package storagetest
+
+import (
+	"context"
+	"metron/internal/core"
+	"metron/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// LeaderLeaseStorage is a storage backend that also holds leader leases
+type LeaderLeaseStorage interface {
+	storage.Storage
+	core.LeaderLeaseStorage
+}
+
+func testLeaderLeases(t *testing.T, s LeaderLeaseStorage) {
+	ctx := context.Background()
+	until := time.Now().Add(time.Minute)
+
+	acquired, err := s.AcquireLeaderLease(ctx, "scheduler", "server-a", until)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	// The holder can renew its lease; others cannot take it
+	acquired, err = s.AcquireLeaderLease(ctx, "scheduler", "server-a", until.Add(time.Minute))
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	acquired, err = s.AcquireLeaderLease(ctx, "scheduler", "server-b", until)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	// Other leases are separate
+	acquired, err = s.AcquireLeaderLease(ctx, "other", "server-b", until)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	// Only the holder can release a lease
+	require.NoError(t, s.ReleaseLeaderLease(ctx, "scheduler", "server-b"))
+	acquired, err = s.AcquireLeaderLease(ctx, "scheduler", "server-b", until)
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	require.NoError(t, s.ReleaseLeaderLease(ctx, "scheduler", "server-a"))
+	acquired, err = s.AcquireLeaderLease(ctx, "scheduler", "server-b", until)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	// Expired leases can be taken over
+	acquired, err = s.AcquireLeaderLease(ctx, "expiring", "server-a", time.Now().Add(-time.Second))
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	acquired, err = s.AcquireLeaderLease(ctx, "expiring", "server-b", until)
+	require.NoError(t, err)
+	assert.True(t, acquired)
+}
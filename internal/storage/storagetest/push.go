@@ -0,0 +1,75 @@
+package storagetest
+
+import (
+	"context"
+	"metron/internal/core"
+	"metron/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// PushStorage is a storage backend that also stores push subscriptions
+type PushStorage interface {
+	storage.Storage
+	core.PushStorage
+}
+
+func testPushSubscriptions(t *testing.T, s PushStorage) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	createChildren(t, s, newChild("alice", "Alice"), newChild("bob", "Bob"))
+
+	subscriptions, err := s.ListPushSubscriptions(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, subscriptions)
+
+	require.NoError(t, s.SavePushSubscription(ctx, &core.PushSubscription{
+		ID: "psh_parent", Endpoint: "https://push.example.com/parent", P256dh: "key1", Auth: "auth1",
+		Label: "Mom's phone", CreatedAt: now.Add(-2 * time.Minute),
+	}))
+	require.NoError(t, s.SavePushSubscription(ctx, &core.PushSubscription{
+		ID: "psh_alice", ChildID: "alice", Endpoint: "https://push.example.com/alice", P256dh: "key2", Auth: "auth2",
+		CreatedAt: now.Add(-time.Minute),
+	}))
+	require.NoError(t, s.SavePushSubscription(ctx, &core.PushSubscription{
+		ID: "psh_bob", ChildID: "bob", Endpoint: "https://push.example.com/bob", P256dh: "key3", Auth: "auth3",
+		CreatedAt: now,
+	}))
+
+	// Oldest first; parents' subscriptions have no child
+	subscriptions, err = s.ListPushSubscriptions(ctx)
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 3)
+	assert.Equal(t, "psh_parent", subscriptions[0].ID)
+	assert.Empty(t, subscriptions[0].ChildID)
+	assert.Equal(t, "Mom's phone", subscriptions[0].Label)
+	assert.Equal(t, "https://push.example.com/parent", subscriptions[0].Endpoint)
+	assert.Equal(t, "key1", subscriptions[0].P256dh)
+	assert.Equal(t, "auth1", subscriptions[0].Auth)
+	assert.WithinDuration(t, now.Add(-2*time.Minute), subscriptions[0].CreatedAt, time.Second)
+	assert.Equal(t, "alice", subscriptions[1].ChildID)
+
+	// Subscribing an endpoint again replaces its subscription
+	require.NoError(t, s.SavePushSubscription(ctx, &core.PushSubscription{
+		ID: "psh_alice2", ChildID: "alice", Endpoint: "https://push.example.com/alice", P256dh: "key4", Auth: "auth4",
+		CreatedAt: now.Add(time.Minute),
+	}))
+	subscriptions, err = s.ListPushSubscriptions(ctx)
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 3)
+	assert.Equal(t, "psh_alice2", subscriptions[2].ID)
+	assert.Equal(t, "key4", subscriptions[2].P256dh)
+
+	require.NoError(t, s.DeletePushSubscription(ctx, "psh_parent"))
+	assert.ErrorIs(t, s.DeletePushSubscription(ctx, "psh_parent"), core.ErrPushSubscriptionNotFound)
+
+	// Deleting a child deletes its subscriptions
+	require.NoError(t, s.DeleteChild(ctx, "bob"))
+	subscriptions, err = s.ListPushSubscriptions(ctx)
+	require.NoError(t, err)
+	require.Len(t, subscriptions, 1)
+	assert.Equal(t, "psh_alice2", subscriptions[0].ID)
+}
@@ -0,0 +1,32 @@
+package storagetest
+
+import (
+	"context"
+	"metron/internal/steam"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSteamPlaytime(t *testing.T, s steam.PlaytimeStorage) {
+	ctx := context.Background()
+
+	playtime, err := s.GetSteamPlaytime(ctx, "7656")
+	require.NoError(t, err)
+	assert.Empty(t, playtime)
+
+	// Saves replace the previous minutes of the game
+	require.NoError(t, s.SaveSteamPlaytime(ctx, "7656", "730", 5000))
+	require.NoError(t, s.SaveSteamPlaytime(ctx, "7656", "730", 5040))
+	require.NoError(t, s.SaveSteamPlaytime(ctx, "7656", "570", 15))
+
+	playtime, err = s.GetSteamPlaytime(ctx, "7656")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"730": 5040, "570": 15}, playtime)
+
+	// Other accounts are separate
+	playtime, err = s.GetSteamPlaytime(ctx, "7657")
+	require.NoError(t, err)
+	assert.Empty(t, playtime)
+}
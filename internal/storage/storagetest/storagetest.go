@@ -0,0 +1,1040 @@
+// Package storagetest is a conformance suite for storage.Storage implementations.
+// Every backend runs the same tests, so CRUD semantics, error sentinels and upsert
+// behavior cannot drift between them:
+//
+//	func TestConformance(t *testing.T) {
+//		storagetest.Run(t, func(t *testing.T) storage.Storage { return newTestStorage(t) })
+//	}
+package storagetest
+
+import (
+	"context"
+	"metron/internal/core"
+	"metron/internal/storage"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Factory returns a new, empty storage using UTC as its timezone
+// The storage must be closed by the factory (e.g. with t.Cleanup)
+type Factory[S any] func(t *testing.T) S
+
+// Run runs the whole suite; each test gets a fresh storage
+// Tests of storage interfaces a backend may leave out (agent tokens, driver jobs, ...) are
+// skipped for backends that don't implement them.
+func Run(t *testing.T, newStorage Factory[storage.Storage]) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, s storage.Storage)
+	}{
+		{"Children", testChildren},
+		{"ChildNotFound", testChildNotFound},
+		{"Sessions", testSessions},
+		{"SessionNotFound", testSessionNotFound},
+		{"SessionValidation", testSessionValidation},
+		{"CreateSessionIsAtomic", testCreateSessionIsAtomic},
+		{"ListSessions", testListSessions},
+		{"UpdateSessionChildren", testUpdateSessionChildren},
+		{"DailyAllocation", testDailyAllocation},
+		{"DailyUsageSummary", testDailyUsageSummary},
+		{"ListDailyRecords", testListDailyRecords},
+		{"ActiveSessionRecords", testActiveSessionRecords},
+		{"DeviceBypass", testDeviceBypass},
+		{"MovieTimeUsage", testMovieTimeUsage},
+		{"MovieTimeBypass", testMovieTimeBypass},
+		{"Lockdown", testLockdown},
+		{"TrackingPause", testTrackingPause},
+		{"DeviceHeartbeat", testDeviceHeartbeat},
+		{"TamperEvents", testTamperEvents},
+		{"SessionClaims", testSessionClaims},
+		{"LimitChanges", testLimitChanges},
+		{"AuditLog", testAuditLog},
+		{"ProfileTransitions", testProfileTransitions},
+		{"AgentTokens", optional(testAgentTokens)},
+		{"AgentTokenNotFound", optional(testAgentTokenNotFound)},
+		{"ChildLogins", optional(testChildLogins)},
+		{"ChildLoginNotFound", optional(testChildLoginNotFound)},
+		{"DowntimeOverrides", optional(testDowntimeOverrides)},
+		{"DowntimeOverrideNotFound", optional(testDowntimeOverrideNotFound)},
+		{"UsageAlerts", optional(testUsageAlerts)},
+		{"DayRollovers", optional(testDayRollovers)},
+		{"AppUsage", optional(testAppUsage)},
+		{"PushSubscriptions", optional(testPushSubscriptions)},
+		{"ChildActivity", optional(testChildActivity)},
+		{"Allocations", optional(testAllocations)},
+		{"DriverJobs", optional(testDriverJobs)},
+		{"DriverJobClaims", optional(testDriverJobClaims)},
+		{"DriverCalls", optional(testDriverCalls)},
+		{"LeaderLeases", optional(testLeaderLeases)},
+		{"FamilyLinkUsage", optional(testFamilyLinkUsage)},
+		{"SteamPlaytime", optional(testSteamPlaytime)},
+		{"HomeKitIdentity", optional(testHomeKitIdentity)},
+		{"HomeKitPairings", optional(testHomeKitPairings)},
+		{"Credentials", optional(testCredentials)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newStorage(t))
+		})
+	}
+}
+
+// optional adapts a test of a storage interface that backends may leave out to the suite
+// The test is skipped for backends that don't implement S.
+func optional[S any](test func(t *testing.T, s S)) func(t *testing.T, s storage.Storage) {
+	return func(t *testing.T, s storage.Storage) {
+		impl, ok := s.(S)
+		if !ok {
+			t.Skipf("%T does not implement %s", s, reflect.TypeFor[S]())
+		}
+		test(t, impl)
+	}
+}
+
+// monday is a fixed weekday used for date-keyed records
+var monday = time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+
+func newChild(id, name string) *core.Child {
+	return &core.Child{
+		ID:           id,
+		Name:         name,
+		WeekdayLimit: 60,
+		WeekendLimit: 120,
+	}
+}
+
+func newSession(id string, childIDs ...string) *core.Session {
+	return &core.Session{
+		ID:               id,
+		DeviceType:       "tv",
+		DeviceID:         "tv1",
+		ChildIDs:         childIDs,
+		StartTime:        time.Now().Add(-10 * time.Minute).Truncate(time.Second),
+		ExpectedDuration: 30,
+		Status:           core.SessionStatusActive,
+	}
+}
+
+func createChildren(t *testing.T, s storage.Storage, children ...*core.Child) {
+	t.Helper()
+	for _, child := range children {
+		require.NoError(t, s.CreateChild(context.Background(), child))
+	}
+}
+
+func sessionIDs(sessions []*core.Session) []string {
+	ids := make([]string, 0, len(sessions))
+	for _, session := range sessions {
+		ids = append(ids, session.ID)
+	}
+	return ids
+}
+
+func testChildren(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	bob := newChild("bob", "Bob")
+	alice := newChild("alice", "Alice")
+	alice.BreakRule = &core.BreakRule{BreakAfterMinutes: 30, BreakDurationMinutes: 10, Action: core.BreakActionLock}
+	alice.DowntimeEnabled = true
+	alice.AllowedDevices = []string{"tv1", "ipad"}
+	alice.Timezone = "Europe/Amsterdam"
+	alice.GraceMinutes = 5
+	birthdate := time.Date(2016, time.May, 14, 0, 0, 0, 0, time.UTC)
+	alice.Birthdate = &birthdate
+	createChildren(t, s, bob, alice)
+	assert.False(t, alice.CreatedAt.IsZero(), "CreateChild sets CreatedAt")
+
+	got, err := s.GetChild(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "Alice", got.Name)
+	assert.Equal(t, 60, got.WeekdayLimit)
+	assert.Equal(t, 120, got.WeekendLimit)
+	assert.Equal(t, alice.BreakRule, got.BreakRule)
+	assert.True(t, got.DowntimeEnabled)
+	assert.Equal(t, []string{"tv1", "ipad"}, got.AllowedDevices)
+	assert.Equal(t, "Europe/Amsterdam", got.Timezone)
+	assert.Equal(t, 5, got.GraceMinutes)
+	require.NotNil(t, got.Birthdate)
+	assert.Equal(t, "2016-05-14", got.Birthdate.Format("2006-01-02"))
+
+	// Listed by name
+	children, err := s.ListChildren(ctx)
+	require.NoError(t, err)
+	require.Len(t, children, 2)
+	assert.Equal(t, "alice", children[0].ID)
+	assert.Equal(t, "bob", children[1].ID)
+	assert.NotNil(t, children[0].Birthdate)
+	assert.Nil(t, children[1].Birthdate)
+
+	// Duplicate IDs are rejected
+	assert.Error(t, s.CreateChild(ctx, newChild("alice", "Another Alice")))
+
+	// Invalid children are rejected on create and update
+	assert.Error(t, s.CreateChild(ctx, &core.Child{ID: "carol", Name: "Carol", WeekdayLimit: -1}))
+	invalid := *got
+	invalid.Name = ""
+	assert.Error(t, s.UpdateChild(ctx, &invalid))
+
+	got.Name = "Alicia"
+	got.WeekdayLimit = 90
+	got.BreakRule = nil
+	got.AllowedDevices = nil
+	got.Birthdate = nil
+	require.NoError(t, s.UpdateChild(ctx, got))
+
+	updated, err := s.GetChild(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "Alicia", updated.Name)
+	assert.Equal(t, 90, updated.WeekdayLimit)
+	assert.Nil(t, updated.BreakRule)
+	assert.Empty(t, updated.AllowedDevices)
+	assert.Nil(t, updated.Birthdate)
+
+	require.NoError(t, s.DeleteChild(ctx, "bob"))
+	children, err = s.ListChildren(ctx)
+	require.NoError(t, err)
+	require.Len(t, children, 1)
+	assert.Equal(t, "alice", children[0].ID)
+}
+
+func testChildNotFound(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	_, err := s.GetChild(ctx, "ghost")
+	assert.ErrorIs(t, err, core.ErrChildNotFound)
+	assert.ErrorIs(t, s.UpdateChild(ctx, newChild("ghost", "Ghost")), core.ErrChildNotFound)
+	assert.ErrorIs(t, s.DeleteChild(ctx, "ghost"), core.ErrChildNotFound)
+
+	children, err := s.ListChildren(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, children)
+}
+
+func testSessions(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	createChildren(t, s, newChild("alice", "Alice"), newChild("bob", "Bob"))
+
+	session := newSession("s1", "alice", "bob")
+	lastBreak := session.StartTime.Add(5 * time.Minute)
+	breakEnds := lastBreak.Add(10 * time.Minute)
+	lastActivity := session.StartTime.Add(2 * time.Minute)
+	session.Status = core.SessionStatusPaused
+	session.LastBreakAt = &lastBreak
+	session.BreakEndsAt = &breakEnds
+	session.LastActivityAt = &lastActivity
+	session.BreakMinutes = 10
+	session.BreakAction = core.BreakActionLock
+	session.BreakExempt = true
+	session.OverrideDowntime = true
+	session.InitiatorType = core.InitiatorParent
+	session.InitiatorID = "telegram:alice"
+	session.Activity = "homework"
+	require.NoError(t, s.CreateSession(ctx, session))
+	assert.False(t, session.CreatedAt.IsZero(), "CreateSession sets CreatedAt")
+
+	got, err := s.GetSession(ctx, "s1")
+	require.NoError(t, err)
+	assert.Equal(t, "tv", got.DeviceType)
+	assert.Equal(t, "tv1", got.DeviceID)
+	assert.ElementsMatch(t, []string{"alice", "bob"}, got.ChildIDs)
+	assert.WithinDuration(t, session.StartTime, got.StartTime, 0)
+	assert.Equal(t, 30, got.ExpectedDuration)
+	assert.Equal(t, core.SessionStatusPaused, got.Status)
+	require.NotNil(t, got.LastBreakAt)
+	assert.WithinDuration(t, lastBreak, *got.LastBreakAt, 0)
+	require.NotNil(t, got.BreakEndsAt)
+	assert.WithinDuration(t, breakEnds, *got.BreakEndsAt, 0)
+	require.NotNil(t, got.LastActivityAt)
+	assert.WithinDuration(t, lastActivity, *got.LastActivityAt, 0)
+	assert.Nil(t, got.WarningSentAt)
+	assert.Equal(t, 10, got.BreakMinutes)
+	assert.Equal(t, core.BreakActionLock, got.BreakAction)
+	assert.True(t, got.BreakExempt)
+	assert.True(t, got.OverrideDowntime)
+	assert.False(t, got.OverrideLimits)
+	assert.Equal(t, core.InitiatorParent, got.InitiatorType)
+	assert.Equal(t, "telegram:alice", got.InitiatorID)
+	assert.Equal(t, "homework", got.Activity)
+	assert.Empty(t, got.EndReason, "running sessions have no end reason")
+	assert.Nil(t, got.ActualDuration, "running sessions have no actual duration")
+
+	// Duplicate IDs are rejected
+	assert.Error(t, s.CreateSession(ctx, newSession("s1", "alice")))
+
+	warning := session.StartTime.Add(25 * time.Minute)
+	got.Status = core.SessionStatusCompleted
+	got.ExpectedDuration = 45
+	got.WarningSentAt = &warning
+	got.LastBreakAt = nil
+	got.BreakEndsAt = nil
+	got.BreakAction = ""
+	graceEnds := session.StartTime.Add(50 * time.Minute)
+	got.GraceEndsAt = &graceEnds
+	got.EndReason = core.SessionEndChildStop
+	got.OverrideLimits = true               // Extensions can add an override
+	got.InitiatorType = core.InitiatorChild // The initiator is fixed at creation
+	got.Activity = ""                       // So is the activity
+	got.Notes = "Watched: Bluey"
+	actual := 38
+	got.ActualDuration = &actual
+	require.NoError(t, s.UpdateSession(ctx, got))
+
+	updated, err := s.GetSession(ctx, "s1")
+	require.NoError(t, err)
+	assert.Equal(t, core.SessionStatusCompleted, updated.Status)
+	assert.Equal(t, 45, updated.ExpectedDuration)
+	require.NotNil(t, updated.WarningSentAt)
+	assert.WithinDuration(t, warning, *updated.WarningSentAt, 0)
+	assert.Nil(t, updated.LastBreakAt)
+	assert.Nil(t, updated.BreakEndsAt)
+	assert.Empty(t, updated.BreakAction)
+	require.NotNil(t, updated.GraceEndsAt)
+	assert.WithinDuration(t, graceEnds, *updated.GraceEndsAt, 0)
+	assert.Equal(t, core.SessionEndChildStop, updated.EndReason)
+	assert.True(t, updated.OverrideDowntime)
+	assert.True(t, updated.OverrideLimits)
+	assert.Equal(t, core.InitiatorParent, updated.InitiatorType)
+	assert.Equal(t, "homework", updated.Activity)
+	assert.Equal(t, "Watched: Bluey", updated.Notes)
+	require.NotNil(t, updated.ActualDuration)
+	assert.Equal(t, 38, *updated.ActualDuration)
+
+	require.NoError(t, s.DeleteSession(ctx, "s1"))
+	_, err = s.GetSession(ctx, "s1")
+	assert.ErrorIs(t, err, core.ErrSessionNotFound)
+}
+
+func testSessionNotFound(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	createChildren(t, s, newChild("alice", "Alice"))
+
+	_, err := s.GetSession(ctx, "ghost")
+	assert.ErrorIs(t, err, core.ErrSessionNotFound)
+	assert.ErrorIs(t, s.UpdateSession(ctx, newSession("ghost", "alice")), core.ErrSessionNotFound)
+	assert.ErrorIs(t, s.DeleteSession(ctx, "ghost"), core.ErrSessionNotFound)
+}
+
+func testSessionValidation(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	createChildren(t, s, newChild("alice", "Alice"))
+
+	noChildren := newSession("s1")
+	assert.ErrorIs(t, s.CreateSession(ctx, noChildren), core.ErrNoChildren)
+
+	noDuration := newSession("s2", "alice")
+	noDuration.ExpectedDuration = 0
+	assert.ErrorIs(t, s.CreateSession(ctx, noDuration), core.ErrInvalidDuration)
+
+	noType := newSession("s3", "alice")
+	noType.DeviceType = ""
+	assert.ErrorIs(t, s.CreateSession(ctx, noType), core.ErrInvalidDeviceType)
+
+	sessions, err := s.ListAllSessions(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+}
+
+// A session whose child associations cannot be stored must not be created at all
+func testCreateSessionIsAtomic(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	createChildren(t, s, newChild("alice", "Alice"))
+
+	assert.Error(t, s.CreateSession(ctx, newSession("s1", "alice", "alice")))
+
+	_, err := s.GetSession(ctx, "s1")
+	assert.ErrorIs(t, err, core.ErrSessionNotFound)
+	sessions, err := s.ListSessionsByChild(ctx, "alice")
+	require.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	// The ID is still free
+	require.NoError(t, s.CreateSession(ctx, newSession("s1", "alice")))
+}
+
+func testListSessions(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	createChildren(t, s, newChild("alice", "Alice"), newChild("bob", "Bob"))
+
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	statuses := []core.SessionStatus{core.SessionStatusActive, core.SessionStatusPaused, core.SessionStatusCompleted, core.SessionStatusExpired}
+	for i, status := range statuses {
+		session := newSession(string(status), "alice")
+		if status == core.SessionStatusPaused {
+			session.ChildIDs = []string{"bob"}
+		}
+		session.StartTime = base.Add(time.Duration(i) * time.Minute)
+		session.Status = status
+		require.NoError(t, s.CreateSession(ctx, session))
+	}
+
+	// Newest first
+	all, err := s.ListAllSessions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"expired", "completed", "paused", "active"}, sessionIDs(all))
+
+	// Running sessions include sessions paused for a break
+	active, err := s.ListActiveSessions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"paused", "active"}, sessionIDs(active))
+
+	byChild, err := s.ListSessionsByChild(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"expired", "completed", "active"}, sessionIDs(byChild))
+	for _, session := range byChild {
+		assert.Equal(t, []string{"alice"}, session.ChildIDs)
+	}
+
+	none, err := s.ListSessionsByChild(ctx, "ghost")
+	require.NoError(t, err)
+	assert.Empty(t, none)
+
+	// The bound is inclusive and independent of the time zone it is given in
+	since, err := s.ListSessionsSince(ctx, base.Add(2*time.Minute).In(time.FixedZone("UTC+5", 5*3600)))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"expired", "completed"}, sessionIDs(since))
+}
+
+// Children can join or leave a running session
+func testUpdateSessionChildren(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	createChildren(t, s, newChild("alice", "Alice"), newChild("bob", "Bob"))
+
+	session := newSession("s1", "alice")
+	require.NoError(t, s.CreateSession(ctx, session))
+
+	session.ChildIDs = []string{"alice", "bob"}
+	require.NoError(t, s.UpdateSession(ctx, session))
+	got, err := s.GetSession(ctx, "s1")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"alice", "bob"}, got.ChildIDs)
+
+	session.ChildIDs = []string{"bob"}
+	require.NoError(t, s.UpdateSession(ctx, session))
+	got, err = s.GetSession(ctx, "s1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"bob"}, got.ChildIDs)
+
+	byChild, err := s.ListSessionsByChild(ctx, "alice")
+	require.NoError(t, err)
+	assert.Empty(t, byChild)
+}
+
+func testDailyAllocation(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	createChildren(t, s, newChild("alice", "Alice"))
+
+	_, err := s.GetDailyAllocation(ctx, "alice", monday)
+	assert.ErrorIs(t, err, core.ErrAllocationNotFound)
+
+	require.NoError(t, s.CreateDailyAllocation(ctx, &core.DailyTimeAllocation{
+		ChildID:      "alice",
+		Date:         monday,
+		BaseLimit:    60,
+		BonusGranted: 15,
+	}))
+
+	// Any instant of the day finds the allocation
+	got, err := s.GetDailyAllocation(ctx, "alice", monday.Add(18*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, "alice", got.ChildID)
+	assert.Equal(t, 60, got.BaseLimit)
+	assert.Equal(t, 15, got.BonusGranted)
+
+	// Only one allocation per child and day
+	assert.Error(t, s.CreateDailyAllocation(ctx, &core.DailyTimeAllocation{ChildID: "alice", Date: monday.Add(time.Hour), BaseLimit: 90}))
+
+	got.BonusGranted = 45
+	require.NoError(t, s.UpdateDailyAllocation(ctx, got))
+	got, err = s.GetDailyAllocation(ctx, "alice", monday)
+	require.NoError(t, err)
+	assert.Equal(t, 60, got.BaseLimit)
+	assert.Equal(t, 45, got.BonusGranted)
+
+	// Other days are separate
+	_, err = s.GetDailyAllocation(ctx, "alice", monday.AddDate(0, 0, 1))
+	assert.ErrorIs(t, err, core.ErrAllocationNotFound)
+}
+
+func testDailyUsageSummary(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	createChildren(t, s, newChild("alice", "Alice"))
+
+	// A missing summary is an empty one, not an error
+	summary, err := s.GetDailyUsageSummary(ctx, "alice", monday)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", summary.ChildID)
+	assert.Equal(t, 0, summary.MinutesUsed)
+	assert.Equal(t, 0, summary.SessionCount)
+
+	// Increments create the summary, then accumulate
+	require.NoError(t, s.IncrementDailyUsageSummary(ctx, "alice", monday.Add(9*time.Hour), 20))
+	require.NoError(t, s.IncrementDailyUsageSummary(ctx, "alice", monday.Add(17*time.Hour), 15))
+	require.NoError(t, s.IncrementSessionCountSummary(ctx, "alice", monday.Add(9*time.Hour)))
+	require.NoError(t, s.IncrementSessionCountSummary(ctx, "alice", monday.Add(17*time.Hour)))
+
+	summary, err = s.GetDailyUsageSummary(ctx, "alice", monday)
+	require.NoError(t, err)
+	assert.Equal(t, 35, summary.MinutesUsed)
+	assert.Equal(t, 2, summary.SessionCount)
+
+	// A session count alone does not add minutes
+	require.NoError(t, s.IncrementSessionCountSummary(ctx, "alice", monday.AddDate(0, 0, 1)))
+	next, err := s.GetDailyUsageSummary(ctx, "alice", monday.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.Equal(t, 0, next.MinutesUsed)
+	assert.Equal(t, 1, next.SessionCount)
+}
+
+// Listing a day's records returns every child's record for that day only
+func testListDailyRecords(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	createChildren(t, s, newChild("alice", "Alice"), newChild("bob", "Bob"))
+
+	allocations, err := s.ListDailyAllocations(ctx, monday)
+	require.NoError(t, err)
+	assert.Empty(t, allocations)
+
+	require.NoError(t, s.CreateDailyAllocation(ctx, &core.DailyTimeAllocation{ChildID: "alice", Date: monday, BaseLimit: 60, BonusGranted: 10}))
+	require.NoError(t, s.CreateDailyAllocation(ctx, &core.DailyTimeAllocation{ChildID: "bob", Date: monday, BaseLimit: 90}))
+	require.NoError(t, s.CreateDailyAllocation(ctx, &core.DailyTimeAllocation{ChildID: "alice", Date: monday.AddDate(0, 0, 1), BaseLimit: 45}))
+
+	allocations, err = s.ListDailyAllocations(ctx, monday.Add(12*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, allocations, 2)
+	limits := make(map[string]int)
+	for _, allocation := range allocations {
+		limits[allocation.ChildID] = allocation.BaseLimit + allocation.BonusGranted
+	}
+	assert.Equal(t, map[string]int{"alice": 70, "bob": 90}, limits)
+
+	require.NoError(t, s.IncrementDailyUsageSummary(ctx, "alice", monday, 20))
+	require.NoError(t, s.IncrementSessionCountSummary(ctx, "alice", monday))
+	require.NoError(t, s.IncrementDailyUsageSummary(ctx, "bob", monday.AddDate(0, 0, 1), 30))
+
+	// Children without usage on the day have no summary
+	summaries, err := s.ListDailyUsageSummaries(ctx, monday)
+	require.NoError(t, err)
+	require.Len(t, summaries, 1)
+	assert.Equal(t, "alice", summaries[0].ChildID)
+	assert.Equal(t, 20, summaries[0].MinutesUsed)
+	assert.Equal(t, 1, summaries[0].SessionCount)
+}
+
+// Session records cover active sessions only; paused sessions are charged through their session
+func testActiveSessionRecords(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	createChildren(t, s, newChild("alice", "Alice"), newChild("bob", "Bob"))
+
+	active := newSession("active", "alice", "bob")
+	active.IsMovieSession = true
+	active.BreakMinutes = 5
+	active.Activity = "homework"
+	require.NoError(t, s.CreateSession(ctx, active))
+	completed := newSession("completed", "alice")
+	completed.Status = core.SessionStatusCompleted
+	require.NoError(t, s.CreateSession(ctx, completed))
+
+	records, err := s.ListActiveSessionRecords(ctx)
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	assert.Equal(t, "active", records[0].ID)
+	assert.ElementsMatch(t, []string{"alice", "bob"}, records[0].ChildIDs)
+	assert.Equal(t, core.SessionStatusActive, records[0].Status)
+	assert.Equal(t, 30, records[0].ExpectedDuration)
+	assert.Equal(t, 5, records[0].BreakMinutes)
+	assert.True(t, records[0].IsMovieSession)
+	assert.Equal(t, "homework", records[0].Activity)
+	assert.WithinDuration(t, active.StartTime, records[0].StartTime, 0)
+}
+
+func testDeviceBypass(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	// A device without a bypass returns nil without an error
+	bypass, err := s.GetDeviceBypass(ctx, "pc1")
+	require.NoError(t, err)
+	assert.Nil(t, bypass)
+
+	now := time.Now().Truncate(time.Second)
+	expired := now.Add(-time.Minute)
+	require.NoError(t, s.SetDeviceBypass(ctx, &core.DeviceBypass{DeviceID: "pc1", Enabled: true, Reason: "homework", EnabledAt: now, EnabledBy: "api"}))
+	require.NoError(t, s.SetDeviceBypass(ctx, &core.DeviceBypass{DeviceID: "pc2", Enabled: true, EnabledAt: now, ExpiresAt: &expired}))
+	require.NoError(t, s.SetDeviceBypass(ctx, &core.DeviceBypass{DeviceID: "pc3", Enabled: false, EnabledAt: now}))
+
+	bypass, err = s.GetDeviceBypass(ctx, "pc1")
+	require.NoError(t, err)
+	require.NotNil(t, bypass)
+	assert.True(t, bypass.Enabled)
+	assert.Equal(t, "homework", bypass.Reason)
+	assert.Equal(t, "api", bypass.EnabledBy)
+	assert.Nil(t, bypass.ExpiresAt)
+
+	// Expired and disabled bypasses are not active
+	active, err := s.ListActiveBypassDevices(ctx)
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, "pc1", active[0].DeviceID)
+
+	// Setting again replaces the bypass
+	expires := now.Add(time.Hour)
+	require.NoError(t, s.SetDeviceBypass(ctx, &core.DeviceBypass{DeviceID: "pc1", Enabled: true, EnabledAt: now, ExpiresAt: &expires}))
+	bypass, err = s.GetDeviceBypass(ctx, "pc1")
+	require.NoError(t, err)
+	assert.Empty(t, bypass.Reason)
+	require.NotNil(t, bypass.ExpiresAt)
+	assert.WithinDuration(t, expires, *bypass.ExpiresAt, 0)
+
+	// Clearing is idempotent
+	require.NoError(t, s.ClearDeviceBypass(ctx, "pc1"))
+	require.NoError(t, s.ClearDeviceBypass(ctx, "pc1"))
+	bypass, err = s.GetDeviceBypass(ctx, "pc1")
+	require.NoError(t, err)
+	assert.Nil(t, bypass)
+}
+
+func testMovieTimeUsage(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	saturday := monday.AddDate(0, 0, 5)
+
+	usage, err := s.GetMovieTimeUsage(ctx, saturday)
+	require.NoError(t, err)
+	assert.Nil(t, usage)
+
+	require.NoError(t, s.SaveMovieTimeUsage(ctx, &core.MovieTimeUsage{Date: saturday, Status: core.MovieTimeStatusAvailable}))
+
+	// Saving again for any instant of the day updates the same record
+	startedAt := saturday.Add(19 * time.Hour)
+	require.NoError(t, s.SaveMovieTimeUsage(ctx, &core.MovieTimeUsage{
+		Date:      startedAt,
+		SessionID: "movie1",
+		StartedAt: &startedAt,
+		StartedBy: "alice",
+		Status:    core.MovieTimeStatusActive,
+	}))
+
+	usage, err = s.GetMovieTimeUsage(ctx, saturday.Add(22*time.Hour))
+	require.NoError(t, err)
+	require.NotNil(t, usage)
+	assert.Equal(t, core.MovieTimeStatusActive, usage.Status)
+	assert.Equal(t, "movie1", usage.SessionID)
+	assert.Equal(t, "alice", usage.StartedBy)
+	require.NotNil(t, usage.StartedAt)
+	assert.WithinDuration(t, startedAt, *usage.StartedAt, 0)
+
+	sunday, err := s.GetMovieTimeUsage(ctx, saturday.AddDate(0, 0, 1))
+	require.NoError(t, err)
+	assert.Nil(t, sunday)
+}
+
+func testMovieTimeBypass(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	bypass, err := s.GetMovieTimeBypass(ctx, "ghost")
+	require.NoError(t, err)
+	assert.Nil(t, bypass)
+
+	require.NoError(t, s.CreateMovieTimeBypass(ctx, &core.MovieTimeBypass{ID: "winter", Reason: "Winter break", StartDate: monday, EndDate: monday.AddDate(0, 0, 4)}))
+	require.NoError(t, s.CreateMovieTimeBypass(ctx, &core.MovieTimeBypass{ID: "holiday", Reason: "Public holiday", StartDate: monday.AddDate(0, 0, 14), EndDate: monday.AddDate(0, 0, 14)}))
+
+	bypass, err = s.GetMovieTimeBypass(ctx, "winter")
+	require.NoError(t, err)
+	require.NotNil(t, bypass)
+	assert.Equal(t, "Winter break", bypass.Reason)
+
+	// Newest start first
+	all, err := s.ListMovieTimeBypasses(ctx)
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "holiday", all[0].ID)
+	assert.Equal(t, "winter", all[1].ID)
+
+	// Start and end days are inclusive
+	for _, day := range []time.Time{monday, monday.AddDate(0, 0, 2).Add(12 * time.Hour), monday.AddDate(0, 0, 4).Add(23 * time.Hour)} {
+		active, err := s.ListActiveMovieTimeBypasses(ctx, day)
+		require.NoError(t, err)
+		require.Len(t, active, 1, "active on %s", day)
+		assert.Equal(t, "winter", active[0].ID)
+	}
+	active, err := s.ListActiveMovieTimeBypasses(ctx, monday.AddDate(0, 0, 5))
+	require.NoError(t, err)
+	assert.Empty(t, active)
+
+	require.NoError(t, s.DeleteMovieTimeBypass(ctx, "winter"))
+	bypass, err = s.GetMovieTimeBypass(ctx, "winter")
+	require.NoError(t, err)
+	assert.Nil(t, bypass)
+}
+
+func testLockdown(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	active, err := s.GetActiveLockdown(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, active)
+
+	now := time.Now().Truncate(time.Second)
+	liftedAt := now.Add(-time.Hour)
+	require.NoError(t, s.CreateLockdown(ctx, &core.Lockdown{ID: "old", Reason: "homework", TriggeredBy: "api", StartedAt: now.Add(-2 * time.Hour), LiftedAt: &liftedAt, LiftedBy: "api"}))
+	lockdown := &core.Lockdown{ID: "current", Reason: "dinner", TriggeredBy: "telegram:1", StartedAt: now}
+	require.NoError(t, s.CreateLockdown(ctx, lockdown))
+
+	active, err = s.GetActiveLockdown(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, active)
+	assert.Equal(t, "current", active.ID)
+	assert.Equal(t, "dinner", active.Reason)
+	assert.Equal(t, "telegram:1", active.TriggeredBy)
+
+	lockdown.StoppedSessions = 2
+	require.NoError(t, s.UpdateLockdown(ctx, lockdown))
+	active, err = s.GetActiveLockdown(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, active.StoppedSessions)
+
+	lifted := now.Add(time.Minute)
+	lockdown.LiftedAt = &lifted
+	lockdown.LiftedBy = "telegram:2"
+	require.NoError(t, s.UpdateLockdown(ctx, lockdown))
+	active, err = s.GetActiveLockdown(ctx)
+	require.NoError(t, err)
+	assert.Nil(t, active)
+
+	// Newest first, limited
+	history, err := s.ListLockdowns(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, "current", history[0].ID)
+	assert.Equal(t, "telegram:2", history[0].LiftedBy)
+	require.NotNil(t, history[0].LiftedAt)
+	assert.WithinDuration(t, lifted, *history[0].LiftedAt, 0)
+	assert.Equal(t, "old", history[1].ID)
+
+	history, err = s.ListLockdowns(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "current", history[0].ID)
+}
+
+func testTrackingPause(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	createChildren(t, s, newChild("alice", "Alice"))
+
+	pauses, err := s.ListActiveTrackingPauses(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, pauses)
+
+	now := time.Now().Truncate(time.Second)
+	resumesAt := now.Add(24 * time.Hour)
+	global := &core.TrackingPause{ID: "global", Reason: "vacation", PausedBy: "api", StartedAt: now.Add(-time.Hour)}
+	child := &core.TrackingPause{ID: "alice-sick", ChildID: "alice", Reason: "sick", PausedBy: "telegram:1", StartedAt: now, ResumesAt: &resumesAt}
+	require.NoError(t, s.CreateTrackingPause(ctx, global))
+	require.NoError(t, s.CreateTrackingPause(ctx, child))
+
+	// Newest first
+	pauses, err = s.ListActiveTrackingPauses(ctx)
+	require.NoError(t, err)
+	require.Len(t, pauses, 2)
+	assert.Equal(t, "alice-sick", pauses[0].ID)
+	assert.Equal(t, "alice", pauses[0].ChildID)
+	require.NotNil(t, pauses[0].ResumesAt)
+	assert.WithinDuration(t, resumesAt, *pauses[0].ResumesAt, 0)
+	assert.Equal(t, "global", pauses[1].ID)
+	assert.True(t, pauses[1].IsGlobal())
+
+	// Resumed pauses are no longer active
+	resumedAt := now.Add(time.Minute)
+	global.ResumedAt = &resumedAt
+	global.ResumedBy = "api"
+	require.NoError(t, s.UpdateTrackingPause(ctx, global))
+
+	pauses, err = s.ListActiveTrackingPauses(ctx)
+	require.NoError(t, err)
+	require.Len(t, pauses, 1)
+	assert.Equal(t, "alice-sick", pauses[0].ID)
+}
+
+func testDeviceHeartbeat(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+
+	heartbeats, err := s.ListDeviceHeartbeats(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, heartbeats)
+
+	now := time.Now().Truncate(time.Second)
+	require.NoError(t, s.SaveDeviceHeartbeat(ctx, &core.DeviceHeartbeat{DeviceID: "win-pc1", Source: "agent", LastSeenAt: now.Add(-time.Hour)}))
+	require.NoError(t, s.SaveDeviceHeartbeat(ctx, &core.DeviceHeartbeat{DeviceID: "tv1", Source: "aqara", LastSeenAt: now}))
+
+	// Saving again replaces the device's heartbeat
+	require.NoError(t, s.SaveDeviceHeartbeat(ctx, &core.DeviceHeartbeat{DeviceID: "win-pc1", Source: "agent", LastSeenAt: now}))
+
+	heartbeats, err = s.ListDeviceHeartbeats(ctx)
+	require.NoError(t, err)
+	require.Len(t, heartbeats, 2)
+
+	byDevice := map[string]*core.DeviceHeartbeat{}
+	for _, heartbeat := range heartbeats {
+		byDevice[heartbeat.DeviceID] = heartbeat
+	}
+	require.Contains(t, byDevice, "win-pc1")
+	assert.Equal(t, "agent", byDevice["win-pc1"].Source)
+	assert.WithinDuration(t, now, byDevice["win-pc1"].LastSeenAt, 0)
+	require.Contains(t, byDevice, "tv1")
+	assert.Equal(t, "aqara", byDevice["tv1"].Source)
+}
+
+func testTamperEvents(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	events, err := s.ListTamperEvents(ctx, time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	require.NoError(t, s.CreateTamperEvent(ctx, &core.TamperEvent{
+		ID: "tmp_2", DeviceID: "win-pc1", Type: core.TamperClockChange,
+		Details: "clock moved back 2h", OccurredAt: now.Add(-2 * time.Hour), ReceivedAt: now,
+	}))
+	require.NoError(t, s.CreateTamperEvent(ctx, &core.TamperEvent{
+		ID: "tmp_1", DeviceID: "win-pc2", Type: core.TamperSafeModeBoot,
+		OccurredAt: now.Add(-time.Hour), ReceivedAt: now.Add(-time.Hour),
+	}))
+
+	// Oldest first by receive time
+	events, err = s.ListTamperEvents(ctx, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "tmp_1", events[0].ID)
+	assert.Empty(t, events[0].Details)
+	assert.Equal(t, "tmp_2", events[1].ID)
+	assert.Equal(t, "win-pc1", events[1].DeviceID)
+	assert.Equal(t, core.TamperClockChange, events[1].Type)
+	assert.Equal(t, "clock moved back 2h", events[1].Details)
+	assert.True(t, events[1].OccurredAt.Equal(now.Add(-2*time.Hour)))
+	assert.True(t, events[1].ReceivedAt.Equal(now))
+
+	// since is exclusive
+	events, err = s.ListTamperEvents(ctx, now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, "tmp_2", events[0].ID)
+}
+
+func testSessionClaims(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	until := time.Now().Add(time.Minute)
+
+	claimed, err := s.ClaimSession(ctx, "sess_1", "server-a", until)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	// The holder can renew its claim; others cannot take it
+	claimed, err = s.ClaimSession(ctx, "sess_1", "server-a", until)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	claimed, err = s.ClaimSession(ctx, "sess_1", "server-b", until)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	// Other sessions are separate
+	claimed, err = s.ClaimSession(ctx, "sess_2", "server-b", until)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	// Only the holder can release a claim
+	require.NoError(t, s.ReleaseSessionClaim(ctx, "sess_1", "server-b"))
+	claimed, err = s.ClaimSession(ctx, "sess_1", "server-b", until)
+	require.NoError(t, err)
+	assert.False(t, claimed)
+
+	require.NoError(t, s.ReleaseSessionClaim(ctx, "sess_1", "server-a"))
+	claimed, err = s.ClaimSession(ctx, "sess_1", "server-b", until)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	// Expired claims can be taken over
+	claimed, err = s.ClaimSession(ctx, "sess_3", "server-a", time.Now().Add(-time.Second))
+	require.NoError(t, err)
+	assert.True(t, claimed)
+	claimed, err = s.ClaimSession(ctx, "sess_3", "server-b", until)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+}
+
+func testLimitChanges(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	createChildren(t, s, newChild("alice", "Alice"), newChild("bob", "Bob"))
+	now := time.Now().Truncate(time.Second)
+	nextMonday := monday.AddDate(0, 0, 7)
+
+	require.NoError(t, s.CreateLimitChange(ctx, &core.LimitChange{
+		ID: "lim_2", ChildID: "alice", WeekdayLimit: 45, WeekendLimit: 90,
+		EffectiveFrom: nextMonday, CreatedBy: "api", CreatedAt: now,
+	}))
+	require.NoError(t, s.CreateLimitChange(ctx, &core.LimitChange{
+		ID: "lim_1", ChildID: "alice", WeekdayLimit: 60, WeekendLimit: 120,
+		EffectiveFrom: monday, CreatedBy: "api", CreatedAt: now, AppliedAt: &now,
+	}))
+	require.NoError(t, s.CreateLimitChange(ctx, &core.LimitChange{
+		ID: "lim_3", ChildID: "bob", WeekdayLimit: 30, WeekendLimit: 60,
+		EffectiveFrom: nextMonday, CreatedBy: "telegram:1", CreatedAt: now,
+	}))
+	assert.Error(t, s.CreateLimitChange(ctx, &core.LimitChange{ID: "lim_4", ChildID: "nobody", EffectiveFrom: monday, CreatedBy: "api", CreatedAt: now}), "unknown child")
+
+	// Oldest effective date first
+	changes, err := s.ListLimitChanges(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	assert.Equal(t, "lim_1", changes[0].ID)
+	require.NotNil(t, changes[0].AppliedAt)
+	assert.True(t, changes[0].AppliedAt.Equal(now))
+	assert.Equal(t, "lim_2", changes[1].ID)
+	assert.Equal(t, 45, changes[1].WeekdayLimit)
+	assert.Equal(t, 90, changes[1].WeekendLimit)
+	assert.Equal(t, "2026-01-12", changes[1].EffectiveFrom.Format("2006-01-02"))
+	assert.Equal(t, "api", changes[1].CreatedBy)
+	assert.Nil(t, changes[1].AppliedAt)
+
+	all, err := s.ListLimitChanges(ctx, "")
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	// Children carry their pending changes
+	alice, err := s.GetChild(ctx, "alice")
+	require.NoError(t, err)
+	require.Len(t, alice.ScheduledLimits, 1)
+	assert.Equal(t, "lim_2", alice.ScheduledLimits[0].ID)
+	assert.Equal(t, 60, alice.GetDailyLimit(monday))
+	assert.Equal(t, 45, alice.GetDailyLimit(nextMonday))
+
+	children, err := s.ListChildren(ctx)
+	require.NoError(t, err)
+	require.Len(t, children, 2)
+	assert.Len(t, children[1].ScheduledLimits, 1, "bob")
+
+	// Applying a change removes it from the pending ones
+	appliedAt := now.Add(time.Minute)
+	changes[1].AppliedAt = &appliedAt
+	require.NoError(t, s.UpdateLimitChange(ctx, changes[1]))
+	alice, err = s.GetChild(ctx, "alice")
+	require.NoError(t, err)
+	assert.Empty(t, alice.ScheduledLimits)
+
+	require.NoError(t, s.DeleteLimitChange(ctx, "lim_3"))
+	assert.ErrorIs(t, s.DeleteLimitChange(ctx, "lim_3"), core.ErrLimitChangeNotFound)
+	assert.ErrorIs(t, s.UpdateLimitChange(ctx, &core.LimitChange{ID: "lim_3", EffectiveFrom: monday}), core.ErrLimitChangeNotFound)
+
+	// Changes are deleted with the child
+	require.NoError(t, s.DeleteChild(ctx, "alice"))
+	all, err = s.ListLimitChanges(ctx, "")
+	require.NoError(t, err)
+	assert.Empty(t, all)
+}
+
+func testAuditLog(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	entries, err := s.ListAuditEntries(ctx, "", 10)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	require.NoError(t, s.CreateAuditEntry(ctx, &core.AuditEntry{
+		ID: "aud_1", Action: core.AuditLimitsScheduled, ChildID: "alice", Actor: "api",
+		Details: "weekday 60 → 45 min", CreatedAt: now.Add(-time.Hour),
+	}))
+	require.NoError(t, s.CreateAuditEntry(ctx, &core.AuditEntry{
+		ID: "aud_2", Action: core.AuditLimitsApplied, ChildID: "alice", Actor: core.AuditActorSchedule, CreatedAt: now,
+	}))
+	require.NoError(t, s.CreateAuditEntry(ctx, &core.AuditEntry{
+		ID: "aud_3", Action: core.AuditLimitsChanged, ChildID: "bob", Actor: "telegram:1", CreatedAt: now.Add(-2 * time.Hour),
+	}))
+
+	// Newest first
+	entries, err = s.ListAuditEntries(ctx, "", 10)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, []string{"aud_2", "aud_1", "aud_3"}, []string{entries[0].ID, entries[1].ID, entries[2].ID})
+	assert.Equal(t, core.AuditLimitsScheduled, entries[1].Action)
+	assert.Equal(t, "alice", entries[1].ChildID)
+	assert.Equal(t, "api", entries[1].Actor)
+	assert.Equal(t, "weekday 60 → 45 min", entries[1].Details)
+	assert.True(t, entries[1].CreatedAt.Equal(now.Add(-time.Hour)))
+	assert.Empty(t, entries[0].Details)
+
+	entries, err = s.ListAuditEntries(ctx, "alice", 1)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "aud_2", entries[0].ID)
+}
+
+func testProfileTransitions(t *testing.T, s storage.Storage) {
+	ctx := context.Background()
+	createChildren(t, s, newChild("alice", "Alice"), newChild("bob", "Bob"))
+	now := time.Now().Truncate(time.Second)
+
+	_, err := s.GetProfileTransition(ctx, "prf_1")
+	assert.ErrorIs(t, err, core.ErrProfileTransitionNotFound)
+
+	require.NoError(t, s.CreateProfileTransition(ctx, &core.ProfileTransition{
+		ID: "prf_2", ChildID: "bob", Profile: "6-8", Age: 6, WeekdayLimit: 45, WeekendLimit: 90,
+		Status: core.ProfileTransitionPending, CreatedAt: now,
+	}))
+	require.NoError(t, s.CreateProfileTransition(ctx, &core.ProfileTransition{
+		ID: "prf_1", ChildID: "alice", Profile: "9-12", Age: 9, WeekdayLimit: 60, WeekendLimit: 120,
+		Status: core.ProfileTransitionPending, CreatedAt: now.Add(-time.Hour),
+	}))
+	assert.Error(t, s.CreateProfileTransition(ctx, &core.ProfileTransition{
+		ID: "prf_3", ChildID: "nobody", Profile: "6-8", Status: core.ProfileTransitionPending, CreatedAt: now,
+	}), "unknown child")
+
+	got, err := s.GetProfileTransition(ctx, "prf_1")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", got.ChildID)
+	assert.Equal(t, "9-12", got.Profile)
+	assert.Equal(t, 9, got.Age)
+	assert.Equal(t, 60, got.WeekdayLimit)
+	assert.Equal(t, 120, got.WeekendLimit)
+	assert.True(t, got.IsPending())
+	assert.True(t, got.CreatedAt.Equal(now.Add(-time.Hour)))
+	assert.Empty(t, got.ResolvedBy)
+	assert.Nil(t, got.ResolvedAt)
+
+	// Oldest first
+	transitions, err := s.ListProfileTransitions(ctx, core.ProfileTransitionPending)
+	require.NoError(t, err)
+	require.Len(t, transitions, 2)
+	assert.Equal(t, "prf_1", transitions[0].ID)
+	assert.Equal(t, "prf_2", transitions[1].ID)
+
+	got.Status = core.ProfileTransitionConfirmed
+	got.ResolvedBy = "telegram:1"
+	got.ResolvedAt = &now
+	require.NoError(t, s.UpdateProfileTransition(ctx, got))
+	assert.ErrorIs(t, s.UpdateProfileTransition(ctx, &core.ProfileTransition{ID: "prf_3"}), core.ErrProfileTransitionNotFound)
+
+	transitions, err = s.ListProfileTransitions(ctx, core.ProfileTransitionPending)
+	require.NoError(t, err)
+	require.Len(t, transitions, 1)
+	assert.Equal(t, "prf_2", transitions[0].ID)
+
+	got, err = s.GetProfileTransition(ctx, "prf_1")
+	require.NoError(t, err)
+	assert.Equal(t, core.ProfileTransitionConfirmed, got.Status)
+	assert.Equal(t, "telegram:1", got.ResolvedBy)
+	require.NotNil(t, got.ResolvedAt)
+	assert.True(t, got.ResolvedAt.Equal(now))
+
+	// Transitions are deleted with the child
+	require.NoError(t, s.DeleteChild(ctx, "bob"))
+	transitions, err = s.ListProfileTransitions(ctx, "")
+	require.NoError(t, err)
+	require.Len(t, transitions, 1)
+	assert.Equal(t, "prf_1", transitions[0].ID)
+}
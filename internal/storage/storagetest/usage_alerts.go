@@ -0,0 +1,89 @@
+package storagetest
+
+import (
+	"context"
+	"metron/internal/core"
+	"metron/internal/storage"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// UsageAlertStorage is a storage backend that also stores usage alerts
+type UsageAlertStorage interface {
+	storage.Storage
+	core.UsageAlertStorage
+}
+
+func testUsageAlerts(t *testing.T, s UsageAlertStorage) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	yesterday := today.AddDate(0, 0, -1)
+	createChildren(t, s, newChild("alice", "Alice"), newChild("bob", "Bob"))
+
+	alerts, err := s.ListUsageAlerts(ctx, time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, alerts)
+
+	threshold, err := s.MaxUsageAlertThreshold(ctx, "alice", today)
+	require.NoError(t, err)
+	assert.Equal(t, 0, threshold)
+
+	require.NoError(t, s.CreateUsageAlert(ctx, &core.UsageAlert{
+		ID: "ual_2", ChildID: "alice", Date: today, Threshold: 90,
+		UsedMinutes: 54, LimitMinutes: 60, CreatedAt: now,
+	}))
+	require.NoError(t, s.CreateUsageAlert(ctx, &core.UsageAlert{
+		ID: "ual_1", ChildID: "alice", Date: today, Threshold: 80,
+		UsedMinutes: 48, LimitMinutes: 60, CreatedAt: now.Add(-time.Hour),
+	}))
+	require.NoError(t, s.CreateUsageAlert(ctx, &core.UsageAlert{
+		ID: "ual_3", ChildID: "bob", Date: yesterday, Threshold: 100,
+		UsedMinutes: 90, LimitMinutes: 90, CreatedAt: now.Add(-24 * time.Hour),
+	}))
+
+	// A threshold is alerted once per child and day
+	err = s.CreateUsageAlert(ctx, &core.UsageAlert{
+		ID: "ual_4", ChildID: "alice", Date: today.Add(12 * time.Hour), Threshold: 80,
+		UsedMinutes: 50, LimitMinutes: 60, CreatedAt: now,
+	})
+	assert.Error(t, err)
+
+	threshold, err = s.MaxUsageAlertThreshold(ctx, "alice", today.Add(15*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 90, threshold, "any time of the day matches")
+
+	threshold, err = s.MaxUsageAlertThreshold(ctx, "bob", today)
+	require.NoError(t, err)
+	assert.Equal(t, 0, threshold, "yesterday's alerts do not count")
+
+	// Oldest first
+	alerts, err = s.ListUsageAlerts(ctx, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, alerts, 3)
+	assert.Equal(t, "ual_3", alerts[0].ID)
+	assert.Equal(t, "ual_1", alerts[1].ID)
+	assert.Equal(t, "ual_2", alerts[2].ID)
+	assert.Equal(t, "alice", alerts[2].ChildID)
+	assert.Equal(t, today.Format("2006-01-02"), alerts[2].Date.Format("2006-01-02"))
+	assert.Equal(t, 90, alerts[2].Threshold)
+	assert.Equal(t, 54, alerts[2].UsedMinutes)
+	assert.Equal(t, 60, alerts[2].LimitMinutes)
+	assert.True(t, alerts[2].CreatedAt.Equal(now))
+
+	// Only alerts created after since
+	alerts, err = s.ListUsageAlerts(ctx, now.Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "ual_2", alerts[0].ID)
+
+	// Deleting a child deletes its alerts
+	require.NoError(t, s.DeleteChild(ctx, "alice"))
+	alerts, err = s.ListUsageAlerts(ctx, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, alerts, 1)
+	assert.Equal(t, "ual_3", alerts[0].ID)
+}
@@ -0,0 +1,120 @@
+// Package systemd implements the small subset of systemd integration Metron needs:
+// sd_notify readiness/watchdog messages and PID files. It has no external dependencies.
+package systemd
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Notification states understood by systemd (see sd_notify(3))
+const (
+	StateReady    = "READY=1"
+	StateStopping = "STOPPING=1"
+	StateWatchdog = "WATCHDOG=1"
+)
+
+// Notify sends a state message to the systemd notification socket
+// Returns false with no error if the process is not running under systemd with Type=notify
+func Notify(state string) (bool, error) {
+	socketPath := os.Getenv("NOTIFY_SOCKET")
+	if socketPath == "" {
+		return false, nil
+	}
+
+	// Abstract namespace sockets are prefixed with '@'
+	if socketPath[0] == '@' {
+		socketPath = "\x00" + socketPath[1:]
+	}
+
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	if err != nil {
+		return false, fmt.Errorf("failed to connect to notify socket: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, fmt.Errorf("failed to send notification: %w", err)
+	}
+
+	return true, nil
+}
+
+// NotifyStatus sends a free-form status line shown by `systemctl status`
+func NotifyStatus(status string) (bool, error) {
+	return Notify("STATUS=" + status)
+}
+
+// WatchdogInterval returns the watchdog timeout configured via WatchdogSec=
+// Returns false if the watchdog is not enabled for this process
+func WatchdogInterval() (time.Duration, bool) {
+	usecStr := os.Getenv("WATCHDOG_USEC")
+	if usecStr == "" {
+		return 0, false
+	}
+
+	// WATCHDOG_PID, when set, must match our PID
+	if pidStr := os.Getenv("WATCHDOG_PID"); pidStr != "" {
+		pid, err := strconv.Atoi(pidStr)
+		if err != nil || pid != os.Getpid() {
+			return 0, false
+		}
+	}
+
+	usec, err := strconv.ParseInt(usecStr, 10, 64)
+	if err != nil || usec <= 0 {
+		return 0, false
+	}
+
+	return time.Duration(usec) * time.Microsecond, true
+}
+
+// ErrPIDFileLocked is returned when the PID file belongs to a running process
+var ErrPIDFileLocked = errors.New("pid file is held by a running process")
+
+// WritePIDFile writes the current PID to path
+// Fails with ErrPIDFileLocked if the file references another live process; stale files are replaced
+func WritePIDFile(path string) error {
+	if data, err := os.ReadFile(path); err == nil {
+		if pid, err := strconv.Atoi(string(trimNewline(data))); err == nil && pid != os.Getpid() && processAlive(pid) {
+			return fmt.Errorf("%w: %s (pid %d)", ErrPIDFileLocked, path, pid)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())+"\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write pid file: %w", err)
+	}
+	return nil
+}
+
+// RemovePIDFile removes path if it still contains the current PID
+func RemovePIDFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read pid file: %w", err)
+	}
+
+	if pid, err := strconv.Atoi(string(trimNewline(data))); err != nil || pid != os.Getpid() {
+		// Another process owns the file now, leave it alone
+		return nil
+	}
+
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to remove pid file: %w", err)
+	}
+	return nil
+}
+
+func trimNewline(data []byte) []byte {
+	for len(data) > 0 && (data[len(data)-1] == '\n' || data[len(data)-1] == '\r' || data[len(data)-1] == ' ') {
+		data = data[:len(data)-1]
+	}
+	return data
+}
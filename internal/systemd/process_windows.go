@@ -0,0 +1,9 @@
+//go:build windows
+
+package systemd
+
+// processAlive always reports false on Windows (systemd is not available there),
+// so stale PID files are simply overwritten
+func processAlive(pid int) bool {
+	return false
+}
@@ -0,0 +1,109 @@
+// Package webhook delivers Metron events to a parent-configured HTTP endpoint, so alerts can
+// reach home automation or chat services that the Telegram bot does not cover.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"metron/internal/core"
+)
+
+// SignatureHeader carries the hex HMAC-SHA256 of the body when a secret is configured
+const SignatureHeader = "X-Metron-Signature"
+
+// EventUsageThresholdReached is sent when a child reaches a usage alert threshold
+const EventUsageThresholdReached = "usage.threshold_reached"
+
+// Notifier POSTs events as JSON to a URL
+type Notifier struct {
+	url        string
+	secret     string
+	httpClient *http.Client
+}
+
+// NewNotifier creates a webhook notifier; bodies are signed if secret is not empty
+func NewNotifier(url, secret string) *Notifier {
+	return &Notifier{
+		url:        url,
+		secret:     secret,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// usageAlertPayload is the body of usage.threshold_reached events
+type usageAlertPayload struct {
+	Event string `json:"event"`
+	Alert struct {
+		ID               string `json:"id"`
+		ChildID          string `json:"child_id"`
+		ChildName        string `json:"child_name"`
+		Date             string `json:"date"`
+		Threshold        int    `json:"threshold"`
+		UsedMinutes      int    `json:"used_minutes"`
+		LimitMinutes     int    `json:"limit_minutes"`
+		RemainingMinutes int    `json:"remaining_minutes"`
+		CreatedAt        string `json:"created_at"`
+	} `json:"alert"`
+}
+
+// NotifyUsageAlert sends a usage.threshold_reached event
+func (n *Notifier) NotifyUsageAlert(ctx context.Context, alert *core.UsageAlert, child *core.Child) error {
+	var payload usageAlertPayload
+	payload.Event = EventUsageThresholdReached
+	payload.Alert.ID = alert.ID
+	payload.Alert.ChildID = alert.ChildID
+	payload.Alert.ChildName = child.Name
+	payload.Alert.Date = alert.Date.Format("2006-01-02")
+	payload.Alert.Threshold = alert.Threshold
+	payload.Alert.UsedMinutes = alert.UsedMinutes
+	payload.Alert.LimitMinutes = alert.LimitMinutes
+	payload.Alert.RemainingMinutes = alert.RemainingMinutes()
+	payload.Alert.CreatedAt = alert.CreatedAt.Format(time.RFC3339)
+
+	return n.post(ctx, payload)
+}
+
+// post sends the payload; any response other than 2xx is an error
+func (n *Notifier) post(ctx context.Context, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if n.secret != "" {
+		req.Header.Set(SignatureHeader, Sign(n.secret, body))
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign returns the hex HMAC-SHA256 of body with the secret, as sent in SignatureHeader
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
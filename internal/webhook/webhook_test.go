@@ -0,0 +1,81 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"metron/internal/core"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testUsageAlert() (*core.UsageAlert, *core.Child) {
+	alert := &core.UsageAlert{
+		ID:           "ual_1",
+		ChildID:      "child1",
+		Date:         time.Date(2026, time.March, 10, 0, 0, 0, 0, time.UTC),
+		Threshold:    80,
+		UsedMinutes:  48,
+		LimitMinutes: 60,
+		CreatedAt:    time.Date(2026, time.March, 10, 15, 4, 5, 0, time.UTC),
+	}
+	return alert, &core.Child{ID: "child1", Name: "Alice"}
+}
+
+func TestNotifier_NotifyUsageAlert(t *testing.T) {
+	var body []byte
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		signature = r.Header.Get(SignatureHeader)
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	alert, child := testUsageAlert()
+	err := NewNotifier(server.URL, "s3cret").NotifyUsageAlert(context.Background(), alert, child)
+	require.NoError(t, err)
+
+	assert.Equal(t, Sign("s3cret", body), signature)
+
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &payload))
+	assert.Equal(t, EventUsageThresholdReached, payload["event"])
+	sent := payload["alert"].(map[string]interface{})
+	assert.Equal(t, "ual_1", sent["id"])
+	assert.Equal(t, "Alice", sent["child_name"])
+	assert.Equal(t, "2026-03-10", sent["date"])
+	assert.Equal(t, float64(80), sent["threshold"])
+	assert.Equal(t, float64(12), sent["remaining_minutes"])
+	assert.Equal(t, "2026-03-10T15:04:05Z", sent["created_at"])
+}
+
+func TestNotifier_NotifyUsageAlert_Unsigned(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get(SignatureHeader))
+	}))
+	defer server.Close()
+
+	alert, child := testUsageAlert()
+	err := NewNotifier(server.URL, "").NotifyUsageAlert(context.Background(), alert, child)
+	assert.NoError(t, err)
+}
+
+func TestNotifier_NotifyUsageAlert_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	alert, child := testUsageAlert()
+	err := NewNotifier(server.URL, "").NotifyUsageAlert(context.Background(), alert, child)
+	assert.ErrorContains(t, err, "502")
+}
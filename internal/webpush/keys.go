@@ -0,0 +1,92 @@
+package webpush
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"metron/internal/credentials"
+)
+
+// credentialName is the name the generated keys are stored under in the credentials store
+const credentialName = "webpush"
+
+// ErrInvalidKeys is returned for VAPID keys that are not a P-256 key pair
+var ErrInvalidKeys = errors.New("invalid VAPID keys")
+
+// Keys is a VAPID key pair, base64url without padding as browsers expect it
+type Keys struct {
+	PublicKey  string `json:"public_key"`  // Uncompressed P-256 point (65 bytes)
+	PrivateKey string `json:"private_key"` // P-256 scalar (32 bytes)
+}
+
+// GenerateKeys creates a new VAPID key pair
+func GenerateKeys() (Keys, error) {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return Keys{}, fmt.Errorf("failed to generate VAPID keys: %w", err)
+	}
+	return Keys{
+		PublicKey:  base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		PrivateKey: base64.RawURLEncoding.EncodeToString(key.Bytes()),
+	}, nil
+}
+
+// LoadKeys returns the configured keys, or the keys kept in the credentials store
+// On first use without configured keys, a key pair is generated and stored, so browsers
+// stay subscribed across restarts.
+func LoadKeys(ctx context.Context, store credentials.Store, configured Keys) (Keys, bool, error) {
+	if configured.PublicKey != "" || configured.PrivateKey != "" {
+		_, err := configured.signingKey()
+		return configured, false, err
+	}
+
+	var keys Keys
+	found, err := credentials.Get(ctx, store, credentialName, &keys)
+	if err != nil {
+		return Keys{}, false, err
+	}
+	if found {
+		_, err := keys.signingKey()
+		return keys, false, err
+	}
+
+	if keys, err = GenerateKeys(); err != nil {
+		return Keys{}, false, err
+	}
+	if err := credentials.Save(ctx, store, credentialName, keys); err != nil {
+		return Keys{}, false, err
+	}
+	return keys, true, nil
+}
+
+// signingKey returns the ECDSA key VAPID tokens are signed with, checking the public key matches
+func (k Keys) signingKey() (*ecdsa.PrivateKey, error) {
+	scalar, err := base64.RawURLEncoding.DecodeString(k.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: private key is not base64url", ErrInvalidKeys)
+	}
+	private, err := ecdh.P256().NewPrivateKey(scalar)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKeys, err)
+	}
+	public := private.PublicKey().Bytes()
+	if k.PublicKey != base64.RawURLEncoding.EncodeToString(public) {
+		return nil, fmt.Errorf("%w: public key does not match the private key", ErrInvalidKeys)
+	}
+
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P256(),
+			X:     new(big.Int).SetBytes(public[1:33]),
+			Y:     new(big.Int).SetBytes(public[33:]),
+		},
+		D: new(big.Int).SetBytes(scalar),
+	}, nil
+}
@@ -0,0 +1,237 @@
+// Package webpush delivers notifications to browsers with the Web Push protocol: messages are
+// encrypted for the subscribed browser (RFC 8291) and sent to its push service with a VAPID
+// token identifying the server (RFC 8292), so no third-party account is needed.
+package webpush
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"metron/internal/core"
+)
+
+const (
+	recordSize = 4096             // Encrypted record size announced in the header (one record per message)
+	tokenTTL   = 12 * time.Hour   // VAPID tokens may be valid for at most 24 hours
+	maxPayload = recordSize - 103 // Push services accept 4096-byte bodies: header, delimiter and GCM tag included
+)
+
+// payload is the JSON the child web app's service worker shows as a notification
+type payload struct {
+	Kind      string `json:"kind"`
+	Title     string `json:"title"`
+	Body      string `json:"body"`
+	ChildID   string `json:"child_id,omitempty"`
+	SessionID string `json:"session_id,omitempty"`
+}
+
+// Sender sends Web Push messages signed with a VAPID key pair
+type Sender struct {
+	keys       Keys
+	key        *ecdsa.PrivateKey
+	subject    string // Contact for push service operators (mailto: or https: URL)
+	httpClient *http.Client
+}
+
+// NewSender creates a sender; subject is a mailto: or https: URL push services can contact
+func NewSender(keys Keys, subject string) (*Sender, error) {
+	key, err := keys.signingKey()
+	if err != nil {
+		return nil, err
+	}
+	return &Sender{
+		keys:       keys,
+		key:        key,
+		subject:    subject,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Send encrypts the message for the subscription and posts it to the browser's push service
+// Returns core.ErrPushSubscriptionGone if the push service no longer knows the subscription.
+func (s *Sender) Send(ctx context.Context, subscription *core.PushSubscription, message *core.PushMessage) error {
+	body, err := json.Marshal(payload{
+		Kind:      message.Kind,
+		Title:     message.Title,
+		Body:      message.Body,
+		ChildID:   message.ChildID,
+		SessionID: message.SessionID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode push message: %w", err)
+	}
+	encrypted, err := encrypt(subscription, body)
+	if err != nil {
+		return err
+	}
+	token, err := s.token(subscription.Endpoint, time.Now())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, subscription.Endpoint, bytes.NewReader(encrypted))
+	if err != nil {
+		return fmt.Errorf("failed to create push request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("vapid t=%s, k=%s", token, s.keys.PublicKey))
+	req.Header.Set("Content-Encoding", "aes128gcm")
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("TTL", strconv.Itoa(int(message.TTL.Seconds())))
+	req.Header.Set("Urgency", "high")
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("push request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone:
+		return core.ErrPushSubscriptionGone
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return fmt.Errorf("push service returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// token returns the VAPID JWT for the push service of endpoint (ES256)
+func (s *Sender) token(endpoint string, now time.Time) (string, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid push endpoint: %w", err)
+	}
+	claims, err := json.Marshal(map[string]any{
+		"aud": parsed.Scheme + "://" + parsed.Host,
+		"exp": now.Add(tokenTTL).Unix(),
+		"sub": s.subject,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString([]byte(`{"typ":"JWT","alg":"ES256"}`)) + "." +
+		base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	r, sig, err := ecdsa.Sign(rand.Reader, s.key, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign VAPID token: %w", err)
+	}
+	signature := make([]byte, 64)
+	r.FillBytes(signature[:32])
+	sig.FillBytes(signature[32:])
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// encrypt encrypts plaintext for the subscription's browser as a single aes128gcm record
+// (RFC 8188), with a key agreed between a new server key and the browser's key (RFC 8291)
+func encrypt(subscription *core.PushSubscription, plaintext []byte) ([]byte, error) {
+	if len(plaintext) > maxPayload {
+		return nil, fmt.Errorf("push message is %d bytes, more than %d", len(plaintext), maxPayload)
+	}
+	browserKey, err := decodeKey(subscription.P256dh)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	browserPublic, err := ecdh.P256().NewPublicKey(browserKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid p256dh key: %w", err)
+	}
+	authSecret, err := decodeKey(subscription.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth secret: %w", err)
+	}
+
+	serverKey, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return encryptWith(serverKey, salt, browserPublic, browserKey, authSecret, plaintext)
+}
+
+// encryptWith encrypts plaintext with the given server key and salt (new for every message)
+func encryptWith(serverKey *ecdh.PrivateKey, salt []byte, browserPublic *ecdh.PublicKey, browserKey, authSecret, plaintext []byte) ([]byte, error) {
+	cek, nonce, err := deriveKeys(serverKey, browserPublic, serverKey.PublicKey().Bytes(), browserKey, authSecret, salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, err
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	// Header: salt, record size, key ID (the server's public key)
+	serverPublic := serverKey.PublicKey().Bytes()
+	header := make([]byte, 0, 21+len(serverPublic))
+	header = append(header, salt...)
+	header = binary.BigEndian.AppendUint32(header, recordSize)
+	header = append(header, byte(len(serverPublic)))
+	header = append(header, serverPublic...)
+
+	// The last (and only) record ends with the 0x02 delimiter
+	record := append(append([]byte(nil), plaintext...), 0x02)
+	return gcm.Seal(header, nonce, record, nil), nil
+}
+
+// deriveKeys derives the content encryption key and nonce from the ECDH secret of
+// private and peer, the browser's auth secret and the record's salt (RFC 8291 section 3.4)
+// serverPublic and browserPublic are the uncompressed public keys of both sides.
+func deriveKeys(private *ecdh.PrivateKey, peer *ecdh.PublicKey, serverPublic, browserPublic, authSecret, salt []byte) ([]byte, []byte, error) {
+	shared, err := private.ECDH(peer)
+	if err != nil {
+		return nil, nil, err
+	}
+	prkKey, err := hkdf.Extract(sha256.New, shared, authSecret)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyInfo := "WebPush: info\x00" + string(browserPublic) + string(serverPublic)
+	ikm, err := hkdf.Expand(sha256.New, prkKey, keyInfo, 32)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prk, err := hkdf.Extract(sha256.New, ikm, salt)
+	if err != nil {
+		return nil, nil, err
+	}
+	cek, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: aes128gcm\x00", 16)
+	if err != nil {
+		return nil, nil, err
+	}
+	nonce, err := hkdf.Expand(sha256.New, prk, "Content-Encoding: nonce\x00", 12)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cek, nonce, nil
+}
+
+// decodeKey decodes a base64url key, padded or not (browsers send it unpadded)
+func decodeKey(key string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(key, "="))
+}
@@ -0,0 +1,217 @@
+package webpush
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"metron/internal/core"
+	"metron/internal/storage/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// browser is the key pair and auth secret a browser subscribes with
+type browser struct {
+	key  *ecdh.PrivateKey
+	auth []byte
+}
+
+func newBrowser(t *testing.T) *browser {
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	auth := make([]byte, 16)
+	_, err = rand.Read(auth)
+	require.NoError(t, err)
+	return &browser{key: key, auth: auth}
+}
+
+func (b *browser) subscription(endpoint string) *core.PushSubscription {
+	return &core.PushSubscription{
+		ID:       "psh_1",
+		Endpoint: endpoint,
+		P256dh:   base64.RawURLEncoding.EncodeToString(b.key.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(b.auth),
+	}
+}
+
+// decrypt decrypts a message as the browser does
+func (b *browser) decrypt(t *testing.T, body []byte) []byte {
+	require.Greater(t, len(body), 21)
+	salt := body[:16]
+	assert.Equal(t, uint32(recordSize), binary.BigEndian.Uint32(body[16:20]))
+	idLen := int(body[20])
+	serverPublic := body[21 : 21+idLen]
+	peer, err := ecdh.P256().NewPublicKey(serverPublic)
+	require.NoError(t, err)
+
+	cek, nonce, err := deriveKeys(b.key, peer, serverPublic, b.key.PublicKey().Bytes(), b.auth, salt)
+	require.NoError(t, err)
+	block, err := aes.NewCipher(cek)
+	require.NoError(t, err)
+	gcm, err := cipher.NewGCM(block)
+	require.NoError(t, err)
+	record, err := gcm.Open(nil, nonce, body[21+idLen:], nil)
+	require.NoError(t, err)
+	require.Equal(t, byte(0x02), record[len(record)-1], "last record delimiter")
+	return record[:len(record)-1]
+}
+
+func TestEncrypt_RoundTrip(t *testing.T) {
+	b := newBrowser(t)
+	message := []byte(`{"title":"Time is almost up"}`)
+
+	encrypted, err := encrypt(b.subscription("https://push.example.com/1"), message)
+	require.NoError(t, err)
+	assert.Equal(t, message, b.decrypt(t, encrypted))
+
+	// Every message gets a new key and salt
+	again, err := encrypt(b.subscription("https://push.example.com/1"), message)
+	require.NoError(t, err)
+	assert.NotEqual(t, encrypted[:16], again[:16])
+
+	_, err = encrypt(b.subscription("https://push.example.com/1"), make([]byte, maxPayload+1))
+	assert.Error(t, err)
+}
+
+func TestSender_Token(t *testing.T) {
+	keys, err := GenerateKeys()
+	require.NoError(t, err)
+	sender, err := NewSender(keys, "mailto:parent@example.com")
+	require.NoError(t, err)
+
+	now := time.Date(2026, time.March, 10, 12, 0, 0, 0, time.UTC)
+	token, err := sender.token("https://fcm.googleapis.com/fcm/send/abc", now)
+	require.NoError(t, err)
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 3)
+
+	claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(claims, &decoded))
+	assert.Equal(t, "https://fcm.googleapis.com", decoded["aud"])
+	assert.Equal(t, "mailto:parent@example.com", decoded["sub"])
+	assert.Equal(t, float64(now.Add(tokenTTL).Unix()), decoded["exp"])
+
+	// The signature verifies with the public key browsers subscribed with
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	require.Len(t, signature, 64)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	r, s := new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:])
+	assert.True(t, ecdsa.Verify(&sender.key.PublicKey, digest[:], r, s))
+}
+
+func TestSender_Send(t *testing.T) {
+	keys, err := GenerateKeys()
+	require.NoError(t, err)
+	sender, err := NewSender(keys, "mailto:parent@example.com")
+	require.NoError(t, err)
+	b := newBrowser(t)
+
+	var received *http.Request
+	var body []byte
+	status := http.StatusCreated
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	message := &core.PushMessage{
+		Kind:      core.PushSessionWarning,
+		Title:     "Time is almost up",
+		Body:      "5 minutes left on tv",
+		ChildID:   "kid_1",
+		SessionID: "sess_1",
+		TTL:       5 * time.Minute,
+	}
+	require.NoError(t, sender.Send(context.Background(), b.subscription(server.URL+"/push/1"), message))
+
+	assert.Equal(t, "/push/1", received.URL.Path)
+	assert.Equal(t, "aes128gcm", received.Header.Get("Content-Encoding"))
+	assert.Equal(t, "300", received.Header.Get("TTL"))
+	assert.True(t, strings.HasPrefix(received.Header.Get("Authorization"), "vapid t="))
+	assert.True(t, strings.HasSuffix(received.Header.Get("Authorization"), ", k="+keys.PublicKey))
+
+	var decoded map[string]string
+	require.NoError(t, json.Unmarshal(b.decrypt(t, body), &decoded))
+	assert.Equal(t, map[string]string{
+		"kind":       core.PushSessionWarning,
+		"title":      "Time is almost up",
+		"body":       "5 minutes left on tv",
+		"child_id":   "kid_1",
+		"session_id": "sess_1",
+	}, decoded)
+
+	// Dropped subscriptions are reported as gone, other failures as errors
+	status = http.StatusGone
+	assert.ErrorIs(t, sender.Send(context.Background(), b.subscription(server.URL), message), core.ErrPushSubscriptionGone)
+	status = http.StatusTooManyRequests
+	err = sender.Send(context.Background(), b.subscription(server.URL), message)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, core.ErrPushSubscriptionGone)
+}
+
+func TestLoadKeys(t *testing.T) {
+	ctx := context.Background()
+	store := memory.New(nil)
+
+	// Generated and stored on first use
+	keys, generated, err := LoadKeys(ctx, store, Keys{})
+	require.NoError(t, err)
+	assert.True(t, generated)
+	assert.NotEmpty(t, keys.PublicKey)
+
+	again, generated, err := LoadKeys(ctx, store, Keys{})
+	require.NoError(t, err)
+	assert.False(t, generated)
+	assert.Equal(t, keys, again)
+
+	// Configured keys win, and must be a key pair
+	configured, err := GenerateKeys()
+	require.NoError(t, err)
+	loaded, _, err := LoadKeys(ctx, store, configured)
+	require.NoError(t, err)
+	assert.Equal(t, configured, loaded)
+
+	_, _, err = LoadKeys(ctx, store, Keys{PublicKey: keys.PublicKey, PrivateKey: configured.PrivateKey})
+	assert.ErrorIs(t, err, ErrInvalidKeys)
+}
+
+// TestEncrypt_RFC8291 encrypts the example message of RFC 8291 appendix A
+func TestEncrypt_RFC8291(t *testing.T) {
+	decode := func(s string) []byte {
+		data, err := base64.RawURLEncoding.DecodeString(s)
+		require.NoError(t, err)
+		return data
+	}
+	serverKey, err := ecdh.P256().NewPrivateKey(decode("yfWPiYE-n46HLnH0KqZOF1fJJU3MYrct3AELtAQ-oRw"))
+	require.NoError(t, err)
+	browserKey := decode("BCVxsr7N_eNgVRqvHtD0zTZsEc6-VV-JvLexhqUzORcxaOzi6-AYWXvTBHm4bjyPjs7Vd8pZGH6SRpkNtoIAiw4")
+	browserPublic, err := ecdh.P256().NewPublicKey(browserKey)
+	require.NoError(t, err)
+
+	encrypted, err := encryptWith(serverKey, decode("DGv6ra1nlYgDCS1FRnbzlw"), browserPublic, browserKey,
+		decode("BTBZMqHH6r4Tts7J_aSIgg"), []byte("When I grow up, I want to be a watermelon"))
+	require.NoError(t, err)
+	assert.Equal(t, "DGv6ra1nlYgDCS1FRnbzlwAAEABBBP4z9KsN6nGRTbVYI_c7VJSPQTBtkgcy27mlmlMoZIIgDll6e3vCYLocInmYWAmS6TlzAC8wEqKK6PBru3jl7A_yl95bQpu6cVPTpK4Mqgkf1CXztLVBSt2Ks3oZwbuwXPXLWyouBWLVWGNWQexSgSxsj_Qulcy4a-fN",
+		base64.RawURLEncoding.EncodeToString(encrypted))
+}
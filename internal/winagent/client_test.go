@@ -22,8 +22,8 @@ func TestHTTPMetronClient_GetSessionStatus_Success(t *testing.T) {
 		if r.Method != "GET" {
 			t.Errorf("Expected GET method, got %s", r.Method)
 		}
-		if r.URL.Path != "/v1/agent/session" {
-			t.Errorf("Expected path /v1/agent/session, got %s", r.URL.Path)
+		if r.URL.Path != "/api/v1/agent/session" {
+			t.Errorf("Expected path /api/v1/agent/session, got %s", r.URL.Path)
 		}
 		if r.URL.Query().Get("device_id") != "test-device" {
 			t.Errorf("Expected device_id=test-device, got %s", r.URL.Query().Get("device_id"))
@@ -173,6 +173,81 @@ func TestHTTPMetronClient_GetSessionStatus_ServerError(t *testing.T) {
 	}
 }
 
+func TestHTTPMetronClient_ReportActivity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("Expected POST method, got %s", r.Method)
+		}
+		if r.URL.Path != "/api/v1/agent/activity" {
+			t.Errorf("Expected path /api/v1/agent/activity, got %s", r.URL.Path)
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected JSON content type, got %s", r.Header.Get("Content-Type"))
+		}
+
+		var body struct {
+			DeviceID    string `json:"device_id"`
+			IdleSeconds int    `json:"idle_seconds"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode body: %v", err)
+		}
+		if body.DeviceID != "test-device" || body.IdleSeconds != 90 {
+			t.Errorf("Unexpected body: %+v", body)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"recorded": true}`))
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	client := NewHTTPMetronClient(server.URL, "test-token", logger)
+
+	if err := client.ReportActivity(context.Background(), "test-device", 90*time.Second); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestHTTPMetronClient_ReportEvents(t *testing.T) {
+	occurredAt := time.Date(2026, 3, 2, 10, 0, 0, 0, time.UTC)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("Expected POST method, got %s", r.Method)
+		}
+		if r.URL.Path != "/api/v1/agent/events" {
+			t.Errorf("Expected path /api/v1/agent/events, got %s", r.URL.Path)
+		}
+
+		var body struct {
+			DeviceID string        `json:"device_id"`
+			Events   []TamperEvent `json:"events"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode body: %v", err)
+		}
+		if body.DeviceID != "test-device" || len(body.Events) != 1 {
+			t.Fatalf("Unexpected body: %+v", body)
+		}
+		if body.Events[0].Type != TamperSafeModeBoot || !body.Events[0].OccurredAt.Equal(occurredAt) {
+			t.Errorf("Unexpected event: %+v", body.Events[0])
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"recorded": 1}`))
+	}))
+	defer server.Close()
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	client := NewHTTPMetronClient(server.URL, "test-token", logger)
+
+	events := []TamperEvent{{Type: TamperSafeModeBoot, OccurredAt: occurredAt}}
+	if err := client.ReportEvents(context.Background(), "test-device", events); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
 func TestHTTPMetronClient_GetSessionStatus_NetworkError(t *testing.T) {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
 	// Use a URL that won't connect
@@ -7,11 +7,12 @@ import (
 )
 
 var (
-	ErrMissingDeviceID  = errors.New("device_id is required")
-	ErrMissingToken     = errors.New("agent_token is required")
-	ErrMissingURL       = errors.New("metron_base_url is required")
-	ErrInvalidInterval  = errors.New("poll_interval must be positive")
-	ErrInvalidGrace     = errors.New("grace_period must be positive")
+	ErrMissingDeviceID       = errors.New("device_id is required")
+	ErrMissingToken          = errors.New("agent_token is required")
+	ErrMissingURL            = errors.New("metron_base_url is required")
+	ErrInvalidInterval       = errors.New("poll_interval must be positive")
+	ErrInvalidGrace          = errors.New("grace_period must be positive")
+	ErrInvalidUpdateInterval = errors.New("update_interval must be positive")
 )
 
 // Config holds the Windows agent configuration
@@ -23,14 +24,20 @@ type Config struct {
 	GracePeriod   time.Duration // Grace period on network error before locking (default: 30s)
 	LogPath       string        // Log file path (empty = stdout)
 	LogLevel      string        // Log level: debug, info, warn, error
+	MarkerPath    string        // File that exists while the agent runs, to detect kills (empty = disabled)
+
+	// Self-update (disabled without a public key)
+	UpdatePublicKey string        // Base64 Ed25519 key that releases must be signed with
+	UpdateInterval  time.Duration // How often to check for updates (default: 6h)
 }
 
 // DefaultConfig returns a config with default values
 func DefaultConfig() *Config {
 	return &Config{
-		PollInterval: 15 * time.Second,
-		GracePeriod:  30 * time.Second,
-		LogLevel:     "info",
+		PollInterval:   15 * time.Second,
+		GracePeriod:    30 * time.Second,
+		LogLevel:       "info",
+		UpdateInterval: 6 * time.Hour,
 	}
 }
 
@@ -51,5 +58,8 @@ func (c *Config) Validate() error {
 	if c.GracePeriod <= 0 {
 		return ErrInvalidGrace
 	}
+	if c.UpdatePublicKey != "" && c.UpdateInterval <= 0 {
+		return ErrInvalidUpdateInterval
+	}
 	return nil
 }
@@ -15,11 +15,12 @@ const (
 
 // EnforcerState tracks the current enforcement state
 type EnforcerState struct {
-	LastSessionID      *string    // Last known session ID
-	WarningSent        bool       // Whether warning was sent for current session
-	LastLockTime       *time.Time // When we last locked (debounce)
-	LastSuccessfulPoll *time.Time // For network error grace period
-	NetworkErrorSince  *time.Time // When network errors started
+	LastSessionID      *string        // Last known session ID
+	WarningSent        bool           // Whether warning was sent for current session
+	LastLockTime       *time.Time     // When we last locked (debounce)
+	LastSuccessfulPoll *time.Time     // For network error grace period
+	NetworkErrorSince  *time.Time     // When network errors started
+	ClockSkew          *time.Duration // Local clock minus server time at the last poll
 }
 
 // Enforcer manages the enforcement loop
@@ -30,6 +31,9 @@ type Enforcer struct {
 	config   *Config
 	state    EnforcerState
 	logger   *slog.Logger
+
+	pendingTamper []TamperEvent // Tamper events not yet sent to the backend
+
 	stopChan chan struct{}
 	wg       sync.WaitGroup
 	mu       sync.Mutex
@@ -59,6 +63,8 @@ func (e *Enforcer) Start(ctx context.Context) {
 	ticker := e.clock.NewTicker(e.config.PollInterval)
 	defer ticker.Stop()
 
+	e.checkSafeMode()
+
 	// Do an initial poll immediately
 	e.poll(ctx)
 
@@ -92,6 +98,36 @@ func (e *Enforcer) poll(ctx context.Context) {
 	}
 
 	e.processStatus(status)
+
+	if status.Active && !status.BypassMode {
+		e.reportActivity(ctx)
+	}
+
+	if err := e.FlushTamperEvents(ctx); err != nil {
+		e.logger.Warn("failed to report tamper events", "error", err)
+	}
+}
+
+// reportActivity sends the idle time to the backend if the platform can measure it
+// Failures are logged only; idle reporting never affects enforcement
+func (e *Enforcer) reportActivity(ctx context.Context) {
+	detector, ok := e.platform.(IdleDetector)
+	if !ok {
+		return
+	}
+
+	idle, err := detector.IdleDuration()
+	if err != nil {
+		e.logger.Warn("failed to measure idle time", "error", err)
+		return
+	}
+
+	if err := e.client.ReportActivity(ctx, e.config.DeviceID, idle); err != nil {
+		e.logger.Warn("failed to report activity", "error", err)
+		return
+	}
+
+	e.logger.Debug("activity reported", "idle", idle.Round(time.Second))
 }
 
 // processStatus handles a successful poll result
@@ -105,6 +141,8 @@ func (e *Enforcer) processStatus(status *SessionStatus) {
 	e.state.LastSuccessfulPoll = &now
 	e.state.NetworkErrorSince = nil
 
+	e.detectClockChange(now, status.ServerTime)
+
 	// Check bypass mode first - no enforcement needed
 	if status.BypassMode {
 		e.logger.Debug("bypass mode active, skipping enforcement")
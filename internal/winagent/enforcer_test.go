@@ -11,10 +11,15 @@ import (
 
 // MockMetronClient is a test double for MetronClient
 type MockMetronClient struct {
-	StatusToReturn *SessionStatus
-	ErrorToReturn  error
-	CallCount      int
-	LastDeviceID   string
+	StatusToReturn      *SessionStatus
+	ErrorToReturn       error
+	CallCount           int
+	LastDeviceID        string
+	ActivityCallCount   int
+	LastIdleReported    time.Duration
+	ActivityErrToReturn error
+	ReportedEvents      []TamperEvent
+	EventsErrToReturn   error
 }
 
 func (m *MockMetronClient) GetSessionStatus(ctx context.Context, deviceID string) (*SessionStatus, error) {
@@ -23,6 +28,20 @@ func (m *MockMetronClient) GetSessionStatus(ctx context.Context, deviceID string
 	return m.StatusToReturn, m.ErrorToReturn
 }
 
+func (m *MockMetronClient) ReportActivity(ctx context.Context, deviceID string, idle time.Duration) error {
+	m.ActivityCallCount++
+	m.LastIdleReported = idle
+	return m.ActivityErrToReturn
+}
+
+func (m *MockMetronClient) ReportEvents(ctx context.Context, deviceID string, events []TamperEvent) error {
+	if m.EventsErrToReturn != nil {
+		return m.EventsErrToReturn
+	}
+	m.ReportedEvents = append(m.ReportedEvents, events...)
+	return nil
+}
+
 // MockPlatform is a test double for Platform
 type MockPlatform struct {
 	LockCallCount    int
@@ -45,6 +64,16 @@ func (m *MockPlatform) ShowWarningNotification(title, message string) error {
 	return m.WarningError
 }
 
+// MockIdlePlatform is a MockPlatform that can also measure idle time
+type MockIdlePlatform struct {
+	MockPlatform
+	IdleToReturn time.Duration
+}
+
+func (m *MockIdlePlatform) IdleDuration() (time.Duration, error) {
+	return m.IdleToReturn, nil
+}
+
 func newTestEnforcer(client MetronClient, platform Platform, clock Clock) *Enforcer {
 	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
 	config := &Config{
@@ -101,6 +130,51 @@ func TestActiveSession_NoLock(t *testing.T) {
 	}
 }
 
+func TestActiveSession_ReportsIdle(t *testing.T) {
+	now := time.Now()
+	sessionID := "session-123"
+	endsAt := now.Add(30 * time.Minute)
+
+	client := &MockMetronClient{
+		StatusToReturn: &SessionStatus{
+			Active:     true,
+			SessionID:  &sessionID,
+			EndsAt:     &endsAt,
+			ServerTime: now,
+		},
+	}
+	platform := &MockIdlePlatform{IdleToReturn: 3 * time.Minute}
+	clock := &MockClock{CurrentTime: now}
+
+	enforcer := newTestEnforcer(client, platform, clock)
+	enforcer.poll(context.Background())
+
+	if client.ActivityCallCount != 1 {
+		t.Fatalf("Expected activity to be reported once, got %d", client.ActivityCallCount)
+	}
+	if client.LastIdleReported != 3*time.Minute {
+		t.Errorf("Expected idle 3m reported, got %v", client.LastIdleReported)
+	}
+}
+
+func TestNoSession_NoIdleReport(t *testing.T) {
+	client := &MockMetronClient{
+		StatusToReturn: &SessionStatus{
+			Active:     false,
+			ServerTime: time.Now(),
+		},
+	}
+	platform := &MockIdlePlatform{IdleToReturn: time.Minute}
+	clock := &MockClock{CurrentTime: time.Now()}
+
+	enforcer := newTestEnforcer(client, platform, clock)
+	enforcer.poll(context.Background())
+
+	if client.ActivityCallCount != 0 {
+		t.Errorf("Expected no activity report without a session, got %d", client.ActivityCallCount)
+	}
+}
+
 func TestBypassMode_NoLock(t *testing.T) {
 	client := &MockMetronClient{
 		StatusToReturn: &SessionStatus{
@@ -360,3 +434,94 @@ func TestGetState_ReturnsCopy(t *testing.T) {
 		t.Errorf("Expected state to have session ID 'test-session'")
 	}
 }
+
+func TestClockChange_Reported(t *testing.T) {
+	now := time.Now()
+	client := &MockMetronClient{
+		StatusToReturn: &SessionStatus{Active: false, BypassMode: true, ServerTime: now},
+	}
+	clock := &MockClock{CurrentTime: now}
+	enforcer := newTestEnforcer(client, &MockPlatform{}, clock)
+	ctx := context.Background()
+
+	// Normal polling: local and server clocks advance together
+	enforcer.poll(ctx)
+	clock.Advance(15 * time.Second)
+	client.StatusToReturn.ServerTime = now.Add(15 * time.Second)
+	enforcer.poll(ctx)
+	if len(client.ReportedEvents) != 0 {
+		t.Fatalf("Expected no tamper events, got %+v", client.ReportedEvents)
+	}
+
+	// The local clock is set back an hour
+	clock.Advance(-time.Hour + 15*time.Second)
+	client.StatusToReturn.ServerTime = now.Add(30 * time.Second)
+	enforcer.poll(ctx)
+
+	if len(client.ReportedEvents) != 1 {
+		t.Fatalf("Expected one tamper event, got %d", len(client.ReportedEvents))
+	}
+	event := client.ReportedEvents[0]
+	if event.Type != TamperClockChange || event.Details != "local clock moved back by 1h0m0s" {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+
+	// The new offset becomes the baseline
+	clock.Advance(15 * time.Second)
+	client.StatusToReturn.ServerTime = now.Add(45 * time.Second)
+	enforcer.poll(ctx)
+	if len(client.ReportedEvents) != 1 {
+		t.Errorf("Expected no further events, got %d", len(client.ReportedEvents))
+	}
+}
+
+func TestTamperEvents_KeptUntilReported(t *testing.T) {
+	now := time.Now()
+	client := &MockMetronClient{
+		StatusToReturn:    &SessionStatus{Active: false, BypassMode: true, ServerTime: now},
+		EventsErrToReturn: errors.New("connection refused"),
+	}
+	enforcer := newTestEnforcer(client, &MockPlatform{}, &MockClock{CurrentTime: now})
+	ctx := context.Background()
+
+	enforcer.ReportTamper(TamperProcessKill, "agent did not shut down cleanly")
+	enforcer.poll(ctx)
+	if len(client.ReportedEvents) != 0 {
+		t.Fatalf("Expected no events reported while failing, got %d", len(client.ReportedEvents))
+	}
+
+	client.EventsErrToReturn = nil
+	enforcer.poll(ctx)
+	if len(client.ReportedEvents) != 1 || client.ReportedEvents[0].Type != TamperProcessKill {
+		t.Fatalf("Expected the queued event to be reported, got %+v", client.ReportedEvents)
+	}
+
+	// Sent events are not sent again
+	enforcer.poll(ctx)
+	if len(client.ReportedEvents) != 1 {
+		t.Errorf("Expected events to be sent once, got %d", len(client.ReportedEvents))
+	}
+}
+
+func TestRunMarker_DetectsUncleanShutdown(t *testing.T) {
+	path := t.TempDir() + "/agent.running"
+
+	unclean, err := AcquireRunMarker(path)
+	if err != nil || unclean {
+		t.Fatalf("First start: unclean=%v err=%v", unclean, err)
+	}
+
+	// Killed: the marker was never released
+	unclean, err = AcquireRunMarker(path)
+	if err != nil || !unclean {
+		t.Fatalf("After kill: unclean=%v err=%v", unclean, err)
+	}
+
+	if err := ReleaseRunMarker(path); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	unclean, err = AcquireRunMarker(path)
+	if err != nil || unclean {
+		t.Errorf("After clean shutdown: unclean=%v err=%v", unclean, err)
+	}
+}
@@ -1,5 +1,7 @@
 package winagent
 
+import "time"
+
 // Platform abstracts OS-specific operations for workstation control.
 // This allows testing on non-Windows platforms with mock implementations.
 type Platform interface {
@@ -9,3 +11,10 @@ type Platform interface {
 	// ShowWarningNotification displays a toast notification to the user
 	ShowWarningNotification(title, message string) error
 }
+
+// IdleDetector is implemented by platforms that can measure user inactivity.
+// The enforcer reports idle time to Metron so idle sessions can be stopped early.
+type IdleDetector interface {
+	// IdleDuration returns the time since the last keyboard or mouse input
+	IdleDuration() (time.Duration, error)
+}
@@ -6,6 +6,8 @@ import (
 	"errors"
 	"log/slog"
 	"syscall"
+	"time"
+	"unsafe"
 )
 
 // WindowsPlatform implements Platform for Windows
@@ -20,7 +22,10 @@ func NewWindowsPlatform(logger *slog.Logger) *WindowsPlatform {
 	}
 }
 
-var ErrLockFailed = errors.New("LockWorkStation failed")
+var (
+	ErrLockFailed      = errors.New("LockWorkStation failed")
+	ErrLastInputFailed = errors.New("GetLastInputInfo failed")
+)
 
 // LockWorkstation locks the Windows workstation using user32.dll
 func (p *WindowsPlatform) LockWorkstation() error {
@@ -158,5 +163,50 @@ func NewPlatform(logger *slog.Logger) Platform {
 	return NewWindowsPlatform(logger)
 }
 
+// lastInputInfo mirrors the Win32 LASTINPUTINFO struct
+type lastInputInfo struct {
+	cbSize uint32
+	dwTime uint32
+}
+
+// IdleDuration returns the time since the last keyboard or mouse input using user32.dll
+func (p *WindowsPlatform) IdleDuration() (time.Duration, error) {
+	user32 := syscall.NewLazyDLL("user32.dll")
+	kernel32 := syscall.NewLazyDLL("kernel32.dll")
+	getLastInputInfo := user32.NewProc("GetLastInputInfo")
+	getTickCount := kernel32.NewProc("GetTickCount")
+
+	info := lastInputInfo{cbSize: uint32(unsafe.Sizeof(lastInputInfo{}))}
+	ret, _, err := getLastInputInfo.Call(uintptr(unsafe.Pointer(&info)))
+	if ret == 0 {
+		p.logger.Error("failed to get last input info", "error", err)
+		return 0, ErrLastInputFailed
+	}
+
+	// Both values are milliseconds since boot; uint32 arithmetic handles the 49-day wraparound
+	now, _, _ := getTickCount.Call()
+	idleMillis := uint32(now) - info.dwTime
+
+	return time.Duration(idleMillis) * time.Millisecond, nil
+}
+
+// smCleanBoot is the GetSystemMetrics index for the boot mode (0 = normal, 1 = safe mode, 2 = safe mode with networking)
+const smCleanBoot = 67
+
+// SafeModeBoot reports whether Windows booted into safe mode using user32.dll
+func (p *WindowsPlatform) SafeModeBoot() (bool, error) {
+	user32 := syscall.NewLazyDLL("user32.dll")
+	getSystemMetrics := user32.NewProc("GetSystemMetrics")
+
+	ret, _, _ := getSystemMetrics.Call(uintptr(smCleanBoot))
+	return ret != 0, nil
+}
+
 // Ensure WindowsPlatform implements Platform
 var _ Platform = (*WindowsPlatform)(nil)
+
+// Ensure WindowsPlatform implements IdleDetector
+var _ IdleDetector = (*WindowsPlatform)(nil)
+
+// Ensure WindowsPlatform implements SafeModeDetector
+var _ SafeModeDetector = (*WindowsPlatform)(nil)
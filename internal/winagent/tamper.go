@@ -0,0 +1,148 @@
+package winagent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"metron/pkg/client"
+)
+
+// Tamper event types, matching the backend's accepted types
+const (
+	TamperClockChange  = "clock_change"
+	TamperProcessKill  = "process_kill" // Previous run ended without a clean shutdown
+	TamperSafeModeBoot = "safe_mode_boot"
+)
+
+const (
+	// clockChangeThreshold is how far the local clock may drift from server time between polls
+	// before it counts as a clock change; it absorbs network latency and second-precision server times
+	clockChangeThreshold = 2 * time.Minute
+
+	// maxPendingTamperEvents bounds the events kept while the backend is unreachable
+	maxPendingTamperEvents = 50
+)
+
+// TamperEvent is a possible tampering attempt detected by the agent
+type TamperEvent = client.AgentEvent
+
+// SafeModeDetector is implemented by platforms that can tell whether the OS booted into safe mode.
+// Safe mode can skip the agent's service, so a safe-mode boot is reported as tampering.
+type SafeModeDetector interface {
+	// SafeModeBoot returns true if the current boot is a safe-mode boot
+	SafeModeBoot() (bool, error)
+}
+
+// ReportTamper queues a tamper event; queued events are sent after the next successful poll
+func (e *Enforcer) ReportTamper(eventType, details string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.queueTamper(eventType, details, e.clock.Now())
+}
+
+// FlushTamperEvents sends queued tamper events to the backend
+// Events stay queued if sending fails
+func (e *Enforcer) FlushTamperEvents(ctx context.Context) error {
+	e.mu.Lock()
+	events := e.pendingTamper
+	e.pendingTamper = nil
+	e.mu.Unlock()
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	if err := e.client.ReportEvents(ctx, e.config.DeviceID, events); err != nil {
+		e.mu.Lock()
+		// Keep the unsent events ahead of any queued meanwhile
+		e.pendingTamper = append(events, e.pendingTamper...)
+		if excess := len(e.pendingTamper) - maxPendingTamperEvents; excess > 0 {
+			e.pendingTamper = e.pendingTamper[excess:]
+		}
+		e.mu.Unlock()
+		return err
+	}
+
+	e.logger.Info("tamper events reported", "count", len(events))
+	return nil
+}
+
+// queueTamper adds an event, dropping the oldest past maxPendingTamperEvents; the caller holds e.mu
+func (e *Enforcer) queueTamper(eventType, details string, now time.Time) {
+	e.logger.Warn("tamper detected", "type", eventType, "details", details)
+
+	e.pendingTamper = append(e.pendingTamper, TamperEvent{
+		Type:       eventType,
+		Details:    details,
+		OccurredAt: now,
+	})
+	if len(e.pendingTamper) > maxPendingTamperEvents {
+		e.pendingTamper = e.pendingTamper[1:]
+	}
+}
+
+// detectClockChange compares the local clock's offset from server time with the previous poll
+// A jump means the local clock was changed, e.g. set back to stretch a session; the caller holds e.mu
+func (e *Enforcer) detectClockChange(now, serverTime time.Time) {
+	if serverTime.IsZero() {
+		return
+	}
+
+	skew := now.Sub(serverTime)
+	if e.state.ClockSkew != nil {
+		if jump := skew - *e.state.ClockSkew; jump >= clockChangeThreshold || jump <= -clockChangeThreshold {
+			direction := "forward"
+			if jump < 0 {
+				direction = "back"
+			}
+			e.queueTamper(TamperClockChange,
+				fmt.Sprintf("local clock moved %s by %s", direction, jump.Abs().Round(time.Second)), now)
+		}
+	}
+	e.state.ClockSkew = &skew
+}
+
+// checkSafeMode reports a safe-mode boot if the platform can detect one
+func (e *Enforcer) checkSafeMode() {
+	detector, ok := e.platform.(SafeModeDetector)
+	if !ok {
+		return
+	}
+
+	safeMode, err := detector.SafeModeBoot()
+	if err != nil {
+		e.logger.Warn("failed to check boot mode", "error", err)
+		return
+	}
+	if safeMode {
+		e.ReportTamper(TamperSafeModeBoot, "device booted into safe mode")
+	}
+}
+
+// AcquireRunMarker creates a marker file that exists while the agent runs
+// It returns true if the marker was already there: the previous run ended without
+// ReleaseRunMarker, so the agent was killed (or crashed, or the device lost power)
+func AcquireRunMarker(path string) (bool, error) {
+	_, err := os.Stat(path)
+	unclean := err == nil
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return false, err
+	}
+
+	if err := os.WriteFile(path, []byte(time.Now().Format(time.RFC3339)), 0644); err != nil {
+		return unclean, err
+	}
+	return unclean, nil
+}
+
+// ReleaseRunMarker removes the marker file on clean shutdown
+func ReleaseRunMarker(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
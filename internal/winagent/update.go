@@ -0,0 +1,152 @@
+package winagent
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"metron/internal/agentupdate"
+	"metron/pkg/client"
+)
+
+// maxUpdateSize bounds the binary an agent will download
+const maxUpdateSize = 100 << 20
+
+// UpdateInfo is the backend's answer to an update check
+type UpdateInfo = client.UpdateInfo
+
+// UpdateClient checks for and downloads agent releases
+type UpdateClient interface {
+	// CheckUpdate asks the backend whether a release newer than version is published
+	CheckUpdate(ctx context.Context, version string) (*UpdateInfo, error)
+
+	// DownloadUpdate downloads a release binary of at most maxSize bytes
+	DownloadUpdate(ctx context.Context, path string, maxSize int64) ([]byte, error)
+}
+
+// Updater keeps the agent binary up to date with signed releases from the backend.
+// Releases are verified against the public key the agent was installed with before
+// the running executable is replaced.
+type Updater struct {
+	client    UpdateClient
+	publicKey ed25519.PublicKey
+	version   string
+	exePath   string
+	interval  time.Duration
+	logger    *slog.Logger
+}
+
+// NewUpdater creates an updater for the executable at exePath running the given version
+func NewUpdater(client UpdateClient, config *Config, version, exePath string, logger *slog.Logger) (*Updater, error) {
+	publicKey, err := agentupdate.ParsePublicKey(config.UpdatePublicKey)
+	if err != nil {
+		return nil, err
+	}
+	return &Updater{
+		client:    client,
+		publicKey: publicKey,
+		version:   version,
+		exePath:   exePath,
+		interval:  config.UpdateInterval,
+		logger:    logger.With("component", "updater"),
+	}, nil
+}
+
+// Run checks for updates now and then every interval
+// It returns true once a new binary is installed (the caller restarts the agent),
+// or false when ctx is cancelled
+func (u *Updater) Run(ctx context.Context) bool {
+	u.logger.Info("update checks enabled", "version", u.version, "interval", u.interval)
+
+	ticker := time.NewTicker(u.interval)
+	defer ticker.Stop()
+
+	for {
+		installed, err := u.Check(ctx)
+		if err != nil {
+			u.logger.Warn("update check failed", "error", err)
+		}
+		if installed {
+			return true
+		}
+
+		select {
+		case <-ctx.Done():
+			return false
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check installs a newer release if one is published and returns true if it did
+func (u *Updater) Check(ctx context.Context) (bool, error) {
+	info, err := u.client.CheckUpdate(ctx, u.version)
+	if err != nil {
+		return false, err
+	}
+	if !info.UpdateAvailable || !agentupdate.IsNewer(info.Version, u.version) {
+		u.logger.Debug("agent is up to date", "version", u.version)
+		return false, nil
+	}
+
+	u.logger.Info("downloading agent update", "version", info.Version, "size", info.Size)
+	binary, err := u.client.DownloadUpdate(ctx, info.DownloadPath, maxUpdateSize)
+	if err != nil {
+		return false, fmt.Errorf("download failed: %w", err)
+	}
+
+	release := &agentupdate.Release{
+		Version:   info.Version,
+		Size:      info.Size,
+		SHA256:    info.SHA256,
+		Signature: info.Signature,
+	}
+	if err := agentupdate.Verify(u.publicKey, release, binary); err != nil {
+		return false, err
+	}
+
+	if err := u.install(binary); err != nil {
+		return false, fmt.Errorf("install failed: %w", err)
+	}
+
+	u.logger.Info("agent update installed", "from", u.version, "to", info.Version)
+	return true, nil
+}
+
+// install replaces the executable with binary
+// Windows cannot overwrite a running executable but can rename it, so the current
+// binary is moved to .old (removed on next start) and the new one takes its place
+func (u *Updater) install(binary []byte) error {
+	newPath := u.exePath + ".new"
+	oldPath := u.exePath + ".old"
+
+	if err := os.WriteFile(newPath, binary, 0755); err != nil {
+		return err
+	}
+	if err := os.Remove(oldPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		os.Remove(newPath)
+		return err
+	}
+	if err := os.Rename(u.exePath, oldPath); err != nil {
+		os.Remove(newPath)
+		return err
+	}
+	if err := os.Rename(newPath, u.exePath); err != nil {
+		// Put the running version back so the agent still starts next time
+		os.Rename(oldPath, u.exePath)
+		return err
+	}
+	return nil
+}
+
+// RemovePreviousVersion deletes the binary left behind by the last update, if any
+func RemovePreviousVersion(exePath string) error {
+	if err := os.Remove(exePath + ".old"); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
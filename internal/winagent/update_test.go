@@ -0,0 +1,128 @@
+package winagent
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"metron/internal/agentupdate"
+)
+
+// MockUpdateClient is a test double for UpdateClient
+type MockUpdateClient struct {
+	Info          *UpdateInfo
+	Binary        []byte
+	DownloadCalls int
+}
+
+func (m *MockUpdateClient) CheckUpdate(ctx context.Context, version string) (*UpdateInfo, error) {
+	return m.Info, nil
+}
+
+func (m *MockUpdateClient) DownloadUpdate(ctx context.Context, path string, maxSize int64) ([]byte, error) {
+	m.DownloadCalls++
+	return m.Binary, nil
+}
+
+// newSignedUpdate signs binary as version and returns the public key and the backend's answer
+func newSignedUpdate(t *testing.T, version string, binary []byte) (string, *UpdateInfo) {
+	t.Helper()
+	publicKey, privateKey, err := agentupdate.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	private, err := agentupdate.ParsePrivateKey(privateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release, err := agentupdate.Sign(private, version, "metron-win-agent.exe", binary)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return publicKey, &UpdateInfo{
+		UpdateAvailable: true,
+		Version:         release.Version,
+		Size:            release.Size,
+		SHA256:          release.SHA256,
+		Signature:       release.Signature,
+		DownloadPath:    "/api/v1/agent/update/download",
+	}
+}
+
+func newTestUpdater(t *testing.T, client UpdateClient, publicKey, version string) (*Updater, string) {
+	t.Helper()
+	exePath := filepath.Join(t.TempDir(), "metron-win-agent.exe")
+	if err := os.WriteFile(exePath, []byte("current binary"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelError}))
+	config := &Config{UpdatePublicKey: publicKey, UpdateInterval: time.Hour}
+	updater, err := NewUpdater(client, config, version, exePath, logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return updater, exePath
+}
+
+func TestUpdater_InstallsSignedUpdate(t *testing.T) {
+	binary := []byte("new binary")
+	publicKey, info := newSignedUpdate(t, "1.5.0", binary)
+	updater, exePath := newTestUpdater(t, &MockUpdateClient{Info: info, Binary: binary}, publicKey, "1.4.0")
+
+	installed, err := updater.Check(context.Background())
+	if err != nil || !installed {
+		t.Fatalf("Expected update to install, installed=%v err=%v", installed, err)
+	}
+
+	current, _ := os.ReadFile(exePath)
+	if string(current) != "new binary" {
+		t.Errorf("Expected new binary in place, got %q", current)
+	}
+	previous, _ := os.ReadFile(exePath + ".old")
+	if string(previous) != "current binary" {
+		t.Errorf("Expected previous binary kept as .old, got %q", previous)
+	}
+
+	if err := RemovePreviousVersion(exePath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(exePath + ".old"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected .old to be removed, got %v", err)
+	}
+}
+
+func TestUpdater_RejectsTamperedUpdate(t *testing.T) {
+	publicKey, info := newSignedUpdate(t, "1.5.0", []byte("new binary"))
+	client := &MockUpdateClient{Info: info, Binary: []byte("malicious binary")}
+	updater, exePath := newTestUpdater(t, client, publicKey, "1.4.0")
+
+	installed, err := updater.Check(context.Background())
+	if installed || !errors.Is(err, agentupdate.ErrChecksumMismatch) {
+		t.Fatalf("Expected checksum error, installed=%v err=%v", installed, err)
+	}
+
+	current, _ := os.ReadFile(exePath)
+	if string(current) != "current binary" {
+		t.Errorf("Expected binary to be untouched, got %q", current)
+	}
+}
+
+func TestUpdater_SkipsSameOrOlderVersion(t *testing.T) {
+	binary := []byte("same binary")
+	publicKey, info := newSignedUpdate(t, "1.4.0", binary)
+	client := &MockUpdateClient{Info: info, Binary: binary}
+	updater, _ := newTestUpdater(t, client, publicKey, "1.4.0")
+
+	installed, err := updater.Check(context.Background())
+	if err != nil || installed {
+		t.Fatalf("Expected no update, installed=%v err=%v", installed, err)
+	}
+	if client.DownloadCalls != 0 {
+		t.Errorf("Expected no download, got %d", client.DownloadCalls)
+	}
+}
@@ -0,0 +1,141 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// DownloadTimeout is how long an agent release download may take
+const DownloadTimeout = 5 * time.Minute
+
+// AgentClient is a client for the agent API, authenticated with a device's agent token
+// (parameters.agent_token of the device, or a token issued through /api/v1/admin/agents)
+type AgentClient struct {
+	transport
+}
+
+// NewAgent creates an agent client for the Metron server at baseURL
+func NewAgent(baseURL, token string) *AgentClient {
+	c := &AgentClient{transport: newTransport(baseURL)}
+	c.header.Set("Authorization", "Bearer "+token)
+	return c
+}
+
+// SessionStatus is what the device should enforce right now
+type SessionStatus struct {
+	Active     bool       `json:"active"`
+	SessionID  *string    `json:"session_id,omitempty"`
+	EndsAt     *time.Time `json:"ends_at,omitempty"`
+	WarnAt     *time.Time `json:"warn_at,omitempty"`
+	ServerTime time.Time  `json:"server_time"`
+	BypassMode bool       `json:"bypass_mode"`
+	Lockdown   bool       `json:"lockdown,omitempty"` // Server-wide lockdown; the device must stay locked
+
+	// TrackingPaused is set during a global tracking pause (vacation mode)
+	// The server also sets BypassMode, so enforcement is skipped like in bypass mode
+	TrackingPaused bool `json:"tracking_paused,omitempty"`
+}
+
+// AgentEvent is a possible tampering attempt detected by an agent
+type AgentEvent struct {
+	Type       string    `json:"type"` // clock_change, process_kill or safe_mode_boot
+	Details    string    `json:"details,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+// UpdateInfo is the server's answer to an agent update check
+type UpdateInfo struct {
+	UpdateAvailable bool   `json:"update_available"`
+	Version         string `json:"version,omitempty"`
+	Size            int64  `json:"size,omitempty"`
+	SHA256          string `json:"sha256,omitempty"`
+	Signature       string `json:"signature,omitempty"`
+	DownloadPath    string `json:"download_path,omitempty"`
+}
+
+// GetSessionStatus retrieves the current session status of a device
+func (c *AgentClient) GetSessionStatus(ctx context.Context, deviceID string) (*SessionStatus, error) {
+	var status SessionStatus
+	if err := c.do(ctx, http.MethodGet, "/api/v1/agent/session?device_id="+url.QueryEscape(deviceID), nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// ReportActivity reports how long the device has been idle, so the server can stop idle sessions
+func (c *AgentClient) ReportActivity(ctx context.Context, deviceID string, idle time.Duration) error {
+	req := struct {
+		DeviceID    string `json:"device_id"`
+		IdleSeconds int    `json:"idle_seconds"`
+	}{
+		DeviceID:    deviceID,
+		IdleSeconds: int(idle.Seconds()),
+	}
+	return c.do(ctx, http.MethodPost, "/api/v1/agent/activity", req, nil)
+}
+
+// ReportEvents sends tamper events detected on the device, so the server can alert parents
+func (c *AgentClient) ReportEvents(ctx context.Context, deviceID string, events []AgentEvent) error {
+	req := struct {
+		DeviceID string       `json:"device_id"`
+		Events   []AgentEvent `json:"events"`
+	}{
+		DeviceID: deviceID,
+		Events:   events,
+	}
+	return c.do(ctx, http.MethodPost, "/api/v1/agent/events", req, nil)
+}
+
+// CheckUpdate asks the server whether an agent release newer than version is published
+func (c *AgentClient) CheckUpdate(ctx context.Context, version string) (*UpdateInfo, error) {
+	var info UpdateInfo
+	if err := c.do(ctx, http.MethodGet, "/api/v1/agent/update?version="+url.QueryEscape(version), nil, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// DownloadUpdate downloads a release binary of at most maxSize bytes from the path in UpdateInfo
+// Downloads are not retried, and may take up to DownloadTimeout.
+func (c *AgentClient) DownloadUpdate(ctx context.Context, path string, maxSize int64) ([]byte, error) {
+	client := *c.http
+	client.Timeout = DownloadTimeout
+
+	resp, err := c.open(ctx, &client, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, responseError(resp.StatusCode, body)
+	}
+
+	binary, err := io.ReadAll(io.LimitReader(resp.Body, maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if int64(len(binary)) > maxSize {
+		return nil, fmt.Errorf("update larger than %d bytes", maxSize)
+	}
+	return binary, nil
+}
+
+// do sends a request with body encoded as JSON and decodes the response into result
+func (c *AgentClient) do(ctx context.Context, method, path string, body, result any) error {
+	data, err := marshalRequest(body)
+	if err != nil {
+		return err
+	}
+
+	statusCode, respBody, err := c.send(ctx, method, path, data, nil)
+	if err != nil {
+		return err
+	}
+	return decodeResponse(respBody, statusCode, result)
+}
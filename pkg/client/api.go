@@ -0,0 +1,772 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// TodayStats represents today's statistics response
+type TodayStats struct {
+	Date           string       `json:"date"`
+	Children       []ChildStats `json:"children"`
+	ActiveSessions int          `json:"active_sessions"`
+	TotalChildren  int          `json:"total_children"`
+}
+
+// ChildStats represents a child's daily statistics
+type ChildStats struct {
+	ChildID        string `json:"child_id"`
+	ChildName      string `json:"child_name"`
+	ChildEmoji     string `json:"child_emoji"`
+	TodayUsed      int    `json:"today_used"`
+	TodayRemaining int    `json:"today_remaining"`
+	TodayLimit     int    `json:"today_limit"`
+	SessionsToday  int    `json:"sessions_today"`
+	UsagePercent   int    `json:"usage_percent"`
+
+	TrackingPaused    bool    `json:"tracking_paused"`               // Vacation mode: usage is not recorded
+	TrackingResumesAt *string `json:"tracking_resumes_at,omitempty"` // When tracking re-enables automatically
+}
+
+// ChildrenStatus represents the status of all children in one response
+type ChildrenStatus struct {
+	Date         string        `json:"date"`
+	Children     []ChildStatus `json:"children"`
+	FamilyBudget *FamilyBudget `json:"family_budget,omitempty"` // Only when a family budget is configured
+}
+
+// FamilyBudget represents today's use of the budget shared by all children
+type FamilyBudget struct {
+	LimitMinutes     int `json:"limit_minutes"`
+	UsedMinutes      int `json:"used_minutes"`
+	CommittedMinutes int `json:"committed_minutes"`
+	RemainingMinutes int `json:"remaining_minutes"`
+	Sessions         int `json:"sessions"`
+}
+
+// ChildStatus represents a child's status for the day and the child's active sessions
+type ChildStatus struct {
+	ID             string    `json:"id"`
+	Name           string    `json:"name"`
+	Emoji          string    `json:"emoji"`
+	TodayUsed      int       `json:"today_used"`
+	TodayRemaining int       `json:"today_remaining"`
+	TodayLimit     int       `json:"today_limit"`
+	SessionsToday  int       `json:"sessions_today"`
+	UsagePercent   int       `json:"usage_percent"`
+	ActiveSessions []Session `json:"active_sessions"` // Shared sessions are listed under each child
+
+	TrackingPaused    bool    `json:"tracking_paused"`               // Vacation mode: usage is not recorded
+	TrackingResumesAt *string `json:"tracking_resumes_at,omitempty"` // When tracking re-enables automatically
+}
+
+// TrendsReport represents the usage trends response
+type TrendsReport struct {
+	Children []ChildTrends `json:"children"`
+}
+
+// ChildTrends represents a child's usage trends over the last 7 and 30 days
+type ChildTrends struct {
+	ChildID           string      `json:"child_id"`
+	ChildName         string      `json:"child_name"`
+	ChildEmoji        string      `json:"child_emoji"`
+	From              string      `json:"from"`
+	To                string      `json:"to"`
+	Week              UsageWindow `json:"week"`
+	PreviousWeek      UsageWindow `json:"previous_week"`
+	Month             UsageWindow `json:"month"`
+	Weekdays          UsageWindow `json:"weekdays"`
+	Weekends          UsageWindow `json:"weekends"`
+	WeekChangePercent *float64    `json:"week_change_percent"` // Nil without a previous week to compare
+	Direction         string      `json:"direction"`           // up, down or flat
+}
+
+// UsageWindow represents usage averaged over a number of days
+type UsageWindow struct {
+	Days           int     `json:"days"`
+	TotalMinutes   int     `json:"total_minutes"`
+	AverageMinutes float64 `json:"average_minutes"`
+	LimitPercent   float64 `json:"limit_percent"`
+}
+
+// Child represents a child
+type Child struct {
+	ID              string     `json:"id"`
+	Name            string     `json:"name"`
+	Emoji           string     `json:"emoji"`
+	WeekdayLimit    int        `json:"weekday_limit"`
+	WeekendLimit    int        `json:"weekend_limit"`
+	BreakRule       *BreakRule `json:"break_rule,omitempty"`
+	DowntimeEnabled bool       `json:"downtime_enabled"`
+	AllowedDevices  []string   `json:"allowed_devices,omitempty"` // Empty means all devices
+	CreatedAt       string     `json:"created_at"`
+	UpdatedAt       string     `json:"updated_at"`
+}
+
+// CanUseDevice returns true if the child is allowed to use the device
+func (c Child) CanUseDevice(deviceID string) bool {
+	if len(c.AllowedDevices) == 0 {
+		return true
+	}
+	for _, allowed := range c.AllowedDevices {
+		if allowed == deviceID {
+			return true
+		}
+	}
+	return false
+}
+
+// BreakRule represents break rule settings
+type BreakRule struct {
+	BreakAfterMinutes    int `json:"break_after_minutes"`
+	BreakDurationMinutes int `json:"break_duration_minutes"`
+}
+
+// Device represents a device
+type Device struct {
+	ID             string             `json:"id"`
+	Name           string             `json:"name"`
+	Type           string             `json:"type"`
+	Emoji          string             `json:"emoji,omitempty"`
+	Capabilities   DeviceCapabilities `json:"capabilities,omitempty"`
+	LastSeenAt     string             `json:"last_seen_at,omitempty"`     // RFC3339; empty if the device never checked in
+	LastSeenSource string             `json:"last_seen_source,omitempty"` // What reported the device (e.g., "agent")
+}
+
+// DeviceCapabilities represents device capabilities
+type DeviceCapabilities struct {
+	SupportsWarnings   bool `json:"supports_warnings"`
+	SupportsLiveState  bool `json:"supports_live_state"`
+	SupportsScheduling bool `json:"supports_scheduling"`
+	SupportsExtension  bool `json:"supports_extension"`
+	SupportsBreaks     bool `json:"supports_breaks"`
+}
+
+// Session represents a screen-time session
+type Session struct {
+	ID               string   `json:"id"`
+	DeviceType       string   `json:"device_type"`
+	DeviceID         string   `json:"device_id"`
+	ChildIDs         []string `json:"child_ids"`
+	StartTime        string   `json:"start_time"`
+	ExpectedDuration int      `json:"expected_duration"`
+	RemainingMinutes int      `json:"remaining_minutes"`
+	Status           string   `json:"status"`
+	CreatedAt        string   `json:"created_at"`
+	UpdatedAt        string   `json:"updated_at"`
+	WarningSentAt    string   `json:"warning_sent_at,omitempty"` // Set once the expiry warning went out
+
+	// Who started the session; nil for sessions started before it was recorded
+	Initiator *SessionInitiator `json:"initiator,omitempty"`
+
+	// Present only in start/extend responses
+	RequestedMinutes int    `json:"requested_minutes,omitempty"`
+	GrantedMinutes   int    `json:"granted_minutes,omitempty"`
+	Capped           bool   `json:"capped,omitempty"`
+	CapReason        string `json:"cap_reason,omitempty"`
+	CapPolicy        string `json:"cap_policy,omitempty"` // Session policy that capped it (cap reason "policy")
+
+	// Present only in start responses when the device was busy (session_conflicts):
+	// the children joined its running session, or the start waits in the queue
+	Merged   bool   `json:"merged,omitempty"`
+	Queued   bool   `json:"queued,omitempty"`
+	QueueID  string `json:"queue_id,omitempty"`
+	Position int    `json:"position,omitempty"`
+	StartsAt string `json:"starts_at,omitempty"`
+	Minutes  int    `json:"minutes,omitempty"` // Requested minutes of a queued start
+}
+
+// SessionInitiator is who started a session: type "parent", "child", "api" or "automation", and an identifier
+type SessionInitiator struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// CreateSessionRequest represents a request to create a session
+type CreateSessionRequest struct {
+	DeviceID      string   `json:"device_id"`
+	ChildIDs      []string `json:"child_ids"`
+	Minutes       int      `json:"minutes"`
+	Override      []string `json:"override,omitempty"`       // Rules the session may ignore ("downtime", "limits")
+	OverrideBy    string   `json:"override_by,omitempty"`    // Parent requesting the override, for the audit log
+	InitiatorType string   `json:"initiator_type,omitempty"` // "parent" or "automation"; the API key's default ("api") if empty
+	InitiatorID   string   `json:"initiator_id,omitempty"`   // Who started it, e.g. the parent's name or the script
+}
+
+// ExtendSessionRequest represents a request to extend a session
+type ExtendSessionRequest struct {
+	Action            string `json:"action"`
+	AdditionalMinutes int    `json:"additional_minutes,omitempty"`
+}
+
+// DurationSuggestion represents a single suggested session duration
+type DurationSuggestion struct {
+	Minutes int    `json:"minutes"`
+	Kind    string `json:"kind"`
+}
+
+// DurationSuggestions represents suggested session durations for a child
+type DurationSuggestions struct {
+	ChildID              string               `json:"child_id"`
+	RemainingMinutes     int                  `json:"remaining_minutes"`
+	MinutesUntilDowntime *int                 `json:"minutes_until_downtime"`
+	MaxMinutes           int                  `json:"max_minutes"`
+	Options              []DurationSuggestion `json:"options"`
+}
+
+// GetTodayStats retrieves today's statistics
+func (c *Client) GetTodayStats(ctx context.Context) (*TodayStats, error) {
+	var stats TodayStats
+	if err := c.do(ctx, http.MethodGet, "/api/v1/stats/today", nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// GetChildrenStatus retrieves every child's status and active sessions in one call
+func (c *Client) GetChildrenStatus(ctx context.Context) (*ChildrenStatus, error) {
+	var status ChildrenStatus
+	if err := c.do(ctx, http.MethodGet, "/api/v1/children/status", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// GetTrends retrieves usage trends for all children
+func (c *Client) GetTrends(ctx context.Context) (*TrendsReport, error) {
+	var report TrendsReport
+	if err := c.do(ctx, http.MethodGet, "/api/v1/reports/trends", nil, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// ListChildren retrieves all children
+func (c *Client) ListChildren(ctx context.Context) ([]Child, error) {
+	var children []Child
+	if err := c.do(ctx, http.MethodGet, "/api/v1/children", nil, &children); err != nil {
+		return nil, err
+	}
+	return children, nil
+}
+
+// CreateChildRequest represents a request to create a child
+type CreateChildRequest struct {
+	Name           string     `json:"name"`
+	Emoji          string     `json:"emoji,omitempty"` // Assigned randomly if empty
+	PIN            string     `json:"pin,omitempty"`   // Optional 4-digit PIN for the child web app
+	WeekdayLimit   int        `json:"weekday_limit"`
+	WeekendLimit   int        `json:"weekend_limit"`
+	BreakRule      *BreakRule `json:"break_rule,omitempty"`
+	AllowedDevices []string   `json:"allowed_devices,omitempty"` // Empty means all devices
+	Timezone       string     `json:"timezone,omitempty"`        // IANA timezone; the server's if empty
+	GraceMinutes   int        `json:"grace_minutes,omitempty"`   // Minutes a session may run past the daily limit
+	Birthdate      string     `json:"birthdate,omitempty"`       // YYYY-MM-DD, for age-based limit profiles
+}
+
+// CreateChild creates a child; the server assigns the ID
+func (c *Client) CreateChild(ctx context.Context, req CreateChildRequest) (*Child, error) {
+	var child Child
+	if err := c.do(ctx, http.MethodPost, "/api/v1/children", req, &child); err != nil {
+		return nil, err
+	}
+	return &child, nil
+}
+
+// DeleteChild deletes a child with the child's limit changes and daily history
+func (c *Client) DeleteChild(ctx context.Context, childID string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/children/"+url.PathEscape(childID), nil, nil)
+}
+
+// GetDurationSuggestions retrieves suggested session durations for a child
+func (c *Client) GetDurationSuggestions(ctx context.Context, childID string) (*DurationSuggestions, error) {
+	var suggestions DurationSuggestions
+	if err := c.do(ctx, http.MethodGet, "/api/v1/children/"+url.PathEscape(childID)+"/suggestions", nil, &suggestions); err != nil {
+		return nil, err
+	}
+	return &suggestions, nil
+}
+
+// ListDevices retrieves all available device types
+func (c *Client) ListDevices(ctx context.Context) ([]Device, error) {
+	var devices []Device
+	if err := c.do(ctx, http.MethodGet, "/api/v1/devices", nil, &devices); err != nil {
+		return nil, err
+	}
+	return devices, nil
+}
+
+// ListSessions retrieves sessions with optional filters
+func (c *Client) ListSessions(ctx context.Context, active bool, childID string) ([]Session, error) {
+	path := "/api/v1/sessions"
+	if active {
+		path += "?active=true"
+	} else if childID != "" {
+		path += "?childId=" + url.QueryEscape(childID)
+	}
+
+	var sessions []Session
+	if err := c.do(ctx, http.MethodGet, path, nil, &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// CreateSession creates a new session
+func (c *Client) CreateSession(ctx context.Context, req CreateSessionRequest) (*Session, error) {
+	var session Session
+	if err := c.do(ctx, http.MethodPost, "/api/v1/sessions", req, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// ExtendSession extends an existing session
+func (c *Client) ExtendSession(ctx context.Context, sessionID string, additionalMinutes int) (*Session, error) {
+	req := ExtendSessionRequest{
+		Action:            "extend",
+		AdditionalMinutes: additionalMinutes,
+	}
+
+	var session Session
+	if err := c.do(ctx, http.MethodPatch, "/api/v1/sessions/"+url.PathEscape(sessionID), req, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// StopSession stops an active session
+func (c *Client) StopSession(ctx context.Context, sessionID string) error {
+	req := ExtendSessionRequest{
+		Action: "stop",
+	}
+	return c.do(ctx, http.MethodPatch, "/api/v1/sessions/"+url.PathEscape(sessionID), req, nil)
+}
+
+// AddChildrenToSession adds one or more children to an active session
+func (c *Client) AddChildrenToSession(ctx context.Context, sessionID string, childIDs []string) (*Session, error) {
+	req := struct {
+		Action   string   `json:"action"`
+		ChildIDs []string `json:"child_ids"`
+	}{
+		Action:   "add_children",
+		ChildIDs: childIDs,
+	}
+
+	var session Session
+	if err := c.do(ctx, http.MethodPatch, "/api/v1/sessions/"+url.PathEscape(sessionID), req, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// RemoveChildrenFromSession removes one or more children from an active session
+func (c *Client) RemoveChildrenFromSession(ctx context.Context, sessionID string, childIDs []string) (*Session, error) {
+	req := struct {
+		Action   string   `json:"action"`
+		ChildIDs []string `json:"child_ids"`
+	}{
+		Action:   "remove_children",
+		ChildIDs: childIDs,
+	}
+
+	var session Session
+	if err := c.do(ctx, http.MethodPatch, "/api/v1/sessions/"+url.PathEscape(sessionID), req, &session); err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// GrantRewardResponse represents the response from granting a reward
+type GrantRewardResponse struct {
+	Message            string `json:"message"`
+	MinutesGranted     int    `json:"minutes_granted"`
+	TodayRewardGranted int    `json:"today_reward_granted"`
+	TodayRemaining     int    `json:"today_remaining"`
+	TodayLimit         int    `json:"today_limit"`
+}
+
+// GrantReward grants reward minutes to a child
+func (c *Client) GrantReward(ctx context.Context, childID string, minutes int) (*GrantRewardResponse, error) {
+	req := struct {
+		Minutes int `json:"minutes"`
+	}{
+		Minutes: minutes,
+	}
+
+	var response GrantRewardResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/children/"+url.PathEscape(childID)+"/rewards", req, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// DeductFineResponse represents the response from applying a fine
+type DeductFineResponse struct {
+	Message            string `json:"message"`
+	MinutesDeducted    int    `json:"minutes_deducted"`
+	TodayFinesDeducted int    `json:"today_fines_deducted"`
+	TodayRemaining     int    `json:"today_remaining"`
+	TodayLimit         int    `json:"today_limit"`
+}
+
+// DeductFine applies a fine to a child (deducts minutes)
+func (c *Client) DeductFine(ctx context.Context, childID string, minutes int) (*DeductFineResponse, error) {
+	req := struct {
+		Minutes int `json:"minutes"`
+	}{
+		Minutes: minutes,
+	}
+
+	var response DeductFineResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/children/"+url.PathEscape(childID)+"/fines", req, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// LimitChange represents a change of a child's daily limits
+type LimitChange struct {
+	ID            string `json:"id"`
+	ChildID       string `json:"child_id"`
+	WeekdayLimit  int    `json:"weekday_limit"`
+	WeekendLimit  int    `json:"weekend_limit"`
+	EffectiveFrom string `json:"effective_from"` // YYYY-MM-DD
+	CreatedBy     string `json:"created_by"`
+	Pending       bool   `json:"pending"`
+}
+
+// ChangeLimits sets a child's daily limits from today on
+// The server records the change in the limit history and audit log with createdBy as the actor
+func (c *Client) ChangeLimits(ctx context.Context, childID string, weekdayLimit, weekendLimit int, createdBy string) (*LimitChange, error) {
+	req := struct {
+		WeekdayLimit int    `json:"weekday_limit"`
+		WeekendLimit int    `json:"weekend_limit"`
+		CreatedBy    string `json:"created_by"`
+	}{
+		WeekdayLimit: weekdayLimit,
+		WeekendLimit: weekendLimit,
+		CreatedBy:    createdBy,
+	}
+
+	var change LimitChange
+	if err := c.do(ctx, http.MethodPost, "/api/v1/children/"+url.PathEscape(childID)+"/limit-changes", req, &change); err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+// UpdateChildDowntime updates the downtime enabled status for a child
+func (c *Client) UpdateChildDowntime(ctx context.Context, childID string, enabled bool) error {
+	req := struct {
+		DowntimeEnabled bool `json:"downtime_enabled"`
+	}{
+		DowntimeEnabled: enabled,
+	}
+
+	return c.do(ctx, http.MethodPatch, "/api/v1/children/"+url.PathEscape(childID), req, nil)
+}
+
+// SkipDowntimeToday skips downtime for all children today
+func (c *Client) SkipDowntimeToday(ctx context.Context) error {
+	return c.do(ctx, http.MethodPost, "/api/v1/downtime/skip-today", nil, nil)
+}
+
+// DowntimeSkipStatus represents the skip status response
+type DowntimeSkipStatus struct {
+	SkippedToday bool    `json:"skipped_today"`
+	SkipDate     *string `json:"skip_date"`
+}
+
+// IsDowntimeSkippedToday checks if downtime is skipped for today
+func (c *Client) IsDowntimeSkippedToday(ctx context.Context) (bool, error) {
+	var status DowntimeSkipStatus
+	if err := c.do(ctx, http.MethodGet, "/api/v1/downtime/skip-status", nil, &status); err != nil {
+		return false, err
+	}
+	return status.SkippedToday, nil
+}
+
+// DeviceBypass represents a device bypass status
+type DeviceBypass struct {
+	DeviceID  string  `json:"device_id"`
+	Enabled   bool    `json:"enabled"`
+	Reason    string  `json:"reason,omitempty"`
+	EnabledAt string  `json:"enabled_at,omitempty"`
+	EnabledBy string  `json:"enabled_by,omitempty"`
+	ExpiresAt *string `json:"expires_at,omitempty"`
+}
+
+// SetDeviceBypassRequest represents a request to enable bypass mode
+type SetDeviceBypassRequest struct {
+	Enabled          bool   `json:"enabled"`
+	Reason           string `json:"reason,omitempty"`
+	ExpiresInMinutes *int   `json:"expires_in_minutes,omitempty"`
+}
+
+// GetDeviceBypass gets the bypass status for a device
+func (c *Client) GetDeviceBypass(ctx context.Context, deviceID string) (*DeviceBypass, error) {
+	var bypass DeviceBypass
+	if err := c.do(ctx, http.MethodGet, "/api/v1/devices/"+url.PathEscape(deviceID)+"/bypass", nil, &bypass); err != nil {
+		return nil, err
+	}
+	return &bypass, nil
+}
+
+// SetDeviceBypass enables bypass mode for a device
+func (c *Client) SetDeviceBypass(ctx context.Context, deviceID string, req SetDeviceBypassRequest) (*DeviceBypass, error) {
+	var bypass DeviceBypass
+	if err := c.do(ctx, http.MethodPost, "/api/v1/devices/"+url.PathEscape(deviceID)+"/bypass", req, &bypass); err != nil {
+		return nil, err
+	}
+	return &bypass, nil
+}
+
+// ClearDeviceBypass disables bypass mode for a device
+func (c *Client) ClearDeviceBypass(ctx context.Context, deviceID string) error {
+	return c.do(ctx, http.MethodDelete, "/api/v1/devices/"+url.PathEscape(deviceID)+"/bypass", nil, nil)
+}
+
+// Lockdown represents an emergency lockdown
+type Lockdown struct {
+	ID              string  `json:"id"`
+	Reason          string  `json:"reason"`
+	TriggeredBy     string  `json:"triggered_by"`
+	StartedAt       string  `json:"started_at"`
+	StoppedSessions int     `json:"stopped_sessions"`
+	Active          bool    `json:"active"`
+	LiftedAt        *string `json:"lifted_at,omitempty"`
+	LiftedBy        string  `json:"lifted_by,omitempty"`
+}
+
+// LockdownStatus represents the current lockdown status
+type LockdownStatus struct {
+	Active   bool      `json:"active"`
+	Lockdown *Lockdown `json:"lockdown,omitempty"`
+}
+
+// ActivateLockdownRequest represents a request to start a lockdown
+type ActivateLockdownRequest struct {
+	TriggeredBy string `json:"triggered_by"`
+	Reason      string `json:"reason,omitempty"`
+}
+
+// LiftLockdownRequest represents a request to lift the active lockdown
+type LiftLockdownRequest struct {
+	LiftedBy string `json:"lifted_by"`
+}
+
+// GetLockdown gets the current lockdown status
+func (c *Client) GetLockdown(ctx context.Context) (*LockdownStatus, error) {
+	var status LockdownStatus
+	if err := c.do(ctx, http.MethodGet, "/api/v1/lockdown", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// ActivateLockdown stops all sessions and blocks new ones until lifted
+func (c *Client) ActivateLockdown(ctx context.Context, triggeredBy, reason string) (*Lockdown, error) {
+	req := ActivateLockdownRequest{
+		TriggeredBy: triggeredBy,
+		Reason:      reason,
+	}
+	var lockdown Lockdown
+	if err := c.do(ctx, http.MethodPost, "/api/v1/lockdown", req, &lockdown); err != nil {
+		return nil, err
+	}
+	return &lockdown, nil
+}
+
+// LiftLockdown ends the active lockdown
+func (c *Client) LiftLockdown(ctx context.Context, liftedBy string) (*Lockdown, error) {
+	req := LiftLockdownRequest{
+		LiftedBy: liftedBy,
+	}
+	var lockdown Lockdown
+	if err := c.do(ctx, http.MethodDelete, "/api/v1/lockdown", req, &lockdown); err != nil {
+		return nil, err
+	}
+	return &lockdown, nil
+}
+
+// TrackingPause represents a vacation mode pause, for one child or everyone
+type TrackingPause struct {
+	ID        string  `json:"id"`
+	Global    bool    `json:"global"`
+	ChildID   string  `json:"child_id,omitempty"`
+	Reason    string  `json:"reason"`
+	PausedBy  string  `json:"paused_by"`
+	StartedAt string  `json:"started_at"`
+	Active    bool    `json:"active"`
+	ResumesAt *string `json:"resumes_at,omitempty"`
+	ResumedAt *string `json:"resumed_at,omitempty"`
+	ResumedBy string  `json:"resumed_by,omitempty"`
+}
+
+// PauseTrackingRequest represents a request to pause tracking
+type PauseTrackingRequest struct {
+	ChildID   string     `json:"child_id,omitempty"`
+	PausedBy  string     `json:"paused_by"`
+	Reason    string     `json:"reason,omitempty"`
+	ResumesAt *time.Time `json:"resumes_at,omitempty"`
+}
+
+// ResumeTrackingRequest represents a request to resume tracking
+type ResumeTrackingRequest struct {
+	ChildID   string `json:"child_id,omitempty"`
+	ResumedBy string `json:"resumed_by"`
+}
+
+// PauseTracking pauses tracking for a child, or for everyone if childID is empty
+func (c *Client) PauseTracking(ctx context.Context, childID, pausedBy, reason string, resumesAt *time.Time) (*TrackingPause, error) {
+	req := PauseTrackingRequest{
+		ChildID:   childID,
+		PausedBy:  pausedBy,
+		Reason:    reason,
+		ResumesAt: resumesAt,
+	}
+	var pause TrackingPause
+	if err := c.do(ctx, http.MethodPost, "/api/v1/tracking-pause", req, &pause); err != nil {
+		return nil, err
+	}
+	return &pause, nil
+}
+
+// ResumeTracking resumes tracking for a child, or for everyone if childID is empty
+func (c *Client) ResumeTracking(ctx context.Context, childID, resumedBy string) (*TrackingPause, error) {
+	req := ResumeTrackingRequest{
+		ChildID:   childID,
+		ResumedBy: resumedBy,
+	}
+	var pause TrackingPause
+	if err := c.do(ctx, http.MethodDelete, "/api/v1/tracking-pause", req, &pause); err != nil {
+		return nil, err
+	}
+	return &pause, nil
+}
+
+// TamperEvent represents a possible tampering attempt reported by a device agent
+type TamperEvent struct {
+	ID         string `json:"id"`
+	DeviceID   string `json:"device_id"`
+	Type       string `json:"type"` // clock_change, process_kill or safe_mode_boot
+	Details    string `json:"details,omitempty"`
+	OccurredAt string `json:"occurred_at"`
+	ReceivedAt string `json:"received_at"`
+}
+
+// ListTamperEvents retrieves the tamper events received after since, oldest first
+func (c *Client) ListTamperEvents(ctx context.Context, since time.Time) ([]TamperEvent, error) {
+	var response struct {
+		Events []TamperEvent `json:"events"`
+	}
+	path := "/api/v1/tamper-events?since=" + url.QueryEscape(since.Format(time.RFC3339Nano))
+	if err := c.do(ctx, http.MethodGet, path, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Events, nil
+}
+
+// UsageAlert is recorded when a child reaches a share of the day's time
+type UsageAlert struct {
+	ID               string `json:"id"`
+	ChildID          string `json:"child_id"`
+	Date             string `json:"date"`
+	Threshold        int    `json:"threshold"` // Percentage of the day's time
+	UsedMinutes      int    `json:"used_minutes"`
+	LimitMinutes     int    `json:"limit_minutes"`
+	RemainingMinutes int    `json:"remaining_minutes"`
+	CreatedAt        string `json:"created_at"`
+}
+
+// ListUsageAlerts retrieves the usage alerts created after since, oldest first
+func (c *Client) ListUsageAlerts(ctx context.Context, since time.Time) ([]UsageAlert, error) {
+	var response struct {
+		Alerts []UsageAlert `json:"alerts"`
+	}
+	path := "/api/v1/usage-alerts?since=" + url.QueryEscape(since.Format(time.RFC3339Nano))
+	if err := c.do(ctx, http.MethodGet, path, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Alerts, nil
+}
+
+// ProfileTransition is an age-based limit profile proposed on a child's birthday
+type ProfileTransition struct {
+	ID           string `json:"id"`
+	ChildID      string `json:"child_id"`
+	Profile      string `json:"profile"`
+	Age          int    `json:"age"`
+	WeekdayLimit int    `json:"weekday_limit"`
+	WeekendLimit int    `json:"weekend_limit"`
+	Status       string `json:"status"` // pending, confirmed or dismissed
+	CreatedAt    string `json:"created_at"`
+	ResolvedBy   string `json:"resolved_by,omitempty"`
+}
+
+// resolveProfileTransitionRequest represents a request to confirm or dismiss a profile transition
+type resolveProfileTransitionRequest struct {
+	ResolvedBy string `json:"resolved_by"`
+}
+
+// ListProfileTransitions retrieves profile transitions with a status (all if empty), oldest first
+func (c *Client) ListProfileTransitions(ctx context.Context, status string) ([]ProfileTransition, error) {
+	var response struct {
+		Transitions []ProfileTransition `json:"transitions"`
+	}
+	path := "/api/v1/profile-transitions"
+	if status != "" {
+		path += "?status=" + url.QueryEscape(status)
+	}
+	if err := c.do(ctx, http.MethodGet, path, nil, &response); err != nil {
+		return nil, err
+	}
+	return response.Transitions, nil
+}
+
+// ConfirmProfileTransition applies the limits of a pending profile transition
+func (c *Client) ConfirmProfileTransition(ctx context.Context, id, confirmedBy string) (*ProfileTransition, error) {
+	var transition ProfileTransition
+	req := resolveProfileTransitionRequest{ResolvedBy: confirmedBy}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/profile-transitions/"+url.PathEscape(id)+"/confirm", req, &transition); err != nil {
+		return nil, err
+	}
+	return &transition, nil
+}
+
+// DismissProfileTransition keeps the child's limits instead of a pending profile transition
+func (c *Client) DismissProfileTransition(ctx context.Context, id, dismissedBy string) (*ProfileTransition, error) {
+	var transition ProfileTransition
+	req := resolveProfileTransitionRequest{ResolvedBy: dismissedBy}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/profile-transitions/"+url.PathEscape(id)+"/dismiss", req, &transition); err != nil {
+		return nil, err
+	}
+	return &transition, nil
+}
+
+// ImportResult counts what a bundle import created
+type ImportResult struct {
+	Children     int `json:"children"`
+	LimitChanges int `json:"limit_changes"`
+	Sessions     int `json:"sessions"`
+	UsageDays    int `json:"usage_days"`
+	Allocations  int `json:"allocations"`
+}
+
+// ImportBundle imports the children, limit changes and history of a bundle, as written by
+// "metron export" or GET /api/v1/admin/export
+// Nothing is imported if any of its children exists (CHILD_EXISTS). The bundle's config section
+// is not applied; devices live in the server's configuration file.
+func (c *Client) ImportBundle(ctx context.Context, bundle json.RawMessage) (*ImportResult, error) {
+	var response struct {
+		Imported ImportResult `json:"imported"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/admin/import", bundle, &response); err != nil {
+		return nil, err
+	}
+	return &response.Imported, nil
+}
@@ -0,0 +1,62 @@
+package client
+
+import (
+	"sync"
+	"time"
+)
+
+// cachedPaths are the GET endpoints whose responses are cached
+// The bot reads them several times per button press, and both rarely change.
+var cachedPaths = map[string]bool{
+	"/api/v1/children": true,
+	"/api/v1/devices":  true,
+}
+
+// responseCache keeps raw API response bodies for a short time
+type responseCache struct {
+	ttl     time.Duration
+	entries map[string]cacheEntry
+	mu      sync.Mutex
+}
+
+type cacheEntry struct {
+	body      []byte
+	expiresAt time.Time
+}
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{
+		ttl:     ttl,
+		entries: make(map[string]cacheEntry),
+	}
+}
+
+// get returns the cached body for path, if present and not expired
+func (c *responseCache) get(path string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[path]
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return entry.body, true
+}
+
+// set caches body for path until the TTL elapses
+func (c *responseCache) set(path string, body []byte) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[path] = cacheEntry{body: body, expiresAt: time.Now().Add(c.ttl)}
+}
+
+// clear drops all cached bodies (e.g., after a change was made through the API)
+func (c *responseCache) clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]cacheEntry)
+}
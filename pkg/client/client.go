@@ -0,0 +1,312 @@
+// Package client is the Go SDK for the Metron REST API. It is used by the Telegram bot and
+// the Windows agent, and by automation scripts that manage children and sessions.
+//
+// Parents and scripts use a Client, authenticated with the API key (security.api_key):
+//
+//	metron := client.New("http://metron.local:8080", apiKey)
+//	session, err := metron.CreateSession(ctx, client.CreateSessionRequest{
+//		DeviceID: "tv1",
+//		ChildIDs: []string{"alice"},
+//		Minutes:  30,
+//	})
+//	var apiErr *client.Error
+//	if errors.As(err, &apiErr) && apiErr.Code == "INSUFFICIENT_TIME" {
+//		// Alice has no time left today
+//	}
+//
+// Device agents use an AgentClient, authenticated with the device's agent token.
+//
+// Requests are sent to the versioned routes (/api/v1) with the payload version the SDK was
+// written against (APIVersion). Reads are retried after network errors and gateway errors, so
+// short server restarts go unnoticed; changes are only retried if the server never saw them.
+// Error responses are returned as *Error with the code from the API's error catalog
+// (GET /api/v1/errors).
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// APIVersion is the payload version the SDK was written against (X-Metron-API-Version)
+const APIVersion = "1"
+
+// Defaults for new clients
+const (
+	DefaultTimeout = 10 * time.Second // Per attempt
+	DefaultRetries = 2                // Retries after a transient failure
+)
+
+// retryBackoff is the wait before the first retry; it grows with each attempt
+var retryBackoff = 500 * time.Millisecond
+
+// Client is a client for the parent API, authenticated with the API key
+// Its methods are safe for concurrent use.
+type Client struct {
+	transport
+	overrideKey string // Optional: sent for parent overrides when the server requires it
+	cache       *responseCache
+}
+
+// New creates a client for the Metron server at baseURL (e.g., "http://localhost:8080")
+func New(baseURL, apiKey string) *Client {
+	c := &Client{
+		transport: newTransport(baseURL),
+		cache:     newResponseCache(0),
+	}
+	c.header.Set("X-Metron-Key", apiKey)
+	return c
+}
+
+// SetOverrideKey sets the key the server requires for parent overrides (security.override_key)
+func (c *Client) SetOverrideKey(key string) {
+	c.overrideKey = key
+}
+
+// SetCacheTTL sets how long the children and devices lists are reused (0, the default, disables it)
+// Any change made through the client clears the cache.
+func (c *Client) SetCacheTTL(ttl time.Duration) {
+	c.cache = newResponseCache(ttl)
+}
+
+// Error is returned for non-2xx API responses
+// Callers branch on Code (see GET /api/v1/errors) rather than on the message.
+type Error struct {
+	StatusCode int
+	Code       string // Empty if the body was not a standard error response
+	Message    string
+	Details    json.RawMessage // Extra fields of some errors (e.g., INSUFFICIENT_TIME)
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("API error %d: %s", e.StatusCode, e.Message)
+	}
+	return fmt.Sprintf("API error %d: %s (%s)", e.StatusCode, e.Message, e.Code)
+}
+
+// errorBody is the body of error responses
+type errorBody struct {
+	Error   string          `json:"error"`
+	Code    string          `json:"code"`
+	Details json.RawMessage `json:"details,omitempty"`
+}
+
+// do sends a request with body encoded as JSON and decodes the response into result
+func (c *Client) do(ctx context.Context, method, path string, body, result any) error {
+	data, err := marshalRequest(body)
+	if err != nil {
+		return err
+	}
+
+	cacheable := method == http.MethodGet && cachedPaths[path]
+	if cacheable {
+		if cached, ok := c.cache.get(path); ok {
+			return decodeResponse(cached, http.StatusOK, result)
+		}
+	}
+
+	var header http.Header
+	if c.overrideKey != "" {
+		header = http.Header{"X-Metron-Override-Key": {c.overrideKey}}
+	}
+	statusCode, respBody, err := c.send(ctx, method, path, data, header)
+	if err != nil {
+		return err
+	}
+
+	if cacheable {
+		c.cache.set(path, respBody)
+	} else if method != http.MethodGet {
+		// The change may affect the cached lists (e.g., a child's downtime setting)
+		c.cache.clear()
+	}
+
+	return decodeResponse(respBody, statusCode, result)
+}
+
+// transport sends authenticated requests with retries; Client and AgentClient share it
+type transport struct {
+	baseURL string
+	header  http.Header // Sent with every request (authentication, API version)
+	http    *http.Client
+	retries int
+	logger  *slog.Logger
+}
+
+func newTransport(baseURL string) transport {
+	t := transport{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		header:  make(http.Header),
+		http:    &http.Client{Timeout: DefaultTimeout},
+		retries: DefaultRetries,
+		logger:  slog.New(slog.DiscardHandler),
+	}
+	t.header.Set("X-Metron-API-Version", APIVersion)
+	return t
+}
+
+// SetTimeout sets how long one request attempt may take
+func (t *transport) SetTimeout(timeout time.Duration) {
+	t.http.Timeout = timeout
+}
+
+// SetRetries sets how often a request is retried after a transient failure
+func (t *transport) SetRetries(retries int) {
+	t.retries = retries
+}
+
+// SetLogger sets the logger for requests and retries (nothing is logged by default)
+func (t *transport) SetLogger(logger *slog.Logger) {
+	t.logger = logger
+}
+
+// send sends a request, retrying transient failures, and returns the status and body of a
+// successful response; error responses are returned as *Error
+func (t *transport) send(ctx context.Context, method, path string, data []byte, header http.Header) (int, []byte, error) {
+	var statusCode int
+	var body []byte
+	var err error
+	for attempt := 0; ; attempt++ {
+		statusCode, body, err = t.attempt(ctx, method, path, data, header)
+		if attempt >= t.retries || ctx.Err() != nil || !isTransient(method, statusCode, err) {
+			break
+		}
+
+		wait := retryBackoff * time.Duration(attempt+1)
+		t.logger.Warn("API request failed, retrying",
+			"method", method,
+			"path", path,
+			"attempt", attempt+1,
+			"status", statusCode,
+			"error", err,
+			"retry_in", wait,
+		)
+		select {
+		case <-ctx.Done():
+			return 0, nil, fmt.Errorf("request failed: %w", ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if statusCode < 200 || statusCode >= 300 {
+		return 0, nil, responseError(statusCode, body)
+	}
+	return statusCode, body, nil
+}
+
+// responseError builds the error for a non-2xx response
+func responseError(statusCode int, body []byte) *Error {
+	var errBody errorBody
+	if err := json.Unmarshal(body, &errBody); err != nil {
+		return &Error{StatusCode: statusCode, Message: string(body)}
+	}
+	return &Error{
+		StatusCode: statusCode,
+		Code:       errBody.Code,
+		Message:    errBody.Error,
+		Details:    errBody.Details,
+	}
+}
+
+// attempt makes one attempt of a request and returns the status code and body
+func (t *transport) attempt(ctx context.Context, method, path string, data []byte, header http.Header) (int, []byte, error) {
+	resp, err := t.open(ctx, t.http, method, path, data, header)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return resp.StatusCode, body, nil
+}
+
+// open sends one request and returns the response for the caller to read and close
+func (t *transport) open(ctx context.Context, client *http.Client, method, path string, data []byte, header http.Header) (*http.Response, error) {
+	url := t.baseURL + path
+
+	var reqBody io.Reader
+	if data != nil {
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	for key, values := range t.header {
+		req.Header[key] = values
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	// The server requires a Content-Type on POST and PATCH, even without a body
+	if data != nil || method == http.MethodPost || method == http.MethodPatch {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	t.logger.Debug("API request",
+		"method", method,
+		"url", url,
+	)
+
+	return client.Do(req)
+}
+
+// isTransient reports whether a failed attempt may succeed when retried
+// Reads are retried on network errors and gateway errors (e.g., while the server restarts).
+// Changes are only retried if the connection was refused, since the server never saw them.
+func isTransient(method string, statusCode int, err error) bool {
+	if err != nil {
+		var opErr *net.OpError
+		if errors.As(err, &opErr) && opErr.Op == "dial" {
+			return true
+		}
+		return method == http.MethodGet
+	}
+
+	switch statusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return method == http.MethodGet
+	}
+	return false
+}
+
+// marshalRequest encodes a request body (nil for none)
+func marshalRequest(body any) ([]byte, error) {
+	if body == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	return data, nil
+}
+
+// decodeResponse unmarshals a successful response body into result
+func decodeResponse(body []byte, statusCode int, result any) error {
+	if result != nil && statusCode != http.StatusNoContent {
+		if err := json.Unmarshal(body, result); err != nil {
+			return fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+	}
+	return nil
+}
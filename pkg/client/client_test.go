@@ -0,0 +1,359 @@
+package client
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"metron/config"
+	"metron/internal/api"
+	"metron/internal/bundle"
+	"metron/internal/core"
+	"metron/internal/devices"
+	"metron/internal/drivers"
+	"metron/internal/drivers/passive"
+	"metron/internal/storage/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testAPIKey     = "test-api-key"
+	testAgentToken = "test-agent-token"
+)
+
+// coreDevices and coreDrivers adapt the registries to the session manager, like cmd/metron
+type coreDevices struct {
+	registry *devices.Registry
+}
+
+func (r coreDevices) Get(id string) (core.Device, error) {
+	return r.registry.Get(id)
+}
+
+type coreDrivers struct {
+	registry *drivers.Registry
+}
+
+func (r coreDrivers) Get(name string) (core.DeviceDriver, error) {
+	return r.registry.Get(name)
+}
+
+// newServer starts a Metron server with in-memory storage, one child (alice) and
+// one agent-controlled device (pc1)
+// wrap, if not nil, wraps the router (e.g., to inject failures).
+func newServer(t *testing.T, wrap func(http.Handler) http.Handler) *httptest.Server {
+	t.Helper()
+	ctx := context.Background()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	db := memory.New(time.UTC)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, db.CreateChild(ctx, &core.Child{
+		ID:           "alice",
+		Name:         "Alice",
+		WeekdayLimit: 120,
+		WeekendLimit: 120,
+	}))
+
+	deviceConfigs := []config.DeviceConfig{{
+		ID:         "pc1",
+		Name:       "Alice's PC",
+		Type:       "pc",
+		Driver:     passive.DriverName,
+		Parameters: map[string]interface{}{"agent_token": testAgentToken},
+	}}
+	deviceRegistry := devices.NewRegistry()
+	for _, d := range deviceConfigs {
+		require.NoError(t, deviceRegistry.Register(&devices.Device{
+			ID:         d.ID,
+			Name:       d.Name,
+			Type:       d.Type,
+			Driver:     d.Driver,
+			Parameters: d.Parameters,
+		}))
+	}
+	driverRegistry := drivers.NewRegistry()
+	require.NoError(t, driverRegistry.Register(passive.NewDriver(logger)))
+
+	downtime := core.NewDowntimeService(nil, time.UTC)
+	calculator := core.NewTimeCalculationService(db, time.UTC)
+	manager := core.NewSessionManager(db, coreDevices{deviceRegistry}, coreDrivers{driverRegistry}, calculator, downtime, time.UTC, logger)
+
+	var handler http.Handler = api.NewRouter(api.RouterConfig{
+		Storage:             db,
+		Manager:             manager,
+		DriverRegistry:      driverRegistry,
+		DeviceRegistry:      deviceRegistry,
+		Downtime:            downtime,
+		ChildLogins:         core.NewChildLoginService(db, logger),
+		DowntimeSkipStorage: db,
+		Bundles:             bundle.NewService(db, nil, time.UTC, logger),
+		APIKey:              testAPIKey,
+		Logger:              logger,
+		Devices:             deviceConfigs,
+	})
+	if wrap != nil {
+		handler = wrap(handler)
+	}
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// flaky fails the first failures requests with 503 Service Unavailable
+func flaky(failures int32, requests *atomic.Int32) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if requests.Add(1) <= failures {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// counting counts the requests that reach the server
+func counting(requests *atomic.Int32) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func fastRetries(t *testing.T) {
+	t.Helper()
+	previous := retryBackoff
+	retryBackoff = time.Millisecond
+	t.Cleanup(func() { retryBackoff = previous })
+}
+
+func TestClient_ChildrenAndDevices(t *testing.T) {
+	server := newServer(t, nil)
+	metron := New(server.URL, testAPIKey)
+	ctx := context.Background()
+
+	children, err := metron.ListChildren(ctx)
+	require.NoError(t, err)
+	require.Len(t, children, 1)
+	assert.Equal(t, "alice", children[0].ID)
+	assert.Equal(t, 120, children[0].WeekdayLimit)
+
+	devices, err := metron.ListDevices(ctx)
+	require.NoError(t, err)
+	require.Len(t, devices, 1)
+	assert.Equal(t, "pc1", devices[0].ID)
+}
+
+func TestClient_CreateChild(t *testing.T) {
+	server := newServer(t, nil)
+	metron := New(server.URL, testAPIKey)
+	ctx := context.Background()
+
+	child, err := metron.CreateChild(ctx, CreateChildRequest{
+		Name:         "Bob",
+		WeekdayLimit: 60,
+		WeekendLimit: 90,
+	})
+	require.NoError(t, err)
+	assert.NotEmpty(t, child.ID)
+	assert.Equal(t, "Bob", child.Name)
+	assert.Equal(t, 90, child.WeekendLimit)
+
+	children, err := metron.ListChildren(ctx)
+	require.NoError(t, err)
+	assert.Len(t, children, 2)
+}
+
+func TestClient_ImportBundleAndDeleteChild(t *testing.T) {
+	server := newServer(t, nil)
+	metron := New(server.URL, testAPIKey)
+	ctx := context.Background()
+
+	result, err := metron.ImportBundle(ctx, []byte(`{
+		"format": "metron-bundle",
+		"version": 1,
+		"children": [{"id": "bob", "name": "Bob", "weekday_limit": 60, "weekend_limit": 90}],
+		"history": {
+			"sessions": [{"id": "s1", "device_type": "pc", "device_id": "pc1", "child_ids": ["bob"],
+				"start_time": "2026-03-09T17:00:00Z", "expected_minutes": 30, "actual_minutes": 30, "status": "completed"}],
+			"usage": [{"child_id": "bob", "date": "2026-03-09", "minutes_used": 30, "session_count": 1}]
+		}
+	}`))
+	require.NoError(t, err)
+	assert.Equal(t, ImportResult{Children: 1, Sessions: 1, UsageDays: 1}, *result)
+
+	_, err = metron.ImportBundle(ctx, []byte(`{"format": "metron-bundle", "version": 1,
+		"children": [{"id": "bob", "name": "Bob", "weekday_limit": 60, "weekend_limit": 90}]}`))
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "CHILD_EXISTS", apiErr.Code)
+
+	require.NoError(t, metron.DeleteChild(ctx, "bob"))
+	err = metron.DeleteChild(ctx, "bob")
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "CHILD_NOT_FOUND", apiErr.Code)
+}
+
+func TestClient_SessionLifecycle(t *testing.T) {
+	server := newServer(t, nil)
+	metron := New(server.URL, testAPIKey)
+	ctx := context.Background()
+
+	session, err := metron.CreateSession(ctx, CreateSessionRequest{
+		DeviceID: "pc1",
+		ChildIDs: []string{"alice"},
+		Minutes:  30,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "pc1", session.DeviceID)
+	assert.Equal(t, []string{"alice"}, session.ChildIDs)
+	assert.Equal(t, 30, session.ExpectedDuration)
+
+	session, err = metron.ExtendSession(ctx, session.ID, 15)
+	require.NoError(t, err)
+	assert.Equal(t, 45, session.ExpectedDuration)
+
+	active, err := metron.ListSessions(ctx, true, "alice")
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, session.ID, active[0].ID)
+
+	require.NoError(t, metron.StopSession(ctx, session.ID))
+
+	active, err = metron.ListSessions(ctx, true, "")
+	require.NoError(t, err)
+	assert.Empty(t, active)
+}
+
+func TestClient_GrantReward(t *testing.T) {
+	server := newServer(t, nil)
+	metron := New(server.URL, testAPIKey)
+
+	reward, err := metron.GrantReward(context.Background(), "alice", 15)
+	require.NoError(t, err)
+	assert.Equal(t, 15, reward.MinutesGranted)
+	assert.Equal(t, 135, reward.TodayRemaining)
+}
+
+func TestClient_Error(t *testing.T) {
+	server := newServer(t, nil)
+	ctx := context.Background()
+
+	_, err := New(server.URL, testAPIKey).GrantReward(ctx, "bob", 15)
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+	assert.Equal(t, "CHILD_NOT_FOUND", apiErr.Code)
+
+	_, err = New(server.URL, "wrong-key").ListChildren(ctx)
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+}
+
+func TestClient_RetriesReads(t *testing.T) {
+	fastRetries(t)
+	var requests atomic.Int32
+	server := newServer(t, flaky(2, &requests))
+
+	children, err := New(server.URL, testAPIKey).ListChildren(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, children, 1)
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestClient_DoesNotRetryChanges(t *testing.T) {
+	fastRetries(t)
+	var requests atomic.Int32
+	server := newServer(t, flaky(1, &requests))
+
+	_, err := New(server.URL, testAPIKey).GrantReward(context.Background(), "alice", 15)
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	assert.Equal(t, int32(1), requests.Load())
+}
+
+func TestClient_RetriesGiveUp(t *testing.T) {
+	fastRetries(t)
+	var requests atomic.Int32
+	server := newServer(t, flaky(10, &requests))
+
+	metron := New(server.URL, testAPIKey)
+	metron.SetRetries(1)
+	_, err := metron.ListChildren(context.Background())
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestClient_Cache(t *testing.T) {
+	var requests atomic.Int32
+	server := newServer(t, counting(&requests))
+	metron := New(server.URL, testAPIKey)
+	metron.SetCacheTTL(time.Minute)
+	ctx := context.Background()
+
+	for range 2 {
+		_, err := metron.ListChildren(ctx)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, int32(1), requests.Load())
+
+	// A change clears the cache
+	require.NoError(t, metron.UpdateChildDowntime(ctx, "alice", true))
+	children, err := metron.ListChildren(ctx)
+	require.NoError(t, err)
+	assert.True(t, children[0].DowntimeEnabled)
+	assert.Equal(t, int32(3), requests.Load())
+}
+
+func TestAgentClient_SessionStatus(t *testing.T) {
+	server := newServer(t, nil)
+	ctx := context.Background()
+
+	agent := NewAgent(server.URL, testAgentToken)
+	status, err := agent.GetSessionStatus(ctx, "pc1")
+	require.NoError(t, err)
+	assert.False(t, status.Active)
+
+	session, err := New(server.URL, testAPIKey).CreateSession(ctx, CreateSessionRequest{
+		DeviceID: "pc1",
+		ChildIDs: []string{"alice"},
+		Minutes:  30,
+	})
+	require.NoError(t, err)
+
+	status, err = agent.GetSessionStatus(ctx, "pc1")
+	require.NoError(t, err)
+	assert.True(t, status.Active)
+	require.NotNil(t, status.SessionID)
+	assert.Equal(t, session.ID, *status.SessionID)
+
+	require.NoError(t, agent.ReportActivity(ctx, "pc1", time.Minute))
+	require.NoError(t, agent.ReportEvents(ctx, "pc1", []AgentEvent{{
+		Type:       "clock_change",
+		OccurredAt: time.Now(),
+	}}))
+}
+
+func TestAgentClient_Unauthorized(t *testing.T) {
+	server := newServer(t, nil)
+
+	_, err := NewAgent(server.URL, "wrong-token").GetSessionStatus(context.Background(), "pc1")
+	var apiErr *Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+	assert.NotEmpty(t, apiErr.Code)
+}
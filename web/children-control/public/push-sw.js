@@ -0,0 +1,39 @@
+// Web Push handlers, imported into the generated service worker (see vite.config.ts)
+// The server sends JSON: { kind, title, body, child_id, session_id }
+
+self.addEventListener('push', (event) => {
+  let message = {};
+  try {
+    message = event.data ? event.data.json() : {};
+  } catch {
+    message = { body: event.data ? event.data.text() : '' };
+  }
+
+  event.waitUntil(
+    self.registration.showNotification(message.title || 'Metron', {
+      body: message.body || '',
+      icon: '/icon-192.png',
+      badge: '/icon-192.png',
+      // A newer notification about the same session replaces the older one
+      tag: message.session_id || message.kind,
+      renotify: true,
+      data: message,
+    })
+  );
+});
+
+self.addEventListener('notificationclick', (event) => {
+  event.notification.close();
+
+  // Focus the open app, or open it
+  event.waitUntil(
+    self.clients.matchAll({ type: 'window', includeUncontrolled: true }).then((clients) => {
+      for (const client of clients) {
+        if ('focus' in client) {
+          return client.focus();
+        }
+      }
+      return self.clients.openWindow('/');
+    })
+  );
+});
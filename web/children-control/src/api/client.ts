@@ -4,18 +4,26 @@ import type {
   Child,
   ChildForAuth,
   TodayStats,
+  DurationSuggestions,
+  DowntimeInfo,
   Device,
   Session,
   LoginRequest,
+  LinkLoginRequest,
   LoginResponse,
   CreateSessionRequest,
   APIError,
   MovieTimeAvailability,
   StartMovieTimeRequest,
+  PushPublicKey,
+  PushSubscribeRequest,
+  PushSubscriptionInfo,
 } from './types';
 
 const API_BASE_URL = import.meta.env.VITE_API_BASE || 'http://localhost:8080';
 const SESSION_KEY = 'metron_child_session';
+// Payload version the app was written against (sent as X-Metron-API-Version)
+const API_VERSION = '1';
 
 class MetronAPI {
   private sessionId: string | null = null;
@@ -32,6 +40,7 @@ class MetronAPI {
   ): Promise<T> {
     const headers = new Headers(options.headers);
     headers.set('Content-Type', 'application/json');
+    headers.set('X-Metron-API-Version', API_VERSION);
 
     // Add session ID to request if available
     if (this.sessionId) {
@@ -70,12 +79,12 @@ class MetronAPI {
   // Authentication methods
 
   async listChildren(): Promise<ChildForAuth[]> {
-    return this.request<ChildForAuth[]>('/child/auth/children');
+    return this.request<ChildForAuth[]>('/api/v1/child/auth/children');
   }
 
   async login(childId: string, pin: string): Promise<LoginResponse> {
     const request: LoginRequest = { child_id: childId, pin };
-    const response = await this.request<LoginResponse>('/child/auth/login', {
+    const response = await this.request<LoginResponse>('/api/v1/child/auth/login', {
       method: 'POST',
       body: JSON.stringify(request),
     });
@@ -87,9 +96,23 @@ class MetronAPI {
     return response;
   }
 
+  // Log in with a code from a parent's login link (QR code) instead of the PIN
+  async loginWithCode(code: string): Promise<LoginResponse> {
+    const request: LinkLoginRequest = { code };
+    const response = await this.request<LoginResponse>('/api/v1/child/auth/link', {
+      method: 'POST',
+      body: JSON.stringify(request),
+    });
+
+    this.sessionId = response.session_id;
+    localStorage.setItem(SESSION_KEY, response.session_id);
+
+    return response;
+  }
+
   async logout(): Promise<void> {
     try {
-      await this.request<void>('/child/auth/logout', {
+      await this.request<void>('/api/v1/child/auth/logout', {
         method: 'POST',
       });
     } finally {
@@ -109,19 +132,27 @@ class MetronAPI {
   // Protected methods (require authentication)
 
   async getMe(): Promise<Child> {
-    return this.request<Child>('/child/me');
+    return this.request<Child>('/api/v1/child/me');
   }
 
   async getToday(): Promise<TodayStats> {
-    return this.request<TodayStats>('/child/today');
+    return this.request<TodayStats>('/api/v1/child/today');
+  }
+
+  async getSuggestions(): Promise<DurationSuggestions> {
+    return this.request<DurationSuggestions>('/api/v1/child/suggestions');
+  }
+
+  async getDowntime(): Promise<DowntimeInfo> {
+    return this.request<DowntimeInfo>('/api/v1/child/downtime');
   }
 
   async getDevices(): Promise<Device[]> {
-    return this.request<Device[]>('/child/devices');
+    return this.request<Device[]>('/api/v1/child/devices');
   }
 
   async getSessions(): Promise<Session[]> {
-    return this.request<Session[]>('/child/sessions');
+    return this.request<Session[]>('/api/v1/child/sessions');
   }
 
   async createSession(deviceId: string, minutes: number): Promise<Session> {
@@ -129,7 +160,7 @@ class MetronAPI {
       device_id: deviceId,
       minutes,
     };
-    return this.request<Session>('/child/sessions', {
+    return this.request<Session>('/api/v1/child/sessions', {
       method: 'POST',
       body: JSON.stringify(request),
     });
@@ -152,7 +183,7 @@ class MetronAPI {
 
   async getMovieTimeAvailability(): Promise<MovieTimeAvailability | null> {
     try {
-      return await this.request<MovieTimeAvailability>('/child/movie-time');
+      return await this.request<MovieTimeAvailability>('/api/v1/child/movie-time');
     } catch {
       // Movie time feature not enabled - return null
       return null;
@@ -161,11 +192,36 @@ class MetronAPI {
 
   async startMovieTime(deviceId: string): Promise<Session> {
     const request: StartMovieTimeRequest = { device_id: deviceId };
-    return this.request<Session>('/child/movie-time', {
+    return this.request<Session>('/api/v1/child/movie-time', {
       method: 'POST',
       body: JSON.stringify(request),
     });
   }
+
+  // Web Push methods
+
+  async getPushPublicKey(): Promise<string | null> {
+    try {
+      const response = await this.request<PushPublicKey>('/api/v1/child/push/key');
+      return response.public_key;
+    } catch {
+      // Web Push not enabled on the server - return null
+      return null;
+    }
+  }
+
+  async subscribePush(request: PushSubscribeRequest): Promise<PushSubscriptionInfo> {
+    return this.request<PushSubscriptionInfo>('/api/v1/child/push/subscriptions', {
+      method: 'POST',
+      body: JSON.stringify(request),
+    });
+  }
+
+  async unsubscribePush(subscriptionId: string): Promise<void> {
+    return this.request<void>(`/child/push/subscriptions/${subscriptionId}`, {
+      method: 'DELETE',
+    });
+  }
 }
 
 // Export singleton instance
@@ -24,6 +24,24 @@ export interface TodayStats {
   downtime_end?: string;
 }
 
+export interface DowntimeDay {
+  day: string; // monday ... sunday
+  start: string; // HH:MM
+  end: string; // HH:MM, the next morning when before start
+}
+
+export interface DowntimeInfo {
+  downtime_enabled: boolean;
+  in_downtime: boolean;
+  skipped_today: boolean;
+  schedule: DowntimeDay[];
+  timezone?: string;
+  downtime_end?: string;
+  next_downtime_start?: string;
+  next_downtime_end?: string;
+  minutes_until_downtime?: number;
+}
+
 export interface Device {
   id: string;
   name: string;
@@ -38,6 +56,27 @@ export interface Session {
   start_time: string;
   remaining_minutes: number;
   status: string;
+  // Set once the expiry warning went out (cleared by an extension)
+  warning_sent_at?: string;
+  grace_ends_at?: string;
+  // Present only in start/extend responses
+  requested_minutes?: number;
+  granted_minutes?: number;
+  capped?: boolean;
+  cap_reason?: 'remaining_time' | 'extension_limit';
+}
+
+export interface DurationSuggestion {
+  minutes: number;
+  kind: 'preset' | 'half' | 'remaining' | 'until_downtime';
+}
+
+export interface DurationSuggestions {
+  child_id: string;
+  remaining_minutes: number;
+  minutes_until_downtime: number | null;
+  max_minutes: number;
+  options: DurationSuggestion[];
 }
 
 export interface LoginRequest {
@@ -45,6 +84,10 @@ export interface LoginRequest {
   pin: string;
 }
 
+export interface LinkLoginRequest {
+  code: string;
+}
+
 export interface LoginResponse {
   session_id: string;
   child: Child;
@@ -55,10 +98,20 @@ export interface CreateSessionRequest {
   minutes: number;
 }
 
+export interface InsufficientTimeDetails {
+  child_id: string;
+  child_name: string;
+  remaining_minutes: number;
+  limit_minutes: number;
+  used_minutes: number;
+  requested_minutes: number;
+}
+
 export interface APIError {
   error: string;
   code: string;
-  details?: string;
+  // Validation message, or structured details for INSUFFICIENT_TIME
+  details?: string | InsufficientTimeDetails;
 }
 
 export interface MovieTimeAvailability {
@@ -79,3 +132,25 @@ export interface MovieTimeAvailability {
 export interface StartMovieTimeRequest {
   device_id: string;
 }
+
+export interface PushPublicKey {
+  public_key: string;
+}
+
+// Browser subscription as serialized by PushSubscription.toJSON()
+export interface PushSubscribeRequest {
+  endpoint: string;
+  keys: {
+    p256dh: string;
+    auth: string;
+  };
+  label?: string;
+}
+
+export interface PushSubscriptionInfo {
+  id: string;
+  child_id?: string;
+  endpoint: string;
+  label?: string;
+  created_at: string;
+}
@@ -29,6 +29,7 @@ export function ActiveSession({ session, device, onStop, onExtend, loading }: Ac
   }, [session.remaining_minutes]);
 
   const extendOptions = [5, 15, 30, 60];
+  const quickExtendMinutes = 10;
 
   const handleExtend = (minutes: number) => {
     setShowExtendOptions(false);
@@ -55,6 +56,17 @@ export function ActiveSession({ session, device, onStop, onExtend, loading }: Ac
           <div className="text-base font-semibold opacity-95">remaining</div>
         </div>
 
+        {/* Quick extension once the expiry warning went out */}
+        {session.warning_sent_at && !showExtendOptions && (
+          <button
+            onClick={() => onExtend(quickExtendMinutes)}
+            disabled={loading}
+            className="w-full bg-yellow-300 text-purple-700 font-bold py-4 px-6 rounded-2xl shadow-lg transform transition hover:scale-105 active:scale-95 disabled:opacity-50 disabled:hover:scale-100"
+          >
+            ⏰ Almost done! Add {quickExtendMinutes} minutes
+          </button>
+        )}
+
         {/* Action buttons */}
         {!showExtendOptions ? (
           <div className="grid grid-cols-2 gap-3">
@@ -1,5 +1,9 @@
 // Duration Picker Component
 
+import { useEffect, useState } from 'react';
+import { api } from '../api/client';
+import type { DurationSuggestion } from '../api/types';
+
 interface DurationPickerProps {
   onSelect: (minutes: number) => void;
   maxMinutes: number;
@@ -12,14 +16,55 @@ interface DurationOption {
   gradient: string;
 }
 
-const durationOptions: DurationOption[] = [
-  { minutes: 5, label: '5 min', gradient: 'from-green-400 to-emerald-500' },
-  { minutes: 15, label: '15 min', gradient: 'from-blue-400 to-cyan-500' },
-  { minutes: 30, label: '30 min', gradient: 'from-purple-400 to-pink-500' },
-  { minutes: 60, label: '1 hour', gradient: 'from-orange-400 to-red-500' },
+const gradients = [
+  'from-green-400 to-emerald-500',
+  'from-blue-400 to-cyan-500',
+  'from-purple-400 to-pink-500',
+  'from-orange-400 to-red-500',
 ];
 
+// Fallback options used until suggestions load (or if the request fails)
+const fallbackMinutes = [5, 15, 30, 60];
+
+function formatLabel(minutes: number, kind?: DurationSuggestion['kind']): string {
+  const base = minutes >= 60 && minutes % 60 === 0
+    ? `${minutes / 60} hour${minutes === 60 ? '' : 's'}`
+    : `${minutes} min`;
+  if (kind === 'remaining') return `All (${base})`;
+  if (kind === 'until_downtime') return `Until bedtime (${base})`;
+  return base;
+}
+
+function buildOptions(suggestions: DurationSuggestion[]): DurationOption[] {
+  return suggestions.map((s, i) => ({
+    minutes: s.minutes,
+    label: formatLabel(s.minutes, s.kind),
+    gradient: gradients[i % gradients.length],
+  }));
+}
+
 export function DurationPicker({ onSelect, maxMinutes, disabled }: DurationPickerProps) {
+  const [durationOptions, setDurationOptions] = useState<DurationOption[]>(
+    buildOptions(fallbackMinutes.map((minutes) => ({ minutes, kind: 'preset' as const })))
+  );
+
+  // Load suggestions from the server (based on remaining time and next downtime)
+  useEffect(() => {
+    let cancelled = false;
+    api.getSuggestions()
+      .then((result) => {
+        if (!cancelled && result.options.length > 0) {
+          setDurationOptions(buildOptions(result.options));
+        }
+      })
+      .catch(() => {
+        // Keep fallback options
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, [maxMinutes]);
+
   const handleSelect = (requestedMinutes: number) => {
     // If there's any time available, allocate up to the requested amount
     const minutesToAllocate = Math.min(requestedMinutes, maxMinutes);
@@ -0,0 +1,40 @@
+// Notifications Toggle Component
+// Lets the child get "time is almost up" notifications even when the app is closed
+
+import { useState } from 'react';
+import { isPushSupported, isPushEnabled, enablePush, disablePush } from '../utils/push';
+
+export function NotificationsToggle() {
+  const [enabled, setEnabled] = useState(() => isPushSupported() && isPushEnabled());
+  const [loading, setLoading] = useState(false);
+
+  // Hidden where the browser cannot receive notifications
+  if (!isPushSupported()) return null;
+
+  const handleToggle = async () => {
+    try {
+      setLoading(true);
+      if (enabled) {
+        await disablePush();
+        setEnabled(false);
+      } else {
+        setEnabled(await enablePush());
+      }
+    } catch (err) {
+      console.error('Failed to change notifications:', err);
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <button
+      onClick={handleToggle}
+      disabled={loading}
+      title={enabled ? 'Turn off notifications' : 'Tell me when my time is almost up'}
+      className="bg-gray-200 text-gray-700 font-semibold py-2 px-4 rounded-xl hover:bg-gray-300 transition disabled:opacity-50"
+    >
+      {enabled ? '🔔' : '🔕'}
+    </button>
+  );
+}
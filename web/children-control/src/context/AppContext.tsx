@@ -2,11 +2,12 @@
 
 import { createContext, useContext, useState, useEffect, useCallback, type ReactNode } from 'react';
 import { api } from '../api/client';
-import type { Child, TodayStats, Device, Session, MovieTimeAvailability } from '../api/types';
+import type { Child, LoginResponse, TodayStats, DowntimeInfo, Device, Session, MovieTimeAvailability } from '../api/types';
 
 interface AppState {
   child: Child | null;
   stats: TodayStats | null;
+  downtime: DowntimeInfo | null;
   devices: Device[];
   sessions: Session[];
   movieTime: MovieTimeAvailability | null;
@@ -17,6 +18,7 @@ interface AppState {
 
 interface AppContextValue extends AppState {
   login: (childId: string, pin: string) => Promise<void>;
+  loginWithCode: (code: string) => Promise<void>;
   logout: () => Promise<void>;
   refresh: () => Promise<void>;
   createSession: (deviceId: string, minutes: number) => Promise<void>;
@@ -34,6 +36,7 @@ export function AppProvider({ children }: { children: ReactNode }) {
   const [state, setState] = useState<AppState>({
     child: null,
     stats: null,
+    downtime: null,
     devices: [],
     sessions: [],
     movieTime: null,
@@ -50,6 +53,7 @@ export function AppProvider({ children }: { children: ReactNode }) {
         isAuthenticated: false,
         child: null,
         stats: null,
+        downtime: null,
         devices: [],
         sessions: [],
         movieTime: null,
@@ -61,9 +65,10 @@ export function AppProvider({ children }: { children: ReactNode }) {
       setState(prev => ({ ...prev, loading: true, error: null }));
 
       // Load all data in parallel
-      const [child, stats, devices, sessions, movieTime] = await Promise.all([
+      const [child, stats, downtime, devices, sessions, movieTime] = await Promise.all([
         api.getMe(),
         api.getToday(),
+        api.getDowntime(),
         api.getDevices(),
         api.getSessions(),
         api.getMovieTimeAvailability(),
@@ -73,6 +78,7 @@ export function AppProvider({ children }: { children: ReactNode }) {
         ...prev,
         child,
         stats,
+        downtime,
         devices,
         sessions,
         movieTime,
@@ -90,11 +96,11 @@ export function AppProvider({ children }: { children: ReactNode }) {
     }
   }, []);
 
-  // Login function
-  const login = useCallback(async (childId: string, pin: string) => {
+  // Runs a login request and loads the child's data
+  const completeLogin = useCallback(async (request: () => Promise<LoginResponse>) => {
     try {
       setState(prev => ({ ...prev, loading: true, error: null }));
-      const response = await api.login(childId, pin);
+      const response = await request();
 
       setState(prev => ({
         ...prev,
@@ -116,6 +122,18 @@ export function AppProvider({ children }: { children: ReactNode }) {
     }
   }, [loadData]);
 
+  // Login function
+  const login = useCallback(
+    (childId: string, pin: string) => completeLogin(() => api.login(childId, pin)),
+    [completeLogin]
+  );
+
+  // Login with a parent's login link code
+  const loginWithCode = useCallback(
+    (code: string) => completeLogin(() => api.loginWithCode(code)),
+    [completeLogin]
+  );
+
   // Logout function
   const logout = useCallback(async () => {
     try {
@@ -126,6 +144,7 @@ export function AppProvider({ children }: { children: ReactNode }) {
       setState({
         child: null,
         stats: null,
+        downtime: null,
         devices: [],
         sessions: [],
         movieTime: null,
@@ -233,6 +252,7 @@ export function AppProvider({ children }: { children: ReactNode }) {
   const value: AppContextValue = {
     ...state,
     login,
+    loginWithCode,
     logout,
     refresh: loadData,
     createSession,
@@ -9,6 +9,10 @@ import { ActiveSession } from '../components/ActiveSession';
 import { DeviceButton } from '../components/DeviceButton';
 import { DurationPicker } from '../components/DurationPicker';
 import { MovieTimeCard } from '../components/MovieTimeCard';
+import { NotificationsToggle } from '../components/NotificationsToggle';
+
+// Show the downtime countdown when downtime starts within this many minutes
+const DOWNTIME_SOON_MINUTES = 60;
 
 export function HomePage() {
   const navigate = useNavigate();
@@ -16,6 +20,7 @@ export function HomePage() {
     isAuthenticated,
     child,
     stats,
+    downtime,
     devices,
     sessions,
     movieTime,
@@ -110,6 +115,8 @@ export function HomePage() {
 
   const hasNoTime = stats.remaining_minutes === 0;
   const isInDowntime = stats.downtime_enabled && stats.in_downtime;
+  const minutesUntilDowntime = downtime?.minutes_until_downtime;
+  const isDowntimeSoon = !isInDowntime && minutesUntilDowntime !== undefined && minutesUntilDowntime <= DOWNTIME_SOON_MINUTES;
 
   return (
     <div className="min-h-screen pb-8">
@@ -118,16 +125,19 @@ export function HomePage() {
         <div className="max-w-4xl mx-auto px-4 py-4 flex items-center justify-between">
           <div>
             <h1 className="text-2xl font-bold text-gray-800">
-              Hi, {child.name}! 👋
+              {child.emoji} Hi, {child.name}! 👋
             </h1>
             <p className="text-sm text-gray-500">Ready to have fun?</p>
           </div>
-          <button
-            onClick={handleLogout}
-            className="bg-gray-200 text-gray-700 font-semibold py-2 px-4 rounded-xl hover:bg-gray-300 transition"
-          >
-            Logout
-          </button>
+          <div className="flex items-center gap-2">
+            <NotificationsToggle />
+            <button
+              onClick={handleLogout}
+              className="bg-gray-200 text-gray-700 font-semibold py-2 px-4 rounded-xl hover:bg-gray-300 transition"
+            >
+              Logout
+            </button>
+          </div>
         </div>
       </div>
 
@@ -161,6 +171,25 @@ export function HomePage() {
           </div>
         )}
 
+        {/* Downtime Countdown - Show when downtime starts soon */}
+        {isDowntimeSoon && downtime?.next_downtime_start && (
+          <div className="card bg-indigo-50 border-2 border-indigo-200">
+            <div className="text-center py-6">
+              <div className="text-5xl mb-3">🌙</div>
+              <div className="text-2xl font-bold text-gray-800 mb-1">
+                Screens close in {formatMinutes(minutesUntilDowntime ?? 0)}
+              </div>
+              <div className="text-gray-600">
+                Downtime starts at{' '}
+                {new Date(downtime.next_downtime_start).toLocaleTimeString([], {
+                  hour: '2-digit',
+                  minute: '2-digit'
+                })}
+              </div>
+            </div>
+          </div>
+        )}
+
         {/* Time Display */}
         <div className="card">
           <TimeDisplay
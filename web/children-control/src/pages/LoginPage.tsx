@@ -1,7 +1,7 @@
 // Login Page with Child Selection and PIN Entry
 
 import { useState, useEffect } from 'react';
-import { useNavigate } from 'react-router-dom';
+import { useNavigate, useSearchParams } from 'react-router-dom';
 import { useApp } from '../context/AppContext';
 import { api } from '../api/client';
 import { ChildSelector } from '../components/ChildSelector';
@@ -9,12 +9,15 @@ import type { ChildForAuth } from '../api/types';
 
 export function LoginPage() {
   const navigate = useNavigate();
-  const { isAuthenticated, login } = useApp();
+  const { isAuthenticated, login, loginWithCode } = useApp();
+  const [searchParams] = useSearchParams();
   const [children, setChildren] = useState<ChildForAuth[]>([]);
   const [selectedChildId, setSelectedChildId] = useState<string | null>(null);
   const [pin, setPin] = useState('');
   const [error, setError] = useState('');
   const [loading, setLoading] = useState(false);
+  const [showCode, setShowCode] = useState(false);
+  const [code, setCode] = useState('');
 
   // Redirect if already authenticated
   useEffect(() => {
@@ -23,6 +26,19 @@ export function LoginPage() {
     }
   }, [isAuthenticated, navigate]);
 
+  // Log in with the code of a parent's login link (the QR code opens /login?code=...)
+  useEffect(() => {
+    const linkCode = searchParams.get('code');
+    if (!linkCode) {
+      return;
+    }
+    setLoading(true);
+    loginWithCode(linkCode)
+      .then(() => navigate('/', { replace: true }))
+      .catch(() => setError('This login link has expired or was already used. Ask a parent for a new one.'))
+      .finally(() => setLoading(false));
+  }, [searchParams, loginWithCode, navigate]);
+
   // Load children list
   useEffect(() => {
     async function loadChildren() {
@@ -80,6 +96,23 @@ export function LoginPage() {
     }
   };
 
+  // Handle a login code typed in by hand
+  const handleCodeLogin = async (e: React.FormEvent) => {
+    e.preventDefault();
+
+    try {
+      setLoading(true);
+      setError('');
+      await loginWithCode(code.trim());
+      navigate('/');
+    } catch {
+      setError('This code has expired or was already used. Ask a parent for a new one.');
+      setCode('');
+    } finally {
+      setLoading(false);
+    }
+  };
+
   // Handle back to child selection
   const handleBack = () => {
     setSelectedChildId(null);
@@ -107,6 +140,46 @@ export function LoginPage() {
           <ChildSelector children={children} onSelect={handleChildSelect} />
         )}
 
+        {/* Login code from a parent (instead of the PIN) */}
+        {!selectedChildId && (
+          <div className="max-w-md mx-auto mt-8 text-center">
+            {!showCode ? (
+              <button
+                type="button"
+                onClick={() => setShowCode(true)}
+                className="text-purple-600 font-semibold underline"
+              >
+                I have a code from a parent
+              </button>
+            ) : (
+              <form onSubmit={handleCodeLogin} className="card flex flex-col gap-4">
+                <input
+                  type="text"
+                  autoCapitalize="characters"
+                  autoComplete="off"
+                  maxLength={8}
+                  value={code}
+                  onChange={(e) => setCode(e.target.value.toUpperCase())}
+                  className="w-full text-center text-3xl font-bold tracking-widest px-4 py-4 border-2 border-gray-300 rounded-2xl focus:border-purple-500 focus:outline-none"
+                  placeholder="CODE"
+                  autoFocus
+                  disabled={loading}
+                />
+                <button type="submit" disabled={loading || code.trim() === ''} className="btn-primary">
+                  {loading ? 'Logging in...' : 'Login'}
+                </button>
+              </form>
+            )}
+          </div>
+        )}
+
+        {/* Error Message (child selection and code) */}
+        {!selectedChildId && error && (
+          <div className="max-w-md mx-auto mt-4 bg-red-50 border-2 border-red-200 rounded-2xl p-4 text-red-600 text-center font-semibold">
+            {error}
+          </div>
+        )}
+
         {/* PIN Entry */}
         {selectedChildId && (
           <div className="card max-w-md mx-auto">
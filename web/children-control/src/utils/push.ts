@@ -0,0 +1,64 @@
+// Web Push utilities: subscribe this browser to the child's session warnings and ends
+
+import { api } from '../api/client';
+
+const PUSH_SUBSCRIPTION_KEY = 'metron_push_subscription';
+
+/**
+ * Reports whether the browser can receive push notifications
+ * iOS only supports them for apps added to the home screen.
+ */
+export function isPushSupported(): boolean {
+  return 'serviceWorker' in navigator && 'PushManager' in window && 'Notification' in window;
+}
+
+/**
+ * Reports whether this browser is subscribed for the logged-in child
+ */
+export function isPushEnabled(): boolean {
+  return localStorage.getItem(PUSH_SUBSCRIPTION_KEY) !== null && Notification.permission === 'granted';
+}
+
+/**
+ * Asks for notification permission, subscribes with the server's VAPID key and saves the subscription
+ * @returns false if the server has no Web Push or permission was denied
+ */
+export async function enablePush(): Promise<boolean> {
+  const publicKey = await api.getPushPublicKey();
+  if (!publicKey) return false;
+
+  if (await Notification.requestPermission() !== 'granted') return false;
+
+  const registration = await navigator.serviceWorker.ready;
+  const subscription = await registration.pushManager.subscribe({
+    userVisibleOnly: true,
+    applicationServerKey: publicKey, // Browsers accept the base64url key as is
+  });
+
+  const json = subscription.toJSON();
+  const saved = await api.subscribePush({
+    endpoint: subscription.endpoint,
+    keys: {
+      p256dh: json.keys?.p256dh ?? '',
+      auth: json.keys?.auth ?? '',
+    },
+  });
+  localStorage.setItem(PUSH_SUBSCRIPTION_KEY, saved.id);
+  return true;
+}
+
+/**
+ * Deletes the saved subscription and unsubscribes the browser
+ */
+export async function disablePush(): Promise<void> {
+  const subscriptionId = localStorage.getItem(PUSH_SUBSCRIPTION_KEY);
+  localStorage.removeItem(PUSH_SUBSCRIPTION_KEY);
+  if (subscriptionId) {
+    await api.unsubscribePush(subscriptionId).catch(() => undefined);
+  }
+
+  const registration = await navigator.serviceWorker.ready;
+  const subscription = await registration.pushManager.getSubscription();
+  await subscription?.unsubscribe();
+}
+
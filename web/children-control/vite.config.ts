@@ -35,12 +35,13 @@ export default defineConfig({
       workbox: {
         cleanupOutdatedCaches: true,
         skipWaiting: true,
+        importScripts: ['push-sw.js'], // Web Push notifications
       }
     })
   ],
   server: {
     proxy: {
-      '/child': {
+      '/api': {
         target: process.env.VITE_API_BASE || 'http://localhost:8080',
         changeOrigin: true,
       },